@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var auditJSON bool
+
+var auditCmd = &cobra.Command{
+	Use:   "audit [task-id]",
+	Short: "Show and verify a task's audit log",
+	Long: `Show the audit log of a task and verify that it has not been modified.
+
+Every file written or deleted from agent output, every git command that
+changes a repository or pushes, and every provider API call made during a
+task is recorded in .mehrhof/work/<task-id>/audit.log. Entries are
+hash-chained: each includes the hash of the one before it, so editing,
+inserting or removing an entry breaks the chain from that point on.
+
+Read-only git commands such as status and diff are not recorded. Files that
+agents edit directly, rather than through their output, are recorded through
+the checkpoint commit that includes them.
+
+The command fails when the chain is broken. Without a task ID, the active
+task is used.`,
+	Example: `  mehr audit
+  mehr audit a1b2c3d4
+  mehr audit --json > audit.json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runAudit,
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.Flags().BoolVar(&auditJSON, "json", false, "Output entries as JSON")
+}
+
+func runAudit(cmd *cobra.Command, args []string) error {
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return err
+	}
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+	taskID, err := resolveTaskIDArg(ws, args)
+	if err != nil {
+		return err
+	}
+	if !ws.WorkExists(taskID) {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+
+	entries, err := ws.ReadAuditLog(taskID)
+	if err == nil {
+		err = storage.VerifyAuditChain(entries)
+	}
+	if err != nil && !errors.Is(err, storage.ErrAuditTampered) {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if auditJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if encErr := enc.Encode(entries); encErr != nil {
+			return fmt.Errorf("encode audit log: %w", encErr)
+		}
+
+		return err
+	}
+
+	writeAuditLog(out, taskID, entries)
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		_, _ = fmt.Fprintln(out, display.SuccessMsg("Audit log intact: %d entries, hash chain verified", len(entries)))
+	}
+
+	return nil
+}
+
+// writeAuditLog prints audit entries, one per line.
+func writeAuditLog(out io.Writer, taskID string, entries []storage.AuditEntry) {
+	if len(entries) == 0 {
+		_, _ = fmt.Fprintf(out, "No audit log for task %s.\n", taskID)
+
+		return
+	}
+
+	for _, e := range entries {
+		line := fmt.Sprintf("%4d  %s  %-11s %s", e.Seq, e.Time.Local().Format("2006-01-02 15:04:05"), e.Kind, e.Target)
+		if e.Detail != "" {
+			line += "  " + display.Muted(e.Detail)
+		}
+		if e.Error != "" {
+			line += "  " + display.Error("failed: "+e.Error)
+		}
+		_, _ = fmt.Fprintln(out, line)
+	}
+}
@@ -0,0 +1,53 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestAuditCommand_Structure(t *testing.T) {
+	if auditCmd.Use != "audit [task-id]" {
+		t.Errorf("Use = %q", auditCmd.Use)
+	}
+	if auditCmd.Flags().Lookup("json") == nil {
+		t.Error("audit has no --json flag")
+	}
+	found := false
+	for _, cmd := range rootCmd.Commands() {
+		if cmd == auditCmd {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("audit command not registered")
+	}
+}
+
+func TestWriteAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	writeAuditLog(&buf, "abc", nil)
+	if !strings.Contains(buf.String(), "No audit log for task abc") {
+		t.Errorf("output = %q", buf.String())
+	}
+
+	buf.Reset()
+	at := time.Date(2025, 1, 15, 10, 30, 0, 0, time.Local)
+	writeAuditLog(&buf, "abc", []storage.AuditEntry{
+		{Seq: 1, Time: at, Kind: storage.AuditGit, Target: "git checkout -b task/abc"},
+		{Seq: 2, Time: at, Kind: storage.AuditFileWrite, Target: "main.go", Detail: "13 bytes, sha256:ab"},
+		{Seq: 3, Time: at, Kind: storage.AuditProvider, Target: "github: create_pull_request", Error: "rate limited"},
+	})
+	out := buf.String()
+	for _, want := range []string{"   1  2025-01-15 10:30:00  git", "git checkout -b task/abc", "main.go", "13 bytes, sha256:ab", "failed: rate limited"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/provider/gitlab"
+	"github.com/valksor/go-mehrhof/internal/secrets"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var (
+	authGitLabHost     string
+	authGitLabClientID string
+	authGitLabLogout   bool
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Authenticate with providers using interactive flows",
+	Long: `Authenticate with providers using interactive OAuth flows.
+
+Credentials obtained this way are kept in ~/.mehrhof/credentials.json and
+refreshed automatically when they expire. Static tokens (environment variables,
+.mehrhof/.env, config.yaml) always take priority over stored credentials.`,
+}
+
+var authGitLabCmd = &cobra.Command{
+	Use:   "gitlab",
+	Short: "Authenticate with GitLab using the OAuth device flow",
+	Long: `Authenticate with GitLab (including self-hosted instances) using the
+OAuth 2.0 device authorization flow.
+
+Requires an OAuth application registered on the GitLab instance with the
+"api" scope and "Device authorization grant" enabled. Pass its application
+ID with --client-id, MEHR_GITLAB_CLIENT_ID, or gitlab.oauth_client_id in
+.mehrhof/config.yaml.`,
+	Example: `  mehr auth gitlab --host https://gitlab.example.com --client-id abc123
+  mehr auth gitlab --logout`,
+	RunE: runAuthGitLab,
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authGitLabCmd)
+
+	authGitLabCmd.Flags().StringVar(&authGitLabHost, "host", "", "GitLab host (default: gitlab.host from config, or https://gitlab.com)")
+	authGitLabCmd.Flags().StringVar(&authGitLabClientID, "client-id", "", "OAuth application ID")
+	authGitLabCmd.Flags().BoolVar(&authGitLabLogout, "logout", false, "Remove stored credentials for the host")
+}
+
+func runAuthGitLab(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	out := cmd.OutOrStdout()
+
+	host, clientID := resolveGitLabAuthSettings(authGitLabHost, authGitLabClientID)
+	store := secrets.NewStore("")
+	key := gitlab.CredentialKey(host)
+
+	if authGitLabLogout {
+		if err := store.Delete(key); err != nil {
+			return fmt.Errorf("remove credentials: %w", err)
+		}
+		_, _ = fmt.Fprintf(out, "Removed stored credentials for %s\n", host)
+
+		return nil
+	}
+
+	flow, err := gitlab.NewDeviceFlow(host, clientID)
+	if err != nil {
+		return fmt.Errorf("%w (use --client-id or set MEHR_GITLAB_CLIENT_ID)", err)
+	}
+
+	da, err := flow.Start(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(out, "Open %s and enter the code: %s\n", da.VerificationURI, da.UserCode)
+	if da.VerificationURIComplete != "" {
+		_, _ = fmt.Fprintf(out, "Or open directly: %s\n", da.VerificationURIComplete)
+	}
+	_, _ = fmt.Fprintln(out, "Waiting for authorization...")
+
+	cred, err := flow.Wait(ctx, da)
+	if err != nil {
+		return err
+	}
+
+	if err := store.Set(key, cred); err != nil {
+		return fmt.Errorf("save credentials: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(out, "\nAuthenticated with %s. Credentials saved to %s\n", host, store.Path())
+
+	return nil
+}
+
+// resolveGitLabAuthSettings determines host and client ID. Flags win over
+// MEHR_GITLAB_CLIENT_ID, which wins over .mehrhof/config.yaml.
+func resolveGitLabAuthSettings(flagHost, flagClientID string) (string, string) {
+	var cfgHost, cfgClientID string
+	if root, err := os.Getwd(); err == nil {
+		if ws, err := storage.OpenWorkspace(root, nil); err == nil {
+			if cfg, err := ws.LoadConfig(); err == nil && cfg.GitLab != nil {
+				cfgHost = cfg.GitLab.Host
+				cfgClientID = cfg.GitLab.OAuthClientID
+			}
+		}
+	}
+
+	host := flagHost
+	if host == "" {
+		host = cfgHost
+	}
+	if host == "" {
+		host = "https://gitlab.com"
+	}
+
+	clientID := flagClientID
+	if clientID == "" {
+		clientID = gitlab.ResolveClientID(cfgClientID)
+	}
+
+	return host, clientID
+}
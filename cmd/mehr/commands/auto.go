@@ -22,6 +22,7 @@ var (
 	autoTargetBranch  string
 	autoQualityTarget string
 	autoNoQuality     bool
+	autoContext       string
 )
 
 var autoCmd = &cobra.Command{
@@ -44,7 +45,13 @@ Examples:
   mehr auto ./tasks/                   # Full cycle from directory
   mehr auto --max-retries 5 task.md    # Allow more quality retries
   mehr auto --no-push task.md          # Don't push after merge
-  mehr auto --no-quality task.md       # Skip quality checks entirely`,
+  mehr auto --no-quality task.md       # Skip quality checks entirely
+  mehr auto template:rotate-secrets    # Full cycle for a task template run
+  pbpaste | mehr auto -                # Full cycle for a task piped on stdin
+
+Task templates (.mehrhof/templates/<name>.yaml) can set the scope and workflow
+variant (no_branch, worktree, skip_quality) of their runs. Combined with a
+scheduler such as cron or CI, recurring chores run fully automated.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runAuto,
 }
@@ -62,32 +69,53 @@ func init() {
 	autoCmd.Flags().StringVarP(&autoTargetBranch, "target", "t", "", "Target branch to merge into")
 	autoCmd.Flags().StringVar(&autoQualityTarget, "quality-target", "quality", "Make target for quality checks")
 	autoCmd.Flags().BoolVar(&autoNoQuality, "no-quality", false, "Skip quality checks entirely")
+	autoCmd.Flags().StringVar(&autoContext, "context", "", contextFlagUsage)
 }
 
 func runAuto(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
-	reference := args[0]
+	reference := expandReference(args[0])
+	contextMode, err := parseContextFlag(autoContext)
+	if err != nil {
+		return err
+	}
+
+	// Task templates supply workflow and scope defaults
+	noBranch, worktree, noQuality := autoNoBranch, autoWorktree, autoNoQuality
+	tpl, err := loadTaskTemplate(ctx, reference)
+	if err != nil {
+		return err
+	}
+	if tpl != nil {
+		noBranch = noBranch || tpl.Workflow.NoBranch
+		worktree = worktree || tpl.Workflow.Worktree
+		noQuality = noQuality || tpl.Workflow.SkipQuality
+	}
 
 	// Determine branch behavior
 	// Branch is created by default; --no-branch disables it; worktree implies branch
-	createBranch := !autoNoBranch || autoWorktree
+	createBranch := !noBranch || worktree
 
 	// Build conductor options with auto mode enabled
 	// Always use deduplicating stdout for auto since it displays progress unconditionally
 	opts := []conductor.Option{
 		conductor.WithVerbose(verbose),
 		conductor.WithCreateBranch(createBranch),
-		conductor.WithUseWorktree(autoWorktree),
+		conductor.WithUseWorktree(worktree),
 		conductor.WithAutoInit(true),
 		conductor.WithAutoMode(true),
 		conductor.WithSkipAgentQuestions(true),
 		conductor.WithMaxQualityRetries(autoMaxRetries),
+		conductor.WithContextMode(contextMode),
 		conductor.WithStdout(getDeduplicatingStdout()),
 	}
 
 	if autoAgent != "" {
 		opts = append(opts, conductor.WithAgent(autoAgent))
 	}
+	if tpl != nil && tpl.Scope != "" {
+		opts = append(opts, conductor.WithScope(tpl.Scope))
+	}
 
 	// Initialize conductor
 	cond, err := initializeConductor(ctx, opts...)
@@ -101,6 +129,57 @@ func runAuto(cmd *cobra.Command, args []string) error {
 	}
 
 	// Subscribe to events for progress display
+	subscribeAutoProgress(cond)
+
+	fmt.Printf("%s Starting auto mode for: %s\n", display.Info("[1/5]"), display.Bold(reference))
+	fmt.Printf("%s Workflow: start → plan → implement → quality → finish\n", display.Muted("     "))
+
+	// Build auto options
+	autoOpts := conductor.AutoOptions{
+		QualityTarget: autoQualityTarget,
+		MaxRetries:    autoMaxRetries,
+		SquashMerge:   !autoNoSquash,
+		DeleteBranch:  !autoNoDelete,
+		TargetBranch:  autoTargetBranch,
+		Push:          !autoNoPush,
+	}
+
+	// Skip quality if requested
+	if noQuality {
+		autoOpts.MaxRetries = 0
+	}
+
+	// Run the full auto cycle
+	result, err := cond.RunAuto(ctx, reference, autoOpts)
+	if err != nil {
+		fmt.Println()
+		fmt.Printf("Auto failed at: %s\n", result.FailedAt)
+		fmt.Printf("  Planning:       %s\n", boolToStatus(result.PlanningDone))
+		fmt.Printf("  Implementation: %s\n", boolToStatus(result.ImplementDone))
+		if cond.DocumentAfterImplement() {
+			fmt.Printf("  Documentation:  %s\n", boolToStatus(result.DocumentDone))
+		}
+		fmt.Printf("  Quality:        %d attempt(s), passed=%v\n", result.QualityAttempts, result.QualityPassed)
+		fmt.Printf("  Finish:         %s\n", boolToStatus(result.FinishDone))
+
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println(display.SuccessMsg("Task completed automatically"))
+	fmt.Printf("  %s Quality attempts: %d\n", display.Muted("•"), result.QualityAttempts)
+	if !autoNoPush {
+		fmt.Printf("  %s Changes merged and pushed\n", display.Muted("•"))
+	} else {
+		fmt.Printf("  %s Changes merged (not pushed)\n", display.Muted("•"))
+	}
+
+	return nil
+}
+
+// subscribeAutoProgress prints the progress of an unattended run, prefixed
+// with the phase it belongs to.
+func subscribeAutoProgress(cond *conductor.Conductor) {
 	w := cond.GetStdout()
 	cond.GetEventBus().SubscribeAll(func(e events.Event) {
 		switch e.Type {
@@ -150,48 +229,6 @@ func runAuto(cmd *cobra.Command, args []string) error {
 			// Ignore other event types in auto mode
 		}
 	})
-
-	fmt.Printf("%s Starting auto mode for: %s\n", display.Info("[1/5]"), display.Bold(reference))
-	fmt.Printf("%s Workflow: start → plan → implement → quality → finish\n", display.Muted("     "))
-
-	// Build auto options
-	autoOpts := conductor.AutoOptions{
-		QualityTarget: autoQualityTarget,
-		MaxRetries:    autoMaxRetries,
-		SquashMerge:   !autoNoSquash,
-		DeleteBranch:  !autoNoDelete,
-		TargetBranch:  autoTargetBranch,
-		Push:          !autoNoPush,
-	}
-
-	// Skip quality if requested
-	if autoNoQuality {
-		autoOpts.MaxRetries = 0
-	}
-
-	// Run the full auto cycle
-	result, err := cond.RunAuto(ctx, reference, autoOpts)
-	if err != nil {
-		fmt.Println()
-		fmt.Printf("Auto failed at: %s\n", result.FailedAt)
-		fmt.Printf("  Planning:       %s\n", boolToStatus(result.PlanningDone))
-		fmt.Printf("  Implementation: %s\n", boolToStatus(result.ImplementDone))
-		fmt.Printf("  Quality:        %d attempt(s), passed=%v\n", result.QualityAttempts, result.QualityPassed)
-		fmt.Printf("  Finish:         %s\n", boolToStatus(result.FinishDone))
-
-		return err
-	}
-
-	fmt.Println()
-	fmt.Println(display.SuccessMsg("Task completed automatically"))
-	fmt.Printf("  %s Quality attempts: %d\n", display.Muted("•"), result.QualityAttempts)
-	if !autoNoPush {
-		fmt.Printf("  %s Changes merged and pushed\n", display.Muted("•"))
-	} else {
-		fmt.Printf("  %s Changes merged (not pushed)\n", display.Muted("•"))
-	}
-
-	return nil
 }
 
 // boolToStatus converts a boolean to a status string.
@@ -0,0 +1,205 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/backup"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// backupPassphraseEnv is the environment variable holding the backup passphrase.
+const backupPassphraseEnv = "MEHR_BACKUP_PASSPHRASE"
+
+var (
+	backupIncludeSecrets bool
+	backupPassphraseFile string
+	backupRestoreForce   bool
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Create or restore encrypted backups of mehrhof state",
+	Long: `Back up and restore the whole .mehrhof state of a project.
+
+An archive contains config.yaml, task work directories (specifications, notes,
+sessions, usage), planned tasks, project plugins and the active task
+reference. Locks, caches and temporary files are excluded.
+
+Secrets in .mehrhof/.env are stored as pointers: variable names are kept but
+values are blanked, so a restored workspace shows which secrets to provide.
+Use --include-secrets to keep the values.
+
+Archives are encrypted with AES-256-GCM using a key derived from a passphrase.
+The passphrase is read from $MEHR_BACKUP_PASSPHRASE, from --passphrase-file,
+or prompted for on stdin.`,
+}
+
+var backupCreateCmd = &cobra.Command{
+	Use:   "create [file]",
+	Short: "Write an encrypted backup of the workspace",
+	Long: `Write an encrypted archive of the .mehrhof state.
+
+Defaults to mehrhof-backup-<date>.mehrbak in the current directory.`,
+	Example: `  mehr backup create
+  mehr backup create ~/backups/project.mehrbak
+  mehr backup create --include-secrets --passphrase-file ~/.mehr-pass`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runBackupCreate,
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <file>",
+	Short: "Restore the workspace from an encrypted backup",
+	Long: `Restore .mehrhof state from an encrypted archive.
+
+Refuses to restore into a workspace that already has config or tasks unless
+--force is given. An existing .mehrhof/.env is kept when the backup only
+contains secret pointers.`,
+	Example: `  mehr backup restore project.mehrbak
+  mehr backup restore project.mehrbak --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBackupRestore,
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	backupCmd.AddCommand(backupCreateCmd)
+	backupCmd.AddCommand(backupRestoreCmd)
+
+	backupCmd.PersistentFlags().StringVar(&backupPassphraseFile, "passphrase-file", "", "Read the passphrase from a file")
+
+	backupCreateCmd.Flags().BoolVar(&backupIncludeSecrets, "include-secrets", false, "Include .env secret values instead of pointers")
+
+	backupRestoreCmd.Flags().BoolVarP(&backupRestoreForce, "force", "f", false, "Overwrite existing workspace state")
+}
+
+func runBackupCreate(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+
+	path := "mehrhof-backup-" + time.Now().Format("2006-01-02") + ".mehrbak"
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	passphrase, err := readBackupPassphrase(cmd)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	manifest, err := backup.Create(ws, &buf, passphrase, backup.Options{IncludeSecrets: backupIncludeSecrets})
+	if err != nil {
+		return fmt.Errorf("create backup: %w", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("write backup: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(out, "Backed up %d file(s), %d task(s) to %s\n", manifest.Files, len(manifest.Tasks), path)
+	if !manifest.SecretsIncluded {
+		_, _ = fmt.Fprintln(out, "Secrets were stored as pointers (use --include-secrets to keep values)")
+	}
+
+	return nil
+}
+
+func runBackupRestore(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("open backup: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	passphrase, err := readBackupPassphrase(cmd)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := backup.Restore(ws, f, passphrase, backup.RestoreOptions{Force: backupRestoreForce})
+	if err != nil {
+		return fmt.Errorf("restore backup: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(out, "Restored %d file(s), %d task(s) from backup created %s",
+		manifest.Files, len(manifest.Tasks), manifest.CreatedAt.Local().Format(time.DateTime))
+	if manifest.Hostname != "" {
+		_, _ = fmt.Fprintf(out, " on %s", manifest.Hostname)
+	}
+	_, _ = fmt.Fprintln(out)
+	if manifest.ActiveTask != "" {
+		_, _ = fmt.Fprintf(out, "Active task: %s\n", manifest.ActiveTask)
+	}
+	if !manifest.SecretsIncluded {
+		_, _ = fmt.Fprintln(out, "Secrets were not included; fill in values in .mehrhof/.env")
+	}
+
+	return nil
+}
+
+// readBackupPassphrase returns the passphrase from the environment, the
+// --passphrase-file flag or an interactive prompt, in that order. On a
+// terminal the prompt does not echo the passphrase.
+func readBackupPassphrase(cmd *cobra.Command) (string, error) {
+	if p := os.Getenv(backupPassphraseEnv); p != "" {
+		return p, nil
+	}
+
+	if backupPassphraseFile != "" {
+		data, err := os.ReadFile(backupPassphraseFile)
+		if err != nil {
+			return "", fmt.Errorf("read passphrase file: %w", err)
+		}
+
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	_, _ = fmt.Fprint(cmd.OutOrStdout(), "Backup passphrase: ")
+	if in, ok := cmd.InOrStdin().(*os.File); ok && isInteractiveInput(in) {
+		passphrase, err := readHiddenLine(in)
+		// The Enter key was not echoed either
+		_, _ = fmt.Fprintln(cmd.OutOrStdout())
+		if err != nil {
+			return "", fmt.Errorf("read passphrase: %w", err)
+		}
+
+		return passphrase, nil
+	}
+	line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
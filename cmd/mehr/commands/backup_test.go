@@ -0,0 +1,114 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestBackupCommand_Properties(t *testing.T) {
+	if backupCmd.Use != "backup" {
+		t.Errorf("Use = %q, want %q", backupCmd.Use, "backup")
+	}
+
+	if backupCmd.Short == "" {
+		t.Error("Short description is empty")
+	}
+
+	subs := map[*cobra.Command]bool{}
+	for _, sub := range backupCmd.Commands() {
+		subs[sub] = true
+	}
+	if !subs[backupCreateCmd] || !subs[backupRestoreCmd] {
+		t.Error("create and restore subcommands not registered")
+	}
+}
+
+func TestBackupCommand_Flags(t *testing.T) {
+	tests := []struct {
+		name         string
+		cmd          *cobra.Command
+		flagName     string
+		shorthand    string
+		defaultValue string
+	}{
+		{name: "passphrase-file flag", cmd: backupCmd, flagName: "passphrase-file", defaultValue: ""},
+		{name: "include-secrets flag", cmd: backupCreateCmd, flagName: "include-secrets", defaultValue: "false"},
+		{name: "force flag", cmd: backupRestoreCmd, flagName: "force", shorthand: "f", defaultValue: "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flag := tt.cmd.Flags().Lookup(tt.flagName)
+			if flag == nil {
+				flag = tt.cmd.PersistentFlags().Lookup(tt.flagName)
+			}
+			if flag == nil {
+				t.Fatalf("flag %q not found", tt.flagName)
+			}
+			if flag.Shorthand != tt.shorthand {
+				t.Errorf("shorthand = %q, want %q", flag.Shorthand, tt.shorthand)
+			}
+			if flag.DefValue != tt.defaultValue {
+				t.Errorf("default = %q, want %q", flag.DefValue, tt.defaultValue)
+			}
+		})
+	}
+}
+
+func TestReadBackupPassphrase(t *testing.T) {
+	oldFile := backupPassphraseFile
+	t.Cleanup(func() { backupPassphraseFile = oldFile })
+
+	newCmd := func(stdin string) (*cobra.Command, *bytes.Buffer) {
+		var out bytes.Buffer
+		cmd := &cobra.Command{}
+		cmd.SetIn(strings.NewReader(stdin))
+		cmd.SetOut(&out)
+
+		return cmd, &out
+	}
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv(backupPassphraseEnv, "from-env")
+		backupPassphraseFile = ""
+
+		cmd, _ := newCmd("")
+		if got, err := readBackupPassphrase(cmd); err != nil || got != "from-env" {
+			t.Errorf("readBackupPassphrase() = %q, %v", got, err)
+		}
+	})
+
+	t.Run("file", func(t *testing.T) {
+		t.Setenv(backupPassphraseEnv, "")
+		backupPassphraseFile = filepath.Join(t.TempDir(), "pass")
+		if err := os.WriteFile(backupPassphraseFile, []byte("from file\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		cmd, _ := newCmd("")
+		if got, err := readBackupPassphrase(cmd); err != nil || got != "from file" {
+			t.Errorf("readBackupPassphrase() = %q, %v", got, err)
+		}
+	})
+
+	t.Run("prompt", func(t *testing.T) {
+		t.Setenv(backupPassphraseEnv, "")
+		backupPassphraseFile = ""
+
+		cmd, out := newCmd("typed\n")
+		if got, err := readBackupPassphrase(cmd); err != nil || got != "typed" {
+			t.Errorf("readBackupPassphrase() = %q, %v", got, err)
+		}
+		if !strings.Contains(out.String(), "passphrase") {
+			t.Errorf("prompt not shown: %q", out.String())
+		}
+	})
+}
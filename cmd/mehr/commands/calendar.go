@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/calendar"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var (
+	calendarExportOutput string
+	calendarExportSince  string
+)
+
+var calendarCmd = &cobra.Command{
+	Use:   "calendar",
+	Short: "Export task milestones to calendars",
+	Long: `Export task milestones as an iCalendar (ICS) feed, so calendars and
+planning tools can show when automated work landed.
+
+Examples:
+  mehr calendar export -o mehrhof.ics   # Write a feed to import
+  mehr serve                            # Subscribe to /api/v1/calendar.ics instead`,
+}
+
+var calendarExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export task milestones as an ICS feed",
+	Long: `Export the milestones of all tasks in the workspace as an iCalendar feed.
+
+Milestones come from each task's event log:
+  Started      The task was registered
+  Planned      A specification was created
+  Implemented  An implementation run completed
+  PR opened    A pull request was created (with its link)
+  Finished     The task was finished
+
+Each milestone is a zero-length, non-blocking event, so it never shows you
+as busy.`,
+	Example: `  mehr calendar export                          # ICS to stdout
+  mehr calendar export -o mehrhof.ics
+  mehr calendar export --since 2026-10-01`,
+	Args: cobra.NoArgs,
+	RunE: runCalendarExport,
+}
+
+func init() {
+	rootCmd.AddCommand(calendarCmd)
+	calendarCmd.AddCommand(calendarExportCmd)
+
+	calendarExportCmd.Flags().StringVarP(&calendarExportOutput, "output", "o", "", "Write to a file instead of stdout")
+	calendarExportCmd.Flags().StringVar(&calendarExportSince, "since", "", "Only include milestones from this date on (YYYY-MM-DD)")
+}
+
+func runCalendarExport(cmd *cobra.Command, args []string) error {
+	var since time.Time
+	if calendarExportSince != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, calendarExportSince, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --since %q: use YYYY-MM-DD", calendarExportSince)
+		}
+		since = parsed
+	}
+
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+
+	milestones, err := calendar.Milestones(ws, since)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if calendarExportOutput != "" {
+		f, err := os.Create(calendarExportOutput)
+		if err != nil {
+			return fmt.Errorf("create output file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+
+	return calendar.Write(out, "mehrhof: "+filepath.Base(res.Root), milestones)
+}
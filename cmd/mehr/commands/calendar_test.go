@@ -0,0 +1,47 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCalendarCommand_Structure(t *testing.T) {
+	if calendarCmd.Use != "calendar" {
+		t.Errorf("Use = %q, want %q", calendarCmd.Use, "calendar")
+	}
+	if !slices.Contains(calendarCmd.Commands(), calendarExportCmd) {
+		t.Error("export subcommand not registered")
+	}
+	if calendarExportCmd.RunE == nil {
+		t.Error("RunE not set")
+	}
+}
+
+func TestCalendarExportCommand_Flags(t *testing.T) {
+	tests := []struct {
+		flagName     string
+		shorthand    string
+		defaultValue string
+	}{
+		{"output", "o", ""},
+		{"since", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flagName, func(t *testing.T) {
+			flag := calendarExportCmd.Flags().Lookup(tt.flagName)
+			if flag == nil {
+				t.Fatalf("flag %q not found", tt.flagName)
+			}
+			if flag.Shorthand != tt.shorthand {
+				t.Errorf("shorthand = %q, want %q", flag.Shorthand, tt.shorthand)
+			}
+			if flag.DefValue != tt.defaultValue {
+				t.Errorf("default = %q, want %q", flag.DefValue, tt.defaultValue)
+			}
+		})
+	}
+}
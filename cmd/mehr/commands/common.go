@@ -9,6 +9,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/valksor/go-mehrhof/internal/agent"
 	"github.com/valksor/go-mehrhof/internal/agent/aider"
@@ -22,6 +23,8 @@ import (
 	"github.com/valksor/go-mehrhof/internal/display"
 	"github.com/valksor/go-mehrhof/internal/events"
 	"github.com/valksor/go-mehrhof/internal/output"
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/provider/adhoc"
 	"github.com/valksor/go-mehrhof/internal/provider/asana"
 	"github.com/valksor/go-mehrhof/internal/provider/azuredevops"
 	"github.com/valksor/go-mehrhof/internal/provider/bitbucket"
@@ -33,7 +36,9 @@ import (
 	"github.com/valksor/go-mehrhof/internal/provider/jira"
 	"github.com/valksor/go-mehrhof/internal/provider/linear"
 	"github.com/valksor/go-mehrhof/internal/provider/notion"
+	"github.com/valksor/go-mehrhof/internal/provider/tasktemplate"
 	"github.com/valksor/go-mehrhof/internal/provider/trello"
+	"github.com/valksor/go-mehrhof/internal/provider/webpage"
 	"github.com/valksor/go-mehrhof/internal/provider/wrike"
 	"github.com/valksor/go-mehrhof/internal/provider/youtrack"
 	"github.com/valksor/go-mehrhof/internal/vcs"
@@ -59,59 +64,93 @@ func getDeduplicatingStdout() io.Writer {
 // providers (file, directory) and agents (claude) registered.
 //
 // This is the common initialization sequence used by most commands.
-// Options should be built by the caller to customize behavior per command.
+// Options should be built by the caller to customize behavior per command;
+// MEHR_* environment variables and layered flags are applied over them.
 func initializeConductor(ctx context.Context, opts ...conductor.Option) (*conductor.Conductor, error) {
+	layered, err := resolveConfig(ctx).Options()
+	if err != nil {
+		return nil, err
+	}
+
+	if noCache {
+		opts = append(opts, conductor.WithResponseCache(false))
+	}
+
 	// Create conductor with provided options
-	cond, err := conductor.New(opts...)
+	cond, err := conductor.New(append(opts, layered...)...)
 	if err != nil {
 		return nil, fmt.Errorf("create conductor: %w", err)
 	}
 
-	// Register standard providers
-	file.Register(cond.GetProviderRegistry())
-	directory.Register(cond.GetProviderRegistry())
-	github.Register(cond.GetProviderRegistry())
-	gitlab.Register(cond.GetProviderRegistry())
-	wrike.Register(cond.GetProviderRegistry())
-	linear.Register(cond.GetProviderRegistry())
-	jira.Register(cond.GetProviderRegistry())
-	notion.Register(cond.GetProviderRegistry())
-	trello.Register(cond.GetProviderRegistry())
-	youtrack.Register(cond.GetProviderRegistry())
-	bitbucket.Register(cond.GetProviderRegistry())
-	asana.Register(cond.GetProviderRegistry())
-	clickup.Register(cond.GetProviderRegistry())
-	azuredevops.Register(cond.GetProviderRegistry())
+	registerStandardProviders(cond.GetProviderRegistry())
+	if err := registerStandardAgents(cond.GetAgentRegistry()); err != nil {
+		return nil, err
+	}
 
-	// Register standard agents
-	if err := claude.Register(cond.GetAgentRegistry()); err != nil {
-		return nil, fmt.Errorf("register claude agent: %w", err)
+	// Initialize the conductor (loads workspace, detects agent, etc.)
+	if err := cond.Initialize(ctx); err != nil {
+		return nil, fmt.Errorf("initialize: %w", err)
 	}
-	if err := codex.Register(cond.GetAgentRegistry()); err != nil {
-		return nil, fmt.Errorf("register codex agent: %w", err)
+
+	return cond, nil
+}
+
+// registerStandardProviders registers the built-in task providers.
+func registerStandardProviders(reg *provider.Registry) {
+	file.Register(reg)
+	directory.Register(reg)
+	tasktemplate.Register(reg)
+	github.Register(reg)
+	gitlab.Register(reg)
+	wrike.Register(reg)
+	linear.Register(reg)
+	jira.Register(reg)
+	notion.Register(reg)
+	trello.Register(reg)
+	youtrack.Register(reg)
+	bitbucket.Register(reg)
+	asana.Register(reg)
+	clickup.Register(reg)
+	azuredevops.Register(reg)
+	webpage.Register(reg)
+	adhoc.Register(reg)
+}
+
+// registerStandardAgents registers the built-in agents.
+func registerStandardAgents(reg *agent.Registry) error {
+	if err := claude.Register(reg); err != nil {
+		return fmt.Errorf("register claude agent: %w", err)
 	}
-	if err := aider.Register(cond.GetAgentRegistry()); err != nil {
-		return nil, fmt.Errorf("register aider agent: %w", err)
+	if err := codex.Register(reg); err != nil {
+		return fmt.Errorf("register codex agent: %w", err)
 	}
-	if err := ollama.Register(cond.GetAgentRegistry()); err != nil {
-		return nil, fmt.Errorf("register ollama agent: %w", err)
+	if err := aider.Register(reg); err != nil {
+		return fmt.Errorf("register aider agent: %w", err)
 	}
-	if err := copilot.Register(cond.GetAgentRegistry()); err != nil {
-		return nil, fmt.Errorf("register copilot agent: %w", err)
+	if err := ollama.Register(reg); err != nil {
+		return fmt.Errorf("register ollama agent: %w", err)
 	}
-	if err := openrouter.Register(cond.GetAgentRegistry()); err != nil {
-		return nil, fmt.Errorf("register openrouter agent: %w", err)
+	if err := copilot.Register(reg); err != nil {
+		return fmt.Errorf("register copilot agent: %w", err)
 	}
-	if err := gemini.Register(cond.GetAgentRegistry()); err != nil {
-		return nil, fmt.Errorf("register gemini agent: %w", err)
+	if err := openrouter.Register(reg); err != nil {
+		return fmt.Errorf("register openrouter agent: %w", err)
+	}
+	if err := gemini.Register(reg); err != nil {
+		return fmt.Errorf("register gemini agent: %w", err)
 	}
 
-	// Initialize the conductor (loads workspace, detects agent, etc.)
-	if err := cond.Initialize(ctx); err != nil {
-		return nil, fmt.Errorf("initialize: %w", err)
+	return nil
+}
+
+// expandReference maps shorthand task references to provider references:
+// "-" reads the task description from stdin.
+func expandReference(reference string) string {
+	if reference == "-" {
+		return adhoc.StdinReference
 	}
 
-	return cond, nil
+	return reference
 }
 
 // confirmAction prompts the user for confirmation unless skipConfirm is true.
@@ -146,6 +185,7 @@ type CommandOptions struct {
 	DryRun      bool
 	StepAgent   string // Per-step agent override (e.g., "planning", "implementing")
 	FullContext bool
+	Context     conductor.ContextMode // Notes and sessions sent to agents (empty uses agent.context)
 }
 
 // IsQuiet returns true if quiet mode is enabled.
@@ -153,6 +193,23 @@ func IsQuiet() bool {
 	return quiet
 }
 
+// contextFlagUsage describes the --context flag of commands that run agents.
+const contextFlagUsage = "Notes and sessions sent to the agent: full, summary or minimal (default: agent.context)"
+
+// parseContextFlag validates a --context value; empty leaves the mode to the
+// workspace config.
+func parseContextFlag(value string) (conductor.ContextMode, error) {
+	if value == "" {
+		return "", nil
+	}
+	mode, err := conductor.ParseContextMode(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid --context: %w", err)
+	}
+
+	return mode, nil
+}
+
 // BuildConductorOptions creates conductor options from command options.
 // This centralizes the common pattern of building options.
 func BuildConductorOptions(cmdOpts CommandOptions) []conductor.Option {
@@ -168,6 +225,10 @@ func BuildConductorOptions(cmdOpts CommandOptions) []conductor.Option {
 		opts = append(opts, conductor.WithIncludeFullContext(true))
 	}
 
+	if cmdOpts.Context != "" {
+		opts = append(opts, conductor.WithContextMode(cmdOpts.Context))
+	}
+
 	if cmdOpts.StepAgent != "" {
 		// Derive step name from the step agent variable name
 		// e.g., "planAgentPlanning" -> "planning"
@@ -196,6 +257,8 @@ func deriveStepName(agentVar string) string {
 		"implement":     "implementing",
 		"reviewing":     "reviewing",
 		"review":        "reviewing",
+		"documenting":   "documenting",
+		"document":      "documenting",
 		"checkpointing": "checkpointing",
 	}
 
@@ -227,7 +290,7 @@ func SetupVerboseEventHandlers(cond *conductor.Conductor) {
 		// Suppress non-essential output in quiet mode
 		if IsQuiet() {
 			switch e.Type {
-			case events.TypeProgress, events.TypeFileChanged, events.TypeCheckpoint:
+			case events.TypeProgress, events.TypeFileChanged, events.TypeCheckpoint, events.TypeRateLimited:
 				return
 			case events.TypeStateChanged, events.TypeError, events.TypeAgentMessage, events.TypeBlueprintReady, events.TypeBranchCreated, events.TypePlanCompleted, events.TypeImplementDone, events.TypePRCreated:
 				// Let other events through
@@ -261,6 +324,14 @@ func SetupVerboseEventHandlers(cond *conductor.Conductor) {
 			if agentEvent, ok := e.Data["event"].(agent.Event); ok {
 				printAgentEventTo(w, agentEvent)
 			}
+		case events.TypeRateLimited:
+			if host, ok := e.Data["host"].(string); ok {
+				wait, _ := e.Data["wait"].(time.Duration)
+				_, err := fmt.Fprintf(w, "  Rate limited by %s, waiting %s\n", host, wait.Round(time.Second))
+				if err != nil {
+					slog.Debug("write rate limit", "error", err)
+				}
+			}
 		case events.TypeStateChanged, events.TypeError, events.TypeBlueprintReady, events.TypeBranchCreated, events.TypePlanCompleted, events.TypeImplementDone, events.TypePRCreated:
 			// Ignore other event types
 		}
@@ -288,6 +359,16 @@ type WorkspaceResolution struct {
 	IsWorktree bool     // True if currently in a git worktree
 }
 
+// VCS returns the version control backend of the resolved workspace, a
+// vcs.NullVCS when it is not a git repository.
+func (r WorkspaceResolution) VCS() vcs.VCS {
+	if r.Git != nil {
+		return r.Git
+	}
+
+	return vcs.NewNull(r.Root)
+}
+
 // ResolveWorkspaceRoot resolves the workspace root directory and git context.
 // This function centralizes the common pattern of finding the workspace root
 // while handling git worktrees correctly.
@@ -40,6 +40,18 @@ func TestConfirmAction(t *testing.T) {
 	}
 }
 
+func TestExpandReference(t *testing.T) {
+	for input, want := range map[string]string{
+		"-":          "stdin:",
+		"clipboard:": "clipboard:",
+		"file:a.md":  "file:a.md",
+	} {
+		if got := expandReference(input); got != want {
+			t.Errorf("expandReference(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
 func TestGetDeduplicatingStdout(t *testing.T) {
 	// Should return a non-nil writer
 	w := getDeduplicatingStdout()
@@ -0,0 +1,237 @@
+package commands
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion <bash|zsh|fish>",
+	Short: "Generate the shell completion script",
+	Long: `Print the completion script for bash, zsh or fish.
+
+Besides commands and flags, the script completes values that are looked up
+when you press Tab: agents (built-in and aliases from config.yaml) for
+--agent and the per-step --agent-* flags, task IDs for --task and task-id
+arguments, plan IDs for 'mehr plan execute', specification numbers of the
+active task for --spec, and provider schemes for 'mehr start'.
+
+Load the completions in the current shell, or install them permanently:
+
+  bash: source <(mehr completion bash)
+        mehr completion bash > /etc/bash_completion.d/mehr
+  zsh:  source <(mehr completion zsh)
+        mehr completion zsh > "${fpath[1]}/_mehr"
+  fish: mehr completion fish | source
+        mehr completion fish > ~/.config/fish/completions/mehr.fish`,
+	Example: `  mehr completion bash > ~/.local/share/bash-completion/completions/mehr
+  mehr completion zsh > "${fpath[1]}/_mehr"
+  mehr completion fish > ~/.config/fish/completions/mehr.fish`,
+	Args:                  cobra.ExactArgs(1),
+	ValidArgs:             []string{"bash", "zsh", "fish"},
+	DisableFlagsInUseLine: true,
+	RunE:                  runCompletion,
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
+
+func runCompletion(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	switch args[0] {
+	case "bash":
+		return rootCmd.GenBashCompletionV2(out, true)
+	case "zsh":
+		return rootCmd.GenZshCompletion(out)
+	case "fish":
+		return rootCmd.GenFishCompletion(out, true)
+	}
+
+	return fmt.Errorf("unsupported shell %q (bash, zsh or fish)", args[0])
+}
+
+// isCompletionRequest reports whether cmd is the hidden command the shell
+// scripts call to compute completions.
+func isCompletionRequest(cmd *cobra.Command) bool {
+	return cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd
+}
+
+// completionFunc computes completions for a flag or argument.
+type completionFunc = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// argCompletions maps argument placeholders in Use lines to their
+// completions.
+var argCompletions = map[string]completionFunc{
+	"[task-id]":   completeTaskIDs,
+	"<task-id>":   completeTaskIDs,
+	"<plan-id>":   completePlanIDs,
+	"<reference>": completeReferences,
+}
+
+// registerCompletions sets up the dynamic completions of cmd and the commands
+// below it, by flag name and by argument placeholder, so commands added later
+// get them without registering anything themselves.
+func registerCompletions(cmd *cobra.Command) {
+	for _, sub := range cmd.Commands() {
+		registerCompletions(sub)
+	}
+
+	cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		var fn completionFunc
+		switch {
+		case f.Name == "agent" || strings.HasPrefix(f.Name, "agent-"):
+			fn = completeAgents
+		case f.Name == "task":
+			fn = completeTaskIDs
+		case f.Name == "spec":
+			fn = completeSpecNumbers
+		}
+		if fn != nil {
+			// Fails only when already registered
+			_ = cmd.RegisterFlagCompletionFunc(f.Name, fn)
+		}
+	})
+
+	if cmd.ValidArgsFunction != nil || len(cmd.ValidArgs) > 0 {
+		return
+	}
+	for _, word := range strings.Fields(cmd.Use) {
+		if fn, ok := argCompletions[word]; ok {
+			cmd.ValidArgsFunction = firstArg(fn)
+
+			return
+		}
+	}
+}
+
+// firstArg limits completions to the first argument.
+func firstArg(fn completionFunc) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return fn(cmd, args, toComplete)
+	}
+}
+
+// completionWorkspace opens the workspace of the current directory.
+func completionWorkspace(cmd *cobra.Command) (*storage.Workspace, error) {
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.OpenWorkspace(res.Root, nil)
+}
+
+// completeAgents completes built-in agents and the aliases configured in the
+// workspace.
+func completeAgents(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	reg := agent.NewRegistry()
+	if err := registerStandardAgents(reg); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names := reg.List()
+	if ws, err := completionWorkspace(cmd); err == nil {
+		if cfg, err := ws.LoadConfig(); err == nil {
+			names = append(names, slices.Collect(maps.Keys(cfg.Agents))...)
+		}
+	}
+	slices.Sort(names)
+
+	return slices.Compact(names), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTaskIDs completes the workspace's task IDs, described by title.
+func completeTaskIDs(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	ws, err := completionWorkspace(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ids, err := ws.ListWorks()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if work, err := ws.LoadWork(id); err == nil && work.Metadata.Title != "" {
+			out = append(out, id+"\t"+work.Metadata.Title)
+		} else {
+			out = append(out, id)
+		}
+	}
+
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePlanIDs completes the IDs of standalone plans.
+func completePlanIDs(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	ws, err := completionWorkspace(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ids, _ := ws.ListPlans()
+
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSpecNumbers completes the active task's specification numbers,
+// described by title and status.
+func completeSpecNumbers(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	ws, err := completionWorkspace(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	active, err := ws.LoadActiveTask()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	specs, err := ws.ListSpecificationsWithStatus(active.ID)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	out := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		out = append(out, fmt.Sprintf("%d\t%s [%s]", spec.Number, spec.Title, spec.Status))
+	}
+
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeReferences completes provider schemes for task references, and
+// falls back to file names when no scheme matches.
+func completeReferences(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if strings.Contains(toComplete, ":") {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	reg := provider.NewRegistry()
+	registerStandardProviders(reg)
+	var out []string
+	for _, info := range reg.List() {
+		for _, scheme := range info.Schemes {
+			if strings.HasPrefix(scheme, toComplete) {
+				out = append(out, scheme+":\t"+info.Description)
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	slices.Sort(out)
+
+	return out, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,123 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestCompletionCommand_Structure(t *testing.T) {
+	if !slices.Equal(completionCmd.ValidArgs, []string{"bash", "zsh", "fish"}) {
+		t.Errorf("ValidArgs = %v", completionCmd.ValidArgs)
+	}
+	found := false
+	for _, cmd := range rootCmd.Commands() {
+		if cmd == completionCmd {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("completion command not registered")
+	}
+}
+
+func TestRunCompletion(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		var buf bytes.Buffer
+		completionCmd.SetOut(&buf)
+		if err := runCompletion(completionCmd, []string{shell}); err != nil {
+			t.Errorf("%s: %v", shell, err)
+		}
+		if !strings.Contains(buf.String(), "__complete") {
+			t.Errorf("%s script does not request dynamic completions", shell)
+		}
+	}
+	completionCmd.SetOut(nil)
+
+	if err := runCompletion(completionCmd, []string{"tcsh"}); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+}
+
+func TestRegisterCompletions(t *testing.T) {
+	registerCompletions(rootCmd)
+
+	for _, tc := range []struct {
+		cmd  *cobra.Command
+		flag string
+	}{
+		{startCmd, "agent"},
+		{startCmd, "agent-plan"},
+		{implementCmd, "agent-implement"},
+		{implementCmd, "spec"},
+		{watchCmd, "task"},
+		{sessionCmd, "task"},
+	} {
+		if _, ok := tc.cmd.GetFlagCompletionFunc(tc.flag); !ok {
+			t.Errorf("%s --%s has no completion", tc.cmd.Name(), tc.flag)
+		}
+	}
+	for _, cmd := range []*cobra.Command{startCmd, planExecuteCmd, auditCmd} {
+		if cmd.ValidArgsFunction == nil {
+			t.Errorf("%s arguments have no completion", cmd.Name())
+		}
+	}
+
+	// Registering again is harmless
+	registerCompletions(rootCmd)
+}
+
+func TestCompletionValues(t *testing.T) {
+	tc := NewTestContext(t)
+	ws := tc.Workspace
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+
+	work, err := ws.CreateWork("a1b2c3d4", storage.SourceInfo{Type: "file", Ref: "task.md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	work.Metadata.Title = "Add caching"
+	if err := ws.SaveWork(work); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.SaveActiveTask(&storage.ActiveTask{ID: "a1b2c3d4", State: "idle"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.SaveSpecification("a1b2c3d4", 2, "# Cache layer\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.CreatePlan("plan-1", "seed"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := completeTaskIDs(cmd, nil, ""); !slices.Equal(got, []string{"a1b2c3d4\tAdd caching"}) {
+		t.Errorf("task IDs = %q", got)
+	}
+	if got, _ := completeSpecNumbers(cmd, nil, ""); len(got) != 1 || !strings.HasPrefix(got[0], "2\tCache layer") {
+		t.Errorf("spec numbers = %q", got)
+	}
+	if got, _ := completePlanIDs(cmd, nil, ""); !slices.Equal(got, []string{"plan-1"}) {
+		t.Errorf("plan IDs = %q", got)
+	}
+	if got, _ := completeAgents(cmd, nil, ""); !slices.Contains(got, "claude") {
+		t.Errorf("agents = %q", got)
+	}
+
+	got, directive := completeReferences(cmd, nil, "git")
+	if !slices.ContainsFunc(got, func(s string) bool { return strings.HasPrefix(s, "github:\t") }) || directive&cobra.ShellCompDirectiveNoSpace == 0 {
+		t.Errorf("references = %q, directive %d", got, directive)
+	}
+	if got, directive := completeReferences(cmd, nil, "./tasks/"); got != nil || directive != cobra.ShellCompDirectiveDefault {
+		t.Errorf("file reference = %q, directive %d", got, directive)
+	}
+}
@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
 	"github.com/valksor/go-mehrhof/internal/conductor"
 	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/output"
 	"github.com/valksor/go-mehrhof/internal/storage"
 	"github.com/valksor/go-mehrhof/internal/validation"
 )
@@ -61,6 +63,39 @@ Examples:
 	RunE: runConfigInit,
 }
 
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective configuration",
+	Long: `Show the effective value of the settings that are resolved across all
+configuration layers. Each layer overrides the ones before it:
+
+  1. built-in defaults
+  2. app settings (~/.mehrhof/settings.json)
+  3. workspace config (.mehrhof/config.yaml, then .mehrhof/config.local.yaml)
+  4. environment variables (MEHR_AGENT, MEHR_CONTEXT, MEHR_PROVIDER,
+     MEHR_COMMIT_PREFIX, MEHR_BRANCH_PATTERN, MEHR_VERBOSE)
+  5. command-line flags (--agent, --context, --commit-prefix,
+     --branch-pattern, --verbose)
+
+With --origin, each value is followed by the layer it came from.
+
+Examples:
+  mehr config show                        # Effective values
+  mehr config show --origin               # Where each value came from
+  MEHR_AGENT=codex mehr config show --origin`,
+	Args: cobra.NoArgs,
+	RunE: runConfigShow,
+}
+
+var (
+	configShowOrigin bool
+	configShowFormat string
+
+	// configFlags holds the layered flags set on the command line, recorded
+	// before each command runs.
+	configFlags map[string]string
+)
+
 var (
 	validateStrict    bool
 	validateFormat    string
@@ -72,6 +107,7 @@ func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configValidateCmd)
 	configCmd.AddCommand(configInitCmd)
+	configCmd.AddCommand(configShowCmd)
 
 	configValidateCmd.Flags().BoolVar(&validateStrict, "strict", false,
 		"Treat warnings as errors (exit code 1 if warnings present)")
@@ -80,6 +116,11 @@ func init() {
 
 	configInitCmd.Flags().BoolVarP(&configInitForce, "force", "f", false,
 		"Overwrite existing config file without prompting")
+	configShowCmd.Flags().BoolVar(&configShowOrigin, "origin", false,
+		"Show the layer each value came from")
+	configShowCmd.Flags().StringVar(&configShowFormat, "format", "text",
+		"Output format: text, json")
+
 	configInitCmd.Flags().StringVar(&configInitProject, "project", "",
 		"Project type for intelligent defaults (go, node, python, php)")
 }
@@ -143,6 +184,86 @@ func getBuiltInAgents(ctx context.Context) []string {
 	return []string{"claude"}
 }
 
+// layeredFlagValues returns the layered flags set on the command line of
+// cmd, by flag name.
+func layeredFlagValues(cmd *cobra.Command) map[string]string {
+	values := make(map[string]string)
+	for _, name := range conductor.LayeredFlags() {
+		if f := cmd.Flags().Lookup(name); f != nil && f.Changed {
+			values[name] = f.Value.String()
+		}
+	}
+
+	return values
+}
+
+// resolveConfig resolves the effective settings of the current workspace
+// from all configuration layers. A workspace config that cannot be read
+// is skipped; the conductor reports it when it loads the workspace.
+func resolveConfig(ctx context.Context) *conductor.ResolvedConfig {
+	layers := conductor.ConfigLayers{
+		App:   settings,
+		Env:   os.Getenv,
+		Flags: configFlags,
+	}
+
+	if res, err := ResolveWorkspaceRoot(ctx); err == nil {
+		if ws, err := storage.OpenWorkspace(res.Root, nil); err == nil {
+			if cfg, err := ws.LoadConfigFile(); err == nil && cfg != nil {
+				layers.Workspace = cfg
+				layers.WorkspaceFile = ws.ConfigPath()
+			}
+			if cfg, err := ws.LoadLocalConfigFile(); err == nil && cfg != nil {
+				layers.Local = cfg
+				layers.LocalFile = ws.LocalConfigPath()
+			}
+		}
+	}
+
+	return layers.Resolve()
+}
+
+// validateReloadedConfig is the check long-running commands give the
+// conductor for configs changed while they run.
+func validateReloadedConfig(cfg *storage.WorkspaceConfig, path string, builtInAgents []string) error {
+	result := validation.ValidateConfig(cfg, path, builtInAgents)
+	if !result.Valid {
+		return errors.New(result.Format("text"))
+	}
+
+	return nil
+}
+
+// runConfigShow prints the effective settings, optionally with their origin.
+func runConfigShow(cmd *cobra.Command, _ []string) error {
+	resolved := resolveConfig(cmd.Context())
+	out := cmd.OutOrStdout()
+
+	if configShowFormat == "json" {
+		return output.JSON(out, resolved.Settings)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, s := range resolved.Settings {
+		value := s.Value
+		if value == "" {
+			value = "(none)"
+		}
+		if !configShowOrigin {
+			_, _ = fmt.Fprintf(w, "%s\t%s\n", s.Key, value)
+
+			continue
+		}
+		origin := s.Origin
+		if s.Source != "" {
+			origin += " (" + s.Source + ")"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, value, display.Muted(origin))
+	}
+
+	return w.Flush()
+}
+
 // runConfigInit creates a new workspace configuration file.
 func runConfigInit(cmd *cobra.Command, args []string) error {
 	// Get working directory
@@ -4,10 +4,16 @@
 package commands
 
 import (
+	"bytes"
+	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/display"
 	"github.com/valksor/go-mehrhof/internal/storage"
 )
 
@@ -322,3 +328,39 @@ func TestApplyProjectCustomizations(t *testing.T) {
 		})
 	}
 }
+
+func TestRunConfigShow_Origin(t *testing.T) {
+	display.SetColorsEnabled(false)
+	t.Cleanup(func() { display.SetColorsEnabled(true) })
+	tc := NewTestContext(t)
+
+	cfg := storage.NewDefaultWorkspaceConfig()
+	cfg.Agent.Default = "codex"
+	if err := tc.Workspace.SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	t.Setenv("MEHR_CONTEXT", "minimal")
+
+	prevFlags, prevOrigin := configFlags, configShowOrigin
+	configFlags, configShowOrigin = map[string]string{"verbose": "true"}, true
+	t.Cleanup(func() { configFlags, configShowOrigin = prevFlags, prevOrigin })
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	cmd.SetContext(context.Background())
+	if err := runConfigShow(cmd, nil); err != nil {
+		t.Fatalf("runConfigShow: %v", err)
+	}
+
+	for _, want := range []string{
+		"agent.default       codex                 workspace (" + tc.Workspace.ConfigPath() + ")",
+		"agent.context       minimal               env (MEHR_CONTEXT)",
+		"providers.default   file                  workspace",
+		"verbose             true                  flag (--verbose)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
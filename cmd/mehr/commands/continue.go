@@ -64,18 +64,15 @@ func runContinue(cmd *cobra.Command, args []string) error {
 	activeTask := cond.GetActiveTask()
 	if activeTask == nil {
 		// Try to detect from branch
-		git := cond.GetGit()
-		if git != nil {
-			branch, err := git.CurrentBranch(ctx)
-			if err == nil && strings.HasPrefix(branch, "task/") {
-				taskID := strings.TrimPrefix(branch, "task/")
-				fmt.Printf("On task branch: %s\n", branch)
-				fmt.Printf("But no active task found with ID: %s\n\n", taskID)
-				fmt.Println("The task may have been completed or deleted.")
-				fmt.Println("To start a new task, run: mehr start <reference>")
-
-				return nil
-			}
+		branch, err := cond.GetVCS().CurrentBranch(ctx)
+		if err == nil && strings.HasPrefix(branch, "task/") {
+			taskID := strings.TrimPrefix(branch, "task/")
+			fmt.Printf("On task branch: %s\n", branch)
+			fmt.Printf("But no active task found with ID: %s\n\n", taskID)
+			fmt.Println("The task may have been completed or deleted.")
+			fmt.Println("To start a new task, run: mehr start <reference>")
+
+			return nil
 		}
 
 		fmt.Println("No active task found.")
@@ -140,6 +137,9 @@ func runContinue(cmd *cobra.Command, args []string) error {
 	case workflow.StateReviewing:
 		fmt.Println("  mehr finish     # Complete and merge")
 		fmt.Println("  mehr implement  # Make more changes")
+	case workflow.StateDocumenting:
+		fmt.Println("  mehr status     # View documentation progress")
+		fmt.Println("  mehr review     # Review the changes")
 	case workflow.StateFailed:
 		fmt.Println("  mehr status     # View error details")
 		fmt.Println("  mehr note       # Add notes")
@@ -193,7 +193,7 @@ func executeNextStep(ctx context.Context, cond *conductor.Conductor, status *con
 		fmt.Println("Running: mehr implement")
 
 		return cond.Implement(ctx)
-	case workflow.StateImplementing, workflow.StateReviewing:
+	case workflow.StateImplementing, workflow.StateReviewing, workflow.StateDocumenting:
 		fmt.Println("Already in progress - use 'mehr finish' when complete")
 
 		return nil
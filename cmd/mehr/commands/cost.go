@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -14,6 +13,7 @@ import (
 
 	"github.com/valksor/go-mehrhof/internal/display"
 	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
 )
 
 var (
@@ -36,7 +36,8 @@ Examples:
   mehr cost --breakdown   # Break down by workflow step
   mehr cost --all         # Show costs for all tasks
   mehr cost --summary     # Summary of all tasks
-  mehr cost --json        # Output as JSON`,
+  mehr cost --json        # Output as JSON
+  mehr cost export        # Export usage as CSV for reporting`,
 	RunE: runCost,
 }
 
@@ -69,7 +70,7 @@ func runCost(cmd *cobra.Command, args []string) error {
 
 	// If in a worktree, auto-detect task from worktree path
 	if res.IsWorktree {
-		return showWorktreeCost(ws, res.Git)
+		return showWorktreeCost(ws, res.VCS())
 	}
 
 	return showActiveCost(ws)
@@ -116,11 +117,11 @@ type jsonSummaryOutput struct {
 	ByStep     map[string]jsonStepCost `json:"by_step"`
 }
 
-func showWorktreeCost(ws *storage.Workspace, git interface{}) error {
-	if git == nil {
+func showWorktreeCost(ws *storage.Workspace, git vcs.VCS) error {
+	if !git.IsWorktree() {
 		return errors.New("not in a worktree")
 	}
-	active, err := ws.FindTaskByWorktreePath(ws.Root())
+	active, err := ws.FindTaskByWorktreePath(git.Root())
 	if err != nil {
 		return fmt.Errorf("find task by worktree: %w", err)
 	}
@@ -272,20 +273,13 @@ func showTaskCost(ws *storage.Workspace, taskID, label string) error {
 	return nil
 }
 
-func outputJSON(v any) error {
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-
-	return enc.Encode(v)
-}
-
 func showAllCosts(ws *storage.Workspace, summaryMode bool) error {
-	taskIDs, err := ws.ListWorks()
+	works, err := ws.LoadWorks()
 	if err != nil {
 		return fmt.Errorf("list tasks: %w", err)
 	}
 
-	if len(taskIDs) == 0 {
+	if len(works) == 0 {
 		if costJSON {
 			return outputJSON(jsonAllCostsOutput{
 				Tasks:      []jsonCostOutput{},
@@ -307,7 +301,7 @@ func showAllCosts(ws *storage.Workspace, summaryMode bool) error {
 	}
 
 	if summaryMode {
-		return showCostSummary(ws, taskIDs)
+		return showCostSummary(works)
 	}
 
 	// JSON output
@@ -316,11 +310,8 @@ func showAllCosts(ws *storage.Workspace, summaryMode bool) error {
 		var grandTotalInput, grandTotalOutput int
 		var grandTotalCost float64
 
-		for _, taskID := range taskIDs {
-			work, err := ws.LoadWork(taskID)
-			if err != nil {
-				continue
-			}
+		for _, work := range works {
+			taskID := work.Metadata.ID
 
 			costs := work.Costs
 			totalTokens := costs.TotalInputTokens + costs.TotalOutputTokens
@@ -362,11 +353,8 @@ func showAllCosts(ws *storage.Workspace, summaryMode bool) error {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	_, _ = fmt.Fprintln(w, "TASK ID\tTITLE\tINPUT\tOUTPUT\tTOTAL\tCOST")
 
-	for _, taskID := range taskIDs {
-		work, err := ws.LoadWork(taskID)
-		if err != nil {
-			continue
-		}
+	for _, work := range works {
+		taskID := work.Metadata.ID
 
 		costs := work.Costs
 		totalTokens := costs.TotalInputTokens + costs.TotalOutputTokens
@@ -400,11 +388,7 @@ func showAllCosts(ws *storage.Workspace, summaryMode bool) error {
 
 	// Calculate grand total
 	var grandTotalInput, grandTotalOutput, grandTotalCost int64
-	for _, taskID := range taskIDs {
-		work, err := ws.LoadWork(taskID)
-		if err != nil {
-			continue
-		}
+	for _, work := range works {
 		grandTotalInput += int64(work.Costs.TotalInputTokens)
 		grandTotalOutput += int64(work.Costs.TotalOutputTokens)
 		grandTotalCost += int64(work.Costs.TotalCostUSD * 10000) // Convert to fixed-point
@@ -420,7 +404,7 @@ func showAllCosts(ws *storage.Workspace, summaryMode bool) error {
 	return nil
 }
 
-func showCostSummary(ws *storage.Workspace, taskIDs []string) error {
+func showCostSummary(works []*storage.TaskWork) error {
 	var grandTotalInput, grandTotalOutput, grandTotalCached int
 	var grandTotalCost float64
 	var taskCount int
@@ -428,12 +412,7 @@ func showCostSummary(ws *storage.Workspace, taskIDs []string) error {
 	// Per-step totals
 	stepTotals := make(map[string]*storage.StepCostStats)
 
-	for _, taskID := range taskIDs {
-		work, err := ws.LoadWork(taskID)
-		if err != nil {
-			continue
-		}
-
+	for _, work := range works {
 		taskCount++
 		costs := work.Costs
 
@@ -0,0 +1,256 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var (
+	costExportFormat string
+	costExportMonth  string
+	costExportOutput string
+)
+
+var costExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export usage and costs as CSV or JSON",
+	Long: `Export token usage and costs for all tasks in the workspace.
+
+Each row is one agent call from the task's usage ledger, tagged with cost
+center fields so reporting pipelines can group spend:
+  project, task_id, title, external_key, task_type, provider, step, agent, model
+
+Tasks recorded before the usage ledger existed export one row per workflow
+step, dated at the task's last update.`,
+	Example: `  mehr cost export                          # CSV to stdout
+  mehr cost export --month 2026-10 -o oct.csv
+  mehr cost export --format json --month 2026-10`,
+	Args: cobra.NoArgs,
+	RunE: runCostExport,
+}
+
+func init() {
+	costCmd.AddCommand(costExportCmd)
+
+	costExportCmd.Flags().StringVar(&costExportFormat, "format", "csv", "Output format (csv, json)")
+	costExportCmd.Flags().StringVar(&costExportMonth, "month", "", "Only include usage from this month (YYYY-MM)")
+	costExportCmd.Flags().StringVarP(&costExportOutput, "output", "o", "", "Write to a file instead of stdout")
+}
+
+// usageRow is one exported usage entry with its cost center tags.
+type usageRow struct {
+	Date         time.Time `json:"date"`
+	Project      string    `json:"project"`
+	TaskID       string    `json:"task_id"`
+	Title        string    `json:"title,omitempty"`
+	ExternalKey  string    `json:"external_key,omitempty"`
+	TaskType     string    `json:"task_type,omitempty"`
+	Provider     string    `json:"provider,omitempty"`
+	Step         string    `json:"step"`
+	Agent        string    `json:"agent,omitempty"`
+	Model        string    `json:"model,omitempty"`
+	Calls        int       `json:"calls"`
+	InputTokens  int       `json:"input_tokens"`
+	OutputTokens int       `json:"output_tokens"`
+	CachedTokens int       `json:"cached_tokens"`
+	CostUSD      float64   `json:"cost_usd"`
+}
+
+var usageCSVHeader = []string{
+	"date", "project", "task_id", "title", "external_key", "task_type", "provider",
+	"step", "agent", "model", "calls", "input_tokens", "output_tokens", "cached_tokens", "cost_usd",
+}
+
+func runCostExport(cmd *cobra.Command, args []string) error {
+	if costExportFormat != "csv" && costExportFormat != "json" {
+		return fmt.Errorf("unsupported format %q (use csv or json)", costExportFormat)
+	}
+	if costExportMonth != "" {
+		if _, err := time.Parse("2006-01", costExportMonth); err != nil {
+			return fmt.Errorf("invalid --month %q: use YYYY-MM", costExportMonth)
+		}
+	}
+
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+
+	rows, err := collectUsageRows(ws, filepath.Base(res.Root), costExportMonth)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if costExportOutput != "" {
+		f, err := os.Create(costExportOutput)
+		if err != nil {
+			return fmt.Errorf("create output file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+
+	if costExportFormat == "json" {
+		return writeUsageJSON(out, rows)
+	}
+
+	return writeUsageCSV(out, rows)
+}
+
+// collectUsageRows builds export rows for all tasks, optionally limited to one month (YYYY-MM).
+func collectUsageRows(ws *storage.Workspace, project, month string) ([]usageRow, error) {
+	taskIDs, err := ws.ListWorks()
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+
+	rows := []usageRow{}
+	for _, taskID := range taskIDs {
+		work, err := ws.LoadWork(taskID)
+		if err != nil {
+			continue
+		}
+		records, err := ws.LoadUsageRecords(taskID)
+		if err != nil {
+			return nil, fmt.Errorf("load usage for %s: %w", taskID, err)
+		}
+
+		base := usageRow{
+			Project:     project,
+			TaskID:      taskID,
+			Title:       work.Metadata.Title,
+			ExternalKey: work.Metadata.ExternalKey,
+			TaskType:    work.Metadata.TaskType,
+			Provider:    work.Source.Type,
+		}
+
+		var taskRows []usageRow
+		if len(records) > 0 {
+			for _, record := range records {
+				row := base
+				row.Date = record.Timestamp
+				row.Step = record.Step
+				row.Agent = record.Agent
+				row.Model = record.Model
+				row.Calls = 1
+				row.InputTokens = record.InputTokens
+				row.OutputTokens = record.OutputTokens
+				row.CachedTokens = record.CachedTokens
+				row.CostUSD = record.CostUSD
+				taskRows = append(taskRows, row)
+			}
+		} else {
+			taskRows = aggregateUsageRows(base, work)
+		}
+
+		for _, row := range taskRows {
+			if month == "" || row.Date.Format("2006-01") == month {
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	slices.SortStableFunc(rows, func(a, b usageRow) int { return a.Date.Compare(b.Date) })
+
+	return rows, nil
+}
+
+// aggregateUsageRows returns one row per workflow step for tasks without a usage ledger.
+func aggregateUsageRows(base usageRow, work *storage.TaskWork) []usageRow {
+	steps := make([]string, 0, len(work.Costs.ByStep))
+	for step := range work.Costs.ByStep {
+		steps = append(steps, step)
+	}
+	slices.Sort(steps)
+
+	rows := make([]usageRow, 0, len(steps))
+	for _, step := range steps {
+		stats := work.Costs.ByStep[step]
+		row := base
+		row.Date = work.Metadata.UpdatedAt
+		row.Step = step
+		row.Agent = stepAgentName(work.Agent, step)
+		row.Calls = stats.Calls
+		row.InputTokens = stats.InputTokens
+		row.OutputTokens = stats.OutputTokens
+		row.CachedTokens = stats.CachedTokens
+		row.CostUSD = stats.CostUSD
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// stepAgentName returns the agent recorded for a usage step. Review usage is
+// recorded as "review" while the agent step is "reviewing".
+func stepAgentName(info storage.AgentInfo, step string) string {
+	if step == "review" {
+		step = "reviewing"
+	}
+	if stepInfo, ok := info.Steps[step]; ok && stepInfo.Name != "" {
+		return stepInfo.Name
+	}
+
+	return info.Name
+}
+
+func writeUsageCSV(w io.Writer, rows []usageRow) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(usageCSVHeader); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+
+	for _, row := range rows {
+		record := []string{
+			row.Date.UTC().Format(time.RFC3339),
+			row.Project,
+			row.TaskID,
+			row.Title,
+			row.ExternalKey,
+			row.TaskType,
+			row.Provider,
+			row.Step,
+			row.Agent,
+			row.Model,
+			strconv.Itoa(row.Calls),
+			strconv.Itoa(row.InputTokens),
+			strconv.Itoa(row.OutputTokens),
+			strconv.Itoa(row.CachedTokens),
+			strconv.FormatFloat(row.CostUSD, 'f', 6, 64),
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("write csv: %w", err)
+		}
+	}
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+
+	return nil
+}
+
+func writeUsageJSON(w io.Writer, rows []usageRow) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(rows)
+}
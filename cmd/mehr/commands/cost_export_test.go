@@ -0,0 +1,178 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestCostExportCommand_Flags(t *testing.T) {
+	tests := []struct {
+		name         string
+		flagName     string
+		shorthand    string
+		defaultValue string
+	}{
+		{name: "format flag", flagName: "format", defaultValue: "csv"},
+		{name: "month flag", flagName: "month", defaultValue: ""},
+		{name: "output flag", flagName: "output", shorthand: "o", defaultValue: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flag := costExportCmd.Flags().Lookup(tt.flagName)
+			if flag == nil {
+				t.Fatalf("flag %q not found", tt.flagName)
+			}
+			if flag.Shorthand != tt.shorthand {
+				t.Errorf("shorthand = %q, want %q", flag.Shorthand, tt.shorthand)
+			}
+			if flag.DefValue != tt.defaultValue {
+				t.Errorf("default = %q, want %q", flag.DefValue, tt.defaultValue)
+			}
+		})
+	}
+}
+
+// newCostWorkspace creates a workspace with one task that has a usage ledger
+// and one older task with only aggregated costs.
+func newCostWorkspace(t *testing.T) *storage.Workspace {
+	t.Helper()
+
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+
+	ledger, err := ws.CreateWork("ledger", storage.SourceInfo{Type: "github", Ref: "github:12"})
+	if err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	ledger.Metadata.Title = "Add login"
+	ledger.Metadata.ExternalKey = "AUTH-1"
+	ledger.Metadata.TaskType = "feature"
+	if err := ws.SaveWork(ledger); err != nil {
+		t.Fatalf("SaveWork: %v", err)
+	}
+	for _, record := range []storage.UsageRecord{
+		{Timestamp: time.Date(2026, 9, 30, 23, 0, 0, 0, time.UTC), Step: "planning", Agent: "claude", Model: "opus", InputTokens: 100, OutputTokens: 10, CostUSD: 0.5},
+		{Timestamp: time.Date(2026, 10, 2, 9, 0, 0, 0, time.UTC), Step: "implementing", Agent: "claude", InputTokens: 200, OutputTokens: 20, CostUSD: 1.5},
+	} {
+		if err := ws.AppendUsageRecord("ledger", record); err != nil {
+			t.Fatalf("AppendUsageRecord: %v", err)
+		}
+	}
+
+	legacy, err := ws.CreateWork("legacy", storage.SourceInfo{Type: "file", Ref: "task.md"})
+	if err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	legacy.Agent = storage.AgentInfo{Name: "claude", Steps: map[string]storage.StepAgentInfo{"reviewing": {Name: "codex"}}}
+	legacy.Costs = storage.CostStats{ByStep: map[string]storage.StepCostStats{
+		"planning": {InputTokens: 50, OutputTokens: 5, CostUSD: 0.25, Calls: 2},
+		"review":   {InputTokens: 70, OutputTokens: 7, CostUSD: 0.75, Calls: 1},
+	}}
+	if err := ws.SaveWork(legacy); err != nil {
+		t.Fatalf("SaveWork: %v", err)
+	}
+
+	return ws
+}
+
+func TestCollectUsageRows(t *testing.T) {
+	ws := newCostWorkspace(t)
+
+	rows, err := collectUsageRows(ws, "shop", "")
+	if err != nil {
+		t.Fatalf("collectUsageRows: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("collectUsageRows returned %d rows, want 4", len(rows))
+	}
+
+	byKey := map[string]usageRow{}
+	for _, row := range rows {
+		byKey[row.TaskID+"/"+row.Step] = row
+	}
+
+	planning := byKey["ledger/planning"]
+	if planning.Project != "shop" || planning.ExternalKey != "AUTH-1" || planning.TaskType != "feature" ||
+		planning.Provider != "github" || planning.Model != "opus" || planning.Calls != 1 {
+		t.Errorf("ledger row = %+v", planning)
+	}
+
+	review := byKey["legacy/review"]
+	if review.Agent != "codex" || review.Calls != 1 || review.CostUSD != 0.75 {
+		t.Errorf("aggregated review row = %+v", review)
+	}
+	if byKey["legacy/planning"].Agent != "claude" {
+		t.Errorf("aggregated planning agent = %q, want claude", byKey["legacy/planning"].Agent)
+	}
+
+	october, err := collectUsageRows(ws, "shop", "2026-10")
+	if err != nil {
+		t.Fatalf("collectUsageRows month: %v", err)
+	}
+	for _, row := range october {
+		if row.TaskID == "ledger" && row.Step != "implementing" {
+			t.Errorf("month filter kept %s/%s from %v", row.TaskID, row.Step, row.Date)
+		}
+	}
+}
+
+func TestWriteUsageCSV(t *testing.T) {
+	rows := []usageRow{{
+		Date:        time.Date(2026, 10, 2, 9, 0, 0, 0, time.UTC),
+		Project:     "shop",
+		TaskID:      "a1",
+		Title:       "Fix, then ship",
+		ExternalKey: "BUG-7",
+		Step:        "implementing",
+		Agent:       "claude",
+		Calls:       1,
+		InputTokens: 10,
+		CostUSD:     0.125,
+	}}
+
+	var buf bytes.Buffer
+	if err := writeUsageCSV(&buf, rows); err != nil {
+		t.Fatalf("writeUsageCSV: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(records) != 2 || len(records[0]) != len(usageCSVHeader) {
+		t.Fatalf("csv = %v", records)
+	}
+	got := records[1]
+	if got[0] != "2026-10-02T09:00:00Z" || got[3] != "Fix, then ship" || got[4] != "BUG-7" || got[14] != "0.125000" {
+		t.Errorf("csv row = %v", got)
+	}
+}
+
+func TestWriteUsageJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeUsageJSON(&buf, []usageRow{{TaskID: "a1", Model: "opus", CostUSD: 1}}); err != nil {
+		t.Fatalf("writeUsageJSON: %v", err)
+	}
+
+	var decoded []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("parse json: %v", err)
+	}
+	if len(decoded) != 1 || decoded[0]["task_id"] != "a1" || decoded[0]["model"] != "opus" {
+		t.Errorf("json = %s", buf.String())
+	}
+}
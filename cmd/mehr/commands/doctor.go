@@ -0,0 +1,215 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/output"
+	"github.com/valksor/go-mehrhof/internal/provider/github"
+	"github.com/valksor/go-mehrhof/internal/provider/gitlab"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/validation"
+)
+
+var (
+	doctorFix     bool
+	doctorOffline bool
+	doctorStrict  bool
+	doctorFormat  string
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the environment mehrhof runs in",
+	Long: `Check everything mehrhof depends on and print how to fix what is wrong:
+
+  - git is installed, recent enough (` + validation.MinGitVersion + `+) and worktrees work
+  - the workspace configuration is valid (as in 'mehr config validate')
+  - the agent CLIs are installed, in particular the default agent
+  - GitHub and GitLab tokens are accepted by the provider, with the
+    remaining API rate limit (skipped with --offline)
+  - the workspace state is consistent: the active task has a work
+    directory and every work directory can be loaded
+
+With --fix, problems with a safe automatic fix are repaired first:
+malformed config.yaml, work.yaml and .active_task files are restored from
+the .bak copy written on every save, an .active_task pointing at a missing
+work directory is removed, missing specifications/, sessions/ and notes.md
+are recreated, and worktrees on a task's branch are linked back to the task.
+The checks then run on the repaired workspace.
+
+The command exits with status 1 when a check fails, so it can gate CI jobs.`,
+	Example: `  mehr doctor                  # Run all checks
+  mehr doctor --fix            # Repair the workspace, then check
+  mehr doctor --offline        # Do not contact GitHub or GitLab
+  mehr doctor --format json    # Findings as JSON`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Repair workspace problems that have an automatic fix")
+	doctorCmd.Flags().BoolVar(&doctorOffline, "offline", false, "Skip the checks that contact providers")
+	doctorCmd.Flags().BoolVar(&doctorStrict, "strict", false, "Fail on warnings too")
+	doctorCmd.Flags().StringVar(&doctorFormat, "format", "text", "Output format: text, json")
+}
+
+func runDoctor(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	out := cmd.OutOrStdout()
+
+	res, err := ResolveWorkspaceRoot(ctx)
+	if err != nil {
+		return err
+	}
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+	cfg, err := ws.LoadConfig()
+	if err != nil {
+		cfg = storage.NewDefaultWorkspaceConfig()
+	}
+
+	reg := agent.NewRegistry()
+	if err := registerStandardAgents(reg); err != nil {
+		return fmt.Errorf("register agents: %w", err)
+	}
+
+	validator := validation.New(res.Root, validation.Options{Strict: doctorStrict})
+	validator.SetBuiltInAgents(reg.List())
+
+	var repairs []validation.Repair
+	if doctorFix {
+		repairs, err = validator.Repair(ctx)
+		if err != nil {
+			return fmt.Errorf("repair: %w", err)
+		}
+		// Tokens and agents come from the config, which may have been restored
+		if cfg, err = ws.LoadConfig(); err != nil {
+			cfg = storage.NewDefaultWorkspaceConfig()
+		}
+	}
+
+	result, err := validator.Doctor(ctx, validation.DoctorOptions{
+		Agents:  reg,
+		Tokens:  doctorTokens(cmd, cfg),
+		Offline: doctorOffline,
+	})
+	if err != nil {
+		return fmt.Errorf("doctor: %w", err)
+	}
+
+	switch {
+	case doctorFormat == "json" && doctorFix:
+		if err := output.JSON(out, doctorFixDocument{Repairs: repairs, Result: result}); err != nil {
+			return err
+		}
+	case doctorFormat == "json":
+		_, _ = fmt.Fprintln(out, result.Format("json"))
+	default:
+		printDoctorRepairs(cmd, repairs)
+		printDoctorResult(cmd, result)
+	}
+
+	if !result.Valid {
+		return errors.New("doctor found problems")
+	}
+
+	return nil
+}
+
+// doctorFixDocument is the JSON document of 'mehr doctor --fix': the
+// repairs, followed by the findings of the checks that ran afterwards.
+type doctorFixDocument struct {
+	Repairs []validation.Repair `json:"repairs"`
+	*validation.Result
+}
+
+// doctorTokens returns the GitHub and GitLab tokens to check: those of the
+// default provider and of the providers configured in config.yaml.
+func doctorTokens(cmd *cobra.Command, cfg *storage.WorkspaceConfig) []validation.ProviderToken {
+	var tokens []validation.ProviderToken
+
+	if cfg.Providers.Default == "github" || cfg.GitHub != nil {
+		configToken := ""
+		if cfg.GitHub != nil {
+			configToken = cfg.GitHub.Token
+		}
+		token, _ := github.ResolveToken(configToken)
+		tokens = append(tokens, validation.ProviderToken{Provider: "github", Token: token, EnvVar: "GITHUB_TOKEN"})
+	}
+
+	if cfg.Providers.Default == "gitlab" || cfg.GitLab != nil {
+		configToken, host := "", ""
+		if cfg.GitLab != nil {
+			configToken, host = cfg.GitLab.Token, cfg.GitLab.Host
+		}
+		token, _ := gitlab.ResolveToken(cmd.Context(), configToken, host)
+		tokens = append(tokens, validation.ProviderToken{Provider: "gitlab", Host: host, Token: token, EnvVar: "GITLAB_TOKEN"})
+	}
+
+	return tokens
+}
+
+// printDoctorRepairs lists the repairs of --fix.
+func printDoctorRepairs(cmd *cobra.Command, repairs []validation.Repair) {
+	if !doctorFix {
+		return
+	}
+	out := cmd.OutOrStdout()
+	if len(repairs) == 0 {
+		_, _ = fmt.Fprintln(out, "Nothing to repair")
+	}
+	for _, r := range repairs {
+		_, _ = fmt.Fprintf(out, "%s %s %s\n", display.SuccessPrefix(), display.Bold("Fixed:"), r.Message)
+	}
+	_, _ = fmt.Fprintln(out)
+}
+
+// printDoctorResult prints one line per finding with the suggested fix
+// below problems, and a summary.
+func printDoctorResult(cmd *cobra.Command, result *validation.Result) {
+	out := cmd.OutOrStdout()
+
+	for _, f := range result.Findings {
+		var prefix string
+		switch f.Severity {
+		case validation.SeverityOK:
+			prefix = display.SuccessPrefix()
+		case validation.SeverityError:
+			prefix = display.ErrorPrefix()
+		case validation.SeverityWarning:
+			prefix = display.WarningPrefix()
+		case validation.SeverityInfo:
+			prefix = display.InfoPrefix()
+		}
+
+		// Config findings name the setting they are about
+		message := f.Message
+		if f.Path != "" && filepath.Base(f.File) == "config.yaml" {
+			message = f.Path + ": " + message
+		}
+		_, _ = fmt.Fprintf(out, "%s %s\n", prefix, message)
+		if f.Suggestion != "" {
+			_, _ = fmt.Fprintf(out, "    %s %s\n", display.Muted("Fix:"), f.Suggestion)
+		}
+	}
+
+	_, _ = fmt.Fprintln(out)
+	switch {
+	case result.Errors == 0 && result.Warnings == 0:
+		_, _ = fmt.Fprintln(out, display.Success("No problems found"))
+	case result.Valid:
+		_, _ = fmt.Fprintf(out, "%d warning(s)\n", result.Warnings)
+	default:
+		_, _ = fmt.Fprintf(out, "%d error(s), %d warning(s)\n", result.Errors, result.Warnings)
+	}
+}
@@ -0,0 +1,96 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/validation"
+)
+
+func TestDoctorCommand_Structure(t *testing.T) {
+	if doctorCmd.Use != "doctor" {
+		t.Errorf("Use = %q, want doctor", doctorCmd.Use)
+	}
+	for _, name := range []string{"fix", "offline", "strict", "format"} {
+		if doctorCmd.Flags().Lookup(name) == nil {
+			t.Errorf("missing --%s flag", name)
+		}
+	}
+	found := false
+	for _, cmd := range rootCmd.Commands() {
+		if cmd == doctorCmd {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("doctor command not registered")
+	}
+}
+
+func TestDoctorTokens(t *testing.T) {
+	t.Setenv("MEHR_GITHUB_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "ghp_env")
+	t.Setenv("MEHR_GITLAB_TOKEN", "")
+	t.Setenv("GITLAB_TOKEN", "glpat-env")
+
+	cfg := storage.NewDefaultWorkspaceConfig()
+	if tokens := doctorTokens(doctorCmd, cfg); len(tokens) != 0 {
+		t.Errorf("tokens without providers = %+v, want none", tokens)
+	}
+
+	cfg.Providers.Default = "github"
+	cfg.GitLab = &storage.GitLabSettings{Host: "https://gitlab.example.com"}
+	doctorCmd.SetContext(context.Background())
+	tokens := doctorTokens(doctorCmd, cfg)
+	want := []validation.ProviderToken{
+		{Provider: "github", Token: "ghp_env", EnvVar: "GITHUB_TOKEN"},
+		{Provider: "gitlab", Host: "https://gitlab.example.com", Token: "glpat-env", EnvVar: "GITLAB_TOKEN"},
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("tokens = %+v, want %+v", tokens, want)
+	}
+	for i := range want {
+		if tokens[i] != want[i] {
+			t.Errorf("tokens[%d] = %+v, want %+v", i, tokens[i], want[i])
+		}
+	}
+}
+
+func TestPrintDoctorResult(t *testing.T) {
+	result := validation.NewResult()
+	result.AddOK(validation.CodeGitOK, "git 2.43.0", "git")
+	result.AddWarningWithSuggestion(validation.CodeGitPatternEmpty, "Branch pattern is empty", "git.branch_pattern", "/repo/.mehrhof/config.yaml", "Set a pattern")
+	result.AddErrorWithSuggestion(validation.CodeActiveTaskOrphaned, "Active task abc has no work directory", ".active_task", "/repo/.active_task", "Remove it")
+
+	prev := display.ColorsEnabled()
+	display.SetColorsEnabled(false)
+	defer display.SetColorsEnabled(prev)
+
+	var buf bytes.Buffer
+	doctorCmd.SetOut(&buf)
+	defer doctorCmd.SetOut(nil)
+	printDoctorResult(doctorCmd, result)
+
+	output := buf.String()
+	for _, want := range []string{
+		"git 2.43.0",
+		"git.branch_pattern: Branch pattern is empty",
+		"Fix: Set a pattern",
+		"Active task abc has no work directory",
+		"1 error(s), 1 warning(s)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q\nGot: %s", want, output)
+		}
+	}
+	if strings.Contains(output, ".active_task: ") {
+		t.Errorf("non-config finding prefixed with its path\nGot: %s", output)
+	}
+}
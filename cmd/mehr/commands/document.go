@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/display"
+)
+
+var (
+	documentDryRun        bool
+	documentAgentDocument string
+)
+
+var documentCmd = &cobra.Command{
+	Use:     "document",
+	Aliases: []string{"docs"},
+	Short:   "Update documentation affected by the task's changes",
+	Long: `Run the documentation phase: the agent updates README files, docs and doc
+comments that the task's changes made outdated, and documents new behavior.
+
+The agent only gets to change documentation. Files matching workflow.doc_paths
+(default: docs/, doc/, README*, *.md, *.mdx, *.rst, *.adoc) are applied, and Go
+files only when nothing but comments changes. Any other change is dropped.
+
+A report listing the touched docs and why is saved as documentation-N.md in the
+task's work directory, and a checkpoint is created for the changes.
+
+Set workflow.document_after_implement in .mehrhof/config.yaml, or pass --docs to
+'mehr implement', to run this phase after every implementation.
+
+Examples:
+  mehr document                 # Update documentation for the task's changes
+  mehr document --dry-run       # Preview without making changes
+  mehr document --agent-document claude-opus`,
+	RunE: runDocument,
+}
+
+func init() {
+	rootCmd.AddCommand(documentCmd)
+
+	documentCmd.Flags().BoolVarP(&documentDryRun, "dry-run", "n", false, "Don't apply file changes (preview only)")
+	documentCmd.Flags().StringVar(&documentAgentDocument, "agent-document", "", "Agent for documentation step")
+}
+
+func runDocument(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	opts := []conductor.Option{
+		conductor.WithVerbose(verbose),
+		conductor.WithDryRun(documentDryRun),
+	}
+	if documentAgentDocument != "" {
+		opts = append(opts, conductor.WithStepAgent("documenting", documentAgentDocument))
+	}
+
+	cond, err := initializeConductor(ctx, opts...)
+	if err != nil {
+		return err
+	}
+
+	// Check for active task
+	if cond.GetActiveTask() == nil {
+		fmt.Print(display.NoActiveTaskError())
+
+		return errors.New("no active task")
+	}
+
+	if err := runDocumentationPhase(ctx, cond); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println(display.Muted("Next steps:"))
+	fmt.Printf("  %s - Run code review\n", display.Cyan("mehr review"))
+	fmt.Printf("  %s - Revert the documentation changes\n", display.Cyan("mehr undo"))
+	fmt.Printf("  %s - Complete the task\n", display.Cyan("mehr finish"))
+
+	return nil
+}
+
+// runDocumentationPhase enters and runs the documentation phase, then points
+// at the report it saved.
+func runDocumentationPhase(ctx context.Context, cond *conductor.Conductor) error {
+	if err := cond.Document(ctx); err != nil {
+		return fmt.Errorf("document: %w", err)
+	}
+
+	var docErr error
+	if verbose {
+		fmt.Println(display.InfoMsg("Updating documentation..."))
+		docErr = cond.RunDocumentation(ctx)
+	} else {
+		spinner := display.NewSpinner("Updating documentation...")
+		spinner.Start()
+		docErr = cond.RunDocumentation(ctx)
+		if docErr != nil {
+			spinner.StopWithError("Documentation update failed")
+		} else {
+			spinner.StopWithSuccess("Documentation updated")
+		}
+	}
+	if docErr != nil {
+		return fmt.Errorf("run documentation: %w", docErr)
+	}
+
+	ws := cond.GetWorkspace()
+	taskID := cond.GetActiveTask().ID
+	if numbers, err := ws.ListDocumentationReports(taskID); err == nil && len(numbers) > 0 {
+		fmt.Printf("  Report: %s\n", ws.DocumentationReportPath(taskID, numbers[len(numbers)-1]))
+	}
+
+	return nil
+}
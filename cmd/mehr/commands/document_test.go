@@ -0,0 +1,76 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDocumentCommand_Properties(t *testing.T) {
+	if documentCmd.Use != "document" {
+		t.Errorf("Use = %q, want %q", documentCmd.Use, "document")
+	}
+
+	if !slices.Contains(documentCmd.Aliases, "docs") {
+		t.Errorf("Aliases = %v, want docs", documentCmd.Aliases)
+	}
+
+	if documentCmd.Short == "" {
+		t.Error("Short description is empty")
+	}
+
+	if documentCmd.RunE == nil {
+		t.Error("RunE not set")
+	}
+}
+
+func TestDocumentCommand_Flags(t *testing.T) {
+	tests := []struct {
+		name         string
+		flagName     string
+		shorthand    string
+		defaultValue string
+	}{
+		{
+			name:         "dry-run flag",
+			flagName:     "dry-run",
+			shorthand:    "n",
+			defaultValue: "false",
+		},
+		{
+			name:         "agent-document flag",
+			flagName:     "agent-document",
+			shorthand:    "",
+			defaultValue: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flag := documentCmd.Flags().Lookup(tt.flagName)
+			if flag == nil {
+				t.Errorf("flag %q not found", tt.flagName)
+
+				return
+			}
+
+			if flag.DefValue != tt.defaultValue {
+				t.Errorf("flag %q default value = %q, want %q", tt.flagName, flag.DefValue, tt.defaultValue)
+			}
+
+			if tt.shorthand != "" && documentCmd.Flags().ShorthandLookup(tt.shorthand) == nil {
+				t.Errorf("shorthand %q not found for flag %q", tt.shorthand, tt.flagName)
+			}
+		})
+	}
+}
+
+func TestDocumentCommand_LongDescriptionContains(t *testing.T) {
+	for _, substr := range []string{"doc_paths", "documentation-N.md", "document_after_implement"} {
+		if !containsString(documentCmd.Long, substr) {
+			t.Errorf("Long description does not contain %q", substr)
+		}
+	}
+}
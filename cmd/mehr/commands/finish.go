@@ -3,15 +3,20 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/spf13/cobra"
 
 	"github.com/valksor/go-mehrhof/internal/conductor"
 	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/output"
+	"github.com/valksor/go-mehrhof/internal/workflow"
 )
 
 var (
 	finishYes           bool
+	finishJSON          bool
 	finishMerge         bool
 	finishDelete        bool
 	finishPush          bool
@@ -20,6 +25,9 @@ var (
 	finishSkipQuality   bool
 	finishQualityTarget string
 	finishDeleteWork    bool
+	finishSync          bool
+	finishChangelog     bool
+	finishSkipReview    bool
 	// PR-related flags.
 	finishDraftPR bool
 	finishPRTitle string
@@ -50,11 +58,22 @@ By default, this:
 - Keeps the task branch (use --delete to remove it)
 - Keeps the work directory (use --delete-work to remove it)
 - Does NOT push after local merge (use --push to enable)
+- Does NOT sync with the base branch first (use --sync, or set
+  git.sync_before_finish in config)
+- Does NOT write a changelog entry (use --changelog, or set
+  workflow.changelog.enabled in config)
+- Refuses to finish while the latest consensus review has blocking findings
+  (use --skip-review-gate to finish anyway)
 
 When using --merge, this performs a local merge instead of creating a PR:
 - Performs a squash merge to keep the history clean
 - Does NOT delete the task branch by default
 - Does NOT push to remote by default
+- When the merge conflicts, the merge is undone and the agent resolves the
+  conflicts on the task branch, as in 'mehr sync', before merging again
+
+Conflict resolutions are shown for confirmation before they are committed;
+--yes accepts them.
 
 If quality checks modify files (e.g., auto-formatting), you'll be prompted
 to confirm before proceeding.
@@ -80,7 +99,11 @@ Examples:
   mehr finish --quality-target lint # Use custom make target
   mehr finish --draft              # Create PR as draft
   mehr finish --pr-title "Fix bug" # Custom PR title
-  mehr finish --delete-work        # Delete work directory after finishing`,
+  mehr finish --delete-work        # Delete work directory after finishing
+  mehr finish --sync               # Rebase onto the base branch first
+  mehr finish --changelog          # Add a changelog entry to the merge or PR
+  mehr finish --skip-review-gate   # Finish despite blocking review findings
+  mehr finish --yes --json         # Print the result as JSON`,
 	RunE: runFinish,
 }
 
@@ -96,6 +119,10 @@ func init() {
 	finishCmd.Flags().BoolVar(&finishSkipQuality, "skip-quality", false, "Skip quality checks (make quality)")
 	finishCmd.Flags().StringVar(&finishQualityTarget, "quality-target", "quality", "Make target for quality checks")
 	finishCmd.Flags().BoolVar(&finishDeleteWork, "delete-work", false, "Delete work directory after finishing")
+	finishCmd.Flags().BoolVar(&finishJSON, "json", false, "Output the result as JSON (requires --yes)")
+	finishCmd.Flags().BoolVar(&finishSync, "sync", false, "Sync with the base branch before quality checks (default: git.sync_before_finish)")
+	finishCmd.Flags().BoolVar(&finishChangelog, "changelog", false, "Write a changelog entry before merging (default: workflow.changelog.enabled)")
+	finishCmd.Flags().BoolVar(&finishSkipReview, "skip-review-gate", false, "Finish despite blocking findings of the latest consensus review")
 
 	// PR-related flags
 	finishCmd.Flags().BoolVar(&finishDraftPR, "draft", false, "Create PR as draft")
@@ -111,8 +138,20 @@ func init() {
 func runFinish(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 
+	// There is no one to answer prompts in JSON mode
+	var jsonOut io.Writer
+	if finishJSON {
+		if !finishYes {
+			return output.WithCode(errors.New("--json requires --yes"), output.CodeUsage, output.ExitUsage)
+		}
+		jsonOut = redirectStdout()
+	}
+
 	// Initialize conductor with standard providers and agents
-	cond, err := initializeConductor(ctx, conductor.WithVerbose(verbose))
+	cond, err := initializeConductor(ctx,
+		conductor.WithVerbose(verbose),
+		conductor.WithConflictResolutionCallback(conflictResolutionPrompt(cmd, finishYes)),
+	)
 	if err != nil {
 		return err
 	}
@@ -122,7 +161,7 @@ func runFinish(cmd *cobra.Command, args []string) error {
 	if activeTask == nil {
 		fmt.Print(display.NoActiveTaskError())
 
-		return errors.New("no active task")
+		return errNoActiveTask
 	}
 
 	// Get status for display
@@ -170,10 +209,24 @@ func runFinish(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Sync first, so quality checks run against the synced code
+	syncFirst := cond.SyncBeforeFinish()
+	if cmd.Flags().Changed("sync") {
+		syncFirst = finishSync
+	}
+	if syncFirst && status.Branch != "" {
+		result, err := cond.Sync(ctx, conductor.SyncOptions{ResolveConflicts: true})
+		if err != nil {
+			return fmt.Errorf("sync: %w", err)
+		}
+		printSyncResult(result)
+	}
+
 	// Run quality checks (unless skipped)
 	if !finishSkipQuality {
 		qualityOpts := conductor.QualityOptions{
-			Target: finishQualityTarget,
+			Target:     finishQualityTarget,
+			SkipPrompt: finishJSON,
 		}
 
 		result, err := cond.RunQuality(ctx, qualityOpts)
@@ -200,6 +253,10 @@ func runFinish(cmd *cobra.Command, args []string) error {
 	if cmd.Flags().Changed("delete-work") {
 		deleteWork = conductor.BoolPtr(finishDeleteWork)
 	}
+	var writeChangelog *bool
+	if cmd.Flags().Changed("changelog") {
+		writeChangelog = conductor.BoolPtr(finishChangelog)
+	}
 
 	opts := conductor.FinishOptions{
 		SquashMerge:  !finishNoSquash,
@@ -207,6 +264,10 @@ func runFinish(cmd *cobra.Command, args []string) error {
 		TargetBranch: finishTargetBranch,
 		PushAfter:    finishPush,
 		DeleteWork:   deleteWork,
+		Sync:         conductor.BoolPtr(false), // Synced above
+		Changelog:    writeChangelog,
+		// Review gate
+		SkipReviewGate: finishSkipReview,
 		// PR options
 		ForceMerge: finishMerge,
 		DraftPR:    finishDraftPR,
@@ -214,11 +275,28 @@ func runFinish(cmd *cobra.Command, args []string) error {
 		PRBody:     finishPRBody,
 	}
 
-	// Perform finish
-	if err := cond.Finish(ctx, opts); err != nil {
+	// Perform finish, noting the pull request it opens
+	var prURL string
+	prSub := cond.GetEventBus().Subscribe(events.TypePRCreated, func(e events.Event) {
+		if url, ok := e.Data["pr_url"].(string); ok && prURL == "" {
+			prURL = url
+		}
+	})
+	err = cond.Finish(ctx, opts)
+	cond.GetEventBus().Unsubscribe(prSub)
+	if err != nil {
 		return fmt.Errorf("finish: %w", err)
 	}
 
+	if jsonOut != nil {
+		result := newJSONTaskResult("finish", status)
+		result.State = string(workflow.StateDone)
+		result.PullRequestURL = prURL
+		result.Merged = finishMerge
+
+		return output.JSON(jsonOut, result)
+	}
+
 	// Success message depends on what happened
 	if finishMerge {
 		fmt.Println(display.SuccessMsg("Task completed and merged"))
@@ -104,6 +104,24 @@ func TestFinishCommand_Flags(t *testing.T) {
 			shorthand:    "",
 			defaultValue: "",
 		},
+		{
+			name:         "sync flag",
+			flagName:     "sync",
+			shorthand:    "",
+			defaultValue: "false",
+		},
+		{
+			name:         "changelog flag",
+			flagName:     "changelog",
+			shorthand:    "",
+			defaultValue: "false",
+		},
+		{
+			name:         "skip-review-gate flag",
+			flagName:     "skip-review-gate",
+			shorthand:    "",
+			defaultValue: "false",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,249 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/registry"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var globalCmd = &cobra.Command{
+	Use:   "global",
+	Short: "Work with tasks across all repositories",
+	Long: `Work with tasks across every repository on this machine.
+
+Each time mehr runs in a workspace that has tasks, the workspace is recorded
+in registry.yaml under the user config directory (~/.config/mehrhof on
+Linux), so half-finished tasks in other projects are not forgotten.`,
+}
+
+var globalStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List in-flight tasks in all registered repositories",
+	Long: `List the tasks that are not finished in every registered repository,
+with their state and branch. The active task of each repository is marked
+with '*'.
+
+A task counts as finished once 'mehr finish' completed it. Repositories that
+no longer exist are reported; --prune removes them from the registry.`,
+	Example: `  mehr global status
+  mehr global status --all
+  mehr global status --prune
+  mehr global status --json`,
+	Args: cobra.NoArgs,
+	RunE: runGlobalStatus,
+}
+
+var (
+	globalStatusAll   bool
+	globalStatusPrune bool
+	globalStatusJSON  bool
+)
+
+func init() {
+	rootCmd.AddCommand(globalCmd)
+	globalCmd.AddCommand(globalStatusCmd)
+
+	globalStatusCmd.Flags().BoolVar(&globalStatusAll, "all", false, "Include finished tasks")
+	globalStatusCmd.Flags().BoolVar(&globalStatusPrune, "prune", false, "Remove repositories that no longer exist from the registry")
+	globalStatusCmd.Flags().BoolVar(&globalStatusJSON, "json", false, "Output as JSON")
+}
+
+// globalTask is one task listed by 'mehr global status'.
+type globalTask struct {
+	Repository string `json:"repository"`
+	TaskID     string `json:"task_id"`
+	Title      string `json:"title,omitempty"`
+	State      string `json:"state"`
+	Branch     string `json:"branch,omitempty"`
+	Active     bool   `json:"active"`
+}
+
+func runGlobalStatus(cmd *cobra.Command, _ []string) error {
+	reg, err := registry.Load()
+	if err != nil {
+		return err
+	}
+
+	tasks := []globalTask{}
+	var missing []string
+	for _, entry := range reg.Workspaces {
+		if _, err := os.Stat(entry.Root); os.IsNotExist(err) {
+			missing = append(missing, entry.Root)
+
+			continue
+		}
+		repoTasks, err := workspaceTasks(entry.Root)
+		if err != nil {
+			slog.Warn("skip repository", "root", entry.Root, "error", err)
+
+			continue
+		}
+		for _, task := range repoTasks {
+			if globalStatusAll || task.State != "done" {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+
+	if globalStatusPrune && len(missing) > 0 {
+		if err := registry.Forget(missing...); err != nil {
+			return fmt.Errorf("prune registry: %w", err)
+		}
+	}
+
+	if globalStatusJSON {
+		return outputJSON(tasks)
+	}
+
+	out := cmd.OutOrStdout()
+	if len(tasks) == 0 {
+		_, _ = fmt.Fprintln(out, "No in-flight tasks.")
+	} else {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "REPOSITORY\tTASK ID\tSTATE\tBRANCH\tTITLE")
+		for _, task := range tasks {
+			id := task.TaskID
+			if task.Active {
+				id += "*"
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", shortenHome(task.Repository), id, task.State, task.Branch, task.Title)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	for _, root := range missing {
+		if globalStatusPrune {
+			_, _ = fmt.Fprintf(out, "Removed missing repository %s\n", shortenHome(root))
+		} else {
+			_, _ = fmt.Fprintf(out, "Missing repository %s (remove with --prune)\n", shortenHome(root))
+		}
+	}
+
+	return nil
+}
+
+// workspaceTasks returns every task of the workspace at root with its state.
+func workspaceTasks(root string) ([]globalTask, error) {
+	ws, err := openConfiguredWorkspace(root)
+	if err != nil {
+		return nil, err
+	}
+
+	works, err := ws.LoadWorks()
+	if err != nil {
+		return nil, err
+	}
+
+	var active *storage.ActiveTask
+	if ws.HasActiveTask() {
+		if active, err = ws.LoadActiveTask(); err != nil {
+			slog.Debug("load active task", "root", root, "error", err)
+		}
+	}
+
+	tasks := make([]globalTask, 0, len(works))
+	for _, work := range works {
+		task := globalTask{
+			Repository: root,
+			TaskID:     work.Metadata.ID,
+			Title:      work.Metadata.Title,
+			State:      lastTaskState(ws, work.Metadata.ID),
+			Branch:     work.Git.Branch,
+		}
+		if active != nil && active.ID == task.TaskID {
+			task.Active = true
+			task.State = active.State
+			if active.Branch != "" {
+				task.Branch = active.Branch
+			}
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// lastTaskState returns the state a task's event log last recorded: "done"
+// once the task finished, "idle" without any state change.
+func lastTaskState(ws *storage.Workspace, taskID string) string {
+	records, err := ws.ReadEvents(taskID, storage.EventFilter{
+		Types: []string{string(events.TypeStateChanged), string(events.TypeTaskFinished)},
+		Limit: 1,
+	})
+	if err != nil || len(records) == 0 {
+		return "idle"
+	}
+	if records[0].Type == string(events.TypeTaskFinished) {
+		return "done"
+	}
+	if to, ok := records[0].Data["to"].(string); ok && to != "" {
+		return to
+	}
+
+	return "idle"
+}
+
+// openConfiguredWorkspace opens the workspace at root with its own config,
+// so a custom work directory is honoured.
+func openConfiguredWorkspace(root string) (*storage.Workspace, error) {
+	ws, err := storage.OpenWorkspace(root, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open workspace: %w", err)
+	}
+	if !ws.HasConfig() {
+		return ws, nil
+	}
+	cfg, err := ws.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	return storage.OpenWorkspace(root, cfg)
+}
+
+// recordWorkspace registers the current workspace in the user registry when
+// it has tasks. Failures are logged only: the registry is a convenience.
+func recordWorkspace(ctx context.Context) {
+	res, err := ResolveWorkspaceRoot(ctx)
+	if err != nil {
+		return
+	}
+	ws, err := openConfiguredWorkspace(res.Root)
+	if err != nil {
+		return
+	}
+	if ids, err := ws.ListWorks(); err != nil || len(ids) == 0 {
+		return
+	}
+	if err := registry.Record(ws.Root()); err != nil {
+		slog.Debug("record workspace in registry", "root", ws.Root(), "error", err)
+	}
+}
+
+// shortenHome replaces the user's home directory prefix of path with '~'.
+func shortenHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if rest, ok := strings.CutPrefix(path, home+string(filepath.Separator)); ok {
+		return "~" + string(filepath.Separator) + rest
+	}
+
+	return path
+}
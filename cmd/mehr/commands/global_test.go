@@ -0,0 +1,64 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestGlobalStatusCommand_Properties(t *testing.T) {
+	if globalStatusCmd.Parent() != globalCmd {
+		t.Error("status is not a subcommand of global")
+	}
+	if globalStatusCmd.RunE == nil {
+		t.Error("RunE not set")
+	}
+	for _, name := range []string{"all", "prune", "json"} {
+		flag := globalStatusCmd.Flags().Lookup(name)
+		if flag == nil {
+			t.Fatalf("flag %q not found", name)
+		}
+		if flag.DefValue != "false" {
+			t.Errorf("flag %q default value = %q, want %q", name, flag.DefValue, "false")
+		}
+	}
+}
+
+func TestLastTaskState(t *testing.T) {
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.CreateWork("t1", storage.SourceInfo{Type: "file"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := lastTaskState(ws, "t1"); got != "idle" {
+		t.Errorf("state without events = %q, want idle", got)
+	}
+
+	appendEvent := func(typ string, data map[string]any) {
+		t.Helper()
+		if err := ws.AppendEvent("t1", storage.EventRecord{Timestamp: time.Now(), Type: typ, Data: data}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	appendEvent("state_changed", map[string]any{"from": "idle", "to": "planning"})
+	appendEvent("file_changed", map[string]any{"path": "a.go"})
+	appendEvent("state_changed", map[string]any{"from": "planning", "to": "implementing"})
+	if got := lastTaskState(ws, "t1"); got != "implementing" {
+		t.Errorf("state = %q, want implementing", got)
+	}
+
+	appendEvent("task_finished", nil)
+	if got := lastTaskState(ws, "t1"); got != "done" {
+		t.Errorf("state after finish = %q, want done", got)
+	}
+}
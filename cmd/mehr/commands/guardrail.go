@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var (
+	guardrailTask   string
+	guardrailReason string
+)
+
+var guardrailCmd = &cobra.Command{
+	Use:   "guardrail",
+	Short: "Manage suppressed guardrail findings",
+	Long: `Manage the guardrail findings suppressed for a task.
+
+Checkpoints and pushes are blocked when the changes contain credentials,
+high-entropy strings or oversized files. Each finding in the report ends
+with an ID in brackets, e.g. [3f9a1c2b7d4e]. When a finding is a false
+positive, such as a test fixture, suppress it by that ID. Suppressions are
+recorded in the task's work directory and apply to that task only. IDs
+depend on the file and the value found, not the line, so they survive edits
+around the finding.`,
+}
+
+var guardrailSuppressCmd = &cobra.Command{
+	Use:   "suppress <id>...",
+	Short: "Suppress findings by ID",
+	Example: `  mehr guardrail suppress 3f9a1c2b7d4e --reason "fake key in a test fixture"
+  mehr guardrail suppress 3f9a1c2b7d4e 8b0e44a1c9f2`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runGuardrailSuppress,
+}
+
+var guardrailUnsuppressCmd = &cobra.Command{
+	Use:     "unsuppress <id>...",
+	Short:   "Remove suppressions",
+	Example: `  mehr guardrail unsuppress 3f9a1c2b7d4e`,
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    runGuardrailUnsuppress,
+}
+
+var guardrailListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List suppressed findings",
+	Args:  cobra.NoArgs,
+	RunE:  runGuardrailList,
+}
+
+func init() {
+	rootCmd.AddCommand(guardrailCmd)
+	guardrailCmd.AddCommand(guardrailSuppressCmd)
+	guardrailCmd.AddCommand(guardrailUnsuppressCmd)
+	guardrailCmd.AddCommand(guardrailListCmd)
+
+	guardrailCmd.PersistentFlags().StringVar(&guardrailTask, "task", "", "Task ID (default: active task)")
+	guardrailSuppressCmd.Flags().StringVar(&guardrailReason, "reason", "", "Why the findings are false positives")
+}
+
+func runGuardrailSuppress(cmd *cobra.Command, args []string) error {
+	ws, taskID, err := openGuardrailWorkspace(cmd)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range args {
+		id = strings.Trim(id, "[]")
+		if err := ws.AddSuppression(taskID, storage.Suppression{ID: id, Reason: guardrailReason}); err != nil {
+			return fmt.Errorf("suppress %s: %w", id, err)
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), display.SuccessMsg("Suppressed %s", id))
+	}
+
+	return nil
+}
+
+func runGuardrailUnsuppress(cmd *cobra.Command, args []string) error {
+	ws, taskID, err := openGuardrailWorkspace(cmd)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range args {
+		id = strings.Trim(id, "[]")
+		removed, err := ws.RemoveSuppression(taskID, id)
+		if err != nil {
+			return fmt.Errorf("unsuppress %s: %w", id, err)
+		}
+		if !removed {
+			return fmt.Errorf("no suppression %s for task %s", id, taskID)
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), display.SuccessMsg("Removed suppression %s", id))
+	}
+
+	return nil
+}
+
+func runGuardrailList(cmd *cobra.Command, args []string) error {
+	ws, taskID, err := openGuardrailWorkspace(cmd)
+	if err != nil {
+		return err
+	}
+
+	suppressions, err := ws.LoadSuppressions(taskID)
+	if err != nil {
+		return err
+	}
+	writeSuppressions(cmd.OutOrStdout(), taskID, suppressions)
+
+	return nil
+}
+
+// writeSuppressions prints a task's suppressed findings.
+func writeSuppressions(out io.Writer, taskID string, suppressions []storage.Suppression) {
+	if len(suppressions) == 0 {
+		_, _ = fmt.Fprintf(out, "No suppressed findings for task %s.\n", taskID)
+
+		return
+	}
+
+	for _, s := range suppressions {
+		line := fmt.Sprintf("%s  %s", s.ID, display.Muted(s.CreatedAt.Format("2006-01-02 15:04")))
+		if s.Reason != "" {
+			line += "  " + s.Reason
+		}
+		_, _ = fmt.Fprintln(out, line)
+	}
+}
+
+// openGuardrailWorkspace opens the workspace and resolves the task whose
+// suppressions are managed.
+func openGuardrailWorkspace(cmd *cobra.Command) (*storage.Workspace, string, error) {
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return nil, "", err
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("open workspace: %w", err)
+	}
+
+	var args []string
+	if guardrailTask != "" {
+		args = []string{guardrailTask}
+	}
+	taskID, err := resolveTaskIDArg(ws, args)
+	if err != nil {
+		return nil, "", err
+	}
+	if !ws.WorkExists(taskID) {
+		return nil, "", fmt.Errorf("task not found: %s", taskID)
+	}
+
+	return ws, taskID, nil
+}
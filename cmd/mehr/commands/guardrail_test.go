@@ -0,0 +1,52 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestGuardrailCommand_Structure(t *testing.T) {
+	want := map[string]bool{"suppress": false, "unsuppress": false, "list": false}
+	for _, sub := range guardrailCmd.Commands() {
+		if _, ok := want[sub.Name()]; ok {
+			want[sub.Name()] = true
+		}
+	}
+	for name, found := range want {
+		if !found {
+			t.Errorf("guardrail subcommand %q not registered", name)
+		}
+	}
+
+	if guardrailSuppressCmd.Flags().Lookup("reason") == nil {
+		t.Error("suppress has no --reason flag")
+	}
+	if guardrailCmd.PersistentFlags().Lookup("task") == nil {
+		t.Error("guardrail has no --task flag")
+	}
+}
+
+func TestWriteSuppressions(t *testing.T) {
+	var buf bytes.Buffer
+	writeSuppressions(&buf, "abc", nil)
+	if !strings.Contains(buf.String(), "No suppressed findings for task abc") {
+		t.Errorf("output = %q", buf.String())
+	}
+
+	buf.Reset()
+	writeSuppressions(&buf, "abc", []storage.Suppression{
+		{ID: "3f9a1c2b7d4e", Reason: "test fixture", CreatedAt: time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)},
+	})
+	for _, want := range []string{"3f9a1c2b7d4e", "2025-01-15 10:30", "test fixture"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q: %q", want, buf.String())
+		}
+	}
+}
@@ -182,6 +182,10 @@ func runGuide(cmd *cobra.Command, args []string) error {
 		fmt.Println("  mehr finish                # Complete and merge")
 		fmt.Println("  mehr implement              # Make more changes")
 
+	case workflow.StateDocumenting:
+		fmt.Println("  mehr status                # View documentation progress")
+		fmt.Println("  mehr review                # Review the changes")
+
 	case workflow.StateDone:
 		fmt.Println("  Task is complete!")
 		fmt.Println("  mehr start <reference>    # Start a new task")
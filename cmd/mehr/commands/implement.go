@@ -1,21 +1,33 @@
 package commands
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/valksor/go-mehrhof/internal/conductor"
 	"github.com/valksor/go-mehrhof/internal/display"
 	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/output"
 )
 
 var (
 	implementDryRun            bool
 	implementAgentImplementing string
+	implementAllowOutsideScope bool
+	implementAllowProtected    bool
+	implementWatch             bool
+	implementSpec              int
+	implementParallel          int
+	implementDocs              bool
+	implementContext           string
+	implementJSON              bool
 )
 
 var implementCmd = &cobra.Command{
@@ -24,15 +36,35 @@ var implementCmd = &cobra.Command{
 	Short:   "Implement the specifications for the active task",
 	Long: `Run the implementation phase to generate code based on specifications.
 
-The agent will read all specification files in the work directory along with any
-notes, then implement them by creating or modifying files.
+The agent reads the latest specification along with any notes, then implements
+it by creating or modifying files.
+
+Use --spec to implement a single specification. Its status moves from ready to
+implementing to done, the session is linked to it and a checkpoint is created
+for its changes. An interrupted run leaves the spec implementing; running the
+same command again resumes it.
+
+Use --parallel N to implement the remaining specifications concurrently, N
+agents at a time. Each spec runs in its own git worktree on a branch from the
+task branch; the branches are then squash-merged back one by one, each into its
+own checkpoint. A spec whose branch conflicts with the others keeps its branch
+and worktree so the conflict can be resolved by hand.
 
 Requires at least one specification file to exist (run 'mehr plan' first).
 
 Examples:
   mehr implement                # Implement the specifications
   mehr implement --dry-run      # Preview without making changes
-  mehr implement --verbose      # Show agent output`,
+  mehr implement --verbose      # Show agent output
+  mehr implement --spec 3       # Implement only specification-3
+  mehr implement --parallel 2   # Implement specs two at a time in worktrees
+  mehr implement --watch        # Pause when you edit files the agent is touching
+  mehr implement --docs         # Update documentation afterwards (mehr document)
+  mehr implement --json         # Print the result as JSON
+
+With --watch, editing a file the agent is working on pauses the run at the next
+safe point (between agent events, and before agent changes are applied) until
+you confirm it should resume. This avoids tug-of-war edits when pairing.`,
 	RunE: runImplement,
 }
 
@@ -41,15 +73,62 @@ func init() {
 
 	implementCmd.Flags().BoolVarP(&implementDryRun, "dry-run", "n", false, "Don't apply file changes (preview only)")
 	implementCmd.Flags().StringVar(&implementAgentImplementing, "agent-implement", "", "Agent for implementation step")
+	implementCmd.Flags().BoolVar(&implementAllowOutsideScope, "allow-outside-scope", false, "Permit file changes outside the task scope")
+	implementCmd.Flags().BoolVar(&implementAllowProtected, "allow-protected", false, "Permit changes to guardrails.protected paths")
+	implementCmd.Flags().IntVar(&implementSpec, "spec", 0, "Implement only this specification number")
+	implementCmd.Flags().IntVar(&implementParallel, "parallel", 0, "Implement specifications concurrently in worktrees, N at a time")
+	implementCmd.Flags().BoolVar(&implementWatch, "watch", false, "Pause when you edit files the agent is touching")
+	implementCmd.Flags().StringVar(&implementContext, "context", "", contextFlagUsage)
+	implementCmd.Flags().BoolVar(&implementJSON, "json", false, "Output the result as JSON")
+	implementCmd.Flags().BoolVar(&implementDocs, "docs", false, "Update documentation after implementing (default: workflow.document_after_implement)")
 }
 
 func runImplement(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 
+	var jsonOut io.Writer
+	if implementJSON {
+		jsonOut = redirectStdout()
+	}
+
+	if implementSpec < 0 {
+		return fmt.Errorf("invalid --spec %d: specification numbers start at 1", implementSpec)
+	}
+	if implementParallel < 0 {
+		return fmt.Errorf("invalid --parallel %d: must be at least 1", implementParallel)
+	}
+	if implementParallel > 0 && (implementSpec > 0 || implementDryRun || implementWatch) {
+		return errors.New("--parallel cannot be combined with --spec, --dry-run or --watch")
+	}
+	contextMode, err := parseContextFlag(implementContext)
+	if err != nil {
+		return err
+	}
+
 	// Build conductor options
 	opts := []conductor.Option{
 		conductor.WithVerbose(verbose),
 		conductor.WithDryRun(implementDryRun),
+		conductor.WithAllowOutsideScope(implementAllowOutsideScope),
+		conductor.WithAllowProtected(implementAllowProtected),
+		conductor.WithSpecification(implementSpec),
+		conductor.WithContextMode(contextMode),
+	}
+
+	// Pause on manual edits; the spinner is stopped while asking
+	var spinner *display.Spinner
+	if implementWatch {
+		opts = append(opts,
+			conductor.WithWatchEdits(true),
+			conductor.WithEditConflictCallback(func(files []string) bool {
+				if spinner != nil {
+					spinner.Stop()
+					defer spinner.Start()
+				}
+
+				return confirmResumeAfterEdits(cmd.InOrStdin(), cmd.OutOrStdout(), files)
+			}),
+		)
 	}
 
 	// Per-step agent override
@@ -72,7 +151,7 @@ func runImplement(cmd *cobra.Command, args []string) error {
 	if cond.GetActiveTask() == nil {
 		fmt.Print(display.NoActiveTaskError())
 
-		return errors.New("no active task")
+		return errNoActiveTask
 	}
 
 	// Set up event handlers
@@ -116,8 +195,26 @@ func runImplement(cmd *cobra.Command, args []string) error {
 	// Run implementation with spinner in non-verbose mode
 	var implErr error
 	spinnerMsg := "Implementing code..."
+	if implementSpec > 0 {
+		spinnerMsg = fmt.Sprintf("Implementing specification-%d...", implementSpec)
+	}
+	if implementParallel > 0 {
+		spinnerMsg = fmt.Sprintf("Implementing specifications, %d at a time...", implementParallel)
+	}
 	if implementDryRun {
-		spinnerMsg = "Implementing code (dry-run)..."
+		spinnerMsg = strings.TrimSuffix(spinnerMsg, "...") + " (dry-run)..."
+	}
+
+	var specResults []conductor.SpecResult
+	run := func() error {
+		if implementParallel > 0 {
+			var err error
+			specResults, err = cond.RunParallelImplementation(ctx, implementParallel)
+
+			return err
+		}
+
+		return cond.RunImplementation(ctx)
 	}
 
 	if verbose {
@@ -126,14 +223,17 @@ func runImplement(cmd *cobra.Command, args []string) error {
 		} else {
 			fmt.Println(display.InfoMsg("Implementing..."))
 		}
-		implErr = cond.RunImplementation(ctx)
+		implErr = run()
 	} else {
-		spinner := display.NewSpinner(spinnerMsg)
+		spinner = display.NewSpinner(spinnerMsg)
 		spinner.Start()
-		implErr = cond.RunImplementation(ctx)
-		if implErr != nil {
+		implErr = run()
+		switch {
+		case errors.Is(implErr, conductor.ErrCanceled):
+			spinner.StopWithError("Implementation interrupted")
+		case implErr != nil:
 			spinner.StopWithError("Implementation failed")
-		} else {
+		default:
 			if implementDryRun {
 				spinner.StopWithSuccess("Implementation preview complete")
 			} else {
@@ -141,16 +241,34 @@ func runImplement(cmd *cobra.Command, args []string) error {
 			}
 		}
 	}
+	printSpecResults(specResults, implErr)
+	if errors.Is(implErr, conductor.ErrCanceled) {
+		fmt.Println(display.WarningMsg("Changes since the last checkpoint were rolled back; run 'mehr implement' to resume"))
+	}
 	if implErr != nil {
 		return fmt.Errorf("run implementation: %w", implErr)
 	}
 
+	// Optional documentation phase
+	if implementDocs || cond.DocumentAfterImplement() {
+		if err := runDocumentationPhase(ctx, cond); err != nil {
+			return err
+		}
+	}
+
 	// Get status
 	status, err := cond.Status()
 	if err != nil {
 		return err
 	}
 
+	if jsonOut != nil {
+		result := newJSONTaskResult("implement", status)
+		result.DryRun = implementDryRun
+
+		return output.JSON(jsonOut, result)
+	}
+
 	if verbose {
 		fmt.Println()
 		if implementDryRun {
@@ -173,3 +291,44 @@ func runImplement(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// printSpecResults reports how each specification of a --parallel run merged.
+func printSpecResults(results []conductor.SpecResult, err error) {
+	for _, r := range results {
+		switch {
+		case r.Merged():
+			fmt.Printf("  specification-%d: merged (checkpoint #%d)\n", r.Number, r.Checkpoint)
+		case len(r.Conflicts) > 0:
+			fmt.Printf("  specification-%d: conflicts in %s\n", r.Number, strings.Join(r.Conflicts, ", "))
+			fmt.Printf("    kept branch %s in %s\n", r.Branch, r.Worktree)
+		default:
+			fmt.Printf("  specification-%d: failed: %v\n", r.Number, r.Err)
+		}
+	}
+	if errors.Is(err, conductor.ErrSpecConflict) {
+		fmt.Println(display.WarningMsg("Merge the kept branches by hand, or re-run 'mehr implement --spec N' for them"))
+	}
+}
+
+// confirmResumeAfterEdits asks whether to resume an implementation run paused
+// because files the agent is working on were edited.
+func confirmResumeAfterEdits(in io.Reader, out io.Writer, files []string) bool {
+	_, _ = fmt.Fprintln(out)
+	_, _ = fmt.Fprintln(out, display.WarningMsg("Paused: you edited files the agent is working on:"))
+	for _, f := range files {
+		_, _ = fmt.Fprintf(out, "  %s\n", f)
+	}
+	_, _ = fmt.Fprint(out, "Press Enter to resume, or type 'abort' to stop: ")
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "abort", "a", "q":
+		return false
+	}
+
+	return true
+}
@@ -4,6 +4,8 @@
 package commands
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 )
 
@@ -46,6 +48,36 @@ func TestImplementCommand_Flags(t *testing.T) {
 			shorthand:    "",
 			defaultValue: "",
 		},
+		{
+			name:         "allow-outside-scope flag",
+			flagName:     "allow-outside-scope",
+			shorthand:    "",
+			defaultValue: "false",
+		},
+		{
+			name:         "allow-protected flag",
+			flagName:     "allow-protected",
+			shorthand:    "",
+			defaultValue: "false",
+		},
+		{
+			name:         "spec flag",
+			flagName:     "spec",
+			shorthand:    "",
+			defaultValue: "0",
+		},
+		{
+			name:         "watch flag",
+			flagName:     "watch",
+			shorthand:    "",
+			defaultValue: "false",
+		},
+		{
+			name:         "docs flag",
+			flagName:     "docs",
+			shorthand:    "",
+			defaultValue: "false",
+		},
 	}
 
 	for _, tt := range tests {
@@ -151,3 +183,30 @@ func TestImplementCommand_DryRunHasShorthand(t *testing.T) {
 		t.Errorf("dry-run flag shorthand = %q, want 'n'", flag.Shorthand)
 	}
 }
+
+func TestConfirmResumeAfterEdits(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{name: "enter resumes", input: "\n", want: true},
+		{name: "yes resumes", input: "y\n", want: true},
+		{name: "abort", input: "abort\n", want: false},
+		{name: "short abort", input: "A\n", want: false},
+		{name: "no input aborts", input: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got := confirmResumeAfterEdits(strings.NewReader(tt.input), &out, []string{"main.go"})
+			if got != tt.want {
+				t.Errorf("confirmResumeAfterEdits() = %v, want %v", got, tt.want)
+			}
+			if !strings.Contains(out.String(), "main.go") {
+				t.Errorf("edited file not listed: %q", out.String())
+			}
+		})
+	}
+}
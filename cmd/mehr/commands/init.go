@@ -1,29 +1,39 @@
 package commands
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/valksor/go-mehrhof/internal/storage"
 	"github.com/valksor/go-mehrhof/internal/vcs"
 )
 
-var initInteractive bool
+var (
+	initInteractive bool
+	initYes         bool
+)
 
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize the task workspace",
 	Long: `Initialize the task workspace by creating the .mehrhof directory
-and updating .gitignore.`,
+and updating .gitignore.
+
+When a new config file is created from a terminal, a setup wizard detects
+the git host from the origin remote, offers to configure the matching
+provider, tests its token, and picks the default agent from the agent CLIs
+installed on this machine. The config is validated before it is written.
+Use --yes to write the defaults without questions, or --interactive to run
+the wizard on an existing config.`,
 	RunE: runInit,
 }
 
 func init() {
-	initCmd.Flags().BoolVarP(&initInteractive, "interactive", "i", false, "Interactive setup for API key, provider, and agent")
+	initCmd.Flags().BoolVarP(&initInteractive, "interactive", "i", false, "Run the setup wizard, also when a config file exists")
+	initCmd.Flags().BoolVarP(&initYes, "yes", "y", false, "Write the default config without asking questions")
 	rootCmd.AddCommand(initCmd)
 }
 
@@ -32,6 +42,10 @@ func runInit(cmd *cobra.Command, args []string) error {
 	out := cmd.OutOrStdout()
 	errOut := cmd.ErrOrStderr()
 
+	if initInteractive && initYes {
+		return errors.New("--interactive and --yes cannot be used together")
+	}
+
 	// Try to find git root, fall back to current directory
 	workDir, err := os.Getwd()
 	if err != nil {
@@ -42,6 +56,8 @@ func runInit(cmd *cobra.Command, args []string) error {
 	root := workDir
 	if err == nil {
 		root = git.Root()
+	} else {
+		git = nil
 	}
 
 	ws, err := storage.OpenWorkspace(root, nil)
@@ -58,17 +74,6 @@ func runInit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("update .gitignore: %w", err)
 	}
 
-	// Create config file with defaults if it doesn't exist
-	if !ws.HasConfig() {
-		cfg := storage.NewDefaultWorkspaceConfig()
-		if err := ws.SaveConfig(cfg); err != nil {
-			return fmt.Errorf("create config file: %w", err)
-		}
-		_, _ = fmt.Fprintf(out, "Created config file: %s\n", ws.ConfigPath())
-	} else {
-		_, _ = fmt.Fprintf(out, "Config file already exists: %s\n", ws.ConfigPath())
-	}
-
 	// Create .env template if it doesn't exist
 	envPath := filepath.Join(ws.TaskRoot(), ".env")
 	if _, err := os.Stat(envPath); os.IsNotExist(err) {
@@ -79,11 +84,23 @@ func runInit(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Run interactive setup if requested
-	if initInteractive {
-		if err := interactiveSetup(cmd, ws, envPath); err != nil {
-			_, _ = fmt.Fprintf(errOut, "warning: interactive setup failed: %v\n", err)
+	// Ask for the config on a terminal, unless --yes
+	wizard := initInteractive || (!initYes && !ws.HasConfig() && isInteractiveInput(cmd.InOrStdin()))
+	if wizard {
+		if err := runInitWizard(cmd, ws, git, envPath); err != nil {
+			_, _ = fmt.Fprintf(errOut, "warning: setup wizard failed: %v\n", err)
+		}
+	}
+
+	// Create config file with defaults if it doesn't exist
+	if !ws.HasConfig() {
+		cfg := storage.NewDefaultWorkspaceConfig()
+		if err := ws.SaveConfig(cfg); err != nil {
+			return fmt.Errorf("create config file: %w", err)
 		}
+		_, _ = fmt.Fprintf(out, "Created config file: %s\n", ws.ConfigPath())
+	} else if !wizard {
+		_, _ = fmt.Fprintf(out, "Config file already exists: %s\n", ws.ConfigPath())
 	}
 
 	_, _ = fmt.Fprintf(out, "Workspace initialized in %s\n", root)
@@ -124,78 +141,3 @@ func createEnvTemplate(path string) error {
 
 	return os.WriteFile(path, []byte(template), 0o600) // 0600 for secrets
 }
-
-// interactiveSetup guides the user through initial configuration.
-func interactiveSetup(cmd *cobra.Command, ws *storage.Workspace, envPath string) error {
-	out := cmd.OutOrStdout()
-	in := bufio.NewReader(cmd.InOrStdin())
-
-	_, _ = fmt.Fprintln(out)
-	_, _ = fmt.Fprintln(out, "Interactive Setup")
-	_, _ = fmt.Fprintln(out, "-----------------")
-
-	// Step 1: API Key
-	_, _ = fmt.Fprintln(out)
-	_, _ = fmt.Fprintf(out, "Enter your Anthropic API key (sk-ant-...): ")
-	apiKey, _ := in.ReadString('\n')
-	apiKey = strings.TrimSpace(apiKey)
-
-	if apiKey != "" {
-		if !strings.HasPrefix(apiKey, "sk-ant-") {
-			_, _ = fmt.Fprintln(out, "Warning: API key doesn't start with 'sk-ant-'. Did you enter it correctly?")
-		}
-		// Append to .env file
-		f, err := os.OpenFile(envPath, os.O_APPEND|os.O_WRONLY, 0o600)
-		if err == nil {
-			_, _ = fmt.Fprintf(f, "\nANTHROPIC_API_KEY=%s\n", apiKey)
-			if err := f.Close(); err != nil {
-				_, _ = fmt.Fprintf(out, "warning: failed to close .env file: %v\n", err)
-			}
-			_, _ = fmt.Fprintln(out, "API key saved to .env")
-		}
-	}
-
-	// Step 2: Default Provider
-	_, _ = fmt.Fprintln(out)
-	_, _ = fmt.Fprintln(out, "Available providers: file, dir, github, jira, linear, notion, wrike, youtrack")
-	_, _ = fmt.Fprintf(out, "Enter default provider [file]: ")
-	provider, _ := in.ReadString('\n')
-	provider = strings.TrimSpace(provider)
-	if provider == "" {
-		provider = "file"
-	}
-
-	// Step 3: Default Agent
-	_, _ = fmt.Fprintln(out)
-	_, _ = fmt.Fprintln(out, "Available built-in agents: claude")
-	_, _ = fmt.Fprintf(out, "Enter default agent [claude]: ")
-	agent, _ := in.ReadString('\n')
-	agent = strings.TrimSpace(agent)
-
-	if agent == "" {
-		agent = "claude"
-	}
-
-	if agent == "glm" {
-		_, _ = fmt.Fprintln(out, "Note: 'glm' is not a built-in agent. You'll need to configure it as an alias in config.yaml.")
-	}
-
-	// Update config.yaml with user's choices
-	cfg, err := ws.LoadConfig()
-	if err != nil {
-		return fmt.Errorf("load config: %w", err)
-	}
-
-	// Agent and Providers are value types in WorkspaceConfig, not pointers
-	cfg.Providers.Default = provider
-	cfg.Agent.Default = agent
-
-	if err := ws.SaveConfig(cfg); err != nil {
-		return fmt.Errorf("save config: %w", err)
-	}
-
-	_, _ = fmt.Fprintln(out)
-	_, _ = fmt.Fprintln(out, "Configuration saved!")
-
-	return nil
-}
@@ -4,12 +4,17 @@
 package commands
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/validation"
 )
 
 func TestInitCommand(t *testing.T) {
@@ -228,3 +233,139 @@ func TestCreateEnvTemplate(t *testing.T) {
 		}
 	}
 }
+
+func TestDetectGitHost(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   *gitHost
+	}{
+		{"git@github.com:acme/widgets.git", &gitHost{Provider: "github", Host: "github.com", Path: "acme/widgets"}},
+		{"https://github.com/acme/widgets", &gitHost{Provider: "github", Host: "github.com", Path: "acme/widgets"}},
+		{"ssh://git@gitlab.com:2222/group/sub/project.git", &gitHost{Provider: "gitlab", Host: "gitlab.com", Path: "group/sub/project"}},
+		{"https://gitlab.example.com/team/app.git", &gitHost{Provider: "gitlab", Host: "gitlab.example.com", Path: "team/app"}},
+		{"git@bitbucket.org:acme/widgets.git", nil},
+		{"https://github.com/acme", nil},
+		{"/srv/git/widgets.git", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			got, ok := detectGitHost(tt.remote)
+			if ok != (tt.want != nil) {
+				t.Fatalf("detectGitHost(%q) ok = %v, want %v", tt.remote, ok, tt.want != nil)
+			}
+			if ok && *got != *tt.want {
+				t.Errorf("detectGitHost(%q) = %+v, want %+v", tt.remote, *got, *tt.want)
+			}
+		})
+	}
+}
+
+// runInitWizardTest runs 'mehr init -i' in a git repository whose origin is
+// remote, answering with input and checking tokens with check.
+func runInitWizardTest(t *testing.T, remote, input string, check func(context.Context, string, string, string) (*validation.TokenStatus, error)) *TestContext {
+	t.Helper()
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GITLAB_TOKEN", "")
+
+	tc := NewTestContext(t)
+	tc.WithGit()
+	if out, err := exec.Command("git", "-C", tc.TmpDir, "remote", "add", "origin", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git remote add: %v: %s", err, out)
+	}
+
+	orig := checkProviderToken
+	checkProviderToken = check
+	t.Cleanup(func() {
+		checkProviderToken = orig
+		initInteractive = false
+	})
+
+	rootCmd := &cobra.Command{Use: "mehr"}
+	rootCmd.SetOut(tc.StdoutBuf)
+	rootCmd.SetErr(tc.StderrBuf)
+	rootCmd.SetIn(strings.NewReader(input))
+	rootCmd.AddCommand(initCmd)
+	rootCmd.SetArgs([]string{"init", "--interactive"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	return tc
+}
+
+func TestInitCommand_Wizard(t *testing.T) {
+	var checked string
+	tc := runInitWizardTest(t, "git@github.com:acme/widgets.git", "\nghp_test\n\n",
+		func(_ context.Context, provider, host, token string) (*validation.TokenStatus, error) {
+			checked = provider + " " + host + " " + token
+
+			return &validation.TokenStatus{User: "octocat", RateLimitRemaining: -1}, nil
+		})
+
+	if checked != "github  ghp_test" {
+		t.Errorf("token check = %q, want github token checked against the public API", checked)
+	}
+	tc.AssertStdoutContains("Detected GitHub repository acme/widgets")
+	tc.AssertStdoutContains("authenticated as octocat")
+	tc.AssertStdoutContains("Configuration saved")
+	tc.AssertFileContains(".mehrhof/.env", "GITHUB_TOKEN=ghp_test")
+
+	cfg, err := tc.GetWorkspaceConfig()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.Providers.Default != "github" {
+		t.Errorf("providers.default = %q, want github", cfg.Providers.Default)
+	}
+	if cfg.GitHub == nil || cfg.GitHub.Owner != "acme" || cfg.GitHub.Repo != "widgets" {
+		t.Errorf("github = %+v, want owner acme, repo widgets", cfg.GitHub)
+	}
+	if cfg.Agent.Default == "" {
+		t.Error("agent.default is empty")
+	}
+}
+
+func TestInitCommand_WizardRejectedToken(t *testing.T) {
+	// Accept gitlab, enter a rejected token, do not save it, skip the retry
+	tc := runInitWizardTest(t, "https://gitlab.example.com/team/app.git", "\nbad\n\n\n\n",
+		func(_ context.Context, _, host, _ string) (*validation.TokenStatus, error) {
+			if host != "https://gitlab.example.com" {
+				t.Errorf("token checked against %q, want the self-hosted instance", host)
+			}
+
+			return nil, fmt.Errorf("%w by gitlab (HTTP 401)", validation.ErrTokenRejected)
+		})
+
+	tc.AssertStdoutContains("The token was rejected")
+	tc.AssertStdoutContains("No token saved")
+	content, err := os.ReadFile(filepath.Join(tc.TmpDir, ".mehrhof", ".env"))
+	if err != nil {
+		t.Fatalf("read .env: %v", err)
+	}
+	if strings.Contains(string(content), "GITLAB_TOKEN=bad") {
+		t.Error("rejected token was saved")
+	}
+
+	cfg, err := tc.GetWorkspaceConfig()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.GitLab == nil || cfg.GitLab.ProjectPath != "team/app" || cfg.GitLab.Host != "https://gitlab.example.com" {
+		t.Errorf("gitlab = %+v, want team/app on the self-hosted instance", cfg.GitLab)
+	}
+}
+
+func TestInitCommand_YesWithInteractive(t *testing.T) {
+	tc := NewTestContext(t)
+	t.Cleanup(func() { initInteractive, initYes = false, false })
+
+	rootCmd := &cobra.Command{Use: "mehr"}
+	rootCmd.SetOut(tc.StdoutBuf)
+	rootCmd.SetErr(tc.StderrBuf)
+	rootCmd.AddCommand(initCmd)
+	rootCmd.SetArgs([]string{"init", "--yes", "--interactive"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("expected an error for --yes with --interactive")
+	}
+}
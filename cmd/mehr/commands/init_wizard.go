@@ -0,0 +1,316 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/validation"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+// checkProviderToken verifies tokens entered in the wizard; replaced in tests.
+var checkProviderToken = validation.CheckToken
+
+// gitHost is the git hosting service a repository's origin points at.
+type gitHost struct {
+	Provider string // "github" or "gitlab"
+	Host     string // Host name, e.g. "github.com"
+	Path     string // Repository path, e.g. "owner/repo" or "group/sub/project"
+}
+
+// detectGitHost recognizes GitHub and GitLab (including self-hosted
+// instances with "gitlab" in the host name) from a remote URL in SSH
+// (git@host:path, ssh://host/path) or HTTPS form.
+func detectGitHost(remoteURL string) (*gitHost, bool) {
+	remoteURL = strings.TrimSpace(remoteURL)
+	var host, path string
+	if at := strings.Index(remoteURL, "@"); !strings.Contains(remoteURL, "://") && at >= 0 {
+		rest := remoteURL[at+1:]
+		colon := strings.Index(rest, ":")
+		if colon < 0 {
+			return nil, false
+		}
+		host, path = rest[:colon], rest[colon+1:]
+	} else {
+		u, err := url.Parse(remoteURL)
+		if err != nil || u.Host == "" {
+			return nil, false
+		}
+		host, path = u.Hostname(), u.Path
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if strings.Count(path, "/") < 1 {
+		return nil, false
+	}
+
+	host = strings.ToLower(host)
+	switch {
+	case host == "github.com":
+		return &gitHost{Provider: "github", Host: host, Path: path}, true
+	case strings.Contains(host, "gitlab"):
+		return &gitHost{Provider: "gitlab", Host: host, Path: path}, true
+	}
+
+	return nil, false
+}
+
+// isInteractiveInput reports whether r is a terminal: a character device
+// other than the null device, which is what stdin is under CI and go test.
+func isInteractiveInput(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	null, err := os.Stat(os.DevNull)
+
+	return err != nil || !os.SameFile(info, null)
+}
+
+// initWizard asks the questions of 'mehr init' on one input stream.
+type initWizard struct {
+	cmd *cobra.Command
+	out io.Writer
+	in  *bufio.Reader
+	ws  *storage.Workspace
+	eof bool // Input ended; every further question gets its default
+}
+
+// ask prints a question and returns the trimmed answer, or def when the
+// answer is empty.
+func (w *initWizard) ask(question, def string) string {
+	if def != "" {
+		_, _ = fmt.Fprintf(w.out, "%s [%s]: ", question, def)
+	} else {
+		_, _ = fmt.Fprintf(w.out, "%s: ", question)
+	}
+	answer, err := w.in.ReadString('\n')
+	w.eof = err != nil
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return def
+	}
+
+	return answer
+}
+
+// confirm asks a yes/no question.
+func (w *initWizard) confirm(question string, def bool) bool {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	switch strings.ToLower(w.ask(question+" ("+hint+")", "")) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+
+	return def
+}
+
+// runInitWizard builds the workspace configuration interactively: the
+// provider of the detected git host with a tested token, and the default
+// agent from the agent CLIs found on this machine. The configuration is
+// validated before it is saved.
+func runInitWizard(cmd *cobra.Command, ws *storage.Workspace, git *vcs.Git, envPath string) error {
+	w := &initWizard{cmd: cmd, out: cmd.OutOrStdout(), in: bufio.NewReader(cmd.InOrStdin()), ws: ws}
+
+	cfg := storage.NewDefaultWorkspaceConfig()
+	if ws.HasConfig() {
+		loaded, err := ws.LoadSharedConfig()
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
+		cfg = loaded
+	}
+
+	_, _ = fmt.Fprintln(w.out)
+	_, _ = fmt.Fprintln(w.out, "Interactive Setup")
+	_, _ = fmt.Fprintln(w.out, "-----------------")
+
+	if err := w.configureProvider(cfg, git, envPath); err != nil {
+		return err
+	}
+	if err := w.configureAgent(cfg); err != nil {
+		return err
+	}
+
+	reg := agent.NewRegistry()
+	if err := registerStandardAgents(reg); err != nil {
+		return fmt.Errorf("register agents: %w", err)
+	}
+	result := validation.ValidateConfig(cfg, ws.ConfigPath(), reg.List())
+	if !result.Valid {
+		_, _ = fmt.Fprintln(w.out)
+		_, _ = fmt.Fprint(w.out, result.Format("text"))
+
+		return errors.New("configuration is invalid, nothing was saved")
+	}
+	if err := ws.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("save config: %w", err)
+	}
+
+	_, _ = fmt.Fprintln(w.out)
+	_, _ = fmt.Fprintf(w.out, "Configuration saved to %s\n", ws.ConfigPath())
+
+	return nil
+}
+
+// configureProvider offers to set up the provider matching the origin remote
+// and checks its token.
+func (w *initWizard) configureProvider(cfg *storage.WorkspaceConfig, git *vcs.Git, envPath string) error {
+	_, _ = fmt.Fprintln(w.out)
+	if git == nil {
+		_, _ = fmt.Fprintln(w.out, "Not a git repository; skipping provider detection.")
+
+		return nil
+	}
+	remote, err := git.RemoteURL(w.cmd.Context(), "origin")
+	if err != nil || remote == "" {
+		_, _ = fmt.Fprintln(w.out, "No origin remote; skipping provider detection.")
+
+		return nil
+	}
+	host, ok := detectGitHost(remote)
+	if !ok {
+		_, _ = fmt.Fprintf(w.out, "Origin %s is not on GitHub or GitLab; skipping provider setup.\n", remote)
+
+		return nil
+	}
+
+	login := providerLoginConfigs[host.Provider]
+	_, _ = fmt.Fprintf(w.out, "Detected %s repository %s on %s.\n", login.Name, host.Path, host.Host)
+	if !w.confirm(fmt.Sprintf("Use %s as the default provider?", host.Provider), true) {
+		return nil
+	}
+
+	cfg.Providers.Default = host.Provider
+	apiHost := ""
+	switch host.Provider {
+	case "github":
+		owner, repo, _ := strings.Cut(host.Path, "/")
+		if cfg.GitHub == nil {
+			cfg.GitHub = &storage.GitHubSettings{}
+		}
+		cfg.GitHub.Owner, cfg.GitHub.Repo = owner, repo
+	case "gitlab":
+		if cfg.GitLab == nil {
+			cfg.GitLab = &storage.GitLabSettings{}
+		}
+		cfg.GitLab.ProjectPath = host.Path
+		if host.Host != "gitlab.com" {
+			cfg.GitLab.Host = "https://" + host.Host
+			apiHost = cfg.GitLab.Host
+		}
+	}
+
+	return w.configureToken(login, host.Provider, apiHost, envPath)
+}
+
+// configureToken tests the provider token that is already set, or asks for
+// one and saves it to .env once the provider accepts it.
+func (w *initWizard) configureToken(login providerLoginConfig, provider, apiHost, envPath string) error {
+	token := os.Getenv(login.EnvVar)
+	if token == "" {
+		if env, err := w.ws.LoadEnv(); err == nil {
+			token = env[login.EnvVar]
+		}
+	}
+	if token != "" {
+		_, _ = fmt.Fprintf(w.out, "Checking the existing %s...\n", login.EnvVar)
+		if w.checkToken(provider, apiHost, token) {
+			return nil
+		}
+	}
+
+	for {
+		_, _ = fmt.Fprintf(w.out, "Get a %s token at: %s\n", login.Name, login.HelpURL)
+		entered := w.ask("Token (empty to skip)", "")
+		if entered == "" {
+			_, _ = fmt.Fprintf(w.out, "No token saved; run 'mehr %s login' later.\n", provider)
+
+			return nil
+		}
+		if login.TokenPrefix != "" && !strings.HasPrefix(entered, login.TokenPrefix) {
+			_, _ = fmt.Fprintf(w.out, "Warning: Token doesn't start with expected prefix '%s'\n", login.TokenPrefix)
+		}
+		if w.checkToken(provider, apiHost, entered) || w.confirm("Save the token anyway?", false) {
+			if err := writeTokenToEnv(envPath, login.EnvVar, entered); err != nil {
+				return fmt.Errorf("save token: %w", err)
+			}
+			_, _ = fmt.Fprintf(w.out, "%s saved to %s\n", login.EnvVar, envPath)
+
+			return nil
+		}
+	}
+}
+
+// checkToken reports the outcome of a token check and whether the token can
+// be used. Tokens that cannot be checked, for example offline, are accepted.
+func (w *initWizard) checkToken(provider, apiHost, token string) bool {
+	status, err := checkProviderToken(w.cmd.Context(), provider, apiHost, token)
+	switch {
+	case errors.Is(err, validation.ErrTokenRejected):
+		_, _ = fmt.Fprintf(w.out, "The token was rejected: %v\n", err)
+
+		return false
+	case err != nil:
+		_, _ = fmt.Fprintf(w.out, "Could not check the token: %v\n", err)
+
+		return true
+	}
+	_, _ = fmt.Fprintf(w.out, "Token OK, authenticated as %s.\n", status.User)
+
+	return true
+}
+
+// configureAgent picks the default agent, suggesting the first one whose CLI
+// is installed.
+func (w *initWizard) configureAgent(cfg *storage.WorkspaceConfig) error {
+	reg := agent.NewRegistry()
+	if err := registerStandardAgents(reg); err != nil {
+		return fmt.Errorf("register agents: %w", err)
+	}
+	known := reg.List()
+	available := reg.Available()
+	slices.Sort(known)
+	slices.Sort(available)
+
+	_, _ = fmt.Fprintln(w.out)
+	def := cfg.Agent.Default
+	if len(available) == 0 {
+		_, _ = fmt.Fprintf(w.out, "No agent CLI found on PATH (known agents: %s).\n", strings.Join(known, ", "))
+	} else {
+		_, _ = fmt.Fprintf(w.out, "Agent CLIs found: %s\n", strings.Join(available, ", "))
+		if !slices.Contains(available, def) {
+			def = available[0]
+		}
+	}
+
+	for {
+		name := w.ask("Default agent", def)
+		if slices.Contains(known, name) || cfg.Agents[name].Extends != "" {
+			cfg.Agent.Default = name
+
+			return nil
+		}
+		if w.eof {
+			return fmt.Errorf("unknown agent %q", name)
+		}
+		_, _ = fmt.Fprintf(w.out, "Unknown agent %q; choose one of: %s\n", name, strings.Join(known, ", "))
+	}
+}
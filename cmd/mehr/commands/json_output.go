@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/output"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// errNoActiveTask is returned by commands that need an active task.
+var errNoActiveTask = errors.New("no active task")
+
+// jsonStdout is the real stdout while workflow commands run in --json mode,
+// with os.Stdout pointing at stderr so progress output from the conductor
+// and agents cannot mix into the JSON document. It is nil otherwise.
+var jsonStdout *os.File
+
+// redirectStdout sends everything printed to stdout to stderr until the
+// command ends, and returns the writer for the JSON document.
+func redirectStdout() io.Writer {
+	if jsonStdout == nil {
+		jsonStdout = os.Stdout
+		os.Stdout = os.Stderr
+	}
+
+	return jsonStdout
+}
+
+// restoreStdout undoes redirectStdout.
+func restoreStdout() {
+	if jsonStdout != nil {
+		os.Stdout = jsonStdout
+		jsonStdout = nil
+	}
+}
+
+// wantsJSON reports whether the command was run with --json.
+func wantsJSON(cmd *cobra.Command) bool {
+	if cmd == nil {
+		return false
+	}
+	flag := cmd.Flags().Lookup("json")
+
+	return flag != nil && flag.Value.String() == "true"
+}
+
+// outputJSON prints v as the command's JSON document.
+func outputJSON(v any) error {
+	return output.JSON(os.Stdout, v)
+}
+
+// classifyError attaches the error code and exit code to errors that have
+// their own, see package output.
+func classifyError(err error) error {
+	var coded *output.Error
+	switch {
+	case err == nil || errors.As(err, &coded):
+		return err
+	case errors.Is(err, errNoActiveTask):
+		return output.WithCode(err, output.CodeNoTask, output.ExitNoTask)
+	case errors.Is(err, conductor.ErrPendingQuestion):
+		return output.WithCode(err, output.CodeQuestion, output.ExitQuestion)
+	case errors.Is(err, conductor.ErrGuardrail), errors.Is(err, conductor.ErrPolicyViolation), errors.Is(err, conductor.ErrOutsideScope):
+		return output.WithCode(err, output.CodeBlocked, output.ExitBlocked)
+	case errors.Is(err, conductor.ErrCanceled):
+		return output.WithCode(err, output.CodeCanceled, output.ExitCanceled)
+	}
+
+	return err
+}
+
+// jsonTaskResult is the --json document of start, plan, implement and
+// finish.
+type jsonTaskResult struct {
+	Command        string        `json:"command"`
+	TaskID         string        `json:"task_id"`
+	Title          string        `json:"title,omitempty"`
+	ExternalKey    string        `json:"external_key,omitempty"`
+	State          string        `json:"state"`
+	Branch         string        `json:"branch,omitempty"`
+	WorktreePath   string        `json:"worktree_path,omitempty"`
+	Specifications int           `json:"specifications"`
+	Checkpoints    int           `json:"checkpoints"`
+	DryRun         bool          `json:"dry_run,omitempty"`
+	Question       *jsonQuestion `json:"question,omitempty"`
+	PullRequestURL string        `json:"pull_request_url,omitempty"`
+	Merged         bool          `json:"merged,omitempty"`
+}
+
+type jsonQuestion struct {
+	Text    string               `json:"text"`
+	Options []jsonQuestionOption `json:"options,omitempty"`
+}
+
+type jsonQuestionOption struct {
+	Label       string `json:"label"`
+	Description string `json:"description,omitempty"`
+}
+
+func newJSONTaskResult(command string, status *conductor.TaskStatus) jsonTaskResult {
+	return jsonTaskResult{
+		Command:        command,
+		TaskID:         status.TaskID,
+		Title:          status.Title,
+		ExternalKey:    status.ExternalKey,
+		State:          status.State,
+		Branch:         status.Branch,
+		WorktreePath:   status.WorktreePath,
+		Specifications: status.Specifications,
+		Checkpoints:    status.Checkpoints,
+	}
+}
+
+func newJSONQuestion(q *storage.PendingQuestion) *jsonQuestion {
+	if q == nil {
+		return nil
+	}
+	out := &jsonQuestion{Text: q.Question}
+	for _, opt := range q.Options {
+		out.Options = append(out.Options, jsonQuestionOption{Label: opt.Label, Description: opt.Description})
+	}
+
+	return out
+}
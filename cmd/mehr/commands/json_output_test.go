@@ -0,0 +1,80 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/output"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestWorkflowCommands_JSONFlag(t *testing.T) {
+	for _, cmd := range []string{"start", "plan", "implement", "finish", "status", "list"} {
+		c, _, err := rootCmd.Find([]string{cmd})
+		if err != nil {
+			t.Fatalf("find %s: %v", cmd, err)
+		}
+		if c.Flags().Lookup("json") == nil {
+			t.Errorf("%s has no --json flag", cmd)
+		}
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{errors.New("boom"), output.ExitError},
+		{errNoActiveTask, output.ExitNoTask},
+		{fmt.Errorf("run planning: %w", conductor.ErrPendingQuestion), output.ExitQuestion},
+		{fmt.Errorf("run implementation: %w", conductor.ErrGuardrail), output.ExitBlocked},
+		{fmt.Errorf("apply files: %w", conductor.ErrOutsideScope), output.ExitBlocked},
+		{fmt.Errorf("run implementation: %w", conductor.ErrCanceled), output.ExitCanceled},
+		{output.WithCode(errors.New("bad flag"), output.CodeUsage, output.ExitUsage), output.ExitUsage},
+	}
+	for _, tt := range tests {
+		if got := output.ExitCode(classifyError(tt.err)); got != tt.want {
+			t.Errorf("exit code for %q = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+	if classifyError(nil) != nil {
+		t.Error("classifyError(nil) != nil")
+	}
+}
+
+func TestRedirectStdout(t *testing.T) {
+	stdout := os.Stdout
+	defer func() { os.Stdout = stdout }()
+
+	out := redirectStdout()
+	if out != stdout || os.Stdout != os.Stderr {
+		t.Errorf("redirectStdout() did not send stdout to stderr")
+	}
+	if again := redirectStdout(); again != out {
+		t.Error("second redirectStdout() lost the real stdout")
+	}
+	restoreStdout()
+	if os.Stdout != stdout || jsonStdout != nil {
+		t.Error("restoreStdout() did not restore stdout")
+	}
+}
+
+func TestNewJSONQuestion(t *testing.T) {
+	if newJSONQuestion(nil) != nil {
+		t.Error("newJSONQuestion(nil) != nil")
+	}
+	q := newJSONQuestion(&storage.PendingQuestion{
+		Question: "Which cache?",
+		Options:  []storage.QuestionOption{{Label: "Redis", Description: "Shared"}},
+	})
+	if q.Text != "Which cache?" || len(q.Options) != 1 || q.Options[0].Label != "Redis" || q.Options[0].Description != "Shared" {
+		t.Errorf("newJSONQuestion() = %+v", q)
+	}
+}
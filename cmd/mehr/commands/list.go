@@ -55,13 +55,13 @@ func runList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("open workspace: %w", err)
 	}
 
-	// Get all tasks
-	taskIDs, err := ws.ListWorks()
+	// Get all tasks (read from the index when the workspace has one)
+	works, err := ws.LoadWorks()
 	if err != nil {
 		return fmt.Errorf("list tasks: %w", err)
 	}
 
-	if len(taskIDs) == 0 {
+	if len(works) == 0 {
 		if listJSON {
 			return outputJSON([]jsonListTask{})
 		}
@@ -89,11 +89,8 @@ func runList(cmd *cobra.Command, args []string) error {
 	// JSON output
 	if listJSON {
 		var tasks []jsonListTask
-		for _, taskID := range taskIDs {
-			work, err := ws.LoadWork(taskID)
-			if err != nil {
-				continue
-			}
+		for _, work := range works {
+			taskID := work.Metadata.ID
 
 			// Filter by worktrees if requested
 			if listWorktreesOnly && work.Git.WorktreePath == "" {
@@ -146,11 +143,8 @@ func runList(cmd *cobra.Command, args []string) error {
 	}
 
 	var shownCount int
-	for _, taskID := range taskIDs {
-		work, err := ws.LoadWork(taskID)
-		if err != nil {
-			continue
-		}
+	for _, work := range works {
+		taskID := work.Metadata.ID
 
 		// Filter by worktrees if requested
 		if listWorktreesOnly && work.Git.WorktreePath == "" {
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/mcp"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Serve the workspace to AI assistants over MCP",
+	Long: `Run a Model Context Protocol (MCP) server on stdin/stdout.
+
+MCP-capable assistants, such as those built into IDEs, can then read task
+specifications, notes, sessions and pending questions, and update them.
+The server reads .mehrhof/ directly and does not run any agent.
+
+TOOLS:
+  list_tasks            Tasks with state and specification count
+  get_task              Task details and specification statuses
+  read_specification    Content of a specification
+  read_notes            The task's notes
+  list_sessions         Agent sessions with bookmarks
+  read_session          Exchanges and annotations of a session
+  get_pending_question  The question the agent is waiting on
+  append_note           Add a note to the task
+  update_spec_status    Set a specification's status
+  answer_question       Answer the pending question
+
+Tools default to the active task when task_id is omitted. Notes,
+specifications and pending questions are also available as resources
+(mehr://tasks/<id>/notes, .../specifications/<n>, .../question).
+
+Register the server in your assistant's MCP configuration, for example:
+
+  {"mcpServers": {"mehrhof": {"command": "mehr", "args": ["mcp"]}}}`,
+	Args: cobra.NoArgs,
+	RunE: runMCP,
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
+
+func runMCP(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	res, err := ResolveWorkspaceRoot(ctx)
+	if err != nil {
+		return err
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+
+	server := mcp.NewServer(ws, "mehrhof", Version)
+
+	return server.Serve(ctx, cmd.InOrStdin(), cmd.OutOrStdout())
+}
@@ -0,0 +1,23 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMCPCommand_Properties(t *testing.T) {
+	if mcpCmd.Use != "mcp" {
+		t.Errorf("Use = %q, want %q", mcpCmd.Use, "mcp")
+	}
+	if mcpCmd.RunE == nil {
+		t.Error("RunE not set")
+	}
+	for _, tool := range []string{"read_specification", "append_note", "update_spec_status", "answer_question"} {
+		if !strings.Contains(mcpCmd.Long, tool) {
+			t.Errorf("Long description does not mention %s", tool)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade task files written by older mehrhof versions",
+	Long: `Upgrade every work.yaml and session file in the workspace to the schema
+versions this mehrhof writes. Each file is rewritten atomically.
+
+Files are also upgraded one at a time as they are loaded; migrate upgrades
+them all at once, for example before sharing the work directory with
+teammates. Use --dry-run to see which files would change.`,
+	Example: `  mehr migrate --dry-run
+  mehr migrate`,
+	Args: cobra.NoArgs,
+	RunE: runMigrate,
+}
+
+var migrateDryRun bool
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Report files that need upgrading without writing them")
+}
+
+func runMigrate(cmd *cobra.Command, _ []string) error {
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+	cfg, err := ws.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if ws, err = storage.OpenWorkspace(res.Root, cfg); err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+
+	changes, err := ws.Migrate(migrateDryRun)
+	out := cmd.OutOrStdout()
+	verb := "Upgraded"
+	if migrateDryRun {
+		verb = "Would upgrade"
+	}
+	for _, change := range changes {
+		from := change.From
+		if from == "" {
+			from = "unversioned"
+		}
+		_, _ = fmt.Fprintf(out, "%s %s (%s -> %s): %s\n", verb, change.Path, from, change.To, strings.Join(change.Steps, "; "))
+	}
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+
+	if len(changes) == 0 {
+		_, _ = fmt.Fprintln(out, "All task files are up to date.")
+	} else if migrateDryRun {
+		_, _ = fmt.Fprintf(out, "\n%d file(s) need upgrading. Run 'mehr migrate' to apply.\n", len(changes))
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"testing"
+)
+
+func TestMigrateCommand_Properties(t *testing.T) {
+	if migrateCmd.Use != "migrate" {
+		t.Errorf("Use = %q, want %q", migrateCmd.Use, "migrate")
+	}
+	if migrateCmd.Short == "" {
+		t.Error("Short description is empty")
+	}
+	if migrateCmd.RunE == nil {
+		t.Error("RunE not set")
+	}
+
+	flag := migrateCmd.Flags().Lookup("dry-run")
+	if flag == nil {
+		t.Fatal("flag \"dry-run\" not found")
+	}
+	if flag.DefValue != "false" {
+		t.Errorf("dry-run default = %q, want %q", flag.DefValue, "false")
+	}
+}
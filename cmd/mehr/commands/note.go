@@ -19,7 +19,8 @@ var noteCmd = &cobra.Command{
 	Short:   "Add notes to the task or answer agent questions",
 	Long: `Add notes, context, or requirements to the current task.
 
-This command saves your input directly to notes.md in the work directory.
+This command saves each note as its own file in the work directory, next to
+notes.md, so notes added from two worktrees or users never conflict in git.
 Notes are included when the agent runs during plan/implement/review phases.
 
 Use this to add requirements, clarify specifications, or provide context
@@ -71,14 +72,7 @@ func runNote(cmd *cobra.Command, args []string) error {
 	// Helper function to save a note
 	saveNote := func(message string) error {
 		if ws.HasPendingQuestion(taskID) {
-			q, _ := ws.LoadPendingQuestion(taskID)
-			note := fmt.Sprintf("**Q:** %s\n\n**A:** %s", q.Question, message)
-			if err := ws.AppendNote(taskID, note, "answer"); err != nil {
-				return fmt.Errorf("save answer: %w", err)
-			}
-			_ = ws.ClearPendingQuestion(taskID)
-
-			return nil
+			return cond.AnswerQuestion(message)
 		}
 
 		return ws.AppendNote(taskID, message, cond.GetActiveTask().State)
@@ -92,9 +86,17 @@ func runNote(cmd *cobra.Command, args []string) error {
 		hadPendingQuestion := ws.HasPendingQuestion(taskID)
 
 		// Show the question being answered (so user knows what they're responding to)
+		continueCmd := "plan"
 		if hadPendingQuestion {
 			q, _ := ws.LoadPendingQuestion(taskID)
 			fmt.Printf("Answering: %s\n", q.Question)
+			// Questions left by a phase timeout are answered by re-running that phase
+			switch q.Phase {
+			case "implementing":
+				continueCmd = "implement"
+			case "reviewing":
+				continueCmd = "review"
+			}
 		}
 
 		if err := saveNote(message); err != nil {
@@ -104,7 +106,7 @@ func runNote(cmd *cobra.Command, args []string) error {
 		// Context-aware success message
 		if hadPendingQuestion {
 			fmt.Println("Answer submitted.")
-			fmt.Println("\nRun 'mehr plan' to continue with your answer.")
+			fmt.Printf("\nRun 'mehr %s' to continue with your answer.\n", continueCmd)
 		} else {
 			fmt.Println("Note saved.")
 		}
@@ -0,0 +1,11 @@
+//go:build linux
+
+package commands
+
+import "syscall"
+
+// Terminal attribute requests of readHiddenLine.
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package commands
+
+import (
+	"errors"
+	"os"
+)
+
+// readHiddenLine refuses to prompt where echo cannot be turned off, rather
+// than show the passphrase as it is typed.
+func readHiddenLine(*os.File) (string, error) {
+	return "", errors.New("cannot hide the passphrase on this terminal; set " + backupPassphraseEnv + " or use --passphrase-file")
+}
@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/patchset"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+var (
+	exportPatchOutput string
+	exportPatchBundle bool
+	importPatchBranch string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export task work for use elsewhere",
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import task work exported elsewhere",
+}
+
+var exportPatchCmd = &cobra.Command{
+	Use:   "patch [task-id]",
+	Short: "Export a task's commits as a patch series for offline review",
+	Long: `Write the commits of a task, from where its branch left the base branch to
+its tip, into a directory as a git format-patch series. The directory also
+holds manifest.json, describing the base commit, checkpoints, specifications
+and sessions, and a copy of the specifications.
+
+With --bundle the commits are written as a single git bundle instead, which
+keeps commit hashes and signatures.
+
+Send the directory to reviewers, who apply it with 'mehr import patch'.
+Defaults to the active task when no task ID is given.`,
+	Example: `  mehr export patch
+  mehr export patch a1b2c3d4 -o /tmp/review
+  mehr export patch --bundle`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runExportPatch,
+}
+
+var importPatchCmd = &cobra.Command{
+	Use:   "patch <dir>",
+	Short: "Apply an exported patch series onto a new branch",
+	Long: `Create a branch from the base commit of an export made by 'mehr export
+patch', apply its commits and check the branch out.
+
+The working tree must be clean and the repository must already contain the
+base commit (fetch the base branch first). When a patch does not apply, the
+repository is left as it was.`,
+	Example: `  mehr import patch FEATURE-123.patches
+  mehr import patch /tmp/review --branch review/cache`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImportPatch,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+	exportCmd.AddCommand(exportPatchCmd)
+	importCmd.AddCommand(importPatchCmd)
+
+	exportPatchCmd.Flags().StringVarP(&exportPatchOutput, "output", "o", "", "Directory to write (default: <task>.patches)")
+	exportPatchCmd.Flags().BoolVar(&exportPatchBundle, "bundle", false, "Write a git bundle instead of a patch series")
+
+	importPatchCmd.Flags().StringVarP(&importPatchBranch, "branch", "b", "", "Branch to create (default: the exported branch)")
+}
+
+func runExportPatch(cmd *cobra.Command, args []string) error {
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if res.Git == nil {
+		return errors.New("not in a git repository")
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+
+	taskID, err := resolveTaskIDArg(ws, args)
+	if err != nil {
+		return err
+	}
+
+	dir := exportPatchOutput
+	if dir == "" {
+		dir = taskID + ".patches"
+		if work, err := ws.LoadWork(taskID); err == nil && work.Metadata.ExternalKey != "" {
+			dir = work.Metadata.ExternalKey + ".patches"
+		}
+	}
+
+	manifest, err := patchset.Export(cmd.Context(), ws, res.Git, taskID, dir, patchset.Options{Bundle: exportPatchBundle})
+	if err != nil {
+		return fmt.Errorf("export patch: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	what := fmt.Sprintf("%d patch(es)", len(manifest.Patches))
+	if manifest.Format == patchset.FormatBundle {
+		what = fmt.Sprintf("a bundle of %d commit(s)", manifest.Commits)
+	}
+	_, _ = fmt.Fprintf(out, "Exported %s, %d specification(s) and %d session(s) to %s\n",
+		what, len(manifest.Specifications), len(manifest.Sessions), dir)
+	_, _ = fmt.Fprintf(out, "Base: %s (%s)\n", manifest.BaseCommit[:8], manifest.BaseBranch)
+
+	return nil
+}
+
+func runImportPatch(cmd *cobra.Command, args []string) error {
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if res.Git == nil {
+		return errors.New("not in a git repository")
+	}
+
+	git := res.Git
+	if ws, err := storage.OpenWorkspace(res.Root, nil); err == nil {
+		if cfg, err := ws.LoadConfig(); err == nil && cfg.Git.SignCommits {
+			git = git.WithSigning(vcs.Signing{Format: cfg.Git.SigningFormat, Key: cfg.Git.SigningKey})
+		}
+	}
+
+	manifest, err := patchset.Import(cmd.Context(), git, args[0], patchset.ImportOptions{Branch: importPatchBranch})
+	if err != nil {
+		return fmt.Errorf("import patch: %w", err)
+	}
+
+	branch, _ := git.CurrentBranch(cmd.Context())
+	title := manifest.TaskID
+	if manifest.Title != "" {
+		title = manifest.Title
+	}
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "Imported %d commit(s) of %q onto branch %s\n", manifest.Commits, title, branch)
+	for _, spec := range manifest.Specifications {
+		_, _ = fmt.Fprintf(out, "  Specification %d: %s (%s)\n", spec.Number, spec.Title, spec.File)
+	}
+
+	return nil
+}
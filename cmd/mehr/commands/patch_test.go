@@ -0,0 +1,55 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestPatchCommands_Structure(t *testing.T) {
+	if !slices.Contains(rootCmd.Commands(), exportCmd) || !slices.Contains(rootCmd.Commands(), importCmd) {
+		t.Fatal("export and import commands not registered")
+	}
+	if !slices.Contains(exportCmd.Commands(), exportPatchCmd) {
+		t.Error("export patch subcommand not registered")
+	}
+	if !slices.Contains(importCmd.Commands(), importPatchCmd) {
+		t.Error("import patch subcommand not registered")
+	}
+	if !slices.Contains(exportCmd.Commands(), exportTaskCmd) || !slices.Contains(importCmd.Commands(), importTaskCmd) {
+		t.Error("export/import task subcommands not registered")
+	}
+}
+
+func TestPatchCommands_Flags(t *testing.T) {
+	tests := []struct {
+		cmd          *cobra.Command
+		flagName     string
+		shorthand    string
+		defaultValue string
+	}{
+		{exportPatchCmd, "output", "o", ""},
+		{exportPatchCmd, "bundle", "", "false"},
+		{importPatchCmd, "branch", "b", ""},
+		{exportTaskCmd, "output", "o", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cmd.Parent().Name()+"_"+tt.cmd.Name()+"/"+tt.flagName, func(t *testing.T) {
+			flag := tt.cmd.Flags().Lookup(tt.flagName)
+			if flag == nil {
+				t.Fatalf("flag %q not found", tt.flagName)
+			}
+			if flag.Shorthand != tt.shorthand {
+				t.Errorf("shorthand = %q, want %q", flag.Shorthand, tt.shorthand)
+			}
+			if flag.DefValue != tt.defaultValue {
+				t.Errorf("default = %q, want %q", flag.DefValue, tt.defaultValue)
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package commands
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -12,6 +14,7 @@ import (
 
 	"github.com/valksor/go-mehrhof/internal/conductor"
 	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/output"
 	"github.com/valksor/go-mehrhof/internal/storage"
 )
 
@@ -20,6 +23,13 @@ var (
 	planSeed          string
 	planFullContext   bool
 	planAgentPlanning string // Per-step agent override
+	planTemplate      string
+	planScaffold      bool
+	planInteractive   bool
+	planContinue      bool
+	planCritique      bool
+	planContext       string
+	planJSON          bool
 )
 
 var planCmd = &cobra.Command{
@@ -41,7 +51,37 @@ You can run this multiple times to create additional specifications.
 STANDALONE MODE (--standalone):
   Start a planning session without an active task. This is useful for
   exploring requirements before creating a formal task.
-  Plans are saved to .mehrhof/planned/ directory.
+  Plans are saved to .mehrhof/planned/ directory. List the plan's tasks as
+  "- [ ] ..." items in its plan.md and run them with 'mehr plan execute'.
+
+SPEC TEMPLATES (--template):
+  Make the agent follow a spec template so specifications share a structure.
+  Built-in templates: go-service, bugfix, migration, api-endpoint. Project
+  templates in .mehrhof/templates/<name>.md override built-ins with the same
+  name. The template is remembered for later planning runs of the task.
+  Use --scaffold to write the template as a draft specification without
+  running the agent, then fill it in yourself.
+
+INTERACTIVE MODE (--interactive):
+  Refine draft specifications in a conversation with the agent. Each message
+  continues the task's planning session and updates the latest draft
+  specification. Approve a draft with /approve N to mark it ready; the next
+  message then starts a new draft. Type /help for all commands.
+
+CONTINUING A CONVERSATION (--continue):
+  Continue the latest planning session instead of starting a new one. Agents
+  that support it (claude) resume their own conversation, so only the new
+  notes are sent; other agents get the earlier exchanges replayed in the
+  prompt. Planning after an agent question always continues the session.
+
+SPEC CRITIQUE (--critique):
+  A critic agent (agent.critic_agent, else the reviewing agent) scores each
+  new specification for completeness, testability and edge cases. The
+  planning agent revises it until the critic passes it, for up to
+  workflow.spec_critique_rounds rounds (default 2). A specification that
+  passes is marked ready; otherwise it stays a draft. The last critique is
+  kept in the specification's frontmatter. Set workflow.spec_critique to
+  critique on every planning run; --critique=false turns it off.
 
 SEED TOPIC:
   For standalone mode, you can provide a seed topic in two ways:
@@ -52,6 +92,12 @@ Examples:
   mehr plan                           # Create specifications for active task
   mehr plan --verbose                 # Show agent output
   mehr plan --full-context            # Include full exploration context
+  mehr plan --continue                # Continue the previous planning conversation
+  mehr plan --interactive             # Refine draft specifications with the agent
+  mehr plan --template bugfix         # Follow the bugfix spec template
+  mehr plan --critique                # Have a critic review and refine the specification
+  mehr plan --template go-service --scaffold  # Write the template without the agent
+  mehr plan --json                    # Print the result (and any question) as JSON
   mehr plan --standalone              # Start standalone planning
   mehr plan --standalone "build CLI"  # Start with seed topic (positional)
   mehr plan --standalone --seed "CLI" # Start with seed topic (flag)`,
@@ -65,6 +111,13 @@ func init() {
 	planCmd.Flags().StringVarP(&planSeed, "seed", "s", "", "Initial topic for standalone planning")
 	planCmd.Flags().BoolVar(&planFullContext, "full-context", false, "Include full exploration context from previous session (default: summary only)")
 	planCmd.Flags().StringVar(&planAgentPlanning, "agent-plan", "", "Agent for planning step")
+	planCmd.Flags().StringVarP(&planTemplate, "template", "t", "", "Spec template the specification must follow")
+	planCmd.Flags().BoolVar(&planScaffold, "scaffold", false, "Write a draft specification from --template without running the agent")
+	planCmd.Flags().BoolVar(&planContinue, "continue", false, "Continue the latest planning session with the agent")
+	planCmd.Flags().StringVar(&planContext, "context", "", contextFlagUsage)
+	planCmd.Flags().BoolVar(&planJSON, "json", false, "Output the result as JSON")
+	planCmd.Flags().BoolVar(&planCritique, "critique", false, "Critique and revise the specification before marking it ready (default: workflow.spec_critique)")
+	planCmd.Flags().BoolVarP(&planInteractive, "interactive", "i", false, "Refine draft specifications interactively with the agent")
 }
 
 func runPlan(cmd *cobra.Command, args []string) error {
@@ -75,21 +128,51 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		planSeed = args[0]
 	}
 
+	if planJSON && (planStandalone || planInteractive) {
+		return errors.New("--json cannot be combined with --standalone or --interactive")
+	}
+	var jsonOut io.Writer
+	if planJSON {
+		jsonOut = redirectStdout()
+	}
+
 	// Standalone planning mode
 	if planStandalone {
 		return runStandalonePlan()
 	}
 
+	if planScaffold && planTemplate == "" {
+		return errors.New("--scaffold requires --template")
+	}
+	if planScaffold && planInteractive {
+		return errors.New("--scaffold cannot be combined with --interactive")
+	}
+
+	contextMode, err := parseContextFlag(planContext)
+	if err != nil {
+		return err
+	}
+
 	// Build conductor options using helper
 	opts := BuildConductorOptions(CommandOptions{
 		Verbose:     verbose,
 		FullContext: planFullContext,
+		Context:     contextMode,
 	})
 
 	// Per-step agent override for planning
 	if planAgentPlanning != "" {
 		opts = append(opts, conductor.WithStepAgent("planning", planAgentPlanning))
 	}
+	if planTemplate != "" {
+		opts = append(opts, conductor.WithSpecTemplate(planTemplate))
+	}
+	if planContinue {
+		opts = append(opts, conductor.WithContinueSession(true))
+	}
+	if cmd.Flags().Changed("critique") {
+		opts = append(opts, conductor.WithSpecCritique(planCritique))
+	}
 
 	// Initialize conductor with standard providers and agents
 	cond, err := initializeConductor(ctx, opts...)
@@ -99,9 +182,21 @@ func runPlan(cmd *cobra.Command, args []string) error {
 
 	// Check for active task
 	if !RequireActiveTask(cond) {
+		if jsonOut != nil {
+			return errNoActiveTask
+		}
+
 		return nil
 	}
 
+	if planScaffold {
+		if err := scaffoldSpecification(cond, planTemplate); err != nil || jsonOut == nil {
+			return err
+		}
+
+		return writePlanResult(jsonOut, cond, nil)
+	}
+
 	// Set up progress callback using helper
 	if verbose {
 		SetupVerboseEventHandlers(cond)
@@ -112,6 +207,10 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("plan: %w", err)
 	}
 
+	if planInteractive {
+		return runInteractivePlan(ctx, cond)
+	}
+
 	// Run planning with spinner in non-verbose mode
 	var planErr error
 	if verbose {
@@ -134,6 +233,13 @@ func runPlan(cmd *cobra.Command, args []string) error {
 	// Check if agent asked a question
 	if errors.Is(err, conductor.ErrPendingQuestion) {
 		q, loadErr := cond.GetWorkspace().LoadPendingQuestion(cond.GetActiveTask().ID)
+		if jsonOut != nil {
+			if loadErr != nil {
+				q = nil
+			}
+
+			return writePlanResult(jsonOut, cond, q)
+		}
 		if loadErr == nil && q != nil {
 			fmt.Println()
 			fmt.Println(display.WarningMsg("Agent has a question:"))
@@ -162,6 +268,10 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("run planning: %w", err)
 	}
 
+	if jsonOut != nil {
+		return writePlanResult(jsonOut, cond, nil)
+	}
+
 	// Get status
 	status, err := cond.Status()
 	if err != nil {
@@ -183,6 +293,199 @@ func runPlan(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// writePlanResult prints the --json document of a planning run, with the
+// agent's question when it asked one.
+func writePlanResult(out io.Writer, cond *conductor.Conductor, q *storage.PendingQuestion) error {
+	status, err := cond.Status()
+	if err != nil {
+		return err
+	}
+	result := newJSONTaskResult("plan", status)
+	result.Question = newJSONQuestion(q)
+
+	return output.JSON(out, result)
+}
+
+// scaffoldSpecification writes the next specification of the active task from
+// a spec template, leaving the sections for the user to fill in.
+func scaffoldSpecification(cond *conductor.Conductor, template string) error {
+	ws := cond.GetWorkspace()
+	work := cond.GetTaskWork()
+	if work == nil {
+		return errors.New("no task work loaded")
+	}
+
+	number, err := ws.SaveSpecificationFromTemplate(work.Metadata.ID, template, work.TemplateVars())
+	if err != nil {
+		return fmt.Errorf("scaffold specification: %w", err)
+	}
+
+	work.Metadata.SpecTemplate = template
+	if err := ws.SaveWork(work); err != nil {
+		return fmt.Errorf("save work: %w", err)
+	}
+
+	fmt.Println(display.SuccessMsg("Scaffolded specification %d from template %s", number, display.Bold(template)))
+	fmt.Printf("  File: %s\n", ws.SpecificationPath(work.Metadata.ID, number))
+
+	PrintNextSteps(
+		"Edit the specification and fill in each section",
+		"mehr plan - Let the agent refine it",
+		"mehr implement - Implement the specifications",
+	)
+
+	return nil
+}
+
+// runInteractivePlan refines the active task's draft specifications in a
+// conversation with the planning agent until the user quits.
+func runInteractivePlan(ctx context.Context, cond *conductor.Conductor) error {
+	fmt.Println(display.InfoMsg("Interactive planning for %s", display.Bold(cond.GetActiveTask().ID)))
+	fmt.Println(display.Muted("Describe what to change in the draft. /approve N marks a specification ready, /help lists commands."))
+	fmt.Println()
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print("plan> ")
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+
+		command, arg := parsePlanCommand(input)
+		switch command {
+		case "":
+			// Plain text is a planning turn
+		case "quit", "exit":
+			return endInteractivePlan(ctx, cond)
+		case "approve":
+			number, err := strconv.Atoi(arg)
+			if err != nil || number < 1 {
+				fmt.Println(display.ErrorMsg("Usage: /approve N"))
+
+				continue
+			}
+			if err := cond.ApproveSpecification(number); err != nil {
+				fmt.Println(display.ErrorMsg("Approve failed: %v", err))
+
+				continue
+			}
+			fmt.Println(display.SuccessMsg("Specification %d is ready", number))
+
+			continue
+		case "specs":
+			printPlanSpecifications(cond)
+
+			continue
+		case "help":
+			fmt.Println("\nCommands:")
+			fmt.Println("  /approve N  - Mark specification N as ready")
+			fmt.Println("  /specs      - List specifications and their status")
+			fmt.Println("  /quit       - End the session")
+			fmt.Println("  /help       - Show this help")
+			fmt.Println("\nAnything else is sent to the agent to refine the current draft.")
+			fmt.Println()
+
+			continue
+		default:
+			fmt.Println(display.ErrorMsg("Unknown command /%s (try /help)", command))
+
+			continue
+		}
+
+		spinner := display.NewSpinner("Refining specification...")
+		spinner.Start()
+		turn, err := cond.ContinuePlanning(ctx, input)
+		if err != nil {
+			spinner.StopWithError("Planning turn failed")
+			fmt.Println(display.ErrorMsg("%v", err))
+
+			continue
+		}
+		spinner.Stop()
+
+		if turn.Question != nil {
+			fmt.Println(display.WarningMsg("Agent has a question:"))
+			fmt.Printf("  %s\n", display.Bold(turn.Question.Text))
+			for i, opt := range turn.Question.Options {
+				fmt.Printf("    %s %s\n", display.Info(fmt.Sprintf("%d.", i+1)), opt.Label)
+			}
+			fmt.Println()
+
+			continue
+		}
+
+		fmt.Println(display.SuccessMsg("Updated specification %d (draft)", turn.Specification))
+		if turn.Reply != "" {
+			fmt.Printf("  %s\n", turn.Reply)
+		}
+		fmt.Println()
+	}
+
+	return endInteractivePlan(ctx, cond)
+}
+
+// endInteractivePlan closes the planning session and prints the next steps.
+func endInteractivePlan(ctx context.Context, cond *conductor.Conductor) error {
+	if err := cond.EndPlanning(ctx); err != nil {
+		return fmt.Errorf("end planning: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Println(display.SuccessMsg("Planning session ended"))
+	printPlanSpecifications(cond)
+
+	PrintNextSteps(
+		"mehr plan --interactive - Continue refining later",
+		"mehr implement - Implement the specifications",
+	)
+
+	return nil
+}
+
+// printPlanSpecifications lists the active task's specifications with status.
+func printPlanSpecifications(cond *conductor.Conductor) {
+	specs, err := cond.GetWorkspace().ListSpecificationsWithStatus(cond.GetActiveTask().ID)
+	if err != nil {
+		fmt.Println(display.ErrorMsg("List specifications: %v", err))
+
+		return
+	}
+	if len(specs) == 0 {
+		fmt.Println(display.Muted("  No specifications yet"))
+
+		return
+	}
+
+	for _, spec := range specs {
+		fmt.Printf("  %s %s %s\n", display.Info(fmt.Sprintf("%d.", spec.Number)), spec.Title, display.Muted("["+spec.Status+"]"))
+	}
+}
+
+// parsePlanCommand splits a "/command arg" REPL input. command is empty for
+// plain text that should go to the agent.
+func parsePlanCommand(input string) (command, arg string) {
+	if !strings.HasPrefix(input, "/") {
+		return "", ""
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(input, "/"))
+	if len(fields) == 0 {
+		return "", ""
+	}
+	command = strings.ToLower(fields[0])
+	if len(fields) > 1 {
+		arg = fields[1]
+	}
+
+	return command, arg
+}
+
 // runStandalonePlan runs an interactive planning session without a task.
 func runStandalonePlan() error {
 	// Get current directory as workspace root
@@ -252,6 +555,7 @@ func runStandalonePlan() error {
 			fmt.Printf("  History: %s/plan-history.md\n", ws.PlannedPath(planID))
 			fmt.Println("\nTo continue later, review the history file.")
 			fmt.Println("To create a task from this plan, copy relevant content to a task file.")
+			fmt.Printf("To run it as tasks, list them as \"- [ ] ...\" items in plan.md and run: mehr plan execute %s\n", planID)
 
 			return nil
 
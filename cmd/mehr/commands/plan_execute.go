@@ -0,0 +1,323 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/scheduler"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var (
+	planExecuteParallel  int
+	planExecuteAgent     string
+	planExecuteNoQuality bool
+	planExecuteNoPush    bool
+	planExecuteBudget    float64
+	planExecuteDryRun    bool
+)
+
+var planExecuteCmd = &cobra.Command{
+	Use:   "execute <plan-id>",
+	Short: "Run a standalone plan's items as tasks",
+	Long: `Turn each item of a standalone plan into a task and run it through the full
+workflow, like 'mehr auto': plan, implement, quality checks and finish.
+
+Plan items are the task list items of the plan document,
+.mehrhof/planned/<plan-id>/plan.md. Lines indented below an item are its
+description:
+
+  - [ ] Add the session store
+    Store sessions in redis and expire them after a day.
+  - [ ] Add the login endpoint
+
+An item can depend on others with a "depends:" line naming their numbers:
+
+  - [ ] Add the logout endpoint
+    depends: 1, 2
+
+Each item's outcome is recorded below it in plan.md, and finished items are
+checked off. Checked items are skipped, so running the plan again after a
+failure resumes with the items still open.
+
+Items run one at a time, in order, on their own branches. With --parallel N
+up to N items run at once, each in its own worktree; starting and finishing
+still take turns. An item starts only once the items it depends on are
+finished, so they are merged first. When an item fails, the items depending
+on it are skipped and the others go on; in a plan without dependencies no
+further items start, as each may build on the ones before it.
+
+With --budget no further items start once the items' agent cost reaches the
+budget, and each item may spend only what is left of it.`,
+	Example: `  mehr plan execute 2026-10-15-093000               # Run the items one by one
+  mehr plan execute 2026-10-15-093000 --parallel 3  # Up to three items at once
+  mehr plan execute 2026-10-15-093000 --budget 20   # Stop starting items after $20
+  mehr plan execute 2026-10-15-093000 --dry-run     # List the items in run order`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPlanExecute,
+}
+
+func init() {
+	planCmd.AddCommand(planExecuteCmd)
+
+	planExecuteCmd.Flags().IntVarP(&planExecuteParallel, "parallel", "j", 1, "Items to run at once (more than 1 uses worktrees)")
+	planExecuteCmd.Flags().StringVarP(&planExecuteAgent, "agent", "a", "", "Agent to use (default: auto-detect)")
+	planExecuteCmd.Flags().BoolVar(&planExecuteNoQuality, "no-quality", false, "Skip quality checks")
+	planExecuteCmd.Flags().BoolVar(&planExecuteNoPush, "no-push", false, "Don't push after merge")
+	planExecuteCmd.Flags().Float64Var(&planExecuteBudget, "budget", 0, "Start no further items once their agent cost reaches this many USD (0 = unlimited)")
+	planExecuteCmd.Flags().BoolVar(&planExecuteDryRun, "dry-run", false, "List the items that would run without running them")
+}
+
+func runPlanExecute(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	out := cmd.OutOrStdout()
+	planID := args[0]
+
+	if planExecuteParallel < 1 {
+		return errors.New("--parallel must be at least 1")
+	}
+
+	res, err := ResolveWorkspaceRoot(ctx)
+	if err != nil {
+		return err
+	}
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+	if _, err := ws.LoadPlan(planID); err != nil {
+		return fmt.Errorf("plan %s: %w", planID, err)
+	}
+	items, err := ws.LoadPlanItems(planID)
+	if err != nil {
+		return fmt.Errorf("plan %s: %w\nList the tasks as \"- [ ] ...\" items in %s", planID, err, ws.PlanDocumentPath(planID))
+	}
+
+	var pending []storage.PlanItem
+	for _, item := range items {
+		if !item.Done {
+			pending = append(pending, item)
+		}
+	}
+	if len(pending) == 0 {
+		_, _ = fmt.Fprintf(out, "All %d items of plan %s are done\n", len(items), planID)
+
+		return nil
+	}
+	jobs, err := planItemJobs(items)
+	if err != nil {
+		return fmt.Errorf("plan %s: %w", planID, err)
+	}
+	order, err := scheduler.Order(jobs)
+	if err != nil {
+		return fmt.Errorf("plan %s: %w", planID, err)
+	}
+
+	_, _ = fmt.Fprintf(out, "Plan %s: %d of %d item(s) to run\n", planID, len(pending), len(items))
+	if planExecuteDryRun {
+		for _, job := range order {
+			line := fmt.Sprintf("  %s. %s", job.ID, items[planItemIndex(job.ID)].Title)
+			if len(job.DependsOn) > 0 {
+				line += display.Muted(" (after " + strings.Join(job.DependsOn, ", ") + ")")
+			}
+			_, _ = fmt.Fprintln(out, line)
+		}
+
+		return nil
+	}
+
+	// Conductors are set up before any item starts: a started item is the
+	// workspace's active task until it finishes
+	worktree := planExecuteParallel > 1
+	runs := make(map[int]planItemTask, len(pending))
+	for _, item := range pending {
+		path, err := ws.WritePlanItemSource(planID, item)
+		if err != nil {
+			return err
+		}
+		cond, err := initializeConductor(ctx, planItemConductorOptions(worktree)...)
+		if err != nil {
+			return err
+		}
+		if active := cond.GetActiveTask(); active != nil {
+			return fmt.Errorf("task already active: %s\nUse 'mehr abandon' to clear it first, or 'mehr status' for details", active.ID)
+		}
+		runs[item.Number] = planItemTask{cond: cond, reference: "file:" + path}
+	}
+
+	autoOpts := conductor.DefaultAutoOptions()
+	autoOpts.Push = !planExecuteNoPush
+	autoOpts.CheckoutLock = &sync.Mutex{}
+	if planExecuteNoQuality {
+		autoOpts.MaxRetries = 0
+	}
+
+	run := func(ctx context.Context, item storage.PlanItem, budget float64) storage.PlanItemOutcome {
+		task := runs[item.Number]
+		opts := autoOpts
+		opts.Policy.BudgetUSD = budget
+		result, err := task.cond.RunAuto(ctx, task.reference, opts)
+		outcome := storage.PlanItemOutcome{Done: err == nil, TaskID: result.TaskID, CostUSD: result.CostUSD, At: time.Now()}
+		if err != nil {
+			outcome.FailedAt = result.FailedAt
+			outcome.Error = err.Error()
+		}
+
+		return outcome
+	}
+	record := func(item storage.PlanItem, outcome storage.PlanItemOutcome) error {
+		return ws.RecordPlanItemOutcome(planID, item.Number, outcome)
+	}
+
+	schedOpts := scheduler.Options{
+		MaxParallel: planExecuteParallel,
+		BudgetUSD:   planExecuteBudget,
+		// Without declared dependencies, later items may build on earlier ones
+		StopOnFailure: !planHasDependencies(items),
+	}
+	outcomes, err := executePlanItems(ctx, out, items, jobs, schedOpts, run, record)
+	if err != nil {
+		return fmt.Errorf("plan %s: %w", planID, err)
+	}
+
+	counts := make(map[scheduler.Status]int)
+	for _, o := range outcomes {
+		counts[o.Status]++
+	}
+	_, _ = fmt.Fprintf(out, "\nPlan %s: %d done, %d failed, %d skipped, %d not started\n", planID,
+		counts[scheduler.StatusDone], counts[scheduler.StatusFailed], counts[scheduler.StatusSkipped], counts[scheduler.StatusNotStarted])
+	_, _ = fmt.Fprintf(out, "Outcomes recorded in %s\n", ws.PlanDocumentPath(planID))
+	if failed := counts[scheduler.StatusFailed]; failed > 0 {
+		return fmt.Errorf("%d plan item(s) failed", failed)
+	}
+
+	return nil
+}
+
+// planItemTask is the conductor and task reference of one plan item.
+type planItemTask struct {
+	cond      *conductor.Conductor
+	reference string
+}
+
+// planItemConductorOptions returns the options of an unattended plan item
+// run, as for 'mehr auto'.
+func planItemConductorOptions(worktree bool) []conductor.Option {
+	opts := []conductor.Option{
+		conductor.WithVerbose(verbose),
+		conductor.WithCreateBranch(true),
+		conductor.WithUseWorktree(worktree),
+		conductor.WithAutoInit(true),
+		conductor.WithAutoMode(true),
+		conductor.WithSkipAgentQuestions(true),
+		conductor.WithStdout(getDeduplicatingStdout()),
+	}
+	if planExecuteAgent != "" {
+		opts = append(opts, conductor.WithAgent(planExecuteAgent))
+	}
+
+	return opts
+}
+
+// planItemJobs returns a scheduler job for each open plan item. Done items
+// satisfy the dependencies on them.
+func planItemJobs(items []storage.PlanItem) ([]scheduler.Job, error) {
+	var jobs []scheduler.Job
+	for _, item := range items {
+		if item.Done {
+			continue
+		}
+		job := scheduler.Job{ID: strconv.Itoa(item.Number)}
+		for _, dep := range item.DependsOn {
+			if dep > len(items) {
+				return nil, fmt.Errorf("item %d depends on unknown item %d", item.Number, dep)
+			}
+			if !items[dep-1].Done {
+				job.DependsOn = append(job.DependsOn, strconv.Itoa(dep))
+			}
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// planItemIndex returns the index in the plan's items of a job ID.
+func planItemIndex(id string) int {
+	n, _ := strconv.Atoi(id)
+
+	return n - 1
+}
+
+// planHasDependencies reports whether any plan item declares dependencies.
+func planHasDependencies(items []storage.PlanItem) bool {
+	for _, item := range items {
+		if len(item.DependsOn) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// executePlanItems runs the jobs of a plan's items with the scheduler: an
+// item starts once the items it depends on are done, so dependencies are
+// merged first. Each outcome is recorded as its item ends; items skipped
+// for a failed dependency or not started are only reported.
+func executePlanItems(
+	ctx context.Context,
+	out io.Writer,
+	items []storage.PlanItem,
+	jobs []scheduler.Job,
+	opts scheduler.Options,
+	run func(context.Context, storage.PlanItem, float64) storage.PlanItemOutcome,
+	record func(storage.PlanItem, storage.PlanItemOutcome) error,
+) ([]scheduler.Outcome, error) {
+	var mu sync.Mutex
+	outcomes, err := scheduler.Run(ctx, jobs, opts, func(ctx context.Context, job scheduler.Job, budget float64) scheduler.Result {
+		item := items[planItemIndex(job.ID)]
+		mu.Lock()
+		_, _ = fmt.Fprintf(out, "%s %d. %s\n", display.Info("→"), item.Number, item.Title)
+		mu.Unlock()
+
+		outcome := run(ctx, item, budget)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err := record(item, outcome); err != nil {
+			_, _ = fmt.Fprintln(out, display.WarningMsg("Could not record outcome of item %d: %v", item.Number, err))
+		}
+		result := scheduler.Result{CostUSD: outcome.CostUSD}
+		if outcome.Done {
+			_, _ = fmt.Fprintln(out, display.SuccessMsg("%d. %s: %s", item.Number, item.Title, outcome))
+		} else {
+			_, _ = fmt.Fprintln(out, display.ErrorMsg("%d. %s: %s", item.Number, item.Title, outcome))
+			result.Err = errors.New(outcome.String())
+		}
+
+		return result
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range outcomes {
+		if o.Status != scheduler.StatusSkipped && o.Status != scheduler.StatusNotStarted {
+			continue
+		}
+		item := items[planItemIndex(o.Job.ID)]
+		_, _ = fmt.Fprintln(out, display.Muted(fmt.Sprintf("%d. %s: %s (%v)", item.Number, item.Title, strings.ReplaceAll(string(o.Status), "_", " "), o.Err)))
+	}
+
+	return outcomes, nil
+}
@@ -0,0 +1,144 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/scheduler"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestPlanExecuteCommand_Properties(t *testing.T) {
+	if planExecuteCmd.Use != "execute <plan-id>" {
+		t.Errorf("Use = %q, want %q", planExecuteCmd.Use, "execute <plan-id>")
+	}
+	if planExecuteCmd.Parent() != planCmd {
+		t.Error("execute is not a subcommand of plan")
+	}
+
+	flag := planExecuteCmd.Flags().Lookup("parallel")
+	if flag == nil || flag.DefValue != "1" || flag.Shorthand != "j" {
+		t.Errorf("parallel flag = %+v, want default 1 and shorthand j", flag)
+	}
+	if flag := planExecuteCmd.Flags().Lookup("budget"); flag == nil || flag.DefValue != "0" {
+		t.Errorf("budget flag = %+v, want default 0", flag)
+	}
+	for _, name := range []string{"no-quality", "no-push", "dry-run"} {
+		if flag := planExecuteCmd.Flags().Lookup(name); flag == nil || flag.DefValue != "false" {
+			t.Errorf("%s flag = %+v, want bool defaulting to false", name, flag)
+		}
+	}
+}
+
+func testPlanItems(n int) []storage.PlanItem {
+	items := make([]storage.PlanItem, n)
+	for i := range items {
+		items[i] = storage.PlanItem{Number: i + 1, Title: "item"}
+	}
+
+	return items
+}
+
+func TestPlanItemJobs(t *testing.T) {
+	items := testPlanItems(3)
+	items[0].Done = true
+	items[2].DependsOn = []int{1, 2}
+
+	jobs, err := planItemJobs(items)
+	if err != nil {
+		t.Fatalf("planItemJobs: %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].ID != "2" || jobs[1].ID != "3" || !slices.Equal(jobs[1].DependsOn, []string{"2"}) {
+		t.Errorf("jobs = %+v, want 2 and 3 depending on 2 only", jobs)
+	}
+
+	items[1].DependsOn = []int{7}
+	if _, err := planItemJobs(items); err == nil {
+		t.Error("planItemJobs(unknown dependency): want error")
+	}
+}
+
+// runPlanItems runs items through executePlanItems with run, returning the
+// outcomes and the recorded item numbers.
+func runPlanItems(t *testing.T, items []storage.PlanItem, opts scheduler.Options, run func(context.Context, storage.PlanItem, float64) storage.PlanItemOutcome) ([]scheduler.Outcome, []int) {
+	t.Helper()
+
+	jobs, err := planItemJobs(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recorded []int
+	record := func(item storage.PlanItem, _ storage.PlanItemOutcome) error {
+		recorded = append(recorded, item.Number)
+
+		return nil
+	}
+
+	var buf bytes.Buffer
+	outcomes, err := executePlanItems(context.Background(), &buf, items, jobs, opts, run, record)
+	if err != nil {
+		t.Fatalf("executePlanItems: %v", err)
+	}
+	slices.Sort(recorded)
+
+	return outcomes, recorded
+}
+
+func TestExecutePlanItems_StopOnFailure(t *testing.T) {
+	var ran []int
+	run := func(_ context.Context, item storage.PlanItem, _ float64) storage.PlanItemOutcome {
+		ran = append(ran, item.Number)
+
+		return storage.PlanItemOutcome{Done: item.Number != 2, FailedAt: "implementation"}
+	}
+
+	outcomes, recorded := runPlanItems(t, testPlanItems(4), scheduler.Options{MaxParallel: 1, StopOnFailure: true}, run)
+	if !slices.Equal(ran, []int{1, 2}) || !slices.Equal(recorded, []int{1, 2}) {
+		t.Errorf("ran %v, recorded %v; want items 1 and 2 only", ran, recorded)
+	}
+	if outcomes[3].Status != scheduler.StatusNotStarted {
+		t.Errorf("item 4 status = %s, want not started", outcomes[3].Status)
+	}
+}
+
+func TestExecutePlanItems_Dependencies(t *testing.T) {
+	items := testPlanItems(4)
+	items[1].DependsOn = []int{1}
+	items[3].DependsOn = []int{3}
+
+	var mu sync.Mutex
+	var ran []int
+	run := func(_ context.Context, item storage.PlanItem, _ float64) storage.PlanItemOutcome {
+		mu.Lock()
+		ran = append(ran, item.Number)
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+
+		return storage.PlanItemOutcome{Done: item.Number != 1, CostUSD: 0.5}
+	}
+
+	outcomes, recorded := runPlanItems(t, items, scheduler.Options{MaxParallel: 2}, run)
+	if !slices.Equal(recorded, []int{1, 3, 4}) {
+		t.Errorf("recorded = %v, want 1, 3 and 4", recorded)
+	}
+	if slices.Index(ran, 4) < slices.Index(ran, 3) {
+		t.Errorf("ran = %v, want 4 after 3", ran)
+	}
+
+	statuses := make(map[string]scheduler.Status)
+	for _, o := range outcomes {
+		statuses[o.Job.ID] = o.Status
+	}
+	want := map[string]scheduler.Status{"1": scheduler.StatusFailed, "2": scheduler.StatusSkipped, "3": scheduler.StatusDone, "4": scheduler.StatusDone}
+	if !maps.Equal(statuses, want) {
+		t.Errorf("statuses = %v, want %v", statuses, want)
+	}
+}
@@ -58,6 +58,36 @@ func TestPlanCommand_Flags(t *testing.T) {
 			shorthand:    "",
 			defaultValue: "",
 		},
+		{
+			name:         "template flag",
+			flagName:     "template",
+			shorthand:    "t",
+			defaultValue: "",
+		},
+		{
+			name:         "scaffold flag",
+			flagName:     "scaffold",
+			shorthand:    "",
+			defaultValue: "false",
+		},
+		{
+			name:         "continue flag",
+			flagName:     "continue",
+			shorthand:    "",
+			defaultValue: "false",
+		},
+		{
+			name:         "interactive flag",
+			flagName:     "interactive",
+			shorthand:    "i",
+			defaultValue: "false",
+		},
+		{
+			name:         "critique flag",
+			flagName:     "critique",
+			shorthand:    "",
+			defaultValue: "false",
+		},
 	}
 
 	for _, tt := range tests {
@@ -170,3 +200,26 @@ func TestPlanCommand_Examples(t *testing.T) {
 		}
 	}
 }
+
+func TestParsePlanCommand(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantCommand string
+		wantArg     string
+	}{
+		{input: "Use PostgreSQL instead", wantCommand: "", wantArg: ""},
+		{input: "/approve 2", wantCommand: "approve", wantArg: "2"},
+		{input: "/APPROVE  3", wantCommand: "approve", wantArg: "3"},
+		{input: "/quit", wantCommand: "quit", wantArg: ""},
+		{input: "/", wantCommand: "", wantArg: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			command, arg := parsePlanCommand(tt.input)
+			if command != tt.wantCommand || arg != tt.wantArg {
+				t.Errorf("parsePlanCommand(%q) = %q, %q, want %q, %q", tt.input, command, arg, tt.wantCommand, tt.wantArg)
+			}
+		})
+	}
+}
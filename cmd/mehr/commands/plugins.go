@@ -6,8 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"slices"
 	"strings"
 	"text/tabwriter"
@@ -18,7 +16,13 @@ import (
 	"github.com/valksor/go-mehrhof/internal/storage"
 )
 
-var pluginGlobal bool // --global flag for install/remove
+var (
+	pluginGlobal    bool   // --global flag for install/remove
+	pluginNoEnable  bool   // --no-enable flag for install
+	pluginChecksum  string // --checksum flag for install/update
+	pluginSignature string // --signature flag for install/update
+	pluginPublicKey string // --public-key flag for install/update
+)
 
 var pluginsCmd = &cobra.Command{
 	Use:   "plugins",
@@ -65,18 +69,28 @@ Examples:
 var pluginsInstallCmd = &cobra.Command{
 	Use:   "install <source>",
 	Short: "Install a plugin",
-	Long: `Install a plugin from a git repository or local path.
+	Long: `Install a plugin from a git repository, an archive or a local path.
 
 Sources:
   - Git URL: https://github.com/user/mehrhof-jira
+  - Archive: https://example.com/jira-1.2.0.tar.gz, ./jira.zip
   - Local path: ./my-plugin
 
+The plugin is installed under the name in its plugin.yaml, after the
+manifest is validated, and enabled in .mehrhof/config.yaml. Archives can be
+checked against a SHA-256 checksum and an ed25519 signature first.
+
 Flags:
-  --global    Install to ~/.mehrhof/plugins/ (default: .mehrhof/plugins/)
+  --global       Install to ~/.mehrhof/plugins/ (default: .mehrhof/plugins/)
+  --no-enable    Do not add the plugin to plugins.enabled
+  --checksum     Expected SHA-256 of the archive
+  --signature    Path or URL of the archive's ed25519 signature
+  --public-key   Base64 ed25519 key to check the signature with
 
 Examples:
   mehr plugins install https://github.com/user/mehrhof-jira
-  mehr plugins install ./my-plugin --global`,
+  mehr plugins install ./my-plugin --global
+  mehr plugins install https://example.com/jira.tar.gz --checksum sha256:9f86d0...`,
 	Args: cobra.ExactArgs(1),
 	RunE: runPluginsInstall,
 }
@@ -86,8 +100,8 @@ var pluginsRemoveCmd = &cobra.Command{
 	Short: "Remove a plugin",
 	Long: `Remove an installed plugin by name.
 
-This deletes the plugin directory. Make sure to also remove
-the plugin from 'plugins.enabled' in config.yaml.
+This deletes the plugin directory and removes the plugin from
+'plugins.enabled' in config.yaml.
 
 Flags:
   --global    Remove from ~/.mehrhof/plugins/ (default: .mehrhof/plugins/)
@@ -99,6 +113,28 @@ Examples:
 	RunE: runPluginsRemove,
 }
 
+var pluginsUpdateCmd = &cobra.Command{
+	Use:   "update [name]",
+	Short: "Update installed plugins",
+	Long: `Reinstall a plugin from the source it was installed from.
+
+Without a name, updates every plugin installed with 'mehr plugins install';
+plugins placed by hand are skipped.
+
+Updates are verified the way the plugin was installed: a signed plugin is
+checked against the public key it was installed with, using the signature
+at the recorded location unless --signature names another. A plugin
+installed with only a checksum needs --checksum for the new archive. The
+verification flags belong to one archive, so they need a plugin name.
+
+Examples:
+  mehr plugins update jira
+  mehr plugins update jira --checksum sha256:9f86d0...
+  mehr plugins update`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPluginsUpdate,
+}
+
 var pluginsValidateCmd = &cobra.Command{
 	Use:   "validate [name]",
 	Short: "Validate plugin manifest and connectivity",
@@ -127,12 +163,20 @@ func init() {
 	rootCmd.AddCommand(pluginsCmd)
 	pluginsCmd.AddCommand(pluginsListCmd)
 	pluginsCmd.AddCommand(pluginsInstallCmd)
+	pluginsCmd.AddCommand(pluginsUpdateCmd)
 	pluginsCmd.AddCommand(pluginsRemoveCmd)
 	pluginsCmd.AddCommand(pluginsValidateCmd)
 	pluginsCmd.AddCommand(pluginsInfoCmd)
 
 	pluginsInstallCmd.Flags().BoolVar(&pluginGlobal, "global", false, "Install to global plugins directory")
+	pluginsInstallCmd.Flags().BoolVar(&pluginNoEnable, "no-enable", false, "Do not enable the plugin in config.yaml")
 	pluginsRemoveCmd.Flags().BoolVar(&pluginGlobal, "global", false, "Remove from global plugins directory")
+
+	for _, cmd := range []*cobra.Command{pluginsInstallCmd, pluginsUpdateCmd} {
+		cmd.Flags().StringVar(&pluginChecksum, "checksum", "", "Expected SHA-256 of the archive")
+		cmd.Flags().StringVar(&pluginSignature, "signature", "", "Path or URL of the archive's ed25519 signature")
+		cmd.Flags().StringVar(&pluginPublicKey, "public-key", "", "Base64 ed25519 key to check the signature with")
+	}
 }
 
 func runPluginsList(cmd *cobra.Command, args []string) error {
@@ -210,106 +254,143 @@ func runPluginsList(cmd *cobra.Command, args []string) error {
 }
 
 func runPluginsInstall(cmd *cobra.Command, args []string) error {
-	source := args[0]
-
-	// Determine target directory
-	var targetDir string
-	if pluginGlobal {
-		globalDir, err := plugin.DefaultGlobalDir()
-		if err != nil {
-			return fmt.Errorf("get global plugins dir: %w", err)
-		}
-		targetDir = globalDir
-	} else {
-		targetDir = plugin.DefaultProjectDir(".")
+	targetDir, err := pluginTargetDir()
+	if err != nil {
+		return err
 	}
 
-	// Ensure target directory exists
-	if err := plugin.EnsureDir(targetDir); err != nil {
-		return fmt.Errorf("create plugins directory: %w", err)
-	}
+	fmt.Printf("Installing plugin from %s...\n", args[0])
 
-	// Check if source is a git URL or local path
-	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "git@") {
-		return installFromGit(source, targetDir)
+	manifest, err := plugin.Install(cmd.Context(), args[0], targetDir, pluginInstallOptions())
+	if err != nil {
+		return err
 	}
 
-	return installFromLocal(source, targetDir)
-}
-
-func installFromGit(url, targetDir string) error {
-	// Extract plugin name from URL
-	name := filepath.Base(url)
-	name = strings.TrimSuffix(name, ".git")
+	fmt.Printf("Plugin '%s' (%s) installed to %s.\n", manifest.Name, manifest.Version, manifest.Dir)
 
-	// Remove "mehrhof-" prefix if present
-	name = strings.TrimPrefix(name, "mehrhof-")
+	if pluginNoEnable {
+		fmt.Printf("Enable it by adding '%s' to plugins.enabled in .mehrhof/config.yaml\n", manifest.Name)
 
-	pluginDir := filepath.Join(targetDir, name)
+		return nil
+	}
 
-	// Check if already installed
-	if _, err := os.Stat(pluginDir); err == nil {
-		return fmt.Errorf("plugin %s already installed at %s", name, pluginDir)
+	enabled, err := setPluginEnabled(manifest.Name, true)
+	if err != nil {
+		return err
+	}
+	if enabled {
+		fmt.Printf("Enabled '%s' in .mehrhof/config.yaml.\n", manifest.Name)
+	} else {
+		fmt.Printf("No workspace config here; enable it by adding '%s' to plugins.enabled in .mehrhof/config.yaml\n", manifest.Name)
 	}
 
-	fmt.Printf("Installing plugin '%s' from %s...\n", name, url)
+	return nil
+}
 
-	// Clone repository
-	gitCmd := exec.CommandContext(context.Background(), "git", "clone", "--depth", "1", url, pluginDir)
-	gitCmd.Stdout = os.Stdout
-	gitCmd.Stderr = os.Stderr
+func runPluginsUpdate(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 && (pluginChecksum != "" || pluginSignature != "" || pluginPublicKey != "") {
+		return errors.New("--checksum, --signature and --public-key apply to one plugin; name the plugin to update")
+	}
 
-	if err := gitCmd.Run(); err != nil {
-		return fmt.Errorf("git clone failed: %w", err)
+	discovery, err := getPluginDiscovery()
+	if err != nil {
+		return err
 	}
 
-	// Validate the installed plugin
-	manifestPath := filepath.Join(pluginDir, plugin.ManifestFileName)
-	if _, err := plugin.LoadManifest(manifestPath); err != nil {
-		// Clean up on validation failure
-		_ = os.RemoveAll(pluginDir)
+	var manifests []*plugin.Manifest
+	if len(args) > 0 {
+		manifest, err := discovery.DiscoverByName(args[0])
+		if err != nil {
+			return fmt.Errorf("find plugin: %w", err)
+		}
+		if manifest == nil {
+			return fmt.Errorf("plugin '%s' not found", args[0])
+		}
+		manifests = append(manifests, manifest)
+	} else {
+		manifests, err = discovery.Discover()
+		if err != nil {
+			return fmt.Errorf("discover plugins: %w", err)
+		}
+		slices.SortFunc(manifests, func(a, b *plugin.Manifest) int {
+			return cmp.Compare(a.Name, b.Name)
+		})
+	}
 
-		return fmt.Errorf("invalid plugin (missing or invalid plugin.yaml): %w", err)
+	var failed int
+	for _, m := range manifests {
+		updated, err := plugin.Update(cmd.Context(), m, pluginInstallOptions())
+		switch {
+		case errors.Is(err, plugin.ErrNoInstallRecord) && len(args) == 0:
+			fmt.Printf("  %s: skipped (not installed with 'mehr plugins install')\n", m.Name)
+		case err != nil:
+			fmt.Printf("  %s: %v\n", m.Name, err)
+			failed++
+		case updated.Version == m.Version:
+			fmt.Printf("  %s: %s (reinstalled)\n", m.Name, updated.Version)
+		default:
+			fmt.Printf("  %s: %s -> %s\n", m.Name, m.Version, updated.Version)
+		}
 	}
 
-	fmt.Printf("Plugin '%s' installed successfully.\n", name)
-	fmt.Printf("Enable it by adding '%s' to plugins.enabled in .mehrhof/config.yaml\n", name)
+	if failed > 0 {
+		return fmt.Errorf("%d plugin(s) failed to update", failed)
+	}
 
 	return nil
 }
 
-func installFromLocal(sourcePath, targetDir string) error {
-	// Resolve source path
-	absSource, err := filepath.Abs(sourcePath)
-	if err != nil {
-		return fmt.Errorf("resolve source path: %w", err)
-	}
+// pluginTargetDir returns the directory install writes to.
+func pluginTargetDir() (string, error) {
+	if pluginGlobal {
+		globalDir, err := plugin.DefaultGlobalDir()
+		if err != nil {
+			return "", fmt.Errorf("get global plugins dir: %w", err)
+		}
 
-	// Validate source has a manifest
-	sourceManifest := filepath.Join(absSource, plugin.ManifestFileName)
-	manifest, err := plugin.LoadManifest(sourceManifest)
-	if err != nil {
-		return fmt.Errorf("invalid plugin source: %w", err)
+		return globalDir, nil
 	}
 
-	pluginDir := filepath.Join(targetDir, manifest.Name)
+	return plugin.DefaultProjectDir("."), nil
+}
 
-	// Check if already installed
-	if _, err := os.Stat(pluginDir); err == nil {
-		return fmt.Errorf("plugin %s already installed at %s", manifest.Name, pluginDir)
+// pluginInstallOptions returns the archive verification flags.
+func pluginInstallOptions() plugin.InstallOptions {
+	return plugin.InstallOptions{
+		Checksum:  pluginChecksum,
+		Signature: pluginSignature,
+		PublicKey: pluginPublicKey,
 	}
+}
 
-	fmt.Printf("Installing plugin '%s' from %s...\n", manifest.Name, absSource)
+// setPluginEnabled adds or removes name in plugins.enabled of the
+// workspace config. It reports false when there is no workspace config.
+func setPluginEnabled(name string, enabled bool) (bool, error) {
+	ws, err := storage.OpenWorkspace(".", nil)
+	if err != nil || !ws.HasConfig() {
+		return false, nil //nolint:nilerr // No workspace to enable the plugin in
+	}
 
-	// Copy directory
-	if err := copyDir(absSource, pluginDir); err != nil {
-		return fmt.Errorf("copy plugin: %w", err)
+	cfg, err := ws.LoadSharedConfig()
+	if err != nil {
+		return false, fmt.Errorf("load config: %w", err)
 	}
 
-	fmt.Printf("Plugin '%s' installed successfully.\n", manifest.Name)
-	fmt.Printf("Enable it by adding '%s' to plugins.enabled in .mehrhof/config.yaml\n", manifest.Name)
+	listed := slices.Contains(cfg.Plugins.Enabled, name)
+	switch {
+	case enabled && !listed:
+		cfg.Plugins.Enabled = append(cfg.Plugins.Enabled, name)
+	case !enabled && listed:
+		cfg.Plugins.Enabled = slices.DeleteFunc(cfg.Plugins.Enabled, func(n string) bool { return n == name })
+	default:
+		return true, nil
+	}
 
-	return nil
+	if err := ws.SaveConfig(cfg); err != nil {
+		return false, fmt.Errorf("save config: %w", err)
+	}
+
+	return true, nil
 }
 
 func runPluginsRemove(cmd *cobra.Command, args []string) error {
@@ -344,7 +425,14 @@ func runPluginsRemove(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("Plugin '%s' removed.\n", name)
-	fmt.Println("Remember to remove it from plugins.enabled in .mehrhof/config.yaml if present.")
+
+	// Keep it enabled while the other scope still provides it
+	if other, _ := discovery.DiscoverByName(name); other != nil {
+		return nil
+	}
+	if _, err := setPluginEnabled(name, false); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -550,31 +638,3 @@ func getPluginDiscovery() (*plugin.Discovery, error) {
 
 	return plugin.NewDiscovery(globalDir, projectDir), nil
 }
-
-// copyDir recursively copies a directory.
-func copyDir(src, dst string) error {
-	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Calculate destination path
-		relPath, err := filepath.Rel(src, path)
-		if err != nil {
-			return err
-		}
-		dstPath := filepath.Join(dst, relPath)
-
-		if info.IsDir() {
-			return os.MkdirAll(dstPath, info.Mode())
-		}
-
-		// Copy file
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return err
-		}
-
-		return os.WriteFile(dstPath, data, info.Mode())
-	})
-}
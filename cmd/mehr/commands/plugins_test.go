@@ -4,7 +4,13 @@
 package commands
 
 import (
+	"slices"
+	"strings"
 	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
 )
 
 func TestPluginsCommand_Properties(t *testing.T) {
@@ -164,7 +170,7 @@ func TestPluginsCommand_HasSubcommands(t *testing.T) {
 		t.Errorf("plugins command has %d subcommands, want at least 5", len(subcommands))
 	}
 
-	expectedSubcommands := []string{"list", "install <source>", "remove <name>", "validate [name]", "info <name>"}
+	expectedSubcommands := []string{"list", "install <source>", "update [name]", "remove <name>", "validate [name]", "info <name>"}
 	for _, exp := range expectedSubcommands {
 		found := false
 		for _, cmd := range subcommands {
@@ -239,3 +245,59 @@ func TestPluginsValidateCommand_Examples(t *testing.T) {
 		}
 	}
 }
+
+func TestPluginsInstallCommand_VerificationFlags(t *testing.T) {
+	for _, c := range []*cobra.Command{pluginsInstallCmd, pluginsUpdateCmd} {
+		for _, name := range []string{"checksum", "signature", "public-key"} {
+			if c.Flags().Lookup(name) == nil {
+				t.Errorf("%s: %s flag not found", c.Name(), name)
+			}
+		}
+	}
+	if pluginsInstallCmd.Flags().Lookup("no-enable") == nil {
+		t.Error("install: no-enable flag not found")
+	}
+}
+
+func TestSetPluginEnabled(t *testing.T) {
+	tc := NewTestContext(t)
+	if err := tc.SaveWorkspaceConfig(storage.NewDefaultWorkspaceConfig()); err != nil {
+		t.Fatal(err)
+	}
+
+	enabled := func() []string {
+		t.Helper()
+		cfg, err := tc.GetWorkspaceConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return cfg.Plugins.Enabled
+	}
+
+	for range 2 {
+		if ok, err := setPluginEnabled("jira", true); err != nil || !ok {
+			t.Fatalf("setPluginEnabled(true) = %v, %v", ok, err)
+		}
+	}
+	if got := enabled(); !slices.Equal(got, []string{"jira"}) {
+		t.Errorf("enabled = %v, want [jira]", got)
+	}
+
+	if _, err := setPluginEnabled("jira", false); err != nil {
+		t.Fatal(err)
+	}
+	if got := enabled(); len(got) != 0 {
+		t.Errorf("enabled after disable = %v, want none", got)
+	}
+}
+
+func TestPluginsUpdateCommand_VerificationNeedsName(t *testing.T) {
+	pluginChecksum = "sha256:9f86d0"
+	t.Cleanup(func() { pluginChecksum = "" })
+
+	err := runPluginsUpdate(pluginsUpdateCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "name the plugin") {
+		t.Errorf("update --checksum without a name = %v, want an error", err)
+	}
+}
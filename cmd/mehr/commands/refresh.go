@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var refreshDiff bool
+
+var refreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Re-fetch the active task's source and detect requirement changes",
+	Long: `Re-fetch the active task's source from its provider and store it as a new
+revision in the work directory.
+
+Only changed files are processed. For directory sources, files whose
+modification time and size are unchanged are not read at all; other files are
+compared by content hash. The added, modified and removed files are recorded
+in work.yaml.
+
+The snapshot taken at start is kept in source-original/. When the source no
+longer matches it, the difference is saved to source-drift.diff and every
+following planning and implementation prompt warns the agent that the
+requirements changed. Re-run 'mehr plan' to bring the specifications in line.`,
+	Example: `  mehr refresh          # Re-fetch the source
+  mehr refresh --diff   # Also print the changes since the task started`,
+	Args: cobra.NoArgs,
+	RunE: runRefresh,
+}
+
+func init() {
+	rootCmd.AddCommand(refreshCmd)
+	refreshCmd.Flags().BoolVar(&refreshDiff, "diff", false, "Print the source changes since the task started")
+}
+
+func runRefresh(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	out := cmd.OutOrStdout()
+
+	cond, err := initializeConductor(ctx, BuildConductorOptions(CommandOptions{Verbose: verbose})...)
+	if err != nil {
+		return err
+	}
+	if !RequireActiveTask(cond) {
+		return nil
+	}
+
+	delta, err := cond.RefreshSource(ctx)
+	if err != nil {
+		return err
+	}
+	printSourceDelta(out, delta)
+
+	return printSourceDrift(out, cond, refreshDiff)
+}
+
+// printSourceDelta lists the files changed by a source refresh.
+func printSourceDelta(out io.Writer, delta *storage.SourceDelta) {
+	if delta.Empty() {
+		_, _ = fmt.Fprintln(out, display.SuccessMsg("Source is up to date"))
+
+		return
+	}
+
+	_, _ = fmt.Fprintln(out, display.SuccessMsg("Source updated to revision %d", delta.Revision))
+	for _, change := range []struct {
+		label string
+		files []string
+	}{
+		{"Added", delta.Added},
+		{"Modified", delta.Modified},
+		{"Removed", delta.Removed},
+	} {
+		for _, f := range change.files {
+			_, _ = fmt.Fprintf(out, "  %-9s %s\n", change.label+":", f)
+		}
+	}
+}
+
+// printSourceDrift warns when the source differs from the snapshot taken at
+// start, optionally with the diff.
+func printSourceDrift(out io.Writer, cond *conductor.Conductor, withDiff bool) error {
+	work := cond.GetTaskWork()
+	if work == nil || work.Source.Drift == nil {
+		return nil
+	}
+
+	drift := work.Source.Drift
+	_, _ = fmt.Fprintln(out)
+	_, _ = fmt.Fprintln(out, display.WarningMsg("Requirements changed since the task started (%s)", strings.Join(drift.Files, ", ")))
+	_, _ = fmt.Fprintln(out, "  Planning and implementation prompts now include the changes.")
+	_, _ = fmt.Fprintf(out, "  Run %s to update the specifications.\n", display.Cyan("mehr plan"))
+	if !withDiff {
+		return nil
+	}
+
+	diff, err := cond.GetWorkspace().SourceDriftDiff(work.Metadata.ID)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(out)
+	_, _ = fmt.Fprint(out, diff)
+
+	return nil
+}
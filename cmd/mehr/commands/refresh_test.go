@@ -0,0 +1,48 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestRefreshCommand_Properties(t *testing.T) {
+	if refreshCmd.Use != "refresh" {
+		t.Errorf("Use = %q, want %q", refreshCmd.Use, "refresh")
+	}
+	if refreshCmd.Short == "" || refreshCmd.Long == "" {
+		t.Error("description is empty")
+	}
+	if refreshCmd.RunE == nil {
+		t.Error("RunE not set")
+	}
+
+	flag := refreshCmd.Flags().Lookup("diff")
+	if flag == nil {
+		t.Fatal("diff flag not found")
+	}
+	if flag.DefValue != "false" {
+		t.Errorf("diff default = %q, want %q", flag.DefValue, "false")
+	}
+}
+
+func TestPrintSourceDelta(t *testing.T) {
+	var buf bytes.Buffer
+	printSourceDelta(&buf, &storage.SourceDelta{})
+	if !strings.Contains(buf.String(), "Source is up to date") {
+		t.Errorf("empty delta output = %q", buf.String())
+	}
+
+	buf.Reset()
+	printSourceDelta(&buf, &storage.SourceDelta{Revision: 3, Added: []string{"new.md"}, Removed: []string{"old.md"}})
+	for _, want := range []string{"revision 3", "Added:    new.md", "Removed:  old.md"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
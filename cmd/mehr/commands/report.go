@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/digest"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var (
+	reportSince  string
+	reportOutput string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarize recent activity as a markdown digest",
+	Long: `Summarize the workspace activity of the last week (or --since) as markdown,
+ready to paste into a standup or status document:
+
+  - Tasks finished in the period, with their pull requests
+  - Tasks in progress
+  - Pull requests opened
+  - Agent cost and tokens
+  - The tasks that took the most agent session time
+
+Subcommands report across finished tasks, for retrospectives.`,
+	Example: `  mehr report                      # Last 7 days
+  mehr report --since 14d
+  mehr report --since 2026-10-01 -o digest.md
+  mehr report estimates            # Estimated vs. actual effort`,
+	Args: cobra.NoArgs,
+	RunE: runReport,
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().StringVar(&reportSince, "since", "7d", "Start of the period: a duration back from now (7d, 2w, 12h) or a date (YYYY-MM-DD)")
+	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "", "Write to a file instead of stdout")
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+	since, err := parseSince(reportSince, now)
+	if err != nil {
+		return err
+	}
+
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+
+	d, err := digest.Build(ws, since, now)
+	if err != nil {
+		return err
+	}
+
+	if reportOutput != "" {
+		if err := os.WriteFile(reportOutput, []byte(d.Markdown()), 0o644); err != nil {
+			return fmt.Errorf("write report: %w", err)
+		}
+
+		return nil
+	}
+	_, err = fmt.Fprint(cmd.OutOrStdout(), d.Markdown())
+
+	return err
+}
+
+// parseSince parses the start of a report period: a number of days (7d),
+// weeks (2w) or hours (12h) back from now, or a local date (YYYY-MM-DD).
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if date, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return date, nil
+	}
+
+	if len(s) > 1 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err == nil && n > 0 {
+			switch strings.ToLower(s[len(s)-1:]) {
+			case "h":
+				return now.Add(-time.Duration(n) * time.Hour), nil
+			case "d":
+				return now.AddDate(0, 0, -n), nil
+			case "w":
+				return now.AddDate(0, 0, -7*n), nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a duration such as 7d, 2w or 12h, or a date (YYYY-MM-DD)", s)
+}
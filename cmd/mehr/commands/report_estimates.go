@@ -0,0 +1,249 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var (
+	reportEstimatesFormat string
+	reportEstimatesSince  string
+	reportEstimatesOutput string
+)
+
+var reportEstimatesCmd = &cobra.Command{
+	Use:   "estimates",
+	Short: "Compare estimated and actual effort of finished tasks",
+	Long: `Compare the estimate of each finished task with what it actually took.
+
+Estimates come from the task source: the 'estimate:' frontmatter field of
+file tasks (e.g. "2h30m", "1d"; a day is 8 hours) or the original estimate
+of Jira and GitLab issues. Actual time is the wall-clock time of the task's
+agent sessions; tokens and cost are the task's recorded usage.
+
+The ratio is actual time divided by the estimate: above 1 the task took
+longer than planned.`,
+	Example: `  mehr report estimates
+  mehr report estimates --since 30d
+  mehr report estimates --format csv -o sprint.csv`,
+	Args: cobra.NoArgs,
+	RunE: runReportEstimates,
+}
+
+func init() {
+	reportCmd.AddCommand(reportEstimatesCmd)
+
+	reportEstimatesCmd.Flags().StringVar(&reportEstimatesFormat, "format", "table", "Output format (table, csv, json)")
+	reportEstimatesCmd.Flags().StringVar(&reportEstimatesSince, "since", "", "Only include tasks finished since then: a duration back from now (30d, 2w) or a date (YYYY-MM-DD)")
+	reportEstimatesCmd.Flags().StringVarP(&reportEstimatesOutput, "output", "o", "", "Write to a file instead of stdout")
+}
+
+// estimateRow compares the estimate of one finished task with its actuals.
+type estimateRow struct {
+	TaskID          string    `json:"task_id"`
+	Title           string    `json:"title,omitempty"`
+	ExternalKey     string    `json:"external_key,omitempty"`
+	TaskType        string    `json:"task_type,omitempty"`
+	FinishedAt      time.Time `json:"finished_at"`
+	EstimateMinutes int       `json:"estimate_minutes,omitempty"` // 0 when not estimated
+	ActualMinutes   int       `json:"actual_minutes"`
+	Ratio           float64   `json:"ratio,omitempty"` // Actual / estimate
+	Tokens          int       `json:"tokens"`
+	CostUSD         float64   `json:"cost_usd"`
+}
+
+var estimateCSVHeader = []string{
+	"task_id", "title", "external_key", "task_type", "finished_at",
+	"estimate_minutes", "actual_minutes", "ratio", "tokens", "cost_usd",
+}
+
+func runReportEstimates(cmd *cobra.Command, args []string) error {
+	if !slices.Contains([]string{"table", "csv", "json"}, reportEstimatesFormat) {
+		return fmt.Errorf("unsupported format %q (use table, csv or json)", reportEstimatesFormat)
+	}
+	var since time.Time
+	if reportEstimatesSince != "" {
+		var err error
+		if since, err = parseSince(reportEstimatesSince, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+
+	rows, err := collectEstimateRows(ws, since)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if reportEstimatesOutput != "" {
+		f, err := os.Create(reportEstimatesOutput)
+		if err != nil {
+			return fmt.Errorf("create output file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+
+	switch reportEstimatesFormat {
+	case "csv":
+		return writeEstimatesCSV(out, rows)
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(rows)
+	default:
+		writeEstimatesTable(out, rows)
+
+		return nil
+	}
+}
+
+// collectEstimateRows builds a row for each task finished on or after since,
+// ordered by finish time.
+func collectEstimateRows(ws *storage.Workspace, since time.Time) ([]estimateRow, error) {
+	works, err := ws.LoadWorks()
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+
+	rows := []estimateRow{}
+	for _, work := range works {
+		if work.Metadata.FinishedAt.IsZero() || work.Metadata.FinishedAt.Before(since) {
+			continue
+		}
+		sessions, err := ws.ListSessions(work.Metadata.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list sessions for %s: %w", work.Metadata.ID, err)
+		}
+
+		row := estimateRow{
+			TaskID:        work.Metadata.ID,
+			Title:         work.Metadata.Title,
+			ExternalKey:   work.Metadata.ExternalKey,
+			TaskType:      work.Metadata.TaskType,
+			FinishedAt:    work.Metadata.FinishedAt,
+			ActualMinutes: int(storage.TotalTime(storage.PhaseTimes(sessions)).Round(time.Minute).Minutes()),
+			Tokens:        work.Costs.TotalInputTokens + work.Costs.TotalOutputTokens,
+			CostUSD:       work.Costs.TotalCostUSD,
+		}
+		if work.Metadata.Estimate != "" {
+			if estimate, err := provider.ParseEstimate(work.Metadata.Estimate); err == nil {
+				row.EstimateMinutes = int(estimate.Round(time.Minute).Minutes())
+			}
+		}
+		if row.EstimateMinutes > 0 {
+			row.Ratio = float64(row.ActualMinutes) / float64(row.EstimateMinutes)
+		}
+		rows = append(rows, row)
+	}
+
+	slices.SortStableFunc(rows, func(a, b estimateRow) int { return a.FinishedAt.Compare(b.FinishedAt) })
+
+	return rows, nil
+}
+
+func writeEstimatesTable(w io.Writer, rows []estimateRow) {
+	if len(rows) == 0 {
+		_, _ = fmt.Fprintln(w, "No finished tasks found.")
+
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "TASK\tTITLE\tFINISHED\tESTIMATE\tACTUAL\tRATIO\tTOKENS\tCOST")
+	var estimated, actual int
+	for _, row := range rows {
+		task := row.TaskID
+		if row.ExternalKey != "" {
+			task = row.ExternalKey
+		}
+		title := row.Title
+		if len(title) > 35 {
+			title = title[:32] + "..."
+		}
+		estimate, ratio := "-", "-"
+		if row.EstimateMinutes > 0 {
+			estimate = storage.FormatMinutes(time.Duration(row.EstimateMinutes) * time.Minute)
+			ratio = fmt.Sprintf("%.2fx", row.Ratio)
+			estimated += row.EstimateMinutes
+			actual += row.ActualMinutes
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			task,
+			title,
+			row.FinishedAt.Local().Format(time.DateOnly),
+			estimate,
+			storage.FormatMinutes(time.Duration(row.ActualMinutes)*time.Minute),
+			ratio,
+			formatNumber(row.Tokens),
+			formatCost(row.CostUSD),
+		)
+	}
+	_ = tw.Flush()
+
+	if estimated > 0 {
+		_, _ = fmt.Fprintf(w, "\nEstimated tasks took %s against %s planned (%.2fx).\n",
+			storage.FormatMinutes(time.Duration(actual)*time.Minute),
+			storage.FormatMinutes(time.Duration(estimated)*time.Minute),
+			float64(actual)/float64(estimated))
+	}
+}
+
+func writeEstimatesCSV(w io.Writer, rows []estimateRow) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(estimateCSVHeader); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+
+	for _, row := range rows {
+		ratio := ""
+		if row.EstimateMinutes > 0 {
+			ratio = strconv.FormatFloat(row.Ratio, 'f', 2, 64)
+		}
+		record := []string{
+			row.TaskID,
+			row.Title,
+			row.ExternalKey,
+			row.TaskType,
+			row.FinishedAt.UTC().Format(time.RFC3339),
+			strconv.Itoa(row.EstimateMinutes),
+			strconv.Itoa(row.ActualMinutes),
+			ratio,
+			strconv.Itoa(row.Tokens),
+			strconv.FormatFloat(row.CostUSD, 'f', 6, 64),
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("write csv: %w", err)
+		}
+	}
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+
+	return nil
+}
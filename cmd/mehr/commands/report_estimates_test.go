@@ -0,0 +1,145 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestReportEstimatesCommand_Flags(t *testing.T) {
+	tests := []struct {
+		name         string
+		flagName     string
+		shorthand    string
+		defaultValue string
+	}{
+		{name: "format flag", flagName: "format", defaultValue: "table"},
+		{name: "since flag", flagName: "since", defaultValue: ""},
+		{name: "output flag", flagName: "output", shorthand: "o", defaultValue: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flag := reportEstimatesCmd.Flags().Lookup(tt.flagName)
+			if flag == nil {
+				t.Fatalf("flag %q not found", tt.flagName)
+			}
+			if flag.Shorthand != tt.shorthand {
+				t.Errorf("shorthand = %q, want %q", flag.Shorthand, tt.shorthand)
+			}
+			if flag.DefValue != tt.defaultValue {
+				t.Errorf("default = %q, want %q", flag.DefValue, tt.defaultValue)
+			}
+		})
+	}
+}
+
+// newEstimateWorkspace creates a workspace with two finished tasks, one of
+// them estimated, and one unfinished task.
+func newEstimateWorkspace(t *testing.T) *storage.Workspace {
+	t.Helper()
+
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	tasks := []struct {
+		id, estimate string
+		minutes      int
+		finished     time.Time
+	}{
+		{"estimated", "2h", 150, start.Add(48 * time.Hour)},
+		{"unestimated", "", 30, start.Add(24 * time.Hour)},
+		{"open", "1h", 10, time.Time{}},
+	}
+	for _, task := range tasks {
+		work, err := ws.CreateWork(task.id, storage.SourceInfo{Type: "file", Ref: task.id + ".md"})
+		if err != nil {
+			t.Fatalf("CreateWork: %v", err)
+		}
+		work.Metadata.Title = "Task " + task.id
+		work.Metadata.Estimate = task.estimate
+		work.Metadata.FinishedAt = task.finished
+		work.Costs = storage.CostStats{TotalInputTokens: 1000, TotalOutputTokens: 200, TotalCostUSD: 0.5}
+		if err := ws.SaveWork(work); err != nil {
+			t.Fatalf("SaveWork: %v", err)
+		}
+
+		session, filename, err := ws.CreateSession(task.id, "implementing", "claude", "implementing")
+		if err != nil {
+			t.Fatalf("CreateSession: %v", err)
+		}
+		session.Metadata.StartedAt = start
+		session.Metadata.EndedAt = start.Add(time.Duration(task.minutes) * time.Minute)
+		if err := ws.SaveSession(task.id, filename, session); err != nil {
+			t.Fatalf("SaveSession: %v", err)
+		}
+	}
+
+	return ws
+}
+
+func TestCollectEstimateRows(t *testing.T) {
+	ws := newEstimateWorkspace(t)
+
+	rows, err := collectEstimateRows(ws, time.Time{})
+	if err != nil {
+		t.Fatalf("collectEstimateRows: %v", err)
+	}
+	if len(rows) != 2 || rows[0].TaskID != "unestimated" || rows[1].TaskID != "estimated" {
+		t.Fatalf("rows = %+v, want finished tasks by finish time", rows)
+	}
+	if rows[0].EstimateMinutes != 0 || rows[0].Ratio != 0 || rows[0].ActualMinutes != 30 {
+		t.Errorf("unestimated row = %+v", rows[0])
+	}
+	est := rows[1]
+	if est.EstimateMinutes != 120 || est.ActualMinutes != 150 || est.Ratio != 1.25 || est.Tokens != 1200 || est.CostUSD != 0.5 {
+		t.Errorf("estimated row = %+v", est)
+	}
+
+	since, err := collectEstimateRows(ws, time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("collectEstimateRows since: %v", err)
+	}
+	if len(since) != 1 || since[0].TaskID != "estimated" {
+		t.Errorf("since rows = %+v", since)
+	}
+
+	var table bytes.Buffer
+	writeEstimatesTable(&table, rows)
+	for _, want := range []string{"ESTIMATE", "2h0m", "2h30m", "1.25x", "took 2h30m against 2h0m planned (1.25x)"} {
+		if !strings.Contains(table.String(), want) {
+			t.Errorf("table missing %q:\n%s", want, table.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := writeEstimatesCSV(&buf, rows); err != nil {
+		t.Fatalf("writeEstimatesCSV: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(records) != 3 || len(records[0]) != len(estimateCSVHeader) {
+		t.Fatalf("csv = %v", records)
+	}
+	if got := records[2]; got[0] != "estimated" || got[5] != "120" || got[6] != "150" || got[7] != "1.25" {
+		t.Errorf("csv row = %v", got)
+	}
+	if records[1][7] != "" {
+		t.Errorf("unestimated ratio = %q, want empty", records[1][7])
+	}
+}
@@ -0,0 +1,69 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReportCommand_Flags(t *testing.T) {
+	tests := []struct {
+		name         string
+		flagName     string
+		shorthand    string
+		defaultValue string
+	}{
+		{name: "since flag", flagName: "since", defaultValue: "7d"},
+		{name: "output flag", flagName: "output", shorthand: "o", defaultValue: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flag := reportCmd.Flags().Lookup(tt.flagName)
+			if flag == nil {
+				t.Fatalf("flag %q not found", tt.flagName)
+			}
+			if flag.Shorthand != tt.shorthand {
+				t.Errorf("shorthand = %q, want %q", flag.Shorthand, tt.shorthand)
+			}
+			if flag.DefValue != tt.defaultValue {
+				t.Errorf("default = %q, want %q", flag.DefValue, tt.defaultValue)
+			}
+		})
+	}
+
+	if sub, _, err := reportCmd.Find([]string{"estimates"}); err != nil || sub == reportCmd {
+		t.Errorf("report estimates subcommand not registered")
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{input: "7d", want: now.AddDate(0, 0, -7)},
+		{input: "2w", want: now.AddDate(0, 0, -14)},
+		{input: "12h", want: now.Add(-12 * time.Hour)},
+		{input: "2026-10-01", want: time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)},
+		{input: "0d", wantErr: true},
+		{input: "7", wantErr: true},
+		{input: "week", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseSince(tt.input, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSince(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("parseSince(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -22,6 +22,12 @@ var (
 	reviewTool           string
 	reviewOutput         string
 	reviewAgentReviewing string
+	reviewPR             int
+	reviewRange          string
+	reviewRemote         string
+	reviewBase           string
+	reviewAgents         []string
+	reviewMinAgreement   int
 )
 
 var reviewCmd = &cobra.Command{
@@ -37,10 +43,26 @@ Review Status:
   ISSUES   - Review found issues that need attention
   ERROR    - Review tool failed to run
 
+With --pr or --range, the review agent reviews changes that mehrhof did not
+create: an existing pull/merge request or a commit range. No active task is
+needed and the working tree is not touched. The findings report is printed,
+and saved when --output is given.
+
+With --agents, two or three agents review the changes independently instead
+(consensus review, also configured by workflow.review). Their findings are
+merged, and each lists the agents that reported it. Findings reported by at
+least --min-agreement agents (default 2) block 'mehr finish' until a later
+review no longer reports them. Without --pr or --range, the active task's
+changes are reviewed and the report is added to the task's notes.
+
 Examples:
   mehr review                     # Run CodeRabbit review
   mehr review --tool coderabbit   # Explicitly specify tool
-  mehr review --output review.txt # Save to specific file`,
+  mehr review --output review.txt # Save to specific file
+  mehr review --pr 123            # Review pull request #123 from origin
+  mehr review --range main..feature -o review.md
+  mehr review --agents claude,codex,gemini          # Consensus review of the task
+  mehr review --pr 123 --agents claude,codex --min-agreement 1`,
 	RunE: runReview,
 }
 
@@ -50,11 +72,26 @@ func init() {
 	reviewCmd.Flags().StringVar(&reviewTool, "tool", "coderabbit", "Review tool to use (coderabbit)")
 	reviewCmd.Flags().StringVarP(&reviewOutput, "output", "o", "", "Output file name (default: review-N.txt)")
 	reviewCmd.Flags().StringVar(&reviewAgentReviewing, "agent-review", "", "Agent for review step (when using agent-based review)")
+	reviewCmd.Flags().IntVar(&reviewPR, "pr", 0, "Review an existing pull/merge request by number")
+	reviewCmd.Flags().StringVar(&reviewRange, "range", "", "Review a commit range, e.g. main..feature")
+	reviewCmd.Flags().StringVar(&reviewRemote, "remote", "origin", "Remote to fetch the pull request from")
+	reviewCmd.Flags().StringVar(&reviewBase, "base", "", "Branch the pull request is compared against (default: detected)")
+	reviewCmd.Flags().StringSliceVar(&reviewAgents, "agents", nil, "Agents reviewing independently for a consensus review (default: workflow.review.agents)")
+	reviewCmd.Flags().IntVar(&reviewMinAgreement, "min-agreement", 0, "Agents that must report a finding for it to block finish (default: workflow.review.min_agreement, else 2)")
+	reviewCmd.MarkFlagsMutuallyExclusive("pr", "range")
+	reviewCmd.MarkFlagsMutuallyExclusive("agents", "agent-review")
 }
 
 func runReview(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 
+	if reviewPR != 0 || reviewRange != "" {
+		return runDiffReview(cmd)
+	}
+	if len(reviewAgents) > 0 {
+		return runConsensusReview(cmd)
+	}
+
 	// Initialize conductor with standard providers and agents
 	cond, err := initializeConductor(ctx, conductor.WithVerbose(verbose))
 	if err != nil {
@@ -137,6 +174,86 @@ func runReview(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runDiffReview reviews a pull request or commit range with the review agent.
+func runDiffReview(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	opts := []conductor.Option{conductor.WithVerbose(verbose)}
+	if reviewAgentReviewing != "" {
+		opts = append(opts, conductor.WithStepAgent("reviewing", reviewAgentReviewing))
+	}
+	if len(reviewAgents) > 0 {
+		opts = append(opts, conductor.WithReviewConsensus(reviewAgents, reviewMinAgreement))
+	}
+	cond, err := initializeConductor(ctx, opts...)
+	if err != nil {
+		return err
+	}
+
+	review, err := cond.ReviewDiff(ctx, conductor.DiffReviewOptions{
+		PR:     reviewPR,
+		Range:  reviewRange,
+		Remote: reviewRemote,
+		Base:   reviewBase,
+	})
+	if err != nil {
+		return fmt.Errorf("review: %w", err)
+	}
+
+	report := review.Report()
+	fmt.Println()
+	fmt.Print(report)
+
+	if reviewOutput != "" {
+		if err := os.WriteFile(reviewOutput, []byte(report), 0o644); err != nil {
+			return fmt.Errorf("save review output: %w", err)
+		}
+		fmt.Printf("\nReview saved to: %s\n", reviewOutput)
+	}
+
+	return nil
+}
+
+// runConsensusReview reviews the active task's changes with several agents.
+func runConsensusReview(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	cond, err := initializeConductor(ctx,
+		conductor.WithVerbose(verbose),
+		conductor.WithReviewConsensus(reviewAgents, reviewMinAgreement),
+	)
+	if err != nil {
+		return err
+	}
+	if cond.GetActiveTask() == nil {
+		fmt.Print(display.NoActiveTaskError())
+
+		return errNoActiveTask
+	}
+
+	fmt.Printf("Reviewing with %s...\n", strings.Join(reviewAgents, ", "))
+	if err := cond.RunReview(ctx); err != nil {
+		return fmt.Errorf("review: %w", err)
+	}
+
+	gate := cond.GetTaskWork().Review
+	if gate == nil {
+		return nil
+	}
+	fmt.Println("Findings were added to the task's notes.")
+	if len(gate.Blocking) == 0 {
+		fmt.Println(display.SuccessMsg("No finding was reported by %d or more agents", gate.MinAgreement))
+
+		return nil
+	}
+	fmt.Printf("\n%d finding(s) reported by at least %d of %d agents block finishing:\n", len(gate.Blocking), gate.MinAgreement, len(gate.Agents))
+	for _, finding := range gate.Blocking {
+		fmt.Println("  " + finding)
+	}
+
+	return nil
+}
+
 // containsIssues checks if the review output indicates issues.
 func containsIssues(output string) bool {
 	lowerOutput := strings.ToLower(output)
@@ -53,6 +53,36 @@ func TestReviewCommand_Flags(t *testing.T) {
 			shorthand:    "",
 			defaultValue: "",
 		},
+		{
+			name:         "pr flag",
+			flagName:     "pr",
+			shorthand:    "",
+			defaultValue: "0",
+		},
+		{
+			name:         "range flag",
+			flagName:     "range",
+			shorthand:    "",
+			defaultValue: "",
+		},
+		{
+			name:         "remote flag",
+			flagName:     "remote",
+			shorthand:    "",
+			defaultValue: "origin",
+		},
+		{
+			name:         "agents flag",
+			flagName:     "agents",
+			shorthand:    "",
+			defaultValue: "[]",
+		},
+		{
+			name:         "min-agreement flag",
+			flagName:     "min-agreement",
+			shorthand:    "",
+			defaultValue: "0",
+		},
 	}
 
 	for _, tt := range tests {
@@ -3,16 +3,21 @@ package commands
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 
 	"github.com/spf13/cobra"
 
+	"github.com/valksor/go-mehrhof/internal/chaos"
+	"github.com/valksor/go-mehrhof/internal/conductor"
 	"github.com/valksor/go-mehrhof/internal/config"
 	"github.com/valksor/go-mehrhof/internal/display"
 	"github.com/valksor/go-mehrhof/internal/help"
 	"github.com/valksor/go-mehrhof/internal/log"
+	"github.com/valksor/go-mehrhof/internal/output"
 )
 
 var (
@@ -22,6 +27,10 @@ var (
 	verbose bool
 	noColor bool
 	quiet   bool
+	noCache bool
+
+	// Hidden testing flag, see package chaos.
+	injectFailure string
 )
 
 var rootCmd = &cobra.Command{
@@ -52,7 +61,13 @@ For full auto: mehr auto task.md`,
 		if err := config.LoadDotEnvFromCwd(); err != nil {
 			// Log warning but don't fail - .env parsing errors should be reported
 			// but shouldn't prevent the command from running
-			fmt.Fprintf(os.Stderr, "warning: failed to load .mehrhof/.env: %v\n", err)
+			fmt.Fprintf(os.Stderr, "warning: failed to load %s/.env: %v\n", config.TaskDir(), err)
+		}
+
+		// MEHR_VERBOSE enables verbose output like --verbose
+		configFlags = layeredFlagValues(cmd)
+		if v, ok := (conductor.ConfigLayers{Env: os.Getenv, Flags: configFlags}).Resolve().Get("verbose"); ok {
+			verbose, _ = strconv.ParseBool(v.Value)
 		}
 
 		// Configure logging from CLI flag
@@ -72,14 +87,24 @@ For full auto: mehr auto task.md`,
 
 		log.Debug("initialized", "verbose", verbose)
 
+		if err := chaos.Configure(injectFailure); err != nil {
+			return err
+		}
+
 		// Async update check (non-blocking, doesn't slow startup)
 		// Skip for the 'update' command itself to avoid redundant checks
-		if cmd.Name() != "update" && shouldCheckForUpdates(settings) {
+		if cmd.Name() != "update" && !isCompletionRequest(cmd) && shouldCheckForUpdates(settings) {
 			go checkForUpdatesInBackground(cmd.Context())
 		}
 
 		return nil
 	},
+	PersistentPostRun: func(cmd *cobra.Command, _ []string) {
+		// Remember workspaces with tasks for 'mehr global status'
+		if !isCompletionRequest(cmd) {
+			recordWorkspace(cmd.Context())
+		}
+	},
 }
 
 // Execute runs the root command with signal handling.
@@ -95,13 +120,34 @@ func Execute() error {
 		cancel()
 	}()
 
-	return rootCmd.ExecuteContext(ctx)
+	registerCompletions(rootCmd)
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	err = classifyError(err)
+	if err != nil && wantsJSON(cmd) {
+		out := io.Writer(os.Stdout)
+		if jsonStdout != nil {
+			out = jsonStdout
+		}
+		_ = output.WriteError(out, err)
+	}
+	restoreStdout()
+
+	return err
 }
 
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress non-essential output")
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable color output")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Run agents even when a cached response exists")
+	rootCmd.PersistentFlags().StringVar(&injectFailure, "inject-failure", "",
+		"Fail subsystems deterministically, e.g. provider.fetch,agent.run:2 (requires "+chaos.EnvGuard+"=1)")
+	_ = rootCmd.PersistentFlags().MarkHidden("inject-failure")
+
+	// Invalid flags exit with their own code
+	rootCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return output.WithCode(err, output.CodeUsage, output.ExitUsage)
+	})
 
 	// Add command groups for better help organization
 	rootCmd.AddGroup(&cobra.Group{
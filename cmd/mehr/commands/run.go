@@ -0,0 +1,297 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/display"
+)
+
+var (
+	runAutoMode      bool
+	runAgent         string
+	runNoBranch      bool
+	runWorktree      bool
+	runMaxIterations int
+	runBudget        float64
+	runOnQuestion    string
+	runMaxRetries    int
+	runQualityTarget string
+	runNoQuality     bool
+	runNoReview      bool
+	runNoPush        bool
+	runNoDelete      bool
+	runNoSquash      bool
+	runTargetBranch  string
+	runTriggerLabel  string
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run [reference]",
+	Short: "Run the whole workflow for a task: start -> plan -> implement -> verify -> review -> finish",
+	Long: `Run every phase of a task in one go, governed by a policy that bounds how
+many agent runs and how much money it may spend.
+
+Phases:
+1. Register the task from the reference
+2. Plan specifications
+3. Implement them
+4. Verify with quality checks, re-implementing with feedback on failure
+5. Review the changes (fixes are checkpointed)
+6. Finish: merge, or open a PR when the provider supports it
+
+Without --auto the run stops before finish, and an agent question stops the
+run so you can answer it with 'mehr note' and continue by hand.
+
+With --auto the run is headless and finishes the task, which is what CI
+wants. Agent questions are handled by --on-question:
+  default    Answer with the recommended or first option and plan again (default)
+  ask_agent  Let the agent answer its own question and plan again
+  skip       Ignore the question and proceed with the agent's best guess
+  stop       Leave the question pending and fail the run
+
+Without --on-question, workflow.question_policy from the config is used.
+
+The run fails once it would exceed --max-iterations agent runs (planning,
+implementation, re-implementation, documentation and review each count
+one) or once the task's agent cost reaches --budget.
+
+Inside GitHub Actions (GITHUB_ACTIONS=true) the run is always headless. The
+reference defaults to the issue from the triggering event, the branch is
+pushed and a pull request opened, and a job summary is written. With
+--trigger-label, "labeled" events for other labels are ignored.
+
+Examples:
+  mehr run task.md                               # Stop before finish for a human look
+  mehr run --auto task.md                        # Headless, end to end
+  mehr run --auto --budget 5 github:123          # Give up after $5 of agent cost
+  mehr run --auto --on-question stop task.md     # Fail instead of guessing answers
+  mehr run --trigger-label mehrhof               # In GitHub Actions, for the labeled issue`,
+	Args: cobra.RangeArgs(0, 1),
+	RunE: runRun,
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+
+	runCmd.Flags().BoolVar(&runAutoMode, "auto", false, "Run headless and finish the task")
+	runCmd.Flags().StringVarP(&runAgent, "agent", "a", "", "Agent to use (default: auto-detect)")
+	runCmd.Flags().BoolVar(&runNoBranch, "no-branch", false, "Do not create a git branch")
+	runCmd.Flags().BoolVarP(&runWorktree, "worktree", "w", false, "Create a separate git worktree")
+	runCmd.Flags().IntVar(&runMaxIterations, "max-iterations", 10, "Maximum agent runs (0 = unlimited)")
+	runCmd.Flags().Float64Var(&runBudget, "budget", 0, "Stop once the task's agent cost reaches this many USD (0 = unlimited)")
+	runCmd.Flags().StringVar(&runOnQuestion, "on-question", "", "What to do when the agent asks a question: default, ask_agent, skip, stop (default: workflow.question_policy, else default with --auto, stop otherwise)")
+	runCmd.Flags().IntVar(&runMaxRetries, "max-retries", 3, "Maximum quality check retry attempts")
+	runCmd.Flags().StringVar(&runQualityTarget, "quality-target", "quality", "Make target for quality checks")
+	runCmd.Flags().BoolVar(&runNoQuality, "no-quality", false, "Skip quality checks")
+	runCmd.Flags().BoolVar(&runNoReview, "no-review", false, "Skip the review phase")
+	runCmd.Flags().BoolVar(&runNoPush, "no-push", false, "Don't push after merge")
+	runCmd.Flags().BoolVar(&runNoDelete, "no-delete", false, "Don't delete task branch after merge")
+	runCmd.Flags().BoolVar(&runNoSquash, "no-squash", false, "Use regular merge instead of squash")
+	runCmd.Flags().StringVarP(&runTargetBranch, "target", "t", "", "Target branch to merge into")
+	runCmd.Flags().StringVar(&runTriggerLabel, "trigger-label", "", "In GitHub Actions, only run for labeled events adding this label")
+}
+
+// parseQuestionPolicy validates --on-question, applying the mode's default.
+func parseQuestionPolicy(value string, auto bool) (conductor.QuestionPolicy, error) {
+	if value != "" {
+		policy, err := conductor.ParseQuestionPolicy(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid --on-question: %w", err)
+		}
+
+		return policy, nil
+	}
+	if auto {
+		return conductor.QuestionDefault, nil
+	}
+
+	return conductor.QuestionStop, nil
+}
+
+// resolveRunReference returns the reference to run: the argument, or the
+// triggering issue inside CI. An empty reference with a nil error means the
+// CI event is not meant for mehrhof.
+func resolveRunReference(args []string, ci *conductor.CIEnvironment, triggerLabel string) (string, error) {
+	if ci != nil && !ci.Triggered(triggerLabel) {
+		return "", nil
+	}
+	if len(args) > 0 {
+		return expandReference(args[0]), nil
+	}
+	if ci == nil {
+		return "", errors.New("a task reference is required outside GitHub Actions")
+	}
+
+	return ci.Reference()
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	ci, err := conductor.DetectCI(os.Getenv)
+	if err != nil {
+		return err
+	}
+	reference, err := resolveRunReference(args, ci, runTriggerLabel)
+	if err != nil {
+		return err
+	}
+	if reference == "" {
+		fmt.Printf("Ignoring %s event for label %q (trigger label is %q)\n", ci.EventName, ci.Label, runTriggerLabel)
+
+		return nil
+	}
+
+	autoMode := runAutoMode
+	if ci != nil {
+		if !ci.HasToken {
+			return errors.New("GITHUB_TOKEN is not set; pass it to the step with env: GITHUB_TOKEN: ${{ secrets.GITHUB_TOKEN }}")
+		}
+		autoMode = true
+	}
+
+	questionPolicy, err := parseQuestionPolicy(runOnQuestion, autoMode)
+	if err != nil {
+		return err
+	}
+
+	// Task templates supply workflow and scope defaults
+	noBranch, worktree, noQuality := runNoBranch, runWorktree, runNoQuality
+	tpl, err := loadTaskTemplate(ctx, reference)
+	if err != nil {
+		return err
+	}
+	if tpl != nil {
+		noBranch = noBranch || tpl.Workflow.NoBranch
+		worktree = worktree || tpl.Workflow.Worktree
+		noQuality = noQuality || tpl.Workflow.SkipQuality
+	}
+
+	opts := []conductor.Option{
+		conductor.WithVerbose(verbose),
+		conductor.WithCreateBranch(!noBranch || worktree),
+		conductor.WithUseWorktree(worktree),
+		conductor.WithAutoInit(true),
+		conductor.WithAutoMode(autoMode),
+		conductor.WithCI(ci),
+		conductor.WithMaxQualityRetries(runMaxRetries),
+		conductor.WithStdout(getDeduplicatingStdout()),
+	}
+	if runAgent != "" {
+		opts = append(opts, conductor.WithAgent(runAgent))
+	}
+	if tpl != nil && tpl.Scope != "" {
+		opts = append(opts, conductor.WithScope(tpl.Scope))
+	}
+
+	cond, err := initializeConductor(ctx, opts...)
+	if err != nil {
+		return err
+	}
+
+	if cond.GetActiveTask() != nil {
+		return fmt.Errorf("task already active: %s\nUse 'mehr abandon' to clear it first, or 'mehr status' for details", cond.GetActiveTask().ID)
+	}
+
+	// workflow.question_policy applies when --on-question is not given
+	if runOnQuestion == "" {
+		if configured := cond.ConfiguredQuestionPolicy(); configured != "" {
+			questionPolicy = configured
+		}
+	}
+
+	subscribeAutoProgress(cond)
+
+	fmt.Printf("%s Running %s\n", display.Info("[1/5]"), display.Bold(reference))
+	fmt.Printf("%s Policy: %s, on question: %s\n", display.Muted("     "),
+		formatRunLimits(runMaxIterations, runBudget), questionPolicy)
+
+	autoOpts := conductor.AutoOptions{
+		QualityTarget: runQualityTarget,
+		MaxRetries:    runMaxRetries,
+		Review:        !runNoReview,
+		NoFinish:      !autoMode,
+		Policy: conductor.AutoPolicy{
+			MaxIterations: runMaxIterations,
+			BudgetUSD:     runBudget,
+			OnQuestion:    questionPolicy,
+		},
+		SquashMerge:  !runNoSquash,
+		DeleteBranch: !runNoDelete,
+		TargetBranch: runTargetBranch,
+		Push:         !runNoPush,
+	}
+	if noQuality {
+		autoOpts.MaxRetries = 0
+	}
+
+	result, err := cond.RunAuto(ctx, reference, autoOpts)
+	if ci != nil {
+		if summaryErr := ci.WriteSummary(ci.JobSummary(reference, result)); summaryErr != nil {
+			fmt.Println(display.WarningMsg("Could not write job summary: %v", summaryErr))
+		}
+	}
+	if err != nil {
+		fmt.Println()
+		if result.Question != "" {
+			fmt.Printf("Agent asked: %s\n", result.Question)
+			fmt.Println("Answer with 'mehr note', then continue with 'mehr plan'.")
+		}
+		fmt.Printf("Run failed at: %s\n", result.FailedAt)
+		printRunSummary(result)
+
+		if errors.Is(err, conductor.ErrBudgetExceeded) || errors.Is(err, conductor.ErrIterationLimit) {
+			fmt.Println("The task is kept; continue by hand or rerun with a larger limit.")
+		}
+
+		return err
+	}
+
+	fmt.Println()
+	if autoMode {
+		fmt.Println(display.SuccessMsg("Task completed automatically"))
+	} else {
+		fmt.Println(display.SuccessMsg("Task ready to finish"))
+	}
+	printRunSummary(result)
+	if !autoMode {
+		fmt.Println("\nCheck the changes, then run 'mehr finish'.")
+	}
+
+	return nil
+}
+
+// formatRunLimits describes the iteration and budget limits of a run.
+func formatRunLimits(maxIterations int, budget float64) string {
+	iterations := "unlimited agent runs"
+	if maxIterations > 0 {
+		iterations = fmt.Sprintf("up to %d agent runs", maxIterations)
+	}
+	if budget <= 0 {
+		return iterations + ", no budget"
+	}
+
+	return fmt.Sprintf("%s, budget $%.2f", iterations, budget)
+}
+
+// printRunSummary prints how far a run got.
+func printRunSummary(result *conductor.AutoResult) {
+	fmt.Printf("  Planning:       %s\n", boolToStatus(result.PlanningDone))
+	fmt.Printf("  Implementation: %s\n", boolToStatus(result.ImplementDone))
+	fmt.Printf("  Quality:        %d attempt(s), passed=%v\n", result.QualityAttempts, result.QualityPassed)
+	fmt.Printf("  Review:         %s\n", boolToStatus(result.ReviewDone))
+	fmt.Printf("  Finish:         %s\n", boolToStatus(result.FinishDone))
+	fmt.Printf("  Agent runs:     %d", result.Iterations)
+	if result.QuestionsAnswered > 0 {
+		fmt.Printf(" (%d question(s) answered with defaults)", result.QuestionsAnswered)
+	}
+	fmt.Printf("\n  Cost:           $%.2f\n", result.CostUSD)
+	if result.PullRequestURL != "" {
+		fmt.Printf("  Pull request:   %s\n", result.PullRequestURL)
+	}
+}
@@ -0,0 +1,137 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/conductor"
+)
+
+func TestRunCommand_Properties(t *testing.T) {
+	if runCmd.Use != "run [reference]" {
+		t.Errorf("Use = %q, want %q", runCmd.Use, "run [reference]")
+	}
+	if runCmd.Short == "" {
+		t.Error("Short description is empty")
+	}
+	if runCmd.RunE == nil {
+		t.Error("RunE not set")
+	}
+
+	for _, substr := range []string{"--auto", "--on-question", "--max-iterations", "--budget", "--trigger-label"} {
+		if !containsString(runCmd.Long, substr) {
+			t.Errorf("Long description does not mention %q", substr)
+		}
+	}
+}
+
+func TestRunCommand_Flags(t *testing.T) {
+	tests := []struct {
+		flagName     string
+		shorthand    string
+		defaultValue string
+	}{
+		{"auto", "", "false"},
+		{"agent", "a", ""},
+		{"no-branch", "", "false"},
+		{"worktree", "w", "false"},
+		{"max-iterations", "", "10"},
+		{"budget", "", "0"},
+		{"on-question", "", ""},
+		{"max-retries", "", "3"},
+		{"quality-target", "", "quality"},
+		{"no-quality", "", "false"},
+		{"no-review", "", "false"},
+		{"no-push", "", "false"},
+		{"no-delete", "", "false"},
+		{"no-squash", "", "false"},
+		{"target", "t", ""},
+		{"trigger-label", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flagName, func(t *testing.T) {
+			flag := runCmd.Flags().Lookup(tt.flagName)
+			if flag == nil {
+				t.Fatalf("flag %q not found", tt.flagName)
+			}
+			if flag.DefValue != tt.defaultValue {
+				t.Errorf("flag %q default value = %q, want %q", tt.flagName, flag.DefValue, tt.defaultValue)
+			}
+			if tt.shorthand != "" && flag.Shorthand != tt.shorthand {
+				t.Errorf("flag %q shorthand = %q, want %q", tt.flagName, flag.Shorthand, tt.shorthand)
+			}
+		})
+	}
+}
+
+func TestParseQuestionPolicy(t *testing.T) {
+	tests := []struct {
+		value   string
+		auto    bool
+		want    conductor.QuestionPolicy
+		wantErr bool
+	}{
+		{"", true, conductor.QuestionDefault, false},
+		{"", false, conductor.QuestionStop, false},
+		{"skip", true, conductor.QuestionSkip, false},
+		{"stop", true, conductor.QuestionStop, false},
+		{"default", false, conductor.QuestionDefault, false},
+		{"fail", true, conductor.QuestionStop, false},
+		{"ask_agent", true, conductor.QuestionAskAgent, false},
+		{"ask", true, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseQuestionPolicy(tt.value, tt.auto)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseQuestionPolicy(%q, %v) error = %v, wantErr %v", tt.value, tt.auto, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("parseQuestionPolicy(%q, %v) = %q, want %q", tt.value, tt.auto, got, tt.want)
+		}
+	}
+}
+
+func TestFormatRunLimits(t *testing.T) {
+	if got := formatRunLimits(10, 0); got != "up to 10 agent runs, no budget" {
+		t.Errorf("formatRunLimits(10, 0) = %q", got)
+	}
+	if got := formatRunLimits(0, 2.5); got != "unlimited agent runs, budget $2.50" {
+		t.Errorf("formatRunLimits(0, 2.5) = %q", got)
+	}
+}
+
+func TestResolveRunReference(t *testing.T) {
+	ci := &conductor.CIEnvironment{Repository: "acme/widgets", EventName: "issues", Action: "labeled", Label: "mehrhof", Issue: 17}
+
+	tests := []struct {
+		name    string
+		args    []string
+		ci      *conductor.CIEnvironment
+		label   string
+		want    string
+		wantErr bool
+	}{
+		{name: "argument", args: []string{"task.md"}, want: "task.md"},
+		{name: "missing outside CI", wantErr: true},
+		{name: "triggering issue", ci: ci, label: "mehrhof", want: "github:acme/widgets#17"},
+		{name: "argument wins in CI", args: []string{"github:5"}, ci: ci, want: "github:5"},
+		{name: "other label", ci: ci, label: "ready", want: ""},
+		{name: "no issue", ci: &conductor.CIEnvironment{EventName: "push"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveRunReference(tt.args, tt.ci, tt.label)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveRunReference() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveRunReference() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
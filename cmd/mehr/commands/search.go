@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var reindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Build or rebuild the workspace index",
+	Long: `Build the workspace index (.mehrhof/index.json) from the task work
+directories, replacing any existing index.
+
+The index is optional. Once built, it is kept up to date as tasks,
+specifications and notes are written, and 'mehr list', 'mehr cost --all' and
+'mehr search' read it instead of every task's files. Run reindex again after
+editing work directories by hand or pulling them from elsewhere; delete the
+file to stop using the index.`,
+	Example: `  mehr reindex`,
+	Args:    cobra.NoArgs,
+	RunE:    runReindex,
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search specifications and notes of all tasks",
+	Long: `Search the specifications and notes of every task in the workspace,
+best matches first. A document matches when it contains every word of the
+query; a word ending in '*' matches any word starting with it.
+
+Search needs the workspace index; build it once with 'mehr reindex'.`,
+	Example: `  mehr search rate limit
+  mehr search "retr* backoff" --limit 5
+  mehr search cache --json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+var (
+	searchLimit int
+	searchJSON  bool
+)
+
+func init() {
+	rootCmd.AddCommand(reindexCmd)
+	rootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 20, "Maximum number of results (0 for all)")
+	searchCmd.Flags().BoolVar(&searchJSON, "json", false, "Output as JSON")
+}
+
+func runReindex(cmd *cobra.Command, _ []string) error {
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+
+	stats, err := ws.Reindex()
+	if err != nil {
+		return fmt.Errorf("reindex: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Indexed %s\n", stats)
+
+	return nil
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+
+	hits, err := ws.Search(strings.Join(args, " "), searchLimit)
+	if errors.Is(err, storage.ErrNoIndex) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+
+	if searchJSON {
+		if hits == nil {
+			hits = []storage.SearchHit{}
+		}
+
+		return outputJSON(hits)
+	}
+
+	out := cmd.OutOrStdout()
+	if len(hits) == 0 {
+		_, _ = fmt.Fprintln(out, "No matches.")
+
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "TASK ID\tDOCUMENT\tMATCH")
+	for _, hit := range hits {
+		doc := "notes"
+		if hit.Kind == storage.DocSpecification {
+			doc = fmt.Sprintf("specification-%d", hit.Number)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", hit.TaskID, doc, hit.Snippet)
+	}
+
+	return w.Flush()
+}
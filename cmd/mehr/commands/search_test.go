@@ -0,0 +1,58 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"testing"
+)
+
+func TestReindexCommand_Properties(t *testing.T) {
+	if reindexCmd.Use != "reindex" {
+		t.Errorf("Use = %q, want %q", reindexCmd.Use, "reindex")
+	}
+	if reindexCmd.Short == "" {
+		t.Error("Short description is empty")
+	}
+	if reindexCmd.RunE == nil {
+		t.Error("RunE not set")
+	}
+}
+
+func TestSearchCommand_Properties(t *testing.T) {
+	if searchCmd.Use != "search <query>" {
+		t.Errorf("Use = %q, want %q", searchCmd.Use, "search <query>")
+	}
+	if searchCmd.Long == "" {
+		t.Error("Long description is empty")
+	}
+	if err := searchCmd.Args(searchCmd, nil); err == nil {
+		t.Error("search accepted no query")
+	}
+}
+
+func TestSearchCommand_Flags(t *testing.T) {
+	tests := []struct {
+		flagName     string
+		shorthand    string
+		defaultValue string
+	}{
+		{flagName: "limit", shorthand: "n", defaultValue: "20"},
+		{flagName: "json", defaultValue: "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flagName, func(t *testing.T) {
+			flag := searchCmd.Flags().Lookup(tt.flagName)
+			if flag == nil {
+				t.Fatalf("flag %q not found", tt.flagName)
+			}
+			if flag.Shorthand != tt.shorthand {
+				t.Errorf("flag %q shorthand = %q, want %q", tt.flagName, flag.Shorthand, tt.shorthand)
+			}
+			if flag.DefValue != tt.defaultValue {
+				t.Errorf("flag %q default value = %q, want %q", tt.flagName, flag.DefValue, tt.defaultValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/server"
+)
+
+var serveAddr string
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a local HTTP API that drives the workflow",
+	Long: `Keep a conductor running and expose the workflow over a local HTTP/JSON API.
+
+Editors and other tools can start tasks, plan, implement and answer agent
+questions without paying for provider and agent initialization on every
+command. Plan and implement run in the background; follow them on the
+event stream. Changes to config.yaml, config.local.yaml and .env are
+reloaded without a restart.
+
+ENDPOINTS:
+  GET  /api/v1/status     Active task and running operation
+  GET  /api/v1/tasks      All tasks in the workspace
+  POST /api/v1/tasks      Start a task: {"reference": "file:task.md"}
+  POST /api/v1/plan       Run planning in the background
+  POST /api/v1/implement  Run implementation in the background
+  GET  /api/v1/question   The agent's pending question
+  POST /api/v1/answer     Answer it: {"answer": "Use PostgreSQL"}
+  GET  /api/v1/events     Server-sent event stream
+
+Only one operation runs at a time; others get 409 Conflict until it ends.
+
+Every request needs the token written to .mehrhof/serve.token, readable
+only by you, as "Authorization: Bearer <token>". A new token is generated
+each time the server starts. GET requests may pass it as ?token= instead.
+POST requests must send "Content-Type: application/json". Requests with an
+Origin header or a Host that is not a loopback address are refused, so web
+pages cannot reach the API.
+
+Examples:
+  mehr serve                      # Listen on 127.0.0.1:7373
+  mehr serve --addr 127.0.0.1:9000
+  curl -N -H "Authorization: Bearer $(cat .mehrhof/serve.token)" \
+    localhost:7373/api/v1/events`,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:7373", "Address to listen on")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	opts := append(BuildConductorOptions(CommandOptions{Verbose: verbose}),
+		conductor.WithConfigValidator(validateReloadedConfig),
+	)
+	cond, err := initializeConductor(ctx, opts...)
+	if err != nil {
+		return err
+	}
+
+	token, err := server.NewToken()
+	if err != nil {
+		return err
+	}
+	tokenPath := cond.GetWorkspace().ServeTokenPath()
+	if err := server.WriteToken(tokenPath, token); err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tokenPath) }()
+
+	srv := server.New(cond, token)
+	defer srv.Close()
+
+	listener, err := net.Listen("tcp", serveAddr)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	httpServer := &http.Server{
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Println(display.SuccessMsg("Serving on http://%s", listener.Addr()))
+	fmt.Println(display.Muted("Token written to " + tokenPath))
+	fmt.Println(display.Muted("Press Ctrl+C to stop"))
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("serve: %w", err)
+		}
+	case <-ctx.Done():
+	}
+
+	// Stop running operations first so event streams end and Shutdown
+	// does not wait on them.
+	srv.Close()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestServeCommand_Properties(t *testing.T) {
+	if serveCmd.Use != "serve" {
+		t.Errorf("Use = %q, want %q", serveCmd.Use, "serve")
+	}
+	if serveCmd.RunE == nil {
+		t.Error("RunE not set")
+	}
+	for _, endpoint := range []string{"/api/v1/tasks", "/api/v1/plan", "/api/v1/implement", "/api/v1/answer", "/api/v1/events"} {
+		if !strings.Contains(serveCmd.Long, endpoint) {
+			t.Errorf("Long description does not mention %s", endpoint)
+		}
+	}
+}
+
+func TestServeCommand_AddrDefaultsToLocalhost(t *testing.T) {
+	flag := serveCmd.Flags().Lookup("addr")
+	if flag == nil {
+		t.Fatal("addr flag not found")
+	}
+	if !strings.HasPrefix(flag.DefValue, "127.0.0.1:") {
+		t.Errorf("addr default = %q, want localhost", flag.DefValue)
+	}
+}
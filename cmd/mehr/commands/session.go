@@ -0,0 +1,251 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var (
+	sessionTask          string
+	sessionAt            int
+	sessionBookmark      bool
+	sessionBookmarksOnly bool
+)
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Annotate and bookmark agent session transcripts",
+	Long: `Work with the session transcripts recorded for a task.
+
+Every plan, implement and review run records its exchanges with the agent in
+a session file. Long transcripts are hard to scan, so important decisions can
+be flagged with a note or a bookmark. Annotations are stored in the session
+file and shown by 'mehr session timeline' and 'mehr task report'.
+
+Session files are named by start time and kind, e.g.
+2025-01-15T10-30-00-planning.yaml. The .yaml suffix may be omitted.`,
+}
+
+var sessionAnnotateCmd = &cobra.Command{
+	Use:   "annotate <file> --at <exchange> <note>",
+	Short: "Attach a note to an exchange",
+	Long: `Attach a note to one exchange of a session. Exchanges are numbered from 1
+in the order they appear in the transcript, as shown by 'mehr session timeline'.`,
+	Example: `  mehr session annotate 2025-01-15T10-30-00-planning --at 4 "Chose Redis over Memcached"
+  mehr session annotate 2025-01-15T10-30-00-planning --at 4 --bookmark "Cache decision"`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runSessionAnnotate,
+}
+
+var sessionBookmarkCmd = &cobra.Command{
+	Use:     "bookmark <file> --at <exchange> [label]",
+	Short:   "Bookmark an exchange",
+	Example: `  mehr session bookmark 2025-01-15T10-30-00-planning --at 4 "Cache decision"`,
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    runSessionBookmark,
+}
+
+var sessionTimelineCmd = &cobra.Command{
+	Use:   "timeline",
+	Short: "List sessions with their annotations",
+	Long: `List the task's sessions in order with their annotations. Bookmarked
+exchanges are marked with ★ and show the start of the exchange.`,
+	Example: `  mehr session timeline
+  mehr session timeline --bookmarks`,
+	Args: cobra.NoArgs,
+	RunE: runSessionTimeline,
+}
+
+func init() {
+	rootCmd.AddCommand(sessionCmd)
+	sessionCmd.AddCommand(sessionAnnotateCmd)
+	sessionCmd.AddCommand(sessionBookmarkCmd)
+	sessionCmd.AddCommand(sessionTimelineCmd)
+
+	sessionCmd.PersistentFlags().StringVar(&sessionTask, "task", "", "Task ID (default: active task)")
+
+	for _, cmd := range []*cobra.Command{sessionAnnotateCmd, sessionBookmarkCmd} {
+		cmd.Flags().IntVar(&sessionAt, "at", 0, "Exchange number (1-based)")
+		_ = cmd.MarkFlagRequired("at")
+	}
+	sessionAnnotateCmd.Flags().BoolVar(&sessionBookmark, "bookmark", false, "Also bookmark the exchange")
+	sessionTimelineCmd.Flags().BoolVar(&sessionBookmarksOnly, "bookmarks", false, "Show only bookmarked exchanges")
+}
+
+func runSessionAnnotate(cmd *cobra.Command, args []string) error {
+	return annotateSession(cmd, args[0], storage.Annotation{
+		Exchange: sessionAt,
+		Note:     strings.Join(args[1:], " "),
+		Bookmark: sessionBookmark,
+	})
+}
+
+func runSessionBookmark(cmd *cobra.Command, args []string) error {
+	return annotateSession(cmd, args[0], storage.Annotation{
+		Exchange: sessionAt,
+		Note:     strings.Join(args[1:], " "),
+		Bookmark: true,
+	})
+}
+
+// annotateSession stores an annotation on a session of the selected task.
+func annotateSession(cmd *cobra.Command, file string, annotation storage.Annotation) error {
+	ws, taskID, err := openSessionWorkspace(cmd)
+	if err != nil {
+		return err
+	}
+
+	filename := sessionFilename(file)
+	if err := ws.AnnotateSession(taskID, filename, annotation); err != nil {
+		return fmt.Errorf("annotate %s: %w", filename, err)
+	}
+
+	what := "Annotated"
+	if annotation.Bookmark {
+		what = "Bookmarked"
+	}
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), display.SuccessMsg("%s exchange %d of %s", what, annotation.Exchange, filename))
+
+	return nil
+}
+
+func runSessionTimeline(cmd *cobra.Command, args []string) error {
+	ws, taskID, err := openSessionWorkspace(cmd)
+	if err != nil {
+		return err
+	}
+
+	files, err := ws.ListSessionFiles(taskID)
+	if err != nil {
+		return fmt.Errorf("list sessions: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	if len(files) == 0 {
+		_, _ = fmt.Fprintf(out, "No sessions for task %s.\n", taskID)
+
+		return nil
+	}
+
+	for _, file := range files {
+		session, err := ws.LoadSession(taskID, file)
+		if err != nil {
+			continue
+		}
+		writeSessionTimeline(out, file, session, sessionBookmarksOnly)
+	}
+
+	return nil
+}
+
+// writeSessionTimeline prints one session and its annotations. With
+// bookmarksOnly, sessions without bookmarks are skipped.
+func writeSessionTimeline(out io.Writer, file string, session *storage.Session, bookmarksOnly bool) {
+	annotations := session.Annotations
+	if bookmarksOnly {
+		annotations = session.Bookmarks()
+		if len(annotations) == 0 {
+			return
+		}
+	}
+
+	_, _ = fmt.Fprintf(out, "%s  %s  %s\n",
+		session.Metadata.StartedAt.Format("2006-01-02 15:04"),
+		display.Bold(session.Kind),
+		display.Muted(fmt.Sprintf("%s, %d exchange(s)", strings.TrimSuffix(file, ".yaml"), len(session.Exchanges))))
+
+	for _, a := range annotations {
+		marker := " "
+		if a.Bookmark {
+			marker = "★"
+		}
+		line := fmt.Sprintf("  %s #%d", marker, a.Exchange)
+		if a.Note != "" {
+			line += " " + a.Note
+		}
+		_, _ = fmt.Fprintln(out, line)
+
+		if a.Bookmark && a.Exchange <= len(session.Exchanges) {
+			exchange := session.Exchanges[a.Exchange-1]
+			_, _ = fmt.Fprintf(out, "      %s\n", display.Muted(exchange.Role+": "+exchangeExcerpt(exchange.Content)))
+		}
+	}
+}
+
+// writeSessionBookmarks prints the bookmarked exchanges of all of a task's
+// sessions, if there are any.
+func writeSessionBookmarks(out io.Writer, ws *storage.Workspace, taskID string) {
+	files, err := ws.ListSessionFiles(taskID)
+	if err != nil {
+		return
+	}
+
+	header := false
+	for _, file := range files {
+		session, err := ws.LoadSession(taskID, file)
+		if err != nil || len(session.Bookmarks()) == 0 {
+			continue
+		}
+		if !header {
+			_, _ = fmt.Fprintln(out, "\nBookmarked decisions:")
+			header = true
+		}
+		writeSessionTimeline(out, file, session, true)
+	}
+}
+
+// exchangeExcerpt returns the first line of an exchange, shortened.
+func exchangeExcerpt(content string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(content), "\n")
+	if len(line) > 100 {
+		line = line[:97] + "..."
+	}
+
+	return line
+}
+
+// openSessionWorkspace opens the workspace and resolves the task selected
+// with --task, or the active task.
+func openSessionWorkspace(cmd *cobra.Command) (*storage.Workspace, string, error) {
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return nil, "", err
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("open workspace: %w", err)
+	}
+
+	var args []string
+	if sessionTask != "" {
+		args = []string{sessionTask}
+	}
+	taskID, err := resolveTaskIDArg(ws, args)
+	if err != nil {
+		return nil, "", err
+	}
+	if !ws.WorkExists(taskID) {
+		return nil, "", fmt.Errorf("task not found: %s", taskID)
+	}
+
+	return ws, taskID, nil
+}
+
+// sessionFilename normalizes a session argument to its filename, accepting a
+// path and an omitted .yaml suffix.
+func sessionFilename(arg string) string {
+	name := filepath.Base(arg)
+	if !strings.HasSuffix(name, ".yaml") {
+		name += ".yaml"
+	}
+
+	return name
+}
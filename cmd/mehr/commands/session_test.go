@@ -0,0 +1,85 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestSessionCommand_Structure(t *testing.T) {
+	want := map[string]bool{"annotate": false, "bookmark": false, "timeline": false}
+	for _, sub := range sessionCmd.Commands() {
+		if _, ok := want[sub.Name()]; ok {
+			want[sub.Name()] = true
+		}
+	}
+	for name, found := range want {
+		if !found {
+			t.Errorf("session subcommand %q not registered", name)
+		}
+	}
+
+	for _, cmd := range []string{"annotate", "bookmark"} {
+		sub, _, err := sessionCmd.Find([]string{cmd})
+		if err != nil {
+			t.Fatalf("Find(%s): %v", cmd, err)
+		}
+		if sub.Flags().Lookup("at") == nil {
+			t.Errorf("%s has no --at flag", cmd)
+		}
+	}
+}
+
+func TestSessionFilename(t *testing.T) {
+	tests := map[string]string{
+		"2025-01-15T10-30-00-planning":                               "2025-01-15T10-30-00-planning.yaml",
+		"2025-01-15T10-30-00-planning.yaml":                          "2025-01-15T10-30-00-planning.yaml",
+		".mehrhof/work/abc/sessions/2025-01-15T10-30-00-review.yaml": "2025-01-15T10-30-00-review.yaml",
+	}
+	for arg, want := range tests {
+		if got := sessionFilename(arg); got != want {
+			t.Errorf("sessionFilename(%q) = %q, want %q", arg, got, want)
+		}
+	}
+}
+
+func TestWriteSessionTimeline(t *testing.T) {
+	session := &storage.Session{
+		Kind:     "planning",
+		Metadata: storage.SessionMetadata{StartedAt: time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)},
+		Exchanges: []storage.Exchange{
+			{Role: "user", Content: "Plan the cache"},
+			{Role: "agent", Content: "Use Redis with a 5 minute TTL\nbecause sessions are shared"},
+		},
+		Annotations: []storage.Annotation{
+			{Exchange: 1, Note: "Initial ask"},
+			{Exchange: 2, Note: "Cache decision", Bookmark: true},
+		},
+	}
+
+	var all bytes.Buffer
+	writeSessionTimeline(&all, "2025-01-15T10-30-00-planning.yaml", session, false)
+	for _, want := range []string{"planning", "2 exchange(s)", "#1 Initial ask", "★ #2 Cache decision", "agent: Use Redis with a 5 minute TTL"} {
+		if !strings.Contains(all.String(), want) {
+			t.Errorf("timeline missing %q:\n%s", want, all.String())
+		}
+	}
+
+	var bookmarks bytes.Buffer
+	writeSessionTimeline(&bookmarks, "2025-01-15T10-30-00-planning.yaml", session, true)
+	if strings.Contains(bookmarks.String(), "Initial ask") {
+		t.Errorf("bookmarks-only timeline shows a plain note:\n%s", bookmarks.String())
+	}
+
+	var empty bytes.Buffer
+	writeSessionTimeline(&empty, "x.yaml", &storage.Session{Kind: "review"}, true)
+	if empty.Len() != 0 {
+		t.Errorf("session without bookmarks should be skipped, got %q", empty.String())
+	}
+}
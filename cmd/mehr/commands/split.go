@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var (
+	splitCreateIssues bool
+	splitDryRun       bool
+	splitYes          bool
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Split the active task into child tasks",
+	Long: `Ask the planning agent to break the active task into smaller child tasks
+that can be implemented, reviewed and merged on their own.
+
+Each child gets its own work directory, linked to the parent, with the
+child's description as its source. With --create-issues a work unit is also
+created for each child with the parent's provider (for example a GitHub
+issue), linked to the parent where the provider supports it.
+
+After the split the parent is no longer the active task. Start a child with
+'mehr start <child-id>', or with the reference of its issue. The parent's
+progress, the share of finished children, is shown by 'mehr status'.`,
+	Example: `  mehr split                  # Propose, confirm and create child tasks
+  mehr split --dry-run        # Only show the proposal
+  mehr split --create-issues  # Also create an issue for each child`,
+	Args: cobra.NoArgs,
+	RunE: runSplit,
+}
+
+func init() {
+	rootCmd.AddCommand(splitCmd)
+	splitCmd.Flags().BoolVar(&splitCreateIssues, "create-issues", false, "Create a work unit for each child with the parent's provider")
+	splitCmd.Flags().BoolVar(&splitDryRun, "dry-run", false, "Show the proposed child tasks without creating them")
+	splitCmd.Flags().BoolVarP(&splitYes, "yes", "y", false, "Skip confirmation prompt")
+}
+
+func runSplit(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	out := cmd.OutOrStdout()
+
+	cond, err := initializeConductor(ctx, BuildConductorOptions(CommandOptions{Verbose: verbose})...)
+	if err != nil {
+		return err
+	}
+	if !RequireActiveTask(cond) {
+		return nil
+	}
+
+	children, err := cond.ProposeSplit(ctx)
+	if err != nil {
+		return err
+	}
+	printChildTasks(out, children)
+	if splitDryRun {
+		return nil
+	}
+
+	confirmed, err := confirmAction(fmt.Sprintf("Create %d child task(s)?", len(children)), splitYes)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		_, _ = fmt.Fprintln(out, "Cancelled")
+
+		return nil
+	}
+
+	created, err := cond.Split(ctx, children, conductor.SplitOptions{CreateIssues: splitCreateIssues})
+	printCreatedChildren(out, created)
+
+	return err
+}
+
+// printChildTasks lists proposed child tasks with their descriptions.
+func printChildTasks(out io.Writer, children []conductor.ChildTask) {
+	_, _ = fmt.Fprintf(out, "Proposed child tasks: %d\n", len(children))
+	for i, child := range children {
+		_, _ = fmt.Fprintf(out, "\n%d. %s\n", i+1, display.Bold(child.Title))
+		for line := range strings.SplitSeq(child.Description, "\n") {
+			_, _ = fmt.Fprintf(out, "   %s\n", line)
+		}
+	}
+	_, _ = fmt.Fprintln(out)
+}
+
+// printCreatedChildren lists the child tasks created by a split.
+func printCreatedChildren(out io.Writer, created []*storage.TaskWork) {
+	if len(created) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintln(out, display.SuccessMsg("Created %d child task(s)", len(created)))
+	for _, work := range created {
+		line := fmt.Sprintf("  %s  %s", work.Metadata.ID, work.Metadata.Title)
+		if work.Source.Ref != "" {
+			line += display.Muted(" (" + work.Source.Ref + ")")
+		}
+		_, _ = fmt.Fprintln(out, line)
+	}
+	_, _ = fmt.Fprintf(out, "\nStart one with: mehr start %s\n", created[0].Metadata.ID)
+}
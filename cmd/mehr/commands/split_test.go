@@ -0,0 +1,67 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestSplitCommand_Properties(t *testing.T) {
+	if splitCmd.Use != "split" {
+		t.Errorf("Use = %q, want %q", splitCmd.Use, "split")
+	}
+	if splitCmd.Short == "" || splitCmd.Long == "" {
+		t.Error("description is empty")
+	}
+	if splitCmd.RunE == nil {
+		t.Error("RunE not set")
+	}
+
+	for _, name := range []string{"create-issues", "dry-run", "yes"} {
+		flag := splitCmd.Flags().Lookup(name)
+		if flag == nil {
+			t.Errorf("%s flag not found", name)
+
+			continue
+		}
+		if flag.DefValue != "false" {
+			t.Errorf("%s default = %q, want %q", name, flag.DefValue, "false")
+		}
+	}
+}
+
+func TestPrintChildTasks(t *testing.T) {
+	var buf bytes.Buffer
+	printChildTasks(&buf, []conductor.ChildTask{
+		{Title: "Add form", Description: "Render the form.\nTest it."},
+		{Title: "Validate input", Description: "Reject empty passwords."},
+	})
+	for _, want := range []string{"Proposed child tasks: 2", "Add form", "   Render the form.\n   Test it.", "2. "} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestPrintCreatedChildren(t *testing.T) {
+	var buf bytes.Buffer
+	printCreatedChildren(&buf, nil)
+	if buf.Len() != 0 {
+		t.Errorf("output without children = %q", buf.String())
+	}
+
+	child := storage.NewTaskWork("abc123", storage.SourceInfo{Type: "split", Ref: "github:42"})
+	child.Metadata.Title = "Add form"
+	printCreatedChildren(&buf, []*storage.TaskWork{child})
+	for _, want := range []string{"Created 1 child task(s)", "abc123  Add form", "github:42", "mehr start abc123"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -10,6 +11,7 @@ import (
 
 	"github.com/valksor/go-mehrhof/internal/conductor"
 	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/output"
 	"github.com/valksor/go-mehrhof/internal/storage"
 	"github.com/valksor/go-mehrhof/internal/template"
 )
@@ -18,12 +20,21 @@ var (
 	startAgent         string
 	startNoBranch      bool
 	startWorktree      bool
+	startWIP           bool
 	startKey           string // External key override (e.g., "FEATURE-123")
 	startTitle         string // Title override for the task
 	startSlug          string // Slug override for branch naming
 	startCommitPrefix  string // Commit prefix template override
 	startBranchPattern string // Branch pattern template override
 	startTemplate      string // Template to apply
+	startJSON          bool
+
+	// Monorepo scoping.
+	startScope             string
+	startAllowOutsideScope bool
+
+	// Multi-repository tasks.
+	startAttach []string
 
 	// Per-step agent overrides.
 	startAgentPlanning     string
@@ -51,6 +62,10 @@ PROVIDERS:
   linear:ABC-123            Linear issue (requires configuration)
   wrike:abc123              Wrike task (requires configuration)
   youtrack:PROJ-123         YouTrack issue (requires configuration)
+  url:https://...           Web page, converted to markdown
+  - / stdin:                Task description piped on stdin
+  clipboard:                Task description from the clipboard
+  <task-id>                 Child task created by 'mehr split'
 
 AGENT SELECTION (highest to lowest priority):
   1. CLI flag: --agent or --agent-plan/--agent-implement/--agent-review
@@ -61,6 +76,17 @@ AGENT SELECTION (highest to lowest priority):
 GIT OPTIONS:
   --no-branch               Do not create a git branch
   --worktree                Create isolated git worktree (allows parallel tasks)
+  --wip                     Park the active task first, committing uncommitted
+                            changes as WIP (see 'mehr task switch')
+
+MONOREPO SCOPING:
+  --scope <path>            Restrict the task to a subdirectory (agent context,
+                            diff stats, linters, quality checks, file changes)
+  --allow-outside-scope     Permit file changes outside the scope
+
+MULTI-REPOSITORY TASKS:
+  --attach <name>           Attach a repository from workspaces: in config.yaml;
+                            creates the task branch there too (repeatable)
 
 PER-STEP AGENT FLAGS:
   --agent-plan              Agent for planning step (overrides default)
@@ -78,7 +104,12 @@ EXAMPLES:
   mehr start dir:./tasks/         # Start from a directory
   mehr start --no-branch task.md  # Start without creating a branch
   mehr start --worktree task.md   # Start with a separate worktree
+  mehr start --scope services/api task.md  # Scope the task to one subproject
+  mehr start --attach frontend task.md     # Task spans this repo and frontend
   mehr start --template bug-fix file:task.md  # Apply bug-fix template
+  mehr start template:rotate-secrets          # New run of a task template
+  echo "Fix the flaky login test" | mehr start -  # Ad-hoc task from stdin
+  mehr start --json task.md       # Print the started task as JSON
 
 See also:
   mehr plan                 - Create implementation specifications
@@ -93,6 +124,7 @@ func init() {
 
 	startCmd.Flags().StringVarP(&startAgent, "agent", "A", "", "Agent to use (default: auto-detect)")
 	startCmd.Flags().BoolVar(&startNoBranch, "no-branch", false, "Do not create a git branch")
+	startCmd.Flags().BoolVar(&startWIP, "wip", false, "Park the active task, committing uncommitted changes as WIP")
 	startCmd.Flags().BoolVarP(&startWorktree, "worktree", "w", false, "Create a separate git worktree for this task")
 
 	// Naming override flags
@@ -102,6 +134,14 @@ func init() {
 	startCmd.Flags().StringVar(&startCommitPrefix, "commit-prefix", "", "Commit prefix template (e.g., [{key}])")
 	startCmd.Flags().StringVar(&startBranchPattern, "branch-pattern", "", "Branch pattern template (e.g., {type}/{key}--{slug})")
 	startCmd.Flags().StringVar(&startTemplate, "template", "", "Template to apply (bug-fix, feature, refactor, docs, test, chore)")
+	startCmd.Flags().BoolVar(&startJSON, "json", false, "Output the result as JSON")
+
+	// Monorepo scoping flags
+	startCmd.Flags().StringVar(&startScope, "scope", "", "Restrict the task to a repository subdirectory")
+	startCmd.Flags().BoolVar(&startAllowOutsideScope, "allow-outside-scope", false, "Permit file changes outside --scope")
+
+	// Multi-repository flags
+	startCmd.Flags().StringSliceVar(&startAttach, "attach", nil, "Attach a configured workspace repository (repeatable)")
 
 	// Per-step agent overrides
 	startCmd.Flags().StringVar(&startAgentPlanning, "agent-plan", "", "Agent for planning step")
@@ -111,7 +151,12 @@ func init() {
 
 func runStart(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
-	reference := args[0]
+	reference := expandReference(args[0])
+
+	var jsonOut io.Writer
+	if startJSON {
+		jsonOut = redirectStdout()
+	}
 
 	// Apply template if specified (only works for file: provider)
 	if startTemplate != "" {
@@ -142,16 +187,31 @@ func runStart(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Applied template '%s' to %s\n", startTemplate, filePath)
 	}
 
+	// Task templates supply workflow and scope defaults
+	noBranch, worktree, scope := startNoBranch, startWorktree, startScope
+	tpl, err := loadTaskTemplate(ctx, reference)
+	if err != nil {
+		return err
+	}
+	if tpl != nil {
+		noBranch = noBranch || tpl.Workflow.NoBranch
+		worktree = worktree || tpl.Workflow.Worktree
+		if scope == "" {
+			scope = tpl.Scope
+		}
+	}
+
 	// Determine branch behavior
 	// Branch creation is default, --no-branch disables it
 	// --worktree forces branch creation (even with --no-branch)
-	createBranch := !startNoBranch || startWorktree
+	createBranch := !noBranch || worktree
 
 	// Build conductor options
 	opts := []conductor.Option{
 		conductor.WithVerbose(verbose),
 		conductor.WithCreateBranch(createBranch),
-		conductor.WithUseWorktree(startWorktree),
+		conductor.WithUseWorktree(worktree),
+		conductor.WithStashWIP(startWIP),
 		conductor.WithAutoInit(true),
 	}
 
@@ -170,15 +230,6 @@ func runStart(cmd *cobra.Command, args []string) error {
 		opts = append(opts, conductor.WithStepAgent("reviewing", startAgentReviewing))
 	}
 
-	// Pass default provider from workspace config
-	if wd, err := os.Getwd(); err == nil {
-		if ws, err := storage.OpenWorkspace(wd, nil); err == nil {
-			if wsCfg, err := ws.LoadConfig(); err == nil && wsCfg.Providers.Default != "" {
-				opts = append(opts, conductor.WithDefaultProvider(wsCfg.Providers.Default))
-			}
-		}
-	}
-
 	// Naming override options
 	if startKey != "" {
 		opts = append(opts, conductor.WithExternalKey(startKey))
@@ -195,6 +246,12 @@ func runStart(cmd *cobra.Command, args []string) error {
 	if startBranchPattern != "" {
 		opts = append(opts, conductor.WithBranchPatternTemplate(startBranchPattern))
 	}
+	if scope != "" {
+		opts = append(opts, conductor.WithScope(scope), conductor.WithAllowOutsideScope(startAllowOutsideScope))
+	}
+	if len(startAttach) > 0 {
+		opts = append(opts, conductor.WithRepositories(startAttach...))
+	}
 
 	// Initialize conductor with standard providers and agents
 	cond, err := initializeConductor(ctx, opts...)
@@ -202,9 +259,9 @@ func runStart(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Check for existing task
-	if cond.GetActiveTask() != nil {
-		return fmt.Errorf("task already active: %s\n\nOptions:\n  mehr status   - View task details\n  mehr finish   - Complete the task\n  mehr abandon  - Cancel and start fresh", cond.GetActiveTask().ID)
+	// Check for existing task; --wip parks it instead
+	if cond.GetActiveTask() != nil && !startWIP {
+		return fmt.Errorf("task already active: %s\n\nOptions:\n  mehr status   - View task details\n  mehr finish   - Complete the task\n  mehr abandon  - Cancel and start fresh\n  mehr start --wip <reference> - Park it and start the new task", cond.GetActiveTask().ID)
 	}
 
 	// Start (register) task
@@ -218,6 +275,10 @@ func runStart(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if jsonOut != nil {
+		return output.JSON(jsonOut, newJSONTaskResult("start", status))
+	}
+
 	// Display task info
 	info := display.TaskInfo{
 		TaskID:      status.TaskID,
@@ -227,6 +288,8 @@ func runStart(cmd *cobra.Command, args []string) error {
 		Source:      status.Ref,
 		Branch:      status.Branch,
 		Worktree:    status.WorktreePath,
+		Scope:       status.Scope,
+		Repos:       formatRepos(status.Repositories),
 	}
 	displayOpts := display.DefaultTaskInfoOptions()
 	displayOpts.ShowStarted = false // Not relevant for just-started task
@@ -248,3 +311,17 @@ func runStart(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// formatRepos formats attached repositories for display as "name (branch)".
+func formatRepos(repos []storage.RepoInfo) []string {
+	out := make([]string, 0, len(repos))
+	for _, r := range repos {
+		if r.Branch != "" {
+			out = append(out, fmt.Sprintf("%s (%s)", r.Name, r.Branch))
+		} else {
+			out = append(out, r.Name)
+		}
+	}
+
+	return out
+}
@@ -4,7 +4,10 @@
 package commands
 
 import (
+	"slices"
 	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
 )
 
 // Note: TestStartCommand_AgentFlagShorthand is in common_test.go
@@ -106,6 +109,24 @@ func TestStartCommand_Flags(t *testing.T) {
 			shorthand:    "",
 			defaultValue: "",
 		},
+		{
+			name:         "scope flag",
+			flagName:     "scope",
+			shorthand:    "",
+			defaultValue: "",
+		},
+		{
+			name:         "allow-outside-scope flag",
+			flagName:     "allow-outside-scope",
+			shorthand:    "",
+			defaultValue: "false",
+		},
+		{
+			name:         "attach flag",
+			flagName:     "attach",
+			shorthand:    "",
+			defaultValue: "[]",
+		},
 	}
 
 	for _, tt := range tests {
@@ -224,3 +245,15 @@ func TestStartCommand_Examples(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatRepos(t *testing.T) {
+	got := formatRepos([]storage.RepoInfo{
+		{Name: "frontend", Branch: "feature/x"},
+		{Name: "docs"},
+	})
+	want := []string{"frontend (feature/x)", "docs"}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("formatRepos() = %v, want %v", got, want)
+	}
+}
@@ -78,15 +78,15 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 	// If in a worktree, auto-detect task from worktree path
 	if res.IsWorktree {
-		return showWorktreeTask(ctx, ws, res.Git)
+		return showWorktreeTask(ctx, ws, res.VCS())
 	}
 
-	return showActiveTask(ctx, ws, res.Git)
+	return showActiveTask(ctx, ws, res.VCS())
 }
 
-func showWorktreeTask(ctx context.Context, ws *storage.Workspace, git *vcs.Git) error {
+func showWorktreeTask(ctx context.Context, ws *storage.Workspace, git vcs.VCS) error {
 	// Auto-detect task from current worktree
-	if git == nil {
+	if !git.IsWorktree() {
 		return errors.New("not in a worktree")
 	}
 	active, err := ws.FindTaskByWorktreePath(git.Root())
@@ -139,6 +139,7 @@ func showWorktreeTask(ctx context.Context, ws *storage.Workspace, git *vcs.Git)
 	if active.Branch != "" {
 		fmt.Printf("  Branch:   %s\n", active.Branch)
 	}
+	printTaskFamily(ws, work)
 
 	// Show specifications with status
 	specifications, _ := ws.ListSpecificationsWithStatus(active.ID)
@@ -153,7 +154,11 @@ func showWorktreeTask(ctx context.Context, ws *storage.Workspace, git *vcs.Git)
 			if len(title) > 50 {
 				title = title[:47] + "..."
 			}
-			fmt.Printf("  %s specification-%d: %s [%s]\n", statusIcon, specification.Number, title, display.FormatSpecificationStatus(specification.Status))
+			statusText := display.FormatSpecificationStatus(specification.Status)
+			if n := len(specification.Checkpoints); n > 0 {
+				statusText += fmt.Sprintf(", checkpoint #%d", specification.Checkpoints[n-1])
+			}
+			fmt.Printf("  %s specification-%d: %s [%s]\n", statusIcon, specification.Number, title, statusText)
 		}
 	} else {
 		fmt.Printf("\nNo specifications yet. Run 'mehr plan' to create them.\n")
@@ -187,7 +192,7 @@ func showWorktreeTask(ctx context.Context, ws *storage.Workspace, git *vcs.Git)
 	return nil
 }
 
-func showActiveTask(ctx context.Context, ws *storage.Workspace, git *vcs.Git) error {
+func showActiveTask(ctx context.Context, ws *storage.Workspace, git vcs.VCS) error {
 	if !ws.HasActiveTask() {
 		if statusJSON {
 			return outputJSON(jsonStatusTask{})
@@ -232,6 +237,20 @@ func showActiveTask(ctx context.Context, ws *storage.Workspace, git *vcs.Git) er
 	if active.Branch != "" {
 		fmt.Printf("  Branch:  %s\n", active.Branch)
 	}
+	if work.Metadata.Scope != "" {
+		fmt.Printf("  Scope:   %s\n", work.Metadata.Scope)
+	}
+	printTaskFamily(ws, work)
+	for _, repo := range work.Repos {
+		fmt.Printf("  Repo:    %s (%s)", repo.Name, repo.Path)
+		if repo.Branch != "" {
+			fmt.Printf(" on %s", repo.Branch)
+		}
+		if repo.PRURL != "" {
+			fmt.Printf(" - %s", repo.PRURL)
+		}
+		fmt.Println()
+	}
 
 	// Show specifications with status
 	specifications, _ := ws.ListSpecificationsWithStatus(active.ID)
@@ -272,13 +291,11 @@ func showActiveTask(ctx context.Context, ws *storage.Workspace, git *vcs.Git) er
 	}
 
 	// Show checkpoints
-	if git != nil {
-		checkpoints, err := git.ListCheckpoints(ctx, active.ID)
-		if err == nil && len(checkpoints) > 0 {
-			fmt.Printf("\nCheckpoints: %d\n", len(checkpoints))
-			for _, cp := range checkpoints {
-				fmt.Printf("  - #%d: %s (%s)\n", cp.Number, cp.Message, cp.ID[:8])
-			}
+	checkpoints, err := git.ListCheckpoints(ctx, active.ID)
+	if err == nil && len(checkpoints) > 0 {
+		fmt.Printf("\nCheckpoints: %d\n", len(checkpoints))
+		for _, cp := range checkpoints {
+			fmt.Printf("  - #%d: %s (%s)\n", cp.Number, cp.Message, cp.ID[:8])
 		}
 	}
 
@@ -286,15 +303,22 @@ func showActiveTask(ctx context.Context, ws *storage.Workspace, git *vcs.Git) er
 	sessions, _ := ws.ListSessions(active.ID)
 	if len(sessions) > 0 {
 		fmt.Printf("\nSessions: %d\n", len(sessions))
-		var totalTokens int
+		var totalTokens, bookmarks int
 		for _, s := range sessions {
 			if s.Usage != nil {
 				totalTokens += s.Usage.InputTokens + s.Usage.OutputTokens
 			}
+			bookmarks += len(s.Bookmarks())
 		}
 		if totalTokens > 0 {
 			fmt.Printf("  Total tokens: %d\n", totalTokens)
 		}
+		if times := storage.PhaseTimes(sessions); len(times) > 0 {
+			fmt.Printf("  Time: %s (%s)\n", storage.FormatMinutes(storage.TotalTime(times)), storage.FormatPhaseTimes(times))
+		}
+		if bookmarks > 0 {
+			fmt.Printf("  Bookmarks: %d (mehr session timeline --bookmarks)\n", bookmarks)
+		}
 	}
 
 	// Show spec icon legend if there are specifications
@@ -349,7 +373,7 @@ func showAllTasks(ws *storage.Workspace) error {
 				continue
 			}
 			isActive := taskID == activeID
-			state := "unknown"
+			state := inactiveTaskState(ws, work)
 			if isActive {
 				active, _ := ws.LoadActiveTask()
 				if active != nil {
@@ -367,6 +391,8 @@ func showAllTasks(ws *storage.Workspace) error {
 				Title:    title,
 				State:    state,
 				IsActive: isActive,
+				ParentID: work.Metadata.ParentID,
+				Children: jsonChildren(ws, taskID),
 			})
 		}
 
@@ -385,7 +411,7 @@ func showAllTasks(ws *storage.Workspace) error {
 		}
 
 		specifications, _ := ws.ListSpecifications(taskID)
-		state := "unknown"
+		state := inactiveTaskState(ws, work)
 
 		// Check if this is the active task
 		isActive := taskID == activeID
@@ -426,6 +452,59 @@ func showAllTasks(ws *storage.Workspace) error {
 	return nil
 }
 
+// printTaskFamily shows the parent of a task split from another, and the
+// progress of the tasks split from this one.
+func printTaskFamily(ws *storage.Workspace, work *storage.TaskWork) {
+	if parentID := work.Metadata.ParentID; parentID != "" {
+		line := parentID
+		if parent, err := ws.LoadWork(parentID); err == nil && parent.Metadata.Title != "" {
+			line += " (" + parent.Metadata.Title + ")"
+		}
+		if progress, err := ws.ChildProgress(parentID); err == nil && progress.Total > 0 {
+			line += fmt.Sprintf(" - %d/%d subtasks done", progress.Done, progress.Total)
+		}
+		fmt.Printf("  Parent:  %s\n", line)
+	}
+
+	children, _ := ws.ListChildren(work.Metadata.ID)
+	if len(children) == 0 {
+		return
+	}
+	progress, _ := ws.ChildProgress(work.Metadata.ID)
+	fmt.Printf("  Subtasks: %d/%d done\n", progress.Done, progress.Total)
+	for _, child := range children {
+		mark := "○"
+		if !child.Metadata.FinishedAt.IsZero() {
+			mark = "●"
+		}
+		fmt.Printf("    %s %s  %s\n", mark, child.Metadata.ID, child.Metadata.Title)
+	}
+}
+
+// inactiveTaskState describes a task that is not active: finished, split
+// into child tasks (with their progress), or unknown.
+func inactiveTaskState(ws *storage.Workspace, work *storage.TaskWork) string {
+	if !work.Metadata.FinishedAt.IsZero() {
+		return "done"
+	}
+	if progress, err := ws.ChildProgress(work.Metadata.ID); err == nil && progress.Total > 0 {
+		return fmt.Sprintf("split (%d/%d)", progress.Done, progress.Total)
+	}
+
+	return "unknown"
+}
+
+// jsonChildren returns the progress of the tasks split from taskID, or nil
+// when it has none.
+func jsonChildren(ws *storage.Workspace, taskID string) *jsonChildProgress {
+	progress, err := ws.ChildProgress(taskID)
+	if err != nil || progress.Total == 0 {
+		return nil
+	}
+
+	return &jsonChildProgress{Total: progress.Total, Done: progress.Done}
+}
+
 // printSpecLegend prints the specification status icon legend.
 func printSpecLegend() {
 	fmt.Println()
@@ -447,6 +526,8 @@ type jsonStatusTask struct {
 	WorkDir        string              `json:"work_dir,omitempty"`
 	WorktreePath   string              `json:"worktree_path,omitempty"`
 	Branch         string              `json:"branch,omitempty"`
+	Scope          string              `json:"scope,omitempty"`
+	Repositories   []jsonRepository    `json:"repositories,omitempty"`
 	Started        string              `json:"started_at"`
 	AgentName      string              `json:"agent_name,omitempty"`
 	AgentSource    string              `json:"agent_source,omitempty"`
@@ -456,6 +537,21 @@ type jsonStatusTask struct {
 	Checkpoints    []jsonCheckpoint    `json:"checkpoints,omitempty"`
 	Sessions       []jsonSession       `json:"sessions,omitempty"`
 	TotalTokens    int                 `json:"total_tokens,omitempty"`
+	ParentID       string              `json:"parent_id,omitempty"`
+	Children       *jsonChildProgress  `json:"children,omitempty"`
+	Question       *jsonQuestion       `json:"pending_question,omitempty"`
+}
+
+type jsonChildProgress struct {
+	Total int `json:"total"`
+	Done  int `json:"done"`
+}
+
+type jsonRepository struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Branch string `json:"branch,omitempty"`
+	PRURL  string `json:"pr_url,omitempty"`
 }
 
 type jsonSpecification struct {
@@ -492,7 +588,7 @@ type jsonStatusAllOutput struct {
 }
 
 // buildJSONStatusTask constructs a jsonStatusTask from workspace data.
-func buildJSONStatusTask(ctx context.Context, ws *storage.Workspace, git *vcs.Git, active *storage.ActiveTask, work *storage.TaskWork, worktreePath string) jsonStatusTask {
+func buildJSONStatusTask(ctx context.Context, ws *storage.Workspace, git vcs.VCS, active *storage.ActiveTask, work *storage.TaskWork, worktreePath string) jsonStatusTask {
 	task := jsonStatusTask{
 		TaskID:       active.ID,
 		Title:        work.Metadata.Title,
@@ -503,10 +599,27 @@ func buildJSONStatusTask(ctx context.Context, ws *storage.Workspace, git *vcs.Gi
 		WorkDir:      active.WorkDir,
 		WorktreePath: worktreePath,
 		Branch:       active.Branch,
+		Scope:        work.Metadata.Scope,
 		Started:      active.Started.Format("2006-01-02T15:04:05Z"),
 		AgentName:    work.Agent.Name,
 		AgentSource:  work.Agent.Source,
 		IsActive:     true,
+		ParentID:     work.Metadata.ParentID,
+		Children:     jsonChildren(ws, active.ID),
+	}
+	if ws.HasPendingQuestion(active.ID) {
+		if q, err := ws.LoadPendingQuestion(active.ID); err == nil {
+			task.Question = newJSONQuestion(q)
+		}
+	}
+
+	for _, repo := range work.Repos {
+		task.Repositories = append(task.Repositories, jsonRepository{
+			Name:   repo.Name,
+			Path:   repo.Path,
+			Branch: repo.Branch,
+			PRURL:  repo.PRURL,
+		})
 	}
 
 	// Get specifications with status
@@ -530,17 +643,15 @@ func buildJSONStatusTask(ctx context.Context, ws *storage.Workspace, git *vcs.Gi
 		Done:         summary[storage.SpecificationStatusDone],
 	}
 
-	// Get checkpoints if git is available
-	if git != nil {
-		checkpoints, _ := git.ListCheckpoints(ctx, active.ID)
-		for _, cp := range checkpoints {
-			task.Checkpoints = append(task.Checkpoints, jsonCheckpoint{
-				Number:    cp.Number,
-				Message:   cp.Message,
-				ID:        cp.ID,
-				Timestamp: cp.Timestamp.Format("2006-01-02T15:04:05Z"),
-			})
-		}
+	// Get checkpoints (none without version control)
+	checkpoints, _ := git.ListCheckpoints(ctx, active.ID)
+	for _, cp := range checkpoints {
+		task.Checkpoints = append(task.Checkpoints, jsonCheckpoint{
+			Number:    cp.Number,
+			Message:   cp.Message,
+			ID:        cp.ID,
+			Timestamp: cp.Timestamp.Format("2006-01-02T15:04:05Z"),
+		})
 	}
 
 	// Get sessions and token usage
@@ -5,6 +5,9 @@ package commands
 
 import (
 	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
 )
 
 // Note: TestStatusCommand_Aliases is in common_test.go
@@ -192,3 +195,41 @@ func TestStatusCommand_JSONFlagNoShorthand(t *testing.T) {
 		t.Errorf("json flag has shorthand %q, expected none", flag.Shorthand)
 	}
 }
+
+func TestInactiveTaskState(t *testing.T) {
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, err := ws.CreateWork("parent", storage.SourceInfo{Type: "file"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := inactiveTaskState(ws, parent); got != "unknown" {
+		t.Errorf("state without children = %q", got)
+	}
+
+	for _, id := range []string{"child-1", "child-2"} {
+		child, err := ws.CreateWork(id, storage.SourceInfo{Type: "split"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		child.Metadata.ParentID = "parent"
+		if id == "child-1" {
+			child.Metadata.FinishedAt = time.Now()
+		}
+		if err := ws.SaveWork(child); err != nil {
+			t.Fatal(err)
+		}
+		if id == "child-1" && inactiveTaskState(ws, child) != "done" {
+			t.Errorf("finished child state = %q, want done", inactiveTaskState(ws, child))
+		}
+	}
+
+	if got := inactiveTaskState(ws, parent); got != "split (1/2)" {
+		t.Errorf("parent state = %q, want %q", got, "split (1/2)")
+	}
+	if got := jsonChildren(ws, "parent"); got == nil || *got != (jsonChildProgress{Total: 2, Done: 1}) {
+		t.Errorf("jsonChildren() = %+v", got)
+	}
+}
@@ -0,0 +1,233 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+var (
+	syncStrategy    string
+	syncRemote      string
+	syncNoFetch     bool
+	syncNoResolve   bool
+	syncMergeShort  bool
+	syncRebaseShort bool
+	syncYes         bool
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Bring the base branch's new commits into the task branch",
+	Long: `Sync the task branch with its base branch, so long-running tasks don't
+drift from main.
+
+Sync fetches the base branch from the remote, then rebases the task branch
+onto it (default) or merges it in. Uncommitted changes are checkpointed
+first, so 'mehr undo' returns to the state before the sync.
+
+When the rebase or merge conflicts, a conflict report goes to the agent in
+a conflict resolution step (agent.steps.resolving): the conflicting hunks,
+and the commits on both sides that touched the files. The agent's
+resolution is applied and shown with the report; once you confirm it, it is
+committed and the rebase or merge continued. Declining, --no-resolve, or an
+agent leaving conflict markers behind aborts the sync and leaves the branch
+as it was.
+
+The task is checkpointed before and after the sync, so 'mehr undo' returns
+to the branch as it was before.
+
+Run sync where the task branch is checked out: in its worktree for
+--worktree tasks.
+
+Rebasing rewrites the task's commits. If the branch was already pushed,
+use --merge (or git.sync_strategy: merge) to avoid force-pushing.
+
+Examples:
+  mehr sync                 # Fetch and rebase onto the base branch
+  mehr sync --merge         # Merge the base branch in instead
+  mehr sync --no-fetch      # Use the local base branch
+  mehr sync --no-resolve    # Stop on conflicts instead of asking the agent
+  mehr sync --yes           # Commit the agent's resolution without asking`,
+	Args: cobra.NoArgs,
+	RunE: runSync,
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+
+	syncCmd.Flags().StringVar(&syncStrategy, "strategy", "", "How to sync: rebase or merge (default: git.sync_strategy, else rebase)")
+	syncCmd.Flags().BoolVar(&syncMergeShort, "merge", false, "Shorthand for --strategy merge")
+	syncCmd.Flags().BoolVar(&syncRebaseShort, "rebase", false, "Shorthand for --strategy rebase")
+	syncCmd.Flags().StringVar(&syncRemote, "remote", "origin", "Remote to fetch the base branch from")
+	syncCmd.Flags().BoolVar(&syncNoFetch, "no-fetch", false, "Don't fetch; sync with the local base branch")
+	syncCmd.Flags().BoolVar(&syncNoResolve, "no-resolve", false, "Abort on conflicts instead of resolving them with the agent")
+	syncCmd.Flags().BoolVarP(&syncYes, "yes", "y", false, "Commit the agent's conflict resolution without confirmation")
+
+	syncCmd.MarkFlagsMutuallyExclusive("strategy", "merge", "rebase")
+}
+
+// resolveSyncStrategy combines --strategy with its --merge and --rebase shorthands.
+func resolveSyncStrategy(strategy string, merge, rebase bool) (string, error) {
+	switch {
+	case merge:
+		return conductor.SyncMerge, nil
+	case rebase:
+		return conductor.SyncRebase, nil
+	case strategy == "":
+		return "", nil // Defer to config
+	default:
+		return conductor.ParseSyncStrategy(strategy)
+	}
+}
+
+func runSync(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+
+	strategy, err := resolveSyncStrategy(syncStrategy, syncMergeShort, syncRebaseShort)
+	if err != nil {
+		return err
+	}
+
+	cond, err := initializeConductor(ctx,
+		conductor.WithVerbose(verbose),
+		conductor.WithConflictResolutionCallback(conflictResolutionPrompt(cmd, syncYes)),
+	)
+	if err != nil {
+		return err
+	}
+	if cond.GetActiveTask() == nil {
+		fmt.Print(display.NoActiveTaskError())
+
+		return errors.New("no active task")
+	}
+
+	result, err := cond.Sync(ctx, conductor.SyncOptions{
+		Strategy:         strategy,
+		Remote:           syncRemote,
+		NoFetch:          syncNoFetch,
+		ResolveConflicts: !syncNoResolve,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, conductor.ErrResolutionRejected):
+			fmt.Println(display.WarningMsg("Sync aborted; the task branch is unchanged"))
+		case errors.Is(err, conductor.ErrSyncConflict):
+			fmt.Println(display.WarningMsg("Sync aborted; the task branch is unchanged"))
+			if result != nil && len(result.Reports) > 0 {
+				fmt.Print(formatConflictReport(result.Reports[len(result.Reports)-1]))
+			}
+		}
+
+		return fmt.Errorf("sync: %w", err)
+	}
+
+	printSyncResult(result)
+
+	return nil
+}
+
+// printSyncResult reports the outcome of a sync.
+func printSyncResult(result *conductor.SyncResult) {
+	if result.UpToDate {
+		fmt.Println(display.SuccessMsg("Already up to date with %s", result.BaseRef))
+
+		return
+	}
+
+	verb := "Rebased onto"
+	if result.Strategy == conductor.SyncMerge {
+		verb = "Merged"
+	}
+	fmt.Println(display.SuccessMsg("%s %s (%d new commit(s))", verb, result.BaseRef, result.Behind))
+	if result.Resolved {
+		fmt.Printf("  Agent resolved conflicts in: %s\n", strings.Join(result.Conflicts, ", "))
+	}
+}
+
+// conflictResolutionPrompt returns the callback that confirms the agent's
+// conflict resolution before it is committed; yes accepts it without asking.
+func conflictResolutionPrompt(cmd *cobra.Command, yes bool) func(*conductor.ConflictReport, *conductor.ConflictResolution) bool {
+	return func(report *conductor.ConflictReport, resolution *conductor.ConflictResolution) bool {
+		if yes {
+			return true
+		}
+
+		return confirmConflictResolution(cmd.InOrStdin(), cmd.OutOrStdout(), report, resolution)
+	}
+}
+
+// confirmConflictResolution shows a conflict report with the agent's
+// resolution and asks whether to commit it.
+func confirmConflictResolution(in io.Reader, out io.Writer, report *conductor.ConflictReport, resolution *conductor.ConflictResolution) bool {
+	_, _ = fmt.Fprintln(out)
+	_, _ = fmt.Fprint(out, formatConflictReport(report))
+	_, _ = fmt.Fprintln(out, display.Bold("Agent's resolution:"))
+	if resolution.Summary != "" {
+		_, _ = fmt.Fprintf(out, "%s\n\n", resolution.Summary)
+	}
+	_, _ = fmt.Fprintln(out, resolution.Diff)
+	_, _ = fmt.Fprint(out, "Commit this resolution? [y/N]: ")
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	}
+
+	return false
+}
+
+// formatConflictReport renders a conflict report: where the conflicts are and
+// which commits on either side caused them.
+func formatConflictReport(report *conductor.ConflictReport) string {
+	var sb strings.Builder
+
+	verb := "rebasing onto"
+	if report.Operation == vcs.OperationMerge {
+		verb = "merging"
+	}
+	sb.WriteString(display.WarningMsg("Conflicts %s %s", verb, report.BaseRef) + "\n")
+	if report.Replaying != "" {
+		fmt.Fprintf(&sb, "  Replaying %s\n", report.Replaying)
+	}
+
+	for _, file := range report.Files {
+		lines := make([]string, 0, len(file.Hunks))
+		for _, hunk := range file.Hunks {
+			lines = append(lines, fmt.Sprintf("%d", hunk.Line))
+		}
+		fmt.Fprintf(&sb, "  %s: %d conflict(s) at line %s\n", file.Path, len(file.Hunks), strings.Join(lines, ", "))
+	}
+
+	for _, side := range []struct {
+		name    string
+		commits []string
+	}{
+		{report.Branch, report.TaskCommits},
+		{report.BaseRef, report.BaseCommits},
+	} {
+		if len(side.commits) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "  %s\n", display.Muted(side.name+" changed them in:"))
+		for _, commit := range side.commits {
+			fmt.Fprintf(&sb, "    %s\n", commit)
+		}
+	}
+	sb.WriteString("\n")
+
+	return sb.String()
+}
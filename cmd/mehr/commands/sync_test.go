@@ -0,0 +1,122 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+func TestSyncCommand_Structure(t *testing.T) {
+	if syncCmd.Use != "sync" {
+		t.Errorf("Use = %q, want %q", syncCmd.Use, "sync")
+	}
+	if syncCmd.RunE == nil {
+		t.Error("RunE not set")
+	}
+}
+
+func TestSyncCommand_Flags(t *testing.T) {
+	tests := []struct {
+		flagName     string
+		defaultValue string
+	}{
+		{"strategy", ""},
+		{"merge", "false"},
+		{"rebase", "false"},
+		{"remote", "origin"},
+		{"no-fetch", "false"},
+		{"no-resolve", "false"},
+		{"yes", "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flagName, func(t *testing.T) {
+			flag := syncCmd.Flags().Lookup(tt.flagName)
+			if flag == nil {
+				t.Fatalf("flag %q not found", tt.flagName)
+			}
+			if flag.DefValue != tt.defaultValue {
+				t.Errorf("default = %q, want %q", flag.DefValue, tt.defaultValue)
+			}
+		})
+	}
+}
+
+func TestResolveSyncStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		merge    bool
+		rebase   bool
+		want     string
+		wantErr  bool
+	}{
+		{name: "config default", want: ""},
+		{name: "merge shorthand", merge: true, want: conductor.SyncMerge},
+		{name: "rebase shorthand", rebase: true, want: conductor.SyncRebase},
+		{name: "explicit merge", strategy: "merge", want: conductor.SyncMerge},
+		{name: "unknown", strategy: "octopus", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveSyncStrategy(tt.strategy, tt.merge, tt.rebase)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveSyncStrategy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfirmConflictResolution(t *testing.T) {
+	report := &conductor.ConflictReport{
+		Operation:   vcs.OperationRebase,
+		Branch:      "task/cache",
+		BaseRef:     "origin/main",
+		Replaying:   "abc1234 Cache lookups",
+		TaskCommits: []string{"abc1234 Cache lookups"},
+		BaseCommits: []string{"def5678 Rename lookup"},
+		Files:       []conductor.ConflictFile{{Path: "lookup.go", Hunks: []conductor.ConflictHunk{{Line: 12}, {Line: 40}}}},
+	}
+	resolution := &conductor.ConflictResolution{Files: []string{"lookup.go"}, Summary: "Kept the rename and the cache", Diff: "+cached := lookup(key)"}
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{input: "y\n", want: true},
+		{input: "yes\n", want: true},
+		{input: "\n", want: false},
+		{input: "n\n", want: false},
+		{input: "", want: false},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if got := confirmConflictResolution(strings.NewReader(tt.input), &out, report, resolution); got != tt.want {
+			t.Errorf("confirmConflictResolution(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+
+		for _, want := range []string{
+			"Conflicts rebasing onto origin/main",
+			"Replaying abc1234 Cache lookups",
+			"lookup.go: 2 conflict(s) at line 12, 40",
+			"def5678 Rename lookup",
+			"Kept the rename and the cache",
+			"+cached := lookup(key)",
+		} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("output missing %q:\n%s", want, out.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var (
+	taskSwitchWIP   bool
+	taskStealForce  bool
+	taskReportJSON  bool
+	taskReportFiles bool
+)
+
+var taskCmd = &cobra.Command{
+	Use:   "task",
+	Short: "Inspect task ownership and planning accuracy",
+	Long: `Inspect and manage the active task.
+
+While a workflow step runs, the conductor holds a lease on the task (owner,
+hostname, process, expiry) and renews it with a heartbeat. A second conductor
+trying to operate on the same task, for example from another machine sharing
+the repository, fails until the lease is released or expires.
+
+The report subcommand compares each specification's predicted files with the
+files actually changed, to help judge and improve planning quality.
+
+The sync subcommand refreshes the task's source snapshot from its provider.
+
+The switch subcommand parks the active task and makes another one active.
+
+The push, pull and remote subcommands share work directories through the
+remote storage configured in storage.remote.`,
+}
+
+var taskStealCmd = &cobra.Command{
+	Use:   "steal [task-id]",
+	Short: "Take over an expired task lease",
+	Long: `Take over the lease on a task held by another conductor.
+
+Only expired leases are taken unless --force is given. A lease also counts as
+expired when its process no longer exists on this host. After stealing, the
+lease is reserved for you on this host until your next command claims it.
+
+Defaults to the active task when no task ID is given.`,
+	Example: `  mehr task steal
+  mehr task steal a1b2c3d4 --force`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runTaskSteal,
+}
+
+var taskSwitchCmd = &cobra.Command{
+	Use:   "switch <task-id>",
+	Short: "Park the active task and make another one active",
+	Long: `Park the active task and make another task active, checking out its branch.
+
+With uncommitted changes, you are asked whether to commit them as a WIP commit
+on the active task's branch (--wip answers yes). Switching back undoes the WIP
+commit, so the changes are uncommitted again, as long as nothing was committed
+on top of it.
+
+Tasks in their own worktree are not switched; cd into the worktree instead.`,
+	Example: `  mehr list
+  mehr task switch a1b2c3d4
+  mehr task switch a1b2c3d4 --wip`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTaskSwitch,
+}
+
+var taskReportCmd = &cobra.Command{
+	Use:   "report [task-id]",
+	Short: "Report spec accuracy (planned vs actual files)",
+	Long: `Compare, per specification, the files the plan said it would touch with the
+files actually changed during implementation.
+
+Predicted files are taken from the specification (preferring a "Files"
+section); actual files are recorded by 'mehr implement'. Drift is the share of
+involved files that were unplanned or missed: 0% means the plan was exact.
+
+Defaults to the active task when no task ID is given.`,
+	Example: `  mehr task report
+  mehr task report --files
+  mehr task report a1b2c3d4 --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runTaskReport,
+}
+
+var taskSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Refresh the active task's source snapshot",
+	Long: `Re-fetch the active task's source and store it as a new revision.
+
+Same as 'mehr refresh'; see 'mehr refresh --help'.`,
+	Example: `  mehr task sync`,
+	Args:    cobra.NoArgs,
+	RunE:    runRefresh,
+}
+
+func init() {
+	rootCmd.AddCommand(taskCmd)
+	taskCmd.AddCommand(taskSwitchCmd)
+	taskCmd.AddCommand(taskStealCmd)
+	taskCmd.AddCommand(taskReportCmd)
+	taskCmd.AddCommand(taskSyncCmd)
+
+	taskSwitchCmd.Flags().BoolVar(&taskSwitchWIP, "wip", false, "Commit uncommitted changes as WIP without asking")
+
+	taskStealCmd.Flags().BoolVarP(&taskStealForce, "force", "f", false, "Take the lease even if it has not expired")
+
+	taskReportCmd.Flags().BoolVar(&taskReportJSON, "json", false, "Output as JSON")
+	taskReportCmd.Flags().BoolVar(&taskReportFiles, "files", false, "List unplanned and missed files")
+}
+
+func runTaskSwitch(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	out := cmd.OutOrStdout()
+
+	switchTo := func(stash bool) (*conductor.SwitchResult, error) {
+		cond, err := initializeConductor(ctx, conductor.WithVerbose(verbose), conductor.WithStashWIP(stash))
+		if err != nil {
+			return nil, err
+		}
+
+		return cond.SwitchTask(ctx, args[0])
+	}
+
+	res, err := switchTo(taskSwitchWIP)
+	if errors.Is(err, conductor.ErrUncommittedChanges) && !taskSwitchWIP && isInteractiveInput(cmd.InOrStdin()) {
+		ok, confirmErr := confirmAction(err.Error()+"\nCommit them as WIP on its branch? They are restored when you switch back.", false)
+		if confirmErr != nil {
+			return confirmErr
+		}
+		if ok {
+			res, err = switchTo(true)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, conductor.ErrUncommittedChanges) {
+			return fmt.Errorf("%w\nCommit or stash them, or use --wip to park them with the task", err)
+		}
+
+		return err
+	}
+
+	if res.From != "" {
+		parked := "Parked task " + res.From
+		if res.Stashed {
+			parked += " (uncommitted changes saved as WIP)"
+		}
+		_, _ = fmt.Fprintln(out, parked)
+	}
+	active := "Switched to task " + res.To
+	if res.Branch != "" {
+		active += " on " + res.Branch
+	}
+	_, _ = fmt.Fprintln(out, active)
+	if res.Restored {
+		_, _ = fmt.Fprintln(out, "Restored its uncommitted changes")
+	}
+
+	return nil
+}
+
+func runTaskSteal(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+
+	taskID, err := resolveTaskIDArg(ws, args)
+	if err != nil {
+		return err
+	}
+
+	previous, err := ws.StealTaskLease(taskID, storage.DefaultLeaseTTL, taskStealForce)
+	if err != nil {
+		return err
+	}
+
+	if previous == nil {
+		_, _ = fmt.Fprintf(out, "Task %s had no lease; reserved it for you\n", taskID)
+
+		return nil
+	}
+
+	state := "expired"
+	if !previous.Expired(time.Now()) {
+		state = "active"
+	}
+	_, _ = fmt.Fprintf(out, "Took over %s lease on task %s from %s\n", state, taskID, previous.Holder())
+
+	return nil
+}
+
+// jsonSpecReport is the JSON output of 'mehr task report'.
+type jsonSpecReport struct {
+	TaskID string             `json:"task_id"`
+	Specs  []jsonSpecAccuracy `json:"specifications"`
+}
+
+type jsonSpecAccuracy struct {
+	storage.SpecAccuracy
+
+	Implemented bool    `json:"implemented"`
+	Precision   float64 `json:"precision"`
+	Recall      float64 `json:"recall"`
+	Drift       float64 `json:"drift"`
+}
+
+func runTaskReport(cmd *cobra.Command, args []string) error {
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+
+	taskID, err := resolveTaskIDArg(ws, args)
+	if err != nil {
+		return err
+	}
+
+	report, err := ws.SpecAccuracyReport(taskID)
+	if err != nil {
+		return fmt.Errorf("build report: %w", err)
+	}
+
+	if err := writeSpecReport(cmd.OutOrStdout(), taskID, report, taskReportJSON, taskReportFiles); err != nil {
+		return err
+	}
+	if !taskReportJSON {
+		writeSessionBookmarks(cmd.OutOrStdout(), ws, taskID)
+	}
+
+	return nil
+}
+
+// writeSpecReport renders a spec accuracy report as a table or JSON.
+func writeSpecReport(out io.Writer, taskID string, report []storage.SpecAccuracy, asJSON, listFiles bool) error {
+	if asJSON {
+		output := jsonSpecReport{TaskID: taskID, Specs: make([]jsonSpecAccuracy, 0, len(report))}
+		for _, acc := range report {
+			output.Specs = append(output.Specs, jsonSpecAccuracy{
+				SpecAccuracy: acc,
+				Implemented:  acc.Implemented(),
+				Precision:    acc.Precision(),
+				Recall:       acc.Recall(),
+				Drift:        acc.Drift(),
+			})
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(output)
+	}
+
+	if len(report) == 0 {
+		_, _ = fmt.Fprintf(out, "No specifications for task %s. Run 'mehr plan' first.\n", taskID)
+
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(out, "Spec accuracy for task %s\n\n", taskID)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SPEC\tPREDICTED\tACTUAL\tMATCHED\tUNPLANNED\tMISSED\tDRIFT")
+
+	var implemented int
+	var totalDrift float64
+	for _, acc := range report {
+		drift := "-"
+		if acc.Implemented() {
+			implemented++
+			totalDrift += acc.Drift()
+			drift = fmt.Sprintf("%.0f%%", acc.Drift()*100)
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%d\t%s\n",
+			acc.Number, len(acc.Predicted), len(acc.Actual), len(acc.Matched),
+			len(acc.Unplanned), len(acc.Missed), drift)
+	}
+	_ = w.Flush()
+
+	if implemented > 0 {
+		_, _ = fmt.Fprintf(out, "\nAverage drift: %.0f%% across %d implemented specification(s)\n",
+			totalDrift/float64(implemented)*100, implemented)
+	}
+
+	if listFiles {
+		for _, acc := range report {
+			if !acc.Implemented() || (len(acc.Unplanned) == 0 && len(acc.Missed) == 0) {
+				continue
+			}
+			_, _ = fmt.Fprintf(out, "\nSpecification %d:\n", acc.Number)
+			if len(acc.Unplanned) > 0 {
+				_, _ = fmt.Fprintf(out, "  Unplanned: %s\n", strings.Join(acc.Unplanned, ", "))
+			}
+			if len(acc.Missed) > 0 {
+				_, _ = fmt.Fprintf(out, "  Missed:    %s\n", strings.Join(acc.Missed, ", "))
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveTaskIDArg returns the task ID from args, or the active task.
+func resolveTaskIDArg(ws *storage.Workspace, args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	if !ws.HasActiveTask() {
+		return "", errors.New("no active task (pass a task ID)")
+	}
+
+	active, err := ws.LoadActiveTask()
+	if err != nil {
+		return "", fmt.Errorf("load active task: %w", err)
+	}
+
+	return active.ID, nil
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var exportTaskOutput string
+
+var exportTaskCmd = &cobra.Command{
+	Use:   "task [task-id]",
+	Short: "Export a task's state as a portable bundle",
+	Long: `Write a task's work directory (work.yaml, source, notes, specifications,
+sessions and a pending question) into a .tar.gz bundle, to move the task to
+another machine or attach it to a ticket. Restore it with 'mehr import task'.
+
+The bundle holds mehrhof state only; export the code with 'mehr export patch'.
+Defaults to the active task when no task ID is given.`,
+	Example: `  mehr export task
+  mehr export task a1b2c3d4 -o /tmp/a1b2c3d4.tar.gz`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runExportTask,
+}
+
+var importTaskCmd = &cobra.Command{
+	Use:   "task <file>",
+	Short: "Import a task bundle into this workspace",
+	Long: `Create a task from a bundle written by 'mehr export task'.
+
+Bundles written by a newer mehrhof, or whose work.yaml uses an unknown schema
+version, are rejected. A task with the same ID is never overwritten.`,
+	Example: `  mehr import task a1b2c3d4.tar.gz`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    runImportTask,
+}
+
+func init() {
+	exportCmd.AddCommand(exportTaskCmd)
+	importCmd.AddCommand(importTaskCmd)
+
+	exportTaskCmd.Flags().StringVarP(&exportTaskOutput, "output", "o", "", "File to write (default: <task>.tar.gz)")
+}
+
+func runExportTask(cmd *cobra.Command, args []string) error {
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+
+	taskID, err := resolveTaskIDArg(ws, args)
+	if err != nil {
+		return err
+	}
+
+	path := exportTaskOutput
+	if path == "" {
+		path = taskID + ".tar.gz"
+	}
+
+	var buf bytes.Buffer
+	manifest, err := ws.ExportBundle(taskID, &buf)
+	if err != nil {
+		return fmt.Errorf("export task: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write bundle: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Exported %d file(s) of task %s to %s\n", manifest.Files, taskID, path)
+
+	return nil
+}
+
+func runImportTask(cmd *cobra.Command, args []string) error {
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("open bundle: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	work, err := ws.ImportBundle(f)
+	if err != nil {
+		return fmt.Errorf("import task: %w", err)
+	}
+
+	title := work.Metadata.Title
+	if title == "" {
+		title = work.Source.Ref
+	}
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "Imported task %s: %s\n", work.Metadata.ID, title)
+	if ws.HasPendingQuestion(work.Metadata.ID) {
+		_, _ = fmt.Fprintln(out, "The task has a pending question from the agent")
+	}
+
+	return nil
+}
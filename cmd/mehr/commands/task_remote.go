@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/bucket"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var taskPushCmd = &cobra.Command{
+	Use:   "push [task-id]",
+	Short: "Upload a task's work directory to remote storage",
+	Long: `Upload a task's work directory (work.yaml, source, notes, specifications,
+sessions) to the remote storage configured in storage.remote, replacing the
+remote copy. Another developer or a CI machine can then continue from it with
+'mehr task pull'.
+
+Defaults to the active task when no task ID is given.`,
+	Example: `  mehr task push
+  mehr task push a1b2c3d4`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runTaskPush,
+}
+
+var taskPullCmd = &cobra.Command{
+	Use:   "pull [task-id]",
+	Short: "Download a task's work directory from remote storage",
+	Long: `Replace the local work directory of a task with the copy in remote storage.
+Local files the remote copy does not have are removed.
+
+Defaults to the active task when no task ID is given. See 'mehr task remote'
+for the tasks available.`,
+	Example: `  mehr task pull a1b2c3d4`,
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    runTaskPull,
+}
+
+var taskRemoteCmd = &cobra.Command{
+	Use:     "remote",
+	Short:   "List tasks in remote storage",
+	Example: `  mehr task remote`,
+	Args:    cobra.NoArgs,
+	RunE:    runTaskRemote,
+}
+
+func init() {
+	taskCmd.AddCommand(taskPushCmd)
+	taskCmd.AddCommand(taskPullCmd)
+	taskCmd.AddCommand(taskRemoteCmd)
+}
+
+// openRemoteStorage opens the workspace, honoring storage.work_dir, and the
+// remote storage it is configured with.
+func openRemoteStorage(cmd *cobra.Command) (*storage.Workspace, storage.Backend, error) {
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open workspace: %w", err)
+	}
+	cfg, err := ws.LoadConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("load config: %w", err)
+	}
+	if ws, err = storage.OpenWorkspace(res.Root, cfg); err != nil {
+		return nil, nil, fmt.Errorf("open workspace: %w", err)
+	}
+
+	backend, err := bucket.FromSettings(cfg.Storage.Remote, res.Root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ws, backend, nil
+}
+
+func runTaskPush(cmd *cobra.Command, args []string) error {
+	ws, backend, err := openRemoteStorage(cmd)
+	if err != nil {
+		return err
+	}
+
+	taskID, err := resolveTaskIDArg(ws, args)
+	if err != nil {
+		return err
+	}
+
+	result, err := ws.PushWork(cmd.Context(), backend, taskID)
+	if err != nil {
+		return fmt.Errorf("push task: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Pushed %d file(s) of task %s to %s", result.Copied, taskID, backend.Name())
+	if result.Deleted > 0 {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), " (%d removed)", result.Deleted)
+	}
+	_, _ = fmt.Fprintln(cmd.OutOrStdout())
+
+	return nil
+}
+
+func runTaskPull(cmd *cobra.Command, args []string) error {
+	ws, backend, err := openRemoteStorage(cmd)
+	if err != nil {
+		return err
+	}
+
+	taskID, err := resolveTaskIDArg(ws, args)
+	if err != nil {
+		return err
+	}
+
+	result, err := ws.PullWork(cmd.Context(), backend, taskID)
+	if err != nil {
+		return fmt.Errorf("pull task: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Pulled %d file(s) of task %s from %s", result.Copied, taskID, backend.Name())
+	if result.Deleted > 0 {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), " (%d local file(s) removed)", result.Deleted)
+	}
+	_, _ = fmt.Fprintln(cmd.OutOrStdout())
+
+	return nil
+}
+
+func runTaskRemote(cmd *cobra.Command, _ []string) error {
+	ws, backend, err := openRemoteStorage(cmd)
+	if err != nil {
+		return err
+	}
+
+	ids, err := ws.RemoteWorks(cmd.Context(), backend)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if len(ids) == 0 {
+		_, _ = fmt.Fprintf(out, "No tasks in %s\n", backend.Name())
+
+		return nil
+	}
+	_, _ = fmt.Fprintf(out, "Tasks in %s:\n", backend.Name())
+	for _, id := range ids {
+		marker := " "
+		if ws.WorkExists(id) {
+			marker = "*"
+		}
+		_, _ = fmt.Fprintf(out, "%s %s\n", marker, id)
+	}
+	_, _ = fmt.Fprintln(out, "\n* also present locally")
+
+	return nil
+}
@@ -0,0 +1,172 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestTaskCommand_Properties(t *testing.T) {
+	if taskCmd.Use != "task" {
+		t.Errorf("Use = %q, want %q", taskCmd.Use, "task")
+	}
+
+	if taskCmd.Short == "" {
+		t.Error("Short description is empty")
+	}
+
+	for _, want := range []*cobra.Command{taskSwitchCmd, taskStealCmd, taskReportCmd, taskSyncCmd, taskPushCmd, taskPullCmd, taskRemoteCmd} {
+		found := false
+		for _, sub := range taskCmd.Commands() {
+			if sub == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s subcommand not registered", want.Name())
+		}
+	}
+}
+
+func TestTaskStealCommand_Flags(t *testing.T) {
+	flag := taskStealCmd.Flags().Lookup("force")
+	if flag == nil {
+		t.Fatal("force flag not found")
+	}
+	if flag.Shorthand != "f" {
+		t.Errorf("force shorthand = %q, want %q", flag.Shorthand, "f")
+	}
+	if flag.DefValue != "false" {
+		t.Errorf("force default = %q, want %q", flag.DefValue, "false")
+	}
+
+	if taskStealCmd.RunE == nil {
+		t.Error("RunE not set")
+	}
+}
+
+func TestTaskSwitchCommand_Flags(t *testing.T) {
+	flag := taskSwitchCmd.Flags().Lookup("wip")
+	if flag == nil {
+		t.Fatal("wip flag not found")
+	}
+	if flag.DefValue != "false" {
+		t.Errorf("wip default = %q, want %q", flag.DefValue, "false")
+	}
+	if err := taskSwitchCmd.Args(taskSwitchCmd, nil); err == nil {
+		t.Error("switch should require a task ID")
+	}
+}
+
+func TestResolveTaskIDArg(t *testing.T) {
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+
+	if _, err := resolveTaskIDArg(ws, nil); err == nil {
+		t.Error("expected error without active task")
+	}
+
+	if id, err := resolveTaskIDArg(ws, []string{"explicit"}); err != nil || id != "explicit" {
+		t.Errorf("resolveTaskIDArg(explicit) = %q, %v", id, err)
+	}
+
+	if err := ws.SaveActiveTask(&storage.ActiveTask{ID: "active1"}); err != nil {
+		t.Fatalf("SaveActiveTask: %v", err)
+	}
+	if id, err := resolveTaskIDArg(ws, nil); err != nil || id != "active1" {
+		t.Errorf("resolveTaskIDArg() = %q, %v, want active1", id, err)
+	}
+}
+
+func TestTaskReportCommand_Flags(t *testing.T) {
+	for _, name := range []string{"json", "files"} {
+		flag := taskReportCmd.Flags().Lookup(name)
+		if flag == nil {
+			t.Errorf("%s flag not found", name)
+
+			continue
+		}
+		if flag.DefValue != "false" {
+			t.Errorf("%s default = %q, want %q", name, flag.DefValue, "false")
+		}
+	}
+}
+
+func TestWriteSpecReport(t *testing.T) {
+	report := []storage.SpecAccuracy{
+		storage.ComputeSpecAccuracy(&storage.Specification{
+			Number:           1,
+			PredictedFiles:   []string{"a.go", "b.go"},
+			ImplementedFiles: []string{"a.go", "c.go"},
+		}),
+		storage.ComputeSpecAccuracy(&storage.Specification{
+			Number:         2,
+			PredictedFiles: []string{"d.go"},
+		}),
+	}
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeSpecReport(&buf, "task1", report, false, true); err != nil {
+			t.Fatalf("writeSpecReport: %v", err)
+		}
+
+		out := buf.String()
+		for _, want := range []string{"SPEC", "67%", "Average drift: 67% across 1", "Unplanned: c.go", "Missed:    b.go"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("output missing %q:\n%s", want, out)
+			}
+		}
+		if strings.Contains(out, "Specification 2:") {
+			t.Errorf("unimplemented spec should not list files:\n%s", out)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeSpecReport(&buf, "task1", report, true, false); err != nil {
+			t.Fatalf("writeSpecReport: %v", err)
+		}
+
+		var got struct {
+			TaskID string `json:"task_id"`
+			Specs  []struct {
+				Number      int     `json:"number"`
+				Implemented bool    `json:"implemented"`
+				Recall      float64 `json:"recall"`
+			} `json:"specifications"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		if got.TaskID != "task1" || len(got.Specs) != 2 {
+			t.Fatalf("unexpected report: %+v", got)
+		}
+		if !got.Specs[0].Implemented || got.Specs[0].Recall != 0.5 {
+			t.Errorf("spec 1 = %+v", got.Specs[0])
+		}
+		if got.Specs[1].Implemented {
+			t.Error("spec 2 should not be implemented")
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeSpecReport(&buf, "task1", nil, false, false); err != nil {
+			t.Fatalf("writeSpecReport: %v", err)
+		}
+		if !strings.Contains(buf.String(), "No specifications") {
+			t.Errorf("output = %q", buf.String())
+		}
+	})
+}
@@ -1,13 +1,17 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/provider/tasktemplate"
+	"github.com/valksor/go-mehrhof/internal/storage"
 	"github.com/valksor/go-mehrhof/internal/template"
 )
 
@@ -24,7 +28,12 @@ Available templates:
   refactor - Code refactoring (quality-focused)
   docs     - Documentation changes (skips quality checks)
   test     - Adding or improving tests
-  chore    - Maintenance tasks and chores`,
+  chore    - Maintenance tasks and chores
+
+Task templates for recurring operational work live in .mehrhof/templates/<name>.yaml
+and define the task content, scope, workflow variant and schedule. Start a run
+with 'mehr start template:<name>' or 'mehr auto template:<name>'; every run
+creates a new task.`,
 	RunE: runTemplatesList,
 }
 
@@ -70,11 +79,41 @@ func runTemplatesList(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  %-12s %s\n", display.Bold(name), tpl.GetDescription())
 	}
 
+	// Project task templates for recurring work
+	taskTemplates, err := listTaskTemplates(cmd.Context())
+	if err != nil {
+		fmt.Println()
+		fmt.Println(display.WarningMsg("Could not load task templates: %v", err))
+	}
+	if len(taskTemplates) > 0 {
+		fmt.Println()
+		fmt.Println("Task templates (.mehrhof/templates):")
+		fmt.Println()
+		for _, tpl := range taskTemplates {
+			fmt.Printf("  %-12s %s\n", display.Bold(tpl.Name), describeTaskTemplate(tpl))
+		}
+	}
+
+	// Spec templates for mehr plan --template
+	specTemplates, err := listSpecTemplates(cmd.Context())
+	if err != nil {
+		fmt.Println()
+		fmt.Println(display.WarningMsg("Could not load spec templates: %v", err))
+	}
+	if len(specTemplates) > 0 {
+		fmt.Println()
+		fmt.Println("Spec templates:")
+		fmt.Println()
+		fmt.Printf("  %s\n", strings.Join(specTemplates, ", "))
+	}
+
 	fmt.Println()
 	fmt.Println("Usage:")
 	fmt.Println("  mehr templates show <name>     Show template details")
 	fmt.Println("  mehr templates apply <name> <file>   Apply template to file")
 	fmt.Println("  mehr start --template <name> file:task.md")
+	fmt.Println("  mehr start template:<task-template>")
+	fmt.Println("  mehr plan --template <spec-template>")
 
 	return nil
 }
@@ -181,3 +220,74 @@ func runTemplateApply(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// listTaskTemplates loads the project's task templates from .mehrhof/templates.
+func listTaskTemplates(ctx context.Context) ([]*tasktemplate.Template, error) {
+	res, err := ResolveWorkspaceRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open workspace: %w", err)
+	}
+
+	return tasktemplate.List(ws.TemplatesDir())
+}
+
+// listSpecTemplates returns the built-in and project spec templates. Outside a
+// workspace only the built-ins are listed.
+func listSpecTemplates(ctx context.Context) ([]string, error) {
+	res, err := ResolveWorkspaceRoot(ctx)
+	if err != nil {
+		return storage.BuiltInSpecTemplates(), nil //nolint:nilerr // Built-ins need no workspace
+	}
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open workspace: %w", err)
+	}
+
+	return ws.ListSpecTemplates()
+}
+
+// loadTaskTemplate returns the task template behind a template: reference, or
+// nil for other references. The template scope is resolved against the
+// repository root.
+func loadTaskTemplate(ctx context.Context, reference string) (*tasktemplate.Template, error) {
+	name, ok := strings.CutPrefix(reference, "template:")
+	if !ok {
+		return nil, nil
+	}
+
+	res, err := ResolveWorkspaceRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open workspace: %w", err)
+	}
+
+	tpl, err := tasktemplate.Load(ws.TemplatesDir(), name)
+	if err != nil {
+		return nil, err
+	}
+	if tpl.Scope != "" && !filepath.IsAbs(tpl.Scope) {
+		tpl.Scope = filepath.Join(res.Root, tpl.Scope)
+	}
+
+	return tpl, nil
+}
+
+// describeTaskTemplate returns the one-line listing description of a task template.
+func describeTaskTemplate(tpl *tasktemplate.Template) string {
+	desc := tpl.Description
+	if desc == "" {
+		desc = tpl.Title
+	}
+	if tpl.Schedule != "" {
+		desc += fmt.Sprintf(" (schedule: %s)", tpl.Schedule)
+	}
+
+	return desc
+}
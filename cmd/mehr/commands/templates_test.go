@@ -4,7 +4,10 @@
 package commands
 
 import (
+	"context"
 	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider/tasktemplate"
 )
 
 func TestTemplatesCommand_Properties(t *testing.T) {
@@ -157,3 +160,37 @@ func TestTemplateApplyCommand_DocumentsMerge(t *testing.T) {
 		t.Error("Long description does not mention merging with existing frontmatter")
 	}
 }
+
+func TestDescribeTaskTemplate(t *testing.T) {
+	tests := []struct {
+		name string
+		tpl  *tasktemplate.Template
+		want string
+	}{
+		{
+			name: "description",
+			tpl:  &tasktemplate.Template{Title: "Rotate secrets", Description: "Monthly rotation"},
+			want: "Monthly rotation",
+		},
+		{
+			name: "title fallback with schedule",
+			tpl:  &tasktemplate.Template{Title: "Rotate secrets", Schedule: "0 9 1 * *"},
+			want: "Rotate secrets (schedule: 0 9 1 * *)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeTaskTemplate(tt.tpl); got != tt.want {
+				t.Errorf("describeTaskTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadTaskTemplate_OtherReference(t *testing.T) {
+	tpl, err := loadTaskTemplate(context.Background(), "file:task.md")
+	if err != nil || tpl != nil {
+		t.Errorf("loadTaskTemplate() = %v, %v, want nil, nil", tpl, err)
+	}
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/tui"
+)
+
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Open a live terminal dashboard for the active task",
+	Long: `Open a full-screen dashboard showing the active task's state, specification
+statuses, token usage, checkpoint history and a live stream of workflow
+events. The dashboard follows the conductor's event bus, so it updates as
+soon as something happens.
+
+KEYS:
+  p  Plan
+  i  Implement
+  r  Review
+  u  Undo to the previous checkpoint
+  q  Quit (cancels a running operation)
+
+One operation runs at a time. Answer agent questions with 'mehr note' from
+another terminal; the dashboard shows when one is pending.`,
+	Args: cobra.NoArgs,
+	RunE: runUI,
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+}
+
+func runUI(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	// Output is drawn by the dashboard; plain conductor output would
+	// corrupt the screen.
+	opts := append(BuildConductorOptions(CommandOptions{Verbose: verbose}),
+		conductor.WithStdout(io.Discard),
+		conductor.WithStderr(io.Discard),
+		conductor.WithConfigValidator(validateReloadedConfig),
+	)
+	cond, err := initializeConductor(ctx, opts...)
+	if err != nil {
+		return err
+	}
+
+	return tui.New(cond, cmd.InOrStdin(), cmd.OutOrStdout()).Run(ctx)
+}
@@ -0,0 +1,23 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUICommand_Properties(t *testing.T) {
+	if uiCmd.Use != "ui" {
+		t.Errorf("Use = %q, want %q", uiCmd.Use, "ui")
+	}
+	if uiCmd.RunE == nil {
+		t.Error("RunE not set")
+	}
+	for _, key := range []string{"p  Plan", "i  Implement", "r  Review", "u  Undo", "q  Quit"} {
+		if !strings.Contains(uiCmd.Long, key) {
+			t.Errorf("Long description does not document key %q", key)
+		}
+	}
+}
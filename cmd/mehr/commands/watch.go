@@ -0,0 +1,341 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/fswatch"
+	"github.com/valksor/go-mehrhof/internal/notify"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+var (
+	watchTask   string
+	watchJSON   bool
+	watchNotify bool
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Follow workspace changes live",
+	Long: `Print changes to the workspace as they happen: state transitions of the
+active task, questions the agent is waiting on, answers, and new agent
+sessions.
+
+Useful when the work runs in another terminal or on a remote machine over
+SSH: keep 'mehr watch' open to see when the agent needs you. On start, the
+current state and any pending question are printed; the command then runs
+until interrupted.
+
+Changes are picked up through inotify on Linux and by polling the workspace
+every half second elsewhere.`,
+	Example: `  mehr watch
+  mehr watch --task a1b2c3d4
+  mehr watch --notify
+  mehr watch --json | jq 'select(.kind == "question")'`,
+	Args: cobra.NoArgs,
+	RunE: runWatch,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().StringVar(&watchTask, "task", "", "Only follow this task (default: all tasks)")
+	watchCmd.Flags().BoolVar(&watchJSON, "json", false, "Print changes as JSON lines")
+	watchCmd.Flags().BoolVar(&watchNotify, "notify", false, "Show a desktop notification when the agent asks a question")
+}
+
+// Kinds of workspace changes reported by 'mehr watch'.
+const (
+	watchKindState    = "state"    // The active task's state changed
+	watchKindInactive = "inactive" // No task is active anymore
+	watchKindQuestion = "question" // The agent is waiting on an answer
+	watchKindAnswered = "answered" // The pending question was answered
+	watchKindSession  = "session"  // An agent session started
+)
+
+// watchEvent is a workspace change.
+type watchEvent struct {
+	Time     time.Time `json:"time"`
+	Kind     string    `json:"kind"`
+	TaskID   string    `json:"task_id,omitempty"`
+	From     string    `json:"from,omitempty"`
+	To       string    `json:"to,omitempty"`
+	Question string    `json:"question,omitempty"`
+	Options  []string  `json:"options,omitempty"`
+	Session  string    `json:"session,omitempty"`
+}
+
+// workspaceWatch turns file changes in the workspace into watch events,
+// remembering what was last reported so repeated writes are not reported
+// again.
+type workspaceWatch struct {
+	ws   *storage.Workspace
+	task string // Only this task's changes ("" for all)
+
+	activeID    string
+	activeState string
+	questions   map[string]string // Task ID -> pending question
+	sessions    map[string]bool   // Session files already seen
+}
+
+func newWorkspaceWatch(ws *storage.Workspace, task string) *workspaceWatch {
+	w := &workspaceWatch{ws: ws, task: task, questions: make(map[string]string), sessions: make(map[string]bool)}
+	if active, err := ws.LoadActiveTask(); err == nil {
+		w.activeID, w.activeState = active.ID, active.State
+	}
+	if ids, err := ws.ListWorks(); err == nil {
+		for _, id := range ids {
+			if q, err := ws.LoadPendingQuestion(id); err == nil {
+				w.questions[id] = q.Question
+			}
+			files, _ := filepath.Glob(filepath.Join(ws.SessionsDir(id), "*.yaml"))
+			for _, f := range files {
+				w.sessions[f] = true
+			}
+		}
+	}
+
+	return w
+}
+
+// current returns the state and pending questions at the start.
+func (w *workspaceWatch) current(now time.Time) []watchEvent {
+	var out []watchEvent
+	if w.activeID != "" && w.follows(w.activeID) {
+		out = append(out, watchEvent{Time: now, Kind: watchKindState, TaskID: w.activeID, To: w.activeState})
+	}
+	for _, id := range slices.Sorted(maps.Keys(w.questions)) {
+		if w.follows(id) {
+			out = append(out, w.questionEvent(id, now)...)
+		}
+	}
+
+	return out
+}
+
+// follows reports whether changes to the task are reported.
+func (w *workspaceWatch) follows(taskID string) bool {
+	return w.task == "" || w.task == taskID
+}
+
+// handle returns the watch events for a changed file.
+func (w *workspaceWatch) handle(e fswatch.Event, now time.Time) []watchEvent {
+	if e.Path == w.ws.ActiveTaskPath() {
+		return w.activeChanged(now)
+	}
+
+	rel, err := filepath.Rel(w.ws.WorkRoot(), e.Path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) < 2 || !w.follows(parts[0]) {
+		return nil
+	}
+	taskID := parts[0]
+
+	switch {
+	case e.Path == w.ws.PendingQuestionPath(taskID):
+		if e.Op == fswatch.Removed {
+			if _, pending := w.questions[taskID]; !pending {
+				return nil
+			}
+			delete(w.questions, taskID)
+
+			return []watchEvent{{Time: now, Kind: watchKindAnswered, TaskID: taskID}}
+		}
+		q, err := w.ws.LoadPendingQuestion(taskID)
+		if err != nil || w.questions[taskID] == q.Question {
+			return nil
+		}
+		w.questions[taskID] = q.Question
+
+		return w.questionEvent(taskID, now)
+	case filepath.Dir(e.Path) == w.ws.SessionsDir(taskID) && filepath.Ext(e.Path) == ".yaml":
+		if e.Op == fswatch.Removed || w.sessions[e.Path] {
+			return nil
+		}
+		w.sessions[e.Path] = true
+
+		return []watchEvent{{Time: now, Kind: watchKindSession, TaskID: taskID, Session: filepath.Base(e.Path)}}
+	}
+
+	return nil
+}
+
+// activeChanged reports a change of the active task or its state.
+func (w *workspaceWatch) activeChanged(now time.Time) []watchEvent {
+	active, err := w.ws.LoadActiveTask()
+	if err != nil {
+		if !w.ws.HasActiveTask() && w.activeID != "" {
+			prev := watchEvent{Time: now, Kind: watchKindInactive, TaskID: w.activeID, From: w.activeState}
+			w.activeID, w.activeState = "", ""
+			if w.follows(prev.TaskID) {
+				return []watchEvent{prev}
+			}
+		}
+
+		return nil
+	}
+	if active.ID == w.activeID && active.State == w.activeState {
+		return nil
+	}
+
+	from := w.activeState
+	if active.ID != w.activeID {
+		from = ""
+	}
+	w.activeID, w.activeState = active.ID, active.State
+	if !w.follows(active.ID) {
+		return nil
+	}
+
+	return []watchEvent{{Time: now, Kind: watchKindState, TaskID: active.ID, From: from, To: active.State}}
+}
+
+// questionEvent returns the event for the task's pending question.
+func (w *workspaceWatch) questionEvent(taskID string, now time.Time) []watchEvent {
+	q, err := w.ws.LoadPendingQuestion(taskID)
+	if err != nil {
+		return nil
+	}
+	e := watchEvent{Time: now, Kind: watchKindQuestion, TaskID: taskID, Question: q.Question}
+	for _, opt := range q.Options {
+		e.Options = append(e.Options, opt.Label)
+	}
+
+	return []watchEvent{e}
+}
+
+func runWatch(cmd *cobra.Command, _ []string) error {
+	res, err := ResolveWorkspaceRoot(cmd.Context())
+	if err != nil {
+		return err
+	}
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+	if watchTask != "" && !ws.WorkExists(watchTask) {
+		return fmt.Errorf("task not found: %s", watchTask)
+	}
+	if err := os.MkdirAll(ws.WorkRoot(), 0o755); err != nil {
+		return fmt.Errorf("create work directory: %w", err)
+	}
+
+	watcher := fswatch.New()
+	defer func() { _ = watcher.Close() }()
+	if err := watcher.Add(filepath.Dir(ws.ActiveTaskPath()), false); err != nil {
+		return fmt.Errorf("watch workspace: %w", err)
+	}
+	if err := watcher.Add(ws.WorkRoot(), true); err != nil {
+		return fmt.Errorf("watch work directory: %w", err)
+	}
+
+	var notifier *notify.Notifier
+	if watchNotify {
+		notifier = notify.New(notify.NewDesktop())
+	}
+
+	out := cmd.OutOrStdout()
+	report := func(ctx context.Context, events []watchEvent) error {
+		for _, e := range events {
+			if err := writeWatchEvent(out, e, watchJSON); err != nil {
+				return err
+			}
+			if e.Kind == watchKindQuestion && notifier != nil {
+				if err := notifier.Notify(ctx, questionNotification(e)); err != nil {
+					_, _ = fmt.Fprintln(cmd.ErrOrStderr(), display.Muted("notification failed: "+err.Error()))
+				}
+			}
+		}
+
+		return nil
+	}
+
+	w := newWorkspaceWatch(ws, watchTask)
+	if !watchJSON {
+		_, _ = fmt.Fprintln(out, display.Muted("Watching "+ws.Root()+" (Ctrl+C to stop)"))
+	}
+	ctx := cmd.Context()
+	if err := report(ctx, w.current(time.Now())); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-watcher.Events():
+			if !ok {
+				return nil
+			}
+			if err := report(ctx, w.handle(e, time.Now())); err != nil {
+				return err
+			}
+		case err := <-watcher.Errors():
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), display.Muted("watch: "+err.Error()))
+		}
+	}
+}
+
+// questionNotification returns the desktop notification for a question.
+func questionNotification(e watchEvent) notify.Notification {
+	return notify.Notification{
+		Event:     string(events.TypeQuestionPending),
+		TaskID:    e.TaskID,
+		Title:     "Agent question (" + e.TaskID + ")",
+		Message:   e.Question,
+		Options:   e.Options,
+		Timestamp: e.Time,
+	}
+}
+
+// writeWatchEvent prints a watch event as a line, or as JSON.
+func writeWatchEvent(out io.Writer, e watchEvent, asJSON bool) error {
+	if asJSON {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("encode watch event: %w", err)
+		}
+		_, err = fmt.Fprintln(out, string(data))
+
+		return err
+	}
+
+	prefix := e.Time.Local().Format("15:04:05") + "  " + display.Bold(e.TaskID) + "  "
+	switch e.Kind {
+	case watchKindState:
+		if e.From == "" {
+			_, _ = fmt.Fprintf(out, "%sstate: %s\n", prefix, e.To)
+		} else {
+			_, _ = fmt.Fprintf(out, "%sstate: %s → %s\n", prefix, e.From, e.To)
+		}
+	case watchKindInactive:
+		_, _ = fmt.Fprintf(out, "%sno longer active\n", prefix)
+	case watchKindQuestion:
+		_, _ = fmt.Fprintf(out, "%s%s %s\n", prefix, display.Warning("question:"), e.Question)
+		for i, opt := range e.Options {
+			_, _ = fmt.Fprintf(out, "            %d. %s\n", i+1, opt)
+		}
+		_, _ = fmt.Fprintln(out, display.Muted("            answer with: mehr answer \"...\""))
+	case watchKindAnswered:
+		_, _ = fmt.Fprintf(out, "%squestion answered\n", prefix)
+	case watchKindSession:
+		_, _ = fmt.Fprintf(out, "%snew session %s\n", prefix, e.Session)
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/fswatch"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestWatchCommand_Structure(t *testing.T) {
+	if watchCmd.Use != "watch" {
+		t.Errorf("Use = %q", watchCmd.Use)
+	}
+	for _, flag := range []string{"task", "json", "notify"} {
+		if watchCmd.Flags().Lookup(flag) == nil {
+			t.Errorf("watch has no --%s flag", flag)
+		}
+	}
+	found := false
+	for _, cmd := range rootCmd.Commands() {
+		if cmd == watchCmd {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("watch command not registered")
+	}
+}
+
+func TestWorkspaceWatch(t *testing.T) {
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"abc", "other"} {
+		if err := os.MkdirAll(ws.SessionsDir(id), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ws.SaveActiveTask(&storage.ActiveTask{ID: "abc", State: "planning"}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	w := newWorkspaceWatch(ws, "abc")
+	if got := w.current(now); len(got) != 1 || got[0].Kind != watchKindState || got[0].To != "planning" {
+		t.Fatalf("current() = %+v", got)
+	}
+
+	changed := func(path string) []watchEvent {
+		return w.handle(fswatch.Event{Path: path, Op: fswatch.Changed}, now)
+	}
+
+	if err := ws.SaveActiveTask(&storage.ActiveTask{ID: "abc", State: "implementing"}); err != nil {
+		t.Fatal(err)
+	}
+	got := changed(ws.ActiveTaskPath())
+	if len(got) != 1 || got[0].From != "planning" || got[0].To != "implementing" {
+		t.Errorf("state change = %+v", got)
+	}
+	if got := changed(ws.ActiveTaskPath()); len(got) != 0 {
+		t.Errorf("unchanged state reported again: %+v", got)
+	}
+
+	q := &storage.PendingQuestion{Question: "Which cache?", Options: []storage.QuestionOption{{Label: "Redis"}}}
+	if err := ws.SavePendingQuestion("abc", q); err != nil {
+		t.Fatal(err)
+	}
+	got = changed(ws.PendingQuestionPath("abc"))
+	if len(got) != 1 || got[0].Kind != watchKindQuestion || got[0].Question != "Which cache?" || got[0].Options[0] != "Redis" {
+		t.Errorf("question = %+v", got)
+	}
+	if got := changed(ws.PendingQuestionPath("abc")); len(got) != 0 {
+		t.Errorf("question reported twice: %+v", got)
+	}
+	if err := ws.ClearPendingQuestion("abc"); err != nil {
+		t.Fatal(err)
+	}
+	got = w.handle(fswatch.Event{Path: ws.PendingQuestionPath("abc"), Op: fswatch.Removed}, now)
+	if len(got) != 1 || got[0].Kind != watchKindAnswered {
+		t.Errorf("answer = %+v", got)
+	}
+
+	session := filepath.Join(ws.SessionsDir("abc"), "2026-01-01T10-00-00-implementation.yaml")
+	got = changed(session)
+	if len(got) != 1 || got[0].Session != "2026-01-01T10-00-00-implementation.yaml" {
+		t.Errorf("session = %+v", got)
+	}
+	if got := changed(session); len(got) != 0 {
+		t.Errorf("session update reported as new: %+v", got)
+	}
+
+	// Other tasks are not followed
+	if got := changed(filepath.Join(ws.SessionsDir("other"), "x.yaml")); len(got) != 0 {
+		t.Errorf("other task reported: %+v", got)
+	}
+
+	if err := ws.ClearActiveTask(); err != nil {
+		t.Fatal(err)
+	}
+	got = w.handle(fswatch.Event{Path: ws.ActiveTaskPath(), Op: fswatch.Removed}, now)
+	if len(got) != 1 || got[0].Kind != watchKindInactive {
+		t.Errorf("inactive = %+v", got)
+	}
+}
+
+func TestWriteWatchEvent(t *testing.T) {
+	at := time.Date(2026, 1, 15, 10, 30, 0, 0, time.Local)
+	var buf bytes.Buffer
+	_ = writeWatchEvent(&buf, watchEvent{Time: at, Kind: watchKindState, TaskID: "abc", From: "planning", To: "implementing"}, false)
+	_ = writeWatchEvent(&buf, watchEvent{Time: at, Kind: watchKindQuestion, TaskID: "abc", Question: "Which cache?", Options: []string{"Redis"}}, false)
+	out := buf.String()
+	for _, want := range []string{"10:30:00", "planning → implementing", "Which cache?", "1. Redis", "mehr answer"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	_ = writeWatchEvent(&buf, watchEvent{Time: at, Kind: watchKindSession, TaskID: "abc", Session: "s.yaml"}, true)
+	if !strings.Contains(buf.String(), `"kind":"session"`) || !strings.Contains(buf.String(), `"session":"s.yaml"`) {
+		t.Errorf("JSON output = %s", buf.String())
+	}
+}
@@ -23,6 +23,7 @@ States:
   planning      AI agent creating specifications
   implementing  AI agent implementing code
   reviewing     Code review in progress
+  documenting   AI agent updating documentation (optional)
   waiting       Waiting for your answer to agent question
   checkpointing Creating git checkpoint
   reverting     Undo operation (restore previous checkpoint)
@@ -118,6 +119,7 @@ COMMANDS BY STATE:
     mehr plan             Create specifications
     mehr implement        Generate code from specifications
     mehr review           Run code review (optional)
+    mehr document         Update affected documentation (optional)
     mehr finish           Complete task (creates PR or merges)
     mehr undo             Revert to previous checkpoint
     mehr redo             Restore forward checkpoint
@@ -141,6 +143,8 @@ KEY TRANSITIONS:
   • implementing → idle     Implementation completes
   • idle → reviewing        "mehr review" (optional)
   • reviewing → idle        Review completes
+  • idle → documenting      "mehr document" (optional)
+  • documenting → idle      Documentation update completes
   • idle → done             "mehr finish"
   • idle → reverting        "mehr undo"
   • idle → restoring        "mehr redo"
@@ -0,0 +1,413 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+var (
+	worktreesPruneDryRun bool
+	worktreesPruneForce  bool
+)
+
+var worktreesCmd = &cobra.Command{
+	Use:   "worktrees",
+	Short: "Manage the pool of task worktrees",
+	Long: `List and clean up the git worktrees mehrhof creates for tasks in
+../<repo>-worktrees, or in git.worktree_dir when configured.
+
+Worktrees are removed when a task is finished or abandoned through mehrhof.
+Tasks deleted by hand, or crashed runs, leave worktrees behind; these are
+orphaned and can be pruned.
+
+Examples:
+  mehr worktrees list              # Show worktrees and their tasks
+  mehr worktrees prune --dry-run   # Show what prune would remove
+  mehr worktrees prune             # Remove orphaned worktrees`,
+}
+
+var worktreesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List task worktrees and the tasks they belong to",
+	Long: `List the worktrees in the worktrees directory with their task.
+
+Status:
+  active    Worktree of the active task
+  task      Worktree of another task in the workspace
+  orphaned  No task in the workspace uses the worktree
+  missing   Git still tracks the worktree but its directory is gone
+
+Lock:
+  locked          Locked with 'git worktree lock'
+  locked (stale)  Locked, but orphaned and its directory is gone
+  leased by ...   A running conductor holds the task's lease
+  stale lease     The task's lease expired or its process died`,
+	Args: cobra.NoArgs,
+	RunE: runWorktreesList,
+}
+
+var worktreesPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove orphaned worktrees and their branches",
+	Long: `Remove worktrees no task in the workspace uses, then prune git's
+records of worktrees whose directory is gone.
+
+Prune is careful by default:
+  - worktrees whose task is leased by a running conductor are skipped
+  - worktrees with uncommitted changes are kept
+  - worktrees locked with 'git worktree lock' are kept, unless the lock is
+    stale (the directory is gone)
+  - branches are only deleted when merged into the base branch
+
+--force removes dirty and locked worktrees and deletes unmerged branches.
+Stale task leases of pruned worktrees are cleared.`,
+	Example: `  mehr worktrees prune --dry-run
+  mehr worktrees prune
+  mehr worktrees prune --force`,
+	Args: cobra.NoArgs,
+	RunE: runWorktreesPrune,
+}
+
+func init() {
+	rootCmd.AddCommand(worktreesCmd)
+	worktreesCmd.AddCommand(worktreesListCmd)
+	worktreesCmd.AddCommand(worktreesPruneCmd)
+
+	worktreesPruneCmd.Flags().BoolVar(&worktreesPruneDryRun, "dry-run", false, "Show what would be removed without removing anything")
+	worktreesPruneCmd.Flags().BoolVarP(&worktreesPruneForce, "force", "f", false, "Remove dirty and locked worktrees and unmerged branches")
+}
+
+// worktreeEntry is a managed worktree with its task association.
+type worktreeEntry struct {
+	vcs.ManagedWorktree
+
+	Task   string             // Task using the worktree ("" = orphaned)
+	Active bool               // The task is the active task
+	Lease  *storage.TaskLease // The task's lease, if any
+}
+
+// taskID returns the task the worktree belongs to. Orphaned worktrees are
+// assumed to follow the default naming, where the directory is the task ID.
+func (e worktreeEntry) taskID() string {
+	if e.Task != "" {
+		return e.Task
+	}
+
+	return e.Name
+}
+
+// orphaned reports whether no task in the workspace uses the worktree.
+func (e worktreeEntry) orphaned() bool {
+	return e.Task == ""
+}
+
+// staleLock reports whether a git lock no longer protects anything.
+func (e worktreeEntry) staleLock() bool {
+	return e.Locked && e.orphaned() && e.Missing
+}
+
+// leaseHeld reports whether a live conductor holds the task's lease.
+func (e worktreeEntry) leaseHeld(now time.Time) bool {
+	return e.Lease != nil && !e.Lease.Expired(now)
+}
+
+// status describes the worktree's relation to the workspace.
+func (e worktreeEntry) status() string {
+	switch {
+	case e.Missing:
+		return "missing"
+	case e.Active:
+		return "active"
+	case e.orphaned():
+		return "orphaned"
+	default:
+		return "task"
+	}
+}
+
+// lockStatus describes git locks and task leases on the worktree.
+func (e worktreeEntry) lockStatus(now time.Time) string {
+	switch {
+	case e.staleLock():
+		return "locked (stale)"
+	case e.Locked:
+		return "locked"
+	case e.leaseHeld(now):
+		return "leased by " + e.Lease.Holder()
+	case e.Lease != nil:
+		return "stale lease"
+	default:
+		return "-"
+	}
+}
+
+// worktreePool is the worktrees directory of the main repository.
+type worktreePool struct {
+	git *vcs.Git
+	ws  *storage.Workspace
+	dir string // Worktrees directory (git.worktree_dir)
+}
+
+// openWorktreePool opens the workspace and the main repository's git.
+func openWorktreePool(ctx context.Context) (*worktreePool, error) {
+	res, err := ResolveWorkspaceRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if res.Git == nil {
+		return nil, errors.New("not in a git repository")
+	}
+
+	// From inside a worktree, the pool is relative to the main repository
+	git, err := vcs.New(ctx, res.Root)
+	if err != nil {
+		return nil, fmt.Errorf("open repository: %w", err)
+	}
+
+	ws, err := storage.OpenWorkspace(res.Root, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open workspace: %w", err)
+	}
+
+	cfg, err := ws.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	dir, err := git.ResolveWorktreesDir(cfg.Git.WorktreeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &worktreePool{git: git, ws: ws, dir: dir}, nil
+}
+
+// collectWorktrees lists managed worktrees and associates them with tasks.
+// A worktree belongs to the task whose work records its path, or else to a
+// task with the worktree's directory name as ID.
+func collectWorktrees(ctx context.Context, pool *worktreePool) ([]worktreeEntry, error) {
+	ws := pool.ws
+	managed, err := pool.git.ListManagedWorktrees(ctx, pool.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	taskIDs, err := ws.ListWorks()
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+	byPath := make(map[string]string)
+	byID := make(map[string]bool)
+	for _, taskID := range taskIDs {
+		work, err := ws.LoadWork(taskID)
+		if err != nil {
+			continue
+		}
+		byID[taskID] = true
+		if work.Git.WorktreePath != "" {
+			byPath[filepath.Clean(work.Git.WorktreePath)] = taskID
+		}
+	}
+
+	var activeID string
+	if active, err := ws.LoadActiveTask(); err == nil {
+		activeID = active.ID
+	}
+
+	entries := make([]worktreeEntry, 0, len(managed))
+	for _, wt := range managed {
+		entry := worktreeEntry{ManagedWorktree: wt, Task: byPath[filepath.Clean(wt.Path)]}
+		if entry.Task == "" && byID[wt.Name] {
+			entry.Task = wt.Name
+		}
+		entry.Active = entry.Task != "" && entry.Task == activeID
+
+		if lease, err := ws.LoadTaskLease(entry.taskID()); err == nil {
+			entry.Lease = lease
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func runWorktreesList(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+
+	pool, err := openWorktreePool(ctx)
+	if err != nil {
+		return err
+	}
+
+	entries, err := collectWorktrees(ctx, pool)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Printf("No worktrees in %s\n", pool.dir)
+
+		return nil
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "TASK\tBRANCH\tSTATUS\tLOCK\tPATH"); err != nil {
+		return fmt.Errorf("print header: %w", err)
+	}
+
+	orphaned := 0
+	for _, e := range entries {
+		if e.orphaned() {
+			orphaned++
+		}
+		branch := e.Branch
+		if branch == "" {
+			branch = "(detached)"
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.taskID(), branch, e.status(), e.lockStatus(now), e.Path); err != nil {
+			return fmt.Errorf("print worktree: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+
+	if orphaned > 0 {
+		fmt.Printf("\n%d orphaned worktree(s). Run 'mehr worktrees prune' to remove them.\n", orphaned)
+	}
+
+	return nil
+}
+
+func runWorktreesPrune(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+
+	pool, err := openWorktreePool(ctx)
+	if err != nil {
+		return err
+	}
+	git := pool.git
+
+	entries, err := collectWorktrees(ctx, pool)
+	if err != nil {
+		return err
+	}
+
+	baseBranch, err := git.GetBaseBranch(ctx)
+	if err != nil {
+		return fmt.Errorf("get base branch: %w", err)
+	}
+
+	now := time.Now()
+	removed, skipped := 0, 0
+	for _, e := range entries {
+		if !e.orphaned() {
+			continue
+		}
+		if e.leaseHeld(now) {
+			fmt.Printf("Skipping %s: leased by %s\n", e.taskID(), e.Lease.Holder())
+			skipped++
+
+			continue
+		}
+		if e.Locked && !e.staleLock() && !worktreesPruneForce {
+			fmt.Printf("Skipping %s: locked (%s); use --force to remove\n", e.taskID(), lockReason(e.LockReason))
+			skipped++
+
+			continue
+		}
+
+		if worktreesPruneDryRun {
+			fmt.Printf("Would remove %s (%s)\n", e.Path, e.status())
+
+			continue
+		}
+
+		if err := pruneWorktree(ctx, git, e); err != nil {
+			fmt.Println(display.WarningMsg("Skipping %s: %v", e.taskID(), err))
+			skipped++
+
+			continue
+		}
+		removed++
+		fmt.Printf("Removed %s\n", e.Path)
+
+		if e.Branch != "" {
+			deleteWorktreeBranch(ctx, git, e.Branch, baseBranch)
+		}
+		// Checkpoints are best-effort; the task is gone
+		_ = git.DeleteAllCheckpoints(ctx, e.taskID())
+		if cleared, err := pool.ws.ClearStaleTaskLease(e.taskID()); err == nil && cleared {
+			fmt.Printf("Cleared stale lease of %s\n", e.taskID())
+		}
+	}
+
+	if worktreesPruneDryRun {
+		return nil
+	}
+
+	if err := git.PruneWorktrees(ctx); err != nil {
+		return fmt.Errorf("prune worktree records: %w", err)
+	}
+
+	fmt.Println(display.SuccessMsg("Removed %d worktree(s), skipped %d", removed, skipped))
+
+	return nil
+}
+
+// pruneWorktree removes an orphaned worktree, clearing its lock first. Git
+// only forgets a missing worktree once it is pruned, which also frees its
+// branch for deletion.
+func pruneWorktree(ctx context.Context, git *vcs.Git, e worktreeEntry) error {
+	if e.Locked {
+		if err := git.UnlockWorktree(ctx, e.Path); err != nil {
+			return err
+		}
+	}
+	if e.Missing {
+		return git.PruneWorktrees(ctx)
+	}
+
+	return git.RemoveWorktree(ctx, e.Path, worktreesPruneForce)
+}
+
+// deleteWorktreeBranch deletes the branch of a pruned worktree when it is
+// merged into base, or always with --force.
+func deleteWorktreeBranch(ctx context.Context, git *vcs.Git, branch, base string) {
+	if !git.BranchExists(ctx, branch) {
+		return
+	}
+
+	merged, err := git.IsMerged(ctx, branch, base)
+	if err != nil || (!merged && !worktreesPruneForce) {
+		fmt.Printf("  Kept branch %s (not merged into %s; use --force to delete)\n", branch, base)
+
+		return
+	}
+
+	if err := git.DeleteBranch(ctx, branch, true); err != nil {
+		fmt.Println(display.WarningMsg("Could not delete branch %s: %v", branch, err))
+
+		return
+	}
+	fmt.Printf("  Deleted branch %s\n", branch)
+}
+
+// lockReason formats the reason of a git worktree lock.
+func lockReason(reason string) string {
+	if reason == "" {
+		return "no reason given"
+	}
+
+	return reason
+}
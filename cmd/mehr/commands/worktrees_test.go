@@ -0,0 +1,104 @@
+//go:build !testbinary
+// +build !testbinary
+
+package commands
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+func TestWorktreesCommand_Structure(t *testing.T) {
+	if worktreesCmd.Use != "worktrees" {
+		t.Errorf("Use = %q, want %q", worktreesCmd.Use, "worktrees")
+	}
+	subs := worktreesCmd.Commands()
+	if !slices.Contains(subs, worktreesListCmd) || !slices.Contains(subs, worktreesPruneCmd) {
+		t.Error("list and prune subcommands not registered")
+	}
+}
+
+func TestWorktreesPruneCommand_Flags(t *testing.T) {
+	tests := []struct {
+		flagName     string
+		shorthand    string
+		defaultValue string
+	}{
+		{"dry-run", "", "false"},
+		{"force", "f", "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flagName, func(t *testing.T) {
+			flag := worktreesPruneCmd.Flags().Lookup(tt.flagName)
+			if flag == nil {
+				t.Fatalf("flag %q not found", tt.flagName)
+			}
+			if flag.Shorthand != tt.shorthand {
+				t.Errorf("shorthand = %q, want %q", flag.Shorthand, tt.shorthand)
+			}
+			if flag.DefValue != tt.defaultValue {
+				t.Errorf("default = %q, want %q", flag.DefValue, tt.defaultValue)
+			}
+		})
+	}
+}
+
+func TestWorktreeEntry_Status(t *testing.T) {
+	now := time.Now()
+	live := &storage.TaskLease{Owner: "alice", Hostname: "other-host", PID: 42, ExpiresAt: now.Add(time.Hour)}
+	expired := &storage.TaskLease{Owner: "alice", Hostname: "other-host", PID: 42, ExpiresAt: now.Add(-time.Minute)}
+
+	tests := []struct {
+		name       string
+		entry      worktreeEntry
+		wantStatus string
+		wantLock   string
+	}{
+		{
+			name:       "active task",
+			entry:      worktreeEntry{Task: "a", Active: true, Lease: live},
+			wantStatus: "active",
+			wantLock:   "leased by alice@other-host (pid 42)",
+		},
+		{
+			name:       "other task with stale lease",
+			entry:      worktreeEntry{Task: "b", Lease: expired},
+			wantStatus: "task",
+			wantLock:   "stale lease",
+		},
+		{
+			name:       "orphaned",
+			entry:      worktreeEntry{},
+			wantStatus: "orphaned",
+			wantLock:   "-",
+		},
+		{
+			name:       "orphaned and locked",
+			entry:      worktreeEntry{ManagedWorktree: vcs.ManagedWorktree{Worktree: vcs.Worktree{Locked: true}}},
+			wantStatus: "orphaned",
+			wantLock:   "locked",
+		},
+		{
+			name:       "orphaned, locked and missing",
+			entry:      worktreeEntry{ManagedWorktree: vcs.ManagedWorktree{Worktree: vcs.Worktree{Locked: true}, Missing: true}},
+			wantStatus: "missing",
+			wantLock:   "locked (stale)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.status(); got != tt.wantStatus {
+				t.Errorf("status() = %q, want %q", got, tt.wantStatus)
+			}
+			if got := tt.entry.lockStatus(now); got != tt.wantLock {
+				t.Errorf("lockStatus() = %q, want %q", got, tt.wantLock)
+			}
+		})
+	}
+}
@@ -5,11 +5,18 @@ import (
 	"os"
 
 	"github.com/valksor/go-mehrhof/cmd/mehr/commands"
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/output"
 )
 
 func main() {
+	// Inside an agent sandbox, mehr only forwards the egress proxy
+	if len(os.Args) > 1 && os.Args[1] == agent.SandboxExecCommand {
+		os.Exit(agent.SandboxExec(os.Args[2:]))
+	}
+
 	if err := commands.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(output.ExitCode(err))
 	}
 }
@@ -6,6 +6,7 @@ require (
 	github.com/google/go-github/v67 v67.0.0
 	github.com/joho/godotenv v1.5.1
 	github.com/spf13/cobra v1.10.2
+	github.com/spf13/pflag v1.0.10
 	gitlab.com/gitlab-org/api/client-go v1.10.0
 	golang.org/x/mod v0.31.0
 	golang.org/x/oauth2 v0.34.0
@@ -18,6 +19,5 @@ require (
 	github.com/hashicorp/go-cleanhttp v0.5.2 // indirect
 	github.com/hashicorp/go-retryablehttp v0.7.8 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/pflag v1.0.10 // indirect
 	golang.org/x/time v0.14.0 // indirect
 )
@@ -79,3 +79,159 @@ type AgentCapabilities struct {
 	SystemPrompt   bool     // Accepts system prompts
 	AllowedTools   []string // List of available tools, empty = all
 }
+
+// Resumer is implemented by agents whose CLI can continue an earlier
+// conversation by its native session ID (see Response.SessionID).
+type Resumer interface {
+	// WithResume returns an agent that continues the given session.
+	WithResume(sessionID string) Agent
+}
+
+// Resume returns a copy of a that continues the given native session. It
+// reports false when the agent (or the agent an alias wraps) cannot resume.
+func Resume(a Agent, sessionID string) (Agent, bool) {
+	if sessionID == "" {
+		return a, false
+	}
+
+	if alias, ok := a.(*AliasAgent); ok {
+		base, ok := Resume(alias.base, sessionID)
+		if !ok {
+			return a, false
+		}
+
+		return &AliasAgent{
+			name:        alias.name,
+			description: alias.description,
+			base:        base,
+			env:         alias.env,
+			args:        alias.args,
+		}, true
+	}
+
+	if traced, ok := a.(*TracedAgent); ok {
+		base, ok := Resume(traced.base, sessionID)
+		if !ok {
+			return a, false
+		}
+
+		return &TracedAgent{base: base, t: traced.t, step: traced.step}, true
+	}
+
+	if wrapped, ok := a.(*ChaosAgent); ok {
+		base, ok := Resume(wrapped.base, sessionID)
+		if !ok {
+			return a, false
+		}
+
+		return &ChaosAgent{base: base}, true
+	}
+
+	if cached, ok := a.(*CachedAgent); ok {
+		base, ok := Resume(cached.base, sessionID)
+		if !ok {
+			return a, false
+		}
+
+		return cached.bound(base, "session:"+sessionID), true
+	}
+
+	r, ok := a.(Resumer)
+	if !ok {
+		return a, false
+	}
+
+	return r.WithResume(sessionID), true
+}
+
+// DirRunner is implemented by agents that can run in a directory other than
+// the process's working directory, such as a worktree.
+type DirRunner interface {
+	// InDir returns an agent that runs in dir.
+	InDir(dir string) Agent
+}
+
+// InDir returns a copy of a that runs in dir. It reports false when the
+// agent (or the agent an alias wraps) cannot change its directory.
+func InDir(a Agent, dir string) (Agent, bool) {
+	switch wrapped := a.(type) {
+	case *AliasAgent:
+		base, ok := InDir(wrapped.base, dir)
+		if !ok {
+			return a, false
+		}
+
+		return &AliasAgent{
+			name:        wrapped.name,
+			description: wrapped.description,
+			base:        base,
+			env:         wrapped.env,
+			args:        wrapped.args,
+		}, true
+	case *TracedAgent:
+		base, ok := InDir(wrapped.base, dir)
+		if !ok {
+			return a, false
+		}
+
+		return &TracedAgent{base: base, t: wrapped.t, step: wrapped.step}, true
+	case *ChaosAgent:
+		base, ok := InDir(wrapped.base, dir)
+		if !ok {
+			return a, false
+		}
+
+		return &ChaosAgent{base: base}, true
+	case *CachedAgent:
+		base, ok := InDir(wrapped.base, dir)
+		if !ok {
+			return a, false
+		}
+
+		return wrapped.bound(base, "dir:"+dir), true
+	}
+
+	r, ok := a.(DirRunner)
+	if !ok {
+		return a, false
+	}
+
+	return r.InDir(dir), true
+}
+
+// AttachmentRunner is implemented by agents that can see image files, such
+// as screenshots, attached to a prompt.
+type AttachmentRunner interface {
+	// RunWithAttachments executes a prompt with the files attached, calling
+	// cb for each event.
+	RunWithAttachments(ctx context.Context, prompt string, files []string, cb StreamCallback) (*Response, error)
+}
+
+// AcceptsAttachments reports whether a (or the agent an alias or wrapper
+// wraps) can see attached files.
+func AcceptsAttachments(a Agent) bool {
+	switch wrapped := a.(type) {
+	case *AliasAgent:
+		return AcceptsAttachments(wrapped.base)
+	case *TracedAgent:
+		return AcceptsAttachments(wrapped.base)
+	case *ChaosAgent:
+		return AcceptsAttachments(wrapped.base)
+	case *CachedAgent:
+		return AcceptsAttachments(wrapped.base)
+	}
+
+	_, ok := a.(AttachmentRunner)
+
+	return ok
+}
+
+// RunWithAttachments executes a prompt with files attached when the agent
+// accepts attachments, and without them otherwise.
+func RunWithAttachments(ctx context.Context, a Agent, prompt string, files []string, cb StreamCallback) (*Response, error) {
+	if r, ok := a.(AttachmentRunner); ok && len(files) > 0 && AcceptsAttachments(a) {
+		return r.RunWithAttachments(ctx, prompt, files, cb)
+	}
+
+	return a.RunWithCallback(ctx, prompt, cb)
+}
@@ -477,3 +477,72 @@ func TestConfigStruct(t *testing.T) {
 		t.Errorf("Config.WorkDir = %q, want %q", cfg.WorkDir, "/tmp/work")
 	}
 }
+
+// visionAgent is a mockAgent that accepts attachments.
+type visionAgent struct {
+	mockAgent
+
+	files []string
+}
+
+func (v *visionAgent) RunWithAttachments(ctx context.Context, prompt string, files []string, cb StreamCallback) (*Response, error) {
+	v.files = files
+
+	return v.response, nil
+}
+
+func TestRunWithAttachments(t *testing.T) {
+	plain := &mockAgent{name: "plain", response: &Response{Summary: "plain"}}
+	if AcceptsAttachments(plain) {
+		t.Error("AcceptsAttachments(plain) = true")
+	}
+	if AcceptsAttachments(NewAlias("plain-alias", plain, nil, nil, "")) {
+		t.Error("alias of an agent without attachments should not accept them")
+	}
+	resp, err := RunWithAttachments(context.Background(), WithFailureInjection(plain), "prompt", []string{"a.png"}, nil)
+	if err != nil || resp.Summary != "plain" {
+		t.Errorf("fallback = %+v, %v", resp, err)
+	}
+
+	vision := &visionAgent{mockAgent: mockAgent{name: "vision", response: &Response{Summary: "vision"}}}
+	wrapped := WithFailureInjection(vision)
+	if !AcceptsAttachments(wrapped) {
+		t.Error("AcceptsAttachments(wrapped vision agent) = false")
+	}
+	if _, err := RunWithAttachments(context.Background(), wrapped, "prompt", []string{"a.png"}, nil); err != nil {
+		t.Fatalf("RunWithAttachments() error = %v", err)
+	}
+	if len(vision.files) != 1 || vision.files[0] != "a.png" {
+		t.Errorf("attached files = %v", vision.files)
+	}
+}
+
+// dirAgent is a mockAgent that can run in another directory.
+type dirAgent struct {
+	mockAgent
+
+	dir string
+}
+
+func (d *dirAgent) InDir(dir string) Agent {
+	return &dirAgent{mockAgent: d.mockAgent, dir: dir}
+}
+
+func TestInDir(t *testing.T) {
+	if _, ok := InDir(&mockAgent{name: "plain"}, "/tmp/wt"); ok {
+		t.Error("InDir(plain) = true")
+	}
+
+	base := &dirAgent{mockAgent: mockAgent{name: "cli"}}
+	moved, ok := InDir(NewAlias("fast", WithFailureInjection(base), nil, nil, ""), "/tmp/wt")
+	if !ok {
+		t.Fatal("InDir(alias of a dir agent) = false")
+	}
+	if moved.Name() != "fast" {
+		t.Errorf("moved agent name = %q, want the alias kept", moved.Name())
+	}
+	inner := moved.(*AliasAgent).base.(*ChaosAgent).base.(*dirAgent)
+	if inner.dir != "/tmp/wt" || base.dir != "" {
+		t.Errorf("dir = %q (original %q), want /tmp/wt and the original unchanged", inner.dir, base.dir)
+	}
+}
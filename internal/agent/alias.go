@@ -73,6 +73,11 @@ func (a *AliasAgent) RunWithCallback(ctx context.Context, prompt string, cb Stre
 	return a.configured().RunWithCallback(ctx, prompt, cb)
 }
 
+// RunWithAttachments executes with attached files, if the base agent accepts them.
+func (a *AliasAgent) RunWithAttachments(ctx context.Context, prompt string, files []string, cb StreamCallback) (*Response, error) {
+	return RunWithAttachments(ctx, a.configured(), prompt, files, cb)
+}
+
 // Available checks if the base agent is available.
 func (a *AliasAgent) Available() error {
 	return a.base.Available()
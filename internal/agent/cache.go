@@ -0,0 +1,278 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// ResponseCache stores agent responses on disk, addressed by a hash of the
+// agent's identity and the prompt, so identical runs, such as a rerun after
+// a crash, are answered without calling the agent again.
+type ResponseCache struct {
+	dir string
+	ttl time.Duration // 0 keeps entries forever
+	now func() time.Time
+}
+
+// NewResponseCache returns a cache storing responses under dir for ttl.
+func NewResponseCache(dir string, ttl time.Duration) *ResponseCache {
+	return &ResponseCache{dir: dir, ttl: ttl, now: time.Now}
+}
+
+// cacheEntry is a cached response as stored on disk.
+type cacheEntry struct {
+	Agent     string    `json:"agent"`
+	CreatedAt time.Time `json:"created_at"`
+	Response  *Response `json:"response"`
+}
+
+// Key returns the cache key for a prompt sent to an agent with the given
+// identity (see cacheIdentity).
+func (c *ResponseCache) Key(identity []string, prompt string) string {
+	h := sha256.New()
+	for _, part := range identity {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte(prompt))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the response cached under key. Expired entries are removed.
+func (c *ResponseCache) Get(key string) (*Response, bool) {
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Response == nil {
+		_ = os.Remove(path)
+
+		return nil, false
+	}
+	if c.ttl > 0 && c.now().Sub(entry.CreatedAt) > c.ttl {
+		_ = os.Remove(path)
+
+		return nil, false
+	}
+
+	return entry.Response, true
+}
+
+// Put caches resp under key.
+func (c *ResponseCache) Put(key, agentName string, resp *Response) error {
+	data, err := json.Marshal(cacheEntry{Agent: agentName, CreatedAt: c.now(), Response: resp})
+	if err != nil {
+		return err
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so concurrent readers never see a partial entry
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// path shards entries by the key's first two characters.
+func (c *ResponseCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// CachedAgent answers prompts from a ResponseCache when it can and caches
+// the responses of the wrapped agent otherwise. Streaming runs are not
+// cached.
+type CachedAgent struct {
+	base  Agent
+	cache *ResponseCache
+	env   map[string]string
+	args  []string
+	extra []string // Session or directory the agent was bound to
+}
+
+// WithCache wraps a so that its responses are cached. It returns a
+// unchanged when cache is nil.
+func WithCache(a Agent, cache *ResponseCache) Agent {
+	if cache == nil {
+		return a
+	}
+	if _, ok := a.(*CachedAgent); ok {
+		return a
+	}
+
+	return &CachedAgent{base: a, cache: cache}
+}
+
+// Name returns the wrapped agent's name.
+func (a *CachedAgent) Name() string {
+	return a.base.Name()
+}
+
+// Run returns the cached response for the prompt, or runs the wrapped agent.
+func (a *CachedAgent) Run(ctx context.Context, prompt string) (*Response, error) {
+	return a.cached(ctx, a.key(prompt), func() (*Response, error) {
+		return a.base.Run(ctx, prompt)
+	})
+}
+
+// RunStream streams the wrapped agent's events without caching.
+func (a *CachedAgent) RunStream(ctx context.Context, prompt string) (<-chan Event, <-chan error) {
+	return a.base.RunStream(ctx, prompt)
+}
+
+// RunWithCallback returns the cached response for the prompt, or runs the
+// wrapped agent. Cached responses produce no events.
+func (a *CachedAgent) RunWithCallback(ctx context.Context, prompt string, cb StreamCallback) (*Response, error) {
+	return a.cached(ctx, a.key(prompt), func() (*Response, error) {
+		return a.base.RunWithCallback(ctx, prompt, cb)
+	})
+}
+
+// RunWithAttachments is like RunWithCallback, with the attached files'
+// contents part of the cache key.
+func (a *CachedAgent) RunWithAttachments(ctx context.Context, prompt string, files []string, cb StreamCallback) (*Response, error) {
+	keyed := prompt
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return RunWithAttachments(ctx, a.base, prompt, files, cb)
+		}
+		sum := sha256.Sum256(data)
+		keyed += "\x00" + hex.EncodeToString(sum[:])
+	}
+
+	return a.cached(ctx, a.key(keyed), func() (*Response, error) {
+		return RunWithAttachments(ctx, a.base, prompt, files, cb)
+	})
+}
+
+// BaseAgent returns the wrapped agent.
+func (a *CachedAgent) BaseAgent() Agent {
+	return a.base
+}
+
+// Available checks if the wrapped agent is available.
+func (a *CachedAgent) Available() error {
+	return a.base.Available()
+}
+
+// WithEnv adds an environment variable to the wrapped agent.
+func (a *CachedAgent) WithEnv(key, value string) Agent {
+	wrapped := a.bound(a.base.WithEnv(key, value))
+	wrapped.env = maps.Clone(a.env)
+	if wrapped.env == nil {
+		wrapped.env = make(map[string]string)
+	}
+	wrapped.env[key] = value
+
+	return wrapped
+}
+
+// WithArgs adds CLI arguments to the wrapped agent.
+func (a *CachedAgent) WithArgs(args ...string) Agent {
+	wrapped := a.bound(a.base.WithArgs(args...))
+	wrapped.args = append(slices.Clone(a.args), args...)
+
+	return wrapped
+}
+
+// bound returns a copy of a wrapping base, with extra describing what base
+// was bound to.
+func (a *CachedAgent) bound(base Agent, extra ...string) *CachedAgent {
+	return &CachedAgent{
+		base:  base,
+		cache: a.cache,
+		env:   a.env,
+		args:  a.args,
+		extra: append(slices.Clone(a.extra), extra...),
+	}
+}
+
+// cached returns the response cached under key, or the response of run,
+// which is cached when it succeeds. A cached response carries no usage, as
+// it cost nothing.
+func (a *CachedAgent) cached(ctx context.Context, key string, run func() (*Response, error)) (*Response, error) {
+	if resp, ok := a.cache.Get(key); ok {
+		resp.Usage = nil
+
+		return resp, nil
+	}
+
+	resp, err := run()
+	if err != nil || resp == nil || ctx.Err() != nil {
+		return resp, err
+	}
+	// Caching is best effort; the response is good either way
+	_ = a.cache.Put(key, a.Name(), resp)
+
+	return resp, nil
+}
+
+func (a *CachedAgent) key(prompt string) string {
+	identity := cacheIdentity(a.base)
+	for _, k := range slices.Sorted(maps.Keys(a.env)) {
+		identity = append(identity, "env:"+k+"="+a.env[k])
+	}
+	for _, arg := range a.args {
+		identity = append(identity, "arg:"+arg)
+	}
+	identity = append(identity, a.extra...)
+
+	return a.cache.Key(identity, prompt)
+}
+
+// cacheIdentity describes what, besides the prompt, decides an agent's
+// answer: its name, and the environment and arguments of the aliases it is
+// built from, which select the model.
+func cacheIdentity(a Agent) []string {
+	switch wrapped := a.(type) {
+	case *AliasAgent:
+		identity := []string{"alias:" + wrapped.name}
+		for _, k := range slices.Sorted(maps.Keys(wrapped.env)) {
+			identity = append(identity, "env:"+k+"="+wrapped.env[k])
+		}
+		for _, arg := range wrapped.args {
+			identity = append(identity, "arg:"+arg)
+		}
+
+		return append(identity, cacheIdentity(wrapped.base)...)
+	case *TracedAgent:
+		return cacheIdentity(wrapped.base)
+	case *ChaosAgent:
+		return cacheIdentity(wrapped.base)
+	case *CachedAgent:
+		return cacheIdentity(wrapped.base)
+	}
+
+	return []string{"agent:" + a.Name()}
+}
+
+// Ensure CachedAgent implements Agent.
+var _ Agent = (*CachedAgent)(nil)
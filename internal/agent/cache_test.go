@@ -0,0 +1,126 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// countingAgent counts the prompts it runs and answers each with its prompt.
+type countingAgent struct {
+	mockAgent
+
+	runs int
+}
+
+func (a *countingAgent) Run(ctx context.Context, prompt string) (*Response, error) {
+	return a.RunWithCallback(ctx, prompt, nil)
+}
+
+func (a *countingAgent) RunWithCallback(ctx context.Context, prompt string, cb StreamCallback) (*Response, error) {
+	a.runs++
+	if a.runErr != nil {
+		return nil, a.runErr
+	}
+
+	return &Response{Summary: prompt, Usage: &UsageStats{InputTokens: 100, OutputTokens: 10}}, nil
+}
+
+func (a *countingAgent) WithEnv(key, value string) Agent {
+	return a
+}
+
+func (a *countingAgent) WithArgs(args ...string) Agent {
+	return a
+}
+
+func TestCachedAgent(t *testing.T) {
+	base := &countingAgent{mockAgent: mockAgent{name: "mock"}}
+	a := WithCache(base, NewResponseCache(t.TempDir(), time.Hour))
+	if WithCache(a, NewResponseCache(t.TempDir(), time.Hour)) != a {
+		t.Error("wrapping twice should return the same agent")
+	}
+
+	first, err := a.RunWithCallback(t.Context(), "plan", nil)
+	if err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if first.Usage == nil {
+		t.Error("a fresh response should keep its usage")
+	}
+
+	second, err := a.Run(t.Context(), "plan")
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if base.runs != 1 {
+		t.Errorf("agent ran %d times, want 1", base.runs)
+	}
+	if second.Summary != "plan" || second.Usage != nil {
+		t.Errorf("cached response = %+v, want the summary without usage", second)
+	}
+
+	if _, err := a.Run(t.Context(), "review"); err != nil {
+		t.Fatalf("other prompt: %v", err)
+	}
+	if _, err := a.WithArgs("--model", "opus").Run(t.Context(), "plan"); err != nil {
+		t.Fatalf("other args: %v", err)
+	}
+	if base.runs != 3 {
+		t.Errorf("agent ran %d times, want 3 for a new prompt and new args", base.runs)
+	}
+}
+
+func TestCachedAgent_Errors(t *testing.T) {
+	base := &countingAgent{mockAgent: mockAgent{name: "mock", runErr: errors.New("rate limited")}}
+	a := WithCache(base, NewResponseCache(t.TempDir(), time.Hour))
+
+	for range 2 {
+		if _, err := a.Run(t.Context(), "plan"); err == nil {
+			t.Error("expected the agent's error")
+		}
+	}
+	if base.runs != 2 {
+		t.Errorf("agent ran %d times, want 2: errors must not be cached", base.runs)
+	}
+}
+
+func TestCachedAgent_TTL(t *testing.T) {
+	cache := NewResponseCache(t.TempDir(), time.Hour)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	base := &countingAgent{mockAgent: mockAgent{name: "mock"}}
+	a := WithCache(base, cache)
+	if _, err := a.Run(t.Context(), "plan"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, ok := cache.Get(cache.Key([]string{"agent:mock"}, "plan")); ok {
+		t.Error("expired entry was returned")
+	}
+	if _, err := a.Run(t.Context(), "plan"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if base.runs != 2 {
+		t.Errorf("agent ran %d times, want 2 after the entry expired", base.runs)
+	}
+}
+
+func TestCachedAgent_AliasIdentity(t *testing.T) {
+	cache := NewResponseCache(t.TempDir(), 0)
+	base := &countingAgent{mockAgent: mockAgent{name: "claude"}}
+
+	opus := WithCache(NewAlias("fast", base, nil, []string{"--model", "opus"}, ""), cache)
+	haiku := WithCache(NewAlias("fast", base, nil, []string{"--model", "haiku"}, ""), cache)
+	for _, a := range []Agent{opus, haiku, opus} {
+		if _, err := a.Run(t.Context(), "plan"); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	}
+	if base.runs != 2 {
+		t.Errorf("agent ran %d times, want 2: aliases with other models must not share entries", base.runs)
+	}
+}
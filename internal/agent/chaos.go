@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/valksor/go-mehrhof/internal/chaos"
+)
+
+// ChaosAgent fails calls selected by failure injection (see package chaos)
+// and passes the others to the wrapped agent.
+type ChaosAgent struct {
+	base Agent
+}
+
+// WithFailureInjection wraps a so that its calls count towards, and can be
+// failed by, the chaos.AgentRun injection point.
+func WithFailureInjection(a Agent) Agent {
+	if _, ok := a.(*ChaosAgent); ok {
+		return a
+	}
+
+	return &ChaosAgent{base: a}
+}
+
+// Name returns the wrapped agent's name.
+func (a *ChaosAgent) Name() string {
+	return a.base.Name()
+}
+
+// Run executes the prompt unless the call is selected to fail.
+func (a *ChaosAgent) Run(ctx context.Context, prompt string) (*Response, error) {
+	if err := chaos.Check(chaos.AgentRun); err != nil {
+		return nil, err
+	}
+
+	return a.base.Run(ctx, prompt)
+}
+
+// RunStream streams the prompt's events unless the call is selected to fail.
+func (a *ChaosAgent) RunStream(ctx context.Context, prompt string) (<-chan Event, <-chan error) {
+	if err := chaos.Check(chaos.AgentRun); err != nil {
+		eventCh := make(chan Event)
+		errCh := make(chan error, 1)
+		close(eventCh)
+		errCh <- err
+		close(errCh)
+
+		return eventCh, errCh
+	}
+
+	return a.base.RunStream(ctx, prompt)
+}
+
+// RunWithCallback executes with a callback unless the call is selected to fail.
+func (a *ChaosAgent) RunWithCallback(ctx context.Context, prompt string, cb StreamCallback) (*Response, error) {
+	if err := chaos.Check(chaos.AgentRun); err != nil {
+		return nil, err
+	}
+
+	return a.base.RunWithCallback(ctx, prompt, cb)
+}
+
+// RunWithAttachments executes with attached files unless a failure is injected.
+func (a *ChaosAgent) RunWithAttachments(ctx context.Context, prompt string, files []string, cb StreamCallback) (*Response, error) {
+	if err := chaos.Check(chaos.AgentRun); err != nil {
+		return nil, err
+	}
+
+	return RunWithAttachments(ctx, a.base, prompt, files, cb)
+}
+
+// Available checks if the wrapped agent is available.
+func (a *ChaosAgent) Available() error {
+	return a.base.Available()
+}
+
+// WithEnv adds an environment variable to the wrapped agent.
+func (a *ChaosAgent) WithEnv(key, value string) Agent {
+	return &ChaosAgent{base: a.base.WithEnv(key, value)}
+}
+
+// WithArgs adds CLI arguments to the wrapped agent.
+func (a *ChaosAgent) WithArgs(args ...string) Agent {
+	return &ChaosAgent{base: a.base.WithArgs(args...)}
+}
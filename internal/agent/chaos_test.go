@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/chaos"
+)
+
+func TestWithFailureInjection(t *testing.T) {
+	t.Cleanup(chaos.Reset)
+	t.Setenv(chaos.EnvGuard, "1")
+	if err := chaos.Configure("agent.run:2"); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+
+	a := WithFailureInjection(&mockAgent{name: "mock", response: &Response{Summary: "ok"}})
+	if WithFailureInjection(a) != a {
+		t.Error("wrapping twice should return the same agent")
+	}
+	if a.Name() != "mock" {
+		t.Errorf("Name() = %q", a.Name())
+	}
+
+	if _, err := a.Run(t.Context(), "first"); err != nil {
+		t.Errorf("first run: %v", err)
+	}
+
+	_, errCh := a.WithEnv("K", "V").RunStream(t.Context(), "second")
+	if err := <-errCh; !errors.Is(err, chaos.ErrInjected) {
+		t.Errorf("second run = %v, want injected failure", err)
+	}
+
+	if _, err := a.RunWithCallback(t.Context(), "third", nil); err != nil {
+		t.Errorf("third run: %v", err)
+	}
+}
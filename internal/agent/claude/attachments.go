@@ -0,0 +1,79 @@
+package claude
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+)
+
+// maxImageSize is the largest image the API accepts.
+const maxImageSize = 5 << 20
+
+// imageMediaTypes are the image formats the API accepts.
+var imageMediaTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+type contentBlock struct {
+	Type   string       `json:"type"`
+	Text   string       `json:"text,omitempty"`
+	Source *imageSource `json:"source,omitempty"`
+}
+
+type imageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+type userMessage struct {
+	Type    string `json:"type"`
+	Message struct {
+		Role    string         `json:"role"`
+		Content []contentBlock `json:"content"`
+	} `json:"message"`
+}
+
+// attachmentMessage encodes the prompt and image files as a stream-json user
+// message. Files in formats the API does not accept, or that are too large,
+// are skipped.
+func attachmentMessage(prompt string, files []string) ([]byte, error) {
+	var msg userMessage
+	msg.Type = "user"
+	msg.Message.Role = "user"
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read attachment: %w", err)
+		}
+		mediaType := http.DetectContentType(data)
+		if !imageMediaTypes[mediaType] || len(data) > maxImageSize {
+			slog.Warn("skipping attachment the agent cannot read", "file", file, "type", mediaType, "size", len(data))
+
+			continue
+		}
+		msg.Message.Content = append(msg.Message.Content, contentBlock{
+			Type: "image",
+			Source: &imageSource{
+				Type:      "base64",
+				MediaType: mediaType,
+				Data:      base64.StdEncoding.EncodeToString(data),
+			},
+		})
+	}
+	msg.Message.Content = append(msg.Message.Content, contentBlock{Type: "text", Text: prompt})
+
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("encode message: %w", err)
+	}
+
+	return append(line, '\n'), nil
+}
@@ -0,0 +1,101 @@
+package claude
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+)
+
+func writePNG(t *testing.T, dir string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "screen.png")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestAttachmentMessage(t *testing.T) {
+	dir := t.TempDir()
+	img := writePNG(t, dir)
+	text := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(text, []byte("not an image"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	line, err := attachmentMessage("Plan the fix", []string{img, text})
+	if err != nil {
+		t.Fatalf("attachmentMessage() error = %v", err)
+	}
+	if !bytes.HasSuffix(line, []byte("\n")) {
+		t.Error("message should be newline-terminated")
+	}
+
+	var msg userMessage
+	if err := json.Unmarshal(line, &msg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	content := msg.Message.Content
+	if msg.Type != "user" || msg.Message.Role != "user" || len(content) != 2 {
+		t.Fatalf("message = %+v, want one image and the prompt", msg)
+	}
+	if content[0].Type != "image" || content[0].Source.MediaType != "image/png" {
+		t.Errorf("image block = %+v", content[0])
+	}
+	data, _ := os.ReadFile(img)
+	if content[0].Source.Data != base64.StdEncoding.EncodeToString(data) {
+		t.Error("image data not base64 encoded")
+	}
+	if content[1].Type != "text" || content[1].Text != "Plan the fix" {
+		t.Errorf("text block = %+v", content[1])
+	}
+}
+
+func TestRunWithAttachments(t *testing.T) {
+	dir := t.TempDir()
+	img := writePNG(t, dir)
+	stdinFile := filepath.Join(dir, "stdin.json")
+
+	// The fake CLI records its stdin and arguments
+	a := NewWithConfig(agent.Config{
+		Command: []string{"sh", "-c", `cat > "$0"; echo "$@" > "$0.args"`, stdinFile},
+		Timeout: 10 * time.Second,
+	})
+	if !agent.AcceptsAttachments(agent.NewAlias("claude-vision", a, nil, nil, "")) {
+		t.Error("alias of claude should accept attachments")
+	}
+
+	var events int
+	_, err := agent.RunWithAttachments(context.Background(), a, "Plan the fix", []string{img}, func(agent.Event) error {
+		events++
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunWithAttachments() error = %v", err)
+	}
+
+	input, _ := os.ReadFile(stdinFile)
+	if !strings.Contains(string(input), `"type":"image"`) || !strings.Contains(string(input), "Plan the fix") {
+		t.Errorf("stdin = %.200s", input)
+	}
+	args, _ := os.ReadFile(stdinFile + ".args")
+	if !strings.Contains(string(args), "--input-format stream-json") || strings.Contains(string(args), "Plan the fix") {
+		t.Errorf("args = %q, want stream-json input and no positional prompt", args)
+	}
+}
@@ -2,6 +2,7 @@ package claude
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -117,7 +118,7 @@ func (a *Agent) RunStream(ctx context.Context, prompt string) (<-chan agent.Even
 		defer close(eventCh)
 		defer close(errCh)
 
-		err := a.executeStream(ctx, prompt, eventCh)
+		err := a.executeStream(ctx, a.buildArgs(prompt), nil, eventCh)
 		if err != nil {
 			errCh <- err
 		}
@@ -145,13 +146,49 @@ func (a *Agent) RunWithCallback(ctx context.Context, prompt string, cb agent.Str
 	return a.parser.Parse(collected)
 }
 
-func (a *Agent) executeStream(ctx context.Context, prompt string, eventCh chan<- agent.Event) error {
+// RunWithAttachments executes a prompt with image files attached. The prompt
+// and images are sent as one stream-json user message on stdin.
+func (a *Agent) RunWithAttachments(ctx context.Context, prompt string, files []string, cb agent.StreamCallback) (*agent.Response, error) {
+	input, err := attachmentMessage(prompt, files)
+	if err != nil {
+		return nil, err
+	}
+
+	eventCh := make(chan agent.Event, 100)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(eventCh)
+		defer close(errCh)
+
+		if err := a.executeStream(ctx, a.buildStreamInputArgs(), bytes.NewReader(input), eventCh); err != nil {
+			errCh <- err
+		}
+	}()
+
+	var collected []agent.Event
+	for event := range eventCh {
+		if cb != nil {
+			if err := cb(event); err != nil {
+				return nil, fmt.Errorf("callback error: %w", err)
+			}
+		}
+		collected = append(collected, event)
+	}
+
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+
+	return a.parser.Parse(collected)
+}
+
+func (a *Agent) executeStream(ctx context.Context, args []string, stdin io.Reader, eventCh chan<- agent.Event) error {
 	// Build command with timeout
 	timeoutCtx, cancel := context.WithTimeout(ctx, a.config.Timeout)
 	defer cancel()
 
-	args := a.buildArgs(prompt)
 	cmd := exec.CommandContext(timeoutCtx, a.config.Command[0], args...)
+	cmd.Stdin = stdin
 
 	// Set working directory
 	if a.config.WorkDir != "" {
@@ -163,6 +200,9 @@ func (a *Agent) executeStream(ctx context.Context, prompt string, eventCh chan<-
 	for k, v := range a.config.Environment {
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
 	}
+	if err := a.config.Sandbox.Wrap(cmd, a.config.Environment); err != nil {
+		return err
+	}
 
 	// Get stdout pipe
 	stdout, err := cmd.StdoutPipe()
@@ -232,6 +272,11 @@ func (a *Agent) executeStream(ctx context.Context, prompt string, eventCh chan<-
 
 	// Wait for command to finish
 	if err := cmd.Wait(); err != nil {
+		// A killed process after cancellation is an interrupt, not a failure
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
 		// Check if it's just a non-zero exit (which might be okay)
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
@@ -251,6 +296,16 @@ func (a *Agent) executeStream(ctx context.Context, prompt string, eventCh chan<-
 }
 
 func (a *Agent) buildArgs(prompt string) []string {
+	// Add prompt as positional argument (last)
+	return append(a.baseArgs(), prompt)
+}
+
+// buildStreamInputArgs reads the prompt as stream-json messages from stdin.
+func (a *Agent) buildStreamInputArgs() []string {
+	return append(a.baseArgs(), "--input-format", "stream-json")
+}
+
+func (a *Agent) baseArgs() []string {
 	args := []string{}
 
 	// Add base arguments from config
@@ -270,9 +325,6 @@ func (a *Agent) buildArgs(prompt string) []string {
 	args = append(args, "--verbose")
 	args = append(args, "--output-format", "stream-json")
 
-	// Add prompt as positional argument (last)
-	args = append(args, prompt)
-
 	return args
 }
 
@@ -293,6 +345,11 @@ func (a *Agent) WithWorkDir(dir string) *Agent {
 	}
 }
 
+// InDir runs the agent in dir, e.g. a worktree.
+func (a *Agent) InDir(dir string) agent.Agent {
+	return a.WithWorkDir(dir)
+}
+
 // WithTimeout sets execution timeout
 // Returns a new Agent instance with the updated config to avoid data races.
 func (a *Agent) WithTimeout(d time.Duration) *Agent {
@@ -340,6 +397,34 @@ func (a *Agent) WithArgs(args ...string) agent.Agent {
 	}
 }
 
+// SandboxNeeds returns the hosts, credentials and state the claude CLI needs
+// from outside a sandbox.
+func (a *Agent) SandboxNeeds() agent.SandboxNeeds {
+	return agent.SandboxNeeds{
+		Hosts: []string{"api.anthropic.com", "statsig.anthropic.com"},
+		Env:   []string{"ANTHROPIC_API_KEY", "ANTHROPIC_BASE_URL", "CLAUDE_CONFIG_DIR"},
+		Dirs:  []string{".claude", ".claude.json"},
+	}
+}
+
+// WithSandbox runs the CLI inside s.
+// Returns a new Agent instance with the updated config to avoid data races.
+func (a *Agent) WithSandbox(s *agent.Sandbox) agent.Agent {
+	newConfig := a.config
+	newConfig.Sandbox = s
+
+	return &Agent{
+		config: newConfig,
+		parser: a.parser,
+	}
+}
+
+// WithResume continues an earlier claude conversation by its session ID.
+// Returns a new Agent instance with the updated config to avoid data races.
+func (a *Agent) WithResume(sessionID string) agent.Agent {
+	return a.WithArgs("--resume", sessionID)
+}
+
 // Register adds the Claude agent to a registry.
 func Register(r *agent.Registry) error {
 	return r.Register(New())
@@ -347,3 +432,5 @@ func Register(r *agent.Registry) error {
 
 // Ensure Agent implements agent.Agent.
 var _ agent.Agent = (*Agent)(nil)
+var _ agent.Sandboxer = (*Agent)(nil)
+var _ agent.AttachmentRunner = (*Agent)(nil)
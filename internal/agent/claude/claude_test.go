@@ -176,6 +176,30 @@ func TestBuildArgs_WithConfigArgs(t *testing.T) {
 	}
 }
 
+func TestWithResume(t *testing.T) {
+	resumed, ok := agent.Resume(New(), "abc-123")
+	if !ok {
+		t.Fatal("claude agent should support resuming sessions")
+	}
+
+	c, ok := resumed.(*Agent)
+	if !ok {
+		t.Fatalf("Resume() returned %T, want *Agent", resumed)
+	}
+	args := c.buildArgs("follow up")
+	if args[0] != "--resume" || args[1] != "abc-123" {
+		t.Errorf("args = %v, want --resume abc-123 first", args)
+	}
+
+	alias := agent.NewAlias("claude-fast", New(), nil, []string{"--model", "haiku"}, "")
+	if _, ok := agent.Resume(alias, "abc-123"); !ok {
+		t.Error("alias of claude should support resuming sessions")
+	}
+	if _, ok := agent.Resume(agent.WithFailureInjection(alias), "abc-123"); !ok {
+		t.Error("failure injection wrapper should not prevent resuming sessions")
+	}
+}
+
 func TestAvailable_NoCLI(t *testing.T) {
 	// Use a non-existent binary to test the "not found" case
 	cfg := agent.Config{
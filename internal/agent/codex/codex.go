@@ -145,6 +145,9 @@ func (a *Agent) executeStream(ctx context.Context, prompt string, eventCh chan<-
 	for k, v := range a.config.Environment {
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
 	}
+	if err := a.config.Sandbox.Wrap(cmd, a.config.Environment); err != nil {
+		return err
+	}
 
 	// Get stdout pipe
 	stdout, err := cmd.StdoutPipe()
@@ -208,6 +211,11 @@ func (a *Agent) executeStream(ctx context.Context, prompt string, eventCh chan<-
 
 	// Wait for command to finish
 	if err := cmd.Wait(); err != nil {
+		// A killed process after cancellation is an interrupt, not a failure
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
 		// Check if it's just a non-zero exit (which might be okay)
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
@@ -269,6 +277,11 @@ func (a *Agent) WithWorkDir(dir string) *Agent {
 	}
 }
 
+// InDir runs the agent in dir, e.g. a worktree.
+func (a *Agent) InDir(dir string) agent.Agent {
+	return a.WithWorkDir(dir)
+}
+
 // WithTimeout sets execution timeout
 // Returns a new Agent instance with the updated config to avoid data races.
 func (a *Agent) WithTimeout(d time.Duration) *Agent {
@@ -316,6 +329,28 @@ func (a *Agent) WithArgs(args ...string) agent.Agent {
 	}
 }
 
+// SandboxNeeds returns the hosts, credentials and state the codex CLI needs
+// from outside a sandbox.
+func (a *Agent) SandboxNeeds() agent.SandboxNeeds {
+	return agent.SandboxNeeds{
+		Hosts: []string{"api.openai.com", "chatgpt.com"},
+		Env:   []string{"OPENAI_API_KEY", "OPENAI_BASE_URL", "CODEX_HOME"},
+		Dirs:  []string{".codex"},
+	}
+}
+
+// WithSandbox runs the CLI inside s.
+// Returns a new Agent instance with the updated config to avoid data races.
+func (a *Agent) WithSandbox(s *agent.Sandbox) agent.Agent {
+	newConfig := a.config
+	newConfig.Sandbox = s
+
+	return &Agent{
+		config: newConfig,
+		parser: a.parser,
+	}
+}
+
 // Register adds the Codex agent to a registry.
 func Register(r *agent.Registry) error {
 	return r.Register(New())
@@ -323,3 +358,4 @@ func Register(r *agent.Registry) error {
 
 // Ensure Agent implements agent.Agent.
 var _ agent.Agent = (*Agent)(nil)
+var _ agent.Sandboxer = (*Agent)(nil)
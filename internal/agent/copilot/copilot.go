@@ -171,6 +171,9 @@ func (a *Agent) executeStream(ctx context.Context, prompt string, eventCh chan<-
 	for k, v := range a.config.Environment {
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
 	}
+	if err := a.config.Sandbox.Wrap(cmd, a.config.Environment); err != nil {
+		return err
+	}
 
 	// Get stdout pipe
 	stdout, err := cmd.StdoutPipe()
@@ -231,6 +234,11 @@ func (a *Agent) executeStream(ctx context.Context, prompt string, eventCh chan<-
 
 	// Wait for command
 	if err := cmd.Wait(); err != nil {
+		// A killed process after cancellation is an interrupt, not a failure
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
 			if exitErr.ExitCode() != 0 {
@@ -324,6 +332,11 @@ func (a *Agent) WithWorkDir(dir string) *Agent {
 	}
 }
 
+// InDir runs the agent in dir, e.g. a worktree.
+func (a *Agent) InDir(dir string) agent.Agent {
+	return a.WithWorkDir(dir)
+}
+
 // WithTimeout sets execution timeout.
 func (a *Agent) WithTimeout(d time.Duration) *Agent {
 	newConfig := a.config
@@ -389,6 +402,30 @@ func (a *Agent) WithArgs(args ...string) agent.Agent {
 	}
 }
 
+// SandboxNeeds returns the hosts, credentials and state the copilot CLI needs
+// from outside a sandbox.
+func (a *Agent) SandboxNeeds() agent.SandboxNeeds {
+	return agent.SandboxNeeds{
+		Hosts: []string{"api.github.com", "*.githubcopilot.com"},
+		Env:   []string{"GH_TOKEN", "GITHUB_TOKEN"},
+		Dirs:  []string{".config/gh", ".copilot"},
+	}
+}
+
+// WithSandbox runs the CLI inside s.
+// Returns a new Agent instance with the updated config to avoid data races.
+func (a *Agent) WithSandbox(s *agent.Sandbox) agent.Agent {
+	newConfig := a.config
+	newConfig.Sandbox = s
+
+	return &Agent{
+		config: newConfig,
+		mode:   a.mode,
+		target: a.target,
+		parser: a.parser,
+	}
+}
+
 // Metadata returns agent capabilities.
 func (a *Agent) Metadata() agent.AgentMetadata {
 	return agent.AgentMetadata{
@@ -163,6 +163,9 @@ func (a *Agent) executeStream(ctx context.Context, prompt string, eventCh chan<-
 	for k, v := range a.config.Environment {
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
 	}
+	if err := a.config.Sandbox.Wrap(cmd, a.config.Environment); err != nil {
+		return err
+	}
 
 	// Get stdout pipe
 	stdout, err := cmd.StdoutPipe()
@@ -232,6 +235,11 @@ func (a *Agent) executeStream(ctx context.Context, prompt string, eventCh chan<-
 
 	// Wait for command to finish
 	if err := cmd.Wait(); err != nil {
+		// A killed process after cancellation is an interrupt, not a failure
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
 		// Check if it's just a non-zero exit (which might be okay)
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
@@ -289,6 +297,11 @@ func (a *Agent) WithWorkDir(dir string) *Agent {
 	}
 }
 
+// InDir runs the agent in dir, e.g. a worktree.
+func (a *Agent) InDir(dir string) agent.Agent {
+	return a.WithWorkDir(dir)
+}
+
 // WithTimeout sets execution timeout
 // Returns a new Agent instance with the updated config to avoid data races.
 func (a *Agent) WithTimeout(d time.Duration) *Agent {
@@ -331,6 +344,28 @@ func (a *Agent) WithArgs(args ...string) agent.Agent {
 	}
 }
 
+// SandboxNeeds returns the hosts, credentials and state the gemini CLI needs
+// from outside a sandbox.
+func (a *Agent) SandboxNeeds() agent.SandboxNeeds {
+	return agent.SandboxNeeds{
+		Hosts: []string{"generativelanguage.googleapis.com", "cloudcode-pa.googleapis.com", "oauth2.googleapis.com"},
+		Env:   []string{"GEMINI_API_KEY", "GOOGLE_API_KEY", "GOOGLE_CLOUD_PROJECT"},
+		Dirs:  []string{".gemini"},
+	}
+}
+
+// WithSandbox runs the CLI inside s.
+// Returns a new Agent instance with the updated config to avoid data races.
+func (a *Agent) WithSandbox(s *agent.Sandbox) agent.Agent {
+	newConfig := a.config
+	newConfig.Sandbox = s
+
+	return &Agent{
+		config: newConfig,
+		parser: a.parser,
+	}
+}
+
 // Metadata returns information about the Gemini agent.
 func (a *Agent) Metadata() agent.AgentMetadata {
 	return agent.AgentMetadata{
@@ -374,6 +409,7 @@ func Register(r *agent.Registry) error {
 
 // Ensure Agent implements agent.Agent.
 var _ agent.Agent = (*Agent)(nil)
+var _ agent.Sandboxer = (*Agent)(nil)
 
 // Ensure Agent implements agent.MetadataProvider.
 var _ agent.MetadataProvider = (*Agent)(nil)
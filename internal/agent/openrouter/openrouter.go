@@ -265,6 +265,10 @@ func (a *Agent) executeStream(ctx context.Context, prompt string, eventCh chan<-
 			break
 		}
 		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
 			return fmt.Errorf("read stream: %w", err)
 		}
 
@@ -392,6 +396,11 @@ func (a *Agent) WithWorkDir(dir string) *Agent {
 	}
 }
 
+// InDir runs the agent in dir, e.g. a worktree.
+func (a *Agent) InDir(dir string) agent.Agent {
+	return a.WithWorkDir(dir)
+}
+
 // WithTimeout sets execution timeout.
 func (a *Agent) WithTimeout(d time.Duration) *Agent {
 	newConfig := a.config
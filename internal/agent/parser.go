@@ -70,6 +70,11 @@ func (p *YAMLBlockParser) ParseEvent(line []byte) (Event, error) {
 			}
 		}
 
+		// claude reports its conversation ID on init and result events
+		if sessionID, ok := jsonData["session_id"].(string); ok {
+			event.SessionID = sessionID
+		}
+
 		// Check for usage stats
 		if usage, ok := jsonData["usage"].(map[string]any); ok {
 			event.Type = EventUsage
@@ -272,6 +277,10 @@ func (p *YAMLBlockParser) Parse(events []Event) (*Response, error) {
 	// Collect all text content and check for questions
 	var textBuilder strings.Builder
 	for _, event := range events {
+		if event.SessionID != "" {
+			response.SessionID = event.SessionID
+		}
+
 		// Check for AskUserQuestion tool call
 		if event.ToolCall != nil && event.ToolCall.Name == "AskUserQuestion" {
 			q := p.extractQuestion(event.ToolCall.Input)
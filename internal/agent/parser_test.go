@@ -372,6 +372,33 @@ func TestParse_TextEvents(t *testing.T) {
 	}
 }
 
+func TestParse_SessionID(t *testing.T) {
+	p := NewYAMLBlockParser()
+
+	var events []Event
+	for _, line := range []string{
+		`{"type":"system","subtype":"init","session_id":"abc-123"}`,
+		`{"type":"result","result":"Done","session_id":"abc-123","usage":{"input_tokens":10}}`,
+	} {
+		event, err := p.ParseEvent([]byte(line))
+		if err != nil {
+			t.Fatalf("ParseEvent: %v", err)
+		}
+		if event.SessionID != "abc-123" {
+			t.Errorf("ParseEvent(%s).SessionID = %q, want %q", line, event.SessionID, "abc-123")
+		}
+		events = append(events, event)
+	}
+
+	resp, err := p.Parse(events)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if resp.SessionID != "abc-123" {
+		t.Errorf("SessionID = %q, want %q", resp.SessionID, "abc-123")
+	}
+}
+
 func TestParse_WithFileBlocks(t *testing.T) {
 	p := NewYAMLBlockParser()
 
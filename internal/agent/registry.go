@@ -43,6 +43,18 @@ func (r *Registry) Register(agent Agent) error {
 	return nil
 }
 
+// Unregister removes an agent from the registry. Removing the fallback
+// agent leaves the registry without one until SetDefault is called.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.agents, name)
+	if r.fallback == name {
+		r.fallback = ""
+	}
+}
+
 // Get returns an agent by name.
 func (r *Registry) Get(name string) (Agent, error) {
 	r.mu.RLock()
@@ -0,0 +1,340 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Sandbox modes an agent's CLI can run in.
+const (
+	SandboxNone   = "none"
+	SandboxDocker = "docker"
+	SandboxBwrap  = "bwrap"
+)
+
+// SandboxModes lists the valid values of agent.sandbox.
+var SandboxModes = []string{SandboxNone, SandboxDocker, SandboxBwrap}
+
+// baseSandboxEnv are the environment variables every sandboxed CLI keeps.
+var baseSandboxEnv = []string{"HOME", "USER", "PATH", "LANG", "LC_ALL", "TERM", "TZ"}
+
+// proxyEnv are the variables pointing a sandboxed CLI at its egress proxy.
+var proxyEnv = []string{"HTTPS_PROXY", "HTTP_PROXY", "https_proxy", "http_proxy"}
+
+// ErrNotSandboxable is returned when an agent cannot run inside a sandbox.
+var ErrNotSandboxable = errors.New("agent cannot run in a sandbox")
+
+// Sandbox confines an agent's CLI to a directory: it can change files only
+// below Root, sees only the system directories, its own installation and
+// the allowed environment variables, and has no network except the egress
+// proxy at ProxySocket.
+type Sandbox struct {
+	Mode        string   // docker or bwrap
+	Root        string   // Directory the CLI may change, usually the task's worktree
+	Env         []string // Host environment variables passed in, besides baseSandboxEnv and the agent's own
+	Dirs        []string // Files and directories the CLI keeps state in, relative to the home directory
+	Paths       []string // Further host paths the CLI may read (bwrap)
+	Image       string   // Image the CLI runs in (docker)
+	ProxySocket string   // Unix socket of the egress proxy; without it the CLI has no network
+	Helper      string   // mehr executable, run inside the sandbox to reach the proxy (see SandboxExec)
+}
+
+// SandboxNeeds lists what an agent's CLI needs from outside its sandbox.
+type SandboxNeeds struct {
+	Hosts []string // API hosts it connects to
+	Env   []string // Environment variables it reads, such as API keys
+	Dirs  []string // Files and directories it keeps state and logins in, relative to the home directory
+}
+
+// Sandboxer is implemented by agents running a local CLI that can be
+// confined to a sandbox.
+type Sandboxer interface {
+	// SandboxNeeds returns what the CLI needs from outside the sandbox.
+	SandboxNeeds() SandboxNeeds
+
+	// WithSandbox returns an agent whose CLI runs inside s.
+	WithSandbox(s *Sandbox) Agent
+}
+
+// NeedsOf returns what a (or the agent an alias or wrapper wraps) needs
+// from outside a sandbox, and false when it cannot be sandboxed.
+func NeedsOf(a Agent) (SandboxNeeds, bool) {
+	switch wrapped := a.(type) {
+	case *AliasAgent:
+		return NeedsOf(wrapped.base)
+	case *TracedAgent:
+		return NeedsOf(wrapped.base)
+	case *ChaosAgent:
+		return NeedsOf(wrapped.base)
+	}
+
+	s, ok := a.(Sandboxer)
+	if !ok {
+		return SandboxNeeds{}, false
+	}
+
+	return s.SandboxNeeds(), true
+}
+
+// Sandboxed returns a copy of a whose CLI runs inside s, keeping any alias
+// and wrappers around it.
+func Sandboxed(a Agent, s *Sandbox) (Agent, error) {
+	switch wrapped := a.(type) {
+	case *AliasAgent:
+		base, err := Sandboxed(wrapped.base, s)
+		if err != nil {
+			return a, err
+		}
+
+		return &AliasAgent{
+			name:        wrapped.name,
+			description: wrapped.description,
+			base:        base,
+			env:         wrapped.env,
+			args:        wrapped.args,
+		}, nil
+	case *TracedAgent:
+		base, err := Sandboxed(wrapped.base, s)
+		if err != nil {
+			return a, err
+		}
+
+		return &TracedAgent{base: base, t: wrapped.t, step: wrapped.step}, nil
+	case *ChaosAgent:
+		base, err := Sandboxed(wrapped.base, s)
+		if err != nil {
+			return a, err
+		}
+
+		return &ChaosAgent{base: base}, nil
+	}
+
+	sb, ok := a.(Sandboxer)
+	if !ok {
+		return a, fmt.Errorf("%s: %w", a.Name(), ErrNotSandboxable)
+	}
+
+	return sb.WithSandbox(s), nil
+}
+
+// Wrap rewrites cmd, built to run an agent's CLI on the host, to run it
+// inside the sandbox. Only the allowed variables of cmd.Env are kept, along
+// with agentEnv, the variables the agent was configured with. Wrap does
+// nothing on a nil sandbox.
+func (s *Sandbox) Wrap(cmd *exec.Cmd, agentEnv map[string]string) error {
+	if s == nil || s.Mode == "" || s.Mode == SandboxNone {
+		return nil
+	}
+
+	root, err := filepath.Abs(s.Root)
+	if err != nil {
+		return fmt.Errorf("sandbox root: %w", err)
+	}
+	// The CLI starts where it would have, as long as that is inside the root
+	dir := root
+	if cmd.Dir != "" {
+		if rel, err := filepath.Rel(root, cmd.Dir); err == nil && !strings.HasPrefix(rel, "..") {
+			dir = cmd.Dir
+		}
+	}
+	if s.ProxySocket != "" && s.Helper == "" {
+		return errors.New("sandbox proxy needs the mehr executable to forward to it")
+	}
+	env := s.environ(cmd.Env, agentEnv)
+
+	var runner string
+	var args []string
+	switch s.Mode {
+	case SandboxBwrap:
+		runner, args = "bwrap", s.bwrapArgs(root, dir, cmd.Path)
+	case SandboxDocker:
+		if s.Image == "" {
+			return errors.New("docker sandbox needs an image (agent.sandbox_image)")
+		}
+		runner, args = "docker", s.dockerArgs(root, dir, env)
+	default:
+		return fmt.Errorf("unknown sandbox %q", s.Mode)
+	}
+
+	path, err := exec.LookPath(runner)
+	if err != nil {
+		return fmt.Errorf("%s sandbox: %w", s.Mode, err)
+	}
+	// The CLI is looked up inside the sandbox, not on the host
+	cmd.Path = path
+	cmd.Args = append(append([]string{runner}, args...), cmd.Args...)
+	cmd.Err = nil
+	cmd.Env = env
+
+	return nil
+}
+
+// environ filters env down to the allowed variables and points the CLI at
+// the helper forwarding to the proxy.
+func (s *Sandbox) environ(env []string, agentEnv map[string]string) []string {
+	allowed := slices.Concat(baseSandboxEnv, s.Env)
+	var out []string
+	for _, kv := range env {
+		key, _, _ := strings.Cut(kv, "=")
+		if _, own := agentEnv[key]; own || slices.Contains(allowed, key) {
+			out = append(out, kv)
+		}
+	}
+	if s.ProxySocket != "" {
+		for _, key := range proxyEnv {
+			out = append(out, key+"=http://"+sandboxProxyAddr)
+		}
+	}
+
+	return out
+}
+
+// stateDirs returns the absolute paths of the state files and directories
+// that exist.
+func (s *Sandbox) stateDirs() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, d := range s.Dirs {
+		p := filepath.Join(home, d)
+		if _, err := os.Stat(p); err == nil {
+			dirs = append(dirs, p)
+		}
+	}
+
+	return dirs
+}
+
+// systemDirs are the host directories a bwrap sandbox sees, read-only.
+// Home directories are left out, so the CLI cannot read credentials such as
+// ~/.ssh or ~/.aws.
+var systemDirs = []string{"/usr", "/bin", "/sbin", "/lib", "/lib32", "/lib64", "/etc", "/opt", "/nix"}
+
+// bwrapArgs mounts the system directories and the CLI's installation
+// read-only, with the root and the state directories writable, a throwaway
+// home and a private /tmp. All namespaces are unshared, the network
+// included, so the CLI reaches the proxy only through its bind-mounted
+// socket. The filtered environment is inherited rather than passed as
+// arguments, which other users could read.
+func (s *Sandbox) bwrapArgs(root, dir, cli string) []string {
+	args := []string{"--die-with-parent", "--unshare-all"}
+	for _, d := range systemDirs {
+		info, err := os.Lstat(d)
+		switch {
+		case err != nil:
+		case info.Mode()&os.ModeSymlink != 0:
+			// Merged /usr: /bin links to usr/bin
+			if target, err := os.Readlink(d); err == nil {
+				args = append(args, "--symlink", target, d)
+			}
+		default:
+			args = append(args, "--ro-bind", d, d)
+		}
+	}
+	args = append(args, "--dev", "/dev", "--proc", "/proc", "--tmpfs", "/tmp")
+	if home, err := os.UserHomeDir(); err == nil {
+		args = append(args, "--tmpfs", home)
+	}
+	// After the tmpfs mounts, which would hide what is below them
+	for _, p := range slices.Concat(toolchainDirs(cli), s.Paths, []string{s.Helper}) {
+		if p != "" && !underSystemDir(p) {
+			args = append(args, "--ro-bind-try", p, p)
+		}
+	}
+	args = append(args, "--bind", root, root)
+	for _, d := range s.stateDirs() {
+		args = append(args, "--bind", d, d)
+	}
+	if s.ProxySocket != "" {
+		sockDir := filepath.Dir(s.ProxySocket)
+		args = append(args, "--bind", sockDir, sockDir)
+	}
+	args = append(args, "--chdir", dir, "--")
+	if s.ProxySocket != "" {
+		args = append(args, s.Helper, SandboxExecCommand, s.ProxySocket)
+	}
+
+	return args
+}
+
+// toolchainDirs returns the installation a CLI at path runs from: for a
+// CLI in a bin directory the directory above it, such as a Node.js prefix
+// holding both the CLI and its interpreter, else its own directory. Links
+// are followed, so a CLI linked into ~/.local/bin brings its package along.
+// The home directory itself is never returned.
+func toolchainDirs(path string) []string {
+	if !filepath.IsAbs(path) {
+		return nil
+	}
+	home, _ := os.UserHomeDir()
+	paths := []string{path}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil && resolved != path {
+		paths = append(paths, resolved)
+	}
+
+	var dirs []string
+	for _, p := range paths {
+		dir := filepath.Dir(p)
+		if prefix := filepath.Dir(dir); filepath.Base(dir) == "bin" && prefix != home && prefix != "/" {
+			dir = prefix
+		}
+		if dir != home && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs
+}
+
+// underSystemDir reports whether path is already mounted as part of a
+// system directory.
+func underSystemDir(path string) bool {
+	return slices.ContainsFunc(systemDirs, func(d string) bool {
+		return path == d || strings.HasPrefix(path, d+"/")
+	})
+}
+
+// dockerArgs runs the CLI as the current user in a throwaway container
+// without a network, with the root and the state directories mounted at
+// their host paths. With a proxy, the helper is mounted in as the
+// entrypoint along with the proxy's socket; it must be able to run in the
+// image, which a statically linked mehr can.
+func (s *Sandbox) dockerArgs(root, dir string, env []string) []string {
+	args := []string{
+		"run", "--rm", "-i", "--network", "none",
+		"--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()),
+		"--volume", root + ":" + root,
+		"--workdir", dir,
+	}
+	for _, d := range s.stateDirs() {
+		args = append(args, "--volume", d+":"+d)
+	}
+	if s.ProxySocket != "" {
+		sockDir := filepath.Dir(s.ProxySocket)
+		args = append(args,
+			"--volume", sockDir+":"+sockDir,
+			"--volume", s.Helper+":"+s.Helper+":ro",
+			"--entrypoint", s.Helper,
+		)
+	}
+	for _, kv := range env {
+		// The image's PATH finds the CLI; the values come from docker's own environment
+		if key, _, _ := strings.Cut(kv, "="); key != "PATH" {
+			args = append(args, "--env", key)
+		}
+	}
+
+	args = append(args, s.Image)
+	if s.ProxySocket != "" {
+		args = append(args, SandboxExecCommand, s.ProxySocket)
+	}
+
+	return args
+}
@@ -0,0 +1,98 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+)
+
+// SandboxExecCommand is the first argument that makes the mehr binary run
+// as the helper inside a sandbox: it forwards the sandbox's loopback proxy
+// address to the egress proxy's socket and runs the agent's CLI.
+const SandboxExecCommand = "__sandbox-exec"
+
+// sandboxProxyAddr is where the helper listens inside the sandbox. The
+// sandbox has its own network namespace, so the port is always free.
+const sandboxProxyAddr = "127.0.0.1:3128"
+
+// SandboxExec runs the sandbox helper with the arguments following
+// SandboxExecCommand: the egress proxy's socket, then the CLI command. It
+// returns the CLI's exit code.
+func SandboxExec(args []string) int {
+	if len(args) < 2 {
+		fmt.Fprintf(os.Stderr, "usage: mehr %s <proxy-socket> <command> [args...]\n", SandboxExecCommand)
+
+		return 2
+	}
+
+	ln, err := net.Listen("tcp", sandboxProxyAddr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox proxy: %v\n", err)
+
+		return 1
+	}
+	defer func() { _ = ln.Close() }()
+	go forwardProxy(ln, args[0])
+
+	cmd := exec.Command(args[1], args[2:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox: %v\n", err)
+
+		return 127
+	}
+
+	// Pass interrupts on, so the CLI can shut down cleanly
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		for sig := range signals {
+			_ = cmd.Process.Signal(sig)
+		}
+	}()
+
+	if err := cmd.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+
+		return 1
+	}
+
+	return 0
+}
+
+// forwardProxy relays each connection accepted on ln to the Unix socket at
+// socket, until ln is closed.
+func forwardProxy(ln net.Listener, socket string) {
+	for {
+		client, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer func() { _ = client.Close() }()
+			upstream, err := net.Dial("unix", socket)
+			if err != nil {
+				return
+			}
+			defer func() { _ = upstream.Close() }()
+
+			done := make(chan struct{}, 2)
+			pipe := func(dst, src net.Conn) {
+				_, _ = io.Copy(dst, src)
+				done <- struct{}{}
+			}
+			go pipe(upstream, client)
+			go pipe(client, upstream)
+			<-done
+		}()
+	}
+}
@@ -0,0 +1,181 @@
+package agent
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EgressProxy is an HTTP proxy that only connects to allowed hosts. It
+// listens on a Unix socket in a private directory, which is mounted into
+// the sandbox; the sandbox has no network of its own, so sandboxed CLIs
+// reach their API only through it.
+type EgressProxy struct {
+	hosts    []string
+	dir      string
+	listener net.Listener
+	server   *http.Server
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	tunnels map[net.Conn]struct{} // Hijacked connections, which the server no longer tracks
+}
+
+// StartEgressProxy starts a proxy letting through the given hosts. A host
+// starting with "*." also allows its subdomains.
+func StartEgressProxy(hosts []string) (*EgressProxy, error) {
+	// MkdirTemp creates the directory readable only by the current user
+	dir, err := os.MkdirTemp("", "mehr-proxy-")
+	if err != nil {
+		return nil, fmt.Errorf("start egress proxy: %w", err)
+	}
+	ln, err := net.Listen("unix", filepath.Join(dir, "proxy.sock"))
+	if err != nil {
+		_ = os.RemoveAll(dir)
+
+		return nil, fmt.Errorf("start egress proxy: %w", err)
+	}
+
+	p := &EgressProxy{hosts: hosts, dir: dir, listener: ln, tunnels: make(map[net.Conn]struct{})}
+	p.server = &http.Server{Handler: p, ReadHeaderTimeout: 10 * time.Second}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		_ = p.server.Serve(ln)
+	}()
+
+	return p, nil
+}
+
+// Socket returns the path of the Unix socket the proxy listens on.
+func (p *EgressProxy) Socket() string {
+	return p.listener.Addr().String()
+}
+
+// Close stops the proxy and the tunnels through it, and removes its socket.
+func (p *EgressProxy) Close() error {
+	err := p.server.Close()
+	p.mu.Lock()
+	for conn := range p.tunnels {
+		_ = conn.Close()
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+	_ = os.RemoveAll(p.dir)
+
+	return err
+}
+
+// Allowed reports whether the proxy connects to host.
+func (p *EgressProxy) Allowed(host string) bool {
+	host = strings.ToLower(host)
+
+	return slices.ContainsFunc(p.hosts, func(allowed string) bool {
+		allowed = strings.ToLower(allowed)
+		if suffix, ok := strings.CutPrefix(allowed, "*"); ok {
+			return strings.HasSuffix(host, suffix)
+		}
+
+		return host == allowed
+	})
+}
+
+// ServeHTTP tunnels CONNECT requests and forwards plain HTTP requests to
+// allowed hosts, and refuses everything else.
+func (p *EgressProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if r.Method != http.MethodConnect && r.URL.Host != "" {
+		host = r.URL.Host
+	}
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	if !p.Allowed(hostname) {
+		http.Error(w, fmt.Sprintf("sandbox: %s is not an allowed host", hostname), http.StatusForbidden)
+
+		return
+	}
+
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, host)
+
+		return
+	}
+
+	r.RequestURI = ""
+	resp, err := http.DefaultTransport.RoundTrip(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+// tunnel connects the client to host and copies bytes both ways.
+func (p *EgressProxy) tunnel(w http.ResponseWriter, host string) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "tunneling not supported", http.StatusInternalServerError)
+
+		return
+	}
+	upstream, err := net.DialTimeout("tcp", host, 30*time.Second)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+
+		return
+	}
+	client, _, err := hijacker.Hijack()
+	if err != nil {
+		_ = upstream.Close()
+
+		return
+	}
+	if _, err := client.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		_ = client.Close()
+		_ = upstream.Close()
+
+		return
+	}
+
+	p.mu.Lock()
+	p.tunnels[client] = struct{}{}
+	p.tunnels[upstream] = struct{}{}
+	p.mu.Unlock()
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer func() {
+			p.mu.Lock()
+			delete(p.tunnels, client)
+			delete(p.tunnels, upstream)
+			p.mu.Unlock()
+		}()
+		done := make(chan struct{}, 2)
+		pipe := func(dst, src net.Conn) {
+			_, _ = io.Copy(dst, src)
+			done <- struct{}{}
+		}
+		go pipe(upstream, client)
+		go pipe(client, upstream)
+		<-done
+		_ = client.Close()
+		_ = upstream.Close()
+		<-done
+	}()
+}
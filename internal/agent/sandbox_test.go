@@ -0,0 +1,232 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// sandboxedMock is a CLI agent that records the sandbox it runs in.
+type sandboxedMock struct {
+	mockAgent
+	sandbox *Sandbox
+}
+
+func (a *sandboxedMock) SandboxNeeds() SandboxNeeds {
+	return SandboxNeeds{Hosts: []string{"api.example.com"}, Env: []string{"EXAMPLE_API_KEY"}}
+}
+
+func (a *sandboxedMock) WithSandbox(s *Sandbox) Agent {
+	return &sandboxedMock{mockAgent: a.mockAgent, sandbox: s}
+}
+
+func TestSandboxed(t *testing.T) {
+	base := &sandboxedMock{mockAgent: mockAgent{name: "cli"}}
+	wrapped := WithFailureInjection(NewAlias("fast", base, nil, nil, ""))
+
+	needs, ok := NeedsOf(wrapped)
+	if !ok || !slices.Equal(needs.Hosts, []string{"api.example.com"}) {
+		t.Fatalf("NeedsOf() = %+v, %v", needs, ok)
+	}
+
+	s := &Sandbox{Mode: SandboxBwrap, Root: t.TempDir()}
+	got, err := Sandboxed(wrapped, s)
+	if err != nil {
+		t.Fatalf("Sandboxed: %v", err)
+	}
+	alias, ok := got.(*ChaosAgent).base.(*AliasAgent)
+	if !ok || alias.Name() != "fast" {
+		t.Fatalf("Sandboxed() lost the wrappers: %#v", got)
+	}
+	if alias.base.(*sandboxedMock).sandbox != s {
+		t.Error("CLI agent not sandboxed")
+	}
+
+	if _, err := Sandboxed(&mockAgent{name: "http"}, s); !errors.Is(err, ErrNotSandboxable) {
+		t.Errorf("Sandboxed(non-CLI agent) = %v, want ErrNotSandboxable", err)
+	}
+}
+
+func TestSandboxEnviron(t *testing.T) {
+	s := &Sandbox{Env: []string{"EXAMPLE_API_KEY"}, ProxySocket: "/tmp/mehr-proxy-1/proxy.sock"}
+	env := []string{"PATH=/usr/bin", "EXAMPLE_API_KEY=k", "AWS_SECRET_ACCESS_KEY=s", "MODEL=fast", "NO_PROXY=*"}
+
+	got := s.environ(env, map[string]string{"MODEL": "fast"})
+	want := []string{
+		"PATH=/usr/bin", "EXAMPLE_API_KEY=k", "MODEL=fast",
+		"HTTPS_PROXY=http://127.0.0.1:3128", "HTTP_PROXY=http://127.0.0.1:3128",
+		"https_proxy=http://127.0.0.1:3128", "http_proxy=http://127.0.0.1:3128",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("environ() = %v, want %v", got, want)
+	}
+}
+
+func TestSandboxWrap(t *testing.T) {
+	if err := (*Sandbox)(nil).Wrap(exec.Command("claude"), nil); err != nil {
+		t.Errorf("nil sandbox: %v", err)
+	}
+
+	root := t.TempDir()
+	bin := t.TempDir()
+	for _, runner := range []string{"bwrap", "docker"} {
+		if err := os.WriteFile(filepath.Join(bin, runner), []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin)
+
+	cmd := exec.Command("claude", "-p", "hello")
+	cmd.Env = []string{"PATH=" + bin, "SECRET=s"}
+	if err := (&Sandbox{Mode: SandboxBwrap, Root: root}).Wrap(cmd, nil); err != nil {
+		t.Fatalf("bwrap: %v", err)
+	}
+	args := strings.Join(cmd.Args, " ")
+	if cmd.Path != filepath.Join(bin, "bwrap") || !strings.Contains(args, fmt.Sprintf("--bind %s %s", root, root)) ||
+		!strings.HasSuffix(args, "--chdir "+root+" -- claude -p hello") {
+		t.Errorf("bwrap command = %s %s", cmd.Path, args)
+	}
+	if strings.Contains(args, "--share-net") || strings.Contains(args, "--ro-bind / /") {
+		t.Errorf("bwrap command = %s, want no host network and no host root", args)
+	}
+	if slices.Contains(cmd.Env, "SECRET=s") {
+		t.Errorf("bwrap env = %v, want SECRET dropped", cmd.Env)
+	}
+
+	socket := filepath.Join(t.TempDir(), "proxy.sock")
+	cmd = exec.Command("claude")
+	if err := (&Sandbox{Mode: SandboxBwrap, Root: root, ProxySocket: socket}).Wrap(cmd, nil); err == nil {
+		t.Error("a proxy without the helper should fail")
+	}
+	cmd = exec.Command("claude")
+	if err := (&Sandbox{Mode: SandboxBwrap, Root: root, ProxySocket: socket, Helper: "/opt/mehr/mehr"}).Wrap(cmd, nil); err != nil {
+		t.Fatalf("bwrap with proxy: %v", err)
+	}
+	args = strings.Join(cmd.Args, " ")
+	sockDir := filepath.Dir(socket)
+	if !strings.Contains(args, "--bind "+sockDir+" "+sockDir) ||
+		!strings.HasSuffix(args, "-- /opt/mehr/mehr "+SandboxExecCommand+" "+socket+" claude") {
+		t.Errorf("bwrap command = %s", args)
+	}
+
+	cmd = exec.Command("claude")
+	if err := (&Sandbox{Mode: SandboxDocker, Root: root}).Wrap(cmd, nil); err == nil {
+		t.Error("docker without an image should fail")
+	}
+	cmd = exec.Command("claude")
+	cmd.Env = []string{"PATH=" + bin, "HOME=/home/dev"}
+	if err := (&Sandbox{Mode: SandboxDocker, Root: root, Image: "agents:latest"}).Wrap(cmd, nil); err != nil {
+		t.Fatalf("docker: %v", err)
+	}
+	args = strings.Join(cmd.Args, " ")
+	if !strings.Contains(args, "--volume "+root+":"+root) || !strings.Contains(args, "--env HOME") ||
+		!strings.Contains(args, "--network none") ||
+		strings.Contains(args, "--env PATH") || !strings.HasSuffix(args, "agents:latest claude") {
+		t.Errorf("docker command = %s", args)
+	}
+
+	cmd = exec.Command("claude")
+	sb := &Sandbox{Mode: SandboxDocker, Root: root, Image: "agents:latest", ProxySocket: socket, Helper: "/opt/mehr/mehr"}
+	if err := sb.Wrap(cmd, nil); err != nil {
+		t.Fatalf("docker with proxy: %v", err)
+	}
+	args = strings.Join(cmd.Args, " ")
+	if !strings.Contains(args, "--volume "+sockDir+":"+sockDir) || !strings.Contains(args, "--entrypoint /opt/mehr/mehr") ||
+		!strings.HasSuffix(args, "agents:latest "+SandboxExecCommand+" "+socket+" claude") {
+		t.Errorf("docker command = %s", args)
+	}
+}
+
+func TestToolchainDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	node := filepath.Join(home, ".nvm", "versions", "node", "v22")
+	pkg := filepath.Join(node, "lib", "node_modules", "cli")
+	for _, dir := range []string{filepath.Join(node, "bin"), pkg, filepath.Join(home, "bin")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(pkg, "cli.js"), nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(pkg, "cli.js"), filepath.Join(node, "bin", "cli")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := toolchainDirs(filepath.Join(node, "bin", "cli")); !slices.Equal(got, []string{node, pkg}) {
+		t.Errorf("toolchainDirs(nvm) = %v, want the Node.js prefix and the package", got)
+	}
+	if got := toolchainDirs(filepath.Join(home, "bin", "cli")); !slices.Equal(got, []string{filepath.Join(home, "bin")}) {
+		t.Errorf("toolchainDirs(~/bin) = %v, want only ~/bin, never the home directory", got)
+	}
+	if got := toolchainDirs("cli"); got != nil {
+		t.Errorf("toolchainDirs(relative) = %v, want nothing", got)
+	}
+}
+
+func TestEgressProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer upstream.Close()
+
+	proxy, err := StartEgressProxy([]string{"127.0.0.1", "*.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = proxy.Close() }()
+
+	if !proxy.Allowed("api.example.com") || proxy.Allowed("example.org") || proxy.Allowed("localhost") {
+		t.Error("Allowed() does not follow the host list")
+	}
+
+	// Reach the proxy the way a sandboxed CLI does: through the helper's
+	// loopback listener, which forwards to the proxy's socket
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+	go forwardProxy(ln, proxy.Socket())
+
+	proxyURL, err := url.Parse("http://" + ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	resp, err := client.Get(upstream.URL)
+	if err != nil {
+		t.Fatalf("allowed host: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("allowed host status = %d", resp.StatusCode)
+	}
+
+	resp, err = client.Get(strings.Replace(upstream.URL, "127.0.0.1", "localhost", 1))
+	if err != nil {
+		t.Fatalf("refused host: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("refused host status = %d, want 403", resp.StatusCode)
+	}
+
+	socketDir := filepath.Dir(proxy.Socket())
+	_ = proxy.Close()
+	if _, err := os.Stat(socketDir); !os.IsNotExist(err) {
+		t.Errorf("Close() left the socket directory behind: %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/telemetry"
+)
+
+// TracedAgent records a telemetry span and usage counters for every call to
+// the wrapped agent.
+type TracedAgent struct {
+	base Agent
+	t    *telemetry.Telemetry
+	step string
+}
+
+// WithTracing wraps a so that its calls are recorded as "agent.run" spans
+// for the given workflow step. It returns a unchanged when t is nil.
+func WithTracing(a Agent, t *telemetry.Telemetry, step string) Agent {
+	if !t.Enabled() {
+		return a
+	}
+	if traced, ok := a.(*TracedAgent); ok {
+		a = traced.base
+	}
+
+	return &TracedAgent{base: a, t: t, step: step}
+}
+
+// Name returns the wrapped agent's name.
+func (a *TracedAgent) Name() string {
+	return a.base.Name()
+}
+
+// Run executes the prompt inside a span.
+func (a *TracedAgent) Run(ctx context.Context, prompt string) (*Response, error) {
+	ctx, span := a.startSpan(ctx)
+	start := time.Now()
+	resp, err := a.base.Run(ctx, prompt)
+	a.endSpan(span, start, resp, err)
+
+	return resp, err
+}
+
+// RunStream streams the prompt's events; the span ends when the error
+// channel is closed.
+func (a *TracedAgent) RunStream(ctx context.Context, prompt string) (<-chan Event, <-chan error) {
+	ctx, span := a.startSpan(ctx)
+	start := time.Now()
+	eventCh, baseErrCh := a.base.RunStream(ctx, prompt)
+
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(errCh)
+
+		var runErr error
+		for err := range baseErrCh {
+			if runErr == nil {
+				runErr = err
+			}
+			errCh <- err
+		}
+		a.endSpan(span, start, nil, runErr)
+	}()
+
+	return eventCh, errCh
+}
+
+// RunWithCallback executes with a callback inside a span.
+func (a *TracedAgent) RunWithCallback(ctx context.Context, prompt string, cb StreamCallback) (*Response, error) {
+	ctx, span := a.startSpan(ctx)
+	start := time.Now()
+	resp, err := a.base.RunWithCallback(ctx, prompt, cb)
+	a.endSpan(span, start, resp, err)
+
+	return resp, err
+}
+
+// RunWithAttachments executes with attached files inside a span.
+func (a *TracedAgent) RunWithAttachments(ctx context.Context, prompt string, files []string, cb StreamCallback) (*Response, error) {
+	ctx, span := a.startSpan(ctx)
+	start := time.Now()
+	resp, err := RunWithAttachments(ctx, a.base, prompt, files, cb)
+	a.endSpan(span, start, resp, err)
+
+	return resp, err
+}
+
+// Available checks if the wrapped agent is available.
+func (a *TracedAgent) Available() error {
+	return a.base.Available()
+}
+
+// WithEnv adds an environment variable to the wrapped agent.
+func (a *TracedAgent) WithEnv(key, value string) Agent {
+	return &TracedAgent{base: a.base.WithEnv(key, value), t: a.t, step: a.step}
+}
+
+// WithArgs adds CLI arguments to the wrapped agent.
+func (a *TracedAgent) WithArgs(args ...string) Agent {
+	return &TracedAgent{base: a.base.WithArgs(args...), t: a.t, step: a.step}
+}
+
+func (a *TracedAgent) attrs() []telemetry.Attr {
+	return []telemetry.Attr{
+		telemetry.String("mehr.agent", a.base.Name()),
+		telemetry.String("mehr.step", a.step),
+	}
+}
+
+func (a *TracedAgent) startSpan(ctx context.Context) (context.Context, *telemetry.Span) {
+	return a.t.StartSpan(ctx, "agent.run", a.attrs()...)
+}
+
+// endSpan records the call's outcome, duration and token usage.
+func (a *TracedAgent) endSpan(span *telemetry.Span, start time.Time, resp *Response, err error) {
+	attrs := a.attrs()
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+
+	a.t.Add("mehr.agent.runs", 1, append(attrs, telemetry.String("status", status))...)
+	a.t.Add("mehr.agent.duration", time.Since(start).Seconds(), attrs...)
+
+	if resp != nil && resp.Usage != nil {
+		usage := resp.Usage
+		span.SetAttributes(
+			telemetry.Int("mehr.tokens.input", usage.InputTokens),
+			telemetry.Int("mehr.tokens.output", usage.OutputTokens),
+			telemetry.Int("mehr.tokens.cached", usage.CachedTokens),
+			telemetry.Float("mehr.cost_usd", usage.CostUSD),
+		)
+		a.t.Add("mehr.agent.tokens", float64(usage.InputTokens), append(attrs, telemetry.String("type", "input"))...)
+		a.t.Add("mehr.agent.tokens", float64(usage.OutputTokens), append(attrs, telemetry.String("type", "output"))...)
+		a.t.Add("mehr.agent.tokens", float64(usage.CachedTokens), append(attrs, telemetry.String("type", "cached"))...)
+		a.t.Add("mehr.agent.cost", usage.CostUSD, attrs...)
+	}
+
+	span.End(err)
+}
@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/telemetry"
+)
+
+func TestWithTracing(t *testing.T) {
+	base := &mockAgent{name: "mock", response: &Response{Usage: &UsageStats{InputTokens: 100, OutputTokens: 20, CostUSD: 0.01}}}
+	if WithTracing(base, nil, "planning") != base {
+		t.Error("disabled telemetry should return the agent unchanged")
+	}
+
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		raw, _ := json.Marshal(payload)
+		bodies = append(bodies, r.URL.Path+" "+string(raw))
+	}))
+	t.Cleanup(srv.Close)
+
+	tel := telemetry.New(telemetry.Config{Endpoint: srv.URL})
+	a := WithTracing(base, tel, "planning")
+	if WithTracing(a, tel, "planning").(*TracedAgent).base != base {
+		t.Error("wrapping twice should not nest")
+	}
+	if a.Name() != "mock" {
+		t.Errorf("Name() = %q", a.Name())
+	}
+
+	// A root agent span exports as soon as it ends
+	if _, err := a.Run(t.Context(), "plan"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	all := strings.Join(bodies, "\n")
+	for _, want := range []string{`/v1/traces`, `"name":"agent.run"`, `"mehr.agent.tokens"`, `"stringValue":"planning"`, `"asDouble":100`} {
+		if !strings.Contains(all, want) {
+			t.Errorf("export missing %s:\n%s", want, all)
+		}
+	}
+
+	if _, ok := Resume(a, "session-1"); ok {
+		t.Error("mock agent cannot resume, Resume should report false")
+	}
+}
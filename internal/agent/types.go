@@ -23,6 +23,7 @@ type Event struct {
 	Raw       []byte
 	ToolCall  *ToolCall // Standardized tool call info (if EventToolUse)
 	Text      string    // Extracted text content (if EventText)
+	SessionID string    // Agent-native conversation ID, when the event carries one
 }
 
 // Response is the aggregated result from an agent run.
@@ -33,6 +34,8 @@ type Response struct {
 	Usage    *UsageStats
 	Duration time.Duration
 	Question *Question // Pending question if agent asked one
+	// Agent-native conversation ID; agents implementing Resumer can continue it
+	SessionID string
 }
 
 // Question represents a question from the agent to the user.
@@ -87,6 +90,7 @@ type Config struct {
 	RetryCount  int
 	RetryDelay  time.Duration
 	WorkDir     string
+	Sandbox     *Sandbox // Runs the CLI confined to a sandbox when set
 }
 
 // NewConfig creates a default config.
@@ -0,0 +1,503 @@
+// Package backup creates and restores encrypted archives of mehrhof workspace state.
+//
+// An archive contains everything needed to move a workspace between machines
+// or recover it after a bad delete: config.yaml, task work directories
+// (specifications, notes, sessions, usage), planned tasks, project plugins and
+// the active task reference. Lock files and temporary files are excluded.
+//
+// Secrets in .mehrhof/.env are stored as pointers by default: the variable
+// names are kept but values are blanked, so a restored workspace shows which
+// secrets need to be provided. Options.IncludeSecrets keeps the values.
+//
+// Archive format:
+//
+//	"MEHRBAK1" | iterations (uint32, big endian) | salt (16 bytes) | nonce (12 bytes) | ciphertext
+//
+// The ciphertext is AES-256-GCM over a gzip-compressed tar stream whose first
+// entry is manifest.json. The key is derived from a passphrase with
+// PBKDF2-HMAC-SHA256.
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// Backup errors.
+var (
+	ErrNoPassphrase    = errors.New("backup passphrase is empty")
+	ErrNotBackup       = errors.New("not a mehrhof backup file")
+	ErrDecrypt         = errors.New("wrong passphrase or corrupted backup")
+	ErrWorkspaceExists = errors.New("workspace already contains mehrhof state")
+)
+
+const (
+	// ManifestName is the name of the manifest entry in the archive.
+	ManifestName = "manifest.json"
+
+	// FormatVersion is the current archive format version.
+	FormatVersion = 1
+
+	magic    = "MEHRBAK1"
+	saltSize = 16
+	keySize  = 32
+)
+
+// kdfIterations is the PBKDF2 iteration count for new archives.
+// It is a variable so tests can lower it.
+var kdfIterations = 600_000
+
+// maxKDFIterations bounds the iteration count read from an archive header.
+const maxKDFIterations = 10_000_000
+
+// excludedDirs are .mehrhof subdirectories that hold runtime state, not data.
+var excludedDirs = []string{"locks", "cache"}
+
+// Manifest describes the contents of a backup archive.
+type Manifest struct {
+	Version         int       `json:"version"`
+	CreatedAt       time.Time `json:"created_at"`
+	Hostname        string    `json:"hostname,omitempty"`
+	Tasks           []string  `json:"tasks"`
+	ActiveTask      string    `json:"active_task,omitempty"`
+	Files           int       `json:"files"`
+	SecretsIncluded bool      `json:"secrets_included"`
+}
+
+// Options configures backup creation.
+type Options struct {
+	IncludeSecrets bool // Keep .env values instead of blanking them
+}
+
+// RestoreOptions configures backup restoration.
+type RestoreOptions struct {
+	Force bool // Overwrite existing state instead of refusing
+}
+
+// Create writes an encrypted archive of the workspace state to w.
+func Create(ws *storage.Workspace, w io.Writer, passphrase string, opts Options) (*Manifest, error) {
+	if passphrase == "" {
+		return nil, ErrNoPassphrase
+	}
+
+	files, err := collectFiles(ws)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		Version:         FormatVersion,
+		CreatedAt:       time.Now().UTC(),
+		Files:           len(files),
+		SecretsIncluded: opts.IncludeSecrets,
+	}
+	manifest.Hostname, _ = os.Hostname()
+	if manifest.Tasks, err = ws.ListWorks(); err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+	if ws.HasActiveTask() {
+		if active, err := ws.LoadActiveTask(); err == nil {
+			manifest.ActiveTask = active.ID
+		}
+	}
+
+	var archive bytes.Buffer
+	if err := writeArchive(&archive, ws, files, manifest, opts); err != nil {
+		return nil, err
+	}
+
+	if err := encrypt(w, archive.Bytes(), passphrase); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// Restore decrypts an archive from r and writes its files into the workspace.
+// Existing state is only overwritten when opts.Force is set. An existing .env
+// is never replaced by blanked secret pointers.
+func Restore(ws *storage.Workspace, r io.Reader, passphrase string, opts RestoreOptions) (*Manifest, error) {
+	if passphrase == "" {
+		return nil, ErrNoPassphrase
+	}
+
+	if !opts.Force && hasState(ws) {
+		return nil, fmt.Errorf("%w: %s (use --force to overwrite)", ErrWorkspaceExists, ws.TaskRoot())
+	}
+
+	data, err := decrypt(r, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return extractArchive(bytes.NewReader(data), ws)
+}
+
+// ReadManifest decrypts an archive and returns its manifest without restoring it.
+func ReadManifest(r io.Reader, passphrase string) (*Manifest, error) {
+	data, err := decrypt(r, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotBackup, err)
+	}
+	tr := tar.NewReader(gz)
+
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != ManifestName {
+		return nil, fmt.Errorf("%w: missing manifest", ErrNotBackup)
+	}
+
+	return decodeManifest(tr)
+}
+
+// collectFiles returns repo-relative paths of all files to back up.
+func collectFiles(ws *storage.Workspace) ([]string, error) {
+	root := ws.Root()
+	var files []string
+
+	walk := func(dir string) error {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if filepath.Dir(path) == ws.TaskRoot() && slices.Contains(excludedDirs, d.Name()) {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+			if !d.Type().IsRegular() || strings.HasSuffix(d.Name(), ".tmp") {
+				return nil
+			}
+
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, rel)
+
+			return nil
+		})
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	if err := walk(ws.TaskRoot()); err != nil {
+		return nil, fmt.Errorf("collect workspace files: %w", err)
+	}
+	// The work directory may be configured outside .mehrhof
+	if rel, err := filepath.Rel(ws.TaskRoot(), ws.WorkRoot()); err != nil || strings.HasPrefix(rel, "..") {
+		if err := walk(ws.WorkRoot()); err != nil {
+			return nil, fmt.Errorf("collect work files: %w", err)
+		}
+	}
+	if ws.HasActiveTask() {
+		rel, err := filepath.Rel(root, ws.ActiveTaskPath())
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, rel)
+	}
+
+	slices.Sort(files)
+
+	return files, nil
+}
+
+// writeArchive writes the manifest and files as a gzip-compressed tar stream.
+func writeArchive(w io.Writer, ws *storage.Workspace, files []string, manifest *Manifest, opts Options) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal manifest: %w", err)
+	}
+	if err := writeEntry(tw, ManifestName, manifestData, 0o644, manifest.CreatedAt); err != nil {
+		return err
+	}
+
+	envRel, _ := filepath.Rel(ws.Root(), ws.EnvPath())
+	for _, rel := range files {
+		path := filepath.Join(ws.Root(), rel)
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("stat %s: %w", rel, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", rel, err)
+		}
+		if rel == envRel && !opts.IncludeSecrets {
+			data = secretPointers(data)
+		}
+		if err := writeEntry(tw, filepath.ToSlash(rel), data, info.Mode().Perm(), info.ModTime()); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("close archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("compress archive: %w", err)
+	}
+
+	return nil
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte, mode os.FileMode, modTime time.Time) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    int64(mode),
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("write archive header %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("write archive entry %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// extractArchive writes archive entries into the workspace, rejecting the
+// archive if any entry lies outside the mehrhof state (see restorable).
+// The whole archive is read and checked before anything is written, so a bad
+// entry or a truncated archive leaves the workspace untouched.
+func extractArchive(r io.Reader, ws *storage.Workspace) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotBackup, err)
+	}
+	tr := tar.NewReader(gz)
+
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != ManifestName {
+		return nil, fmt.Errorf("%w: missing manifest", ErrNotBackup)
+	}
+	manifest, err := decodeManifest(tr)
+	if err != nil {
+		return nil, err
+	}
+
+	type entry struct {
+		name string
+		path string
+		mode os.FileMode
+		data []byte
+	}
+	var entries []entry
+
+	root := ws.Root()
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		path := filepath.Join(root, filepath.FromSlash(hdr.Name))
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%w: entry escapes workspace: %s", ErrNotBackup, hdr.Name)
+		}
+		if !restorable(ws, path) {
+			return nil, fmt.Errorf("%w: entry outside mehrhof state: %s", ErrNotBackup, hdr.Name)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("read archive entry %s: %w", hdr.Name, err)
+		}
+		entries = append(entries, entry{name: hdr.Name, path: path, mode: os.FileMode(hdr.Mode).Perm(), data: data})
+	}
+
+	for _, e := range entries {
+		// Keep real secrets over blanked pointers
+		if e.path == ws.EnvPath() && !manifest.SecretsIncluded {
+			if _, err := os.Stat(e.path); err == nil {
+				continue
+			}
+		}
+
+		if err := os.MkdirAll(filepath.Dir(e.path), 0o755); err != nil {
+			return nil, fmt.Errorf("create directory for %s: %w", e.name, err)
+		}
+		if err := os.WriteFile(e.path, e.data, e.mode); err != nil {
+			return nil, fmt.Errorf("write %s: %w", e.name, err)
+		}
+	}
+
+	return manifest, nil
+}
+
+// restorable reports whether path is one a backup may write: files below
+// the .mehrhof directory (config, .env, tasks, plugins), below the work
+// directory, and the active task reference. Everything else in the project,
+// such as sources or .git/hooks, is off limits to an archive.
+func restorable(ws *storage.Workspace, path string) bool {
+	if path == ws.ActiveTaskPath() {
+		return true
+	}
+	for _, dir := range []string{ws.TaskRoot(), ws.WorkRoot()} {
+		rel, err := filepath.Rel(dir, path)
+		if err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func decodeManifest(r io.Reader) (*Manifest, error) {
+	var manifest Manifest
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: invalid manifest: %w", ErrNotBackup, err)
+	}
+	if manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("backup format version %d is newer than supported version %d", manifest.Version, FormatVersion)
+	}
+
+	return &manifest, nil
+}
+
+// hasState reports whether the workspace already holds tasks or config.
+func hasState(ws *storage.Workspace) bool {
+	if ws.HasConfig() || ws.HasActiveTask() {
+		return true
+	}
+	tasks, err := ws.ListWorks()
+
+	return err == nil && len(tasks) > 0
+}
+
+// secretPointers blanks the values of a .env file, keeping names and comments.
+func secretPointers(data []byte) []byte {
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if key, _, ok := strings.Cut(line, "="); ok {
+			lines[i] = key + "="
+		}
+	}
+
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// encrypt seals plaintext with a passphrase-derived key and writes the archive.
+func encrypt(w io.Writer, plaintext []byte, passphrase string) error {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("generate salt: %w", err)
+	}
+
+	gcm, err := newGCM(passphrase, salt, kdfIterations)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("generate nonce: %w", err)
+	}
+
+	header := make([]byte, 0, len(magic)+4+saltSize+len(nonce))
+	header = append(header, magic...)
+	header = binary.BigEndian.AppendUint32(header, uint32(kdfIterations)) //nolint:gosec // iteration count fits in uint32
+	header = append(header, salt...)
+	header = append(header, nonce...)
+
+	if _, err := w.Write(gcm.Seal(header, nonce, plaintext, header[:len(magic)])); err != nil {
+		return fmt.Errorf("write backup: %w", err)
+	}
+
+	return nil
+}
+
+// decrypt reads and opens an archive written by encrypt.
+func decrypt(r io.Reader, passphrase string) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read backup: %w", err)
+	}
+
+	headerSize := len(magic) + 4 + saltSize
+	if len(data) < headerSize || string(data[:len(magic)]) != magic {
+		return nil, ErrNotBackup
+	}
+	iterations := int(binary.BigEndian.Uint32(data[len(magic):]))
+	if iterations < 1 || iterations > maxKDFIterations {
+		return nil, ErrNotBackup
+	}
+	salt := data[len(magic)+4 : headerSize]
+
+	gcm, err := newGCM(passphrase, salt, iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerSize+gcm.NonceSize() {
+		return nil, ErrNotBackup
+	}
+	nonce := data[headerSize : headerSize+gcm.NonceSize()]
+
+	plaintext, err := gcm.Open(nil, nonce, data[headerSize+gcm.NonceSize():], data[:len(magic)])
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+
+	return gcm, nil
+}
@@ -0,0 +1,287 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func init() {
+	// Keep key derivation fast in tests
+	kdfIterations = 1000
+}
+
+// newWorkspace creates a workspace with a config, one task, secrets and
+// runtime files that must not be backed up.
+func newWorkspace(t *testing.T) *storage.Workspace {
+	t.Helper()
+
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+	if err := ws.SaveConfig(storage.NewDefaultWorkspaceConfig()); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	if _, err := ws.CreateWork("task1", storage.SourceInfo{Type: "file", Ref: "task.md"}); err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	if err := ws.SaveSpecification("task1", 1, "# Spec\n"); err != nil {
+		t.Fatalf("SaveSpecification: %v", err)
+	}
+	if err := ws.SaveActiveTask(&storage.ActiveTask{ID: "task1"}); err != nil {
+		t.Fatalf("SaveActiveTask: %v", err)
+	}
+
+	writeFile(t, ws.EnvPath(), "# tokens\nGITHUB_TOKEN=secret\nEMPTY=\n")
+	writeFile(t, filepath.Join(ws.TaskRoot(), "locks", "task1.lease"), "owner: me\n")
+	writeFile(t, filepath.Join(ws.TaskRoot(), "config.yaml.tmp"), "partial")
+
+	return ws
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+func emptyWorkspace(t *testing.T) *storage.Workspace {
+	t.Helper()
+
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+
+	return ws
+}
+
+func TestCreateRestore_Roundtrip(t *testing.T) {
+	src := newWorkspace(t)
+
+	var buf bytes.Buffer
+	manifest, err := Create(src, &buf, "pass", Options{})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !slices.Equal(manifest.Tasks, []string{"task1"}) || manifest.ActiveTask != "task1" {
+		t.Errorf("manifest = %+v", manifest)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("GITHUB_TOKEN")) {
+		t.Error("archive is not encrypted")
+	}
+
+	dst := emptyWorkspace(t)
+	restored, err := Restore(dst, bytes.NewReader(buf.Bytes()), "pass", RestoreOptions{})
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restored.Files != manifest.Files {
+		t.Errorf("restored Files = %d, want %d", restored.Files, manifest.Files)
+	}
+
+	if !dst.HasConfig() {
+		t.Error("config not restored")
+	}
+	if content, err := dst.LoadSpecification("task1", 1); err != nil || content != "# Spec\n" {
+		t.Errorf("LoadSpecification = %q, %v", content, err)
+	}
+	active, err := dst.LoadActiveTask()
+	if err != nil || active.ID != "task1" {
+		t.Errorf("LoadActiveTask = %+v, %v", active, err)
+	}
+
+	env, err := os.ReadFile(dst.EnvPath())
+	if err != nil {
+		t.Fatalf("read .env: %v", err)
+	}
+	if string(env) != "# tokens\nGITHUB_TOKEN=\nEMPTY=\n" {
+		t.Errorf(".env = %q, want blanked pointers", env)
+	}
+
+	if _, err := os.Stat(filepath.Join(dst.TaskRoot(), "locks")); !os.IsNotExist(err) {
+		t.Error("locks directory should not be restored")
+	}
+	if _, err := os.Stat(filepath.Join(dst.TaskRoot(), "config.yaml.tmp")); !os.IsNotExist(err) {
+		t.Error("temporary files should not be restored")
+	}
+}
+
+func TestCreate_IncludeSecrets(t *testing.T) {
+	src := newWorkspace(t)
+
+	var buf bytes.Buffer
+	if _, err := Create(src, &buf, "pass", Options{IncludeSecrets: true}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	dst := emptyWorkspace(t)
+	if _, err := Restore(dst, &buf, "pass", RestoreOptions{}); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	env, err := os.ReadFile(dst.EnvPath())
+	if err != nil {
+		t.Fatalf("read .env: %v", err)
+	}
+	if !strings.Contains(string(env), "GITHUB_TOKEN=secret") {
+		t.Errorf(".env = %q, want secret values", env)
+	}
+}
+
+func TestRestore_ExistingState(t *testing.T) {
+	src := newWorkspace(t)
+
+	var buf bytes.Buffer
+	if _, err := Create(src, &buf, "pass", Options{}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	data := buf.Bytes()
+
+	dst := newWorkspace(t)
+	writeFile(t, dst.EnvPath(), "GITHUB_TOKEN=mine\n")
+
+	if _, err := Restore(dst, bytes.NewReader(data), "pass", RestoreOptions{}); !errors.Is(err, ErrWorkspaceExists) {
+		t.Fatalf("Restore without force = %v, want ErrWorkspaceExists", err)
+	}
+
+	if _, err := Restore(dst, bytes.NewReader(data), "pass", RestoreOptions{Force: true}); err != nil {
+		t.Fatalf("Restore with force: %v", err)
+	}
+
+	env, err := os.ReadFile(dst.EnvPath())
+	if err != nil {
+		t.Fatalf("read .env: %v", err)
+	}
+	if string(env) != "GITHUB_TOKEN=mine\n" {
+		t.Errorf(".env = %q, existing secrets should be kept", env)
+	}
+}
+
+func TestRestore_Errors(t *testing.T) {
+	src := newWorkspace(t)
+
+	var buf bytes.Buffer
+	if _, err := Create(src, &buf, "pass", Options{}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	data := buf.Bytes()
+
+	tampered := slices.Clone(data)
+	tampered[len(tampered)-1] ^= 0xff
+
+	tests := []struct {
+		name       string
+		data       []byte
+		passphrase string
+		want       error
+	}{
+		{name: "empty passphrase", data: data, passphrase: "", want: ErrNoPassphrase},
+		{name: "wrong passphrase", data: data, passphrase: "wrong", want: ErrDecrypt},
+		{name: "tampered", data: tampered, passphrase: "pass", want: ErrDecrypt},
+		{name: "not a backup", data: []byte("hello world, this is not a backup"), passphrase: "pass", want: ErrNotBackup},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Restore(emptyWorkspace(t), bytes.NewReader(tt.data), tt.passphrase, RestoreOptions{})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Restore() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestore_OutsideState(t *testing.T) {
+	for _, name := range []string{".git/hooks/pre-commit", "main.go", "../escape.txt"} {
+		t.Run(name, func(t *testing.T) {
+			// A crafted archive: a valid manifest and entry, then an entry
+			// aimed at the project
+			var archive bytes.Buffer
+			gz := gzip.NewWriter(&archive)
+			tw := tar.NewWriter(gz)
+			if err := writeEntry(tw, ManifestName, []byte(`{"version":1}`), 0o644, time.Now()); err != nil {
+				t.Fatalf("writeEntry: %v", err)
+			}
+			if err := writeEntry(tw, ".mehrhof/config.yaml", []byte("agent:\n  default: evil\n"), 0o644, time.Now()); err != nil {
+				t.Fatalf("writeEntry: %v", err)
+			}
+			if err := writeEntry(tw, name, []byte("#!/bin/sh\n"), 0o755, time.Now()); err != nil {
+				t.Fatalf("writeEntry: %v", err)
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatalf("close tar: %v", err)
+			}
+			if err := gz.Close(); err != nil {
+				t.Fatalf("close gzip: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := encrypt(&buf, archive.Bytes(), "pass"); err != nil {
+				t.Fatalf("encrypt: %v", err)
+			}
+
+			dst := emptyWorkspace(t)
+			if _, err := Restore(dst, &buf, "pass", RestoreOptions{}); !errors.Is(err, ErrNotBackup) {
+				t.Fatalf("Restore() error = %v, want ErrNotBackup", err)
+			}
+			if _, err := os.Stat(filepath.Join(dst.Root(), filepath.FromSlash(name))); err == nil {
+				t.Errorf("%s was written outside the mehrhof state", name)
+			}
+			if dst.HasConfig() {
+				t.Error("entries before the rejected one were written")
+			}
+		})
+	}
+}
+
+func TestCreate_EmptyPassphrase(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := Create(emptyWorkspace(t), &buf, "", Options{}); !errors.Is(err, ErrNoPassphrase) {
+		t.Errorf("Create() error = %v, want ErrNoPassphrase", err)
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	src := newWorkspace(t)
+
+	var buf bytes.Buffer
+	created, err := Create(src, &buf, "pass", Options{})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	manifest, err := ReadManifest(&buf, "pass")
+	if err != nil {
+		t.Fatalf("ReadManifest: %v", err)
+	}
+	if manifest.Version != FormatVersion || manifest.Files != created.Files || manifest.SecretsIncluded {
+		t.Errorf("manifest = %+v, want %+v", manifest, created)
+	}
+}
+
+func TestSecretPointers(t *testing.T) {
+	in := "# comment\nA=1\n\n  B = two\nexport C=x=y\n"
+	want := "# comment\nA=\n\n  B =\nexport C=\n"
+
+	if got := string(secretPointers([]byte(in))); got != want {
+		t.Errorf("secretPointers() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,85 @@
+// Package bucket provides the shared storage task work directories are
+// pushed to and pulled from: a local directory, such as a network mount, or
+// an S3-compatible object store (Amazon S3, Google Cloud Storage through its
+// XML API with HMAC keys, MinIO, Cloudflare R2).
+package bucket
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// Backend types.
+const (
+	TypeLocal = "local"
+	TypeS3    = "s3"
+	TypeGCS   = "gcs"
+)
+
+const gcsEndpoint = "https://storage.googleapis.com"
+
+// Bucket errors.
+var (
+	ErrNotConfigured = errors.New("remote storage is not configured (set storage.remote in config.yaml)")
+	ErrNoCredentials = errors.New("remote storage credentials missing (set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY)")
+)
+
+// FromSettings creates the backend configured in storage.remote. Relative
+// local paths are resolved against root. Settings may reference environment
+// variables (${VAR}); keys default to the standard AWS variables.
+func FromSettings(settings *storage.RemoteStorageSettings, root string) (storage.Backend, error) {
+	if settings == nil || settings.Type == "" {
+		return nil, ErrNotConfigured
+	}
+
+	switch settings.Type {
+	case TypeLocal:
+		path := os.ExpandEnv(settings.Path)
+		if path == "" {
+			return nil, errors.New("storage.remote.path is required for local remote storage")
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+
+		return storage.NewLocalBackend(filepath.Join(path, filepath.FromSlash(os.ExpandEnv(settings.Prefix)))), nil
+
+	case TypeS3, TypeGCS:
+		cfg := S3Config{
+			Endpoint:  os.ExpandEnv(settings.Endpoint),
+			Bucket:    os.ExpandEnv(settings.Bucket),
+			Prefix:    os.ExpandEnv(settings.Prefix),
+			Region:    os.ExpandEnv(settings.Region),
+			AccessKey: os.ExpandEnv(settings.AccessKey),
+			SecretKey: os.ExpandEnv(settings.SecretKey),
+		}
+		if cfg.AccessKey == "" && cfg.SecretKey == "" {
+			cfg.AccessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+			cfg.SecretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+			cfg.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+		}
+		if settings.Type == TypeGCS {
+			if cfg.Endpoint == "" {
+				cfg.Endpoint = gcsEndpoint
+			}
+			if cfg.Region == "" {
+				cfg.Region = "auto"
+			}
+		}
+		if cfg.Region == "" {
+			cfg.Region = os.Getenv("AWS_REGION")
+		}
+		if cfg.Region == "" {
+			cfg.Region = "us-east-1"
+		}
+
+		return NewS3(cfg)
+
+	default:
+		return nil, fmt.Errorf("unknown remote storage type %q (want %s, %s or %s)", settings.Type, TypeLocal, TypeS3, TypeGCS)
+	}
+}
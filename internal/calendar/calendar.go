@@ -0,0 +1,108 @@
+// Package calendar exports task milestones from the workspace event logs as
+// an iCalendar (RFC 5545) feed.
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Event is one calendar entry. Milestones are instants, so an event starts
+// and ends at the same time.
+type Event struct {
+	UID         string
+	Start       time.Time
+	Summary     string
+	Description string
+	URL         string
+	Categories  []string
+}
+
+// icsTime is the UTC date-time format of iCalendar.
+const icsTime = "20060102T150405Z"
+
+// maxLineOctets is the longest content line RFC 5545 allows before folding.
+const maxLineOctets = 75
+
+// Write writes events as an iCalendar feed named name.
+func Write(w io.Writer, name string, events []Event) error {
+	bw := bufio.NewWriter(w)
+	line := func(content string) {
+		writeFolded(bw, content)
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//valksor//mehrhof//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	if name != "" {
+		line("X-WR-CALNAME:" + escapeText(name))
+	}
+	for _, e := range events {
+		start := e.Start.UTC().Format(icsTime)
+		line("BEGIN:VEVENT")
+		line("UID:" + e.UID)
+		line("DTSTAMP:" + start)
+		line("DTSTART:" + start)
+		line("DTEND:" + start)
+		line("SUMMARY:" + escapeText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION:" + escapeText(e.Description))
+		}
+		if e.URL != "" {
+			line("URL:" + e.URL)
+		}
+		if len(e.Categories) > 0 {
+			escaped := make([]string, len(e.Categories))
+			for i, c := range e.Categories {
+				escaped[i] = escapeText(c)
+			}
+			line("CATEGORIES:" + strings.Join(escaped, ","))
+		}
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write calendar: %w", err)
+	}
+
+	return nil
+}
+
+// escapeText escapes a TEXT property value.
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// writeFolded writes a content line, folding it into continuation lines of
+// at most maxLineOctets octets without splitting UTF-8 sequences.
+func writeFolded(w *bufio.Writer, content string) {
+	limit := maxLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(content[cut]) {
+			cut--
+		}
+		_, _ = w.WriteString(content[:cut] + "\r\n ")
+		content = content[cut:]
+		limit = maxLineOctets - 1 // Continuation lines start with a space
+	}
+	_, _ = w.WriteString(content + "\r\n")
+}
+
+// isRuneStart reports whether b starts a UTF-8 sequence.
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
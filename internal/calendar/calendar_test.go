@@ -0,0 +1,143 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestWrite(t *testing.T) {
+	var sb strings.Builder
+	err := Write(&sb, "mehrhof: api", []Event{{
+		UID:         "t1-pr_created-1@mehrhof",
+		Start:       time.Date(2026, 3, 4, 15, 30, 0, 0, time.FixedZone("CET", 3600)),
+		Summary:     "PR #12 opened: Add caching, eviction; metrics",
+		Description: "Task: t1\nBranch: feature/cache",
+		URL:         "https://github.com/acme/api/pull/12",
+		Categories:  []string{"mehrhof", "pr_created"},
+	}})
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got := sb.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"X-WR-CALNAME:mehrhof: api\r\n",
+		"DTSTART:20260304T143000Z\r\n",
+		`SUMMARY:PR #12 opened: Add caching\, eviction\; metrics`,
+		`DESCRIPTION:Task: t1\nBranch: feature/cache`,
+		"CATEGORIES:mehrhof,pr_created\r\n",
+		"END:VEVENT\r\nEND:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("calendar missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestWrite_FoldsLongLines(t *testing.T) {
+	var sb strings.Builder
+	summary := strings.Repeat("Übersicht ", 20)
+	if err := Write(&sb, "", []Event{{UID: "u", Summary: summary}}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	var unfolded strings.Builder
+	for i, line := range strings.Split(strings.TrimSuffix(sb.String(), "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line %d has %d octets", i, len(line))
+		}
+		if strings.HasPrefix(line, " ") {
+			unfolded.WriteString(line[1:])
+		} else {
+			unfolded.WriteString("\n" + line)
+		}
+	}
+	if !strings.Contains(unfolded.String(), "SUMMARY:"+summary) {
+		t.Errorf("unfolded summary does not round-trip:\n%s", unfolded.String())
+	}
+}
+
+func TestMilestones(t *testing.T) {
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	// A task with a full event log
+	work, err := ws.CreateWork("t1", storage.SourceInfo{Type: "github", Ref: "github:12"})
+	if err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	work.Metadata.Title = "Add caching"
+	work.Metadata.ExternalKey = "#12"
+	work.Costs.TotalCostUSD = 1.5
+	if err := ws.SaveWork(work); err != nil {
+		t.Fatalf("SaveWork: %v", err)
+	}
+	logged := []events.Eventer{
+		events.TaskStartedEvent{Timestamp: start, TaskID: "t1"},
+		events.PlanCompletedEvent{Timestamp: start.Add(time.Hour), TaskID: "t1", SpecificationID: 1},
+		events.AgentMessageEvent{Timestamp: start.Add(90 * time.Minute), TaskID: "t1", Content: "not a milestone"},
+		events.PRCreatedEvent{Timestamp: start.Add(2 * time.Hour), TaskID: "t1", PRNumber: 13, PRURL: "https://github.com/acme/api/pull/13"},
+		events.TaskFinishedEvent{Timestamp: start.Add(3 * time.Hour), TaskID: "t1"},
+	}
+	for _, e := range logged {
+		ev := e.ToEvent()
+		if err := ws.AppendEvent("t1", storage.EventRecord{Timestamp: ev.Timestamp, Type: string(ev.Type), Data: ev.Data}); err != nil {
+			t.Fatalf("AppendEvent: %v", err)
+		}
+	}
+
+	// A task from before the event log, dated by its metadata
+	old, err := ws.CreateWork("t0", storage.SourceInfo{Type: "file", Ref: "task.md"})
+	if err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	old.Metadata.CreatedAt = start.Add(-24 * time.Hour)
+	if err := ws.SaveWork(old); err != nil {
+		t.Fatalf("SaveWork: %v", err)
+	}
+
+	got, err := Milestones(ws, time.Time{})
+	if err != nil {
+		t.Fatalf("Milestones: %v", err)
+	}
+	var summaries []string
+	for _, e := range got {
+		summaries = append(summaries, e.Summary)
+	}
+	want := []string{
+		"Started: t0",
+		"Started: #12 Add caching",
+		"Planned: #12 Add caching",
+		"PR #13 opened: #12 Add caching",
+		"Finished: #12 Add caching",
+	}
+	if strings.Join(summaries, "|") != strings.Join(want, "|") {
+		t.Fatalf("summaries = %q, want %q", summaries, want)
+	}
+	if got[3].URL != "https://github.com/acme/api/pull/13" {
+		t.Errorf("PR URL = %q", got[3].URL)
+	}
+	if !strings.Contains(got[2].Description, "Specification: 1") || !strings.Contains(got[4].Description, "Agent cost: $1.50") {
+		t.Errorf("descriptions = %q, %q", got[2].Description, got[4].Description)
+	}
+
+	recent, err := Milestones(ws, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Milestones: %v", err)
+	}
+	if len(recent) != 3 {
+		t.Errorf("Milestones since planning = %d events, want 3", len(recent))
+	}
+}
@@ -0,0 +1,132 @@
+package calendar
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// milestoneLabels name the calendar entry for each logged event type that
+// marks a task milestone.
+var milestoneLabels = map[string]string{
+	string(events.TypeTaskStarted):   "Started",
+	string(events.TypePlanCompleted): "Planned",
+	string(events.TypeImplementDone): "Implemented",
+	string(events.TypePRCreated):     "PR opened",
+	string(events.TypeTaskFinished):  "Finished",
+}
+
+// Milestones returns a calendar event for every milestone of every task in
+// the workspace at or after since (zero = all), oldest first. Tasks started
+// before the event log existed get their start from the work metadata.
+func Milestones(ws *storage.Workspace, since time.Time) ([]Event, error) {
+	taskIDs, err := ws.ListWorks()
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+
+	types := make([]string, 0, len(milestoneLabels))
+	for t := range milestoneLabels {
+		types = append(types, t)
+	}
+
+	var out []Event
+	for _, taskID := range taskIDs {
+		work, err := ws.LoadWork(taskID)
+		if err != nil {
+			continue
+		}
+		records, err := ws.ReadEvents(taskID, storage.EventFilter{Types: types})
+		if err != nil {
+			return nil, fmt.Errorf("read events for %s: %w", taskID, err)
+		}
+
+		started := slices.ContainsFunc(records, func(r storage.EventRecord) bool {
+			return r.Type == string(events.TypeTaskStarted)
+		})
+		if !started && !work.Metadata.CreatedAt.IsZero() {
+			records = append(records, storage.EventRecord{
+				Timestamp: work.Metadata.CreatedAt,
+				Type:      string(events.TypeTaskStarted),
+			})
+		}
+
+		for _, record := range records {
+			if record.Timestamp.Before(since) {
+				continue
+			}
+			out = append(out, milestoneEvent(work, record))
+		}
+	}
+
+	slices.SortStableFunc(out, func(a, b Event) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	return out, nil
+}
+
+// milestoneEvent builds the calendar event for one logged milestone.
+func milestoneEvent(work *storage.TaskWork, record storage.EventRecord) Event {
+	taskID := work.Metadata.ID
+	title := cmp.Or(work.Metadata.Title, taskID)
+	if work.Metadata.ExternalKey != "" {
+		title = work.Metadata.ExternalKey + " " + title
+	}
+
+	details := []string{"Task: " + taskID}
+	if work.Source.Ref != "" {
+		details = append(details, "Source: "+work.Source.Ref)
+	}
+	if work.Git.Branch != "" {
+		details = append(details, "Branch: "+work.Git.Branch)
+	}
+
+	e := Event{
+		UID:        fmt.Sprintf("%s-%s-%d@mehrhof", taskID, record.Type, record.Timestamp.UnixNano()),
+		Start:      record.Timestamp,
+		Summary:    milestoneLabels[record.Type] + ": " + title,
+		Categories: []string{"mehrhof", record.Type},
+	}
+	switch events.Type(record.Type) {
+	case events.TypePlanCompleted:
+		if spec := number(record.Data["specification_id"]); spec > 0 {
+			details = append(details, fmt.Sprintf("Specification: %d", spec))
+		}
+	case events.TypeImplementDone:
+		if stat, _ := record.Data["diff_stat"].(string); stat != "" {
+			details = append(details, "Changes: "+stat)
+		}
+	case events.TypePRCreated:
+		e.URL, _ = record.Data["pr_url"].(string)
+		if pr := number(record.Data["pr_number"]); pr > 0 {
+			e.Summary = fmt.Sprintf("PR #%d opened: %s", pr, title)
+		}
+	case events.TypeTaskFinished:
+		if cost := work.Costs.TotalCostUSD; cost > 0 {
+			details = append(details, fmt.Sprintf("Agent cost: $%.2f", cost))
+		}
+	default:
+	}
+	e.Description = strings.Join(details, "\n")
+
+	return e
+}
+
+// number reads an integer from event data, which holds float64 once the
+// event log has been read back from JSON.
+func number(v any) int {
+	switch v := v.(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,211 @@
+// Package changelog writes changelog entries for finished tasks, either into
+// the Unreleased section of a Keep a Changelog file or as towncrier news
+// fragments.
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Changelog formats.
+const (
+	FormatKeepAChangelog = "keepachangelog"
+	FormatTowncrier      = "towncrier"
+)
+
+// ErrInvalidEntry is returned when an entry has no known category or no text.
+var ErrInvalidEntry = errors.New("invalid changelog entry")
+
+// Categories are the Keep a Changelog change types, in the order their
+// sections are listed.
+var Categories = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+// towncrierTypes maps categories to towncrier's default fragment types.
+var towncrierTypes = map[string]string{
+	"Added":      "feature",
+	"Changed":    "feature",
+	"Deprecated": "removal",
+	"Removed":    "removal",
+	"Fixed":      "bugfix",
+	"Security":   "bugfix",
+}
+
+const keepAChangelogHeader = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/).
+`
+
+// Entry is a single changelog entry.
+type Entry struct {
+	Category string // One of Categories
+	Text     string // One line describing the change for users
+}
+
+// ValidFormat reports whether format is a known changelog format.
+func ValidFormat(format string) bool {
+	return format == FormatKeepAChangelog || format == FormatTowncrier
+}
+
+// DefaultPath returns where entries of format are written when no path is
+// configured: a file for Keep a Changelog, a directory for towncrier.
+func DefaultPath(format string) string {
+	if format == FormatTowncrier {
+		return "changelog.d"
+	}
+
+	return "CHANGELOG.md"
+}
+
+// ParseEntry parses an entry of the form "Category: text" from the first
+// non-empty line of text, as written by an agent. List markers, quotes and
+// case differences in the category are tolerated.
+func ParseEntry(text string) (Entry, error) {
+	for line := range strings.Lines(text) {
+		line = strings.TrimSpace(line)
+		line = strings.TrimSpace(strings.TrimLeft(line, "-*`"))
+		if line == "" {
+			continue
+		}
+
+		category, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			return Entry{}, fmt.Errorf("%w: missing category in %q", ErrInvalidEntry, line)
+		}
+		category = strings.Trim(strings.TrimSpace(category), "*#[] ")
+		idx := slices.IndexFunc(Categories, func(c string) bool { return strings.EqualFold(c, category) })
+		if idx < 0 {
+			return Entry{}, fmt.Errorf("%w: unknown category %q", ErrInvalidEntry, category)
+		}
+		rest = strings.Trim(strings.TrimSpace(rest), "`\"")
+		if rest == "" {
+			return Entry{}, fmt.Errorf("%w: empty text", ErrInvalidEntry)
+		}
+
+		return Entry{Category: Categories[idx], Text: rest}, nil
+	}
+
+	return Entry{}, fmt.Errorf("%w: empty", ErrInvalidEntry)
+}
+
+// Write writes e in format to path below root, naming towncrier fragments
+// after name. It returns the path of the file written, relative to root.
+func Write(root, format, path, name string, e Entry) (string, error) {
+	if path == "" {
+		path = DefaultPath(format)
+	}
+
+	switch format {
+	case FormatTowncrier:
+		typ := towncrierTypes[e.Category]
+		rel := filepath.Join(path, fmt.Sprintf("%s.%s.md", name, typ))
+		full := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			return "", fmt.Errorf("create fragment directory: %w", err)
+		}
+		if err := os.WriteFile(full, []byte(e.Text+"\n"), 0o644); err != nil {
+			return "", fmt.Errorf("write fragment: %w", err)
+		}
+
+		return rel, nil
+	case FormatKeepAChangelog:
+		full := filepath.Join(root, path)
+		data, err := os.ReadFile(full)
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("read changelog: %w", err)
+		}
+		content := string(data)
+		if strings.TrimSpace(content) == "" {
+			content = keepAChangelogHeader
+		}
+		if err := os.WriteFile(full, []byte(InsertUnreleased(content, e)), 0o644); err != nil {
+			return "", fmt.Errorf("write changelog: %w", err)
+		}
+
+		return path, nil
+	default:
+		return "", fmt.Errorf("unknown changelog format %q", format)
+	}
+}
+
+// InsertUnreleased adds e to the Unreleased section of a Keep a Changelog
+// document, creating the section and its category heading when missing.
+// Categories keep their conventional order.
+func InsertUnreleased(content string, e Entry) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	bullet := "- " + e.Text
+
+	start := slices.IndexFunc(lines, isUnreleasedHeading)
+	if start < 0 {
+		// New section above the latest release, or at the end
+		at := slices.IndexFunc(lines, isReleaseHeading)
+		section := []string{"## [Unreleased]", "", "### " + e.Category, bullet, ""}
+		if at < 0 {
+			return strings.Join(append(lines, append([]string{""}, section[:len(section)-1]...)...), "\n") + "\n"
+		}
+
+		return strings.Join(slices.Insert(lines, at, section...), "\n") + "\n"
+	}
+
+	end := len(lines)
+	if next := slices.IndexFunc(lines[start+1:], isReleaseHeading); next >= 0 {
+		end = start + 1 + next
+	}
+
+	// Append to an existing category, after its last bullet
+	for i := start + 1; i < end; i++ {
+		if category, ok := strings.CutPrefix(lines[i], "### "); ok && strings.EqualFold(strings.TrimSpace(category), e.Category) {
+			at := i + 1
+			for at < end && strings.TrimSpace(lines[at]) != "" && !strings.HasPrefix(lines[at], "#") {
+				at++
+			}
+
+			return strings.Join(slices.Insert(lines, at, bullet), "\n") + "\n"
+		}
+	}
+
+	// New category before the first one listed after it
+	rank := slices.Index(Categories, e.Category)
+	at := end
+	for i := start + 1; i < end; i++ {
+		category, ok := strings.CutPrefix(lines[i], "### ")
+		if ok && slices.Index(Categories, strings.TrimSpace(category)) > rank {
+			at = i
+
+			break
+		}
+	}
+	// Keep the blank line separating the section from the next release
+	if at == end {
+		for at > start+1 && strings.TrimSpace(lines[at-1]) == "" {
+			at--
+		}
+	}
+	block := []string{"### " + e.Category, bullet}
+	if strings.TrimSpace(lines[at-1]) != "" {
+		block = slices.Insert(block, 0, "")
+	}
+	if at < len(lines) && strings.TrimSpace(lines[at]) != "" {
+		block = append(block, "")
+	}
+
+	return strings.Join(slices.Insert(lines, at, block...), "\n") + "\n"
+}
+
+// isReleaseHeading reports whether line starts a release section.
+func isReleaseHeading(line string) bool {
+	return strings.HasPrefix(line, "## ")
+}
+
+// isUnreleasedHeading reports whether line starts the Unreleased section.
+func isUnreleasedHeading(line string) bool {
+	heading, ok := strings.CutPrefix(line, "## ")
+
+	return ok && strings.EqualFold(strings.Trim(strings.TrimSpace(heading), "[]"), "unreleased")
+}
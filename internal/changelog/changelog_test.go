@@ -0,0 +1,125 @@
+package changelog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseEntry(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    Entry
+		wantErr bool
+	}{
+		{name: "plain", text: "Added: Login with SSO", want: Entry{Category: "Added", Text: "Login with SSO"}},
+		{name: "bullet and case", text: "\n- **fixed**: Crash on empty config\nmore", want: Entry{Category: "Fixed", Text: "Crash on empty config"}},
+		{name: "unknown category", text: "Improved: Faster startup", wantErr: true},
+		{name: "no category", text: "Faster startup", wantErr: true},
+		{name: "empty text", text: "Changed:", wantErr: true},
+		{name: "empty", text: "\n\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEntry(tt.text)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidEntry) {
+					t.Fatalf("ParseEntry() error = %v, want ErrInvalidEntry", err)
+				}
+
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseEntry() = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsertUnreleased(t *testing.T) {
+	const released = "## [1.0.0] - 2024-01-01\n\n### Added\n- First release\n"
+
+	tests := []struct {
+		name    string
+		content string
+		entry   Entry
+		want    string
+	}{
+		{
+			name:    "no sections",
+			content: "# Changelog\n",
+			entry:   Entry{Category: "Added", Text: "X"},
+			want:    "# Changelog\n\n## [Unreleased]\n\n### Added\n- X\n",
+		},
+		{
+			name:    "unreleased section created above the latest release",
+			content: "# Changelog\n\n" + released,
+			entry:   Entry{Category: "Fixed", Text: "X"},
+			want:    "# Changelog\n\n## [Unreleased]\n\n### Fixed\n- X\n\n" + released,
+		},
+		{
+			name:    "existing category",
+			content: "## [Unreleased]\n\n### Fixed\n- A\n\n" + released,
+			entry:   Entry{Category: "Fixed", Text: "X"},
+			want:    "## [Unreleased]\n\n### Fixed\n- A\n- X\n\n" + released,
+		},
+		{
+			name:    "category before a later one",
+			content: "## [Unreleased]\n\n### Fixed\n- A\n\n" + released,
+			entry:   Entry{Category: "Added", Text: "X"},
+			want:    "## [Unreleased]\n\n### Added\n- X\n\n### Fixed\n- A\n\n" + released,
+		},
+		{
+			name:    "category at the end of the section",
+			content: "## [Unreleased]\n\n### Fixed\n- A\n\n" + released,
+			entry:   Entry{Category: "Security", Text: "X"},
+			want:    "## [Unreleased]\n\n### Fixed\n- A\n\n### Security\n- X\n\n" + released,
+		},
+		{
+			name:    "empty unreleased section",
+			content: "## Unreleased\n\n" + released,
+			entry:   Entry{Category: "Removed", Text: "X"},
+			want:    "## Unreleased\n\n### Removed\n- X\n\n" + released,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InsertUnreleased(tt.content, tt.entry); got != tt.want {
+				t.Errorf("InsertUnreleased() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	root := t.TempDir()
+	entry := Entry{Category: "Fixed", Text: "Crash on empty config"}
+
+	rel, err := Write(root, FormatTowncrier, "", "PROJ-12", entry)
+	if err != nil {
+		t.Fatalf("Write towncrier: %v", err)
+	}
+	if want := filepath.Join("changelog.d", "PROJ-12.bugfix.md"); rel != want {
+		t.Errorf("towncrier fragment = %q, want %q", rel, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, rel)); string(data) != entry.Text+"\n" {
+		t.Errorf("fragment content = %q", data)
+	}
+
+	rel, err = Write(root, FormatKeepAChangelog, "", "PROJ-12", entry)
+	if err != nil {
+		t.Fatalf("Write keepachangelog: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(root, rel))
+	if want := keepAChangelogHeader + "\n## [Unreleased]\n\n### Fixed\n- Crash on empty config\n"; rel != "CHANGELOG.md" || string(data) != want {
+		t.Errorf("%s =\n%s\nwant\n%s", rel, data, want)
+	}
+
+	if _, err := Write(root, "rst", "", "PROJ-12", entry); err == nil {
+		t.Error("Write with unknown format should fail")
+	}
+}
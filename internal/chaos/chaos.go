@@ -0,0 +1,134 @@
+// Package chaos injects deterministic failures into named subsystems so that
+// recovery paths (retries, resumption, cleanup) can be exercised in
+// integration tests and demos.
+//
+// Injection is configured from a spec such as "provider.fetch,agent.run:2"
+// and only when the MEHR_CHAOS environment variable is set, so it cannot be
+// enabled by accident.
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// EnvGuard must be set to a non-empty value for injection to be allowed.
+const EnvGuard = "MEHR_CHAOS"
+
+// Injection points.
+const (
+	ProviderFetch = "provider.fetch" // Reading a task from its provider
+	AgentRun      = "agent.run"      // Every agent call
+	GitCheckpoint = "git.checkpoint" // Creating a checkpoint commit
+	PRCreate      = "pr.create"      // Opening a pull request
+)
+
+// Points lists the injection points.
+var Points = []string{ProviderFetch, AgentRun, GitCheckpoint, PRCreate}
+
+// ErrInjected is wrapped by every injected failure.
+var ErrInjected = errors.New("injected failure")
+
+// rule fails a point on one call, or on every call when call is 0.
+type rule struct {
+	point string
+	call  int
+}
+
+var (
+	mu    sync.Mutex
+	rules []rule
+	calls map[string]int
+)
+
+// Configure enables injection from spec: comma-separated points, each
+// optionally followed by ":n" to fail only the nth call (counting from 1)
+// instead of every call. An empty spec disables injection.
+func Configure(spec string) error {
+	parsed, err := parse(spec)
+	if err != nil {
+		return err
+	}
+	if len(parsed) > 0 && os.Getenv(EnvGuard) == "" {
+		return fmt.Errorf("failure injection requires %s=1", EnvGuard)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	rules = parsed
+	calls = make(map[string]int)
+
+	return nil
+}
+
+// parse parses an injection spec.
+func parse(spec string) ([]rule, error) {
+	var parsed []rule
+	for entry := range strings.SplitSeq(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		point, n, hasCall := strings.Cut(entry, ":")
+		if !slices.Contains(Points, point) {
+			return nil, fmt.Errorf("unknown injection point %q (known: %s)", point, strings.Join(Points, ", "))
+		}
+
+		r := rule{point: point}
+		if hasCall {
+			call, err := strconv.Atoi(n)
+			if err != nil || call < 1 {
+				return nil, fmt.Errorf("invalid call number in %q: must be a positive integer", entry)
+			}
+			r.call = call
+		}
+		parsed = append(parsed, r)
+	}
+
+	return parsed, nil
+}
+
+// Enabled reports whether any failure is configured.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return len(rules) > 0
+}
+
+// Check counts a call to point and returns an injected error if a rule
+// matches it.
+func Check(point string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	calls[point]++
+	call := calls[point]
+	for _, r := range rules {
+		if r.point == point && (r.call == 0 || r.call == call) {
+			return fmt.Errorf("%w: %s (call %d)", ErrInjected, point, call)
+		}
+	}
+
+	return nil
+}
+
+// Reset disables injection and clears call counts.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	rules = nil
+	calls = nil
+}
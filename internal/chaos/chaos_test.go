@@ -0,0 +1,74 @@
+package chaos
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	rules, err := parse("provider.fetch, agent.run:2,")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := []rule{{point: ProviderFetch}, {point: AgentRun, call: 2}}
+	if len(rules) != len(want) {
+		t.Fatalf("rules = %+v, want %+v", rules, want)
+	}
+	for i := range want {
+		if rules[i] != want[i] {
+			t.Errorf("rules[%d] = %+v, want %+v", i, rules[i], want[i])
+		}
+	}
+
+	for _, spec := range []string{"disk.full", "agent.run:0", "agent.run:x"} {
+		if _, err := parse(spec); err == nil {
+			t.Errorf("parse(%q) should fail", spec)
+		}
+	}
+}
+
+func TestConfigure_RequiresEnvGuard(t *testing.T) {
+	t.Cleanup(Reset)
+	t.Setenv(EnvGuard, "")
+
+	if err := Configure("agent.run"); err == nil {
+		t.Fatal("Configure should fail without " + EnvGuard)
+	}
+	if Enabled() {
+		t.Error("injection enabled without " + EnvGuard)
+	}
+	if err := Configure(""); err != nil {
+		t.Errorf("Configure(\"\") = %v, want nil", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	t.Cleanup(Reset)
+	t.Setenv(EnvGuard, "1")
+
+	if err := Configure("provider.fetch,agent.run:2"); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+
+	for call := 1; call <= 3; call++ {
+		if err := Check(ProviderFetch); !errors.Is(err, ErrInjected) {
+			t.Errorf("provider.fetch call %d = %v, want injected failure", call, err)
+		}
+	}
+
+	for call, wantFail := range []bool{false, true, false} {
+		err := Check(AgentRun)
+		if (err != nil) != wantFail {
+			t.Errorf("agent.run call %d = %v, want failure %v", call+1, err, wantFail)
+		}
+	}
+
+	if err := Check(PRCreate); err != nil {
+		t.Errorf("pr.create = %v, want nil", err)
+	}
+
+	Reset()
+	if err := Check(ProviderFetch); err != nil {
+		t.Errorf("after Reset = %v, want nil", err)
+	}
+}
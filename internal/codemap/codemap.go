@@ -0,0 +1,262 @@
+// Package codemap builds a lightweight map of a repository: its source files,
+// their packages and top-level symbols, and which test files cover them.
+//
+// The map grounds agents in the code that exists before they change it. Go
+// files are parsed with the standard library and list their exported
+// declarations; other languages supported by diffsummary list the
+// declarations found by its patterns. Maps are cached as JSON, and only
+// files whose size or modification time changed are parsed again.
+package codemap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/diffsummary"
+)
+
+// cacheVersion changes whenever the cached format or its content changes.
+const cacheVersion = 1
+
+// Limits on what is mapped.
+const (
+	maxFileBytes = 256 * 1024
+	maxFiles     = 20_000
+)
+
+// skippedDirs are directories that never hold the repository's own code.
+var skippedDirs = []string{"node_modules", "vendor", "testdata", "dist", "build", "target", "__pycache__"}
+
+// File is a mapped source file.
+type File struct {
+	Path     string   `json:"path"`              // Relative to the repository root, slash-separated
+	Language string   `json:"language"`          // e.g. "Go"
+	Package  string   `json:"package,omitempty"` // Go package name
+	Symbols  []string `json:"symbols,omitempty"` // e.g. "func New", "type Config"
+	Test     bool     `json:"test,omitempty"`    // A test file
+	Tests    []string `json:"tests,omitempty"`   // Test files covering this file
+
+	Size    int64 `json:"size"`
+	ModTime int64 `json:"mod_time"` // Unix nanoseconds
+}
+
+// Map is the code map of a repository directory.
+type Map struct {
+	Version int    `json:"version"`
+	Dir     string `json:"dir,omitempty"` // Mapped subdirectory ("" for the whole repository)
+	Files   []File `json:"files"`
+}
+
+// Options configures Build.
+type Options struct {
+	Dir       string // Subdirectory to map, relative to the root ("" for all)
+	CachePath string // File the map is cached in ("" disables caching)
+}
+
+// Build maps the source files under root, reusing the cached map for files
+// that have not changed since it was written.
+func Build(root string, opts Options) (*Map, error) {
+	cached := make(map[string]File)
+	if prev, err := load(opts.CachePath); err == nil && prev.Dir == opts.Dir {
+		for _, f := range prev.Files {
+			cached[f.Path] = f
+		}
+	}
+
+	m := &Map{Version: cacheVersion, Dir: opts.Dir}
+	start := filepath.Join(root, filepath.FromSlash(opts.Dir))
+	err := filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == start {
+				return err
+			}
+
+			return nil // Unreadable entries are left out
+		}
+		if d.IsDir() {
+			if p != start && (strings.HasPrefix(d.Name(), ".") || slices.Contains(skippedDirs, d.Name())) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+		if len(m.Files) >= maxFiles || diffsummary.Language(p) == "" {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Size() > maxFileBytes {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if f, ok := cached[rel]; ok && f.Size == info.Size() && f.ModTime == info.ModTime().UnixNano() {
+			f.Tests = nil
+			m.Files = append(m.Files, f)
+
+			return nil
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return nil
+		}
+		f := mapFile(rel, string(content))
+		f.Size, f.ModTime = info.Size(), info.ModTime().UnixNano()
+		m.Files = append(m.Files, f)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("map %s: %w", start, err)
+	}
+
+	linkTests(m.Files)
+	if opts.CachePath != "" {
+		if err := save(opts.CachePath, m); err != nil {
+			return m, err
+		}
+	}
+
+	return m, nil
+}
+
+// mapFile maps one source file.
+func mapFile(rel, content string) File {
+	f := File{Path: rel, Language: diffsummary.Language(rel), Test: isTestFile(rel)}
+
+	if f.Language == "Go" {
+		if file, err := parser.ParseFile(token.NewFileSet(), rel, content, parser.PackageClauseOnly); err == nil {
+			f.Package = file.Name.Name
+		}
+	}
+	decls, ok := diffsummary.Declarations(rel, content)
+	if !ok {
+		return f
+	}
+	for _, d := range decls {
+		// Go files list their API; unexported names would crowd it out
+		if f.Language == "Go" && !d.Exported {
+			continue
+		}
+		f.Symbols = append(f.Symbols, d.Name)
+	}
+
+	return f
+}
+
+// isTestFile reports whether a path follows a common test file naming.
+func isTestFile(p string) bool {
+	base := path.Base(p)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	if path.Ext(base) == ".go" {
+		return strings.HasSuffix(stem, "_test")
+	}
+
+	switch {
+	case strings.HasSuffix(stem, "_test"), strings.HasPrefix(stem, "test_"),
+		strings.HasSuffix(stem, ".test"), strings.HasSuffix(stem, ".spec"),
+		strings.HasSuffix(stem, "Test") && path.Ext(base) == ".php":
+		return true
+	}
+
+	return slices.ContainsFunc(strings.Split(path.Dir(p), "/"), func(dir string) bool {
+		return dir == "tests" || dir == "__tests__"
+	})
+}
+
+// testSubject returns the key linking a file and its tests: the directory
+// and stem for Go, where tests sit next to the code, and the stem alone for
+// other languages, whose tests often live in a separate tree.
+func testSubject(f File) string {
+	base := path.Base(f.Path)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	for _, marker := range []string{"_test", ".test", ".spec", "Test"} {
+		stem = strings.TrimSuffix(stem, marker)
+	}
+	stem = strings.TrimPrefix(stem, "test_")
+
+	if f.Language == "Go" {
+		return path.Dir(f.Path) + "/" + stem
+	}
+
+	return f.Language + ":" + stem
+}
+
+// linkTests records on each file the test files covering it.
+func linkTests(files []File) {
+	tests := make(map[string][]string)
+	for _, f := range files {
+		if f.Test {
+			key := testSubject(f)
+			tests[key] = append(tests[key], f.Path)
+		}
+	}
+	for i := range files {
+		if !files[i].Test {
+			files[i].Tests = tests[testSubject(files[i])]
+		}
+	}
+}
+
+// load reads a cached map; an outdated cache counts as missing.
+func load(cachePath string) (*Map, error) {
+	if cachePath == "" {
+		return nil, fs.ErrNotExist
+	}
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, err
+	}
+	var m Map
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if m.Version != cacheVersion {
+		return nil, errors.New("outdated code map cache")
+	}
+
+	return &m, nil
+}
+
+// save writes the map to the cache.
+func save(cachePath string, m *Map) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("encode code map: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0o755); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+	// Concurrent runs each write their own file; the last rename wins
+	tmp, err := os.CreateTemp(filepath.Dir(cachePath), filepath.Base(cachePath)+".*")
+	if err != nil {
+		return fmt.Errorf("write code map: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), cachePath)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("write code map: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package codemap
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+var testRepo = map[string]string{
+	"internal/session/store.go":       "package session\n\ntype Store struct{}\n\nfunc NewStore() *Store { return nil }\n\nfunc (s *Store) Expire() {}\n\nfunc helper() {}\n",
+	"internal/session/store_test.go":  "package session\n\nfunc TestStore(t *testing.T) {}\n",
+	"internal/login/handler.go":       "package login\n\nfunc Handle() {}\n",
+	"web/src/login.ts":                "export function renderLogin() {}\n",
+	"web/src/__tests__/login.test.ts": "test('renders', () => {})\n",
+	"node_modules/dep/index.js":       "export function dep() {}\n",
+	".mehrhof/work/x.go":              "package x\n",
+	"README.md":                       "# Repo\n",
+}
+
+func TestBuild(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, testRepo)
+
+	m, err := Build(root, Options{})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	paths := make([]string, len(m.Files))
+	for i, f := range m.Files {
+		paths[i] = f.Path
+	}
+	want := []string{"internal/login/handler.go", "internal/session/store.go", "internal/session/store_test.go", "web/src/__tests__/login.test.ts", "web/src/login.ts"}
+	if !slices.Equal(paths, want) {
+		t.Fatalf("paths = %v, want %v", paths, want)
+	}
+
+	store := m.Files[1]
+	if store.Package != "session" || !slices.Equal(store.Symbols, []string{"func (*Store) Expire", "func NewStore", "type Store"}) {
+		t.Errorf("store.go = %+v", store)
+	}
+	if !slices.Equal(store.Tests, []string{"internal/session/store_test.go"}) || !m.Files[2].Test {
+		t.Errorf("store.go tests = %v, store_test.go test = %v", store.Tests, m.Files[2].Test)
+	}
+	if login := m.Files[4]; !slices.Equal(login.Tests, []string{"web/src/__tests__/login.test.ts"}) || !slices.Equal(login.Symbols, []string{"function renderLogin"}) {
+		t.Errorf("login.ts = %+v", login)
+	}
+
+	scoped, err := Build(root, Options{Dir: "web"})
+	if err != nil {
+		t.Fatalf("Build(web): %v", err)
+	}
+	if len(scoped.Files) != 2 || scoped.Files[1].Path != "web/src/login.ts" {
+		t.Errorf("scoped files = %+v", scoped.Files)
+	}
+}
+
+func TestBuild_Cache(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, testRepo)
+	cachePath := filepath.Join(root, ".mehrhof", "cache", "codemap.json")
+
+	if _, err := Build(root, Options{CachePath: cachePath}); err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if _, err := os.Stat(cachePath); err != nil {
+		t.Fatalf("cache not written: %v", err)
+	}
+
+	// Cached entries are reused while size and modification time match
+	handler := filepath.Join(root, "internal", "login", "handler.go")
+	info, err := os.Stat(handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(handler, []byte("package login\n\nfunc Serves() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(handler, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	m, err := Build(root, Options{CachePath: cachePath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Files[0].Symbols; !slices.Equal(got, []string{"func Handle"}) {
+		t.Errorf("unchanged stat: symbols = %v, want cached", got)
+	}
+
+	later := info.ModTime().Add(time.Second)
+	if err := os.Chtimes(handler, later, later); err != nil {
+		t.Fatal(err)
+	}
+	m, err = Build(root, Options{CachePath: cachePath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Files[0].Symbols; !slices.Equal(got, []string{"func Serves"}) {
+		t.Errorf("changed file: symbols = %v, want reparsed", got)
+	}
+}
+
+func TestWords(t *testing.T) {
+	got := words("Add parseHTTPRequest to the session_store for users")
+	want := []string{"parse", "http", "request", "session", "store", "users"}
+	if !slices.Equal(got, want) {
+		t.Errorf("words() = %v, want %v", got, want)
+	}
+}
+
+func TestRelevant(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, testRepo)
+	m, err := Build(root, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := "## Overview\nExpire sessions in the Store after a day, see s.Expire.\n\n## Files to Modify\n- internal/login/handler.go\n"
+	got := m.Relevant(spec, 5)
+	if len(got) != 2 || got[0].Path != "internal/login/handler.go" || got[1].Path != "internal/session/store.go" {
+		t.Errorf("Relevant() = %+v, want the named file, then the session store", got)
+	}
+	if got := m.Relevant(spec, 1); len(got) != 1 {
+		t.Errorf("Relevant(limit 1) returned %d files", len(got))
+	}
+	if got := m.Relevant("Unrelated words only", 5); len(got) != 0 {
+		t.Errorf("Relevant(unrelated) = %+v, want none", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	files := []File{
+		{Path: "internal/session/store.go", Package: "session", Symbols: []string{"func NewStore", "type Store"}, Tests: []string{"internal/session/store_test.go"}},
+		{Path: "web/src/login.ts", Tests: []string{"web/src/__tests__/login.test.ts"}},
+	}
+	got := Render(files)
+	want := "internal/session/store.go (package session; tests: store_test.go)\n  func NewStore; type Store\n" +
+		"web/src/login.ts (tests: web/src/__tests__/login.test.ts)\n"
+	if got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestTestLayout(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, testRepo)
+	m, err := Build(root, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := m.TestLayout()
+	if !strings.Contains(got, "*_test.go") || !strings.Contains(got, "__tests__") {
+		t.Errorf("TestLayout() = %q", got)
+	}
+	if got := (&Map{}).TestLayout(); got != "" {
+		t.Errorf("TestLayout(no tests) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,194 @@
+package codemap
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Scores of a keyword match; a file named outright beats everything else.
+const (
+	scoreNamed  = 100
+	scorePath   = 3
+	scoreSymbol = 1
+)
+
+// maxSymbolsShown caps the symbols listed per file when rendering.
+const maxSymbolsShown = 25
+
+var (
+	identPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+	// Paths, or bare names of mapped source files; "cfg.Load" is no file
+	pathPattern = regexp.MustCompile(`[\w.\-]+(?:/[\w.\-]+)+|[\w\-]+\.(?:go|py|jsx?|mjs|tsx?|php|rs)\b`)
+)
+
+// stopWords are common words of specifications that name no code.
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true, "from": true,
+	"into": true, "are": true, "not": true, "should": true, "must": true, "will": true, "when": true,
+	"add": true, "new": true, "use": true, "all": true, "can": true, "each": true, "file": true,
+	"files": true, "code": true, "test": true, "tests": true, "func": true, "type": true, "return": true,
+	"implementation": true, "specification": true, "overview": true, "details": true, "modify": true,
+	"internal": true, "src": true, "cmd": true, "pkg": true, "lib": true,
+}
+
+// words splits text into lowercase words: identifiers are split at
+// underscores and case changes, and short and common words are dropped.
+func words(text string) []string {
+	var out []string
+	for _, ident := range identPattern.FindAllString(text, -1) {
+		for part := range strings.SplitSeq(ident, "_") {
+			for _, w := range splitCamel(part) {
+				w = strings.ToLower(w)
+				if len(w) >= 3 && !stopWords[w] && !slices.Contains(out, w) {
+					out = append(out, w)
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+// splitCamel splits an identifier at case changes: "parseHTTPRequest" is
+// "parse", "HTTP", "Request".
+func splitCamel(s string) []string {
+	runes := []rune(s)
+	var parts []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		lowerToUpper := unicode.IsLower(runes[i-1]) && unicode.IsUpper(runes[i])
+		acronymEnd := i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i+1])
+		if lowerToUpper || acronymEnd {
+			parts = append(parts, string(runes[start:i]))
+			start = i
+		}
+	}
+
+	return append(parts, string(runes[start:]))
+}
+
+// Relevant returns up to limit non-test files most related to text, such as
+// a specification: files it names outright first, then files whose path and
+// symbols share the most words with it.
+func (m *Map) Relevant(text string, limit int) []File {
+	// Named files score on their own; their directories would match widely
+	named := pathPattern.FindAllString(text, -1)
+	keywords := words(pathPattern.ReplaceAllString(text, " "))
+
+	type scored struct {
+		file  File
+		score int
+	}
+	var ranked []scored
+	for _, f := range m.Files {
+		if f.Test {
+			continue
+		}
+
+		score := 0
+		if slices.ContainsFunc(named, func(n string) bool { return n == f.Path || strings.HasSuffix(f.Path, "/"+n) }) {
+			score += scoreNamed
+		}
+		pathWords := words(f.Path)
+		symbolWords := words(strings.Join(f.Symbols, " "))
+		for _, k := range keywords {
+			if slices.Contains(pathWords, k) {
+				score += scorePath
+			}
+			if slices.Contains(symbolWords, k) {
+				score += scoreSymbol
+			}
+		}
+		if score > 0 {
+			ranked = append(ranked, scored{f, score})
+		}
+	}
+
+	slices.SortStableFunc(ranked, func(a, b scored) int { return b.score - a.score })
+	files := make([]File, 0, min(limit, len(ranked)))
+	for _, r := range ranked[:min(limit, len(ranked))] {
+		files = append(files, r.file)
+	}
+
+	return files
+}
+
+// Render formats files as a compact listing for a prompt: one line per file
+// with its package and tests, then its symbols.
+func Render(files []File) string {
+	var sb strings.Builder
+	for _, f := range files {
+		sb.WriteString(f.Path)
+		var notes []string
+		if f.Package != "" {
+			notes = append(notes, "package "+f.Package)
+		}
+		if len(f.Tests) > 0 {
+			tests := make([]string, len(f.Tests))
+			for i, t := range f.Tests {
+				// Tests next to the file are named by file name only
+				if path.Dir(t) == path.Dir(f.Path) {
+					t = path.Base(t)
+				}
+				tests[i] = t
+			}
+			notes = append(notes, "tests: "+strings.Join(tests, ", "))
+		}
+		if len(notes) > 0 {
+			fmt.Fprintf(&sb, " (%s)", strings.Join(notes, "; "))
+		}
+		sb.WriteString("\n")
+
+		if len(f.Symbols) > 0 {
+			shown := f.Symbols[:min(maxSymbolsShown, len(f.Symbols))]
+			sb.WriteString("  " + strings.Join(shown, "; "))
+			if more := len(f.Symbols) - len(shown); more > 0 {
+				fmt.Fprintf(&sb, "; and %d more", more)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// TestLayout describes where the mapped repository keeps its tests, e.g.
+// "Go tests sit next to the code in *_test.go files", or "" without tests.
+func (m *Map) TestLayout() string {
+	counts := make(map[string]int)
+	for _, f := range m.Files {
+		if !f.Test {
+			continue
+		}
+		switch base := path.Base(f.Path); {
+		case strings.HasSuffix(base, "_test.go"):
+			counts["Go tests sit next to the code in *_test.go files"]++
+		case strings.Contains(f.Path, "__tests__/"):
+			counts["tests live in __tests__ directories"]++
+		case strings.HasPrefix(f.Path, "tests/") || strings.Contains(f.Path, "/tests/"):
+			counts["tests live in tests/ directories"]++
+		case strings.Contains(base, ".test."), strings.Contains(base, ".spec."):
+			counts["tests sit next to the code in *.test.* or *.spec.* files"]++
+		default:
+			counts["tests are named test_* or *Test"]++
+		}
+	}
+
+	layouts := make([]string, 0, len(counts))
+	for layout := range counts {
+		layouts = append(layouts, layout)
+	}
+	slices.SortFunc(layouts, func(a, b string) int {
+		if d := counts[b] - counts[a]; d != 0 {
+			return d
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	return strings.Join(layouts, "; ")
+}
@@ -3,20 +3,79 @@ package conductor
 import (
 	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/workflow"
 )
 
+// ErrBudgetExceeded is returned when an auto run spends its cost budget.
+var ErrBudgetExceeded = errors.New("auto run budget exceeded")
+
+// ErrIterationLimit is returned when an auto run uses up its agent runs.
+var ErrIterationLimit = errors.New("auto run iteration limit reached")
+
+// QuestionPolicy decides what an auto run does when the agent asks a question.
+type QuestionPolicy string
+
+const (
+	QuestionSkip     QuestionPolicy = "skip"      // Proceed with the agent's best guess
+	QuestionDefault  QuestionPolicy = "default"   // Answer with the first offered option and plan again
+	QuestionStop     QuestionPolicy = "stop"      // Leave the question pending and stop the run
+	QuestionAskAgent QuestionPolicy = "ask_agent" // Let the agent answer its own question and plan again
+)
+
+// ParseQuestionPolicy validates a question policy name. The config names
+// "fail" and "default_option" are accepted for stop and default.
+func ParseQuestionPolicy(name string) (QuestionPolicy, error) {
+	switch policy := QuestionPolicy(strings.ToLower(strings.TrimSpace(name))); policy {
+	case "fail":
+		return QuestionStop, nil
+	case "default_option":
+		return QuestionDefault, nil
+	case QuestionSkip, QuestionDefault, QuestionStop, QuestionAskAgent:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown question policy %q: use fail, default_option, ask_agent or skip", name)
+	}
+}
+
+// AutoPolicy bounds an unattended run.
+type AutoPolicy struct {
+	MaxIterations int            // Agent phase runs allowed (0 = unlimited)
+	BudgetUSD     float64        // Stop before a phase once the task's agent cost reaches this (0 = unlimited)
+	OnQuestion    QuestionPolicy // Empty uses workflow.question_policy, else the conductor's SkipAgentQuestions setting
+}
+
 // AutoOptions configures the full automation run.
 type AutoOptions struct {
 	// Quality settings
 	QualityTarget string // Make target (default: "quality")
 	MaxRetries    int    // Max quality retry attempts (0 = skip quality)
 
+	// Review the changes after quality checks pass
+	Review bool
+
+	// Stop before finishing, leaving the task for mehr finish
+	NoFinish bool
+
+	// Limits on iterations, spend and agent questions
+	Policy AutoPolicy
+
 	// Finish settings
 	SquashMerge  bool   // Use squash merge (default: true)
 	DeleteBranch bool   // Delete branch after merge (default: true)
 	TargetBranch string // Branch to merge into (default: auto-detect)
 	Push         bool   // Push after merge
+
+	// CheckoutLock, when set, is held while the task starts and finishes.
+	// Both change the main checkout and the active task, so runs sharing a
+	// repository through worktrees take turns there.
+	CheckoutLock sync.Locker
 }
 
 // DefaultAutoOptions returns sensible defaults for auto mode.
@@ -33,27 +92,55 @@ func DefaultAutoOptions() AutoOptions {
 
 // AutoResult holds the result of a full auto run.
 type AutoResult struct {
-	PlanningDone    bool   // Planning phase completed
-	ImplementDone   bool   // Implementation phase completed
-	QualityAttempts int    // Number of quality check attempts
-	QualityPassed   bool   // Quality checks passed
-	FinishDone      bool   // Task finished and merged
-	Error           error  // First error encountered (if any)
-	FailedAt        string // Phase where failure occurred
+	TaskID            string  // Task registered by the run
+	PlanningDone      bool    // Planning phase completed
+	ImplementDone     bool    // Implementation phase completed
+	DocumentDone      bool    // Documentation phase completed (when enabled)
+	QualityAttempts   int     // Number of quality check attempts
+	QualityPassed     bool    // Quality checks passed
+	ReviewDone        bool    // Review phase completed (when enabled)
+	FinishDone        bool    // Task finished and merged
+	Iterations        int     // Agent phase runs used
+	QuestionsAnswered int     // Agent questions answered by the question policy
+	CostUSD           float64 // Task agent cost when the run ended
+	Question          string  // Pending question that stopped the run (QuestionStop)
+	PullRequestURL    string  // Pull request opened by finish (when the provider supports PRs)
+	Error             error   // First error encountered (if any)
+	FailedAt          string  // Phase where failure occurred
+}
+
+// fail records err as the run's failure in phase and returns it.
+func (r *AutoResult) fail(phase string, err error) error {
+	r.Error = err
+	r.FailedAt = phase
+
+	return err
 }
 
-// RunAuto executes the full automation cycle: start -> plan -> implement -> quality -> finish.
+// RunAuto executes the full automation cycle: start -> plan -> implement -> (document) -> quality -> (review) -> finish.
+// The policy in opts bounds the agent runs and spend, and decides how agent
+// questions are handled.
 func (c *Conductor) RunAuto(ctx context.Context, reference string, opts AutoOptions) (*AutoResult, error) {
 	result := &AutoResult{}
+	policy := opts.Policy
+	policy.OnQuestion = cmp.Or(policy.OnQuestion, c.ConfiguredQuestionPolicy())
+	if policy.OnQuestion != "" {
+		c.opts.SkipAgentQuestions = policy.OnQuestion == QuestionSkip
+	}
+	defer func() { result.CostUSD = c.taskSpend() }()
 
 	// Step 1: Start task (register it)
 	c.publishProgress("Starting task...", 5)
-	if err := c.Start(ctx, reference); err != nil {
+	unlock := lockCheckout(opts.CheckoutLock)
+	err := c.Start(ctx, reference)
+	unlock()
+	if err != nil {
 		result.Error = err
 		result.FailedAt = "start"
 
 		return result, fmt.Errorf("start: %w", err)
 	}
+	result.TaskID = c.activeTask.ID
 	c.publishProgress("Task registered", 10)
 
 	// Step 2: Planning phase
@@ -65,11 +152,7 @@ func (c *Conductor) RunAuto(ctx context.Context, reference string, opts AutoOpti
 		return result, fmt.Errorf("enter planning: %w", err)
 	}
 
-	if err := c.RunPlanning(ctx); err != nil {
-		// In auto mode, ErrPendingQuestion should not occur (skipped in handlers.go)
-		result.Error = err
-		result.FailedAt = "planning"
-
+	if err := c.autoPlan(ctx, policy, result); err != nil {
 		return result, fmt.Errorf("planning: %w", err)
 	}
 	result.PlanningDone = true
@@ -84,6 +167,9 @@ func (c *Conductor) RunAuto(ctx context.Context, reference string, opts AutoOpti
 		return result, fmt.Errorf("enter implementation: %w", err)
 	}
 
+	if err := c.spendIteration(policy, result); err != nil {
+		return result, result.fail("implementation", err)
+	}
 	if err := c.RunImplementation(ctx); err != nil {
 		result.Error = err
 		result.FailedAt = "implementation"
@@ -93,6 +179,26 @@ func (c *Conductor) RunAuto(ctx context.Context, reference string, opts AutoOpti
 	result.ImplementDone = true
 	c.publishProgress("Implementation complete", 50)
 
+	// Optional documentation phase
+	if c.DocumentAfterImplement() {
+		if err := c.Document(ctx); err != nil {
+			result.Error = err
+			result.FailedAt = "document"
+
+			return result, fmt.Errorf("enter documentation: %w", err)
+		}
+		if err := c.spendIteration(policy, result); err != nil {
+			return result, result.fail("documentation", err)
+		}
+		if err := c.RunDocumentation(ctx); err != nil {
+			result.Error = err
+			result.FailedAt = "documentation"
+
+			return result, fmt.Errorf("documentation: %w", err)
+		}
+		result.DocumentDone = true
+	}
+
 	// Step 4: Quality retry loop (skip if MaxRetries is 0)
 	if opts.MaxRetries > 0 {
 		// Use opts.MaxRetries if positive, otherwise fall back to conductor default
@@ -128,6 +234,10 @@ func (c *Conductor) RunAuto(ctx context.Context, reference string, opts AutoOpti
 			// Quality failed, but we have retries left
 			if attempt < maxRetries {
 				c.publishProgress(fmt.Sprintf("Quality failed, re-implementing (attempt %d)...", attempt+1), 55)
+				c.countRetry("quality")
+				if err := c.spendIteration(policy, result); err != nil {
+					return result, result.fail("re-implementation", err)
+				}
 
 				// Re-run implementation with quality feedback
 				if err := c.reImplementWithFeedback(ctx, qualityResult.Output); err != nil {
@@ -152,6 +262,33 @@ func (c *Conductor) RunAuto(ctx context.Context, reference string, opts AutoOpti
 		c.publishProgress("Quality checks skipped", 80)
 	}
 
+	// Optional review phase; fixes the reviewer makes are checkpointed
+	if opts.Review {
+		c.publishProgress("Reviewing changes...", 80)
+		if err := c.Review(ctx); err != nil {
+			result.Error = err
+			result.FailedAt = "review"
+
+			return result, fmt.Errorf("enter review: %w", err)
+		}
+		if err := c.spendIteration(policy, result); err != nil {
+			return result, result.fail("review", err)
+		}
+		if err := c.RunReview(ctx); err != nil {
+			result.Error = err
+			result.FailedAt = "review"
+
+			return result, fmt.Errorf("review: %w", err)
+		}
+		result.ReviewDone = true
+	}
+
+	if opts.NoFinish {
+		c.publishProgress("Stopped before finish", 100)
+
+		return result, nil
+	}
+
 	// Step 5: Finish (merge)
 	c.publishProgress("Finishing task...", 85)
 	finishOpts := FinishOptions{
@@ -161,7 +298,16 @@ func (c *Conductor) RunAuto(ctx context.Context, reference string, opts AutoOpti
 		PushAfter:    opts.Push,
 	}
 
-	if err := c.Finish(ctx, finishOpts); err != nil {
+	prSub := c.eventBus.Subscribe(events.TypePRCreated, func(e events.Event) {
+		if url, ok := e.Data["pr_url"].(string); ok && result.PullRequestURL == "" {
+			result.PullRequestURL = url
+		}
+	})
+	unlock = lockCheckout(opts.CheckoutLock)
+	err = c.Finish(ctx, finishOpts)
+	unlock()
+	c.eventBus.Unsubscribe(prSub)
+	if err != nil {
 		result.Error = err
 		result.FailedAt = "finish"
 
@@ -173,6 +319,16 @@ func (c *Conductor) RunAuto(ctx context.Context, reference string, opts AutoOpti
 	return result, nil
 }
 
+// lockCheckout locks l, if set, and returns the function unlocking it.
+func lockCheckout(l sync.Locker) func() {
+	if l == nil {
+		return func() {}
+	}
+	l.Lock()
+
+	return l.Unlock
+}
+
 // reImplementWithFeedback runs implementation phase with quality failure context.
 func (c *Conductor) reImplementWithFeedback(ctx context.Context, qualityOutput string) error {
 	// Append quality feedback to notes so agent sees what failed
@@ -195,3 +351,180 @@ func (c *Conductor) reImplementWithFeedback(ctx context.Context, qualityOutput s
 
 	return nil
 }
+
+// autoPlan runs planning, answering agent questions as the policy says.
+// Under QuestionDefault the first offered option (or a request to use the
+// most reasonable default) is recorded as the answer and planning runs again.
+func (c *Conductor) autoPlan(ctx context.Context, policy AutoPolicy, result *AutoResult) error {
+	for {
+		if err := c.spendIteration(policy, result); err != nil {
+			return result.fail("planning", err)
+		}
+
+		err := c.RunPlanning(ctx)
+		if !errors.Is(err, ErrPendingQuestion) {
+			if err != nil {
+				return result.fail("planning", err)
+			}
+
+			return nil
+		}
+
+		question, loadErr := c.workspace.LoadPendingQuestion(c.activeTask.ID)
+		if loadErr != nil || question == nil {
+			return result.fail("planning", err)
+		}
+		var answer string
+		switch policy.OnQuestion {
+		case QuestionDefault:
+			answer = defaultAnswer(question)
+			c.publishProgress("Answering agent question with default: "+answer, 20)
+		case QuestionAskAgent:
+			if err := c.spendIteration(policy, result); err != nil {
+				return result.fail("planning", err)
+			}
+			answer = c.askAgentAnswer(ctx, question)
+			c.publishProgress("Agent answered its own question: "+answer, 20)
+		case QuestionSkip, QuestionStop:
+			result.Question = question.Question
+
+			return result.fail("planning", err)
+		}
+
+		if err := c.AnswerQuestion(autoAnswerNote(policy.OnQuestion, answer)); err != nil {
+			return result.fail("planning", fmt.Errorf("answer question: %w", err))
+		}
+		result.QuestionsAnswered++
+
+		if err := c.Plan(ctx); err != nil {
+			return result.fail("plan", err)
+		}
+	}
+}
+
+// defaultAnswer picks the answer QuestionDefault gives to a question: the
+// option marked as recommended, else the first one.
+func defaultAnswer(question *storage.PendingQuestion) string {
+	for _, opt := range question.Options {
+		if strings.Contains(strings.ToLower(opt.Label+" "+opt.Description), "recommended") {
+			return opt.Label
+		}
+	}
+	if len(question.Options) > 0 {
+		return question.Options[0].Label
+	}
+
+	return "No one is available to answer. Choose the most reasonable default and note the assumption in the specification."
+}
+
+// askAgentAnswer asks the planning agent to answer its own question, falling
+// back to the default answer when the agent fails or says nothing.
+func (c *Conductor) askAgentAnswer(ctx context.Context, question *storage.PendingQuestion) string {
+	fallback := defaultAnswer(question)
+
+	planningAgent, err := c.GetAgentForStep(ctx, workflow.StepPlanning)
+	if err != nil {
+		c.logError(fmt.Errorf("get agent to answer question: %w", err))
+
+		return fallback
+	}
+
+	var sb strings.Builder
+	sb.WriteString("You are planning this task and asked the question below, but no one is available to answer it.\n")
+	sb.WriteString("Decide the answer yourself. Prefer the option that is safest and most consistent with the existing code.\n\n")
+	fmt.Fprintf(&sb, "## Question\n%s\n", question.Question)
+	if len(question.Options) > 0 {
+		sb.WriteString("\n## Options\n")
+		for _, opt := range question.Options {
+			fmt.Fprintf(&sb, "- %s", opt.Label)
+			if opt.Description != "" {
+				sb.WriteString(": " + opt.Description)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	if question.ContextSummary != "" {
+		fmt.Fprintf(&sb, "\n## What You Found So Far\n%s\n", question.ContextSummary)
+	}
+	sb.WriteString("\nReply with the answer only, in one or two sentences. Do not change any files.\n")
+
+	response, err := planningAgent.Run(ctx, sb.String())
+	if err != nil {
+		c.logError(fmt.Errorf("agent answer to question: %w", err))
+
+		return fallback
+	}
+	c.recordUsage(c.activeTask.ID, "planning", workflow.StepPlanning, planningAgent, response.Usage)
+
+	answer := strings.TrimSpace(response.Summary)
+	if answer == "" && len(response.Messages) > 0 {
+		answer = strings.TrimSpace(response.Messages[len(response.Messages)-1])
+	}
+	if answer == "" {
+		return fallback
+	}
+
+	return answer
+}
+
+// autoAnswerNote marks an answer given by a question policy, so the notes
+// and the planning session show no person made the choice.
+func autoAnswerNote(policy QuestionPolicy, answer string) string {
+	return fmt.Sprintf("%s\n\n_Answered automatically by the %s question policy._", answer, policy)
+}
+
+// ConfiguredQuestionPolicy returns workflow.question_policy from the
+// workspace config, or "" when it is unset or invalid.
+func (c *Conductor) ConfiguredQuestionPolicy() QuestionPolicy {
+	if c.workspace == nil {
+		return ""
+	}
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil || cfg.Workflow.QuestionPolicy == "" {
+		return ""
+	}
+
+	policy, err := ParseQuestionPolicy(cfg.Workflow.QuestionPolicy)
+	if err != nil {
+		c.logError(fmt.Errorf("workflow.question_policy: %w", err))
+
+		return ""
+	}
+
+	return policy
+}
+
+// spendIteration counts the next agent phase run against the policy, failing
+// once the iteration limit or cost budget is used up.
+func (c *Conductor) spendIteration(policy AutoPolicy, result *AutoResult) error {
+	if policy.BudgetUSD > 0 {
+		if spent := c.taskSpend(); spent >= policy.BudgetUSD {
+			return fmt.Errorf("%w: spent $%.2f of $%.2f", ErrBudgetExceeded, spent, policy.BudgetUSD)
+		}
+	}
+	if policy.MaxIterations > 0 && result.Iterations >= policy.MaxIterations {
+		return fmt.Errorf("%w: %d agent runs", ErrIterationLimit, policy.MaxIterations)
+	}
+	result.Iterations++
+
+	return nil
+}
+
+// taskSpend returns the agent cost recorded for the active task so far.
+func (c *Conductor) taskSpend() float64 {
+	if c.activeTask == nil || c.workspace == nil {
+		return 0
+	}
+
+	records, err := c.workspace.LoadUsageRecords(c.activeTask.ID)
+	if err != nil {
+		return 0
+	}
+
+	var total float64
+	for _, r := range records {
+		total += r.CostUSD
+	}
+
+	return total
+}
@@ -1,7 +1,13 @@
 package conductor
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/storage"
 )
 
 func TestDefaultAutoOptions(t *testing.T) {
@@ -208,3 +214,196 @@ func TestOptionsApply_AutoOptions(t *testing.T) {
 		t.Errorf("MaxQualityRetries = %d, want 10", opts.MaxQualityRetries)
 	}
 }
+
+// questionAgent asks one question on its first call, then answers every
+// prompt. Each call costs one dollar.
+type questionAgent struct {
+	mockAgent
+	calls   int
+	prompts []string
+}
+
+func (a *questionAgent) Run(_ context.Context, prompt string) (*agent.Response, error) {
+	a.calls++
+	a.prompts = append(a.prompts, prompt)
+	resp := &agent.Response{Summary: "Use a cache.", Usage: &agent.UsageStats{InputTokens: 10, CostUSD: 1}}
+	if a.calls == 1 {
+		resp.Question = &agent.Question{
+			Text:    "Which cache backend?",
+			Options: []agent.QuestionOption{{Label: "Redis"}, {Label: "Memcached"}},
+		}
+	}
+
+	return resp, nil
+}
+
+func (a *questionAgent) RunWithCallback(ctx context.Context, prompt string, _ agent.StreamCallback) (*agent.Response, error) {
+	return a.Run(ctx, prompt)
+}
+
+func TestRunAuto_Policy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	tests := []struct {
+		name       string
+		policy     AutoPolicy
+		wantErr    error
+		failedAt   string
+		iterations int
+		answered   int
+		question   string
+	}{
+		{
+			name:       "answers questions with the first option",
+			policy:     AutoPolicy{MaxIterations: 10, OnQuestion: QuestionDefault},
+			iterations: 4, // planning twice, implementation, review
+			answered:   1,
+		},
+		{
+			name:     "stops on questions",
+			policy:   AutoPolicy{OnQuestion: QuestionStop},
+			wantErr:  ErrPendingQuestion,
+			failedAt: "planning",
+			question: "Which cache backend?",
+		},
+		{
+			name:       "stops at the budget",
+			policy:     AutoPolicy{BudgetUSD: 1.5, OnQuestion: QuestionDefault},
+			wantErr:    ErrBudgetExceeded,
+			failedAt:   "implementation",
+			iterations: 2,
+			answered:   1,
+		},
+		{
+			name:       "stops at the iteration limit",
+			policy:     AutoPolicy{MaxIterations: 1, OnQuestion: QuestionDefault},
+			wantErr:    ErrIterationLimit,
+			failedAt:   "planning",
+			iterations: 1,
+			answered:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &questionAgent{mockAgent: mockAgent{name: "mock"}}
+			c, reference := newFileTaskConductor(t, a, WithAutoMode(true))
+
+			result, err := c.RunAuto(context.Background(), reference, AutoOptions{
+				Review:   true,
+				NoFinish: true,
+				Policy:   tt.policy,
+			})
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("RunAuto: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RunAuto error = %v, want %v", err, tt.wantErr)
+			}
+
+			if result.FailedAt != tt.failedAt {
+				t.Errorf("FailedAt = %q, want %q", result.FailedAt, tt.failedAt)
+			}
+			if result.Iterations != tt.iterations && tt.wantErr != ErrPendingQuestion {
+				t.Errorf("Iterations = %d, want %d", result.Iterations, tt.iterations)
+			}
+			if result.QuestionsAnswered != tt.answered {
+				t.Errorf("QuestionsAnswered = %d, want %d", result.QuestionsAnswered, tt.answered)
+			}
+			if result.Question != tt.question {
+				t.Errorf("Question = %q, want %q", result.Question, tt.question)
+			}
+			if result.CostUSD != float64(a.calls) {
+				t.Errorf("CostUSD = %v, want %d", result.CostUSD, a.calls)
+			}
+		})
+	}
+}
+
+func TestRunAuto_DefaultAnswerReachesPlanning(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	a := &questionAgent{mockAgent: mockAgent{name: "mock"}}
+	c, reference := newFileTaskConductor(t, a, WithAutoMode(true))
+
+	result, err := c.RunAuto(context.Background(), reference, AutoOptions{
+		NoFinish: true,
+		Policy:   AutoPolicy{OnQuestion: QuestionDefault},
+	})
+	if err != nil {
+		t.Fatalf("RunAuto: %v", err)
+	}
+	if !result.PlanningDone || !result.ImplementDone || result.ReviewDone || result.FinishDone {
+		t.Errorf("result = %+v, want planning and implementation only", result)
+	}
+	if len(a.prompts) < 2 || !strings.Contains(a.prompts[1], "Redis") {
+		t.Errorf("second planning prompt should carry the default answer")
+	}
+}
+
+func TestParseQuestionPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    QuestionPolicy
+		wantErr bool
+	}{
+		{"fail", QuestionStop, false},
+		{"default_option", QuestionDefault, false},
+		{"ask_agent", QuestionAskAgent, false},
+		{"Skip", QuestionSkip, false},
+		{"stop", QuestionStop, false},
+		{"ask", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseQuestionPolicy(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseQuestionPolicy(%q) = %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestDefaultAnswer_Recommended(t *testing.T) {
+	question := &storage.PendingQuestion{Options: []storage.QuestionOption{
+		{Label: "Memcached"},
+		{Label: "Redis", Description: "Recommended: already used for sessions"},
+	}}
+	if got := defaultAnswer(question); got != "Redis" {
+		t.Errorf("defaultAnswer() = %q, want the recommended option", got)
+	}
+}
+
+func TestRunAuto_ConfiguredAskAgent(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	a := &questionAgent{mockAgent: mockAgent{name: "mock"}}
+	c, reference := newFileTaskConductor(t, a, WithAutoMode(true))
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	cfg.Workflow.QuestionPolicy = "ask_agent"
+	if err := c.workspace.SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	result, err := c.RunAuto(context.Background(), reference, AutoOptions{NoFinish: true})
+	if err != nil {
+		t.Fatalf("RunAuto: %v", err)
+	}
+	if result.QuestionsAnswered != 1 || result.Iterations != 4 { // planning, answer, planning, implementation
+		t.Errorf("result = %+v", result)
+	}
+	if !strings.Contains(a.prompts[1], "Which cache backend?") || !strings.Contains(a.prompts[1], "Reply with the answer only") {
+		t.Errorf("agent should be asked to answer its question:\n%s", a.prompts[1])
+	}
+	if !strings.Contains(a.prompts[2], "Use a cache.") || !strings.Contains(a.prompts[2], "Answered automatically by the ask_agent question policy") {
+		t.Errorf("replanning prompt should carry the marked answer:\n%s", a.prompts[2])
+	}
+}
@@ -31,12 +31,15 @@ import (
 	"fmt"
 	"io"
 	"sync"
+	"sync/atomic"
 
 	"github.com/valksor/go-mehrhof/internal/agent"
 	"github.com/valksor/go-mehrhof/internal/events"
 	"github.com/valksor/go-mehrhof/internal/plugin"
 	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/script"
 	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/telemetry"
 	"github.com/valksor/go-mehrhof/internal/vcs"
 	"github.com/valksor/go-mehrhof/internal/workflow"
 )
@@ -56,15 +59,24 @@ type Conductor struct {
 	agents    *agent.Registry
 	plugins   *plugin.Registry
 
+	// Response cache of planning and review agents (nil when off), replaced by ReloadConfig
+	responseCache atomic.Pointer[agent.ResponseCache]
+
 	// Workflow plugin adapters (for lifecycle management)
 	workflowAdapters []*plugin.WorkflowAdapter
 
+	// Workflow scripts from .mehrhof/scripts (nil until Initialize)
+	scripts *script.Set
+
 	// Current state
 	activeTask *storage.ActiveTask
 	taskWork   *storage.TaskWork
+	agentFiles map[string]bool // Files agents changed since the last checkpoint
 
 	// Configuration
-	opts Options
+	opts       Options
+	applied    appliedConfig // Workspace config in effect, replaced by ReloadConfig
+	defaultsMu sync.Mutex    // Guards opts.DefaultAgent and opts.DefaultProvider, which ReloadConfig replaces
 
 	// Active agent
 	activeAgent     agent.Agent
@@ -73,6 +85,13 @@ type Conductor struct {
 	// Session tracking (for conversation history and token usage)
 	currentSession     *storage.Session
 	currentSessionFile string
+
+	// Span and metric export (nil when telemetry is disabled)
+	telemetry *telemetry.Telemetry
+
+	// Audit entries of operations run before their task was registered
+	auditMu      sync.Mutex
+	auditPending []storage.AuditEntry
 }
 
 // New creates a new Conductor with the given options.
@@ -101,6 +120,11 @@ func New(opts ...Option) (*Conductor, error) {
 	// Subscribe to state changes
 	bus.Subscribe(events.TypeStateChanged, c.onStateChanged)
 
+	// Keep a per-task log of everything published, which late
+	// subscribers replay
+	bus.SubscribeAll(c.logEvent)
+	bus.SetHistory(c.eventHistory)
+
 	return c, nil
 }
 
@@ -129,6 +153,19 @@ func (c *Conductor) GetGit() *vcs.Git {
 	return c.git
 }
 
+// GetVCS returns the version control backend: git when the project is a git
+// repository, otherwise a vcs.NullVCS rooted at the workspace.
+func (c *Conductor) GetVCS() vcs.VCS {
+	if c.git != nil {
+		return c.git
+	}
+	if c.workspace != nil {
+		return vcs.NewNull(c.workspace.Root())
+	}
+
+	return vcs.NewNull(c.opts.WorkDir)
+}
+
 // GetActiveTask returns the current active task.
 // Returns a copy to avoid data races; the caller cannot modify the internal state.
 // Note: ActiveTask only contains value types (strings, bool, time.Time),
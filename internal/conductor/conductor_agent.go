@@ -1,11 +1,16 @@
 package conductor
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
+	"path/filepath"
+	"slices"
+	"time"
 
 	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/chaos"
 	"github.com/valksor/go-mehrhof/internal/coordination"
 	"github.com/valksor/go-mehrhof/internal/plugin"
 	"github.com/valksor/go-mehrhof/internal/provider"
@@ -38,7 +43,7 @@ func (c *Conductor) resolveAgentForTask() (agent.Agent, string, error) {
 		source = "task"
 	} else {
 		// Priority 3: Workspace default or auto-detect
-		if cfg, err := c.workspace.LoadConfig(); err == nil && cfg.Agent.Default != "" {
+		if cfg, err := c.agentConfig(); err == nil && cfg.Agent.Default != "" {
 			agentName = cfg.Agent.Default
 			source = "workspace"
 		} else {
@@ -69,6 +74,20 @@ func (c *Conductor) resolveAgentForTask() (agent.Agent, string, error) {
 	return agentInst, source, nil
 }
 
+// agentConfig returns the workspace config with agent.default replaced by
+// the default agent of the options, which layers the app settings under it.
+func (c *Conductor) agentConfig() (*storage.WorkspaceConfig, error) {
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if name := c.defaultAgent(); name != "" {
+		cfg.Agent.Default = name
+	}
+
+	return cfg, nil
+}
+
 // AgentResolution holds the result of agent resolution for a specific step.
 //
 // Deprecated: Use coordination.Resolution instead.
@@ -92,6 +111,9 @@ func (c *Conductor) resolveAgentForStep(ctx context.Context, step workflow.Step)
 		TaskConfig:     c.taskAgentConfig,
 		Step:           step,
 	}
+	if cfg, err := c.agentConfig(); err == nil {
+		req.WorkspaceCfg = cfg
+	}
 
 	resolution, err := resolver.ResolveForStep(ctx, req)
 	if err != nil {
@@ -119,6 +141,8 @@ func (c *Conductor) GetAgentForStep(ctx context.Context, step workflow.Step) (ag
 			// Restore from persisted config
 			agentInst, err := c.agents.Get(stepInfo.Name)
 			if err == nil {
+				// Cache before the env and args, which become part of the cache key
+				agentInst = c.withResponseCache(agentInst, step)
 				// Re-apply inline env
 				agentInst = applyAgentEnv(agentInst, stepInfo.InlineEnv)
 				// Re-apply args
@@ -126,7 +150,7 @@ func (c *Conductor) GetAgentForStep(ctx context.Context, step workflow.Step) (ag
 					agentInst = agentInst.WithArgs(stepInfo.Args...)
 				}
 
-				return agentInst, nil
+				return c.withTracing(withFailureInjection(agentInst), stepStr), nil
 			}
 			// Fall through to re-resolve if stored agent not found
 		}
@@ -156,7 +180,32 @@ func (c *Conductor) GetAgentForStep(ctx context.Context, step workflow.Step) (ag
 		}
 	}
 
-	return resolution.Agent, nil
+	return c.withTracing(withFailureInjection(c.withResponseCache(resolution.Agent, step)), stepStr), nil
+}
+
+// cachedSteps are the steps whose agents may answer from the response
+// cache. Their result is the response text; steps whose agents change files
+// are never cached, as a cached response would skip the changes.
+var cachedSteps = []workflow.Step{workflow.StepPlanning, workflow.StepReviewing}
+
+// withResponseCache wraps a in the response cache when caching is on and
+// step is one of cachedSteps.
+func (c *Conductor) withResponseCache(a agent.Agent, step workflow.Step) agent.Agent {
+	cache := c.responseCache.Load()
+	if cache == nil || !slices.Contains(cachedSteps, step) {
+		return a
+	}
+
+	return agent.WithCache(a, cache)
+}
+
+// withFailureInjection wraps a when chaos failure injection is configured.
+func withFailureInjection(a agent.Agent) agent.Agent {
+	if !chaos.Enabled() {
+		return a
+	}
+
+	return agent.WithFailureInjection(a)
 }
 
 // registerAliasAgents registers user-defined agent aliases from workspace config.
@@ -205,7 +254,6 @@ func (c *Conductor) registerAliasAgents(cfg *storage.WorkspaceConfig) error {
 		if err != nil {
 			return fmt.Errorf("get base agent for alias %q: %w", name, err)
 		}
-
 		// Resolve environment variable references
 		env := agent.ResolveEnvReferences(alias.Env)
 
@@ -214,6 +262,7 @@ func (c *Conductor) registerAliasAgents(cfg *storage.WorkspaceConfig) error {
 		if err := c.agents.Register(aliasAgent); err != nil {
 			return fmt.Errorf("register alias %q: %w", name, err)
 		}
+		c.applied.aliases = append(c.applied.aliases, name)
 
 		resolved[name] = true
 		resolving[name] = false
@@ -231,17 +280,39 @@ func (c *Conductor) registerAliasAgents(cfg *storage.WorkspaceConfig) error {
 	return nil
 }
 
-// loadPlugins discovers and loads enabled plugins.
-func (c *Conductor) loadPlugins(ctx context.Context, cfg *storage.WorkspaceConfig) error {
+// setupResponseCache makes planning and review agents answer repeated
+// prompts from .mehrhof/cache/agent when agent.cache.enabled is set, unless
+// the options turn caching off.
+func (c *Conductor) setupResponseCache(cfg *storage.WorkspaceConfig) {
+	enabled := cfg.Agent.Cache.Enabled
+	if c.opts.ResponseCache != nil {
+		enabled = *c.opts.ResponseCache
+	}
+	if !enabled {
+		c.responseCache.Store(nil)
+
+		return
+	}
+
+	ttl := cfg.Agent.Cache.TTLHours
+	if ttl == 0 {
+		ttl = storage.DefaultAgentCacheTTLHours
+	}
+	c.responseCache.Store(agent.NewResponseCache(filepath.Join(c.workspace.CacheDir(), "agent"), time.Duration(ttl)*time.Hour))
+}
+
+// loadPlugins discovers and loads enabled plugins. Workflow plugins extend
+// builder, and loadPlugins reports whether any did.
+func (c *Conductor) loadPlugins(ctx context.Context, cfg *storage.WorkspaceConfig, builder *workflow.MachineBuilder) (bool, error) {
 	// Skip if no plugins are enabled
 	if len(cfg.Plugins.Enabled) == 0 {
-		return nil
+		return false, nil
 	}
 
 	// Get plugin directories
 	globalDir, err := plugin.DefaultGlobalDir()
 	if err != nil {
-		return fmt.Errorf("get global plugins dir: %w", err)
+		return false, fmt.Errorf("get global plugins dir: %w", err)
 	}
 	projectDir := plugin.DefaultProjectDir(c.workspace.Root())
 
@@ -255,7 +326,7 @@ func (c *Conductor) loadPlugins(ctx context.Context, cfg *storage.WorkspaceConfi
 
 	// Discover and load plugins
 	if err := c.plugins.DiscoverAndLoad(ctx); err != nil {
-		return fmt.Errorf("discover and load plugins: %w", err)
+		return false, fmt.Errorf("discover and load plugins: %w", err)
 	}
 
 	// Register provider plugins
@@ -297,41 +368,41 @@ func (c *Conductor) loadPlugins(ctx context.Context, cfg *storage.WorkspaceConfi
 
 	// Register workflow plugins (phases, guards, effects)
 	workflowPlugins := c.plugins.Workflows()
-	if len(workflowPlugins) > 0 {
-		// Build a new machine with plugin extensions
-		builder := workflow.NewMachineBuilder()
-
-		for _, info := range workflowPlugins {
-			if info.Process == nil {
-				continue
-			}
+	slices.SortFunc(workflowPlugins, func(a, b *plugin.PluginInfo) int {
+		return cmp.Compare(a.Manifest.Name, b.Manifest.Name)
+	})
+	for _, info := range workflowPlugins {
+		if info.Process == nil {
+			continue
+		}
 
-			adapter := plugin.NewWorkflowAdapter(info.Manifest, info.Process)
+		adapter := plugin.NewWorkflowAdapter(info.Manifest, info.Process)
 
-			// Initialize adapter with plugin-specific config
-			pluginCfg := cfg.Plugins.Config[info.Manifest.Name]
-			if err := adapter.Initialize(ctx, pluginCfg); err != nil {
-				// Log warning but continue - don't fail if one plugin can't initialize
-				continue
-			}
+		// Initialize adapter with plugin-specific config
+		pluginCfg := cfg.Plugins.Config[info.Manifest.Name]
+		if err := adapter.Initialize(ctx, pluginCfg); err != nil {
+			// Log warning but continue - don't fail if one plugin can't initialize
+			continue
+		}
 
-			// Store adapter for lifecycle management
-			c.workflowAdapters = append(c.workflowAdapters, adapter)
+		// Store adapter for lifecycle management
+		c.workflowAdapters = append(c.workflowAdapters, adapter)
 
-			// Register phases with the machine builder
-			for _, phase := range adapter.BuildPhaseDefinitions() {
-				if err := builder.RegisterPhase(phase); err != nil {
-					// Log warning but continue
-					continue
-				}
+		// Register phases with the machine builder
+		for _, phase := range adapter.BuildPhaseDefinitions() {
+			if err := builder.RegisterPhase(phase); err != nil {
+				// Log warning but continue
+				continue
 			}
 		}
 
-		// Replace the default machine with the configured one
-		c.machine = builder.Build(c.eventBus)
+		// Guards and effects on built-in transitions
+		if err := adapter.RegisterTransitionHooks(builder); err != nil {
+			c.logError(fmt.Errorf("workflow plugin %s: %w", info.Manifest.Name, err))
+		}
 	}
 
-	return nil
+	return len(workflowPlugins) > 0, nil
 }
 
 // GetPluginRegistry returns the plugin registry.
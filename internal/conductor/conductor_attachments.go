@@ -0,0 +1,230 @@
+package conductor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/provider/httpclient"
+)
+
+// maxImageSize bounds a single downloaded image.
+const maxImageSize = 20 << 20
+
+// imagePatterns match image references in markdown and HTML source files;
+// the first submatch is the URL.
+var imagePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`!\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`),
+	regexp.MustCompile(`(?i)<img\b[^>]*?\bsrc\s*=\s*["']([^"']+)["']`),
+}
+
+// imageExtensions maps sniffed image types to file extensions.
+var imageExtensions = map[string]string{
+	"image/png":                ".png",
+	"image/jpeg":               ".jpg",
+	"image/gif":                ".gif",
+	"image/webp":               ".webp",
+	"image/bmp":                ".bmp",
+	"image/x-icon":             ".ico",
+	"image/vnd.microsoft.icon": ".ico",
+}
+
+// localizeSourceImages downloads the images the task's source references and
+// points the source files in the work directory at the local copies.
+func (c *Conductor) localizeSourceImages(ctx context.Context, p any, taskID string, snapshot *provider.Snapshot) {
+	files := snapshotFiles(snapshot)
+	localized := c.cacheSourceImages(ctx, p, taskID, files)
+
+	var changed []provider.SnapshotFile
+	for i, f := range localized {
+		if f.Content != files[i].Content {
+			changed = append(changed, f)
+		}
+	}
+	if err := c.writeSourceFileList(taskID, changed); err != nil {
+		slog.Warn("write localized source files", "task", taskID, "error", err)
+	}
+}
+
+// cacheSourceImages downloads the images referenced by source files into the
+// task's attachments/ directory and returns the files with those references
+// rewritten to the local copies, so agents can read them without provider
+// credentials. Images already cached are not downloaded again; images that
+// cannot be downloaded keep their remote URL. Recorded source states are
+// computed from the provider's content, so rewriting does not show up as a
+// source change.
+func (c *Conductor) cacheSourceImages(ctx context.Context, p any, taskID string, files []provider.SnapshotFile) []provider.SnapshotFile {
+	fetcher, _ := p.(provider.ImageFetcher)
+	cached := make(map[string]string) // image URL -> path relative to the work directory
+
+	out := make([]provider.SnapshotFile, len(files))
+	for i, f := range files {
+		out[i] = f
+		if !hasImageReferences(f.Path) {
+			continue
+		}
+		prefix := strings.Repeat("../", strings.Count(f.Path, "/")+1)
+		out[i].Content = rewriteImageReferences(f.Content, func(ref string) string {
+			local, ok := cached[ref]
+			if !ok {
+				var err error
+				local, err = c.cacheImage(ctx, fetcher, taskID, ref)
+				if err != nil {
+					slog.Warn("download source image", "url", ref, "error", err)
+				}
+				cached[ref] = local
+			}
+			if local == "" {
+				return ""
+			}
+
+			return prefix + local
+		})
+	}
+
+	return out
+}
+
+// cacheImage returns the local copy of an image, downloading it unless it is
+// cached. It returns "" for references that are not downloadable.
+func (c *Conductor) cacheImage(ctx context.Context, fetcher provider.ImageFetcher, taskID, ref string) (string, error) {
+	remote := strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://")
+	// Paths relative to the provider's site (e.g. GitLab uploads) need the provider to resolve them
+	if !remote && (fetcher == nil || !strings.HasPrefix(ref, "/") || strings.HasPrefix(ref, "//")) {
+		return "", nil
+	}
+
+	sum := sha256.Sum256([]byte(ref))
+	stem := "img-" + hex.EncodeToString(sum[:8])
+	if local := c.workspace.FindAttachment(taskID, stem); local != "" {
+		return local, nil
+	}
+
+	var body io.ReadCloser
+	var err error
+	if fetcher != nil {
+		body, err = fetcher.FetchImage(ctx, ref)
+	} else {
+		body, err = fetchImage(ctx, ref)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(body, maxImageSize+1))
+	if err != nil {
+		return "", fmt.Errorf("read image: %w", err)
+	}
+	if len(data) > maxImageSize {
+		return "", fmt.Errorf("image exceeds %d MB", maxImageSize>>20)
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return "", fmt.Errorf("not an image (%s)", contentType)
+	}
+
+	return c.workspace.SaveAttachment(taskID, stem+ext, data)
+}
+
+// fetchImage downloads an image anonymously, for providers without their own
+// image access.
+func fetchImage(ctx context.Context, imageURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	resp, err := httpclient.NewHTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+
+		return nil, fmt.Errorf("download failed: status %d", resp.StatusCode)
+	}
+
+	return resp.Body, nil
+}
+
+// hasImageReferences reports whether a source file is a document whose
+// image references are rewritten.
+func hasImageReferences(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".md", ".markdown", ".html", ".htm":
+		return true
+	}
+
+	return false
+}
+
+// rewriteImageReferences replaces the URL of every image reference in content
+// with rewrite(url), keeping references for which rewrite returns "".
+func rewriteImageReferences(content string, rewrite func(ref string) string) string {
+	for _, re := range imagePatterns {
+		content = re.ReplaceAllStringFunc(content, func(match string) string {
+			loc := re.FindStringSubmatchIndex(match)
+			local := rewrite(match[loc[2]:loc[3]])
+			if local == "" {
+				return match
+			}
+
+			return match[:loc[2]] + local + match[loc[3]:]
+		})
+	}
+
+	return content
+}
+
+// maxPromptImages bounds the images attached to a prompt.
+const maxPromptImages = 8
+
+// attachmentRefPattern matches references to cached images in source files.
+var attachmentRefPattern = regexp.MustCompile(`attachments/(img-[0-9a-f]{16}\.[a-z]+)`)
+
+// sourceImages returns the cached images the task's source references, in
+// order of appearance, for agents that accept attachments.
+func (c *Conductor) sourceImages(taskID, sourceContent string) []string {
+	var images []string
+	seen := make(map[string]bool)
+	for _, m := range attachmentRefPattern.FindAllStringSubmatch(sourceContent, -1) {
+		if seen[m[1]] || len(images) == maxPromptImages {
+			continue
+		}
+		seen[m[1]] = true
+		file := filepath.Join(c.workspace.WorkPath(taskID), "attachments", m[1])
+		if _, err := os.Stat(file); err == nil {
+			images = append(images, file)
+		}
+	}
+
+	return images
+}
+
+// attachedImagesPrompt tells the agent which source images are attached.
+func attachedImagesPrompt(images []string) string {
+	if len(images) == 0 {
+		return ""
+	}
+
+	names := make([]string, len(images))
+	for i, image := range images {
+		names[i] = "attachments/" + filepath.Base(image)
+	}
+
+	return "\n## Attached Images\n" +
+		"The images the task source references are attached to this message, in this order: " + strings.Join(names, ", ") + ". " +
+		"Use them to understand the reported behavior and UI, such as layouts, error messages and visual defects.\n"
+}
@@ -0,0 +1,142 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+type stubImageFetcher struct {
+	data    []byte
+	fetched []string
+}
+
+func (f *stubImageFetcher) FetchImage(_ context.Context, imageURL string) (io.ReadCloser, error) {
+	f.fetched = append(f.fetched, imageURL)
+	if strings.Contains(imageURL, "missing") {
+		return nil, errors.New("not found")
+	}
+
+	return io.NopCloser(strings.NewReader(string(f.data))), nil
+}
+
+func pngBytes(t *testing.T) []byte {
+	t.Helper()
+	var sb strings.Builder
+	if err := png.Encode(&sb, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+
+	return []byte(sb.String())
+}
+
+func TestCacheSourceImages(t *testing.T) {
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	c := &Conductor{workspace: ws}
+	fetcher := &stubImageFetcher{data: pngBytes(t)}
+
+	files := []provider.SnapshotFile{
+		{Path: "issue.md", Content: "# Bug\n\n![screen](https://tracker.example/a.png \"Screen\")\n" +
+			"<img src=\"/uploads/b.png\">\n![gone](https://tracker.example/missing.png)\n![local](./diagram.png)\n"},
+		{Path: "notes/extra.md", Content: "Again: ![screen](https://tracker.example/a.png)\n"},
+		{Path: "data.json", Content: `{"image": "![x](https://tracker.example/a.png)"}`},
+	}
+
+	got := c.cacheSourceImages(context.Background(), fetcher, "task-1", files)
+
+	issue := got[0].Content
+	for _, want := range []string{"](../attachments/img-", ".png \"Screen\")", "<img src=\"../attachments/img-",
+		"![gone](https://tracker.example/missing.png)", "![local](./diagram.png)"} {
+		if !strings.Contains(issue, want) {
+			t.Errorf("issue.md missing %q:\n%s", want, issue)
+		}
+	}
+	if !strings.Contains(got[1].Content, "](../../attachments/img-") {
+		t.Errorf("nested file = %q, want a path relative to its directory", got[1].Content)
+	}
+	if got[2].Content != files[2].Content {
+		t.Errorf("non-document file rewritten: %q", got[2].Content)
+	}
+	if len(fetcher.fetched) != 3 {
+		t.Errorf("fetched = %v, want each remote image once", fetcher.fetched)
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(ws.WorkPath("task-1"), "attachments"))
+	if len(entries) != 2 {
+		t.Errorf("attachments = %d files, want 2", len(entries))
+	}
+
+	// Cached images are not downloaded again
+	fetcher.fetched = nil
+	c.cacheSourceImages(context.Background(), fetcher, "task-1", files[:1])
+	if len(fetcher.fetched) != 1 || !strings.Contains(fetcher.fetched[0], "missing") {
+		t.Errorf("second run fetched = %v, want only the failed image", fetcher.fetched)
+	}
+}
+
+func TestCacheSourceImages_Anonymous(t *testing.T) {
+	data := pngBytes(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/page.html" {
+			_, _ = w.Write([]byte("<html></html>"))
+
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	c := &Conductor{workspace: ws}
+
+	content := "![a](" + server.URL + "/a.png) ![b](" + server.URL + "/page.html) ![c](/relative.png)"
+	got := c.cacheSourceImages(context.Background(), nil, "task-1", []provider.SnapshotFile{{Path: "task.md", Content: content}})
+
+	want := []string{"![a](../attachments/img-", "![b](" + server.URL + "/page.html)", "![c](/relative.png)"}
+	for _, w := range want {
+		if !strings.Contains(got[0].Content, w) {
+			t.Errorf("content missing %q: %s", w, got[0].Content)
+		}
+	}
+}
+
+func TestSourceImages(t *testing.T) {
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	c := &Conductor{workspace: ws}
+	first, _ := ws.SaveAttachment("task-1", "img-00000000000000aa.png", pngBytes(t))
+	second, _ := ws.SaveAttachment("task-1", "img-00000000000000bb.jpg", pngBytes(t))
+
+	source := "![b](../" + second + ")\n![a](../" + first + ")\n![again](../" + second + ")\n![gone](../attachments/img-00000000000000cc.png)\n"
+	images := c.sourceImages("task-1", source)
+	if len(images) != 2 || filepath.Base(images[0]) != "img-00000000000000bb.jpg" || filepath.Base(images[1]) != "img-00000000000000aa.png" {
+		t.Fatalf("sourceImages() = %v, want existing images in order of appearance", images)
+	}
+
+	prompt := attachedImagesPrompt(images)
+	if !strings.Contains(prompt, "## Attached Images") || !strings.Contains(prompt, "attachments/img-00000000000000bb.jpg, attachments/img-00000000000000aa.png") {
+		t.Errorf("attachedImagesPrompt() = %q", prompt)
+	}
+	if attachedImagesPrompt(nil) != "" {
+		t.Error("attachedImagesPrompt(nil) should be empty")
+	}
+}
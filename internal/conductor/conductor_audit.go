@@ -0,0 +1,132 @@
+package conductor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+// maxPendingAudit bounds the entries kept for a task not registered yet.
+const maxPendingAudit = 100
+
+// readOnlyGitCommands are git subcommands that never change a repository
+// or its remotes, and are left out of the audit log.
+var readOnlyGitCommands = []string{
+	"status", "diff", "log", "show", "rev-parse", "rev-list", "ls-files", "ls-tree", "merge-base",
+	"cat-file", "for-each-ref", "show-ref", "describe", "blame", "grep", "diff-tree", "check-ignore",
+	"name-rev", "symbolic-ref", "var", "version",
+}
+
+// audit records an operation in the active task's audit log. Operations run
+// before the task is registered, such as creating its branch, are kept until
+// it is; tasks without a work directory are not audited.
+func (c *Conductor) audit(kind, target, detail string, err error) {
+	if c.workspace == nil {
+		return
+	}
+
+	entry := storage.AuditEntry{Kind: kind, Target: target, Detail: detail}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	c.auditMu.Lock()
+	defer c.auditMu.Unlock()
+
+	if c.activeTask == nil {
+		if len(c.auditPending) < maxPendingAudit {
+			c.auditPending = append(c.auditPending, entry)
+		}
+
+		return
+	}
+	if !c.workspace.WorkExists(c.activeTask.ID) {
+		return
+	}
+	if err := c.workspace.AppendAudit(c.activeTask.ID, entry); err != nil {
+		c.logError(fmt.Errorf("audit log: %w", err))
+	}
+}
+
+// flushAudit writes the entries kept before a task was registered to its
+// audit log.
+func (c *Conductor) flushAudit(taskID string) {
+	c.auditMu.Lock()
+	defer c.auditMu.Unlock()
+
+	for _, entry := range c.auditPending {
+		if err := c.workspace.AppendAudit(taskID, entry); err != nil {
+			c.logError(fmt.Errorf("audit log: %w", err))
+
+			break
+		}
+	}
+	c.auditPending = nil
+}
+
+// auditFile records a file written or deleted from agent output; writes
+// record the size and hash of the content.
+func (c *Conductor) auditFile(kind, path, content string, err error) {
+	detail := ""
+	if kind == storage.AuditFileWrite {
+		sum := sha256.Sum256([]byte(content))
+		detail = fmt.Sprintf("%d bytes, sha256:%s", len(content), hex.EncodeToString(sum[:]))
+	}
+	c.audit(kind, path, detail, err)
+}
+
+// auditedGit returns git with every command that changes the repository
+// recorded in the audit log. repo names an attached repository ("" for the
+// primary one).
+func (c *Conductor) auditedGit(git *vcs.Git, repo string) *vcs.Git {
+	detail := ""
+	if repo != "" {
+		detail = "workspace " + repo
+	}
+
+	return git.WithObserver(func(args []string, err error) {
+		if gitMutates(args) {
+			c.audit(storage.AuditGit, "git "+strings.Join(args, " "), detail, err)
+		}
+	})
+}
+
+// gitMutates reports whether git arguments may change the repository or a
+// remote. Listing forms of otherwise changing commands, such as
+// "branch --show-current", do not.
+func gitMutates(args []string) bool {
+	// Skip global options such as "-c gpg.format=ssh"
+	i := 0
+	for i < len(args) && strings.HasPrefix(args[i], "-") {
+		if args[i] == "-c" || args[i] == "-C" {
+			i++
+		}
+		i++
+	}
+	if i >= len(args) {
+		return false
+	}
+	cmd, rest := args[i], args[i+1:]
+
+	switch {
+	case slices.Contains(readOnlyGitCommands, cmd):
+		return false
+	case cmd == "config":
+		return !slices.ContainsFunc(rest, func(a string) bool { return strings.HasPrefix(a, "--get") || a == "--list" || a == "-l" })
+	case cmd == "branch":
+		return len(rest) > 0 && !slices.ContainsFunc(rest, func(a string) bool {
+			return a == "--show-current" || a == "--list" || a == "-a" || a == "-r" || a == "--format" || strings.HasPrefix(a, "--format=") || a == "--contains" || a == "--merged"
+		})
+	case cmd == "stash":
+		return len(rest) == 0 || rest[0] != "list" && rest[0] != "show"
+	case cmd == "worktree" || cmd == "remote":
+		return len(rest) > 0 && rest[0] != "list" && rest[0] != "get-url" && rest[0] != "-v"
+	}
+
+	return true
+}
@@ -0,0 +1,108 @@
+package conductor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/provider/file"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestGitMutates(t *testing.T) {
+	tests := map[string]bool{
+		"status --porcelain":                       false,
+		"rev-parse --abbrev-ref HEAD":              false,
+		"config --get user.name":                   false,
+		"branch --show-current":                    false,
+		"branch":                                   false,
+		"worktree list --porcelain":                false,
+		"stash list":                               false,
+		"-c gpg.format=ssh log --oneline":          false,
+		"checkout -b task/abc":                     true,
+		"-c gpg.format=ssh commit -S -m Implement": true,
+		"branch -D task/abc":                       true,
+		"config user.name Test":                    true,
+		"stash":                                    true,
+		"worktree add ../wt task/abc":              true,
+		"push origin task/abc":                     true,
+	}
+	for args, want := range tests {
+		if got := gitMutates(strings.Fields(args)); got != want {
+			t.Errorf("gitMutates(%q) = %v, want %v", args, got, want)
+		}
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	initGitRepo(t, tmpDir)
+	if err := os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte(".mehrhof/\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runGitCmd(ctx, tmpDir, "add", ".gitignore"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runGitCmd(ctx, tmpDir, "commit", "-m", "ignore workspace"); err != nil {
+		t.Fatal(err)
+	}
+	taskPath := filepath.Join(t.TempDir(), "task.md")
+	if err := os.WriteFile(taskPath, []byte("# Audit me\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := New(WithWorkDir(tmpDir), WithCreateBranch(true), WithAgent("mock"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	file.Register(c.GetProviderRegistry())
+	if err := c.GetAgentRegistry().Register(&mockAgent{name: "mock"}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := c.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if err := c.Start(ctx, "file:"+taskPath); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if err := applyFiles(ctx, c, []agent.FileChange{
+		{Path: "main.go", Operation: agent.FileOpCreate, Content: "package main\n"},
+		{Path: "README.md", Operation: agent.FileOpDelete},
+	}); err != nil {
+		t.Fatalf("applyFiles: %v", err)
+	}
+
+	entries, err := c.workspace.ReadAuditLog(c.activeTask.ID)
+	if err != nil {
+		t.Fatalf("ReadAuditLog: %v", err)
+	}
+	if err := storage.VerifyAuditChain(entries); err != nil {
+		t.Errorf("VerifyAuditChain() = %v", err)
+	}
+
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Kind+" "+e.Target)
+	}
+	log := strings.Join(got, "\n")
+	for _, want := range []string{"provider file: fetch", "git checkout -b ", "file_write main.go", "file_delete README.md"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("audit log missing %q:\n%s", want, log)
+		}
+	}
+	if strings.Contains(log, "git status") || strings.Contains(log, "git rev-parse") {
+		t.Errorf("audit log records read-only git commands:\n%s", log)
+	}
+	if last := entries[len(entries)-2]; !strings.Contains(last.Detail, "13 bytes, sha256:") {
+		t.Errorf("file write detail = %q", last.Detail)
+	}
+}
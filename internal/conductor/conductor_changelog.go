@@ -0,0 +1,122 @@
+package conductor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/changelog"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// changelogOnFinish reports whether finishing writes a changelog entry: the
+// finish option when given, otherwise workflow.changelog.enabled.
+func (c *Conductor) changelogOnFinish(opts FinishOptions) bool {
+	if opts.Changelog != nil {
+		return *opts.Changelog
+	}
+	cfg, err := c.workspace.LoadConfig()
+
+	return err == nil && cfg.Workflow.Changelog.Enabled
+}
+
+// writeChangelog asks the summary agent for a changelog entry describing the
+// task and writes it as configured by workflow.changelog. With git, the entry
+// is committed on the task branch, so the merge or pull request includes it.
+// It returns the path written, relative to the repository root.
+func (c *Conductor) writeChangelog(ctx context.Context) (string, error) {
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil {
+		return "", fmt.Errorf("load config: %w", err)
+	}
+	settings := cfg.Workflow.Changelog
+	format := settings.Format
+	if format == "" {
+		format = changelog.FormatKeepAChangelog
+	}
+
+	stepAgent, err := c.GetAgentForStep(ctx, workflow.StepCheckpointing)
+	if err != nil {
+		return "", fmt.Errorf("get agent: %w", err)
+	}
+	writer := c.summaryAgent(workflow.StepCheckpointing, stepAgent)
+
+	specs, err := c.loadSpecificationsForPR()
+	if err != nil {
+		c.logError(fmt.Errorf("load specifications for changelog: %w", err))
+	}
+	response, err := writer.Run(ctx, buildChangelogPrompt(c.taskTitle(), specs, c.getDiffStats(ctx)))
+	if err != nil {
+		return "", fmt.Errorf("agent changelog entry: %w", err)
+	}
+	c.recordUsage(c.activeTask.ID, "changelog", workflow.StepCheckpointing, writer, response.Usage)
+
+	text := response.Summary
+	if strings.TrimSpace(text) == "" && len(response.Messages) > 0 {
+		text = response.Messages[len(response.Messages)-1]
+	}
+	entry, err := changelog.ParseEntry(text)
+	if err != nil {
+		return "", err
+	}
+
+	name := c.activeTask.ID
+	if c.taskWork != nil && c.taskWork.Metadata.ExternalKey != "" {
+		name = c.taskWork.Metadata.ExternalKey
+	}
+	path, err := changelog.Write(c.repoRoot(), format, settings.Path, name, entry)
+	if err != nil {
+		return "", err
+	}
+
+	if c.git != nil && c.activeTask.UseGit && c.activeTask.Branch != "" {
+		if err := c.git.Add(ctx, path); err != nil {
+			return path, fmt.Errorf("stage changelog: %w", err)
+		}
+		prefix := fmt.Sprintf("[%s]", c.activeTask.ID)
+		if c.taskWork != nil && c.taskWork.Git.CommitPrefix != "" {
+			prefix = c.taskWork.Git.CommitPrefix
+		}
+		if _, err := c.git.Commit(ctx, prefix+" Add changelog entry"); err != nil {
+			return path, fmt.Errorf("commit changelog: %w", err)
+		}
+	}
+
+	return path, nil
+}
+
+// taskTitle returns the title of the active task, or its ID without one.
+func (c *Conductor) taskTitle() string {
+	if c.taskWork != nil && c.taskWork.Metadata.Title != "" {
+		return c.taskWork.Metadata.Title
+	}
+
+	return c.activeTask.ID
+}
+
+// buildChangelogPrompt asks an agent for a one-line changelog entry.
+func buildChangelogPrompt(title string, specs []*storage.Specification, diffStat string) string {
+	var sb strings.Builder
+	sb.WriteString("Write the changelog entry for the finished software task below.\n\n")
+	fmt.Fprintf(&sb, "## Task\n%s\n\n", title)
+	if len(specs) > 0 {
+		sb.WriteString("## Specifications\n")
+		for _, spec := range specs {
+			content := spec.Content
+			if len(content) > 1000 {
+				content = content[:1000] + "..."
+			}
+			sb.WriteString(content + "\n\n")
+		}
+	}
+	if diffStat != "" {
+		fmt.Fprintf(&sb, "## Changed Files\n```\n%s\n```\n\n", diffStat)
+	}
+	sb.WriteString("## Format\n")
+	fmt.Fprintf(&sb, "- Reply with a single line `Category: entry`, with Category one of %s.\n", strings.Join(changelog.Categories, ", "))
+	sb.WriteString("- Describe the change for users of the project, in the imperative or past tense, in under 100 characters, without a trailing period.\n")
+	sb.WriteString("- Reply with the line only, no quotes or code fences. Do not change any files.\n")
+
+	return sb.String()
+}
@@ -0,0 +1,109 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/changelog"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestWriteChangelog(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	tests := []struct {
+		name     string
+		format   string
+		reply    string
+		wantPath string
+		wantText string
+		wantErr  error
+	}{
+		{
+			name:     "keep a changelog",
+			reply:    "Added: Cache for repeated lookups",
+			wantPath: "CHANGELOG.md",
+			wantText: "### Added\n- Cache for repeated lookups\n",
+		},
+		{
+			name:     "towncrier",
+			format:   changelog.FormatTowncrier,
+			reply:    "- Fixed: Stale lookups after updates",
+			wantPath: filepath.Join("changelog.d", "FEATURE-1.bugfix.md"),
+			wantText: "Stale lookups after updates\n",
+		},
+		{
+			name:    "unparsable reply",
+			reply:   "I added a cache.",
+			wantErr: changelog.ErrInvalidEntry,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			tmpDir := t.TempDir()
+			initGitRepo(t, tmpDir)
+
+			a := &messageAgent{mockAgent: mockAgent{name: "writer"}, reply: tt.reply}
+			c, err := New(WithWorkDir(tmpDir), WithAgent("writer"))
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			if err := c.GetAgentRegistry().Register(a); err != nil {
+				t.Fatalf("Register agent: %v", err)
+			}
+			if err := c.Initialize(ctx); err != nil {
+				t.Fatalf("Initialize: %v", err)
+			}
+			cfg, err := c.workspace.LoadConfig()
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			cfg.Workflow.Changelog = storage.ChangelogSettings{Enabled: true, Format: tt.format}
+			if err := c.workspace.SaveConfig(cfg); err != nil {
+				t.Fatalf("SaveConfig: %v", err)
+			}
+			branch, _ := c.git.CurrentBranch(ctx)
+			c.activeTask = &storage.ActiveTask{ID: "cl", Branch: branch, UseGit: true}
+			c.taskWork = &storage.TaskWork{
+				Metadata: storage.WorkMetadata{Title: "Cache lookups", ExternalKey: "FEATURE-1"},
+				Git:      storage.GitInfo{CommitPrefix: "[FEATURE-1]"},
+			}
+
+			if !c.changelogOnFinish(FinishOptions{}) || c.changelogOnFinish(FinishOptions{Changelog: BoolPtr(false)}) {
+				t.Error("changelogOnFinish should follow workflow.changelog.enabled unless overridden")
+			}
+
+			path, err := c.writeChangelog(ctx)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("writeChangelog error = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("writeChangelog: %v", err)
+			}
+			if path != tt.wantPath {
+				t.Errorf("path = %q, want %q", path, tt.wantPath)
+			}
+			if data, _ := os.ReadFile(filepath.Join(tmpDir, path)); !strings.Contains(string(data), tt.wantText) {
+				t.Errorf("%s = %q, want it to contain %q", path, data, tt.wantText)
+			}
+			if msg, _ := c.git.GetCommitMessage(ctx, "HEAD"); msg != "[FEATURE-1] Add changelog entry" {
+				t.Errorf("HEAD commit = %q, want the changelog entry committed", msg)
+			}
+			if len(a.prompts) != 1 || !strings.Contains(a.prompts[0], "Cache lookups") {
+				t.Errorf("prompts = %q, want one about the task", a.prompts)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package conductor
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/diffsummary"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// summarizeFileChanges builds structural summaries of agent file changes.
+// It must run before the changes are applied so the current content of each
+// file is still on disk.
+func summarizeFileChanges(root string, files []agent.FileChange) []storage.FileSummary {
+	summaries := make([]storage.FileSummary, 0, len(files))
+	for _, fc := range files {
+		var before string
+		if data, err := os.ReadFile(filepath.Join(root, fc.Path)); err == nil {
+			before = string(data)
+		}
+
+		after := fc.Content
+		if fc.Operation == agent.FileOpDelete || fc.Content == DeleteFileSentinel {
+			after = ""
+		}
+
+		summaries = append(summaries, diffsummary.Summarize(fc.Path, before, after))
+	}
+
+	return summaries
+}
+
+// recordChangeSummaries adds applied file change summaries to the current session.
+func (c *Conductor) recordChangeSummaries(summaries []storage.FileSummary) {
+	if c.currentSession == nil || len(summaries) == 0 {
+		return
+	}
+
+	c.currentSession.Changes = diffsummary.Merge(c.currentSession.Changes, summaries)
+}
+
+// loadChangeSummaries merges the change summaries recorded across all of a
+// task's sessions, oldest first.
+func (c *Conductor) loadChangeSummaries(taskID string) []storage.FileSummary {
+	sessions, err := c.workspace.ListSessions(taskID)
+	if err != nil {
+		c.logError(fmt.Errorf("list sessions: %w", err))
+
+		return nil
+	}
+
+	batches := make([][]storage.FileSummary, 0, len(sessions))
+	for _, session := range sessions {
+		batches = append(batches, session.Changes)
+	}
+
+	return diffsummary.Merge(batches...)
+}
+
+// formatStructuralChanges renders change summaries as markdown for PR bodies:
+// the symbols touched in each file, followed by exported API changes grouped
+// by package directory.
+func formatStructuralChanges(summaries []storage.FileSummary) string {
+	var files, api strings.Builder
+
+	for _, s := range summaries {
+		var details []string
+		for _, group := range []struct {
+			label   string
+			symbols []string
+		}{
+			{"added", s.Added},
+			{"modified", s.Modified},
+			{"removed", s.Removed},
+		} {
+			if len(group.symbols) > 0 {
+				details = append(details, group.label+" "+codeList(group.symbols))
+			}
+		}
+		if len(details) == 0 {
+			continue
+		}
+		fmt.Fprintf(&files, "- `%s`: %s\n", s.Path, strings.Join(details, "; "))
+
+		for _, change := range s.API {
+			pkg := path.Dir(s.Path)
+			switch change.Change {
+			case diffsummary.APIAdded:
+				fmt.Fprintf(&api, "- `%s`: added `%s`\n", pkg, oneLine(change.After))
+			case diffsummary.APIRemoved:
+				fmt.Fprintf(&api, "- `%s`: removed `%s`\n", pkg, oneLine(change.Before))
+			default:
+				fmt.Fprintf(&api, "- `%s`: `%s` → `%s`\n", pkg, oneLine(change.Before), oneLine(change.After))
+			}
+		}
+	}
+
+	if files.Len() == 0 {
+		return ""
+	}
+
+	out := "\n## Structural Changes\n" + files.String()
+	if api.Len() > 0 {
+		out += "\n### Exported API Changes\n" + api.String()
+	}
+
+	return out
+}
+
+func codeList(symbols []string) string {
+	quoted := make([]string, len(symbols))
+	for i, s := range symbols {
+		quoted[i] = "`" + s + "`"
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// oneLine collapses a multi-line declaration for inline code spans.
+func oneLine(decl string) string {
+	return strings.Join(strings.Fields(decl), " ")
+}
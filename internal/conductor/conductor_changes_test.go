@@ -0,0 +1,41 @@
+package conductor
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+)
+
+func TestSummarizeFileChanges(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "util.go"), []byte("package util\n\nfunc Old() {}\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	got := summarizeFileChanges(root, []agent.FileChange{
+		{Path: "util.go", Operation: agent.FileOpUpdate, Content: "package util\n\nfunc New() {}\n"},
+		{Path: "gone.go", Content: DeleteFileSentinel},
+	})
+
+	if len(got) != 2 {
+		t.Fatalf("summarizeFileChanges() = %d summaries, want 2", len(got))
+	}
+	if !slices.Equal(got[0].Added, []string{"func New"}) || !slices.Equal(got[0].Removed, []string{"func Old"}) {
+		t.Errorf("util.go summary = %+v", got[0])
+	}
+	if len(got[0].API) != 2 {
+		t.Errorf("util.go API changes = %+v, want New added and Old removed", got[0].API)
+	}
+	if got[1].Added != nil || got[1].Removed != nil {
+		t.Errorf("gone.go summary = %+v, want no symbols for a missing file", got[1])
+	}
+}
+
+func TestFormatStructuralChanges_Empty(t *testing.T) {
+	if got := formatStructuralChanges(nil); got != "" {
+		t.Errorf("formatStructuralChanges(nil) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,73 @@
+package conductor
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strings"
+)
+
+// trackAgentFile records a file an agent changed, for checkpoints limited to
+// the agent's files.
+func (c *Conductor) trackAgentFile(file string) {
+	if c.agentFiles == nil {
+		c.agentFiles = make(map[string]bool)
+	}
+	c.agentFiles[file] = true
+}
+
+// checkpointPaths returns the changed paths the next checkpoint commits under
+// git.checkpoint_agent_files, git.checkpoint_include and
+// git.checkpoint_exclude. filtered is false when none is set and the
+// checkpoint commits all changes.
+func (c *Conductor) checkpointPaths(ctx context.Context) ([]string, bool, error) {
+	if c.workspace == nil {
+		return nil, false, nil
+	}
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil {
+		return nil, false, nil //nolint:nilerr // Without config, checkpoints commit everything as before
+	}
+	git := cfg.Git
+	if !git.CheckpointAgentFiles && len(git.CheckpointInclude) == 0 && len(git.CheckpointExclude) == 0 {
+		return nil, false, nil
+	}
+
+	changed, err := c.git.ChangedFiles(ctx)
+	if err != nil {
+		return nil, true, err
+	}
+
+	var paths []string
+	for _, file := range changed {
+		switch {
+		case matchesAnyGlob(git.CheckpointExclude, file):
+		case len(git.CheckpointInclude) > 0 && !matchesAnyGlob(git.CheckpointInclude, file):
+		case git.CheckpointAgentFiles && !c.agentFiles[file]:
+		default:
+			paths = append(paths, file)
+		}
+	}
+
+	return paths, true, nil
+}
+
+// matchesAnyGlob reports whether a repo-relative path matches one of the
+// patterns. A pattern without a slash also matches the file name alone, and
+// a trailing "/**" matches everything below a directory.
+func matchesAnyGlob(patterns []string, file string) bool {
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+			return file == dir || strings.HasPrefix(file, dir+"/")
+		}
+		if ok, _ := path.Match(pattern, file); ok {
+			return true
+		}
+		if strings.Contains(pattern, "/") {
+			return false
+		}
+		ok, _ := path.Match(pattern, path.Base(file))
+
+		return ok
+	})
+}
@@ -0,0 +1,136 @@
+package conductor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestCreateCheckpointIfNeeded_Paths(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	tests := []struct {
+		name          string
+		git           storage.GitSettings
+		wantCommitted string // Comma-separated; "" means no checkpoint
+	}{
+		{
+			name:          "all changes by default",
+			wantCommitted: "experiment.txt,notes.txt.swp,src/app.go",
+		},
+		{
+			name:          "agent files",
+			git:           storage.GitSettings{CheckpointAgentFiles: true},
+			wantCommitted: "src/app.go",
+		},
+		{
+			name:          "exclude",
+			git:           storage.GitSettings{CheckpointExclude: []string{"*.swp"}},
+			wantCommitted: "experiment.txt,src/app.go",
+		},
+		{
+			name:          "include",
+			git:           storage.GitSettings{CheckpointInclude: []string{"src/**"}},
+			wantCommitted: "src/app.go",
+		},
+		{
+			name: "nothing selected",
+			git:  storage.GitSettings{CheckpointInclude: []string{"docs/**"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			tmpDir := t.TempDir()
+			initGitRepo(t, tmpDir)
+
+			c, err := New(WithWorkDir(tmpDir), WithAgent("mock"))
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			if err := c.GetAgentRegistry().Register(&mockAgent{name: "mock"}); err != nil {
+				t.Fatalf("Register agent: %v", err)
+			}
+			if err := c.Initialize(ctx); err != nil {
+				t.Fatalf("Initialize: %v", err)
+			}
+			cfg, err := c.workspace.LoadConfig()
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			cfg.Git.CheckpointAgentFiles = tt.git.CheckpointAgentFiles
+			cfg.Git.CheckpointInclude = tt.git.CheckpointInclude
+			cfg.Git.CheckpointExclude = tt.git.CheckpointExclude
+			if err := c.workspace.SaveConfig(cfg); err != nil {
+				t.Fatalf("SaveConfig: %v", err)
+			}
+			c.activeTask = &storage.ActiveTask{ID: "paths", UseGit: true}
+			c.taskWork = &storage.TaskWork{}
+			// Keep the workspace's own files out of what is compared
+			if err := os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte(".mehrhof/\n.gitignore\n"), 0o644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+
+			if err := applyFiles(ctx, c, []agent.FileChange{{Path: "src/app.go", Operation: agent.FileOpCreate, Content: "package app\n"}}); err != nil {
+				t.Fatalf("applyFiles: %v", err)
+			}
+			for _, name := range []string{"notes.txt.swp", "experiment.txt"} {
+				if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("local"), 0o644); err != nil {
+					t.Fatalf("WriteFile: %v", err)
+				}
+			}
+
+			event := c.createCheckpointIfNeeded(ctx, "paths", "Implement task paths")
+			if tt.wantCommitted == "" {
+				if event != nil {
+					t.Fatalf("created checkpoint %v, want none", event.Data)
+				}
+
+				return
+			}
+			if event == nil {
+				t.Fatal("createCheckpointIfNeeded created no checkpoint")
+			}
+
+			committed, err := c.git.Log(ctx, "-1", "--name-only", "--format=")
+			if err != nil {
+				t.Fatalf("Log: %v", err)
+			}
+			if got := strings.Join(strings.Fields(committed), ","); got != tt.wantCommitted {
+				t.Errorf("checkpoint committed %s, want %s", got, tt.wantCommitted)
+			}
+			if c.agentFiles != nil {
+				t.Errorf("agentFiles = %v after a checkpoint, want them cleared", c.agentFiles)
+			}
+		})
+	}
+}
+
+func TestMatchesAnyGlob(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		file     string
+		want     bool
+	}{
+		{patterns: []string{"*.swp"}, file: "src/.app.go.swp", want: true},
+		{patterns: []string{"src/*.go"}, file: "src/app.go", want: true},
+		{patterns: []string{"src/*.go"}, file: "lib/src/app.go", want: false},
+		{patterns: []string{"build/**"}, file: "build/out/app", want: true},
+		{patterns: []string{"build/**"}, file: "buildx/app", want: false},
+		{patterns: nil, file: "app.go", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := matchesAnyGlob(tt.patterns, tt.file); got != tt.want {
+			t.Errorf("matchesAnyGlob(%v, %q) = %v, want %v", tt.patterns, tt.file, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package conductor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// CIProviderGitHubActions identifies a GitHub Actions run.
+const CIProviderGitHubActions = "github-actions"
+
+// ErrNoTriggeringIssue is returned when a CI event names no issue to work on.
+var ErrNoTriggeringIssue = errors.New("no triggering issue in CI event")
+
+// CIEnvironment describes the CI run mehrhof executes in.
+type CIEnvironment struct {
+	Provider    string // CIProviderGitHubActions
+	Repository  string // owner/repo the workflow runs for
+	EventName   string // Triggering event (issues, issue_comment, workflow_dispatch, ...)
+	Action      string // Event activity type (labeled, opened, ...)
+	Issue       int    // Issue number from the event payload (0 = none)
+	IssueTitle  string // Issue title from the event payload
+	Label       string // Label added by a labeled event
+	RunURL      string // Link to the workflow run
+	SummaryPath string // File the job summary is appended to
+	HasToken    bool   // A GitHub token is available in the environment
+}
+
+// githubEvent is the part of a GitHub Actions event payload mehrhof reads.
+type githubEvent struct {
+	Action string `json:"action"`
+	Issue  *struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+	} `json:"issue"`
+	Label *struct {
+		Name string `json:"name"`
+	} `json:"label"`
+	Inputs map[string]any `json:"inputs"`
+}
+
+// DetectCI returns the CI environment described by getenv, or nil when not
+// running in a supported CI system. Only GitHub Actions is detected.
+func DetectCI(getenv func(string) string) (*CIEnvironment, error) {
+	if getenv("GITHUB_ACTIONS") != "true" {
+		return nil, nil //nolint:nilnil // Not in CI is not an error
+	}
+
+	env := &CIEnvironment{
+		Provider:    CIProviderGitHubActions,
+		Repository:  getenv("GITHUB_REPOSITORY"),
+		EventName:   getenv("GITHUB_EVENT_NAME"),
+		SummaryPath: getenv("GITHUB_STEP_SUMMARY"),
+		HasToken:    getenv("MEHR_GITHUB_TOKEN") != "" || getenv("GITHUB_TOKEN") != "",
+	}
+	if server, runID := getenv("GITHUB_SERVER_URL"), getenv("GITHUB_RUN_ID"); server != "" && runID != "" && env.Repository != "" {
+		env.RunURL = fmt.Sprintf("%s/%s/actions/runs/%s", server, env.Repository, runID)
+	}
+
+	path := getenv("GITHUB_EVENT_PATH")
+	if path == "" {
+		return env, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read GitHub event payload: %w", err)
+	}
+	var event githubEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("parse GitHub event payload: %w", err)
+	}
+
+	env.Action = event.Action
+	if event.Issue != nil {
+		env.Issue = event.Issue.Number
+		env.IssueTitle = event.Issue.Title
+	}
+	if event.Label != nil {
+		env.Label = event.Label.Name
+	}
+	// workflow_dispatch runs pass the issue as an input
+	if env.Issue == 0 {
+		env.Issue = issueInput(event.Inputs["issue"])
+	}
+
+	return env, nil
+}
+
+// issueInput reads an issue number from a workflow_dispatch input.
+func issueInput(v any) int {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+
+	var n int
+	if _, err := fmt.Sscanf(strings.TrimPrefix(strings.TrimSpace(s), "#"), "%d", &n); err != nil {
+		return 0
+	}
+
+	return n
+}
+
+// Reference returns the task reference for the triggering issue.
+func (e *CIEnvironment) Reference() (string, error) {
+	if e.Issue == 0 {
+		return "", fmt.Errorf("%w (event %q)", ErrNoTriggeringIssue, e.EventName)
+	}
+	if e.Repository == "" {
+		return fmt.Sprintf("github:%d", e.Issue), nil
+	}
+
+	return fmt.Sprintf("github:%s#%d", e.Repository, e.Issue), nil
+}
+
+// Triggered reports whether the event should start a run for label. Label
+// events only trigger for the configured label, so one workflow listening
+// on "issues: labeled" ignores unrelated labels; other events always do.
+func (e *CIEnvironment) Triggered(label string) bool {
+	if label == "" || e.Action != "labeled" {
+		return true
+	}
+
+	return strings.EqualFold(e.Label, label)
+}
+
+// WriteSummary appends markdown to the job summary. It is a no-op when the
+// CI system provides no summary file.
+func (e *CIEnvironment) WriteSummary(markdown string) error {
+	if e.SummaryPath == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(e.SummaryPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open job summary: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.WriteString(markdown); err != nil {
+		return fmt.Errorf("write job summary: %w", err)
+	}
+
+	return nil
+}
+
+// JobSummary renders the markdown job summary of an auto run.
+func (e *CIEnvironment) JobSummary(reference string, result *AutoResult) string {
+	var sb strings.Builder
+
+	status := "completed"
+	if result.Error != nil {
+		status = "failed at " + result.FailedAt
+	}
+	sb.WriteString("## Mehrhof\n\n")
+	title := reference
+	if e.IssueTitle != "" {
+		title = fmt.Sprintf("#%d %s", e.Issue, e.IssueTitle)
+	}
+	fmt.Fprintf(&sb, "**%s**: %s\n\n", title, status)
+
+	sb.WriteString("| Phase | Result |\n|---|---|\n")
+	fmt.Fprintf(&sb, "| Planning | %s |\n", summaryMark(result.PlanningDone))
+	fmt.Fprintf(&sb, "| Implementation | %s |\n", summaryMark(result.ImplementDone))
+	fmt.Fprintf(&sb, "| Quality | %s (%d attempt(s)) |\n", summaryMark(result.QualityPassed), result.QualityAttempts)
+	fmt.Fprintf(&sb, "| Review | %s |\n", summaryMark(result.ReviewDone))
+	fmt.Fprintf(&sb, "| Pull request | %s |\n", summaryMark(result.PullRequestURL != ""))
+	sb.WriteString("\n")
+
+	if result.PullRequestURL != "" {
+		fmt.Fprintf(&sb, "Pull request: %s\n\n", result.PullRequestURL)
+	}
+	fmt.Fprintf(&sb, "Agent runs: %d, cost: $%.2f\n", result.Iterations, result.CostUSD)
+	if result.Question != "" {
+		fmt.Fprintf(&sb, "\nThe agent asked: %s\n", result.Question)
+	}
+	if result.Error != nil {
+		fmt.Fprintf(&sb, "\n```\n%v\n```\n", result.Error)
+	}
+
+	return sb.String()
+}
+
+// summaryMark renders a phase outcome for the job summary.
+func summaryMark(done bool) string {
+	if done {
+		return "done"
+	}
+
+	return "-"
+}
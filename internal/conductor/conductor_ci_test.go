@@ -0,0 +1,134 @@
+package conductor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func ciEnv(t *testing.T, payload string, vars map[string]string) func(string) string {
+	t.Helper()
+
+	env := map[string]string{
+		"GITHUB_ACTIONS":    "true",
+		"GITHUB_REPOSITORY": "acme/widgets",
+		"GITHUB_EVENT_NAME": "issues",
+		"GITHUB_SERVER_URL": "https://github.com",
+		"GITHUB_RUN_ID":     "42",
+		"GITHUB_TOKEN":      "ghs_test",
+	}
+	if payload != "" {
+		path := filepath.Join(t.TempDir(), "event.json")
+		if err := os.WriteFile(path, []byte(payload), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		env["GITHUB_EVENT_PATH"] = path
+	}
+	for k, v := range vars {
+		env[k] = v
+	}
+
+	return func(key string) string { return env[key] }
+}
+
+func TestDetectCI(t *testing.T) {
+	if env, err := DetectCI(func(string) string { return "" }); env != nil || err != nil {
+		t.Fatalf("DetectCI outside CI = %+v, %v; want nil", env, err)
+	}
+
+	env, err := DetectCI(ciEnv(t, `{"action":"labeled","issue":{"number":17,"title":"Add CSV export"},"label":{"name":"mehrhof"}}`, nil))
+	if err != nil {
+		t.Fatalf("DetectCI: %v", err)
+	}
+	if env.Provider != CIProviderGitHubActions || env.Issue != 17 || env.Label != "mehrhof" || !env.HasToken {
+		t.Errorf("DetectCI = %+v", env)
+	}
+	if env.RunURL != "https://github.com/acme/widgets/actions/runs/42" {
+		t.Errorf("RunURL = %q", env.RunURL)
+	}
+	if ref, err := env.Reference(); err != nil || ref != "github:acme/widgets#17" {
+		t.Errorf("Reference() = %q, %v", ref, err)
+	}
+
+	if !env.Triggered("MEHRHOF") || env.Triggered("bug") || !env.Triggered("") {
+		t.Error("Triggered should match the added label case-insensitively")
+	}
+}
+
+func TestDetectCI_WorkflowDispatch(t *testing.T) {
+	env, err := DetectCI(ciEnv(t, `{"inputs":{"issue":"#23"}}`, map[string]string{"GITHUB_EVENT_NAME": "workflow_dispatch", "GITHUB_TOKEN": ""}))
+	if err != nil {
+		t.Fatalf("DetectCI: %v", err)
+	}
+	if env.Issue != 23 || env.HasToken {
+		t.Errorf("DetectCI = %+v", env)
+	}
+	if !env.Triggered("mehrhof") {
+		t.Error("non-label events should always trigger")
+	}
+
+	env.Issue = 0
+	if _, err := env.Reference(); !errors.Is(err, ErrNoTriggeringIssue) {
+		t.Errorf("Reference() error = %v, want ErrNoTriggeringIssue", err)
+	}
+}
+
+func TestDetectCI_BadPayload(t *testing.T) {
+	if _, err := DetectCI(ciEnv(t, `{not json`, nil)); err == nil {
+		t.Error("DetectCI should fail on an unreadable payload")
+	}
+}
+
+func TestCIEnvironment_JobSummary(t *testing.T) {
+	summaryPath := filepath.Join(t.TempDir(), "summary.md")
+	env := &CIEnvironment{Issue: 17, IssueTitle: "Add CSV export", SummaryPath: summaryPath}
+
+	done := env.JobSummary("github:acme/widgets#17", &AutoResult{
+		PlanningDone: true, ImplementDone: true, QualityPassed: true, QualityAttempts: 1,
+		FinishDone: true, Iterations: 2, CostUSD: 0.42, PullRequestURL: "https://github.com/acme/widgets/pull/18",
+	})
+	for _, want := range []string{"**#17 Add CSV export**: completed", "| Pull request | done |", "Pull request: https://github.com/acme/widgets/pull/18", "cost: $0.42"} {
+		if !strings.Contains(done, want) {
+			t.Errorf("summary missing %q:\n%s", want, done)
+		}
+	}
+
+	failed := env.JobSummary("github:acme/widgets#17", &AutoResult{FailedAt: "quality", Error: errors.New("quality check failed after 3 attempts")})
+	if !strings.Contains(failed, "failed at quality") || !strings.Contains(failed, "quality check failed after 3 attempts") {
+		t.Errorf("failure summary:\n%s", failed)
+	}
+
+	if err := env.WriteSummary(done); err != nil {
+		t.Fatalf("WriteSummary: %v", err)
+	}
+	if err := env.WriteSummary(failed); err != nil {
+		t.Fatalf("WriteSummary: %v", err)
+	}
+	data, err := os.ReadFile(summaryPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != done+failed {
+		t.Error("WriteSummary should append to the summary file")
+	}
+
+	if err := (&CIEnvironment{}).WriteSummary("x"); err != nil {
+		t.Errorf("WriteSummary without a summary file: %v", err)
+	}
+}
+
+func TestWithCI(t *testing.T) {
+	opts := DefaultOptions()
+	WithCI(&CIEnvironment{Provider: CIProviderGitHubActions})(&opts)
+	if !opts.AutoMode || !opts.SkipAgentQuestions || opts.DefaultProvider != "github" {
+		t.Errorf("WithCI options = auto %v, skip %v, provider %q", opts.AutoMode, opts.SkipAgentQuestions, opts.DefaultProvider)
+	}
+
+	opts = DefaultOptions()
+	WithCI(nil)(&opts)
+	if opts.AutoMode || opts.CI != nil {
+		t.Error("WithCI(nil) should leave options untouched")
+	}
+}
@@ -0,0 +1,67 @@
+package conductor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/codemap"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// codeMapCacheFile is the repository map's file in the workspace cache.
+const codeMapCacheFile = "codemap.json"
+
+// codeMapPrompt lists the files and symbols of the repository most related to
+// the specification, so the agent builds on code that already exists. It
+// returns "" when the map is disabled or nothing relates.
+func (c *Conductor) codeMapPrompt(specContent string) string {
+	settings := storage.CodeMapSettings{}
+	if cfg, err := c.workspace.LoadConfig(); err == nil {
+		settings = cfg.Context.CodeMap
+	}
+	if settings.Disabled || strings.TrimSpace(specContent) == "" {
+		return ""
+	}
+	limit := settings.MaxFiles
+	if limit <= 0 {
+		limit = storage.DefaultCodeMapFiles
+	}
+
+	// Scoped tasks only map their scope, so the cache is kept per scope
+	scope := c.taskScope()
+	cacheFile := codeMapCacheFile
+	if scope != "" {
+		cacheFile = "codemap-" + strings.NewReplacer("/", "-", ".", "_").Replace(scope) + ".json"
+	}
+
+	m, err := codemap.Build(c.repoRoot(), codemap.Options{
+		Dir:       scope,
+		CachePath: filepath.Join(c.workspace.CacheDir(), cacheFile),
+	})
+	if err != nil {
+		c.logError(fmt.Errorf("repository map: %w", err))
+		if m == nil {
+			return ""
+		}
+	}
+
+	return buildCodeMapPrompt(m.Relevant(specContent, limit), m.TestLayout())
+}
+
+// buildCodeMapPrompt formats related files and the test layout for a prompt.
+func buildCodeMapPrompt(files []codemap.File, testLayout string) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n## Related Code\n")
+	sb.WriteString("Existing files related to the specification, with their main symbols. Reuse and extend them rather than duplicating them:\n\n")
+	sb.WriteString(codemap.Render(files))
+	if testLayout != "" {
+		fmt.Fprintf(&sb, "\nTest layout: %s.\n", testLayout)
+	}
+
+	return sb.String()
+}
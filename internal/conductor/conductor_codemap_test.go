@@ -0,0 +1,61 @@
+package conductor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/codemap"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestBuildCodeMapPrompt(t *testing.T) {
+	if got := buildCodeMapPrompt(nil, "Go tests sit next to the code in *_test.go files"); got != "" {
+		t.Errorf("buildCodeMapPrompt(no files) = %q, want empty", got)
+	}
+
+	files := []codemap.File{{Path: "internal/session/store.go", Package: "session", Symbols: []string{"type Store"}}}
+	prompt := buildCodeMapPrompt(files, "Go tests sit next to the code in *_test.go files")
+	for _, want := range []string{"## Related Code", "internal/session/store.go (package session)\n  type Store\n", "Test layout: Go tests sit next"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestCodeMapPrompt(t *testing.T) {
+	root := t.TempDir()
+	writeConventionFiles(t, root, map[string]string{
+		"internal/session/store.go":      "package session\n\ntype Store struct{}\n\nfunc (s *Store) Expire() {}\n",
+		"internal/session/store_test.go": "package session\n",
+		"internal/billing/invoice.go":    "package billing\n\ntype Invoice struct{}\n",
+	})
+
+	c, err := New(WithWorkDir(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws, err := storage.OpenWorkspace(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.workspace = ws
+
+	prompt := c.codeMapPrompt("Expire sessions in the store after a day.")
+	if !strings.Contains(prompt, "internal/session/store.go (package session; tests: store_test.go)") || strings.Contains(prompt, "invoice.go") {
+		t.Errorf("prompt = %q, want the session store only", prompt)
+	}
+	if _, err := os.Stat(filepath.Join(ws.CacheDir(), codeMapCacheFile)); err != nil {
+		t.Errorf("code map not cached: %v", err)
+	}
+
+	cfg := storage.NewDefaultWorkspaceConfig()
+	cfg.Context.CodeMap.Disabled = true
+	if err := ws.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.codeMapPrompt("Expire sessions in the store."); got != "" {
+		t.Errorf("disabled code map prompt = %q, want empty", got)
+	}
+}
@@ -0,0 +1,124 @@
+package conductor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/vcs"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// commitDiffLimit bounds the patch the agent sees when writing a commit
+// message; the stat summary is always complete.
+const commitDiffLimit = 30000
+
+// commitMessageStyle returns git.commit_message_style, or "" when commit
+// messages are templated.
+func (c *Conductor) commitMessageStyle() string {
+	if c.workspace == nil {
+		return ""
+	}
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil || !vcs.ValidCommitStyle(cfg.Git.CommitMessageStyle) {
+		return ""
+	}
+
+	return cfg.Git.CommitMessageStyle
+}
+
+// generateCommitMessage asks the checkpointing agent to describe the staged
+// changes. hint is the templated message, telling the agent why the commit
+// is made, and optional paths limit the changes described. It returns ""
+// when generation is off or fails, so callers fall back to the templated
+// message.
+func (c *Conductor) generateCommitMessage(ctx context.Context, prefix, hint string, paths ...string) string {
+	style := c.commitMessageStyle()
+	if style == "" {
+		return ""
+	}
+
+	diff, err := c.git.StagedDiff(ctx, commitDiffLimit, paths...)
+	if err != nil || strings.TrimSpace(diff) == "" {
+		return ""
+	}
+
+	writer, err := c.GetAgentForStep(ctx, workflow.StepCheckpointing)
+	if err != nil {
+		c.logError(fmt.Errorf("get commit message agent: %w", err))
+
+		return ""
+	}
+
+	title := c.activeTask.ID
+	if c.taskWork != nil && c.taskWork.Metadata.Title != "" {
+		title = c.taskWork.Metadata.Title
+	}
+
+	response, err := writer.Run(ctx, buildCommitMessagePrompt(style, prefix, title, hint, diff))
+	if err != nil {
+		c.logError(fmt.Errorf("agent commit message: %w", err))
+
+		return ""
+	}
+	c.recordUsage(c.activeTask.ID, "commit", workflow.StepCheckpointing, writer, response.Usage)
+
+	text := response.Summary
+	if strings.TrimSpace(text) == "" && len(response.Messages) > 0 {
+		text = response.Messages[len(response.Messages)-1]
+	}
+	msg, err := vcs.ParseCommitMessage(text, style)
+	if err != nil {
+		c.logVerbosef("Using templated commit message: %v", err)
+
+		return ""
+	}
+
+	return msg.Format(prefix)
+}
+
+// generateCheckpointMessage stages the changes a checkpoint commits, paths
+// or all when empty, and generates the message for the commit.
+func (c *Conductor) generateCheckpointMessage(ctx context.Context, prefix, hint string, paths []string) string {
+	if c.commitMessageStyle() == "" {
+		return ""
+	}
+	var err error
+	if len(paths) > 0 {
+		err = c.git.Add(ctx, append([]string{"-A", "--"}, paths...)...)
+	} else {
+		err = c.git.AddAll(ctx)
+	}
+	if err != nil {
+		return ""
+	}
+
+	return c.generateCommitMessage(ctx, prefix, hint, paths...)
+}
+
+// buildCommitMessagePrompt asks for a commit message for a diff.
+func buildCommitMessagePrompt(style, prefix, title, hint, diff string) string {
+	var sb strings.Builder
+	sb.WriteString("Write the git commit message for the staged changes below.\n\n")
+	fmt.Fprintf(&sb, "## Task\n%s\n\n", title)
+	fmt.Fprintf(&sb, "## Why this commit is made\n%s\n\n", hint)
+
+	sb.WriteString("## Format\n")
+	if style == vcs.CommitStyleConventional {
+		sb.WriteString("- Subject: a Conventional Commits header, `type(scope): description`, with type one of feat, fix, docs, style, refactor, perf, test, build, ci, chore, revert. The scope is optional.\n")
+	} else {
+		sb.WriteString("- Subject: an imperative summary, e.g. \"Add retry to the upload client\".\n")
+	}
+	limit := vcs.MaxSubjectLength
+	if prefix != "" {
+		limit -= len(prefix) + 1
+		fmt.Fprintf(&sb, "- The subject will be prefixed with %q; do not include it yourself.\n", prefix)
+	}
+	fmt.Fprintf(&sb, "- Keep the subject under %d characters, without a trailing period.\n", limit)
+	sb.WriteString("- Optionally, after a blank line, a short body explaining what changed and why, wrapped at 72 characters.\n")
+	sb.WriteString("- Reply with the commit message only, no quotes or code fences. Do not change any files.\n\n")
+
+	fmt.Fprintf(&sb, "## Staged Changes\n```diff\n%s\n```\n", strings.TrimSpace(diff))
+
+	return sb.String()
+}
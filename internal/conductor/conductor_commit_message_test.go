@@ -0,0 +1,136 @@
+package conductor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// messageAgent answers a commit message prompt with a fixed reply.
+type messageAgent struct {
+	mockAgent
+
+	reply   string
+	prompts []string
+}
+
+func (a *messageAgent) Run(ctx context.Context, prompt string) (*agent.Response, error) {
+	a.prompts = append(a.prompts, prompt)
+
+	return &agent.Response{Summary: a.reply}, nil
+}
+
+func TestCreateCheckpointIfNeeded_CommitMessage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	tests := []struct {
+		name        string
+		style       string
+		reply       string
+		wantMessage string
+		wantPrompts int
+	}{
+		{
+			name:        "conventional",
+			style:       "conventional",
+			reply:       "feat(cache): add lookup cache\n\nLookups no longer hit the database twice.",
+			wantMessage: "[FEATURE-1] feat(cache): add lookup cache\n\nLookups no longer hit the database twice.",
+			wantPrompts: 1,
+		},
+		{
+			name:        "plain",
+			style:       "plain",
+			reply:       "Add lookup cache",
+			wantMessage: "[FEATURE-1] Add lookup cache",
+			wantPrompts: 1,
+		},
+		{
+			name:        "invalid reply falls back",
+			style:       "conventional",
+			reply:       "I added a cache.",
+			wantMessage: "[FEATURE-1] checkpoint 1: Implement task msg",
+			wantPrompts: 1,
+		},
+		{
+			name:        "templated by default",
+			wantMessage: "[FEATURE-1] checkpoint 1: Implement task msg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			tmpDir := t.TempDir()
+			initGitRepo(t, tmpDir)
+
+			a := &messageAgent{mockAgent: mockAgent{name: "writer"}, reply: tt.reply}
+			c, err := New(WithWorkDir(tmpDir), WithAgent("writer"))
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			if err := c.GetAgentRegistry().Register(a); err != nil {
+				t.Fatalf("Register agent: %v", err)
+			}
+			if err := c.Initialize(ctx); err != nil {
+				t.Fatalf("Initialize: %v", err)
+			}
+			cfg, err := c.workspace.LoadConfig()
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			cfg.Git.CommitMessageStyle = tt.style
+			if err := c.workspace.SaveConfig(cfg); err != nil {
+				t.Fatalf("SaveConfig: %v", err)
+			}
+			c.activeTask = &storage.ActiveTask{ID: "msg", UseGit: true}
+			c.taskWork = &storage.TaskWork{
+				Metadata: storage.WorkMetadata{Title: "Cache lookups"},
+				Git:      storage.GitInfo{CommitPrefix: "[FEATURE-1]"},
+			}
+
+			if err := os.WriteFile(filepath.Join(tmpDir, "cache.go"), []byte("package cache\n"), 0o644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			if event := c.createCheckpointIfNeeded(ctx, "msg", "Implement task msg"); event == nil {
+				t.Fatal("createCheckpointIfNeeded created no checkpoint")
+			}
+
+			got, err := c.git.GetCommitMessage(ctx, "HEAD")
+			if err != nil {
+				t.Fatalf("GetCommitMessage: %v", err)
+			}
+			if got != tt.wantMessage {
+				t.Errorf("commit message = %q, want %q", got, tt.wantMessage)
+			}
+			if len(a.prompts) != tt.wantPrompts {
+				t.Fatalf("agent ran %d time(s), want %d", len(a.prompts), tt.wantPrompts)
+			}
+			if tt.wantPrompts > 0 {
+				for _, want := range []string{"Cache lookups", "Implement task msg", "cache.go", `prefixed with "[FEATURE-1]"`} {
+					if !strings.Contains(a.prompts[0], want) {
+						t.Errorf("prompt missing %q", want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBuildCommitMessagePrompt(t *testing.T) {
+	conventional := buildCommitMessagePrompt("conventional", "[FEATURE-1]", "Cache lookups", "Implement task", "diff")
+	if !strings.Contains(conventional, "Conventional Commits") || !strings.Contains(conventional, "under 60 characters") {
+		t.Errorf("conventional prompt:\n%s", conventional)
+	}
+
+	plain := buildCommitMessagePrompt("plain", "", "Cache lookups", "Implement task", "diff")
+	if strings.Contains(plain, "Conventional Commits") || strings.Contains(plain, "prefixed") || !strings.Contains(plain, "under 72 characters") {
+		t.Errorf("plain prompt:\n%s", plain)
+	}
+}
@@ -0,0 +1,282 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// ErrResolutionRejected is returned when the agent's conflict resolution was
+// not confirmed. The rebase or merge has been aborted.
+var ErrResolutionRejected = errors.New("conflict resolution rejected")
+
+// conflictContext is the number of lines shown around a conflict hunk.
+const conflictContext = 3
+
+// maxIntentCommits bounds the commit subjects listed per side in a report.
+const maxIntentCommits = 20
+
+// ConflictReport describes the conflicts a rebase or merge stopped on: where
+// they are, and what each side meant to do.
+type ConflictReport struct {
+	Operation   string         // vcs.OperationRebase or vcs.OperationMerge
+	Branch      string         // Task branch
+	BaseRef     string         // Ref brought into the task branch
+	Replaying   string         // Task commit being replayed when a rebase stopped
+	TaskCommits []string       // Task commits touching the conflicting files, newest first
+	BaseCommits []string       // Base commits touching the conflicting files, newest first
+	Files       []ConflictFile // Conflicting files
+}
+
+// ConflictFile is a conflicting file with its conflict hunks.
+type ConflictFile struct {
+	Path  string
+	Hunks []ConflictHunk
+}
+
+// ConflictHunk is one conflict region of a file.
+type ConflictHunk struct {
+	Line    int    // Line of the <<<<<<< marker (1-based)
+	Task    string // The task branch's side
+	Base    string // The base branch's side
+	Excerpt string // The region and surrounding lines, numbered
+}
+
+// ConflictResolution is the agent's proposed resolution of a ConflictReport.
+type ConflictResolution struct {
+	Files   []string // Files the resolution covers
+	Summary string   // The agent's explanation
+	Diff    string   // The resolved files against HEAD
+}
+
+// conflictReport builds the report for the conflicts in files.
+func (c *Conductor) conflictReport(ctx context.Context, op, baseRef string, files []string) (*ConflictReport, error) {
+	report := &ConflictReport{Operation: op, Branch: c.activeTask.Branch, BaseRef: baseRef}
+
+	root := c.repoRoot()
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(root, file))
+		if err != nil {
+			return nil, fmt.Errorf("read conflicted file: %w", err)
+		}
+		report.Files = append(report.Files, ConflictFile{
+			Path:  file,
+			Hunks: parseConflictHunks(string(content), op, conflictContext),
+		})
+	}
+
+	// Intents come from the commits on either side that touch the files.
+	// The task branch ref stays put until a rebase finishes, so both ranges
+	// hold for rebases and merges alike.
+	report.TaskCommits = c.intentCommits(ctx, baseRef+".."+report.Branch, files)
+	report.BaseCommits = c.intentCommits(ctx, report.Branch+".."+baseRef, files)
+	if op == vcs.OperationRebase {
+		if out, err := c.git.Log(ctx, "-1", "--format=%h %s", "REBASE_HEAD"); err == nil {
+			report.Replaying = strings.TrimSpace(out)
+		}
+	}
+
+	return report, nil
+}
+
+// intentCommits lists the subjects of the commits in rng touching files.
+func (c *Conductor) intentCommits(ctx context.Context, rng string, files []string) []string {
+	args := append([]string{"--no-merges", fmt.Sprintf("--max-count=%d", maxIntentCommits), "--format=%h %s", rng, "--"}, files...)
+	out, err := c.git.Log(ctx, args...)
+	if err != nil {
+		return nil
+	}
+
+	var commits []string
+	for line := range strings.SplitSeq(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			commits = append(commits, line)
+		}
+	}
+
+	return commits
+}
+
+// runConflictResolution is the conflict resolution step: the agent gets the
+// report and writes the resolved files. The resolution is applied to the
+// working tree but not staged or committed.
+func (c *Conductor) runConflictResolution(ctx context.Context, report *ConflictReport) (*ConflictResolution, error) {
+	resolver, err := c.GetAgentForStep(ctx, workflow.StepResolving)
+	if err != nil {
+		return nil, fmt.Errorf("get conflict resolution agent: %w", err)
+	}
+
+	title := c.activeTask.ID
+	if c.taskWork != nil {
+		title = c.taskWork.Metadata.Title
+	}
+
+	response, err := resolver.RunWithCallback(ctx, buildConflictPrompt(title, report), func(event agent.Event) error {
+		c.eventBus.PublishRaw(events.Event{
+			Type: events.TypeAgentMessage,
+			Data: map[string]any{"event": event},
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("agent conflict resolution: %w", err)
+	}
+	c.recordUsage(c.activeTask.ID, "conflicts", workflow.StepResolving, resolver, response.Usage)
+
+	if len(response.Files) > 0 {
+		if err := applyFiles(ctx, c, response.Files); err != nil {
+			return nil, fmt.Errorf("apply conflict resolution: %w", err)
+		}
+	}
+
+	// The agent may also have edited the files in place; either way no
+	// conflict markers may remain
+	resolution := &ConflictResolution{Summary: response.Summary}
+	root := c.repoRoot()
+	for _, file := range report.Files {
+		resolution.Files = append(resolution.Files, file.Path)
+
+		content, err := os.ReadFile(filepath.Join(root, file.Path))
+		if err != nil {
+			continue // Resolved by deleting the file
+		}
+		if hasConflictMarkers(string(content)) {
+			return nil, fmt.Errorf("%w: %s still has conflict markers", ErrSyncConflict, file.Path)
+		}
+	}
+
+	resolution.Diff, err = c.git.Diff(ctx, append([]string{"HEAD", "--"}, resolution.Files...)...)
+	if err != nil {
+		return nil, fmt.Errorf("diff conflict resolution: %w", err)
+	}
+
+	return resolution, nil
+}
+
+// confirmResolution asks whether a resolution may be committed. Without a
+// callback, only auto mode commits resolutions.
+func (c *Conductor) confirmResolution(report *ConflictReport, resolution *ConflictResolution) bool {
+	if c.opts.OnConflictResolution == nil {
+		return c.opts.AutoMode
+	}
+
+	return c.opts.OnConflictResolution(report, resolution)
+}
+
+// parseConflictHunks returns the conflicts in content. The sides of the
+// markers are mapped to the task and base branch according to op: a rebase
+// has the base on the HEAD side, a merge the task.
+func parseConflictHunks(content, op string, contextLines int) []ConflictHunk {
+	lines := strings.Split(content, "\n")
+
+	var hunks []ConflictHunk
+	for i := 0; i < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], "<<<<<<<") {
+			continue
+		}
+
+		var head, other []string
+		section := &head
+		end := i + 1
+		for ; end < len(lines) && !strings.HasPrefix(lines[end], ">>>>>>>"); end++ {
+			switch line := lines[end]; {
+			case strings.HasPrefix(line, "|||||||"):
+				section = nil // diff3 common ancestor
+			case line == "=======":
+				section = &other
+			case section != nil:
+				*section = append(*section, line)
+			}
+		}
+		end = min(end, len(lines)-1)
+
+		hunk := ConflictHunk{Line: i + 1, Task: strings.Join(head, "\n"), Base: strings.Join(other, "\n")}
+		if op == vcs.OperationRebase {
+			hunk.Task, hunk.Base = hunk.Base, hunk.Task
+		}
+
+		var sb strings.Builder
+		for n := max(i-contextLines, 0); n <= min(end+contextLines, len(lines)-1); n++ {
+			fmt.Fprintf(&sb, "%4d| %s\n", n+1, lines[n])
+		}
+		hunk.Excerpt = sb.String()
+
+		hunks = append(hunks, hunk)
+		i = end
+	}
+
+	return hunks
+}
+
+// hasConflictMarkers reports whether content still contains conflict markers.
+func hasConflictMarkers(content string) bool {
+	for line := range strings.SplitSeq(content, "\n") {
+		if strings.HasPrefix(line, "<<<<<<<") || strings.HasPrefix(line, ">>>>>>>") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// buildConflictPrompt asks the agent to resolve the conflicts in a report.
+func buildConflictPrompt(title string, report *ConflictReport) string {
+	var sb strings.Builder
+	sb.WriteString("You are resolving git conflicts between a task branch and its base branch.\n\n")
+	fmt.Fprintf(&sb, "## Task\n%s\n\n", title)
+
+	sb.WriteString("## Situation\n")
+	if report.Operation == vcs.OperationMerge {
+		fmt.Fprintf(&sb, "%s is being merged into the task branch. The HEAD side (<<<<<<<) is the task branch; the other side (>>>>>>>) is %s.\n", report.BaseRef, report.BaseRef)
+	} else {
+		fmt.Fprintf(&sb, "The task branch is being rebased onto %s. The HEAD side (<<<<<<<) is %s; the other side (>>>>>>>) is the task's commit being replayed.\n", report.BaseRef, report.BaseRef)
+		if report.Replaying != "" {
+			fmt.Fprintf(&sb, "Replaying: %s\n", report.Replaying)
+		}
+	}
+
+	sb.WriteString("\n## Intent\n")
+	writeIntent(&sb, "The task branch", report.TaskCommits)
+	writeIntent(&sb, report.BaseRef, report.BaseCommits)
+
+	sb.WriteString("\n## Conflicts\n")
+	for _, file := range report.Files {
+		fmt.Fprintf(&sb, "\n### %s\n", file.Path)
+		for _, hunk := range file.Hunks {
+			sb.WriteString("```\n" + hunk.Excerpt + "```\n")
+		}
+	}
+
+	sb.WriteString(`
+## Instructions
+- Resolve every conflict so that both the base branch's changes and the task's intent are kept.
+- Write each file above in full, without any conflict markers (<<<<<<<, =======, >>>>>>>).
+- Change only the conflicting regions; do not refactor or touch other files.
+- Briefly explain each resolution.
+`)
+
+	return sb.String()
+}
+
+// writeIntent lists the commits one side made to the conflicting files.
+func writeIntent(sb *strings.Builder, side string, commits []string) {
+	if len(commits) == 0 {
+		fmt.Fprintf(sb, "%s has no commits touching these files.\n", side)
+
+		return
+	}
+
+	fmt.Fprintf(sb, "%s changed these files in:\n", side)
+	for _, commit := range commits {
+		fmt.Fprintf(sb, "- %s\n", commit)
+	}
+}
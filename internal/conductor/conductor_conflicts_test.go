@@ -0,0 +1,254 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+// resolvedNotes is the resolution the test agent writes for notes.txt.
+var resolvedNotes = []agent.FileChange{
+	{Path: "notes.txt", Operation: agent.FileOpUpdate, Content: "one\nbase and task\nthree\n"},
+}
+
+func TestConflictResolution_Report(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	for _, strategy := range []string{SyncRebase, SyncMerge} {
+		t.Run(strategy, func(t *testing.T) {
+			var report *ConflictReport
+			var resolution *ConflictResolution
+			confirm := WithConflictResolutionCallback(func(r *ConflictReport, res *ConflictResolution) bool {
+				report, resolution = r, res
+
+				return true
+			})
+			c, _ := newSyncConductor(t, &resolvingAgent{files: resolvedNotes}, confirm)
+
+			result, err := c.Sync(context.Background(), SyncOptions{Strategy: strategy, ResolveConflicts: true})
+			if err != nil {
+				t.Fatalf("Sync: %v", err)
+			}
+			if report == nil || len(result.Reports) != 1 || result.Reports[0] != report {
+				t.Fatalf("Reports = %v, want the confirmed report", result.Reports)
+			}
+
+			if strings.Join(report.TaskCommits, ",") == "" || !strings.HasSuffix(report.TaskCommits[0], " Task change") {
+				t.Errorf("TaskCommits = %v, want the task's commit", report.TaskCommits)
+			}
+			if len(report.BaseCommits) != 1 || !strings.HasSuffix(report.BaseCommits[0], " Base change") {
+				t.Errorf("BaseCommits = %v, want the base's commit", report.BaseCommits)
+			}
+			if wantReplaying := strategy == SyncRebase; strings.HasSuffix(report.Replaying, " Task change") != wantReplaying {
+				t.Errorf("Replaying = %q", report.Replaying)
+			}
+
+			if len(report.Files) != 1 || len(report.Files[0].Hunks) != 1 {
+				t.Fatalf("Files = %+v, want one hunk in notes.txt", report.Files)
+			}
+			if hunk := report.Files[0].Hunks[0]; hunk.Task != "task" || hunk.Base != "base" || hunk.Line != 2 {
+				t.Errorf("hunk = %+v, want task/base sides at line 2", hunk)
+			}
+
+			if !strings.Contains(resolution.Diff, "+base and task") || resolution.Summary != "Resolved conflicts" {
+				t.Errorf("resolution = %+v", resolution)
+			}
+		})
+	}
+}
+
+func TestConflictResolution_Confirmation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	reject := func(*ConflictReport, *ConflictResolution) bool { return false }
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr error
+	}{
+		{name: "rejected", opts: []Option{WithConflictResolutionCallback(reject)}, wantErr: ErrResolutionRejected},
+		{name: "no callback", opts: []Option{WithConflictResolutionCallback(nil)}, wantErr: ErrResolutionRejected},
+		{name: "no callback in auto mode", opts: []Option{WithConflictResolutionCallback(nil), WithAutoMode(true)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, tmpDir := newSyncConductor(t, &resolvingAgent{files: resolvedNotes}, tt.opts...)
+			ctx := context.Background()
+
+			_, err := c.Sync(ctx, SyncOptions{ResolveConflicts: true})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Sync error = %v, want %v", err, tt.wantErr)
+			}
+			if op := c.git.OperationInProgress(ctx); op != "" {
+				t.Errorf("%s left in progress", op)
+			}
+
+			content, err := os.ReadFile(filepath.Join(tmpDir, "notes.txt"))
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			want := "one\nbase and task\nthree\n"
+			if tt.wantErr != nil {
+				want = "one\ntask\nthree\n"
+			}
+			if string(content) != want {
+				t.Errorf("notes.txt = %q, want %q", content, want)
+			}
+		})
+	}
+}
+
+func TestConflictResolution_Undo(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	c, tmpDir := newSyncConductor(t, &resolvingAgent{files: resolvedNotes})
+	ctx := context.Background()
+
+	if _, err := c.Sync(ctx, SyncOptions{ResolveConflicts: true}); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if _, err := c.git.Undo(ctx, "sync"); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "notes.txt"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(content) != "one\ntask\nthree\n" {
+		t.Errorf("notes.txt = %q after undo, want the task branch before the sync", content)
+	}
+}
+
+func TestFinishWithMerge_ResolvesConflicts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	a := &resolvingAgent{files: resolvedNotes}
+	c, tmpDir := newSyncConductor(t, a)
+	ctx := context.Background()
+	base := c.taskWork.Git.BaseBranch
+
+	err := c.performMerge(ctx, FinishOptions{SquashMerge: true})
+	if !errors.Is(err, errMergeConflict) {
+		t.Fatalf("performMerge error = %v, want errMergeConflict", err)
+	}
+	if branch, _ := c.git.CurrentBranch(ctx); branch != "task/sync" {
+		t.Errorf("on branch %s after a conflicting merge, want task/sync", branch)
+	}
+	if changed, _ := c.git.HasChanges(ctx); changed {
+		t.Error("conflicting merge left changes behind")
+	}
+
+	if err := c.finishWithMerge(ctx, FinishOptions{SquashMerge: true}); err != nil {
+		t.Fatalf("finishWithMerge: %v", err)
+	}
+	if len(a.prompts) != 1 {
+		t.Errorf("agent ran %d time(s), want 1", len(a.prompts))
+	}
+	if branch, _ := c.git.CurrentBranch(ctx); branch != base {
+		t.Errorf("on branch %s, want the target %s", branch, base)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "notes.txt"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(content) != "one\nbase and task\nthree\n" {
+		t.Errorf("merged notes.txt = %q", content)
+	}
+}
+
+func TestParseConflictHunks(t *testing.T) {
+	content := "a\nb\nc\n<<<<<<< HEAD\nours\n=======\ntheirs\n>>>>>>> task\nd\ne\nf\ng\n"
+
+	hunks := parseConflictHunks(content, vcs.OperationMerge, 2)
+	if len(hunks) != 1 {
+		t.Fatalf("got %d hunks, want 1", len(hunks))
+	}
+	want := "   2| b\n   3| c\n   4| <<<<<<< HEAD\n   5| ours\n   6| =======\n   7| theirs\n   8| >>>>>>> task\n   9| d\n  10| e\n"
+	if hunks[0].Excerpt != want {
+		t.Errorf("excerpt =\n%s\nwant\n%s", hunks[0].Excerpt, want)
+	}
+	if hunks[0].Line != 4 || hunks[0].Task != "ours" || hunks[0].Base != "theirs" {
+		t.Errorf("merge hunk = %+v, want the HEAD side as the task's", hunks[0])
+	}
+
+	rebase := parseConflictHunks(content, vcs.OperationRebase, 0)
+	if rebase[0].Task != "theirs" || rebase[0].Base != "ours" {
+		t.Errorf("rebase hunk = %+v, want the HEAD side as the base's", rebase[0])
+	}
+
+	diff3 := parseConflictHunks("<<<<<<< HEAD\nours\n||||||| base\nold\n=======\ntheirs\n>>>>>>> task\n", vcs.OperationMerge, 0)
+	if diff3[0].Task != "ours" || diff3[0].Base != "theirs" {
+		t.Errorf("diff3 hunk = %+v, want the common ancestor left out", diff3[0])
+	}
+
+	if got := parseConflictHunks("no conflicts\n", vcs.OperationMerge, 3); len(got) != 0 {
+		t.Errorf("parseConflictHunks without markers = %v, want none", got)
+	}
+}
+
+func TestHasConflictMarkers(t *testing.T) {
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{content: "plain\ntext\n", want: false},
+		{content: "a\n<<<<<<< HEAD\nb\n", want: true},
+		{content: "a\n>>>>>>> main\n", want: true},
+		{content: "a\n=======\nb\n", want: false}, // Markdown setext headings use it
+	}
+
+	for _, tt := range tests {
+		if got := hasConflictMarkers(tt.content); got != tt.want {
+			t.Errorf("hasConflictMarkers(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestBuildConflictPrompt(t *testing.T) {
+	report := &ConflictReport{
+		Operation:   vcs.OperationRebase,
+		Branch:      "task/sync",
+		BaseRef:     "origin/main",
+		Replaying:   "abc1234 Cache lookups",
+		TaskCommits: []string{"abc1234 Cache lookups"},
+		Files:       []ConflictFile{{Path: "notes.txt", Hunks: []ConflictHunk{{Excerpt: "   1| <<<<<<< HEAD\n"}}}},
+	}
+
+	rebase := buildConflictPrompt("Add caching", report)
+	for _, want := range []string{
+		"Add caching",
+		"rebased onto origin/main",
+		"Replaying: abc1234 Cache lookups",
+		"- abc1234 Cache lookups",
+		"origin/main has no commits touching these files",
+		"### notes.txt",
+		"<<<<<<< HEAD",
+	} {
+		if !strings.Contains(rebase, want) {
+			t.Errorf("rebase prompt missing %q", want)
+		}
+	}
+
+	report.Operation = vcs.OperationMerge
+	merge := buildConflictPrompt("Add caching", report)
+	if !strings.Contains(merge, "origin/main is being merged into the task branch") || strings.Contains(merge, "Replaying") {
+		t.Errorf("merge prompt does not describe the merge:\n%s", merge)
+	}
+}
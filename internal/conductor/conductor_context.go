@@ -0,0 +1,204 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// ContextMode decides how much of a task's notes and earlier sessions agent
+// prompts include. The source and specifications are always included.
+type ContextMode string
+
+const (
+	ContextFull    ContextMode = "full"    // Everything; older notes and sessions are summarized only over the token budget
+	ContextSummary ContextMode = "summary" // Older notes and sessions summarized, the latest ones verbatim
+	ContextMinimal ContextMode = "minimal" // Only the latest note, no earlier sessions
+)
+
+// ParseContextMode validates a context mode name.
+func ParseContextMode(name string) (ContextMode, error) {
+	switch mode := ContextMode(strings.ToLower(strings.TrimSpace(name))); mode {
+	case ContextFull, ContextSummary, ContextMinimal:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown context mode %q: use full, summary or minimal", name)
+	}
+}
+
+// charsPerToken approximates the characters per token of English text and
+// code, which is close enough to decide when context needs summarizing.
+const charsPerToken = 4
+
+// keptExchanges is how many of the latest session exchanges stay verbatim
+// when older context is summarized.
+const keptExchanges = 2
+
+// estimateTokens estimates the number of tokens in s.
+func estimateTokens(s string) int {
+	return (len(s) + charsPerToken - 1) / charsPerToken
+}
+
+// promptContext is the notes and session history a prompt includes.
+type promptContext struct {
+	Notes   string
+	History []storage.Exchange
+	Summary string // Summary of the older notes and exchanges left out
+}
+
+// summaryPrompt renders the summary of older context, or "" without one.
+func (pc promptContext) summaryPrompt() string {
+	if pc.Summary == "" {
+		return ""
+	}
+
+	return "\n## Earlier Context (summarized)\nOlder notes and conversation about this task, summarized to fit the context window:\n\n" + pc.Summary + "\n"
+}
+
+// contextMode returns the context mode from the options, else agent.context
+// from the workspace config, else full.
+func (c *Conductor) contextMode() ContextMode {
+	if c.opts.ContextMode != "" {
+		return c.opts.ContextMode
+	}
+	if c.workspace != nil {
+		if cfg, err := c.workspace.LoadConfig(); err == nil && cfg.Agent.Context != "" {
+			mode, err := ParseContextMode(cfg.Agent.Context)
+			if err == nil {
+				return mode
+			}
+			c.logError(fmt.Errorf("agent.context: %w", err))
+		}
+	}
+
+	return ContextFull
+}
+
+// contextTokens returns the estimated prompt size above which full context
+// is summarized.
+func (c *Conductor) contextTokens() int {
+	if c.workspace != nil {
+		if cfg, err := c.workspace.LoadConfig(); err == nil && cfg.Agent.ContextTokens > 0 {
+			return cfg.Agent.ContextTokens
+		}
+	}
+
+	return storage.DefaultContextTokens
+}
+
+// buildContext fits a prompt's notes and session history to the context
+// mode. fixed is the rest of the prompt, counted against the token budget.
+// Older context is summarized by the summary agent, falling back to the
+// latest note and exchanges alone when summarizing fails.
+func (c *Conductor) buildContext(ctx context.Context, step workflow.Step, stepAgent agent.Agent, fixed, notes string, history []storage.Exchange) promptContext {
+	olderNotes, latestNotes := splitNotes(notes)
+	var olderHistory []storage.Exchange
+	recentHistory := history
+	if len(history) > keptExchanges {
+		olderHistory, recentHistory = history[:len(history)-keptExchanges], history[len(history)-keptExchanges:]
+	}
+
+	switch c.contextMode() {
+	case ContextMinimal:
+		return promptContext{Notes: latestNotes}
+	case ContextFull:
+		total := estimateTokens(fixed) + estimateTokens(notes) + estimateTokens(sessionHistoryPrompt("", history))
+		if total <= c.contextTokens() {
+			return promptContext{Notes: notes, History: history}
+		}
+		c.publishProgress(fmt.Sprintf("Context is about %d tokens, summarizing older notes and sessions...", total), 3)
+	case ContextSummary:
+	}
+
+	older := strings.TrimSpace(olderNotes + sessionHistoryPrompt("Earlier Conversation", olderHistory))
+	if older == "" {
+		return promptContext{Notes: notes, History: history}
+	}
+
+	summary, err := c.summarizeContext(ctx, step, stepAgent, older)
+	if err != nil {
+		c.logError(fmt.Errorf("summarize context: %w", err))
+	}
+
+	return promptContext{Notes: latestNotes, History: recentHistory, Summary: summary}
+}
+
+// splitNotes splits notes into the older entries and the latest one.
+func splitNotes(notes string) (string, string) {
+	idx := strings.LastIndex(notes, "\n## ")
+	if idx == -1 {
+		return "", notes
+	}
+
+	return notes[:idx], notes[idx+1:]
+}
+
+// summarizeContext summarizes older context with the summary agent. Summaries
+// are cached per source revision and content, so unchanged context is only
+// summarized once.
+func (c *Conductor) summarizeContext(ctx context.Context, step workflow.Step, stepAgent agent.Agent, older string) (string, error) {
+	taskID := c.activeTask.ID
+	key := storage.ContextSummaryKey(c.taskWork.Source.Revision, older)
+	if summary, ok, err := c.workspace.LoadContextSummary(taskID, key); err != nil {
+		c.logError(err)
+	} else if ok {
+		return summary, nil
+	}
+
+	summarizer := c.summaryAgent(step, stepAgent)
+	response, err := summarizer.Run(ctx, buildContextSummaryPrompt(c.taskWork.Metadata.Title, older))
+	if err != nil {
+		return "", err
+	}
+	c.recordUsage(taskID, "summary", step, summarizer, response.Usage)
+
+	summary := strings.TrimSpace(response.Summary)
+	if summary == "" && len(response.Messages) > 0 {
+		summary = strings.TrimSpace(response.Messages[len(response.Messages)-1])
+	}
+	if summary == "" {
+		return "", errors.New("agent returned an empty summary")
+	}
+	if err := c.workspace.SaveContextSummary(taskID, key, summary); err != nil {
+		c.logError(err)
+	}
+
+	return summary, nil
+}
+
+// summaryAgent returns agent.summary_agent from the workspace config, or the
+// step's agent when none is configured.
+func (c *Conductor) summaryAgent(step workflow.Step, stepAgent agent.Agent) agent.Agent {
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil || cfg.Agent.SummaryAgent == "" {
+		return stepAgent
+	}
+
+	summarizer, err := c.agents.Get(cfg.Agent.SummaryAgent)
+	if err != nil {
+		c.logError(fmt.Errorf("agent.summary_agent: %w", err))
+
+		return stepAgent
+	}
+
+	return c.withTracing(summarizer, step.String())
+}
+
+// buildContextSummaryPrompt asks an agent to condense older task context.
+func buildContextSummaryPrompt(title, older string) string {
+	var sb strings.Builder
+	sb.WriteString("Summarize the notes and conversation below from earlier work on a software task, so the work can continue without them.\n\n")
+	fmt.Fprintf(&sb, "## Task\n%s\n\n", title)
+	fmt.Fprintf(&sb, "## Earlier Context\n%s\n\n", older)
+	sb.WriteString("## Instructions\n")
+	sb.WriteString("- Keep decisions, requirements, constraints, answers to questions and open issues, with names of files and functions.\n")
+	sb.WriteString("- Drop exploration details, repetition and anything later superseded.\n")
+	sb.WriteString("- Reply with the summary only, as markdown bullet points. Do not change any files.\n")
+
+	return sb.String()
+}
@@ -0,0 +1,127 @@
+package conductor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider/file"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+func TestParseContextMode(t *testing.T) {
+	for name, want := range map[string]ContextMode{"full": ContextFull, " Summary ": ContextSummary, "MINIMAL": ContextMinimal} {
+		got, err := ParseContextMode(name)
+		if err != nil || got != want {
+			t.Errorf("ParseContextMode(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseContextMode("everything"); err == nil {
+		t.Error("ParseContextMode(everything): want error")
+	}
+}
+
+func TestSplitNotes(t *testing.T) {
+	older, latest := splitNotes("## t1 [idle]\n\nfirst\n\n## t2 [planning]\n\nsecond\n")
+	if older != "## t1 [idle]\n\nfirst\n" || latest != "## t2 [planning]\n\nsecond\n" {
+		t.Errorf("splitNotes = %q, %q", older, latest)
+	}
+
+	if older, latest := splitNotes("## t1 [idle]\n\nonly\n"); older != "" || latest != "## t1 [idle]\n\nonly\n" {
+		t.Errorf("splitNotes(one note) = %q, %q", older, latest)
+	}
+}
+
+func TestBuildContext(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	taskPath := filepath.Join(tmpDir, "task.md")
+	if err := os.WriteFile(taskPath, []byte("# Login\n\nAdd a login page."), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	c, err := New(WithWorkDir(tmpDir), WithCreateBranch(false), WithAgent("mock"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	file.Register(c.GetProviderRegistry())
+	if err := c.GetAgentRegistry().Register(&mockAgent{name: "mock"}); err != nil {
+		t.Fatalf("Register mock agent: %v", err)
+	}
+	if err := c.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if err := c.Start(ctx, "file:"+taskPath); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	notes := "## t1 [idle]\n\nUse bcrypt for passwords.\n\n## t2 [planning]\n\nAdd a remember-me box.\n"
+	history := []storage.Exchange{
+		{Role: "user", Content: "Which framework?"},
+		{Role: "agent", Content: "The existing one."},
+		{Role: "user", Content: "Keep the form small."},
+		{Role: "agent", Content: "Two fields then."},
+	}
+	summarizer := &messageAgent{mockAgent: mockAgent{name: "mock"}, reply: "- Passwords use bcrypt"}
+	setContext := func(mode string, tokens int) {
+		t.Helper()
+		cfg, err := c.GetWorkspace().LoadConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.Agent.Context = mode
+		cfg.Agent.ContextTokens = tokens
+		if err := c.GetWorkspace().SaveConfig(cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pc := c.buildContext(ctx, workflow.StepPlanning, summarizer, "source", notes, history)
+	if pc.Notes != notes || len(pc.History) != 4 || pc.Summary != "" || len(summarizer.prompts) != 0 {
+		t.Errorf("full under budget = %+v, %d prompts; want everything unsummarized", pc, len(summarizer.prompts))
+	}
+
+	setContext("minimal", 0)
+	pc = c.buildContext(ctx, workflow.StepPlanning, summarizer, "source", notes, history)
+	if pc.Notes != "## t2 [planning]\n\nAdd a remember-me box.\n" || pc.History != nil || pc.Summary != "" {
+		t.Errorf("minimal = %+v, want the latest note only", pc)
+	}
+
+	setContext("full", 10)
+	pc = c.buildContext(ctx, workflow.StepPlanning, summarizer, "source", notes, history)
+	if !strings.HasPrefix(pc.Notes, "## t2") || len(pc.History) != keptExchanges || pc.Summary != "- Passwords use bcrypt" {
+		t.Errorf("full over budget = %+v, want latest note, %d exchanges and the summary", pc, keptExchanges)
+	}
+	if len(summarizer.prompts) != 1 || !strings.Contains(summarizer.prompts[0], "Use bcrypt") || !strings.Contains(summarizer.prompts[0], "Which framework?") {
+		t.Fatalf("summary prompts = %q, want one with the older note and exchanges", summarizer.prompts)
+	}
+	if strings.Contains(summarizer.prompts[0], "Two fields then.") {
+		t.Error("summary prompt includes a kept exchange")
+	}
+	if !strings.Contains(pc.summaryPrompt(), "## Earlier Context (summarized)") {
+		t.Errorf("summaryPrompt() = %q", pc.summaryPrompt())
+	}
+
+	// Same revision and older context: the cached summary is reused
+	setContext("summary", 0)
+	pc = c.buildContext(ctx, workflow.StepImplementing, summarizer, "source", notes, history)
+	if pc.Summary != "- Passwords use bcrypt" || len(summarizer.prompts) != 1 {
+		t.Errorf("cached summary = %q after %d prompts, want no new prompt", pc.Summary, len(summarizer.prompts))
+	}
+
+	// Nothing older to summarize
+	pc = c.buildContext(ctx, workflow.StepImplementing, summarizer, "source", "## t3 [idle]\n\nonly\n", nil)
+	if pc.Summary != "" || len(summarizer.prompts) != 1 {
+		t.Errorf("single note = %+v after %d prompts, want no summary", pc, len(summarizer.prompts))
+	}
+
+	// Summarizer failure keeps the latest context only
+	summarizer.reply = ""
+	pc = c.buildContext(ctx, workflow.StepImplementing, summarizer, "source", notes+"\n## t4 [idle]\n\nnew\n", nil)
+	if pc.Summary != "" || !strings.HasPrefix(pc.Notes, "## t4") {
+		t.Errorf("failed summary = %+v, want latest note without summary", pc)
+	}
+}
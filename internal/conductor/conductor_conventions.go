@@ -0,0 +1,127 @@
+package conductor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// Size limits of convention files in prompts; larger files are cut.
+const (
+	maxConventionFileBytes = 16 * 1024
+	maxConventionBytes     = 48 * 1024
+)
+
+// styleGuideSteps are the steps that see style guides and lint configs by
+// default. Planning decides what to build, not how it is formatted.
+var styleGuideSteps = []workflow.Step{workflow.StepImplementing, workflow.StepReviewing}
+
+// conventionGlobs returns the globs of the files included in step's prompts:
+// the step's context.steps entry, else context.include_globs, else the
+// defaults.
+func (c *Conductor) conventionGlobs(step workflow.Step) []string {
+	var settings storage.ContextSettings
+	if c.workspace != nil {
+		if cfg, err := c.workspace.LoadConfig(); err == nil {
+			settings = cfg.Context
+		}
+	}
+
+	if override, ok := settings.Steps[step.String()]; ok {
+		return override.IncludeGlobs
+	}
+	if len(settings.IncludeGlobs) > 0 {
+		return settings.IncludeGlobs
+	}
+	if slices.Contains(styleGuideSteps, step) {
+		return slices.Concat(storage.DefaultConventionGlobs, storage.DefaultStyleGuideGlobs)
+	}
+
+	return storage.DefaultConventionGlobs
+}
+
+// conventionFiles returns the repo-relative paths of the files matching
+// globs. Scoped tasks also match the globs inside the scope directory, so a
+// subproject's own conventions are found.
+func conventionFiles(root, scope string, globs []string) []string {
+	dirs := []string{""}
+	if scope != "" {
+		dirs = append(dirs, scope)
+	}
+
+	var files []string
+	for _, dir := range dirs {
+		for _, glob := range globs {
+			matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(dir), filepath.FromSlash(glob)))
+			if err != nil {
+				continue
+			}
+			for _, match := range matches {
+				rel, err := filepath.Rel(root, match)
+				if err != nil {
+					continue
+				}
+				rel = filepath.ToSlash(rel)
+				if info, err := os.Stat(match); err != nil || !info.Mode().IsRegular() || slices.Contains(files, rel) {
+					continue
+				}
+				files = append(files, rel)
+			}
+		}
+	}
+
+	return files
+}
+
+// conventionsPrompt includes the repository's convention files in step's
+// prompt, or returns "" when none are found.
+func (c *Conductor) conventionsPrompt(step workflow.Step) string {
+	globs := c.conventionGlobs(step)
+	if len(globs) == 0 {
+		return ""
+	}
+	root := c.repoRoot()
+
+	return buildConventionsPrompt(root, conventionFiles(root, c.taskScope(), globs))
+}
+
+// buildConventionsPrompt formats convention files for a prompt, cutting
+// files and the total at the size limits.
+func buildConventionsPrompt(root string, files []string) string {
+	var sb strings.Builder
+	total := 0
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(file)))
+		if err != nil || len(strings.TrimSpace(string(data))) == 0 {
+			continue
+		}
+		if total >= maxConventionBytes {
+			fmt.Fprintf(&sb, "\n### %s\n(omitted: read it yourself if needed)\n", file)
+
+			continue
+		}
+
+		content := string(data)
+		limit := min(maxConventionFileBytes, maxConventionBytes-total)
+		cut := len(content) > limit
+		if cut {
+			content = content[:limit]
+		}
+		total += len(content)
+
+		fmt.Fprintf(&sb, "\n### %s\n%s\n", file, strings.TrimRight(content, "\n"))
+		if cut {
+			sb.WriteString("(cut: read the file for the rest)\n")
+		}
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+
+	return "\n## Project Conventions\nThis repository documents its conventions in the files below. Follow them.\n" + sb.String()
+}
@@ -0,0 +1,107 @@
+package conductor
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+func writeConventionFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestConventionFiles(t *testing.T) {
+	root := t.TempDir()
+	writeConventionFiles(t, root, map[string]string{
+		"CONTRIBUTING.md":             "Write tests.",
+		".editorconfig":               "indent_style = tab",
+		"web/CONTRIBUTING.md":         "Use pnpm.",
+		"other/CONTRIBUTING.md":       "Not in scope.",
+		"docs/CONTRIBUTING.md/ignore": "directory, not a file",
+	})
+
+	got := conventionFiles(root, "web", []string{"CONTRIBUTING.md", "docs/CONTRIBUTING.md", ".editorconfig", "[bad"})
+	want := []string{"CONTRIBUTING.md", ".editorconfig", "web/CONTRIBUTING.md"}
+	if !slices.Equal(got, want) {
+		t.Errorf("conventionFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestBuildConventionsPrompt(t *testing.T) {
+	root := t.TempDir()
+	writeConventionFiles(t, root, map[string]string{
+		"CONTRIBUTING.md": "Write tests.\n",
+		"AGENTS.md":       strings.Repeat("a", maxConventionFileBytes+10),
+		"empty.md":        "\n",
+		"huge1.md":        strings.Repeat("b", maxConventionFileBytes),
+		"huge2.md":        strings.Repeat("c", maxConventionFileBytes),
+		"huge3.md":        strings.Repeat("d", maxConventionFileBytes),
+	})
+
+	if got := buildConventionsPrompt(root, []string{"empty.md"}); got != "" {
+		t.Errorf("buildConventionsPrompt(empty file) = %q, want empty", got)
+	}
+
+	prompt := buildConventionsPrompt(root, []string{"CONTRIBUTING.md", "AGENTS.md", "empty.md", "huge1.md", "huge2.md", "huge3.md"})
+	for _, want := range []string{"## Project Conventions", "### CONTRIBUTING.md\nWrite tests.\n", "(cut: read the file for the rest)", "### huge3.md\n(omitted"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+	if strings.Contains(prompt, "empty.md") {
+		t.Error("prompt includes an empty file")
+	}
+}
+
+func TestConventionGlobs(t *testing.T) {
+	c, err := New(WithWorkDir(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Defaults: style guides for implementing and reviewing only
+	if got := c.conventionGlobs(workflow.StepPlanning); !slices.Equal(got, storage.DefaultConventionGlobs) {
+		t.Errorf("planning globs = %v, want conventions only", got)
+	}
+	if got := c.conventionGlobs(workflow.StepReviewing); !slices.Contains(got, ".editorconfig") || !slices.Contains(got, "CONTRIBUTING.md") {
+		t.Errorf("reviewing globs = %v, want conventions and style guides", got)
+	}
+
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := storage.NewDefaultWorkspaceConfig()
+	cfg.Context = storage.ContextSettings{
+		IncludeGlobs: []string{"GUIDE.md"},
+		Steps:        map[string]storage.ContextStepSettings{"planning": {}, "reviewing": {IncludeGlobs: []string{".golangci.yml"}}},
+	}
+	if err := ws.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	c.workspace = ws
+
+	if got := c.conventionGlobs(workflow.StepPlanning); len(got) != 0 {
+		t.Errorf("planning globs = %v, want none", got)
+	}
+	if got := c.conventionGlobs(workflow.StepReviewing); !slices.Equal(got, []string{".golangci.yml"}) {
+		t.Errorf("reviewing globs = %v, want the step override", got)
+	}
+	if got := c.conventionGlobs(workflow.StepImplementing); !slices.Equal(got, []string{"GUIDE.md"}) {
+		t.Errorf("implementing globs = %v, want include_globs", got)
+	}
+}
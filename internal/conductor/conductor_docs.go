@@ -0,0 +1,428 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/progress"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// Document enters the documentation phase.
+func (c *Conductor) Document(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.activeTask == nil {
+		return errors.New("no active task")
+	}
+
+	// Update machine with specifications
+	specifications, err := c.workspace.ListSpecifications(c.activeTask.ID)
+	if err != nil {
+		return fmt.Errorf("list specifications: %w", err)
+	}
+	if wu := c.machine.WorkUnit(); wu != nil {
+		wu.Specifications = make([]string, len(specifications))
+		for i, num := range specifications {
+			wu.Specifications[i] = fmt.Sprintf("specification-%d.md", num)
+		}
+	}
+
+	// Update state
+	c.activeTask.State = "documenting"
+	if err := c.workspace.SaveActiveTask(c.activeTask); err != nil {
+		return fmt.Errorf("save active task: %w", err)
+	}
+
+	// Dispatch document event
+	if err := c.machine.Dispatch(ctx, workflow.EventDocument); err != nil {
+		return fmt.Errorf("enter documentation: %w", err)
+	}
+
+	return nil
+}
+
+// DocumentAfterImplement reports whether the workspace config asks for the
+// documentation phase after every implementation.
+func (c *Conductor) DocumentAfterImplement() bool {
+	cfg, err := c.workspace.LoadConfig()
+
+	return err == nil && cfg.Workflow.DocumentAfterImplement
+}
+
+// RunDocumentation executes the documentation phase: the agent updates the
+// README files, docs and doc comments affected by the task's changes.
+// Changes outside the documentation paths are dropped, and a report of the
+// touched docs is saved as documentation-N.md in the work directory.
+func (c *Conductor) RunDocumentation(ctx context.Context) error {
+	return c.tracePhase(ctx, "documentation", c.runDocumentation)
+}
+
+func (c *Conductor) runDocumentation(ctx context.Context) (err error) {
+	ctx, release, err := c.acquireLease(ctx)
+	if err != nil {
+		return err
+	}
+	defer release(&err)
+
+	c.publishProgress("Starting documentation phase...", 0)
+
+	taskID := c.activeTask.ID
+
+	// Create progress tracker for this phase
+	var statusLine *progress.StatusLine
+	if !c.opts.DryRun {
+		statusLine = progress.NewStatusLine("Documenting")
+		defer statusLine.Done()
+	}
+
+	// Get agent for documenting step
+	docAgent, err := c.GetAgentForStep(ctx, workflow.StepDocumenting)
+	if err != nil {
+		return fmt.Errorf("get documentation agent: %w", err)
+	}
+
+	// Create session for this documentation run
+	session, filename, err := c.workspace.CreateSession(taskID, "documentation", docAgent.Name(), c.activeTask.State)
+	if err != nil {
+		c.logError(fmt.Errorf("create session: %w", err))
+	} else {
+		c.currentSession = session
+		c.currentSessionFile = filename
+	}
+
+	// Missing specifications leave the diff as the only context
+	specContent, _, _ := c.workspace.GetLatestSpecificationContent(taskID)
+
+	c.publishProgress("Collecting changes...", 5)
+	changes, truncated := c.taskDiff(ctx)
+	if changes == "" {
+		changes = c.changedFilesList(taskID)
+	}
+
+	docPaths := c.docPaths()
+	prompt := buildDocumentationPrompt(c.taskWork.Metadata.Title, specContent, changes, truncated, docPaths)
+	prompt += scopePrompt(c.taskScope())
+	prompt += c.conventionsPrompt(workflow.StepDocumenting)
+	prompt = c.scriptPrompt(ctx, workflow.StepDocumenting, prompt)
+
+	// Run agent
+	c.publishProgress("Agent updating documentation...", 20)
+	response, err := docAgent.RunWithCallback(ctx, prompt, func(event agent.Event) error {
+		// Always publish to event bus
+		c.eventBus.PublishRaw(events.Event{
+			Type: events.TypeAgentMessage,
+			Data: map[string]any{"event": event},
+		})
+		// Also track progress if not dry-run
+		if statusLine != nil {
+			_ = statusLine.OnEvent(event)
+		}
+
+		return nil
+	})
+	if err != nil {
+		if statusLine != nil {
+			statusLine.Done()
+		}
+		c.activeTask.State = "idle"
+		if err := c.workspace.SaveActiveTask(c.activeTask); err != nil {
+			c.logError(fmt.Errorf("save active task after documentation error: %w", err))
+		}
+		_ = c.machine.Dispatch(ctx, workflow.EventError)
+
+		return fmt.Errorf("agent documentation: %w", err)
+	}
+
+	// Record usage stats
+	c.recordUsage(taskID, "documentation", workflow.StepDocumenting, docAgent, response.Usage)
+
+	c.publishProgress("Applying documentation changes...", 70)
+
+	// Only documentation changes are applied
+	files, skipped := filterDocChanges(c.repoRoot(), c.taskScope(), docPaths, response.Files)
+	if !c.opts.DryRun && len(files) > 0 {
+		summaries := summarizeFileChanges(c.repoRoot(), files)
+		if err := applyFiles(ctx, c, files); err != nil {
+			c.activeTask.State = "idle"
+			if err := c.workspace.SaveActiveTask(c.activeTask); err != nil {
+				c.logError(fmt.Errorf("save active task after documentation error: %w", err))
+			}
+			_ = c.machine.Dispatch(ctx, workflow.EventError)
+
+			return fmt.Errorf("apply documentation changes: %w", err)
+		}
+		c.recordChangeSummaries(summaries)
+
+		// Create checkpoint unless the changes introduce secrets or large blobs
+		if err := c.enforceGuardrails(ctx, docAgent, "documentation", workflow.StepDocumenting); err != nil {
+			c.logError(err)
+		} else if event := c.createCheckpointIfNeeded(ctx, taskID, "Update documentation for task "+taskID); event != nil {
+			c.eventBus.PublishRaw(*event)
+		}
+	}
+
+	// Save the report of touched docs
+	if !c.opts.DryRun {
+		number, err := c.workspace.NextDocumentationReportNumber(taskID)
+		if err != nil {
+			c.logError(fmt.Errorf("get next documentation report number: %w", err))
+		} else {
+			summary, reasons := parseDocumentationNotes(strings.Join(append([]string{response.Summary}, response.Messages...), "\n"))
+			report := formatDocumentationReport(number, time.Now(), summary, files, skipped, reasons)
+			if err := c.workspace.SaveDocumentationReport(taskID, number, report); err != nil {
+				c.logError(fmt.Errorf("save documentation report: %w", err))
+			}
+		}
+	}
+
+	// Update state back to idle
+	c.activeTask.State = "idle"
+	if err := c.workspace.SaveActiveTask(c.activeTask); err != nil {
+		c.logError(fmt.Errorf("save active task: %w", err))
+	}
+
+	// Dispatch completion
+	_ = c.machine.Dispatch(ctx, workflow.EventDocumentDone)
+
+	// Save session with completion time
+	c.saveCurrentSession(taskID)
+
+	c.publishProgress("Documentation complete", 100)
+
+	return nil
+}
+
+// docPaths returns the configured documentation paths, or the defaults.
+func (c *Conductor) docPaths() []string {
+	if cfg, err := c.workspace.LoadConfig(); err == nil && len(cfg.Workflow.DocPaths) > 0 {
+		return cfg.Workflow.DocPaths
+	}
+
+	return storage.DefaultDocPaths
+}
+
+// taskDiff returns the task's changes against its base branch, including
+// uncommitted ones, cut at maxReviewDiffBytes.
+func (c *Conductor) taskDiff(ctx context.Context) (string, bool) {
+	if c.git == nil || c.taskWork == nil {
+		return "", false
+	}
+
+	baseBranch := c.taskWork.Git.BaseBranch
+	if baseBranch == "" {
+		baseBranch, _ = c.git.GetBaseBranch(ctx)
+	}
+	if baseBranch == "" {
+		return "", false
+	}
+
+	args := []string{baseBranch}
+	if scope := c.taskScope(); scope != "" {
+		args = append(args, "--", scope)
+	}
+	diff, err := c.git.Diff(ctx, args...)
+	if err != nil {
+		return "", false
+	}
+	if len(diff) > maxReviewDiffBytes {
+		return diff[:maxReviewDiffBytes], true
+	}
+
+	return diff, false
+}
+
+// changedFilesList lists the files changed by the task's sessions, for when
+// no diff is available.
+func (c *Conductor) changedFilesList(taskID string) string {
+	var sb strings.Builder
+	for _, s := range c.loadChangeSummaries(taskID) {
+		fmt.Fprintf(&sb, "- %s (%s)\n", s.Path, s.Operation)
+	}
+
+	return sb.String()
+}
+
+// Reasons a documentation change is not applied.
+const (
+	skipNotDoc      = "not a documentation file"
+	skipChangesCode = "changes code, not only comments"
+	skipOutOfScope  = "outside the task scope"
+)
+
+// filterDocChanges splits agent file changes into those the documentation
+// phase may apply and those it drops, keyed by path with the reason. Files
+// matching docPaths are allowed; Go files only when nothing but comments
+// changes.
+func filterDocChanges(root, scope string, docPaths []string, files []agent.FileChange) ([]agent.FileChange, map[string]string) {
+	var allowed []agent.FileChange
+	skipped := make(map[string]string)
+	for _, fc := range files {
+		switch {
+		case !inScope(scope, fc.Path):
+			skipped[fc.Path] = skipOutOfScope
+		case isDocPath(docPaths, fc.Path):
+			allowed = append(allowed, fc)
+		case path.Ext(filepath.ToSlash(fc.Path)) != ".go":
+			skipped[fc.Path] = skipNotDoc
+		case fc.Operation != agent.FileOpUpdate || fc.Content == DeleteFileSentinel:
+			skipped[fc.Path] = skipChangesCode
+		default:
+			before, err := os.ReadFile(filepath.Join(root, fc.Path))
+			if err != nil || !commentOnlyGoChange(before, []byte(fc.Content)) {
+				skipped[fc.Path] = skipChangesCode
+
+				continue
+			}
+			allowed = append(allowed, fc)
+		}
+	}
+
+	return allowed, skipped
+}
+
+// isDocPath reports whether a repo-relative file matches one of the
+// documentation patterns. A pattern ending in a slash matches a directory at
+// any depth; a pattern without a slash matches the file name.
+func isDocPath(patterns []string, file string) bool {
+	file = path.Clean(filepath.ToSlash(file))
+	for _, pattern := range patterns {
+		if dir, ok := strings.CutSuffix(pattern, "/"); ok {
+			if strings.HasPrefix(file, dir+"/") || strings.Contains(file, "/"+dir+"/") {
+				return true
+			}
+
+			continue
+		}
+
+		target := file
+		if !strings.Contains(pattern, "/") {
+			target = path.Base(file)
+		}
+		if ok, _ := path.Match(pattern, target); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// commentOnlyGoChange reports whether two versions of a Go file differ only
+// in comments and whitespace.
+func commentOnlyGoChange(before, after []byte) bool {
+	a, b := goTokens(before), goTokens(after)
+
+	return a != nil && b != nil && slices.Equal(a, b)
+}
+
+// goTokens returns the tokens of Go source without comments, or nil when the
+// source does not scan.
+func goTokens(src []byte) []string {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var s scanner.Scanner
+	failed := false
+	s.Init(file, src, func(token.Position, string) { failed = true }, 0)
+
+	tokens := []string{}
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON {
+			lit = "" // Automatic semicolons carry the newline as literal
+		}
+		tokens = append(tokens, tok.String()+" "+lit)
+	}
+	if failed {
+		return nil
+	}
+
+	return tokens
+}
+
+// parseDocumentationNotes splits the agent's answer into its summary and the
+// reason given for each touched file under "## Documentation Updates".
+func parseDocumentationNotes(text string) (string, map[string]string) {
+	reasons := make(map[string]string)
+	var summary []string
+	inUpdates := false
+	for line := range strings.SplitSeq(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if heading, ok := strings.CutPrefix(trimmed, "## "); ok {
+			inUpdates = strings.EqualFold(strings.TrimSpace(heading), "Documentation Updates")
+
+			continue
+		}
+		if !inUpdates {
+			summary = append(summary, line)
+
+			continue
+		}
+
+		item, ok := strings.CutPrefix(trimmed, "- ")
+		if !ok {
+			continue
+		}
+		file, reason, ok := strings.Cut(item, ": ")
+		if !ok {
+			continue
+		}
+		reasons[strings.Trim(strings.TrimSpace(file), "`")] = strings.TrimSpace(reason)
+	}
+
+	return strings.TrimSpace(strings.Join(summary, "\n")), reasons
+}
+
+// formatDocumentationReport formats the documentation report: the files the
+// phase touched and why, and the changes it dropped.
+func formatDocumentationReport(number int, at time.Time, summary string, files []agent.FileChange, skipped, reasons map[string]string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Documentation Update %d\n\n", number)
+	fmt.Fprintf(&sb, "Date: %s\n", at.Format("2006-01-02 15:04:05"))
+
+	if summary != "" {
+		sb.WriteString("\n## Summary\n\n" + summary + "\n")
+	}
+
+	sb.WriteString("\n## Updated Documentation\n\n")
+	if len(files) == 0 {
+		sb.WriteString("No documentation needed updating.\n")
+	}
+	for _, fc := range files {
+		fmt.Fprintf(&sb, "- `%s` (%s)", fc.Path, fc.Operation)
+		if reason := reasons[fc.Path]; reason != "" {
+			sb.WriteString(": " + reason)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(skipped) > 0 {
+		sb.WriteString("\n## Skipped Changes\n\n")
+		paths := make([]string, 0, len(skipped))
+		for p := range skipped {
+			paths = append(paths, p)
+		}
+		slices.Sort(paths)
+		for _, p := range paths {
+			fmt.Fprintf(&sb, "- `%s`: %s\n", p, skipped[p])
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,157 @@
+package conductor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+)
+
+func TestIsDocPath(t *testing.T) {
+	patterns := []string{"docs/", "README*", "*.md"}
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{"README", true},
+		{"README.md", true},
+		{"docs/guides/setup.txt", true},
+		{"services/api/docs/index.html", true},
+		{"CHANGELOG.md", true},
+		{"pkg/api/README.md", true},
+		{"main.go", false},
+		{"mydocs/setup.txt", false},
+	}
+
+	for _, tt := range tests {
+		if got := isDocPath(patterns, tt.file); got != tt.want {
+			t.Errorf("isDocPath(%q) = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestCommentOnlyGoChange(t *testing.T) {
+	before := "package cache\n\nfunc Get(key string) string {\n\treturn key\n}\n"
+	tests := []struct {
+		name  string
+		after string
+		want  bool
+	}{
+		{"doc comment added", "// Package cache caches values.\npackage cache\n\n// Get returns the value for key.\nfunc Get(key string) string {\n\treturn key // unchanged\n}\n", true},
+		{"code changed", "package cache\n\nfunc Get(key string) string {\n\treturn \"\"\n}\n", false},
+		{"string changed", "package cache\n\nfunc Get(key string) string {\n\treturn key + \"x\"\n}\n", false},
+		{"does not scan", "package cache\n\nfunc Get(key string) string {\n\treturn `key\n}\n", false},
+	}
+
+	for _, tt := range tests {
+		if got := commentOnlyGoChange([]byte(before), []byte(tt.after)); got != tt.want {
+			t.Errorf("%s: commentOnlyGoChange() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseDocumentationNotes(t *testing.T) {
+	summary, reasons := parseDocumentationNotes(`Documented the cache flag.
+
+## Documentation Updates
+- ` + "`README.md`" + `: Added the --cache flag to the usage section
+- internal/cache/cache.go: Explained the eviction policy
+- not a reason`)
+
+	if summary != "Documented the cache flag." {
+		t.Errorf("summary = %q", summary)
+	}
+	if len(reasons) != 2 || reasons["README.md"] != "Added the --cache flag to the usage section" ||
+		reasons["internal/cache/cache.go"] != "Explained the eviction policy" {
+		t.Errorf("reasons = %v", reasons)
+	}
+}
+
+// docsAgent answers the documentation prompt with fixed file changes.
+type docsAgent struct {
+	mockAgent
+
+	files  []agent.FileChange
+	prompt string
+}
+
+func (a *docsAgent) Run(ctx context.Context, prompt string) (*agent.Response, error) {
+	a.prompt = prompt
+
+	return &agent.Response{
+		Summary: "Documented the cache.\n\n## Documentation Updates\n- docs/cache.md: Describes the new cache",
+		Files:   a.files,
+	}, nil
+}
+
+func (a *docsAgent) RunWithCallback(ctx context.Context, prompt string, cb agent.StreamCallback) (*agent.Response, error) {
+	return a.Run(ctx, prompt)
+}
+
+func TestRunDocumentation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	a := &docsAgent{mockAgent: mockAgent{name: "mock"}, files: []agent.FileChange{
+		{Path: "docs/cache.md", Operation: agent.FileOpCreate, Content: "# Cache\n"},
+		{Path: "main.go", Operation: agent.FileOpUpdate, Content: "package main\n\nfunc main() { panic(1) }\n"},
+	}}
+	c := newPlanningConductor(t, a)
+	ctx := context.Background()
+	root := c.repoRoot()
+	taskID := c.GetActiveTask().ID
+
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := c.GetWorkspace().SaveSpecification(taskID, 1, "# Add a cache\n"); err != nil {
+		t.Fatalf("SaveSpecification: %v", err)
+	}
+
+	if err := c.Document(ctx); err != nil {
+		t.Fatalf("Document: %v", err)
+	}
+	if err := c.RunDocumentation(ctx); err != nil {
+		t.Fatalf("RunDocumentation: %v", err)
+	}
+
+	if !strings.Contains(a.prompt, "# Add a cache") || !strings.Contains(a.prompt, "docs/, doc/") {
+		t.Errorf("prompt should carry the specification and doc paths:\n%s", a.prompt)
+	}
+	if _, err := os.Stat(filepath.Join(root, "docs", "cache.md")); err != nil {
+		t.Errorf("documentation change not applied: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "main.go")); strings.Contains(string(data), "panic") {
+		t.Error("code change should not be applied by the documentation phase")
+	}
+	if c.GetActiveTask().State != "idle" {
+		t.Errorf("state = %q, want idle", c.GetActiveTask().State)
+	}
+
+	report, err := c.GetWorkspace().LoadDocumentationReport(taskID, 1)
+	if err != nil {
+		t.Fatalf("LoadDocumentationReport: %v", err)
+	}
+	for _, want := range []string{
+		"# Documentation Update 1",
+		"Documented the cache.",
+		"- `docs/cache.md` (create): Describes the new cache",
+		"- `main.go`: changes code, not only comments",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestFormatDocumentationReport_NoChanges(t *testing.T) {
+	report := formatDocumentationReport(2, time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC), "", nil, map[string]string{}, nil)
+	if !strings.Contains(report, "No documentation needed updating.") || strings.Contains(report, "Skipped") {
+		t.Errorf("report = %q", report)
+	}
+}
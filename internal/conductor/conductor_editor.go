@@ -0,0 +1,156 @@
+package conductor
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/guardrail"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// editorPresets are the ui.editor_cmd values that name an editor rather
+// than spell out its command.
+var editorPresets = map[string]string{
+	"code":     "code -g {file}:{line}",
+	"vscode":   "code -g {file}:{line}",
+	"cursor":   "cursor -g {file}:{line}",
+	"idea":     "idea --line {line} {file}",
+	"goland":   "goland --line {line} {file}",
+	"pycharm":  "pycharm --line {line} {file}",
+	"webstorm": "webstorm --line {line} {file}",
+	"phpstorm": "phpstorm --line {line} {file}",
+}
+
+// Limits on opening changed files in the editor.
+const (
+	maxEditorFiles = 10
+	editorTimeout  = 10 * time.Second
+)
+
+// editorCommand returns the command opening path at line, from a preset
+// name or a command template with {file} and {line} placeholders. Without
+// {file}, the path is appended.
+func editorCommand(template, path string, line int) []string {
+	if preset, ok := editorPresets[strings.TrimSpace(template)]; ok {
+		template = preset
+	}
+	line = max(line, 1)
+
+	fields := strings.Fields(template)
+	hasFile := false
+	args := make([]string, len(fields))
+	for i, f := range fields {
+		hasFile = hasFile || strings.Contains(f, "{file}")
+		args[i] = strings.NewReplacer("{file}", path, "{line}", strconv.Itoa(line)).Replace(f)
+	}
+	if !hasFile && len(args) > 0 {
+		args = append(args, path)
+	}
+
+	return args
+}
+
+// implementedChanges returns the files an implementation run changed, each
+// with its first changed line. Uncommitted changes are read from git, which
+// also covers agents that edit files themselves; without git, the files from
+// the agent's output are used.
+func (c *Conductor) implementedChanges(ctx context.Context, files []agent.FileChange) []storage.FileChange {
+	if c.git == nil {
+		changes := make([]storage.FileChange, 0, len(files))
+		for _, f := range files {
+			changes = append(changes, storage.FileChange{Path: f.Path, Operation: string(f.Operation)})
+		}
+
+		return changes
+	}
+
+	summary, err := c.git.GetChangeSummary(ctx)
+	if err != nil {
+		c.logError(fmt.Errorf("list implemented changes: %w", err))
+
+		return nil
+	}
+	var added map[string][]guardrail.Line
+	if len(summary.Modified) > 0 {
+		args := append([]string{"HEAD", "--no-color", "--unified=0", "--"}, summary.Modified...)
+		if diff, err := c.git.Diff(ctx, args...); err == nil {
+			added = guardrail.AddedLines(diff)
+		}
+	}
+
+	var changes []storage.FileChange
+	for _, path := range summary.Added {
+		changes = append(changes, storage.FileChange{Path: path, Operation: string(agent.FileOpCreate), Line: 1})
+	}
+	for _, path := range summary.Modified {
+		change := storage.FileChange{Path: path, Operation: string(agent.FileOpUpdate)}
+		if lines := added[path]; len(lines) > 0 {
+			change.Line = lines[0].Number
+		}
+		changes = append(changes, change)
+	}
+	for _, path := range summary.Deleted {
+		changes = append(changes, storage.FileChange{Path: path, Operation: string(agent.FileOpDelete)})
+	}
+	slices.SortFunc(changes, func(a, b storage.FileChange) int { return strings.Compare(a.Path, b.Path) })
+
+	return changes
+}
+
+// recordImplementation adds the implementation run's outcome to the current
+// session as an agent exchange listing the files it changed.
+func (c *Conductor) recordImplementation(summary string, changes []storage.FileChange) {
+	if c.currentSession == nil || (summary == "" && len(changes) == 0) {
+		return
+	}
+	if summary == "" {
+		summary = fmt.Sprintf("Changed %d file(s)", len(changes))
+	}
+
+	c.currentSession.Exchanges = append(c.currentSession.Exchanges, storage.Exchange{
+		Role:         "agent",
+		Timestamp:    time.Now(),
+		Content:      summary,
+		FilesChanged: changes,
+	})
+}
+
+// openInEditor opens the changed files at their first changed line with
+// ui.editor_cmd, when it is set. Deleted files are skipped, and at most
+// maxEditorFiles are opened. Editor failures are logged, not returned.
+func (c *Conductor) openInEditor(ctx context.Context, changes []storage.FileChange) {
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil || strings.TrimSpace(cfg.UI.EditorCmd) == "" {
+		return
+	}
+
+	changes = slices.DeleteFunc(slices.Clone(changes), func(fc storage.FileChange) bool {
+		return fc.Operation == string(agent.FileOpDelete)
+	})
+	if len(changes) > maxEditorFiles {
+		c.publishProgress(fmt.Sprintf("Opening the first %d of %d changed files in the editor", maxEditorFiles, len(changes)), 100)
+		changes = changes[:maxEditorFiles]
+	}
+
+	root := c.repoRoot()
+	for _, change := range changes {
+		args := editorCommand(cfg.UI.EditorCmd, filepath.Join(root, filepath.FromSlash(change.Path)), change.Line)
+		runCtx, cancel := context.WithTimeout(ctx, editorTimeout)
+		cmd := exec.CommandContext(runCtx, args[0], args[1:]...)
+		cmd.Dir = root
+		err := cmd.Run()
+		cancel()
+		if err != nil {
+			c.logError(fmt.Errorf("open %s in editor: %w", change.Path, err))
+
+			return
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package conductor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+func TestEditorCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		line     int
+		want     []string
+	}{
+		{"vscode preset", "code", 12, []string{"code", "-g", "/r/main.go:12"}},
+		{"jetbrains preset", "goland", 3, []string{"goland", "--line", "3", "/r/main.go"}},
+		{"template", "subl {file}:{line}", 7, []string{"subl", "/r/main.go:7"}},
+		{"file appended", "vim -p", 7, []string{"vim", "-p", "/r/main.go"}},
+		{"line defaults to 1", "code", 0, []string{"code", "-g", "/r/main.go:1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := editorCommand(tt.template, "/r/main.go", tt.line); !slices.Equal(got, tt.want) {
+				t.Errorf("editorCommand(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImplementedChanges(t *testing.T) {
+	repo := t.TempDir()
+	initGitRepo(t, repo)
+	ctx := context.Background()
+
+	c, err := New(WithWorkDir(repo))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if c.git, err = vcs.New(ctx, repo); err != nil {
+		t.Fatalf("vcs.New: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("# Test\n\nUsage\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := c.implementedChanges(ctx, nil)
+	want := []storage.FileChange{
+		{Path: "README.md", Operation: string(agent.FileOpUpdate), Line: 2},
+		{Path: "main.go", Operation: string(agent.FileOpCreate), Line: 1},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("implementedChanges() = %+v, want %+v", got, want)
+	}
+
+	c.git = nil
+	got = c.implementedChanges(ctx, []agent.FileChange{{Path: "a.go", Operation: agent.FileOpCreate}})
+	if len(got) != 1 || got[0].Path != "a.go" || got[0].Operation != string(agent.FileOpCreate) {
+		t.Errorf("implementedChanges() without git = %+v", got)
+	}
+}
+
+func TestOpenInEditor(t *testing.T) {
+	repo := t.TempDir()
+	c, err := New(WithWorkDir(repo))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if c.workspace, err = storage.OpenWorkspace(repo, nil); err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := c.workspace.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+
+	// The editor command appends each file it is asked to open to a log
+	log := filepath.Join(repo, "opened.log")
+	cfg, _ := c.workspace.LoadConfig()
+	cfg.UI.EditorCmd = "sh " + writeEditorScript(t, log) + " {file}:{line}"
+	if err := c.workspace.SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	c.openInEditor(context.Background(), []storage.FileChange{
+		{Path: "main.go", Operation: string(agent.FileOpUpdate), Line: 4},
+		{Path: "old.go", Operation: string(agent.FileOpDelete)},
+	})
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatalf("editor not run: %v", err)
+	}
+	if want := filepath.Join(repo, "main.go") + ":4\n"; string(data) != want {
+		t.Errorf("opened %q, want %q", data, want)
+	}
+}
+
+// writeEditorScript writes a shell script that appends its argument to log.
+func writeEditorScript(t *testing.T, log string) string {
+	t.Helper()
+	script := filepath.Join(t.TempDir(), "editor.sh")
+	if err := os.WriteFile(script, []byte("echo \"$1\" >> "+log+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	return script
+}
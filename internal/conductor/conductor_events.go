@@ -0,0 +1,108 @@
+package conductor
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// logEvent appends every bus event to the event log of the task it belongs
+// to: the task named in the event, or else the active task. Events outside
+// any task are not logged. Failures are only logged at debug level so the
+// event log can never break the workflow.
+func (c *Conductor) logEvent(e events.Event) {
+	if c.workspace == nil {
+		return
+	}
+
+	taskID, _ := e.Data["task_id"].(string)
+	if taskID == "" && c.activeTask != nil {
+		taskID = c.activeTask.ID
+	}
+	if taskID == "" {
+		return
+	}
+
+	ts := e.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	record := storage.EventRecord{Timestamp: ts, Type: string(e.Type), Data: eventLogData(e.Data)}
+	if err := c.workspace.AppendEvent(taskID, record); err != nil {
+		slog.Debug("append event log", "task_id", taskID, "type", e.Type, "error", err)
+	}
+}
+
+// eventHistory reads the active task's event log for events.Bus replay. A
+// negative offset returns the last -offset events. Without an active task
+// there is no history.
+func (c *Conductor) eventHistory(offset int) ([]events.Event, error) {
+	if c.workspace == nil || c.activeTask == nil {
+		return nil, nil
+	}
+
+	filter := storage.EventFilter{Offset: offset}
+	if offset < 0 {
+		filter = storage.EventFilter{Limit: -offset}
+	}
+	records, err := c.workspace.ReadEvents(c.activeTask.ID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	past := make([]events.Event, len(records))
+	for i, r := range records {
+		past[i] = events.Event{Type: events.Type(r.Type), Timestamp: r.Timestamp, Data: r.Data}
+	}
+
+	return past, nil
+}
+
+// eventLogData converts event data to a form worth keeping in the log:
+// errors become their message and agent stream events lose their raw bytes.
+func eventLogData(data map[string]any) map[string]any {
+	if len(data) == 0 {
+		return nil
+	}
+
+	out := make(map[string]any, len(data))
+	for k, v := range data {
+		switch v := v.(type) {
+		case agent.Event:
+			out[k] = agentEventData(v)
+		case error:
+			out[k] = v.Error()
+		default:
+			out[k] = v
+		}
+	}
+
+	return out
+}
+
+// agentEventData summarizes an agent stream event. Text events keep only
+// their text; the parsed data of others is kept as is.
+func agentEventData(e agent.Event) map[string]any {
+	m := map[string]any{"type": string(e.Type)}
+	if e.Text != "" {
+		m["text"] = e.Text
+	}
+	if e.ToolCall != nil {
+		m["tool"] = e.ToolCall.Name
+		if e.ToolCall.Description != "" {
+			m["description"] = e.ToolCall.Description
+		}
+	}
+	if e.SessionID != "" {
+		m["session_id"] = e.SessionID
+	}
+	if e.Type != agent.EventText && len(e.Data) > 0 {
+		m["data"] = e.Data
+	}
+
+	return m
+}
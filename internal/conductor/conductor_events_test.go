@@ -0,0 +1,56 @@
+package conductor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestLogEvent(t *testing.T) {
+	c, err := New(WithWorkDir(t.TempDir()), WithAutoInit(true))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	_ = c.Initialize(context.Background())
+
+	// Without a task nothing is logged
+	c.eventBus.PublishRaw(events.Event{Type: events.TypeProgress, Data: map[string]any{"message": "orphan"}})
+
+	for _, id := range []string{"active", "other"} {
+		if _, err := c.workspace.CreateWork(id, storage.SourceInfo{Type: "file", Ref: "task.md"}); err != nil {
+			t.Fatalf("CreateWork: %v", err)
+		}
+	}
+	c.activeTask = &storage.ActiveTask{ID: "active"}
+
+	c.eventBus.PublishRaw(events.Event{Type: events.TypeProgress, Data: map[string]any{"message": "planning"}})
+	c.eventBus.PublishRaw(events.Event{Type: events.TypeAgentMessage, Data: map[string]any{"event": agent.Event{
+		Type:     agent.EventToolUse,
+		Raw:      []byte(`{"huge":"payload"}`),
+		ToolCall: &agent.ToolCall{Name: "Read", Description: "Reading main.go"},
+	}}})
+	c.eventBus.Publish(events.StateChangedEvent{From: "idle", To: "planning", TaskID: "other"})
+
+	logged, err := c.workspace.ReadEvents("active", storage.EventFilter{})
+	if err != nil {
+		t.Fatalf("ReadEvents: %v", err)
+	}
+	if len(logged) != 2 {
+		t.Fatalf("active task has %d events, want 2: %+v", len(logged), logged)
+	}
+	if logged[0].Data["message"] != "planning" || logged[0].Timestamp.IsZero() {
+		t.Errorf("progress event = %+v", logged[0])
+	}
+	ae, _ := logged[1].Data["event"].(map[string]any)
+	if ae["type"] != "tool_use" || ae["tool"] != "Read" || ae["description"] != "Reading main.go" || ae["raw"] != nil {
+		t.Errorf("agent event = %+v", logged[1].Data)
+	}
+
+	other, _ := c.workspace.ReadEvents("other", storage.EventFilter{Types: []string{string(events.TypeStateChanged)}})
+	if len(other) != 1 || other[0].Data["to"] != "planning" {
+		t.Errorf("events naming a task belong to it: %+v", other)
+	}
+}
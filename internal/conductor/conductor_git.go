@@ -1,13 +1,24 @@
 package conductor
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/valksor/go-mehrhof/internal/naming"
 	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
 )
 
+// errMergeConflict is returned when merging the task branch into the target
+// branch conflicts. The merge has been undone.
+var errMergeConflict = errors.New("merge conflicts with the target branch")
+
 // gitInfo holds git branch/worktree information created during task start.
 type gitInfo struct {
 	branchName    string
@@ -15,6 +26,8 @@ type gitInfo struct {
 	worktreePath  string
 	commitPrefix  string // Resolved commit prefix (e.g., "[FEATURE-123]")
 	branchPattern string // Template used to generate branch
+
+	repos []storage.RepoInfo // Attached secondary repositories
 }
 
 // namingInfo holds resolved naming for a task.
@@ -25,6 +38,8 @@ type namingInfo struct {
 	branchName    string // Resolved branch name
 	commitPrefix  string // Resolved commit prefix
 	branchPattern string // Template used for branch
+
+	vars naming.TemplateVars // Template variables, reused for secondary repository branches
 }
 
 // resolveNaming resolves external key, branch name, and commit prefix from workUnit and options.
@@ -96,6 +111,7 @@ func (c *Conductor) resolveNaming(workUnit *provider.WorkUnit, taskID string) *n
 		branchName:    branchName,
 		commitPrefix:  commitPrefix,
 		branchPattern: branchPattern,
+		vars:          vars,
 	}
 }
 
@@ -109,9 +125,9 @@ func (c *Conductor) createBranchOrWorktree(ctx context.Context, taskID string, n
 	branchName := ni.branchName // Use resolved branch name from naming
 
 	if c.opts.UseWorktree {
-		worktreePath := c.git.GetWorktreePath(taskID)
-		if err := c.git.EnsureWorktreesDir(); err != nil {
-			return nil, fmt.Errorf("create worktrees directory: %w", err)
+		worktreePath, err := c.resolveWorktreePath(taskID, ni)
+		if err != nil {
+			return nil, err
 		}
 		if err := c.git.CreateWorktreeNewBranch(ctx, worktreePath, branchName, baseBranch); err != nil {
 			return nil, fmt.Errorf("create worktree: %w", err)
@@ -147,6 +163,35 @@ func (c *Conductor) createBranchOrWorktree(ctx context.Context, taskID string, n
 	}, nil
 }
 
+// resolveWorktreePath returns where the task's worktree goes, following
+// git.worktree_dir and git.worktree_pattern. The pattern names a single
+// directory; when another worktree already uses it, the task ID is appended.
+func (c *Conductor) resolveWorktreePath(taskID string, ni *namingInfo) (string, error) {
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil {
+		cfg = storage.NewDefaultWorkspaceConfig()
+	}
+
+	dir, err := c.git.ResolveWorktreesDir(cfg.Git.WorktreeDir)
+	if err != nil {
+		return "", fmt.Errorf("resolve worktree directory: %w", err)
+	}
+
+	pattern := cmp.Or(cfg.Git.WorktreePattern, storage.DefaultWorktreePattern)
+	name := naming.CleanBranchName(naming.ExpandTemplate(pattern, ni.vars))
+	name = strings.ReplaceAll(name, "/", "-")
+	if name == "" || name == "." || name == ".." {
+		name = taskID
+	}
+
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err == nil && name != taskID {
+		path += "-" + taskID
+	}
+
+	return path, nil
+}
+
 // resolveTargetBranch determines the target branch for merging.
 func (c *Conductor) resolveTargetBranch(ctx context.Context, requested string) string {
 	if requested != "" {
@@ -178,9 +223,7 @@ func (c *Conductor) performMerge(ctx context.Context, opts FinishOptions) error
 	// Merge (squash or regular)
 	if opts.SquashMerge {
 		if err := c.git.MergeSquash(ctx, currentBranch); err != nil {
-			_ = c.git.Checkout(ctx, currentBranch)
-
-			return fmt.Errorf("squash merge: %w", err)
+			return c.abandonMerge(ctx, currentBranch, "squash merge", err)
 		}
 		// Use stored commit prefix, fallback to task ID if not set
 		prefix := c.taskWork.Git.CommitPrefix
@@ -188,22 +231,45 @@ func (c *Conductor) performMerge(ctx context.Context, opts FinishOptions) error
 			prefix = fmt.Sprintf("(%s)", taskID)
 		}
 		msg := fmt.Sprintf("%s merged from %s", prefix, currentBranch)
+		if generated := c.generateCommitMessage(ctx, prefix, "Squash merge of the whole task: "+msg); generated != "" {
+			msg = generated
+		}
 		if _, err := c.git.Commit(ctx, msg); err != nil {
+			if errors.Is(err, vcs.ErrSigning) {
+				// Leave the target branch as it was rather than with the task staged
+				_ = c.git.ResetMerge(ctx)
+			}
 			_ = c.git.Checkout(ctx, currentBranch)
 
 			return fmt.Errorf("commit merge: %w", err)
 		}
 	} else {
 		if err := c.git.MergeBranch(ctx, currentBranch, true); err != nil {
-			_ = c.git.Checkout(ctx, currentBranch)
-
-			return fmt.Errorf("merge: %w", err)
+			return c.abandonMerge(ctx, currentBranch, "merge", err)
 		}
 	}
 
 	return nil
 }
 
+// abandonMerge undoes a failed merge into the target branch and returns to
+// the task branch. Conflicts are reported as errMergeConflict.
+func (c *Conductor) abandonMerge(ctx context.Context, branch, what string, err error) error {
+	files, _ := c.git.ConflictedFiles(ctx)
+	if len(files) > 0 {
+		if resetErr := c.git.ResetMerge(ctx); resetErr != nil {
+			c.logError(fmt.Errorf("reset %s: %w", what, resetErr))
+		}
+	}
+	_ = c.git.Checkout(ctx, branch)
+
+	if len(files) > 0 {
+		return fmt.Errorf("%s: %w in %s", what, errMergeConflict, strings.Join(files, ", "))
+	}
+
+	return fmt.Errorf("%s: %w", what, err)
+}
+
 // cleanupAfterMerge removes the branch and worktree after successful merge
 // NOTE: Errors are logged but not returned intentionally.
 // The merge succeeded, so cleanup failures should not undo the user's work.
@@ -230,3 +296,13 @@ func (c *Conductor) cleanupAfterMerge(ctx context.Context, opts FinishOptions) {
 		c.logError(fmt.Errorf("delete branch: %w", err))
 	}
 }
+
+// signedGit returns git signing the commits it creates when git.sign_commits
+// is set, and git unchanged otherwise.
+func signedGit(cfg *storage.WorkspaceConfig, git *vcs.Git) *vcs.Git {
+	if cfg == nil || git == nil || !cfg.Git.SignCommits {
+		return git
+	}
+
+	return git.WithSigning(vcs.Signing{Format: cfg.Git.SigningFormat, Key: cfg.Git.SigningKey})
+}
@@ -0,0 +1,323 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/guardrail"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// ErrGuardrail is returned when agent changes contain secrets or large blobs
+// that the agent did not remove when asked.
+var ErrGuardrail = errors.New("changes blocked by guardrails")
+
+// ErrPolicyViolation is returned when agent output changes paths the
+// guardrail policy forbids, or writes secrets that are not redacted.
+var ErrPolicyViolation = errors.New("file changes violate guardrail policy")
+
+// checkPolicy enforces the guardrail policy on file changes from agent
+// output before any is written: denied and protected paths reject the whole
+// batch, and secrets in written content either reject it or are redacted in
+// place. Each violation is published as a policy violation event.
+func (c *Conductor) checkPolicy(files []agent.FileChange) error {
+	if c.workspace == nil {
+		return nil
+	}
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil || cfg.Guardrails.Disabled {
+		return nil
+	}
+	settings := cfg.Guardrails
+	redact := settings.Secrets == guardrail.SecretsRedact
+
+	paths := make([]string, len(files))
+	for i, fc := range files {
+		paths[i] = fc.Path
+	}
+	policy := guardrail.Policy{Deny: settings.Deny, Allow: settings.Allow, Protected: settings.Protected}
+	rejected := policy.Check(paths, c.opts.AllowProtected)
+
+	scanner := newGuardrailScanner(settings)
+	suppressed := c.suppressedFindings()
+	for i := range files {
+		fc := &files[i]
+		if fc.Operation == agent.FileOpDelete || fc.Content == DeleteFileSentinel {
+			continue
+		}
+		findings := guardrail.Suppress(scanner.Scan([]guardrail.Change{{
+			Path:    fc.Path,
+			Size:    int64(len(fc.Content)),
+			Content: []byte(fc.Content),
+			Added:   guardrail.AllLines(fc.Content),
+		}}), suppressed)
+		for _, f := range findings {
+			if f.Kind != guardrail.KindSecret {
+				continue // Sizes are checked before checkpoints
+			}
+			if !redact {
+				rejected = append(rejected, f)
+
+				continue
+			}
+			c.publishPolicyViolation(f, "redacted")
+		}
+		if redact && len(findings) > 0 {
+			fc.Content, _ = scanner.Redact(fc.Path, fc.Content)
+		}
+	}
+	if len(rejected) == 0 {
+		return nil
+	}
+
+	var hints []string
+	for _, f := range rejected {
+		c.publishPolicyViolation(f, "rejected")
+		if f.Kind == guardrail.KindProtected && !slices.Contains(hints, protectedHint) {
+			hints = append(hints, protectedHint)
+		}
+		if f.ID != "" && !slices.Contains(hints, suppressHint) {
+			hints = append(hints, suppressHint)
+		}
+	}
+
+	return fmt.Errorf("%w:\n%s%s", ErrPolicyViolation, guardrail.Report(rejected), strings.Join(hints, ""))
+}
+
+// Hints appended to guardrail reports.
+const (
+	protectedHint = "Use --allow-protected to permit changes to protected paths\n"
+	suppressHint  = "Suppress a false positive with 'mehr guardrail suppress <id>'\n"
+)
+
+// suppressedFindings returns the IDs of the active task's suppressed
+// guardrail findings.
+func (c *Conductor) suppressedFindings() []string {
+	if c.workspace == nil || c.activeTask == nil {
+		return nil
+	}
+	ids, err := c.workspace.SuppressedIDs(c.activeTask.ID)
+	if err != nil {
+		c.logError(fmt.Errorf("load guardrail suppressions: %w", err))
+	}
+
+	return ids
+}
+
+// publishPolicyViolation publishes a guardrail policy violation.
+func (c *Conductor) publishPolicyViolation(f guardrail.Finding, action string) {
+	taskID := ""
+	if c.activeTask != nil {
+		taskID = c.activeTask.ID
+	}
+	c.eventBus.Publish(events.PolicyViolationEvent{TaskID: taskID, Path: f.Path, Rule: f.Rule, Action: action})
+}
+
+// enforceGuardrails scans the uncommitted changes before a checkpoint. When
+// something is found, the agent is asked once to remove it; if findings
+// remain, ErrGuardrail is returned with the report and no checkpoint should
+// be created.
+func (c *Conductor) enforceGuardrails(ctx context.Context, agentInst agent.Agent, usageStep string, step workflow.Step) error {
+	findings, err := c.scanChanges(ctx)
+	if err != nil {
+		c.logError(fmt.Errorf("guardrail scan: %w", err))
+
+		return nil
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+
+	c.publishProgress(fmt.Sprintf("Guardrails blocked %d change(s), asking the agent to remove them...", len(findings)), 75)
+	c.recordFailures(lessonSourceGuardrail, categorizeGuardrailFindings(findings))
+
+	response, err := agentInst.Run(ctx, guardrailFixPrompt(findings))
+	if err != nil {
+		return fmt.Errorf("%w (agent fix failed: %w):\n%s", ErrGuardrail, err, guardrail.Report(findings))
+	}
+	c.recordUsage(c.activeTask.ID, usageStep, step, agentInst, response.Usage)
+	if len(response.Files) > 0 {
+		if err := applyFiles(ctx, c, response.Files); err != nil {
+			return fmt.Errorf("apply guardrail fixes: %w", err)
+		}
+	}
+
+	findings, err = c.scanChanges(ctx)
+	if err != nil {
+		c.logError(fmt.Errorf("guardrail scan: %w", err))
+
+		return nil
+	}
+	if len(findings) > 0 {
+		return fmt.Errorf("%w:\n%s%s", ErrGuardrail, guardrail.Report(findings), suppressHint)
+	}
+
+	return nil
+}
+
+// scanChanges runs the guardrail scanner over the primary repository's
+// uncommitted changes: added lines of modified files, and every line of new
+// files.
+func (c *Conductor) scanChanges(ctx context.Context) ([]guardrail.Finding, error) {
+	if c.git == nil {
+		return nil, nil
+	}
+	scanner := c.guardrailScanner()
+	if scanner == nil {
+		return nil, nil
+	}
+
+	summary, err := c.git.GetChangeSummary(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list changes: %w", err)
+	}
+	if len(summary.Added)+len(summary.Modified) == 0 {
+		return nil, nil
+	}
+
+	modified := map[string][]guardrail.Line{}
+	if len(summary.Modified) > 0 {
+		args := append([]string{"HEAD", "--no-color", "--unified=0", "--"}, summary.Modified...)
+		diff, err := c.git.Diff(ctx, args...)
+		if err != nil {
+			return nil, fmt.Errorf("diff changes: %w", err)
+		}
+		modified = guardrail.AddedLines(diff)
+	}
+
+	root := c.git.Root()
+	maxFile, _ := scanner.Limits()
+	changes := make([]guardrail.Change, 0, len(summary.Added)+len(summary.Modified))
+	for _, file := range slices.Concat(summary.Added, summary.Modified) {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		change := guardrail.Change{Path: file, Size: info.Size()}
+		if lines, ok := modified[file]; ok {
+			change.Content, _ = readHead(path, guardrail.BinarySniffLen)
+			change.Added = lines
+		} else if info.Size() <= maxFile {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			change.Content = content
+			if !guardrail.IsBinary(content) {
+				change.Added = guardrail.AllLines(string(content))
+			}
+		} else {
+			change.Content, _ = readHead(path, guardrail.BinarySniffLen)
+		}
+		changes = append(changes, change)
+	}
+
+	return guardrail.Suppress(scanner.Scan(changes), c.suppressedFindings()), nil
+}
+
+// checkPush scans the commits a push would publish, those on head but not
+// on base, for secrets, and returns ErrGuardrail with the report when any
+// are found. Suppressed findings are left out, and a failed scan does not
+// block the push.
+func (c *Conductor) checkPush(ctx context.Context, git *vcs.Git, base, head string) error {
+	scanner := c.guardrailScanner()
+	if scanner == nil || git == nil {
+		return nil
+	}
+
+	diff, err := git.Diff(ctx, base+"..."+head, "--no-color", "--unified=0")
+	if err != nil {
+		c.logError(fmt.Errorf("guardrail push scan: %w", err))
+
+		return nil
+	}
+	added := guardrail.AddedLines(diff)
+	files := slices.Sorted(maps.Keys(added))
+	changes := make([]guardrail.Change, len(files))
+	for i, file := range files {
+		// Sizes are checked at checkpoints; only added lines are in the diff
+		changes[i] = guardrail.Change{Path: file, Added: added[file]}
+	}
+
+	findings := guardrail.Suppress(scanner.Scan(changes), c.suppressedFindings())
+	if len(findings) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: push of %s:\n%s%s", ErrGuardrail, head, guardrail.Report(findings), suppressHint)
+}
+
+// guardrailScanner returns the scanner configured for the workspace, or nil
+// when guardrails are disabled.
+func (c *Conductor) guardrailScanner() *guardrail.Scanner {
+	if c.workspace == nil {
+		return &guardrail.Scanner{}
+	}
+
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil {
+		return &guardrail.Scanner{}
+	}
+	if cfg.Guardrails.Disabled {
+		return nil
+	}
+
+	return newGuardrailScanner(cfg.Guardrails)
+}
+
+// newGuardrailScanner returns a scanner for the guardrail settings.
+func newGuardrailScanner(settings storage.GuardrailSettings) *guardrail.Scanner {
+	scanner := &guardrail.Scanner{
+		MaxFileSize:   settings.MaxFileSizeKB << 10,
+		MaxBinarySize: settings.MaxBinarySizeKB << 10,
+		Ignore:        settings.Ignore,
+	}
+	if settings.DisableEntropy {
+		scanner.Detectors = guardrail.PatternDetectors()
+	}
+
+	return scanner
+}
+
+// readHead reads up to n bytes from the start of a file.
+func readHead(path string, n int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	buf := make([]byte, n)
+	read, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return buf[:read], nil
+}
+
+// guardrailFixPrompt asks the agent to remove blocked content.
+func guardrailFixPrompt(findings []guardrail.Finding) string {
+	return `Your changes cannot be committed because they contain credentials or files
+that must not be stored in the repository:
+
+` + guardrail.Report(findings) + `
+Remove them without undoing the rest of your work:
+- Replace hard-coded credentials with configuration or environment variable lookups.
+- Delete binary or oversized files you generated, or produce them at build time instead.
+Do not print the secret values in your answer.
+`
+}
@@ -0,0 +1,266 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// fixingAgent answers a guardrail prompt with the given file changes.
+type fixingAgent struct {
+	mockAgent
+
+	files   []agent.FileChange
+	prompts []string
+}
+
+func (a *fixingAgent) Run(ctx context.Context, prompt string) (*agent.Response, error) {
+	a.prompts = append(a.prompts, prompt)
+
+	return &agent.Response{Summary: "Removed credentials", Files: a.files}, nil
+}
+
+func newGuardrailConductor(t *testing.T) (*Conductor, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	initGitRepo(t, tmpDir)
+
+	c, err := New(WithWorkDir(tmpDir), WithAutoInit(true))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	_ = c.Initialize(context.Background())
+	c.activeTask = &storage.ActiveTask{ID: "guard", UseGit: true}
+
+	token := "ghp_" + strings.Repeat("a1B2", 9)
+	if err := os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("# Test\n\ntoken: \""+token+"\"\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	return c, tmpDir
+}
+
+func TestScanChanges(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	c, tmpDir := newGuardrailConductor(t)
+	blob := make([]byte, 200<<10)
+	blob[0] = 0
+	if err := os.MkdirAll(filepath.Join(tmpDir, "assets"), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "assets", "model.bin"), blob, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	findings, err := c.scanChanges(context.Background())
+	if err != nil {
+		t.Fatalf("scanChanges: %v", err)
+	}
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Kind+" "+f.Path)
+	}
+	want := "binary assets/model.bin,secret README.md"
+	if strings.Join(got, ",") != want {
+		t.Errorf("findings = %v, want %s", got, want)
+	}
+	if findings[1].Line != 3 {
+		t.Errorf("secret line = %d, want 3", findings[1].Line)
+	}
+}
+
+func TestEnforceGuardrails(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	t.Run("agent removes the secret", func(t *testing.T) {
+		c, _ := newGuardrailConductor(t)
+		fixer := &fixingAgent{mockAgent: mockAgent{name: "mock"}, files: []agent.FileChange{
+			{Path: "README.md", Operation: agent.FileOpUpdate, Content: "# Test\n\ntoken: os.Getenv(\"GITHUB_TOKEN\")\n"},
+		}}
+
+		if err := c.enforceGuardrails(context.Background(), fixer, "implementing", workflow.StepImplementing); err != nil {
+			t.Fatalf("enforceGuardrails: %v", err)
+		}
+		if len(fixer.prompts) != 1 || !strings.Contains(fixer.prompts[0], "README.md:3: GitHub token") {
+			t.Errorf("fix prompt = %q", fixer.prompts)
+		}
+	})
+
+	t.Run("blocked when the secret remains", func(t *testing.T) {
+		c, _ := newGuardrailConductor(t)
+
+		err := c.enforceGuardrails(context.Background(), &fixingAgent{mockAgent: mockAgent{name: "mock"}}, "implementing", workflow.StepImplementing)
+		if !errors.Is(err, ErrGuardrail) || !strings.Contains(err.Error(), "README.md:3") {
+			t.Errorf("enforceGuardrails() error = %v, want ErrGuardrail report", err)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		c, _ := newGuardrailConductor(t)
+		cfg, err := c.workspace.LoadConfig()
+		if err != nil {
+			t.Fatalf("LoadConfig: %v", err)
+		}
+		cfg.Guardrails.Disabled = true
+		if err := c.workspace.SaveConfig(cfg); err != nil {
+			t.Fatalf("SaveConfig: %v", err)
+		}
+
+		if err := c.enforceGuardrails(context.Background(), &fixingAgent{mockAgent: mockAgent{name: "mock"}}, "implementing", workflow.StepImplementing); err != nil {
+			t.Errorf("enforceGuardrails() with guardrails disabled = %v", err)
+		}
+	})
+}
+
+func TestCheckPolicy(t *testing.T) {
+	token := "ghp_" + strings.Repeat("a1B2", 9)
+
+	newPolicyConductor := func(t *testing.T, settings storage.GuardrailSettings, opts ...Option) (*Conductor, *[]events.Event) {
+		t.Helper()
+
+		root := t.TempDir()
+		c, err := New(append([]Option{WithWorkDir(root)}, opts...)...)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		ws, err := storage.OpenWorkspace(root, nil)
+		if err != nil {
+			t.Fatalf("OpenWorkspace: %v", err)
+		}
+		cfg := storage.NewDefaultWorkspaceConfig()
+		cfg.Guardrails = settings
+		if err := ws.SaveConfig(cfg); err != nil {
+			t.Fatalf("SaveConfig: %v", err)
+		}
+		c.workspace = ws
+		c.activeTask = &storage.ActiveTask{ID: "guard"}
+
+		var published []events.Event
+		c.eventBus.Subscribe(events.TypePolicyViolation, func(e events.Event) {
+			published = append(published, e)
+		})
+
+		return c, &published
+	}
+	paths := storage.GuardrailSettings{Deny: []string{"deploy/**"}, Allow: []string{"deploy/README.md"}, Protected: []string{"go.sum"}}
+
+	t.Run("denied path rejects the batch", func(t *testing.T) {
+		c, published := newPolicyConductor(t, paths)
+
+		err := c.checkPolicy([]agent.FileChange{
+			{Path: "main.go", Operation: agent.FileOpUpdate, Content: "package main\n"},
+			{Path: "deploy/prod.yaml", Operation: agent.FileOpUpdate, Content: "replicas: 3\n"},
+			{Path: "deploy/README.md", Operation: agent.FileOpUpdate, Content: "# Deploy\n"},
+		})
+		if !errors.Is(err, ErrPolicyViolation) || !strings.Contains(err.Error(), "deploy/prod.yaml") || strings.Contains(err.Error(), "README") {
+			t.Errorf("checkPolicy() error = %v, want deploy/prod.yaml rejected", err)
+		}
+		if len(*published) != 1 || (*published)[0].Data["action"] != "rejected" || (*published)[0].Data["task_id"] != "guard" {
+			t.Errorf("events = %v, want one rejection", *published)
+		}
+	})
+
+	t.Run("protected path needs permission", func(t *testing.T) {
+		files := []agent.FileChange{{Path: "go.sum", Operation: agent.FileOpUpdate, Content: "example.com/m v1.0.0 h1:x\n"}}
+
+		c, _ := newPolicyConductor(t, paths)
+		if err := c.checkPolicy(files); !errors.Is(err, ErrPolicyViolation) || !strings.Contains(err.Error(), "--allow-protected") {
+			t.Errorf("checkPolicy() error = %v, want protected path hint", err)
+		}
+		c, _ = newPolicyConductor(t, paths, WithAllowProtected(true))
+		if err := c.checkPolicy(files); err != nil {
+			t.Errorf("checkPolicy(allow protected) = %v", err)
+		}
+	})
+
+	t.Run("secrets are rejected by default", func(t *testing.T) {
+		c, _ := newPolicyConductor(t, storage.GuardrailSettings{})
+
+		err := c.checkPolicy([]agent.FileChange{{Path: "config.go", Operation: agent.FileOpCreate, Content: "package config\n\nconst token = \"" + token + "\"\n"}})
+		if !errors.Is(err, ErrPolicyViolation) || !strings.Contains(err.Error(), "config.go:3: GitHub token") {
+			t.Errorf("checkPolicy() error = %v, want secret rejected", err)
+		}
+	})
+
+	t.Run("secrets are redacted", func(t *testing.T) {
+		c, published := newPolicyConductor(t, storage.GuardrailSettings{Secrets: "redact"})
+
+		files := []agent.FileChange{{Path: "config.go", Operation: agent.FileOpCreate, Content: "package config\n\nconst token = \"" + token + "\"\n"}}
+		if err := c.checkPolicy(files); err != nil {
+			t.Fatalf("checkPolicy() = %v", err)
+		}
+		if want := "package config\n\nconst token = \"REDACTED\"\n"; files[0].Content != want {
+			t.Errorf("content = %q, want %q", files[0].Content, want)
+		}
+		if len(*published) != 1 || (*published)[0].Data["action"] != "redacted" {
+			t.Errorf("events = %v, want one redaction", *published)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		settings := paths
+		settings.Disabled = true
+		c, _ := newPolicyConductor(t, settings)
+
+		if err := c.checkPolicy([]agent.FileChange{{Path: "deploy/prod.yaml", Operation: agent.FileOpUpdate, Content: "replicas: 3\n"}}); err != nil {
+			t.Errorf("checkPolicy() with guardrails disabled = %v", err)
+		}
+	})
+}
+
+func TestCheckPush(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	c, tmpDir := newGuardrailConductor(t)
+	if err := runGitCmd(ctx, tmpDir, "checkout", "-b", "feature"); err != nil {
+		t.Fatalf("git checkout: %v", err)
+	}
+	if err := runGitCmd(ctx, tmpDir, "commit", "-am", "add token"); err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+
+	err := c.checkPush(ctx, c.git, "HEAD~1", "feature")
+	if !errors.Is(err, ErrGuardrail) || !strings.Contains(err.Error(), "README.md:3: GitHub token") || !strings.Contains(err.Error(), "mehr guardrail suppress") {
+		t.Fatalf("checkPush() error = %v, want the token reported", err)
+	}
+
+	// Suppressed findings no longer block the push
+	id := findingID(t, err)
+	if err := c.workspace.AddSuppression("guard", storage.Suppression{ID: id, Reason: "test token"}); err != nil {
+		t.Fatalf("AddSuppression: %v", err)
+	}
+	if err := c.checkPush(ctx, c.git, "HEAD~1", "feature"); err != nil {
+		t.Errorf("checkPush() with the finding suppressed = %v", err)
+	}
+}
+
+// findingID returns the ID of the first finding in a guardrail error.
+func findingID(t *testing.T, err error) string {
+	t.Helper()
+
+	_, rest, ok := strings.Cut(err.Error(), "[")
+	id, _, ok2 := strings.Cut(rest, "]")
+	if !ok || !ok2 || len(id) != 12 {
+		t.Fatalf("no finding ID in %v", err)
+	}
+
+	return id
+}
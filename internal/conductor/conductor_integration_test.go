@@ -2,6 +2,7 @@ package conductor
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -10,7 +11,9 @@ import (
 
 	"github.com/valksor/go-mehrhof/internal/agent"
 	"github.com/valksor/go-mehrhof/internal/provider/file"
+	"github.com/valksor/go-mehrhof/internal/providertest"
 	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
 	"github.com/valksor/go-mehrhof/internal/workflow"
 )
 
@@ -415,7 +418,7 @@ func TestUndo_NoGit(t *testing.T) {
 	if err == nil {
 		t.Error("Undo should fail when git is not available")
 	}
-	if err.Error() != "git not available" {
+	if !errors.Is(err, vcs.ErrUnsupported) {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
@@ -445,11 +448,29 @@ func TestRedo_NoGit(t *testing.T) {
 	if err == nil {
 		t.Error("Redo should fail when git is not available")
 	}
-	if err.Error() != "git not available" {
+	if !errors.Is(err, vcs.ErrUnsupported) {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
 
+func TestGetVCS_NoGit(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	c, err := New(WithWorkDir(tmpDir))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	v := c.GetVCS()
+	if v.Name() != "none" || v.Root() != tmpDir {
+		t.Errorf("GetVCS() = %s at %s, want none at %s", v.Name(), v.Root(), tmpDir)
+	}
+	c.activeTask = &storage.ActiveTask{ID: "test-task"}
+	if c.countCheckpoints() != 0 {
+		t.Error("countCheckpoints() should be 0 without version control")
+	}
+}
+
 func TestFinish_NoActiveTask(t *testing.T) {
 	ctx := context.Background()
 
@@ -1086,6 +1107,116 @@ This is a test task description.
 	}
 }
 
+// TestStart_WithScope stores a validated monorepo scope on the task.
+func TestStart_WithScope(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	tmpDir := t.TempDir()
+	ctx := context.Background()
+
+	if err := os.MkdirAll(filepath.Join(tmpDir, "services", "api"), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	taskPath := filepath.Join(tmpDir, "task.md")
+	if err := os.WriteFile(taskPath, []byte("# Scoped task\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	newConductor := func(scope string) *Conductor {
+		c, err := New(WithWorkDir(tmpDir), WithCreateBranch(false), WithAgent("mock"), WithScope(scope))
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		file.Register(c.GetProviderRegistry())
+		if err := c.GetAgentRegistry().Register(&mockAgent{name: "mock"}); err != nil {
+			t.Fatalf("Register mock agent: %v", err)
+		}
+		if err := c.Initialize(ctx); err != nil {
+			t.Fatalf("Initialize: %v", err)
+		}
+
+		return c
+	}
+
+	if err := newConductor("services/missing").Start(ctx, "file:"+taskPath); err == nil {
+		t.Fatal("Start with missing scope directory should fail")
+	}
+
+	c := newConductor("services/api/")
+	if err := c.Start(ctx, "file:"+taskPath); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if got := c.GetTaskWork().Metadata.Scope; got != "services/api" {
+		t.Errorf("scope = %q, want %q", got, "services/api")
+	}
+
+	status, err := c.Status()
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.Scope != "services/api" {
+		t.Errorf("status scope = %q, want %q", status.Scope, "services/api")
+	}
+}
+
+// TestStart_WithGitHubReference starts a task from a fake GitHub issue.
+func TestStart_WithGitHubReference(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	tmpDir := t.TempDir()
+	ctx := context.Background()
+
+	gh := providertest.NewGitHub(t)
+	gh.Repo = "acme/app"
+	gh.AddIssue("acme/app", providertest.Issue{
+		Number:   42,
+		Title:    "Fix flaky login",
+		Body:     "Login fails intermittently.",
+		Labels:   []string{"bug"},
+		Comments: []providertest.Comment{{Author: "alice", Body: "Seen on staging"}},
+	})
+
+	c, err := New(WithWorkDir(tmpDir), WithCreateBranch(false), WithAgent("mock"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	gh.Register(c.GetProviderRegistry())
+	if err := c.GetAgentRegistry().Register(&mockAgent{name: "mock"}); err != nil {
+		t.Fatalf("Register mock agent: %v", err)
+	}
+	if err := c.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	if err := c.Start(ctx, "github:acme/app#42"); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	taskWork := c.GetTaskWork()
+	if taskWork == nil {
+		t.Fatal("GetTaskWork returned nil")
+	}
+	if taskWork.Metadata.Title != "Fix flaky login" {
+		t.Errorf("task title = %q, want %q", taskWork.Metadata.Title, "Fix flaky login")
+	}
+	if taskWork.Metadata.ExternalKey != "42" {
+		t.Errorf("external key = %q, want %q", taskWork.Metadata.ExternalKey, "42")
+	}
+
+	// The issue snapshot (including comments) is written to the work directory
+	sourceDir := filepath.Join(c.GetWorkspace().WorkPath(taskWork.Metadata.ID), "source")
+	for _, name := range []string{"issue.md", "comments.md"} {
+		if _, err := os.Stat(filepath.Join(sourceDir, name)); err != nil {
+			t.Errorf("source/%s not written: %v", name, err)
+		}
+	}
+}
+
 // TestStatus_Integration tests getting status when there's an active task.
 func TestStatus_Integration(t *testing.T) {
 	tmpDir := t.TempDir()
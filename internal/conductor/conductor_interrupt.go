@@ -0,0 +1,127 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// ErrCanceled is returned when a phase is interrupted, e.g. by Ctrl-C. The
+// task is left idle so the phase can be run again.
+var ErrCanceled = errors.New("canceled")
+
+// interruptGuard remembers which files had uncommitted changes before an
+// agent run, so an interrupted run only rolls back what the run changed.
+type interruptGuard struct {
+	dirty map[string]bool
+	ok    bool
+}
+
+// guardInterrupt records the uncommitted changes before an agent run. Without
+// git there is no checkpoint to return to and the guard does nothing.
+func (c *Conductor) guardInterrupt(ctx context.Context) *interruptGuard {
+	guard := &interruptGuard{dirty: make(map[string]bool)}
+	if c.git == nil || c.activeTask == nil || !c.activeTask.UseGit || c.opts.DryRun {
+		return guard
+	}
+
+	changed, err := c.git.ChangedFiles(ctx)
+	if err != nil {
+		c.logError(fmt.Errorf("record changes before run: %w", err))
+
+		return guard
+	}
+	for _, file := range changed {
+		guard.dirty[file] = true
+	}
+	guard.ok = true
+
+	return guard
+}
+
+// interrupted handles a phase cut short by cancellation: it rolls back the
+// files the run changed to the last checkpoint, saves the partial session
+// marked canceled and returns the task to idle. The returned error wraps
+// ErrCanceled and cause.
+func (c *Conductor) interrupted(ctx context.Context, taskID string, guard *interruptGuard, cause error) error {
+	// A phase deadline that pauses the task keeps the work for a checkpoint
+	keep := c.pausingOnTimeout(ctx)
+
+	// The phase context is already done; clean up regardless
+	ctx = context.WithoutCancel(ctx)
+
+	if guard != nil && guard.ok && !keep {
+		if n, err := c.rollbackInterrupted(ctx, guard); err != nil {
+			c.logError(fmt.Errorf("roll back interrupted run: %w", err))
+		} else if n > 0 {
+			c.publishProgress(fmt.Sprintf("Interrupted: rolled back %d file(s) to the last checkpoint", n), 0)
+		}
+	}
+
+	c.activeTask.State = "idle"
+	if err := c.workspace.SaveActiveTask(c.activeTask); err != nil {
+		c.logError(fmt.Errorf("save active task after interrupt: %w", err))
+	}
+	_ = c.machine.Dispatch(ctx, workflow.EventError)
+
+	if c.currentSession != nil {
+		c.currentSession.Metadata.Canceled = true
+	}
+	c.saveCurrentSession(taskID)
+
+	return fmt.Errorf("%w: %w", ErrCanceled, cause)
+}
+
+// rollbackInterrupted restores every file changed since the guard was taken
+// and returns how many were restored. Files that already had uncommitted
+// changes before the run are left alone, as their earlier state is unknown.
+func (c *Conductor) rollbackInterrupted(ctx context.Context, guard *interruptGuard) (int, error) {
+	changed, err := c.git.ChangedFiles(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Never touch mehrhof's own files, which the run updates on purpose
+	var paths []string
+	for _, file := range c.withoutOwnFiles(changed) {
+		if !guard.dirty[file] {
+			paths = append(paths, file)
+		}
+	}
+	if len(paths) == 0 {
+		return 0, nil
+	}
+	if err := c.git.RestorePaths(ctx, paths...); err != nil {
+		return 0, err
+	}
+	for _, file := range paths {
+		delete(c.agentFiles, file)
+	}
+
+	return len(paths), nil
+}
+
+// withoutOwnFiles drops mehrhof's own files, those under its task and work
+// directories and the active task file, from a list of changed files
+// relative to the repository root.
+func (c *Conductor) withoutOwnFiles(files []string) []string {
+	var skip []string
+	for _, dir := range []string{c.workspace.TaskRoot(), c.workspace.WorkRoot()} {
+		if rel, err := filepath.Rel(c.git.Root(), dir); err == nil && !strings.HasPrefix(rel, "..") {
+			skip = append(skip, filepath.ToSlash(rel)+"/")
+		}
+	}
+	// .active_task and its backup
+	if rel, err := filepath.Rel(c.git.Root(), c.workspace.ActiveTaskPath()); err == nil && !strings.HasPrefix(rel, "..") {
+		skip = append(skip, filepath.ToSlash(rel))
+	}
+
+	return slices.DeleteFunc(files, func(file string) bool {
+		return slices.ContainsFunc(skip, func(dir string) bool { return strings.HasPrefix(file, dir) })
+	})
+}
@@ -0,0 +1,88 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestInterrupted(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	tmpDir := t.TempDir()
+	initGitRepo(t, tmpDir)
+
+	c, err := New(WithWorkDir(tmpDir), WithAgent("mock"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := c.GetAgentRegistry().Register(&mockAgent{name: "mock"}); err != nil {
+		t.Fatalf("Register agent: %v", err)
+	}
+	if err := c.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if _, err := c.workspace.CreateWork("cancel", storage.SourceInfo{Type: "file", Ref: "task.md"}); err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	c.activeTask = &storage.ActiveTask{ID: "cancel", State: "implementing", UseGit: true}
+	session, filename, err := c.workspace.CreateSession("cancel", "implementation", "mock", "implementing")
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	c.currentSession, c.currentSessionFile = session, filename
+
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The user's own edit before the run survives the rollback
+	write("notes.txt", "mine")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	guard := c.guardInterrupt(ctx)
+
+	// The agent's partial changes are rolled back
+	write("README.md", "half-written")
+	write("src/new.go", "package src")
+	cancel()
+
+	err = c.interrupted(ctx, "cancel", guard, ctx.Err())
+	if !errors.Is(err, ErrCanceled) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("interrupted error = %v, want ErrCanceled wrapping context.Canceled", err)
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "README.md")); string(data) != "# Test\n" {
+		t.Errorf("README.md = %q, want the checkpointed content", data)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "src", "new.go")); !os.IsNotExist(err) {
+		t.Error("src/new.go should be removed")
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "notes.txt")); string(data) != "mine" {
+		t.Errorf("notes.txt = %q, want the user's edit kept", data)
+	}
+
+	if c.activeTask.State != "idle" {
+		t.Errorf("task state = %q, want idle", c.activeTask.State)
+	}
+	saved, err := c.workspace.LoadSession("cancel", filename)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if !saved.Metadata.Canceled || saved.Metadata.EndedAt.IsZero() {
+		t.Errorf("session metadata = %+v, want canceled and ended", saved.Metadata)
+	}
+}
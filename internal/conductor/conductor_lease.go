@@ -0,0 +1,90 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// ErrLeaseLost is returned when a conductor loses the lease on its task while
+// operating on it, because it was stolen or could not be renewed in time.
+var ErrLeaseLost = errors.New("task lease lost")
+
+// acquireLease takes the lease on the active task and keeps it alive with a
+// heartbeat until the returned release function is called. It prevents two
+// conductors (e.g., on a shared repository) from operating on the same task.
+//
+// The operation must run on the returned context, which is cancelled if the
+// lease is lost. Release with a pointer to the operation's error, which is
+// then set to the ErrLeaseLost error.
+func (c *Conductor) acquireLease(ctx context.Context) (context.Context, func(*error), error) {
+	if c.activeTask == nil || c.workspace == nil {
+		return ctx, func(*error) {}, nil
+	}
+
+	ttl := c.opts.LeaseTTL
+	if ttl <= 0 {
+		ttl = storage.DefaultLeaseTTL
+	}
+
+	lease, err := c.workspace.AcquireTaskLease(c.activeTask.ID, ttl)
+	if err != nil {
+		if errors.Is(err, storage.ErrLeaseHeld) {
+			return nil, nil, fmt.Errorf("%w (run 'mehr task steal' once it expires)", err)
+		}
+
+		return nil, nil, fmt.Errorf("acquire task lease: %w", err)
+	}
+
+	opCtx, cancel := context.WithCancelCause(ctx)
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-opCtx.Done():
+				return
+			case <-ticker.C:
+				err := c.workspace.RenewTaskLease(lease, ttl)
+				if err == nil {
+					continue
+				}
+				// A failed renewal is retried while the lease is still ours
+				if errors.Is(err, storage.ErrLeaseHeld) || !time.Now().Before(lease.ExpiresAt) {
+					cancel(fmt.Errorf("%w: %w", ErrLeaseLost, err))
+
+					return
+				}
+				c.logError(fmt.Errorf("renew task lease: %w", err))
+			}
+		}
+	})
+
+	return opCtx, func(errp *error) {
+		cancel(nil)
+		wg.Wait()
+		if err := c.workspace.ReleaseTaskLease(lease); err != nil {
+			c.logError(fmt.Errorf("release task lease: %w", err))
+		}
+
+		lost := context.Cause(opCtx)
+		if !errors.Is(lost, ErrLeaseLost) {
+			c.autoPushWork(ctx, lease.TaskID)
+
+			return
+		}
+		// The operation may have failed only because it was cancelled, and
+		// its work must not be pushed over the new holder's
+		if *errp == nil || errors.Is(*errp, context.Canceled) {
+			*errp = lost
+		} else {
+			*errp = errors.Join(lost, *errp)
+		}
+	}, nil
+}
@@ -0,0 +1,222 @@
+package conductor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/guardrail"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// Checks whose failures are recorded as lessons.
+const (
+	lessonSourceQuality   = "quality"
+	lessonSourceGuardrail = "guardrail"
+	lessonSourceReview    = "review"
+)
+
+// maxLessonExample caps the failure detail kept per lesson.
+const maxLessonExample = 200
+
+// failure is one categorized failure from a check.
+type failure struct {
+	category string
+	example  string
+}
+
+// qualityCategories map quality check output to failure categories, most
+// specific first.
+var qualityCategories = []struct {
+	category string
+	pattern  *regexp.Regexp
+}{
+	{"failing tests", regexp.MustCompile(`(?m)^\s*(--- FAIL|FAIL\s|FAILED\s|not ok \d)|\b\d+ (tests? )?failed\b`)},
+	{"build errors", regexp.MustCompile(`(?m)(undefined: |cannot use |syntax error|error TS\d+|cannot find (module|package)|build failed)`)},
+	{"lint findings", regexp.MustCompile(`(?m)^\S+:\d+:\d+: .*\([\w-]+\)\s*$|golangci-lint|eslint`)},
+	{"formatting", regexp.MustCompile(`(?mi)(gofmt|goimports|not (properly )?formatted|would reformat|prettier)`)},
+}
+
+// categorizeQualityOutput returns the failure categories found in the output
+// of a failed quality check, each with the first matching line.
+func categorizeQualityOutput(output string) []failure {
+	var failures []failure
+	for _, qc := range qualityCategories {
+		loc := qc.pattern.FindStringIndex(output)
+		if loc == nil {
+			continue
+		}
+		failures = append(failures, failure{category: qc.category, example: lineAt(output, loc[0])})
+	}
+	if len(failures) == 0 {
+		failures = append(failures, failure{category: "quality check failure", example: lastLine(output)})
+	}
+
+	return failures
+}
+
+// guardrailCategories names the lesson for each guardrail finding kind.
+var guardrailCategories = map[string]string{
+	guardrail.KindSecret:    "committed secrets",
+	guardrail.KindLargeFile: "large files",
+	guardrail.KindBinary:    "binary files",
+}
+
+// categorizeGuardrailFindings returns one failure per finding kind.
+func categorizeGuardrailFindings(findings []guardrail.Finding) []failure {
+	var failures []failure
+	seen := make(map[string]bool)
+	for _, f := range findings {
+		category, ok := guardrailCategories[f.Kind]
+		if !ok {
+			category = f.Kind
+		}
+		if seen[category] {
+			continue
+		}
+		seen[category] = true
+		failures = append(failures, failure{category: category, example: f.String()})
+	}
+
+	return failures
+}
+
+// reviewTagPattern matches the area tags the review prompt asks for.
+var reviewTagPattern = regexp.MustCompile(`(?i)\[(correctness|code quality|quality|security|performance|best[ -]practices|lint)\]`)
+
+// categorizeReview returns one failure per tagged review area, each with the
+// first issue line carrying the tag.
+func categorizeReview(review string) []failure {
+	var failures []failure
+	seen := make(map[string]bool)
+	for _, loc := range reviewTagPattern.FindAllStringSubmatchIndex(review, -1) {
+		category := strings.ToLower(review[loc[2]:loc[3]])
+		category = strings.ReplaceAll(category, "-", " ")
+		if category == "quality" {
+			category = "code quality"
+		}
+		if seen[category] {
+			continue
+		}
+		seen[category] = true
+		failures = append(failures, failure{category: category, example: lineAt(review, loc[0])})
+	}
+
+	return failures
+}
+
+// lessonGuidance tells the agent how to avoid each failure category.
+var lessonGuidance = map[string]string{
+	"failing tests":         "Run the affected tests and make them pass; do not weaken or skip assertions.",
+	"build errors":          "Check that every file compiles: imports, identifiers and types must match the code you reference.",
+	"lint findings":         "Follow the project's linter rules in new and changed code.",
+	"formatting":            "Format every changed file with the project's formatter.",
+	"quality check failure": "Run the project's quality checks mentally before finishing and fix what they report.",
+	"committed secrets":     "Never write credentials, tokens or keys into files; read them from the environment.",
+	"large files":           "Do not add large generated or data files to the repository.",
+	"binary files":          "Do not add binary files to the repository.",
+	"correctness":           "Verify the implementation against every requirement in the specification.",
+	"code quality":          "Keep changes small, readable and consistent with the surrounding code.",
+	"security":              "Validate input and avoid injection, unsafe defaults and leaked data.",
+	"performance":           "Avoid needless allocations, repeated work and unbounded loops.",
+	"best practices":        "Follow the language's and project's established conventions.",
+	"lint":                  "Address automated linter findings before finishing.",
+}
+
+// recordFailures counts the failures of a check in the task's lessons and
+// persists them in work.yaml.
+func (c *Conductor) recordFailures(source string, failures []failure) {
+	if c.taskWork == nil || len(failures) == 0 {
+		return
+	}
+
+	now := time.Now()
+	for _, f := range failures {
+		c.taskWork.RecordLesson(source, f.category, truncateExample(f.example), now)
+	}
+	if err := c.workspace.SaveWork(c.taskWork); err != nil {
+		c.logError(fmt.Errorf("save lessons: %w", err))
+	}
+}
+
+// lessonThreshold returns the failure count after which a category is
+// included in prompts, or 0 when lessons are disabled.
+func (c *Conductor) lessonThreshold() int {
+	threshold := storage.DefaultLessonThreshold
+	if c.workspace != nil {
+		if cfg, err := c.workspace.LoadConfig(); err == nil && cfg.Workflow.LessonThreshold != 0 {
+			threshold = cfg.Workflow.LessonThreshold
+		}
+	}
+	if threshold < 0 {
+		return 0
+	}
+
+	return threshold
+}
+
+// lessonsPrompt returns prompt instructions listing the failure categories
+// that keep recurring for the task.
+func (c *Conductor) lessonsPrompt() string {
+	if c.taskWork == nil {
+		return ""
+	}
+	threshold := c.lessonThreshold()
+	if threshold == 0 {
+		return ""
+	}
+
+	return buildLessonsPrompt(c.taskWork.RecurringLessons(threshold))
+}
+
+// buildLessonsPrompt formats recurring lessons for a prompt.
+func buildLessonsPrompt(lessons []storage.Lesson) string {
+	if len(lessons) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n## Lessons From Earlier Attempts\n")
+	sb.WriteString("Checks on this task have failed repeatedly in these areas. Do not repeat these mistakes:\n")
+	for _, lesson := range lessons {
+		fmt.Fprintf(&sb, "- %s (%s, %d times)", lesson.Category, lesson.Source, lesson.Count)
+		if guidance := lessonGuidance[lesson.Category]; guidance != "" {
+			sb.WriteString(": " + guidance)
+		}
+		if lesson.Example != "" {
+			fmt.Fprintf(&sb, " Last seen: %s", lesson.Example)
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// lineAt returns the trimmed line containing offset i of s.
+func lineAt(s string, i int) string {
+	start := strings.LastIndex(s[:i], "\n") + 1
+	end := strings.IndexByte(s[i:], '\n')
+	if end < 0 {
+		return strings.TrimSpace(s[start:])
+	}
+
+	return strings.TrimSpace(s[start : i+end])
+}
+
+// lastLine returns the last non-empty line of s.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// truncateExample shortens a failure detail to maxLessonExample runes.
+func truncateExample(s string) string {
+	runes := []rune(s)
+	if len(runes) <= maxLessonExample {
+		return s
+	}
+
+	return string(runes[:maxLessonExample-3]) + "..."
+}
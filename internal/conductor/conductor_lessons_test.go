@@ -0,0 +1,142 @@
+package conductor
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/guardrail"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func categories(failures []failure) []string {
+	var names []string
+	for _, f := range failures {
+		names = append(names, f.category)
+	}
+
+	return names
+}
+
+func TestCategorizeQualityOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{
+			name:   "go test failure",
+			output: "go test ./...\n--- FAIL: TestParse (0.00s)\n    parse_test.go:12: got 1, want 2\nFAIL\tgithub.com/x/y\t0.01s\n",
+			want:   []string{"failing tests"},
+		},
+		{
+			name:   "compile error and lint",
+			output: "main.go:3:2: undefined: foo\nhandler.go:10:5: ineffectual assignment to err (ineffassign)\n",
+			want:   []string{"build errors", "lint findings"},
+		},
+		{
+			name:   "formatting",
+			output: "gofmt -l .\nmain.go\nFiles are not formatted\n",
+			want:   []string{"formatting"},
+		},
+		{
+			name:   "unknown",
+			output: "make: *** [quality] Error 2\n",
+			want:   []string{"quality check failure"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := categories(categorizeQualityOutput(tt.output)); !slices.Equal(got, tt.want) {
+				t.Errorf("categories = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	failures := categorizeQualityOutput("ok\n--- FAIL: TestParse (0.00s)\n")
+	if failures[0].example != "--- FAIL: TestParse (0.00s)" {
+		t.Errorf("example = %q, want the matching line", failures[0].example)
+	}
+}
+
+func TestCategorizeGuardrailFindings(t *testing.T) {
+	failures := categorizeGuardrailFindings([]guardrail.Finding{
+		{Kind: guardrail.KindSecret, Path: "config.go", Line: 3, Rule: "aws-access-key"},
+		{Kind: guardrail.KindSecret, Path: "main.go", Line: 9, Rule: "github-token"},
+		{Kind: guardrail.KindBinary, Path: "app.bin"},
+	})
+
+	if got := categories(failures); !slices.Equal(got, []string{"committed secrets", "binary files"}) {
+		t.Errorf("categories = %v", got)
+	}
+}
+
+func TestCategorizeReview(t *testing.T) {
+	review := `## Issues
+- major [Security] SQL query built with string concatenation in store.go
+- minor [code quality] duplicated parsing logic
+- minor [security] token logged at debug level
+- minor [best-practices] exported function without doc comment`
+
+	failures := categorizeReview(review)
+	if got := categories(failures); !slices.Equal(got, []string{"security", "code quality", "best practices"}) {
+		t.Errorf("categories = %v", got)
+	}
+	if !strings.Contains(failures[0].example, "string concatenation") {
+		t.Errorf("example = %q", failures[0].example)
+	}
+
+	if failures := categorizeReview("Looks good, no issues found."); len(failures) != 0 {
+		t.Errorf("untagged review should have no failures, got %v", categories(failures))
+	}
+}
+
+func TestLessonsPrompt(t *testing.T) {
+	c := newPlanningConductor(t, &mockAgent{name: "mock"})
+
+	c.recordFailures(lessonSourceQuality, categorizeQualityOutput("--- FAIL: TestA\n"))
+	if got := c.lessonsPrompt(); got != "" {
+		t.Errorf("a single failure should not produce lessons, got %q", got)
+	}
+
+	c.recordFailures(lessonSourceQuality, categorizeQualityOutput("--- FAIL: TestB\n"))
+	c.recordFailures(lessonSourceReview, []failure{{category: "security"}})
+
+	prompt := c.lessonsPrompt()
+	for _, want := range []string{"## Lessons From Earlier Attempts", "failing tests (quality, 2 times)", "Last seen: --- FAIL: TestB"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "security") {
+		t.Errorf("lessons below the threshold should be left out:\n%s", prompt)
+	}
+
+	// Lessons are persisted in work.yaml
+	work, err := c.workspace.LoadWork(c.activeTask.ID)
+	if err != nil {
+		t.Fatalf("LoadWork: %v", err)
+	}
+	if len(work.Lessons) != 2 || work.Lessons[0].Count != 2 {
+		t.Errorf("persisted lessons = %+v", work.Lessons)
+	}
+
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	cfg.Workflow.LessonThreshold = -1
+	if err := c.workspace.SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	if got := c.lessonsPrompt(); got != "" {
+		t.Errorf("lessons should be disabled, got %q", got)
+	}
+}
+
+func TestBuildLessonsPrompt_Empty(t *testing.T) {
+	if got := buildLessonsPrompt([]storage.Lesson{}); got != "" {
+		t.Errorf("buildLessonsPrompt(nil) = %q", got)
+	}
+}
@@ -11,9 +11,14 @@ import (
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/valksor/go-mehrhof/internal/chaos"
+	"github.com/valksor/go-mehrhof/internal/config"
+	"github.com/valksor/go-mehrhof/internal/events"
 	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/provider/httpclient"
 	"github.com/valksor/go-mehrhof/internal/storage"
 	"github.com/valksor/go-mehrhof/internal/vcs"
+	"github.com/valksor/go-mehrhof/internal/workflow"
 )
 
 // Initialize sets up the conductor for a repository.
@@ -24,7 +29,7 @@ func (c *Conductor) Initialize(ctx context.Context) error {
 	// Initialize git (optional - might not be in a git repo)
 	git, err := vcs.New(ctx, c.opts.WorkDir)
 	if err == nil {
-		c.git = git
+		c.git = c.auditedGit(git, "")
 	}
 
 	// Determine workspace root
@@ -45,7 +50,7 @@ func (c *Conductor) Initialize(ctx context.Context) error {
 
 	// Load workspace config to get work directory setting
 	var cfg *storage.WorkspaceConfig
-	configPath := filepath.Join(root, ".mehrhof", "config.yaml")
+	configPath := filepath.Join(config.TaskRoot(root), "config.yaml")
 	if data, err := os.ReadFile(configPath); err == nil {
 		cfg = storage.NewDefaultWorkspaceConfig()
 		if err := yaml.Unmarshal(data, cfg); err != nil {
@@ -60,6 +65,9 @@ func (c *Conductor) Initialize(ctx context.Context) error {
 	}
 	c.workspace = ws
 
+	// Report provider API rate limit waits instead of stalling silently
+	httpclient.SharedRateLimiter().OnWait(c.publishRateLimitWait)
+
 	// Auto-initialize if requested
 	if c.opts.AutoInit {
 		if err := ws.EnsureInitialized(); err != nil {
@@ -107,13 +115,30 @@ func (c *Conductor) Initialize(ctx context.Context) error {
 			if err := c.registerAliasAgents(cfg); err != nil {
 				return fmt.Errorf("register alias agents: %w", err)
 			}
+			c.setupResponseCache(cfg)
+			c.rememberConfig(cfg)
 
-			// Load plugins
-			if err := c.loadPlugins(ctx, cfg); err != nil {
+			// Load plugins and scripts, which extend the state machine
+			builder := workflow.NewMachineBuilder()
+			extended, err := c.loadPlugins(ctx, cfg, builder)
+			if err != nil {
 				// Plugins are optional, but log the error for debugging
 				// Don't fail initialization since plugins are optional
 				c.logError(fmt.Errorf("load plugins (non-fatal): %w", err))
 			}
+			if c.loadScripts(builder) {
+				extended = true
+			}
+			if extended {
+				// Replace the default machine with the configured one,
+				// keeping the restored task
+				c.machine = builder.Build(c.eventBus)
+				c.machine.SetWorkUnit(c.buildWorkUnit())
+			}
+
+			c.setupNotifications(cfg)
+			c.setupTelemetry(cfg)
+			c.git = signedGit(cfg, c.git)
 		}
 	}
 
@@ -167,6 +192,12 @@ func (c *Conductor) Initialize(ctx context.Context) error {
 
 // Start registers a new task from a reference (does not run planning).
 func (c *Conductor) Start(ctx context.Context, reference string) error {
+	return c.tracePhase(ctx, "start", func(ctx context.Context) error {
+		return c.start(ctx, reference)
+	})
+}
+
+func (c *Conductor) start(ctx context.Context, reference string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -177,9 +208,21 @@ func (c *Conductor) Start(ctx context.Context, reference string) error {
 		return fmt.Errorf("this command must be run from the main repository; you are currently in a worktree, return to the main repository first: cd %s", mainRepo)
 	}
 
-	// Check for existing active task (only applies in main repo)
+	// Check for existing active task (only applies in main repo); with
+	// WithStashWIP it is parked and the new task starts from its base branch
+	if c.activeTask != nil && c.opts.StashWIP {
+		if err := c.parkForStart(ctx); err != nil {
+			return err
+		}
+	}
 	if c.activeTask != nil {
-		return fmt.Errorf("task already active: %s (use 'task status' to check)", c.activeTask.ID)
+		return fmt.Errorf("task already active: %s (use 'task status' to check, or --wip to park it)", c.activeTask.ID)
+	}
+
+	// Validate scope before touching git or the workspace
+	scope, err := normalizeScope(c.repoRoot(), c.opts.WorkDir, c.opts.Scope)
+	if err != nil {
+		return err
 	}
 
 	// If git is available and branch creation requested, check for clean workspace FIRST
@@ -187,12 +230,22 @@ func (c *Conductor) Start(ctx context.Context, reference string) error {
 		return err
 	}
 
+	// A task split from another starts from its existing work directory
+	if child := c.pendingChild(reference); child != nil {
+		return c.startChild(ctx, child, scope)
+	}
+
 	// Detect provider and fetch work unit
 	p, workUnit, err := c.fetchWorkUnit(ctx, reference)
 	if err != nil {
 		return err
 	}
 
+	// A query reference is stored as the work unit it picked
+	if pinner, ok := p.(provider.ReferencePinner); ok {
+		reference = pinner.PinReference(reference, workUnit)
+	}
+
 	// Capture task agent config from workUnit (if specified in task frontmatter)
 	c.taskAgentConfig = workUnit.AgentConfig
 
@@ -202,20 +255,38 @@ func (c *Conductor) Start(ctx context.Context, reference string) error {
 	// Resolve naming (external key, branch pattern, commit prefix)
 	namingInfo := c.resolveNaming(workUnit, taskID)
 
+	// Create task branches in attached secondary repositories
+	repos, err := c.attachRepositories(ctx, namingInfo)
+	if err != nil {
+		return err
+	}
+
 	// Create and switch to branch (or worktree) BEFORE creating work directory
 	gitInfo, err := c.createBranchOrWorktree(ctx, taskID, namingInfo)
 	if err != nil {
+		c.detachRepositories(ctx, repos)
+
 		return err
 	}
+	gitInfo.repos = repos
 
 	// Snapshot the source (read-only copy)
 	snapshot := c.snapshotSource(ctx, p, reference, workUnit)
 
 	// Register the task with workspace (writes source files)
-	if err := c.registerTask(taskID, reference, workUnit, snapshot, gitInfo, namingInfo); err != nil {
+	if err := c.registerTask(taskID, reference, workUnit, snapshot, gitInfo, namingInfo, scope); err != nil {
 		return err
 	}
 
+	// Keep local copies of the images the source references
+	c.localizeSourceImages(ctx, p, taskID, snapshot)
+
+	c.eventBus.Publish(events.TaskStartedEvent{
+		TaskID:    taskID,
+		Title:     workUnit.Title,
+		Reference: reference,
+		Branch:    gitInfo.branchName,
+	})
 	c.publishProgress("Task registered", 100)
 
 	return nil
@@ -241,9 +312,9 @@ func (c *Conductor) ensureCleanWorkspace(ctx context.Context) error {
 // fetchWorkUnit resolves the provider and fetches the work unit.
 func (c *Conductor) fetchWorkUnit(ctx context.Context, reference string) (any, *provider.WorkUnit, error) {
 	resolveOpts := provider.ResolveOptions{
-		DefaultProvider: c.opts.DefaultProvider,
+		DefaultProvider: c.defaultProvider(),
 	}
-	p, id, err := c.providers.Resolve(ctx, reference, provider.Config{}, resolveOpts)
+	p, id, err := c.providers.Resolve(ctx, reference, c.providerConfig(c.referenceProvider(reference)), resolveOpts)
 	if err != nil {
 		return nil, nil, fmt.Errorf("resolve provider: %w", err)
 	}
@@ -253,7 +324,16 @@ func (c *Conductor) fetchWorkUnit(ctx context.Context, reference string) (any, *
 		return nil, nil, errors.New("provider does not support reading")
 	}
 
-	workUnit, err := reader.Fetch(ctx, id)
+	if err := chaos.Check(chaos.ProviderFetch); err != nil {
+		return nil, nil, fmt.Errorf("fetch work unit: %w", err)
+	}
+
+	var workUnit *provider.WorkUnit
+	err = c.traceProvider(ctx, c.referenceProvider(reference), "fetch", func(ctx context.Context) error {
+		workUnit, err = reader.Fetch(ctx, id)
+
+		return err
+	})
 	if err != nil {
 		return nil, nil, fmt.Errorf("fetch work unit: %w", err)
 	}
@@ -285,62 +365,77 @@ func (c *Conductor) buildSourceInfo(snapshot *provider.Snapshot) storage.SourceI
 		ReadAt: time.Now(),
 	}
 
-	// For single file content, store path reference
-	if snapshot.Content != "" {
-		// Generate filename from reference or use default
-		filename := "source.md"
-		if snapshot.Ref != "" {
-			// Extract filename from reference if possible
-			if idx := strings.LastIndex(snapshot.Ref, "/"); idx != -1 {
-				filename = snapshot.Ref[idx+1:]
-			} else if idx := strings.LastIndex(snapshot.Ref, ":"); idx != -1 {
-				filename = snapshot.Ref[idx+1:] + ".md"
-			}
-		}
-		info.Files = []string{"source/" + filename}
+	files := snapshotFiles(snapshot)
+	if len(files) > 0 {
+		info.States = make(map[string]storage.SourceFileState, len(files))
 	}
-
-	// For directory/multiple files, store file paths
-	for _, f := range snapshot.Files {
+	for _, f := range files {
 		info.Files = append(info.Files, "source/"+f.Path)
+		info.States[f.Path] = sourceFileState(f)
 	}
 
 	return info
 }
 
+// sourceFilename returns the file a single-content snapshot is stored in.
+func sourceFilename(ref string) string {
+	// Extract filename from reference if possible
+	if idx := strings.LastIndex(ref, "/"); idx != -1 {
+		return ref[idx+1:]
+	}
+	if idx := strings.LastIndex(ref, ":"); idx != -1 {
+		return ref[idx+1:] + ".md"
+	}
+
+	return "source.md"
+}
+
+// snapshotFiles returns the files of a snapshot, treating single-file content
+// as one file named after the reference.
+func snapshotFiles(snapshot *provider.Snapshot) []provider.SnapshotFile {
+	if snapshot.Content == "" {
+		return snapshot.Files
+	}
+
+	files := []provider.SnapshotFile{{Path: sourceFilename(snapshot.Ref), Content: snapshot.Content}}
+
+	return append(files, snapshot.Files...)
+}
+
+// sourceFileState records the version of a snapshotted file.
+func sourceFileState(f provider.SnapshotFile) storage.SourceFileState {
+	hash := f.Hash
+	if hash == "" {
+		hash = provider.HashContent(f.Content)
+	}
+
+	return storage.SourceFileState{
+		ModTime: f.ModTime,
+		Size:    int64(len(f.Content)),
+		Hash:    hash,
+		ETag:    f.ETag,
+	}
+}
+
 // writeSourceFiles writes snapshot content to the work directory's source/ subdirectory.
 func (c *Conductor) writeSourceFiles(taskID string, snapshot *provider.Snapshot) error {
 	if snapshot == nil {
 		return nil
 	}
 
-	workPath := c.workspace.WorkPath(taskID)
-	sourceDir := filepath.Join(workPath, "source")
+	return c.writeSourceFileList(taskID, snapshotFiles(snapshot))
+}
+
+// writeSourceFileList writes files to the work directory's source/ subdirectory.
+func (c *Conductor) writeSourceFileList(taskID string, files []provider.SnapshotFile) error {
+	sourceDir := filepath.Join(c.workspace.WorkPath(taskID), "source")
 
 	// Create source directory if it doesn't exist
 	if err := os.MkdirAll(sourceDir, 0o755); err != nil {
 		return fmt.Errorf("create source directory: %w", err)
 	}
 
-	// Write single file content
-	if snapshot.Content != "" {
-		filename := "source.md"
-		if snapshot.Ref != "" {
-			// Extract filename from reference
-			if idx := strings.LastIndex(snapshot.Ref, "/"); idx != -1 {
-				filename = snapshot.Ref[idx+1:]
-			} else if idx := strings.LastIndex(snapshot.Ref, ":"); idx != -1 {
-				filename = snapshot.Ref[idx+1:] + ".md"
-			}
-		}
-		destPath := filepath.Join(sourceDir, filename)
-		if err := os.WriteFile(destPath, []byte(snapshot.Content), 0o644); err != nil {
-			return fmt.Errorf("write source file: %w", err)
-		}
-	}
-
-	// Write multiple files (directory provider)
-	for _, f := range snapshot.Files {
+	for _, f := range files {
 		destPath := filepath.Join(sourceDir, f.Path)
 		// Ensure parent directory exists
 		if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
@@ -355,7 +450,7 @@ func (c *Conductor) writeSourceFiles(taskID string, snapshot *provider.Snapshot)
 }
 
 // registerTask creates the work directory and active task reference.
-func (c *Conductor) registerTask(taskID, reference string, workUnit *provider.WorkUnit, snapshot *provider.Snapshot, gi *gitInfo, ni *namingInfo) error {
+func (c *Conductor) registerTask(taskID, reference string, workUnit *provider.WorkUnit, snapshot *provider.Snapshot, gi *gitInfo, ni *namingInfo, scope string) error {
 	// Resolve agent for this task (uses priority: CLI > task > workspace > auto)
 	agentInst, agentSource, err := c.resolveAgentForTask()
 	if err != nil {
@@ -377,8 +472,32 @@ func (c *Conductor) registerTask(taskID, reference string, workUnit *provider.Wo
 		return fmt.Errorf("write source files: %w", err)
 	}
 
-	// Set metadata from work unit and naming info
+	// Set metadata from work unit
 	work.Metadata.Title = workUnit.Title
+	work.Metadata.Scope = scope
+	work.Metadata.AllowOutsideScope = scope != "" && c.opts.AllowOutsideScope
+	if workUnit.Estimate > 0 {
+		work.Metadata.Estimate = storage.FormatMinutes(workUnit.Estimate)
+	}
+	work.Repos = gi.repos
+
+	// Store agent info for persistence (so subsequent commands use the same agent)
+	work.Agent = storage.AgentInfo{
+		Name:   agentInst.Name(),
+		Source: agentSource,
+	}
+	// Store inline env vars if specified in task (for reference, not resolved values)
+	if c.taskAgentConfig != nil && len(c.taskAgentConfig.Env) > 0 {
+		work.Agent.InlineEnv = c.taskAgentConfig.Env
+	}
+
+	return c.activateWork(work, reference, gi, ni)
+}
+
+// activateWork records the naming and git info of a task's work and makes it
+// the active task.
+func (c *Conductor) activateWork(work *storage.TaskWork, reference string, gi *gitInfo, ni *namingInfo) error {
+	taskID := work.Metadata.ID
 	work.Metadata.ExternalKey = ni.externalKey
 	work.Metadata.TaskType = ni.taskType
 	work.Metadata.Slug = ni.slug
@@ -393,16 +512,6 @@ func (c *Conductor) registerTask(taskID, reference string, workUnit *provider.Wo
 		work.Git.BranchPattern = gi.branchPattern
 	}
 
-	// Store agent info for persistence (so subsequent commands use the same agent)
-	work.Agent = storage.AgentInfo{
-		Name:   agentInst.Name(),
-		Source: agentSource,
-	}
-	// Store inline env vars if specified in task (for reference, not resolved values)
-	if c.taskAgentConfig != nil && len(c.taskAgentConfig.Env) > 0 {
-		work.Agent.InlineEnv = c.taskAgentConfig.Env
-	}
-
 	if err := c.workspace.SaveWork(work); err != nil {
 		return fmt.Errorf("save work: %w", err)
 	}
@@ -428,6 +537,7 @@ func (c *Conductor) registerTask(taskID, reference string, workUnit *provider.Wo
 
 	c.activeTask = active
 	c.taskWork = work
+	c.flushAudit(taskID)
 
 	// Set up state machine
 	c.machine.SetWorkUnit(c.buildWorkUnit())
@@ -462,7 +572,7 @@ func (c *Conductor) Resume(ctx context.Context) error {
 }
 
 // Delete abandons the current task without merging.
-func (c *Conductor) Delete(ctx context.Context, opts DeleteOptions) error {
+func (c *Conductor) Delete(ctx context.Context, opts DeleteOptions) (err error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -470,6 +580,12 @@ func (c *Conductor) Delete(ctx context.Context, opts DeleteOptions) error {
 		return errors.New("no active task")
 	}
 
+	ctx, release, err := c.acquireLease(ctx)
+	if err != nil {
+		return err
+	}
+	defer release(&err)
+
 	taskID := c.activeTask.ID
 
 	// Handle git operations if applicable
@@ -512,6 +628,10 @@ func (c *Conductor) Delete(ctx context.Context, opts DeleteOptions) error {
 		if err := c.git.DeleteBranch(ctx, taskBranch, true); err != nil {
 			c.logError(fmt.Errorf("delete branch: %w", err))
 		}
+
+		if c.taskWork != nil {
+			c.detachRepositories(ctx, c.taskWork.Repos)
+		}
 	}
 
 	// Delete work directory based on: CLI flag > config > default (delete)
@@ -561,6 +681,8 @@ func (c *Conductor) Status() (*TaskStatus, error) {
 		Ref:            c.activeTask.Ref,
 		Branch:         c.activeTask.Branch,
 		WorktreePath:   c.activeTask.WorktreePath,
+		Scope:          c.taskWork.Metadata.Scope,
+		Repositories:   c.taskWork.Repos,
 		Specifications: len(specifications),
 		Checkpoints:    c.countCheckpoints(),
 		Started:        c.activeTask.Started,
@@ -587,6 +709,8 @@ type TaskStatus struct {
 	Ref            string
 	Branch         string
 	WorktreePath   string
+	Scope          string             // Repo-relative subdirectory the task is restricted to
+	Repositories   []storage.RepoInfo // Attached secondary repositories
 	Specifications int
 	Checkpoints    int
 	Started        time.Time
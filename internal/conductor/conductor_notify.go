@@ -0,0 +1,132 @@
+package conductor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/notify"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// setupNotifications sends pending question alerts to the sinks configured
+// in the workspace config, and task lifecycle events to Slack when enabled.
+// Delivery is synchronous so the alert goes out before a CLI command exits;
+// each sink is bounded by a timeout.
+func (c *Conductor) setupNotifications(cfg *storage.WorkspaceConfig) {
+	c.setupSlackEvents(cfg.Notifications.Slack)
+
+	notifier := notify.FromSettings(cfg.Notifications)
+	if !notifier.Enabled() {
+		return
+	}
+
+	c.eventBus.Subscribe(events.TypeQuestionPending, func(e events.Event) {
+		if err := notifier.Notify(context.Background(), questionNotification(e)); err != nil {
+			c.logError(fmt.Errorf("send question notification: %w", err))
+		}
+	})
+}
+
+// questionNotification builds the alert for a pending question event.
+func questionNotification(e events.Event) notify.Notification {
+	taskID, _ := e.Data["task_id"].(string)
+	title, _ := e.Data["title"].(string)
+	question, _ := e.Data["question"].(string)
+	options, _ := e.Data["options"].([]string)
+
+	heading := "Agent is waiting for your answer"
+	if title != "" {
+		heading += ": " + title
+	}
+
+	return notify.Notification{
+		Event:     string(events.TypeQuestionPending),
+		TaskID:    taskID,
+		Title:     heading,
+		Message:   question,
+		Options:   options,
+		Timestamp: e.Timestamp,
+	}
+}
+
+// setupSlackEvents posts the task lifecycle events enabled in the Slack
+// settings.
+func (c *Conductor) setupSlackEvents(settings *storage.SlackNotification) {
+	if settings == nil || settings.WebhookURL == "" || settings.Events == nil {
+		return
+	}
+
+	notifier := notify.New(notify.SlackFromSettings(settings))
+	enabled := []struct {
+		eventType events.Type
+		on        bool
+	}{
+		{events.TypeTaskStarted, settings.Events.OnTaskStarted},
+		{events.TypePlanCompleted, settings.Events.OnPlanDone},
+		{events.TypePRCreated, settings.Events.OnPRCreated},
+		{events.TypeTaskFinished, settings.Events.OnFinished},
+	}
+	for _, e := range enabled {
+		if !e.on {
+			continue
+		}
+		c.eventBus.Subscribe(e.eventType, func(e events.Event) {
+			if err := notifier.Notify(context.Background(), lifecycleNotification(e)); err != nil {
+				c.logError(fmt.Errorf("send %s notification: %w", e.Type, err))
+			}
+		})
+	}
+}
+
+// lifecycleNotification builds the message for a task lifecycle event.
+func lifecycleNotification(e events.Event) notify.Notification {
+	taskID, _ := e.Data["task_id"].(string)
+	title, _ := e.Data["title"].(string)
+
+	n := notify.Notification{
+		Event:     string(e.Type),
+		TaskID:    taskID,
+		Timestamp: e.Timestamp,
+	}
+	switch e.Type {
+	case events.TypeTaskStarted:
+		n.Title = "Task started"
+		if branch, _ := e.Data["branch"].(string); branch != "" {
+			n.Message = fmt.Sprintf("Working on branch `%s`", branch)
+		}
+	case events.TypePlanCompleted:
+		n.Title = "Plan ready"
+		spec, _ := e.Data["specification_id"].(int)
+		summary, _ := e.Data["summary"].(string)
+		if len(summary) > 500 {
+			summary = summary[:500] + "..."
+		}
+		n.Message = strings.TrimSpace(fmt.Sprintf("specification-%d\n%s", spec, summary))
+	case events.TypePRCreated:
+		n.Title = "Pull request opened"
+		number, _ := e.Data["pr_number"].(int)
+		url, _ := e.Data["pr_url"].(string)
+		n.Message = fmt.Sprintf("#%d %s", number, url)
+	case events.TypeTaskFinished:
+		n.Title = "Task finished"
+	default:
+		n.Title = string(e.Type)
+	}
+	if title != "" {
+		n.Title += ": " + title
+	}
+
+	return n
+}
+
+// questionOptionLabels returns the labels of a question's answer options.
+func questionOptionLabels(options []storage.QuestionOption) []string {
+	labels := make([]string, 0, len(options))
+	for _, opt := range options {
+		labels = append(labels, opt.Label)
+	}
+
+	return labels
+}
@@ -0,0 +1,109 @@
+package conductor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestQuestionNotification(t *testing.T) {
+	event := events.QuestionPendingEvent{
+		TaskID:   "abc123",
+		Title:    "Add caching",
+		Phase:    "planning",
+		Question: "Redis or in-memory?",
+		Options:  []string{"Redis", "In-memory"},
+	}.ToEvent()
+
+	n := questionNotification(event)
+
+	if n.Title != "Agent is waiting for your answer: Add caching" {
+		t.Errorf("Title = %q", n.Title)
+	}
+	if n.TaskID != "abc123" || n.Message != "Redis or in-memory?" || n.Event != "question_pending" {
+		t.Errorf("notification = %+v", n)
+	}
+	if !slices.Equal(n.Options, []string{"Redis", "In-memory"}) {
+		t.Errorf("Options = %v", n.Options)
+	}
+}
+
+func TestLifecycleNotification(t *testing.T) {
+	tests := []struct {
+		name        string
+		event       events.Eventer
+		wantTitle   string
+		wantMessage string
+	}{
+		{
+			name:        "task started",
+			event:       events.TaskStartedEvent{TaskID: "t1", Title: "Add caching", Branch: "feature/cache"},
+			wantTitle:   "Task started: Add caching",
+			wantMessage: "Working on branch `feature/cache`",
+		},
+		{
+			name:        "plan done",
+			event:       events.PlanCompletedEvent{TaskID: "t1", Title: "Add caching", SpecificationID: 2, Summary: "Use Redis"},
+			wantTitle:   "Plan ready: Add caching",
+			wantMessage: "specification-2\nUse Redis",
+		},
+		{
+			name:        "pr created",
+			event:       events.PRCreatedEvent{TaskID: "t1", PRNumber: 7, PRURL: "https://example.test/pr/7"},
+			wantTitle:   "Pull request opened",
+			wantMessage: "#7 https://example.test/pr/7",
+		},
+		{
+			name:      "finished",
+			event:     events.TaskFinishedEvent{TaskID: "t1", Title: "Add caching"},
+			wantTitle: "Task finished: Add caching",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := lifecycleNotification(tt.event.ToEvent())
+			if n.Title != tt.wantTitle || n.Message != tt.wantMessage || n.TaskID != "t1" {
+				t.Errorf("notification = %+v, want title %q message %q", n, tt.wantTitle, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestSetupSlackEvents(t *testing.T) {
+	var mu sync.Mutex
+	var texts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		texts = append(texts, payload["text"])
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	c.setupSlackEvents(&storage.SlackNotification{
+		WebhookURL: server.URL,
+		Events:     &storage.SlackEventSettings{OnPRCreated: true},
+	})
+
+	c.eventBus.Publish(events.TaskStartedEvent{TaskID: "t1", Title: "Add caching"})
+	c.eventBus.Publish(events.PRCreatedEvent{TaskID: "t1", Title: "Add caching", PRNumber: 7, PRURL: "https://example.test/pr/7"})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(texts) != 1 || !strings.Contains(texts[0], "Pull request opened: Add caching") {
+		t.Errorf("posted = %q, want only the PR message", texts)
+	}
+}
@@ -0,0 +1,425 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+var (
+	// ErrSpecConflict is returned when the branch of a specification
+	// implemented in parallel does not merge cleanly into the task branch.
+	ErrSpecConflict = errors.New("specification branches conflict")
+
+	// ErrParallelUnsupported is returned when the implementing agent cannot
+	// run in a worktree of its own.
+	ErrParallelUnsupported = errors.New("agent cannot run in a worktree")
+
+	// ErrSpecsNotIndependent is returned when the remaining specifications
+	// cannot be shown to change disjoint files, so running them side by side
+	// would build each on code the others are still changing.
+	ErrSpecsNotIndependent = errors.New("specifications are not independent")
+)
+
+// SpecResult is the outcome of one specification implemented in parallel.
+type SpecResult struct {
+	Number     int
+	Branch     string   // Per-spec branch, kept when it could not be merged
+	Worktree   string   // Per-spec worktree, kept when it could not be merged
+	Checkpoint int      // Checkpoint holding the merged changes, 0 when not merged
+	Conflicts  []string // Files that conflicted with the task branch
+	Err        error    // Why the spec was not implemented or merged
+}
+
+// Merged reports whether the specification's changes are on the task branch.
+func (r SpecResult) Merged() bool {
+	return r.Err == nil && len(r.Conflicts) == 0
+}
+
+// parallelJob is a specification being implemented in its own worktree.
+type parallelJob struct {
+	SpecResult
+
+	prompt      string
+	resumed     bool
+	session     *storage.Session
+	sessionFile string
+	response    *agent.Response
+}
+
+// RunParallelImplementation implements the task's remaining specifications
+// concurrently, at most parallel at a time. Each runs in a worktree on its own
+// branch from the task branch; the branches are then squash-merged back one
+// by one, each into its own checkpoint. A spec whose branch conflicts keeps
+// its branch and worktree for manual resolution and ErrSpecConflict is
+// returned.
+func (c *Conductor) RunParallelImplementation(ctx context.Context, parallel int) ([]SpecResult, error) {
+	var results []SpecResult
+	err := c.tracePhase(ctx, "implementation", func(ctx context.Context) error {
+		var err error
+		results, err = c.runParallelImplementation(ctx, parallel)
+
+		return err
+	})
+
+	return results, err
+}
+
+func (c *Conductor) runParallelImplementation(ctx context.Context, parallel int) (_ []SpecResult, err error) {
+	if parallel < 1 {
+		return nil, fmt.Errorf("parallel implementation needs at least 1 agent, got %d", parallel)
+	}
+	if err := vcs.Require(c.GetVCS(), vcs.CapWorktrees, "parallel implementation"); err != nil {
+		return nil, err
+	}
+	if c.git == nil || !c.activeTask.UseGit {
+		return nil, errors.New("parallel implementation needs a task created with git")
+	}
+
+	ctx, release, err := c.acquireLease(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release(&err)
+
+	taskID := c.activeTask.ID
+
+	// Merging the spec branches back needs a clean tree
+	changed, err := c.git.ChangedFiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("check changes: %w", err)
+	}
+	if len(c.withoutOwnFiles(changed)) > 0 {
+		return nil, errors.New("commit or stash uncommitted changes before a parallel implementation")
+	}
+
+	base, err := c.git.CurrentBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	implementingAgent, err := c.GetAgentForStep(ctx, workflow.StepImplementing)
+	if err != nil {
+		return nil, fmt.Errorf("get implementing agent: %w", err)
+	}
+	if cfg, err := c.workspace.LoadConfig(); err == nil && cfg.Agent.Sandbox != "" && cfg.Agent.Sandbox != agent.SandboxNone {
+		return nil, errors.New("agent.sandbox is not supported with parallel implementation")
+	}
+	if _, ok := agent.InDir(implementingAgent, base); !ok {
+		return nil, fmt.Errorf("%s: %w", implementingAgent.Name(), ErrParallelUnsupported)
+	}
+
+	jobs, err := c.prepareParallelJobs(ctx, taskID, base, implementingAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	c.publishProgress(fmt.Sprintf("Implementing %d specifications, %d at a time...", len(jobs), parallel), 20)
+	c.runParallelJobs(ctx, jobs, parallel, implementingAgent)
+
+	if ctx.Err() != nil {
+		for _, job := range jobs {
+			c.discardSpecWorktree(context.WithoutCancel(ctx), &job.SpecResult)
+			c.saveJobSession(taskID, job, true)
+		}
+
+		return nil, c.interrupted(ctx, taskID, nil, ctx.Err())
+	}
+
+	results := make([]SpecResult, 0, len(jobs))
+	for _, job := range jobs {
+		c.mergeSpecJob(ctx, taskID, job, implementingAgent)
+		if job.Err != nil && job.Worktree != "" {
+			// Only a conflict is worth keeping; a failed spec stays
+			// implementing and resumes on the next run
+			c.discardSpecWorktree(ctx, &job.SpecResult)
+		}
+		c.saveJobSession(taskID, job, false)
+		results = append(results, job.SpecResult)
+	}
+
+	var failed []string
+	for _, r := range results {
+		if !r.Merged() {
+			failed = append(failed, fmt.Sprintf("specification-%d", r.Number))
+		}
+	}
+
+	c.activeTask.State = "idle"
+	if err := c.workspace.SaveActiveTask(c.activeTask); err != nil {
+		c.logError(fmt.Errorf("save active task: %w", err))
+	}
+	if len(failed) > 0 {
+		_ = c.machine.Dispatch(ctx, workflow.EventError)
+
+		return results, fmt.Errorf("%w: %s", ErrSpecConflict, strings.Join(failed, ", "))
+	}
+	_ = c.machine.Dispatch(ctx, workflow.EventImplementDone)
+	c.publishProgress("Implementation complete", 100)
+
+	return results, nil
+}
+
+// prepareParallelJobs creates a worktree, branch, session and prompt for
+// every specification that is not done yet.
+func (c *Conductor) prepareParallelJobs(ctx context.Context, taskID, base string, implementingAgent agent.Agent) ([]*parallelJob, error) {
+	specs, err := c.workspace.ListSpecificationsWithStatus(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list specifications: %w", err)
+	}
+	specs = slices.DeleteFunc(specs, func(s *storage.Specification) bool {
+		return s.Status == storage.SpecificationStatusDone
+	})
+	if len(specs) == 0 {
+		return nil, errors.New("no specifications left to implement")
+	}
+	if err := checkSpecsIndependent(specs); err != nil {
+		return nil, err
+	}
+
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil {
+		cfg = storage.NewDefaultWorkspaceConfig()
+	}
+	dir, err := c.git.ResolveWorktreesDir(cfg.Git.WorktreeDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve worktree directory: %w", err)
+	}
+
+	// The parts of the prompt every specification shares
+	sourceContent, err := c.workspace.GetSourceContent(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("get source content: %w", err)
+	}
+	notes, _ := c.workspace.ReadNotes(taskID)
+	pc := c.buildContext(ctx, workflow.StepImplementing, implementingAgent, sourceContent, notes, nil)
+	shared := pc.summaryPrompt() + scopePrompt(c.taskScope()) + c.reposPrompt() + c.lessonsPrompt() +
+		c.conventionsPrompt(workflow.StepImplementing)
+
+	jobs := make([]*parallelJob, 0, len(specs))
+	for _, spec := range specs {
+		job := &parallelJob{
+			SpecResult: SpecResult{
+				Number:   spec.Number,
+				Branch:   fmt.Sprintf("%s-spec-%d", base, spec.Number),
+				Worktree: filepath.Join(dir, fmt.Sprintf("%s-spec-%d", taskID, spec.Number)),
+			},
+			resumed: spec.Status == storage.SpecificationStatusImplementing,
+		}
+		if _, err := os.Stat(job.Worktree); err == nil || c.git.BranchExists(ctx, job.Branch) {
+			return nil, fmt.Errorf("specification-%d: %s or branch %s is left from an earlier run; merge or remove it first", spec.Number, job.Worktree, job.Branch)
+		}
+
+		job.prompt = buildImplementationPrompt(c.taskWork.Metadata.Title, sourceContent, spec.Content, pc.Notes) +
+			shared + c.codeMapPrompt(spec.Content) + specPrompt(spec.Number, job.resumed)
+		job.prompt = c.scriptPrompt(ctx, workflow.StepImplementing, job.prompt)
+
+		session, filename, err := c.workspace.CreateSession(taskID, "implementation", implementingAgent.Name(), c.activeTask.State)
+		if err != nil {
+			c.logError(fmt.Errorf("create session: %w", err))
+		} else {
+			session.Metadata.Specification = spec.Number
+			job.session, job.sessionFile = session, filename
+		}
+		if err := c.workspace.StartSpecificationImplementation(taskID, spec.Number, filename); err != nil {
+			c.logError(fmt.Errorf("update specification status: %w", err))
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	// Create the worktrees only once every spec is known to be free
+	for i, job := range jobs {
+		if err := c.git.CreateWorktreeNewBranch(ctx, job.Worktree, job.Branch, base); err != nil {
+			for _, created := range jobs[:i] {
+				c.discardSpecWorktree(ctx, &created.SpecResult)
+			}
+
+			return nil, fmt.Errorf("create worktree for specification-%d: %w", job.Number, err)
+		}
+	}
+
+	return jobs, nil
+}
+
+// checkSpecsIndependent returns ErrSpecsNotIndependent unless every
+// specification plans the files it changes and no two plan the same file.
+// A spec without planned files could touch anything, so it is refused too.
+func checkSpecsIndependent(specs []*storage.Specification) error {
+	planned := make(map[string]int)
+	for _, spec := range specs {
+		files := spec.PredictedFiles
+		if len(files) == 0 {
+			files = storage.ExtractPredictedFiles(spec.Content)
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("%w: specification-%d does not list the files it changes; implement the specifications one at a time",
+				ErrSpecsNotIndependent, spec.Number)
+		}
+		for _, f := range files {
+			if other, ok := planned[f]; ok && other != spec.Number {
+				return fmt.Errorf("%w: specification-%d and specification-%d both change %s; implement them one at a time",
+					ErrSpecsNotIndependent, other, spec.Number, f)
+			}
+			planned[f] = spec.Number
+		}
+	}
+
+	return nil
+}
+
+// runParallelJobs runs the agent for every job in its worktree, at most
+// parallel at a time. Failures are recorded on the job.
+func (c *Conductor) runParallelJobs(ctx context.Context, jobs []*parallelJob, parallel int, implementingAgent agent.Agent) {
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Go(func() {
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				job.Err = ctx.Err()
+
+				return
+			}
+
+			specAgent, _ := agent.InDir(implementingAgent, job.Worktree)
+			job.response, job.Err = specAgent.RunWithCallback(ctx, job.prompt, func(event agent.Event) error {
+				c.eventBus.PublishRaw(events.Event{
+					Type: events.TypeAgentMessage,
+					Data: map[string]any{"event": event, "specification": job.Number},
+				})
+
+				return nil
+			})
+		})
+	}
+	wg.Wait()
+}
+
+// mergeSpecJob applies a finished job's changes in its worktree, commits them
+// on its branch and squash-merges the branch into the task branch as one
+// checkpoint. A conflicting job keeps its worktree and branch.
+func (c *Conductor) mergeSpecJob(ctx context.Context, taskID string, job *parallelJob, implementingAgent agent.Agent) {
+	if job.Err != nil {
+		job.Err = fmt.Errorf("agent implementation: %w", job.Err)
+
+		return
+	}
+	c.recordUsage(taskID, "implementing", workflow.StepImplementing, implementingAgent, job.response.Usage)
+
+	if len(job.response.Files) > 0 {
+		if err := applyFilesIn(ctx, c, job.Worktree, job.response.Files); err != nil {
+			job.Err = fmt.Errorf("apply files: %w", err)
+
+			return
+		}
+	}
+
+	wt, err := vcs.New(ctx, job.Worktree)
+	if err != nil {
+		job.Err = err
+
+		return
+	}
+	changed, err := wt.HasChanges(ctx)
+	if err != nil {
+		job.Err = err
+
+		return
+	}
+	if !changed {
+		c.publishProgress(fmt.Sprintf("Specification %d made no changes", job.Number), 70)
+		c.completeSpecJob(ctx, taskID, job, 0)
+
+		return
+	}
+	if err := wt.AddAll(ctx); err != nil {
+		job.Err = err
+
+		return
+	}
+	if _, err := wt.Commit(ctx, fmt.Sprintf("Implement specification %d of task %s", job.Number, taskID)); err != nil {
+		job.Err = fmt.Errorf("commit specification-%d: %w", job.Number, err)
+
+		return
+	}
+
+	c.publishProgress(fmt.Sprintf("Merging specification %d...", job.Number), 80)
+	if err := c.git.MergeSquash(ctx, job.Branch); err != nil {
+		job.Conflicts, _ = c.git.ConflictedFiles(ctx)
+		if len(job.Conflicts) == 0 {
+			job.Err = fmt.Errorf("merge specification-%d: %w", job.Number, err)
+		}
+		if err := c.git.ResetMerge(ctx); err != nil {
+			c.logError(fmt.Errorf("abandon merge of specification-%d: %w", job.Number, err))
+		}
+
+		return
+	}
+
+	if err := c.enforceGuardrails(ctx, implementingAgent, "implementing", workflow.StepImplementing); err != nil {
+		job.Err = err
+		if err := c.git.ResetMerge(ctx); err != nil {
+			c.logError(fmt.Errorf("abandon merge of specification-%d: %w", job.Number, err))
+		}
+
+		return
+	}
+
+	checkpoint := 0
+	message := fmt.Sprintf("Implement specification %d of task %s", job.Number, taskID)
+	if event := c.createCheckpointIfNeeded(ctx, taskID, message); event != nil {
+		checkpoint, _ = event.Data["checkpoint"].(int)
+		c.eventBus.PublishRaw(*event)
+	}
+	c.completeSpecJob(ctx, taskID, job, checkpoint)
+}
+
+// completeSpecJob marks a merged specification done and removes its worktree
+// and branch.
+func (c *Conductor) completeSpecJob(ctx context.Context, taskID string, job *parallelJob, checkpoint int) {
+	job.Checkpoint = checkpoint
+	if err := c.workspace.CompleteSpecificationImplementation(taskID, job.Number, checkpoint); err != nil {
+		c.logError(fmt.Errorf("update specification status: %w", err))
+	} else {
+		c.syncSpecTaskList(ctx, taskID, job.Number)
+	}
+	c.discardSpecWorktree(ctx, &job.SpecResult)
+}
+
+// discardSpecWorktree removes a specification's worktree and branch.
+func (c *Conductor) discardSpecWorktree(ctx context.Context, r *SpecResult) {
+	if err := c.git.RemoveWorktree(ctx, r.Worktree, true); err != nil {
+		c.logError(fmt.Errorf("remove worktree of specification-%d: %w", r.Number, err))
+	}
+	if err := c.git.DeleteBranch(ctx, r.Branch, true); err != nil {
+		c.logError(fmt.Errorf("delete branch of specification-%d: %w", r.Number, err))
+	}
+	r.Worktree, r.Branch = "", ""
+}
+
+// saveJobSession ends and saves a job's session.
+func (c *Conductor) saveJobSession(taskID string, job *parallelJob, canceled bool) {
+	if job.session == nil {
+		return
+	}
+	job.session.Metadata.Canceled = canceled
+	job.session.Metadata.EndedAt = time.Now()
+	if err := c.workspace.SaveSession(taskID, job.sessionFile, job.session); err != nil {
+		c.logError(fmt.Errorf("save session: %w", err))
+	}
+}
@@ -0,0 +1,175 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// specAgent writes a file per specification and can run in a worktree.
+type specAgent struct {
+	mockAgent
+	files map[int]agent.FileChange
+	dir   string
+}
+
+func (a *specAgent) InDir(dir string) agent.Agent {
+	return &specAgent{mockAgent: a.mockAgent, files: a.files, dir: dir}
+}
+
+func (a *specAgent) RunWithCallback(ctx context.Context, prompt string, cb agent.StreamCallback) (*agent.Response, error) {
+	if a.dir == "" {
+		return nil, errors.New("not run in a worktree")
+	}
+	for n, f := range a.files {
+		if strings.Contains(prompt, fmt.Sprintf("Implement only specification %d", n)) {
+			return &agent.Response{Summary: "done", Files: []agent.FileChange{f}}, nil
+		}
+	}
+
+	return &agent.Response{Summary: "nothing to do"}, nil
+}
+
+func TestRunParallelImplementation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	tests := []struct {
+		name          string
+		files         map[int]agent.FileChange
+		wantErr       error
+		wantConflicts map[int]bool
+	}{
+		{
+			name: "independent",
+			files: map[int]agent.FileChange{
+				1: {Path: "one.txt", Operation: agent.FileOpCreate, Content: "one\n"},
+				2: {Path: "two.txt", Operation: agent.FileOpCreate, Content: "two\n"},
+			},
+		},
+		{
+			name: "conflicting",
+			files: map[int]agent.FileChange{
+				1: {Path: "README.md", Operation: agent.FileOpUpdate, Content: "# One\n"},
+				2: {Path: "README.md", Operation: agent.FileOpUpdate, Content: "# Two\n"},
+			},
+			wantErr:       ErrSpecConflict,
+			wantConflicts: map[int]bool{2: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			initGitRepo(t, tmpDir)
+
+			c, err := New(WithWorkDir(tmpDir), WithAgent("mock"))
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			if err := c.GetAgentRegistry().Register(&specAgent{mockAgent: mockAgent{name: "mock"}, files: tt.files}); err != nil {
+				t.Fatalf("Register agent: %v", err)
+			}
+			if err := c.Initialize(context.Background()); err != nil {
+				t.Fatalf("Initialize: %v", err)
+			}
+			work, err := c.workspace.CreateWork("par", storage.SourceInfo{Type: "file", Ref: "task.md"})
+			if err != nil {
+				t.Fatalf("CreateWork: %v", err)
+			}
+			for n := 1; n <= 2; n++ {
+				// Planned files are disjoint; the conflicting agent strays from them
+				content := fmt.Sprintf("# Spec %d\n\n## Files\n\n- `spec%d.txt`\n", n, n)
+				if err := c.workspace.SaveSpecification("par", n, content); err != nil {
+					t.Fatalf("SaveSpecification: %v", err)
+				}
+			}
+			c.taskWork = work
+			c.activeTask = &storage.ActiveTask{ID: "par", State: "implementing", UseGit: true}
+
+			results, err := c.RunParallelImplementation(context.Background(), 2)
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RunParallelImplementation error = %v, want %v", err, tt.wantErr)
+			}
+			if len(results) != 2 {
+				t.Fatalf("results = %+v, want 2", results)
+			}
+
+			for _, r := range results {
+				if tt.wantConflicts[r.Number] {
+					if len(r.Conflicts) == 0 || r.Branch == "" {
+						t.Errorf("specification-%d = %+v, want conflicts and its branch kept", r.Number, r)
+					}
+					if _, err := os.Stat(r.Worktree); err != nil {
+						t.Errorf("worktree of specification-%d should be kept: %v", r.Number, err)
+					}
+
+					continue
+				}
+				if !r.Merged() || r.Checkpoint == 0 {
+					t.Errorf("specification-%d = %+v, want merged into a checkpoint", r.Number, r)
+				}
+
+				f := tt.files[r.Number]
+				if data, _ := os.ReadFile(filepath.Join(tmpDir, f.Path)); string(data) != f.Content {
+					t.Errorf("%s = %q, want %q", f.Path, data, f.Content)
+				}
+				spec, err := c.workspace.ParseSpecification("par", r.Number)
+				if err != nil {
+					t.Fatalf("ParseSpecification: %v", err)
+				}
+				if spec.Status != storage.SpecificationStatusDone {
+					t.Errorf("specification-%d status = %q, want done", r.Number, spec.Status)
+				}
+			}
+
+			if c.activeTask.State != "idle" {
+				t.Errorf("task state = %q, want idle", c.activeTask.State)
+			}
+		})
+	}
+}
+
+func TestCheckSpecsIndependent(t *testing.T) {
+	spec := func(n int, content string, predicted ...string) *storage.Specification {
+		return &storage.Specification{Number: n, Content: content, PredictedFiles: predicted}
+	}
+
+	tests := []struct {
+		name    string
+		specs   []*storage.Specification
+		wantErr bool
+	}{
+		{
+			name:  "disjoint files",
+			specs: []*storage.Specification{spec(1, "Change `api/handler.go`"), spec(2, "", "web/app.ts")},
+		},
+		{
+			name:    "shared file",
+			specs:   []*storage.Specification{spec(1, "Change `api/handler.go`"), spec(2, "", "api/handler.go", "web/app.ts")},
+			wantErr: true,
+		},
+		{
+			name:    "no planned files",
+			specs:   []*storage.Specification{spec(1, "Change `api/handler.go`"), spec(2, "Tidy up the API")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSpecsIndependent(tt.specs)
+			if tt.wantErr != errors.Is(err, ErrSpecsNotIndependent) {
+				t.Errorf("checkSpecsIndependent() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// PlanningTurn is the outcome of one interactive planning turn.
+type PlanningTurn struct {
+	Specification int             // Draft specification updated by the turn (0 when the agent asked a question)
+	Reply         string          // Agent's summary of what changed
+	Question      *agent.Question // Question the agent asked instead of updating the draft
+}
+
+// ContinuePlanning runs one turn of interactive planning. The user's input is
+// appended to the task's planning session, the agent sees the whole
+// conversation, and its answer replaces the latest draft specification (or
+// starts a new one when every specification has been approved).
+func (c *Conductor) ContinuePlanning(ctx context.Context, input string) (_ *PlanningTurn, err error) {
+	if c.activeTask == nil {
+		return nil, errors.New("no active task")
+	}
+
+	ctx, release, err := c.acquireLease(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release(&err)
+
+	taskID := c.activeTask.ID
+
+	specTemplate, err := c.planningSpecTemplate(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	planningAgent, err := c.GetAgentForStep(ctx, workflow.StepPlanning)
+	if err != nil {
+		return nil, fmt.Errorf("get planning agent: %w", err)
+	}
+
+	if err := c.resumeSession(taskID, "planning", planningAgent.Name()); err != nil {
+		return nil, err
+	}
+	c.recordExchange("user", input)
+
+	number, draft, err := c.draftSpecification(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	// An agent that resumed its own conversation already has the task context
+	planningAgent, resumed := c.continuationAgent(planningAgent)
+
+	var prompt string
+	if resumed {
+		prompt = buildFollowUpPrompt(input) + draftRevisionPrompt(number, draft)
+	} else {
+		sourceContent, err := c.workspace.GetSourceContent(taskID)
+		if err != nil {
+			return nil, fmt.Errorf("get source content: %w", err)
+		}
+		notes, _ := c.workspace.ReadNotes(taskID)
+
+		prompt = buildPlanningPrompt(c.taskWork.Metadata.Title, sourceContent, notes, "")
+		prompt += scopePrompt(c.taskScope())
+		prompt += c.reposPrompt()
+		prompt += c.conventionsPrompt(workflow.StepPlanning)
+		prompt += specTemplatePrompt(specTemplate)
+		prompt += planningConversationPrompt(c.currentSession.Exchanges, number, draft)
+	}
+	prompt = c.scriptPrompt(ctx, workflow.StepPlanning, prompt)
+
+	response, err := planningAgent.RunWithCallback(ctx, prompt, func(event agent.Event) error {
+		c.eventBus.PublishRaw(events.Event{
+			Type: events.TypeAgentMessage,
+			Data: map[string]any{"event": event},
+		})
+
+		return nil
+	})
+	if err != nil {
+		c.persistCurrentSession(taskID)
+
+		return nil, fmt.Errorf("agent planning: %w", err)
+	}
+
+	c.recordUsage(taskID, "planning", workflow.StepPlanning, planningAgent, response.Usage)
+	c.recordAgentSession(response)
+
+	turn := &PlanningTurn{Reply: extractContextSummary(response)}
+	if response.Question != nil {
+		// The question becomes part of the conversation; the next turn answers it
+		turn.Question = response.Question
+		turn.Reply = response.Question.Text
+	} else {
+		spec := &storage.Specification{
+			Number:   number,
+			Status:   storage.SpecificationStatusDraft,
+			Template: c.taskWork.Metadata.SpecTemplate,
+			Content:  formatSpecificationContent(number, response),
+		}
+		if existing, err := c.workspace.ParseSpecification(taskID, number); err == nil {
+			spec.CreatedAt = existing.CreatedAt
+			spec.Template = existing.Template
+		}
+		if err := c.workspace.SaveSpecificationWithMeta(taskID, spec); err != nil {
+			c.persistCurrentSession(taskID)
+
+			return nil, fmt.Errorf("save specification: %w", err)
+		}
+		turn.Specification = number
+	}
+
+	c.recordExchange("agent", turn.Reply)
+	c.persistCurrentSession(taskID)
+
+	return turn, nil
+}
+
+// EndPlanning closes an interactive planning session: the session is saved,
+// a checkpoint is created for the drafted specifications and the task
+// returns to idle.
+func (c *Conductor) EndPlanning(ctx context.Context) error {
+	if c.activeTask == nil {
+		return errors.New("no active task")
+	}
+
+	taskID := c.activeTask.ID
+	c.saveCurrentSession(taskID)
+	c.createCheckpointIfNeeded(ctx, taskID, "Refine specifications for task "+taskID)
+
+	c.activeTask.State = "idle"
+	if err := c.workspace.SaveActiveTask(c.activeTask); err != nil {
+		return fmt.Errorf("save active task: %w", err)
+	}
+	_ = c.machine.Dispatch(ctx, workflow.EventPlanDone)
+
+	return nil
+}
+
+// ApproveSpecification marks a draft specification as ready for implementation.
+func (c *Conductor) ApproveSpecification(number int) error {
+	if c.activeTask == nil {
+		return errors.New("no active task")
+	}
+
+	taskID := c.activeTask.ID
+	spec, err := c.workspace.ParseSpecification(taskID, number)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("specification-%d not found", number)
+		}
+
+		return fmt.Errorf("load specification-%d: %w", number, err)
+	}
+
+	switch spec.Status {
+	case storage.SpecificationStatusReady:
+		return nil
+	case storage.SpecificationStatusImplementing, storage.SpecificationStatusDone:
+		return fmt.Errorf("specification-%d is already %s", number, spec.Status)
+	}
+
+	return c.workspace.UpdateSpecificationStatus(taskID, number, storage.SpecificationStatusReady)
+}
+
+// AnswerQuestion records an answer to the agent's pending question as a task
+// note and clears the question, so the next planning run continues with it.
+func (c *Conductor) AnswerQuestion(answer string) error {
+	if c.activeTask == nil {
+		return errors.New("no active task")
+	}
+
+	return c.workspace.AnswerPendingQuestion(c.activeTask.ID, answer)
+}
+
+// draftSpecification returns the specification an interactive planning turn
+// revises: the latest draft, or the next number when there is none.
+func (c *Conductor) draftSpecification(taskID string) (int, string, error) {
+	specs, err := c.workspace.ListSpecificationsWithStatus(taskID)
+	if err != nil {
+		return 0, "", fmt.Errorf("list specifications: %w", err)
+	}
+
+	for i := len(specs) - 1; i >= 0; i-- {
+		if specs[i].Status == storage.SpecificationStatusDraft {
+			return specs[i].Number, specs[i].Content, nil
+		}
+	}
+
+	number, err := c.workspace.NextSpecificationNumber(taskID)
+	if err != nil {
+		return 0, "", fmt.Errorf("get next specification number: %w", err)
+	}
+
+	return number, "", nil
+}
+
+// planningConversationPrompt adds the planning conversation so far and the
+// draft being refined to an interactive planning prompt.
+func planningConversationPrompt(exchanges []storage.Exchange, number int, draft string) string {
+	return sessionHistoryPrompt("Planning Conversation", exchanges) + draftRevisionPrompt(number, draft)
+}
+
+// draftRevisionPrompt asks the agent to revise the draft specification.
+func draftRevisionPrompt(number int, draft string) string {
+	var sb strings.Builder
+
+	if draft != "" {
+		fmt.Fprintf(&sb, "\n## Draft Specification %d\n%s\n", number, draft)
+	}
+
+	sb.WriteString(`
+Revise the draft specification to address the latest user message. Output the
+complete updated specification, not just the changes, and summarize what you
+changed.
+`)
+
+	return sb.String()
+}
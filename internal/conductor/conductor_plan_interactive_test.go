@@ -0,0 +1,129 @@
+package conductor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider/file"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestContinuePlanning(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	taskPath := filepath.Join(tmpDir, "task.md")
+	if err := os.WriteFile(taskPath, []byte("# Interactive task\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	c, err := New(WithWorkDir(tmpDir), WithCreateBranch(false), WithAgent("mock"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	file.Register(c.GetProviderRegistry())
+	if err := c.GetAgentRegistry().Register(&mockAgent{name: "mock"}); err != nil {
+		t.Fatalf("Register mock agent: %v", err)
+	}
+	if err := c.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if err := c.Start(ctx, "file:"+taskPath); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := c.Plan(ctx); err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+
+	taskID := c.GetActiveTask().ID
+	ws := c.GetWorkspace()
+
+	// Turns refine the same draft until it is approved
+	for _, input := range []string{"Use PostgreSQL", "Add a migration step"} {
+		turn, err := c.ContinuePlanning(ctx, input)
+		if err != nil {
+			t.Fatalf("ContinuePlanning(%q): %v", input, err)
+		}
+		if turn.Specification != 1 {
+			t.Errorf("ContinuePlanning(%q) specification = %d, want 1", input, turn.Specification)
+		}
+	}
+
+	spec, err := ws.ParseSpecification(taskID, 1)
+	if err != nil {
+		t.Fatalf("ParseSpecification: %v", err)
+	}
+	if spec.Status != storage.SpecificationStatusDraft {
+		t.Errorf("status = %q, want draft", spec.Status)
+	}
+
+	if err := c.ApproveSpecification(1); err != nil {
+		t.Fatalf("ApproveSpecification: %v", err)
+	}
+	if spec, _ := ws.ParseSpecification(taskID, 1); spec.Status != storage.SpecificationStatusReady {
+		t.Errorf("status after approve = %q, want ready", spec.Status)
+	}
+	if err := c.ApproveSpecification(5); err == nil {
+		t.Error("ApproveSpecification(5) should fail for a missing specification")
+	}
+
+	turn, err := c.ContinuePlanning(ctx, "Now plan the API")
+	if err != nil {
+		t.Fatalf("ContinuePlanning: %v", err)
+	}
+	if turn.Specification != 2 {
+		t.Errorf("specification after approve = %d, want 2", turn.Specification)
+	}
+
+	if err := c.EndPlanning(ctx); err != nil {
+		t.Fatalf("EndPlanning: %v", err)
+	}
+	if c.GetActiveTask().State != "idle" {
+		t.Errorf("state = %q, want idle", c.GetActiveTask().State)
+	}
+
+	// All turns were appended to one planning session
+	filename, err := ws.LatestSessionFile(taskID, "planning")
+	if err != nil || filename == "" {
+		t.Fatalf("LatestSessionFile = %q, %v", filename, err)
+	}
+	session, err := ws.LoadSession(taskID, filename)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if len(session.Exchanges) != 6 {
+		t.Errorf("exchanges = %d, want 6", len(session.Exchanges))
+	}
+	if session.Metadata.EndedAt.IsZero() {
+		t.Error("session end time not set")
+	}
+}
+
+func TestPlanningConversationPrompt(t *testing.T) {
+	exchanges := []storage.Exchange{
+		{Role: "user", Content: "Use PostgreSQL"},
+		{Role: "agent", Content: "Switched storage to PostgreSQL"},
+	}
+
+	prompt := planningConversationPrompt(exchanges, 2, "# Specification 2")
+	for _, want := range []string{
+		"**User:** Use PostgreSQL",
+		"**Agent:** Switched storage to PostgreSQL",
+		"## Draft Specification 2",
+		"# Specification 2",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+
+	if strings.Contains(planningConversationPrompt(exchanges, 1, ""), "## Draft Specification") {
+		t.Error("prompt without a draft should not include a draft section")
+	}
+}
@@ -6,15 +6,17 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/valksor/go-mehrhof/internal/chaos"
 	"github.com/valksor/go-mehrhof/internal/events"
 	"github.com/valksor/go-mehrhof/internal/provider"
 	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
 )
 
 // finishWithPR creates a pull request instead of merging locally.
 func (c *Conductor) finishWithPR(ctx context.Context, opts FinishOptions) (*provider.PullRequest, error) {
-	if c.git == nil {
-		return nil, errors.New("git not available for PR creation")
+	if err := vcs.Require(c.GetVCS(), vcs.CapPullRequests, "pull request creation"); err != nil {
+		return nil, err
 	}
 
 	if c.activeTask.Branch == "" {
@@ -24,7 +26,10 @@ func (c *Conductor) finishWithPR(ctx context.Context, opts FinishOptions) (*prov
 	taskID := c.activeTask.ID
 	sourceBranch := c.activeTask.Branch
 
-	// Push the branch to remote first
+	// Push the branch to remote first, unless it would publish secrets
+	if err := c.checkPush(ctx, c.git, c.resolveTargetBranch(ctx, opts.TargetBranch), sourceBranch); err != nil {
+		return nil, err
+	}
 	if err := c.git.PushBranch(ctx, sourceBranch, "origin", true); err != nil {
 		return nil, fmt.Errorf("push branch: %w", err)
 	}
@@ -73,11 +78,19 @@ func (c *Conductor) finishWithPR(ctx context.Context, opts FinishOptions) (*prov
 
 	// Generate body if not provided
 	if prOpts.Body == "" {
-		prOpts.Body = c.generatePRBody(specs, diffStat)
+		prOpts.Body = c.generatePRBody(specs, diffStat, c.loadChangeSummaries(taskID))
 	}
 
 	// Create the PR
-	pr, err := prCreator.CreatePullRequest(ctx, prOpts)
+	if err := chaos.Check(chaos.PRCreate); err != nil {
+		return nil, fmt.Errorf("create pull request: %w", err)
+	}
+	var pr *provider.PullRequest
+	err = c.traceProvider(ctx, c.taskWork.Source.Type, "create_pull_request", func(ctx context.Context) error {
+		pr, err = prCreator.CreatePullRequest(ctx, prOpts)
+
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("create pull request: %w", err)
 	}
@@ -85,6 +98,7 @@ func (c *Conductor) finishWithPR(ctx context.Context, opts FinishOptions) (*prov
 	// Publish PRCreatedEvent
 	c.eventBus.Publish(events.PRCreatedEvent{
 		TaskID:   taskID,
+		Title:    c.taskWork.Metadata.Title,
 		PRNumber: pr.Number,
 		PRURL:    pr.URL,
 	})
@@ -95,7 +109,12 @@ func (c *Conductor) finishWithPR(ctx context.Context, opts FinishOptions) (*prov
 		if issueID != "" {
 			comment := fmt.Sprintf("Pull request created: #%d\n%s\n\nThe PR includes all changes from branch `%s`.",
 				pr.Number, pr.URL, sourceBranch)
-			if _, err := commenter.AddComment(ctx, issueID, comment); err != nil {
+			err := c.traceProvider(ctx, c.taskWork.Source.Type, "add_comment", func(ctx context.Context) error {
+				_, err := commenter.AddComment(ctx, issueID, comment)
+
+				return err
+			})
+			if err != nil {
 				c.logError(fmt.Errorf("add PR comment to issue: %w", err))
 				// Don't fail the PR creation
 			}
@@ -113,9 +132,9 @@ func (c *Conductor) resolveTaskProvider(ctx context.Context) (any, error) {
 
 	// Resolve provider from the stored reference
 	resolveOpts := provider.ResolveOptions{
-		DefaultProvider: c.opts.DefaultProvider,
+		DefaultProvider: c.defaultProvider(),
 	}
-	p, _, err := c.providers.Resolve(ctx, c.activeTask.Ref, provider.Config{}, resolveOpts)
+	p, _, err := c.providers.Resolve(ctx, c.activeTask.Ref, c.providerConfig(c.referenceProvider(c.activeTask.Ref)), resolveOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -165,8 +184,12 @@ func (c *Conductor) getDiffStats(ctx context.Context) string {
 		return ""
 	}
 
-	// git diff --stat base..HEAD
-	stat, err := c.git.Diff(ctx, "--stat", baseBranch+"..HEAD")
+	// git diff --stat base..HEAD [-- scope]
+	args := []string{"--stat", baseBranch + "..HEAD"}
+	if scope := c.taskScope(); scope != "" {
+		args = append(args, "--", scope)
+	}
+	stat, err := c.git.Diff(ctx, args...)
 	if err != nil {
 		return ""
 	}
@@ -194,44 +217,47 @@ func (c *Conductor) generatePRTitle() string {
 	return title
 }
 
-// generatePRBody generates a PR body with implementation summary.
-func (c *Conductor) generatePRBody(specs []*storage.Specification, diffStat string) string {
-	var parts []string
+// generatePRBody generates a PR body with implementation summary and the
+// structural changes recorded in the task's sessions. A PR template of the
+// workspace or repository is filled in when there is one.
+func (c *Conductor) generatePRBody(specs []*storage.Specification, diffStat string, changes []storage.FileSummary) string {
+	vars := c.prBodyVars(specs, diffStat, changes)
 
-	// Summary section
-	parts = append(parts, "## Summary\n")
+	if c.workspace != nil {
+		tmpl, path, err := c.workspace.LoadPRTemplate()
+		if err != nil {
+			c.logError(err)
+		}
+		if tmpl != "" {
+			c.logVerbosef("Filling PR template %s", path)
 
-	if c.taskWork != nil && c.taskWork.Metadata.Title != "" {
-		parts = append(parts, fmt.Sprintf("Implementation for: %s\n", c.taskWork.Metadata.Title))
+			return fillPRTemplate(tmpl, vars) + prBodyFooter
+		}
 	}
 
-	// Link to issue if this is a GitHub issue task
-	if c.taskWork != nil && c.taskWork.Source.Type == "github" && c.taskWork.Metadata.ExternalKey != "" {
-		parts = append(parts, fmt.Sprintf("Closes #%s\n", c.taskWork.Metadata.ExternalKey))
+	var parts []string
+
+	// Summary section
+	parts = append(parts, "## Summary\n")
+	if vars["summary"] != "" {
+		parts = append(parts, vars["summary"])
 	}
 
 	// Specifications section
-	if len(specs) > 0 {
+	if vars["specs"] != "" {
 		parts = append(parts, "\n## Implementation Details\n")
-		for _, spec := range specs {
-			if spec.Title != "" {
-				parts = append(parts, fmt.Sprintf("### %s\n", spec.Title))
-			}
-			// Include first 500 chars of spec content as summary
-			content := spec.Content
-			if len(content) > 500 {
-				content = content[:500] + "..."
-			}
-			parts = append(parts, content+"\n")
-		}
+		parts = append(parts, vars["specs"])
 	}
 
 	// Changes section
-	if diffStat != "" {
+	if vars["diffstat"] != "" {
 		parts = append(parts, "\n## Changes\n")
-		parts = append(parts, "```\n"+diffStat+"\n```\n")
+		parts = append(parts, vars["diffstat"])
 	}
 
+	// Structural changes section
+	parts = append(parts, vars["structural_changes"])
+
 	// Test plan section
 	parts = append(parts, "\n## Test Plan\n")
 	parts = append(parts, "- [ ] Manual testing\n")
@@ -239,8 +265,7 @@ func (c *Conductor) generatePRBody(specs []*storage.Specification, diffStat stri
 	parts = append(parts, "- [ ] Code review\n")
 
 	// Footer
-	parts = append(parts, "\n---\n")
-	parts = append(parts, "*Generated by [Mehrhof](https://github.com/valksor/go-mehrhof)*\n")
+	parts = append(parts, prBodyFooter)
 
 	// Use strings.Builder for efficient concatenation
 	var sb strings.Builder
@@ -0,0 +1,109 @@
+package conductor
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// prBodyFooter ends every generated PR body.
+const prBodyFooter = "\n---\n*Generated by [Mehrhof](https://github.com/valksor/go-mehrhof)*\n"
+
+// prBodyKeys are the PR template placeholders filled from the task's work, in
+// addition to the task placeholders of spec templates.
+var prBodyKeys = []string{"summary", "closes", "specs", "diffstat", "structural_changes", "changes"}
+
+// prSections maps keywords of PR template headings to the placeholder whose
+// content fills the section. The first match wins.
+var prSections = []struct{ keyword, key string }{
+	{"summary", "summary"},
+	{"description", "summary"},
+	{"what", "summary"},
+	{"implementation", "specs"},
+	{"detail", "specs"},
+	{"specification", "specs"},
+	{"change", "changes"},
+}
+
+// prBodyVars returns the placeholder values of a PR body.
+func (c *Conductor) prBodyVars(specs []*storage.Specification, diffStat string, changes []storage.FileSummary) map[string]string {
+	vars := make(map[string]string)
+	if c.taskWork != nil {
+		maps.Copy(vars, c.taskWork.TemplateVars())
+	}
+
+	var summary strings.Builder
+	if c.taskWork != nil && c.taskWork.Metadata.Title != "" {
+		fmt.Fprintf(&summary, "Implementation for: %s\n", c.taskWork.Metadata.Title)
+	}
+	// Link to issue if this is a GitHub issue task
+	if c.taskWork != nil && c.taskWork.Source.Type == "github" && c.taskWork.Metadata.ExternalKey != "" {
+		vars["closes"] = fmt.Sprintf("Closes #%s\n", c.taskWork.Metadata.ExternalKey)
+		summary.WriteString(vars["closes"])
+	}
+	vars["summary"] = summary.String()
+
+	var details strings.Builder
+	for _, spec := range specs {
+		if spec.Title != "" {
+			fmt.Fprintf(&details, "### %s\n", spec.Title)
+		}
+		// Include first 500 chars of spec content as summary
+		content := spec.Content
+		if len(content) > 500 {
+			content = content[:500] + "..."
+		}
+		details.WriteString(content + "\n")
+	}
+	vars["specs"] = details.String()
+
+	if diffStat != "" {
+		vars["diffstat"] = "```\n" + diffStat + "\n```\n"
+	}
+	vars["structural_changes"] = formatStructuralChanges(changes)
+	vars["changes"] = vars["diffstat"] + vars["structural_changes"]
+
+	return vars
+}
+
+// fillPRTemplate fills a PR template with vars. Templates using {name}
+// placeholders are rendered like spec templates. Other templates have the
+// generated content inserted below the headings of their sections: summary
+// or description, implementation details, and changes.
+func fillPRTemplate(tmpl string, vars map[string]string) string {
+	for _, key := range prBodyKeys {
+		if strings.Contains(tmpl, "{"+key+"}") {
+			return storage.RenderSpecTemplate(tmpl, vars)
+		}
+	}
+
+	filled := make(map[string]bool)
+	var sb strings.Builder
+	for line := range strings.Lines(tmpl) {
+		sb.WriteString(line)
+
+		heading, ok := strings.CutPrefix(strings.TrimSpace(line), "#")
+		if !ok {
+			continue
+		}
+		heading = strings.ToLower(strings.TrimLeft(heading, "# "))
+		for _, s := range prSections {
+			if !strings.Contains(heading, s.keyword) {
+				continue
+			}
+			if !filled[s.key] && vars[s.key] != "" {
+				if !strings.HasSuffix(line, "\n") {
+					sb.WriteString("\n")
+				}
+				sb.WriteString("\n" + strings.TrimLeft(vars[s.key], "\n") + "\n")
+				filled[s.key] = true
+			}
+
+			break
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,56 @@
+package conductor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFillPRTemplate(t *testing.T) {
+	vars := map[string]string{
+		"title":    "Add login",
+		"summary":  "Implementation for: Add login\n",
+		"specs":    "### Login form\nDetails\n",
+		"diffstat": "```\n 1 file changed\n```\n",
+		"changes":  "```\n 1 file changed\n```\n",
+	}
+
+	tests := []struct {
+		name        string
+		tmpl        string
+		wantContain []string
+		wantAbsent  []string
+	}{
+		{
+			name:        "placeholders",
+			tmpl:        "# {title}\n\n{summary}\n## Files\n{diffstat}\n{unknown}\n",
+			wantContain: []string{"# Add login\n", "Implementation for: Add login", "1 file changed", "{unknown}"},
+			wantAbsent:  []string{"### Login form"},
+		},
+		{
+			name: "sections",
+			tmpl: "## Description\n<!-- What does this change? -->\n\n## Changes made\n\n## Checklist\n- [ ] Tests\n",
+			wantContain: []string{
+				"## Description\n\nImplementation for: Add login\n\n<!-- What does this change? -->",
+				"## Changes made\n\n```\n 1 file changed\n```\n",
+				"## Checklist\n- [ ] Tests\n",
+			},
+			wantAbsent: []string{"### Login form"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fillPRTemplate(tt.tmpl, vars)
+			for _, want := range tt.wantContain {
+				if !strings.Contains(got, want) {
+					t.Errorf("fillPRTemplate() missing %q in:\n%s", want, got)
+				}
+			}
+			for _, absent := range tt.wantAbsent {
+				if strings.Contains(got, absent) {
+					t.Errorf("fillPRTemplate() contains %q in:\n%s", absent, got)
+				}
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/storage"
 )
 
 // buildPlanningPrompt creates the prompt for specification generation.
@@ -55,6 +56,60 @@ Output your specification in a structured format with clear sections.`
 	return prompt
 }
 
+// sessionHistoryPrompt replays a session's earlier exchanges for agents that
+// cannot resume their own conversation.
+func sessionHistoryPrompt(title string, exchanges []storage.Exchange) string {
+	if len(exchanges) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\n## %s\n", title)
+	for _, ex := range exchanges {
+		role := "User"
+		if ex.Role != "user" {
+			role = "Agent"
+		}
+		fmt.Fprintf(&sb, "\n**%s:** %s\n", role, ex.Content)
+	}
+
+	return sb.String()
+}
+
+// buildFollowUpPrompt starts a prompt for an agent that resumed its earlier
+// conversation, so the task context is not sent again.
+func buildFollowUpPrompt(input string) string {
+	return fmt.Sprintf(`Continue our previous conversation about this task.
+
+%s
+`, input)
+}
+
+// planningFollowUp asks a resumed planning agent for an updated specification.
+func planningFollowUp(notes string) string {
+	prompt := "Create an updated implementation specification based on our conversation so far."
+	if notes != "" {
+		prompt += "\n\n## Additional Notes\n" + notes
+	}
+
+	return prompt
+}
+
+// specTemplatePrompt asks the planning agent to follow a rendered spec template.
+func specTemplatePrompt(template string) string {
+	if template == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(`
+## Specification Format
+Structure your specification using the following template. Keep its headings
+in order and fill in every section; write "N/A" for sections that do not apply.
+
+%s
+`, template)
+}
+
 // buildImplementationPrompt creates the prompt for implementation.
 func buildImplementationPrompt(title, sourceContent, specsContent, notes string) string {
 	prompt := fmt.Sprintf(`You are a software engineer. Implement the following task according to the specifications.
@@ -136,13 +191,93 @@ Review the implementation for:
 
 Provide:
 1. A summary of your findings
-2. Any issues found (critical, major, minor)
+2. Any issues found (critical, major, minor), each tagged with its area: [correctness], [code quality], [security], [performance], [best practices] or [lint]
 3. Suggested improvements
 4. If needed, provide corrected code in yaml:file blocks`
 
 	return prompt
 }
 
+// buildDiffReviewPrompt builds the prompt for reviewing changes that were not
+// made by a mehrhof task, asking for findings in a fixed line format.
+func buildDiffReviewPrompt(target, commits, diff string, truncated bool) string {
+	prompt := fmt.Sprintf(`You are a senior software engineer reviewing %s.
+
+## Commits
+%s
+
+## Diff
+`+"```diff\n%s\n```"+`
+`, target, commits, diff)
+
+	if truncated {
+		prompt += `
+The diff was too long and has been cut; review what is shown.
+`
+	}
+
+	prompt += `
+## Instructions
+Review the changes for correctness, security, performance, maintainability
+and adherence to the conventions of the surrounding code. Do not modify files.
+` + reviewFindingsFormat
+
+	return prompt
+}
+
+// reviewFindingsFormat asks for a review in the line format parsed by
+// parseReviewFindings.
+const reviewFindingsFormat = `
+Respond in exactly this format:
+
+## Summary
+A short overall assessment.
+
+## Findings
+- [critical|major|minor] path/to/file:line - description of the issue
+
+Use one line per finding with a single severity, the file path from the diff
+and the line number in the new version of the file (omit ":line" if the
+finding is not about a specific line). Write "- none" if there are no findings.`
+
+// buildConsensusReviewPrompt builds the prompt each agent of a consensus
+// review gets. The agents review the same changes independently and report
+// findings in the line format, so they can be reconciled.
+func buildConsensusReviewPrompt(title, sourceContent, specsContent, lintResults, diff string, truncated bool) string {
+	prompt := fmt.Sprintf(`You are a senior software engineer conducting a code review.
+
+## Task
+%s
+
+## Original Requirements
+%s
+
+## Specifications
+%s
+`, title, sourceContent, specsContent)
+
+	if lintResults != "" {
+		prompt += "\n" + lintResults + "\n"
+	}
+
+	if diff != "" {
+		prompt += "\n## Diff\n```diff\n" + diff + "\n```\n"
+		if truncated {
+			prompt += "\nThe diff was too long and has been cut; review what is shown.\n"
+		}
+	}
+
+	prompt += `
+## Instructions
+Review the implementation for correctness against the specifications,
+security, performance, maintainability and adherence to the conventions of
+the surrounding code. Other reviewers assess the same changes independently.
+Do not modify files and do not provide corrected code.
+` + reviewFindingsFormat
+
+	return prompt
+}
+
 // formatSpecificationContent formats a specification file from agent response.
 func formatSpecificationContent(num int, response *agent.Response) string {
 	content := fmt.Sprintf("# Specification %d\n\n", num)
@@ -212,3 +347,45 @@ func extractExploredFiles(response *agent.Response) []string {
 
 	return files
 }
+
+// buildDocumentationPrompt creates the prompt for the documentation phase.
+func buildDocumentationPrompt(title, specContent, changes string, truncated bool, docPaths []string) string {
+	prompt := fmt.Sprintf(`You are a software engineer keeping documentation in sync with code.
+The changes below were implemented for a task. Update the documentation they affect.
+
+## Task
+%s
+`, title)
+
+	if specContent != "" {
+		prompt += fmt.Sprintf(`
+## Specification
+%s
+`, specContent)
+	}
+
+	prompt += "\n## Changes\n```diff\n" + changes + "\n```\n"
+	if truncated {
+		prompt += `
+The diff was too long and has been cut; inspect the repository for the rest.
+`
+	}
+
+	prompt += fmt.Sprintf(`
+## Instructions
+Update README files, documentation and doc comments that these changes make
+outdated or incomplete, and document new user-facing behavior:
+1. Only change files matching: %s
+2. Go source files may only have their comments changed; do not change code
+3. Match the structure and tone of the existing documentation
+4. If nothing needs updating, do not output any files
+
+Output each file change in a yaml:file block with path, operation and the full content.
+
+End your answer with:
+
+## Documentation Updates
+- path/to/file: why it was changed`, strings.Join(docPaths, ", "))
+
+	return prompt
+}
@@ -0,0 +1,88 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+// ErrProtectedBranch is returned when finishing would merge locally into a
+// branch that is protected on the service hosting the repository.
+var ErrProtectedBranch = errors.New("target branch is protected")
+
+// checkTargetProtection returns an error wrapping ErrProtectedBranch when the
+// branch the task finishes into is protected. The task's provider is asked
+// when it can read branch protection, otherwise the provider matching the
+// origin remote's host. Protection that cannot be read does not block.
+func (c *Conductor) checkTargetProtection(ctx context.Context, requested string) error {
+	target := c.resolveTargetBranch(ctx, requested)
+	remoteURL, err := c.git.RemoteURL(ctx, "origin")
+	if target == "" || err != nil {
+		return nil //nolint:nilerr // Without a remote there is nothing to protect
+	}
+
+	name, reader := c.protectionReader(ctx, remoteURL)
+	if reader == nil {
+		return nil
+	}
+
+	var bp *provider.BranchProtection
+	err = c.traceProvider(ctx, name, "branch_protection", func(ctx context.Context) error {
+		bp, err = reader.BranchProtection(ctx, remoteURL, target)
+
+		return err
+	})
+	if err != nil {
+		c.logVerbosef("Could not read protection of %s: %v", target, err)
+
+		return nil
+	}
+	if !bp.Protected {
+		return nil
+	}
+
+	var rules []string
+	if bp.RequiredReviews > 0 {
+		rules = append(rules, fmt.Sprintf("%d approving review(s)", bp.RequiredReviews))
+	}
+	if len(bp.RequiredChecks) > 0 {
+		rules = append(rules, "checks "+strings.Join(bp.RequiredChecks, ", "))
+	}
+	if len(rules) > 0 {
+		return fmt.Errorf("%w: %s requires %s", ErrProtectedBranch, target, strings.Join(rules, " and "))
+	}
+
+	return fmt.Errorf("%w: %s", ErrProtectedBranch, target)
+}
+
+// protectionReader returns the provider to read branch protection from, with
+// its name, or nil when none can.
+func (c *Conductor) protectionReader(ctx context.Context, remoteURL string) (string, provider.ProtectionReader) {
+	if p, err := c.resolveTaskProvider(ctx); err == nil {
+		if reader, ok := p.(provider.ProtectionReader); ok {
+			return c.referenceProvider(c.activeTask.Ref), reader
+		}
+	}
+
+	var name string
+	switch {
+	case strings.Contains(remoteURL, "github"):
+		name = "github"
+	case strings.Contains(remoteURL, "gitlab"):
+		name = "gitlab"
+	default:
+		return "", nil
+	}
+	p, err := c.providers.Create(ctx, name, c.providerConfig(name))
+	if err != nil {
+		c.logVerbosef("Could not create %s provider to read branch protection: %v", name, err)
+
+		return "", nil
+	}
+	reader, _ := p.(provider.ProtectionReader)
+
+	return name, reader
+}
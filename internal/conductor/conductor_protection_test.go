@@ -0,0 +1,119 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+type stubProtectionProvider struct {
+	protection *provider.BranchProtection
+	err        error
+	remoteURL  string
+	branch     string
+}
+
+func (p *stubProtectionProvider) Parse(input string) (string, error) {
+	return strings.TrimPrefix(input, "stub:"), nil
+}
+
+func (p *stubProtectionProvider) Match(input string) bool {
+	return strings.HasPrefix(input, "stub:")
+}
+
+func (p *stubProtectionProvider) BranchProtection(_ context.Context, remoteURL, branch string) (*provider.BranchProtection, error) {
+	p.remoteURL, p.branch = remoteURL, branch
+
+	return p.protection, p.err
+}
+
+func TestCheckTargetProtection(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	const remote = "git@example.com:acme/widgets.git"
+
+	tests := []struct {
+		name       string
+		remote     bool
+		protection *provider.BranchProtection
+		err        error
+		wantErr    string
+	}{
+		{
+			name:       "no remote",
+			protection: &provider.BranchProtection{Protected: true},
+		},
+		{
+			name:       "unprotected",
+			remote:     true,
+			protection: &provider.BranchProtection{},
+		},
+		{
+			name:       "protected",
+			remote:     true,
+			protection: &provider.BranchProtection{Protected: true},
+			wantErr:    "target branch is protected: main",
+		},
+		{
+			name:       "protected with rules",
+			remote:     true,
+			protection: &provider.BranchProtection{Protected: true, RequiredReviews: 2, RequiredChecks: []string{"ci"}},
+			wantErr:    "main requires 2 approving review(s) and checks ci",
+		},
+		{
+			name:   "unreadable protection",
+			remote: true,
+			err:    errors.New("forbidden"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			tmpDir := t.TempDir()
+			initGitRepo(t, tmpDir)
+			if tt.remote {
+				if err := runGitCmd(ctx, tmpDir, "remote", "add", "origin", remote); err != nil {
+					t.Fatalf("git remote add: %v", err)
+				}
+			}
+
+			c, err := New(WithWorkDir(tmpDir), WithAgent("mock"))
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			if err := c.GetAgentRegistry().Register(&mockAgent{name: "mock"}); err != nil {
+				t.Fatalf("Register agent: %v", err)
+			}
+			if err := c.Initialize(ctx); err != nil {
+				t.Fatalf("Initialize: %v", err)
+			}
+			stub := &stubProtectionProvider{protection: tt.protection, err: tt.err}
+			info := provider.ProviderInfo{Name: "stub", Schemes: []string{"stub"}}
+			if err := c.GetProviderRegistry().Register(info, func(context.Context, provider.Config) (any, error) {
+				return stub, nil
+			}); err != nil {
+				t.Fatalf("Register: %v", err)
+			}
+			c.activeTask = &storage.ActiveTask{ID: "t1", Ref: "stub:42", Branch: "task/t1", UseGit: true}
+
+			err = c.checkTargetProtection(ctx, "main")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkTargetProtection = %v, want nil", err)
+				}
+			} else if !errors.Is(err, ErrProtectedBranch) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("checkTargetProtection = %v, want ErrProtectedBranch containing %q", err, tt.wantErr)
+			}
+			if tt.remote && (stub.remoteURL != remote || stub.branch != "main") {
+				t.Errorf("asked for %q on %q, want main on %q", stub.branch, stub.remoteURL, remote)
+			}
+		})
+	}
+}
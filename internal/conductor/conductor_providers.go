@@ -0,0 +1,48 @@
+package conductor
+
+import (
+	"strconv"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+// providerConfig returns the config the provider with the given scheme or
+// name is created with: the templates directory, and the workspace settings
+// the provider cannot find on its own, such as the GitLab host its stored
+// credentials belong to or the GitHub App to authenticate as.
+func (c *Conductor) providerConfig(scheme string) provider.Config {
+	cfg := provider.NewConfig()
+	if c.workspace == nil {
+		return cfg
+	}
+	cfg.Set("templates_dir", c.workspace.TemplatesDir())
+
+	ws, err := c.workspace.LoadConfig()
+	if err != nil {
+		return cfg
+	}
+	info, _, ok := c.providers.GetByScheme(scheme)
+	if !ok {
+		return cfg
+	}
+
+	switch info.Name {
+	case "github":
+		if ws.GitHub != nil && ws.GitHub.App != nil {
+			app := ws.GitHub.App
+			if app.AppID != 0 {
+				cfg.Set("app.app_id", strconv.FormatInt(app.AppID, 10))
+			}
+			cfg.Set("app.private_key_path", app.PrivateKeyPath)
+			if app.InstallationID != 0 {
+				cfg.Set("app.installation_id", strconv.FormatInt(app.InstallationID, 10))
+			}
+		}
+	case "gitlab":
+		if ws.GitLab != nil && ws.GitLab.Host != "" {
+			cfg.Set("host", ws.GitLab.Host)
+		}
+	}
+
+	return cfg
+}
@@ -0,0 +1,138 @@
+package conductor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// stubIdentifier parses any reference by dropping its scheme.
+type stubIdentifier struct{}
+
+func (stubIdentifier) Parse(input string) (string, error) {
+	_, id, _ := strings.Cut(input, ":")
+
+	return id, nil
+}
+
+func (stubIdentifier) Match(string) bool {
+	return true
+}
+
+// newProviderConfigConductor returns an initialized conductor with a
+// provider registered under name and schemes, recording the config each
+// instance was created with.
+func newProviderConfigConductor(t *testing.T, name string, schemes ...string) (*Conductor, *[]provider.Config) {
+	t.Helper()
+
+	c, err := New(WithWorkDir(t.TempDir()), WithAgent("mock"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := c.GetAgentRegistry().Register(&mockAgent{name: "mock"}); err != nil {
+		t.Fatalf("Register agent: %v", err)
+	}
+	if err := c.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	var created []provider.Config
+	info := provider.ProviderInfo{Name: name, Schemes: schemes}
+	if err := c.GetProviderRegistry().Register(info, func(_ context.Context, cfg provider.Config) (any, error) {
+		created = append(created, cfg)
+
+		return stubIdentifier{}, nil
+	}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	return c, &created
+}
+
+func TestProviderConfig_GitLabHost(t *testing.T) {
+	c, created := newProviderConfigConductor(t, "gitlab", "gitlab", "gl")
+
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	cfg.GitLab = &storage.GitLabSettings{Host: "https://gitlab.example.com"}
+	if err := c.workspace.SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	// A task reference using the short scheme, as 'mehr start gl:...' stores it
+	c.activeTask = &storage.ActiveTask{ID: "t1", Ref: "gl:group/project#7"}
+	if _, err := c.resolveTaskProvider(context.Background()); err != nil {
+		t.Fatalf("resolveTaskProvider: %v", err)
+	}
+	// A provider created by name, as for reading branch protection
+	if _, err := c.providers.Create(context.Background(), "gitlab", c.providerConfig("gitlab")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if len(*created) != 2 {
+		t.Fatalf("created %d providers, want 2", len(*created))
+	}
+	for _, got := range *created {
+		if host := got.GetString("host"); host != "https://gitlab.example.com" {
+			t.Errorf("provider host = %q, want the workspace's gitlab.host", host)
+		}
+		if got.GetString("templates_dir") == "" {
+			t.Error("provider config lost the templates directory")
+		}
+	}
+}
+
+func TestProviderConfig_GitHubApp(t *testing.T) {
+	c, created := newProviderConfigConductor(t, "github", "github", "gh")
+
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	cfg.GitHub = &storage.GitHubSettings{App: &storage.GitHubAppSettings{
+		AppID:          123456,
+		PrivateKeyPath: "/etc/mehrhof/app.pem",
+		InstallationID: 42,
+	}}
+	if err := c.workspace.SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	c.activeTask = &storage.ActiveTask{ID: "t1", Ref: "gh:7"}
+	if _, err := c.resolveTaskProvider(context.Background()); err != nil {
+		t.Fatalf("resolveTaskProvider: %v", err)
+	}
+
+	got := (*created)[0]
+	if got.GetString("app.app_id") != "123456" || got.GetString("app.private_key_path") != "/etc/mehrhof/app.pem" ||
+		got.GetString("app.installation_id") != "42" {
+		t.Errorf("app config = %q, %q, %q, want the workspace's github.app",
+			got.GetString("app.app_id"), got.GetString("app.private_key_path"), got.GetString("app.installation_id"))
+	}
+}
+
+func TestProviderConfig_OtherProviders(t *testing.T) {
+	c, created := newProviderConfigConductor(t, "wrike", "wrike")
+
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	cfg.GitLab = &storage.GitLabSettings{Host: "https://gitlab.example.com"}
+	if err := c.workspace.SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	c.activeTask = &storage.ActiveTask{ID: "t1", Ref: "wrike:123"}
+	if _, err := c.resolveTaskProvider(context.Background()); err != nil {
+		t.Fatalf("resolveTaskProvider: %v", err)
+	}
+	if host := (*created)[0].GetString("host"); host != "" {
+		t.Errorf("wrike host = %q, want gitlab.host kept to GitLab", host)
+	}
+}
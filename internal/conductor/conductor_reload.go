@@ -0,0 +1,179 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/config"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/fswatch"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// reloadDebounce is how long WatchConfig waits after a change for more
+// changes, since editors often write a file in several steps.
+const reloadDebounce = 250 * time.Millisecond
+
+// ErrInvalidConfig is returned by ReloadConfig when the changed config does
+// not validate; the previous config stays in effect.
+var ErrInvalidConfig = errors.New("workspace config is invalid")
+
+// appliedConfig is the part of the workspace config a long-running
+// conductor holds on to, which ReloadConfig compares against and replaces.
+type appliedConfig struct {
+	aliases  []string          // Agent aliases registered from the config
+	agent    string            // agent.default the options were built from
+	provider string            // providers.default the options were built from
+	env      map[string]string // Variables of the .env file last loaded
+}
+
+// rememberConfig records the config Initialize applied.
+func (c *Conductor) rememberConfig(cfg *storage.WorkspaceConfig) {
+	c.applied.agent = cfg.Agent.Default
+	c.applied.provider = cfg.Providers.Default
+	c.applied.env, _ = config.ReadDotEnv(c.workspace.Root())
+}
+
+// ReloadConfig re-reads the workspace config and .env file and applies
+// them without a restart: .env variables, agent aliases, and the default
+// agent and provider unless a flag or environment variable overrides
+// them. The config is first checked with Options.ValidateConfig; when it
+// is invalid nothing is applied and ErrInvalidConfig is returned. Settings read when they are
+// used, such as provider credentials, need no reload. Publishes a
+// ConfigReloadedEvent naming the changed files.
+func (c *Conductor) ReloadConfig(ctx context.Context, files ...string) error {
+	if c.workspace == nil {
+		return errors.New("workspace not initialized")
+	}
+
+	env, err := config.ReadDotEnv(c.workspace.Root())
+	if err != nil {
+		return fmt.Errorf("read %s: %w", config.EnvFileName, err)
+	}
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	c.mu.Lock()
+	if c.opts.ValidateConfig != nil {
+		builtIn := slices.DeleteFunc(c.agents.List(), func(name string) bool {
+			return slices.Contains(c.applied.aliases, name)
+		})
+		if err := c.opts.ValidateConfig(cfg, c.workspace.ConfigPath(), builtIn); err != nil {
+			c.mu.Unlock()
+
+			return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+	}
+
+	// .env first: alias env may reference its variables
+	changedEnv := config.ReloadDotEnv(c.applied.env, env)
+	c.applied.env = env
+
+	for _, name := range c.applied.aliases {
+		c.agents.Unregister(name)
+	}
+	c.applied.aliases = nil
+	aliasErr := c.registerAliasAgents(cfg)
+	c.setupResponseCache(cfg)
+
+	c.defaultsMu.Lock()
+	if c.opts.DefaultAgent == "" || c.opts.DefaultAgent == c.applied.agent {
+		c.opts.DefaultAgent = cfg.Agent.Default
+	}
+	if c.opts.DefaultProvider == "" || c.opts.DefaultProvider == c.applied.provider {
+		c.opts.DefaultProvider = cfg.Providers.Default
+	}
+	c.defaultsMu.Unlock()
+	c.applied.agent = cfg.Agent.Default
+	c.applied.provider = cfg.Providers.Default
+	aliases := slices.Clone(c.applied.aliases)
+	c.mu.Unlock()
+
+	if aliasErr != nil {
+		return fmt.Errorf("register alias agents: %w", aliasErr)
+	}
+
+	c.eventBus.Publish(events.ConfigReloadedEvent{Files: files, Aliases: aliases, EnvVars: changedEnv})
+
+	return nil
+}
+
+// defaultAgent returns the agent used when neither the task nor a step
+// names one. ReloadConfig may replace it while a workflow step runs.
+func (c *Conductor) defaultAgent() string {
+	c.defaultsMu.Lock()
+	defer c.defaultsMu.Unlock()
+
+	return c.opts.DefaultAgent
+}
+
+// defaultProvider returns the provider for bare references. ReloadConfig
+// may replace it while a workflow step runs.
+func (c *Conductor) defaultProvider() string {
+	c.defaultsMu.Lock()
+	defer c.defaultsMu.Unlock()
+
+	return c.opts.DefaultProvider
+}
+
+// isConfigFile reports whether a change to path needs a config reload.
+func isConfigFile(path string) bool {
+	switch filepath.Base(path) {
+	case "config.yaml", "config.local.yaml", config.EnvFileName:
+		return true
+	}
+
+	return false
+}
+
+// WatchConfig reloads the workspace config whenever config.yaml,
+// config.local.yaml or .env changes, until ctx is done. Reload failures,
+// such as an invalid config, are published as error events and the
+// previous config stays in effect.
+func (c *Conductor) WatchConfig(ctx context.Context) error {
+	if c.workspace == nil {
+		return errors.New("workspace not initialized")
+	}
+
+	watcher := fswatch.New()
+	defer func() { _ = watcher.Close() }()
+	if err := watcher.Add(c.workspace.TaskRoot(), false); err != nil {
+		return fmt.Errorf("watch %s: %w", c.workspace.TaskRoot(), err)
+	}
+
+	var (
+		changed []string
+		timer   <-chan time.Time
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-watcher.Events():
+			if !ok {
+				return nil
+			}
+			if !isConfigFile(e.Path) {
+				continue
+			}
+			if name := filepath.Base(e.Path); !slices.Contains(changed, name) {
+				changed = append(changed, name)
+			}
+			timer = time.After(reloadDebounce)
+		case err := <-watcher.Errors():
+			c.logError(fmt.Errorf("watch config: %w", err))
+		case <-timer:
+			slices.Sort(changed)
+			if err := c.ReloadConfig(ctx, changed...); err != nil {
+				c.eventBus.Publish(events.ErrorEvent{Error: fmt.Errorf("reload config: %w", err)})
+			}
+			changed, timer = nil, nil
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/validation"
+)
+
+// newReloadConductor initializes a conductor on a workspace whose config
+// defines the alias "fast" of the registered agent "claude".
+func newReloadConductor(t *testing.T) (*Conductor, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	writeReloadConfig(t, tmpDir, "agents:\n  fast:\n    extends: claude\n")
+
+	validate := func(cfg *storage.WorkspaceConfig, path string, builtIn []string) error {
+		if result := validation.ValidateConfig(cfg, path, builtIn); !result.Valid {
+			return errors.New(result.Format("text"))
+		}
+
+		return nil
+	}
+
+	c, err := New(WithWorkDir(tmpDir), WithConfigValidator(validate))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := c.GetAgentRegistry().Register(&testAgent{name: "claude"}); err != nil {
+		t.Fatalf("Register agent: %v", err)
+	}
+	if err := c.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	return c, tmpDir
+}
+
+func writeReloadConfig(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, ".mehrhof"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".mehrhof", "config.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	c, tmpDir := newReloadConductor(t)
+
+	var reloaded []events.Event
+	c.GetEventBus().Subscribe(events.TypeConfigReloaded, func(e events.Event) {
+		reloaded = append(reloaded, e)
+	})
+
+	t.Setenv("RELOAD_TEST_KEY", "")
+	_ = os.Unsetenv("RELOAD_TEST_KEY")
+	if err := os.WriteFile(filepath.Join(tmpDir, ".mehrhof", ".env"), []byte("RELOAD_TEST_KEY=secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	writeReloadConfig(t, tmpDir, "agents:\n  careful:\n    extends: claude\nproviders:\n  default: github\n")
+
+	if err := c.ReloadConfig(context.Background(), "config.yaml", ".env"); err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+
+	agents := c.GetAgentRegistry().List()
+	if !slices.Contains(agents, "careful") || slices.Contains(agents, "fast") {
+		t.Errorf("agents after reload = %v, want careful without fast", agents)
+	}
+	if got := os.Getenv("RELOAD_TEST_KEY"); got != "secret" {
+		t.Errorf("RELOAD_TEST_KEY = %q, want secret", got)
+	}
+	if c.opts.DefaultProvider != "github" {
+		t.Errorf("DefaultProvider = %q, want github", c.opts.DefaultProvider)
+	}
+	if len(reloaded) != 1 || !slices.Equal(reloaded[0].Data["files"].([]string), []string{"config.yaml", ".env"}) {
+		t.Errorf("config_reloaded events = %+v", reloaded)
+	}
+
+	// An invalid config is not applied
+	writeReloadConfig(t, tmpDir, "agents:\n  broken:\n    extends: nosuchagent\n")
+	if err := c.ReloadConfig(context.Background()); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("ReloadConfig(invalid) error = %v, want ErrInvalidConfig", err)
+	}
+	agents = c.GetAgentRegistry().List()
+	if !slices.Contains(agents, "careful") || slices.Contains(agents, "broken") {
+		t.Errorf("agents after invalid reload = %v, want careful kept", agents)
+	}
+	if len(reloaded) != 1 {
+		t.Errorf("invalid reload published config_reloaded")
+	}
+}
+
+func TestReloadConfig_KeepsOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeReloadConfig(t, tmpDir, "providers:\n  default: file\n")
+
+	c, err := New(WithWorkDir(tmpDir), WithDefaultProvider("jira"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := c.GetAgentRegistry().Register(&testAgent{name: "claude"}); err != nil {
+		t.Fatalf("Register agent: %v", err)
+	}
+	if err := c.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	writeReloadConfig(t, tmpDir, "providers:\n  default: github\n")
+	if err := c.ReloadConfig(context.Background()); err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	if c.opts.DefaultProvider != "jira" {
+		t.Errorf("DefaultProvider = %q, want the jira override kept", c.opts.DefaultProvider)
+	}
+}
+
+func TestReloadConfig_ConcurrentDefaults(t *testing.T) {
+	c, tmpDir := newReloadConductor(t)
+	writeReloadConfig(t, tmpDir, "agent:\n  default: claude\nproviders:\n  default: github\n")
+
+	// Steps resolve their agent and provider while the watcher reloads;
+	// run with -race to catch unguarded reads
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 20 {
+			if err := c.ReloadConfig(context.Background()); err != nil {
+				t.Errorf("ReloadConfig: %v", err)
+
+				return
+			}
+		}
+	}()
+	for range 20 {
+		if _, err := c.agentConfig(); err != nil {
+			t.Fatalf("agentConfig: %v", err)
+		}
+		_ = c.referenceProvider("task-123")
+	}
+	<-done
+
+	if got := c.defaultProvider(); got != "github" {
+		t.Errorf("defaultProvider() = %q, want github", got)
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	c, tmpDir := newReloadConductor(t)
+
+	reloaded := make(chan events.Event, 1)
+	c.GetEventBus().Subscribe(events.TypeConfigReloaded, func(e events.Event) {
+		select {
+		case reloaded <- e:
+		default:
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.WatchConfig(ctx) }()
+
+	// Give the watcher time to start before changing the file
+	time.Sleep(100 * time.Millisecond)
+	writeReloadConfig(t, tmpDir, "agents:\n  careful:\n    extends: claude\n")
+
+	select {
+	case e := <-reloaded:
+		if files := e.Data["files"].([]string); !slices.Equal(files, []string{"config.yaml"}) {
+			t.Errorf("files = %v, want [config.yaml]", files)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no config_reloaded event after editing config.yaml")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("WatchConfig: %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package conductor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/bucket"
+)
+
+// autoPushTimeout bounds the upload after a workflow step.
+const autoPushTimeout = 2 * time.Minute
+
+// autoPushWork uploads the active task's work directory to remote storage
+// when storage.remote.auto_push is set, so its state survives an ephemeral
+// machine and can be picked up elsewhere with 'mehr task pull'. It runs when
+// a step releases the task lease, also after a failed or interrupted step.
+// Failures are logged; they never fail the step.
+func (c *Conductor) autoPushWork(ctx context.Context, taskID string) {
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil || cfg.Storage.Remote == nil || !cfg.Storage.Remote.AutoPush {
+		return
+	}
+
+	backend, err := bucket.FromSettings(cfg.Storage.Remote, c.workspace.Root())
+	if err != nil {
+		c.logError(fmt.Errorf("auto-push task: %w", err))
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), autoPushTimeout)
+	defer cancel()
+
+	result, err := c.workspace.PushWork(ctx, backend, taskID)
+	if err != nil {
+		c.logError(fmt.Errorf("auto-push task to %s: %w", backend.Name(), err))
+
+		return
+	}
+	c.logVerbosef("Pushed %d file(s) of task %s to %s", result.Copied, taskID, backend.Name())
+}
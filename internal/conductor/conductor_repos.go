@@ -0,0 +1,452 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/chaos"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/naming"
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+// ErrUnknownWorkspace is returned when a task attaches a workspace that is not configured.
+var ErrUnknownWorkspace = errors.New("unknown workspace")
+
+// attachedRepo is an opened secondary repository of the active task.
+type attachedRepo struct {
+	info *storage.RepoInfo
+	git  *vcs.Git
+}
+
+// resolveRepoPath returns the absolute path of a secondary repository.
+// Relative paths are resolved against the project root.
+func (c *Conductor) resolveRepoPath(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+
+	return filepath.Join(c.workspace.Root(), path)
+}
+
+// attachRepositories opens the workspaces named in the options and, when branch
+// creation is enabled, creates and checks out the task branch in each. Branches
+// created before a failure are removed again.
+func (c *Conductor) attachRepositories(ctx context.Context, ni *namingInfo) ([]storage.RepoInfo, error) {
+	if len(c.opts.Repositories) == 0 {
+		return nil, nil
+	}
+	if err := vcs.Require(c.GetVCS(), vcs.CapBranches|vcs.CapCheckpoints, "attaching repositories"); err != nil {
+		return nil, err
+	}
+
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	var repos []storage.RepoInfo
+	fail := func(err error) ([]storage.RepoInfo, error) {
+		c.detachRepositories(ctx, repos)
+
+		return nil, err
+	}
+
+	var seen []string
+	for _, name := range c.opts.Repositories {
+		if slices.Contains(seen, name) {
+			continue
+		}
+		seen = append(seen, name)
+
+		wsCfg, ok := cfg.Workspaces[name]
+		if !ok {
+			return fail(fmt.Errorf("%w %q (define it under workspaces: in .mehrhof/config.yaml)", ErrUnknownWorkspace, name))
+		}
+
+		git, err := vcs.New(ctx, c.resolveRepoPath(wsCfg.Path))
+		if err != nil {
+			return fail(fmt.Errorf("open workspace %q: %w", name, err))
+		}
+		git = c.auditedGit(git, name)
+
+		info := storage.RepoInfo{
+			Name:         name,
+			Path:         wsCfg.Path,
+			TargetBranch: wsCfg.TargetBranch,
+		}
+		info.BaseBranch, _ = git.GetBaseBranch(ctx)
+
+		if c.opts.CreateBranch {
+			hasChanges, err := git.HasChanges(ctx)
+			if err != nil {
+				return fail(fmt.Errorf("check git status of workspace %q: %w", name, err))
+			}
+			if hasChanges {
+				return fail(fmt.Errorf("workspace %q has uncommitted changes", name))
+			}
+
+			pattern := wsCfg.BranchPattern
+			if pattern == "" {
+				pattern = ni.branchPattern
+			}
+			branch := naming.CleanBranchName(naming.ExpandTemplate(pattern, ni.vars))
+
+			if err := git.CreateBranch(ctx, branch, info.BaseBranch); err != nil {
+				return fail(fmt.Errorf("create branch in workspace %q: %w", name, err))
+			}
+			if err := git.Checkout(ctx, branch); err != nil {
+				_ = git.DeleteBranch(ctx, branch, false)
+
+				return fail(fmt.Errorf("checkout branch in workspace %q: %w", name, err))
+			}
+			info.Branch = branch
+		}
+
+		repos = append(repos, info)
+	}
+
+	return repos, nil
+}
+
+// detachRepositories switches secondary repositories back to their base branch
+// and deletes the task branches and checkpoints.
+// NOTE: Errors are logged but not returned; this is best-effort cleanup.
+func (c *Conductor) detachRepositories(ctx context.Context, repos []storage.RepoInfo) {
+	for _, info := range repos {
+		if info.Branch == "" {
+			continue
+		}
+
+		git, err := vcs.New(ctx, c.resolveRepoPath(info.Path))
+		if err != nil {
+			c.logError(fmt.Errorf("open workspace %q: %w", info.Name, err))
+
+			continue
+		}
+		git = c.auditedGit(git, info.Name)
+
+		if current, _ := git.CurrentBranch(ctx); current == info.Branch && info.BaseBranch != "" {
+			if err := git.Checkout(ctx, info.BaseBranch); err != nil {
+				c.logError(fmt.Errorf("checkout base branch in workspace %q: %w", info.Name, err))
+
+				continue
+			}
+		}
+
+		if c.activeTask != nil {
+			// Checkpoint deletion is best-effort; ignore errors
+			_ = git.DeleteAllCheckpoints(ctx, c.activeTask.ID)
+		}
+
+		if err := git.DeleteBranch(ctx, info.Branch, true); err != nil {
+			c.logError(fmt.Errorf("delete branch in workspace %q: %w", info.Name, err))
+		}
+	}
+}
+
+// attachedRepos opens the secondary repositories of the active task.
+// Repositories that cannot be opened are logged and skipped.
+func (c *Conductor) attachedRepos(ctx context.Context) []attachedRepo {
+	if c.taskWork == nil || len(c.taskWork.Repos) == 0 {
+		return nil
+	}
+
+	cfg, _ := c.workspace.LoadConfig()
+	repos := make([]attachedRepo, 0, len(c.taskWork.Repos))
+	for i := range c.taskWork.Repos {
+		info := &c.taskWork.Repos[i]
+		git, err := vcs.New(ctx, c.resolveRepoPath(info.Path))
+		if err != nil {
+			c.logError(fmt.Errorf("open workspace %q: %w", info.Name, err))
+
+			continue
+		}
+		repos = append(repos, attachedRepo{info: info, git: signedGit(cfg, c.auditedGit(git, info.Name))})
+	}
+
+	return repos
+}
+
+// repoRelPath returns the path of a secondary repository relative to the
+// primary repository root, as agents use it in file paths.
+func (c *Conductor) repoRelPath(info storage.RepoInfo) string {
+	abs := c.resolveRepoPath(info.Path)
+	if rel, err := filepath.Rel(c.repoRoot(), abs); err == nil {
+		return filepath.ToSlash(rel)
+	}
+
+	return filepath.ToSlash(abs)
+}
+
+// inAttachedRepo reports whether a path (relative to the primary repository
+// root) lies inside one of the task's secondary repositories.
+func (c *Conductor) inAttachedRepo(path string) bool {
+	if c.taskWork == nil || len(c.taskWork.Repos) == 0 {
+		return false
+	}
+
+	abs := filepath.Join(c.repoRoot(), path)
+	for _, info := range c.taskWork.Repos {
+		if validatePathInWorkspace(abs, c.resolveRepoPath(info.Path)) == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reposPrompt returns prompt instructions describing the attached repositories.
+func (c *Conductor) reposPrompt() string {
+	if c.taskWork == nil || len(c.taskWork.Repos) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n## Additional Repositories\n")
+	sb.WriteString("This task spans several repositories. Besides the current repository, you may read and change files in:\n")
+	for _, info := range c.taskWork.Repos {
+		fmt.Fprintf(&sb, "- %s: %s/", info.Name, c.repoRelPath(info))
+		if info.Branch != "" {
+			fmt.Fprintf(&sb, " (branch %s)", info.Branch)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("File paths in your output must be relative to the current repository root, so files in these repositories are prefixed with the paths above.\n")
+
+	return sb.String()
+}
+
+// createRepoCheckpoints creates checkpoint number with the same message in the
+// primary and all secondary repositories, so undo and redo stay in step.
+func (c *Conductor) createRepoCheckpoints(ctx context.Context, repos []attachedRepo, taskID, message, commitPrefix string) (*vcs.Checkpoint, error) {
+	number, err := c.git.NextCheckpointNumber(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint, err := c.git.CreateCheckpointAt(ctx, taskID, message, commitPrefix, number)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, repo := range repos {
+		if _, err := repo.git.CreateCheckpointAt(ctx, taskID, message, commitPrefix, number); err != nil {
+			c.logError(fmt.Errorf("create checkpoint in workspace %q: %w", repo.info.Name, err))
+		}
+	}
+
+	return checkpoint, nil
+}
+
+// reposHaveChanges reports whether any secondary repository has uncommitted changes.
+func (c *Conductor) reposHaveChanges(ctx context.Context, repos []attachedRepo) bool {
+	for _, repo := range repos {
+		if hasChanges, err := repo.git.HasChanges(ctx); err == nil && hasChanges {
+			return true
+		}
+	}
+
+	return false
+}
+
+// syncRepoCheckpoints restores secondary repositories to checkpoint number
+// after the primary repository moved there by undo or redo.
+func (c *Conductor) syncRepoCheckpoints(ctx context.Context, taskID string, number int) {
+	for _, repo := range c.attachedRepos(ctx) {
+		if err := repo.git.RestoreCheckpoint(ctx, taskID, number); err != nil {
+			c.logError(fmt.Errorf("restore checkpoint %d in workspace %q: %w", number, repo.info.Name, err))
+		}
+	}
+}
+
+// repoTargetBranch returns the branch a secondary repository's task branch
+// merges into.
+func repoTargetBranch(ctx context.Context, repo attachedRepo) string {
+	if repo.info.TargetBranch != "" {
+		return repo.info.TargetBranch
+	}
+	if repo.info.BaseBranch != "" {
+		return repo.info.BaseBranch
+	}
+	base, _ := repo.git.GetBaseBranch(ctx)
+
+	return base
+}
+
+// mergeRepos merges the task branch of every secondary repository into its
+// target branch, mirroring the options used for the primary repository.
+func (c *Conductor) mergeRepos(ctx context.Context, opts FinishOptions) error {
+	for _, repo := range c.attachedRepos(ctx) {
+		branch := repo.info.Branch
+		if branch == "" {
+			continue
+		}
+		target := repoTargetBranch(ctx, repo)
+
+		if err := repo.git.Checkout(ctx, target); err != nil {
+			return fmt.Errorf("workspace %q: checkout target: %w", repo.info.Name, err)
+		}
+
+		if opts.SquashMerge {
+			if err := repo.git.MergeSquash(ctx, branch); err != nil {
+				_ = repo.git.Checkout(ctx, branch)
+
+				return fmt.Errorf("workspace %q: squash merge: %w", repo.info.Name, err)
+			}
+			prefix := c.taskWork.Git.CommitPrefix
+			if prefix == "" {
+				prefix = fmt.Sprintf("(%s)", c.activeTask.ID)
+			}
+			if _, err := repo.git.Commit(ctx, fmt.Sprintf("%s merged from %s", prefix, branch)); err != nil {
+				_ = repo.git.Checkout(ctx, branch)
+
+				return fmt.Errorf("workspace %q: commit merge: %w", repo.info.Name, err)
+			}
+		} else if err := repo.git.MergeBranch(ctx, branch, true); err != nil {
+			_ = repo.git.Checkout(ctx, branch)
+
+			return fmt.Errorf("workspace %q: merge: %w", repo.info.Name, err)
+		}
+
+		if opts.PushAfter {
+			if err := c.checkPush(ctx, repo.git, "origin/"+target, target); err != nil {
+				return fmt.Errorf("workspace %q: %w", repo.info.Name, err)
+			}
+			if err := repo.git.PushBranch(ctx, target, "origin", false); err != nil {
+				return fmt.Errorf("workspace %q: push: %w", repo.info.Name, err)
+			}
+		}
+
+		if opts.DeleteBranch && branch != target {
+			// Checkpoint deletion is best-effort; ignore errors
+			_ = repo.git.DeleteAllCheckpoints(ctx, c.activeTask.ID)
+			if err := repo.git.DeleteBranch(ctx, branch, true); err != nil {
+				c.logError(fmt.Errorf("workspace %q: delete branch: %w", repo.info.Name, err))
+			}
+		}
+	}
+
+	return nil
+}
+
+// openRepoPRs pushes the task branch of every secondary repository and opens a
+// pull request in those with a PR provider configured. Each PR links back to
+// the primary PR.
+func (c *Conductor) openRepoPRs(ctx context.Context, opts FinishOptions, primary *provider.PullRequest) error {
+	repos := c.attachedRepos(ctx)
+	if len(repos) == 0 {
+		return nil
+	}
+
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	for _, repo := range repos {
+		branch := repo.info.Branch
+		if branch == "" {
+			continue
+		}
+
+		if err := c.checkPush(ctx, repo.git, repoTargetBranch(ctx, repo), branch); err != nil {
+			return fmt.Errorf("workspace %q: %w", repo.info.Name, err)
+		}
+		if err := repo.git.PushBranch(ctx, branch, "origin", true); err != nil {
+			return fmt.Errorf("workspace %q: push branch: %w", repo.info.Name, err)
+		}
+
+		wsCfg := cfg.Workspaces[repo.info.Name]
+		if wsCfg.PRProvider == "" {
+			c.logVerbosef("Pushed %s in workspace %q (no pr_provider configured)", branch, repo.info.Name)
+
+			continue
+		}
+
+		p, err := c.providers.Create(ctx, wsCfg.PRProvider, repoProviderConfig(wsCfg.PRConfig))
+		if err != nil {
+			return fmt.Errorf("workspace %q: create provider: %w", repo.info.Name, err)
+		}
+		prCreator, ok := p.(provider.PRCreator)
+		if !ok {
+			return fmt.Errorf("workspace %q: provider %s does not support PR creation", repo.info.Name, wsCfg.PRProvider)
+		}
+
+		title := opts.PRTitle
+		if title == "" {
+			title = c.generatePRTitle()
+		}
+		body := fmt.Sprintf("Part of a multi-repository task (%s).\n\nPrimary pull request: %s\n", c.activeTask.ID, primary.URL)
+		if opts.PRBody != "" {
+			body = opts.PRBody + "\n\n" + body
+		}
+
+		if err := chaos.Check(chaos.PRCreate); err != nil {
+			return fmt.Errorf("workspace %q: create pull request: %w", repo.info.Name, err)
+		}
+		prOpts := provider.PullRequestOptions{
+			Title:        title,
+			Body:         body,
+			SourceBranch: branch,
+			TargetBranch: repoTargetBranch(ctx, repo),
+			Draft:        opts.DraftPR,
+		}
+		var pr *provider.PullRequest
+		err = c.traceProvider(ctx, wsCfg.PRProvider, "create_pull_request", func(ctx context.Context) error {
+			pr, err = prCreator.CreatePullRequest(ctx, prOpts)
+
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("workspace %q: create pull request: %w", repo.info.Name, err)
+		}
+
+		repo.info.PRURL = pr.URL
+		c.eventBus.Publish(events.PRCreatedEvent{
+			TaskID:   c.activeTask.ID,
+			Title:    c.taskWork.Metadata.Title,
+			PRNumber: pr.Number,
+			PRURL:    pr.URL,
+		})
+		c.logVerbosef("Created PR #%d in workspace %q: %s", pr.Number, repo.info.Name, pr.URL)
+	}
+
+	if err := c.workspace.SaveWork(c.taskWork); err != nil {
+		c.logError(fmt.Errorf("save work: %w", err))
+	}
+
+	return nil
+}
+
+// repoProviderConfig converts a pr_config map into provider config.
+// Nested maps are flattened into dotted keys (e.g., app.app_id).
+func repoProviderConfig(values map[string]any) provider.Config {
+	cfg := provider.NewConfig()
+
+	var set func(prefix string, values map[string]any)
+	set = func(prefix string, values map[string]any) {
+		for k, v := range values {
+			if nested, ok := v.(map[string]any); ok {
+				set(prefix+k+".", nested)
+
+				continue
+			}
+			switch v.(type) {
+			case string, bool:
+				cfg.Set(prefix+k, v)
+			default:
+				cfg.Set(prefix+k, fmt.Sprint(v))
+			}
+		}
+	}
+	set("", values)
+
+	return cfg
+}
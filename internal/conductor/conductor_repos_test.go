@@ -0,0 +1,154 @@
+package conductor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider/file"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+func TestStart_WithAttachedRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	backend := filepath.Join(tmpDir, "backend")
+	frontend := filepath.Join(tmpDir, "frontend")
+	for _, dir := range []string{backend, frontend} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		initGitRepo(t, dir)
+	}
+
+	// Keep .mehrhof out of git so the primary repository stays clean
+	if err := os.WriteFile(filepath.Join(backend, ".gitignore"), []byte(".mehrhof/\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := runGitCmd(ctx, backend, "add", "."); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+	if err := runGitCmd(ctx, backend, "commit", "-m", "ignore mehrhof"); err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+
+	ws, err := storage.OpenWorkspace(backend, nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	cfg := storage.NewDefaultWorkspaceConfig()
+	cfg.Workspaces = map[string]storage.RepositoryWorkspace{
+		"frontend": {Path: "../frontend"},
+	}
+	if err := ws.SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	taskPath := filepath.Join(tmpDir, "task.md")
+	if err := os.WriteFile(taskPath, []byte("# Cross-repo feature\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	newConductor := func(repos ...string) *Conductor {
+		c, err := New(WithWorkDir(backend), WithCreateBranch(true), WithAgent("mock"), WithRepositories(repos...))
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		file.Register(c.GetProviderRegistry())
+		if err := c.GetAgentRegistry().Register(&mockAgent{name: "mock"}); err != nil {
+			t.Fatalf("Register mock agent: %v", err)
+		}
+		if err := c.Initialize(ctx); err != nil {
+			t.Fatalf("Initialize: %v", err)
+		}
+
+		return c
+	}
+
+	if err := newConductor("missing").Start(ctx, "file:"+taskPath); err == nil || !strings.Contains(err.Error(), "unknown workspace") {
+		t.Fatalf("Start with unknown workspace = %v, want unknown workspace error", err)
+	}
+
+	c := newConductor("frontend")
+	if err := c.Start(ctx, "file:"+taskPath); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	repos := c.GetTaskWork().Repos
+	if len(repos) != 1 || repos[0].Name != "frontend" {
+		t.Fatalf("repos = %+v", repos)
+	}
+	fg, err := vcs.New(ctx, frontend)
+	if err != nil {
+		t.Fatalf("vcs.New: %v", err)
+	}
+	if branch, _ := fg.CurrentBranch(ctx); branch != c.GetActiveTask().Branch || branch == "" {
+		t.Errorf("frontend branch = %q, want task branch %q", branch, c.GetActiveTask().Branch)
+	}
+	if !strings.Contains(c.reposPrompt(), "../frontend/") {
+		t.Errorf("reposPrompt() = %q", c.reposPrompt())
+	}
+	if !c.inAttachedRepo("../frontend/src/app.ts") || c.inAttachedRepo("../other/x") {
+		t.Error("inAttachedRepo() mismatch")
+	}
+
+	// Checkpoints are created in step, even when only the secondary changed
+	taskID := c.GetActiveTask().ID
+	appPath := filepath.Join(frontend, "app.ts")
+	for _, content := range []string{"v1", "v2"} {
+		if err := os.WriteFile(appPath, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		if event := c.createCheckpointIfNeeded(ctx, taskID, "change "+content); event == nil {
+			t.Fatalf("expected checkpoint for %s", content)
+		}
+	}
+	for name, g := range map[string]*vcs.Git{"backend": c.git, "frontend": fg} {
+		if cps, _ := g.ListCheckpoints(ctx, taskID); len(cps) != 2 {
+			t.Errorf("%s checkpoints = %d, want 2", name, len(cps))
+		}
+	}
+
+	checkpoint, err := c.git.Undo(ctx, taskID)
+	if err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	c.syncRepoCheckpoints(ctx, taskID, checkpoint.Number)
+	if data, _ := os.ReadFile(appPath); string(data) != "v1" {
+		t.Errorf("frontend app.ts after undo = %q, want v1", data)
+	}
+
+	// Abandoning removes the task branch from the secondary repository
+	branch := c.GetActiveTask().Branch
+	if err := c.Delete(ctx, DeleteOptions{}); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if fg.BranchExists(ctx, branch) {
+		t.Error("frontend task branch should be deleted")
+	}
+}
+
+func TestRepoProviderConfig(t *testing.T) {
+	cfg := repoProviderConfig(map[string]any{
+		"owner":    "acme",
+		"draft_pr": true,
+		"app":      map[string]any{"app_id": 42},
+	})
+
+	if cfg.GetString("owner") != "acme" {
+		t.Errorf("owner = %q", cfg.GetString("owner"))
+	}
+	if !cfg.GetBool("draft_pr") {
+		t.Error("draft_pr should be true")
+	}
+	if cfg.GetString("app.app_id") != "42" {
+		t.Errorf("app.app_id = %q, want 42", cfg.GetString("app.app_id"))
+	}
+}
@@ -0,0 +1,335 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/progress"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// ErrReviewBlocking is returned by Finish while the latest consensus review
+// of the task has findings that enough agents agree on.
+var ErrReviewBlocking = errors.New("consensus review has blocking findings")
+
+// reviewLineTolerance is how many lines apart two agents' findings in the
+// same file may be and still count as the same issue.
+const reviewLineTolerance = 3
+
+// reviewMessageSimilarity is the share of words two findings without a line
+// must have in common to count as the same issue.
+const reviewMessageSimilarity = 0.5
+
+// agentReview is one agent's part of a consensus review.
+type agentReview struct {
+	agent    agent.Agent
+	response *agent.Response
+	err      error
+}
+
+// consensusReviewers returns the agents of a consensus review and the
+// agreement needed for a finding to block, from the options or else from
+// workflow.review. It returns no agents when consensus review is not
+// configured.
+func (c *Conductor) consensusReviewers() ([]agent.Agent, int, error) {
+	names, minAgreement := c.opts.ReviewAgents, c.opts.ReviewMinAgreement
+	if len(names) == 0 && c.workspace != nil {
+		if cfg, err := c.workspace.LoadConfig(); err == nil {
+			names = cfg.Workflow.Review.Agents
+			if minAgreement == 0 {
+				minAgreement = cfg.Workflow.Review.MinAgreement
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, 0, nil
+	}
+	if len(names) < 2 {
+		return nil, 0, errors.New("consensus review needs at least two agents")
+	}
+
+	if minAgreement == 0 {
+		minAgreement = min(storage.DefaultReviewMinAgreement, len(names))
+	}
+	if minAgreement < 1 || minAgreement > len(names) {
+		return nil, 0, fmt.Errorf("minimum agreement %d must be between 1 and %d", minAgreement, len(names))
+	}
+
+	reviewers := make([]agent.Agent, 0, len(names))
+	for _, name := range names {
+		reviewer, err := c.agents.Get(name)
+		if err != nil {
+			return nil, 0, fmt.Errorf("review agent %q: %w", name, err)
+		}
+		reviewers = append(reviewers, c.withTracing(withFailureInjection(reviewer), workflow.StepReviewing.String()))
+	}
+
+	return reviewers, minAgreement, nil
+}
+
+// reviewWithConsensus has every reviewer review independently and in
+// parallel, then reconciles their findings into review. Usage is recorded
+// for taskID when it is set. Reviewers that fail are left out, as long as
+// enough remain to reach minAgreement.
+func (c *Conductor) reviewWithConsensus(ctx context.Context, taskID string, reviewers []agent.Agent, minAgreement int, prompt string, review *DiffReview) error {
+	reviews := make([]*agentReview, len(reviewers))
+	var wg sync.WaitGroup
+	for i, reviewer := range reviewers {
+		reviews[i] = &agentReview{agent: reviewer}
+		wg.Go(func() {
+			reviews[i].response, reviews[i].err = reviewer.RunWithCallback(ctx, prompt, func(event agent.Event) error {
+				c.eventBus.PublishRaw(events.Event{
+					Type: events.TypeAgentMessage,
+					Data: map[string]any{"event": event, "agent": reviewer.Name()},
+				})
+
+				return nil
+			})
+		})
+	}
+	wg.Wait()
+
+	var findings [][]ReviewFinding
+	var summaries []string
+	for _, r := range reviews {
+		name := r.agent.Name()
+		if r.err != nil {
+			c.logError(fmt.Errorf("%s review: %w", name, r.err))
+
+			continue
+		}
+		if taskID != "" {
+			c.recordUsage(taskID, "review", workflow.StepReviewing, r.agent, r.response.Usage)
+		}
+
+		text := strings.Join(append([]string{r.response.Summary}, r.response.Messages...), "\n")
+		summary, agentFindings := parseReviewFindings(text)
+		for i := range agentFindings {
+			agentFindings[i].Agents = []string{name}
+		}
+		review.Agents = append(review.Agents, name)
+		findings = append(findings, agentFindings)
+		if summary != "" {
+			summaries = append(summaries, fmt.Sprintf("**%s:** %s", name, summary))
+		}
+	}
+	if len(review.Agents) < minAgreement {
+		return fmt.Errorf("only %d of %d review agents finished, %d needed", len(review.Agents), len(reviewers), minAgreement)
+	}
+
+	review.MinAgreement = minAgreement
+	review.Summary = strings.Join(summaries, "\n\n")
+	review.Findings = reconcileFindings(findings)
+
+	return nil
+}
+
+// reconcileFindings merges the findings of several agents, given in agent
+// order, into one list. Findings about the same issue are merged into one
+// that lists every agent that reported it and keeps the highest severity.
+// The result is ordered by agreement, then severity.
+func reconcileFindings(perAgent [][]ReviewFinding) []ReviewFinding {
+	var merged []ReviewFinding
+	for _, findings := range perAgent {
+		for _, f := range findings {
+			i := slices.IndexFunc(merged, func(m ReviewFinding) bool {
+				return !slices.Contains(m.Agents, f.Agents[0]) && sameFinding(m, f)
+			})
+			if i < 0 {
+				merged = append(merged, f)
+
+				continue
+			}
+
+			m := &merged[i]
+			m.Agents = append(slices.Clip(m.Agents), f.Agents...)
+			if severityRank(f.Severity) < severityRank(m.Severity) {
+				m.Severity = f.Severity
+			}
+			if m.Line == 0 {
+				m.Line = f.Line
+			}
+		}
+	}
+
+	slices.SortStableFunc(merged, func(a, b ReviewFinding) int {
+		if len(a.Agents) != len(b.Agents) {
+			return len(b.Agents) - len(a.Agents)
+		}
+
+		return severityRank(a.Severity) - severityRank(b.Severity)
+	})
+
+	return merged
+}
+
+// sameFinding reports whether two findings describe the same issue: they are
+// about the same file and either lie within reviewLineTolerance lines of each
+// other or, when one has no line, are worded alike.
+func sameFinding(a, b ReviewFinding) bool {
+	if path.Clean(a.File) != path.Clean(b.File) {
+		return false
+	}
+	if a.Line > 0 && b.Line > 0 {
+		return max(a.Line-b.Line, b.Line-a.Line) <= reviewLineTolerance
+	}
+
+	return wordOverlap(a.Message, b.Message) >= reviewMessageSimilarity
+}
+
+// wordOverlap returns the share of distinct words the two texts have in
+// common, from 0 to 1.
+func wordOverlap(a, b string) float64 {
+	words := func(s string) map[string]bool {
+		set := make(map[string]bool)
+		for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+			return !('a' <= r && r <= 'z' || '0' <= r && r <= '9' || r == '_')
+		}) {
+			set[w] = true
+		}
+
+		return set
+	}
+	wa, wb := words(a), words(b)
+	if len(wa) == 0 || len(wb) == 0 {
+		return 0
+	}
+
+	common := 0
+	for w := range wa {
+		if wb[w] {
+			common++
+		}
+	}
+
+	return float64(common) / float64(len(wa)+len(wb)-common)
+}
+
+// severityRank orders severities from most (0) to least severe.
+func severityRank(severity string) int {
+	if i := slices.Index(ReviewSeverities, severity); i >= 0 {
+		return i
+	}
+
+	return len(ReviewSeverities)
+}
+
+// runConsensusReview reviews the active task with several agents. Unlike the
+// single-agent review it does not apply fixes: the reconciled findings are
+// saved as a note, and the ones enough agents agree on are recorded on the
+// task to block finishing until a later review clears them.
+func (c *Conductor) runConsensusReview(ctx context.Context, reviewers []agent.Agent, minAgreement int, statusLine *progress.StatusLine) error {
+	taskID := c.activeTask.ID
+
+	names := make([]string, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		names = append(names, reviewer.Name())
+	}
+	session, filename, err := c.workspace.CreateSession(taskID, "review", strings.Join(names, ","), c.activeTask.State)
+	if err != nil {
+		c.logError(fmt.Errorf("create session: %w", err))
+	} else {
+		c.currentSession = session
+		c.currentSessionFile = filename
+	}
+
+	sourceContent, err := c.workspace.GetSourceContent(taskID)
+	if err != nil {
+		return fmt.Errorf("get source content: %w", err)
+	}
+	specContent, _, _ := c.workspace.GetLatestSpecificationContent(taskID)
+
+	c.publishProgress("Running automated linters...", 10)
+	lintResults := c.runLinters(ctx)
+	diff, truncated := c.taskDiff(ctx)
+
+	prompt := buildConsensusReviewPrompt(c.taskWork.Metadata.Title, sourceContent, specContent, lintResults, diff, truncated)
+	prompt += scopePrompt(c.taskScope())
+	prompt += c.reposPrompt()
+	prompt += c.lessonsPrompt()
+	prompt += c.conventionsPrompt(workflow.StepReviewing)
+	prompt = c.scriptPrompt(ctx, workflow.StepReviewing, prompt)
+
+	c.publishProgress(fmt.Sprintf("%d agents reviewing...", len(reviewers)), 20)
+	review := &DiffReview{CreatedAt: time.Now(), Target: "task " + taskID, Truncated: truncated}
+	if err := c.reviewWithConsensus(ctx, taskID, reviewers, minAgreement, prompt, review); err != nil {
+		c.abortReview(ctx, statusLine)
+
+		return fmt.Errorf("consensus review: %w", err)
+	}
+
+	c.publishProgress("Processing review...", 70)
+	report := review.Report()
+	if err := c.workspace.AppendNote(taskID, "## Review Results\n\n"+report, "reviewing"); err != nil {
+		c.logError(fmt.Errorf("append review note: %w", err))
+	}
+	c.recordFailures(lessonSourceReview, categorizeReview(report))
+
+	gate := &storage.ReviewGate{ReviewedAt: review.CreatedAt, Agents: review.Agents, MinAgreement: minAgreement}
+	for _, f := range review.Blocking() {
+		gate.Blocking = append(gate.Blocking, formatFinding(f))
+	}
+	c.taskWork.Review = gate
+	if err := c.workspace.SaveWork(c.taskWork); err != nil {
+		c.logError(fmt.Errorf("save review gate: %w", err))
+	}
+	if len(gate.Blocking) > 0 {
+		c.publishProgress(fmt.Sprintf("%d finding(s) reported by at least %d agents block finishing", len(gate.Blocking), minAgreement), 90)
+	}
+
+	c.activeTask.State = "idle"
+	if err := c.workspace.SaveActiveTask(c.activeTask); err != nil {
+		c.logError(fmt.Errorf("save active task: %w", err))
+	}
+	_ = c.machine.Dispatch(ctx, workflow.EventReviewDone)
+	c.saveCurrentSession(taskID)
+	c.publishProgress("Review complete", 100)
+
+	return nil
+}
+
+// abortReview returns the task to idle after a review that could not run.
+func (c *Conductor) abortReview(ctx context.Context, statusLine *progress.StatusLine) {
+	if statusLine != nil {
+		statusLine.Done()
+	}
+	c.activeTask.State = "idle"
+	if err := c.workspace.SaveActiveTask(c.activeTask); err != nil {
+		c.logError(fmt.Errorf("save active task after review error: %w", err))
+	}
+	_ = c.machine.Dispatch(ctx, workflow.EventError)
+}
+
+// formatFinding formats a finding on one line, as recorded in the review gate.
+func formatFinding(f ReviewFinding) string {
+	location := f.File
+	if f.Line > 0 {
+		location += fmt.Sprintf(":%d", f.Line)
+	}
+
+	return fmt.Sprintf("[%s] %s - %s (%s)", f.Severity, location, f.Message, strings.Join(f.Agents, ", "))
+}
+
+// checkReviewGate refuses to finish while the latest consensus review has
+// blocking findings, unless opts skips the gate.
+func (c *Conductor) checkReviewGate(opts FinishOptions) error {
+	if opts.SkipReviewGate || c.taskWork == nil || c.taskWork.Review == nil {
+		return nil
+	}
+	gate := c.taskWork.Review
+	if len(gate.Blocking) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %d reported by at least %d of %d agents:\n  %s",
+		ErrReviewBlocking, len(gate.Blocking), gate.MinAgreement, len(gate.Agents), strings.Join(gate.Blocking, "\n  "))
+}
@@ -0,0 +1,203 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// findingsAgent answers a review prompt with a fixed review, or fails.
+type findingsAgent struct {
+	mockAgent
+
+	reply string
+	err   error
+}
+
+func (a *findingsAgent) RunWithCallback(ctx context.Context, prompt string, cb agent.StreamCallback) (*agent.Response, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+
+	return &agent.Response{Summary: a.reply}, nil
+}
+
+func TestReconcileFindings(t *testing.T) {
+	perAgent := [][]ReviewFinding{
+		{
+			{Severity: SeverityMajor, File: "cache.go", Line: 42, Message: "Map written without the lock", Agents: []string{"claude"}},
+			{Severity: SeverityMinor, File: "README.md", Message: "Typo in the install heading", Agents: []string{"claude"}},
+		},
+		{
+			{Severity: SeverityCritical, File: "./cache.go", Line: 44, Message: "Data race on the entries map", Agents: []string{"codex"}},
+			{Severity: SeverityMinor, File: "cache.go", Line: 90, Message: "Unused parameter", Agents: []string{"codex"}},
+			{Severity: SeverityMinor, File: "README.md", Message: "typo in install heading", Agents: []string{"codex"}},
+		},
+		{
+			{Severity: SeverityMajor, File: "cache.go", Line: 41, Message: "Concurrent map write", Agents: []string{"gemini"}},
+		},
+	}
+
+	got := reconcileFindings(perAgent)
+	if len(got) != 3 {
+		t.Fatalf("findings = %+v, want 3", got)
+	}
+
+	race := got[0]
+	if race.File != "cache.go" || race.Line != 42 || race.Severity != SeverityCritical ||
+		!slices.Equal(race.Agents, []string{"claude", "codex", "gemini"}) {
+		t.Errorf("race finding = %+v", race)
+	}
+	if typo := got[1]; typo.File != "README.md" || !slices.Equal(typo.Agents, []string{"claude", "codex"}) {
+		t.Errorf("typo finding = %+v", typo)
+	}
+	if unused := got[2]; unused.Line != 90 || len(unused.Agents) != 1 {
+		t.Errorf("unused finding = %+v", unused)
+	}
+}
+
+func TestReconcileFindings_SameAgentKeepsBoth(t *testing.T) {
+	got := reconcileFindings([][]ReviewFinding{{
+		{Severity: SeverityMinor, File: "a.go", Line: 1, Message: "First", Agents: []string{"claude"}},
+		{Severity: SeverityMinor, File: "a.go", Line: 2, Message: "Second", Agents: []string{"claude"}},
+	}})
+	if len(got) != 2 {
+		t.Errorf("findings of one agent were merged: %+v", got)
+	}
+}
+
+func TestDiffReviewBlocking(t *testing.T) {
+	review := &DiffReview{
+		Target:       "task abc",
+		Agents:       []string{"claude", "codex", "gemini"},
+		MinAgreement: 2,
+		Findings: []ReviewFinding{
+			{Severity: SeverityMajor, File: "cache.go", Line: 42, Message: "Data race", Agents: []string{"claude", "codex"}},
+			{Severity: SeverityMinor, File: "cache.go", Line: 90, Message: "Unused parameter", Agents: []string{"gemini"}},
+		},
+	}
+
+	blocking := review.Blocking()
+	if len(blocking) != 1 || blocking[0].Message != "Data race" {
+		t.Errorf("Blocking() = %+v", blocking)
+	}
+
+	report := review.Report()
+	for _, want := range []string{
+		"Agents: claude, codex, gemini",
+		"Blocking: 1 (reported by at least 2 of 3 agents)",
+		"- `cache.go:42` Data race (2/3 agents: claude, codex)",
+		"- `cache.go:90` Unused parameter (1/3 agents: gemini)",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report does not contain %q:\n%s", want, report)
+		}
+	}
+
+	single := &DiffReview{Agent: "claude", Findings: review.Findings}
+	if len(single.Blocking()) != 0 {
+		t.Error("a single-agent review should not block")
+	}
+}
+
+func TestReviewWithConsensus(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := New(WithWorkDir(t.TempDir()), WithAgent("claude"), WithReviewConsensus([]string{"claude", "codex", "gemini"}, 0))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for _, a := range []agent.Agent{
+		&findingsAgent{mockAgent: mockAgent{name: "claude"}, reply: "## Summary\nOne race.\n\n## Findings\n- [major] cache.go:42 - Map written without the lock"},
+		&findingsAgent{mockAgent: mockAgent{name: "codex"}, reply: "## Summary\nRacy.\n\n## Findings\n- [critical] cache.go:43 - Data race\n- [minor] cache.go:90 - Unused parameter"},
+		&findingsAgent{mockAgent: mockAgent{name: "gemini"}, err: errors.New("rate limited")},
+	} {
+		if err := c.agents.Register(a); err != nil {
+			t.Fatalf("Register: %v", err)
+		}
+	}
+	if err := c.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	reviewers, minAgreement, err := c.consensusReviewers()
+	if err != nil {
+		t.Fatalf("consensusReviewers: %v", err)
+	}
+	if len(reviewers) != 3 || minAgreement != 2 {
+		t.Fatalf("reviewers = %d, minAgreement = %d", len(reviewers), minAgreement)
+	}
+
+	review := &DiffReview{}
+	if err := c.reviewWithConsensus(ctx, "", reviewers, minAgreement, "review", review); err != nil {
+		t.Fatalf("reviewWithConsensus: %v", err)
+	}
+	if !slices.Equal(review.Agents, []string{"claude", "codex"}) {
+		t.Errorf("agents = %v, want the two that finished", review.Agents)
+	}
+	if blocking := review.Blocking(); len(blocking) != 1 || blocking[0].Severity != SeverityCritical {
+		t.Errorf("blocking = %+v", blocking)
+	}
+	if !strings.Contains(review.Summary, "**claude:** One race.") {
+		t.Errorf("summary = %q", review.Summary)
+	}
+
+	if err := c.reviewWithConsensus(ctx, "", reviewers, 3, "review", &DiffReview{}); err == nil {
+		t.Error("expected an error when too few agents finish to reach the agreement")
+	}
+}
+
+func TestConsensusReviewers_Invalid(t *testing.T) {
+	tests := []struct {
+		name         string
+		agents       []string
+		minAgreement int
+	}{
+		{name: "one agent", agents: []string{"claude"}},
+		{name: "agreement above agents", agents: []string{"claude", "codex"}, minAgreement: 3},
+		{name: "unknown agent", agents: []string{"claude", "nope"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(WithWorkDir(t.TempDir()), WithReviewConsensus(tt.agents, tt.minAgreement))
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			for _, name := range []string{"claude", "codex"} {
+				if err := c.agents.Register(&mockAgent{name: name}); err != nil {
+					t.Fatalf("Register: %v", err)
+				}
+			}
+			if _, _, err := c.consensusReviewers(); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestCheckReviewGate(t *testing.T) {
+	c := &Conductor{taskWork: &storage.TaskWork{}}
+	if err := c.checkReviewGate(FinishOptions{}); err != nil {
+		t.Errorf("unreviewed task: %v", err)
+	}
+
+	c.taskWork.Review = &storage.ReviewGate{Agents: []string{"claude", "codex"}, MinAgreement: 2}
+	if err := c.checkReviewGate(FinishOptions{}); err != nil {
+		t.Errorf("review without blocking findings: %v", err)
+	}
+
+	c.taskWork.Review.Blocking = []string{"[major] cache.go:42 - Data race (claude, codex)"}
+	err := c.checkReviewGate(FinishOptions{})
+	if !errors.Is(err, ErrReviewBlocking) || !strings.Contains(err.Error(), "cache.go:42") {
+		t.Errorf("err = %v, want ErrReviewBlocking listing the finding", err)
+	}
+	if err := c.checkReviewGate(FinishOptions{SkipReviewGate: true}); err != nil {
+		t.Errorf("skipped gate: %v", err)
+	}
+}
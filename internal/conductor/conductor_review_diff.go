@@ -0,0 +1,331 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// maxReviewDiffBytes caps the diff sent to the agent; larger diffs are cut
+// and the agent is told so.
+const maxReviewDiffBytes = 200 << 10
+
+// Review finding severities, most severe first.
+const (
+	SeverityCritical = "critical"
+	SeverityMajor    = "major"
+	SeverityMinor    = "minor"
+)
+
+// ReviewSeverities lists the finding severities, most severe first.
+var ReviewSeverities = []string{SeverityCritical, SeverityMajor, SeverityMinor}
+
+// DiffReviewOptions selects the changes reviewed by ReviewDiff. Exactly one of
+// Range and PR must be set.
+type DiffReviewOptions struct {
+	Range  string // Commit range, e.g. "main..feature"
+	Remote string // Remote the pull request is fetched from (default: origin)
+	Base   string // Branch a pull request is compared against (default: detected base branch)
+	PR     int    // Pull/merge request number
+}
+
+// ReviewFinding is one issue reported by a review.
+type ReviewFinding struct {
+	Severity string
+	File     string
+	Message  string
+	Line     int      // 0 when the finding is not tied to a line
+	Agents   []string // Agents that reported the finding, in a consensus review
+}
+
+// DiffReview is the structured result of reviewing a diff.
+type DiffReview struct {
+	CreatedAt time.Time
+	Target    string // What was reviewed, e.g. "PR #123" or "main..feature"
+	Agent     string
+	Summary   string
+	Findings  []ReviewFinding
+	Truncated bool // The diff exceeded maxReviewDiffBytes
+
+	// Consensus reviews only
+	Agents       []string // Agents whose reviews were reconciled
+	MinAgreement int      // Agents that must report a finding for it to block
+}
+
+// Status returns ISSUES when the review has findings and COMPLETE otherwise.
+func (r *DiffReview) Status() string {
+	if len(r.Findings) > 0 {
+		return "ISSUES"
+	}
+
+	return "COMPLETE"
+}
+
+// Blocking returns the findings of a consensus review that enough agents
+// reported to block finishing. Single-agent reviews never block.
+func (r *DiffReview) Blocking() []ReviewFinding {
+	if r.MinAgreement == 0 {
+		return nil
+	}
+
+	var blocking []ReviewFinding
+	for _, f := range r.Findings {
+		if len(f.Agents) >= r.MinAgreement {
+			blocking = append(blocking, f)
+		}
+	}
+
+	return blocking
+}
+
+// Count returns the number of findings with the given severity.
+func (r *DiffReview) Count(severity string) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == severity {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Report formats the review as a markdown findings report.
+func (r *DiffReview) Report() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Code Review - %s\n\n", r.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&sb, "Target: %s\n", r.Target)
+	if len(r.Agents) > 0 {
+		fmt.Fprintf(&sb, "Agents: %s\n", strings.Join(r.Agents, ", "))
+	} else {
+		fmt.Fprintf(&sb, "Agent: %s\n", r.Agent)
+	}
+	fmt.Fprintf(&sb, "Status: %s\n", r.Status())
+
+	counts := make([]string, 0, len(ReviewSeverities))
+	for _, severity := range ReviewSeverities {
+		counts = append(counts, fmt.Sprintf("%d %s", r.Count(severity), severity))
+	}
+	fmt.Fprintf(&sb, "Findings: %s\n", strings.Join(counts, ", "))
+	if r.MinAgreement > 0 {
+		fmt.Fprintf(&sb, "Blocking: %d (reported by at least %d of %d agents)\n", len(r.Blocking()), r.MinAgreement, len(r.Agents))
+	}
+	if r.Truncated {
+		fmt.Fprintf(&sb, "Note: the diff was longer than %d KB and only its beginning was reviewed\n", maxReviewDiffBytes>>10)
+	}
+
+	if r.Summary != "" {
+		sb.WriteString("\n## Summary\n\n" + r.Summary + "\n")
+	}
+
+	for _, severity := range ReviewSeverities {
+		if r.Count(severity) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n## %s\n\n", strings.ToUpper(severity[:1])+severity[1:])
+		for _, f := range r.Findings {
+			if f.Severity != severity {
+				continue
+			}
+			location := f.File
+			if f.Line > 0 {
+				location += ":" + strconv.Itoa(f.Line)
+			}
+			if len(r.Agents) > 0 {
+				fmt.Fprintf(&sb, "- `%s` %s (%d/%d agents: %s)\n", location, f.Message, len(f.Agents), len(r.Agents), strings.Join(f.Agents, ", "))
+
+				continue
+			}
+			fmt.Fprintf(&sb, "- `%s` %s\n", location, f.Message)
+		}
+	}
+
+	return sb.String()
+}
+
+// ReviewDiff reviews changes that mehrhof did not create: a commit range or
+// an existing pull request. It needs no active task and does not modify the
+// working tree.
+func (c *Conductor) ReviewDiff(ctx context.Context, opts DiffReviewOptions) (*DiffReview, error) {
+	if c.git == nil {
+		return nil, errors.New("reviewing a diff requires a git repository")
+	}
+	if (opts.Range == "") == (opts.PR == 0) {
+		return nil, errors.New("specify either a commit range or a pull request")
+	}
+
+	target, diffRange, err := c.resolveReviewRange(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	c.publishProgress("Collecting changes for "+target+"...", 0)
+	diff, err := c.git.Diff(ctx, diffRange)
+	if err != nil {
+		return nil, fmt.Errorf("diff %s: %w", diffRange, err)
+	}
+	if strings.TrimSpace(diff) == "" {
+		return nil, fmt.Errorf("no changes in %s", target)
+	}
+	commits, _ := c.git.Log(ctx, "--oneline", "--no-decorate", diffRange)
+
+	review := &DiffReview{CreatedAt: time.Now(), Target: target}
+	if len(diff) > maxReviewDiffBytes {
+		diff = diff[:maxReviewDiffBytes]
+		review.Truncated = true
+	}
+
+	prompt := buildDiffReviewPrompt(target, commits, diff, review.Truncated)
+
+	reviewers, minAgreement, err := c.consensusReviewers()
+	if err != nil {
+		return nil, err
+	}
+	if len(reviewers) > 0 {
+		c.publishProgress(fmt.Sprintf("%d agents reviewing %s...", len(reviewers), target), 20)
+		if err := c.reviewWithConsensus(ctx, "", reviewers, minAgreement, prompt, review); err != nil {
+			return nil, err
+		}
+		c.publishProgress("Review complete", 100)
+
+		return review, nil
+	}
+
+	reviewAgent, err := c.GetAgentForStep(ctx, workflow.StepReviewing)
+	if err != nil {
+		return nil, fmt.Errorf("get review agent: %w", err)
+	}
+	review.Agent = reviewAgent.Name()
+
+	c.publishProgress("Agent reviewing "+target+"...", 20)
+	response, err := reviewAgent.RunWithCallback(ctx, prompt, func(event agent.Event) error {
+		c.eventBus.PublishRaw(events.Event{
+			Type: events.TypeAgentMessage,
+			Data: map[string]any{"event": event},
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("agent review: %w", err)
+	}
+
+	text := strings.Join(append([]string{response.Summary}, response.Messages...), "\n")
+	review.Summary, review.Findings = parseReviewFindings(text)
+	c.publishProgress("Review complete", 100)
+
+	return review, nil
+}
+
+// resolveReviewRange returns a description of what is reviewed and the git
+// range to diff, fetching the pull request when one is requested.
+func (c *Conductor) resolveReviewRange(ctx context.Context, opts DiffReviewOptions) (string, string, error) {
+	if opts.Range != "" {
+		if strings.HasPrefix(opts.Range, "-") || !strings.Contains(opts.Range, "..") {
+			return "", "", fmt.Errorf("invalid commit range %q: use <from>..<to>", opts.Range)
+		}
+
+		return opts.Range, opts.Range, nil
+	}
+
+	remote := opts.Remote
+	if remote == "" {
+		remote = "origin"
+	}
+
+	c.publishProgress(fmt.Sprintf("Fetching pull request #%d from %s...", opts.PR, remote), 0)
+	head, err := c.fetchPullRequest(ctx, remote, opts.PR)
+	if err != nil {
+		return "", "", err
+	}
+
+	base := opts.Base
+	if base == "" {
+		if base, err = c.git.GetBaseBranch(ctx); err != nil {
+			return "", "", fmt.Errorf("detect base branch: %w", err)
+		}
+	}
+	if c.git.RemoteBranchExists(ctx, remote, base) {
+		base = remote + "/" + base
+	}
+
+	// Three dots: only the pull request's own changes since it branched off.
+	return fmt.Sprintf("PR #%d", opts.PR), base + "..." + head, nil
+}
+
+// fetchPullRequest fetches a pull request's head from remote and returns its
+// commit. GitHub publishes pull requests under pull/<n>/head,
+// GitLab under merge-requests/<n>/head.
+func (c *Conductor) fetchPullRequest(ctx context.Context, remote string, number int) (string, error) {
+	var lastErr error
+	for _, ref := range []string{
+		fmt.Sprintf("pull/%d/head", number),
+		fmt.Sprintf("merge-requests/%d/head", number),
+	} {
+		if err := c.git.Fetch(ctx, remote, ref); err != nil {
+			lastErr = err
+
+			continue
+		}
+
+		return c.git.RevParse(ctx, "FETCH_HEAD")
+	}
+
+	return "", fmt.Errorf("fetch pull request #%d from %s: %w", number, remote, lastErr)
+}
+
+// findingPattern matches finding lines such as
+// "- [major] internal/cache/cache.go:42 - Missing lock around map write".
+var findingPattern = regexp.MustCompile(`^\s*[-*]?\s*\[(?i:(critical|major|minor))\]\s+([^\s:]+)(?::(\d+))?\s*(?:[-–—:]\s*)?(.+)$`)
+
+// parseReviewFindings splits an agent's review into its summary and findings.
+// The summary is the text under "## Summary", or everything that is not a
+// finding when the agent did not use the headings.
+func parseReviewFindings(text string) (string, []ReviewFinding) {
+	var findings []ReviewFinding
+	var summary, rest []string
+	section := ""
+	for line := range strings.SplitSeq(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if heading, ok := strings.CutPrefix(trimmed, "## "); ok {
+			section = strings.ToLower(strings.TrimSpace(heading))
+
+			continue
+		}
+
+		if m := findingPattern.FindStringSubmatch(trimmed); m != nil {
+			lineNo, _ := strconv.Atoi(m[3])
+			findings = append(findings, ReviewFinding{
+				Severity: strings.ToLower(m[1]),
+				File:     strings.Trim(m[2], "`"),
+				Line:     lineNo,
+				Message:  strings.TrimSpace(m[4]),
+			})
+
+			continue
+		}
+
+		switch section {
+		case "summary":
+			summary = append(summary, line)
+		case "findings":
+			// Lines such as "- none" carry no finding.
+		default:
+			rest = append(rest, line)
+		}
+	}
+
+	if len(summary) == 0 {
+		summary = rest
+	}
+
+	return strings.TrimSpace(strings.Join(summary, "\n")), findings
+}
@@ -0,0 +1,149 @@
+package conductor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+)
+
+func TestParseReviewFindings(t *testing.T) {
+	text := `## Summary
+Solid change, one race.
+
+## Findings
+- [Critical] internal/cache/cache.go:42 - Map written without holding the lock
+- [minor] ` + "`README.md`" + ` - Typo in the heading
+- none`
+
+	summary, findings := parseReviewFindings(text)
+	if summary != "Solid change, one race." {
+		t.Errorf("summary = %q", summary)
+	}
+
+	want := []ReviewFinding{
+		{Severity: SeverityCritical, File: "internal/cache/cache.go", Line: 42, Message: "Map written without holding the lock"},
+		{Severity: SeverityMinor, File: "README.md", Message: "Typo in the heading"},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("findings = %+v, want %+v", findings, want)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(findings[i], want[i]) {
+			t.Errorf("findings[%d] = %+v, want %+v", i, findings[i], want[i])
+		}
+	}
+
+	summary, findings = parseReviewFindings("Looks good to me.")
+	if summary != "Looks good to me." || len(findings) != 0 {
+		t.Errorf("unstructured review = %q, %v", summary, findings)
+	}
+}
+
+func TestDiffReviewReport(t *testing.T) {
+	review := &DiffReview{
+		Target:  "PR #7",
+		Agent:   "claude",
+		Summary: "One race.",
+		Findings: []ReviewFinding{
+			{Severity: SeverityMinor, File: "README.md", Message: "Typo"},
+			{Severity: SeverityCritical, File: "cache.go", Line: 42, Message: "Unlocked write"},
+		},
+	}
+
+	report := review.Report()
+	for _, want := range []string{"Target: PR #7", "Status: ISSUES", "Findings: 1 critical, 0 major, 1 minor", "## Critical\n\n- `cache.go:42` Unlocked write", "## Minor\n\n- `README.md` Typo"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+	if strings.Index(report, "## Critical") > strings.Index(report, "## Minor") {
+		t.Error("findings should be ordered by severity")
+	}
+}
+
+// reviewingAgent records the review prompt and answers with a fixed review.
+type reviewingAgent struct {
+	mockAgent
+
+	prompt string
+}
+
+func (a *reviewingAgent) RunWithCallback(ctx context.Context, prompt string, cb agent.StreamCallback) (*agent.Response, error) {
+	a.prompt = prompt
+
+	return &agent.Response{Summary: "## Summary\nFine.\n\n## Findings\n- [major] lib.go:1 - Exported without a doc comment"}, nil
+}
+
+func TestReviewDiff(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	origin := t.TempDir()
+	initGitRepo(t, origin)
+
+	// Publish a pull request the way GitHub does: a commit under refs/pull.
+	if err := runGitCmd(ctx, origin, "checkout", "-q", "-b", "feature"); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(origin, "lib.go"), []byte("package lib\n\nfunc Exported() {}\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	for _, args := range [][]string{
+		{"add", "."},
+		{"commit", "-q", "-m", "Add lib"},
+		{"update-ref", "refs/pull/7/head", "HEAD"},
+		{"checkout", "-q", "-"},
+	} {
+		if err := runGitCmd(ctx, origin, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+
+	dir := filepath.Join(t.TempDir(), "clone")
+	if err := runGitCmd(ctx, filepath.Dir(dir), "clone", "-q", origin, dir); err != nil {
+		t.Fatalf("clone: %v", err)
+	}
+
+	c, err := New(WithWorkDir(dir), WithAutoInit(true), WithAgent("reviewer"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	reviewer := &reviewingAgent{mockAgent: mockAgent{name: "reviewer"}}
+	if err := c.agents.Register(reviewer); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := c.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	review, err := c.ReviewDiff(ctx, DiffReviewOptions{PR: 7})
+	if err != nil {
+		t.Fatalf("ReviewDiff: %v", err)
+	}
+	if !strings.Contains(reviewer.prompt, "+func Exported() {}") || !strings.Contains(reviewer.prompt, "Add lib") {
+		t.Errorf("prompt does not contain the pull request's diff and commits:\n%s", reviewer.prompt)
+	}
+	if review.Target != "PR #7" || review.Agent != "reviewer" || review.Summary != "Fine." {
+		t.Errorf("review = %+v", review)
+	}
+	if len(review.Findings) != 1 || review.Findings[0].File != "lib.go" {
+		t.Errorf("findings = %+v", review.Findings)
+	}
+
+	if _, err := c.ReviewDiff(ctx, DiffReviewOptions{PR: 8}); err == nil {
+		t.Error("reviewing a missing pull request should fail")
+	}
+	if _, err := c.ReviewDiff(ctx, DiffReviewOptions{Range: "HEAD..HEAD"}); err == nil {
+		t.Error("reviewing an empty range should fail")
+	}
+	if _, err := c.ReviewDiff(ctx, DiffReviewOptions{Range: "--output=x"}); err == nil {
+		t.Error("option-like ranges should be rejected")
+	}
+}
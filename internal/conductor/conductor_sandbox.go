@@ -0,0 +1,59 @@
+package conductor
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+)
+
+// sandboxAgent confines a's CLI to the repository when agent.sandbox is
+// set: it may change files only in the worktree, sees only its own and the
+// allowed environment variables, and reaches only its API hosts through an
+// egress proxy, which the running mehr binary forwards to from inside the
+// sandbox. stop shuts the proxy down once the agent is done.
+func (c *Conductor) sandboxAgent(a agent.Agent) (sandboxed agent.Agent, stop func(), err error) {
+	noop := func() {}
+	if c.workspace == nil {
+		return a, noop, nil
+	}
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil || cfg.Agent.Sandbox == "" || cfg.Agent.Sandbox == agent.SandboxNone {
+		return a, noop, nil
+	}
+	settings := cfg.Agent
+
+	needs, ok := agent.NeedsOf(a)
+	if !ok {
+		return a, noop, fmt.Errorf("%s sandbox: %s: %w", settings.Sandbox, a.Name(), agent.ErrNotSandboxable)
+	}
+
+	helper, err := os.Executable()
+	if err != nil {
+		return a, noop, fmt.Errorf("%s sandbox: %w", settings.Sandbox, err)
+	}
+	proxy, err := agent.StartEgressProxy(slices.Concat(needs.Hosts, settings.SandboxHosts))
+	if err != nil {
+		return a, noop, err
+	}
+	sandboxed, err = agent.Sandboxed(a, &agent.Sandbox{
+		Mode:        settings.Sandbox,
+		Root:        c.repoRoot(),
+		Env:         slices.Concat(needs.Env, settings.SandboxEnv),
+		Dirs:        needs.Dirs,
+		Paths:       settings.SandboxPaths,
+		Image:       settings.SandboxImage,
+		ProxySocket: proxy.Socket(),
+		Helper:      helper,
+	})
+	if err != nil {
+		_ = proxy.Close()
+
+		return a, noop, err
+	}
+	slog.Debug("agent sandboxed", "agent", a.Name(), "sandbox", settings.Sandbox, "root", c.repoRoot())
+
+	return sandboxed, func() { _ = proxy.Close() }, nil
+}
@@ -0,0 +1,40 @@
+package conductor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestSandboxAgent(t *testing.T) {
+	root := t.TempDir()
+	c, err := New(WithWorkDir(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws, err := storage.OpenWorkspace(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.workspace = ws
+
+	base := &mockAgent{name: "mock"}
+	got, stop, err := c.sandboxAgent(base)
+	if err != nil || got != base {
+		t.Fatalf("sandboxAgent(no sandbox) = %v, %v, want the agent unchanged", got, err)
+	}
+	stop()
+
+	cfg := storage.NewDefaultWorkspaceConfig()
+	cfg.Agent.Sandbox = agent.SandboxBwrap
+	if err := ws.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, stop, err := c.sandboxAgent(base); !errors.Is(err, agent.ErrNotSandboxable) {
+		t.Errorf("sandboxAgent(non-CLI agent) = %v, want ErrNotSandboxable", err)
+	} else {
+		stop()
+	}
+}
@@ -0,0 +1,128 @@
+package conductor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+)
+
+// ErrOutsideScope is returned when an agent changes files outside the task scope.
+var ErrOutsideScope = errors.New("file changes outside task scope")
+
+// normalizeScope validates a scope against the repository root and returns it
+// as a clean, slash-separated path relative to the root. Relative scopes are
+// resolved against workDir. An empty result means the whole repository.
+func normalizeScope(root, workDir, scope string) (string, error) {
+	scope = strings.TrimSpace(scope)
+	if scope == "" {
+		return "", nil
+	}
+
+	abs := scope
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(workDir, scope)
+	}
+	abs, err := filepath.Abs(abs)
+	if err != nil {
+		return "", fmt.Errorf("invalid scope %q: %w", scope, err)
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("resolve repository root: %w", err)
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return "", fmt.Errorf("invalid scope %q: %w", scope, err)
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid scope %q: outside repository", scope)
+	}
+	if rel == "." {
+		return "", nil
+	}
+
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", fmt.Errorf("invalid scope %q: %w", scope, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("invalid scope %q: not a directory", scope)
+	}
+
+	return filepath.ToSlash(rel), nil
+}
+
+// inScope reports whether a repo-relative file path lies within scope.
+func inScope(scope, file string) bool {
+	if scope == "" {
+		return true
+	}
+	file = path.Clean(filepath.ToSlash(file))
+
+	return file == scope || strings.HasPrefix(file, scope+"/")
+}
+
+// repoRoot returns the root directory that agent file paths are relative to.
+func (c *Conductor) repoRoot() string {
+	return c.GetVCS().Root()
+}
+
+// taskScope returns the scope of the active task ("" for the whole repository).
+func (c *Conductor) taskScope() string {
+	if c.taskWork == nil {
+		return ""
+	}
+
+	return c.taskWork.Metadata.Scope
+}
+
+// scopeDir returns the directory verification commands run in: the scope
+// directory for scoped tasks, otherwise root.
+func (c *Conductor) scopeDir(root string) string {
+	if scope := c.taskScope(); scope != "" {
+		return filepath.Join(root, filepath.FromSlash(scope))
+	}
+
+	return root
+}
+
+// checkScope rejects file changes outside the task scope unless allowed by the
+// task or the --allow-outside-scope flag. The scope only restricts the primary
+// repository; changes in attached repositories are always allowed.
+func (c *Conductor) checkScope(files []agent.FileChange) error {
+	scope := c.taskScope()
+	if scope == "" || c.opts.AllowOutsideScope || c.taskWork.Metadata.AllowOutsideScope {
+		return nil
+	}
+
+	var outside []string
+	for _, fc := range files {
+		if !inScope(scope, fc.Path) && !c.inAttachedRepo(fc.Path) {
+			outside = append(outside, fc.Path)
+		}
+	}
+	if len(outside) > 0 {
+		return fmt.Errorf("%w %q: %s (use --allow-outside-scope to permit)", ErrOutsideScope, scope, strings.Join(outside, ", "))
+	}
+
+	return nil
+}
+
+// scopePrompt returns prompt instructions restricting the agent to the task scope.
+func scopePrompt(scope string) string {
+	if scope == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(`
+## Scope
+This task is scoped to the %q subdirectory of a larger repository.
+Only read files under %s/ for context and only create, modify, or delete files under %s/.
+File paths in your output must remain relative to the repository root (e.g., %s/...).
+`, scope, scope, scope, scope)
+}
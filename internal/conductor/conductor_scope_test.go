@@ -0,0 +1,140 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestNormalizeScope(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "services", "api"), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "README.md"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		workDir string
+		scope   string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty", workDir: root, scope: "", want: ""},
+		{name: "root", workDir: root, scope: ".", want: ""},
+		{name: "relative", workDir: root, scope: "services/api/", want: "services/api"},
+		{name: "relative to subdir", workDir: filepath.Join(root, "services"), scope: "api", want: "services/api"},
+		{name: "absolute", workDir: root, scope: filepath.Join(root, "services"), want: "services"},
+		{name: "outside repository", workDir: root, scope: "..", wantErr: true},
+		{name: "missing", workDir: root, scope: "nope", wantErr: true},
+		{name: "file", workDir: root, scope: "README.md", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeScope(root, tt.workDir, tt.scope)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeScope() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeScope() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInScope(t *testing.T) {
+	tests := []struct {
+		scope string
+		file  string
+		want  bool
+	}{
+		{scope: "", file: "anything.go", want: true},
+		{scope: "services/api", file: "services/api/main.go", want: true},
+		{scope: "services/api", file: "./services/api/main.go", want: true},
+		{scope: "services/api", file: "services/api", want: true},
+		{scope: "services/api", file: "services/api-v2/main.go", want: false},
+		{scope: "services/api", file: "services/api/../web/main.go", want: false},
+		{scope: "services/api", file: "go.mod", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := inScope(tt.scope, tt.file); got != tt.want {
+			t.Errorf("inScope(%q, %q) = %v, want %v", tt.scope, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestApplyFiles_OutsideScope(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx := context.Background()
+
+	files := []agent.FileChange{
+		{Path: "services/api/main.go", Operation: agent.FileOpCreate, Content: "package main"},
+		{Path: "go.mod", Operation: agent.FileOpCreate, Content: "module x"},
+	}
+
+	tests := []struct {
+		name       string
+		taskAllows bool
+		optAllows  bool
+		wantErr    bool
+	}{
+		{name: "rejected", wantErr: true},
+		{name: "allowed by task", taskAllows: true},
+		{name: "allowed by flag", optAllows: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(tmpDir, strings.ReplaceAll(tt.name, " ", "-"))
+
+			c, err := New(WithWorkDir(dir), WithAllowOutsideScope(tt.optAllows))
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			c.eventBus = events.NewBus()
+			c.taskWork = &storage.TaskWork{Metadata: storage.WorkMetadata{
+				Scope:             "services/api",
+				AllowOutsideScope: tt.taskAllows,
+			}}
+
+			err = applyFiles(ctx, c, files)
+			if tt.wantErr {
+				if !errors.Is(err, ErrOutsideScope) {
+					t.Fatalf("applyFiles error = %v, want ErrOutsideScope", err)
+				}
+				// Nothing is written when the batch is rejected
+				if _, err := os.Stat(filepath.Join(dir, "services/api/main.go")); !os.IsNotExist(err) {
+					t.Error("in-scope file should not be written when batch is rejected")
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyFiles: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+				t.Errorf("go.mod should be written: %v", err)
+			}
+		})
+	}
+}
+
+func TestScopePrompt(t *testing.T) {
+	if scopePrompt("") != "" {
+		t.Error("scopePrompt should be empty without scope")
+	}
+	if got := scopePrompt("services/api"); !strings.Contains(got, "services/api/") {
+		t.Errorf("scopePrompt = %q, want scope instructions", got)
+	}
+}
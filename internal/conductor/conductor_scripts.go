@@ -0,0 +1,50 @@
+package conductor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/valksor/go-mehrhof/internal/script"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// loadScripts loads the workspace's workflow scripts and adds their guards
+// and hooks to builder, reporting whether any were added. A broken script
+// disables every script rather than running the rest half-configured.
+func (c *Conductor) loadScripts(builder *workflow.MachineBuilder) bool {
+	scripts, err := script.Load(c.workspace.ScriptsDir(), func(msg string) {
+		c.logVerbosef("script: %s", msg)
+	})
+	if err != nil {
+		c.logError(fmt.Errorf("load workflow scripts (non-fatal): %w", err))
+
+		return false
+	}
+	c.scripts = scripts
+
+	if !scripts.HasTransitionHooks() {
+		return false
+	}
+	if err := scripts.RegisterTransitionHooks(builder); err != nil {
+		c.logError(fmt.Errorf("workflow scripts: %w", err))
+	}
+
+	return true
+}
+
+// scriptPrompt passes an agent prompt for step through the workflow scripts.
+// A failing script is logged and the prompt is used as it was.
+func (c *Conductor) scriptPrompt(ctx context.Context, step workflow.Step, prompt string) string {
+	if c.scripts == nil || c.scripts.Empty() {
+		return prompt
+	}
+
+	mutated, err := c.scripts.MutatePrompt(ctx, step, prompt, c.buildWorkUnit())
+	if err != nil {
+		c.logError(fmt.Errorf("workflow scripts, %s prompt: %w", step, err))
+
+		return prompt
+	}
+
+	return mutated
+}
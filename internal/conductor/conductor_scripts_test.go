@@ -0,0 +1,114 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/provider/file"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// promptAgent records the prompts it runs.
+type promptAgent struct {
+	mockAgent
+
+	prompts []string
+}
+
+func (a *promptAgent) RunWithCallback(ctx context.Context, prompt string, cb agent.StreamCallback) (*agent.Response, error) {
+	a.prompts = append(a.prompts, prompt)
+
+	return &agent.Response{Summary: "## Plan\nRetry uploads."}, nil
+}
+
+func TestWorkflowScripts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	taskPath := filepath.Join(tmpDir, "task.md")
+	if err := os.WriteFile(taskPath, []byte("# Add retries\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	scriptsDir := filepath.Join(tmpDir, ".mehrhof", "scripts")
+	if err := os.MkdirAll(scriptsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	policy := `
+def rollback(prompt, task):
+    return prompt + "\n\nEvery plan for " + task.title + " needs a rollback section."
+
+def split(task):
+    if len(task.specifications) < 2:
+        return "split the work into at least two specifications"
+
+prompt("planning", rollback)
+guard("split", "idle", "implement", split)
+`
+	if err := os.WriteFile(filepath.Join(scriptsDir, "policy.star"), []byte(policy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	planner := &promptAgent{mockAgent: mockAgent{name: "mock"}}
+	c, err := New(WithWorkDir(tmpDir), WithCreateBranch(false), WithAgent("mock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Register(c.GetProviderRegistry())
+	if err := c.GetAgentRegistry().Register(planner); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if err := c.Start(ctx, "file:"+taskPath); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if err := c.Plan(ctx); err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if err := c.RunPlanning(ctx); err != nil {
+		t.Fatalf("RunPlanning: %v", err)
+	}
+	if len(planner.prompts) == 0 || !strings.HasSuffix(planner.prompts[0], "Every plan for Add retries needs a rollback section.") {
+		t.Errorf("planning prompt was not passed through the script")
+	}
+
+	err = c.Implement(ctx)
+	if !errors.Is(err, workflow.ErrTransitionVetoed) || !strings.Contains(err.Error(), "policy/split") {
+		t.Errorf("Implement() error = %v, want the script's veto", err)
+	}
+}
+
+func TestWorkflowScripts_Broken(t *testing.T) {
+	tmpDir := t.TempDir()
+	scriptsDir := filepath.Join(tmpDir, ".mehrhof", "scripts")
+	if err := os.MkdirAll(scriptsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(scriptsDir, "broken.star"), []byte("guard(\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := New(WithWorkDir(tmpDir), WithAgent("mock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.GetAgentRegistry().Register(&mockAgent{name: "mock"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error = %v, want broken scripts to be non-fatal", err)
+	}
+	if got := c.scriptPrompt(context.Background(), workflow.StepPlanning, "Plan it."); got != "Plan it." {
+		t.Errorf("scriptPrompt() = %q, want the prompt unchanged", got)
+	}
+}
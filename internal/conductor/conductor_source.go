@@ -0,0 +1,164 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// RefreshSource re-fetches the active task's source from its provider and
+// stores it as a new source revision. Providers that support incremental
+// snapshots skip unchanged files entirely; for others the full snapshot is
+// compared by content hash. When the source no longer matches the snapshot
+// taken at start, the drift is recorded and later planning and
+// implementation prompts warn the agent that the requirements changed.
+// Returns the changes applied by this refresh.
+func (c *Conductor) RefreshSource(ctx context.Context) (*storage.SourceDelta, error) {
+	if c.activeTask == nil || c.taskWork == nil {
+		return nil, errors.New("no active task")
+	}
+
+	p, id, err := c.providers.Resolve(ctx, c.activeTask.Ref, c.providerConfig(c.referenceProvider(c.activeTask.Ref)), provider.ResolveOptions{
+		DefaultProvider: c.defaultProvider(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("resolve provider: %w", err)
+	}
+
+	taskID := c.activeTask.ID
+	var changes *provider.SnapshotDelta
+	err = c.traceProvider(ctx, c.referenceProvider(c.activeTask.Ref), "snapshot", func(ctx context.Context) error {
+		switch s := p.(type) {
+		case provider.IncrementalSnapshotter:
+			changes, err = s.SnapshotChanges(ctx, id, toFileStates(c.taskWork.Source.States))
+		case provider.Snapshotter:
+			var snapshot *provider.Snapshot
+			snapshot, err = s.Snapshot(ctx, id)
+			if err == nil {
+				changes = diffSnapshot(c.taskWork.Source.States, snapshot)
+			}
+		default:
+			err = fmt.Errorf("provider for %s does not support source snapshots", c.activeTask.Ref)
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("snapshot source: %w", err)
+	}
+
+	update := storage.SourceUpdate{
+		Removed: changes.Removed,
+		States:  make(map[string]storage.SourceFileState, len(changes.States)),
+	}
+	for _, f := range c.cacheSourceImages(ctx, p, taskID, changes.Changed) {
+		update.Changed = append(update.Changed, storage.SourceFile{Path: f.Path, Content: f.Content})
+	}
+	for path, state := range changes.States {
+		update.States[path] = storage.SourceFileState(state)
+	}
+
+	delta, err := c.workspace.RefreshSource(taskID, update)
+	if err != nil {
+		return nil, fmt.Errorf("refresh source: %w", err)
+	}
+	work, err := c.workspace.LoadWork(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("load work: %w", err)
+	}
+	c.taskWork = work
+
+	return delta, nil
+}
+
+// sourceDriftPrompt warns the agent that the task's requirements changed
+// since it started, with the diff of the source. Empty when the source has
+// not drifted.
+func (c *Conductor) sourceDriftPrompt() string {
+	if c.taskWork == nil || c.taskWork.Source.Drift == nil {
+		return ""
+	}
+	diff, err := c.workspace.SourceDriftDiff(c.taskWork.Metadata.ID)
+	if err != nil {
+		c.logError(err)
+	}
+
+	return buildSourceDriftPrompt(c.taskWork.Source.Drift, diff)
+}
+
+// maxDriftPromptBytes caps the source diff included in prompts.
+const maxDriftPromptBytes = 12_000
+
+// buildSourceDriftPrompt formats a source drift warning for a prompt.
+func buildSourceDriftPrompt(drift *storage.SourceDrift, diff string) string {
+	var sb strings.Builder
+	sb.WriteString("\n## Requirements Changed\n")
+	fmt.Fprintf(&sb, "The task source was updated after the task started (now at revision %d; changed: %s). ",
+		drift.Revision, strings.Join(drift.Files, ", "))
+	sb.WriteString("The source above is the current version. Existing specifications and code may implement the old requirements: check them against the changes below and follow the current source where they disagree.\n")
+	if diff == "" {
+		return sb.String()
+	}
+
+	truncated := false
+	if len(diff) > maxDriftPromptBytes {
+		diff = diff[:maxDriftPromptBytes]
+		if i := strings.LastIndexByte(diff, '\n'); i > 0 {
+			diff = diff[:i+1]
+		}
+		truncated = true
+	}
+	sb.WriteString("\nChanges since the task started:\n\n```diff\n" + diff)
+	if !strings.HasSuffix(diff, "\n") {
+		sb.WriteString("\n")
+	}
+	sb.WriteString("```\n")
+	if truncated {
+		sb.WriteString("(diff truncated)\n")
+	}
+
+	return sb.String()
+}
+
+// toFileStates converts recorded source states for an incremental snapshot.
+func toFileStates(states map[string]storage.SourceFileState) map[string]provider.FileState {
+	out := make(map[string]provider.FileState, len(states))
+	for path, state := range states {
+		out[path] = provider.FileState(state)
+	}
+
+	return out
+}
+
+// diffSnapshot compares a full snapshot with the recorded source states by
+// content hash.
+func diffSnapshot(previous map[string]storage.SourceFileState, snapshot *provider.Snapshot) *provider.SnapshotDelta {
+	delta := &provider.SnapshotDelta{
+		Ref:    snapshot.Ref,
+		States: make(map[string]provider.FileState),
+	}
+
+	for _, f := range snapshotFiles(snapshot) {
+		state := sourceFileState(f)
+		delta.States[f.Path] = provider.FileState(state)
+		if prev, ok := previous[f.Path]; ok && prev.Hash == state.Hash {
+			continue
+		}
+		f.Hash = state.Hash
+		delta.Changed = append(delta.Changed, f)
+	}
+
+	for path := range previous {
+		if _, ok := delta.States[path]; !ok {
+			delta.Removed = append(delta.Removed, path)
+		}
+	}
+	slices.Sort(delta.Removed)
+
+	return delta
+}
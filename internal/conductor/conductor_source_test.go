@@ -0,0 +1,149 @@
+package conductor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/provider/directory"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestRefreshSource_Directory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	taskDir := filepath.Join(tmpDir, "feature")
+	if err := os.MkdirAll(taskDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(taskDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	write("README.md", "# Feature")
+	write("api.md", "# API")
+	write("old.md", "# Old")
+
+	c, err := New(WithWorkDir(tmpDir), WithCreateBranch(false), WithAgent("mock"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	directory.Register(c.GetProviderRegistry())
+	if err := c.GetAgentRegistry().Register(&mockAgent{name: "mock"}); err != nil {
+		t.Fatalf("Register mock agent: %v", err)
+	}
+	if err := c.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if err := c.Start(ctx, "dir:"+taskDir); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	// First sync records the baseline
+	if _, err := c.RefreshSource(ctx); err != nil {
+		t.Fatalf("RefreshSource: %v", err)
+	}
+
+	unchanged, err := c.RefreshSource(ctx)
+	if err != nil {
+		t.Fatalf("RefreshSource: %v", err)
+	}
+	if !unchanged.Empty() {
+		t.Errorf("sync without changes = %+v, want empty delta", unchanged)
+	}
+
+	write("api.md", "# API v2")
+	write("new.md", "# New")
+	if err := os.Remove(filepath.Join(taskDir, "old.md")); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+
+	delta, err := c.RefreshSource(ctx)
+	if err != nil {
+		t.Fatalf("RefreshSource: %v", err)
+	}
+	if !slices.Equal(delta.Added, []string{"new.md"}) ||
+		!slices.Equal(delta.Modified, []string{"api.md"}) ||
+		!slices.Equal(delta.Removed, []string{"old.md"}) {
+		t.Errorf("delta = %+v", delta)
+	}
+
+	taskID := c.GetActiveTask().ID
+	sourceDir := filepath.Join(c.GetWorkspace().WorkPath(taskID), "source")
+	if data, _ := os.ReadFile(filepath.Join(sourceDir, "api.md")); string(data) != "# API v2" {
+		t.Errorf("source/api.md = %q, want updated content", data)
+	}
+	if _, err := os.Stat(filepath.Join(sourceDir, "old.md")); !os.IsNotExist(err) {
+		t.Error("source/old.md should be removed")
+	}
+
+	work, err := c.GetWorkspace().LoadWork(taskID)
+	if err != nil {
+		t.Fatalf("LoadWork: %v", err)
+	}
+	if work.Source.Delta == nil || !slices.Equal(work.Source.Delta.Added, []string{"new.md"}) {
+		t.Errorf("recorded delta = %+v", work.Source.Delta)
+	}
+	if !slices.Equal(work.Source.Files, []string{"source/README.md", "source/api.md", "source/new.md"}) {
+		t.Errorf("source files = %v", work.Source.Files)
+	}
+}
+
+func TestDiffSnapshot(t *testing.T) {
+	previous := map[string]storage.SourceFileState{
+		"keep.md": {Hash: provider.HashContent("keep")},
+		"edit.md": {Hash: provider.HashContent("before")},
+		"gone.md": {Hash: provider.HashContent("gone")},
+	}
+	snapshot := &provider.Snapshot{
+		Ref: "dir",
+		Files: []provider.SnapshotFile{
+			{Path: "keep.md", Content: "keep"},
+			{Path: "edit.md", Content: "after"},
+			{Path: "new.md", Content: "new"},
+		},
+	}
+
+	delta := diffSnapshot(previous, snapshot)
+
+	var changed []string
+	for _, f := range delta.Changed {
+		changed = append(changed, f.Path)
+	}
+	if !slices.Equal(changed, []string{"edit.md", "new.md"}) {
+		t.Errorf("Changed = %v, want [edit.md new.md]", changed)
+	}
+	if !slices.Equal(delta.Removed, []string{"gone.md"}) {
+		t.Errorf("Removed = %v, want [gone.md]", delta.Removed)
+	}
+	if len(delta.States) != 3 {
+		t.Errorf("States = %d, want 3", len(delta.States))
+	}
+}
+
+func TestBuildSourceDriftPrompt(t *testing.T) {
+	drift := &storage.SourceDrift{Revision: 2, Files: []string{"spec.md"}}
+
+	got := buildSourceDriftPrompt(drift, "--- a/spec.md\n+++ b/spec.md\n@@ -1,1 +1,1 @@\n-REST\n+gRPC\n")
+	for _, want := range []string{"## Requirements Changed", "revision 2; changed: spec.md", "```diff\n--- a/spec.md", "+gRPC\n```\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("prompt missing %q:\n%s", want, got)
+		}
+	}
+
+	long := strings.Repeat("+line\n", maxDriftPromptBytes)
+	got = buildSourceDriftPrompt(drift, long)
+	if !strings.Contains(got, "(diff truncated)") || len(got) > maxDriftPromptBytes+1000 {
+		t.Errorf("long diff not truncated (%d bytes)", len(got))
+	}
+}
@@ -0,0 +1,213 @@
+package conductor
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// minCritiqueScore is the lowest rubric score a specification passes with
+// when the critic gives no verdict.
+const minCritiqueScore = 4
+
+// critiqueScorePattern matches rubric score lines such as "Edge cases: 3/5".
+var critiqueScorePattern = regexp.MustCompile(`(?i)^[-*\s]*(completeness|testability|edge[ _-]?cases)\s*:\s*([1-5])`)
+
+// critiqueVerdictPattern matches the verdict line, "Verdict: PASS" or a bare
+// "REVISE" under the verdict heading.
+var critiqueVerdictPattern = regexp.MustCompile(`(?i)^(?:verdict\s*:\s*)?\**(pass|revise)\**\.?$`)
+
+// specCritiqueRounds returns the number of critique rounds planned
+// specifications get, or 0 when critique is off.
+func (c *Conductor) specCritiqueRounds() int {
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil {
+		cfg = storage.NewDefaultWorkspaceConfig()
+	}
+
+	enabled := cfg.Workflow.SpecCritique
+	if c.opts.SpecCritique != nil {
+		enabled = *c.opts.SpecCritique
+	}
+	if !enabled {
+		return 0
+	}
+	if cfg.Workflow.SpecCritiqueRounds > 0 {
+		return cfg.Workflow.SpecCritiqueRounds
+	}
+
+	return storage.DefaultSpecCritiqueRounds
+}
+
+// criticAgent returns agent.critic_agent from the workspace config, or the
+// reviewing agent when none is configured.
+func (c *Conductor) criticAgent(ctx context.Context) (agent.Agent, error) {
+	cfg, err := c.workspace.LoadConfig()
+	if err == nil && cfg.Agent.CriticAgent != "" {
+		critic, err := c.agents.Get(cfg.Agent.CriticAgent)
+		if err == nil {
+			return c.withTracing(withFailureInjection(critic), workflow.StepPlanning.String()), nil
+		}
+		c.logError(fmt.Errorf("agent.critic_agent: %w", err))
+	}
+
+	return c.GetAgentForStep(ctx, workflow.StepReviewing)
+}
+
+// critiqueSpecification has the critic agent assess a drafted specification
+// against the planning rubric and the planning agent revise it, for up to
+// rounds rounds or until the critic accepts it. It returns the final
+// specification content with the last critique. Agent failures end the loop
+// early; the critique is nil when the critic never answered.
+func (c *Conductor) critiqueSpecification(ctx context.Context, taskID string, number int, planningAgent agent.Agent, sourceContent, content string, rounds int) (string, *storage.SpecCritique) {
+	critic, err := c.criticAgent(ctx)
+	if err != nil {
+		c.logError(fmt.Errorf("get critic agent: %w", err))
+
+		return content, nil
+	}
+
+	title := c.taskWork.Metadata.Title
+	var critique *storage.SpecCritique
+	for round := 1; round <= rounds; round++ {
+		c.publishProgress(fmt.Sprintf("Critiquing specification-%d (round %d of %d)...", number, round, rounds), 75)
+		response, err := c.runCritiqueAgent(ctx, critic, buildSpecCritiquePrompt(title, sourceContent, content))
+		if err != nil {
+			c.logError(fmt.Errorf("critique specification-%d: %w", number, err))
+
+			break
+		}
+		c.recordUsage(taskID, "planning", workflow.StepPlanning, critic, response.Usage)
+
+		critique = parseSpecCritique(strings.Join(append([]string{response.Summary}, response.Messages...), "\n"))
+		critique.Critic = critic.Name()
+		critique.Rounds = round
+		if critique.Passed() || round == rounds {
+			break
+		}
+
+		c.publishProgress(fmt.Sprintf("Revising specification-%d...", number), 80)
+		response, err = c.runCritiqueAgent(ctx, planningAgent, buildSpecRevisionPrompt(title, sourceContent, content, critique))
+		if err != nil {
+			c.logError(fmt.Errorf("revise specification-%d: %w", number, err))
+
+			break
+		}
+		c.recordUsage(taskID, "planning", workflow.StepPlanning, planningAgent, response.Usage)
+		content = formatSpecificationContent(number, response)
+	}
+
+	return content, critique
+}
+
+// runCritiqueAgent runs one critique or revision turn, streaming its events.
+func (c *Conductor) runCritiqueAgent(ctx context.Context, a agent.Agent, prompt string) (*agent.Response, error) {
+	return a.RunWithCallback(ctx, prompt, func(event agent.Event) error {
+		c.eventBus.PublishRaw(events.Event{
+			Type: events.TypeAgentMessage,
+			Data: map[string]any{"event": event},
+		})
+
+		return nil
+	})
+}
+
+// parseSpecCritique reads the rubric scores, issues and verdict from a
+// critic's answer. Without a verdict, the specification passes when every
+// score is at least minCritiqueScore.
+func parseSpecCritique(text string) *storage.SpecCritique {
+	critique := &storage.SpecCritique{}
+	section := ""
+	for line := range strings.SplitSeq(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if heading, ok := strings.CutPrefix(trimmed, "## "); ok {
+			section = strings.ToLower(strings.TrimSpace(heading))
+
+			continue
+		}
+
+		if m := critiqueScorePattern.FindStringSubmatch(trimmed); m != nil {
+			score, _ := strconv.Atoi(m[2])
+			switch strings.ToLower(m[1][:1]) {
+			case "c":
+				critique.Completeness = score
+			case "t":
+				critique.Testability = score
+			default:
+				critique.EdgeCases = score
+			}
+
+			continue
+		}
+
+		if m := critiqueVerdictPattern.FindStringSubmatch(trimmed); m != nil {
+			critique.Verdict = strings.ToLower(m[1])
+
+			continue
+		}
+
+		if section == "issues" {
+			issue := strings.TrimSpace(strings.TrimLeft(trimmed, "-*"))
+			if issue != "" && !strings.EqualFold(issue, "none") {
+				critique.Issues = append(critique.Issues, issue)
+			}
+		}
+	}
+
+	if critique.Verdict == "" {
+		critique.Verdict = storage.CritiqueVerdictRevise
+		if min(critique.Completeness, critique.Testability, critique.EdgeCases) >= minCritiqueScore {
+			critique.Verdict = storage.CritiqueVerdictPass
+		}
+	}
+
+	return critique
+}
+
+// buildSpecCritiquePrompt asks a critic agent to assess a specification
+// against the planning rubric.
+func buildSpecCritiquePrompt(title, sourceContent, spec string) string {
+	var sb strings.Builder
+	sb.WriteString("You are a critic reviewing an implementation specification before it is implemented.\n\n")
+	fmt.Fprintf(&sb, "## Task\n%s\n\n", title)
+	fmt.Fprintf(&sb, "## Original Requirements\n%s\n\n", sourceContent)
+	fmt.Fprintf(&sb, "## Specification\n%s\n\n", spec)
+	sb.WriteString("## Rubric\n")
+	sb.WriteString("Score each criterion from 1 (poor) to 5 (excellent):\n")
+	sb.WriteString("- Completeness: every requirement is covered, with the files and changes needed.\n")
+	sb.WriteString("- Testability: the expected behavior can be verified, and the tests to add are named.\n")
+	sb.WriteString("- Edge cases: errors, empty and boundary inputs, concurrency and compatibility are handled.\n\n")
+	sb.WriteString("## Format\n")
+	sb.WriteString("Respond in exactly this format, without changing any files:\n\n")
+	sb.WriteString("## Scores\nCompleteness: <1-5>\nTestability: <1-5>\nEdge cases: <1-5>\n\n")
+	sb.WriteString("## Issues\n- One line per concrete gap the specification must address, or \"- none\"\n\n")
+	fmt.Fprintf(&sb, "## Verdict\nPASS if the specification can be implemented as written and every score is at least %d, otherwise REVISE.\n", minCritiqueScore)
+
+	return sb.String()
+}
+
+// buildSpecRevisionPrompt asks the planning agent to revise a specification
+// to address a critique.
+func buildSpecRevisionPrompt(title, sourceContent, spec string, critique *storage.SpecCritique) string {
+	var sb strings.Builder
+	sb.WriteString("Revise the implementation specification below to address the critic's review.\n\n")
+	fmt.Fprintf(&sb, "## Task\n%s\n\n", title)
+	fmt.Fprintf(&sb, "## Original Requirements\n%s\n\n", sourceContent)
+	fmt.Fprintf(&sb, "## Current Specification\n%s\n\n", spec)
+	sb.WriteString("## Critique\n")
+	fmt.Fprintf(&sb, "Completeness %d/5, testability %d/5, edge cases %d/5.\n", critique.Completeness, critique.Testability, critique.EdgeCases)
+	for _, issue := range critique.Issues {
+		sb.WriteString("- " + issue + "\n")
+	}
+	sb.WriteString("\n## Instructions\n")
+	sb.WriteString("Reply with the complete revised specification, keeping what is already right. Do not change any files.\n")
+
+	return sb.String()
+}
@@ -0,0 +1,203 @@
+package conductor
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// criticAgent answers critique prompts with its replies in turn.
+type criticAgent struct {
+	mockAgent
+
+	replies []string
+	prompts []string
+}
+
+func (a *criticAgent) RunWithCallback(ctx context.Context, prompt string, cb agent.StreamCallback) (*agent.Response, error) {
+	a.prompts = append(a.prompts, prompt)
+	reply := a.replies[min(len(a.prompts), len(a.replies))-1]
+
+	return &agent.Response{Summary: reply}, nil
+}
+
+// revisingAgent plans, and revises its plan when asked to.
+type revisingAgent struct {
+	mockAgent
+}
+
+func (a *revisingAgent) RunWithCallback(ctx context.Context, prompt string, cb agent.StreamCallback) (*agent.Response, error) {
+	if strings.Contains(prompt, "address the critic's review") {
+		return &agent.Response{Summary: "Revised plan, rejecting empty input"}, nil
+	}
+
+	return &agent.Response{Summary: "Initial plan"}, nil
+}
+
+func TestParseSpecCritique(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		wantVerdict string
+		wantScores  [3]int
+		wantIssues  []string
+	}{
+		{
+			name: "revise with issues",
+			text: `## Scores
+Completeness: 4
+Testability: 2/5
+- Edge cases: 3
+
+## Issues
+- No test for an empty file
+- Error from the parser is ignored
+
+## Verdict
+REVISE`,
+			wantVerdict: storage.CritiqueVerdictRevise,
+			wantScores:  [3]int{4, 2, 3},
+			wantIssues:  []string{"No test for an empty file", "Error from the parser is ignored"},
+		},
+		{
+			name:        "pass",
+			text:        "## Scores\nCompleteness: 5\nTestability: 4\nEdge_cases: 4\n\n## Issues\n- none\n\nVerdict: **PASS**",
+			wantVerdict: storage.CritiqueVerdictPass,
+			wantScores:  [3]int{5, 4, 4},
+		},
+		{
+			name:        "no verdict, high scores",
+			text:        "Completeness: 4\nTestability: 5\nEdge cases: 4",
+			wantVerdict: storage.CritiqueVerdictPass,
+			wantScores:  [3]int{4, 5, 4},
+		},
+		{
+			name:        "no verdict, low score",
+			text:        "Completeness: 4\nTestability: 5\nEdge cases: 2",
+			wantVerdict: storage.CritiqueVerdictRevise,
+			wantScores:  [3]int{4, 5, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseSpecCritique(tt.text)
+			if got.Verdict != tt.wantVerdict {
+				t.Errorf("Verdict = %q, want %q", got.Verdict, tt.wantVerdict)
+			}
+			if scores := [3]int{got.Completeness, got.Testability, got.EdgeCases}; scores != tt.wantScores {
+				t.Errorf("scores = %v, want %v", scores, tt.wantScores)
+			}
+			if !slices.Equal(got.Issues, tt.wantIssues) {
+				t.Errorf("Issues = %q, want %q", got.Issues, tt.wantIssues)
+			}
+		})
+	}
+}
+
+func TestRunPlanning_SpecCritique(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	c := newPlanningConductor(t, &revisingAgent{mockAgent{name: "mock"}}, WithSpecCritique(true))
+	ctx := context.Background()
+	taskID := c.GetActiveTask().ID
+	ws := c.GetWorkspace()
+
+	critic := &criticAgent{
+		mockAgent: mockAgent{name: "critic"},
+		replies: []string{
+			"## Scores\nCompleteness: 4\nTestability: 3\nEdge cases: 2\n\n## Issues\n- Empty input is not handled\n\n## Verdict\nREVISE",
+			"## Scores\nCompleteness: 4\nTestability: 4\nEdge cases: 4\n\n## Issues\n- none\n\n## Verdict\nPASS",
+		},
+	}
+	if err := c.GetAgentRegistry().Register(critic); err != nil {
+		t.Fatalf("Register critic: %v", err)
+	}
+	cfg, err := ws.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	cfg.Agent.CriticAgent = "critic"
+	if err := ws.SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	if err := c.Plan(ctx); err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if err := c.RunPlanning(ctx); err != nil {
+		t.Fatalf("RunPlanning: %v", err)
+	}
+
+	spec, err := ws.ParseSpecification(taskID, 1)
+	if err != nil {
+		t.Fatalf("ParseSpecification: %v", err)
+	}
+	if spec.Status != storage.SpecificationStatusReady {
+		t.Errorf("Status = %q, want ready after the critic passed it", spec.Status)
+	}
+	if !strings.Contains(spec.Content, "Revised plan") {
+		t.Errorf("content is not the revised specification:\n%s", spec.Content)
+	}
+	if spec.Critique == nil || spec.Critique.Rounds != 2 || spec.Critique.Critic != "critic" || !spec.Critique.Passed() {
+		t.Errorf("Critique = %+v", spec.Critique)
+	}
+	if len(critic.prompts) != 2 || !strings.Contains(critic.prompts[1], "Revised plan") {
+		t.Errorf("critic should have seen the revision in round 2, got %d prompts", len(critic.prompts))
+	}
+}
+
+func TestRunPlanning_SpecCritiqueRoundsRunOut(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	c := newPlanningConductor(t, &revisingAgent{mockAgent{name: "mock"}}, WithSpecCritique(true))
+	ctx := context.Background()
+	taskID := c.GetActiveTask().ID
+	ws := c.GetWorkspace()
+
+	critic := &criticAgent{
+		mockAgent: mockAgent{name: "critic"},
+		replies:   []string{"## Scores\nCompleteness: 2\nTestability: 2\nEdge cases: 2\n\n## Issues\n- Too vague\n\n## Verdict\nREVISE"},
+	}
+	if err := c.GetAgentRegistry().Register(critic); err != nil {
+		t.Fatalf("Register critic: %v", err)
+	}
+	cfg, err := ws.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	cfg.Agent.CriticAgent = "critic"
+	cfg.Workflow.SpecCritiqueRounds = 3
+	if err := ws.SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	if err := c.Plan(ctx); err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if err := c.RunPlanning(ctx); err != nil {
+		t.Fatalf("RunPlanning: %v", err)
+	}
+
+	spec, err := ws.ParseSpecification(taskID, 1)
+	if err != nil {
+		t.Fatalf("ParseSpecification: %v", err)
+	}
+	if spec.Status != storage.SpecificationStatusDraft {
+		t.Errorf("Status = %q, want draft when the critic never passed it", spec.Status)
+	}
+	if spec.Critique == nil || spec.Critique.Rounds != 3 || !slices.Equal(spec.Critique.Issues, []string{"Too vague"}) {
+		t.Errorf("Critique = %+v", spec.Critique)
+	}
+	if len(critic.prompts) != 3 {
+		t.Errorf("critic ran %d times, want 3", len(critic.prompts))
+	}
+}
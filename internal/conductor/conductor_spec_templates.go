@@ -0,0 +1,38 @@
+package conductor
+
+import (
+	"fmt"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// planningSpecTemplate returns the rendered spec template for a planning run,
+// or "" when the task has none. A template given via WithSpecTemplate is
+// remembered on the task so later planning iterations keep using it.
+func (c *Conductor) planningSpecTemplate(taskID string) (string, error) {
+	if c.taskWork == nil {
+		return "", nil
+	}
+
+	name := c.opts.SpecTemplate
+	if name == "" {
+		name = c.taskWork.Metadata.SpecTemplate
+	}
+	if name == "" {
+		return "", nil
+	}
+
+	content, err := c.workspace.LoadSpecTemplate(name)
+	if err != nil {
+		return "", fmt.Errorf("load spec template: %w", err)
+	}
+
+	if c.taskWork.Metadata.SpecTemplate != name {
+		c.taskWork.Metadata.SpecTemplate = name
+		if err := c.workspace.SaveWork(c.taskWork); err != nil {
+			c.logError(fmt.Errorf("save spec template for %s: %w", taskID, err))
+		}
+	}
+
+	return storage.RenderSpecTemplate(content, c.taskWork.TemplateVars()), nil
+}
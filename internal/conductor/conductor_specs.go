@@ -0,0 +1,57 @@
+package conductor
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// ErrSpecificationDone is returned when implementing a specification that is already done.
+var ErrSpecificationDone = errors.New("specification already implemented")
+
+// implementationSpec selects the specification to implement: the one chosen
+// with WithSpecification, or the latest one. resumed reports whether an earlier
+// run already started on the chosen specification.
+func (c *Conductor) implementationSpec(taskID string) (content string, number int, resumed bool, err error) {
+	if c.opts.Specification == 0 {
+		content, number, err = c.workspace.GetLatestSpecificationContent(taskID)
+		if err != nil {
+			return "", 0, false, fmt.Errorf("get latest specification: %w", err)
+		}
+
+		return content, number, false, nil
+	}
+
+	number = c.opts.Specification
+	spec, err := c.workspace.ParseSpecification(taskID, number)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", 0, false, fmt.Errorf("specification-%d not found", number)
+		}
+
+		return "", 0, false, fmt.Errorf("load specification-%d: %w", number, err)
+	}
+	if spec.Status == storage.SpecificationStatusDone {
+		return "", 0, false, fmt.Errorf("specification-%d: %w", number, ErrSpecificationDone)
+	}
+
+	return spec.Content, number, spec.Status == storage.SpecificationStatusImplementing, nil
+}
+
+// specPrompt limits the agent to one specification when implementing per spec.
+func specPrompt(number int, resumed bool) string {
+	prompt := fmt.Sprintf(`
+## Specification Scope
+Implement only specification %d. Other specifications are implemented separately; do not work on them.
+`, number)
+
+	if resumed {
+		prompt += `A previous run started this specification and may have left partial changes.
+Inspect the current code and continue from where it stopped instead of starting over.
+`
+	}
+
+	return prompt
+}
@@ -0,0 +1,159 @@
+package conductor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestImplementationSpec(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	ws, err := storage.OpenWorkspace(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+	if _, err := ws.CreateWork("task1", storage.SourceInfo{Type: "file", Ref: "task.md"}); err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	for i, content := range []string{"# One", "# Two", "# Three"} {
+		if err := ws.SaveSpecification("task1", i+1, content); err != nil {
+			t.Fatalf("SaveSpecification: %v", err)
+		}
+	}
+	if err := ws.StartSpecificationImplementation("task1", 2, "s1.yaml"); err != nil {
+		t.Fatalf("StartSpecificationImplementation: %v", err)
+	}
+	if err := ws.CompleteSpecificationImplementation("task1", 1, 1); err != nil {
+		t.Fatalf("CompleteSpecificationImplementation: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		spec        int
+		wantNum     int
+		wantContent string
+		wantResumed bool
+		wantErr     error
+	}{
+		{name: "latest by default", spec: 0, wantNum: 3, wantContent: "# Three"},
+		{name: "selected", spec: 3, wantNum: 3, wantContent: "# Three"},
+		{name: "resumes implementing", spec: 2, wantNum: 2, wantContent: "# Two", wantResumed: true},
+		{name: "done", spec: 1, wantErr: ErrSpecificationDone},
+		{name: "missing", spec: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(WithWorkDir(tmpDir), WithSpecification(tt.spec))
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			c.workspace = ws
+
+			content, num, resumed, err := c.implementationSpec("task1")
+			if tt.wantNum == 0 {
+				if err == nil {
+					t.Fatal("implementationSpec() should fail")
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("implementationSpec() error = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("implementationSpec: %v", err)
+			}
+			if num != tt.wantNum || content != tt.wantContent || resumed != tt.wantResumed {
+				t.Errorf("implementationSpec() = %q, %d, %v, want %q, %d, %v",
+					content, num, resumed, tt.wantContent, tt.wantNum, tt.wantResumed)
+			}
+		})
+	}
+}
+
+func TestSpecPrompt(t *testing.T) {
+	prompt := specPrompt(3, false)
+	if !strings.Contains(prompt, "Implement only specification 3") {
+		t.Errorf("specPrompt() = %q, should name the specification", prompt)
+	}
+	if strings.Contains(prompt, "previous run") {
+		t.Errorf("specPrompt() = %q, should not mention a previous run", prompt)
+	}
+
+	if prompt := specPrompt(3, true); !strings.Contains(prompt, "previous run") {
+		t.Errorf("specPrompt() = %q, should mention the previous run when resuming", prompt)
+	}
+}
+
+func TestPlanningSpecTemplate(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	ws, err := storage.OpenWorkspace(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+	work, err := ws.CreateWork("task1", storage.SourceInfo{Type: "file", Ref: "task.md"})
+	if err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	work.Metadata.Title = "Orders API"
+
+	c, err := New(WithWorkDir(tmpDir))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	c.workspace = ws
+	c.taskWork = work
+
+	if got, err := c.planningSpecTemplate("task1"); err != nil || got != "" {
+		t.Errorf("planningSpecTemplate() without template = %q, %v", got, err)
+	}
+
+	c.opts.SpecTemplate = "api-endpoint"
+	got, err := c.planningSpecTemplate("task1")
+	if err != nil {
+		t.Fatalf("planningSpecTemplate: %v", err)
+	}
+	if !strings.HasPrefix(got, "# Orders API") {
+		t.Errorf("planningSpecTemplate() = %q, want rendered title", got)
+	}
+
+	saved, err := ws.LoadWork("task1")
+	if err != nil {
+		t.Fatalf("LoadWork: %v", err)
+	}
+	if saved.Metadata.SpecTemplate != "api-endpoint" {
+		t.Errorf("SpecTemplate = %q, want it remembered", saved.Metadata.SpecTemplate)
+	}
+
+	// Later runs without the option keep using the remembered template
+	c.opts.SpecTemplate = ""
+	if got, err := c.planningSpecTemplate("task1"); err != nil || got == "" {
+		t.Errorf("planningSpecTemplate() from metadata = %q, %v", got, err)
+	}
+
+	c.opts.SpecTemplate = "missing"
+	if _, err := c.planningSpecTemplate("task1"); !errors.Is(err, storage.ErrSpecTemplateNotFound) {
+		t.Errorf("planningSpecTemplate(missing) error = %v", err)
+	}
+}
+
+func TestSpecTemplatePrompt(t *testing.T) {
+	if got := specTemplatePrompt(""); got != "" {
+		t.Errorf("specTemplatePrompt(\"\") = %q, want empty", got)
+	}
+	got := specTemplatePrompt("# Title\n## Root Cause\n")
+	if !strings.Contains(got, "## Specification Format") || !strings.Contains(got, "## Root Cause") {
+		t.Errorf("specTemplatePrompt() = %q", got)
+	}
+}
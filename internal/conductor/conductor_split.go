@@ -0,0 +1,316 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/naming"
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// childHeadingPrefix starts each child task in the agent's split proposal.
+const childHeadingPrefix = "## Child Task:"
+
+// splitSourceType is the source type of tasks created by Split.
+const splitSourceType = "split"
+
+// ChildTask is a child work unit proposed when splitting a task.
+type ChildTask struct {
+	Title       string
+	Description string
+}
+
+// SplitOptions configures Split.
+type SplitOptions struct {
+	// CreateIssues creates a work unit for each child with the parent's
+	// provider, linked to the parent where the provider supports it.
+	CreateIssues bool
+}
+
+// ProposeSplit asks the planning agent to break the active task into child
+// tasks that can be implemented and reviewed independently.
+func (c *Conductor) ProposeSplit(ctx context.Context) ([]ChildTask, error) {
+	if c.activeTask == nil || c.taskWork == nil {
+		return nil, errors.New("no active task")
+	}
+	taskID := c.activeTask.ID
+
+	sourceContent, err := c.workspace.GetSourceContent(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("read source: %w", err)
+	}
+
+	planner, err := c.GetAgentForStep(ctx, workflow.StepPlanning)
+	if err != nil {
+		return nil, fmt.Errorf("get planning agent: %w", err)
+	}
+
+	c.publishProgress("Proposing child tasks...", 10)
+	response, err := planner.Run(ctx, buildSplitPrompt(c.taskWork.Metadata.Title, sourceContent))
+	if err != nil {
+		return nil, fmt.Errorf("agent split: %w", err)
+	}
+	c.recordUsage(taskID, "split", workflow.StepPlanning, planner, response.Usage)
+
+	children := parseChildTasks(response.Summary)
+	if len(children) == 0 {
+		children = parseChildTasks(strings.Join(response.Messages, "\n"))
+	}
+	if len(children) == 0 {
+		return nil, errors.New("agent proposed no child tasks")
+	}
+
+	return children, nil
+}
+
+// Split creates a work directory for each child task, linked to the active
+// task through WorkMetadata.ParentID, and optionally a work unit with the
+// parent's provider. The parent stops being the active task, so the
+// children can be started with 'mehr start <child-id>'; its completion is
+// tracked from the children's.
+func (c *Conductor) Split(ctx context.Context, children []ChildTask, opts SplitOptions) ([]*storage.TaskWork, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.activeTask == nil || c.taskWork == nil {
+		return nil, errors.New("no active task")
+	}
+	if len(children) == 0 {
+		return nil, errors.New("no child tasks")
+	}
+	parent := c.taskWork
+
+	var creator provider.WorkUnitCreator
+	var parentUnitID string
+	if opts.CreateIssues {
+		p, id, err := c.providers.Resolve(ctx, c.activeTask.Ref, c.providerConfig(c.referenceProvider(c.activeTask.Ref)), provider.ResolveOptions{
+			DefaultProvider: c.defaultProvider(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("resolve provider: %w", err)
+		}
+		var ok bool
+		if creator, ok = p.(provider.WorkUnitCreator); !ok {
+			return nil, fmt.Errorf("provider for %s cannot create work units", c.activeTask.Ref)
+		}
+		parentUnitID = id
+	}
+
+	created := make([]*storage.TaskWork, 0, len(children))
+	for _, child := range children {
+		reference, externalKey := "", ""
+		if creator != nil {
+			var unit *provider.WorkUnit
+			err := c.traceProvider(ctx, c.referenceProvider(c.activeTask.Ref), "create", func(ctx context.Context) error {
+				var err error
+				unit, err = creator.CreateWorkUnit(ctx, provider.CreateWorkUnitOptions{
+					Title:       child.Title,
+					Description: child.Description,
+					ParentID:    parentUnitID,
+				})
+
+				return err
+			})
+			if err != nil {
+				return created, fmt.Errorf("create work unit for %q: %w", child.Title, err)
+			}
+			reference = c.referenceProvider(c.activeTask.Ref) + ":" + unit.ID
+			externalKey = unit.ExternalKey
+		}
+
+		work, err := c.createChildWork(parent, child, reference, externalKey)
+		if err != nil {
+			return created, err
+		}
+		created = append(created, work)
+	}
+
+	c.parkParent(ctx)
+
+	return created, nil
+}
+
+// createChildWork creates the work directory of a child task, with the
+// child's description as its source.
+func (c *Conductor) createChildWork(parent *storage.TaskWork, child ChildTask, reference, externalKey string) (*storage.TaskWork, error) {
+	taskID := storage.GenerateTaskID()
+	snapshot := &provider.Snapshot{
+		Type:  splitSourceType,
+		Ref:   reference,
+		Files: []provider.SnapshotFile{{Path: "task.md", Content: childSource(child)}},
+	}
+
+	work, err := c.workspace.CreateWork(taskID, c.buildSourceInfo(snapshot))
+	if err != nil {
+		return nil, fmt.Errorf("create work: %w", err)
+	}
+	if err := c.writeSourceFiles(taskID, snapshot); err != nil {
+		return nil, fmt.Errorf("write source files: %w", err)
+	}
+
+	work.Metadata.Title = child.Title
+	work.Metadata.ParentID = parent.Metadata.ID
+	work.Metadata.ExternalKey = externalKey
+	work.Metadata.TaskType = parent.Metadata.TaskType
+	work.Metadata.Slug = naming.Slugify(child.Title, 50)
+	work.Metadata.Scope = parent.Metadata.Scope
+	work.Metadata.AllowOutsideScope = parent.Metadata.AllowOutsideScope
+	if err := c.workspace.SaveWork(work); err != nil {
+		return nil, fmt.Errorf("save work: %w", err)
+	}
+
+	return work, nil
+}
+
+// parkParent clears the active task after a split, leaving its branch for
+// the base branch the children start from.
+func (c *Conductor) parkParent(ctx context.Context) {
+	if c.git != nil && c.activeTask.Branch != "" && c.activeTask.WorktreePath == "" {
+		current, _ := c.git.CurrentBranch(ctx)
+		if current == c.activeTask.Branch && c.taskWork.Git.BaseBranch != "" {
+			if err := c.git.Checkout(ctx, c.taskWork.Git.BaseBranch); err != nil {
+				c.logError(fmt.Errorf("checkout base branch: %w", err))
+			}
+		}
+	}
+
+	if err := c.workspace.ClearActiveTask(); err != nil {
+		c.logError(fmt.Errorf("clear active task: %w", err))
+	}
+	c.activeTask = nil
+	c.taskWork = nil
+}
+
+// pendingChild returns the not yet started child task a start reference
+// names, by task ID or by the reference of its work unit.
+func (c *Conductor) pendingChild(reference string) *storage.TaskWork {
+	pending := func(work *storage.TaskWork) bool {
+		return work.Metadata.ParentID != "" && work.Metadata.FinishedAt.IsZero() && work.Git.Branch == ""
+	}
+
+	if c.workspace.WorkExists(reference) {
+		if work, err := c.workspace.LoadWork(reference); err == nil && pending(work) {
+			return work
+		}
+
+		return nil
+	}
+
+	works, err := c.workspace.LoadWorks()
+	if err != nil {
+		return nil
+	}
+	for _, work := range works {
+		if work.Source.Ref != "" && work.Source.Ref == reference && pending(work) {
+			return work
+		}
+	}
+
+	return nil
+}
+
+// startChild makes a child task created by Split the active task, creating
+// its branch or worktree.
+func (c *Conductor) startChild(ctx context.Context, work *storage.TaskWork, scope string) error {
+	taskID := work.Metadata.ID
+	workUnit := &provider.WorkUnit{
+		Title:       work.Metadata.Title,
+		ExternalKey: work.Metadata.ExternalKey,
+		TaskType:    work.Metadata.TaskType,
+		Slug:        work.Metadata.Slug,
+	}
+	namingInfo := c.resolveNaming(workUnit, taskID)
+
+	repos, err := c.attachRepositories(ctx, namingInfo)
+	if err != nil {
+		return err
+	}
+	gitInfo, err := c.createBranchOrWorktree(ctx, taskID, namingInfo)
+	if err != nil {
+		c.detachRepositories(ctx, repos)
+
+		return err
+	}
+
+	agentInst, agentSource, err := c.resolveAgentForTask()
+	if err != nil {
+		return fmt.Errorf("resolve agent: %w", err)
+	}
+	c.activeAgent = agentInst
+
+	work.Agent = storage.AgentInfo{Name: agentInst.Name(), Source: agentSource}
+	work.Repos = repos
+	if scope != "" {
+		work.Metadata.Scope = scope
+		work.Metadata.AllowOutsideScope = c.opts.AllowOutsideScope
+	}
+	if err := c.activateWork(work, work.Source.Ref, gitInfo, namingInfo); err != nil {
+		return err
+	}
+
+	c.eventBus.Publish(events.TaskStartedEvent{
+		TaskID:    taskID,
+		Title:     work.Metadata.Title,
+		Reference: work.Source.Ref,
+		Branch:    gitInfo.branchName,
+	})
+	c.publishProgress("Task registered", 100)
+
+	return nil
+}
+
+// childSource renders a child task as the markdown source of its work.
+func childSource(child ChildTask) string {
+	return fmt.Sprintf("# %s\n\n%s\n", child.Title, strings.TrimSpace(child.Description))
+}
+
+// buildSplitPrompt asks the agent to propose child tasks for a task.
+func buildSplitPrompt(title, sourceContent string) string {
+	var sb strings.Builder
+	sb.WriteString("Split the task below into smaller child tasks.\n\n")
+	fmt.Fprintf(&sb, "## Task\n%s\n\n", title)
+	fmt.Fprintf(&sb, "## Source\n%s\n\n", strings.TrimSpace(sourceContent))
+
+	sb.WriteString("## Instructions\n")
+	sb.WriteString("- Explore the codebase as needed, but do not change any files.\n")
+	sb.WriteString("- Propose 2 to 8 child tasks that together cover the whole task. Each must be implementable, reviewable and mergeable on its own; order them so earlier tasks do not depend on later ones.\n")
+	sb.WriteString("- Give each child task a short imperative title and a description stating its scope, the requirements it covers and how to verify it.\n\n")
+
+	sb.WriteString("## Output Format\n")
+	fmt.Fprintf(&sb, "Reply with one section per child task, and nothing else:\n\n%s <title>\n<description>\n", childHeadingPrefix)
+
+	return sb.String()
+}
+
+// parseChildTasks reads the child tasks from an agent's split proposal.
+// Text before the first child heading is ignored.
+func parseChildTasks(text string) []ChildTask {
+	var children []ChildTask
+	var body []string
+
+	flush := func() {
+		if len(children) > 0 {
+			children[len(children)-1].Description = strings.TrimSpace(strings.Join(body, "\n"))
+		}
+		body = nil
+	}
+	for line := range strings.SplitSeq(text, "\n") {
+		if title, ok := strings.CutPrefix(strings.TrimSpace(line), childHeadingPrefix); ok {
+			flush()
+			if title = strings.TrimSpace(title); title != "" {
+				children = append(children, ChildTask{Title: title})
+			}
+
+			continue
+		}
+		body = append(body, strings.TrimRight(line, "\r"))
+	}
+	flush()
+
+	return children
+}
@@ -0,0 +1,115 @@
+package conductor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider/file"
+)
+
+func TestParseChildTasks(t *testing.T) {
+	text := `Here is the split.
+
+## Child Task: Add the login form
+Render the form.
+
+Verify with the UI tests.
+## Child Task:   Validate input  
+Reject empty passwords.
+`
+	got := parseChildTasks(text)
+	if len(got) != 2 {
+		t.Fatalf("parseChildTasks() = %+v, want 2 children", got)
+	}
+	if got[0].Title != "Add the login form" || got[0].Description != "Render the form.\n\nVerify with the UI tests." {
+		t.Errorf("child 1 = %+v", got[0])
+	}
+	if got[1].Title != "Validate input" || got[1].Description != "Reject empty passwords." {
+		t.Errorf("child 2 = %+v", got[1])
+	}
+
+	if got := parseChildTasks("No proposal."); got != nil {
+		t.Errorf("parseChildTasks(no headings) = %+v, want nil", got)
+	}
+}
+
+func TestBuildSplitPrompt(t *testing.T) {
+	prompt := buildSplitPrompt("Login", "# Login\nAdd a login page.")
+	for _, want := range []string{"## Task\nLogin", "Add a login page.", childHeadingPrefix + " <title>", "do not change any files"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	taskPath := filepath.Join(tmpDir, "task.md")
+	if err := os.WriteFile(taskPath, []byte("# Login\n\nAdd a login page."), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	c, err := New(WithWorkDir(tmpDir), WithCreateBranch(false), WithAgent("mock"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	file.Register(c.GetProviderRegistry())
+	if err := c.GetAgentRegistry().Register(&mockAgent{name: "mock"}); err != nil {
+		t.Fatalf("Register mock agent: %v", err)
+	}
+	if err := c.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if err := c.Start(ctx, "file:"+taskPath); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	parentID := c.GetActiveTask().ID
+	ws := c.GetWorkspace()
+
+	if _, err := c.Split(ctx, []ChildTask{{Title: "Add form"}}, SplitOptions{CreateIssues: true}); err == nil {
+		t.Error("Split with issues for a file task should fail")
+	}
+
+	children, err := c.Split(ctx, []ChildTask{
+		{Title: "Add form", Description: "Render the form."},
+		{Title: "Validate input", Description: "Reject empty passwords."},
+	}, SplitOptions{})
+	if err != nil {
+		t.Fatalf("Split: %v", err)
+	}
+	if len(children) != 2 || c.GetActiveTask() != nil || ws.HasActiveTask() {
+		t.Fatalf("after split: %d children, active = %v", len(children), c.GetActiveTask())
+	}
+
+	child := children[0]
+	if child.Metadata.ParentID != parentID || child.Metadata.Title != "Add form" || child.Source.Type != splitSourceType {
+		t.Errorf("child metadata = %+v, source = %+v", child.Metadata, child.Source)
+	}
+	source, err := ws.GetSourceContent(child.Metadata.ID)
+	if err != nil || !strings.Contains(source, "# Add form\n\nRender the form.") {
+		t.Errorf("child source = %q, %v", source, err)
+	}
+
+	// Starting the child by ID activates its existing work directory
+	if err := c.Start(ctx, child.Metadata.ID); err != nil {
+		t.Fatalf("Start(child): %v", err)
+	}
+	if active := c.GetActiveTask(); active == nil || active.ID != child.Metadata.ID {
+		t.Fatalf("active task = %+v, want child", active)
+	}
+	if works, _ := ws.ListWorks(); len(works) != 3 {
+		t.Errorf("work directories = %v, want parent and two children", works)
+	}
+
+	progress, err := ws.ChildProgress(parentID)
+	if err != nil {
+		t.Fatalf("ChildProgress: %v", err)
+	}
+	if progress.Total != 2 || progress.Done != 0 {
+		t.Errorf("progress = %+v, want 0 of 2 done", progress)
+	}
+}
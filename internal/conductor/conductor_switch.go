@@ -0,0 +1,231 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// ErrUncommittedChanges is returned when the active task would be set aside
+// with uncommitted changes and WithStashWIP is not given.
+var ErrUncommittedChanges = errors.New("workspace has uncommitted changes")
+
+// SwitchResult describes a task switch.
+type SwitchResult struct {
+	From     string // Task that was parked, empty when none was active
+	To       string // Task that is now active
+	Stashed  bool   // Uncommitted changes of From were committed as WIP
+	Restored bool   // WIP changes of To were restored as uncommitted changes
+	Branch   string // Branch checked out for To
+}
+
+// SwitchTask parks the active task and makes taskID the active task, checking
+// out its branch. Uncommitted changes are refused unless WithStashWIP is
+// given; they are then committed as WIP on the parked task's branch and
+// restored as uncommitted changes when switching back.
+func (c *Conductor) SwitchTask(ctx context.Context, taskID string) (*SwitchResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.activeTask != nil && c.activeTask.ID == taskID {
+		return nil, fmt.Errorf("task %s is already active", taskID)
+	}
+	if !c.workspace.WorkExists(taskID) {
+		return nil, fmt.Errorf("task not found: %s", taskID)
+	}
+	work, err := c.workspace.LoadWork(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("load work: %w", err)
+	}
+	if !work.Metadata.FinishedAt.IsZero() {
+		return nil, fmt.Errorf("task %s is finished", taskID)
+	}
+	if err := switchable(work); err != nil {
+		return nil, err
+	}
+
+	result := &SwitchResult{To: taskID, Branch: work.Git.Branch}
+	if c.activeTask != nil {
+		result.From = c.activeTask.ID
+		if result.Stashed, err = c.parkActiveTask(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.git != nil && work.Git.Branch != "" {
+		if err := c.git.Checkout(ctx, work.Git.Branch); err != nil {
+			return result, err
+		}
+	}
+	if result.Restored, err = c.unparkTask(ctx, work); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// parkForStart parks the active task before starting another and returns to
+// the branch the parked task was started from.
+func (c *Conductor) parkForStart(ctx context.Context) error {
+	base := c.taskWork.Git.BaseBranch
+	stashed, err := c.parkActiveTask(ctx)
+	if err != nil {
+		return err
+	}
+	if stashed {
+		c.publishProgress("Uncommitted changes committed as WIP on the parked task", 0)
+	}
+	if c.git != nil && base != "" {
+		if err := c.git.Checkout(ctx, base); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// switchable reports why a task cannot take part in a switch, if it cannot.
+func switchable(work *storage.TaskWork) error {
+	if work.Git.WorktreePath != "" {
+		return fmt.Errorf("task %s has its own worktree; cd %s instead of switching", work.Metadata.ID, work.Git.WorktreePath)
+	}
+	if len(work.Repos) > 0 {
+		return fmt.Errorf("task %s has attached repositories, which cannot be switched", work.Metadata.ID)
+	}
+
+	return nil
+}
+
+// parkActiveTask sets the active task aside: its state is recorded in its
+// work and, with WithStashWIP, uncommitted changes are committed as WIP on its
+// branch. It reports whether a WIP commit was made. Afterwards no task is
+// active.
+func (c *Conductor) parkActiveTask(ctx context.Context) (_ bool, err error) {
+	if c.taskWork == nil {
+		return false, fmt.Errorf("load work for task %s", c.activeTask.ID)
+	}
+	if err := switchable(c.taskWork); err != nil {
+		return false, err
+	}
+
+	ctx, release, err := c.acquireLease(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer release(&err)
+
+	parked := &storage.ParkedTask{
+		Ref:      c.activeTask.Ref,
+		State:    c.activeTask.State,
+		ParkedAt: time.Now(),
+	}
+
+	if c.git != nil && c.activeTask.UseGit {
+		changed, err := c.git.ChangedFiles(ctx)
+		if err != nil {
+			return false, fmt.Errorf("check changes: %w", err)
+		}
+		changed = c.withoutOwnFiles(changed)
+		if len(changed) > 0 && !c.opts.StashWIP {
+			return false, fmt.Errorf("%w in task %s", ErrUncommittedChanges, c.activeTask.ID)
+		}
+		if len(changed) > 0 {
+			if err := c.git.Add(ctx, changed...); err != nil {
+				return false, err
+			}
+			message := fmt.Sprintf("WIP: %s (parked by mehrhof)", c.activeTask.ID)
+			if parked.WIPCommit, err = c.git.Commit(ctx, message); err != nil {
+				return false, fmt.Errorf("commit WIP: %w", err)
+			}
+		}
+	}
+
+	c.taskWork.Parked = parked
+	if err := c.workspace.SaveWork(c.taskWork); err != nil {
+		return false, fmt.Errorf("save work: %w", err)
+	}
+	if err := c.workspace.ClearActiveTask(); err != nil {
+		return false, fmt.Errorf("clear active task: %w", err)
+	}
+	c.activeTask = nil
+	c.taskWork = nil
+
+	return parked.WIPCommit != "", nil
+}
+
+// unparkTask makes a task active again. Its WIP commit, when still at the tip
+// of the branch, is undone so its changes are uncommitted as before; it
+// reports whether that happened.
+func (c *Conductor) unparkTask(ctx context.Context, work *storage.TaskWork) (bool, error) {
+	taskID := work.Metadata.ID
+
+	ref, state, wip := work.Source.Ref, "idle", ""
+	if work.Parked != nil {
+		ref, state, wip = work.Parked.Ref, work.Parked.State, work.Parked.WIPCommit
+	}
+
+	restored := false
+	if wip != "" && c.git != nil {
+		head, err := c.git.RevParse(ctx, "HEAD")
+		switch {
+		case err != nil:
+			return false, err
+		case head == wip:
+			if err := c.git.ResetMixed(ctx, "HEAD~1"); err != nil {
+				return false, fmt.Errorf("restore WIP: %w", err)
+			}
+			restored = true
+		default:
+			c.publishProgress(fmt.Sprintf("WIP commit %s is no longer the tip of %s; left as a commit", shortHash(wip), work.Git.Branch), 0)
+		}
+	}
+
+	work.Parked = nil
+	if err := c.workspace.SaveWork(work); err != nil {
+		return restored, fmt.Errorf("save work: %w", err)
+	}
+
+	active := storage.NewActiveTask(taskID, ref, c.workspace.WorkPath(taskID))
+	active.State = state
+	active.UseGit = c.git != nil
+	active.Branch = work.Git.Branch
+	if err := c.workspace.SaveActiveTask(active); err != nil {
+		return restored, fmt.Errorf("save active task: %w", err)
+	}
+
+	c.activeTask = active
+	c.taskWork = work
+	c.machine.SetWorkUnit(c.buildWorkUnit())
+	c.restoreTaskAgent()
+
+	return restored, nil
+}
+
+// restoreTaskAgent selects the agent stored with the active task, unless one
+// was chosen explicitly.
+func (c *Conductor) restoreTaskAgent() {
+	if c.opts.AgentName != "" || c.taskWork.Agent.Name == "" {
+		return
+	}
+	agentInst, err := c.agents.Get(c.taskWork.Agent.Name)
+	if err != nil {
+		return
+	}
+	agentInst = applyAgentEnv(agentInst, c.taskWork.Agent.InlineEnv)
+	if len(c.taskWork.Agent.Args) > 0 {
+		agentInst = agentInst.WithArgs(c.taskWork.Agent.Args...)
+	}
+	c.activeAgent = agentInst
+}
+
+// shortHash abbreviates a commit hash for display.
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+
+	return hash
+}
@@ -0,0 +1,102 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestSwitchTask(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	initGitRepo(t, tmpDir)
+
+	c, err := New(WithWorkDir(tmpDir), WithAgent("mock"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := c.GetAgentRegistry().Register(&mockAgent{name: "mock"}); err != nil {
+		t.Fatalf("Register agent: %v", err)
+	}
+	if err := c.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	base, err := c.git.CurrentBranch(ctx)
+	if err != nil {
+		t.Fatalf("CurrentBranch: %v", err)
+	}
+	for _, id := range []string{"one", "two"} {
+		work, err := c.workspace.CreateWork(id, storage.SourceInfo{Type: "file", Ref: id + ".md"})
+		if err != nil {
+			t.Fatalf("CreateWork: %v", err)
+		}
+		work.Git.Branch, work.Git.BaseBranch = "task/"+id, base
+		if err := c.workspace.SaveWork(work); err != nil {
+			t.Fatalf("SaveWork: %v", err)
+		}
+		if err := runGitCmd(ctx, tmpDir, "branch", "task/"+id); err != nil {
+			t.Fatalf("git branch: %v", err)
+		}
+	}
+	if err := c.git.Checkout(ctx, "task/one"); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	c.activeTask = &storage.ActiveTask{ID: "one", Ref: "file:one.md", State: "implementing", Branch: "task/one", UseGit: true}
+	c.taskWork, _ = c.workspace.LoadWork("one")
+
+	readme := filepath.Join(tmpDir, "README.md")
+	if err := os.WriteFile(readme, []byte("# Half done\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.SwitchTask(ctx, "two"); !errors.Is(err, ErrUncommittedChanges) {
+		t.Fatalf("SwitchTask with changes = %v, want ErrUncommittedChanges", err)
+	}
+
+	c.opts.StashWIP = true
+	res, err := c.SwitchTask(ctx, "two")
+	if err != nil {
+		t.Fatalf("SwitchTask: %v", err)
+	}
+	if res.From != "one" || !res.Stashed || res.Restored {
+		t.Errorf("switch to two = %+v, want one parked with a WIP commit", res)
+	}
+	if branch, _ := c.git.CurrentBranch(ctx); branch != "task/two" {
+		t.Errorf("branch = %q, want task/two", branch)
+	}
+	if data, _ := os.ReadFile(readme); string(data) != "# Test\n" {
+		t.Errorf("README.md on task/two = %q, want the base content", data)
+	}
+	if c.activeTask.ID != "two" || c.activeTask.State != "idle" {
+		t.Errorf("active task = %+v, want two idle", c.activeTask)
+	}
+
+	res, err = c.SwitchTask(ctx, "one")
+	if err != nil {
+		t.Fatalf("SwitchTask back: %v", err)
+	}
+	if !res.Restored {
+		t.Errorf("switch back = %+v, want the WIP restored", res)
+	}
+	if data, _ := os.ReadFile(readme); string(data) != "# Half done\n" {
+		t.Errorf("README.md = %q, want the parked change", data)
+	}
+	if changed, _ := c.git.ChangedFiles(ctx); len(c.withoutOwnFiles(changed)) != 1 {
+		t.Errorf("changed files = %v, want README.md uncommitted again", changed)
+	}
+	if c.activeTask.State != "implementing" || c.activeTask.Ref != "file:one.md" {
+		t.Errorf("active task = %+v, want the parked state and reference", c.activeTask)
+	}
+	if work, _ := c.workspace.LoadWork("one"); work.Parked != nil {
+		t.Errorf("parked = %+v, want cleared", work.Parked)
+	}
+}
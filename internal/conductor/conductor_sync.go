@@ -0,0 +1,312 @@
+package conductor
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+// Strategies for bringing the base branch into the task branch.
+const (
+	SyncRebase = "rebase" // Replay the task's commits on top of the base branch
+	SyncMerge  = "merge"  // Merge the base branch into the task branch
+)
+
+// ErrSyncConflict is returned when a sync stops on conflicts that were not
+// resolved. The rebase or merge has been aborted.
+var ErrSyncConflict = errors.New("sync stopped on conflicts")
+
+// maxConflictRounds bounds how many times the agent is asked to resolve
+// conflicts in one sync. A rebase can conflict once per replayed commit.
+const maxConflictRounds = 10
+
+// SyncOptions configures a sync of the task branch with its base branch.
+type SyncOptions struct {
+	Strategy string // SyncRebase or SyncMerge (default: git.sync_strategy, else rebase)
+	Remote   string // Remote to fetch the base branch from (default: origin)
+	NoFetch  bool   // Use the local base branch without fetching
+	BaseRef  string // Ref to sync with as is, without fetching (default: the task's base branch)
+	// ResolveConflicts hands conflicts to the agent. Without it, the sync is
+	// aborted and ErrSyncConflict lists the conflicting files.
+	ResolveConflicts bool
+}
+
+// SyncResult describes what a sync did.
+type SyncResult struct {
+	Strategy  string   // Strategy used
+	BaseRef   string   // What the task branch was synced with (e.g. origin/main)
+	Behind    int      // Commits on the base the task branch did not have
+	UpToDate  bool     // Nothing to do
+	Conflicts []string // Files that conflicted, across all rounds
+	Resolved  bool     // Conflicts were resolved by the agent
+	// Reports describes each round of conflicts, in order. A rebase can stop
+	// once per replayed commit.
+	Reports []*ConflictReport
+}
+
+// ParseSyncStrategy validates a sync strategy name. Empty means the default.
+func ParseSyncStrategy(name string) (string, error) {
+	switch name {
+	case "", SyncRebase:
+		return SyncRebase, nil
+	case SyncMerge:
+		return SyncMerge, nil
+	default:
+		return "", fmt.Errorf("unknown sync strategy %q (use rebase or merge)", name)
+	}
+}
+
+// Sync brings the base branch into the task branch: it fetches the remote,
+// then rebases the task branch onto the base branch or merges it in. With
+// ResolveConflicts, each round of conflicts is reported to the agent in a
+// conflict resolution step, and its resolution is committed once confirmed;
+// otherwise conflicts abort the sync.
+func (c *Conductor) Sync(ctx context.Context, opts SyncOptions) (*SyncResult, error) {
+	var result *SyncResult
+	err := c.tracePhase(ctx, "sync", func(ctx context.Context) (err error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if c.activeTask == nil {
+			return errors.New("no active task")
+		}
+
+		ctx, release, err := c.acquireLease(ctx)
+		if err != nil {
+			return err
+		}
+		defer release(&err)
+
+		result, err = c.syncBranch(ctx, opts)
+
+		return err
+	})
+
+	return result, err
+}
+
+// syncBranch syncs the task branch. Callers hold the lock and the lease.
+func (c *Conductor) syncBranch(ctx context.Context, opts SyncOptions) (*SyncResult, error) {
+	if c.git == nil || !c.activeTask.UseGit || c.activeTask.Branch == "" {
+		return nil, errors.New("task has no git branch to sync")
+	}
+
+	strategy, err := ParseSyncStrategy(cmp.Or(opts.Strategy, c.configuredSyncStrategy()))
+	if err != nil {
+		return nil, err
+	}
+	result := &SyncResult{Strategy: strategy}
+
+	branch := c.activeTask.Branch
+	if current, _ := c.git.CurrentBranch(ctx); current != branch {
+		return nil, fmt.Errorf("task branch %s is not checked out here (on %s); run sync from the task's worktree", branch, current)
+	}
+	if op := c.git.OperationInProgress(ctx); op != "" {
+		return nil, fmt.Errorf("a %s is already in progress; finish or abort it first", op)
+	}
+
+	// Checkpoint first, so undo can return across the rewritten history
+	c.checkpointHead(ctx, "Before sync with base branch")
+
+	result.BaseRef, err = c.syncBaseRef(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Behind, err = c.git.GetBranchCommitCount(ctx, result.BaseRef, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("compare with %s: %w", result.BaseRef, err)
+	}
+	if result.Behind == 0 {
+		result.UpToDate = true
+
+		return result, nil
+	}
+
+	c.publishProgress(fmt.Sprintf("Syncing %s with %s (%s, %d new commit(s))...", branch, result.BaseRef, strategy, result.Behind), 10)
+
+	if strategy == SyncMerge {
+		err = c.git.MergeBranch(ctx, result.BaseRef, false)
+	} else {
+		err = c.git.RebaseBranch(ctx, result.BaseRef)
+	}
+	if err != nil {
+		if err := c.resolveSyncConflicts(ctx, opts, result); err != nil {
+			c.abortSync(ctx)
+
+			return result, err
+		}
+		result.Resolved = true
+	}
+
+	c.checkpointHead(ctx, "Sync with "+result.BaseRef)
+
+	return result, nil
+}
+
+// checkpointHead makes HEAD a checkpoint. Unlike createCheckpointIfNeeded it
+// also checkpoints a clean tree, by tagging HEAD, unless HEAD already is the
+// latest checkpoint.
+func (c *Conductor) checkpointHead(ctx context.Context, message string) {
+	if event := c.createCheckpointIfNeeded(ctx, c.activeTask.ID, message); event != nil {
+		c.eventBus.PublishRaw(*event)
+
+		return
+	}
+
+	head, err := c.git.RevParse(ctx, "HEAD")
+	if err != nil {
+		return
+	}
+	if latest, err := c.git.GetLatestCheckpoint(ctx, c.activeTask.ID); err == nil && latest.ID == head {
+		return
+	}
+
+	prefix := fmt.Sprintf("[%s]", c.activeTask.ID)
+	if c.taskWork != nil && c.taskWork.Git.CommitPrefix != "" {
+		prefix = c.taskWork.Git.CommitPrefix
+	}
+	checkpoint, err := c.git.CreateCheckpointWithPrefix(ctx, c.activeTask.ID, message, prefix)
+	if err != nil {
+		c.logError(fmt.Errorf("create checkpoint: %w", err))
+
+		return
+	}
+	c.eventBus.PublishRaw(events.Event{
+		Type: events.TypeCheckpoint,
+		Data: map[string]any{
+			"action":     "create",
+			"checkpoint": checkpoint.Number,
+			"commit":     checkpoint.ID,
+		},
+	})
+}
+
+// syncBaseRef fetches the task's base branch and returns the ref to sync
+// with: the remote branch when there is one, else the local branch.
+func (c *Conductor) syncBaseRef(ctx context.Context, opts SyncOptions) (string, error) {
+	if opts.BaseRef != "" {
+		return opts.BaseRef, nil
+	}
+
+	base := c.resolveTargetBranch(ctx, "")
+	if base == "" {
+		return "", errors.New("cannot determine the base branch")
+	}
+
+	remote := cmp.Or(opts.Remote, "origin")
+	if _, err := c.git.RemoteURL(ctx, remote); err != nil {
+		return base, nil //nolint:nilerr // No remote: sync with the local base branch
+	}
+	if !opts.NoFetch {
+		c.publishProgress(fmt.Sprintf("Fetching %s from %s...", base, remote), 5)
+		if err := c.git.Fetch(ctx, remote, base); err != nil {
+			return "", fmt.Errorf("fetch %s from %s: %w", base, remote, err)
+		}
+	}
+	if c.git.RemoteBranchExists(ctx, remote, base) {
+		return remote + "/" + base, nil
+	}
+
+	return base, nil
+}
+
+// resolveSyncConflicts handles a rebase or merge that stopped. Conflicts are
+// resolved by the agent round by round until the operation completes.
+func (c *Conductor) resolveSyncConflicts(ctx context.Context, opts SyncOptions, result *SyncResult) error {
+	for round := 1; ; round++ {
+		op := c.git.OperationInProgress(ctx)
+		if op == "" {
+			return nil
+		}
+
+		files, err := c.git.ConflictedFiles(ctx)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("%s with %s failed without conflicts", result.Strategy, result.BaseRef)
+		}
+		for _, file := range files {
+			if !slices.Contains(result.Conflicts, file) {
+				result.Conflicts = append(result.Conflicts, file)
+			}
+		}
+
+		report, err := c.conflictReport(ctx, op, result.BaseRef, files)
+		if err != nil {
+			return err
+		}
+		result.Reports = append(result.Reports, report)
+
+		if !opts.ResolveConflicts {
+			return fmt.Errorf("%w in %s", ErrSyncConflict, strings.Join(files, ", "))
+		}
+		if round > maxConflictRounds {
+			return fmt.Errorf("%w: still conflicting after %d resolution rounds", ErrSyncConflict, maxConflictRounds)
+		}
+
+		c.publishProgress(fmt.Sprintf("Resolving conflicts in %d file(s)...", len(files)), 30)
+		resolution, err := c.runConflictResolution(ctx, report)
+		if err != nil {
+			return err
+		}
+		if !c.confirmResolution(report, resolution) {
+			return ErrResolutionRejected
+		}
+
+		if err := c.git.Add(ctx, files...); err != nil {
+			return err
+		}
+		if op == vcs.OperationMerge {
+			if _, err := c.git.Commit(ctx, fmt.Sprintf("Merge %s into %s", result.BaseRef, c.activeTask.Branch)); err != nil {
+				return err
+			}
+
+			continue
+		}
+		// Continuing may stop again on the next replayed commit
+		_ = c.git.ContinueRebase(ctx)
+	}
+}
+
+// abortSync aborts a rebase or merge left in progress by a failed sync.
+func (c *Conductor) abortSync(ctx context.Context) {
+	var err error
+	switch c.git.OperationInProgress(ctx) {
+	case vcs.OperationRebase:
+		err = c.git.AbortRebase(ctx)
+	case vcs.OperationMerge:
+		err = c.git.AbortMerge(ctx)
+	}
+	if err != nil {
+		c.logError(fmt.Errorf("abort sync: %w", err))
+	}
+}
+
+// configuredSyncStrategy returns git.sync_strategy from the workspace config.
+func (c *Conductor) configuredSyncStrategy() string {
+	if c.workspace == nil {
+		return ""
+	}
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil {
+		return ""
+	}
+
+	return cfg.Git.SyncStrategy
+}
+
+// SyncBeforeFinish reports whether the workspace config asks to sync the
+// task branch before finishing.
+func (c *Conductor) SyncBeforeFinish() bool {
+	cfg, err := c.workspace.LoadConfig()
+
+	return err == nil && cfg.Git.SyncBeforeFinish
+}
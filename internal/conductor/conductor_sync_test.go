@@ -0,0 +1,237 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+// resolvingAgent answers a conflict resolution prompt with the given files.
+type resolvingAgent struct {
+	mockAgent
+
+	files   []agent.FileChange
+	prompts []string
+}
+
+func (a *resolvingAgent) Run(ctx context.Context, prompt string) (*agent.Response, error) {
+	a.prompts = append(a.prompts, prompt)
+
+	return &agent.Response{Summary: "Resolved conflicts", Files: a.files}, nil
+}
+
+func (a *resolvingAgent) RunWithCallback(ctx context.Context, prompt string, _ agent.StreamCallback) (*agent.Response, error) {
+	return a.Run(ctx, prompt)
+}
+
+// newSyncConductor sets up a task branch and a base branch that both change
+// the same line of notes.txt. Conflict resolutions are confirmed unless
+// options say otherwise.
+func newSyncConductor(t *testing.T, a *resolvingAgent, opts ...Option) (*Conductor, string) {
+	t.Helper()
+
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	initGitRepo(t, tmpDir)
+
+	commit := func(content, message string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(tmpDir, "notes.txt"), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		if err := runGitCmd(ctx, tmpDir, "add", "notes.txt"); err != nil {
+			t.Fatalf("git add: %v", err)
+		}
+		if err := runGitCmd(ctx, tmpDir, "commit", "-m", message); err != nil {
+			t.Fatalf("git commit: %v", err)
+		}
+	}
+
+	commit("one\ntwo\nthree\n", "Add notes")
+	git, err := vcs.New(ctx, tmpDir)
+	if err != nil {
+		t.Fatalf("vcs.New: %v", err)
+	}
+	base, err := git.CurrentBranch(ctx)
+	if err != nil {
+		t.Fatalf("CurrentBranch: %v", err)
+	}
+
+	if err := runGitCmd(ctx, tmpDir, "checkout", "-b", "task/sync"); err != nil {
+		t.Fatalf("git checkout: %v", err)
+	}
+	commit("one\ntask\nthree\n", "Task change")
+	if err := runGitCmd(ctx, tmpDir, "checkout", base); err != nil {
+		t.Fatalf("git checkout: %v", err)
+	}
+	commit("one\nbase\nthree\n", "Base change")
+	if err := runGitCmd(ctx, tmpDir, "checkout", "task/sync"); err != nil {
+		t.Fatalf("git checkout: %v", err)
+	}
+
+	a.name = "resolver"
+	confirm := WithConflictResolutionCallback(func(*ConflictReport, *ConflictResolution) bool { return true })
+	c, err := New(append([]Option{WithWorkDir(tmpDir), WithAgent("resolver"), confirm}, opts...)...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := c.GetAgentRegistry().Register(a); err != nil {
+		t.Fatalf("Register agent: %v", err)
+	}
+	if err := c.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	c.activeTask = &storage.ActiveTask{ID: "sync", Branch: "task/sync", UseGit: true}
+	c.taskWork = &storage.TaskWork{Git: storage.GitInfo{Branch: "task/sync", BaseBranch: base}}
+
+	return c, tmpDir
+}
+
+func TestSync_ResolvesConflicts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	for _, strategy := range []string{SyncRebase, SyncMerge} {
+		t.Run(strategy, func(t *testing.T) {
+			a := &resolvingAgent{files: []agent.FileChange{
+				{Path: "notes.txt", Operation: agent.FileOpUpdate, Content: "one\nbase and task\nthree\n"},
+			}}
+			c, tmpDir := newSyncConductor(t, a)
+			ctx := context.Background()
+
+			result, err := c.Sync(ctx, SyncOptions{Strategy: strategy, ResolveConflicts: true})
+			if err != nil {
+				t.Fatalf("Sync: %v", err)
+			}
+			if !result.Resolved || result.Behind != 1 || strings.Join(result.Conflicts, ",") != "notes.txt" {
+				t.Errorf("result = %+v, want 1 commit behind and notes.txt resolved", result)
+			}
+			if len(a.prompts) != 1 || !strings.Contains(a.prompts[0], "<<<<<<<") {
+				t.Fatalf("prompts = %q, want one prompt with the conflict hunk", a.prompts)
+			}
+
+			content, err := os.ReadFile(filepath.Join(tmpDir, "notes.txt"))
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			if string(content) != "one\nbase and task\nthree\n" {
+				t.Errorf("notes.txt = %q", content)
+			}
+			if op := c.git.OperationInProgress(ctx); op != "" {
+				t.Errorf("%s still in progress", op)
+			}
+			if branch, _ := c.git.CurrentBranch(ctx); branch != "task/sync" {
+				t.Errorf("on branch %s, want task/sync", branch)
+			}
+			if behind, _ := c.git.GetBranchCommitCount(ctx, c.taskWork.Git.BaseBranch, "HEAD"); behind != 0 {
+				t.Errorf("still %d commit(s) behind the base branch", behind)
+			}
+		})
+	}
+}
+
+func TestSync_ConflictAborts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	tests := []struct {
+		name    string
+		files   []agent.FileChange
+		resolve bool
+		prompts int
+	}{
+		{name: "no resolve", resolve: false, prompts: 0},
+		{
+			name: "markers left",
+			files: []agent.FileChange{
+				{Path: "notes.txt", Operation: agent.FileOpUpdate, Content: "one\n<<<<<<< HEAD\nbase\n=======\ntask\n>>>>>>> task\nthree\n"},
+			},
+			resolve: true,
+			prompts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &resolvingAgent{files: tt.files}
+			c, tmpDir := newSyncConductor(t, a)
+			ctx := context.Background()
+
+			result, err := c.Sync(ctx, SyncOptions{ResolveConflicts: tt.resolve})
+			if !errors.Is(err, ErrSyncConflict) {
+				t.Fatalf("Sync error = %v, want ErrSyncConflict", err)
+			}
+			if strings.Join(result.Conflicts, ",") != "notes.txt" {
+				t.Errorf("Conflicts = %v, want [notes.txt]", result.Conflicts)
+			}
+			if len(a.prompts) != tt.prompts {
+				t.Errorf("agent ran %d time(s), want %d", len(a.prompts), tt.prompts)
+			}
+			if op := c.git.OperationInProgress(ctx); op != "" {
+				t.Errorf("%s left in progress", op)
+			}
+
+			content, err := os.ReadFile(filepath.Join(tmpDir, "notes.txt"))
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			if string(content) != "one\ntask\nthree\n" {
+				t.Errorf("notes.txt = %q, want the task branch unchanged", content)
+			}
+		})
+	}
+}
+
+func TestSync_UpToDate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	c, _ := newSyncConductor(t, &resolvingAgent{})
+	ctx := context.Background()
+
+	if _, err := c.Sync(ctx, SyncOptions{Strategy: SyncMerge, ResolveConflicts: false}); !errors.Is(err, ErrSyncConflict) {
+		t.Fatalf("Sync error = %v, want ErrSyncConflict", err)
+	}
+	c.taskWork.Git.BaseBranch = "task/sync"
+
+	result, err := c.Sync(ctx, SyncOptions{})
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if !result.UpToDate {
+		t.Errorf("result = %+v, want up to date", result)
+	}
+}
+
+func TestParseSyncStrategy(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "", want: SyncRebase},
+		{name: "rebase", want: SyncRebase},
+		{name: "merge", want: SyncMerge},
+		{name: "squash", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSyncStrategy(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSyncStrategy(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseSyncStrategy(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
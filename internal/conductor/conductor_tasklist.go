@@ -0,0 +1,96 @@
+package conductor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// checklistPrompt asks the planner to map the source's open task list items
+// to specifications, so completing a specification can check them off on
+// the issue. It returns "" when the source has no open items.
+func checklistPrompt(sourceContent string) string {
+	var open []string
+	for _, item := range provider.ParseTaskListItems(sourceContent) {
+		if !item.Checked {
+			open = append(open, "- "+item.Text)
+		}
+	}
+	if len(open) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(`
+## Checklist
+The task source has these open checklist items:
+
+%s
+
+End each specification with a "## Checklist" section listing, one per line
+as "- <item>", the items it completes. Copy the item text exactly; leave the
+section out of specifications that complete no item.
+`, strings.Join(open, "\n"))
+}
+
+// syncSpecTaskList checks off the task list items of a completed
+// specification on the task's issue.
+func (c *Conductor) syncSpecTaskList(ctx context.Context, taskID string, number int) {
+	spec, err := c.workspace.ParseSpecification(taskID, number)
+	if err != nil {
+		c.logError(fmt.Errorf("read specification %d: %w", number, err))
+
+		return
+	}
+	c.syncTaskList(ctx, []*storage.Specification{spec})
+}
+
+// syncTaskList checks off the task list items mapped to specs on the task's
+// issue, when its provider can update task lists.
+func (c *Conductor) syncTaskList(ctx context.Context, specs []*storage.Specification) {
+	var items []string
+	for _, spec := range specs {
+		items = append(items, storage.ExtractChecklist(spec.Content)...)
+	}
+	if len(items) == 0 || c.activeTask.Ref == "" {
+		return
+	}
+
+	resolveOpts := provider.ResolveOptions{
+		DefaultProvider: c.defaultProvider(),
+	}
+	p, id, err := c.providers.Resolve(ctx, c.activeTask.Ref, c.providerConfig(c.referenceProvider(c.activeTask.Ref)), resolveOpts)
+	if err != nil {
+		return
+	}
+	updater, ok := p.(provider.TaskListUpdater)
+	if !ok {
+		return
+	}
+
+	for _, item := range items {
+		err := c.traceProvider(ctx, c.referenceProvider(c.activeTask.Ref), "update_task_list", func(ctx context.Context) error {
+			return updater.SetTaskListItem(ctx, id, item, true)
+		})
+		if err != nil {
+			c.logError(fmt.Errorf("check off %q: %w", item, err))
+
+			continue
+		}
+		c.logVerbosef("Checked off %q", item)
+	}
+}
+
+// syncFinishedTaskList checks off the task list items of every specification
+// when the task finishes.
+func (c *Conductor) syncFinishedTaskList(ctx context.Context) {
+	specs, err := c.workspace.ListSpecificationsWithStatus(c.activeTask.ID)
+	if err != nil {
+		c.logError(fmt.Errorf("list specifications: %w", err))
+
+		return
+	}
+	c.syncTaskList(ctx, specs)
+}
@@ -0,0 +1,79 @@
+package conductor
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+type stubTaskListProvider struct {
+	checked []string
+}
+
+func (p *stubTaskListProvider) Parse(input string) (string, error) {
+	return strings.TrimPrefix(input, "stub:"), nil
+}
+
+func (p *stubTaskListProvider) Match(input string) bool {
+	return strings.HasPrefix(input, "stub:")
+}
+
+func (p *stubTaskListProvider) SetTaskListItem(_ context.Context, workUnitID, text string, checked bool) error {
+	if workUnitID != "42" || !checked {
+		return nil
+	}
+	if text == "Unknown" {
+		return provider.ErrTaskListItemNotFound
+	}
+	p.checked = append(p.checked, text)
+
+	return nil
+}
+
+func TestChecklistPrompt(t *testing.T) {
+	if got := checklistPrompt("No checklist\n- [x] Already done\n"); got != "" {
+		t.Errorf("checklistPrompt(no open items) = %q, want empty", got)
+	}
+
+	got := checklistPrompt("Tasks:\n- [ ] Add login form\n- [x] Done\n* [ ] Write docs\n")
+	if !strings.Contains(got, "## Checklist") || !strings.Contains(got, "- Add login form\n- Write docs") {
+		t.Errorf("checklistPrompt() = %q", got)
+	}
+	if strings.Contains(got, "Done") {
+		t.Errorf("checklistPrompt() lists a checked item: %q", got)
+	}
+}
+
+func TestSyncTaskList(t *testing.T) {
+	c, err := New(WithWorkDir(t.TempDir()))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	stub := &stubTaskListProvider{}
+	info := provider.ProviderInfo{Name: "stub", Schemes: []string{"stub"}}
+	if err := c.GetProviderRegistry().Register(info, func(context.Context, provider.Config) (any, error) {
+		return stub, nil
+	}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	var errs []error
+	c.opts.OnError = func(err error) { errs = append(errs, err) }
+	c.activeTask = &storage.ActiveTask{ID: "t1", Ref: "stub:42"}
+
+	c.syncTaskList(context.Background(), []*storage.Specification{
+		{Number: 1, Content: "# Spec 1\n\n## Checklist\n- Add login form\n- Unknown\n"},
+		{Number: 2, Content: "# Spec 2\n\nNo checklist.\n"},
+	})
+
+	if !slices.Equal(stub.checked, []string{"Add login form"}) {
+		t.Errorf("checked items = %q", stub.checked)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `"Unknown"`) {
+		t.Errorf("errors = %v, want one for the unknown item", errs)
+	}
+}
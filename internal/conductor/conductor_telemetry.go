@@ -0,0 +1,91 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/telemetry"
+)
+
+// setupTelemetry enables span and metric export when configured.
+func (c *Conductor) setupTelemetry(cfg *storage.WorkspaceConfig) {
+	c.telemetry = telemetry.FromSettings(cfg.Telemetry)
+}
+
+// tracePhase runs a workflow phase inside a "mehr.<phase>" span and records
+// its duration and outcome. A pending question ends the phase with status
+// "waiting" rather than as a failure.
+func (c *Conductor) tracePhase(ctx context.Context, phase string, run func(context.Context) error) error {
+	ctx, span := c.telemetry.StartSpan(ctx, "mehr."+phase, telemetry.String("mehr.phase", phase))
+	start := time.Now()
+
+	err := run(ctx)
+
+	status := "ok"
+	spanErr := err
+	switch {
+	case errors.Is(err, ErrPendingQuestion):
+		status = "waiting"
+		spanErr = nil
+	case err != nil:
+		status = "error"
+	}
+
+	attrs := []telemetry.Attr{telemetry.String("mehr.phase", phase)}
+	c.telemetry.Add("mehr.phase.runs", 1, append(attrs, telemetry.String("status", status))...)
+	c.telemetry.Add("mehr.phase.duration", time.Since(start).Seconds(), attrs...)
+
+	if c.activeTask != nil {
+		span.SetAttributes(telemetry.String("mehr.task_id", c.activeTask.ID))
+	}
+	span.SetAttributes(telemetry.String("mehr.status", status))
+	span.End(spanErr)
+
+	return err
+}
+
+// traceProvider runs a provider call inside a "provider.<operation>" span
+// and counts it by provider, operation and outcome.
+func (c *Conductor) traceProvider(ctx context.Context, providerName, operation string, call func(context.Context) error) error {
+	attrs := []telemetry.Attr{
+		telemetry.String("mehr.provider", providerName),
+		telemetry.String("mehr.operation", operation),
+	}
+	ctx, span := c.telemetry.StartSpan(ctx, "provider."+operation, attrs...)
+
+	err := call(ctx)
+
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	c.telemetry.Add("mehr.provider.calls", 1, append(attrs, telemetry.String("status", status))...)
+	span.End(err)
+	c.audit(storage.AuditProvider, providerName+": "+operation, "", err)
+
+	return err
+}
+
+// countRetry records a retried phase attempt.
+func (c *Conductor) countRetry(phase string) {
+	c.telemetry.Add("mehr.retries", 1, telemetry.String("mehr.phase", phase))
+}
+
+// withTracing wraps a so that its runs are recorded for step.
+func (c *Conductor) withTracing(a agent.Agent, step string) agent.Agent {
+	return agent.WithTracing(a, c.telemetry, step)
+}
+
+// referenceProvider returns the provider scheme of a task reference,
+// falling back to the default provider.
+func (c *Conductor) referenceProvider(reference string) string {
+	if scheme, _, ok := strings.Cut(reference, ":"); ok && len(scheme) > 1 {
+		return scheme
+	}
+
+	return c.defaultProvider()
+}
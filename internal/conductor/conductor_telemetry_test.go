@@ -0,0 +1,61 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/telemetry"
+)
+
+func TestTracePhase(t *testing.T) {
+	var exported strings.Builder
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		exported.WriteString(r.URL.Path + " " + string(body) + "\n")
+	}))
+	t.Cleanup(srv.Close)
+
+	c := &Conductor{telemetry: telemetry.New(telemetry.Config{Endpoint: srv.URL})}
+
+	err := c.tracePhase(t.Context(), "planning", func(context.Context) error {
+		return ErrPendingQuestion
+	})
+	if !errors.Is(err, ErrPendingQuestion) {
+		t.Fatalf("tracePhase should return the phase error, got %v", err)
+	}
+
+	got := exported.String()
+	for _, want := range []string{`"name":"mehr.planning"`, `"stringValue":"waiting"`, `"mehr.phase.duration"`, `"code":1`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("export missing %s:\n%s", want, got)
+		}
+	}
+
+	exported.Reset()
+	_ = c.traceProvider(t.Context(), "github", "fetch", func(context.Context) error {
+		return errors.New("rate limited")
+	})
+	got = exported.String()
+	for _, want := range []string{`"name":"provider.fetch"`, `"mehr.provider.calls"`, `"message":"rate limited"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("export missing %s:\n%s", want, got)
+		}
+	}
+}
+
+func TestTracePhase_Disabled(t *testing.T) {
+	c := &Conductor{}
+	ran := false
+	if err := c.tracePhase(t.Context(), "review", func(context.Context) error {
+		ran = true
+
+		return nil
+	}); err != nil || !ran {
+		t.Errorf("tracePhase without telemetry should just run the phase (ran=%v, err=%v)", ran, err)
+	}
+}
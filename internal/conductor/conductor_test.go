@@ -6,14 +6,17 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/valksor/go-mehrhof/internal/agent"
 	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/naming"
 	"github.com/valksor/go-mehrhof/internal/provider"
 	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
 	"github.com/valksor/go-mehrhof/internal/workflow"
 )
 
@@ -61,6 +64,15 @@ func TestWithTimeout(t *testing.T) {
 	}
 }
 
+func TestWithLeaseTTL(t *testing.T) {
+	opts := DefaultOptions()
+	WithLeaseTTL(30 * time.Second)(&opts)
+
+	if opts.LeaseTTL != 30*time.Second {
+		t.Errorf("LeaseTTL = %v, want %v", opts.LeaseTTL, 30*time.Second)
+	}
+}
+
 func TestWithDryRun(t *testing.T) {
 	opts := DefaultOptions()
 	WithDryRun(true)(&opts)
@@ -854,6 +866,7 @@ func TestGeneratePRBody(t *testing.T) {
 		taskWork    *storage.TaskWork
 		specs       []*storage.Specification
 		diffStat    string
+		changes     []storage.FileSummary
 		wantContain []string
 	}{
 		{
@@ -921,6 +934,27 @@ func TestGeneratePRBody(t *testing.T) {
 				"2 files changed",
 			},
 		},
+		{
+			name: "with structural changes",
+			changes: []storage.FileSummary{
+				{
+					Path:     "internal/api/server.go",
+					Language: "Go",
+					Added:    []string{"func New"},
+					Modified: []string{"func (*Server) Run"},
+					API: []storage.APIChange{
+						{Change: "added", Symbol: "func New", After: "func New(cfg Config) *Server"},
+					},
+				},
+				{Path: "README.md", Operation: "update"},
+			},
+			wantContain: []string{
+				"## Structural Changes",
+				"- `internal/api/server.go`: added `func New`; modified `func (*Server) Run`",
+				"### Exported API Changes",
+				"- `internal/api`: added `func New(cfg Config) *Server`",
+			},
+		},
 		{
 			name: "spec content truncated",
 			taskWork: &storage.TaskWork{
@@ -948,7 +982,7 @@ func TestGeneratePRBody(t *testing.T) {
 			}
 			c.taskWork = tt.taskWork
 
-			got := c.generatePRBody(tt.specs, tt.diffStat)
+			got := c.generatePRBody(tt.specs, tt.diffStat, tt.changes)
 			for _, want := range tt.wantContain {
 				if !strings.Contains(got, want) {
 					t.Errorf("generatePRBody() missing %q in:\n%s", want, got)
@@ -1759,6 +1793,42 @@ func TestGetAgentForStep(t *testing.T) {
 	}
 }
 
+func TestGetAgentForStep_ResponseCache(t *testing.T) {
+	c, err := New(WithWorkDir(t.TempDir()), WithAgent("fresh-agent"), WithResponseCache(true))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := c.agents.Register(&testAgent{name: "fresh-agent"}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := c.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	tests := []struct {
+		step       workflow.Step
+		wantCached bool
+	}{
+		{step: workflow.StepPlanning, wantCached: true},
+		{step: workflow.StepReviewing, wantCached: true},
+		// Agents that change files must always run
+		{step: workflow.StepImplementing},
+		{step: workflow.StepResolving},
+		{step: workflow.StepDocumenting},
+	}
+	for _, tt := range tests {
+		t.Run(tt.step.String(), func(t *testing.T) {
+			got, err := c.GetAgentForStep(context.Background(), tt.step)
+			if err != nil {
+				t.Fatalf("GetAgentForStep: %v", err)
+			}
+			if _, cached := got.(*agent.CachedAgent); cached != tt.wantCached {
+				t.Errorf("cached = %v, want %v", cached, tt.wantCached)
+			}
+		})
+	}
+}
+
 // Test resolveNaming - external key resolution, template expansion.
 func TestResolveNaming(t *testing.T) {
 	tests := []struct {
@@ -1905,6 +1975,61 @@ func TestResolveNaming(t *testing.T) {
 	}
 }
 
+func TestResolveWorktreePath(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := filepath.Join(tmpDir, "repo")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	initGitRepo(t, repo)
+
+	c, err := New(WithWorkDir(repo))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if c.git, err = vcs.New(context.Background(), repo); err != nil {
+		t.Fatalf("vcs.New: %v", err)
+	}
+	if c.workspace, err = storage.OpenWorkspace(repo, nil); err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := c.workspace.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+
+	ni := &namingInfo{vars: naming.TemplateVars{Key: "FEAT-1", TaskID: "a1b2c3d4", Type: "feature", Slug: "add-cache"}}
+
+	// Default: ../repo-worktrees/<task-id>
+	got, err := c.resolveWorktreePath("a1b2c3d4", ni)
+	if err != nil {
+		t.Fatalf("resolveWorktreePath: %v", err)
+	}
+	if want := filepath.Join(filepath.Dir(c.git.Root()), "repo-worktrees", "a1b2c3d4"); got != want {
+		t.Errorf("default path = %q, want %q", got, want)
+	}
+
+	cfg, _ := c.workspace.LoadConfig()
+	cfg.Git.WorktreeDir = filepath.Join(tmpDir, "trees")
+	cfg.Git.WorktreePattern = "{type}/{key}-{slug}"
+	if err := c.workspace.SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	got, _ = c.resolveWorktreePath("a1b2c3d4", ni)
+	want := filepath.Join(tmpDir, "trees", "feature-FEAT-1-add-cache")
+	if got != want {
+		t.Errorf("configured path = %q, want %q", got, want)
+	}
+
+	// A taken directory gets the task ID appended
+	if err := os.MkdirAll(want, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if got, _ := c.resolveWorktreePath("a1b2c3d4", ni); got != want+"-a1b2c3d4" {
+		t.Errorf("taken path = %q, want %q", got, want+"-a1b2c3d4")
+	}
+}
+
 // Test buildWorkUnit - WorkUnit construction with specifications.
 func TestBuildWorkUnit_WithSpecs(t *testing.T) {
 	tests := []struct {
@@ -2240,3 +2365,139 @@ func TestWithSlugOverride(t *testing.T) {
 		t.Errorf("SlugOverride = %q, want %q", opts.SlugOverride, "custom-task-slug")
 	}
 }
+
+func TestAcquireLease(t *testing.T) {
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+
+	c := &Conductor{
+		workspace:  ws,
+		activeTask: &storage.ActiveTask{ID: "task1"},
+		opts:       DefaultOptions(),
+	}
+
+	_, release, err := c.acquireLease(context.Background())
+	if err != nil {
+		t.Fatalf("acquireLease: %v", err)
+	}
+	if lease, _ := ws.LoadTaskLease("task1"); lease == nil {
+		t.Error("lease should exist while held")
+	}
+
+	release(&err)
+	if err != nil {
+		t.Errorf("release error = %v", err)
+	}
+	if lease, _ := ws.LoadTaskLease("task1"); lease != nil {
+		t.Error("lease should be removed after release")
+	}
+}
+
+func TestAcquireLease_AutoPush(t *testing.T) {
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+	if _, err := ws.CreateWork("task1", storage.SourceInfo{Type: "file", Ref: "task.md"}); err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	cfg, err := ws.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	remote := t.TempDir()
+	cfg.Storage.Remote = &storage.RemoteStorageSettings{Type: "local", Path: remote, AutoPush: true}
+	if err := ws.SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	c := &Conductor{
+		workspace:  ws,
+		activeTask: &storage.ActiveTask{ID: "task1"},
+		opts:       DefaultOptions(),
+	}
+
+	_, release, err := c.acquireLease(context.Background())
+	if err != nil {
+		t.Fatalf("acquireLease: %v", err)
+	}
+	release(&err)
+
+	if _, err := os.Stat(filepath.Join(remote, "task1", "work.yaml")); err != nil {
+		t.Errorf("task not pushed on release: %v", err)
+	}
+}
+
+func TestAcquireLease_HeldByOther(t *testing.T) {
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+
+	// Another user on another host holds the lease.
+	lease := fmt.Sprintf("task_id: task1\nowner: alice\nhostname: elsewhere\npid: 4242\nexpires_at: %s\n",
+		time.Now().Add(time.Hour).Format(time.RFC3339))
+	if err := os.MkdirAll(ws.LocksDir(), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(ws.TaskLeasePath("task1"), []byte(lease), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	c := &Conductor{
+		workspace:  ws,
+		activeTask: &storage.ActiveTask{ID: "task1"},
+		opts:       DefaultOptions(),
+	}
+
+	if _, _, err := c.acquireLease(context.Background()); !errors.Is(err, storage.ErrLeaseHeld) {
+		t.Errorf("acquireLease error = %v, want ErrLeaseHeld", err)
+	}
+}
+
+func TestAcquireLease_Lost(t *testing.T) {
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+
+	opts := DefaultOptions()
+	opts.LeaseTTL = 300 * time.Millisecond
+	c := &Conductor{
+		workspace:  ws,
+		activeTask: &storage.ActiveTask{ID: "task1"},
+		opts:       opts,
+	}
+
+	ctx, release, err := c.acquireLease(context.Background())
+	if err != nil {
+		t.Fatalf("acquireLease: %v", err)
+	}
+
+	// Someone steals the lease while the operation runs.
+	lease := fmt.Sprintf("task_id: task1\nowner: alice\nhostname: elsewhere\npid: 4242\nexpires_at: %s\n",
+		time.Now().Add(time.Hour).Format(time.RFC3339))
+	if err := os.WriteFile(ws.TaskLeasePath("task1"), []byte(lease), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("operation context not cancelled after the lease was lost")
+	}
+
+	err = ctx.Err()
+	release(&err)
+	if !errors.Is(err, ErrLeaseLost) || !errors.Is(err, storage.ErrLeaseHeld) {
+		t.Errorf("release error = %v, want ErrLeaseLost", err)
+	}
+	if current, _ := ws.LoadTaskLease("task1"); current == nil || current.Owner != "alice" {
+		t.Error("the new holder's lease should be left alone")
+	}
+}
@@ -0,0 +1,79 @@
+package conductor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// logTaskTime logs the wall-clock time spent on the task, summed from its
+// agent sessions, to the task's provider when the provider's log_time setting
+// is on. Failures are only reported: the task is finished locally either way.
+func (c *Conductor) logTaskTime(ctx context.Context) {
+	if c.activeTask.Ref == "" {
+		return
+	}
+	scheme := c.referenceProvider(c.activeTask.Ref)
+	info, _, ok := c.providers.GetByScheme(scheme)
+	if !ok {
+		return
+	}
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil || !cfg.LogTimeEnabled(info.Name) {
+		return
+	}
+
+	sessions, err := c.workspace.ListSessions(c.activeTask.ID)
+	if err != nil {
+		c.logError(fmt.Errorf("list sessions for time log: %w", err))
+
+		return
+	}
+	times := storage.PhaseTimes(sessions)
+	total := storage.TotalTime(times)
+	if total < time.Minute {
+		return
+	}
+
+	resolveOpts := provider.ResolveOptions{
+		DefaultProvider: c.defaultProvider(),
+	}
+	p, id, err := c.providers.Resolve(ctx, c.activeTask.Ref, c.providerConfig(c.referenceProvider(c.activeTask.Ref)), resolveOpts)
+	if err != nil {
+		return
+	}
+	logger, ok := p.(provider.TimeLogger)
+	if !ok {
+		return
+	}
+
+	entry := provider.TimeEntry{
+		Duration: total,
+		Started:  firstSessionStart(sessions),
+		Comment:  "Time spent with mehrhof: " + storage.FormatPhaseTimes(times),
+	}
+	err = c.traceProvider(ctx, scheme, "log_time", func(ctx context.Context) error {
+		return logger.LogTime(ctx, id, entry)
+	})
+	if err != nil {
+		c.logError(fmt.Errorf("log time to provider: %w", err))
+
+		return
+	}
+	c.logVerbosef("Logged %s to %s", storage.FormatMinutes(total), info.Name)
+}
+
+// firstSessionStart returns when the earliest session started.
+func firstSessionStart(sessions []*storage.Session) time.Time {
+	var first time.Time
+	for _, s := range sessions {
+		if started := s.Metadata.StartedAt; !started.IsZero() && (first.IsZero() || started.Before(first)) {
+			first = started
+		}
+	}
+
+	return first
+}
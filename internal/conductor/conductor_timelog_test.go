@@ -0,0 +1,98 @@
+package conductor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+type stubTimeLogger struct {
+	entries []provider.TimeEntry
+}
+
+func (p *stubTimeLogger) Parse(input string) (string, error) {
+	return strings.TrimPrefix(input, "jira:"), nil
+}
+
+func (p *stubTimeLogger) Match(input string) bool {
+	return strings.HasPrefix(input, "jira:")
+}
+
+func (p *stubTimeLogger) LogTime(_ context.Context, workUnitID string, entry provider.TimeEntry) error {
+	if workUnitID == "PROJ-1" {
+		p.entries = append(p.entries, entry)
+	}
+
+	return nil
+}
+
+func TestLogTaskTime(t *testing.T) {
+	c, err := New(WithWorkDir(t.TempDir()), WithAgent("mock"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := c.GetAgentRegistry().Register(&mockAgent{name: "mock"}); err != nil {
+		t.Fatalf("Register agent: %v", err)
+	}
+	if err := c.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	stub := &stubTimeLogger{}
+	info := provider.ProviderInfo{Name: "jira", Schemes: []string{"jira"}}
+	if err := c.GetProviderRegistry().Register(info, func(context.Context, provider.Config) (any, error) {
+		return stub, nil
+	}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	if _, err := c.workspace.CreateWork("t1", storage.SourceInfo{Type: "jira", Ref: "jira:PROJ-1"}); err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	c.activeTask = &storage.ActiveTask{ID: "t1", Ref: "jira:PROJ-1"}
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	for _, s := range []struct {
+		typ           string
+		from, minutes int
+	}{{"planning", 0, 12}, {"implementing", 15, 65}} {
+		session, filename, err := c.workspace.CreateSession("t1", s.typ, "mock", s.typ)
+		if err != nil {
+			t.Fatalf("CreateSession: %v", err)
+		}
+		session.Metadata.StartedAt = start.Add(time.Duration(s.from) * time.Minute)
+		session.Metadata.EndedAt = session.Metadata.StartedAt.Add(time.Duration(s.minutes) * time.Minute)
+		if err := c.workspace.SaveSession("t1", filename, session); err != nil {
+			t.Fatalf("SaveSession: %v", err)
+		}
+	}
+
+	// Disabled by default
+	c.logTaskTime(context.Background())
+	if len(stub.entries) != 0 {
+		t.Fatalf("logged %d entries without log_time", len(stub.entries))
+	}
+
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	cfg.Jira = &storage.JiraSettings{LogTime: true}
+	if err := c.workspace.SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	c.logTaskTime(context.Background())
+	if len(stub.entries) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(stub.entries))
+	}
+	entry := stub.entries[0]
+	if entry.Duration != 77*time.Minute || !entry.Started.Equal(start) {
+		t.Errorf("entry = %+v, want 1h17m from %v", entry, start)
+	}
+	if want := "Time spent with mehrhof: planning 12m, implementing 1h5m"; entry.Comment != want {
+		t.Errorf("comment = %q, want %q", entry.Comment, want)
+	}
+}
@@ -0,0 +1,139 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// ErrPhaseTimeout is returned when a phase runs past its agent.phase_timeouts
+// deadline and agent.on_timeout does not pause or retry it.
+var ErrPhaseTimeout = errors.New("phase timed out")
+
+// phaseTimeout returns the deadline of a phase from agent.phase_timeouts, or
+// 0 when the phase has none.
+func (c *Conductor) phaseTimeout(step workflow.Step) time.Duration {
+	if c.workspace == nil {
+		return 0
+	}
+	cfg, err := c.workspace.LoadConfig()
+	if err != nil {
+		return 0
+	}
+
+	return time.Duration(cfg.Agent.PhaseTimeouts[string(step)]) * time.Second
+}
+
+// timeoutPolicy returns agent.on_timeout, defaulting to fail.
+func (c *Conductor) timeoutPolicy() string {
+	if c.workspace != nil {
+		if cfg, err := c.workspace.LoadConfig(); err == nil && cfg.Agent.OnTimeout != "" {
+			return cfg.Agent.OnTimeout
+		}
+	}
+
+	return storage.OnTimeoutFail
+}
+
+// withPhaseTimeout bounds run by the phase's deadline and applies
+// agent.on_timeout when it passes: fail returns ErrPhaseTimeout, pause
+// checkpoints the partial work and leaves a pending question, and retry
+// re-enters the phase once with minimal context. enter puts the task back
+// into the phase, which a timed out run leaves.
+func (c *Conductor) withPhaseTimeout(step workflow.Step, enter, run func(context.Context) error) func(context.Context) error {
+	return func(ctx context.Context) error {
+		timeout := c.phaseTimeout(step)
+		if timeout <= 0 {
+			return run(ctx)
+		}
+
+		timedOut, err := runWithDeadline(ctx, timeout, run)
+		if !timedOut {
+			return err
+		}
+
+		switch c.timeoutPolicy() {
+		case storage.OnTimeoutPause:
+			return c.pauseAfterTimeout(ctx, step, timeout)
+		case storage.OnTimeoutRetry:
+			c.publishProgress(fmt.Sprintf("%s timed out after %s, retrying with minimal context...", step, timeout), 0)
+			if err := enter(ctx); err != nil {
+				return err
+			}
+
+			mode := c.opts.ContextMode
+			c.opts.ContextMode = ContextMinimal
+			timedOut, err = runWithDeadline(ctx, timeout, run)
+			c.opts.ContextMode = mode
+			if !timedOut {
+				return err
+			}
+		}
+
+		return fmt.Errorf("%w: %s after %s", ErrPhaseTimeout, step, timeout)
+	}
+}
+
+// runWithDeadline runs fn with a deadline. timedOut is true when the deadline
+// passed, as opposed to ctx itself being canceled.
+func runWithDeadline(ctx context.Context, timeout time.Duration, fn func(context.Context) error) (bool, error) {
+	phaseCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := fn(phaseCtx)
+
+	return errors.Is(phaseCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil, err
+}
+
+// pausingOnTimeout reports whether ctx ended at a phase deadline that pauses
+// the task; the partial work is then kept for a checkpoint.
+func (c *Conductor) pausingOnTimeout(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded) && c.timeoutPolicy() == storage.OnTimeoutPause
+}
+
+// pauseAfterTimeout checkpoints what a timed out phase left behind and asks
+// the user how to continue. The task stays idle, so running the phase again
+// resumes from the checkpoint with the answer in the notes.
+func (c *Conductor) pauseAfterTimeout(ctx context.Context, step workflow.Step, timeout time.Duration) error {
+	taskID := c.activeTask.ID
+
+	saved := "No changes were made."
+	if event := c.createCheckpointIfNeeded(ctx, taskID, fmt.Sprintf("Partial %s before timeout", step)); event != nil {
+		c.eventBus.PublishRaw(*event)
+		if number, ok := event.Data["checkpoint"].(int); ok {
+			saved = fmt.Sprintf("The partial work is in checkpoint %d.", number)
+		}
+	}
+
+	question := &storage.PendingQuestion{
+		Question: fmt.Sprintf("The %s phase timed out after %s. %s How should it continue?", step, timeout, saved),
+		Options: []storage.QuestionOption{
+			{Label: "Continue", Description: "Run the phase again from where it stopped"},
+			{Label: "Narrow the scope", Description: "Describe which part to finish first"},
+		},
+		Phase:   string(step),
+		AskedAt: time.Now(),
+	}
+	if err := c.workspace.SavePendingQuestion(taskID, question); err != nil {
+		c.logError(fmt.Errorf("save pending question: %w", err))
+	}
+
+	title := ""
+	if c.taskWork != nil {
+		title = c.taskWork.Metadata.Title
+	}
+	c.eventBus.Publish(events.QuestionPendingEvent{
+		TaskID:   taskID,
+		Title:    title,
+		Phase:    question.Phase,
+		Question: question.Question,
+		Options:  questionOptionLabels(question.Options),
+	})
+
+	return fmt.Errorf("%w: %s timed out after %s", ErrPendingQuestion, step, timeout)
+}
@@ -0,0 +1,112 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+func TestWithPhaseTimeout(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		wantErr    error
+		wantRuns   int
+		wantEnters int
+	}{
+		{name: "fail", policy: storage.OnTimeoutFail, wantErr: ErrPhaseTimeout, wantRuns: 1},
+		{name: "retry", policy: storage.OnTimeoutRetry, wantRuns: 2, wantEnters: 1},
+		{name: "pause", policy: storage.OnTimeoutPause, wantErr: ErrPendingQuestion, wantRuns: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(WithWorkDir(t.TempDir()))
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			if err := c.GetAgentRegistry().Register(&testAgent{name: "claude"}); err != nil {
+				t.Fatalf("Register agent: %v", err)
+			}
+			if err := c.Initialize(context.Background()); err != nil {
+				t.Fatalf("Initialize: %v", err)
+			}
+			cfg, err := c.workspace.LoadConfig()
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			cfg.Agent.PhaseTimeouts = map[string]int{"reviewing": 1}
+			cfg.Agent.OnTimeout = tt.policy
+			if err := c.workspace.SaveConfig(cfg); err != nil {
+				t.Fatalf("SaveConfig: %v", err)
+			}
+			if _, err := c.workspace.CreateWork("slow", storage.SourceInfo{Type: "file", Ref: "task.md"}); err != nil {
+				t.Fatalf("CreateWork: %v", err)
+			}
+			c.activeTask = &storage.ActiveTask{ID: "slow"}
+
+			runs, enters := 0, 0
+			var modes []ContextMode
+			run := func(ctx context.Context) error {
+				runs++
+				modes = append(modes, c.contextMode())
+				if runs == 1 {
+					<-ctx.Done()
+
+					return ctx.Err()
+				}
+
+				return nil
+			}
+			enter := func(context.Context) error {
+				enters++
+
+				return nil
+			}
+
+			err = c.withPhaseTimeout(workflow.StepReviewing, enter, run)(context.Background())
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if runs != tt.wantRuns || enters != tt.wantEnters {
+				t.Errorf("runs = %d, enters = %d, want %d and %d", runs, enters, tt.wantRuns, tt.wantEnters)
+			}
+
+			switch tt.policy {
+			case storage.OnTimeoutRetry:
+				if modes[1] != ContextMinimal || c.opts.ContextMode != "" {
+					t.Errorf("retry context = %q, restored to %q", modes[1], c.opts.ContextMode)
+				}
+			case storage.OnTimeoutPause:
+				q, err := c.workspace.LoadPendingQuestion("slow")
+				if err != nil {
+					t.Fatalf("LoadPendingQuestion: %v", err)
+				}
+				if q.Phase != "reviewing" {
+					t.Errorf("question phase = %q, want reviewing", q.Phase)
+				}
+			}
+		})
+	}
+}
+
+func TestWithPhaseTimeout_NoDeadline(t *testing.T) {
+	c, err := New(WithWorkDir(t.TempDir()))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	run := func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); ok {
+			t.Error("phase without agent.phase_timeouts got a deadline")
+		}
+
+		return nil
+	}
+	if err := c.withPhaseTimeout(workflow.StepPlanning, nil, run)(context.Background()); err != nil {
+		t.Errorf("error = %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package conductor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// recordUsage adds an agent call's usage to the task totals in work.yaml and
+// to the per-call usage ledger used for cost exports.
+func (c *Conductor) recordUsage(taskID, usageStep string, step workflow.Step, agentInst agent.Agent, usage *agent.UsageStats) {
+	if usage == nil {
+		return
+	}
+
+	if err := c.workspace.AddUsage(taskID, usageStep,
+		usage.InputTokens,
+		usage.OutputTokens,
+		usage.CachedTokens,
+		usage.CostUSD,
+	); err != nil {
+		c.logError(fmt.Errorf("record %s usage: %w", usageStep, err))
+	}
+
+	record := storage.UsageRecord{
+		Timestamp:    time.Now(),
+		Step:         usageStep,
+		Agent:        agentInst.Name(),
+		Model:        c.stepModel(step),
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		CachedTokens: usage.CachedTokens,
+		CostUSD:      usage.CostUSD,
+	}
+	if err := c.workspace.AppendUsageRecord(taskID, record); err != nil {
+		c.logError(fmt.Errorf("append usage record: %w", err))
+	}
+}
+
+// stepModel returns the model configured for a step's agent through --model
+// args or a *_MODEL env var, falling back to the task-level agent settings.
+func (c *Conductor) stepModel(step workflow.Step) string {
+	if c.taskWork == nil {
+		return ""
+	}
+
+	info := c.taskWork.Agent
+	if stepInfo, ok := info.Steps[step.String()]; ok {
+		if model := modelFromAgentConfig(stepInfo.Args, stepInfo.InlineEnv); model != "" {
+			return model
+		}
+	}
+
+	return modelFromAgentConfig(info.Args, info.InlineEnv)
+}
+
+// modelFromAgentConfig extracts a model name from agent CLI args or env vars.
+func modelFromAgentConfig(args []string, env map[string]string) string {
+	for i, arg := range args {
+		if model, ok := strings.CutPrefix(arg, "--model="); ok {
+			return model
+		}
+		if arg == "--model" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+
+	for key, value := range env {
+		if strings.HasSuffix(strings.ToUpper(key), "_MODEL") && value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,57 @@
+package conductor
+
+import (
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+func TestModelFromAgentConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want string
+	}{
+		{name: "none", want: ""},
+		{name: "flag", args: []string{"--verbose", "--model", "opus"}, want: "opus"},
+		{name: "flag with equals", args: []string{"--model=sonnet"}, want: "sonnet"},
+		{name: "dangling flag", args: []string{"--model"}, want: ""},
+		{name: "env", env: map[string]string{"ANTHROPIC_MODEL": "glm-4.6", "OTHER": "x"}, want: "glm-4.6"},
+		{name: "args win over env", args: []string{"--model", "opus"}, env: map[string]string{"ANTHROPIC_MODEL": "glm"}, want: "opus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := modelFromAgentConfig(tt.args, tt.env); got != tt.want {
+				t.Errorf("modelFromAgentConfig() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStepModel(t *testing.T) {
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if got := c.stepModel(workflow.StepPlanning); got != "" {
+		t.Errorf("stepModel() without task = %q, want empty", got)
+	}
+
+	c.taskWork = &storage.TaskWork{Agent: storage.AgentInfo{
+		Args: []string{"--model", "sonnet"},
+		Steps: map[string]storage.StepAgentInfo{
+			"planning": {Name: "claude", Args: []string{"--model", "opus"}},
+		},
+	}}
+
+	if got := c.stepModel(workflow.StepPlanning); got != "opus" {
+		t.Errorf("stepModel(planning) = %q, want opus", got)
+	}
+	if got := c.stepModel(workflow.StepImplementing); got != "sonnet" {
+		t.Errorf("stepModel(implementing) = %q, want task-level sonnet", got)
+	}
+}
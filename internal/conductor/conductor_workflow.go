@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/valksor/go-mehrhof/internal/events"
 	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/provider/httpclient"
+	"github.com/valksor/go-mehrhof/internal/vcs"
 	"github.com/valksor/go-mehrhof/internal/workflow"
 )
 
@@ -98,7 +101,7 @@ func (c *Conductor) Review(ctx context.Context) error {
 }
 
 // Undo reverts to the previous checkpoint.
-func (c *Conductor) Undo(ctx context.Context) error {
+func (c *Conductor) Undo(ctx context.Context) (err error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -106,8 +109,14 @@ func (c *Conductor) Undo(ctx context.Context) error {
 		return errors.New("no active task")
 	}
 
-	if c.git == nil {
-		return errors.New("git not available")
+	ctx, release, err := c.acquireLease(ctx)
+	if err != nil {
+		return err
+	}
+	defer release(&err)
+
+	if err := vcs.Require(c.GetVCS(), vcs.CapCheckpoints, "undo"); err != nil {
+		return err
 	}
 
 	taskID := c.activeTask.ID
@@ -131,6 +140,7 @@ func (c *Conductor) Undo(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("git undo: %w", err)
 	}
+	c.syncRepoCheckpoints(ctx, taskID, checkpoint.Number)
 
 	// Publish event
 	c.eventBus.PublishRaw(events.Event{
@@ -149,7 +159,7 @@ func (c *Conductor) Undo(ctx context.Context) error {
 }
 
 // Redo moves forward to the next checkpoint.
-func (c *Conductor) Redo(ctx context.Context) error {
+func (c *Conductor) Redo(ctx context.Context) (err error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -157,8 +167,14 @@ func (c *Conductor) Redo(ctx context.Context) error {
 		return errors.New("no active task")
 	}
 
-	if c.git == nil {
-		return errors.New("git not available")
+	ctx, release, err := c.acquireLease(ctx)
+	if err != nil {
+		return err
+	}
+	defer release(&err)
+
+	if err := vcs.Require(c.GetVCS(), vcs.CapCheckpoints, "redo"); err != nil {
+		return err
 	}
 
 	taskID := c.activeTask.ID
@@ -182,6 +198,7 @@ func (c *Conductor) Redo(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("git redo: %w", err)
 	}
+	c.syncRepoCheckpoints(ctx, taskID, checkpoint.Number)
 
 	// Publish event
 	c.eventBus.PublishRaw(events.Event{
@@ -201,6 +218,12 @@ func (c *Conductor) Redo(ctx context.Context) error {
 
 // Finish completes the task.
 func (c *Conductor) Finish(ctx context.Context, opts FinishOptions) error {
+	return c.tracePhase(ctx, "finish", func(ctx context.Context) error {
+		return c.finish(ctx, opts)
+	})
+}
+
+func (c *Conductor) finish(ctx context.Context, opts FinishOptions) (err error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -208,13 +231,65 @@ func (c *Conductor) Finish(ctx context.Context, opts FinishOptions) error {
 		return errors.New("no active task")
 	}
 
+	ctx, release, err := c.acquireLease(ctx)
+	if err != nil {
+		return err
+	}
+	defer release(&err)
+
+	if err := c.checkReviewGate(opts); err != nil {
+		return err
+	}
+
+	// Bring the base branch in first, so the merge or PR is conflict-free
+	sync := c.SyncBeforeFinish()
+	if opts.Sync != nil {
+		sync = *opts.Sync
+	}
+	if sync && c.git != nil && c.activeTask.UseGit && c.activeTask.Branch != "" {
+		result, err := c.syncBranch(ctx, SyncOptions{ResolveConflicts: true})
+		if err != nil {
+			return fmt.Errorf("sync before finish: %w", err)
+		}
+		if !result.UpToDate {
+			c.logVerbosef("Synced %s with %s (%s)", c.activeTask.Branch, result.BaseRef, result.Strategy)
+		}
+	}
+
+	// Changelog entry, committed before the merge or PR so it is part of it
+	if c.changelogOnFinish(opts) {
+		path, err := c.writeChangelog(ctx)
+		if err != nil {
+			c.logError(fmt.Errorf("changelog entry: %w", err))
+		} else {
+			c.publishProgress("Changelog entry written to "+path, 0)
+		}
+	}
+
+	finishInfo := provider.FinishInfo{Branch: c.activeTask.Branch}
+
+	// A protected target branch only takes changes through a pull request
+	hasBranch := c.git != nil && c.activeTask.UseGit && c.activeTask.Branch != ""
+	supportsPR := c.providerSupportsPR(ctx)
+	var protectErr error
+	if hasBranch && (opts.ForceMerge || !supportsPR) {
+		protectErr = c.checkTargetProtection(ctx, opts.TargetBranch)
+	}
+	if opts.ForceMerge && protectErr != nil {
+		if !supportsPR {
+			return protectErr
+		}
+		c.publishProgress(protectErr.Error()+"; opening a pull request instead", 0)
+		opts.ForceMerge = false
+	}
+
 	// Determine action based on flags and provider support
 	if opts.ForceMerge {
 		// User explicitly requested local merge
 		if err := c.finishWithMerge(ctx, opts); err != nil {
 			return err
 		}
-	} else if c.providerSupportsPR(ctx) {
+	} else if supportsPR {
 		// Provider supports PR, create one by default
 		prResult, err := c.finishWithPR(ctx, opts)
 		if err != nil {
@@ -223,12 +298,21 @@ func (c *Conductor) Finish(ctx context.Context, opts FinishOptions) error {
 		// Store PR info for later reference
 		if prResult != nil {
 			c.logVerbosef("Created PR #%d: %s", prResult.Number, prResult.URL)
+			finishInfo.PullRequestURL = prResult.URL
+
+			// Push attached repositories and open their PRs
+			if err := c.openRepoPRs(ctx, opts, prResult); err != nil {
+				return err
+			}
 		}
-	} else if c.git != nil && c.activeTask.UseGit && c.activeTask.Branch != "" {
+	} else if hasBranch {
 		// Provider doesn't support PR, ask user what to do
 		action := c.askUserFinishAction()
 		switch action {
 		case "merge":
+			if protectErr != nil {
+				return protectErr
+			}
 			if err := c.finishWithMerge(ctx, opts); err != nil {
 				return err
 			}
@@ -248,11 +332,26 @@ func (c *Conductor) Finish(ctx context.Context, opts FinishOptions) error {
 	if err := c.workspace.SaveActiveTask(c.activeTask); err != nil {
 		c.logError(fmt.Errorf("save active task: %w", err))
 	}
+	if c.taskWork != nil {
+		// Counts toward the parent's progress for tasks split from another
+		c.taskWork.Metadata.FinishedAt = time.Now()
+		if err := c.workspace.SaveWork(c.taskWork); err != nil {
+			c.logError(fmt.Errorf("save work: %w", err))
+		}
+	}
+	c.notifyProviderFinished(ctx, finishInfo)
+	c.logTaskTime(ctx)
+	c.syncFinishedTaskList(ctx)
 
 	// Dispatch finish event
 	if err := c.machine.Dispatch(ctx, workflow.EventFinish); err != nil {
 		return fmt.Errorf("finish workflow: %w", err)
 	}
+	finished := events.TaskFinishedEvent{TaskID: c.activeTask.ID}
+	if c.taskWork != nil {
+		finished.Title = c.taskWork.Metadata.Title
+	}
+	c.eventBus.Publish(finished)
 
 	// Clear active task
 	if err := c.workspace.ClearActiveTask(); err != nil {
@@ -324,11 +423,11 @@ func (c *Conductor) onStateChanged(e events.Event) {
 
 // countCheckpoints returns the number of checkpoints for current task.
 func (c *Conductor) countCheckpoints() int {
-	if c.activeTask == nil || c.git == nil {
+	if c.activeTask == nil {
 		return 0
 	}
 	// Use background context since this is called from Status() which doesn't take context
-	checkpoints, err := c.git.ListCheckpoints(context.Background(), c.activeTask.ID)
+	checkpoints, err := c.GetVCS().ListCheckpoints(context.Background(), c.activeTask.ID)
 	if err != nil {
 		return 0
 	}
@@ -351,6 +450,16 @@ func (c *Conductor) publishProgress(message string, percent int) {
 	}
 }
 
+// publishRateLimitWait reports a provider request waiting for its rate limit.
+func (c *Conductor) publishRateLimitWait(wait httpclient.RateLimitWait) {
+	c.eventBus.Publish(events.RateLimitedEvent{
+		Host:    wait.Host,
+		Wait:    wait.Wait,
+		Reason:  wait.Reason,
+		Attempt: wait.Attempt,
+	})
+}
+
 // finishWithMerge performs a local merge operation.
 func (c *Conductor) finishWithMerge(ctx context.Context, opts FinishOptions) error {
 	// Handle git merge operations if applicable
@@ -358,13 +467,31 @@ func (c *Conductor) finishWithMerge(ctx context.Context, opts FinishOptions) err
 		return errors.New("git not available or no branch associated with task")
 	}
 
-	if err := c.performMerge(ctx, opts); err != nil {
+	err := c.performMerge(ctx, opts)
+	if errors.Is(err, errMergeConflict) {
+		// Resolve the conflicts on the task branch by syncing it with the
+		// target, then merge again
+		target := c.resolveTargetBranch(ctx, opts.TargetBranch)
+		if _, syncErr := c.syncBranch(ctx, SyncOptions{BaseRef: target, ResolveConflicts: true}); syncErr != nil {
+			return fmt.Errorf("%w; resolving them failed: %w", err, syncErr)
+		}
+		err = c.performMerge(ctx, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Merge attached repositories the same way
+	if err := c.mergeRepos(ctx, opts); err != nil {
 		return err
 	}
 
 	// Push if requested
 	if opts.PushAfter {
 		targetBranch := c.resolveTargetBranch(ctx, opts.TargetBranch)
+		if err := c.checkPush(ctx, c.git, "origin/"+targetBranch, targetBranch); err != nil {
+			return err
+		}
 		if err := c.git.PushBranch(ctx, targetBranch, "origin", false); err != nil {
 			return fmt.Errorf("push: %w", err)
 		}
@@ -384,9 +511,9 @@ func (c *Conductor) providerSupportsPR(ctx context.Context) bool {
 
 	// Resolve provider from the stored reference
 	resolveOpts := provider.ResolveOptions{
-		DefaultProvider: c.opts.DefaultProvider,
+		DefaultProvider: c.defaultProvider(),
 	}
-	p, _, err := c.providers.Resolve(ctx, c.activeTask.Ref, provider.Config{}, resolveOpts)
+	p, _, err := c.providers.Resolve(ctx, c.activeTask.Ref, c.providerConfig(c.referenceProvider(c.activeTask.Ref)), resolveOpts)
 	if err != nil {
 		return false
 	}
@@ -397,6 +524,34 @@ func (c *Conductor) providerSupportsPR(ctx context.Context) bool {
 	return ok
 }
 
+// notifyProviderFinished lets the task's provider write back to its work
+// unit, e.g. to set its status to done and link the branch or PR. Failures are only reported: the task
+// is finished locally either way.
+func (c *Conductor) notifyProviderFinished(ctx context.Context, info provider.FinishInfo) {
+	if c.activeTask.Ref == "" {
+		return
+	}
+
+	resolveOpts := provider.ResolveOptions{
+		DefaultProvider: c.defaultProvider(),
+	}
+	p, id, err := c.providers.Resolve(ctx, c.activeTask.Ref, c.providerConfig(c.referenceProvider(c.activeTask.Ref)), resolveOpts)
+	if err != nil {
+		return
+	}
+	handler, ok := p.(provider.FinishHandler)
+	if !ok {
+		return
+	}
+
+	err = c.traceProvider(ctx, c.referenceProvider(c.activeTask.Ref), "finish", func(ctx context.Context) error {
+		return handler.OnTaskFinished(ctx, id, info)
+	})
+	if err != nil {
+		c.logError(fmt.Errorf("update provider on finish: %w", err))
+	}
+}
+
 // askUserFinishAction prompts the user to choose an action when PR is not supported.
 func (c *Conductor) askUserFinishAction() string {
 	// For non-interactive use (auto mode), default to "done"
@@ -0,0 +1,172 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+)
+
+// ErrEditConflict is returned when the user aborts an implementation run after
+// editing files the agent is also working on.
+var ErrEditConflict = errors.New("implementation aborted: files were edited during the agent run")
+
+// fileStamp identifies a version of a file by modification time and size.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+
+	return fileStamp{modTime: info.ModTime(), size: info.Size(), exists: true}
+}
+
+// editWatcher detects manual edits to files the agent is touching during an
+// implementation run. It is checked at safe points: between agent events and
+// before agent file changes are applied.
+type editWatcher struct {
+	root    string
+	started time.Time
+	tracked map[string]fileStamp // Files the agent touched, with the last accepted version
+	pending map[string]bool      // Files the agent is about to write
+}
+
+func newEditWatcher(root string) *editWatcher {
+	return &editWatcher{
+		root:    root,
+		started: time.Now(),
+		tracked: make(map[string]fileStamp),
+		pending: make(map[string]bool),
+	}
+}
+
+// observe tracks files referenced by agent tool calls. Files the agent writes
+// are marked pending so the agent's own change is not reported as a conflict.
+func (w *editWatcher) observe(event agent.Event) {
+	if event.ToolCall == nil {
+		return
+	}
+
+	var path string
+	for _, key := range []string{"file_path", "path", "notebook_path"} {
+		if p, ok := event.ToolCall.Input[key].(string); ok && p != "" {
+			path = p
+
+			break
+		}
+	}
+	if path == "" {
+		return
+	}
+	path = w.abs(path)
+
+	if _, ok := w.tracked[path]; !ok {
+		w.tracked[path] = statFile(path)
+	}
+	if isWriteTool(event.ToolCall.Name) {
+		w.pending[path] = true
+	}
+}
+
+// conflicts returns tracked files changed by someone other than the agent
+// since they were last checked. Each change is reported once.
+func (w *editWatcher) conflicts() []string {
+	var changed []string
+	for path, last := range w.tracked {
+		current := statFile(path)
+		if current == last {
+			continue
+		}
+		w.tracked[path] = current
+
+		if w.pending[path] {
+			// The agent's own write
+			delete(w.pending, path)
+
+			continue
+		}
+		changed = append(changed, w.rel(path))
+	}
+	slices.Sort(changed)
+
+	return changed
+}
+
+// conflictsIn returns files about to be written that were edited since the run
+// started without the agent touching them through a tool.
+func (w *editWatcher) conflictsIn(files []agent.FileChange) []string {
+	changed := w.conflicts()
+	for _, fc := range files {
+		path := w.abs(fc.Path)
+		if _, ok := w.tracked[path]; ok {
+			continue
+		}
+		if stamp := statFile(path); stamp.exists && stamp.modTime.After(w.started) {
+			changed = append(changed, w.rel(path))
+		}
+	}
+	slices.Sort(changed)
+
+	return slices.Compact(changed)
+}
+
+func (w *editWatcher) abs(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+
+	return filepath.Join(w.root, path)
+}
+
+func (w *editWatcher) rel(path string) string {
+	if rel, err := filepath.Rel(w.root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+
+	return path
+}
+
+// isWriteTool reports whether an agent tool modifies the file it references.
+func isWriteTool(name string) bool {
+	switch name {
+	case "Write", "Edit", "MultiEdit", "NotebookEdit":
+		return true
+	}
+
+	return false
+}
+
+// pauseForEdits pauses the run until the user confirms it should resume.
+// Without a confirmation callback the run continues after a warning.
+func (c *Conductor) pauseForEdits(ctx context.Context, files []string) error {
+	c.publishProgress("Paused: you edited "+strings.Join(files, ", "), 0)
+
+	if c.opts.OnEditConflict == nil {
+		c.logError(fmt.Errorf("files edited during agent run: %s", strings.Join(files, ", ")))
+
+		return nil
+	}
+
+	if !c.opts.OnEditConflict(files) {
+		return ErrEditConflict
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.publishProgress("Resuming implementation...", 0)
+
+	return nil
+}
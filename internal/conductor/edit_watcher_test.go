@@ -0,0 +1,116 @@
+package conductor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+)
+
+// touch writes content to path and moves its modification time forward so
+// the change is visible regardless of filesystem timestamp resolution.
+func touch(t *testing.T, path, content string, offset time.Duration) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	mtime := time.Now().Add(offset)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+}
+
+func toolEvent(name, path string) agent.Event {
+	return agent.Event{ToolCall: &agent.ToolCall{Name: name, Input: map[string]any{"file_path": path}}}
+}
+
+func TestEditWatcher_Conflicts(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	touch(t, path, "package main\n", -time.Hour)
+
+	w := newEditWatcher(root)
+	w.observe(toolEvent("Read", "main.go"))
+	if got := w.conflicts(); len(got) != 0 {
+		t.Fatalf("conflicts() before edits = %v", got)
+	}
+
+	// The agent's own write is not a conflict
+	w.observe(toolEvent("Edit", path))
+	touch(t, path, "package main\n\nfunc main() {}\n", time.Minute)
+	if got := w.conflicts(); len(got) != 0 {
+		t.Errorf("conflicts() after agent write = %v", got)
+	}
+
+	// A manual edit is reported once
+	touch(t, path, "package main // edited\n", 2*time.Minute)
+	if got := w.conflicts(); !slices.Equal(got, []string{"main.go"}) {
+		t.Errorf("conflicts() after manual edit = %v, want [main.go]", got)
+	}
+	if got := w.conflicts(); len(got) != 0 {
+		t.Errorf("conflicts() reported twice: %v", got)
+	}
+}
+
+func TestEditWatcher_ConflictsIn(t *testing.T) {
+	root := t.TempDir()
+	old := filepath.Join(root, "old.go")
+	edited := filepath.Join(root, "edited.go")
+	touch(t, old, "old", -time.Hour)
+
+	w := newEditWatcher(root)
+	touch(t, edited, "edited", time.Minute)
+
+	got := w.conflictsIn([]agent.FileChange{{Path: "old.go"}, {Path: "edited.go"}, {Path: "new.go"}})
+	if !slices.Equal(got, []string{"edited.go"}) {
+		t.Errorf("conflictsIn() = %v, want [edited.go]", got)
+	}
+}
+
+func TestPauseForEdits(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("no callback continues", func(t *testing.T) {
+		c, err := New()
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		if err := c.pauseForEdits(ctx, []string{"main.go"}); err != nil {
+			t.Errorf("pauseForEdits() = %v, want nil", err)
+		}
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		var seen []string
+		c, err := New(WithEditConflictCallback(func(files []string) bool {
+			seen = files
+
+			return true
+		}))
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		if err := c.pauseForEdits(ctx, []string{"main.go"}); err != nil {
+			t.Errorf("pauseForEdits() = %v, want nil", err)
+		}
+		if !slices.Equal(seen, []string{"main.go"}) {
+			t.Errorf("callback files = %v", seen)
+		}
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		c, err := New(WithEditConflictCallback(func([]string) bool { return false }))
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		if err := c.pauseForEdits(ctx, []string{"main.go"}); !errors.Is(err, ErrEditConflict) {
+			t.Errorf("pauseForEdits() = %v, want ErrEditConflict", err)
+		}
+	})
+}
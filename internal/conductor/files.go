@@ -2,19 +2,151 @@ package conductor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/valksor/go-mehrhof/internal/agent"
 	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
 )
 
 // DeleteFileSentinel is a special marker that indicates a file should be deleted
 // This provides an alternative to setting operation: delete in YAML blocks.
 const DeleteFileSentinel = "__DELETE_FILE__"
 
+// ErrInvalidFilePath is returned when an agent file change names a path that
+// cannot be written safely.
+var ErrInvalidFilePath = errors.New("invalid file path")
+
+// windowsDrivePattern matches a slash-converted Windows absolute path.
+var windowsDrivePattern = regexp.MustCompile(`^[A-Za-z]:/`)
+
+// normalizeFilePath turns an agent-emitted path into a clean, slash-separated
+// path relative to root: backslashes become slashes and absolute paths inside
+// root are made relative. Empty paths, absolute paths elsewhere and paths into
+// .git are rejected. Escaping the root is left to validatePathInWorkspace,
+// which knows about attached repositories.
+func normalizeFilePath(p, root string) (string, error) {
+	original := p
+	p = strings.ReplaceAll(strings.TrimSpace(p), "\\", "/")
+	if p == "" {
+		return "", fmt.Errorf("%w: empty path", ErrInvalidFilePath)
+	}
+	if strings.ContainsRune(p, 0) {
+		return "", fmt.Errorf("%w %q: contains a NUL byte", ErrInvalidFilePath, original)
+	}
+
+	if strings.HasPrefix(p, "/") || windowsDrivePattern.MatchString(p) {
+		rel, ok := relativeToRoot(p, root)
+		if !ok {
+			return "", fmt.Errorf("%w %q: absolute path outside the repository %s; use a path relative to the repository root", ErrInvalidFilePath, original, root)
+		}
+		p = rel
+	}
+
+	p = path.Clean(p)
+	if p == "." {
+		return "", fmt.Errorf("%w %q: names the repository root, not a file", ErrInvalidFilePath, original)
+	}
+	if first, _, _ := strings.Cut(p, "/"); first == ".git" {
+		return "", fmt.Errorf("%w %q: refusing to write inside .git", ErrInvalidFilePath, original)
+	}
+
+	return p, nil
+}
+
+// relativeToRoot returns the slash-separated absolute path p relative to
+// root, reporting false when p is not inside root. Windows paths compare
+// case-insensitively.
+func relativeToRoot(p, root string) (string, bool) {
+	root = strings.TrimSuffix(strings.ReplaceAll(root, "\\", "/"), "/")
+	if len(p) < len(root) {
+		return "", false
+	}
+
+	prefix := p[:len(root)]
+	if prefix != root && (!windowsDrivePattern.MatchString(p) || !strings.EqualFold(prefix, root)) {
+		return "", false
+	}
+	rest := p[len(root):]
+	if rest == "" {
+		return ".", true
+	}
+	rel, ok := strings.CutPrefix(rest, "/")
+
+	return rel, ok
+}
+
+// checkPathCase rejects a path that differs only in letter case from an
+// existing file or directory under root. On case-insensitive filesystems
+// (macOS, Windows) writing it would silently change the existing entry, and
+// on others it would create a near-duplicate that breaks those checkouts.
+func checkPathCase(root, rel string) error {
+	dir := root
+	for component := range strings.SplitSeq(rel, "/") {
+		if component == ".." {
+			dir = filepath.Join(dir, component)
+
+			continue
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil // New directory, nothing to clash with
+		}
+		exact := false
+		for _, entry := range entries {
+			if entry.Name() == component {
+				exact = true
+
+				break
+			}
+		}
+		if !exact {
+			for _, entry := range entries {
+				if strings.EqualFold(entry.Name(), component) {
+					existing := filepath.ToSlash(filepath.Join(dir, entry.Name()))
+
+					return fmt.Errorf("%w %q: differs only in case from existing %s", ErrInvalidFilePath, rel, existing)
+				}
+			}
+
+			return nil
+		}
+		dir = filepath.Join(dir, component)
+	}
+
+	return nil
+}
+
+// normalizeFileChanges normalizes the path of every change in place and
+// validates the batch: no path may clash in case with an existing entry or
+// with another change.
+func normalizeFileChanges(files []agent.FileChange, root string) error {
+	seen := make(map[string]string, len(files))
+	for i := range files {
+		p, err := normalizeFilePath(files[i].Path, root)
+		if err != nil {
+			return err
+		}
+		if err := checkPathCase(root, p); err != nil {
+			return err
+		}
+		if other, ok := seen[strings.ToLower(p)]; ok && other != p {
+			return fmt.Errorf("%w %q: differs only in case from %q in the same change set", ErrInvalidFilePath, p, other)
+		}
+		seen[strings.ToLower(p)] = p
+		files[i].Path = p
+	}
+
+	return nil
+}
+
 // ensureDirExists creates the directory for the given file path if it doesn't exist.
 // This is a helper to avoid code duplication when writing files.
 func ensureDirExists(path string) error {
@@ -43,10 +175,26 @@ func validatePathInWorkspace(resolved, root string) error {
 }
 
 // applyFiles writes agent file changes to disk.
-func applyFiles(_ context.Context, c *Conductor, files []agent.FileChange) error {
-	root := c.opts.WorkDir
-	if c.git != nil {
-		root = c.git.Root()
+func applyFiles(ctx context.Context, c *Conductor, files []agent.FileChange) error {
+	return applyFilesIn(ctx, c, c.GetVCS().Root(), files)
+}
+
+// applyFilesIn writes agent file changes below root, such as a worktree.
+func applyFilesIn(ctx context.Context, c *Conductor, root string, files []agent.FileChange) error {
+	// Reject the whole batch if any path is malformed, before anything is written
+	if err := normalizeFileChanges(files, root); err != nil {
+		return err
+	}
+
+	// Reject the whole batch if any change escapes the task scope
+	if err := c.checkScope(files); err != nil {
+		return err
+	}
+
+	// Reject the whole batch if the guardrail policy forbids any change;
+	// secrets may be redacted instead
+	if err := c.checkPolicy(files); err != nil {
+		return err
 	}
 
 	// Resolve symlinks in root path for accurate validation (handles macOS /var -> /private/var symlinks)
@@ -63,6 +211,11 @@ func applyFiles(_ context.Context, c *Conductor, files []agent.FileChange) error
 	}
 
 	for _, fc := range files {
+		// Stop between files on interrupt; the caller rolls back what was written
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		path := filepath.Join(root, fc.Path)
 
 		// Validate the path is within workspace (prevent path traversal attacks)
@@ -72,7 +225,7 @@ func applyFiles(_ context.Context, c *Conductor, files []agent.FileChange) error
 			resolvedPath = res
 		}
 		// Validate against both the original root and resolved root to handle symlinked paths
-		if err := validatePathInWorkspace(resolvedPath, root); err != nil {
+		if err := validatePathInWorkspace(resolvedPath, root); err != nil && !c.inAttachedRepo(fc.Path) {
 			if err := validatePathInWorkspace(resolvedPath, resolvedRoot); err != nil {
 				return fmt.Errorf("invalid file path %q: %w", fc.Path, err)
 			}
@@ -91,7 +244,9 @@ func applyFiles(_ context.Context, c *Conductor, files []agent.FileChange) error
 			}
 
 			// Write file
-			if err := os.WriteFile(path, []byte(fc.Content), 0o644); err != nil {
+			err := os.WriteFile(path, []byte(fc.Content), 0o644)
+			c.auditFile(storage.AuditFileWrite, fc.Path, fc.Content, err)
+			if err != nil {
 				return fmt.Errorf("write file %s: %w", path, err)
 			}
 			stats.created++
@@ -111,7 +266,9 @@ func applyFiles(_ context.Context, c *Conductor, files []agent.FileChange) error
 			}
 
 			// Write file
-			if err := os.WriteFile(path, []byte(fc.Content), 0o644); err != nil {
+			err := os.WriteFile(path, []byte(fc.Content), 0o644)
+			c.auditFile(storage.AuditFileWrite, fc.Path, fc.Content, err)
+			if err != nil {
 				return fmt.Errorf("write file %s: %w", path, err)
 			}
 			stats.updated++
@@ -125,7 +282,12 @@ func applyFiles(_ context.Context, c *Conductor, files []agent.FileChange) error
 			})
 
 		case agent.FileOpDelete:
-			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			err := os.Remove(path)
+			if os.IsNotExist(err) {
+				err = nil
+			}
+			c.auditFile(storage.AuditFileDelete, fc.Path, "", err)
+			if err != nil {
 				return fmt.Errorf("delete file %s: %w", path, err)
 			}
 			stats.deleted++
@@ -138,6 +300,7 @@ func applyFiles(_ context.Context, c *Conductor, files []agent.FileChange) error
 				},
 			})
 		}
+		c.trackAgentFile(fc.Path)
 	}
 
 	// Publish summary of file operations
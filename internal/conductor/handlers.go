@@ -17,12 +17,31 @@ import (
 // Using errors.New() instead of fmt.Errorf() ensures errors.Is() works reliably.
 var ErrPendingQuestion = errors.New("agent has a pending question")
 
+// ErrNoPendingQuestion is returned when answering without a pending question.
+var ErrNoPendingQuestion = storage.ErrNoPendingQuestion
+
 // RunPlanning executes the planning phase (creates SPEC files).
 func (c *Conductor) RunPlanning(ctx context.Context) error {
+	return c.tracePhase(ctx, "planning", c.withPhaseTimeout(workflow.StepPlanning, c.Plan, c.runPlanning))
+}
+
+func (c *Conductor) runPlanning(ctx context.Context) (err error) {
+	ctx, release, err := c.acquireLease(ctx)
+	if err != nil {
+		return err
+	}
+	defer release(&err)
+
 	c.publishProgress("Starting planning phase...", 0)
 
 	taskID := c.activeTask.ID
 
+	// Resolve the spec template before spending agent time on an unknown name
+	specTemplate, err := c.planningSpecTemplate(taskID)
+	if err != nil {
+		return err
+	}
+
 	// Create progress tracker for this phase
 	var statusLine *progress.StatusLine
 	if !c.opts.DryRun {
@@ -36,13 +55,21 @@ func (c *Conductor) RunPlanning(ctx context.Context) error {
 		return fmt.Errorf("get planning agent: %w", err)
 	}
 
-	// Create session for this planning run
-	session, filename, err := c.workspace.CreateSession(taskID, "planning", planningAgent.Name(), c.activeTask.State)
-	if err != nil {
-		c.logError(fmt.Errorf("create session: %w", err))
+	// Continue the latest planning session after a question or when asked to,
+	// otherwise start a new one
+	continuing := c.opts.ContinueSession || c.workspace.HasPendingQuestion(taskID)
+	if continuing {
+		if err := c.resumeSession(taskID, "planning", planningAgent.Name()); err != nil {
+			c.logError(err)
+		}
 	} else {
-		c.currentSession = session
-		c.currentSessionFile = filename
+		session, filename, err := c.workspace.CreateSession(taskID, "planning", planningAgent.Name(), c.activeTask.State)
+		if err != nil {
+			c.logError(fmt.Errorf("create session: %w", err))
+		} else {
+			c.currentSession = session
+			c.currentSessionFile = filename
+		}
 	}
 
 	// Get source content for the prompt
@@ -79,15 +106,50 @@ func (c *Conductor) RunPlanning(ctx context.Context) error {
 		_ = c.workspace.ClearPendingQuestion(taskID)
 	}
 
+	// An agent that resumed its own conversation already has the task context
+	var history []storage.Exchange
+	resumed := false
+	if continuing && c.currentSession != nil {
+		history = c.currentSession.Exchanges
+		planningAgent, resumed = c.continuationAgent(planningAgent)
+	}
+	if note := latestNote(notes); note != "" && !lastExchangeIs(history, "user", note) {
+		c.recordExchange("user", note)
+	}
+
 	// Build planning prompt
-	prompt := buildPlanningPrompt(c.taskWork.Metadata.Title, sourceContent, notes, existingSpecifications)
-	if pendingContext != "" {
-		prompt += "\n\n## Previous Analysis (before question)\nThe following is context from your previous planning session. Use this to avoid re-exploring:\n\n" + pendingContext
+	var prompt string
+	if resumed {
+		prompt = buildFollowUpPrompt(planningFollowUp(notes))
+	} else {
+		// Older notes and sessions are summarized when they don't fit
+		pc := c.buildContext(ctx, workflow.StepPlanning, planningAgent, sourceContent+existingSpecifications, notes, history)
+		prompt = buildPlanningPrompt(c.taskWork.Metadata.Title, sourceContent, pc.Notes, existingSpecifications)
+		prompt += pc.summaryPrompt()
+		prompt += scopePrompt(c.taskScope())
+		prompt += c.reposPrompt()
+		prompt += c.lessonsPrompt()
+		prompt += c.conventionsPrompt(workflow.StepPlanning)
+		prompt += specTemplatePrompt(specTemplate)
+		prompt += checklistPrompt(sourceContent)
+		prompt += sessionHistoryPrompt("Previous Planning Conversation", pc.History)
+		if pendingContext != "" {
+			prompt += "\n\n## Previous Analysis (before question)\nThe following is context from your previous planning session. Use this to avoid re-exploring:\n\n" + pendingContext
+		}
+	}
+	prompt += c.sourceDriftPrompt()
+
+	// Show screenshots from the source to agents that can see them
+	var images []string
+	if !resumed && agent.AcceptsAttachments(planningAgent) {
+		images = c.sourceImages(taskID, sourceContent)
+		prompt += attachedImagesPrompt(images)
 	}
+	prompt = c.scriptPrompt(ctx, workflow.StepPlanning, prompt)
 
 	// Run agent with streaming
 	c.publishProgress("Agent analyzing task...", 20)
-	response, err := planningAgent.RunWithCallback(ctx, prompt, func(event agent.Event) error {
+	response, err := agent.RunWithAttachments(ctx, planningAgent, prompt, images, func(event agent.Event) error {
 		// Always publish to event bus
 		c.eventBus.PublishRaw(events.Event{
 			Type: events.TypeAgentMessage,
@@ -114,16 +176,8 @@ func (c *Conductor) RunPlanning(ctx context.Context) error {
 	}
 
 	// Record usage stats
-	if response.Usage != nil {
-		if err := c.workspace.AddUsage(taskID, "planning",
-			response.Usage.InputTokens,
-			response.Usage.OutputTokens,
-			response.Usage.CachedTokens,
-			response.Usage.CostUSD,
-		); err != nil {
-			c.logError(fmt.Errorf("record planning usage: %w", err))
-		}
-	}
+	c.recordUsage(taskID, "planning", workflow.StepPlanning, planningAgent, response.Usage)
+	c.recordAgentSession(response)
 
 	// If agent asked a question, handle based on mode
 	if response.Question != nil {
@@ -153,6 +207,16 @@ func (c *Conductor) RunPlanning(ctx context.Context) error {
 			if err := c.workspace.SavePendingQuestion(taskID, pendingQuestion); err != nil {
 				c.logError(fmt.Errorf("save pending question: %w", err))
 			}
+			c.eventBus.Publish(events.QuestionPendingEvent{
+				TaskID:   taskID,
+				Title:    c.taskWork.Metadata.Title,
+				Phase:    pendingQuestion.Phase,
+				Question: pendingQuestion.Question,
+				Options:  questionOptionLabels(pendingQuestion.Options),
+			})
+			// Keep the session open so the next plan run continues the conversation
+			c.recordExchange("agent", response.Question.Text)
+			c.persistCurrentSession(taskID)
 			// Dispatch EventWait to properly transition FSM to StateWaiting
 			_ = c.machine.Dispatch(ctx, workflow.EventWait)
 			c.activeTask.State = string(workflow.StateWaiting)
@@ -175,9 +239,26 @@ func (c *Conductor) RunPlanning(ctx context.Context) error {
 	// Format specification content
 	specContent := formatSpecificationContent(nextNum, response)
 
-	if err := c.workspace.SaveSpecification(taskID, nextNum, specContent); err != nil {
+	// A critic reviews the draft, which is revised until it passes or rounds run out
+	if rounds := c.specCritiqueRounds(); rounds > 0 {
+		content, critique := c.critiqueSpecification(ctx, taskID, nextNum, planningAgent, sourceContent, specContent, rounds)
+		spec := &storage.Specification{
+			Number:   nextNum,
+			Status:   storage.SpecificationStatusDraft,
+			Template: c.taskWork.Metadata.SpecTemplate,
+			Content:  content,
+			Critique: critique,
+		}
+		if critique != nil && critique.Passed() {
+			spec.Status = storage.SpecificationStatusReady
+		}
+		if err := c.workspace.SaveSpecificationWithMeta(taskID, spec); err != nil {
+			return fmt.Errorf("save specification: %w", err)
+		}
+	} else if err := c.workspace.SaveSpecification(taskID, nextNum, specContent); err != nil {
 		return fmt.Errorf("save specification: %w", err)
 	}
+	c.recordExchange("agent", extractContextSummary(response))
 
 	// Create checkpoint if git is available
 	c.createCheckpointIfNeeded(ctx, taskID, fmt.Sprintf("Add specification-%d for task %s", nextNum, taskID))
@@ -194,6 +275,12 @@ func (c *Conductor) RunPlanning(ctx context.Context) error {
 	// Save session with completion time
 	c.saveCurrentSession(taskID)
 
+	c.eventBus.Publish(events.PlanCompletedEvent{
+		TaskID:          taskID,
+		Title:           c.taskWork.Metadata.Title,
+		Summary:         extractContextSummary(response),
+		SpecificationID: nextNum,
+	})
 	c.publishProgress("Planning complete", 100)
 
 	return nil
@@ -201,6 +288,16 @@ func (c *Conductor) RunPlanning(ctx context.Context) error {
 
 // RunImplementation executes the implementation phase.
 func (c *Conductor) RunImplementation(ctx context.Context) error {
+	return c.tracePhase(ctx, "implementation", c.withPhaseTimeout(workflow.StepImplementing, c.Implement, c.runImplementation))
+}
+
+func (c *Conductor) runImplementation(ctx context.Context) (err error) {
+	ctx, release, err := c.acquireLease(ctx)
+	if err != nil {
+		return err
+	}
+	defer release(&err)
+
 	c.publishProgress("Starting implementation phase...", 0)
 
 	taskID := c.activeTask.ID
@@ -217,6 +314,11 @@ func (c *Conductor) RunImplementation(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("get implementing agent: %w", err)
 	}
+	implementingAgent, stopSandbox, err := c.sandboxAgent(implementingAgent)
+	if err != nil {
+		return fmt.Errorf("sandbox implementing agent: %w", err)
+	}
+	defer stopSandbox()
 
 	// Create session for this implementation run
 	session, filename, err := c.workspace.CreateSession(taskID, "implementation", implementingAgent.Name(), c.activeTask.State)
@@ -227,16 +329,30 @@ func (c *Conductor) RunImplementation(ctx context.Context) error {
 		c.currentSessionFile = filename
 	}
 
-	// Get latest specification content only (use the most refined version)
-	specContent, specNum, err := c.workspace.GetLatestSpecificationContent(taskID)
+	// Use the selected specification, or the latest (most refined) one
+	specContent, specNum, resumed, err := c.implementationSpec(taskID)
 	if err != nil {
-		return fmt.Errorf("get latest specification: %w", err)
+		return err
 	}
 	if specContent == "" {
 		return errors.New("no specifications found - run 'task plan' first")
 	}
 
-	c.publishProgress(fmt.Sprintf("Using specification-%d for implementation...", specNum), 5)
+	perSpec := c.opts.Specification > 0
+	if perSpec && !c.opts.DryRun {
+		if c.currentSession != nil {
+			c.currentSession.Metadata.Specification = specNum
+		}
+		if err := c.workspace.StartSpecificationImplementation(taskID, specNum, c.currentSessionFile); err != nil {
+			c.logError(fmt.Errorf("update specification status: %w", err))
+		}
+	}
+
+	if resumed {
+		c.publishProgress(fmt.Sprintf("Resuming specification-%d...", specNum), 5)
+	} else {
+		c.publishProgress(fmt.Sprintf("Using specification-%d for implementation...", specNum), 5)
+	}
 
 	// Get source content for context
 	sourceContent, err := c.workspace.GetSourceContent(taskID)
@@ -247,8 +363,30 @@ func (c *Conductor) RunImplementation(ctx context.Context) error {
 	// Get notes (missing notes is acceptable, returns empty string)
 	notes, _ := c.workspace.ReadNotes(taskID)
 
-	// Build implementation prompt with latest spec
-	prompt := buildImplementationPrompt(c.taskWork.Metadata.Title, sourceContent, specContent, notes)
+	// Build implementation prompt with latest spec, summarizing older notes
+	// when they don't fit
+	pc := c.buildContext(ctx, workflow.StepImplementing, implementingAgent, sourceContent+specContent, notes, nil)
+	prompt := buildImplementationPrompt(c.taskWork.Metadata.Title, sourceContent, specContent, pc.Notes)
+	prompt += pc.summaryPrompt()
+	prompt += scopePrompt(c.taskScope())
+	prompt += c.reposPrompt()
+	prompt += c.lessonsPrompt()
+	prompt += c.conventionsPrompt(workflow.StepImplementing)
+	prompt += c.codeMapPrompt(specContent)
+	prompt += c.sourceDriftPrompt()
+	if perSpec {
+		prompt += specPrompt(specNum, resumed)
+	}
+	prompt = c.scriptPrompt(ctx, workflow.StepImplementing, prompt)
+
+	// Watch for manual edits to files the agent is touching
+	var watcher *editWatcher
+	if c.opts.WatchEdits && !c.opts.DryRun {
+		watcher = newEditWatcher(c.repoRoot())
+	}
+
+	// Remember what was already changed so an interrupt only rolls back this run
+	guard := c.guardInterrupt(ctx)
 
 	// Run agent with streaming
 	c.publishProgress("Agent implementing...", 20)
@@ -263,12 +401,25 @@ func (c *Conductor) RunImplementation(ctx context.Context) error {
 			_ = statusLine.OnEvent(event)
 		}
 
+		// Between events is a safe point to pause
+		if watcher != nil {
+			if files := watcher.conflicts(); len(files) > 0 {
+				if err := c.pauseForEdits(ctx, files); err != nil {
+					return err
+				}
+			}
+			watcher.observe(event)
+		}
+
 		return nil
 	})
 	if err != nil {
 		if statusLine != nil {
 			statusLine.Done()
 		}
+		if ctx.Err() != nil {
+			return c.interrupted(ctx, taskID, guard, err)
+		}
 		c.activeTask.State = "idle"
 		if err := c.workspace.SaveActiveTask(c.activeTask); err != nil {
 			c.logError(fmt.Errorf("save active task after implementation error: %w", err))
@@ -279,31 +430,85 @@ func (c *Conductor) RunImplementation(ctx context.Context) error {
 	}
 
 	// Record usage stats
-	if response.Usage != nil {
-		if err := c.workspace.AddUsage(taskID, "implementing",
-			response.Usage.InputTokens,
-			response.Usage.OutputTokens,
-			response.Usage.CachedTokens,
-			response.Usage.CostUSD,
-		); err != nil {
-			c.logError(fmt.Errorf("record implementation usage: %w", err))
-		}
-	}
+	c.recordUsage(taskID, "implementing", workflow.StepImplementing, implementingAgent, response.Usage)
 
 	c.publishProgress("Applying changes...", 70)
 
 	// Apply file changes
 	if !c.opts.DryRun && len(response.Files) > 0 {
+		if watcher != nil {
+			if files := watcher.conflictsIn(response.Files); len(files) > 0 {
+				if err := c.pauseForEdits(ctx, files); err != nil {
+					if ctx.Err() != nil {
+						return c.interrupted(ctx, taskID, guard, err)
+					}
+
+					return err
+				}
+			}
+		}
+
+		summaries := summarizeFileChanges(c.GetVCS().Root(), response.Files)
 		if err := applyFiles(ctx, c, response.Files); err != nil {
+			if ctx.Err() != nil {
+				return c.interrupted(ctx, taskID, guard, err)
+			}
+
 			return fmt.Errorf("apply files: %w", err)
 		}
+		c.recordChangeSummaries(summaries)
+
+		// Track plan vs actual for spec accuracy reporting
+		changed := make([]string, 0, len(response.Files))
+		for _, f := range response.Files {
+			changed = append(changed, f.Path)
+		}
+		if err := c.workspace.RecordImplementedFiles(taskID, specNum, changed); err != nil {
+			c.logError(fmt.Errorf("record implemented files: %w", err))
+		}
+	}
+
+	// Block the checkpoint if the changes introduce secrets or large blobs
+	if !c.opts.DryRun {
+		if err := c.enforceGuardrails(ctx, implementingAgent, "implementing", workflow.StepImplementing); err != nil {
+			c.activeTask.State = "idle"
+			if err := c.workspace.SaveActiveTask(c.activeTask); err != nil {
+				c.logError(fmt.Errorf("save active task after guardrail failure: %w", err))
+			}
+			_ = c.machine.Dispatch(ctx, workflow.EventError)
+			c.saveCurrentSession(taskID)
+
+			return err
+		}
+	}
+
+	// Record what changed, and open it in the editor when configured
+	if !c.opts.DryRun {
+		changes := c.implementedChanges(ctx, response.Files)
+		c.recordImplementation(response.Summary, changes)
+		c.openInEditor(ctx, changes)
 	}
 
 	// Create checkpoint if git is available
-	if event := c.createCheckpointIfNeeded(ctx, taskID, "Implement task "+taskID); event != nil {
+	message := "Implement task " + taskID
+	if perSpec {
+		message = fmt.Sprintf("Implement specification %d of task %s", specNum, taskID)
+	}
+	checkpoint := 0
+	if event := c.createCheckpointIfNeeded(ctx, taskID, message); event != nil {
+		checkpoint, _ = event.Data["checkpoint"].(int)
 		c.eventBus.PublishRaw(*event)
 	}
 
+	// A failed run leaves the spec implementing so it can be resumed
+	if perSpec && !c.opts.DryRun {
+		if err := c.workspace.CompleteSpecificationImplementation(taskID, specNum, checkpoint); err != nil {
+			c.logError(fmt.Errorf("update specification status: %w", err))
+		} else {
+			c.syncSpecTaskList(ctx, taskID, specNum)
+		}
+	}
+
 	// Update state back to idle
 	c.activeTask.State = "idle"
 	if err := c.workspace.SaveActiveTask(c.activeTask); err != nil {
@@ -323,6 +528,16 @@ func (c *Conductor) RunImplementation(ctx context.Context) error {
 
 // RunReview executes the review phase.
 func (c *Conductor) RunReview(ctx context.Context) error {
+	return c.tracePhase(ctx, "review", c.withPhaseTimeout(workflow.StepReviewing, c.Review, c.runReview))
+}
+
+func (c *Conductor) runReview(ctx context.Context) (err error) {
+	ctx, release, err := c.acquireLease(ctx)
+	if err != nil {
+		return err
+	}
+	defer release(&err)
+
 	c.publishProgress("Starting review phase...", 0)
 
 	taskID := c.activeTask.ID
@@ -334,6 +549,17 @@ func (c *Conductor) RunReview(ctx context.Context) error {
 		defer statusLine.Done()
 	}
 
+	// Several agents reviewing independently when consensus review is configured
+	reviewers, minAgreement, err := c.consensusReviewers()
+	if err != nil {
+		c.abortReview(ctx, statusLine)
+
+		return err
+	}
+	if len(reviewers) > 0 {
+		return c.runConsensusReview(ctx, reviewers, minAgreement, statusLine)
+	}
+
 	// Get agent for reviewing step
 	reviewAgent, err := c.GetAgentForStep(ctx, workflow.StepReviewing)
 	if err != nil {
@@ -367,6 +593,11 @@ func (c *Conductor) RunReview(ctx context.Context) error {
 
 	// Build review prompt with lint results
 	prompt := buildReviewPromptWithLint(c.taskWork.Metadata.Title, sourceContent, specContent, lintResults)
+	prompt += scopePrompt(c.taskScope())
+	prompt += c.reposPrompt()
+	prompt += c.lessonsPrompt()
+	prompt += c.conventionsPrompt(workflow.StepReviewing)
+	prompt = c.scriptPrompt(ctx, workflow.StepReviewing, prompt)
 
 	// Run agent
 	c.publishProgress("Agent reviewing...", 20)
@@ -397,16 +628,7 @@ func (c *Conductor) RunReview(ctx context.Context) error {
 	}
 
 	// Record usage stats
-	if response.Usage != nil {
-		if err := c.workspace.AddUsage(taskID, "review",
-			response.Usage.InputTokens,
-			response.Usage.OutputTokens,
-			response.Usage.CachedTokens,
-			response.Usage.CostUSD,
-		); err != nil {
-			c.logError(fmt.Errorf("record review usage: %w", err))
-		}
-	}
+	c.recordUsage(taskID, "review", workflow.StepReviewing, reviewAgent, response.Usage)
 
 	c.publishProgress("Processing review...", 70)
 
@@ -419,16 +641,24 @@ func (c *Conductor) RunReview(ctx context.Context) error {
 		if err := c.workspace.AppendNote(taskID, "## Review Results\n\n"+reviewContent, "reviewing"); err != nil {
 			c.logError(fmt.Errorf("append review note: %w", err))
 		}
+		c.recordFailures(lessonSourceReview, categorizeReview(reviewContent))
 	}
 
 	// Apply any suggested fixes if not dry-run
 	if !c.opts.DryRun && len(response.Files) > 0 {
+		summaries := summarizeFileChanges(c.GetVCS().Root(), response.Files)
 		if err := applyFiles(ctx, c, response.Files); err != nil {
 			c.logError(fmt.Errorf("apply review fixes: %w", err))
+		} else {
+			c.recordChangeSummaries(summaries)
 		}
 
-		// Create checkpoint for review fixes
-		c.createCheckpointIfNeeded(ctx, taskID, "Apply review fixes for task "+taskID)
+		// Create checkpoint for review fixes unless they introduce secrets or large blobs
+		if err := c.enforceGuardrails(ctx, reviewAgent, "review", workflow.StepReviewing); err != nil {
+			c.logError(err)
+		} else {
+			c.createCheckpointIfNeeded(ctx, taskID, "Apply review fixes for task "+taskID)
+		}
 	}
 
 	// Update state back to idle
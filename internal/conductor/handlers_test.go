@@ -605,6 +605,137 @@ func TestApplyFiles_WithGitRoot(t *testing.T) {
 	}
 }
 
+func TestNormalizeFilePath(t *testing.T) {
+	root := "/workspace/repo"
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		errMsg  string
+		wantErr bool
+	}{
+		{name: "relative", path: "src/main.go", want: "src/main.go"},
+		{name: "backslashes", path: `src\pkg\main.go`, want: "src/pkg/main.go"},
+		{name: "dot prefix", path: "./src/../main.go", want: "main.go"},
+		{name: "surrounding space", path: " main.go\n", want: "main.go"},
+		{name: "absolute inside root", path: "/workspace/repo/src/main.go", want: "src/main.go"},
+		{name: "attached repo", path: "../shared/lib.go", want: "../shared/lib.go"},
+		{name: "empty", path: "  ", wantErr: true, errMsg: "empty path"},
+		{name: "absolute outside root", path: "/etc/passwd", wantErr: true, errMsg: "outside the repository"},
+		{name: "sibling with root prefix", path: "/workspace/repo-other/x.go", wantErr: true, errMsg: "outside the repository"},
+		{name: "windows drive", path: `C:\Users\dev\main.go`, wantErr: true, errMsg: "outside the repository"},
+		{name: "root itself", path: "/workspace/repo", wantErr: true, errMsg: "repository root"},
+		{name: "git directory", path: `.git\hooks\pre-commit`, wantErr: true, errMsg: ".git"},
+		{name: "nul byte", path: "main\x00.go", wantErr: true, errMsg: "NUL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeFilePath(tt.path, root)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidFilePath) {
+					t.Fatalf("normalizeFilePath() error = %v, want ErrInvalidFilePath", err)
+				}
+				if !strings.Contains(err.Error(), tt.errMsg) {
+					t.Errorf("error = %q, want contain %q", err.Error(), tt.errMsg)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("normalizeFilePath() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeFilePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeFilePath_WindowsRoot(t *testing.T) {
+	got, err := normalizeFilePath(`c:\Work\Repo\src\main.go`, `C:\Work\Repo`)
+	if err != nil {
+		t.Fatalf("normalizeFilePath() unexpected error: %v", err)
+	}
+	if got != "src/main.go" {
+		t.Errorf("normalizeFilePath() = %q, want %q", got, "src/main.go")
+	}
+}
+
+func TestApplyFiles_NormalizesPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	c, err := New(WithWorkDir(tmpDir))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	c.eventBus = events.NewBus()
+
+	files := []agent.FileChange{
+		{Path: `pkg\util\strings.go`, Operation: agent.FileOpCreate, Content: "package util"},
+		{Path: filepath.Join(c.GetVCS().Root(), "README.md"), Operation: agent.FileOpCreate, Content: "# Readme"},
+	}
+	if err := applyFiles(context.Background(), c, files); err != nil {
+		t.Fatalf("applyFiles: %v", err)
+	}
+
+	for _, path := range []string{"pkg/util/strings.go", "README.md"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, path)); err != nil {
+			t.Errorf("%s was not written: %v", path, err)
+		}
+	}
+	if files[0].Path != "pkg/util/strings.go" {
+		t.Errorf("change path = %q, want it normalized in place", files[0].Path)
+	}
+}
+
+func TestApplyFiles_RejectsCaseClash(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "Docs"), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	c, err := New(WithWorkDir(tmpDir))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	c.eventBus = events.NewBus()
+
+	tests := []struct {
+		name   string
+		files  []agent.FileChange
+		errMsg string
+	}{
+		{
+			name:   "existing directory",
+			files:  []agent.FileChange{{Path: "docs/guide.md", Operation: agent.FileOpCreate, Content: "x"}},
+			errMsg: "differs only in case from existing",
+		},
+		{
+			name: "same change set",
+			files: []agent.FileChange{
+				{Path: "Docs/Guide.md", Operation: agent.FileOpCreate, Content: "x"},
+				{Path: "Docs/guide.md", Operation: agent.FileOpCreate, Content: "y"},
+			},
+			errMsg: "in the same change set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyFiles(context.Background(), c, tt.files)
+			if !errors.Is(err, ErrInvalidFilePath) || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Fatalf("applyFiles() error = %v, want %q", err, tt.errMsg)
+			}
+			entries, _ := os.ReadDir(filepath.Join(tmpDir, "Docs"))
+			if len(entries) != 0 {
+				t.Errorf("rejected batch should write nothing, found %d entries", len(entries))
+			}
+		})
+	}
+}
+
 func TestDeleteFileSentinelConstant(t *testing.T) {
 	if DeleteFileSentinel != "__DELETE_FILE__" {
 		t.Errorf("DeleteFileSentinel = %q, want %q", DeleteFileSentinel, "__DELETE_FILE__")
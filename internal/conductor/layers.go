@@ -0,0 +1,197 @@
+package conductor
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/valksor/go-mehrhof/internal/config"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// Configuration layers, in increasing order of precedence.
+const (
+	OriginDefault   = "default"   // Built-in default
+	OriginApp       = "app"       // ~/.mehrhof/settings.json
+	OriginWorkspace = "workspace" // .mehrhof/config.yaml and config.local.yaml
+	OriginEnv       = "env"       // MEHR_* environment variable
+	OriginFlag      = "flag"      // Command-line flag
+)
+
+// ConfigLayers are the sources the effective settings are resolved from.
+// Nil layers are skipped.
+type ConfigLayers struct {
+	App           *config.Settings         // User settings
+	Workspace     *storage.WorkspaceConfig // Only the settings config.yaml sets, without defaults
+	WorkspaceFile string                   // Path of config.yaml, reported as the workspace source
+	Local         *storage.WorkspaceConfig // Only the settings config.local.yaml sets, over config.yaml
+	LocalFile     string                   // Path of config.local.yaml
+	Env           func(string) string      // Environment lookup, usually os.Getenv
+	Flags         map[string]string        // Flags set on the command line, by flag name
+}
+
+// ResolvedSetting is the effective value of a setting and where it came from.
+type ResolvedSetting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Origin string `json:"origin"`
+	Source string `json:"source,omitempty"` // File, environment variable or flag that set the value
+}
+
+// layeredSetting describes a setting that every layer can set.
+type layeredSetting struct {
+	key       string
+	fallback  string // Default when the workspace defaults leave it empty
+	env       string
+	flag      string
+	app       func(*config.Settings) string
+	workspace func(*storage.WorkspaceConfig) string
+}
+
+// layeredSettings are the settings resolved across all layers, in the order
+// 'mehr config show' prints them.
+var layeredSettings = []layeredSetting{
+	{
+		key: "agent.default", env: "MEHR_AGENT", flag: "agent",
+		app:       func(s *config.Settings) string { return s.PreferredAgent },
+		workspace: func(c *storage.WorkspaceConfig) string { return c.Agent.Default },
+	},
+	{
+		key: "agent.context", fallback: string(ContextFull), env: "MEHR_CONTEXT", flag: "context",
+		workspace: func(c *storage.WorkspaceConfig) string { return c.Agent.Context },
+	},
+	{
+		key: "providers.default", env: "MEHR_PROVIDER",
+		workspace: func(c *storage.WorkspaceConfig) string { return c.Providers.Default },
+	},
+	{
+		key: "git.commit_prefix", env: "MEHR_COMMIT_PREFIX", flag: "commit-prefix",
+		workspace: func(c *storage.WorkspaceConfig) string { return c.Git.CommitPrefix },
+	},
+	{
+		key: "git.branch_pattern", env: "MEHR_BRANCH_PATTERN", flag: "branch-pattern",
+		workspace: func(c *storage.WorkspaceConfig) string { return c.Git.BranchPattern },
+	},
+	{key: "verbose", fallback: "false", env: "MEHR_VERBOSE", flag: "verbose"},
+}
+
+// LayeredFlags returns the names of the flags that set layered settings.
+func LayeredFlags() []string {
+	var flags []string
+	for _, s := range layeredSettings {
+		if s.flag != "" {
+			flags = append(flags, s.flag)
+		}
+	}
+
+	return flags
+}
+
+// ResolvedConfig holds the effective value of every layered setting.
+type ResolvedConfig struct {
+	Settings []ResolvedSetting
+}
+
+// Resolve returns the effective settings. Each layer overrides the ones
+// before it: defaults, app settings, workspace config, MEHR_* environment
+// variables, command-line flags. Within the workspace layer,
+// config.local.yaml overrides config.yaml. Empty values do not override.
+func (l ConfigLayers) Resolve() *ResolvedConfig {
+	defaults := storage.NewDefaultWorkspaceConfig()
+	resolved := &ResolvedConfig{}
+
+	for _, s := range layeredSettings {
+		r := ResolvedSetting{Key: s.key, Value: s.fallback, Origin: OriginDefault}
+		if s.workspace != nil && s.workspace(defaults) != "" {
+			r.Value = s.workspace(defaults)
+		}
+		if s.app != nil && l.App != nil {
+			if v := s.app(l.App); v != "" {
+				r = ResolvedSetting{Key: s.key, Value: v, Origin: OriginApp, Source: config.SettingsPath()}
+			}
+		}
+		if s.workspace != nil && l.Workspace != nil {
+			if v := s.workspace(l.Workspace); v != "" {
+				r = ResolvedSetting{Key: s.key, Value: v, Origin: OriginWorkspace, Source: l.WorkspaceFile}
+			}
+		}
+		if s.workspace != nil && l.Local != nil {
+			if v := s.workspace(l.Local); v != "" {
+				r = ResolvedSetting{Key: s.key, Value: v, Origin: OriginWorkspace, Source: l.LocalFile}
+			}
+		}
+		if s.env != "" && l.Env != nil {
+			if v := l.Env(s.env); v != "" {
+				r = ResolvedSetting{Key: s.key, Value: v, Origin: OriginEnv, Source: s.env}
+			}
+		}
+		if s.flag != "" {
+			if v, ok := l.Flags[s.flag]; ok && v != "" {
+				r = ResolvedSetting{Key: s.key, Value: v, Origin: OriginFlag, Source: "--" + s.flag}
+			}
+		}
+		resolved.Settings = append(resolved.Settings, r)
+	}
+
+	return resolved
+}
+
+// Get returns the effective value of a setting.
+func (r *ResolvedConfig) Get(key string) (ResolvedSetting, bool) {
+	for _, s := range r.Settings {
+		if s.Key == key {
+			return s, true
+		}
+	}
+
+	return ResolvedSetting{}, false
+}
+
+// Options returns the conductor options for the effective settings. Values
+// from the environment and flags override the options commands set; the
+// default agent and provider apply whatever their origin, since no command
+// sets them. Lower layers are otherwise left to the conductor, which reads
+// config.yaml itself.
+func (r *ResolvedConfig) Options() ([]Option, error) {
+	var opts []Option
+
+	for _, s := range r.Settings {
+		override := s.Origin == OriginEnv || s.Origin == OriginFlag
+
+		switch s.Key {
+		case "agent.default":
+			if override {
+				opts = append(opts, WithAgent(s.Value))
+			} else {
+				opts = append(opts, WithDefaultAgent(s.Value))
+			}
+		case "providers.default":
+			opts = append(opts, WithDefaultProvider(s.Value))
+		case "agent.context":
+			if override {
+				mode, err := ParseContextMode(s.Value)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", s.Source, err)
+				}
+				opts = append(opts, WithContextMode(mode))
+			}
+		case "git.commit_prefix":
+			if override {
+				opts = append(opts, WithCommitPrefixTemplate(s.Value))
+			}
+		case "git.branch_pattern":
+			if override {
+				opts = append(opts, WithBranchPatternTemplate(s.Value))
+			}
+		case "verbose":
+			if override {
+				enabled, err := strconv.ParseBool(s.Value)
+				if err != nil {
+					return nil, fmt.Errorf("%s: invalid boolean %q", s.Source, s.Value)
+				}
+				opts = append(opts, WithVerbose(enabled))
+			}
+		}
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,111 @@
+package conductor
+
+import (
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/config"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestConfigLayers_Precedence(t *testing.T) {
+	env := map[string]string{}
+	layers := ConfigLayers{
+		App:           &config.Settings{PreferredAgent: "aider"},
+		Workspace:     &storage.WorkspaceConfig{},
+		WorkspaceFile: ".mehrhof/config.yaml",
+		Env:           func(name string) string { return env[name] },
+		Flags:         map[string]string{},
+	}
+
+	check := func(stage, key, wantValue, wantOrigin string) {
+		t.Helper()
+		got, ok := layers.Resolve().Get(key)
+		if !ok || got.Value != wantValue || got.Origin != wantOrigin {
+			t.Errorf("%s: %s = %q from %q, want %q from %q", stage, key, got.Value, got.Origin, wantValue, wantOrigin)
+		}
+	}
+
+	check("defaults", "git.commit_prefix", "[{key}]", OriginDefault)
+	check("defaults", "agent.context", "full", OriginDefault)
+	check("app", "agent.default", "aider", OriginApp)
+
+	layers.Workspace.Agent.Default = "codex"
+	check("workspace", "agent.default", "codex", OriginWorkspace)
+
+	layers.Local = &storage.WorkspaceConfig{Agent: storage.AgentSettings{Default: "ollama"}}
+	layers.LocalFile = ".mehrhof/config.local.yaml"
+	if got, _ := layers.Resolve().Get("agent.default"); got.Value != "ollama" || got.Source != layers.LocalFile {
+		t.Errorf("local: agent.default = %q from %q, want ollama from %q", got.Value, got.Source, layers.LocalFile)
+	}
+
+	env["MEHR_AGENT"] = "gemini"
+	check("env", "agent.default", "gemini", OriginEnv)
+
+	layers.Flags["agent"] = "claude"
+	check("flag", "agent.default", "claude", OriginFlag)
+
+	// Empty values do not override
+	layers.Flags["agent"] = ""
+	check("empty flag", "agent.default", "gemini", OriginEnv)
+}
+
+func TestResolvedConfig_Options(t *testing.T) {
+	tests := []struct {
+		name    string
+		layers  ConfigLayers
+		check   func(o Options) bool
+		wantErr bool
+	}{
+		{
+			name:   "workspace agent is only the default",
+			layers: ConfigLayers{Workspace: &storage.WorkspaceConfig{Agent: storage.AgentSettings{Default: "codex"}}},
+			check:  func(o Options) bool { return o.AgentName == "" && o.DefaultAgent == "codex" },
+		},
+		{
+			name:   "env agent overrides the task",
+			layers: ConfigLayers{Env: func(name string) string { return map[string]string{"MEHR_AGENT": "codex"}[name] }},
+			check:  func(o Options) bool { return o.AgentName == "codex" },
+		},
+		{
+			name:   "default provider applies from any layer",
+			layers: ConfigLayers{},
+			check:  func(o Options) bool { return o.DefaultProvider == "file" && !o.Verbose && o.ContextMode == "" },
+		},
+		{
+			name:   "flags override",
+			layers: ConfigLayers{Flags: map[string]string{"context": "minimal", "verbose": "true", "commit-prefix": "{key}:"}},
+			check: func(o Options) bool {
+				return o.ContextMode == ContextMinimal && o.Verbose && o.CommitPrefixTemplate == "{key}:"
+			},
+		},
+		{
+			name:    "invalid context",
+			layers:  ConfigLayers{Flags: map[string]string{"context": "everything"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid verbose",
+			layers:  ConfigLayers{Env: func(name string) string { return map[string]string{"MEHR_VERBOSE": "maybe"}[name] }},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := tt.layers.Resolve().Options()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Options() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			o := Options{}
+			for _, opt := range opts {
+				opt(&o)
+			}
+			if !tt.check(o) {
+				t.Errorf("Options() applied = %+v", o)
+			}
+		})
+	}
+}
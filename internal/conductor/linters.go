@@ -3,6 +3,7 @@ package conductor
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/valksor/go-mehrhof/internal/quality"
@@ -11,10 +12,8 @@ import (
 // runLinters executes available linters for the project and returns formatted results.
 // Returns empty string if no linters are available or all pass with no issues.
 func (c *Conductor) runLinters(ctx context.Context) string {
-	workDir := c.opts.WorkDir
-	if c.git != nil {
-		workDir = c.git.Root()
-	}
+	workDir := c.scopeDir(c.GetVCS().Root())
+	scope := c.taskScope()
 
 	// Create linter registry and detect applicable linters
 	registry := quality.NewRegistry()
@@ -28,16 +27,22 @@ func (c *Conductor) runLinters(ctx context.Context) string {
 
 	c.logVerbosef("Running %d linter(s): %s", len(linters), linterNames(linters))
 
-	// Get changed files if git is available (only lint changed files for efficiency)
+	// Get changed files when tracked (only lint changed files for efficiency);
+	// without version control every file is linted
 	var files []string
-	if c.git != nil {
-		changedFiles, err := c.git.Status(ctx)
-		if err == nil {
-			for _, f := range changedFiles {
-				// Check if file is modified, staged, or untracked ('?' in index)
-				if f.IsModified() || f.IsStaged() || f.Index == '?' {
-					files = append(files, f.Path)
-				}
+	changedFiles, err := c.GetVCS().Status(ctx)
+	if err == nil {
+		for _, f := range changedFiles {
+			// Check if file is modified, staged, or untracked ('?' in index)
+			changed := f.IsModified() || f.IsStaged() || f.Index == '?'
+			if !changed || !inScope(scope, f.Path) {
+				continue
+			}
+			// Linters run in the scope directory, so paths are made relative to it
+			if scope != "" {
+				files = append(files, strings.TrimPrefix(filepath.ToSlash(f.Path), scope+"/"))
+			} else {
+				files = append(files, f.Path)
 			}
 		}
 	}
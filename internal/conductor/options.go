@@ -4,14 +4,21 @@ import (
 	"io"
 	"os"
 	"time"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
 )
 
 // Options configures the Conductor.
 type Options struct {
 	// Agent configuration
-	AgentName  string            // Which agent to use for all steps (default: auto-detect)
-	StepAgents map[string]string // Per-step agent overrides (e.g., {"planning": "glm", "implementing": "claude"})
-	Timeout    time.Duration     // Agent execution timeout
+	AgentName    string            // Which agent to use for all steps (default: auto-detect)
+	DefaultAgent string            // Agent used when neither the task nor a step names one (default: agent.default)
+	StepAgents   map[string]string // Per-step agent overrides (e.g., {"planning": "glm", "implementing": "claude"})
+	Timeout      time.Duration     // Agent execution timeout
+
+	// Consensus review (overrides workflow.review)
+	ReviewAgents       []string // Agents reviewing independently; two or more enable consensus review
+	ReviewMinAgreement int      // Agents that must report a finding for it to block finishing
 
 	// Behavior
 	DryRun       bool // If true, don't apply file changes
@@ -19,14 +26,20 @@ type Options struct {
 	CreateBranch bool // Create git branch for task
 	UseWorktree  bool // Create git worktree for task
 	AutoInit     bool // Auto-initialize workspace if needed
+	StashWIP     bool // Commit uncommitted changes as WIP when setting the active task aside
 
 	// Auto mode (full automation)
 	AutoMode           bool // Enable full automation mode
 	SkipAgentQuestions bool // Skip agent questions, proceed with best guess
 	MaxQualityRetries  int  // Max retries for quality loop (default: 3)
 
+	// CI environment (nil outside CI); runs there are non-interactive
+	CI *CIEnvironment
+
 	// Context preservation
-	IncludeFullContext bool // Include full exploration context from pending question (default: summary only)
+	IncludeFullContext bool        // Include full exploration context from pending question (default: summary only)
+	ContinueSession    bool        // Continue the latest planning session instead of starting a new one
+	ContextMode        ContextMode // Notes and sessions sent to agents (empty uses agent.context)
 
 	// Output
 	Stdout io.Writer // Where to write output (default: os.Stdout)
@@ -38,6 +51,32 @@ type Options struct {
 	// Provider configuration
 	DefaultProvider string // Default provider for bare references (e.g., "file")
 
+	// Monorepo scoping
+	Scope             string // Restrict the task to a repo-relative subdirectory
+	AllowOutsideScope bool   // Permit file changes outside Scope
+
+	// Guardrail policy
+	AllowProtected bool // Permit agent changes to guardrails.protected paths
+
+	// Per-spec implementation
+	Specification int // Implement only this specification number (0 = latest)
+
+	// Spec templates
+	SpecTemplate string // Spec template the planning agent must follow
+	SpecCritique *bool  // Critique planned specifications: nil=defer to config (workflow.spec_critique)
+
+	// Agent response caching
+	ResponseCache *bool // Answer repeated prompts from the cache: nil=defer to config (agent.cache.enabled)
+
+	// Pair-with-agent sessions
+	WatchEdits bool // Pause implementation when files the agent touches are edited manually
+
+	// Multi-repository tasks
+	Repositories []string // Names of configured workspaces to attach to a new task
+
+	// Concurrency
+	LeaseTTL time.Duration // How long a task lease survives without a heartbeat (default: 2m)
+
 	// Naming overrides (CLI flags)
 	ExternalKey           string // Override external key (e.g., "FEATURE-123")
 	TitleOverride         string // Override task title
@@ -49,6 +88,20 @@ type Options struct {
 	OnStateChange func(from, to string)
 	OnProgress    func(message string, percent int)
 	OnError       func(err error)
+
+	// OnEditConflict is called when WatchEdits detects manual edits. The run is
+	// paused until it returns: true resumes, false aborts with ErrEditConflict.
+	OnEditConflict func(files []string) bool
+
+	// OnConflictResolution is called with the agent's resolution of sync
+	// conflicts before it is committed: true commits it, false aborts the
+	// sync. Without it, only auto mode commits resolutions.
+	OnConflictResolution func(report *ConflictReport, resolution *ConflictResolution) bool
+
+	// ValidateConfig checks a changed workspace config before ReloadConfig
+	// applies it; builtInAgents are the registered agents that are not
+	// aliases. Without it, reloaded configs are applied unchecked.
+	ValidateConfig func(cfg *storage.WorkspaceConfig, path string, builtInAgents []string) error
 }
 
 // Option is a functional option for configuring Conductor.
@@ -65,6 +118,7 @@ func DefaultOptions() Options {
 		Stderr:            os.Stderr,
 		WorkDir:           ".",
 		MaxQualityRetries: 3,
+		LeaseTTL:          2 * time.Minute,
 	}
 }
 
@@ -75,6 +129,14 @@ func WithAgent(name string) Option {
 	}
 }
 
+// WithDefaultAgent sets the agent used when neither the task nor a step
+// names one.
+func WithDefaultAgent(name string) Option {
+	return func(o *Options) {
+		o.DefaultAgent = name
+	}
+}
+
 // WithStepAgent sets a specific agent for a workflow step.
 func WithStepAgent(step, agentName string) Option {
 	return func(o *Options) {
@@ -85,6 +147,16 @@ func WithStepAgent(step, agentName string) Option {
 	}
 }
 
+// WithReviewConsensus has agents review independently, with findings
+// reported by at least minAgreement of them blocking finish. A minAgreement
+// of 0 defers to workflow.review.min_agreement.
+func WithReviewConsensus(agents []string, minAgreement int) Option {
+	return func(o *Options) {
+		o.ReviewAgents = agents
+		o.ReviewMinAgreement = minAgreement
+	}
+}
+
 // WithTimeout sets the execution timeout.
 func WithTimeout(d time.Duration) Option {
 	return func(o *Options) {
@@ -124,6 +196,14 @@ func WithUseWorktree(enabled bool) Option {
 	}
 }
 
+// WithStashWIP lets switching or starting tasks park uncommitted changes in
+// a WIP commit on the active task's branch instead of refusing.
+func WithStashWIP(enabled bool) Option {
+	return func(o *Options) {
+		o.StashWIP = enabled
+	}
+}
+
 // WithAutoInit enables auto-initialization of workspace.
 func WithAutoInit(enabled bool) Option {
 	return func(o *Options) {
@@ -141,6 +221,23 @@ func WithAutoMode(enabled bool) Option {
 	}
 }
 
+// WithCI marks the run as executing in a CI environment. CI runs are
+// headless, so it implies auto mode, and bare references resolve against
+// the CI system's provider.
+func WithCI(env *CIEnvironment) Option {
+	return func(o *Options) {
+		o.CI = env
+		if env == nil {
+			return
+		}
+		o.AutoMode = true
+		o.SkipAgentQuestions = true
+		if o.DefaultProvider == "" && env.Provider == CIProviderGitHubActions {
+			o.DefaultProvider = "github"
+		}
+	}
+}
+
 // WithSkipAgentQuestions skips pending questions from agents.
 func WithSkipAgentQuestions(enabled bool) Option {
 	return func(o *Options) {
@@ -162,6 +259,21 @@ func WithIncludeFullContext(enabled bool) Option {
 	}
 }
 
+// WithContextMode sets how much of the task's notes and sessions agents get.
+func WithContextMode(mode ContextMode) Option {
+	return func(o *Options) {
+		o.ContextMode = mode
+	}
+}
+
+// WithContinueSession continues the latest planning session, keeping the
+// conversation with the agent instead of rebuilding the prompt from scratch.
+func WithContinueSession(enabled bool) Option {
+	return func(o *Options) {
+		o.ContinueSession = enabled
+	}
+}
+
 // WithStdout sets the stdout writer.
 func WithStdout(w io.Writer) Option {
 	return func(o *Options) {
@@ -225,6 +337,93 @@ func WithSlugOverride(slug string) Option {
 	}
 }
 
+// WithScope restricts a new task to a repo-relative subdirectory.
+func WithScope(scope string) Option {
+	return func(o *Options) {
+		o.Scope = scope
+	}
+}
+
+// WithAllowOutsideScope permits file changes outside the task scope.
+func WithAllowOutsideScope(allow bool) Option {
+	return func(o *Options) {
+		o.AllowOutsideScope = allow
+	}
+}
+
+// WithAllowProtected permits agent changes to guardrails.protected paths.
+func WithAllowProtected(allow bool) Option {
+	return func(o *Options) {
+		o.AllowProtected = allow
+	}
+}
+
+// WithSpecification implements a single specification instead of the latest one.
+func WithSpecification(number int) Option {
+	return func(o *Options) {
+		o.Specification = number
+	}
+}
+
+// WithSpecTemplate makes the planning agent follow a spec template.
+func WithSpecTemplate(name string) Option {
+	return func(o *Options) {
+		o.SpecTemplate = name
+	}
+}
+
+// WithSpecCritique turns the critic pass over planned specifications on or
+// off, overriding workflow.spec_critique.
+func WithSpecCritique(enabled bool) Option {
+	return func(o *Options) {
+		o.SpecCritique = &enabled
+	}
+}
+
+// WithResponseCache turns the agent response cache on or off, overriding
+// agent.cache.enabled.
+func WithResponseCache(enabled bool) Option {
+	return func(o *Options) {
+		o.ResponseCache = &enabled
+	}
+}
+
+// WithWatchEdits pauses implementation when files the agent touches are edited manually.
+func WithWatchEdits(watch bool) Option {
+	return func(o *Options) {
+		o.WatchEdits = watch
+	}
+}
+
+// WithEditConflictCallback sets the callback that confirms resuming after manual edits.
+func WithEditConflictCallback(fn func(files []string) bool) Option {
+	return func(o *Options) {
+		o.OnEditConflict = fn
+	}
+}
+
+// WithConflictResolutionCallback sets the callback that confirms the agent's
+// resolution of sync conflicts before it is committed.
+func WithConflictResolutionCallback(fn func(report *ConflictReport, resolution *ConflictResolution) bool) Option {
+	return func(o *Options) {
+		o.OnConflictResolution = fn
+	}
+}
+
+// WithRepositories attaches configured secondary repositories to a new task.
+func WithRepositories(names ...string) Option {
+	return func(o *Options) {
+		o.Repositories = names
+	}
+}
+
+// WithLeaseTTL sets how long a task lease survives without a heartbeat.
+func WithLeaseTTL(ttl time.Duration) Option {
+	return func(o *Options) {
+		o.LeaseTTL = ttl
+	}
+}
+
 // WithStateChangeCallback sets the state change callback.
 func WithStateChangeCallback(fn func(from, to string)) Option {
 	return func(o *Options) {
@@ -246,6 +445,14 @@ func WithErrorCallback(fn func(err error)) Option {
 	}
 }
 
+// WithConfigValidator sets the check ReloadConfig runs before applying a
+// changed workspace config.
+func WithConfigValidator(fn func(cfg *storage.WorkspaceConfig, path string, builtInAgents []string) error) Option {
+	return func(o *Options) {
+		o.ValidateConfig = fn
+	}
+}
+
 // Apply applies options to the Options struct.
 func (o *Options) Apply(opts ...Option) {
 	for _, opt := range opts {
@@ -260,12 +467,16 @@ type FinishOptions struct {
 	TargetBranch string // Branch to merge into
 	PushAfter    bool   // Push after merge
 	DeleteWork   *bool  // Delete work directory: nil=defer to config, true=delete, false=keep
+	Sync         *bool  // Sync with the base branch first: nil=defer to config (git.sync_before_finish)
+	Changelog    *bool  // Write a changelog entry: nil=defer to config (workflow.changelog.enabled)
 
 	// PR-related options (for GitHub provider)
 	ForceMerge bool   // Force local merge instead of PR creation
 	DraftPR    bool   // Create PR as draft
 	PRTitle    string // Custom PR title (defaults to task title)
 	PRBody     string // Custom PR body
+
+	SkipReviewGate bool // Finish despite blocking findings of the latest consensus review
 }
 
 // DefaultFinishOptions returns default finish options.
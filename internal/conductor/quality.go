@@ -58,7 +58,7 @@ func (c *Conductor) HasQualityTarget(ctx context.Context) bool {
 
 	// Check if quality target exists using make -q
 	cmd := exec.CommandContext(ctx, "make", "-q", "quality")
-	cmd.Dir = c.workspace.Root()
+	cmd.Dir = c.scopeDir(c.workspace.Root())
 	err := cmd.Run()
 
 	var exitErr *exec.ExitError
@@ -85,33 +85,30 @@ func (c *Conductor) RunQuality(ctx context.Context, opts QualityOptions) (*Quali
 
 	// Get git status before running quality
 	var beforeFiles []string
-	if c.git != nil {
-		files, _ := c.git.Status(ctx)
-		for _, f := range files {
-			beforeFiles = append(beforeFiles, f.Path)
-		}
+	files, _ := c.GetVCS().Status(ctx)
+	for _, f := range files {
+		beforeFiles = append(beforeFiles, f.Path)
 	}
 
 	// Run make quality
 	result.Ran = true
 	cmd := exec.CommandContext(ctx, "make", target)
-	cmd.Dir = c.workspace.Root()
+	cmd.Dir = c.scopeDir(c.workspace.Root())
 
 	output, err := cmd.CombinedOutput()
 	result.Output = string(output)
 
 	if err != nil {
 		result.Passed = false
+		c.recordFailures(lessonSourceQuality, categorizeQualityOutput(result.Output))
 		if !opts.AllowFailure {
 			return result, fmt.Errorf("quality check failed\n\nOutput:\n%s\n\nTo fix:\n  1. Review the output above for specific issues\n  2. Fix the issues and run 'mehr implement' again\n  3. Or use 'mehr finish --no-quality' to proceed", result.Output)
 		}
 	}
 
 	// Check if files changed after running quality
-	if c.git != nil {
-		afterFiles, _ := c.git.Status(ctx)
-		result.FilesChanged = detectChangedFiles(beforeFiles, afterFiles)
-	}
+	afterFiles, _ := c.GetVCS().Status(ctx)
+	result.FilesChanged = detectChangedFiles(beforeFiles, afterFiles)
 
 	// If files changed, prompt user
 	if len(result.FilesChanged) > 0 && !opts.SkipPrompt {
@@ -2,10 +2,16 @@ package conductor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/chaos"
 	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
 )
 
 // createCheckpointIfNeeded creates a git checkpoint if there are changes.
@@ -14,6 +20,8 @@ func (c *Conductor) createCheckpointIfNeeded(ctx context.Context, taskID, messag
 		return nil
 	}
 
+	repos := c.attachedRepos(ctx)
+
 	hasChanges, err := c.git.HasChanges(ctx)
 	if err != nil {
 		// If we can't determine changes, log but continue (treat as no changes)
@@ -22,7 +30,7 @@ func (c *Conductor) createCheckpointIfNeeded(ctx context.Context, taskID, messag
 
 		return nil
 	}
-	if !hasChanges {
+	if !hasChanges && !c.reposHaveChanges(ctx, repos) {
 		return nil
 	}
 
@@ -35,12 +43,49 @@ func (c *Conductor) createCheckpointIfNeeded(ctx context.Context, taskID, messag
 		commitPrefix = fmt.Sprintf("[%s]", taskID)
 	}
 
-	checkpoint, err := c.git.CreateCheckpointWithPrefix(ctx, taskID, message, commitPrefix)
+	if err := chaos.Check(chaos.GitCheckpoint); err != nil {
+		c.logError(fmt.Errorf("create checkpoint: %w", err))
+
+		return nil
+	}
+
+	// Attached repositories are always checkpointed in full so checkpoint
+	// numbers stay aligned across them
+	var paths []string
+	if len(repos) == 0 {
+		var filtered bool
+		paths, filtered, err = c.checkpointPaths(ctx)
+		if err != nil {
+			c.logError(fmt.Errorf("select checkpoint paths: %w", err))
+
+			return nil
+		}
+		if filtered && len(paths) == 0 {
+			c.logVerbosef("No changes selected for a checkpoint by git.checkpoint_* settings")
+
+			return nil
+		}
+	}
+	opts := vcs.CheckpointOptions{Paths: paths}
+
+	var checkpoint *vcs.Checkpoint
+	if len(repos) > 0 {
+		checkpoint, err = c.createRepoCheckpoints(ctx, repos, taskID, message, commitPrefix)
+	} else if generated := c.generateCheckpointMessage(ctx, commitPrefix, message, paths); generated != "" {
+		checkpoint, err = c.git.CreateCheckpointWithMessage(ctx, taskID, generated, opts)
+	} else {
+		checkpoint, err = c.git.CreateCheckpointWithPrefix(ctx, taskID, message, commitPrefix, opts)
+	}
 	if err != nil {
 		c.logError(fmt.Errorf("create checkpoint: %w", err))
+		if errors.Is(err, vcs.ErrSigning) {
+			// Unsigned work would pile up silently; say why nothing was committed
+			c.publishProgress(fmt.Sprintf("Warning: checkpoint not created: %v", err), 0)
+		}
 
 		return nil
 	}
+	c.agentFiles = nil
 
 	return &events.Event{
 		Type: events.TypeCheckpoint,
@@ -70,3 +115,110 @@ func (c *Conductor) saveCurrentSession(taskID string) {
 	c.currentSession = nil
 	c.currentSessionFile = ""
 }
+
+// resumeSession makes the task's latest session of the given type current so
+// a conversation can continue across runs, creating one when none exists.
+func (c *Conductor) resumeSession(taskID, sessionType, agentName string) error {
+	if c.currentSession != nil && c.currentSession.Metadata.Type == sessionType {
+		return nil
+	}
+
+	filename, err := c.workspace.LatestSessionFile(taskID, sessionType)
+	if err != nil {
+		return fmt.Errorf("find %s session: %w", sessionType, err)
+	}
+	if filename != "" {
+		session, err := c.workspace.LoadSession(taskID, filename)
+		if err != nil {
+			return fmt.Errorf("load %s session: %w", sessionType, err)
+		}
+		session.Metadata.EndedAt = time.Time{}
+		c.currentSession = session
+		c.currentSessionFile = filename
+
+		return nil
+	}
+
+	session, filename, err := c.workspace.CreateSession(taskID, sessionType, agentName, c.activeTask.State)
+	if err != nil {
+		return fmt.Errorf("create session: %w", err)
+	}
+	c.currentSession = session
+	c.currentSessionFile = filename
+
+	return nil
+}
+
+// persistCurrentSession writes the current session without ending it.
+func (c *Conductor) persistCurrentSession(taskID string) {
+	if c.currentSession == nil || c.currentSessionFile == "" {
+		return
+	}
+
+	if err := c.workspace.SaveSession(taskID, c.currentSessionFile, c.currentSession); err != nil {
+		c.logError(fmt.Errorf("save session: %w", err))
+	}
+}
+
+// continuationAgent returns the agent resumed into the current session's
+// native conversation. resumed is false when the session has no native ID,
+// was held with a different agent, or the agent cannot resume; callers then
+// replay the session's exchanges in the prompt instead.
+func (c *Conductor) continuationAgent(base agent.Agent) (agent.Agent, bool) {
+	if c.currentSession == nil || c.currentSession.Metadata.Agent != base.Name() {
+		return base, false
+	}
+
+	return agent.Resume(base, c.currentSession.Metadata.AgentSessionID)
+}
+
+// recordExchange appends a message to the current session.
+func (c *Conductor) recordExchange(role, content string) {
+	if c.currentSession == nil || content == "" {
+		return
+	}
+
+	c.currentSession.Exchanges = append(c.currentSession.Exchanges, storage.Exchange{
+		Role:      role,
+		Timestamp: time.Now(),
+		Content:   content,
+	})
+}
+
+// recordAgentSession remembers the agent's native conversation ID so the
+// next turn can resume it.
+func (c *Conductor) recordAgentSession(response *agent.Response) {
+	if c.currentSession == nil || response == nil || response.SessionID == "" {
+		return
+	}
+
+	c.currentSession.Metadata.AgentSessionID = response.SessionID
+}
+
+// latestNote returns the body of the newest entry in notes.md, which holds
+// the user's most recent input such as an answer to an agent question.
+func latestNote(notes string) string {
+	idx := strings.LastIndex(notes, "\n## ")
+	if idx == -1 {
+		return ""
+	}
+
+	entry := notes[idx+len("\n## "):]
+	if nl := strings.Index(entry, "\n"); nl != -1 {
+		return strings.TrimSpace(entry[nl:])
+	}
+
+	return ""
+}
+
+// lastExchangeIs reports whether the last exchange with the given role has
+// the given content, so repeated runs do not record the same input twice.
+func lastExchangeIs(exchanges []storage.Exchange, role, content string) bool {
+	for i := len(exchanges) - 1; i >= 0; i-- {
+		if exchanges[i].Role == role {
+			return exchanges[i].Content == content
+		}
+	}
+
+	return false
+}
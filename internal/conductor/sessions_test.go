@@ -0,0 +1,200 @@
+package conductor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/provider/file"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// resumableAgent is a mock agent that supports native session resume and
+// records the prompts it receives.
+type resumableAgent struct {
+	mockAgent
+
+	resumeID string
+	prompts  *[]string
+	resumes  *[]string
+}
+
+func (a *resumableAgent) Run(ctx context.Context, prompt string) (*agent.Response, error) {
+	*a.prompts = append(*a.prompts, prompt)
+	*a.resumes = append(*a.resumes, a.resumeID)
+
+	return &agent.Response{
+		Summary:   "Updated the plan",
+		Messages:  []string{"Plan details"},
+		SessionID: "native-1",
+	}, nil
+}
+
+func (a *resumableAgent) RunWithCallback(ctx context.Context, prompt string, cb agent.StreamCallback) (*agent.Response, error) {
+	return a.Run(ctx, prompt)
+}
+
+func (a *resumableAgent) WithResume(sessionID string) agent.Agent {
+	resumed := *a
+	resumed.resumeID = sessionID
+
+	return &resumed
+}
+
+// newFileTaskConductor returns an initialized conductor, with the given agent
+// registered as "mock", and the reference of a file task it has not started.
+func newFileTaskConductor(t *testing.T, a agent.Agent, opts ...Option) (*Conductor, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	taskPath := filepath.Join(tmpDir, "task.md")
+	if err := os.WriteFile(taskPath, []byte("# Session task\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	c, err := New(append([]Option{WithWorkDir(tmpDir), WithCreateBranch(false), WithAgent("mock")}, opts...)...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	file.Register(c.GetProviderRegistry())
+	if err := c.GetAgentRegistry().Register(a); err != nil {
+		t.Fatalf("Register agent: %v", err)
+	}
+	if err := c.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	return c, "file:" + taskPath
+}
+
+// newPlanningConductor starts a file task with the given agent registered as "mock".
+func newPlanningConductor(t *testing.T, a agent.Agent, opts ...Option) *Conductor {
+	t.Helper()
+
+	c, reference := newFileTaskConductor(t, a, opts...)
+	if err := c.Start(context.Background(), reference); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	return c
+}
+
+func TestContinuePlanning_NativeResume(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	var prompts, resumes []string
+	c := newPlanningConductor(t, &resumableAgent{mockAgent: mockAgent{name: "mock"}, prompts: &prompts, resumes: &resumes})
+	ctx := context.Background()
+
+	for _, input := range []string{"Use PostgreSQL", "Add a migration step"} {
+		if _, err := c.ContinuePlanning(ctx, input); err != nil {
+			t.Fatalf("ContinuePlanning(%q): %v", input, err)
+		}
+	}
+
+	if resumes[0] != "" || resumes[1] != "native-1" {
+		t.Errorf("resumed sessions = %q, want first fresh then native-1", resumes)
+	}
+	if !strings.Contains(prompts[0], "## Source Content") {
+		t.Error("first turn should send the full task context")
+	}
+	if strings.Contains(prompts[1], "## Source Content") || !strings.Contains(prompts[1], "Add a migration step") {
+		t.Errorf("resumed turn should only send the follow-up, got:\n%s", prompts[1])
+	}
+
+	filename, _ := c.GetWorkspace().LatestSessionFile(c.GetActiveTask().ID, "planning")
+	session, err := c.GetWorkspace().LoadSession(c.GetActiveTask().ID, filename)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if session.Metadata.AgentSessionID != "native-1" {
+		t.Errorf("agent session ID = %q, want %q", session.Metadata.AgentSessionID, "native-1")
+	}
+}
+
+func TestRunPlanning_ContinueSessionReplaysHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	c := newPlanningConductor(t, &mockAgent{name: "mock"}, WithContinueSession(true))
+	ctx := context.Background()
+	taskID := c.GetActiveTask().ID
+	ws := c.GetWorkspace()
+
+	// An earlier planning conversation with an agent that cannot resume natively
+	session, filename, err := ws.CreateSession(taskID, "planning", "mock", "idle")
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	session.Exchanges = []storage.Exchange{
+		{Role: "user", Content: "Prefer PostgreSQL"},
+		{Role: "agent", Content: "Planned a PostgreSQL storage layer"},
+	}
+	if err := ws.SaveSession(taskID, filename, session); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+
+	if err := c.Plan(ctx); err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if err := c.RunPlanning(ctx); err != nil {
+		t.Fatalf("RunPlanning: %v", err)
+	}
+
+	if latest, _ := ws.LatestSessionFile(taskID, "planning"); latest != filename {
+		t.Errorf("latest session = %q, want continued session %q", latest, filename)
+	}
+	continued, err := ws.LoadSession(taskID, filename)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if len(continued.Exchanges) != 3 || continued.Exchanges[2].Role != "agent" {
+		t.Errorf("exchanges = %+v, want agent reply appended", continued.Exchanges)
+	}
+}
+
+func TestSessionHistoryPrompt(t *testing.T) {
+	if got := sessionHistoryPrompt("History", nil); got != "" {
+		t.Errorf("sessionHistoryPrompt(nil) = %q, want empty", got)
+	}
+
+	got := sessionHistoryPrompt("History", []storage.Exchange{
+		{Role: "user", Content: "question"},
+		{Role: "agent", Content: "answer"},
+	})
+	for _, want := range []string{"## History", "**User:** question", "**Agent:** answer"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("prompt missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestLatestNote(t *testing.T) {
+	tests := []struct {
+		name  string
+		notes string
+		want  string
+	}{
+		{name: "header only", notes: "# Notes\n\n", want: ""},
+		{name: "single", notes: "# Notes\n\n\n## 2025-01-01 10:00:00\n\nUse PostgreSQL\n", want: "Use PostgreSQL"},
+		{
+			name:  "newest wins",
+			notes: "# Notes\n\n\n## 2025-01-01 10:00:00\n\nfirst\n\n## 2025-01-02 10:00:00 [waiting]\n\nsecond\n",
+			want:  "second",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := latestNote(tt.notes); got != tt.want {
+				t.Errorf("latestNote() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
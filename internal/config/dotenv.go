@@ -3,24 +3,27 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/joho/godotenv"
 )
 
 const (
-	// MehrhofDir is the name of the mehrhof configuration directory.
+	// MehrhofDir is the default name of the mehrhof task root directory
+	// (see TaskDir).
 	MehrhofDir = ".mehrhof"
 	// EnvFileName is the name of the environment variables file.
 	EnvFileName = ".env"
 )
 
-// LoadDotEnv loads environment variables from .mehrhof/.env if it exists.
+// LoadDotEnv loads environment variables from .mehrhof/.env (in the task
+// root directory, see TaskDir) if it exists.
 // It uses godotenv.Load() which respects existing environment variables
 // (system env vars take priority over .env values).
 // Returns nil if the file doesn't exist (not an error condition).
 // Returns error only if the file exists but cannot be parsed.
 func LoadDotEnv(baseDir string) error {
-	envPath := filepath.Join(baseDir, MehrhofDir, EnvFileName)
+	envPath := filepath.Join(TaskRoot(baseDir), EnvFileName)
 
 	// Check if file exists - silently skip if not
 	if _, err := os.Stat(envPath); os.IsNotExist(err) {
@@ -40,3 +43,48 @@ func LoadDotEnvFromCwd() error {
 
 	return LoadDotEnv(cwd)
 }
+
+// ReadDotEnv returns the variables in .mehrhof/.env without changing the
+// environment, or nil when the file doesn't exist.
+func ReadDotEnv(baseDir string) (map[string]string, error) {
+	vars, err := godotenv.Read(filepath.Join(TaskRoot(baseDir), EnvFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return vars, err
+}
+
+// ReloadDotEnv applies a changed .env file to the environment: variables
+// that still hold their previous .env value (or are unset) take the new
+// one, and variables removed from the file are unset. Variables the system
+// environment overrides are left alone, as LoadDotEnv does. It returns the
+// names of the variables it changed.
+func ReloadDotEnv(previous, current map[string]string) []string {
+	var changed []string
+
+	for name, value := range current {
+		existing, set := os.LookupEnv(name)
+		if old, inFile := previous[name]; set && (!inFile || existing != old) {
+			continue // Set by the system environment
+		}
+		if existing == value {
+			continue
+		}
+		_ = os.Setenv(name, value)
+		changed = append(changed, name)
+	}
+
+	for name, old := range previous {
+		if _, kept := current[name]; kept {
+			continue
+		}
+		if existing, set := os.LookupEnv(name); set && existing == old {
+			_ = os.Unsetenv(name)
+			changed = append(changed, name)
+		}
+	}
+	slices.Sort(changed)
+
+	return changed
+}
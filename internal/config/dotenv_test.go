@@ -193,3 +193,43 @@ func TestLoadDotEnvFromCwd_NoEnvFile(t *testing.T) {
 		t.Errorf("expected nil error when .env doesn't exist, got: %v", err)
 	}
 }
+
+func TestReloadDotEnv(t *testing.T) {
+	testSetenv(t, "TEST_RELOAD_CHANGED", "old")
+	testSetenv(t, "TEST_RELOAD_REMOVED", "old")
+	testSetenv(t, "TEST_RELOAD_SYSTEM", "from_system")
+	testSetenv(t, "TEST_RELOAD_ADDED", "")
+	testUnsetenv(t, "TEST_RELOAD_ADDED")
+
+	previous := map[string]string{
+		"TEST_RELOAD_CHANGED": "old",
+		"TEST_RELOAD_REMOVED": "old",
+		"TEST_RELOAD_SYSTEM":  "old",
+	}
+	current := map[string]string{
+		"TEST_RELOAD_CHANGED": "new",
+		"TEST_RELOAD_SYSTEM":  "new",
+		"TEST_RELOAD_ADDED":   "added",
+	}
+
+	changed := ReloadDotEnv(previous, current)
+
+	want := []string{"TEST_RELOAD_ADDED", "TEST_RELOAD_CHANGED", "TEST_RELOAD_REMOVED"}
+	if len(changed) != len(want) {
+		t.Fatalf("ReloadDotEnv() = %v, want %v", changed, want)
+	}
+	for i := range want {
+		if changed[i] != want[i] {
+			t.Fatalf("ReloadDotEnv() = %v, want %v", changed, want)
+		}
+	}
+	if got := os.Getenv("TEST_RELOAD_CHANGED"); got != "new" {
+		t.Errorf("TEST_RELOAD_CHANGED = %q, want new", got)
+	}
+	if _, ok := os.LookupEnv("TEST_RELOAD_REMOVED"); ok {
+		t.Error("TEST_RELOAD_REMOVED is still set after removal from .env")
+	}
+	if got := os.Getenv("TEST_RELOAD_SYSTEM"); got != "from_system" {
+		t.Errorf("TEST_RELOAD_SYSTEM = %q, want the system value kept", got)
+	}
+}
@@ -14,6 +14,9 @@ type Settings struct {
 	// Preferred agent (overrides config default)
 	PreferredAgent string `json:"preferred_agent,omitempty"`
 
+	// Task root directory relative to each repository (default .mehrhof)
+	TaskDir string `json:"task_dir,omitempty"`
+
 	// Default target branch for merges
 	TargetBranch string `json:"target_branch,omitempty"`
 
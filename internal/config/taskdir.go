@@ -0,0 +1,68 @@
+package config
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// TaskDirEnv names the environment variable overriding the task root
+// directory, for teams whose conventions reserve another name than .mehrhof.
+const TaskDirEnv = "MEHR_TASK_DIR"
+
+// ErrInvalidTaskDir is returned for task root directories outside the repository.
+var ErrInvalidTaskDir = errors.New("task directory must be a relative path inside the repository")
+
+var (
+	settingsTaskDirOnce sync.Once
+	settingsTaskDir     string
+)
+
+// TaskDir returns the task root directory relative to the repository root,
+// in slash form. It is $MEHR_TASK_DIR when set, then task_dir in the user
+// settings, then .mehrhof. An invalid override is reported and ignored.
+func TaskDir() string {
+	dir, source := os.Getenv(TaskDirEnv), TaskDirEnv
+	if dir == "" {
+		settingsTaskDirOnce.Do(func() {
+			if settings, err := LoadSettings(); err == nil {
+				settingsTaskDir = settings.TaskDir
+			}
+		})
+		dir, source = settingsTaskDir, SettingsPath()
+	}
+	if dir == "" {
+		return MehrhofDir
+	}
+
+	if err := ValidateTaskDir(dir); err != nil {
+		slog.Warn("ignoring task directory override", "source", source, "dir", dir, "error", err)
+
+		return MehrhofDir
+	}
+
+	return path.Clean(filepath.ToSlash(dir))
+}
+
+// TaskRoot returns the absolute task root directory of a repository.
+func TaskRoot(repoRoot string) string {
+	return filepath.Join(repoRoot, filepath.FromSlash(TaskDir()))
+}
+
+// ValidateTaskDir checks that a task root directory stays inside the repository.
+func ValidateTaskDir(dir string) error {
+	slashed := filepath.ToSlash(dir)
+	clean := path.Clean(slashed)
+	switch {
+	case dir == "", filepath.IsAbs(dir), strings.HasPrefix(slashed, "/"), strings.HasPrefix(dir, "~"):
+		return ErrInvalidTaskDir
+	case clean == "." || clean == ".." || strings.HasPrefix(clean, "../"):
+		return ErrInvalidTaskDir
+	}
+
+	return nil
+}
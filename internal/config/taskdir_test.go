@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateTaskDir(t *testing.T) {
+	tests := []struct {
+		dir     string
+		wantErr bool
+	}{
+		{".ai-tasks", false},
+		{"tools/mehrhof", false},
+		{"./.tasks/", false},
+		{"", true},
+		{".", true},
+		{"..", true},
+		{"../shared", true},
+		{"a/../../b", true},
+		{"/tmp/tasks", true},
+		{"~/tasks", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			if err := ValidateTaskDir(tt.dir); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTaskDir(%q) error = %v, wantErr %v", tt.dir, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTaskDir(t *testing.T) {
+	t.Setenv(TaskDirEnv, "./.ai-tasks/")
+	if got := TaskDir(); got != ".ai-tasks" {
+		t.Errorf("TaskDir() = %q, want .ai-tasks", got)
+	}
+	if got, want := TaskRoot("/repo"), filepath.Join("/repo", ".ai-tasks"); got != want {
+		t.Errorf("TaskRoot() = %q, want %q", got, want)
+	}
+
+	t.Setenv(TaskDirEnv, "../outside")
+	if got := TaskDir(); got != MehrhofDir {
+		t.Errorf("TaskDir() with invalid override = %q, want %q", got, MehrhofDir)
+	}
+}
+
+func TestLoadDotEnv_TaskDirOverride(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv(TaskDirEnv, ".ai-tasks")
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".ai-tasks"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, ".ai-tasks", EnvFileName), []byte("TEST_DOTENV_TASK_DIR=found\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	testUnsetenv(t, "TEST_DOTENV_TASK_DIR")
+	defer testUnsetenv(t, "TEST_DOTENV_TASK_DIR")
+
+	if err := LoadDotEnv(tmpDir); err != nil {
+		t.Fatalf("LoadDotEnv: %v", err)
+	}
+	if got := os.Getenv("TEST_DOTENV_TASK_DIR"); got != "found" {
+		t.Errorf("TEST_DOTENV_TASK_DIR = %q, want found", got)
+	}
+}
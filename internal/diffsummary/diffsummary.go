@@ -0,0 +1,285 @@
+// Package diffsummary produces language-aware structural summaries of file
+// changes: which functions, methods and types were added, modified or
+// removed, and for Go packages which exported declarations changed.
+package diffsummary
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// Operations recorded in a file summary.
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// Changes to exported declarations.
+const (
+	APIAdded   = "added"
+	APIRemoved = "removed"
+	APIChanged = "changed"
+)
+
+// symbol is a top-level declaration extracted from a source file.
+type symbol struct {
+	Decl     string // Declaration header, e.g. "func (*Server) Run(ctx context.Context) error"
+	Body     string // Full source of the declaration, used to detect modifications
+	Exported bool   // Part of the package's public API
+}
+
+// extractor returns the top-level declarations of a source file keyed by
+// their symbol name, e.g. "func New" or "type Config". ok is false when the
+// content could not be analyzed.
+type extractor func(path, content string) (symbols map[string]symbol, ok bool)
+
+// languages maps file extensions to a language name and its extractor.
+var languages = map[string]struct {
+	name    string
+	extract extractor
+}{
+	".go":  {"Go", goSymbols},
+	".py":  {"Python", patternSymbols(pythonPatterns)},
+	".js":  {"JavaScript", patternSymbols(scriptPatterns)},
+	".jsx": {"JavaScript", patternSymbols(scriptPatterns)},
+	".mjs": {"JavaScript", patternSymbols(scriptPatterns)},
+	".ts":  {"TypeScript", patternSymbols(scriptPatterns)},
+	".tsx": {"TypeScript", patternSymbols(scriptPatterns)},
+	".php": {"PHP", patternSymbols(phpPatterns)},
+	".rs":  {"Rust", patternSymbols(rustPatterns)},
+}
+
+// Language returns the language name for a file path, or "" when the file
+// type has no structural analyzer.
+func Language(path string) string {
+	return languages[strings.ToLower(filepath.Ext(path))].name
+}
+
+// Declaration is a top-level declaration of a source file.
+type Declaration struct {
+	Name     string // Symbol name, e.g. "func New" or "type Config"
+	Exported bool   // Part of a Go package's public API
+}
+
+// Declarations returns the top-level declarations of a source file sorted by
+// name. ok is false for unsupported languages and files that fail to parse.
+func Declarations(path, content string) ([]Declaration, bool) {
+	lang, ok := languages[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return nil, false
+	}
+	symbols, ok := lang.extract(path, content)
+	if !ok {
+		return nil, false
+	}
+
+	decls := make([]Declaration, 0, len(symbols))
+	for name, sym := range symbols {
+		decls = append(decls, Declaration{Name: name, Exported: sym.Exported})
+	}
+	slices.SortFunc(decls, func(a, b Declaration) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return decls, true
+}
+
+// Summarize compares the content of a file before and after a change. before
+// is empty for created files and after is empty for deleted files. Files in
+// unsupported languages, or that fail to parse, are summarized by operation
+// only.
+func Summarize(path, before, after string) storage.FileSummary {
+	summary := storage.FileSummary{
+		Path:      filepath.ToSlash(path),
+		Operation: OpUpdate,
+	}
+	switch {
+	case before == "" && after != "":
+		summary.Operation = OpCreate
+	case after == "" && before != "":
+		summary.Operation = OpDelete
+	}
+
+	lang, ok := languages[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return summary
+	}
+	summary.Language = lang.name
+
+	oldSymbols, ok := lang.extract(path, before)
+	if !ok {
+		return summary
+	}
+	newSymbols, ok := lang.extract(path, after)
+	if !ok {
+		return summary
+	}
+
+	for name, sym := range newSymbols {
+		old, existed := oldSymbols[name]
+		switch {
+		case !existed:
+			summary.Added = append(summary.Added, name)
+			if sym.Exported {
+				summary.API = append(summary.API, storage.APIChange{Change: APIAdded, Symbol: name, After: sym.Decl})
+			}
+		case old.Body != sym.Body:
+			summary.Modified = append(summary.Modified, name)
+			if (old.Exported || sym.Exported) && old.Decl != sym.Decl {
+				summary.API = append(summary.API, storage.APIChange{Change: APIChanged, Symbol: name, Before: old.Decl, After: sym.Decl})
+			}
+		}
+	}
+	for name, sym := range oldSymbols {
+		if _, ok := newSymbols[name]; ok {
+			continue
+		}
+		summary.Removed = append(summary.Removed, name)
+		if sym.Exported {
+			summary.API = append(summary.API, storage.APIChange{Change: APIRemoved, Symbol: name, Before: sym.Decl})
+		}
+	}
+
+	slices.Sort(summary.Added)
+	slices.Sort(summary.Modified)
+	slices.Sort(summary.Removed)
+	slices.SortFunc(summary.API, func(a, b storage.APIChange) int {
+		return strings.Compare(a.Symbol, b.Symbol)
+	})
+
+	return summary
+}
+
+// Merge combines the summaries of successive changes into one summary per
+// file, in first-seen order. A symbol added and later modified stays added;
+// one added and later removed disappears.
+func Merge(summaries ...[]storage.FileSummary) []storage.FileSummary {
+	var merged []storage.FileSummary
+	index := make(map[string]int)
+
+	for _, batch := range summaries {
+		for _, s := range batch {
+			i, ok := index[s.Path]
+			if !ok {
+				index[s.Path] = len(merged)
+				merged = append(merged, s)
+
+				continue
+			}
+			merged[i] = mergeFile(merged[i], s)
+		}
+	}
+
+	return merged
+}
+
+// mergeFile applies a later summary of a file on top of an earlier one.
+func mergeFile(prev, next storage.FileSummary) storage.FileSummary {
+	out := storage.FileSummary{
+		Path:      prev.Path,
+		Language:  next.Language,
+		Operation: next.Operation,
+	}
+	switch {
+	case prev.Operation == OpCreate && next.Operation != OpDelete:
+		out.Operation = OpCreate
+	case prev.Operation == OpDelete && next.Operation == OpCreate:
+		out.Operation = OpUpdate
+	}
+
+	added := slices.Clone(prev.Added)
+	modified := slices.Clone(prev.Modified)
+	removed := slices.Clone(prev.Removed)
+
+	for _, name := range next.Added {
+		if i := slices.Index(removed, name); i >= 0 {
+			removed = slices.Delete(removed, i, i+1)
+			modified = appendUnique(modified, name)
+		} else {
+			added = appendUnique(added, name)
+		}
+	}
+	for _, name := range next.Modified {
+		if !slices.Contains(added, name) {
+			modified = appendUnique(modified, name)
+		}
+	}
+	for _, name := range next.Removed {
+		if i := slices.Index(added, name); i >= 0 {
+			added = slices.Delete(added, i, i+1)
+
+			continue
+		}
+		modified = slices.DeleteFunc(modified, func(s string) bool { return s == name })
+		removed = appendUnique(removed, name)
+	}
+
+	slices.Sort(added)
+	slices.Sort(modified)
+	slices.Sort(removed)
+	out.Added, out.Modified, out.Removed = nilIfEmpty(added), nilIfEmpty(modified), nilIfEmpty(removed)
+	out.API = mergeAPI(prev.API, next.API)
+
+	return out
+}
+
+// mergeAPI combines exported API changes so each symbol is reported once,
+// relative to its state before the first change.
+func mergeAPI(prev, next []storage.APIChange) []storage.APIChange {
+	out := slices.Clone(prev)
+
+	for _, change := range next {
+		i := slices.IndexFunc(out, func(c storage.APIChange) bool { return c.Symbol == change.Symbol })
+		if i < 0 {
+			out = append(out, change)
+
+			continue
+		}
+
+		earlier := out[i]
+		switch {
+		case earlier.Change == APIAdded && change.Change == APIRemoved:
+			out = slices.Delete(out, i, i+1)
+
+			continue
+		case earlier.Change == APIAdded:
+			earlier.After = change.After
+		case change.Change == APIRemoved:
+			earlier.Change, earlier.After = APIRemoved, ""
+		default:
+			earlier.Change, earlier.After = APIChanged, change.After
+		}
+		if earlier.Change == APIChanged && earlier.Before == earlier.After {
+			out = slices.Delete(out, i, i+1)
+
+			continue
+		}
+		out[i] = earlier
+	}
+
+	slices.SortFunc(out, func(a, b storage.APIChange) int {
+		return strings.Compare(a.Symbol, b.Symbol)
+	})
+
+	return nilIfEmpty(out)
+}
+
+func appendUnique(list []string, name string) []string {
+	if slices.Contains(list, name) {
+		return list
+	}
+
+	return append(list, name)
+}
+
+func nilIfEmpty[T any](list []T) []T {
+	if len(list) == 0 {
+		return nil
+	}
+
+	return list
+}
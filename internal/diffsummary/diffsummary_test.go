@@ -0,0 +1,214 @@
+package diffsummary
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+const goBefore = `package server
+
+const Version = "1"
+
+type Config struct {
+	Addr string
+}
+
+type Server struct{}
+
+func New(cfg Config) *Server { return &Server{} }
+
+func (s *Server) Run() error { return nil }
+
+func (s *Server) Stop() {}
+
+func helper() int { return 1 }
+`
+
+const goAfter = `package server
+
+const Version = "1"
+
+type Config struct {
+	Addr    string
+	Timeout int
+}
+
+type Server struct{}
+
+func New(cfg Config, opts ...Option) *Server { return &Server{} }
+
+func (s *Server) Run() error {
+	return s.serve()
+}
+
+func (s *Server) serve() error { return nil }
+
+type Option func(*Server)
+
+func helper() int { return 2 }
+`
+
+func TestSummarize_Go(t *testing.T) {
+	got := Summarize("internal/server/server.go", goBefore, goAfter)
+
+	if got.Language != "Go" || got.Operation != OpUpdate {
+		t.Errorf("language/operation = %q/%q", got.Language, got.Operation)
+	}
+	if want := []string{"func (*Server) serve", "type Option"}; !slices.Equal(got.Added, want) {
+		t.Errorf("Added = %v, want %v", got.Added, want)
+	}
+	if want := []string{"func (*Server) Run", "func New", "func helper", "type Config"}; !slices.Equal(got.Modified, want) {
+		t.Errorf("Modified = %v, want %v", got.Modified, want)
+	}
+	if want := []string{"func (*Server) Stop"}; !slices.Equal(got.Removed, want) {
+		t.Errorf("Removed = %v, want %v", got.Removed, want)
+	}
+
+	api := make(map[string]string)
+	for _, change := range got.API {
+		api[change.Symbol] = change.Change
+	}
+	want := map[string]string{
+		"func (*Server) Stop": APIRemoved,
+		"func New":            APIChanged,
+		"type Config":         APIChanged,
+		"type Option":         APIAdded,
+	}
+	if len(api) != len(want) {
+		t.Errorf("API = %+v, want %v", got.API, want)
+	}
+	for symbol, change := range want {
+		if api[symbol] != change {
+			t.Errorf("API[%q] = %q, want %q", symbol, api[symbol], change)
+		}
+	}
+}
+
+func TestSummarize_GoNotPublic(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+	}{
+		{name: "package main", path: "cmd/tool/main.go", content: "package main\n\nfunc Run() {}\n"},
+		{name: "test file", path: "server/server_test.go", content: "package server\n\nfunc TestRun(t *testing.T) {}\n"},
+		{name: "unexported receiver", path: "server/server.go", content: "package server\n\nfunc (h *handler) Serve() {}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Summarize(tt.path, "", tt.content)
+			if got.Operation != OpCreate || len(got.Added) != 1 {
+				t.Errorf("summary = %+v, want one added symbol", got)
+			}
+			if len(got.API) != 0 {
+				t.Errorf("API = %+v, want none", got.API)
+			}
+		})
+	}
+}
+
+func TestSummarize_Fallbacks(t *testing.T) {
+	t.Run("python", func(t *testing.T) {
+		before := "class Parser:\n    def parse(self):\n        return 1\n"
+		after := "class Parser:\n    def parse(self):\n        return 2\n\nasync def fetch():\n    pass\n"
+
+		got := Summarize("app/parser.py", before, after)
+		if got.Language != "Python" || !slices.Equal(got.Added, []string{"def fetch"}) || !slices.Equal(got.Modified, []string{"def parse"}) {
+			t.Errorf("summary = %+v", got)
+		}
+	})
+
+	t.Run("typescript", func(t *testing.T) {
+		after := "export function render() {}\nexport const useStore = () => {}\nexport interface Props {}\n"
+
+		got := Summarize("web/app.ts", "", after)
+		if want := []string{"function render", "function useStore", "interface Props"}; !slices.Equal(got.Added, want) {
+			t.Errorf("Added = %v, want %v", got.Added, want)
+		}
+	})
+
+	t.Run("unparseable go", func(t *testing.T) {
+		got := Summarize("broken.go", "package x\n", "package x\nfunc {")
+		if got.Language != "Go" || got.Added != nil || got.Modified != nil {
+			t.Errorf("summary = %+v, want operation only", got)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		got := Summarize("README.md", "old", "")
+		if got.Language != "" || got.Operation != OpDelete {
+			t.Errorf("summary = %+v", got)
+		}
+	})
+}
+
+func TestDeclarations(t *testing.T) {
+	decls, ok := Declarations("server/server.go", goBefore)
+	if !ok {
+		t.Fatal("Declarations(go): not ok")
+	}
+	var exported []string
+	for _, d := range decls {
+		if d.Exported {
+			exported = append(exported, d.Name)
+		}
+	}
+	if want := []string{"const Version", "func (*Server) Run", "func (*Server) Stop", "func New", "type Config", "type Server"}; !slices.Equal(exported, want) {
+		t.Errorf("exported = %v, want %v", exported, want)
+	}
+
+	if decls, ok := Declarations("app/parser.py", "class Parser:\n    def parse(self):\n        pass\n"); !ok || len(decls) != 2 || decls[0].Name != "class Parser" {
+		t.Errorf("Declarations(python) = %v, %v", decls, ok)
+	}
+	if _, ok := Declarations("README.md", "# Title"); ok {
+		t.Error("Declarations(markdown): want not ok")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	first := []storage.FileSummary{{
+		Path:      "pkg/a.go",
+		Operation: OpCreate,
+		Added:     []string{"func A", "func B"},
+		API: []storage.APIChange{
+			{Change: APIAdded, Symbol: "func A", After: "func A()"},
+			{Change: APIAdded, Symbol: "func B", After: "func B()"},
+		},
+	}}
+	second := []storage.FileSummary{
+		{
+			Path:      "pkg/a.go",
+			Operation: OpUpdate,
+			Added:     []string{"func C"},
+			Modified:  []string{"func A"},
+			Removed:   []string{"func B"},
+			API: []storage.APIChange{
+				{Change: APIChanged, Symbol: "func A", Before: "func A()", After: "func A(n int)"},
+				{Change: APIRemoved, Symbol: "func B", Before: "func B()"},
+			},
+		},
+		{Path: "pkg/b.go", Operation: OpUpdate, Modified: []string{"func D"}},
+	}
+
+	got := Merge(first, second)
+	if len(got) != 2 {
+		t.Fatalf("Merge() = %d files, want 2", len(got))
+	}
+
+	a := got[0]
+	if a.Operation != OpCreate {
+		t.Errorf("Operation = %q, want %q", a.Operation, OpCreate)
+	}
+	if !slices.Equal(a.Added, []string{"func A", "func C"}) || a.Modified != nil || a.Removed != nil {
+		t.Errorf("symbols = added %v, modified %v, removed %v", a.Added, a.Modified, a.Removed)
+	}
+	if len(a.API) != 1 || a.API[0].Change != APIAdded || a.API[0].After != "func A(n int)" {
+		t.Errorf("API = %+v, want func A added with its final signature", a.API)
+	}
+	if got[1].Path != "pkg/b.go" {
+		t.Errorf("second file = %q, want pkg/b.go", got[1].Path)
+	}
+}
@@ -0,0 +1,104 @@
+package diffsummary
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strings"
+)
+
+// goSymbols extracts top-level functions, methods, types, constants and
+// variables from Go source. Exported declarations in _test.go files and in
+// package main are not part of an importable API.
+func goSymbols(path, content string) (map[string]symbol, bool) {
+	symbols := make(map[string]symbol)
+	if content == "" {
+		return symbols, true
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, false
+	}
+	public := file.Name.Name != "main" && !strings.HasSuffix(path, "_test.go")
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			name := "func " + d.Name.Name
+			exported := d.Name.IsExported()
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				recv := nodeString(fset, d.Recv.List[0].Type)
+				name = "func (" + recv + ") " + d.Name.Name
+				exported = exported && ast.IsExported(receiverType(d.Recv.List[0].Type))
+			}
+
+			header := *d
+			header.Doc, header.Body = nil, nil
+			symbols[name] = symbol{
+				Decl:     nodeString(fset, &header),
+				Body:     nodeString(fset, d),
+				Exported: public && exported,
+			}
+
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					text := "type " + nodeString(fset, s)
+					symbols["type "+s.Name.Name] = symbol{
+						Decl:     text,
+						Body:     text,
+						Exported: public && s.Name.IsExported(),
+					}
+
+				case *ast.ValueSpec:
+					for _, ident := range s.Names {
+						if ident.Name == "_" {
+							continue
+						}
+						decl := d.Tok.String() + " " + ident.Name
+						if s.Type != nil {
+							decl += " " + nodeString(fset, s.Type)
+						}
+						symbols[d.Tok.String()+" "+ident.Name] = symbol{
+							Decl:     decl,
+							Body:     d.Tok.String() + " " + nodeString(fset, s),
+							Exported: public && ident.IsExported(),
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return symbols, true
+}
+
+// receiverType returns the base type name of a method receiver.
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+
+	return ""
+}
+
+func nodeString(fset *token.FileSet, node any) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,82 @@
+package diffsummary
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Declaration patterns per language. The first submatch is the symbol kind
+// and the second its name; patterns with a single submatch declare functions.
+var (
+	pythonPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^\s*(?:async\s+)?(def|class)\s+([A-Za-z_]\w*)`),
+	}
+	scriptPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?(function)\*?\s+([A-Za-z_$][\w$]*)`),
+		regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?(class|interface|enum)\s+([A-Za-z_$][\w$]*)`),
+		regexp.MustCompile(`^\s*(?:export\s+)?(type)\s+([A-Za-z_$][\w$]*)\s*(?:<[^=]*>)?\s*=`),
+		regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*=\s*(?:async\s+)?(?:function|\([^)]*\)\s*=>|[A-Za-z_$][\w$]*\s*=>)`),
+	}
+	phpPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^\s*(?:(?:public|protected|private|static|abstract|final)\s+)*(function)\s+&?([A-Za-z_]\w*)`),
+		regexp.MustCompile(`^\s*(?:(?:abstract|final|readonly)\s+)*(class|interface|trait|enum)\s+([A-Za-z_]\w*)`),
+	}
+	rustPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?(?:unsafe\s+)?(fn|struct|enum|trait|type)\s+([A-Za-z_]\w*)`),
+	}
+)
+
+// patternSymbols returns an extractor for languages without a parser in the
+// standard library. Declarations are found line by line, and each symbol's
+// body runs until the next declaration. Nested declarations such as methods
+// are reported by name, so same-named methods of different classes merge.
+func patternSymbols(patterns []*regexp.Regexp) extractor {
+	return func(_, content string) (map[string]symbol, bool) {
+		symbols := make(map[string]symbol)
+		if content == "" {
+			return symbols, true
+		}
+
+		var (
+			current string
+			body    strings.Builder
+		)
+		flush := func() {
+			if current != "" {
+				if _, seen := symbols[current]; !seen {
+					symbols[current] = symbol{Decl: current, Body: body.String()}
+				}
+			}
+			body.Reset()
+		}
+
+		for line := range strings.Lines(content) {
+			if name := matchDecl(patterns, line); name != "" {
+				flush()
+				current = name
+			}
+			if current != "" {
+				body.WriteString(strings.TrimSpace(line))
+				body.WriteByte('\n')
+			}
+		}
+		flush()
+
+		return symbols, true
+	}
+}
+
+// matchDecl returns the symbol name declared on a line, e.g. "def parse".
+func matchDecl(patterns []*regexp.Regexp, line string) string {
+	for _, p := range patterns {
+		m := p.FindStringSubmatch(line)
+		switch len(m) {
+		case 3:
+			return m[1] + " " + m[2]
+		case 2:
+			return "function " + m[1]
+		}
+	}
+
+	return ""
+}
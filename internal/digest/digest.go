@@ -0,0 +1,237 @@
+// Package digest summarizes recent workspace activity (finished and ongoing
+// tasks, pull requests, agent cost and where the time went) as a markdown
+// report for standups and weekly updates.
+package digest
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// maxTimeSinks is how many tasks the time sinks section lists.
+const maxTimeSinks = 5
+
+// Digest is the workspace activity between Since and Until.
+type Digest struct {
+	Since     time.Time
+	Until     time.Time
+	Finished  []Task
+	Ongoing   []Task
+	PRs       []PR
+	Usage     Usage
+	TimeSinks []Task // Tasks that took the most session time, most first
+}
+
+// Task is one task's activity in the digest period.
+type Task struct {
+	ID          string
+	Title       string
+	ExternalKey string
+	FinishedAt  time.Time
+	PRURL       string              // Latest pull request opened for the task
+	CostUSD     float64             // Agent cost in the period
+	Time        time.Duration       // Session time in the period
+	Phases      []storage.PhaseTime // Session time in the period per phase
+}
+
+// PR is a pull request opened in the digest period.
+type PR struct {
+	TaskID string
+	Title  string
+	Number int
+	URL    string
+	Opened time.Time
+}
+
+// Usage is the agent usage in the digest period.
+type Usage struct {
+	Calls   int
+	Tokens  int
+	CostUSD float64
+}
+
+// Build collects the activity of every task in the workspace between since
+// and until. Usage comes from the task usage ledgers; tasks recorded before
+// the ledger existed count their totals when they were updated in the period.
+func Build(ws *storage.Workspace, since, until time.Time) (*Digest, error) {
+	works, err := ws.LoadWorks()
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+
+	d := &Digest{Since: since, Until: until}
+	inPeriod := func(t time.Time) bool { return !t.Before(since) && t.Before(until) }
+
+	for _, work := range works {
+		taskID := work.Metadata.ID
+		task := Task{
+			ID:          taskID,
+			Title:       work.Metadata.Title,
+			ExternalKey: work.Metadata.ExternalKey,
+			FinishedAt:  work.Metadata.FinishedAt,
+		}
+
+		records, err := ws.LoadUsageRecords(taskID)
+		if err != nil {
+			return nil, fmt.Errorf("load usage for %s: %w", taskID, err)
+		}
+		if len(records) == 0 && inPeriod(work.Metadata.UpdatedAt) {
+			records = []storage.UsageRecord{{
+				InputTokens:  work.Costs.TotalInputTokens,
+				OutputTokens: work.Costs.TotalOutputTokens,
+				CostUSD:      work.Costs.TotalCostUSD,
+				Timestamp:    work.Metadata.UpdatedAt,
+			}}
+		}
+		for _, record := range records {
+			if inPeriod(record.Timestamp) {
+				d.Usage.Calls++
+				d.Usage.Tokens += record.InputTokens + record.OutputTokens
+				d.Usage.CostUSD += record.CostUSD
+				task.CostUSD += record.CostUSD
+			}
+		}
+
+		sessions, err := ws.ListSessions(taskID)
+		if err != nil {
+			return nil, fmt.Errorf("list sessions for %s: %w", taskID, err)
+		}
+		sessions = slices.DeleteFunc(sessions, func(s *storage.Session) bool { return !inPeriod(s.Metadata.StartedAt) })
+		task.Phases = storage.PhaseTimes(sessions)
+		task.Time = storage.TotalTime(task.Phases)
+
+		prs, err := ws.ReadEvents(taskID, storage.EventFilter{
+			Until: until,
+			Types: []string{string(events.TypePRCreated)},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("read events for %s: %w", taskID, err)
+		}
+		for _, record := range prs {
+			url, _ := record.Data["pr_url"].(string)
+			task.PRURL = cmp.Or(url, task.PRURL)
+			if record.Timestamp.Before(since) {
+				continue
+			}
+			d.PRs = append(d.PRs, PR{
+				TaskID: taskID,
+				Title:  task.Title,
+				Number: number(record.Data["pr_number"]),
+				URL:    url,
+				Opened: record.Timestamp,
+			})
+		}
+
+		switch {
+		case inPeriod(work.Metadata.FinishedAt):
+			d.Finished = append(d.Finished, task)
+		case work.Metadata.FinishedAt.IsZero() && (inPeriod(work.Metadata.UpdatedAt) || task.Time > 0):
+			d.Ongoing = append(d.Ongoing, task)
+		}
+		if task.Time > 0 {
+			d.TimeSinks = append(d.TimeSinks, task)
+		}
+	}
+
+	slices.SortStableFunc(d.Finished, func(a, b Task) int { return a.FinishedAt.Compare(b.FinishedAt) })
+	slices.SortStableFunc(d.PRs, func(a, b PR) int { return a.Opened.Compare(b.Opened) })
+	slices.SortStableFunc(d.TimeSinks, func(a, b Task) int { return cmp.Compare(b.Time, a.Time) })
+	if len(d.TimeSinks) > maxTimeSinks {
+		d.TimeSinks = d.TimeSinks[:maxTimeSinks]
+	}
+
+	return d, nil
+}
+
+// Markdown renders the digest for pasting into a standup or status document.
+func (d *Digest) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Mehrhof digest: %s to %s\n", d.Since.Format(time.DateOnly), d.Until.Add(-time.Nanosecond).Format(time.DateOnly))
+
+	fmt.Fprintf(&sb, "\n## Finished (%d)\n\n", len(d.Finished))
+	if len(d.Finished) == 0 {
+		sb.WriteString("No tasks finished.\n")
+	}
+	for _, t := range d.Finished {
+		line := "- " + t.label()
+		if t.PRURL != "" {
+			line += " ([PR](" + t.PRURL + "))"
+		}
+		line += ", finished " + t.FinishedAt.Local().Format("Mon Jan 2")
+		if t.CostUSD > 0 {
+			line += fmt.Sprintf(", $%.2f", t.CostUSD)
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	fmt.Fprintf(&sb, "\n## In Progress (%d)\n\n", len(d.Ongoing))
+	if len(d.Ongoing) == 0 {
+		sb.WriteString("No tasks in progress.\n")
+	}
+	for _, t := range d.Ongoing {
+		line := "- " + t.label()
+		if t.Time > 0 {
+			line += ", " + storage.FormatMinutes(t.Time) + " this period"
+		}
+		if t.CostUSD > 0 {
+			line += fmt.Sprintf(", $%.2f", t.CostUSD)
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	fmt.Fprintf(&sb, "\n## Pull Requests (%d)\n\n", len(d.PRs))
+	if len(d.PRs) == 0 {
+		sb.WriteString("No pull requests opened.\n")
+	}
+	for _, pr := range d.PRs {
+		name := "PR"
+		if pr.Number > 0 {
+			name = fmt.Sprintf("PR #%d", pr.Number)
+		}
+		if pr.URL != "" {
+			name = "[" + name + "](" + pr.URL + ")"
+		}
+		fmt.Fprintf(&sb, "- %s: %s\n", name, cmp.Or(pr.Title, pr.TaskID))
+	}
+
+	sb.WriteString("\n## Cost\n\n")
+	fmt.Fprintf(&sb, "$%.2f over %d agent calls (%d tokens)\n", d.Usage.CostUSD, d.Usage.Calls, d.Usage.Tokens)
+
+	if len(d.TimeSinks) > 0 {
+		sb.WriteString("\n## Top Time Sinks\n\n")
+		for i, t := range d.TimeSinks {
+			fmt.Fprintf(&sb, "%d. %s: %s (%s)\n", i+1, t.label(), storage.FormatMinutes(t.Time), storage.FormatPhaseTimes(t.Phases))
+		}
+	}
+
+	return sb.String()
+}
+
+// label names a task by its external key and title.
+func (t Task) label() string {
+	title := cmp.Or(t.Title, t.ID)
+	if t.ExternalKey != "" {
+		return t.ExternalKey + " " + title
+	}
+
+	return title
+}
+
+// number reads an integer from event data, which holds float64 once the
+// event log has been read back from JSON.
+func number(v any) int {
+	switch v := v.(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,108 @@
+package digest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func TestBuild(t *testing.T) {
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+
+	since := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	until := since.AddDate(0, 0, 7)
+	day := func(n int) time.Time { return since.AddDate(0, 0, n).Add(9 * time.Hour) }
+
+	tasks := []struct {
+		id, key  string
+		finished time.Time
+		sessions map[string]int // Phase -> minutes, on the task's day
+		at       time.Time
+	}{
+		{"done", "AUTH-1", day(2), map[string]int{"planning": 20, "implementing": 60}, day(1)},
+		{"wip", "", time.Time{}, map[string]int{"implementing": 90}, day(3)},
+		{"old", "", day(-10), map[string]int{"implementing": 300}, day(-12)},
+	}
+	for _, task := range tasks {
+		work, err := ws.CreateWork(task.id, storage.SourceInfo{Type: "file", Ref: task.id + ".md"})
+		if err != nil {
+			t.Fatalf("CreateWork: %v", err)
+		}
+		work.Metadata.Title = "Task " + task.id
+		work.Metadata.ExternalKey = task.key
+		work.Metadata.FinishedAt = task.finished
+		if err := ws.SaveWork(work); err != nil {
+			t.Fatalf("SaveWork: %v", err)
+		}
+		for phase, minutes := range task.sessions {
+			session, filename, err := ws.CreateSession(task.id, phase, "claude", phase)
+			if err != nil {
+				t.Fatalf("CreateSession: %v", err)
+			}
+			session.Metadata.StartedAt = task.at
+			session.Metadata.EndedAt = task.at.Add(time.Duration(minutes) * time.Minute)
+			if err := ws.SaveSession(task.id, filename, session); err != nil {
+				t.Fatalf("SaveSession: %v", err)
+			}
+		}
+		record := storage.UsageRecord{Timestamp: task.at, Step: "implementing", InputTokens: 1000, OutputTokens: 100, CostUSD: 1.5}
+		if err := ws.AppendUsageRecord(task.id, record); err != nil {
+			t.Fatalf("AppendUsageRecord: %v", err)
+		}
+	}
+	pr := storage.EventRecord{
+		Timestamp: day(2),
+		Type:      string(events.TypePRCreated),
+		Data:      map[string]any{"pr_number": 7, "pr_url": "https://github.com/acme/api/pull/7"},
+	}
+	if err := ws.AppendEvent("done", pr); err != nil {
+		t.Fatalf("AppendEvent: %v", err)
+	}
+
+	d, err := Build(ws, since, until)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	if len(d.Finished) != 1 || d.Finished[0].ID != "done" || d.Finished[0].PRURL == "" {
+		t.Errorf("Finished = %+v, want the task finished in the period with its PR", d.Finished)
+	}
+	if len(d.Ongoing) != 1 || d.Ongoing[0].ID != "wip" {
+		t.Errorf("Ongoing = %+v", d.Ongoing)
+	}
+	if len(d.PRs) != 1 || d.PRs[0].Number != 7 {
+		t.Errorf("PRs = %+v", d.PRs)
+	}
+	if d.Usage != (Usage{Calls: 2, Tokens: 2200, CostUSD: 3}) {
+		t.Errorf("Usage = %+v, want the two records in the period", d.Usage)
+	}
+	if len(d.TimeSinks) != 2 || d.TimeSinks[0].ID != "wip" || d.TimeSinks[1].Time != 80*time.Minute {
+		t.Errorf("TimeSinks = %+v", d.TimeSinks)
+	}
+
+	md := d.Markdown()
+	for _, want := range []string{
+		"# Mehrhof digest: 2025-03-03 to 2025-03-09",
+		"## Finished (1)\n\n- AUTH-1 Task done ([PR](https://github.com/acme/api/pull/7)), finished ",
+		"## In Progress (1)\n\n- Task wip, 1h30m this period, $1.50",
+		"- [PR #7](https://github.com/acme/api/pull/7): Task done",
+		"$3.00 over 2 agent calls (2200 tokens)",
+		"1. Task wip: 1h30m (implementing 1h30m)",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "Task old") {
+		t.Errorf("markdown lists a task from before the period:\n%s", md)
+	}
+}
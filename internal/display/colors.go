@@ -180,7 +180,7 @@ func ColorState(state, displayName string) string {
 	switch state {
 	case "idle":
 		return Muted(displayName)
-	case "planning", "implementing", "reviewing", "checkpointing":
+	case "planning", "implementing", "reviewing", "documenting", "checkpointing":
 		return Info(displayName)
 	case "done":
 		return Success(displayName)
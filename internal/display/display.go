@@ -14,6 +14,7 @@ var StateDisplay = map[workflow.State]string{
 	workflow.StatePlanning:      "Planning",
 	workflow.StateImplementing:  "Implementing",
 	workflow.StateReviewing:     "Reviewing",
+	workflow.StateDocumenting:   "Documenting",
 	workflow.StateDone:          "Completed",
 	workflow.StateFailed:        "Failed",
 	workflow.StateWaiting:       "Waiting",
@@ -28,6 +29,7 @@ var StateDescription = map[workflow.State]string{
 	workflow.StatePlanning:      "AI is creating specifications",
 	workflow.StateImplementing:  "AI is generating code",
 	workflow.StateReviewing:     "Code review in progress",
+	workflow.StateDocumenting:   "AI is updating documentation",
 	workflow.StateDone:          "Task completed successfully",
 	workflow.StateFailed:        "Task failed with error",
 	workflow.StateWaiting:       "Action required: Awaiting your response",
@@ -43,6 +45,7 @@ var StateAccessiblePrefix = map[workflow.State]string{
 	workflow.StatePlanning:      "[P]", // Planning
 	workflow.StateImplementing:  "[I]", // Implementing
 	workflow.StateReviewing:     "[R]", // Reviewing (R is more intuitive than V)
+	workflow.StateDocumenting:   "[O]", // Documenting (D is taken by Done)
 	workflow.StateDone:          "[D]", // Done
 	workflow.StateFailed:        "[F]", // Failed
 	workflow.StateWaiting:       "[W]", // Waiting
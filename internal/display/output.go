@@ -17,6 +17,8 @@ type TaskInfo struct {
 	Source      string
 	Branch      string
 	Worktree    string
+	Scope       string
+	Repos       []string // Attached secondary repositories (e.g., "frontend (feature/x)")
 	Started     string
 }
 
@@ -86,6 +88,14 @@ func FormatTaskInfo(header string, info TaskInfo, opts TaskInfoOptions) string {
 		sb.WriteString(fmt.Sprintf("  %-10s%s\n", "Worktree:", info.Worktree))
 	}
 
+	if info.Scope != "" {
+		sb.WriteString(fmt.Sprintf("  %-10s%s\n", "Scope:", info.Scope))
+	}
+
+	if len(info.Repos) > 0 {
+		sb.WriteString(fmt.Sprintf("  %-10s%s\n", "Repos:", strings.Join(info.Repos, ", ")))
+	}
+
 	if opts.ShowStarted && info.Started != "" {
 		sb.WriteString(fmt.Sprintf("  %-10s%s\n", "Started:", info.Started))
 	}
@@ -58,6 +58,30 @@ func TestFormatTaskInfo(t *testing.T) {
 				"AI is creating", // State description should not appear in compact mode
 			},
 		},
+		{
+			name:   "scope is shown when set",
+			header: "Task started",
+			info: TaskInfo{
+				TaskID: "abc123",
+				Scope:  "services/api",
+			},
+			opts: DefaultTaskInfoOptions(),
+			contains: []string{
+				"Scope:    services/api",
+			},
+		},
+		{
+			name:   "attached repos are shown when set",
+			header: "Task started",
+			info: TaskInfo{
+				TaskID: "abc123",
+				Repos:  []string{"frontend (feature/x)", "docs"},
+			},
+			opts: DefaultTaskInfoOptions(),
+			contains: []string{
+				"Repos:    frontend (feature/x), docs",
+			},
+		},
 		{
 			name:   "empty fields are hidden",
 			header: "Task started",
@@ -75,6 +99,8 @@ func TestFormatTaskInfo(t *testing.T) {
 				"Key:",
 				"Branch:",
 				"Worktree:",
+				"Scope:",
+				"Repos:",
 			},
 		},
 		{
@@ -2,8 +2,13 @@ package events
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -11,9 +16,31 @@ const (
 	maxAsyncPublishes = 100
 )
 
+// TypeAll subscribes to every event type. Other types containing "*", such
+// as "agent_*", subscribe to the types they match.
+const TypeAll Type = "*"
+
+// ErrNoHistory is returned by Replay and SubscribeFrom when the bus has no
+// event history.
+var ErrNoHistory = errors.New("event bus has no history")
+
 // Handler processes events.
 type Handler func(Event)
 
+// History returns persisted events, oldest first, starting at offset. A
+// negative offset returns the last -offset events.
+type History func(offset int) ([]Event, error)
+
+// Backpressure decides what a buffered subscription does with an event when
+// its buffer is full.
+type Backpressure int
+
+const (
+	DropNewest Backpressure = iota // Discard the event being published
+	DropOldest                     // Discard the oldest buffered event
+	Block                          // Wait for room, holding up the publisher
+)
+
 // Subscription tracks a handler registration.
 type Subscription struct {
 	ID      string
@@ -26,6 +53,9 @@ type Bus struct {
 	mu          sync.RWMutex
 	handlers    map[Type][]Subscription
 	allHandlers []Subscription
+	patterns    []Subscription    // Wildcard subscriptions other than TypeAll
+	queues      map[string]*queue // Buffered subscriptions by ID
+	history     History           // Source for Replay and SubscribeFrom
 	nextID      int
 	// semaphore limits concurrent goroutines in PublishAsync
 	semaphore chan struct{}
@@ -44,15 +74,21 @@ func NewBus() *Bus {
 	return &Bus{
 		handlers:    make(map[Type][]Subscription),
 		allHandlers: make([]Subscription, 0),
+		queues:      make(map[string]*queue),
 		semaphore:   make(chan struct{}, maxAsyncPublishes),
 		ctx:         ctx,
 		cancel:      cancel,
 	}
 }
 
-// Subscribe registers a handler for a specific event type
+// Subscribe registers a handler for a specific event type, or for every
+// type a wildcard such as TypeAll or "agent_*" matches.
 // Returns subscription ID for later unsubscription.
 func (b *Bus) Subscribe(eventType Type, handler Handler) string {
+	if eventType == TypeAll {
+		return b.SubscribeAll(handler)
+	}
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
@@ -65,7 +101,11 @@ func (b *Bus) Subscribe(eventType Type, handler Handler) string {
 		Handler: handler,
 	}
 
-	b.handlers[eventType] = append(b.handlers[eventType], sub)
+	if isPattern(eventType) {
+		b.patterns = append(b.patterns, sub)
+	} else {
+		b.handlers[eventType] = append(b.handlers[eventType], sub)
+	}
 
 	return id
 }
@@ -94,6 +134,20 @@ func (b *Bus) Unsubscribe(id string) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	if q, ok := b.queues[id]; ok {
+		q.close()
+		delete(b.queues, id)
+	}
+
+	// Remove from wildcard handlers
+	for i, sub := range b.patterns {
+		if sub.ID == id {
+			b.patterns = append(b.patterns[:i], b.patterns[i+1:]...)
+
+			return
+		}
+	}
+
 	// Remove from type-specific handlers
 	for eventType, subs := range b.handlers {
 		for i, sub := range subs {
@@ -142,6 +196,13 @@ func (b *Bus) PublishRaw(event Event) {
 	for _, sub := range b.allHandlers {
 		handlers = append(handlers, sub.Handler)
 	}
+
+	// Wildcard handlers
+	for _, sub := range b.patterns {
+		if Matches(sub.Type, event.Type) {
+			handlers = append(handlers, sub.Handler)
+		}
+	}
 	b.mu.RUnlock()
 
 	// Call handlers outside lock to prevent deadlocks
@@ -178,6 +239,11 @@ func (b *Bus) HasSubscribers(eventType Type) bool {
 	if len(b.allHandlers) > 0 {
 		return true
 	}
+	for _, sub := range b.patterns {
+		if Matches(sub.Type, eventType) {
+			return true
+		}
+	}
 
 	return len(b.handlers[eventType]) > 0
 }
@@ -189,6 +255,8 @@ func (b *Bus) Clear() {
 
 	b.handlers = make(map[Type][]Subscription)
 	b.allHandlers = make([]Subscription, 0)
+	b.patterns = nil
+	b.closeQueues()
 }
 
 // Shutdown gracefully shuts down the event bus, waiting for async publishes to complete.
@@ -196,4 +264,206 @@ func (b *Bus) Shutdown() {
 	b.cancel()
 	// Wait for all active async publishes to complete
 	b.wg.Wait()
+
+	b.mu.Lock()
+	b.closeQueues()
+	b.mu.Unlock()
+}
+
+// closeQueues stops every buffered subscription (must hold lock).
+func (b *Bus) closeQueues() {
+	for id, q := range b.queues {
+		q.close()
+		delete(b.queues, id)
+	}
+}
+
+// isPattern reports whether a subscription type is a wildcard.
+func isPattern(t Type) bool {
+	return strings.ContainsAny(string(t), "*?[")
+}
+
+// Matches reports whether an event type matches a subscription type, which
+// may be a wildcard.
+func Matches(pattern, t Type) bool {
+	if pattern == TypeAll || pattern == t {
+		return true
+	}
+	if !isPattern(pattern) {
+		return false
+	}
+	ok, _ := path.Match(string(pattern), string(t))
+
+	return ok
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Buffered subscriptions
+// ─────────────────────────────────────────────────────────────────────────────
+
+// queue feeds a buffered subscription's handler from its own goroutine.
+type queue struct {
+	ch      chan Event
+	policy  Backpressure
+	done    chan struct{}
+	once    sync.Once
+	dropped atomic.Uint64
+}
+
+func (q *queue) push(e Event) {
+	switch q.policy {
+	case Block:
+		select {
+		case q.ch <- e:
+		case <-q.done:
+		}
+	case DropOldest:
+		for {
+			select {
+			case q.ch <- e:
+				return
+			default:
+			}
+			select {
+			case <-q.ch:
+				q.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case q.ch <- e:
+		default:
+			q.dropped.Add(1)
+		}
+	}
+}
+
+func (q *queue) run(handler Handler) {
+	for {
+		select {
+		case e := <-q.ch:
+			handler(e)
+		case <-q.done:
+			return
+		}
+	}
+}
+
+func (q *queue) close() {
+	q.once.Do(func() { close(q.done) })
+}
+
+// SubscribeBuffered registers a handler that runs on its own goroutine, fed
+// by a buffer of size events, so a slow handler never holds up publishers
+// unless policy is Block. Unsubscribe, Clear and Shutdown stop it.
+func (b *Bus) SubscribeBuffered(eventType Type, size int, policy Backpressure, handler Handler) string {
+	q := &queue{ch: make(chan Event, max(size, 1)), policy: policy, done: make(chan struct{})}
+	go q.run(handler)
+
+	id := b.Subscribe(eventType, q.push)
+
+	b.mu.Lock()
+	b.queues[id] = q
+	b.mu.Unlock()
+
+	return id
+}
+
+// Dropped returns how many events a buffered subscription has discarded
+// because its buffer was full.
+func (b *Bus) Dropped(id string) uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if q, ok := b.queues[id]; ok {
+		return q.dropped.Load()
+	}
+
+	return 0
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Replay
+// ─────────────────────────────────────────────────────────────────────────────
+
+// SetHistory sets where Replay and SubscribeFrom read past events from.
+func (b *Bus) SetHistory(history History) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.history = history
+}
+
+// Replay returns past events from offset, as History does.
+func (b *Bus) Replay(offset int) ([]Event, error) {
+	b.mu.RLock()
+	history := b.history
+	b.mu.RUnlock()
+
+	if history == nil {
+		return nil, ErrNoHistory
+	}
+
+	return history(offset)
+}
+
+// SubscribeFrom registers a handler that first receives the past events of
+// eventType from offset, then live ones. Past events are delivered before
+// SubscribeFrom returns. Live events published while the history is read
+// are held back and delivered after it, skipping those not newer than the
+// last past event, which the history already contained.
+func (b *Bus) SubscribeFrom(eventType Type, offset int, handler Handler) (string, error) {
+	var (
+		mu      sync.Mutex
+		pending []Event
+		live    bool
+	)
+	id := b.Subscribe(eventType, func(e Event) {
+		mu.Lock()
+		if !live {
+			pending = append(pending, e)
+			mu.Unlock()
+
+			return
+		}
+		mu.Unlock()
+		handler(e)
+	})
+
+	past, err := b.Replay(offset)
+	if err != nil {
+		b.Unsubscribe(id)
+
+		return "", err
+	}
+
+	var last time.Time
+	for _, e := range past {
+		if e.Timestamp.After(last) {
+			last = e.Timestamp
+		}
+		if Matches(eventType, e.Type) {
+			handler(e)
+		}
+	}
+
+	for {
+		mu.Lock()
+		held := pending
+		pending = nil
+		if len(held) == 0 {
+			live = true
+			mu.Unlock()
+
+			return id, nil
+		}
+		mu.Unlock()
+
+		for _, e := range held {
+			if e.Timestamp.IsZero() || e.Timestamp.After(last) {
+				handler(e)
+			}
+		}
+	}
 }
@@ -1,6 +1,7 @@
 package events
 
 import (
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -612,3 +613,119 @@ func TestPublishAsyncSemaphoreLimiting(t *testing.T) {
 		t.Errorf("max concurrent = %d, should not exceed %d", maxConcurrent.Load(), maxAsyncPublishes)
 	}
 }
+
+func TestSubscribeWildcard(t *testing.T) {
+	bus := NewBus()
+
+	var got []Type
+	bus.Subscribe("operation_*", func(e Event) { got = append(got, e.Type) })
+	id := bus.Subscribe(TypeAll, func(e Event) {})
+
+	bus.PublishRaw(Event{Type: "operation_started"})
+	bus.PublishRaw(Event{Type: TypeProgress})
+	bus.PublishRaw(Event{Type: "operation_failed"})
+
+	if len(got) != 2 || got[0] != "operation_started" || got[1] != "operation_failed" {
+		t.Errorf("wildcard received %v, want operation_started, operation_failed", got)
+	}
+	if !bus.HasSubscribers("operation_completed") {
+		t.Error("HasSubscribers false for a type the wildcard matches")
+	}
+
+	bus.Unsubscribe(id)
+	if bus.HasSubscribers(TypeProgress) {
+		t.Error("TypeAll subscription not removed by Unsubscribe")
+	}
+}
+
+func TestSubscribeBuffered(t *testing.T) {
+	tests := []struct {
+		policy      Backpressure
+		wantDropped uint64
+		wantLast    string
+	}{
+		{policy: DropNewest, wantDropped: 2, wantLast: "2"},
+		{policy: DropOldest, wantDropped: 2, wantLast: "4"},
+		{policy: Block, wantDropped: 0, wantLast: "4"},
+	}
+
+	for _, tt := range tests {
+		bus := NewBus()
+		release := make(chan struct{})
+		received := make(chan string, 10)
+
+		id := bus.SubscribeBuffered(TypeProgress, 2, tt.policy, func(e Event) {
+			<-release
+			received <- e.Data["n"].(string)
+		})
+
+		// The first event is taken by the handler, which then waits;
+		// the buffer holds two of the remaining four
+		bus.PublishRaw(Event{Type: TypeProgress, Data: map[string]any{"n": "0"}})
+		time.Sleep(20 * time.Millisecond)
+		published := make(chan struct{})
+		go func() {
+			for _, n := range []string{"1", "2", "3", "4"} {
+				bus.PublishRaw(Event{Type: TypeProgress, Data: map[string]any{"n": n}})
+			}
+			close(published)
+		}()
+		if tt.policy != Block {
+			<-published
+		}
+		close(release)
+		<-published
+
+		var last string
+		for range 5 - tt.wantDropped {
+			select {
+			case last = <-received:
+			case <-time.After(time.Second):
+				t.Fatalf("policy %d: handler did not receive all buffered events", tt.policy)
+			}
+		}
+		if last != tt.wantLast {
+			t.Errorf("policy %d: last event = %s, want %s", tt.policy, last, tt.wantLast)
+		}
+		if got := bus.Dropped(id); got != tt.wantDropped {
+			t.Errorf("policy %d: Dropped = %d, want %d", tt.policy, got, tt.wantDropped)
+		}
+		bus.Shutdown()
+	}
+}
+
+func TestSubscribeFrom(t *testing.T) {
+	bus := NewBus()
+	if _, err := bus.SubscribeFrom(TypeAll, 0, func(Event) {}); err == nil {
+		t.Fatal("SubscribeFrom without history should fail")
+	}
+
+	start := time.Now().Add(-time.Minute)
+	log := []Event{
+		{Type: TypeStateChanged, Timestamp: start},
+		{Type: TypeProgress, Timestamp: start.Add(time.Second), Data: map[string]any{"n": "1"}},
+		{Type: TypeProgress, Timestamp: start.Add(2 * time.Second), Data: map[string]any{"n": "2"}},
+	}
+	bus.SetHistory(func(offset int) ([]Event, error) {
+		// A live event arrives while the history is read; it is logged
+		// and published at once
+		late := Event{Type: TypeProgress, Timestamp: start.Add(3 * time.Second), Data: map[string]any{"n": "3"}}
+		if len(log) == 3 {
+			log = append(log, late)
+			bus.PublishRaw(late)
+		}
+
+		return log[offset:], nil
+	})
+
+	var got []string
+	if _, err := bus.SubscribeFrom(TypeProgress, 1, func(e Event) { got = append(got, e.Data["n"].(string)) }); err != nil {
+		t.Fatalf("SubscribeFrom: %v", err)
+	}
+	bus.PublishRaw(Event{Type: TypeProgress, Timestamp: time.Now(), Data: map[string]any{"n": "4"}})
+
+	// 3 is in the history and held back as a live event, but sent once
+	if want := "1,2,3,4"; strings.Join(got, ",") != want {
+		t.Errorf("received %v, want %s", got, want)
+	}
+}
@@ -6,13 +6,19 @@ import "time"
 type Type string
 
 const (
-	TypeStateChanged   Type = "state_changed"
-	TypeProgress       Type = "progress"
-	TypeError          Type = "error"
-	TypeFileChanged    Type = "file_changed"
-	TypeAgentMessage   Type = "agent_message"
-	TypeCheckpoint     Type = "checkpoint"
-	TypeBlueprintReady Type = "blueprint_ready"
+	TypeStateChanged    Type = "state_changed"
+	TypeProgress        Type = "progress"
+	TypeError           Type = "error"
+	TypeFileChanged     Type = "file_changed"
+	TypeAgentMessage    Type = "agent_message"
+	TypeCheckpoint      Type = "checkpoint"
+	TypeBlueprintReady  Type = "blueprint_ready"
+	TypeQuestionPending Type = "question_pending"
+	TypeTaskStarted     Type = "task_started"
+	TypeTaskFinished    Type = "task_finished"
+	TypePolicyViolation Type = "policy_violation"
+	TypeConfigReloaded  Type = "config_reloaded"
+	TypeRateLimited     Type = "rate_limited"
 
 	// GitHub-related events.
 	TypeBranchCreated Type = "branch_created"
@@ -235,6 +241,8 @@ func (e BranchCreatedEvent) ToEvent() Event {
 type PlanCompletedEvent struct {
 	Timestamp       time.Time
 	TaskID          string
+	Title           string // Task title
+	Summary         string // Summary of the new specification
 	SpecificationID int
 }
 
@@ -248,6 +256,8 @@ func (e PlanCompletedEvent) ToEvent() Event {
 		Timestamp: e.Timestamp,
 		Data: map[string]any{
 			"task_id":          e.TaskID,
+			"title":            e.Title,
+			"summary":          e.Summary,
 			"specification_id": e.SpecificationID,
 		},
 	}
@@ -279,6 +289,7 @@ func (e ImplementDoneEvent) ToEvent() Event {
 type PRCreatedEvent struct {
 	Timestamp time.Time
 	TaskID    string
+	Title     string // Task title
 	PRURL     string
 	PRNumber  int
 }
@@ -293,8 +304,165 @@ func (e PRCreatedEvent) ToEvent() Event {
 		Timestamp: e.Timestamp,
 		Data: map[string]any{
 			"task_id":   e.TaskID,
+			"title":     e.Title,
 			"pr_number": e.PRNumber,
 			"pr_url":    e.PRURL,
 		},
 	}
 }
+
+// QuestionPendingEvent when an agent stops to wait for the user's answer.
+type QuestionPendingEvent struct {
+	Timestamp time.Time
+	TaskID    string
+	Title     string // Task title
+	Phase     string
+	Question  string
+	Options   []string
+}
+
+func (e QuestionPendingEvent) ToEvent() Event {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+
+	return Event{
+		Type:      TypeQuestionPending,
+		Timestamp: e.Timestamp,
+		Data: map[string]any{
+			"task_id":  e.TaskID,
+			"title":    e.Title,
+			"phase":    e.Phase,
+			"question": e.Question,
+			"options":  e.Options,
+		},
+	}
+}
+
+// TaskStartedEvent when a new task is registered.
+type TaskStartedEvent struct {
+	Timestamp time.Time
+	TaskID    string
+	Title     string
+	Reference string // Source reference the task was started from
+	Branch    string
+}
+
+func (e TaskStartedEvent) ToEvent() Event {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+
+	return Event{
+		Type:      TypeTaskStarted,
+		Timestamp: e.Timestamp,
+		Data: map[string]any{
+			"task_id":   e.TaskID,
+			"title":     e.Title,
+			"reference": e.Reference,
+			"branch":    e.Branch,
+		},
+	}
+}
+
+// TaskFinishedEvent when a task is finished.
+type TaskFinishedEvent struct {
+	Timestamp time.Time
+	TaskID    string
+	Title     string
+}
+
+func (e TaskFinishedEvent) ToEvent() Event {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+
+	return Event{
+		Type:      TypeTaskFinished,
+		Timestamp: e.Timestamp,
+		Data: map[string]any{
+			"task_id": e.TaskID,
+			"title":   e.Title,
+		},
+	}
+}
+
+// PolicyViolationEvent when agent output breaks a guardrail policy: a change
+// to a denied or protected path, or a secret in a written file.
+type PolicyViolationEvent struct {
+	Timestamp time.Time
+	TaskID    string
+	Path      string
+	Rule      string // e.g. "denied path" or the secret rule
+	Action    string // rejected or redacted
+}
+
+func (e PolicyViolationEvent) ToEvent() Event {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+
+	return Event{
+		Type:      TypePolicyViolation,
+		Timestamp: e.Timestamp,
+		Data: map[string]any{
+			"task_id": e.TaskID,
+			"path":    e.Path,
+			"rule":    e.Rule,
+			"action":  e.Action,
+		},
+	}
+}
+
+// ConfigReloadedEvent when a long-running conductor applied a changed
+// workspace config or .env file without a restart.
+type ConfigReloadedEvent struct {
+	Timestamp time.Time
+	Files     []string // Names of the changed files, e.g. config.yaml
+	Aliases   []string // Agent aliases registered from the new config
+	EnvVars   []string // Environment variables set or unset from .env
+}
+
+func (e ConfigReloadedEvent) ToEvent() Event {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+
+	return Event{
+		Type:      TypeConfigReloaded,
+		Timestamp: e.Timestamp,
+		Data: map[string]any{
+			"files":    e.Files,
+			"aliases":  e.Aliases,
+			"env_vars": e.EnvVars,
+		},
+	}
+}
+
+// RateLimitedEvent when a request to a provider API waits for its rate
+// limit, because the remaining quota is nearly spent or the API asked to
+// retry later.
+type RateLimitedEvent struct {
+	Timestamp time.Time
+	Host      string
+	Wait      time.Duration
+	Reason    string // quota or retry-after
+	Attempt   int    // Retry number, 0 when waiting for quota
+}
+
+func (e RateLimitedEvent) ToEvent() Event {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+
+	return Event{
+		Type:      TypeRateLimited,
+		Timestamp: e.Timestamp,
+		Data: map[string]any{
+			"host":    e.Host,
+			"wait":    e.Wait,
+			"reason":  e.Reason,
+			"attempt": e.Attempt,
+		},
+	}
+}
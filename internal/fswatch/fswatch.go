@@ -0,0 +1,72 @@
+// Package fswatch reports changes to the files in directories as they
+// happen: through inotify on Linux, and by polling on other systems or
+// where inotify is unavailable.
+//
+// Events are reported per file; directories created below a recursively
+// watched directory are watched as well. A single change may be reported
+// more than once, so consumers re-read the file rather than count events.
+package fswatch
+
+import (
+	"errors"
+	"time"
+)
+
+// Op is the kind of change to a file.
+type Op int
+
+const (
+	// Changed reports a file created, written or moved into place.
+	Changed Op = iota + 1
+	// Removed reports a file deleted or moved away.
+	Removed
+)
+
+// String returns the operation's name.
+func (o Op) String() string {
+	switch o {
+	case Changed:
+		return "changed"
+	case Removed:
+		return "removed"
+	}
+
+	return "unknown"
+}
+
+// Event is a change to a file.
+type Event struct {
+	Path string
+	Op   Op
+}
+
+// DefaultPollInterval is how often the polling watcher checks for changes.
+const DefaultPollInterval = 500 * time.Millisecond
+
+// ErrClosed is returned when adding to a closed watcher.
+var ErrClosed = errors.New("watcher closed")
+
+// Watcher reports changes to files in the directories added to it.
+type Watcher interface {
+	// Add watches the files in dir, and in its subdirectories when recursive.
+	Add(dir string, recursive bool) error
+
+	// Events returns the channel changes are sent on; it is closed by Close.
+	Events() <-chan Event
+
+	// Errors returns the channel watch errors are sent on.
+	Errors() <-chan error
+
+	// Close stops watching.
+	Close() error
+}
+
+// New returns a watcher using inotify on Linux, falling back to polling
+// every DefaultPollInterval.
+func New() Watcher {
+	if w, err := newNative(); err == nil {
+		return w
+	}
+
+	return NewPolling(DefaultPollInterval)
+}
@@ -0,0 +1,90 @@
+package fswatch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitFor reads events until want arrives or the timeout passes.
+func waitFor(t *testing.T, w Watcher, want Event) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e := <-w.Events():
+			if e == want {
+				return
+			}
+		case err := <-w.Errors():
+			t.Fatalf("watch error: %v", err)
+		case <-timeout:
+			t.Fatalf("no %s event for %s", want.Op, want.Path)
+		}
+	}
+}
+
+func testWatcher(t *testing.T, w Watcher) {
+	t.Helper()
+	defer func() { _ = w.Close() }()
+
+	root := t.TempDir()
+	work := filepath.Join(root, "work")
+	if err := os.MkdirAll(filepath.Join(work, "a1"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Add(root, false); err != nil {
+		t.Fatalf("Add(root): %v", err)
+	}
+	if err := w.Add(work, true); err != nil {
+		t.Fatalf("Add(work): %v", err)
+	}
+
+	active := filepath.Join(root, ".active_task")
+	if err := os.WriteFile(active, []byte("state: planning\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, w, Event{Path: active, Op: Changed})
+
+	// Atomic writes rename a temporary file into place
+	question := filepath.Join(work, "a1", "pending_question.yaml")
+	if err := os.WriteFile(question+".tmp", []byte("question: Which?\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(question+".tmp", question); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, w, Event{Path: question, Op: Changed})
+
+	// Directories created later are watched too
+	session := filepath.Join(work, "b2", "sessions", "2026-01-01T10-00-00-planning.yaml")
+	if err := os.MkdirAll(filepath.Dir(session), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(session, []byte("type: planning\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, w, Event{Path: session, Op: Changed})
+
+	if err := os.Remove(question); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, w, Event{Path: question, Op: Removed})
+
+	if err := w.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	if err := w.Add(root, false); !errors.Is(err, ErrClosed) {
+		t.Errorf("Add after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	testWatcher(t, New())
+}
+
+func TestPolling(t *testing.T) {
+	testWatcher(t, NewPolling(20*time.Millisecond))
+}
@@ -0,0 +1,222 @@
+//go:build linux
+
+package fswatch
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// inotifyMask selects the changes reported for files and the creation of
+// subdirectories to watch.
+const inotifyMask = syscall.IN_CREATE | syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO |
+	syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_DELETE_SELF
+
+// inotifyWatcher reads change events from the kernel.
+type inotifyWatcher struct {
+	fd     int
+	file   *os.File // The non-blocking descriptor, so Close unblocks Read
+	events chan Event
+	errors chan error
+	done   chan struct{}
+	wg     sync.WaitGroup
+
+	mu        sync.Mutex
+	watches   map[int32]string // Watch descriptor -> directory
+	recursive map[string]bool  // Directory -> its new subdirectories are watched
+	closed    bool
+}
+
+func newNative() (Watcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	w := &inotifyWatcher{
+		fd:        fd,
+		file:      os.NewFile(uintptr(fd), "inotify"),
+		events:    make(chan Event, 64),
+		errors:    make(chan error, 8),
+		done:      make(chan struct{}),
+		watches:   make(map[int32]string),
+		recursive: make(map[string]bool),
+	}
+	w.wg.Add(1)
+	go w.read()
+
+	return w, nil
+}
+
+func (w *inotifyWatcher) Add(dir string, recursive bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return ErrClosed
+	}
+	if !recursive {
+		return w.watch(dir, false)
+	}
+
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		return w.watch(path, true)
+	})
+}
+
+// watch adds an inotify watch on dir. Callers hold w.mu.
+func (w *inotifyWatcher) watch(dir string, recursive bool) error {
+	wd, err := syscall.InotifyAddWatch(w.fd, dir, inotifyMask)
+	if err != nil {
+		return &os.PathError{Op: "watch", Path: dir, Err: err}
+	}
+	w.watches[int32(wd)] = dir
+	w.recursive[dir] = recursive
+
+	return nil
+}
+
+func (w *inotifyWatcher) Events() <-chan Event { return w.events }
+
+func (w *inotifyWatcher) Errors() <-chan error { return w.errors }
+
+func (w *inotifyWatcher) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.done)
+	err := w.file.Close()
+	w.wg.Wait()
+	close(w.events)
+
+	return err
+}
+
+// read turns the kernel's events into Events until the watcher is closed.
+func (w *inotifyWatcher) read() {
+	defer w.wg.Done()
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				w.sendError(err)
+			}
+
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			raw := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			nameEnd := nameStart + int(raw.Len)
+			offset = nameEnd
+			if nameEnd > n {
+				break
+			}
+			name := string(trimNUL(buf[nameStart:nameEnd]))
+			w.handle(raw.Wd, raw.Mask, name)
+		}
+	}
+}
+
+// handle reports one kernel event and watches new subdirectories.
+func (w *inotifyWatcher) handle(wd int32, mask uint32, name string) {
+	w.mu.Lock()
+	dir, ok := w.watches[wd]
+	if mask&(syscall.IN_DELETE_SELF|syscall.IN_IGNORED) != 0 {
+		delete(w.watches, wd)
+		delete(w.recursive, dir)
+		w.mu.Unlock()
+
+		return
+	}
+	if !ok || name == "" {
+		w.mu.Unlock()
+
+		return
+	}
+	path := filepath.Join(dir, name)
+
+	var created []string
+	if mask&syscall.IN_ISDIR != 0 {
+		if mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 && w.recursive[dir] && !w.closed {
+			// Files written before the watch was in place are reported too
+			_ = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return nil //nolint:nilerr // Removed again before it could be watched
+				}
+				if d.IsDir() {
+					if err := w.watch(p, true); err != nil {
+						w.sendError(err)
+					}
+				} else {
+					created = append(created, p)
+				}
+
+				return nil
+			})
+		}
+		w.mu.Unlock()
+		for _, p := range created {
+			w.send(Event{Path: p, Op: Changed})
+		}
+
+		return
+	}
+	w.mu.Unlock()
+
+	switch {
+	case mask&(syscall.IN_DELETE|syscall.IN_MOVED_FROM) != 0:
+		w.send(Event{Path: path, Op: Removed})
+	case mask&(syscall.IN_CLOSE_WRITE|syscall.IN_MOVED_TO) != 0:
+		w.send(Event{Path: path, Op: Changed})
+	}
+}
+
+// send reports e unless the watcher is closing.
+func (w *inotifyWatcher) send(e Event) {
+	select {
+	case w.events <- e:
+	case <-w.done:
+	}
+}
+
+// sendError reports err unless the error channel is full.
+func (w *inotifyWatcher) sendError(err error) {
+	select {
+	case w.errors <- err:
+	default:
+	}
+}
+
+// trimNUL drops the NUL padding after an event's file name.
+func trimNUL(b []byte) []byte {
+	for i, c := range b {
+		if c == 0 {
+			return b[:i]
+		}
+	}
+
+	return b
+}
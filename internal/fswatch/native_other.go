@@ -0,0 +1,10 @@
+//go:build !linux
+
+package fswatch
+
+import "errors"
+
+// newNative reports that no native watcher exists, so New polls.
+func newNative() (Watcher, error) {
+	return nil, errors.New("no native file watching on this system")
+}
@@ -0,0 +1,161 @@
+package fswatch
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// stamp identifies a version of a file by modification time and size.
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// pollingWatcher finds changes by listing the watched directories at an
+// interval and comparing the files' modification times and sizes.
+type pollingWatcher struct {
+	interval time.Duration
+	events   chan Event
+	errors   chan error
+	done     chan struct{}
+	wg       sync.WaitGroup
+
+	mu     sync.Mutex
+	dirs   map[string]bool // Watched directory -> recursive
+	files  map[string]stamp
+	closed bool
+}
+
+// NewPolling returns a watcher that checks for changes every interval.
+func NewPolling(interval time.Duration) Watcher {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	w := &pollingWatcher{
+		interval: interval,
+		events:   make(chan Event, 64),
+		errors:   make(chan error, 8),
+		done:     make(chan struct{}),
+		dirs:     make(map[string]bool),
+		files:    make(map[string]stamp),
+	}
+	w.wg.Add(1)
+	go w.loop()
+
+	return w
+}
+
+func (w *pollingWatcher) Add(dir string, recursive bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return ErrClosed
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	w.dirs[dir] = recursive
+	// Files already there are the baseline, not changes
+	for path, s := range w.list(dir, recursive) {
+		w.files[path] = s
+	}
+
+	return nil
+}
+
+func (w *pollingWatcher) Events() <-chan Event { return w.events }
+
+func (w *pollingWatcher) Errors() <-chan error { return w.errors }
+
+func (w *pollingWatcher) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.done)
+	w.wg.Wait()
+	close(w.events)
+
+	return nil
+}
+
+func (w *pollingWatcher) loop() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			for _, e := range w.scan() {
+				select {
+				case w.events <- e:
+				case <-w.done:
+					return
+				}
+			}
+		}
+	}
+}
+
+// scan lists the watched directories and returns what changed since the
+// last scan.
+func (w *pollingWatcher) scan() []Event {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	current := make(map[string]stamp, len(w.files))
+	for dir, recursive := range w.dirs {
+		for path, s := range w.list(dir, recursive) {
+			current[path] = s
+		}
+	}
+
+	var events []Event
+	for path, s := range current {
+		if prev, ok := w.files[path]; !ok || prev != s {
+			events = append(events, Event{Path: path, Op: Changed})
+		}
+	}
+	for path := range w.files {
+		if _, ok := current[path]; !ok {
+			events = append(events, Event{Path: path, Op: Removed})
+		}
+	}
+	w.files = current
+
+	return events
+}
+
+// list returns the files in dir, and below it when recursive.
+func (w *pollingWatcher) list(dir string, recursive bool) map[string]stamp {
+	files := make(map[string]stamp)
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil //nolint:nilerr // Unreadable entries are skipped
+		}
+		if d.IsDir() {
+			if path != dir && !recursive {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files[path] = stamp{modTime: info.ModTime(), size: info.Size()}
+		}
+
+		return nil
+	})
+
+	return files
+}
@@ -0,0 +1,67 @@
+package guardrail
+
+import (
+	"strconv"
+	"strings"
+)
+
+// AddedLines returns the lines a unified diff adds, keyed by the new file path.
+func AddedLines(diff string) map[string][]Line {
+	added := make(map[string][]Line)
+
+	var (
+		file string
+		next int
+	)
+	for line := range strings.Lines(diff) {
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			file = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+			if file == "/dev/null" {
+				file = ""
+			}
+		case strings.HasPrefix(line, "@@ "):
+			next = hunkStart(line)
+		case file == "" || next == 0:
+			continue
+		case strings.HasPrefix(line, "+"):
+			added[file] = append(added[file], Line{Number: next, Text: line[1:]})
+			next++
+		case strings.HasPrefix(line, " "):
+			next++
+		}
+	}
+
+	return added
+}
+
+// hunkStart parses the new-file start line from a hunk header such as
+// "@@ -10,2 +12,3 @@".
+func hunkStart(header string) int {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return 0
+	}
+
+	start, _, _ := strings.Cut(fields[2][1:], ",")
+	n, err := strconv.Atoi(start)
+	if err != nil {
+		return 0
+	}
+
+	return max(n, 1)
+}
+
+// AllLines returns every line of a new file as added lines.
+func AllLines(content string) []Line {
+	var lines []Line
+	n := 0
+	for line := range strings.Lines(content) {
+		n++
+		lines = append(lines, Line{Number: n, Text: strings.TrimSuffix(line, "\n")})
+	}
+
+	return lines
+}
@@ -0,0 +1,235 @@
+// Package guardrail scans changes before they are committed or pushed for
+// content that must never land in the repository: credentials found by
+// pluggable detectors, and binary or oversized files.
+package guardrail
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Default size limits.
+const (
+	DefaultMaxFileSize   = 5 << 20   // Any file larger than 5 MiB is blocked
+	DefaultMaxBinarySize = 100 << 10 // Binary files larger than 100 KiB are blocked
+)
+
+// BinarySniffLen is how much of a file is inspected for NUL bytes.
+const BinarySniffLen = 8000
+
+// Finding kinds.
+const (
+	KindSecret    = "secret"
+	KindLargeFile = "large_file"
+	KindBinary    = "binary"
+	KindDenied    = "denied_path"
+	KindProtected = "protected_path"
+)
+
+// Line is an added line of a change.
+type Line struct {
+	Number int    // Line number in the new file
+	Text   string // Line content without the leading "+"
+}
+
+// Change is a file as it would be committed, with the lines the change adds.
+type Change struct {
+	Path    string
+	Size    int64  // Size of the new file
+	Content []byte // Leading bytes of the new file, used to detect binaries
+	Added   []Line // Lines added by the change; every line for new files
+}
+
+// Finding is a blocked change.
+type Finding struct {
+	Kind   string
+	Path   string
+	Line   int    // 0 for whole-file findings
+	Rule   string // Secret rule or size limit that matched
+	Detail string // Redacted hunk or size description
+	ID     string // Fingerprint used to suppress the finding; stable across line moves
+}
+
+// String formats the finding for reports.
+func (f Finding) String() string {
+	location := f.Path
+	if f.Line > 0 {
+		location = fmt.Sprintf("%s:%d", f.Path, f.Line)
+	}
+	s := fmt.Sprintf("%s: %s (%s)", location, f.Rule, f.Detail)
+	if f.ID != "" {
+		s += " [" + f.ID + "]"
+	}
+
+	return s
+}
+
+// fingerprint identifies a finding by what was found rather than where, so
+// suppressing it survives edits that move the line.
+func fingerprint(path, rule, value string) string {
+	sum := sha256.Sum256([]byte(path + "\x00" + rule + "\x00" + value))
+
+	return hex.EncodeToString(sum[:6])
+}
+
+// Suppress returns the findings whose ID is not in ids.
+func Suppress(findings []Finding, ids []string) []Finding {
+	if len(ids) == 0 {
+		return findings
+	}
+
+	return slices.DeleteFunc(slices.Clone(findings), func(f Finding) bool {
+		return slices.Contains(ids, f.ID)
+	})
+}
+
+// Scanner checks changes against the guardrails.
+type Scanner struct {
+	MaxFileSize   int64      // Block any file larger than this (0 = DefaultMaxFileSize)
+	MaxBinarySize int64      // Block binary files larger than this (0 = DefaultMaxBinarySize)
+	Ignore        []string   // Path globs that are never scanned (e.g., "testdata/**")
+	Detectors     []Detector // Secret detectors (nil = DefaultDetectors)
+}
+
+// detectors returns the scanner's secret detectors.
+func (s *Scanner) detectors() []Detector {
+	if s.Detectors == nil {
+		return DefaultDetectors()
+	}
+
+	return s.Detectors
+}
+
+// Scan returns the findings for a set of changes, in change order.
+func (s *Scanner) Scan(changes []Change) []Finding {
+	var findings []Finding
+
+	for _, change := range changes {
+		if s.ignored(change.Path) {
+			continue
+		}
+
+		binary := IsBinary(change.Content)
+		switch maxFile, maxBinary := s.Limits(); {
+		case change.Size > maxFile:
+			findings = append(findings, Finding{
+				Kind:   KindLargeFile,
+				Path:   change.Path,
+				Rule:   "max file size " + formatSize(maxFile),
+				Detail: formatSize(change.Size),
+				ID:     fingerprint(change.Path, KindLargeFile, ""),
+			})
+		case binary && change.Size > maxBinary:
+			findings = append(findings, Finding{
+				Kind:   KindBinary,
+				Path:   change.Path,
+				Rule:   "max binary size " + formatSize(maxBinary),
+				Detail: formatSize(change.Size) + " binary",
+				ID:     fingerprint(change.Path, KindBinary, ""),
+			})
+		}
+		if binary {
+			continue
+		}
+
+		for _, line := range change.Added {
+			if f, ok := s.scanLine(change.Path, line); ok {
+				findings = append(findings, f)
+			}
+		}
+	}
+
+	return findings
+}
+
+// scanLine returns the finding of the first detector that finds a secret in
+// a line.
+func (s *Scanner) scanLine(file string, line Line) (Finding, bool) {
+	for _, d := range s.detectors() {
+		secret := d.Find(file, line.Text)
+		if secret == "" || isPlaceholder(secret) {
+			continue
+		}
+
+		return Finding{
+			Kind:   KindSecret,
+			Path:   file,
+			Line:   line.Number,
+			Rule:   d.Name(),
+			Detail: maskSecret(line.Text, secret),
+			ID:     fingerprint(file, d.Name(), secret),
+		}, true
+	}
+
+	return Finding{}, false
+}
+
+// Report formats findings for the user and for the agent prompt.
+func Report(findings []Finding) string {
+	var sb strings.Builder
+	for _, f := range findings {
+		sb.WriteString("- " + f.String() + "\n")
+	}
+
+	return sb.String()
+}
+
+// IsBinary reports whether content looks like binary data.
+func IsBinary(content []byte) bool {
+	if len(content) > BinarySniffLen {
+		content = content[:BinarySniffLen]
+	}
+
+	return bytes.IndexByte(content, 0) >= 0
+}
+
+// Limits returns the effective maximum file and binary sizes.
+func (s *Scanner) Limits() (int64, int64) {
+	maxFile, maxBinary := s.MaxFileSize, s.MaxBinarySize
+	if maxFile <= 0 {
+		maxFile = DefaultMaxFileSize
+	}
+	if maxBinary <= 0 {
+		maxBinary = DefaultMaxBinarySize
+	}
+
+	return maxFile, maxBinary
+}
+
+// ignored reports whether a path matches an ignore glob.
+func (s *Scanner) ignored(file string) bool {
+	return MatchAny(s.Ignore, file)
+}
+
+// MatchAny reports whether a slash-separated path matches any of the globs.
+// A glob matches the whole path or its base name, and a trailing "/**"
+// matches everything below a directory.
+func MatchAny(patterns []string, file string) bool {
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+			return file == dir || strings.HasPrefix(file, dir+"/")
+		}
+		if ok, _ := path.Match(pattern, file); ok {
+			return true
+		}
+		ok, _ := path.Match(pattern, path.Base(file))
+
+		return ok
+	})
+}
+
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
@@ -0,0 +1,32 @@
+package guardrail
+
+// Ways to handle secrets in files written from agent output.
+const (
+	SecretsReject = "reject" // Reject the changes
+	SecretsRedact = "redact" // Write the files with the secrets replaced
+)
+
+// Policy decides which paths agents may change.
+type Policy struct {
+	Deny      []string // Paths agents may never change (e.g., "deploy/**", "*.pem")
+	Allow     []string // Exceptions to Deny and Protected
+	Protected []string // Paths agents may change only when permitted (e.g., "go.sum")
+}
+
+// Check returns a finding for each path the policy forbids. Protected paths
+// are forbidden unless allowProtected is set.
+func (p *Policy) Check(paths []string, allowProtected bool) []Finding {
+	var findings []Finding
+	for _, file := range paths {
+		switch {
+		case MatchAny(p.Allow, file):
+			continue
+		case MatchAny(p.Deny, file):
+			findings = append(findings, Finding{Kind: KindDenied, Path: file, Rule: "denied path", Detail: "agents may not change it"})
+		case !allowProtected && MatchAny(p.Protected, file):
+			findings = append(findings, Finding{Kind: KindProtected, Path: file, Rule: "protected path", Detail: "changes need --allow-protected"})
+		}
+	}
+
+	return findings
+}
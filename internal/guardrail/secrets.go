@@ -0,0 +1,221 @@
+package guardrail
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Detector finds secrets in the lines of a file. The scanner runs
+// DefaultDetectors unless it is given its own, so detectors can be added or
+// replaced without changing the scanner.
+type Detector interface {
+	// Name is the rule reported for what the detector finds.
+	Name() string
+	// Find returns the secret in a line of the file at path, or "".
+	Find(path, line string) string
+}
+
+// secretRule is a credential pattern. The secret is the first submatch when
+// the pattern has one, otherwise the whole match.
+type secretRule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// secretRules are gitleaks-style patterns for credentials with a recognizable
+// format, plus quoted assignments to variables named like secrets.
+var secretRules = []secretRule{
+	{"private key", regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )?PRIVATE KEY(?: BLOCK)?-----`)},
+	{"AWS access key", regexp.MustCompile(`\b((?:AKIA|ASIA|AGPA|AIDA|AROA)[A-Z0-9]{16})\b`)},
+	{"GitHub token", regexp.MustCompile(`\b((?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{50,})\b`)},
+	{"GitLab token", regexp.MustCompile(`\b(glpat-[A-Za-z0-9_-]{20,})\b`)},
+	{"Slack token", regexp.MustCompile(`\b(xox[abposr]-[A-Za-z0-9-]{10,})\b`)},
+	{"Slack webhook", regexp.MustCompile(`(https://hooks\.slack\.com/services/[A-Za-z0-9/_-]{20,})`)},
+	{"Stripe key", regexp.MustCompile(`\b((?:sk|rk)_live_[A-Za-z0-9]{20,})\b`)},
+	{"Google API key", regexp.MustCompile(`\b(AIza[0-9A-Za-z_-]{35})\b`)},
+	{"OpenAI key", regexp.MustCompile(`\b(sk-(?:proj-)?[A-Za-z0-9_-]{20,}T3BlbkFJ[A-Za-z0-9_-]{20,})\b`)},
+	{"Anthropic key", regexp.MustCompile(`\b(sk-ant-[A-Za-z0-9_-]{32,})\b`)},
+	{"JWT", regexp.MustCompile(`\b(eyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,})\b`)},
+	{
+		"generic secret",
+		regexp.MustCompile(`(?i)(?:api[_-]?key|secret|passw(?:or)?d|token|access[_-]?key)["']?\s*[:=]\s*["']([A-Za-z0-9+/_\-.=]{16,})["']`),
+	},
+}
+
+// PatternDetectors returns detectors for credentials with a recognizable
+// format, such as API keys, tokens and private keys.
+func PatternDetectors() []Detector {
+	detectors := make([]Detector, len(secretRules))
+	for i := range secretRules {
+		detectors[i] = &secretRules[i]
+	}
+
+	return detectors
+}
+
+// DefaultDetectors returns the pattern detectors followed by an
+// EntropyDetector with default settings.
+func DefaultDetectors() []Detector {
+	return append(PatternDetectors(), &EntropyDetector{})
+}
+
+// Name implements Detector.
+func (r *secretRule) Name() string {
+	return r.name
+}
+
+// Find implements Detector.
+func (r *secretRule) Find(_, line string) string {
+	return r.find(line)
+}
+
+func (r *secretRule) find(line string) string {
+	m := r.pattern.FindStringSubmatch(line)
+	switch {
+	case m == nil:
+		return ""
+	case len(m) > 1:
+		return m[1]
+	default:
+		return m[0]
+	}
+}
+
+// Default EntropyDetector settings.
+const (
+	DefaultEntropyMinLength = 24
+	DefaultEntropyThreshold = 4.0 // Bits per character; hex digests stay below it
+)
+
+// entropyCandidate matches quoted tokens and unquoted values of KEY=value
+// lines, as in .env files.
+var entropyCandidate = regexp.MustCompile(`["'\x60]([A-Za-z0-9+/_\-=.]+)["'\x60]|^\s*(?:export\s+)?[A-Za-z_][A-Za-z0-9_]*\s*=\s*([A-Za-z0-9+/_\-=.]+)\s*$`)
+
+// entropySkipped are files full of random-looking hashes by design.
+var entropySkipped = []string{"go.sum", "*.lock", "package-lock.json", "pnpm-lock.yaml", "*.svg"}
+
+// EntropyDetector finds random-looking strings that are probably
+// credentials without a recognizable format: quoted tokens and .env values
+// that are long, mix letters and digits, and have a high Shannon entropy.
+type EntropyDetector struct {
+	MinLength int     // Shortest candidate (0 = DefaultEntropyMinLength)
+	Threshold float64 // Minimum entropy in bits per character (0 = DefaultEntropyThreshold)
+}
+
+// Name implements Detector.
+func (d *EntropyDetector) Name() string {
+	return "high-entropy string"
+}
+
+// Find implements Detector.
+func (d *EntropyDetector) Find(path, line string) string {
+	if MatchAny(entropySkipped, path) {
+		return ""
+	}
+	minLength, threshold := d.MinLength, d.Threshold
+	if minLength <= 0 {
+		minLength = DefaultEntropyMinLength
+	}
+	if threshold <= 0 {
+		threshold = DefaultEntropyThreshold
+	}
+
+	for _, m := range entropyCandidate.FindAllStringSubmatch(line, -1) {
+		token := m[1] + m[2]
+		if len(token) < minLength || strings.Contains(token, "/") && !strings.ContainsAny(token, "+=") {
+			continue // Short, or a path
+		}
+		if strings.ContainsAny(token, "0123456789") && strings.ContainsFunc(token, isLetter) && Entropy(token) >= threshold {
+			return token
+		}
+	}
+
+	return ""
+}
+
+// Entropy returns the Shannon entropy of s in bits per character.
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+
+	var entropy float64
+	for _, count := range counts {
+		p := float64(count) / float64(n)
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
+
+func isLetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+// placeholderHints mark values that are examples rather than real secrets.
+var placeholderHints = []string{"example", "xxxx", "changeme", "placeholder", "your_", "your-", "dummy", "${"}
+
+// maskSecret returns the line with the secret masked except for a short
+// prefix, so reports identify the hunk without repeating the credential.
+func maskSecret(line, secret string) string {
+	keep := min(4, len(secret)/4)
+
+	return strings.TrimSpace(strings.Replace(line, secret, secret[:keep]+strings.Repeat("*", 8), 1))
+}
+
+func isPlaceholder(secret string) bool {
+	lower := strings.ToLower(secret)
+	for _, hint := range placeholderHints {
+		if strings.Contains(lower, hint) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RedactedSecret replaces secrets removed from agent-written files.
+const RedactedSecret = "REDACTED"
+
+// privateKeyBlock matches a whole PEM private key, which is redacted as one.
+var privateKeyBlock = regexp.MustCompile(`(?s)-----BEGIN (?:[A-Z]+ )?PRIVATE KEY(?: BLOCK)?-----.*?-----END (?:[A-Z]+ )?PRIVATE KEY(?: BLOCK)?-----`)
+
+// Redact replaces the secrets the scanner's detectors find in content with
+// RedactedSecret, keeping the text around them, and returns the number
+// replaced.
+func (s *Scanner) Redact(path, content string) (string, int) {
+	count := 0
+	content = privateKeyBlock.ReplaceAllStringFunc(content, func(string) string {
+		count++
+
+		return RedactedSecret
+	})
+
+	// Detectors work line by line, as when scanning
+	var sb strings.Builder
+	for line := range strings.Lines(content) {
+		for _, d := range s.detectors() {
+			if d.Name() == "private key" {
+				continue // Unterminated keys keep their header and stay detectable
+			}
+			for {
+				secret := d.Find(path, line)
+				if secret == "" || secret == RedactedSecret || isPlaceholder(secret) {
+					break
+				}
+				line = strings.Replace(line, secret, RedactedSecret, 1)
+				count++
+			}
+		}
+		sb.WriteString(line)
+	}
+
+	return sb.String(), count
+}
@@ -38,6 +38,8 @@ var commandRules = map[string]CommandRule{
 	"note":     {Available: needsActiveTask, Reason: "needs active task"},
 	"abandon":  {Available: needsActiveTask, Reason: "needs active task"},
 	"answer":   {Available: needsActiveTask, Reason: "needs active task"},
+	"refresh":  {Available: needsActiveTask, Reason: "needs active task"},
+	"split":    {Available: needsActiveTask, Reason: "needs active task"},
 
 	// Commands that need specifications
 	"implement": {Available: needsSpecifications, Reason: "needs specifications"},
@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// resourceScheme prefixes resource URIs: mehr://tasks/<id>/notes,
+// mehr://tasks/<id>/specifications/<n> and mehr://tasks/<id>/question.
+const resourceScheme = "mehr://tasks/"
+
+type resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType"`
+}
+
+// listResources lists the notes, specifications and pending question of
+// every task.
+func (s *Server) listResources() (any, error) {
+	taskIDs, err := s.ws.ListWorks()
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+
+	resources := []resource{}
+	for _, id := range taskIDs {
+		work, err := s.ws.LoadWork(id)
+		if err != nil {
+			continue
+		}
+		title := work.Metadata.Title
+		if title == "" {
+			title = id
+		}
+
+		resources = append(resources, resource{
+			URI:      resourceScheme + id + "/notes",
+			Name:     title + ": notes",
+			MimeType: "text/markdown",
+		})
+
+		specs, _ := s.ws.ListSpecificationsWithStatus(id)
+		for _, spec := range specs {
+			resources = append(resources, resource{
+				URI:         fmt.Sprintf("%s%s/specifications/%d", resourceScheme, id, spec.Number),
+				Name:        fmt.Sprintf("%s: specification %d", title, spec.Number),
+				Description: fmt.Sprintf("%s [%s]", spec.Title, spec.Status),
+				MimeType:    "text/markdown",
+			})
+		}
+
+		if s.ws.HasPendingQuestion(id) {
+			resources = append(resources, resource{
+				URI:      resourceScheme + id + "/question",
+				Name:     title + ": pending question",
+				MimeType: "text/plain",
+			})
+		}
+	}
+
+	return map[string]any{"resources": resources}, nil
+}
+
+// readResource returns the content of a resource URI.
+func (s *Server) readResource(params json.RawMessage) (any, error) {
+	var p struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, invalidParams(err)
+	}
+
+	rest, ok := strings.CutPrefix(p.URI, resourceScheme)
+	if !ok {
+		return nil, unknownResource(p.URI)
+	}
+	parts := strings.Split(rest, "/")
+	args := toolArgs{TaskID: parts[0]}
+	if args.TaskID == "" {
+		return nil, unknownResource(p.URI)
+	}
+
+	var text, mimeType string
+	var err error
+	switch {
+	case len(parts) == 2 && parts[1] == "notes":
+		text, err = s.readNotes(args)
+		mimeType = "text/markdown"
+	case len(parts) == 2 && parts[1] == "question":
+		text, err = s.getPendingQuestion(args)
+		mimeType = "text/plain"
+	case len(parts) == 3 && parts[1] == "specifications":
+		args.Number, err = strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, unknownResource(p.URI)
+		}
+		text, err = s.readSpecification(args)
+		mimeType = "text/markdown"
+	default:
+		return nil, unknownResource(p.URI)
+	}
+	if err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	return map[string]any{
+		"contents": []map[string]any{{"uri": p.URI, "mimeType": mimeType, "text": text}},
+	}, nil
+}
+
+func unknownResource(uri string) error {
+	return &rpcError{Code: codeInvalidParams, Message: "unknown resource: " + uri}
+}
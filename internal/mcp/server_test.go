@@ -0,0 +1,199 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+func newTestWorkspace(t *testing.T) *storage.Workspace {
+	t.Helper()
+
+	ws, err := storage.OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+
+	work, err := ws.CreateWork("task1", storage.SourceInfo{Type: "file", Ref: "task.md"})
+	if err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	work.Metadata.Title = "Add caching"
+	if err := ws.SaveWork(work); err != nil {
+		t.Fatalf("SaveWork: %v", err)
+	}
+	if err := ws.SaveActiveTask(storage.NewActiveTask("task1", "file:task.md", ws.WorkPath("task1"))); err != nil {
+		t.Fatalf("SaveActiveTask: %v", err)
+	}
+	if err := ws.SaveSpecification("task1", 1, "# Cache layer\n\nUse Redis.\n"); err != nil {
+		t.Fatalf("SaveSpecification: %v", err)
+	}
+
+	return ws
+}
+
+// rpc sends requests to a fresh server and returns the responses by id.
+func rpc(t *testing.T, ws *storage.Workspace, requests ...string) map[float64]map[string]any {
+	t.Helper()
+
+	var out bytes.Buffer
+	in := strings.NewReader(strings.Join(requests, "\n") + "\n")
+	if err := NewServer(ws, "mehrhof", "test").Serve(context.Background(), in, &out); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+
+	responses := map[float64]map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var resp map[string]any
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("response %q: %v", line, err)
+		}
+		id, _ := resp["id"].(float64)
+		responses[id] = resp
+	}
+
+	return responses
+}
+
+// toolText returns the text and error flag of a tools/call result.
+func toolText(t *testing.T, resp map[string]any) (string, bool) {
+	t.Helper()
+
+	result, ok := resp["result"].(map[string]any)
+	if !ok {
+		t.Fatalf("no result in %v", resp)
+	}
+	content := result["content"].([]any)[0].(map[string]any)
+	isError, _ := result["isError"].(bool)
+
+	return content["text"].(string), isError
+}
+
+func TestServer_Initialize(t *testing.T) {
+	ws := newTestWorkspace(t)
+
+	responses := rpc(t, ws,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"bogus"}`,
+		`not json`,
+	)
+
+	if len(responses) != 4 {
+		t.Fatalf("got %d responses, want 4 (notifications get none)", len(responses))
+	}
+
+	result := responses[1]["result"].(map[string]any)
+	if result["protocolVersion"] != "2024-11-05" {
+		t.Errorf("protocolVersion = %v, want the client's version", result["protocolVersion"])
+	}
+
+	tools := responses[2]["result"].(map[string]any)["tools"].([]any)
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.(map[string]any)["name"].(string))
+	}
+	for _, want := range []string{"read_specification", "append_note", "update_spec_status", "answer_question"} {
+		if !strings.Contains(strings.Join(names, ","), want) {
+			t.Errorf("tools/list missing %s: %v", want, names)
+		}
+	}
+
+	if code := responses[3]["error"].(map[string]any)["code"].(float64); code != codeMethodNotFound {
+		t.Errorf("unknown method code = %v", code)
+	}
+	if code := responses[0]["error"].(map[string]any)["code"].(float64); code != codeParseError {
+		t.Errorf("parse error code = %v", code)
+	}
+}
+
+func TestServer_Tools(t *testing.T) {
+	ws := newTestWorkspace(t)
+	if err := ws.SavePendingQuestion("task1", &storage.PendingQuestion{
+		Question: "Which TTL?",
+		Options:  []storage.QuestionOption{{Label: "5 minutes"}, {Label: "1 hour"}},
+	}); err != nil {
+		t.Fatalf("SavePendingQuestion: %v", err)
+	}
+
+	responses := rpc(t, ws,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"read_specification","arguments":{"number":1}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"get_pending_question","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"answer_question","arguments":{"answer":"5 minutes"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"append_note","arguments":{"content":"Invalidate on write"}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"update_spec_status","arguments":{"number":1,"status":"ready"}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"update_spec_status","arguments":{"number":1,"status":"shipped"}}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"get_task","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":8,"method":"tools/call","params":{"name":"read_notes","arguments":{"task_id":"missing"}}}`,
+	)
+
+	if text, _ := toolText(t, responses[1]); !strings.Contains(text, "Use Redis.") {
+		t.Errorf("read_specification = %q", text)
+	}
+	if text, _ := toolText(t, responses[2]); !strings.Contains(text, "Which TTL?") || !strings.Contains(text, "2. 1 hour") {
+		t.Errorf("get_pending_question = %q", text)
+	}
+	if _, isError := toolText(t, responses[3]); isError {
+		t.Error("answer_question failed")
+	}
+	if _, isError := toolText(t, responses[6]); !isError {
+		t.Error("update_spec_status should reject an unknown status")
+	}
+	if _, isError := toolText(t, responses[8]); !isError {
+		t.Error("read_notes should fail for a missing task")
+	}
+
+	text, _ := toolText(t, responses[7])
+	var task taskSummary
+	if err := json.Unmarshal([]byte(text), &task); err != nil {
+		t.Fatalf("get_task: %v", err)
+	}
+	if task.Title != "Add caching" || !task.Active || task.PendingQuestion || task.Specifications[0].Status != storage.SpecificationStatusReady {
+		t.Errorf("get_task = %+v", task)
+	}
+
+	notes, _ := ws.ReadNotes("task1")
+	for _, want := range []string{"**A:** 5 minutes", "Invalidate on write"} {
+		if !strings.Contains(notes, want) {
+			t.Errorf("notes missing %q:\n%s", want, notes)
+		}
+	}
+}
+
+func TestServer_Resources(t *testing.T) {
+	ws := newTestWorkspace(t)
+
+	responses := rpc(t, ws,
+		`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"mehr://tasks/task1/specifications/1"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"mehr://tasks/task1/other"}}`,
+	)
+
+	var uris []string
+	for _, r := range responses[1]["result"].(map[string]any)["resources"].([]any) {
+		uris = append(uris, r.(map[string]any)["uri"].(string))
+	}
+	want := []string{"mehr://tasks/task1/notes", "mehr://tasks/task1/specifications/1"}
+	if strings.Join(uris, ",") != strings.Join(want, ",") {
+		t.Errorf("resources = %v, want %v", uris, want)
+	}
+
+	contents := responses[2]["result"].(map[string]any)["contents"].([]any)
+	if text := contents[0].(map[string]any)["text"].(string); !strings.Contains(text, "Use Redis.") {
+		t.Errorf("resource text = %q", text)
+	}
+	if responses[3]["error"] == nil {
+		t.Error("reading an unknown resource should fail")
+	}
+}
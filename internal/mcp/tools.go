@@ -0,0 +1,477 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// tool is an MCP tool backed by the workspace.
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+
+	call func(s *Server, args toolArgs) (string, error)
+}
+
+// toolArgs are the arguments of every tool; each tool reads the ones it needs.
+type toolArgs struct {
+	TaskID  string `json:"task_id"`
+	Number  int    `json:"number"`
+	Status  string `json:"status"`
+	Content string `json:"content"`
+	Answer  string `json:"answer"`
+	File    string `json:"file"`
+}
+
+var taskIDProperty = map[string]any{
+	"type":        "string",
+	"description": "Task ID. Defaults to the active task.",
+}
+
+// schema builds a JSON schema for an object with the given properties.
+func schema(required []string, properties map[string]any) map[string]any {
+	props := map[string]any{"task_id": taskIDProperty}
+	for name, prop := range properties {
+		props[name] = prop
+	}
+
+	s := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+
+	return s
+}
+
+var tools = []tool{
+	{
+		Name:        "list_tasks",
+		Description: "List the tasks in the workspace with their title, state and specification count.",
+		InputSchema: map[string]any{"type": "object", "properties": map[string]any{}},
+		call:        (*Server).listTasks,
+	},
+	{
+		Name:        "get_task",
+		Description: "Show a task: title, source, branch, specifications with status, and whether a question is pending.",
+		InputSchema: schema(nil, nil),
+		call:        (*Server).getTask,
+	},
+	{
+		Name:        "read_specification",
+		Description: "Read a specification of a task.",
+		InputSchema: schema([]string{"number"}, map[string]any{
+			"number": map[string]any{"type": "integer", "description": "Specification number", "minimum": 1},
+		}),
+		call: (*Server).readSpecification,
+	},
+	{
+		Name:        "read_notes",
+		Description: "Read the user's notes for a task, oldest first.",
+		InputSchema: schema(nil, nil),
+		call:        (*Server).readNotes,
+	},
+	{
+		Name:        "list_sessions",
+		Description: "List a task's agent sessions with kind, start time, exchange count and bookmarks.",
+		InputSchema: schema(nil, nil),
+		call:        (*Server).listSessions,
+	},
+	{
+		Name:        "read_session",
+		Description: "Read the exchanges and annotations of one agent session.",
+		InputSchema: schema([]string{"file"}, map[string]any{
+			"file": map[string]any{"type": "string", "description": "Session filename from list_sessions"},
+		}),
+		call: (*Server).readSession,
+	},
+	{
+		Name:        "get_pending_question",
+		Description: "Show the question the agent is waiting on, if any.",
+		InputSchema: schema(nil, nil),
+		call:        (*Server).getPendingQuestion,
+	},
+	{
+		Name:        "append_note",
+		Description: "Add a note to a task. Notes are included in later planning, implementation and review prompts.",
+		InputSchema: schema([]string{"content"}, map[string]any{
+			"content": map[string]any{"type": "string", "description": "Note text (markdown)"},
+		}),
+		call: (*Server).appendNote,
+	},
+	{
+		Name:        "update_spec_status",
+		Description: "Set the status of a specification.",
+		InputSchema: schema([]string{"number", "status"}, map[string]any{
+			"number": map[string]any{"type": "integer", "description": "Specification number", "minimum": 1},
+			"status": map[string]any{"type": "string", "enum": specStatuses},
+		}),
+		call: (*Server).updateSpecStatus,
+	},
+	{
+		Name:        "answer_question",
+		Description: "Answer the agent's pending question. The answer is saved as a note; run 'mehr plan' to continue.",
+		InputSchema: schema([]string{"answer"}, map[string]any{
+			"answer": map[string]any{"type": "string"},
+		}),
+		call: (*Server).answerQuestion,
+	},
+}
+
+var specStatuses = []string{
+	storage.SpecificationStatusDraft,
+	storage.SpecificationStatusReady,
+	storage.SpecificationStatusImplementing,
+	storage.SpecificationStatusDone,
+}
+
+// toolDefinitions returns the tools advertised to clients.
+func toolDefinitions() []tool {
+	return tools
+}
+
+// callTool runs a tool. Failures of the tool itself are reported in the
+// result with isError set, so the model can see and react to them.
+func (s *Server) callTool(_ context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, invalidParams(err)
+	}
+
+	idx := slices.IndexFunc(tools, func(t tool) bool { return t.Name == p.Name })
+	if idx == -1 {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + p.Name}
+	}
+
+	var args toolArgs
+	if len(p.Arguments) > 0 {
+		if err := json.Unmarshal(p.Arguments, &args); err != nil {
+			return nil, invalidParams(err)
+		}
+	}
+
+	text, err := tools[idx].call(s, args)
+	if err != nil {
+		return toolResult(err.Error(), true), nil
+	}
+
+	return toolResult(text, false), nil
+}
+
+func toolResult(text string, isError bool) map[string]any {
+	return map[string]any{
+		"content": []map[string]any{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
+
+// resolveTask returns the given task ID, or the active task's.
+func (s *Server) resolveTask(taskID string) (string, error) {
+	if taskID == "" {
+		if !s.ws.HasActiveTask() {
+			return "", errors.New("no active task: pass task_id")
+		}
+		active, err := s.ws.LoadActiveTask()
+		if err != nil {
+			return "", fmt.Errorf("load active task: %w", err)
+		}
+		taskID = active.ID
+	}
+	if !s.ws.WorkExists(taskID) {
+		return "", fmt.Errorf("task not found: %s", taskID)
+	}
+
+	return taskID, nil
+}
+
+// taskSummary is the JSON form of a task in list_tasks and get_task.
+type taskSummary struct {
+	ID              string        `json:"id"`
+	Title           string        `json:"title"`
+	Source          string        `json:"source,omitempty"`
+	Branch          string        `json:"branch,omitempty"`
+	Active          bool          `json:"active"`
+	State           string        `json:"state,omitempty"`
+	PendingQuestion bool          `json:"pending_question"`
+	Specifications  []specSummary `json:"specifications"`
+}
+
+type specSummary struct {
+	Number int    `json:"number"`
+	Title  string `json:"title,omitempty"`
+	Status string `json:"status"`
+}
+
+func (s *Server) summarizeTask(taskID string, active *storage.ActiveTask) (*taskSummary, error) {
+	work, err := s.ws.LoadWork(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("load task: %w", err)
+	}
+
+	summary := &taskSummary{
+		ID:              taskID,
+		Title:           work.Metadata.Title,
+		Source:          work.Source.Ref,
+		Branch:          work.Git.Branch,
+		PendingQuestion: s.ws.HasPendingQuestion(taskID),
+		Specifications:  []specSummary{},
+	}
+	if active != nil && active.ID == taskID {
+		summary.Active = true
+		summary.State = active.State
+	}
+
+	specs, _ := s.ws.ListSpecificationsWithStatus(taskID)
+	for _, spec := range specs {
+		summary.Specifications = append(summary.Specifications, specSummary{
+			Number: spec.Number,
+			Title:  spec.Title,
+			Status: spec.Status,
+		})
+	}
+
+	return summary, nil
+}
+
+func (s *Server) activeTask() *storage.ActiveTask {
+	if !s.ws.HasActiveTask() {
+		return nil
+	}
+	active, _ := s.ws.LoadActiveTask()
+
+	return active
+}
+
+func (s *Server) listTasks(_ toolArgs) (string, error) {
+	taskIDs, err := s.ws.ListWorks()
+	if err != nil {
+		return "", fmt.Errorf("list tasks: %w", err)
+	}
+
+	active := s.activeTask()
+	tasks := make([]*taskSummary, 0, len(taskIDs))
+	for _, id := range taskIDs {
+		summary, err := s.summarizeTask(id, active)
+		if err != nil {
+			continue
+		}
+		tasks = append(tasks, summary)
+	}
+
+	return marshalText(tasks)
+}
+
+func (s *Server) getTask(args toolArgs) (string, error) {
+	taskID, err := s.resolveTask(args.TaskID)
+	if err != nil {
+		return "", err
+	}
+
+	summary, err := s.summarizeTask(taskID, s.activeTask())
+	if err != nil {
+		return "", err
+	}
+
+	return marshalText(summary)
+}
+
+func (s *Server) readSpecification(args toolArgs) (string, error) {
+	taskID, err := s.resolveTask(args.TaskID)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := s.ws.LoadSpecification(taskID, args.Number)
+	if err != nil {
+		return "", fmt.Errorf("specification-%d not found", args.Number)
+	}
+
+	return content, nil
+}
+
+func (s *Server) readNotes(args toolArgs) (string, error) {
+	taskID, err := s.resolveTask(args.TaskID)
+	if err != nil {
+		return "", err
+	}
+
+	notes, err := s.ws.ReadNotes(taskID)
+	if err != nil {
+		return "No notes.", nil //nolint:nilerr // A task without notes is not an error
+	}
+
+	return notes, nil
+}
+
+func (s *Server) listSessions(args toolArgs) (string, error) {
+	taskID, err := s.resolveTask(args.TaskID)
+	if err != nil {
+		return "", err
+	}
+
+	files, err := s.ws.ListSessionFiles(taskID)
+	if err != nil {
+		return "", fmt.Errorf("list sessions: %w", err)
+	}
+
+	type sessionSummary struct {
+		File      string   `json:"file"`
+		Kind      string   `json:"kind"`
+		StartedAt string   `json:"started_at"`
+		Exchanges int      `json:"exchanges"`
+		Bookmarks []string `json:"bookmarks,omitempty"`
+	}
+	sessions := make([]sessionSummary, 0, len(files))
+	for _, file := range files {
+		session, err := s.ws.LoadSession(taskID, file)
+		if err != nil {
+			continue
+		}
+		summary := sessionSummary{
+			File:      file,
+			Kind:      session.Kind,
+			StartedAt: session.Metadata.StartedAt.Format("2006-01-02T15:04:05Z07:00"),
+			Exchanges: len(session.Exchanges),
+		}
+		for _, b := range session.Bookmarks() {
+			summary.Bookmarks = append(summary.Bookmarks, fmt.Sprintf("#%d %s", b.Exchange, b.Note))
+		}
+		sessions = append(sessions, summary)
+	}
+
+	return marshalText(sessions)
+}
+
+func (s *Server) readSession(args toolArgs) (string, error) {
+	taskID, err := s.resolveTask(args.TaskID)
+	if err != nil {
+		return "", err
+	}
+	if args.File == "" || strings.ContainsAny(args.File, `/\`) {
+		return "", errors.New("file must be a session filename from list_sessions")
+	}
+
+	session, err := s.ws.LoadSession(taskID, args.File)
+	if err != nil {
+		return "", fmt.Errorf("session %s not found", args.File)
+	}
+
+	notes := map[int][]string{}
+	for _, a := range session.Annotations {
+		label := "Note"
+		if a.Bookmark {
+			label = "Bookmark"
+		}
+		notes[a.Exchange] = append(notes[a.Exchange], label+": "+a.Note)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s session (%s)\n", session.Kind, session.Metadata.StartedAt.Format("2006-01-02 15:04"))
+	for i, ex := range session.Exchanges {
+		fmt.Fprintf(&sb, "\n## [%d] %s\n", i+1, ex.Role)
+		for _, note := range notes[i+1] {
+			fmt.Fprintf(&sb, "> %s\n", note)
+		}
+		sb.WriteString("\n" + strings.TrimSpace(ex.Content) + "\n")
+	}
+
+	return sb.String(), nil
+}
+
+func (s *Server) getPendingQuestion(args toolArgs) (string, error) {
+	taskID, err := s.resolveTask(args.TaskID)
+	if err != nil {
+		return "", err
+	}
+	if !s.ws.HasPendingQuestion(taskID) {
+		return "No pending question.", nil
+	}
+
+	q, err := s.ws.LoadPendingQuestion(taskID)
+	if err != nil {
+		return "", fmt.Errorf("load question: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(q.Question + "\n")
+	for i, opt := range q.Options {
+		fmt.Fprintf(&sb, "%d. %s", i+1, opt.Label)
+		if opt.Description != "" {
+			sb.WriteString(" - " + opt.Description)
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String(), nil
+}
+
+func (s *Server) appendNote(args toolArgs) (string, error) {
+	taskID, err := s.resolveTask(args.TaskID)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(args.Content) == "" {
+		return "", errors.New("content is required")
+	}
+
+	state := ""
+	if active := s.activeTask(); active != nil && active.ID == taskID {
+		state = active.State
+	}
+	if err := s.ws.AppendNote(taskID, args.Content, state); err != nil {
+		return "", fmt.Errorf("save note: %w", err)
+	}
+
+	return "Note saved.", nil
+}
+
+func (s *Server) updateSpecStatus(args toolArgs) (string, error) {
+	taskID, err := s.resolveTask(args.TaskID)
+	if err != nil {
+		return "", err
+	}
+	if !slices.Contains(specStatuses, args.Status) {
+		return "", fmt.Errorf("invalid status %q: want one of %s", args.Status, strings.Join(specStatuses, ", "))
+	}
+
+	if err := s.ws.UpdateSpecificationStatus(taskID, args.Number, args.Status); err != nil {
+		return "", fmt.Errorf("update specification-%d: %w", args.Number, err)
+	}
+
+	return fmt.Sprintf("Specification %d is %s.", args.Number, args.Status), nil
+}
+
+func (s *Server) answerQuestion(args toolArgs) (string, error) {
+	taskID, err := s.resolveTask(args.TaskID)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(args.Answer) == "" {
+		return "", errors.New("answer is required")
+	}
+
+	if err := s.ws.AnswerPendingQuestion(taskID, args.Answer); err != nil {
+		return "", err
+	}
+
+	return "Answer saved. Run 'mehr plan' to continue planning with it.", nil
+}
+
+// marshalText renders a tool result as indented JSON.
+func marshalText(v any) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal result: %w", err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+)
+
+// Desktop shows notifications with the operating system's notifier:
+// notify-send on Linux and osascript on macOS.
+type Desktop struct {
+	goos string
+	run  func(ctx context.Context, name string, args ...string) error
+}
+
+// NewDesktop creates a desktop sink for the current platform.
+func NewDesktop() *Desktop {
+	return &Desktop{
+		goos: runtime.GOOS,
+		run: func(ctx context.Context, name string, args ...string) error {
+			return exec.CommandContext(ctx, name, args...).Run()
+		},
+	}
+}
+
+// Name returns the sink name.
+func (d *Desktop) Name() string {
+	return "desktop"
+}
+
+// Notify shows the notification.
+func (d *Desktop) Notify(ctx context.Context, n Notification) error {
+	name, args, err := d.command(n)
+	if err != nil {
+		return err
+	}
+	if err := d.run(ctx, name, args...); err != nil {
+		return fmt.Errorf("run %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// command returns the notifier command for the platform.
+func (d *Desktop) command(n Notification) (string, []string, error) {
+	switch d.goos {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return "notify-send", []string{"--app-name=mehr", n.Title, n.Message}, nil
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(n.Message), strconv.Quote(n.Title))
+
+		return "osascript", []string{"-e", script}, nil
+	default:
+		return "", nil, errors.New("desktop notifications are not supported on " + d.goos)
+	}
+}
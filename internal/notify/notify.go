@@ -0,0 +1,95 @@
+// Package notify alerts the user about workflow events that need their
+// attention, such as an agent waiting on an answer, through pluggable sinks.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// defaultTimeout bounds each sink's delivery so a slow endpoint cannot stall
+// the workflow.
+const defaultTimeout = 10 * time.Second
+
+// Notification is a message delivered to every configured sink.
+type Notification struct {
+	Event     string    `json:"event"` // Event type, e.g. "question_pending"
+	TaskID    string    `json:"task_id"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	Options   []string  `json:"options,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Sink delivers notifications to one destination.
+type Sink interface {
+	Name() string
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Notifier fans notifications out to its sinks.
+type Notifier struct {
+	sinks []Sink
+}
+
+// New creates a notifier for the given sinks.
+func New(sinks ...Sink) *Notifier {
+	return &Notifier{sinks: sinks}
+}
+
+// FromSettings creates a notifier for the sinks enabled in the workspace
+// config. Environment variables in webhook URLs and headers are expanded.
+func FromSettings(settings storage.NotificationSettings) *Notifier {
+	var sinks []Sink
+
+	if settings.Desktop {
+		sinks = append(sinks, NewDesktop())
+	}
+	if settings.Slack != nil && settings.Slack.WebhookURL != "" {
+		sinks = append(sinks, SlackFromSettings(settings.Slack))
+	}
+	for _, hook := range settings.Webhooks {
+		if hook.URL == "" {
+			continue
+		}
+		headers := make(map[string]string, len(hook.Headers))
+		for k, v := range hook.Headers {
+			headers[k] = os.ExpandEnv(v)
+		}
+		sinks = append(sinks, NewWebhook(os.ExpandEnv(hook.URL), headers))
+	}
+
+	return New(sinks...)
+}
+
+// Enabled reports whether any sink is configured.
+func (n *Notifier) Enabled() bool {
+	return n != nil && len(n.sinks) > 0
+}
+
+// Notify delivers the notification to every sink. A failing sink does not
+// stop delivery to the others; all failures are returned joined.
+func (n *Notifier) Notify(ctx context.Context, notification Notification) error {
+	if !n.Enabled() {
+		return nil
+	}
+	if notification.Timestamp.IsZero() {
+		notification.Timestamp = time.Now()
+	}
+
+	var errs []error
+	for _, sink := range n.sinks {
+		sinkCtx, cancel := context.WithTimeout(ctx, defaultTimeout)
+		if err := sink.Notify(sinkCtx, notification); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+		cancel()
+	}
+
+	return errors.Join(errs...)
+}
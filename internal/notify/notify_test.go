@@ -0,0 +1,165 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+type recordingSink struct {
+	name string
+	err  error
+	got  []Notification
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) Notify(_ context.Context, n Notification) error {
+	s.got = append(s.got, n)
+
+	return s.err
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	failing := &recordingSink{name: "failing", err: errors.New("boom")}
+	ok := &recordingSink{name: "ok"}
+
+	err := New(failing, ok).Notify(context.Background(), Notification{Title: "Question"})
+	if err == nil || !strings.Contains(err.Error(), "failing: boom") {
+		t.Errorf("Notify() error = %v, want failing sink error", err)
+	}
+	if len(ok.got) != 1 {
+		t.Fatal("a failing sink should not stop delivery to the others")
+	}
+	if ok.got[0].Timestamp.IsZero() {
+		t.Error("Notify() should set the timestamp")
+	}
+
+	var disabled *Notifier
+	if err := disabled.Notify(context.Background(), Notification{}); err != nil {
+		t.Errorf("nil notifier Notify() = %v, want nil", err)
+	}
+}
+
+func TestFromSettings(t *testing.T) {
+	t.Setenv("TEST_HOOK_TOKEN", "secret")
+
+	n := FromSettings(storage.NotificationSettings{
+		Desktop: true,
+		Slack:   &storage.SlackNotification{WebhookURL: "https://hooks.slack.test/x"},
+		Webhooks: []storage.WebhookNotification{
+			{URL: "https://example.test/hook", Headers: map[string]string{"Authorization": "Bearer ${TEST_HOOK_TOKEN}"}},
+			{URL: ""},
+		},
+	})
+
+	var names []string
+	for _, s := range n.sinks {
+		names = append(names, s.Name())
+	}
+	if !slices.Equal(names, []string{"desktop", "slack", "webhook"}) {
+		t.Errorf("sinks = %v", names)
+	}
+	if got := n.sinks[2].(*Webhook).headers["Authorization"]; got != "Bearer secret" {
+		t.Errorf("header = %q, want expanded env var", got)
+	}
+
+	if FromSettings(storage.NotificationSettings{}).Enabled() {
+		t.Error("empty settings should not enable notifications")
+	}
+}
+
+func TestWebhook_Notify(t *testing.T) {
+	var got Notification
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	hook := NewWebhook(server.URL, map[string]string{"Authorization": "Bearer t"})
+	err := hook.Notify(context.Background(), Notification{Event: "question_pending", TaskID: "abc", Message: "Which DB?"})
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if got.TaskID != "abc" || got.Message != "Which DB?" || auth != "Bearer t" {
+		t.Errorf("received %+v with auth %q", got, auth)
+	}
+}
+
+func TestSlack_Notify(t *testing.T) {
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	err := NewSlack(server.URL, "#dev").Notify(context.Background(), Notification{
+		TaskID:  "abc",
+		Title:   "Agent is waiting",
+		Message: "Which DB?",
+		Options: []string{"PostgreSQL", "SQLite"},
+	})
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if payload["channel"] != "#dev" {
+		t.Errorf("channel = %q, want #dev", payload["channel"])
+	}
+	for _, want := range []string{"*Agent is waiting*", "Which DB?", "• PostgreSQL", "_Task abc_"} {
+		if !strings.Contains(payload["text"], want) {
+			t.Errorf("text missing %q:\n%s", want, payload["text"])
+		}
+	}
+}
+
+func TestWebhook_NotifyErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such hook", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	err := NewWebhook(server.URL, nil).Notify(context.Background(), Notification{})
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Notify() error = %v, want status error", err)
+	}
+}
+
+func TestDesktop_Command(t *testing.T) {
+	tests := []struct {
+		goos     string
+		wantName string
+		wantErr  bool
+	}{
+		{goos: "linux", wantName: "notify-send"},
+		{goos: "darwin", wantName: "osascript"},
+		{goos: "windows", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			var ran string
+			d := &Desktop{goos: tt.goos, run: func(_ context.Context, name string, _ ...string) error {
+				ran = name
+
+				return nil
+			}}
+
+			err := d.Notify(context.Background(), Notification{Title: "Title", Message: `Use "quotes"?`})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ran != tt.wantName {
+				t.Errorf("ran %q, want %q", ran, tt.wantName)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// Webhook posts notifications as JSON to an HTTP endpoint.
+type Webhook struct {
+	url        string
+	headers    map[string]string
+	httpClient *http.Client
+}
+
+// NewWebhook creates a generic webhook sink. Headers are sent with every
+// request, e.g. for authorization.
+func NewWebhook(url string, headers map[string]string) *Webhook {
+	return &Webhook{
+		url:        url,
+		headers:    headers,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+}
+
+// Name returns the sink name.
+func (w *Webhook) Name() string {
+	return "webhook"
+}
+
+// Notify posts the notification.
+func (w *Webhook) Notify(ctx context.Context, n Notification) error {
+	return postJSON(ctx, w.httpClient, w.url, w.headers, n)
+}
+
+// Slack posts notifications to a Slack incoming webhook.
+type Slack struct {
+	webhookURL string
+	channel    string
+	httpClient *http.Client
+}
+
+// NewSlack creates a Slack sink for an incoming webhook URL. An empty channel
+// posts to the webhook's default channel.
+func NewSlack(webhookURL, channel string) *Slack {
+	return &Slack{
+		webhookURL: webhookURL,
+		channel:    channel,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+}
+
+// SlackFromSettings creates a Slack sink from the workspace config, expanding
+// environment variables in the webhook URL.
+func SlackFromSettings(settings *storage.SlackNotification) *Slack {
+	return NewSlack(os.ExpandEnv(settings.WebhookURL), settings.Channel)
+}
+
+// Name returns the sink name.
+func (s *Slack) Name() string {
+	return "slack"
+}
+
+// Notify posts the notification as a Slack message.
+func (s *Slack) Notify(ctx context.Context, n Notification) error {
+	payload := map[string]string{"text": slackText(n)}
+	if s.channel != "" {
+		payload["channel"] = s.channel
+	}
+
+	return postJSON(ctx, s.httpClient, s.webhookURL, nil, payload)
+}
+
+// slackText formats a notification in Slack mrkdwn.
+func slackText(n Notification) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "*%s*", n.Title)
+	if n.Message != "" {
+		sb.WriteString("\n" + n.Message)
+	}
+	for _, opt := range n.Options {
+		sb.WriteString("\n• " + opt)
+	}
+	if n.TaskID != "" {
+		fmt.Fprintf(&sb, "\n_Task %s_", n.TaskID)
+	}
+
+	return sb.String()
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// Exit codes of mehr commands. Together with the --json documents they are
+// the interface editor integrations and scripts rely on, so existing codes
+// never change meaning.
+const (
+	ExitOK       = 0
+	ExitError    = 1   // Any failure without a more specific code
+	ExitUsage    = 2   // Invalid flags or arguments
+	ExitNoTask   = 3   // The command needs an active task
+	ExitQuestion = 4   // The agent is waiting for an answer
+	ExitBlocked  = 5   // Guardrails or the task scope blocked the changes
+	ExitCanceled = 130 // Interrupted, e.g. by Ctrl-C; the task can be resumed
+)
+
+// Error codes in JSON error documents, one per exit code.
+const (
+	CodeError    = "error"
+	CodeUsage    = "usage"
+	CodeNoTask   = "no_active_task"
+	CodeQuestion = "pending_question"
+	CodeBlocked  = "blocked"
+	CodeCanceled = "canceled"
+)
+
+// Error is an error with a machine-readable code and the exit code it ends
+// the process with.
+type Error struct {
+	Code string
+	Exit int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithCode attaches an error code and exit code to err. A nil err stays nil.
+func WithCode(err error, code string, exit int) error {
+	if err == nil {
+		return nil
+	}
+
+	return &Error{Code: code, Exit: exit, Err: err}
+}
+
+// Classify returns the error code and exit code of err: those of the first
+// *Error in its chain, or CodeError and ExitError.
+func Classify(err error) (string, int) {
+	if err == nil {
+		return "", ExitOK
+	}
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code, coded.Exit
+	}
+
+	return CodeError, ExitError
+}
+
+// ExitCode returns the process exit code for err.
+func ExitCode(err error) int {
+	_, exit := Classify(err)
+
+	return exit
+}
+
+// ErrorDocument is the JSON written for a command that failed in --json mode.
+type ErrorDocument struct {
+	Error ErrorInfo `json:"error"`
+}
+
+// ErrorInfo describes a failure.
+type ErrorInfo struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// JSON writes v as indented JSON followed by a newline.
+func JSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+// WriteError writes the error document for err.
+func WriteError(w io.Writer, err error) error {
+	code, exit := Classify(err)
+
+	return JSON(w, ErrorDocument{Error: ErrorInfo{Code: code, Message: err.Error(), ExitCode: exit}})
+}
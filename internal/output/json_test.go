@@ -0,0 +1,60 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	blocked := WithCode(errors.New("secret found"), CodeBlocked, ExitBlocked)
+	tests := []struct {
+		name     string
+		err      error
+		wantCode string
+		wantExit int
+	}{
+		{"nil", nil, "", ExitOK},
+		{"plain", errors.New("boom"), CodeError, ExitError},
+		{"coded", blocked, CodeBlocked, ExitBlocked},
+		{"wrapped", fmt.Errorf("implement: %w", blocked), CodeBlocked, ExitBlocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, exit := Classify(tt.err)
+			if code != tt.wantCode || exit != tt.wantExit {
+				t.Errorf("Classify() = %q, %d, want %q, %d", code, exit, tt.wantCode, tt.wantExit)
+			}
+			if got := ExitCode(tt.err); got != tt.wantExit {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.wantExit)
+			}
+		})
+	}
+
+	if WithCode(nil, CodeUsage, ExitUsage) != nil {
+		t.Error("WithCode(nil) != nil")
+	}
+	inner := errors.New("inner")
+	if !errors.Is(WithCode(inner, CodeUsage, ExitUsage), inner) {
+		t.Error("WithCode does not unwrap")
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	var buf bytes.Buffer
+	err := fmt.Errorf("plan: %w", WithCode(errors.New("no active task"), CodeNoTask, ExitNoTask))
+	if err := WriteError(&buf, err); err != nil {
+		t.Fatalf("WriteError: %v", err)
+	}
+
+	var doc ErrorDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	want := ErrorInfo{Code: CodeNoTask, Message: "plan: no active task", ExitCode: ExitNoTask}
+	if doc.Error != want {
+		t.Errorf("error = %+v, want %+v", doc.Error, want)
+	}
+}
@@ -0,0 +1,354 @@
+// Package patchset exports the commits of a task for review outside a code
+// host and imports them into another clone.
+//
+// An export is a directory holding the task's commits, either as a
+// git format-patch series (0001-*.patch, ...) or as a single git bundle,
+// next to manifest.json and a copy of the task's specifications:
+//
+//	<task>.patches/
+//	  manifest.json
+//	  0001-FEATURE-123-checkpoint-1-add-cache.patch
+//	  specifications/specification-1.md
+//
+// The manifest records the base commit the series applies on, the task's
+// checkpoints, and the specifications and sessions behind the change.
+// Session transcripts stay in the workspace; the manifest only lists them.
+package patchset
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+// Patch set errors.
+var (
+	ErrNoCommits      = errors.New("task has no commits to export")
+	ErrNotPatchSet    = errors.New("not a mehrhof patch export")
+	ErrUnknownVersion = errors.New("unsupported patch export version")
+	ErrMissingBase    = errors.New("base commit not found")
+	ErrBranchExists   = errors.New("branch already exists")
+	ErrDirtyTree      = errors.New("working tree has uncommitted changes")
+)
+
+const (
+	// ManifestName is the name of the manifest file in an export.
+	ManifestName = "manifest.json"
+
+	// FormatVersion is the current manifest version.
+	FormatVersion = 1
+
+	// Formats the commits are exported in.
+	FormatSeries = "series"
+	FormatBundle = "bundle"
+
+	specsDir = "specifications"
+)
+
+// Manifest describes a patch export.
+type Manifest struct {
+	Version     int       `json:"version"`
+	CreatedAt   time.Time `json:"created_at"`
+	TaskID      string    `json:"task_id"`
+	Title       string    `json:"title,omitempty"`
+	ExternalKey string    `json:"external_key,omitempty"`
+	Branch      string    `json:"branch"`
+	BaseBranch  string    `json:"base_branch,omitempty"`
+	BaseCommit  string    `json:"base_commit"`
+	HeadCommit  string    `json:"head_commit"`
+	Format      string    `json:"format"`
+	Commits     int       `json:"commits"`
+	Patches     []string  `json:"patches,omitempty"` // Series files, in apply order
+	Bundle      string    `json:"bundle,omitempty"`  // Bundle file
+
+	Checkpoints    []Checkpoint    `json:"checkpoints,omitempty"`
+	Specifications []Specification `json:"specifications,omitempty"`
+	Sessions       []Session       `json:"sessions,omitempty"`
+}
+
+// Checkpoint is a task checkpoint at export time.
+type Checkpoint struct {
+	Number  int    `json:"number"`
+	Commit  string `json:"commit"`
+	Message string `json:"message"`
+}
+
+// Specification is a specification copied into the export.
+type Specification struct {
+	Number int    `json:"number"`
+	Title  string `json:"title,omitempty"`
+	Status string `json:"status,omitempty"`
+	File   string `json:"file"` // Relative to the export directory
+}
+
+// Session is an agent session of the task.
+type Session struct {
+	File          string    `json:"file"`
+	Type          string    `json:"type"`
+	Agent         string    `json:"agent,omitempty"`
+	StartedAt     time.Time `json:"started_at"`
+	Exchanges     int       `json:"exchanges"`
+	Specification int       `json:"specification,omitempty"`
+}
+
+// Options configures an export.
+type Options struct {
+	Bundle bool // Write a git bundle instead of a patch series
+}
+
+// ImportOptions configures an import.
+type ImportOptions struct {
+	Branch string // Branch to create; defaults to the exported branch
+}
+
+// Export writes the commits of a task, from where its branch left the base
+// branch to its tip, and the manifest into dir. The branch tip falls back to
+// the latest checkpoint when the branch is gone.
+func Export(ctx context.Context, ws *storage.Workspace, git *vcs.Git, taskID, dir string, opts Options) (*Manifest, error) {
+	work, err := ws.LoadWork(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("load task %s: %w", taskID, err)
+	}
+
+	manifest := &Manifest{
+		Version:     FormatVersion,
+		CreatedAt:   time.Now().UTC(),
+		TaskID:      taskID,
+		Title:       work.Metadata.Title,
+		ExternalKey: work.Metadata.ExternalKey,
+		Branch:      work.Git.Branch,
+		BaseBranch:  work.Git.BaseBranch,
+		Format:      FormatSeries,
+	}
+
+	head := manifest.Branch
+	if head == "" || !git.BranchExists(ctx, head) {
+		latest, err := git.GetLatestCheckpoint(ctx, taskID)
+		if err != nil || latest == nil {
+			return nil, fmt.Errorf("%w: branch %q not found and no checkpoints", ErrNoCommits, manifest.Branch)
+		}
+		head = latest.ID
+		if opts.Bundle {
+			return nil, fmt.Errorf("bundle needs the task branch %q; export a patch series instead", manifest.Branch)
+		}
+	}
+	if manifest.HeadCommit, err = git.RevParse(ctx, head); err != nil {
+		return nil, err
+	}
+
+	base := manifest.BaseBranch
+	if base == "" {
+		if base, err = git.GetBaseBranch(ctx); err != nil {
+			return nil, fmt.Errorf("determine base branch: %w", err)
+		}
+	}
+	if manifest.BaseCommit, err = git.GetMergeBase(ctx, base, head); err != nil {
+		return nil, err
+	}
+	if manifest.Commits, err = git.GetBranchCommitCount(ctx, head, manifest.BaseCommit); err != nil {
+		return nil, fmt.Errorf("count commits: %w", err)
+	}
+	if manifest.Commits == 0 {
+		return nil, fmt.Errorf("%w: %s has nothing on top of %s", ErrNoCommits, head, base)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create export directory: %w", err)
+	}
+
+	if opts.Bundle {
+		manifest.Format = FormatBundle
+		manifest.Bundle = taskID + ".bundle"
+		if err := git.CreateBundle(ctx, filepath.Join(dir, manifest.Bundle), head, manifest.BaseCommit); err != nil {
+			return nil, err
+		}
+	} else {
+		revRange := manifest.BaseCommit + ".." + manifest.HeadCommit
+		if manifest.Patches, err = git.FormatPatch(ctx, revRange, dir); err != nil {
+			return nil, err
+		}
+	}
+
+	if checkpoints, err := git.ListCheckpoints(ctx, taskID); err == nil {
+		for _, cp := range checkpoints {
+			manifest.Checkpoints = append(manifest.Checkpoints, Checkpoint{Number: cp.Number, Commit: cp.ID, Message: cp.Message})
+		}
+	}
+	if err := copySpecifications(ws, taskID, dir, manifest); err != nil {
+		return nil, err
+	}
+	if err := listSessions(ws, taskID, manifest); err != nil {
+		return nil, err
+	}
+
+	if err := writeManifest(dir, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// Import creates a branch holding the exported commits and checks it out.
+// The working tree must be clean and the repository must contain the base
+// commit. A series that does not apply leaves the repository as it was.
+func Import(ctx context.Context, git *vcs.Git, dir string, opts ImportOptions) (*Manifest, error) {
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	branch := opts.Branch
+	if branch == "" {
+		branch = manifest.Branch
+	}
+	if branch == "" {
+		branch = "review/" + manifest.TaskID
+	}
+	if git.BranchExists(ctx, branch) {
+		return nil, fmt.Errorf("%w: %s (use --branch to pick another name)", ErrBranchExists, branch)
+	}
+	if changed, err := git.HasChanges(ctx); err != nil {
+		return nil, err
+	} else if changed {
+		return nil, ErrDirtyTree
+	}
+	if !git.HasCommit(ctx, manifest.BaseCommit) {
+		return nil, fmt.Errorf("%w: %s (fetch %s first)", ErrMissingBase, manifest.BaseCommit[:min(8, len(manifest.BaseCommit))], manifest.BaseBranch)
+	}
+
+	if manifest.Format == FormatBundle {
+		if err := git.FetchBundle(ctx, filepath.Join(dir, manifest.Bundle), manifest.Branch, branch); err != nil {
+			return nil, err
+		}
+		if err := git.Checkout(ctx, branch); err != nil {
+			return nil, err
+		}
+
+		return manifest, nil
+	}
+
+	previous, _ := git.CurrentBranch(ctx)
+	if err := git.CreateBranch(ctx, branch, manifest.BaseCommit); err != nil {
+		return nil, err
+	}
+
+	patches := make([]string, 0, len(manifest.Patches))
+	for _, p := range manifest.Patches {
+		patches = append(patches, filepath.Join(dir, p))
+	}
+	if err := git.ApplyMailbox(ctx, patches...); err != nil {
+		_ = git.AbortMailbox(ctx)
+		if previous != "" {
+			_ = git.Checkout(ctx, previous)
+			_ = git.DeleteBranch(ctx, branch, true)
+		}
+
+		return nil, fmt.Errorf("apply patches: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// ReadManifest reads and checks the manifest of an export directory.
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: no %s in %s", ErrNotPatchSet, ManifestName, dir)
+		}
+
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotPatchSet, err)
+	}
+	if manifest.Version < 1 || manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("%w %d (this mehrhof reads version %d)", ErrUnknownVersion, manifest.Version, FormatVersion)
+	}
+	if manifest.BaseCommit == "" || (manifest.Format == FormatBundle) == (manifest.Bundle == "") {
+		return nil, fmt.Errorf("%w: incomplete manifest", ErrNotPatchSet)
+	}
+
+	return &manifest, nil
+}
+
+func writeManifest(dir string, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ManifestName), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// copySpecifications copies the task's specifications into the export.
+func copySpecifications(ws *storage.Workspace, taskID, dir string, manifest *Manifest) error {
+	specs, err := ws.ListSpecificationsWithStatus(taskID)
+	if err != nil {
+		return fmt.Errorf("list specifications: %w", err)
+	}
+	if len(specs) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, specsDir), 0o755); err != nil {
+		return fmt.Errorf("create specifications directory: %w", err)
+	}
+	for _, spec := range specs {
+		src := ws.SpecificationPath(taskID, spec.Number)
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return fmt.Errorf("read specification %d: %w", spec.Number, err)
+		}
+		file := specsDir + "/" + filepath.Base(src)
+		if err := os.WriteFile(filepath.Join(dir, file), data, 0o644); err != nil {
+			return fmt.Errorf("write specification %d: %w", spec.Number, err)
+		}
+		manifest.Specifications = append(manifest.Specifications, Specification{
+			Number: spec.Number,
+			Title:  spec.Title,
+			Status: spec.Status,
+			File:   file,
+		})
+	}
+
+	return nil
+}
+
+// listSessions adds the task's sessions to the manifest.
+func listSessions(ws *storage.Workspace, taskID string, manifest *Manifest) error {
+	files, err := ws.ListSessionFiles(taskID)
+	if err != nil {
+		return fmt.Errorf("list sessions: %w", err)
+	}
+
+	for _, file := range files {
+		session, err := ws.LoadSession(taskID, file)
+		if err != nil {
+			continue // Skip unreadable sessions
+		}
+		manifest.Sessions = append(manifest.Sessions, Session{
+			File:          file,
+			Type:          session.Metadata.Type,
+			Agent:         session.Metadata.Agent,
+			StartedAt:     session.Metadata.StartedAt,
+			Exchanges:     len(session.Exchanges),
+			Specification: session.Metadata.Specification,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,260 @@
+package patchset
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+// newTaskRepo creates a repository whose task "task1" has two commits on
+// feature/task1 on top of main, one specification and one session.
+func newTaskRepo(t *testing.T) (*storage.Workspace, *vcs.Git) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	git(t, dir, "init", "-q", "-b", "main")
+	git(t, dir, "config", "user.email", "test@example.com")
+	git(t, dir, "config", "user.name", "Test")
+	writeFile(t, filepath.Join(dir, ".gitignore"), ".mehrhof/\n")
+	writeFile(t, filepath.Join(dir, "app.go"), "package app\n")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "initial")
+
+	git(t, dir, "checkout", "-q", "-b", "feature/task1")
+	writeFile(t, filepath.Join(dir, "cache.go"), "package app\n\n// Cache holds lookups.\ntype Cache struct{}\n")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "[task1] checkpoint 1: Add cache")
+	writeFile(t, filepath.Join(dir, "app.go"), "package app\n\nvar cache Cache\n")
+	git(t, dir, "commit", "-q", "-am", "[task1] checkpoint 2: Use cache")
+
+	ws, err := storage.OpenWorkspace(dir, nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+	work, err := ws.CreateWork("task1", storage.SourceInfo{Type: "file", Ref: "task.md"})
+	if err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	work.Metadata.Title = "Cache lookups"
+	work.Git = storage.GitInfo{Branch: "feature/task1", BaseBranch: "main"}
+	if err := ws.SaveWork(work); err != nil {
+		t.Fatalf("SaveWork: %v", err)
+	}
+	if err := ws.SaveSpecification("task1", 1, "---\ntitle: Add a cache\nstatus: done\n---\n# Add a cache\n"); err != nil {
+		t.Fatalf("SaveSpecification: %v", err)
+	}
+	if _, _, err := ws.CreateSession("task1", "implementing", "claude", "implementing"); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	g, err := vcs.New(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("vcs.New: %v", err)
+	}
+
+	return ws, g
+}
+
+// cloneMain clones the repository with only main checked out.
+func cloneMain(t *testing.T, src string) *vcs.Git {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "clone")
+	git(t, filepath.Dir(dir), "clone", "-q", "--single-branch", "--branch", "main", src, dir)
+	git(t, dir, "config", "user.email", "reviewer@example.com")
+	git(t, dir, "config", "user.name", "Reviewer")
+
+	g, err := vcs.New(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("vcs.New: %v", err)
+	}
+
+	return g
+}
+
+func TestExportImport(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	for _, bundle := range []bool{false, true} {
+		name := FormatSeries
+		if bundle {
+			name = FormatBundle
+		}
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			ws, g := newTaskRepo(t)
+			out := filepath.Join(t.TempDir(), "task1.patches")
+
+			manifest, err := Export(ctx, ws, g, "task1", out, Options{Bundle: bundle})
+			if err != nil {
+				t.Fatalf("Export: %v", err)
+			}
+			if manifest.Commits != 2 || manifest.Format != name || manifest.Title != "Cache lookups" {
+				t.Errorf("manifest = %+v", manifest)
+			}
+			if !bundle && len(manifest.Patches) != 2 {
+				t.Errorf("Patches = %v, want 2", manifest.Patches)
+			}
+			if len(manifest.Specifications) != 1 || manifest.Specifications[0].Title != "Add a cache" {
+				t.Errorf("Specifications = %+v", manifest.Specifications)
+			}
+			if _, err := os.Stat(filepath.Join(out, manifest.Specifications[0].File)); err != nil {
+				t.Errorf("specification not copied: %v", err)
+			}
+			if len(manifest.Sessions) != 1 || manifest.Sessions[0].Type != "implementing" {
+				t.Errorf("Sessions = %+v", manifest.Sessions)
+			}
+
+			clone := cloneMain(t, g.Root())
+			imported, err := Import(ctx, clone, out, ImportOptions{})
+			if err != nil {
+				t.Fatalf("Import: %v", err)
+			}
+			if branch, _ := clone.CurrentBranch(ctx); branch != "feature/task1" || imported.TaskID != "task1" {
+				t.Errorf("on branch %s after import, want feature/task1", branch)
+			}
+			if log := git(t, clone.Root(), "log", "--format=%s", "main..HEAD"); log != "[task1] checkpoint 2: Use cache\n[task1] checkpoint 1: Add cache" {
+				t.Errorf("imported commits:\n%s", log)
+			}
+			if tree, want := git(t, clone.Root(), "rev-parse", "HEAD^{tree}"), git(t, g.Root(), "rev-parse", "feature/task1^{tree}"); tree != want {
+				t.Errorf("imported tree %s, want %s", tree, want)
+			}
+			if head, _ := clone.RevParse(ctx, "HEAD"); bundle && head != manifest.HeadCommit {
+				t.Errorf("bundle HEAD = %s, want the exported commit %s", head, manifest.HeadCommit)
+			}
+
+			if _, err := Import(ctx, clone, out, ImportOptions{}); !errors.Is(err, ErrBranchExists) {
+				t.Errorf("second Import error = %v, want ErrBranchExists", err)
+			}
+		})
+	}
+}
+
+func TestImport_Conflict(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	ws, g := newTaskRepo(t)
+	out := filepath.Join(t.TempDir(), "task1.patches")
+	if _, err := Export(ctx, ws, g, "task1", out, Options{}); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	// A reviewer whose clone has the base commit but the patch context was
+	// edited away cannot apply the series
+	clone := cloneMain(t, g.Root())
+	git(t, clone.Root(), "checkout", "-q", "-b", "local")
+	writeFile(t, filepath.Join(clone.Root(), "cache.go"), "package other\n")
+	git(t, clone.Root(), "add", ".")
+	git(t, clone.Root(), "commit", "-q", "-m", "local cache")
+	manifest, err := ReadManifest(out)
+	if err != nil {
+		t.Fatalf("ReadManifest: %v", err)
+	}
+	manifest.BaseCommit = git(t, clone.Root(), "rev-parse", "HEAD")
+	if err := writeManifest(out, manifest); err != nil {
+		t.Fatalf("writeManifest: %v", err)
+	}
+
+	if _, err := Import(ctx, clone, out, ImportOptions{}); err == nil {
+		t.Fatal("Import succeeded, want the first patch to fail")
+	}
+	if branch, _ := clone.CurrentBranch(ctx); branch != "local" {
+		t.Errorf("on branch %s after a failed import, want local", branch)
+	}
+	if clone.BranchExists(ctx, "feature/task1") {
+		t.Error("failed import left its branch behind")
+	}
+	if changed, _ := clone.HasChanges(ctx); changed {
+		t.Error("failed import left changes behind")
+	}
+}
+
+func TestImport_Errors(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	ws, g := newTaskRepo(t)
+	out := filepath.Join(t.TempDir(), "task1.patches")
+	if _, err := Export(ctx, ws, g, "task1", out, Options{}); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	other := t.TempDir()
+	git(t, other, "init", "-q", "-b", "main")
+	git(t, other, "-c", "user.email=a@example.com", "-c", "user.name=A", "commit", "-q", "--allow-empty", "-m", "unrelated")
+	unrelated, err := vcs.New(ctx, other)
+	if err != nil {
+		t.Fatalf("vcs.New: %v", err)
+	}
+	if _, err := Import(ctx, unrelated, out, ImportOptions{}); !errors.Is(err, ErrMissingBase) {
+		t.Errorf("Import error = %v, want ErrMissingBase", err)
+	}
+
+	writeFile(t, filepath.Join(out, ManifestName), `{"version": 99, "base_commit": "abc", "format": "series"}`)
+	if _, err := Import(ctx, unrelated, out, ImportOptions{}); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("Import error = %v, want ErrUnknownVersion", err)
+	}
+	if _, err := Import(ctx, unrelated, t.TempDir(), ImportOptions{}); !errors.Is(err, ErrNotPatchSet) {
+		t.Errorf("Import error = %v, want ErrNotPatchSet", err)
+	}
+}
+
+func TestExport_NoCommits(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	ws, g := newTaskRepo(t)
+	git(t, g.Root(), "checkout", "-q", "main")
+	git(t, g.Root(), "branch", "-q", "-f", "feature/task1", "main")
+
+	_, err := Export(ctx, ws, g, "task1", filepath.Join(t.TempDir(), "out"), Options{})
+	if !errors.Is(err, ErrNoCommits) {
+		t.Errorf("Export error = %v, want ErrNoCommits", err)
+	}
+}
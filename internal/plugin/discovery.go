@@ -7,6 +7,8 @@ import (
 
 	_maps "maps"
 	_slices "slices"
+
+	"github.com/valksor/go-mehrhof/internal/config"
 )
 
 const (
@@ -48,7 +50,7 @@ func DefaultGlobalDir() (string, error) {
 
 // DefaultProjectDir returns the default project plugins directory.
 func DefaultProjectDir(workspaceRoot string) string {
-	return filepath.Join(workspaceRoot, ".mehrhof", "plugins")
+	return filepath.Join(config.TaskRoot(workspaceRoot), "plugins")
 }
 
 // Discover finds all plugins in configured directories.
@@ -0,0 +1,518 @@
+package plugin
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// InstallRecordFileName is the file in an installed plugin's directory that
+// records where it was installed from, so it can be updated.
+const InstallRecordFileName = ".install.yaml"
+
+// maxArchiveSize caps the size of a plugin archive.
+const maxArchiveSize = 256 << 20
+
+var (
+	// ErrAlreadyInstalled is returned when a plugin with the same name is
+	// already installed in the target directory.
+	ErrAlreadyInstalled = errors.New("plugin already installed")
+
+	// ErrChecksumMismatch is returned when an archive does not match the
+	// expected SHA-256 checksum.
+	ErrChecksumMismatch = errors.New("plugin archive checksum mismatch")
+
+	// ErrSignatureInvalid is returned when an archive signature does not
+	// verify against the public key.
+	ErrSignatureInvalid = errors.New("plugin archive signature is invalid")
+
+	// ErrNoInstallRecord is returned when updating a plugin that was not
+	// installed with 'mehr plugins install'.
+	ErrNoInstallRecord = errors.New("plugin has no install record")
+
+	// ErrVerificationRequired is returned when updating a plugin that was
+	// installed with a checksum, without a checksum for the new archive.
+	ErrVerificationRequired = errors.New("plugin update must be verified")
+)
+
+// InstallOptions configures Install.
+type InstallOptions struct {
+	// Checksum is the expected SHA-256 of an archive, in hex, optionally
+	// prefixed with "sha256:".
+	Checksum string
+
+	// Signature is the path or URL of an ed25519 signature of an archive,
+	// raw or base64 encoded. Requires PublicKey.
+	Signature string
+
+	// PublicKey is the base64 encoded ed25519 key Signature is checked with.
+	PublicKey string
+
+	// Replace overwrites an installed plugin of the same name.
+	Replace bool
+}
+
+// InstallRecord describes where an installed plugin came from and how it
+// was verified, so updates are verified the same way.
+type InstallRecord struct {
+	Source      string    `yaml:"source"`
+	InstalledAt time.Time `yaml:"installed_at"`
+	Checksum    string    `yaml:"checksum,omitempty"`   // SHA-256 the archive was checked against
+	Signature   string    `yaml:"signature,omitempty"`  // Path or URL of the archive's signature
+	PublicKey   string    `yaml:"public_key,omitempty"` // Key the signature was checked with
+}
+
+// Source kinds accepted by Install.
+const (
+	SourceGit     = "git"
+	SourceArchive = "archive"
+	SourceDir     = "dir"
+)
+
+// SourceKind reports whether source is a git repository, an archive
+// (.tar.gz, .tgz or .zip, local or over HTTP) or a local directory.
+func SourceKind(source string) string {
+	if isArchive(source) {
+		return SourceArchive
+	}
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") ||
+		strings.HasPrefix(source, "git@") || strings.HasSuffix(source, ".git") {
+		return SourceGit
+	}
+
+	return SourceDir
+}
+
+func isArchive(source string) bool {
+	path := strings.ToLower(strings.SplitN(source, "?", 2)[0])
+
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz") || strings.HasSuffix(path, ".zip")
+}
+
+// Install fetches a plugin from a git repository, an archive or a local
+// directory into targetDir, under the name its manifest declares. The
+// manifest is validated and archives are checked against the optional
+// checksum and signature before anything is installed.
+func Install(ctx context.Context, source, targetDir string, opts InstallOptions) (*Manifest, error) {
+	kind := SourceKind(source)
+	if kind != SourceArchive && (opts.Checksum != "" || opts.Signature != "") {
+		return nil, errors.New("checksum and signature apply to archive sources only")
+	}
+	if opts.Signature != "" && opts.PublicKey == "" {
+		return nil, errors.New("signature requires a public key")
+	}
+
+	if err := EnsureDir(targetDir); err != nil {
+		return nil, fmt.Errorf("create plugins directory: %w", err)
+	}
+
+	// Stage next to the target so the final move is a rename
+	staging, err := os.MkdirTemp(targetDir, ".install-")
+	if err != nil {
+		return nil, fmt.Errorf("create staging directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+
+	root := filepath.Join(staging, "plugin")
+	switch kind {
+	case SourceGit:
+		err = cloneGit(ctx, source, root)
+	case SourceArchive:
+		err = fetchArchive(ctx, source, root, opts)
+	default:
+		err = copyDir(source, root)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	root = pluginRoot(root)
+	manifest, err := LoadManifest(filepath.Join(root, ManifestFileName))
+	if err != nil {
+		return nil, fmt.Errorf("invalid plugin: %w", err)
+	}
+	if manifest.Name != filepath.Base(manifest.Name) || strings.HasPrefix(manifest.Name, ".") {
+		return nil, fmt.Errorf("invalid plugin name %q", manifest.Name)
+	}
+
+	record, err := yaml.Marshal(InstallRecord{
+		Source:      installSource(source, kind),
+		InstalledAt: time.Now(),
+		Checksum:    opts.Checksum,
+		Signature:   installSource(opts.Signature, SourceArchive),
+		PublicKey:   opts.PublicKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal install record: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, InstallRecordFileName), record, 0o644); err != nil {
+		return nil, fmt.Errorf("write install record: %w", err)
+	}
+
+	dest := PluginDir(targetDir, manifest.Name)
+	if err := replaceDir(root, dest, opts.Replace); err != nil {
+		return nil, err
+	}
+
+	return LoadManifest(filepath.Join(dest, ManifestFileName))
+}
+
+// Update reinstalls an installed plugin from the source it was installed
+// from. A plugin installed with a signature is checked against the recorded
+// public key, with the signature from the recorded location unless opts
+// names another; one installed with only a checksum needs the checksum of
+// the new archive.
+func Update(ctx context.Context, m *Manifest, opts InstallOptions) (*Manifest, error) {
+	record, err := ReadInstallRecord(m.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.PublicKey != "" {
+		if opts.PublicKey != "" && opts.PublicKey != record.PublicKey {
+			return nil, errors.New("plugin was installed with another public key; reinstall it to change keys")
+		}
+		opts.PublicKey = record.PublicKey
+		if opts.Signature == "" {
+			opts.Signature = record.Signature
+		}
+	}
+	if record.Checksum != "" && opts.Checksum == "" && opts.Signature == "" {
+		return nil, fmt.Errorf("%w: %s was installed with a checksum, pass the checksum of the new archive", ErrVerificationRequired, m.Name)
+	}
+
+	opts.Replace = true
+	updated, err := Install(ctx, record.Source, filepath.Dir(m.Dir), opts)
+	if err != nil {
+		return nil, err
+	}
+	if updated.Name != m.Name {
+		return nil, fmt.Errorf("source now provides plugin %q instead of %q", updated.Name, m.Name)
+	}
+
+	return updated, nil
+}
+
+// ReadInstallRecord returns the install record of the plugin in dir.
+func ReadInstallRecord(dir string) (*InstallRecord, error) {
+	data, err := os.ReadFile(filepath.Join(dir, InstallRecordFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoInstallRecord
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read install record: %w", err)
+	}
+
+	var record InstallRecord
+	if err := yaml.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("parse install record: %w", err)
+	}
+
+	return &record, nil
+}
+
+// installSource makes local sources absolute so updates work from any
+// directory.
+func installSource(source, kind string) string {
+	if source == "" || kind == SourceGit || strings.Contains(source, "://") {
+		return source
+	}
+	if abs, err := filepath.Abs(source); err == nil {
+		return abs
+	}
+
+	return source
+}
+
+// pluginRoot returns the directory holding the manifest: dir itself, or its
+// only subdirectory, as archives usually wrap their content in one.
+func pluginRoot(dir string) string {
+	if _, err := os.Stat(filepath.Join(dir, ManifestFileName)); err == nil {
+		return dir
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return dir
+	}
+
+	return filepath.Join(dir, entries[0].Name())
+}
+
+// replaceDir moves src to dest. An existing dest is only replaced when
+// replace is set, and is restored if the move fails.
+func replaceDir(src, dest string, replace bool) error {
+	if _, err := os.Stat(dest); err == nil {
+		if !replace {
+			return fmt.Errorf("%w: %s", ErrAlreadyInstalled, dest)
+		}
+		backup := dest + ".old"
+		_ = os.RemoveAll(backup)
+		if err := os.Rename(dest, backup); err != nil {
+			return fmt.Errorf("move old plugin aside: %w", err)
+		}
+		if err := os.Rename(src, dest); err != nil {
+			_ = os.Rename(backup, dest)
+
+			return fmt.Errorf("install plugin: %w", err)
+		}
+
+		return os.RemoveAll(backup)
+	}
+
+	if err := os.Rename(src, dest); err != nil {
+		return fmt.Errorf("install plugin: %w", err)
+	}
+
+	return nil
+}
+
+func cloneGit(ctx context.Context, url, dest string) error {
+	cmd := exec.CommandContext(ctx, "git", "clone", "--depth", "1", "--quiet", url, dest)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git clone failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	// The history is not needed once the plugin is installed
+	return os.RemoveAll(filepath.Join(dest, ".git"))
+}
+
+// fetchArchive downloads or reads an archive, verifies it and extracts it
+// into dest.
+func fetchArchive(ctx context.Context, source, dest string, opts InstallOptions) error {
+	data, err := readSource(ctx, source)
+	if err != nil {
+		return fmt.Errorf("read archive: %w", err)
+	}
+
+	if opts.Checksum != "" {
+		want := strings.TrimPrefix(strings.ToLower(opts.Checksum), "sha256:")
+		sum := sha256.Sum256(data)
+		if got := hex.EncodeToString(sum[:]); got != want {
+			return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, want, got)
+		}
+	}
+	if opts.Signature != "" {
+		if err := verifySignature(ctx, data, opts.Signature, opts.PublicKey); err != nil {
+			return err
+		}
+	}
+
+	path := strings.ToLower(strings.SplitN(source, "?", 2)[0])
+	if strings.HasSuffix(path, ".zip") {
+		return extractZip(data, dest)
+	}
+
+	return extractTarGz(data, dest)
+}
+
+func verifySignature(ctx context.Context, data []byte, signature, publicKey string) error {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return errors.New("public key must be a base64 encoded ed25519 key")
+	}
+
+	sig, err := readSource(ctx, signature)
+	if err != nil {
+		return fmt.Errorf("read signature: %w", err)
+	}
+	if len(sig) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+		if err != nil {
+			return fmt.Errorf("%w: signature is neither raw nor base64", ErrSignatureInvalid)
+		}
+		sig = decoded
+	}
+
+	if !ed25519.Verify(key, data, sig) {
+		return ErrSignatureInvalid
+	}
+
+	return nil
+}
+
+// readSource reads a local file or downloads a URL.
+func readSource(ctx context.Context, source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return os.ReadFile(source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 5 * time.Minute}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download %s: %s", source, resp.Status)
+	}
+
+	return readLimited(resp.Body)
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxArchiveSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxArchiveSize {
+		return nil, fmt.Errorf("archive larger than %d MB", maxArchiveSize>>20)
+	}
+
+	return data, nil
+}
+
+// extractPath returns where an archive entry goes, rejecting entries that
+// would land outside dest.
+func extractPath(dest, name string) (string, error) {
+	path := filepath.Join(dest, name)
+	if path != dest && !strings.HasPrefix(path, dest+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry %q escapes the plugin directory", name)
+	}
+
+	return path, nil
+}
+
+func extractTarGz(data []byte, dest string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("open archive: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read archive: %w", err)
+		}
+
+		path, err := extractPath(dest, hdr.Name)
+		if err != nil {
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(path, tr, hdr.FileInfo().Mode()); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("archive entry %q: only files and directories are supported", hdr.Name)
+		}
+	}
+}
+
+func extractZip(data []byte, dest string) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("open archive: %w", err)
+	}
+
+	for _, f := range zr.File {
+		path, err := extractPath(dest, f.Name)
+		if err != nil {
+			return err
+		}
+		mode := f.Mode()
+		if mode.IsDir() {
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				return err
+			}
+
+			continue
+		}
+		if !mode.IsRegular() {
+			return fmt.Errorf("archive entry %q: only files and directories are supported", f.Name)
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("read archive entry %q: %w", f.Name, err)
+		}
+		err = writeFile(path, rc, mode)
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeFile(path string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, io.LimitReader(r, maxArchiveSize)); err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+// copyDir recursively copies a directory.
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		dstPath := filepath.Join(dst, relPath)
+
+		if info.IsDir() {
+			if info.Name() == ".git" && path != src {
+				return filepath.SkipDir
+			}
+
+			return os.MkdirAll(dstPath, info.Mode()|0o700)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(dstPath, data, info.Mode())
+	})
+}
@@ -0,0 +1,288 @@
+package plugin
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTarGz writes a .tar.gz archive of files, keyed by archive path.
+func writeTarGz(t *testing.T, path string, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestSourceKind(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/user/mehrhof-jira":         SourceGit,
+		"git@github.com:user/mehrhof-jira.git":         SourceGit,
+		"https://example.com/jira-1.0.tar.gz":          SourceArchive,
+		"https://example.com/jira.zip?token=abc":       SourceArchive,
+		"./dist/jira.tgz":                              SourceArchive,
+		"./my-plugin":                                  SourceDir,
+		"/home/user/plugins/jira":                      SourceDir,
+		"https://example.com/download/jira.TAR.GZ?x=1": SourceArchive,
+	}
+	for source, want := range tests {
+		if got := SourceKind(source); got != want {
+			t.Errorf("SourceKind(%q) = %q, want %q", source, got, want)
+		}
+	}
+}
+
+func TestInstall_Dir(t *testing.T) {
+	src := t.TempDir()
+	createTestPlugin(t, src, "checkout", PluginTypeProvider)
+	target := t.TempDir()
+
+	m, err := Install(context.Background(), filepath.Join(src, "checkout"), target, InstallOptions{})
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if m.Name != "checkout" || m.Dir != filepath.Join(target, "checkout") {
+		t.Errorf("installed %q at %q", m.Name, m.Dir)
+	}
+
+	record, err := ReadInstallRecord(m.Dir)
+	if err != nil {
+		t.Fatalf("ReadInstallRecord: %v", err)
+	}
+	if record.Source != filepath.Join(src, "checkout") {
+		t.Errorf("record source = %q", record.Source)
+	}
+
+	if _, err := Install(context.Background(), filepath.Join(src, "checkout"), target, InstallOptions{}); !errors.Is(err, ErrAlreadyInstalled) {
+		t.Errorf("second Install error = %v, want ErrAlreadyInstalled", err)
+	}
+
+	// No staging directories are left behind
+	entries, _ := os.ReadDir(target)
+	if len(entries) != 1 {
+		t.Errorf("target has %d entries, want 1", len(entries))
+	}
+}
+
+func TestInstall_Archive(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "jira-1.0.tar.gz")
+	data := writeTarGz(t, archive, map[string]string{
+		"jira-1.0/" + ManifestFileName: buildManifest("jira", PluginTypeProvider),
+		"jira-1.0/plugin":              "#!/bin/sh\n",
+	})
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigPath := filepath.Join(dir, "jira-1.0.tar.gz.sig")
+	if err := os.WriteFile(sigPath, []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, data))), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	key := base64.StdEncoding.EncodeToString(pub)
+
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+
+	tests := []struct {
+		name    string
+		opts    InstallOptions
+		wantErr error
+	}{
+		{name: "no checks", opts: InstallOptions{}},
+		{name: "checksum", opts: InstallOptions{Checksum: "sha256:" + checksum}},
+		{name: "wrong checksum", opts: InstallOptions{Checksum: strings.Repeat("0", 64)}, wantErr: ErrChecksumMismatch},
+		{name: "signature", opts: InstallOptions{Signature: sigPath, PublicKey: key}},
+		{
+			name:    "wrong key",
+			opts:    InstallOptions{Signature: sigPath, PublicKey: base64.StdEncoding.EncodeToString(otherPub)},
+			wantErr: ErrSignatureInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := t.TempDir()
+			m, err := Install(context.Background(), archive, target, tt.opts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Install error = %v, want %v", err, tt.wantErr)
+				}
+				if _, statErr := os.Stat(filepath.Join(target, "jira")); statErr == nil {
+					t.Error("plugin installed despite failed check")
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("Install: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(m.Dir, "plugin")); err != nil {
+				t.Errorf("plugin executable not extracted: %v", err)
+			}
+		})
+	}
+}
+
+func TestInstall_ArchiveEscapes(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "evil.zip")
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("../../evil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte("x"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(archive, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Install(context.Background(), archive, filepath.Join(dir, "plugins"), InstallOptions{})
+	if err == nil || !strings.Contains(err.Error(), "escapes") {
+		t.Errorf("Install error = %v, want an escape error", err)
+	}
+}
+
+func TestInstall_ChecksumNeedsArchive(t *testing.T) {
+	if _, err := Install(context.Background(), t.TempDir(), t.TempDir(), InstallOptions{Checksum: "abc"}); err == nil {
+		t.Error("expected an error for a checksum on a directory source")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	src := t.TempDir()
+	createTestPlugin(t, src, "checkout", PluginTypeProvider)
+	target := t.TempDir()
+
+	m, err := Install(context.Background(), filepath.Join(src, "checkout"), target, InstallOptions{})
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+
+	manifest := strings.Replace(buildManifest("checkout", PluginTypeProvider), `version: "1.0"`, `version: "2.0"`, 1)
+	if err := os.WriteFile(filepath.Join(src, "checkout", ManifestFileName), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := Update(context.Background(), m, InstallOptions{})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Version != "2.0" {
+		t.Errorf("updated version = %q, want 2.0", updated.Version)
+	}
+
+	// Plugins placed by hand cannot be updated
+	createTestPlugin(t, target, "manual", PluginTypeAgent)
+	manual, err := LoadManifest(filepath.Join(target, "manual", ManifestFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Update(context.Background(), manual, InstallOptions{}); !errors.Is(err, ErrNoInstallRecord) {
+		t.Errorf("Update(manual) error = %v, want ErrNoInstallRecord", err)
+	}
+}
+
+func TestUpdate_Verified(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "jira.tar.gz")
+	files := map[string]string{"jira/" + ManifestFileName: buildManifest("jira", PluginTypeProvider)}
+	data := writeTarGz(t, archive, files)
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := base64.StdEncoding.EncodeToString(pub)
+	sigPath := filepath.Join(dir, "jira.tar.gz.sig")
+	sign := func(data []byte) {
+		if err := os.WriteFile(sigPath, ed25519.Sign(priv, data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sign(data)
+
+	target := t.TempDir()
+	signed, err := Install(context.Background(), archive, target, InstallOptions{Signature: sigPath, PublicKey: key})
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	record, err := ReadInstallRecord(signed.Dir)
+	if err != nil {
+		t.Fatalf("ReadInstallRecord: %v", err)
+	}
+	if record.Signature != sigPath || record.PublicKey != key {
+		t.Errorf("record = %+v, want the signature location and public key", record)
+	}
+
+	// A new release signed with the same key updates without flags
+	files["jira/plugin"] = "#!/bin/sh\n"
+	sign(writeTarGz(t, archive, files))
+	if _, err := Update(context.Background(), signed, InstallOptions{}); err != nil {
+		t.Fatalf("Update(signed): %v", err)
+	}
+
+	// A tampered archive is refused with the recorded key
+	writeTarGz(t, archive, map[string]string{"jira/" + ManifestFileName: buildManifest("jira", PluginTypeProvider), "jira/evil": "x"})
+	if _, err := Update(context.Background(), signed, InstallOptions{}); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Update(tampered) error = %v, want ErrSignatureInvalid", err)
+	}
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	if _, err := Update(context.Background(), signed, InstallOptions{PublicKey: base64.StdEncoding.EncodeToString(otherPub)}); err == nil {
+		t.Error("Update with another public key should fail")
+	}
+
+	// A checksummed plugin needs the checksum of the new archive
+	data = writeTarGz(t, archive, files)
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	summed, err := Install(context.Background(), archive, t.TempDir(), InstallOptions{Checksum: checksum})
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if _, err := Update(context.Background(), summed, InstallOptions{}); !errors.Is(err, ErrVerificationRequired) {
+		t.Errorf("Update(checksummed) error = %v, want ErrVerificationRequired", err)
+	}
+	if _, err := Update(context.Background(), summed, InstallOptions{Checksum: checksum}); err != nil {
+		t.Errorf("Update(checksummed) with checksum: %v", err)
+	}
+}
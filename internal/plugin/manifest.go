@@ -94,6 +94,10 @@ type PhaseConfig struct {
 type GuardConfig struct {
 	Name        string `yaml:"name"`
 	Description string `yaml:"description"`
+	From        string `yaml:"from,omitempty"`    // Built-in transition to guard: source state
+	Event       string `yaml:"event,omitempty"`   // Built-in transition to guard: event
+	Order       int    `yaml:"order,omitempty"`   // Lower runs first
+	Timeout     string `yaml:"timeout,omitempty"` // Go duration (default: 30s)
 }
 
 // EffectConfig describes a custom workflow effect.
@@ -101,6 +105,10 @@ type EffectConfig struct {
 	Name        string `yaml:"name"`
 	Description string `yaml:"description"`
 	Critical    bool   `yaml:"critical,omitempty"` // If true, effect failure blocks transition
+	From        string `yaml:"from,omitempty"`     // Built-in transition to augment: source state
+	Event       string `yaml:"event,omitempty"`    // Built-in transition to augment: event
+	Order       int    `yaml:"order,omitempty"`    // Lower runs first
+	Timeout     string `yaml:"timeout,omitempty"`  // Go duration (default: 5m)
 }
 
 // EnvVarSpec documents an expected environment variable.
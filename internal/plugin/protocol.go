@@ -292,6 +292,7 @@ type PhaseInfo struct {
 type GuardInfo struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	TransitionHook
 }
 
 // EffectInfo describes a custom effect from a workflow plugin.
@@ -299,6 +300,22 @@ type EffectInfo struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Critical    bool   `json:"critical,omitempty"` // If true, effect failure blocks transition
+	TransitionHook
+}
+
+// TransitionHook attaches a guard or effect to a built-in transition, such
+// as from "idle" on "finish". Without From and Event, a guard or effect
+// belongs to the plugin phase its name starts with.
+type TransitionHook struct {
+	From    string `json:"from,omitempty"`    // Source state
+	Event   string `json:"event,omitempty"`   // Triggering event
+	Order   int    `json:"order,omitempty"`   // Lower runs first; equal orders run by name
+	Timeout string `json:"timeout,omitempty"` // Go duration, e.g. "10s"
+}
+
+// IsTransitionHook reports whether the hook names a transition.
+func (h TransitionHook) IsTransitionHook() bool {
+	return h.From != "" && h.Event != ""
 }
 
 // EvaluateGuardParams contains parameters for workflow.evaluateGuard.
@@ -3,6 +3,7 @@ package plugin
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -114,7 +115,7 @@ func (a *WorkflowAdapter) ExecuteEffect(ctx context.Context, name string, wu *wo
 func (a *WorkflowAdapter) CreateGuardFunc(name string) workflow.GuardFunc {
 	return func(ctx context.Context, wu *workflow.WorkUnit) bool {
 		// Use a timeout to prevent hanging
-		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		ctx, cancel := context.WithTimeout(ctx, workflow.DefaultGuardTimeout)
 		defer cancel()
 
 		passed, _, err := a.EvaluateGuard(ctx, name, wu)
@@ -131,7 +132,7 @@ func (a *WorkflowAdapter) CreateGuardFunc(name string) workflow.GuardFunc {
 func (a *WorkflowAdapter) CreateEffectFunc(name string, data map[string]any) workflow.EffectFunc {
 	return func(ctx context.Context, wu *workflow.WorkUnit) error {
 		// Use a timeout to prevent hanging
-		ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		ctx, cancel := context.WithTimeout(ctx, workflow.DefaultEffectTimeout)
 		defer cancel()
 
 		return a.ExecuteEffect(ctx, name, wu, data)
@@ -184,7 +185,7 @@ func (a *WorkflowAdapter) BuildPhaseDefinitions() []workflow.PhaseDefinition {
 		for _, g := range a.guards {
 			// Check if this guard is associated with this phase
 			// (convention: guard name starts with phase name)
-			if len(g.Name) > len(p.Name) && g.Name[:len(p.Name)] == p.Name {
+			if !g.IsTransitionHook() && len(g.Name) > len(p.Name) && g.Name[:len(p.Name)] == p.Name {
 				phase.Guards = append(phase.Guards, a.CreateGuardFunc(g.Name))
 			}
 		}
@@ -192,7 +193,7 @@ func (a *WorkflowAdapter) BuildPhaseDefinitions() []workflow.PhaseDefinition {
 		// Build effects for this phase (as CriticalEffects)
 		for _, e := range a.effects {
 			// Check if this effect is associated with this phase
-			if len(e.Name) > len(p.Name) && e.Name[:len(p.Name)] == p.Name {
+			if !e.IsTransitionHook() && len(e.Name) > len(p.Name) && e.Name[:len(p.Name)] == p.Name {
 				phase.Effects = append(phase.Effects, a.CreateCriticalEffect(e, nil))
 			}
 		}
@@ -203,6 +204,79 @@ func (a *WorkflowAdapter) BuildPhaseDefinitions() []workflow.PhaseDefinition {
 	return phases
 }
 
+// RegisterTransitionHooks attaches the guards and effects that name a
+// transition to it. Hooks are registered as "<plugin>/<name>", so their run
+// order does not depend on the order plugins load in.
+func (a *WorkflowAdapter) RegisterTransitionHooks(b *workflow.MachineBuilder) error {
+	var errs []error
+
+	for _, g := range a.guards {
+		if !g.IsTransitionHook() {
+			continue
+		}
+		timeout, err := hookTimeout(g.TransitionHook)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("guard %s: %w", g.Name, err))
+
+			continue
+		}
+		name := g.Name
+		guard := workflow.TransitionGuard{
+			Name:    a.manifest.Name + "/" + name,
+			Order:   g.Order,
+			Timeout: timeout,
+			Check: func(ctx context.Context, wu *workflow.WorkUnit) (bool, string, error) {
+				return a.EvaluateGuard(ctx, name, wu)
+			},
+		}
+		if err := b.RegisterTransitionGuard(workflow.State(g.From), workflow.Event(g.Event), guard); err != nil {
+			errs = append(errs, fmt.Errorf("guard %s: %w", g.Name, err))
+		}
+	}
+
+	for _, e := range a.effects {
+		if !e.IsTransitionHook() {
+			continue
+		}
+		timeout, err := hookTimeout(e.TransitionHook)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("effect %s: %w", e.Name, err))
+
+			continue
+		}
+		name := e.Name
+		effect := workflow.TransitionEffect{
+			CriticalEffect: workflow.CriticalEffect{
+				Name:     a.manifest.Name + "/" + name,
+				Critical: e.Critical,
+				Fn: func(ctx context.Context, wu *workflow.WorkUnit) error {
+					return a.ExecuteEffect(ctx, name, wu, nil)
+				},
+			},
+			Order:   e.Order,
+			Timeout: timeout,
+		}
+		if err := b.RegisterTransitionEffects(workflow.State(e.From), workflow.Event(e.Event), effect); err != nil {
+			errs = append(errs, fmt.Errorf("effect %s: %w", e.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// hookTimeout parses a hook's timeout; zero leaves the workflow default.
+func hookTimeout(h TransitionHook) (time.Duration, error) {
+	if h.Timeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(h.Timeout)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q", h.Timeout)
+	}
+
+	return timeout, nil
+}
+
 // GetEffectInfo returns effect info by name, if found.
 func (a *WorkflowAdapter) GetEffectInfo(name string) (EffectInfo, bool) {
 	for _, e := range a.effects {
@@ -2,6 +2,7 @@ package plugin
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/valksor/go-mehrhof/internal/workflow"
@@ -323,3 +324,37 @@ func TestBuildPhaseDefinitions(t *testing.T) {
 		t.Error("Effect Critical = false, want true")
 	}
 }
+
+func TestRegisterTransitionHooks(t *testing.T) {
+	adapter := NewWorkflowAdapter(&Manifest{Name: "changelog"}, &Process{})
+	adapter.phases = []PhaseInfo{{Name: "release", After: "reviewing"}}
+	adapter.guards = []GuardInfo{
+		{Name: "has-entry", TransitionHook: TransitionHook{From: "idle", Event: "finish", Timeout: "5s"}},
+		// Phase-prefixed but attached to a transition: not a phase guard
+		{Name: "release-notes", TransitionHook: TransitionHook{From: "idle", Event: "finish", Order: -1}},
+	}
+	adapter.effects = []EffectInfo{
+		{Name: "stamp", Critical: true, TransitionHook: TransitionHook{From: "idle", Event: "finish"}},
+	}
+
+	if defs := adapter.BuildPhaseDefinitions(); len(defs) != 1 || len(defs[0].Guards) != 0 {
+		t.Fatalf("transition hooks were attached to the phase: %+v", defs)
+	}
+
+	if err := adapter.RegisterTransitionHooks(workflow.NewMachineBuilder()); err != nil {
+		t.Errorf("RegisterTransitionHooks() error = %v", err)
+	}
+}
+
+func TestRegisterTransitionHooks_Errors(t *testing.T) {
+	adapter := NewWorkflowAdapter(&Manifest{Name: "broken"}, &Process{})
+	adapter.guards = []GuardInfo{
+		{Name: "slow", TransitionHook: TransitionHook{From: "idle", Event: "finish", Timeout: "soon"}},
+		{Name: "nowhere", TransitionHook: TransitionHook{From: "done", Event: "plan"}},
+	}
+
+	err := adapter.RegisterTransitionHooks(workflow.NewMachineBuilder())
+	if err == nil || !strings.Contains(err.Error(), "invalid timeout") || !strings.Contains(err.Error(), "no transition from done") {
+		t.Errorf("RegisterTransitionHooks() error = %v, want timeout and transition errors", err)
+	}
+}
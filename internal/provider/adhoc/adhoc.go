@@ -0,0 +1,244 @@
+// Package adhoc implements the stdin: and clipboard: providers, which start
+// quick tasks from text that was never written to a file.
+package adhoc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/valksor/go-mehrhof/internal/naming"
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/provider/file"
+)
+
+const (
+	// ProviderName is the registered name for this provider.
+	ProviderName = "adhoc"
+
+	// StdinReference is the reference `mehr start -` expands to.
+	StdinReference = "stdin:"
+
+	// snapshotFile is the source file the content is stored in.
+	snapshotFile = "task.md"
+
+	// maxTitleLength bounds titles synthesized from the first sentence.
+	maxTitleLength = 72
+)
+
+var (
+	// ErrEmptyContent is returned when stdin or the clipboard holds no text.
+	ErrEmptyContent = errors.New("no task content")
+
+	// ErrNotRereadable is returned when the content of an earlier start is
+	// requested again: it only exists in the task's source snapshot.
+	ErrNotRereadable = errors.New("ad-hoc task content cannot be read again; edit the task's source snapshot instead")
+)
+
+// Provider reads ad-hoc task content from stdin or the clipboard. The
+// content is read once, on the first Fetch, and reused for the snapshot.
+type Provider struct {
+	stdin     io.Reader
+	clipboard func(ctx context.Context) (string, error)
+
+	mu      sync.Mutex
+	content map[string]string // by source ("stdin", "clipboard")
+}
+
+// Info returns provider metadata.
+func Info() provider.ProviderInfo {
+	return provider.ProviderInfo{
+		Name:        ProviderName,
+		Description: "Ad-hoc task from stdin or the clipboard",
+		Schemes:     []string{"stdin", "clipboard"},
+		Priority:    10,
+		Capabilities: provider.CapabilitySet{
+			provider.CapRead:     true,
+			provider.CapSnapshot: true,
+		},
+	}
+}
+
+// New creates an ad-hoc provider.
+func New(_ context.Context, cfg provider.Config) (any, error) {
+	stdin, ok := cfg.Get("stdin").(io.Reader)
+	if !ok {
+		stdin = os.Stdin
+	}
+
+	return &Provider{
+		stdin:     stdin,
+		clipboard: readClipboard,
+		content:   make(map[string]string),
+	}, nil
+}
+
+// Register adds the ad-hoc provider to the registry.
+func Register(r *provider.Registry) {
+	_ = r.Register(Info(), New)
+}
+
+// Match checks if input is an ad-hoc reference.
+func (p *Provider) Match(input string) bool {
+	return strings.HasPrefix(input, "stdin:") || strings.HasPrefix(input, "clipboard:")
+}
+
+// Parse returns the content source ("stdin" or "clipboard") as the ID.
+func (p *Provider) Parse(input string) (string, error) {
+	source, rest, _ := strings.Cut(input, ":")
+	if (source != "stdin" && source != "clipboard") || rest != "" {
+		return "", fmt.Errorf("invalid ad-hoc reference %q: use stdin: (or -) or clipboard:", input)
+	}
+
+	return source, nil
+}
+
+// Fetch reads the content and creates a WorkUnit, with a title taken from
+// the frontmatter, the first heading or the first sentence.
+func (p *Provider) Fetch(ctx context.Context, id string) (*provider.WorkUnit, error) {
+	content, err := p.read(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := file.ParseMarkdown(content, "")
+	if err != nil {
+		return nil, fmt.Errorf("parse content: %w", err)
+	}
+	title := parsed.Title
+	if title == "" {
+		title = firstSentence(parsed.Body)
+	}
+
+	now := time.Now()
+	wu := &provider.WorkUnit{
+		ID:          id,
+		ExternalID:  id,
+		Provider:    ProviderName,
+		Title:       title,
+		Description: parsed.Body,
+		Status:      provider.StatusOpen,
+		Priority:    provider.PriorityNormal,
+		Labels:      []string{},
+		Metadata:    map[string]any{"source": id},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Source: provider.SourceInfo{
+			Type:      ProviderName,
+			Reference: id + ":",
+			SyncedAt:  now,
+		},
+		Slug: naming.Slugify(title, 50),
+	}
+	if fm := parsed.Frontmatter; fm != nil {
+		if len(fm.Labels) > 0 {
+			wu.Labels = fm.Labels
+		}
+		if fm.Key != "" {
+			wu.ExternalKey = fm.Key
+		}
+		if fm.Type != "" {
+			wu.TaskType = fm.Type
+		}
+		if fm.Slug != "" {
+			wu.Slug = fm.Slug
+		}
+	}
+
+	return wu, nil
+}
+
+// Snapshot stores the content read by Fetch as the task's source file.
+func (p *Provider) Snapshot(_ context.Context, id string) (*provider.Snapshot, error) {
+	p.mu.Lock()
+	content, ok := p.content[id]
+	p.mu.Unlock()
+	if !ok {
+		return nil, ErrNotRereadable
+	}
+
+	return &provider.Snapshot{
+		Type:  ProviderName,
+		Ref:   id + ":",
+		Files: []provider.SnapshotFile{{Path: snapshotFile, Content: content}},
+	}, nil
+}
+
+// read returns the content of a source, reading it on first use.
+func (p *Provider) read(ctx context.Context, source string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if content, ok := p.content[source]; ok {
+		return content, nil
+	}
+
+	var content string
+	switch source {
+	case "stdin":
+		data, err := io.ReadAll(p.stdin)
+		if err != nil {
+			return "", fmt.Errorf("read stdin: %w", err)
+		}
+		content = string(data)
+	case "clipboard":
+		text, err := p.clipboard(ctx)
+		if err != nil {
+			return "", fmt.Errorf("read clipboard: %w", err)
+		}
+		content = text
+	default:
+		return "", fmt.Errorf("unknown ad-hoc source %q", source)
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return "", fmt.Errorf("%w on %s", ErrEmptyContent, source)
+	}
+	p.content[source] = content
+
+	return content, nil
+}
+
+// firstSentence synthesizes a title from the start of the text: its first
+// line up to the end of the first sentence, shortened at a word boundary.
+func firstSentence(text string) string {
+	var line string
+	for l := range strings.SplitSeq(text, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			line = l
+
+			break
+		}
+	}
+	// Drop markdown block markers: headings, list bullets, quotes
+	line = strings.TrimSpace(strings.TrimLeft(line, "#>*-+ "))
+	if line == "" {
+		return "Ad-hoc task"
+	}
+
+	for i, r := range line {
+		if (r == '.' || r == '!' || r == '?') && (i+1 == len(line) || line[i+1] == ' ') {
+			line = line[:i]
+
+			break
+		}
+	}
+	if len(line) > maxTitleLength {
+		cut := strings.LastIndex(line[:maxTitleLength], " ")
+		if cut <= 0 {
+			cut = maxTitleLength
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+		}
+		line = strings.TrimRight(line[:cut], " ,;:") + "..."
+	}
+
+	return line
+}
@@ -0,0 +1,98 @@
+package adhoc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+func newTestProvider(t *testing.T, stdin string) *Provider {
+	t.Helper()
+	v, err := New(context.Background(), provider.NewConfig().Set("stdin", strings.NewReader(stdin)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return v.(*Provider)
+}
+
+func TestFetchAndSnapshot_Stdin(t *testing.T) {
+	content := "# Fix flaky login test\n\nThe test times out on CI.\n"
+	p := newTestProvider(t, content)
+	ctx := context.Background()
+
+	id, err := p.Parse("stdin:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wu, err := p.Fetch(ctx, id)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if wu.Title != "Fix flaky login test" || wu.Description != "The test times out on CI." {
+		t.Errorf("work unit = %q / %q", wu.Title, wu.Description)
+	}
+	if wu.Slug != "fix-flaky-login-test" {
+		t.Errorf("Slug = %q", wu.Slug)
+	}
+
+	// The snapshot reuses the content; stdin is not read again
+	snap, err := p.Snapshot(ctx, wu.ID)
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if len(snap.Files) != 1 || snap.Files[0].Path != "task.md" || snap.Files[0].Content != content {
+		t.Errorf("snapshot = %+v", snap)
+	}
+}
+
+func TestFetch_Clipboard(t *testing.T) {
+	p := newTestProvider(t, "")
+	p.clipboard = func(context.Context) (string, error) {
+		return "Rename the config loader. It reads more than config now.", nil
+	}
+
+	wu, err := p.Fetch(context.Background(), "clipboard")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if wu.Title != "Rename the config loader" {
+		t.Errorf("Title = %q", wu.Title)
+	}
+}
+
+func TestFetch_Errors(t *testing.T) {
+	p := newTestProvider(t, "  \n")
+	if _, err := p.Fetch(context.Background(), "stdin"); !errors.Is(err, ErrEmptyContent) {
+		t.Errorf("Fetch(empty) error = %v, want ErrEmptyContent", err)
+	}
+	if _, err := p.Snapshot(context.Background(), "clipboard"); !errors.Is(err, ErrNotRereadable) {
+		t.Errorf("Snapshot() without Fetch error = %v, want ErrNotRereadable", err)
+	}
+	if _, err := p.Parse("stdin:extra"); err == nil {
+		t.Error("Parse(stdin:extra) succeeded, want error")
+	}
+}
+
+func TestFirstSentence(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Add retries to the uploader. Use backoff.", "Add retries to the uploader"},
+		{"\n\n- bump go to 1.25\n- rerun tests", "bump go to 1.25"},
+		{"## Why?\nBecause.", "Why"},
+		{"Version 1.2 is out", "Version 1.2 is out"},
+		{strings.Repeat("word ", 30), strings.TrimSpace(strings.Repeat("word ", 14)) + "..."},
+		{"", "Ad-hoc task"},
+	}
+
+	for _, tt := range tests {
+		if got := firstSentence(tt.text); got != tt.want {
+			t.Errorf("firstSentence(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
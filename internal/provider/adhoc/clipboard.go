@@ -0,0 +1,57 @@
+package adhoc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrNoClipboard is returned when no clipboard tool is available.
+var ErrNoClipboard = errors.New("no clipboard tool found (install wl-clipboard, xclip or xsel)")
+
+// clipboardCommands lists the commands that print the clipboard, per OS, in
+// order of preference.
+func clipboardCommands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbpaste"}}
+	case "windows":
+		return [][]string{{"powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw"}}
+	default:
+		cmds := [][]string{
+			{"xclip", "-selection", "clipboard", "-o"},
+			{"xsel", "--clipboard", "--output"},
+		}
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			cmds = append([][]string{{"wl-paste", "--no-newline"}}, cmds...)
+		}
+
+		return cmds
+	}
+}
+
+// readClipboard returns the clipboard text using the first available tool.
+func readClipboard(ctx context.Context) (string, error) {
+	for _, args := range clipboardCommands() {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		out, err := exec.CommandContext(ctx, args[0], args[1:]...).Output()
+		if err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+				return "", fmt.Errorf("%s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+			}
+
+			return "", fmt.Errorf("%s: %w", args[0], err)
+		}
+
+		return string(out), nil
+	}
+
+	return "", ErrNoClipboard
+}
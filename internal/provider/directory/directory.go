@@ -3,6 +3,7 @@ package directory
 import (
 	"context"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"slices"
@@ -295,8 +296,83 @@ func (p *Provider) Snapshot(ctx context.Context, id string) (*provider.Snapshot,
 		Files: []provider.SnapshotFile{},
 	}
 
-	// Walk directory and capture all markdown files
-	err := filepath.WalkDir(id, func(path string, d os.DirEntry, err error) error {
+	err := walkSnapshotFiles(id, func(relPath, path string, info fs.FileInfo) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return // Skip files we can't read
+		}
+
+		snapshot.Files = append(snapshot.Files, provider.SnapshotFile{
+			Path:    relPath,
+			Content: string(content),
+			ModTime: info.ModTime(),
+			Hash:    provider.HashContent(string(content)),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// SnapshotChanges captures only the files that changed since a previous
+// snapshot. Files with the same modification time and size are skipped
+// without reading them; others are read and compared by content hash.
+func (p *Provider) SnapshotChanges(ctx context.Context, id string, previous map[string]provider.FileState) (*provider.SnapshotDelta, error) {
+	delta := &provider.SnapshotDelta{
+		Ref:    id,
+		States: make(map[string]provider.FileState),
+	}
+
+	err := walkSnapshotFiles(id, func(relPath, path string, info fs.FileInfo) {
+		prev, known := previous[relPath]
+		if known && prev.Size == info.Size() && prev.ModTime.Equal(info.ModTime()) {
+			delta.States[relPath] = prev
+
+			return
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return // Skip files we can't read
+		}
+
+		state := provider.FileState{
+			ModTime: info.ModTime(),
+			Size:    info.Size(),
+			Hash:    provider.HashContent(string(content)),
+		}
+		delta.States[relPath] = state
+		if known && prev.Hash == state.Hash {
+			return // Touched but not modified
+		}
+
+		delta.Changed = append(delta.Changed, provider.SnapshotFile{
+			Path:    relPath,
+			Content: string(content),
+			ModTime: state.ModTime,
+			Hash:    state.Hash,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for relPath := range previous {
+		if _, ok := delta.States[relPath]; !ok {
+			delta.Removed = append(delta.Removed, relPath)
+		}
+	}
+	slices.Sort(delta.Removed)
+
+	return delta, nil
+}
+
+// walkSnapshotFiles calls fn for every file under dir that is captured in
+// snapshots, with its slash-separated path relative to dir.
+func walkSnapshotFiles(dir string, fn func(relPath, path string, info fs.FileInfo)) error {
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -312,26 +388,23 @@ func (p *Provider) Snapshot(ctx context.Context, id string) (*provider.Snapshot,
 			return nil
 		}
 
-		content, err := os.ReadFile(path)
+		info, err := d.Info()
 		if err != nil {
-			//nolint:nilerr // Skip unreadable files in WalkDir
-			return nil // Skip files we can't read
+			//nolint:nilerr // Skip files removed during the walk
+			return nil
 		}
 
 		// Store relative path
-		relPath, _ := filepath.Rel(id, path)
-		snapshot.Files = append(snapshot.Files, provider.SnapshotFile{
-			Path:    relPath,
-			Content: string(content),
-		})
+		relPath, _ := filepath.Rel(dir, path)
+		fn(filepath.ToSlash(relPath), path, info)
 
 		return nil
 	})
 	if err != nil {
-		return nil, fmt.Errorf("walk directory: %w", err)
+		return fmt.Errorf("walk directory: %w", err)
 	}
 
-	return snapshot, nil
+	return nil
 }
 
 // Register adds directory provider to registry.
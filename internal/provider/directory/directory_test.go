@@ -4,7 +4,9 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/valksor/go-mehrhof/internal/provider"
 )
@@ -461,6 +463,65 @@ func TestSnapshotWithSubdirectories(t *testing.T) {
 	}
 }
 
+func TestSnapshotChanges(t *testing.T) {
+	taskDir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(taskDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	write("keep.md", "# Keep")
+	write("touch.md", "# Touch")
+	write("edit.md", "# Edit")
+	write("gone.md", "# Gone")
+
+	p := &Provider{basePath: taskDir}
+	ctx := context.Background()
+
+	first, err := p.SnapshotChanges(ctx, taskDir, nil)
+	if err != nil {
+		t.Fatalf("SnapshotChanges: %v", err)
+	}
+	if len(first.Changed) != 4 || len(first.States) != 4 {
+		t.Fatalf("initial snapshot changed = %d, states = %d, want 4", len(first.Changed), len(first.States))
+	}
+
+	// Touch one file without changing it, edit another, add and remove files
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(taskDir, "touch.md"), later, later); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+	write("edit.md", "# Edited content")
+	write("new.md", "# New")
+	if err := os.Remove(filepath.Join(taskDir, "gone.md")); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+
+	delta, err := p.SnapshotChanges(ctx, taskDir, first.States)
+	if err != nil {
+		t.Fatalf("SnapshotChanges: %v", err)
+	}
+
+	var changed []string
+	for _, f := range delta.Changed {
+		changed = append(changed, f.Path)
+	}
+	slices.Sort(changed)
+	if !slices.Equal(changed, []string{"edit.md", "new.md"}) {
+		t.Errorf("Changed = %v, want [edit.md new.md]", changed)
+	}
+	if !slices.Equal(delta.Removed, []string{"gone.md"}) {
+		t.Errorf("Removed = %v, want [gone.md]", delta.Removed)
+	}
+	if len(delta.States) != 4 {
+		t.Errorf("States = %d, want 4", len(delta.States))
+	}
+	if !delta.States["touch.md"].ModTime.Equal(later) {
+		t.Error("touched file should record its new modification time")
+	}
+}
+
 func TestSnapshotNotFound(t *testing.T) {
 	p := &Provider{basePath: t.TempDir()}
 	ctx := context.Background()
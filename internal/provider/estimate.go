@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidEstimate is returned for estimates that cannot be parsed.
+var ErrInvalidEstimate = errors.New("invalid estimate")
+
+// Working time units of estimates, as used by Jira and YouTrack.
+const (
+	estimateDay  = 8 * time.Hour
+	estimateWeek = 5 * estimateDay
+)
+
+// ParseEstimate parses an effort estimate such as "90m", "2h30m", "1.5h",
+// "1d 4h" or "1w". Days are 8 working hours and weeks 5 working days.
+func ParseEstimate(s string) (time.Duration, error) {
+	rest := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), " ", "")
+	if rest == "" {
+		return 0, fmt.Errorf("%w: empty", ErrInvalidEstimate)
+	}
+
+	var total time.Duration
+	for rest != "" {
+		i := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if i <= 0 {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidEstimate, s)
+		}
+		n, err := strconv.ParseFloat(rest[:i], 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidEstimate, s)
+		}
+		rest = rest[i:]
+
+		var unit time.Duration
+		switch rest[0] {
+		case 'w':
+			unit = estimateWeek
+		case 'd':
+			unit = estimateDay
+		case 'h':
+			unit = time.Hour
+		case 'm':
+			unit = time.Minute
+		default:
+			return 0, fmt.Errorf("%w: unknown unit in %q", ErrInvalidEstimate, s)
+		}
+		rest = rest[1:]
+		total += time.Duration(n * float64(unit))
+	}
+
+	return total, nil
+}
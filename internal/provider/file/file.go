@@ -129,6 +129,13 @@ func (p *Provider) Fetch(ctx context.Context, id string) (*provider.WorkUnit, er
 		if parsed.Frontmatter.Slug != "" {
 			wu.Slug = parsed.Frontmatter.Slug
 		}
+		if parsed.Frontmatter.Estimate != "" {
+			estimate, err := provider.ParseEstimate(parsed.Frontmatter.Estimate)
+			if err != nil {
+				return nil, err
+			}
+			wu.Estimate = estimate
+		}
 		// Agent configuration from frontmatter
 		if parsed.Frontmatter.Agent != "" || len(parsed.Frontmatter.AgentEnv) > 0 || len(parsed.Frontmatter.AgentArgs) > 0 || len(parsed.Frontmatter.AgentSteps) > 0 {
 			wu.AgentConfig = &provider.AgentConfig{
@@ -2,9 +2,11 @@ package file
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/valksor/go-mehrhof/internal/provider"
 )
@@ -294,6 +296,33 @@ This is the task description.
 	}
 }
 
+func TestFetchWithEstimate(t *testing.T) {
+	tmpDir := t.TempDir()
+	taskFile := filepath.Join(tmpDir, "my-task.md")
+	if err := os.WriteFile(taskFile, []byte("---\nestimate: 1d 2h\n---\n\n# My Task\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	badFile := filepath.Join(tmpDir, "bad.md")
+	if err := os.WriteFile(badFile, []byte("---\nestimate: soon\n---\n\n# Bad\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	p := &Provider{basePath: tmpDir}
+	ctx := context.Background()
+
+	wu, err := p.Fetch(ctx, taskFile)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if wu.Estimate != 10*time.Hour {
+		t.Errorf("Estimate = %v, want 10h", wu.Estimate)
+	}
+
+	if _, err := p.Fetch(ctx, badFile); !errors.Is(err, provider.ErrInvalidEstimate) {
+		t.Errorf("Fetch(bad estimate) error = %v, want ErrInvalidEstimate", err)
+	}
+}
+
 func TestSnapshot(t *testing.T) {
 	tmpDir := t.TempDir()
 	taskFile := filepath.Join(tmpDir, "snapshot.md")
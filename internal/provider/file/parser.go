@@ -22,6 +22,7 @@ type Frontmatter struct {
 	Priority    string   `yaml:"priority"`
 	Labels      []string `yaml:"labels"`
 	Assignees   []string `yaml:"assignees"`
+	Estimate    string   `yaml:"estimate"` // Planned effort (e.g., "2h30m", "1d")
 
 	// Naming overrides for branch/commit customization
 	Key  string `yaml:"key"`  // External key override (e.g., "FEATURE-123")
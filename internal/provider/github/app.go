@@ -0,0 +1,268 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"golang.org/x/oauth2"
+)
+
+// GitHub App error types.
+var (
+	ErrAppNotInstalled            = errors.New("github app is not installed on the repository")
+	ErrAppUnauthorized            = errors.New("github app authentication failed (check app_id and private key)")
+	ErrAppInsufficientPermissions = errors.New("github app installation lacks required permissions")
+	ErrAppInstallationUnknown     = errors.New("github app installation_id required when owner/repo are not configured")
+)
+
+const (
+	// appJWTLifetime is the lifetime of the app JWT (GitHub allows at most 10 minutes).
+	appJWTLifetime = 9 * time.Minute
+
+	// appTokenRefreshLeeway renews installation tokens this long before they expire.
+	appTokenRefreshLeeway = 5 * time.Minute
+)
+
+// AppConfig holds GitHub App credentials.
+type AppConfig struct {
+	AppID          int64
+	PrivateKeyPath string
+	InstallationID int64  // Optional; looked up from owner/repo when zero
+	BaseURL        string // API base URL override (GitHub Enterprise)
+}
+
+// ResolveAppConfig builds the app configuration from config values and environment.
+// Environment variables take priority:
+//   - MEHR_GITHUB_APP_ID
+//   - MEHR_GITHUB_APP_PRIVATE_KEY_PATH
+//   - MEHR_GITHUB_APP_INSTALLATION_ID
+//
+// A private key path starting with "~/" is relative to the home directory.
+// Returns nil when no app is configured.
+func ResolveAppConfig(appID, privateKeyPath, installationID string) (*AppConfig, error) {
+	if v := os.Getenv("MEHR_GITHUB_APP_ID"); v != "" {
+		appID = v
+	}
+	if v := os.Getenv("MEHR_GITHUB_APP_PRIVATE_KEY_PATH"); v != "" {
+		privateKeyPath = v
+	}
+	if v := os.Getenv("MEHR_GITHUB_APP_INSTALLATION_ID"); v != "" {
+		installationID = v
+	}
+
+	if appID == "" && privateKeyPath == "" {
+		return nil, nil //nolint:nilnil // no app configured is not an error
+	}
+	if appID == "" || privateKeyPath == "" {
+		return nil, errors.New("github app requires both app_id and private_key_path")
+	}
+
+	if rest, ok := strings.CutPrefix(privateKeyPath, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			privateKeyPath = filepath.Join(home, rest)
+		}
+	}
+	cfg := &AppConfig{PrivateKeyPath: privateKeyPath}
+
+	id, err := strconv.ParseInt(appID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid github app_id %q: %w", appID, err)
+	}
+	cfg.AppID = id
+
+	if installationID != "" {
+		iid, err := strconv.ParseInt(installationID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid github installation_id %q: %w", installationID, err)
+		}
+		cfg.InstallationID = iid
+	}
+
+	return cfg, nil
+}
+
+// AppTokenSource issues installation access tokens for a GitHub App.
+// Tokens are cached and renewed shortly before they expire.
+// It implements oauth2.TokenSource and is safe for concurrent use.
+type AppTokenSource struct {
+	mu    sync.Mutex
+	cfg   AppConfig
+	key   *rsa.PrivateKey
+	owner string
+	repo  string
+	token *oauth2.Token
+	now   func() time.Time
+}
+
+// NewAppTokenSource loads the app private key and prepares a token source.
+// owner/repo are used to look up the installation when cfg.InstallationID is zero.
+func NewAppTokenSource(cfg AppConfig, owner, repo string) (*AppTokenSource, error) {
+	data, err := os.ReadFile(cfg.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("read github app private key: %w", err)
+	}
+
+	key, err := parseRSAPrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse github app private key: %w", err)
+	}
+
+	if cfg.InstallationID == 0 && (owner == "" || repo == "") {
+		return nil, ErrAppInstallationUnknown
+	}
+
+	return &AppTokenSource{
+		cfg:   cfg,
+		key:   key,
+		owner: owner,
+		repo:  repo,
+		now:   time.Now,
+	}, nil
+}
+
+// Token returns a cached installation token or requests a new one.
+func (s *AppTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && s.now().Add(appTokenRefreshLeeway).Before(s.token.Expiry) {
+		return s.token, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client, err := s.appClient()
+	if err != nil {
+		return nil, err
+	}
+
+	installationID := s.cfg.InstallationID
+	if installationID == 0 {
+		inst, _, err := client.Apps.FindRepositoryInstallation(ctx, s.owner, s.repo)
+		if err != nil {
+			return nil, wrapAppError(err, fmt.Sprintf("find installation for %s/%s", s.owner, s.repo))
+		}
+		installationID = inst.GetID()
+		s.cfg.InstallationID = installationID
+	}
+
+	tok, _, err := client.Apps.CreateInstallationToken(ctx, installationID, nil)
+	if err != nil {
+		return nil, wrapAppError(err, fmt.Sprintf("create installation token for installation %d", installationID))
+	}
+
+	s.token = &oauth2.Token{
+		AccessToken: tok.GetToken(),
+		TokenType:   "token",
+		Expiry:      tok.GetExpiresAt().Time,
+	}
+
+	return s.token, nil
+}
+
+// appClient returns a GitHub client authenticated with the app JWT.
+func (s *AppTokenSource) appClient() (*github.Client, error) {
+	jwt, err := s.signJWT()
+	if err != nil {
+		return nil, err
+	}
+
+	client := github.NewClient(nil).WithAuthToken(jwt)
+	if s.cfg.BaseURL != "" {
+		client, err = client.WithEnterpriseURLs(s.cfg.BaseURL, s.cfg.BaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("set github base url: %w", err)
+		}
+	}
+
+	return client, nil
+}
+
+// signJWT creates the RS256-signed JWT used to authenticate as the app.
+func (s *AppTokenSource) signJWT() (string, error) {
+	now := s.now()
+	header := map[string]string{"alg": "RS256", "typ": "JWT"}
+	claims := map[string]any{
+		"iat": now.Add(-60 * time.Second).Unix(), // allow for clock drift
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(s.cfg.AppID, 10),
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(headerJSON) + "." + enc.EncodeToString(claimsJSON)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("sign github app jwt: %w", err)
+	}
+
+	return signingInput + "." + enc.EncodeToString(sig), nil
+}
+
+// parseRSAPrivateKey decodes a PEM-encoded PKCS#1 or PKCS#8 RSA private key.
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not RSA")
+	}
+
+	return key, nil
+}
+
+// wrapAppError converts GitHub App API errors to descriptive typed errors.
+func wrapAppError(err error, action string) error {
+	var ghErr *github.ErrorResponse
+	if errors.As(err, &ghErr) && ghErr.Response != nil {
+		switch ghErr.Response.StatusCode {
+		case http.StatusUnauthorized:
+			return fmt.Errorf("%s: %w: %w", action, ErrAppUnauthorized, err)
+		case http.StatusNotFound:
+			return fmt.Errorf("%s: %w: %w", action, ErrAppNotInstalled, err)
+		case http.StatusForbidden, http.StatusUnprocessableEntity:
+			return fmt.Errorf("%s: %w: %w", action, ErrAppInsufficientPermissions, err)
+		}
+	}
+
+	return fmt.Errorf("%s: %w", action, wrapAPIError(err))
+}
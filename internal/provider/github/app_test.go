@@ -0,0 +1,218 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// writeTestKey generates an RSA key and writes it as PKCS#1 PEM.
+func writeTestKey(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "app.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+
+	return key, path
+}
+
+// verifyJWT checks the RS256 signature and issuer of an app JWT.
+func verifyJWT(t *testing.T, key *rsa.PrivateKey, authHeader string) {
+	t.Helper()
+
+	jwt := strings.TrimPrefix(authHeader, "Bearer ")
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("malformed jwt: %q", jwt)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("decode signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+		t.Errorf("jwt signature invalid: %v", err)
+	}
+
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatalf("decode claims: %v", err)
+	}
+	if claims["iss"] != "12345" {
+		t.Errorf("jwt iss = %v, want 12345", claims["iss"])
+	}
+}
+
+func TestAppTokenSource(t *testing.T) {
+	key, keyPath := writeTestKey(t)
+
+	var tokenRequests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/acme/app/installation", func(w http.ResponseWriter, r *http.Request) {
+		verifyJWT(t, key, r.Header.Get("Authorization"))
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 777})
+	})
+	mux.HandleFunc("POST /api/v3/app/installations/777/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		verifyJWT(t, key, r.Header.Get("Authorization"))
+		n := tokenRequests.Add(1)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"token":      fmt.Sprintf("ghs_installation_%d", n),
+			"expires_at": time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+		})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ts, err := NewAppTokenSource(AppConfig{AppID: 12345, PrivateKeyPath: keyPath, BaseURL: srv.URL + "/"}, "acme", "app")
+	if err != nil {
+		t.Fatalf("NewAppTokenSource: %v", err)
+	}
+
+	tok, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if tok.AccessToken != "ghs_installation_1" {
+		t.Errorf("AccessToken = %q", tok.AccessToken)
+	}
+
+	// Cached while valid
+	if tok, _ = ts.Token(); tok.AccessToken != "ghs_installation_1" || tokenRequests.Load() != 1 {
+		t.Errorf("expected cached token, got %q after %d requests", tok.AccessToken, tokenRequests.Load())
+	}
+
+	// Renewed when close to expiry
+	ts.now = func() time.Time { return time.Now().Add(58 * time.Minute) }
+	tok, err = ts.Token()
+	if err != nil {
+		t.Fatalf("Token after expiry: %v", err)
+	}
+	if tok.AccessToken != "ghs_installation_2" {
+		t.Errorf("AccessToken after refresh = %q, want ghs_installation_2", tok.AccessToken)
+	}
+}
+
+func TestAppTokenSource_Errors(t *testing.T) {
+	_, keyPath := writeTestKey(t)
+
+	tests := []struct {
+		name       string
+		lookupCode int
+		tokenCode  int
+		wantErr    error
+	}{
+		{name: "not installed", lookupCode: http.StatusNotFound, wantErr: ErrAppNotInstalled},
+		{name: "bad credentials", lookupCode: http.StatusUnauthorized, wantErr: ErrAppUnauthorized},
+		{name: "permissions", lookupCode: http.StatusOK, tokenCode: http.StatusUnprocessableEntity, wantErr: ErrAppInsufficientPermissions},
+		{name: "forbidden", lookupCode: http.StatusOK, tokenCode: http.StatusForbidden, wantErr: ErrAppInsufficientPermissions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /api/v3/repos/acme/app/installation", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.lookupCode)
+				_ = json.NewEncoder(w).Encode(map[string]any{"id": 1, "message": "x"})
+			})
+			mux.HandleFunc("POST /api/v3/app/installations/1/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.tokenCode)
+				_ = json.NewEncoder(w).Encode(map[string]any{"message": "x"})
+			})
+			srv := httptest.NewServer(mux)
+			defer srv.Close()
+
+			ts, err := NewAppTokenSource(AppConfig{AppID: 12345, PrivateKeyPath: keyPath, BaseURL: srv.URL + "/"}, "acme", "app")
+			if err != nil {
+				t.Fatalf("NewAppTokenSource: %v", err)
+			}
+			if _, err := ts.Token(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Token() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewAppTokenSource_Validation(t *testing.T) {
+	_, keyPath := writeTestKey(t)
+
+	if _, err := NewAppTokenSource(AppConfig{AppID: 1, PrivateKeyPath: keyPath}, "", ""); !errors.Is(err, ErrAppInstallationUnknown) {
+		t.Errorf("missing installation: error = %v, want ErrAppInstallationUnknown", err)
+	}
+	if _, err := NewAppTokenSource(AppConfig{AppID: 1, PrivateKeyPath: keyPath, InstallationID: 9}, "", ""); err != nil {
+		t.Errorf("explicit installation: unexpected error %v", err)
+	}
+	if _, err := NewAppTokenSource(AppConfig{AppID: 1, PrivateKeyPath: filepath.Join(t.TempDir(), "missing.pem")}, "o", "r"); err == nil {
+		t.Error("missing key file: expected error")
+	}
+
+	badKey := filepath.Join(t.TempDir(), "bad.pem")
+	_ = os.WriteFile(badKey, []byte("not a key"), 0o600)
+	if _, err := NewAppTokenSource(AppConfig{AppID: 1, PrivateKeyPath: badKey}, "o", "r"); err == nil {
+		t.Error("invalid key: expected error")
+	}
+}
+
+func TestResolveAppConfig(t *testing.T) {
+	cfg, err := ResolveAppConfig("", "", "")
+	if err != nil || cfg != nil {
+		t.Errorf("ResolveAppConfig(empty) = %+v, %v; want nil, nil", cfg, err)
+	}
+
+	if _, err := ResolveAppConfig("123", "", ""); err == nil {
+		t.Error("ResolveAppConfig without key path: expected error")
+	}
+	if _, err := ResolveAppConfig("abc", "/key.pem", ""); err == nil {
+		t.Error("ResolveAppConfig with invalid app id: expected error")
+	}
+
+	cfg, err = ResolveAppConfig("123", "/key.pem", "456")
+	if err != nil {
+		t.Fatalf("ResolveAppConfig: %v", err)
+	}
+	if cfg.AppID != 123 || cfg.PrivateKeyPath != "/key.pem" || cfg.InstallationID != 456 {
+		t.Errorf("ResolveAppConfig = %+v", cfg)
+	}
+
+	t.Setenv("HOME", "/home/dev")
+	cfg, err = ResolveAppConfig("123", "~/.config/mehrhof/app.pem", "")
+	if err != nil {
+		t.Fatalf("ResolveAppConfig with ~: %v", err)
+	}
+	if cfg.PrivateKeyPath != "/home/dev/.config/mehrhof/app.pem" {
+		t.Errorf("PrivateKeyPath = %q, want it under the home directory", cfg.PrivateKeyPath)
+	}
+
+	t.Setenv("MEHR_GITHUB_APP_ID", "999")
+	t.Setenv("MEHR_GITHUB_APP_PRIVATE_KEY_PATH", "/env.pem")
+	cfg, err = ResolveAppConfig("123", "/key.pem", "")
+	if err != nil {
+		t.Fatalf("ResolveAppConfig with env: %v", err)
+	}
+	if cfg.AppID != 999 || cfg.PrivateKeyPath != "/env.pem" {
+		t.Errorf("env should take priority, got %+v", cfg)
+	}
+}
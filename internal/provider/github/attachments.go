@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
 )
@@ -53,17 +54,34 @@ func (p *Provider) DownloadAttachment(ctx context.Context, workUnitID, attachmen
 	}
 
 	// Download the attachment
-	return downloadURL(ctx, targetURL)
+	return p.FetchImage(ctx, targetURL)
+}
+
+// FetchImage downloads an image embedded in an issue. Images hosted by
+// GitHub, such as uploads to private repositories, are fetched with the
+// provider's credentials; other hosts get an anonymous request.
+func (p *Provider) FetchImage(ctx context.Context, imageURL string) (io.ReadCloser, error) {
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse image url: %w", err)
+	}
+
+	client := http.DefaultClient
+	if p.client.http != nil && p.client.ownsHost(u.Hostname()) {
+		client = p.client.http
+	}
+
+	return downloadURL(ctx, client, imageURL)
 }
 
 // downloadURL fetches content from a URL.
-func downloadURL(ctx context.Context, url string) (io.ReadCloser, error) {
+func downloadURL(ctx context.Context, client *http.Client, url string) (io.ReadCloser, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("download: %w", err)
 	}
@@ -386,7 +386,7 @@ func TestDownloadAttachment_MockServerError(t *testing.T) {
 		defer server.Close()
 
 		ctx := context.Background()
-		_, err := downloadURL(ctx, server.URL+"/image.png")
+		_, err := downloadURL(ctx, http.DefaultClient, server.URL+"/image.png")
 
 		if err == nil {
 			t.Error("downloadURL() expected error for 500 response, got nil")
@@ -410,7 +410,7 @@ func TestDownloadAttachment_MockServerSuccess(t *testing.T) {
 		defer server.Close()
 
 		ctx := context.Background()
-		rc, err := downloadURL(ctx, server.URL+"/image.png")
+		rc, err := downloadURL(ctx, http.DefaultClient, server.URL+"/image.png")
 		if err != nil {
 			t.Fatalf("downloadURL() error = %v", err)
 		}
@@ -477,6 +477,41 @@ func TestFetchLinkedIssueContent_EdgeCases(t *testing.T) {
 	})
 }
 
+func TestFetchImage_Credentials(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("png"))
+	}))
+	defer server.Close()
+
+	client := NewClient(context.Background(), "secret", "owner", "repo")
+	if err := client.SetBaseURL(server.URL + "/"); err != nil {
+		t.Fatal(err)
+	}
+	p := &Provider{client: client}
+
+	rc, err := p.FetchImage(context.Background(), server.URL+"/user-attachments/assets/1")
+	if err != nil {
+		t.Fatalf("FetchImage() error = %v", err)
+	}
+	_ = rc.Close()
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want the provider token", auth)
+	}
+
+	for host, want := range map[string]bool{
+		"github.com": true,
+		"private-user-images.githubusercontent.com": true,
+		"example.com":            false,
+		"github.com.example.com": false,
+	} {
+		if got := client.ownsHost(host); got != want {
+			t.Errorf("ownsHost(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
+
 // ──────────────────────────────────────────────────────────────────────────────
 // Context cancellation tests
 // ──────────────────────────────────────────────────────────────────────────────
@@ -485,7 +520,7 @@ func TestDownloadAttachment_ContextCanceled(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately
 
-	_, err := downloadURL(ctx, "http://example.com/image.png")
+	_, err := downloadURL(ctx, http.DefaultClient, "http://example.com/image.png")
 	if err == nil {
 		t.Error("downloadURL() expected error for canceled context, got nil")
 	}
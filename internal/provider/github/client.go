@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"golang.org/x/oauth2"
 
 	"github.com/valksor/go-mehrhof/internal/cache"
+	"github.com/valksor/go-mehrhof/internal/provider/httpclient"
 	"github.com/valksor/go-mehrhof/internal/provider/token"
 )
 
@@ -23,6 +25,7 @@ func ptr[T any](v T) *T {
 // Client wraps the GitHub API client.
 type Client struct {
 	gh    *github.Client
+	http  *http.Client // authenticated, for downloads outside the API
 	cache *cache.Cache
 	owner string
 	repo  string
@@ -36,16 +39,48 @@ func NewClient(ctx context.Context, token, owner, repo string) *Client {
 // NewClientWithCache creates a new GitHub API client with an optional cache.
 func NewClientWithCache(ctx context.Context, token, owner, repo string, c *cache.Cache) *Client {
 	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+
+	return NewClientWithTokenSource(ctx, ts, owner, repo, c)
+}
+
+// NewClientWithTokenSource creates a GitHub API client that obtains tokens from ts
+// (e.g., an AppTokenSource issuing short-lived installation tokens).
+func NewClientWithTokenSource(ctx context.Context, ts oauth2.TokenSource, owner, repo string, c *cache.Cache) *Client {
 	tc := oauth2.NewClient(ctx, ts)
+	// Pace requests by the quota GitHub reports, shared with other clients
+	tc.Transport = httpclient.SharedRateLimiter().Transport(tc.Transport)
 
 	return &Client{
 		gh:    github.NewClient(tc),
+		http:  tc,
 		owner: owner,
 		repo:  repo,
 		cache: c,
 	}
 }
 
+// SetBaseURL points the client at a GitHub Enterprise Server (or compatible) API.
+func (c *Client) SetBaseURL(baseURL string) error {
+	gh, err := c.gh.WithEnterpriseURLs(baseURL, baseURL)
+	if err != nil {
+		return fmt.Errorf("set github base url: %w", err)
+	}
+	c.gh = gh
+
+	return nil
+}
+
+// ownsHost reports whether a URL host belongs to the GitHub instance the
+// client talks to, and may be sent its credentials.
+func (c *Client) ownsHost(host string) bool {
+	host = strings.ToLower(host)
+	if host == c.gh.BaseURL.Hostname() {
+		return true
+	}
+
+	return host == "github.com" || host == "api.github.com" || strings.HasSuffix(host, ".githubusercontent.com")
+}
+
 // SetCache sets or updates the cache for this client.
 func (c *Client) SetCache(cache *cache.Cache) {
 	c.cache = cache
@@ -210,6 +245,16 @@ func (c *Client) GetDefaultBranch(ctx context.Context) (string, error) {
 	return branch, nil
 }
 
+// GetBranch returns a branch of the repository, with its protection summary.
+func (c *Client) GetBranch(ctx context.Context, branch string) (*github.Branch, error) {
+	b, _, err := c.gh.Repositories.GetBranch(ctx, c.owner, c.repo, branch, 1)
+	if err != nil {
+		return nil, wrapAPIError(err)
+	}
+
+	return b, nil
+}
+
 // DownloadFile downloads a file from the repository.
 func (c *Client) DownloadFile(ctx context.Context, path, ref string) ([]byte, error) {
 	opts := &github.RepositoryContentGetOptions{Ref: ref}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v67/github"
 	providererrors "github.com/valksor/go-mehrhof/internal/provider/errors"
@@ -26,6 +27,20 @@ func wrapAPIError(err error) error {
 		return nil
 	}
 
+	var rateErr *github.RateLimitError
+	if errors.As(err, &rateErr) {
+		return providererrors.RateLimitedError("github", "quota resets at "+rateErr.Rate.Reset.Local().Format(time.Kitchen))
+	}
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		detail := "secondary rate limit"
+		if abuseErr.RetryAfter != nil {
+			detail += ", retry after " + abuseErr.RetryAfter.String()
+		}
+
+		return providererrors.RateLimitedError("github", detail)
+	}
+
 	var ghErr *github.ErrorResponse
 	if errors.As(err, &ghErr) {
 		switch ghErr.Response.StatusCode {
@@ -5,6 +5,7 @@ import (
 	"net"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v67/github"
 	providererrors "github.com/valksor/go-mehrhof/internal/provider/errors"
@@ -44,6 +45,17 @@ func TestWrapAPIError_500ServerError(t *testing.T) {
 	}
 }
 
+func TestWrapAPIError_RateLimit(t *testing.T) {
+	for _, err := range []error{
+		&github.RateLimitError{Rate: github.Rate{Reset: github.Timestamp{Time: time.Now().Add(time.Hour)}}},
+		&github.AbuseRateLimitError{RetryAfter: ptr(time.Minute)},
+	} {
+		if got := wrapAPIError(err); !errors.Is(got, providererrors.ErrRateLimited) {
+			t.Errorf("wrapAPIError(%T) = %v, want wrapped %v", err, got, providererrors.ErrRateLimited)
+		}
+	}
+}
+
 func TestErrorVariables(t *testing.T) {
 	// Test that GitHub-specific error variables are properly defined
 	tests := []struct {
@@ -66,6 +66,8 @@ func Info() provider.ProviderInfo {
 			provider.CapDownloadAttachment: true,
 			provider.CapSnapshot:           true,
 			provider.CapFetchSubtasks:      true,
+			provider.CapUpdateTaskList:     true,
+			provider.CapBranchProtection:   true,
 		},
 	}
 }
@@ -81,12 +83,21 @@ func New(ctx context.Context, cfg provider.Config) (any, error) {
 	targetBranch := cfg.GetString("target_branch")
 	draftPR := cfg.GetBool("draft_pr")
 
-	// Resolve token
-	resolvedToken, err := ResolveToken(token)
+	// GitHub App credentials take priority over personal access tokens
+	appConfig, err := ResolveAppConfig(cfg.GetString("app.app_id"), cfg.GetString("app.private_key_path"), cfg.GetString("app.installation_id"))
 	if err != nil {
 		return nil, err
 	}
 
+	// Resolve token
+	var resolvedToken string
+	if appConfig == nil {
+		resolvedToken, err = ResolveToken(token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Set defaults
 	if branchPattern == "" {
 		branchPattern = "issue/{key}-{slug}"
@@ -122,8 +133,27 @@ func New(ctx context.Context, cfg provider.Config) (any, error) {
 		providerCache.Disable()
 	}
 
+	baseURL := cfg.GetString("base_url")
+
+	var client *Client
+	if appConfig != nil {
+		appConfig.BaseURL = baseURL
+		ts, err := NewAppTokenSource(*appConfig, owner, repo)
+		if err != nil {
+			return nil, err
+		}
+		client = NewClientWithTokenSource(ctx, ts, owner, repo, providerCache)
+	} else {
+		client = NewClientWithCache(ctx, resolvedToken, owner, repo, providerCache)
+	}
+	if baseURL != "" {
+		if err := client.SetBaseURL(baseURL); err != nil {
+			return nil, err
+		}
+	}
+
 	return &Provider{
-		client: NewClientWithCache(ctx, resolvedToken, owner, repo, providerCache),
+		client: client,
 		owner:  owner,
 		repo:   repo,
 		config: config,
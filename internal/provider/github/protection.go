@@ -0,0 +1,47 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+// BranchProtection implements the provider.ProtectionReader interface.
+// The repository is detected from remoteURL when none is configured.
+func (p *Provider) BranchProtection(ctx context.Context, remoteURL, branch string) (*provider.BranchProtection, error) {
+	owner, repo := p.owner, p.repo
+	if owner == "" || repo == "" {
+		var err error
+		if owner, repo, err = DetectRepository(remoteURL); err != nil {
+			return nil, err
+		}
+	}
+	p.client.SetOwnerRepo(owner, repo)
+
+	b, err := p.client.GetBranch(ctx, branch)
+	if err != nil {
+		return nil, fmt.Errorf("get branch %s: %w", branch, err)
+	}
+
+	bp := &provider.BranchProtection{Branch: branch, Protected: b.GetProtected()}
+	// The protection details are only included for tokens with admin access
+	rules := b.GetProtection()
+	if rules == nil {
+		return bp, nil
+	}
+	if reviews := rules.GetRequiredPullRequestReviews(); reviews != nil {
+		bp.RequiredReviews = reviews.RequiredApprovingReviewCount
+	}
+	if checks := rules.GetRequiredStatusChecks(); checks != nil {
+		if checks.Checks != nil {
+			for _, check := range *checks.Checks {
+				bp.RequiredChecks = append(bp.RequiredChecks, check.Context)
+			}
+		} else if checks.Contexts != nil {
+			bp.RequiredChecks = append(bp.RequiredChecks, *checks.Contexts...)
+		}
+	}
+
+	return bp, nil
+}
@@ -0,0 +1,75 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestBranchProtection(t *testing.T) {
+	tests := []struct {
+		name        string
+		owner       string
+		body        string
+		wantPath    string
+		wantProt    bool
+		wantReviews int
+		wantChecks  []string
+	}{
+		{
+			name:     "unprotected branch",
+			owner:    "owner",
+			body:     `{"name": "main", "protected": false}`,
+			wantPath: "/repos/owner/repo/branches/main",
+		},
+		{
+			name:  "protected branch with rules",
+			owner: "owner",
+			body: `{"name": "main", "protected": true, "protection": {
+				"required_status_checks": {"strict": true, "checks": [{"context": "ci/build"}, {"context": "lint"}]},
+				"required_pull_request_reviews": {"required_approving_review_count": 2}
+			}}`,
+			wantPath:    "/repos/owner/repo/branches/main",
+			wantProt:    true,
+			wantReviews: 2,
+			wantChecks:  []string{"ci/build", "lint"},
+		},
+		{
+			name:     "repository detected from remote",
+			body:     `{"name": "main", "protected": true}`,
+			wantPath: "/repos/acme/widgets/branches/main",
+			wantProt: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.body))
+			})
+
+			client, cleanup := setupMockStatusClient(t, handler)
+			defer cleanup()
+
+			p := &Provider{client: client, owner: tt.owner, repo: "repo", config: &Config{}}
+			if tt.owner == "" {
+				p.repo = ""
+			}
+
+			bp, err := p.BranchProtection(context.Background(), "git@github.com:acme/widgets.git", "main")
+			if err != nil {
+				t.Fatalf("BranchProtection error = %v", err)
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("request path = %q, want %q", gotPath, tt.wantPath)
+			}
+			if bp.Protected != tt.wantProt || bp.RequiredReviews != tt.wantReviews || !slices.Equal(bp.RequiredChecks, tt.wantChecks) {
+				t.Errorf("BranchProtection = %+v, want protected=%v reviews=%d checks=%v", bp, tt.wantProt, tt.wantReviews, tt.wantChecks)
+			}
+		})
+	}
+}
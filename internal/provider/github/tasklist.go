@@ -0,0 +1,60 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/google/go-github/v67/github"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+// SetTaskListItem implements the provider.TaskListUpdater interface.
+// It checks or unchecks a task list item in the GitHub issue body.
+func (p *Provider) SetTaskListItem(ctx context.Context, workUnitID, text string, checked bool) error {
+	ref, err := ParseReference(workUnitID)
+	if err != nil {
+		return fmt.Errorf("parse reference: %w", err)
+	}
+
+	owner := ref.Owner
+	repo := ref.Repo
+	if owner == "" {
+		owner = p.owner
+	}
+	if repo == "" {
+		repo = p.repo
+	}
+	if owner == "" || repo == "" {
+		return ErrRepoNotConfigured
+	}
+
+	p.client.SetOwnerRepo(owner, repo)
+
+	// Edit the latest body, not a cached one, so concurrent edits survive
+	p.client.forgetIssue(ref.IssueNumber)
+	issue, err := p.client.GetIssue(ctx, ref.IssueNumber)
+	if err != nil {
+		return fmt.Errorf("get issue: %w", err)
+	}
+
+	body, changed, err := provider.SetTaskListItem(issue.GetBody(), text, checked)
+	if err != nil || !changed {
+		return err
+	}
+
+	if _, _, err := p.client.EditIssue(ctx, ref.IssueNumber, &github.IssueRequest{Body: &body}); err != nil {
+		return wrapAPIError(err)
+	}
+	p.client.forgetIssue(ref.IssueNumber)
+
+	return nil
+}
+
+// forgetIssue drops a cached issue.
+func (c *Client) forgetIssue(number int) {
+	if c.cache != nil {
+		c.cache.Delete(c.CacheKey("issue", strconv.Itoa(number)))
+	}
+}
@@ -0,0 +1,49 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+func TestSetTaskListItem(t *testing.T) {
+	var patched []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPatch {
+			data, _ := io.ReadAll(r.Body)
+			var req struct {
+				Body string `json:"body"`
+			}
+			_ = json.Unmarshal(data, &req)
+			patched = append(patched, req.Body)
+		}
+		_, _ = w.Write([]byte(`{"number": 123, "body": "Tasks:\n- [ ] Add login form\n- [x] Write docs"}`))
+	})
+
+	client, cleanup := setupMockStatusClient(t, handler)
+	defer cleanup()
+	p := &Provider{client: client, owner: "owner", repo: "repo", config: &Config{}}
+	ctx := context.Background()
+
+	if err := p.SetTaskListItem(ctx, "#123", "Add login form", true); err != nil {
+		t.Fatalf("SetTaskListItem() error = %v", err)
+	}
+	if len(patched) != 1 || patched[0] != "Tasks:\n- [x] Add login form\n- [x] Write docs" {
+		t.Errorf("patched bodies = %q", patched)
+	}
+
+	// Already in the wanted state: no edit
+	if err := p.SetTaskListItem(ctx, "#123", "Write docs", true); err != nil || len(patched) != 1 {
+		t.Errorf("unchanged item: error = %v, edits = %d", err, len(patched))
+	}
+
+	if err := p.SetTaskListItem(ctx, "#123", "Unknown", true); !errors.Is(err, provider.ErrTaskListItemNotFound) {
+		t.Errorf("unknown item error = %v, want ErrTaskListItemNotFound", err)
+	}
+}
@@ -0,0 +1,88 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/provider/httpclient"
+)
+
+// uploadPattern matches the path of a file uploaded to an issue:
+// [/<project>|/-/project/<id>]/uploads/<secret>/<filename>.
+var uploadPattern = regexp.MustCompile(`^(.*)/uploads/([0-9a-f]{32})/([^/]+)$`)
+
+// FetchImage downloads an image embedded in an issue. Uploads to the GitLab
+// instance, which issue descriptions reference by a path relative to the
+// project, are fetched through the project uploads API with the provider's
+// token; other hosts get an anonymous request.
+func (p *Provider) FetchImage(ctx context.Context, imageURL string) (io.ReadCloser, error) {
+	host := p.client.Host()
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	base, err := url.Parse(strings.TrimSuffix(host, "/") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("parse gitlab host: %w", err)
+	}
+	ref, err := url.Parse(imageURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse image url: %w", err)
+	}
+	target := base.ResolveReference(ref)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if target.Host == base.Host {
+		if apiURL := p.uploadAPIURL(base, target.Path); apiURL != "" {
+			req, err = http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+			if err != nil {
+				return nil, fmt.Errorf("create request: %w", err)
+			}
+		}
+		if p.config != nil && p.config.Token != "" {
+			req.Header.Set("PRIVATE-TOKEN", p.config.Token)
+		}
+	}
+
+	resp, err := httpclient.NewHTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+
+		return nil, fmt.Errorf("download failed: status %d", resp.StatusCode)
+	}
+
+	return resp.Body, nil
+}
+
+// uploadAPIURL maps the path of an issue upload to the API endpoint serving
+// it, or returns "" for other paths.
+func (p *Provider) uploadAPIURL(base *url.URL, path string) string {
+	m := uploadPattern.FindStringSubmatch(path)
+	if m == nil {
+		return ""
+	}
+
+	project := strings.Trim(m[1], "/")
+	if id, ok := strings.CutPrefix(project, "-/project/"); ok {
+		project = id
+	}
+	if project == "" {
+		project = p.client.ProjectPath()
+	}
+	if project == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%sapi/v4/projects/%s/uploads/%s/%s",
+		base.String(), url.PathEscape(project), m[2], url.PathEscape(m[3]))
+}
@@ -0,0 +1,55 @@
+package gitlab
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchImage(t *testing.T) {
+	var gotPath, gotToken string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		gotToken = r.Header.Get("PRIVATE-TOKEN")
+		_, _ = w.Write([]byte("png"))
+	}))
+	defer server.Close()
+
+	p := &Provider{
+		client: NewClient("secret", server.URL, "group/project", 0),
+		config: &Config{Token: "secret"},
+	}
+	const secret = "0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name     string
+		imageURL string
+		wantPath string
+	}{
+		{"relative upload", "/uploads/" + secret + "/shot.png", "/api/v4/projects/group%2Fproject/uploads/" + secret + "/shot.png"},
+		{"project upload", server.URL + "/other/repo/uploads/" + secret + "/a.png", "/api/v4/projects/other%2Frepo/uploads/" + secret + "/a.png"},
+		{"project id upload", server.URL + "/-/project/42/uploads/" + secret + "/a.png", "/api/v4/projects/42/uploads/" + secret + "/a.png"},
+		{"other path", server.URL + "/static/logo.png", "/static/logo.png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc, err := p.FetchImage(context.Background(), tt.imageURL)
+			if err != nil {
+				t.Fatalf("FetchImage() error = %v", err)
+			}
+			data, _ := io.ReadAll(rc)
+			_ = rc.Close()
+			if string(data) != "png" {
+				t.Errorf("data = %q", data)
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("path = %q, want %q", gotPath, tt.wantPath)
+			}
+			if gotToken != "secret" {
+				t.Errorf("PRIVATE-TOKEN = %q, want the provider token", gotToken)
+			}
+		})
+	}
+}
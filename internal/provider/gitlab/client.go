@@ -3,10 +3,14 @@ package gitlab
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 
 	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"github.com/valksor/go-mehrhof/internal/provider/httpclient"
+	"github.com/valksor/go-mehrhof/internal/secrets"
 )
 
 // ptr is a helper to create a pointer to a value.
@@ -24,7 +28,10 @@ type Client struct {
 
 // NewClient creates a new GitLab API client.
 func NewClient(token, host, projectPath string, projectID int64) *Client {
-	var options []gitlab.ClientOptionFunc
+	// Pace requests by the quota GitLab reports, shared with other clients
+	options := []gitlab.ClientOptionFunc{
+		gitlab.WithHTTPClient(&http.Client{Transport: httpclient.SharedRateLimiter().Transport(nil)}),
+	}
 
 	// For self-hosted GitLab, set the base URL
 	if host != "" && host != "https://gitlab.com" && host != "gitlab.com" {
@@ -45,12 +52,17 @@ func NewClient(token, host, projectPath string, projectID int64) *Client {
 	}
 }
 
+// credentialStore holds OAuth credentials obtained via 'mehr auth gitlab'.
+// Tests replace it with a store in a temporary directory.
+var credentialStore = secrets.NewStore("")
+
 // ResolveToken finds the GitLab token from multiple sources
 // Priority order:
 //  1. MEHR_GITLAB_TOKEN env var
 //  2. GITLAB_TOKEN env var
 //  3. configToken (from config.yaml)
-func ResolveToken(configToken string) (string, error) {
+//  4. OAuth credential for host from the secret store (refreshed if expired)
+func ResolveToken(ctx context.Context, configToken, host string) (string, error) {
 	// 1. Check MEHR_GITLAB_TOKEN
 	if token := os.Getenv("MEHR_GITLAB_TOKEN"); token != "" {
 		return token, nil
@@ -66,7 +78,8 @@ func ResolveToken(configToken string) (string, error) {
 		return configToken, nil
 	}
 
-	return "", ErrNoToken
+	// 4. Check stored OAuth credential
+	return storedToken(ctx, credentialStore, host)
 }
 
 // getProjectID retrieves the numeric project ID from the project path.
@@ -338,6 +351,21 @@ func (c *Client) CreateMergeRequest(ctx context.Context, title, description, sou
 	return mr, nil
 }
 
+// GetBranch returns a branch of the project.
+func (c *Client) GetBranch(ctx context.Context, branch string) (*gitlab.Branch, error) {
+	pid, err := c.getProjectID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	b, _, err := c.gl.Branches.GetBranch(pid, branch, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, wrapAPIError(err)
+	}
+
+	return b, nil
+}
+
 // GetDefaultBranch returns the project's default branch.
 func (c *Client) GetDefaultBranch(ctx context.Context) (string, error) {
 	pid, err := c.getProjectID(ctx)
@@ -55,6 +55,9 @@ func Info() provider.ProviderInfo {
 			provider.CapSnapshot:           true,
 			provider.CapCreatePR:           true, // MR creation
 			provider.CapFetchSubtasks:      true,
+			provider.CapUpdateTaskList:     true,
+			provider.CapBranchProtection:   true,
+			provider.CapLogTime:            true,
 		},
 	}
 }
@@ -66,12 +69,6 @@ func New(ctx context.Context, cfg provider.Config) (any, error) {
 	host := cfg.GetString("host")
 	projectPath := cfg.GetString("project_path")
 
-	// Resolve token
-	resolvedToken, err := ResolveToken(token)
-	if err != nil {
-		return nil, err
-	}
-
 	// Set defaults
 	if host == "" {
 		host = "https://gitlab.com"
@@ -79,6 +76,12 @@ func New(ctx context.Context, cfg provider.Config) (any, error) {
 	// Strip trailing slash from host
 	host = strings.TrimSuffix(host, "/")
 
+	// Resolve token
+	resolvedToken, err := ResolveToken(ctx, token, host)
+	if err != nil {
+		return nil, err
+	}
+
 	// Set defaults for branch/commit patterns
 	branchPattern := cfg.GetString("branch_pattern")
 	if branchPattern == "" {
@@ -219,6 +222,9 @@ func (p *Provider) Fetch(ctx context.Context, id string) (*provider.WorkUnit, er
 			"host":           p.client.Host(),
 		},
 	}
+	if issue.TimeStats != nil {
+		wu.Estimate = time.Duration(issue.TimeStats.TimeEstimate) * time.Second
+	}
 
 	// Fetch notes (comments) if available
 	notes, err := p.client.GetIssueNotes(ctx, ref.IssueIID)
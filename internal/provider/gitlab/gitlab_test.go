@@ -3,6 +3,7 @@ package gitlab
 import (
 	"context"
 	"errors"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -10,6 +11,7 @@ import (
 	gitlab "gitlab.com/gitlab-org/api/client-go"
 
 	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/secrets"
 )
 
 func TestParseReference(t *testing.T) {
@@ -923,7 +925,11 @@ func TestResolveToken(t *testing.T) {
 				t.Setenv("GITLAB_TOKEN", tt.setGitlabEnv)
 			}
 
-			got, err := ResolveToken(tt.configToken)
+			oldStore := credentialStore
+			credentialStore = secrets.NewStore(filepath.Join(t.TempDir(), "credentials.json"))
+			t.Cleanup(func() { credentialStore = oldStore })
+
+			got, err := ResolveToken(context.Background(), tt.configToken, "https://gitlab.com")
 
 			if tt.wantErr {
 				if err == nil {
@@ -1619,6 +1625,34 @@ func TestFormatNotesMarkdown(t *testing.T) {
 	}
 }
 
+func TestSpendNote(t *testing.T) {
+	started := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		entry provider.TimeEntry
+		want  string
+	}{
+		{
+			name:  "hours and comment",
+			entry: provider.TimeEntry{Duration: 65*time.Minute + 20*time.Second, Started: started, Comment: "implementing 1h5m"},
+			want:  "/spend 1h5m 2026-01-05\n\nimplementing 1h5m",
+		},
+		{
+			name:  "under a minute",
+			entry: provider.TimeEntry{Duration: 10 * time.Second},
+			want:  "/spend 1m",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := spendNote(tt.entry); got != tt.want {
+				t.Errorf("spendNote() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRegister(t *testing.T) {
 	registry := provider.NewRegistry()
 	Register(registry)
@@ -0,0 +1,155 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/oauth2"
+
+	"github.com/valksor/go-mehrhof/internal/secrets"
+)
+
+// DefaultOAuthScopes are requested during the device authorization flow.
+var DefaultOAuthScopes = []string{"api"}
+
+// ErrNoClientID is returned when the device flow is started without an OAuth application ID.
+var ErrNoClientID = errors.New("gitlab oauth client id not configured")
+
+// oauthConfig builds the OAuth2 configuration for a GitLab host.
+func oauthConfig(host, clientID string, scopes []string) *oauth2.Config {
+	host = strings.TrimSuffix(host, "/")
+
+	return &oauth2.Config{
+		ClientID: clientID,
+		Scopes:   scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:       host + "/oauth/authorize",
+			TokenURL:      host + "/oauth/token",
+			DeviceAuthURL: host + "/oauth/authorize_device",
+			AuthStyle:     oauth2.AuthStyleInParams,
+		},
+	}
+}
+
+// ResolveClientID finds the OAuth application ID used for the device flow.
+// Priority order:
+//  1. MEHR_GITLAB_CLIENT_ID env var
+//  2. configClientID (from config.yaml)
+func ResolveClientID(configClientID string) string {
+	if id := os.Getenv("MEHR_GITLAB_CLIENT_ID"); id != "" {
+		return id
+	}
+
+	return configClientID
+}
+
+// DeviceFlow runs the OAuth 2.0 device authorization grant against a GitLab host.
+type DeviceFlow struct {
+	config *oauth2.Config
+	host   string
+}
+
+// NewDeviceFlow creates a device flow for the given host and OAuth application.
+func NewDeviceFlow(host, clientID string) (*DeviceFlow, error) {
+	if clientID == "" {
+		return nil, ErrNoClientID
+	}
+	if host == "" {
+		host = "https://gitlab.com"
+	}
+	host = strings.TrimSuffix(host, "/")
+
+	return &DeviceFlow{
+		config: oauthConfig(host, clientID, DefaultOAuthScopes),
+		host:   host,
+	}, nil
+}
+
+// Start requests a device and user code from GitLab.
+// The caller should show VerificationURI and UserCode to the user, then call Wait.
+func (f *DeviceFlow) Start(ctx context.Context) (*oauth2.DeviceAuthResponse, error) {
+	resp, err := f.config.DeviceAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("start device authorization: %w", err)
+	}
+
+	return resp, nil
+}
+
+// Wait polls GitLab until the user approves the request, then returns the credential.
+func (f *DeviceFlow) Wait(ctx context.Context, da *oauth2.DeviceAuthResponse) (*secrets.Credential, error) {
+	tok, err := f.config.DeviceAccessToken(ctx, da)
+	if err != nil {
+		return nil, fmt.Errorf("device authorization: %w", err)
+	}
+
+	return credentialFromToken(tok, f.host, f.config.ClientID, f.config.Scopes), nil
+}
+
+// credentialFromToken converts an OAuth2 token to a stored credential.
+func credentialFromToken(tok *oauth2.Token, host, clientID string, scopes []string) *secrets.Credential {
+	return &secrets.Credential{
+		AccessToken:  tok.AccessToken,
+		RefreshToken: tok.RefreshToken,
+		TokenType:    tok.TokenType,
+		ExpiresAt:    tok.Expiry,
+		Host:         host,
+		ClientID:     clientID,
+		Scopes:       scopes,
+	}
+}
+
+// CredentialKey returns the secret store key for a GitLab host.
+func CredentialKey(host string) string {
+	if host == "" {
+		host = "https://gitlab.com"
+	}
+
+	return secrets.Key(ProviderName, strings.TrimSuffix(host, "/"))
+}
+
+// storedToken returns a valid access token from the secret store, refreshing
+// and persisting it when expired. Returns ErrNoToken if nothing is stored.
+func storedToken(ctx context.Context, store *secrets.Store, host string) (string, error) {
+	key := CredentialKey(host)
+
+	cred, err := store.Get(key)
+	if err != nil {
+		if errors.Is(err, secrets.ErrNotFound) {
+			return "", ErrNoToken
+		}
+
+		return "", err
+	}
+
+	if !cred.Expired() {
+		return cred.AccessToken, nil
+	}
+	if cred.RefreshToken == "" || cred.ClientID == "" {
+		return "", fmt.Errorf("%w: stored credential expired, run 'mehr auth gitlab'", ErrUnauthorized)
+	}
+
+	cfg := oauthConfig(cred.Host, cred.ClientID, cred.Scopes)
+	tok, err := cfg.TokenSource(ctx, &oauth2.Token{
+		AccessToken:  cred.AccessToken,
+		RefreshToken: cred.RefreshToken,
+		TokenType:    cred.TokenType,
+		Expiry:       cred.ExpiresAt,
+	}).Token()
+	if err != nil {
+		return "", fmt.Errorf("%w: refresh token: %w", ErrUnauthorized, err)
+	}
+
+	refreshed := credentialFromToken(tok, cred.Host, cred.ClientID, cred.Scopes)
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = cred.RefreshToken
+	}
+	if err := store.Set(key, refreshed); err != nil {
+		return "", fmt.Errorf("save refreshed token: %w", err)
+	}
+
+	return refreshed.AccessToken, nil
+}
@@ -0,0 +1,169 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/secrets"
+)
+
+func TestNewDeviceFlow_RequiresClientID(t *testing.T) {
+	if _, err := NewDeviceFlow("https://gitlab.example.com", ""); !errors.Is(err, ErrNoClientID) {
+		t.Errorf("NewDeviceFlow() error = %v, want ErrNoClientID", err)
+	}
+}
+
+func TestDeviceFlow(t *testing.T) {
+	var polls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/authorize_device":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"device_code":      "dev-code",
+				"user_code":        "ABCD-EFGH",
+				"verification_uri": "https://gitlab.example.com/oauth/device",
+				"expires_in":       300,
+				"interval":         1,
+			})
+		case "/oauth/token":
+			polls++
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"access_token":  "access-1",
+				"refresh_token": "refresh-1",
+				"token_type":    "Bearer",
+				"expires_in":    7200,
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	flow, err := NewDeviceFlow(srv.URL+"/", "client-1")
+	if err != nil {
+		t.Fatalf("NewDeviceFlow() error = %v", err)
+	}
+
+	ctx := context.Background()
+	da, err := flow.Start(ctx)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if da.UserCode != "ABCD-EFGH" {
+		t.Errorf("UserCode = %q, want ABCD-EFGH", da.UserCode)
+	}
+
+	cred, err := flow.Wait(ctx, da)
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if cred.AccessToken != "access-1" || cred.RefreshToken != "refresh-1" {
+		t.Errorf("credential = %+v", cred)
+	}
+	if cred.Host != srv.URL || cred.ClientID != "client-1" {
+		t.Errorf("credential host/client = %q/%q", cred.Host, cred.ClientID)
+	}
+	if cred.ExpiresAt.IsZero() {
+		t.Error("credential ExpiresAt not set")
+	}
+	if polls != 1 {
+		t.Errorf("token endpoint polled %d times, want 1", polls)
+	}
+}
+
+func TestResolveToken_StoredCredential(t *testing.T) {
+	var refreshes int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth/token" {
+			http.NotFound(w, r)
+
+			return
+		}
+		_ = r.ParseForm()
+		if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "old-refresh" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+
+			return
+		}
+		refreshes++
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  "new-access",
+			"refresh_token": "new-refresh",
+			"token_type":    "Bearer",
+			"expires_in":    7200,
+		})
+	}))
+	defer srv.Close()
+
+	store := secrets.NewStore(filepath.Join(t.TempDir(), "credentials.json"))
+	oldStore := credentialStore
+	credentialStore = store
+	t.Cleanup(func() { credentialStore = oldStore })
+
+	ctx := context.Background()
+
+	// Valid credential is returned as-is
+	if err := store.Set(CredentialKey(srv.URL), &secrets.Credential{
+		AccessToken: "fresh",
+		ExpiresAt:   time.Now().Add(time.Hour),
+		Host:        srv.URL,
+		ClientID:    "client-1",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ResolveToken(ctx, "", srv.URL)
+	if err != nil || got != "fresh" {
+		t.Fatalf("ResolveToken() = %q, %v; want fresh", got, err)
+	}
+
+	// Expired credential is refreshed and persisted
+	if err := store.Set(CredentialKey(srv.URL), &secrets.Credential{
+		AccessToken:  "stale",
+		RefreshToken: "old-refresh",
+		ExpiresAt:    time.Now().Add(-time.Minute),
+		Host:         srv.URL,
+		ClientID:     "client-1",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	got, err = ResolveToken(ctx, "", srv.URL)
+	if err != nil || got != "new-access" {
+		t.Fatalf("ResolveToken() = %q, %v; want new-access", got, err)
+	}
+	if refreshes != 1 {
+		t.Errorf("refreshes = %d, want 1", refreshes)
+	}
+
+	saved, err := store.Get(CredentialKey(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.AccessToken != "new-access" || saved.RefreshToken != "new-refresh" {
+		t.Errorf("saved credential = %+v", saved)
+	}
+
+	// Expired credential without refresh token is unauthorized
+	if err := store.Set(CredentialKey(srv.URL), &secrets.Credential{
+		AccessToken: "stale",
+		ExpiresAt:   time.Now().Add(-time.Minute),
+		Host:        srv.URL,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ResolveToken(ctx, "", srv.URL); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("ResolveToken() error = %v, want ErrUnauthorized", err)
+	}
+
+	// Other hosts are not affected
+	if _, err := ResolveToken(ctx, "", "https://other.example.com"); !errors.Is(err, ErrNoToken) {
+		t.Errorf("ResolveToken() for other host error = %v, want ErrNoToken", err)
+	}
+}
@@ -0,0 +1,37 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+// BranchProtection implements the provider.ProtectionReader interface.
+// The project is detected from remoteURL when none is configured. A protected
+// branch the token's user may still push to is not reported as protected.
+func (p *Provider) BranchProtection(ctx context.Context, remoteURL, branch string) (*provider.BranchProtection, error) {
+	projectPath := p.config.ProjectPath
+	if projectPath == "" {
+		host := p.config.Host
+		if u, err := url.Parse(host); err == nil && u.Host != "" {
+			host = u.Host
+		}
+		var err error
+		if projectPath, err = DetectProject(remoteURL, host); err != nil {
+			return nil, err
+		}
+	}
+	p.client.SetProjectPath(projectPath)
+
+	b, err := p.client.GetBranch(ctx, branch)
+	if err != nil {
+		return nil, fmt.Errorf("get branch %s: %w", branch, err)
+	}
+
+	return &provider.BranchProtection{
+		Branch:    branch,
+		Protected: b.Protected && !b.CanPush,
+	}, nil
+}
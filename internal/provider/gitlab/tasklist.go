@@ -0,0 +1,51 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+// SetTaskListItem implements the provider.TaskListUpdater interface.
+// It checks or unchecks a task list item in the GitLab issue description.
+func (p *Provider) SetTaskListItem(ctx context.Context, workUnitID, text string, checked bool) error {
+	ref, err := ParseReference(workUnitID)
+	if err != nil {
+		return fmt.Errorf("parse reference: %w", err)
+	}
+
+	projectPath := ref.ProjectPath
+	if projectPath == "" {
+		projectPath = p.projectPath
+		if projectPath == "" {
+			projectPath = p.config.ProjectPath
+		}
+	}
+
+	if ref.ProjectID > 0 {
+		p.client.SetProjectID(ref.ProjectID)
+	} else if projectPath != "" {
+		p.client.SetProjectPath(projectPath)
+	} else {
+		return ErrProjectNotConfigured
+	}
+
+	issue, err := p.client.GetIssue(ctx, ref.IssueIID)
+	if err != nil {
+		return fmt.Errorf("get issue: %w", err)
+	}
+
+	description, changed, err := provider.SetTaskListItem(issue.Description, text, checked)
+	if err != nil || !changed {
+		return err
+	}
+
+	_, err = p.client.UpdateIssue(ctx, ref.IssueIID, &gitlab.UpdateIssueOptions{
+		Description: &description,
+	})
+
+	return err
+}
@@ -0,0 +1,44 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetTaskListItem(t *testing.T) {
+	var updated string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/42/issues/7" {
+			http.NotFound(w, r)
+
+			return
+		}
+		if r.Method == http.MethodPut {
+			data, _ := io.ReadAll(r.Body)
+			var req struct {
+				Description string `json:"description"`
+			}
+			_ = json.Unmarshal(data, &req)
+			updated = req.Description
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": 1007, "iid": 7, "project_id": 42, "description": "- [x] Add login form\n- [ ] Write docs"}`))
+	}))
+	defer server.Close()
+
+	p := &Provider{
+		client: NewClient("secret", server.URL, "", 0),
+		config: &Config{Token: "secret"},
+	}
+
+	if err := p.SetTaskListItem(context.Background(), "42#7", "Add login form", false); err != nil {
+		t.Fatalf("SetTaskListItem() error = %v", err)
+	}
+	if updated != "- [ ] Add login form\n- [ ] Write docs" {
+		t.Errorf("description = %q", updated)
+	}
+}
@@ -0,0 +1,43 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+// LogTime records time spent on an issue with a /spend quick action note.
+func (p *Provider) LogTime(ctx context.Context, workUnitID string, entry provider.TimeEntry) error {
+	ref, err := ParseReference(workUnitID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.client.AddNote(ctx, ref.IssueIID, spendNote(entry)); err != nil {
+		return fmt.Errorf("log time: %w", err)
+	}
+
+	return nil
+}
+
+// spendNote builds a note whose /spend quick action adds the entry's
+// duration, in whole minutes, on the day the work started.
+func spendNote(entry provider.TimeEntry) string {
+	minutes := int(max(entry.Duration.Round(time.Minute), time.Minute).Minutes())
+	spent := fmt.Sprintf("%dm", minutes)
+	if minutes >= 60 {
+		spent = fmt.Sprintf("%dh%dm", minutes/60, minutes%60)
+	}
+
+	note := "/spend " + spent
+	if !entry.Started.IsZero() {
+		note += " " + entry.Started.Format(time.DateOnly)
+	}
+	if entry.Comment != "" {
+		note += "\n\n" + entry.Comment
+	}
+
+	return note
+}
@@ -0,0 +1,323 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rate limiter defaults.
+const (
+	// DefaultQuotaReserve is the remaining quota at which requests to a host
+	// wait for its quota to reset.
+	DefaultQuotaReserve = 5
+	// DefaultMaxRateLimitWait is the longest the limiter waits before a
+	// request. Longer waits fail with the API's rate limit error instead.
+	DefaultMaxRateLimitWait = 5 * time.Minute
+	// DefaultRateLimitRetries is how often a rate-limited request is retried.
+	DefaultRateLimitRetries = 3
+)
+
+// Reasons a request waits.
+const (
+	WaitQuota      = "quota"       // The host's remaining quota ran low
+	WaitRetryAfter = "retry-after" // The host rejected the request for now
+)
+
+// RateLimitWait describes a request held back by a RateLimiter.
+type RateLimitWait struct {
+	Host    string
+	Wait    time.Duration
+	Reason  string // WaitQuota or WaitRetryAfter
+	Attempt int    // Retry number, 0 when waiting for quota
+}
+
+// hostQuota is what a host last reported about its rate limit.
+type hostQuota struct {
+	remaining int
+	reset     time.Time
+}
+
+// RateLimiter paces requests to API hosts by the quota they report in
+// rate limit headers, shared by every client that sends through it. Requests
+// wait while a host's quota is nearly spent, and requests the host rejects
+// with 429, or 403 when its quota is spent, are retried after the time it
+// asks for.
+type RateLimiter struct {
+	mu       sync.Mutex
+	hosts    map[string]*hostQuota
+	onWait   func(RateLimitWait)
+	reserve  int
+	maxWait  time.Duration
+	retries  int
+	now      func() time.Time
+	sleep    func(ctx context.Context, d time.Duration) error
+	backoffs RetryConfig
+}
+
+// NewRateLimiter creates a rate limiter with the default limits.
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		hosts:    make(map[string]*hostQuota),
+		reserve:  DefaultQuotaReserve,
+		maxWait:  DefaultMaxRateLimitWait,
+		retries:  DefaultRateLimitRetries,
+		now:      time.Now,
+		sleep:    sleep,
+		backoffs: DefaultRetryConfig(),
+	}
+}
+
+var (
+	sharedLimiter     *RateLimiter
+	sharedLimiterOnce sync.Once
+)
+
+// SharedRateLimiter returns the rate limiter shared by provider clients, so
+// all clients of one host draw on the same quota.
+func SharedRateLimiter() *RateLimiter {
+	sharedLimiterOnce.Do(func() {
+		sharedLimiter = NewRateLimiter()
+	})
+
+	return sharedLimiter
+}
+
+// OnWait sets a function called whenever a request waits. A nil fn stops
+// the reports.
+func (l *RateLimiter) OnWait(fn func(RateLimitWait)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.onWait = fn
+}
+
+// Transport returns a round tripper sending requests through base (the
+// default transport when nil) at the limiter's pace. Waits count towards the
+// timeout of a client using it; clients with a timeout should use Do.
+func (l *RateLimiter) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &rateLimitTransport{limiter: l, base: base}
+}
+
+// Do sends req with client at the limiter's pace. The client's timeout
+// applies to each attempt, not to the waits between them.
+func (l *RateLimiter) Do(client *http.Client, req *http.Request) (*http.Response, error) {
+	return l.send(req, client.Do)
+}
+
+type rateLimitTransport struct {
+	limiter *RateLimiter
+	base    http.RoundTripper
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.limiter.send(req, t.base.RoundTrip)
+}
+
+// send sends req, waiting first while its host's quota is nearly spent and
+// retrying while the host asks to. Once the retries are used up, or the host
+// asks for a longer wait than the limiter allows, the rejection is returned
+// for the client to report.
+func (l *RateLimiter) send(req *http.Request, do func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	ctx := req.Context()
+	host := req.URL.Host
+
+	for attempt := 0; ; attempt++ {
+		if wait := l.reserveQuota(host); wait > 0 {
+			l.notify(RateLimitWait{Host: host, Wait: wait, Reason: WaitQuota})
+			if err := l.sleep(ctx, wait); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := do(req)
+		if err != nil {
+			return nil, err
+		}
+		l.observe(host, resp.Header)
+
+		wait, limited := l.retryAfter(resp, attempt)
+		if !limited || attempt >= l.retries || wait > l.maxWait {
+			return resp, nil
+		}
+		retry, ok := rewind(req)
+		if !ok {
+			return resp, nil
+		}
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		l.notify(RateLimitWait{Host: host, Wait: wait, Reason: WaitRetryAfter, Attempt: attempt + 1})
+		if err := l.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		req = retry
+	}
+}
+
+// reserveQuota takes one request from the host's remaining quota and
+// returns how long the request must wait for the quota to reset first.
+func (l *RateLimiter) reserveQuota(host string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	q := l.hosts[host]
+	if q == nil {
+		return 0
+	}
+
+	wait := q.reset.Sub(l.now())
+	if wait <= 0 || wait > l.maxWait {
+		// The quota has reset, or the wait is too long: let the host answer
+		delete(l.hosts, host)
+
+		return 0
+	}
+	q.remaining--
+	if q.remaining >= l.reserve {
+		return 0
+	}
+
+	// Until the reset, this and later requests queue for the new quota
+	return wait
+}
+
+// observe records the quota a host reported in its response headers:
+// X-RateLimit-Remaining and X-RateLimit-Reset (GitHub, Jira) or
+// RateLimit-Remaining and RateLimit-Reset (GitLab).
+func (l *RateLimiter) observe(host string, header http.Header) {
+	remaining, err := strconv.Atoi(firstHeader(header, "X-RateLimit-Remaining", "RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	untilReset, ok := l.parseWait(firstHeader(header, "X-RateLimit-Reset", "RateLimit-Reset"))
+	if !ok {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hosts[host] = &hostQuota{remaining: remaining, reset: l.now().Add(untilReset)}
+}
+
+// retryAfter reports whether resp is a rate limit rejection and how long to
+// wait before retrying it: the Retry-After header, else until the quota
+// resets, else an exponential backoff.
+func (l *RateLimiter) retryAfter(resp *http.Response, attempt int) (time.Duration, bool) {
+	retryAfter := resp.Header.Get("Retry-After")
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+	case http.StatusForbidden:
+		// A 403 is only a rate limit when the host says so
+		if retryAfter == "" && firstHeader(resp.Header, "X-RateLimit-Remaining", "RateLimit-Remaining") != "0" {
+			return 0, false
+		}
+	case http.StatusServiceUnavailable:
+		if retryAfter == "" {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	if wait, ok := l.parseWait(retryAfter); ok {
+		return max(wait, 0), true
+	}
+	if wait, ok := l.parseWait(firstHeader(resp.Header, "X-RateLimit-Reset", "RateLimit-Reset")); ok {
+		return max(wait, 0), true
+	}
+
+	backoff := l.backoffs.InitialBackoff
+	for range attempt {
+		backoff = time.Duration(float64(backoff) * l.backoffs.Multiplier)
+	}
+
+	return min(backoff, l.backoffs.MaxBackoff), true
+}
+
+// parseWait reads how long from now a rate limit header points to: seconds
+// from now or since the epoch, an HTTP date or an RFC 3339 timestamp.
+func (l *RateLimiter) parseWait(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		// Epoch seconds are far larger than any wait in seconds
+		if n > 1_000_000_000 {
+			return time.Unix(n, 0).Sub(l.now()), true
+		}
+
+		return time.Duration(n) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t.Sub(l.now()), true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Sub(l.now()), true
+	}
+
+	return 0, false
+}
+
+func (l *RateLimiter) notify(wait RateLimitWait) {
+	l.mu.Lock()
+	onWait := l.onWait
+	l.mu.Unlock()
+
+	if onWait != nil {
+		onWait(wait)
+	}
+}
+
+// rewind returns a copy of req to send again, with a fresh body. It reports
+// false when the body cannot be read again.
+func rewind(req *http.Request) (*http.Request, bool) {
+	retry := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return retry, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	retry.Body = body
+
+	return retry, true
+}
+
+func firstHeader(header http.Header, names ...string) string {
+	for _, name := range names {
+		if v := header.Get(name); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,178 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestLimiter returns a limiter that records its waits instead of sleeping.
+func newTestLimiter() (*RateLimiter, *[]RateLimitWait) {
+	l := NewRateLimiter()
+	l.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	var mu sync.Mutex
+	var waits []RateLimitWait
+	l.OnWait(func(w RateLimitWait) {
+		mu.Lock()
+		defer mu.Unlock()
+		waits = append(waits, w)
+	})
+
+	return l, &waits
+}
+
+func TestRateLimiter_RetryAfter(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("attempt %d sent body %q", calls, body)
+		}
+		if calls == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	l, waits := newTestLimiter()
+	client := &http.Client{Transport: l.Transport(nil)}
+	resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls != 2 {
+		t.Errorf("status = %d after %d calls, want 200 after 2", resp.StatusCode, calls)
+	}
+	if len(*waits) != 1 || (*waits)[0].Wait != 7*time.Second || (*waits)[0].Reason != WaitRetryAfter || (*waits)[0].Attempt != 1 {
+		t.Errorf("waits = %+v", *waits)
+	}
+}
+
+func TestRateLimiter_Forbidden(t *testing.T) {
+	reset := time.Now().Add(30 * time.Second).Unix()
+	tests := []struct {
+		name      string
+		remaining string
+		wantCalls int
+	}{
+		{name: "quota spent", remaining: "0", wantCalls: 2},
+		{name: "permission denied", remaining: "42", wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls == 1 {
+					w.Header().Set("X-RateLimit-Remaining", tt.remaining)
+					w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+					w.WriteHeader(http.StatusForbidden)
+
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer srv.Close()
+
+			l, _ := newTestLimiter()
+			req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+			resp, err := l.Do(srv.Client(), req)
+			if err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+			_ = resp.Body.Close()
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_QueuesNearQuota(t *testing.T) {
+	reset := time.Now().Add(time.Minute)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// GitLab's header names
+		w.Header().Set("RateLimit-Remaining", "5")
+		w.Header().Set("RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	}))
+	defer srv.Close()
+
+	l, waits := newTestLimiter()
+	client := &http.Client{Transport: l.Transport(nil)}
+	for range 2 {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	if len(*waits) != 1 || (*waits)[0].Reason != WaitQuota || (*waits)[0].Wait <= 0 {
+		t.Errorf("waits = %+v, want one quota wait before the second request", *waits)
+	}
+}
+
+func TestRateLimiter_GivesUp(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	l, waits := newTestLimiter()
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	resp, err := l.Do(srv.Client(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests || calls != 1 || len(*waits) != 0 {
+		t.Errorf("status = %d, calls = %d, waits = %d: a wait above the maximum should return the rejection", resp.StatusCode, calls, len(*waits))
+	}
+}
+
+func TestRateLimiter_ParseWait(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	l := NewRateLimiter()
+	l.now = func() time.Time { return now }
+
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{value: "30", want: 30 * time.Second, ok: true},
+		{value: strconv.FormatInt(now.Add(time.Hour).Unix(), 10), want: time.Hour, ok: true},
+		{value: "Sun, 01 Mar 2026 12:05:00 GMT", want: 5 * time.Minute, ok: true},
+		{value: "2026-03-01T12:10:00Z", want: 10 * time.Minute, ok: true},
+		{value: ""},
+		{value: "soon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := l.parseWait(tt.value)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("parseWait(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
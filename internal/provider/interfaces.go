@@ -2,7 +2,10 @@ package provider
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"io"
+	"time"
 )
 
 // Reader fetches work units from a provider.
@@ -26,6 +29,13 @@ type AttachmentDownloader interface {
 	DownloadAttachment(ctx context.Context, workUnitID, attachmentID string) (io.ReadCloser, error)
 }
 
+// ImageFetcher downloads an image referenced from work unit content. URLs
+// hosted by the provider are fetched with its credentials, so images on
+// private trackers can be cached locally.
+type ImageFetcher interface {
+	FetchImage(ctx context.Context, imageURL string) (io.ReadCloser, error)
+}
+
 // CommentFetcher retrieves comments.
 type CommentFetcher interface {
 	FetchComments(ctx context.Context, workUnitID string) ([]Comment, error)
@@ -41,6 +51,13 @@ type StatusUpdater interface {
 	UpdateStatus(ctx context.Context, workUnitID string, status Status) error
 }
 
+// TaskListUpdater checks and unchecks task list items (markdown checkboxes)
+// in a work unit's description.
+type TaskListUpdater interface {
+	// SetTaskListItem sets the checkbox of the item whose text is text.
+	SetTaskListItem(ctx context.Context, workUnitID, text string, checked bool) error
+}
+
 // LabelManager manages labels on work units.
 type LabelManager interface {
 	AddLabels(ctx context.Context, workUnitID string, labels []string) error
@@ -83,6 +100,9 @@ type Snapshot struct {
 type SnapshotFile struct {
 	Path    string
 	Content string
+	ModTime time.Time // zero when the provider does not track modification times
+	Hash    string    // hex SHA-256 of Content, empty when not computed
+	ETag    string    // HTTP entity tag, for providers that fetch over HTTP
 }
 
 // Snapshotter captures source content for storage.
@@ -90,6 +110,36 @@ type Snapshotter interface {
 	Snapshot(ctx context.Context, id string) (*Snapshot, error)
 }
 
+// FileState identifies the version of a previously snapshotted file.
+type FileState struct {
+	ModTime time.Time
+	Size    int64
+	Hash    string
+	ETag    string
+}
+
+// SnapshotDelta contains the files that changed since a previous snapshot.
+type SnapshotDelta struct {
+	Ref     string
+	Changed []SnapshotFile       // added or modified files, with content
+	Removed []string             // paths no longer present in the source
+	States  map[string]FileState // current state of every file in the source
+}
+
+// IncrementalSnapshotter re-snapshots only the files that changed since a
+// previous snapshot. Files whose modification time and size match are not
+// read; files that were touched but kept their content hash are unchanged.
+type IncrementalSnapshotter interface {
+	SnapshotChanges(ctx context.Context, id string, previous map[string]FileState) (*SnapshotDelta, error)
+}
+
+// HashContent returns the hex SHA-256 of snapshot content.
+func HashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+
+	return hex.EncodeToString(sum[:])
+}
+
 // ──────────────────────────────────────────────────────────────────────────────
 // Extended interfaces for bidirectional providers (GitHub, Wrike, etc.)
 // ──────────────────────────────────────────────────────────────────────────────
@@ -119,6 +169,22 @@ type PullRequest struct {
 	Number int
 }
 
+// ProtectionReader reads the protection rules of a branch on the service
+// hosting the repository, so a protected branch is not merged into locally.
+type ProtectionReader interface {
+	// BranchProtection returns the protection of branch in the repository
+	// remoteURL points at, when the provider has no repository configured.
+	BranchProtection(ctx context.Context, remoteURL, branch string) (*BranchProtection, error)
+}
+
+// BranchProtection describes the protection of a branch.
+type BranchProtection struct {
+	Branch          string
+	Protected       bool     // Changes must go through a pull request
+	RequiredReviews int      // Approving reviews a pull request needs, 0 when none or unknown
+	RequiredChecks  []string // Status checks a pull request must pass
+}
+
 // BranchLinker links work units to git branches.
 type BranchLinker interface {
 	LinkBranch(ctx context.Context, workUnitID, branch string) error
@@ -136,6 +202,40 @@ type SubtaskFetcher interface {
 	FetchSubtasks(ctx context.Context, workUnitID string) ([]*WorkUnit, error)
 }
 
+// ReferencePinner is implemented by providers whose references can select a
+// work unit by query, such as the first page matching a database filter.
+// PinReference returns the reference that addresses the picked work unit
+// itself, which the task stores instead of the query, so later syncs and
+// write-backs reach the same work unit. Other references are returned as is.
+type ReferencePinner interface {
+	PinReference(reference string, wu *WorkUnit) string
+}
+
+// FinishHandler is notified when a task sourced from the provider finishes,
+// e.g. to move the work unit to a done status.
+type FinishHandler interface {
+	OnTaskFinished(ctx context.Context, workUnitID string, info FinishInfo) error
+}
+
+// FinishInfo describes where the finished task's changes went.
+type FinishInfo struct {
+	Branch         string // Task branch, empty without git
+	PullRequestURL string // Pull request opened on finish, empty when merged locally
+}
+
+// TimeLogger records time spent on work units, such as Jira worklogs or
+// YouTrack work items.
+type TimeLogger interface {
+	LogTime(ctx context.Context, workUnitID string, entry TimeEntry) error
+}
+
+// TimeEntry is time spent on a work unit.
+type TimeEntry struct {
+	Duration time.Duration
+	Started  time.Time // When the work started
+	Comment  string    // Describes the work, e.g. the time per phase
+}
+
 // CreateWorkUnitOptions for creating a work unit.
 type CreateWorkUnitOptions struct {
 	CustomFields map[string]any
@@ -161,6 +261,8 @@ type FullProvider interface {
 	CommentFetcher
 	PRCreator
 	BranchLinker
+	ProtectionReader
+	TimeLogger
 	WorkUnitCreator
 	Snapshotter
 	SubtaskFetcher
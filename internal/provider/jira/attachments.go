@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
+	"strings"
 
 	providererrors "github.com/valksor/go-mehrhof/internal/provider/errors"
 )
@@ -49,3 +52,51 @@ func (p *Provider) DownloadAttachment(ctx context.Context, workUnitID, attachmen
 
 	return reader, nil
 }
+
+// FetchImage downloads an image referenced from an issue snapshot. Attachment
+// URLs on the Jira instance are fetched with the provider's credentials;
+// other hosts get an anonymous request.
+func (p *Provider) FetchImage(ctx context.Context, imageURL string) (io.ReadCloser, error) {
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse image url: %w", err)
+	}
+	if p.client.ownsHost(u.Host) {
+		reader, _, err := p.client.DownloadAttachment(ctx, imageURL)
+
+		return reader, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	resp, err := p.client.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+
+		return nil, fmt.Errorf("download failed: status %d", resp.StatusCode)
+	}
+
+	return resp.Body, nil
+}
+
+// imageAttachmentsMarkdown lists an issue's image attachments as markdown
+// images, so the snapshot references them like inline images.
+func imageAttachmentsMarkdown(attachments []*Attachment) string {
+	var sb strings.Builder
+	for _, a := range attachments {
+		if a == nil || a.Content == "" || !strings.HasPrefix(a.MimeType, "image/") {
+			continue
+		}
+		fmt.Fprintf(&sb, "![%s](%s)\n", a.Filename, a.Content)
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+
+	return "## Attachments\n\n" + sb.String() + "\n"
+}
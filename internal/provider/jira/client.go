@@ -14,6 +14,7 @@ import (
 	"time"
 
 	providererrors "github.com/valksor/go-mehrhof/internal/provider/errors"
+	"github.com/valksor/go-mehrhof/internal/provider/httpclient"
 	"github.com/valksor/go-mehrhof/internal/provider/token"
 )
 
@@ -70,6 +71,17 @@ func (c *Client) SetBaseURL(baseURL string) {
 	}
 }
 
+// ownsHost reports whether a URL host is the Jira instance's, and may be sent
+// its credentials.
+func (c *Client) ownsHost(host string) bool {
+	base, err := url.Parse(c.baseURL)
+	if err != nil || base.Host == "" {
+		return false
+	}
+
+	return strings.EqualFold(base.Host, host)
+}
+
 // ResolveToken finds the Jira token from multiple sources.
 // Priority order:
 //  1. MEHR_JIRA_TOKEN env var
@@ -140,7 +152,7 @@ func (c *Client) doRequest(ctx context.Context, method, endpoint string, body an
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := httpclient.SharedRateLimiter().Do(c.httpClient, req)
 	if err != nil {
 		return wrapAPIError(err)
 	}
@@ -242,6 +254,20 @@ func (c *Client) AddComment(ctx context.Context, issueKey, body string) (*Commen
 	return &response, nil
 }
 
+// AddWorklog logs time spent on an issue.
+func (c *Client) AddWorklog(ctx context.Context, issueKey string, seconds int, started time.Time, comment string) error {
+	endpoint := fmt.Sprintf("/issue/%s/worklog", issueKey)
+	input := map[string]any{
+		"timeSpentSeconds": seconds,
+		"started":          started.Format("2006-01-02T15:04:05.000-0700"),
+	}
+	if comment != "" {
+		input["comment"] = comment
+	}
+
+	return c.doRequest(ctx, http.MethodPost, endpoint, input, nil)
+}
+
 // GetComments fetches comments for an issue.
 func (c *Client) GetComments(ctx context.Context, issueKey string) ([]*Comment, error) {
 	endpoint := fmt.Sprintf("/issue/%s/comment", issueKey)
@@ -274,7 +300,7 @@ func (c *Client) DownloadAttachment(ctx context.Context, attachmentURL string) (
 
 	req.Header.Set("Authorization", c.getAuthHeader())
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := httpclient.SharedRateLimiter().Do(c.httpClient, req)
 	if err != nil {
 		return nil, "", wrapAPIError(err)
 	}
@@ -328,6 +354,8 @@ type Fields struct {
 	Attachments []*Attachment `json:"attachment"`
 	Subtasks    []*Issue      `json:"subtasks"`
 	Parent      *Issue        `json:"parent"`
+
+	TimeOriginalEstimate int `json:"timeoriginalestimate"` // Seconds, 0 when not estimated
 }
 
 // Status represents issue status.
@@ -45,6 +45,7 @@ func Info() provider.ProviderInfo {
 			provider.CapDownloadAttachment: true,
 			provider.CapSnapshot:           true,
 			provider.CapFetchSubtasks:      true,
+			provider.CapLogTime:            true,
 		},
 	}
 }
@@ -135,6 +136,7 @@ func (p *Provider) Fetch(ctx context.Context, id string) (*provider.WorkUnit, er
 		TaskType:    inferTaskTypeFromLabels(issue.Fields.Labels),
 		Slug:        naming.Slugify(issue.Fields.Summary, 50),
 		Metadata:    buildMetadata(issue),
+		Estimate:    time.Duration(issue.Fields.TimeOriginalEstimate) * time.Second,
 	}
 
 	// Fetch comments if available
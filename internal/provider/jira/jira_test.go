@@ -1348,3 +1348,28 @@ func TestSetBaseURL(t *testing.T) {
 		})
 	}
 }
+
+func TestImageAttachmentsMarkdown(t *testing.T) {
+	got := imageAttachmentsMarkdown([]*Attachment{
+		{Filename: "screen.png", MimeType: "image/png", Content: "https://acme.atlassian.net/rest/api/3/attachment/content/1"},
+		{Filename: "log.txt", MimeType: "text/plain", Content: "https://acme.atlassian.net/rest/api/3/attachment/content/2"},
+	})
+	want := "## Attachments\n\n![screen.png](https://acme.atlassian.net/rest/api/3/attachment/content/1)\n\n"
+	if got != want {
+		t.Errorf("imageAttachmentsMarkdown() = %q, want %q", got, want)
+	}
+
+	if got := imageAttachmentsMarkdown(nil); got != "" {
+		t.Errorf("imageAttachmentsMarkdown(nil) = %q, want empty", got)
+	}
+}
+
+func TestClientOwnsHost(t *testing.T) {
+	c := NewClient("token", "me@example.com", "https://acme.atlassian.net")
+	if !c.ownsHost("acme.atlassian.net") {
+		t.Error("ownsHost(instance) = false")
+	}
+	if c.ownsHost("evil.example.com") {
+		t.Error("ownsHost(other) = true")
+	}
+}
@@ -86,6 +86,8 @@ func (p *Provider) Snapshot(ctx context.Context, id string) (*provider.Snapshot,
 		content.WriteString("\n\n")
 	}
 
+	content.WriteString(imageAttachmentsMarkdown(issue.Fields.Attachments))
+
 	// Comments
 	if len(comments) > 0 {
 		content.WriteString("## Comments\n\n")
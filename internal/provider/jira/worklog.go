@@ -0,0 +1,34 @@
+package jira
+
+import (
+	"context"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+// LogTime adds a worklog to a Jira issue.
+func (p *Provider) LogTime(ctx context.Context, workUnitID string, entry provider.TimeEntry) error {
+	ref, err := ParseReference(workUnitID)
+	if err != nil {
+		return err
+	}
+
+	// Update base URL if detected from reference
+	if ref.BaseURL != "" && p.baseURL == "" {
+		p.baseURL = ref.BaseURL
+		p.client.SetBaseURL(ref.BaseURL)
+	}
+
+	started := entry.Started
+	if started.IsZero() {
+		started = time.Now().Add(-entry.Duration)
+	}
+
+	return p.client.AddWorklog(ctx, ref.IssueKey, worklogSeconds(entry.Duration), started, entry.Comment)
+}
+
+// worklogSeconds rounds d to whole minutes, the smallest worklog Jira accepts.
+func worklogSeconds(d time.Duration) int {
+	return int(max(d.Round(time.Minute), time.Minute).Seconds())
+}
@@ -107,10 +107,40 @@ func (c *Client) GetPage(ctx context.Context, pageID string) (*Page, error) {
 	return &page, nil
 }
 
-// GetPageContent fetches the block content of a page.
+// GetPageContent fetches the block content of a page, including nested
+// blocks (list items, toggles, ...) up to maxBlockDepth levels deep. Nested
+// blocks that cannot be fetched are left out.
 func (c *Client) GetPageContent(ctx context.Context, pageID string) ([]Block, error) {
-	normalizedID := NormalizePageID(pageID)
+	return c.getBlockTree(ctx, NormalizePageID(pageID), 1)
+}
+
+// maxBlockDepth bounds how deep GetPageContent descends into nested blocks.
+const maxBlockDepth = 4
+
+func (c *Client) getBlockTree(ctx context.Context, blockID string, depth int) ([]Block, error) {
+	blocks, err := c.getBlockChildren(ctx, blockID)
+	if err != nil {
+		return nil, err
+	}
+	if depth >= maxBlockDepth {
+		return blocks, nil
+	}
 
+	for i := range blocks {
+		// Sub-pages and databases are separate documents, not page content
+		if !blocks[i].HasChildren || blocks[i].Type == "child_page" || blocks[i].Type == "child_database" {
+			continue
+		}
+		if children, err := c.getBlockTree(ctx, blocks[i].ID, depth+1); err == nil {
+			blocks[i].Children = children
+		}
+	}
+
+	return blocks, nil
+}
+
+// getBlockChildren fetches the direct children of a block or page.
+func (c *Client) getBlockChildren(ctx context.Context, blockID string) ([]Block, error) {
 	var blocks struct {
 		Object     string  `json:"object"`
 		NextCursor string  `json:"next_cursor,omitempty"`
@@ -118,7 +148,7 @@ func (c *Client) GetPageContent(ctx context.Context, pageID string) ([]Block, er
 		HasMore    bool    `json:"has_more"`
 	}
 
-	path := fmt.Sprintf("/v1/blocks/%s/children", normalizedID)
+	path := fmt.Sprintf("/v1/blocks/%s/children", blockID)
 	allBlocks := []Block{}
 
 	for {
@@ -137,7 +167,7 @@ func (c *Client) GetPageContent(ctx context.Context, pageID string) ([]Block, er
 		}
 
 		// Continue pagination
-		path = fmt.Sprintf("/v1/blocks/%s/children?start_cursor=%s", normalizedID, blocks.NextCursor)
+		path = fmt.Sprintf("/v1/blocks/%s/children?start_cursor=%s", blockID, blocks.NextCursor)
 	}
 
 	return allBlocks, nil
@@ -402,106 +432,6 @@ func ExtractLabels(prop Property) []string {
 	return labels
 }
 
-// Convert blocks to markdown.
-func BlocksToMarkdown(blocks []Block) string {
-	var md strings.Builder
-
-	for _, block := range blocks {
-		switch block.Type {
-		case "paragraph":
-			if block.Paragraph != nil {
-				for _, rt := range block.Paragraph.RichText {
-					md.WriteString(rt.PlainText)
-				}
-				md.WriteString("\n\n")
-			}
-		case "heading_1":
-			if block.Heading1 != nil {
-				md.WriteString("# ")
-				for _, rt := range block.Heading1.RichText {
-					md.WriteString(rt.PlainText)
-				}
-				md.WriteString("\n\n")
-			}
-		case "heading_2":
-			if block.Heading2 != nil {
-				md.WriteString("## ")
-				for _, rt := range block.Heading2.RichText {
-					md.WriteString(rt.PlainText)
-				}
-				md.WriteString("\n\n")
-			}
-		case "heading_3":
-			if block.Heading3 != nil {
-				md.WriteString("### ")
-				for _, rt := range block.Heading3.RichText {
-					md.WriteString(rt.PlainText)
-				}
-				md.WriteString("\n\n")
-			}
-		case "bulleted_list_item":
-			if block.BulletedListItem != nil {
-				md.WriteString("- ")
-				for _, rt := range block.BulletedListItem.RichText {
-					md.WriteString(rt.PlainText)
-				}
-				md.WriteString("\n")
-			}
-		case "numbered_list_item":
-			if block.NumberedListItem != nil {
-				md.WriteString("1. ")
-				for _, rt := range block.NumberedListItem.RichText {
-					md.WriteString(rt.PlainText)
-				}
-				md.WriteString("\n")
-			}
-		case "to_do":
-			if block.ToDo != nil {
-				checkbox := "[ ]"
-				if block.ToDo.Checked {
-					checkbox = "[x]"
-				}
-				md.WriteString(checkbox)
-				md.WriteString(" ")
-				for _, rt := range block.ToDo.RichText {
-					md.WriteString(rt.PlainText)
-				}
-				md.WriteString("\n")
-			}
-		case "code":
-			if block.Code != nil {
-				md.WriteString("```")
-				md.WriteString(block.Code.Language)
-				md.WriteString("\n")
-				for _, rt := range block.Code.RichText {
-					md.WriteString(rt.PlainText)
-				}
-				md.WriteString("\n```\n\n")
-			}
-		case "quote":
-			if block.Quote != nil {
-				md.WriteString("> ")
-				for _, rt := range block.Quote.RichText {
-					md.WriteString(rt.PlainText)
-				}
-				md.WriteString("\n\n")
-			}
-		case "divider":
-			md.WriteString("---\n\n")
-		case "callout":
-			if block.Callout != nil {
-				md.WriteString("> ")
-				for _, rt := range block.Callout.RichText {
-					md.WriteString(rt.PlainText)
-				}
-				md.WriteString("\n\n")
-			}
-		}
-	}
-
-	return md.String()
-}
-
 // Helper to get property by name (case-insensitive).
 func GetProperty(page Page, name string) (Property, bool) {
 	for key, prop := range page.Properties {
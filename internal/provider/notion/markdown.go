@@ -0,0 +1,182 @@
+package notion
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BlocksToMarkdown converts page blocks, including their nested children, to
+// markdown.
+func BlocksToMarkdown(blocks []Block) string {
+	var md strings.Builder
+	writeBlocks(&md, blocks, "")
+
+	return md.String()
+}
+
+// writeBlocks writes blocks at one nesting level, numbering consecutive
+// numbered list items.
+func writeBlocks(md *strings.Builder, blocks []Block, indent string) {
+	number := 0
+	for _, block := range blocks {
+		if block.Type == "numbered_list_item" {
+			number++
+		} else {
+			number = 0
+		}
+		writeBlock(md, block, indent, number)
+	}
+}
+
+func writeBlock(md *strings.Builder, block Block, indent string, number int) {
+	childIndent := indent + "  "
+
+	switch block.Type {
+	case "paragraph":
+		if block.Paragraph != nil {
+			writeLine(md, indent, richTextToMarkdown(block.Paragraph.RichText))
+			md.WriteString("\n")
+		}
+	case "heading_1", "heading_2", "heading_3":
+		heading := map[string]*HeadingBlock{"heading_1": block.Heading1, "heading_2": block.Heading2, "heading_3": block.Heading3}[block.Type]
+		if heading != nil {
+			level := int(block.Type[len(block.Type)-1] - '0')
+			writeLine(md, indent, strings.Repeat("#", level)+" "+richTextToMarkdown(heading.RichText))
+			md.WriteString("\n")
+		}
+		childIndent = indent // Toggle heading content follows the heading
+	case "bulleted_list_item":
+		if block.BulletedListItem != nil {
+			writeLine(md, indent, "- "+richTextToMarkdown(block.BulletedListItem.RichText))
+		}
+	case "numbered_list_item":
+		if block.NumberedListItem != nil {
+			writeLine(md, indent, fmt.Sprintf("%d. ", number)+richTextToMarkdown(block.NumberedListItem.RichText))
+			childIndent = indent + "   "
+		}
+	case "to_do":
+		if block.ToDo != nil {
+			checkbox := "- [ ] "
+			if block.ToDo.Checked {
+				checkbox = "- [x] "
+			}
+			writeLine(md, indent, checkbox+richTextToMarkdown(block.ToDo.RichText))
+		}
+	case "toggle":
+		if block.Toggle != nil {
+			writeLine(md, indent, "- "+richTextToMarkdown(block.Toggle.RichText))
+		}
+	case "code":
+		if block.Code != nil {
+			language := block.Code.Language
+			if language == "plain text" {
+				language = ""
+			}
+			writeLine(md, indent, "```"+language)
+			writeLine(md, indent, plainText(block.Code.RichText))
+			writeLine(md, indent, "```")
+			md.WriteString("\n")
+		}
+	case "quote":
+		if block.Quote != nil {
+			writeLine(md, indent+"> ", richTextToMarkdown(block.Quote.RichText))
+			md.WriteString("\n")
+		}
+	case "callout":
+		if block.Callout != nil {
+			text := richTextToMarkdown(block.Callout.RichText)
+			if block.Callout.Icon != nil && block.Callout.Icon.Emoji != "" {
+				text = block.Callout.Icon.Emoji + " " + text
+			}
+			writeLine(md, indent+"> ", text)
+			md.WriteString("\n")
+		}
+	case "image":
+		if block.Image != nil {
+			var url string
+			switch {
+			case block.Image.External != nil:
+				url = block.Image.External.URL
+			case block.Image.File != nil:
+				url = block.Image.File.URL
+			}
+			if url != "" {
+				writeLine(md, indent, fmt.Sprintf("![%s](%s)", plainText(block.Image.Caption), url))
+				md.WriteString("\n")
+			}
+		}
+	case "bookmark":
+		if block.Bookmark != nil && block.Bookmark.URL != "" {
+			title := plainText(block.Bookmark.Caption)
+			if title == "" {
+				title = block.Bookmark.URL
+			}
+			writeLine(md, indent, fmt.Sprintf("[%s](%s)", title, block.Bookmark.URL))
+			md.WriteString("\n")
+		}
+	case "divider":
+		writeLine(md, indent, "---")
+		md.WriteString("\n")
+	}
+
+	if len(block.Children) > 0 {
+		writeBlocks(md, block.Children, childIndent)
+	}
+}
+
+// writeLine writes text with every line prefixed by indent.
+func writeLine(md *strings.Builder, indent, text string) {
+	for line := range strings.SplitSeq(text, "\n") {
+		md.WriteString(indent)
+		md.WriteString(line)
+		md.WriteString("\n")
+	}
+}
+
+// plainText joins rich text without formatting.
+func plainText(rts []RichText) string {
+	var sb strings.Builder
+	for _, rt := range rts {
+		sb.WriteString(rt.PlainText)
+	}
+
+	return sb.String()
+}
+
+// richTextToMarkdown renders rich text with its inline code, bold, italic,
+// strikethrough and link formatting.
+func richTextToMarkdown(rts []RichText) string {
+	var sb strings.Builder
+	for _, rt := range rts {
+		text := rt.PlainText
+		if a := rt.Annotations; a != nil && strings.TrimSpace(text) != "" {
+			if a.Code {
+				text = wrapText(text, "`")
+			}
+			if a.Bold {
+				text = wrapText(text, "**")
+			}
+			if a.Italic {
+				text = wrapText(text, "_")
+			}
+			if a.Strikethrough {
+				text = wrapText(text, "~~")
+			}
+		}
+		if rt.Href != "" {
+			text = "[" + text + "](" + rt.Href + ")"
+		}
+		sb.WriteString(text)
+	}
+
+	return sb.String()
+}
+
+// wrapText surrounds text with a markdown marker, keeping surrounding
+// whitespace outside it (markdown ignores "** bold**").
+func wrapText(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	start := strings.Index(text, trimmed)
+
+	return text[:start] + marker + trimmed + marker + text[start+len(trimmed):]
+}
@@ -2,6 +2,7 @@ package notion
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
@@ -19,6 +20,7 @@ type Provider struct {
 	statusProperty      string
 	descriptionProperty string
 	labelsProperty      string
+	finishStatus        string
 }
 
 // Config holds Notion provider configuration.
@@ -28,6 +30,7 @@ type Config struct {
 	StatusProperty      string
 	DescriptionProperty string
 	LabelsProperty      string
+	FinishStatus        string
 }
 
 // Info returns provider metadata.
@@ -57,6 +60,7 @@ func New(_ context.Context, cfg provider.Config) (any, error) {
 	statusProperty := cfg.GetString("status_property")
 	descriptionProperty := cfg.GetString("description_property")
 	labelsProperty := cfg.GetString("labels_property")
+	finishStatus := cfg.GetString("finish_status")
 
 	// Set defaults for property names
 	if statusProperty == "" {
@@ -68,6 +72,9 @@ func New(_ context.Context, cfg provider.Config) (any, error) {
 	if labelsProperty == "" {
 		labelsProperty = "Tags"
 	}
+	if finishStatus == "" {
+		finishStatus = mapProviderStatusToNotion(provider.StatusDone)
+	}
 
 	// Try to resolve token from env if not provided
 	if token == "" {
@@ -84,6 +91,7 @@ func New(_ context.Context, cfg provider.Config) (any, error) {
 		statusProperty:      statusProperty,
 		descriptionProperty: descriptionProperty,
 		labelsProperty:      labelsProperty,
+		finishStatus:        finishStatus,
 	}, nil
 }
 
@@ -92,16 +100,64 @@ func (p *Provider) Match(input string) bool {
 	return strings.HasPrefix(input, "notion:") || strings.HasPrefix(input, "nt:")
 }
 
-// Parse extracts the page reference from input.
+// Parse extracts the page reference from input. Database references keep
+// their query, in canonical form.
 func (p *Provider) Parse(input string) (string, error) {
 	ref, err := ParseReference(input)
 	if err != nil {
 		return "", err
 	}
+	if ref.IsDatabase() {
+		return ref.String(), nil
+	}
 
 	return ref.PageID, nil
 }
 
+// PinReference replaces a database query reference with a reference to the
+// page it picked, so the task keeps addressing that page once its
+// properties no longer match the query.
+func (p *Provider) PinReference(reference string, wu *provider.WorkUnit) string {
+	ref, err := ParseReference(reference)
+	if err != nil || !ref.IsDatabase() || wu == nil {
+		return reference
+	}
+
+	return "notion:page/" + NormalizePageID(wu.ID)
+}
+
+// OnTaskFinished sets the page's status property to the configured finish
+// status ("Done" by default; "none" disables the write-back).
+func (p *Provider) OnTaskFinished(ctx context.Context, workUnitID string, _ provider.FinishInfo) error {
+	if strings.EqualFold(p.finishStatus, "none") {
+		return nil
+	}
+
+	ref, err := ParseReference(workUnitID)
+	if err != nil {
+		return err
+	}
+	page, err := p.resolvePage(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	prop, ok := GetProperty(*page, p.statusProperty)
+	if !ok {
+		return fmt.Errorf("status property %q not found on page", p.statusProperty)
+	}
+
+	value := MakeStatusProperty(p.finishStatus)
+	if prop.Type == "select" {
+		value = Property{Type: "select", Select: &SelectProp{Name: p.finishStatus}}
+	}
+	_, err = p.client.UpdatePage(ctx, page.ID, &UpdatePageInput{
+		Properties: map[string]Property{p.statusProperty: value},
+	})
+
+	return err
+}
+
 // Fetch reads a Notion page and creates a WorkUnit.
 func (p *Provider) Fetch(ctx context.Context, id string) (*provider.WorkUnit, error) {
 	ref, err := ParseReference(id)
@@ -109,14 +165,14 @@ func (p *Provider) Fetch(ctx context.Context, id string) (*provider.WorkUnit, er
 		return nil, err
 	}
 
-	// Fetch page from Notion
-	page, err := p.client.GetPage(ctx, ref.PageID)
+	// Fetch page from Notion (for database references, the first match)
+	page, err := p.resolvePage(ctx, ref)
 	if err != nil {
 		return nil, err
 	}
 
 	// Fetch page content (blocks)
-	blocks, err := p.client.GetPageContent(ctx, ref.PageID)
+	blocks, err := p.client.GetPageContent(ctx, page.ID)
 	if err != nil {
 		blocks = []Block{} // Continue without content
 	}
@@ -308,6 +364,9 @@ func buildMetadata(page Page, ref *Ref) map[string]any {
 	if ref != nil && ref.URL != "" {
 		metadata["source_url"] = ref.URL
 	}
+	if ref != nil && ref.IsDatabase() {
+		metadata["database_query"] = ref.String()
+	}
 
 	if page.Parent.Type == "database_id" {
 		metadata["database_id"] = page.Parent.DatabaseID
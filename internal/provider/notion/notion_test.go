@@ -700,3 +700,35 @@ func BenchmarkMatch(b *testing.B) {
 		p.Match(input)
 	}
 }
+
+func TestBlocksToMarkdown_Rich(t *testing.T) {
+	text := func(s string) []RichText { return []RichText{{Type: "text", PlainText: s}} }
+	blocks := []Block{
+		{Type: "heading_2", Heading2: &HeadingBlock{RichText: text("Plan")}},
+		{Type: "paragraph", Paragraph: &ParagraphBlock{RichText: []RichText{
+			{PlainText: "Use "},
+			{PlainText: "retry ", Annotations: &Annotations{Bold: true}},
+			{PlainText: "Do()", Annotations: &Annotations{Code: true}},
+			{PlainText: " docs", Href: "https://example.com"},
+		}}},
+		{Type: "to_do", ToDo: &ToDoBlock{RichText: text("Write tests"), Checked: true}, Children: []Block{
+			{Type: "to_do", ToDo: &ToDoBlock{RichText: text("Unit")}},
+		}},
+		{Type: "numbered_list_item", NumberedListItem: &ListItemBlock{RichText: text("One")}},
+		{Type: "numbered_list_item", NumberedListItem: &ListItemBlock{RichText: text("Two")}},
+		{Type: "code", Code: &CodeBlock{Language: "plain text", RichText: text("a\nb")}},
+		{Type: "image", Image: &ImageBlock{Type: "external", External: &FileURL{URL: "https://example.com/a.png"}, Caption: text("Diagram")}},
+	}
+
+	want := "## Plan\n\n" +
+		"Use **retry** `Do()`[ docs](https://example.com)\n\n" +
+		"- [x] Write tests\n" +
+		"  - [ ] Unit\n" +
+		"1. One\n" +
+		"2. Two\n" +
+		"```\na\nb\n```\n\n" +
+		"![Diagram](https://example.com/a.png)\n\n"
+	if got := BlocksToMarkdown(blocks); got != want {
+		t.Errorf("BlocksToMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+}
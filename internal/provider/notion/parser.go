@@ -2,6 +2,7 @@ package notion
 
 import (
 	"fmt"
+	"net/url"
 	"regexp"
 	"strings"
 
@@ -10,14 +11,38 @@ import (
 
 // Ref represents a parsed Notion reference.
 type Ref struct {
-	PageID     string // The 32-char page ID (UUID without dashes)
-	URL        string // The full URL if provided
-	DatabaseID string // Optional database ID for queries
-	IsExplicit bool   // true if explicitly formatted
+	PageID     string           // The 32-char page ID (UUID without dashes)
+	URL        string           // The full URL if provided
+	DatabaseID string           // Database queried for the page (db/ references)
+	Filters    []PropertyFilter // Property filters of a database query, all must match
+	IsExplicit bool             // true if explicitly formatted
+}
+
+// PropertyFilter selects database pages whose property has a value.
+type PropertyFilter struct {
+	Property string
+	Value    string
+}
+
+// IsDatabase reports whether the reference selects a page by database query.
+func (r *Ref) IsDatabase() bool {
+	return r.DatabaseID != ""
 }
 
 // String returns the canonical string representation.
 func (r *Ref) String() string {
+	if r.IsDatabase() {
+		s := "db/" + r.DatabaseID
+		for i, f := range r.Filters {
+			sep := "&"
+			if i == 0 {
+				sep = "?"
+			}
+			s += sep + "filter=" + url.QueryEscape(f.Property) + "=" + url.QueryEscape(f.Value)
+		}
+
+		return s
+	}
 	if r.URL != "" {
 		return r.URL
 	}
@@ -44,6 +69,9 @@ var (
 // Supported formats:
 //   - "notion:page-id"        -> page ID with scheme
 //   - "nt:page-id"            -> short scheme
+//   - "notion:page/page-id"   -> explicit page reference
+//   - "notion:db/database-id?filter=Status=Todo" -> first page of a database
+//     query; repeat filter to require several properties
 //   - "notion:https://www.notion.so/...title" -> URL with scheme
 //   - "https://www.notion.so/...title" -> URL
 //   - "a1b2c3d4e5f6..."       -> bare page ID (32-char hex)
@@ -76,8 +104,12 @@ func ParseReference(input string) (*Ref, error) {
 		}, nil
 	}
 
+	if rest, ok := strings.CutPrefix(schemeStripped, "db/"); ok {
+		return parseDatabaseReference(input, rest)
+	}
+
 	// Use scheme-stripped version for remaining checks
-	pageID := schemeStripped
+	pageID, explicit := strings.CutPrefix(schemeStripped, "page/")
 
 	// Check for UUID with dashes (convert to 32-char format)
 	if uuidWithDashes.MatchString(pageID) {
@@ -85,21 +117,52 @@ func ParseReference(input string) (*Ref, error) {
 
 		return &Ref{
 			PageID:     normalizedID,
-			IsExplicit: false,
+			IsExplicit: explicit,
 		}, nil
 	}
 
 	// Parse plain 32-char page ID format
 	if pageIDPattern.MatchString(pageID) {
 		return &Ref{
-			PageID:     pageID,
-			IsExplicit: false,
+			PageID:     strings.ToLower(pageID),
+			IsExplicit: explicit,
 		}, nil
 	}
 
 	return nil, fmt.Errorf("%w: unrecognized format: %s (expected 32-char page ID or Notion URL)", providererrors.ErrInvalidReference, input)
 }
 
+// parseDatabaseReference parses the part of a db/ reference after the
+// prefix: a database ID, optionally followed by filter query parameters.
+func parseDatabaseReference(input, rest string) (*Ref, error) {
+	id, query, _ := strings.Cut(rest, "?")
+	databaseID := NormalizePageID(id)
+	if databaseID == "" {
+		return nil, fmt.Errorf("%w: invalid database ID in %s", providererrors.ErrInvalidReference, input)
+	}
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid query in %s: %w", providererrors.ErrInvalidReference, input, err)
+	}
+
+	ref := &Ref{DatabaseID: databaseID, IsExplicit: true}
+	for key, list := range values {
+		if key != "filter" {
+			return nil, fmt.Errorf("%w: unknown parameter %q in %s (expected filter=Property=Value)", providererrors.ErrInvalidReference, key, input)
+		}
+		for _, f := range list {
+			property, value, ok := strings.Cut(f, "=")
+			if !ok || property == "" {
+				return nil, fmt.Errorf("%w: invalid filter %q in %s (expected Property=Value)", providererrors.ErrInvalidReference, f, input)
+			}
+			ref.Filters = append(ref.Filters, PropertyFilter{Property: property, Value: value})
+		}
+	}
+
+	return ref, nil
+}
+
 // ExtractPageID extracts the page ID from a Notion URL
 // Returns empty string if not a valid URL.
 func ExtractPageID(url string) string {
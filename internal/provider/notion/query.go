@@ -0,0 +1,96 @@
+package notion
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	providererrors "github.com/valksor/go-mehrhof/internal/provider/errors"
+)
+
+// resolvePage fetches the page a reference addresses. For a database
+// reference that is the oldest page matching its filters.
+func (p *Provider) resolvePage(ctx context.Context, ref *Ref) (*Page, error) {
+	if !ref.IsDatabase() {
+		return p.client.GetPage(ctx, ref.PageID)
+	}
+
+	req := &DatabaseQueryRequest{
+		Sorts:    []Sort{{Timestamp: "created_time", Direction: "ascending"}},
+		PageSize: 1,
+	}
+	if len(ref.Filters) > 0 {
+		database, err := p.client.GetDatabase(ctx, ref.DatabaseID)
+		if err != nil {
+			return nil, err
+		}
+		filters := make([]Filter, 0, len(ref.Filters))
+		for _, f := range ref.Filters {
+			filter, err := buildPropertyFilter(database, f)
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, filter)
+		}
+		if len(filters) == 1 {
+			req.Filter = &filters[0]
+		} else {
+			req.Filter = &Filter{And: filters}
+		}
+	}
+
+	response, err := p.client.QueryDatabase(ctx, ref.DatabaseID, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Results) == 0 {
+		return nil, fmt.Errorf("%w: no page in database %s matches %s", providererrors.ErrNotFound, ref.DatabaseID, ref)
+	}
+
+	return &response.Results[0], nil
+}
+
+// buildPropertyFilter builds the query filter for one property, using the
+// property's type from the database schema.
+func buildPropertyFilter(database *Database, f PropertyFilter) (Filter, error) {
+	var (
+		prop  DatabaseProperty
+		name  string
+		found bool
+	)
+	for key, candidate := range database.Properties {
+		if strings.EqualFold(key, f.Property) {
+			prop, name, found = candidate, key, true
+
+			break
+		}
+	}
+	if !found {
+		return Filter{}, fmt.Errorf("%w: database has no property %q", providererrors.ErrInvalidReference, f.Property)
+	}
+
+	filter := Filter{Property: name}
+	switch prop.Type {
+	case "status":
+		filter.Status = &StatusFilter{Equals: f.Value}
+	case "select":
+		filter.Select = &SelectFilter{Equals: f.Value}
+	case "multi_select":
+		filter.MultiSelect = &MultiSelectFilter{Contains: f.Value}
+	case "checkbox":
+		checked, err := strconv.ParseBool(f.Value)
+		if err != nil {
+			return Filter{}, fmt.Errorf("%w: property %q is a checkbox, expected true or false", providererrors.ErrInvalidReference, name)
+		}
+		filter.Checkbox = &CheckboxFilter{Equals: checked}
+	case "rich_text":
+		filter.RichText = &TextFilter{Equals: f.Value}
+	case "title":
+		filter.Title = &TextFilter{Equals: f.Value}
+	default:
+		return Filter{}, fmt.Errorf("%w: cannot filter on %s property %q", providererrors.ErrInvalidReference, prop.Type, name)
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,126 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+func TestParseDatabaseReference(t *testing.T) {
+	ref, err := ParseReference("notion:db/a1b2c3d4-e5f6-7890-1234-567890abcdef?filter=Status=Todo&filter=Team=Web%20App")
+	if err != nil {
+		t.Fatalf("ParseReference() error = %v", err)
+	}
+	if !ref.IsDatabase() || ref.DatabaseID != "a1b2c3d4e5f678901234567890abcdef" {
+		t.Fatalf("DatabaseID = %q, want normalized database ID", ref.DatabaseID)
+	}
+	want := []PropertyFilter{{Property: "Status", Value: "Todo"}, {Property: "Team", Value: "Web App"}}
+	if len(ref.Filters) != len(want) {
+		t.Fatalf("Filters = %+v, want %+v", ref.Filters, want)
+	}
+	for i := range want {
+		if ref.Filters[i] != want[i] {
+			t.Errorf("Filters[%d] = %+v, want %+v", i, ref.Filters[i], want[i])
+		}
+	}
+
+	// The canonical form parses back to the same reference
+	again, err := ParseReference(ref.String())
+	if err != nil {
+		t.Fatalf("ParseReference(%q) error = %v", ref.String(), err)
+	}
+	if again.String() != ref.String() {
+		t.Errorf("round trip = %q, want %q", again.String(), ref.String())
+	}
+
+	for _, input := range []string{
+		"notion:db/not-an-id",
+		"notion:db/a1b2c3d4e5f678901234567890abcdef?sort=Name",
+		"notion:db/a1b2c3d4e5f678901234567890abcdef?filter=Status",
+	} {
+		if _, err := ParseReference(input); err == nil {
+			t.Errorf("ParseReference(%q) succeeded, want error", input)
+		}
+	}
+
+	page, err := ParseReference("nt:page/a1b2c3d4e5f678901234567890abcdef")
+	if err != nil || page.PageID != "a1b2c3d4e5f678901234567890abcdef" || page.IsDatabase() {
+		t.Errorf("page reference = %+v, %v", page, err)
+	}
+}
+
+func TestResolvePageFromDatabase(t *testing.T) {
+	var query DatabaseQueryRequest
+	var updated UpdatePageInput
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/databases/"):
+			_, _ = w.Write([]byte(`{"id":"db","properties":{"Status":{"id":"s","name":"Status","type":"status","status":{"options":[]}},"Done":{"id":"d","name":"Done","type":"checkbox","checkbox":{}}}}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/query"):
+			_ = json.NewDecoder(r.Body).Decode(&query)
+			_, _ = w.Write([]byte(`{"results":[{"id":"11111111-2222-3333-4444-555555555555","properties":{"Status":{"id":"s","type":"status","status":{"name":"Todo"}}}}]}`))
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/pages/"):
+			_, _ = w.Write([]byte(`{"id":"11111111-2222-3333-4444-555555555555","properties":{"Status":{"id":"s","type":"status","status":{"name":"In Progress"}}}}`))
+		case r.Method == http.MethodPatch:
+			_ = json.NewDecoder(r.Body).Decode(&updated)
+			_, _ = w.Write([]byte(`{"id":"11111111-2222-3333-4444-555555555555"}`))
+		default:
+			_, _ = w.Write([]byte(`{"results":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+	p := &Provider{client: client, statusProperty: "Status", finishStatus: "Done"}
+
+	input := "notion:db/a1b2c3d4e5f678901234567890abcdef?filter=status=Todo&filter=Done=false"
+	id, err := p.Parse(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wu, err := p.Fetch(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if wu.ID != "11111111-2222-3333-4444-555555555555" {
+		t.Errorf("picked page %q", wu.ID)
+	}
+
+	if query.PageSize != 1 || query.Filter == nil || len(query.Filter.And) != 2 {
+		t.Fatalf("query = %+v, want one page matching both filters", query)
+	}
+	if f := query.Filter.And[0]; f.Property != "Status" || f.Status == nil || f.Status.Equals != "Todo" {
+		t.Errorf("status filter = %+v", f)
+	}
+	if f := query.Filter.And[1]; f.Checkbox == nil || f.Checkbox.Equals {
+		t.Errorf("checkbox filter = %+v", f)
+	}
+
+	pinned := p.PinReference(input, wu)
+	if pinned != "notion:page/11111111222233334444555555555555" {
+		t.Errorf("PinReference() = %q", pinned)
+	}
+	if got := p.PinReference("notion:a1b2c3d4e5f678901234567890abcdef", wu); got != "notion:a1b2c3d4e5f678901234567890abcdef" {
+		t.Errorf("PinReference() changed a page reference to %q", got)
+	}
+
+	pageID, err := p.Parse(pinned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.OnTaskFinished(context.Background(), pageID, provider.FinishInfo{}); err != nil {
+		t.Fatalf("OnTaskFinished() error = %v", err)
+	}
+	if prop := updated.Properties["Status"]; prop.Status == nil || prop.Status.Name != "Done" {
+		t.Errorf("finish update = %+v, want status Done", updated.Properties)
+	}
+
+	var _ provider.FinishHandler = p
+	var _ provider.ReferencePinner = p
+}
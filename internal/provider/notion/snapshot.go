@@ -17,19 +17,19 @@ func (p *Provider) Snapshot(ctx context.Context, id string) (*provider.Snapshot,
 	}
 
 	// Fetch the page
-	page, err := p.client.GetPage(ctx, ref.PageID)
+	page, err := p.resolvePage(ctx, ref)
 	if err != nil {
 		return nil, err
 	}
 
 	// Fetch page content blocks
-	blocks, err := p.client.GetPageContent(ctx, ref.PageID)
+	blocks, err := p.client.GetPageContent(ctx, page.ID)
 	if err != nil {
 		blocks = []Block{} // Continue without content
 	}
 
 	// Fetch comments
-	comments, _ := p.client.GetComments(ctx, ref.PageID)
+	comments, _ := p.client.GetComments(ctx, page.ID)
 
 	var content strings.Builder
 
@@ -164,9 +164,14 @@ type Block struct {
 	Quote            *QuoteBlock     `json:"quote,omitempty"`
 	ToDo             *ToDoBlock      `json:"to_do,omitempty"`
 	Code             *CodeBlock      `json:"code,omitempty"`
+	Toggle           *ListItemBlock  `json:"toggle,omitempty"`
+	Image            *ImageBlock     `json:"image,omitempty"`
+	Bookmark         *BookmarkBlock  `json:"bookmark,omitempty"`
 	Type             string          `json:"type"`
 	ID               string          `json:"id"`
 	HasOnly          bool            `json:"has_only"`
+	HasChildren      bool            `json:"has_children"`
+	Children         []Block         `json:"-"` // Nested blocks, filled by GetPageContent
 }
 
 // ParagraphBlock represents a paragraph block.
@@ -208,6 +213,26 @@ type QuoteBlock struct {
 	RichText []RichText `json:"rich_text"`
 }
 
+// ImageBlock represents an image block. Notion-hosted files (File) have
+// signed URLs that expire after an hour; External URLs do not.
+type ImageBlock struct {
+	External *FileURL   `json:"external,omitempty"`
+	File     *FileURL   `json:"file,omitempty"`
+	Type     string     `json:"type"`
+	Caption  []RichText `json:"caption"`
+}
+
+// FileURL is the location of a file.
+type FileURL struct {
+	URL string `json:"url"`
+}
+
+// BookmarkBlock represents a bookmark block.
+type BookmarkBlock struct {
+	URL     string     `json:"url"`
+	Caption []RichText `json:"caption"`
+}
+
 // DividerBlock represents a divider block.
 type DividerBlock struct {
 	Type string `json:"type"`
@@ -243,10 +268,18 @@ type CommentParent struct {
 
 // Database represents a Notion database.
 type Database struct {
-	Properties map[string]Property `json:"properties"`
-	Parent     Parent              `json:"parent"`
-	ID         string              `json:"id"`
-	Title      []RichText          `json:"title"`
+	Properties map[string]DatabaseProperty `json:"properties"`
+	Parent     Parent                      `json:"parent"`
+	ID         string                      `json:"id"`
+	Title      []RichText                  `json:"title"`
+}
+
+// DatabaseProperty describes a property in a database schema. Only its type
+// is decoded; the type-specific configuration differs from page values.
+type DatabaseProperty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
 }
 
 // DatabaseQueryRequest represents a database query request.
@@ -259,10 +292,13 @@ type DatabaseQueryRequest struct {
 
 // Filter represents a query filter.
 type Filter struct {
-	Property    string             `json:"property"`
+	Property    string             `json:"property,omitempty"`
 	Status      *StatusFilter      `json:"status,omitempty"`
 	Select      *SelectFilter      `json:"select,omitempty"`
 	MultiSelect *MultiSelectFilter `json:"multi_select,omitempty"`
+	Checkbox    *CheckboxFilter    `json:"checkbox,omitempty"`
+	RichText    *TextFilter        `json:"rich_text,omitempty"`
+	Title       *TextFilter        `json:"title,omitempty"`
 	And         []Filter           `json:"and,omitempty"`
 	Or          []Filter           `json:"or,omitempty"`
 }
@@ -291,9 +327,21 @@ type MultiSelectFilter struct {
 	IsNotEmpty     bool   `json:"is_not_empty,omitempty"`
 }
 
-// Sort represents a sort order.
+// CheckboxFilter filters by checkbox property.
+type CheckboxFilter struct {
+	Equals bool `json:"equals"`
+}
+
+// TextFilter filters by title or rich text property.
+type TextFilter struct {
+	Equals string `json:"equals"`
+}
+
+// Sort represents a sort order, by property or by timestamp
+// ("created_time" or "last_edited_time").
 type Sort struct {
-	Property  string `json:"property"`
+	Property  string `json:"property,omitempty"`
+	Timestamp string `json:"timestamp,omitempty"`
 	Direction string `json:"direction"` // "ascending" or "descending"
 }
 
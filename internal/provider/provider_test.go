@@ -794,3 +794,37 @@ func TestInferCapabilitiesMore(t *testing.T) {
 		t.Error("InferCapabilities(string) should return empty set")
 	}
 }
+
+func TestParseEstimate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "90m", want: 90 * time.Minute},
+		{input: "2h30m", want: 150 * time.Minute},
+		{input: "1.5h", want: 90 * time.Minute},
+		{input: "1d 4h", want: 12 * time.Hour},
+		{input: "1W", want: 40 * time.Hour},
+		{input: "", wantErr: true},
+		{input: "3", wantErr: true},
+		{input: "2 hours", wantErr: true},
+		{input: "h", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseEstimate(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidEstimate) {
+					t.Errorf("ParseEstimate(%q) error = %v, want ErrInvalidEstimate", tt.input, err)
+				}
+
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseEstimate(%q) = %v, %v, want %v", tt.input, got, err, tt.want)
+			}
+		})
+	}
+}
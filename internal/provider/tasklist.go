@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// ErrTaskListItemNotFound is returned when no task list item has the text to
+// update.
+var ErrTaskListItemNotFound = errors.New("task list item not found")
+
+// taskListLinePattern matches a markdown task list item, capturing the text
+// before the checkbox state, the state, and the item text.
+var taskListLinePattern = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+\[)([ xX])\]\s+(.+?)\s*$`)
+
+// TaskListItem is a markdown checkbox.
+type TaskListItem struct {
+	Text    string
+	Checked bool
+}
+
+// ParseTaskListItems returns the task list items of a markdown text in
+// document order.
+func ParseTaskListItems(markdown string) []TaskListItem {
+	var items []TaskListItem
+	for line := range strings.SplitSeq(markdown, "\n") {
+		if m := taskListLinePattern.FindStringSubmatch(strings.TrimRight(line, "\r")); m != nil {
+			items = append(items, TaskListItem{Text: m[3], Checked: m[2] != " "})
+		}
+	}
+
+	return items
+}
+
+// SetTaskListItem sets the checkbox of the first task list item whose text
+// matches text, ignoring differences in whitespace. It reports whether the
+// markdown changed.
+func SetTaskListItem(markdown, text string, checked bool) (string, bool, error) {
+	want := strings.Join(strings.Fields(text), " ")
+	state := " "
+	if checked {
+		state = "x"
+	}
+
+	lines := strings.Split(markdown, "\n")
+	for i, line := range lines {
+		m := taskListLinePattern.FindStringSubmatchIndex(strings.TrimRight(line, "\r"))
+		if m == nil || strings.Join(strings.Fields(line[m[6]:m[7]]), " ") != want {
+			continue
+		}
+		if (line[m[4]:m[5]] != " ") == checked {
+			return markdown, false, nil
+		}
+		lines[i] = line[:m[4]] + state + line[m[5]:]
+
+		return strings.Join(lines, "\n"), true, nil
+	}
+
+	return markdown, false, ErrTaskListItemNotFound
+}
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTaskListItems(t *testing.T) {
+	body := "Intro\n- [ ] Add login form\r\n  * [x] Validate  input \n1. [X] Numbered\n- [] not an item\n- plain\n"
+
+	got := ParseTaskListItems(body)
+	want := []TaskListItem{
+		{Text: "Add login form"},
+		{Text: "Validate  input", Checked: true},
+		{Text: "Numbered", Checked: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseTaskListItems() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("item %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSetTaskListItem(t *testing.T) {
+	body := "## Tasks\r\n- [ ] Add login form\r\n- [x] Validate input\r\n"
+
+	got, changed, err := SetTaskListItem(body, "Add  login form", true)
+	if err != nil || !changed {
+		t.Fatalf("SetTaskListItem() = %v, %v", changed, err)
+	}
+	if want := "## Tasks\r\n- [x] Add login form\r\n- [x] Validate input\r\n"; got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+
+	if _, changed, err := SetTaskListItem(got, "Validate input", true); err != nil || changed {
+		t.Errorf("checking a checked item = %v, %v, want no change", changed, err)
+	}
+
+	got, changed, err = SetTaskListItem(got, "Validate input", false)
+	if err != nil || !changed || got != "## Tasks\r\n- [x] Add login form\r\n- [ ] Validate input\r\n" {
+		t.Errorf("unchecking = %q, %v, %v", got, changed, err)
+	}
+
+	if _, _, err := SetTaskListItem(body, "Missing", true); !errors.Is(err, ErrTaskListItemNotFound) {
+		t.Errorf("missing item error = %v, want ErrTaskListItemNotFound", err)
+	}
+}
@@ -0,0 +1,254 @@
+// Package tasktemplate provides the template: provider for recurring
+// operational work. A task template lives in .mehrhof/templates/<name>.yaml and
+// defines the source content, scope, workflow variant and schedule of a chore.
+// Every start creates a new task, so each run is an auditable work unit.
+package tasktemplate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/valksor/go-mehrhof/internal/config"
+	"github.com/valksor/go-mehrhof/internal/naming"
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+// ProviderName is the registered name for this provider.
+const ProviderName = "template"
+
+// DefaultDir returns the templates directory relative to the project root.
+func DefaultDir() string {
+	return filepath.Join(filepath.FromSlash(config.TaskDir()), "templates")
+}
+
+// ErrTemplateNotFound is returned when no template file exists for a name.
+var ErrTemplateNotFound = errors.New("task template not found")
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// Template defines a recurring task.
+type Template struct {
+	Name        string   `yaml:"-"`
+	Title       string   `yaml:"title,omitempty"`
+	Description string   `yaml:"description,omitempty"` // Short summary for listings
+	Type        string   `yaml:"type,omitempty"`        // Task type for naming (default: chore)
+	Key         string   `yaml:"key,omitempty"`         // External key prefix (default: template name)
+	Labels      []string `yaml:"labels,omitempty"`
+	Agent       string   `yaml:"agent,omitempty"`
+	Scope       string   `yaml:"scope,omitempty"`    // Repo-relative subdirectory the task is restricted to
+	Schedule    string   `yaml:"schedule,omitempty"` // Cron expression for external schedulers
+	Workflow    Workflow `yaml:"workflow,omitempty"`
+	Content     string   `yaml:"content"` // Task source (markdown)
+}
+
+// Workflow selects the workflow variant used for runs of a template.
+type Workflow struct {
+	NoBranch    bool `yaml:"no_branch,omitempty"`    // Work on the current branch
+	Worktree    bool `yaml:"worktree,omitempty"`     // Run in a separate git worktree
+	SkipQuality bool `yaml:"skip_quality,omitempty"` // Skip quality checks in mehr auto
+}
+
+// Path returns the file path of a named template in dir.
+func Path(dir, name string) string {
+	return filepath.Join(dir, name+".yaml")
+}
+
+// Load reads and validates a named template from dir.
+func Load(dir, name string) (*Template, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid template name %q", name)
+	}
+
+	data, err := os.ReadFile(Path(dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+		}
+
+		return nil, fmt.Errorf("read template: %w", err)
+	}
+
+	var tpl Template
+	if err := yaml.Unmarshal(data, &tpl); err != nil {
+		return nil, fmt.Errorf("parse template %s: %w", name, err)
+	}
+	tpl.Name = name
+
+	if strings.TrimSpace(tpl.Content) == "" {
+		return nil, fmt.Errorf("template %s has no content", name)
+	}
+	if tpl.Title == "" {
+		tpl.Title = titleFromContent(tpl.Content, name)
+	}
+	if tpl.Type == "" {
+		tpl.Type = "chore"
+	}
+
+	return &tpl, nil
+}
+
+// List loads all templates in dir, sorted by name. A missing directory is not an error.
+func List(dir string) ([]*Template, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("read templates directory: %w", err)
+	}
+
+	var templates []*Template
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".yaml")
+		if entry.IsDir() || !ok {
+			continue
+		}
+		tpl, err := Load(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, tpl)
+	}
+	slices.SortFunc(templates, func(a, b *Template) int { return strings.Compare(a.Name, b.Name) })
+
+	return templates, nil
+}
+
+// RunKey returns the external key for a run started at the given time, so
+// branches and commits of separate runs do not collide.
+func (t *Template) RunKey(at time.Time) string {
+	key := t.Key
+	if key == "" {
+		key = t.Name
+	}
+
+	return key + "-" + at.Format("20060102-150405")
+}
+
+func titleFromContent(content, fallback string) string {
+	for line := range strings.Lines(content) {
+		if title, ok := strings.CutPrefix(strings.TrimSpace(line), "# "); ok {
+			return title
+		}
+	}
+
+	return fallback
+}
+
+// Provider starts tasks from project task templates.
+type Provider struct {
+	dir string
+	now func() time.Time
+}
+
+// Info returns provider metadata.
+func Info() provider.ProviderInfo {
+	return provider.ProviderInfo{
+		Name:        ProviderName,
+		Description: "Recurring task templates",
+		Schemes:     []string{"template"},
+		Priority:    10,
+		Capabilities: provider.CapabilitySet{
+			provider.CapRead: true,
+		},
+	}
+}
+
+// New creates a template provider.
+func New(ctx context.Context, cfg provider.Config) (any, error) {
+	dir := cfg.GetString("templates_dir")
+	if dir == "" {
+		dir = DefaultDir()
+	}
+
+	return &Provider{dir: dir, now: time.Now}, nil
+}
+
+// Match checks if input has the template: scheme prefix.
+func (p *Provider) Match(input string) bool {
+	return strings.HasPrefix(input, "template:")
+}
+
+// Parse extracts the template name from input and verifies it exists.
+func (p *Provider) Parse(input string) (string, error) {
+	name := strings.TrimPrefix(input, "template:")
+	if _, err := Load(p.dir, name); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// Fetch renders a template into a work unit for a new run.
+func (p *Provider) Fetch(ctx context.Context, id string) (*provider.WorkUnit, error) {
+	tpl, err := Load(p.dir, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := p.now()
+	wu := &provider.WorkUnit{
+		ID:          id,
+		ExternalID:  Path(p.dir, id),
+		Provider:    ProviderName,
+		Title:       tpl.Title,
+		Description: tpl.Content,
+		Status:      provider.StatusOpen,
+		Priority:    provider.PriorityNormal,
+		Labels:      append([]string{}, tpl.Labels...),
+		Metadata: map[string]any{
+			"template": tpl.Name,
+			"run_at":   now,
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+		Source: provider.SourceInfo{
+			Type:      ProviderName,
+			Reference: "template:" + id,
+			SyncedAt:  now,
+		},
+		ExternalKey: tpl.RunKey(now),
+		TaskType:    tpl.Type,
+		Slug:        naming.Slugify(tpl.Title, 50),
+	}
+	if tpl.Scope != "" {
+		wu.Metadata["scope"] = tpl.Scope
+	}
+	if tpl.Schedule != "" {
+		wu.Metadata["schedule"] = tpl.Schedule
+	}
+	if tpl.Agent != "" {
+		wu.AgentConfig = &provider.AgentConfig{Name: tpl.Agent}
+	}
+
+	return wu, nil
+}
+
+// Snapshot captures the template content as the task source.
+func (p *Provider) Snapshot(ctx context.Context, id string) (*provider.Snapshot, error) {
+	tpl, err := Load(p.dir, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &provider.Snapshot{
+		Type:    ProviderName,
+		Ref:     "template:" + id,
+		Content: tpl.Content,
+	}, nil
+}
+
+// Register adds the template provider to registry.
+func Register(r *provider.Registry) {
+	_ = r.Register(Info(), New)
+}
@@ -0,0 +1,191 @@
+package tasktemplate
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+const rotateSecrets = `title: Rotate service secrets
+description: Monthly credential rotation
+key: OPS
+labels: [ops]
+agent: claude
+scope: services/api
+schedule: "0 9 1 * *"
+workflow:
+  no_branch: true
+  skip_quality: true
+content: |
+  # Rotate secrets
+
+  Rotate the API signing keys.
+`
+
+func writeTemplate(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(Path(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+func newProvider(t *testing.T, dir string, now time.Time) *Provider {
+	t.Helper()
+
+	p, err := New(context.Background(), provider.NewConfig().Set("templates_dir", dir))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	prov := p.(*Provider)
+	prov.now = func() time.Time { return now }
+
+	return prov
+}
+
+func TestInfo(t *testing.T) {
+	info := Info()
+
+	if info.Name != ProviderName {
+		t.Errorf("Name = %q, want %q", info.Name, ProviderName)
+	}
+	if len(info.Schemes) == 0 || info.Schemes[0] != "template" {
+		t.Errorf("Schemes = %v, want [template]", info.Schemes)
+	}
+	if !info.Capabilities[provider.CapRead] {
+		t.Error("should have read capability")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "rotate-secrets", rotateSecrets)
+	writeTemplate(t, dir, "minimal", "content: |\n  # Clean caches\n")
+	writeTemplate(t, dir, "empty", "title: Nothing\n")
+
+	tpl, err := Load(dir, "rotate-secrets")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if tpl.Name != "rotate-secrets" || tpl.Title != "Rotate service secrets" || tpl.Scope != "services/api" {
+		t.Errorf("Load() = %+v", tpl)
+	}
+	if !tpl.Workflow.NoBranch || !tpl.Workflow.SkipQuality || tpl.Workflow.Worktree {
+		t.Errorf("Workflow = %+v", tpl.Workflow)
+	}
+
+	minimal, err := Load(dir, "minimal")
+	if err != nil {
+		t.Fatalf("Load minimal: %v", err)
+	}
+	if minimal.Title != "Clean caches" || minimal.Type != "chore" {
+		t.Errorf("defaults: Title = %q, Type = %q", minimal.Title, minimal.Type)
+	}
+
+	if _, err := Load(dir, "empty"); err == nil {
+		t.Error("Load should reject templates without content")
+	}
+	if _, err := Load(dir, "missing"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("Load missing = %v, want ErrTemplateNotFound", err)
+	}
+	if _, err := Load(dir, "../escape"); err == nil {
+		t.Error("Load should reject names with path separators")
+	}
+}
+
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+
+	templates, err := List(filepath.Join(dir, "missing"))
+	if err != nil || len(templates) != 0 {
+		t.Fatalf("List missing dir = %v, %v", templates, err)
+	}
+
+	writeTemplate(t, dir, "rotate-secrets", rotateSecrets)
+	writeTemplate(t, dir, "bump-deps", "content: |\n  # Bump dependencies\n")
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	templates, err = List(dir)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(templates) != 2 || templates[0].Name != "bump-deps" || templates[1].Name != "rotate-secrets" {
+		t.Errorf("List() returned %d templates", len(templates))
+	}
+}
+
+func TestFetch(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "rotate-secrets", rotateSecrets)
+
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	p := newProvider(t, dir, now)
+
+	if !p.Match("template:rotate-secrets") || p.Match("file:task.md") {
+		t.Error("Match should only accept template: references")
+	}
+
+	id, err := p.Parse("template:rotate-secrets")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if _, err := p.Parse("template:missing"); err == nil {
+		t.Error("Parse should fail for unknown templates")
+	}
+
+	wu, err := p.Fetch(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if wu.Title != "Rotate service secrets" || wu.TaskType != "chore" {
+		t.Errorf("Title = %q, TaskType = %q", wu.Title, wu.TaskType)
+	}
+	if wu.ExternalKey != "OPS-20260301-090000" {
+		t.Errorf("ExternalKey = %q, want OPS-20260301-090000", wu.ExternalKey)
+	}
+	if wu.Metadata["template"] != "rotate-secrets" || wu.Metadata["schedule"] != "0 9 1 * *" || wu.Metadata["scope"] != "services/api" {
+		t.Errorf("Metadata = %v", wu.Metadata)
+	}
+	if wu.AgentConfig == nil || wu.AgentConfig.Name != "claude" {
+		t.Errorf("AgentConfig = %+v", wu.AgentConfig)
+	}
+
+	snapshot, err := p.Snapshot(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if snapshot.Ref != "template:rotate-secrets" || snapshot.Content != "# Rotate secrets\n\nRotate the API signing keys.\n" {
+		t.Errorf("Snapshot = %+v", snapshot)
+	}
+}
+
+func TestRunKey(t *testing.T) {
+	at := time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)
+
+	if got := (&Template{Name: "bump-deps"}).RunKey(at); got != "bump-deps-20261015-093000" {
+		t.Errorf("RunKey() = %q, want bump-deps-20261015-093000", got)
+	}
+	if got := (&Template{Name: "bump-deps", Key: "OPS"}).RunKey(at); got != "OPS-20261015-093000" {
+		t.Errorf("RunKey() = %q, want OPS-20261015-093000", got)
+	}
+}
+
+func TestRunKey_SameDay(t *testing.T) {
+	tpl := &Template{Name: "bump-deps"}
+	morning := time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)
+	afternoon := time.Date(2026, 10, 15, 14, 5, 12, 0, time.UTC)
+
+	if tpl.RunKey(morning) == tpl.RunKey(afternoon) {
+		t.Errorf("runs on the same day share the key %q", tpl.RunKey(morning))
+	}
+}
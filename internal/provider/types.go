@@ -17,6 +17,7 @@ type WorkUnit struct {
 	Attachments []Attachment
 	Subtasks    []string
 	Metadata    map[string]any
+	Estimate    time.Duration // Planned effort, zero when not estimated
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	Source      SourceInfo
@@ -131,6 +132,9 @@ const (
 	CapLinkBranch         Capability = "link_branch"
 	CapCreateWorkUnit     Capability = "create_work_unit"
 	CapFetchSubtasks      Capability = "fetch_subtasks"
+	CapUpdateTaskList     Capability = "update_task_list"
+	CapBranchProtection   Capability = "branch_protection"
+	CapLogTime            Capability = "log_time"
 )
 
 // CapabilitySet is a set of capabilities.
@@ -181,6 +185,15 @@ func InferCapabilities(p any) CapabilitySet {
 	if _, ok := p.(SubtaskFetcher); ok {
 		caps[CapFetchSubtasks] = true
 	}
+	if _, ok := p.(TaskListUpdater); ok {
+		caps[CapUpdateTaskList] = true
+	}
+	if _, ok := p.(ProtectionReader); ok {
+		caps[CapBranchProtection] = true
+	}
+	if _, ok := p.(TimeLogger); ok {
+		caps[CapLogTime] = true
+	}
 
 	return caps
 }
@@ -0,0 +1,158 @@
+package webpage
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Readability-style content extraction: drop page chrome, score the
+// remaining containers by the paragraphs they hold, and keep the best one.
+
+// ignoredTags never contribute readable content.
+var ignoredTags = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "iframe": true, "svg": true,
+	"canvas": true, "form": true, "button": true, "input": true, "select": true, "textarea": true,
+	"nav": true, "aside": true, "footer": true, "header": true, "head": true, "object": true,
+}
+
+var (
+	// unlikelyPattern matches class/id values of page chrome.
+	unlikelyPattern = regexp.MustCompile(`(?i)banner|breadcrumb|comment|cookie|disqus|footer|menu|modal|navbar|newsletter|popup|promo|related|share|sidebar|social|sponsor|subscribe|toolbar`)
+
+	// positivePattern rescues chrome-looking containers that hold content.
+	positivePattern = regexp.MustCompile(`(?i)article|body|content|entry|main|post|story|text`)
+)
+
+// minParagraphLength is the shortest text that counts as a paragraph.
+const minParagraphLength = 25
+
+// article is the readable part of a page.
+type article struct {
+	Title   string
+	Content *node
+}
+
+// extractArticle finds the page title and main content of a document.
+func extractArticle(doc *node) article {
+	title := pageTitle(doc)
+	prune(doc)
+
+	return article{Title: title, Content: mainContent(doc)}
+}
+
+// pageTitle prefers the Open Graph title, then <title>, then the first <h1>.
+func pageTitle(doc *node) string {
+	og := find(doc, func(n *node) bool {
+		return n.tag == "meta" && (n.attrs["property"] == "og:title" || n.attrs["name"] == "og:title")
+	})
+	if og != nil && strings.TrimSpace(og.attrs["content"]) != "" {
+		return collapseSpace(og.attrs["content"])
+	}
+	for _, tag := range []string{"title", "h1"} {
+		if n := find(doc, byTag(tag)); n != nil {
+			if title := collapseSpace(textContent(n)); title != "" {
+				return title
+			}
+		}
+	}
+
+	return ""
+}
+
+// prune removes non-content elements from the tree.
+func prune(n *node) {
+	kept := n.children[:0]
+	for _, c := range n.children {
+		if c.tag != "" && (ignoredTags[c.tag] || unlikely(c)) {
+			continue
+		}
+		prune(c)
+		kept = append(kept, c)
+	}
+	n.children = kept
+}
+
+// unlikely reports whether an element looks like page chrome.
+func unlikely(n *node) bool {
+	switch n.tag {
+	case "html", "body", "article", "main":
+		return false
+	}
+	if n.attrs["role"] == "navigation" || n.attrs["role"] == "complementary" || n.attrs["aria-hidden"] == "true" {
+		return true
+	}
+	hint := n.attrs["class"] + " " + n.attrs["id"]
+
+	return unlikelyPattern.MatchString(hint) && !positivePattern.MatchString(hint)
+}
+
+// mainContent returns the element holding the page's readable content: a
+// single <article> or <main> if the page marks one, otherwise the container
+// whose paragraphs score best.
+func mainContent(doc *node) *node {
+	for _, tag := range []string{"article", "main"} {
+		if found := findAll(doc, byTag(tag)); len(found) == 1 && len(collapseSpace(textContent(found[0]))) >= minParagraphLength {
+			return found[0]
+		}
+	}
+	if n := find(doc, func(n *node) bool { return n.attrs["role"] == "main" }); n != nil {
+		return n
+	}
+
+	scores := make(map[*node]float64)
+	var candidates []*node // in document order, so ties resolve stably
+	addScore := func(n *node, score float64) {
+		if _, ok := scores[n]; !ok {
+			candidates = append(candidates, n)
+		}
+		scores[n] += score
+	}
+	for _, para := range findAll(doc, func(n *node) bool { return n.tag == "p" || n.tag == "pre" || n.tag == "td" }) {
+		text := collapseSpace(textContent(para))
+		if len(text) < minParagraphLength {
+			continue
+		}
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+		if para.parent != nil {
+			addScore(para.parent, score)
+			if para.parent.parent != nil {
+				addScore(para.parent.parent, score/2)
+			}
+		}
+	}
+
+	var best *node
+	bestScore := 0.0
+	for _, n := range candidates {
+		if score := scores[n] * (1 - linkDensity(n)); score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	if best != nil && best.tag != "#document" {
+		return best
+	}
+	if body := find(doc, byTag("body")); body != nil {
+		return body
+	}
+
+	return doc
+}
+
+// linkDensity is the share of an element's text that sits inside links.
+func linkDensity(n *node) float64 {
+	total := len(collapseSpace(textContent(n)))
+	if total == 0 {
+		return 0
+	}
+	linked := 0
+	for _, a := range findAll(n, byTag("a")) {
+		linked += len(collapseSpace(textContent(a)))
+	}
+
+	return float64(linked) / float64(total)
+}
+
+// collapseSpace trims s and collapses whitespace runs to single spaces.
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,103 @@
+package webpage
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const articlePage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>Ignored &amp; replaced</title>
+  <meta property="og:title" content="Add rate limiting">
+  <style>body { color: red; }</style>
+  <script>if (a < b) { document.write("<p>no</p>"); }</script>
+</head>
+<body>
+  <nav class="navbar"><a href="/">Home</a> <a href="/docs">Docs</a></nav>
+  <div class="sidebar"><p>Sign up for our newsletter, it is great, really.</p></div>
+  <div id="content">
+    <h2>Goal</h2>
+    <p>Requests to the <code>/api</code> endpoints should be limited per client, using a token bucket.
+    <p>See the <a href="../design/limits.html">design notes</a>, and keep <strong> existing </strong> behaviour.</p>
+    <ul>
+      <li>Limit per API key
+      <li>Return <em>429</em> with Retry-After
+        <ol><li>Header in seconds</li><li>Body in JSON</li></ol>
+    </ul>
+    <pre><code class="language-go">limiter := rate.NewLimiter(10, 20)
+if !limiter.Allow() {
+	return
+}</code></pre>
+    <blockquote><p>Never block health checks.</p></blockquote>
+    <table>
+      <tr><th>Tier</th><th>Rate</th></tr>
+      <tr><td>Free</td><td>10/s</td></tr>
+    </table>
+    <p><img src="/img/flow.png" alt="Request flow"><br>Figure 1</p>
+  </div>
+  <footer><p>Copyright 2026, Example Corp, all rights reserved.</p></footer>
+</body>
+</html>`
+
+func TestExtractArticle(t *testing.T) {
+	base, _ := url.Parse("https://example.com/specs/rate-limits.html")
+	art := extractArticle(parseHTML(articlePage))
+
+	if art.Title != "Add rate limiting" {
+		t.Errorf("Title = %q", art.Title)
+	}
+
+	got := toMarkdown(art.Content, base)
+	want := "## Goal\n\n" +
+		"Requests to the `/api` endpoints should be limited per client, using a token bucket.\n\n" +
+		"See the [design notes](https://example.com/design/limits.html), and keep **existing** behaviour.\n\n" +
+		"- Limit per API key\n" +
+		"- Return _429_ with Retry-After\n" +
+		"  1. Header in seconds\n" +
+		"  2. Body in JSON\n\n" +
+		"```go\nlimiter := rate.NewLimiter(10, 20)\nif !limiter.Allow() {\n\treturn\n}\n```\n\n" +
+		"> Never block health checks.\n\n" +
+		"| Tier | Rate |\n| --- | --- |\n| Free | 10/s |\n\n" +
+		"![Request flow](https://example.com/img/flow.png)\nFigure 1\n"
+	if got != want {
+		t.Errorf("toMarkdown() =\n%s\nwant:\n%s", got, want)
+	}
+
+	for _, chrome := range []string{"newsletter", "Copyright", "Home", "document.write"} {
+		if strings.Contains(got, chrome) {
+			t.Errorf("markdown contains page chrome %q", chrome)
+		}
+	}
+}
+
+func TestExtractArticle_PrefersArticleElement(t *testing.T) {
+	doc := parseHTML(`<html><head><title>Release notes</title></head><body>
+		<div><p>Short teaser paragraph that is long enough, with commas, to score.</p></div>
+		<article><h1>Release 2.0</h1><p>Adds the url provider.</p></article>
+	</body></html>`)
+	art := extractArticle(doc)
+
+	if art.Title != "Release notes" {
+		t.Errorf("Title = %q", art.Title)
+	}
+	if got := toMarkdown(art.Content, nil); got != "# Release 2.0\n\nAdds the url provider.\n" {
+		t.Errorf("toMarkdown() = %q", got)
+	}
+}
+
+func TestParseHTML_Lenient(t *testing.T) {
+	doc := parseHTML(`<p>one<p>two</b> &lt;three&gt; <div>four</div> 1 < 2`)
+
+	var texts []string
+	for _, p := range findAll(doc, byTag("p")) {
+		texts = append(texts, collapseSpace(textContent(p)))
+	}
+	if strings.Join(texts, "|") != "one|two <three>" {
+		t.Errorf("paragraphs = %q", texts)
+	}
+	if got := collapseSpace(textContent(doc)); got != "onetwo <three> four 1 < 2" {
+		t.Errorf("text = %q", got)
+	}
+}
@@ -0,0 +1,134 @@
+package webpage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	providererrors "github.com/valksor/go-mehrhof/internal/provider/errors"
+	"github.com/valksor/go-mehrhof/internal/provider/httpclient"
+)
+
+// maxPageSize caps how much of a response body is read.
+const maxPageSize = 10 << 20
+
+// ErrUnsupportedContent is returned for responses that are not HTML or text.
+var ErrUnsupportedContent = errors.New("unsupported content type")
+
+// page is a fetched web page converted to markdown.
+type page struct {
+	URL          string // Final URL, after redirects
+	Title        string
+	Markdown     string
+	ETag         string
+	LastModified time.Time // zero when the server does not send it
+	NotModified  bool      // conditional request matched; other fields are unset
+}
+
+// validators are the cache validators of a previous fetch.
+type validators struct {
+	ETag         string
+	LastModified time.Time
+}
+
+// fetchPage downloads rawURL and extracts its readable content. With
+// validators the request is conditional, and an unchanged page comes back
+// with NotModified set.
+func (p *Provider) fetchPage(ctx context.Context, rawURL string, prev *validators) (*page, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", p.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9,*/*;q=0.5")
+	if prev != nil {
+		if prev.ETag != "" {
+			req.Header.Set("If-None-Match", prev.ETag)
+		}
+		if !prev.LastModified.IsZero() {
+			req.Header.Set("If-Modified-Since", prev.LastModified.UTC().Format(http.TimeFormat))
+		}
+	}
+
+	var pg *page
+	err = httpclient.WithRetry(ctx, httpclient.DefaultRetryConfig(), func() error {
+		resp, err := p.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("%w: %w", providererrors.ErrNetworkError, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode == http.StatusNotModified {
+			pg = &page{URL: rawURL, NotModified: true}
+
+			return nil
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return httpclient.NewHTTPError(resp.StatusCode, resp.Status)
+		}
+
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+		if err != nil {
+			return fmt.Errorf("read response: %w", err)
+		}
+		pg, err = parsePage(resp, string(body))
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pg, nil
+}
+
+// parsePage converts a response body to a page according to its content type.
+func parsePage(resp *http.Response, body string) (*page, error) {
+	pageURL := resp.Request.URL
+	pg := &page{
+		URL:  pageURL.String(),
+		ETag: resp.Header.Get("ETag"),
+	}
+	if lm, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		pg.LastModified = lm
+	}
+
+	mediaType := "text/html"
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		if mt, _, err := mime.ParseMediaType(ct); err == nil {
+			mediaType = mt
+		}
+	}
+
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		art := extractArticle(parseHTML(body))
+		pg.Title = art.Title
+		pg.Markdown = toMarkdown(art.Content, pageURL)
+	case strings.HasPrefix(mediaType, "text/"):
+		pg.Markdown = body
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedContent, mediaType)
+	}
+	if pg.Title == "" {
+		pg.Title = fallbackTitle(pageURL)
+	}
+
+	return pg, nil
+}
+
+// fallbackTitle names a page without a title after its URL.
+func fallbackTitle(u *url.URL) string {
+	if name := path.Base(strings.TrimSuffix(u.Path, "/")); name != "." && name != "/" && name != "" {
+		return name
+	}
+
+	return u.Host
+}
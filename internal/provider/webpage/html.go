@@ -0,0 +1,337 @@
+package webpage
+
+import (
+	"html"
+	"slices"
+	"strings"
+)
+
+// node is an element or text node of a parsed HTML document. The parser is
+// lenient rather than spec-complete: it keeps enough structure to find the
+// readable part of a page and render it as markdown.
+type node struct {
+	tag      string // lowercase element name, "" for text nodes
+	attrs    map[string]string
+	text     string // text nodes only, entities decoded
+	parent   *node
+	children []*node
+}
+
+// rawTextTags hold unparsed text up to their closing tag.
+var rawTextTags = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true, "noscript": true, "template": true,
+}
+
+// voidTags never have content or a closing tag.
+var voidTags = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// paragraphClosers implicitly end an open <p>.
+var paragraphClosers = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "div": true, "dl": true,
+	"fieldset": true, "figure": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "hr": true, "main": true, "nav": true, "ol": true,
+	"p": true, "pre": true, "section": true, "table": true, "ul": true,
+}
+
+// impliedEnds lists, per tag, the open elements a new start tag closes and
+// the container that bounds the search (e.g. a new <li> closes the previous
+// item of the same list, but not one of an outer list).
+var impliedEnds = map[string]struct {
+	closes []string
+	scope  []string
+}{
+	"li":     {closes: []string{"li"}, scope: []string{"ul", "ol"}},
+	"dt":     {closes: []string{"dt", "dd"}, scope: []string{"dl"}},
+	"dd":     {closes: []string{"dt", "dd"}, scope: []string{"dl"}},
+	"tr":     {closes: []string{"tr", "td", "th"}, scope: []string{"table", "thead", "tbody", "tfoot"}},
+	"td":     {closes: []string{"td", "th"}, scope: []string{"tr", "table"}},
+	"th":     {closes: []string{"td", "th"}, scope: []string{"tr", "table"}},
+	"option": {closes: []string{"option"}, scope: []string{"select"}},
+}
+
+// parseHTML parses an HTML document into a node tree rooted at a synthetic
+// document node.
+func parseHTML(src string) *node {
+	p := &htmlParser{src: src}
+	p.root = &node{tag: "#document"}
+	p.stack = []*node{p.root}
+	p.parse()
+
+	return p.root
+}
+
+type htmlParser struct {
+	src   string
+	pos   int
+	root  *node
+	stack []*node
+}
+
+func (p *htmlParser) current() *node {
+	return p.stack[len(p.stack)-1]
+}
+
+func (p *htmlParser) parse() {
+	for p.pos < len(p.src) {
+		lt := strings.IndexByte(p.src[p.pos:], '<')
+		if lt < 0 {
+			p.addText(p.src[p.pos:])
+
+			return
+		}
+		if lt > 0 {
+			p.addText(p.src[p.pos : p.pos+lt])
+			p.pos += lt
+		}
+
+		rest := p.src[p.pos:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			p.skipPast("-->")
+		case strings.HasPrefix(rest, "<!"), strings.HasPrefix(rest, "<?"):
+			p.skipPast(">")
+		case strings.HasPrefix(rest, "</"):
+			p.parseEndTag()
+		case len(rest) > 1 && isLetter(rest[1]):
+			p.parseStartTag()
+		default:
+			p.addText("<")
+			p.pos++
+		}
+	}
+}
+
+func (p *htmlParser) skipPast(marker string) {
+	end := strings.Index(p.src[p.pos:], marker)
+	if end < 0 {
+		p.pos = len(p.src)
+
+		return
+	}
+	p.pos += end + len(marker)
+}
+
+func (p *htmlParser) addText(raw string) {
+	if raw == "" {
+		return
+	}
+	parent := p.current()
+	text := html.UnescapeString(raw)
+	// Merge with a preceding text node so entity-split text stays whole
+	if n := len(parent.children); n > 0 && parent.children[n-1].tag == "" {
+		parent.children[n-1].text += text
+
+		return
+	}
+	parent.children = append(parent.children, &node{text: text, parent: parent})
+}
+
+func (p *htmlParser) parseEndTag() {
+	end := strings.IndexByte(p.src[p.pos:], '>')
+	if end < 0 {
+		p.pos = len(p.src)
+
+		return
+	}
+	name := strings.ToLower(strings.TrimSpace(p.src[p.pos+2 : p.pos+end]))
+	p.pos += end + 1
+	if i := strings.IndexAny(name, " \t\r\n"); i >= 0 {
+		name = name[:i]
+	}
+
+	// Pop to the matching open element; stray end tags are ignored
+	for i := len(p.stack) - 1; i > 0; i-- {
+		if p.stack[i].tag == name {
+			p.stack = p.stack[:i]
+
+			return
+		}
+	}
+}
+
+func (p *htmlParser) parseStartTag() {
+	i := p.pos + 1
+	for i < len(p.src) && !isSpace(p.src[i]) && p.src[i] != '>' && p.src[i] != '/' {
+		i++
+	}
+	n := &node{tag: strings.ToLower(p.src[p.pos+1 : i]), attrs: map[string]string{}}
+
+	selfClosing := false
+	for i < len(p.src) {
+		for i < len(p.src) && isSpace(p.src[i]) {
+			i++
+		}
+		if i >= len(p.src) {
+			break
+		}
+		if p.src[i] == '>' {
+			i++
+
+			break
+		}
+		if p.src[i] == '/' {
+			selfClosing = true
+			i++
+
+			continue
+		}
+
+		start := i
+		for i < len(p.src) && !isSpace(p.src[i]) && p.src[i] != '=' && p.src[i] != '>' && p.src[i] != '/' {
+			i++
+		}
+		name := strings.ToLower(p.src[start:i])
+		value := ""
+		for i < len(p.src) && isSpace(p.src[i]) {
+			i++
+		}
+		if i < len(p.src) && p.src[i] == '=' {
+			i++
+			for i < len(p.src) && isSpace(p.src[i]) {
+				i++
+			}
+			if i < len(p.src) && (p.src[i] == '"' || p.src[i] == '\'') {
+				quote := p.src[i]
+				end := strings.IndexByte(p.src[i+1:], quote)
+				if end < 0 {
+					end = len(p.src) - i - 1
+				}
+				value = p.src[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				start := i
+				for i < len(p.src) && !isSpace(p.src[i]) && p.src[i] != '>' {
+					i++
+				}
+				value = p.src[start:i]
+			}
+		}
+		if name != "" {
+			n.attrs[name] = html.UnescapeString(value)
+		}
+	}
+	p.pos = min(i, len(p.src))
+
+	p.closeImplied(n.tag)
+	parent := p.current()
+	n.parent = parent
+	parent.children = append(parent.children, n)
+
+	switch {
+	case rawTextTags[n.tag]:
+		end := indexFold(p.src[p.pos:], "</"+n.tag)
+		if end < 0 {
+			end = len(p.src) - p.pos
+		}
+		if text := p.src[p.pos : p.pos+end]; text != "" {
+			if n.tag == "title" || n.tag == "textarea" {
+				text = html.UnescapeString(text)
+			}
+			n.children = []*node{{text: text, parent: n}}
+		}
+		p.pos += end
+		p.skipPast(">")
+	case voidTags[n.tag], selfClosing:
+	default:
+		p.stack = append(p.stack, n)
+	}
+}
+
+// closeImplied pops the elements a new start tag implicitly ends.
+func (p *htmlParser) closeImplied(tag string) {
+	if paragraphClosers[tag] {
+		p.popUntil([]string{"p"}, []string{"div", "td", "th", "li", "blockquote", "section", "article"})
+	}
+	if rule, ok := impliedEnds[tag]; ok {
+		p.popUntil(rule.closes, rule.scope)
+	}
+}
+
+// popUntil closes the innermost open element named in closes, unless an
+// element named in scope is open above it.
+func (p *htmlParser) popUntil(closes, scope []string) {
+	for i := len(p.stack) - 1; i > 0; i-- {
+		tag := p.stack[i].tag
+		if slices.Contains(closes, tag) {
+			p.stack = p.stack[:i]
+
+			return
+		}
+		if slices.Contains(scope, tag) {
+			return
+		}
+	}
+}
+
+// indexFold is strings.Index ignoring ASCII case.
+func indexFold(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func isLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f'
+}
+
+// textContent returns the concatenated text below n.
+func textContent(n *node) string {
+	if n.tag == "" {
+		return n.text
+	}
+	var sb strings.Builder
+	for _, c := range n.children {
+		sb.WriteString(textContent(c))
+	}
+
+	return sb.String()
+}
+
+// find returns the first element below n (depth-first) satisfying match.
+func find(n *node, match func(*node) bool) *node {
+	for _, c := range n.children {
+		if c.tag == "" {
+			continue
+		}
+		if match(c) {
+			return c
+		}
+		if found := find(c, match); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// findAll returns every element below n (depth-first) satisfying match.
+func findAll(n *node, match func(*node) bool) []*node {
+	var out []*node
+	for _, c := range n.children {
+		if c.tag == "" {
+			continue
+		}
+		if match(c) {
+			out = append(out, c)
+		}
+		out = append(out, findAll(c, match)...)
+	}
+
+	return out
+}
+
+// byTag matches elements with the given name.
+func byTag(tag string) func(*node) bool {
+	return func(n *node) bool { return n.tag == tag }
+}
@@ -0,0 +1,313 @@
+package webpage
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// blockTags start a new markdown block; everything else renders inline.
+var blockTags = map[string]bool{
+	"address": true, "article": true, "blockquote": true, "body": true, "dd": true, "details": true,
+	"div": true, "dl": true, "dt": true, "fieldset": true, "figcaption": true, "figure": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "hr": true, "html": true,
+	"li": true, "main": true, "ol": true, "p": true, "pre": true, "section": true, "summary": true,
+	"table": true, "ul": true,
+}
+
+// markdownRenderer converts extracted content to markdown, resolving links
+// and images against the page URL.
+type markdownRenderer struct {
+	base *url.URL
+}
+
+// toMarkdown renders n's content as markdown.
+func toMarkdown(n *node, base *url.URL) string {
+	r := &markdownRenderer{base: base}
+	out := strings.Join(r.blocks(n), "\n\n")
+	if out == "" {
+		return ""
+	}
+
+	return out + "\n"
+}
+
+// blocks renders the children of n as markdown blocks, grouping runs of
+// inline content into paragraphs.
+func (r *markdownRenderer) blocks(n *node) []string {
+	var out []string
+	var inline strings.Builder
+	flush := func() {
+		if p := cleanParagraph(inline.String()); p != "" {
+			out = append(out, p)
+		}
+		inline.Reset()
+	}
+
+	for _, c := range n.children {
+		if c.tag == "" || !blockTags[c.tag] {
+			inline.WriteString(r.inline(c))
+
+			continue
+		}
+		flush()
+		out = append(out, r.block(c)...)
+	}
+	flush()
+
+	return out
+}
+
+// block renders one block element.
+func (r *markdownRenderer) block(n *node) []string {
+	switch n.tag {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := cleanParagraph(r.inlineChildren(n))
+		if text == "" {
+			return nil
+		}
+
+		return []string{strings.Repeat("#", int(n.tag[1]-'0')) + " " + strings.ReplaceAll(text, "\n", " ")}
+	case "p", "dt", "summary", "figcaption":
+		if text := cleanParagraph(r.inlineChildren(n)); text != "" {
+			return []string{text}
+		}
+
+		return nil
+	case "pre":
+		code := strings.Trim(textContent(n), "\n")
+		if code == "" {
+			return nil
+		}
+
+		return []string{"```" + codeLanguage(n) + "\n" + code + "\n```"}
+	case "hr":
+		return []string{"---"}
+	case "ul", "ol":
+		if list := r.list(n); list != "" {
+			return []string{list}
+		}
+
+		return nil
+	case "blockquote":
+		inner := strings.Join(r.blocks(n), "\n\n")
+		if inner == "" {
+			return nil
+		}
+
+		return []string{prefixLines(inner, "> ", "> ")}
+	case "table":
+		if table := r.table(n); table != "" {
+			return []string{table}
+		}
+
+		return nil
+	default:
+		return r.blocks(n)
+	}
+}
+
+// list renders a <ul> or <ol>, indenting nested content under its item.
+func (r *markdownRenderer) list(n *node) string {
+	var items []string
+	num := 1
+	for _, li := range n.children {
+		if li.tag != "li" {
+			continue
+		}
+		marker := "- "
+		if n.tag == "ol" {
+			marker = fmt.Sprintf("%d. ", num)
+			num++
+		}
+		content := strings.Join(r.blocks(li), "\n")
+		if content == "" {
+			continue
+		}
+		items = append(items, prefixLines(content, marker, strings.Repeat(" ", len(marker))))
+	}
+
+	return strings.Join(items, "\n")
+}
+
+// table renders a table as a markdown pipe table, using the first row as
+// the header.
+func (r *markdownRenderer) table(n *node) string {
+	var rows [][]string
+	for _, tr := range findAll(n, byTag("tr")) {
+		var cells []string
+		for _, cell := range tr.children {
+			if cell.tag != "td" && cell.tag != "th" {
+				continue
+			}
+			text := cleanParagraph(r.inlineChildren(cell))
+			text = strings.ReplaceAll(strings.ReplaceAll(text, "\n", " "), "|", `\|`)
+			cells = append(cells, text)
+		}
+		if len(cells) > 0 {
+			rows = append(rows, cells)
+		}
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	var sb strings.Builder
+	for i, row := range rows {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		sb.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			sb.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+		}
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// inline renders a node in running text.
+func (r *markdownRenderer) inline(n *node) string {
+	if n.tag == "" {
+		return collapseRuns(n.text)
+	}
+
+	switch n.tag {
+	case "br":
+		return "\n"
+	case "img":
+		src := r.resolve(n.attrs["src"])
+		if src == "" {
+			return ""
+		}
+
+		return fmt.Sprintf("![%s](%s)", collapseSpace(n.attrs["alt"]), src)
+	case "a":
+		text := r.inlineChildren(n)
+		href := n.attrs["href"]
+		if strings.TrimSpace(text) == "" || href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+			return text
+		}
+
+		return wrapInline(text, "[", "]("+r.resolve(href)+")")
+	case "strong", "b":
+		return wrapInline(r.inlineChildren(n), "**", "**")
+	case "em", "i":
+		return wrapInline(r.inlineChildren(n), "_", "_")
+	case "del", "s", "strike":
+		return wrapInline(r.inlineChildren(n), "~~", "~~")
+	case "code", "kbd", "samp", "tt":
+		return wrapInline(collapseRuns(textContent(n)), "`", "`")
+	default:
+		if blockTags[n.tag] {
+			// Block content inside inline elements renders as running text
+			return " " + r.inlineChildren(n) + " "
+		}
+
+		return r.inlineChildren(n)
+	}
+}
+
+func (r *markdownRenderer) inlineChildren(n *node) string {
+	var sb strings.Builder
+	for _, c := range n.children {
+		sb.WriteString(r.inline(c))
+	}
+
+	return sb.String()
+}
+
+// resolve makes a link absolute against the page URL.
+func (r *markdownRenderer) resolve(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || r.base == nil {
+		return ref
+	}
+	u, err := r.base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+
+	return u.String()
+}
+
+// wrapInline wraps text in markdown delimiters, keeping surrounding spaces
+// outside them ("** bold **" is not bold).
+func wrapInline(text, open, closing string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	lead := text[:strings.Index(text, trimmed)]
+	trail := text[len(lead)+len(trimmed):]
+
+	return lead + open + trimmed + closing + trail
+}
+
+// codeLanguage reads a "language-x" or "lang-x" class from a <pre> or its <code>.
+func codeLanguage(pre *node) string {
+	classes := pre.attrs["class"]
+	if code := find(pre, byTag("code")); code != nil {
+		classes += " " + code.attrs["class"]
+	}
+	for _, class := range strings.Fields(classes) {
+		for _, prefix := range []string{"language-", "lang-"} {
+			if lang, ok := strings.CutPrefix(class, prefix); ok {
+				return lang
+			}
+		}
+	}
+
+	return ""
+}
+
+// collapseRuns collapses whitespace runs to single spaces, keeping a leading
+// or trailing space so adjacent inline text stays separated.
+func collapseRuns(s string) string {
+	if s == "" {
+		return ""
+	}
+	out := collapseSpace(s)
+	if isSpace(s[0]) {
+		out = " " + out
+	}
+	if isSpace(s[len(s)-1]) && out != " " {
+		out += " "
+	}
+
+	return out
+}
+
+// cleanParagraph trims each line of rendered inline text and drops empty ones.
+func cleanParagraph(s string) string {
+	lines := strings.Split(s, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = collapseSpace(line); line != "" {
+			kept = append(kept, line)
+		}
+	}
+
+	return strings.Join(kept, "\n")
+}
+
+// prefixLines prefixes the first line of s with first and the rest with rest.
+func prefixLines(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		switch {
+		case i == 0:
+			lines[i] = first + line
+		case line == "":
+			lines[i] = strings.TrimRight(rest, " ")
+		default:
+			lines[i] = rest + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
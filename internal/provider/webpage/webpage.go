@@ -0,0 +1,201 @@
+// Package webpage implements the url: provider, which uses arbitrary web
+// pages as task sources.
+package webpage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/naming"
+	"github.com/valksor/go-mehrhof/internal/provider"
+	providererrors "github.com/valksor/go-mehrhof/internal/provider/errors"
+	"github.com/valksor/go-mehrhof/internal/provider/httpclient"
+)
+
+const (
+	// ProviderName is the registered name for this provider.
+	ProviderName = "url"
+
+	// snapshotFile is the source file a page is snapshotted to.
+	snapshotFile = "page.md"
+
+	defaultUserAgent = "mehrhof (+https://github.com/valksor/go-mehrhof)"
+)
+
+// Provider fetches web pages as tasks.
+type Provider struct {
+	httpClient *http.Client
+	userAgent  string
+}
+
+// Info returns provider metadata.
+func Info() provider.ProviderInfo {
+	return provider.ProviderInfo{
+		Name:        ProviderName,
+		Description: "Web page task source",
+		Schemes:     []string{"url"},
+		Priority:    10,
+		Capabilities: provider.CapabilitySet{
+			provider.CapRead:     true,
+			provider.CapSnapshot: true,
+		},
+	}
+}
+
+// New creates a url provider.
+func New(_ context.Context, cfg provider.Config) (any, error) {
+	userAgent := cfg.GetString("user_agent")
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+
+	return &Provider{
+		httpClient: httpclient.NewHTTPClient(),
+		userAgent:  userAgent,
+	}, nil
+}
+
+// Register adds the url provider to the registry.
+func Register(r *provider.Registry) {
+	_ = r.Register(Info(), New)
+}
+
+// Match checks if input has the url: scheme prefix.
+func (p *Provider) Match(input string) bool {
+	return strings.HasPrefix(input, "url:")
+}
+
+// Parse validates the page URL and returns it as the work unit ID.
+func (p *Provider) Parse(input string) (string, error) {
+	raw := strings.TrimSpace(strings.TrimPrefix(input, "url:"))
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%w: expected an http(s) URL, got %q", providererrors.ErrInvalidReference, raw)
+	}
+	u.Fragment = ""
+
+	return u.String(), nil
+}
+
+// Fetch downloads the page and creates a WorkUnit from its readable content.
+func (p *Provider) Fetch(ctx context.Context, id string) (*provider.WorkUnit, error) {
+	pg, err := p.fetchPage(ctx, id, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetch page: %w", err)
+	}
+
+	now := time.Now()
+	updated := now
+	if !pg.LastModified.IsZero() {
+		updated = pg.LastModified
+	}
+	metadata := map[string]any{"url": pg.URL}
+	if pg.ETag != "" {
+		metadata["etag"] = pg.ETag
+	}
+	if !pg.LastModified.IsZero() {
+		metadata["last_modified"] = pg.LastModified.UTC().Format(http.TimeFormat)
+	}
+
+	return &provider.WorkUnit{
+		ID:          id,
+		ExternalID:  pg.URL,
+		Provider:    ProviderName,
+		Title:       pg.Title,
+		Description: pg.Markdown,
+		Status:      provider.StatusOpen,
+		Priority:    provider.PriorityNormal,
+		Labels:      []string{},
+		Metadata:    metadata,
+		CreatedAt:   updated,
+		UpdatedAt:   updated,
+		Source: provider.SourceInfo{
+			Type:      ProviderName,
+			Reference: id,
+			SyncedAt:  now,
+		},
+		Slug: naming.Slugify(pg.Title, 50),
+	}, nil
+}
+
+// Snapshot captures the page as markdown, recording its cache validators.
+func (p *Provider) Snapshot(ctx context.Context, id string) (*provider.Snapshot, error) {
+	pg, err := p.fetchPage(ctx, id, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetch page for snapshot: %w", err)
+	}
+
+	return &provider.Snapshot{
+		Type:  ProviderName,
+		Ref:   id,
+		Files: []provider.SnapshotFile{snapshotPage(pg)},
+	}, nil
+}
+
+// SnapshotChanges re-fetches the page with a conditional request, so an
+// unchanged page is neither downloaded nor re-written.
+func (p *Provider) SnapshotChanges(ctx context.Context, id string, previous map[string]provider.FileState) (*provider.SnapshotDelta, error) {
+	delta := &provider.SnapshotDelta{
+		Ref:    id,
+		States: make(map[string]provider.FileState),
+	}
+	for path := range previous {
+		if path != snapshotFile {
+			delta.Removed = append(delta.Removed, path)
+		}
+	}
+
+	var prev *validators
+	prevState, known := previous[snapshotFile]
+	if known {
+		prev = &validators{ETag: prevState.ETag, LastModified: prevState.ModTime}
+	}
+	slices.Sort(delta.Removed)
+
+	pg, err := p.fetchPage(ctx, id, prev)
+	if err != nil {
+		return nil, fmt.Errorf("fetch page for snapshot: %w", err)
+	}
+	if pg.NotModified {
+		delta.States[snapshotFile] = prevState
+
+		return delta, nil
+	}
+
+	file := snapshotPage(pg)
+	delta.States[snapshotFile] = provider.FileState{
+		ModTime: file.ModTime,
+		Size:    int64(len(file.Content)),
+		Hash:    file.Hash,
+		ETag:    file.ETag,
+	}
+	if !known || prevState.Hash != file.Hash {
+		delta.Changed = append(delta.Changed, file)
+	}
+
+	return delta, nil
+}
+
+// snapshotPage renders a page as the snapshot's markdown file.
+func snapshotPage(pg *page) provider.SnapshotFile {
+	var sb strings.Builder
+	if pg.Title != "" {
+		sb.WriteString("# " + pg.Title + "\n\n")
+	}
+	sb.WriteString("Source: " + pg.URL + "\n\n")
+	sb.WriteString(pg.Markdown)
+	content := sb.String()
+
+	return provider.SnapshotFile{
+		Path:    snapshotFile,
+		Content: content,
+		ModTime: pg.LastModified,
+		Hash:    provider.HashContent(content),
+		ETag:    pg.ETag,
+	}
+}
@@ -0,0 +1,143 @@
+package webpage
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+	providererrors "github.com/valksor/go-mehrhof/internal/provider/errors"
+)
+
+func newTestProvider(t *testing.T) *Provider {
+	t.Helper()
+	v, err := New(context.Background(), provider.NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return v.(*Provider)
+}
+
+func TestParse(t *testing.T) {
+	p := newTestProvider(t)
+
+	got, err := p.Parse("url:https://example.com/spec#section")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "https://example.com/spec" {
+		t.Errorf("Parse() = %q", got)
+	}
+
+	for _, input := range []string{"url:ftp://example.com/x", "url:example.com", "url:"} {
+		if _, err := p.Parse(input); !errors.Is(err, providererrors.ErrInvalidReference) {
+			t.Errorf("Parse(%q) error = %v, want invalid reference", input, err)
+		}
+	}
+}
+
+func TestSnapshotChanges(t *testing.T) {
+	lastModified := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	body := `<html><head><title>Spec</title></head><body><main><p>Version one of the spec, with details.</p></main></body></html>`
+	etag := `"v1"`
+	var conditional []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != defaultUserAgent {
+			t.Errorf("User-Agent = %q", r.Header.Get("User-Agent"))
+		}
+		conditional = append(conditional, r.Header.Get("If-None-Match")+"|"+r.Header.Get("If-Modified-Since"))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	p := newTestProvider(t)
+	ctx := context.Background()
+
+	wu, err := p.Fetch(ctx, srv.URL)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if wu.Title != "Spec" || wu.Description != "Version one of the spec, with details.\n" {
+		t.Errorf("work unit = %q / %q", wu.Title, wu.Description)
+	}
+	if wu.Metadata["etag"] != etag || !wu.UpdatedAt.Equal(lastModified) {
+		t.Errorf("metadata = %v, updated = %v", wu.Metadata, wu.UpdatedAt)
+	}
+
+	snap, err := p.Snapshot(ctx, srv.URL)
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	file := snap.Files[0]
+	if file.Path != "page.md" || file.ETag != etag || !file.ModTime.Equal(lastModified) {
+		t.Errorf("snapshot file = %+v", file)
+	}
+	if !strings.HasPrefix(file.Content, "# Spec\n\nSource: "+srv.URL+"\n\n") {
+		t.Errorf("snapshot content = %q", file.Content)
+	}
+
+	previous := map[string]provider.FileState{
+		"page.md": {ModTime: file.ModTime, Size: int64(len(file.Content)), Hash: file.Hash, ETag: file.ETag},
+	}
+
+	// Unchanged upstream: the server answers 304
+	delta, err := p.SnapshotChanges(ctx, srv.URL, previous)
+	if err != nil {
+		t.Fatalf("SnapshotChanges() error = %v", err)
+	}
+	if len(delta.Changed) != 0 || delta.States["page.md"] != previous["page.md"] {
+		t.Errorf("unchanged delta = %+v", delta)
+	}
+	if got := conditional[len(conditional)-1]; got != etag+"|"+lastModified.Format(http.TimeFormat) {
+		t.Errorf("conditional headers = %q", got)
+	}
+
+	// Changed upstream: new content and validators
+	body = strings.Replace(body, "Version one", "Version two", 1)
+	etag = `"v2"`
+	delta, err = p.SnapshotChanges(ctx, srv.URL, previous)
+	if err != nil {
+		t.Fatalf("SnapshotChanges() error = %v", err)
+	}
+	if len(delta.Changed) != 1 || !strings.Contains(delta.Changed[0].Content, "Version two") {
+		t.Fatalf("changed delta = %+v", delta)
+	}
+	if state := delta.States["page.md"]; state.ETag != `"v2"` || state.Hash == previous["page.md"].Hash {
+		t.Errorf("new state = %+v", state)
+	}
+}
+
+func TestFetch_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/image" {
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte{0x89, 'P', 'N', 'G'})
+
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	p := newTestProvider(t)
+	if _, err := p.Fetch(context.Background(), srv.URL+"/missing"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Fetch(missing) error = %v, want 404", err)
+	}
+	if _, err := p.Fetch(context.Background(), srv.URL+"/image"); !errors.Is(err, ErrUnsupportedContent) {
+		t.Errorf("Fetch(image) error = %v, want ErrUnsupportedContent", err)
+	}
+}
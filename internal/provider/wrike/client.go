@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/valksor/go-mehrhof/internal/provider/httpclient"
@@ -32,6 +33,10 @@ type Client struct {
 	token      string
 	folderID   string // Default folder for list/create operations
 	spaceID    string // Default space for list operations
+
+	// Workflows are fetched once per client (see GetWorkflows)
+	workflowsMu sync.Mutex
+	workflows   []Workflow
 }
 
 // NewClient creates a new Wrike API client.
@@ -347,6 +352,10 @@ type Task struct {
 	Priority    string    `json:"priority"`
 	Permalink   string    `json:"permalink"`
 	SubTaskIDs  []string  `json:"subTaskIds"`
+
+	// CustomStatusID is the task's status within its workflow; Status is
+	// the standard group that status belongs to.
+	CustomStatusID string `json:"customStatusId,omitempty"`
 }
 
 // Comment represents a Wrike comment.
@@ -8,13 +8,31 @@ import (
 )
 
 // UpdateStatus implements the provider.StatusUpdater interface.
-// It changes the status of a Wrike task.
+// It changes the status of a Wrike task. Tasks in a custom workflow are moved
+// to the workflow's first status that maps to status (see mapCustomStatus);
+// otherwise, or when the workflow has no such status, the standard status is
+// set.
 func (p *Provider) UpdateStatus(ctx context.Context, workUnitID string, status provider.Status) error {
+	task, err := p.fetchTask(ctx, workUnitID)
+	if err != nil {
+		return fmt.Errorf("update task status: %w", err)
+	}
+
+	if wf, _ := findCustomStatus(p.workflowsFor(ctx, task), task.CustomStatusID); wf != nil {
+		if cs := pickCustomStatus(wf, status); cs != nil {
+			if err := p.client.UpdateTaskCustomStatus(ctx, task.ID, cs.ID); err != nil {
+				return fmt.Errorf("update task status: %w", err)
+			}
+
+			return nil
+		}
+	}
+
 	// Convert provider status to Wrike status
 	wrikeStatus := mapProviderStatusToWrike(status)
 
 	// Update the task via API
-	if err := p.client.UpdateTaskStatus(ctx, workUnitID, wrikeStatus); err != nil {
+	if err := p.client.UpdateTaskStatus(ctx, task.ID, wrikeStatus); err != nil {
 		return fmt.Errorf("update task status: %w", err)
 	}
 
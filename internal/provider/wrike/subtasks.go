@@ -3,6 +3,7 @@ package wrike
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/valksor/go-mehrhof/internal/naming"
@@ -89,6 +90,53 @@ func (p *Provider) subtaskToWorkUnit(task *Task, parentID string) *provider.Work
 	}
 }
 
+// subtaskNode is a subtask in a task's subtask tree.
+type subtaskNode struct {
+	task        *Task
+	parentID    string // API ID of the parent task
+	parentTitle string
+}
+
+// fetchSubtaskTree fetches the subtasks of a task and their nested subtasks,
+// level by level up to maxSubtaskDepth levels, parents before children.
+func (p *Provider) fetchSubtaskTree(ctx context.Context, root *Task) ([]subtaskNode, error) {
+	var nodes []subtaskNode
+	parents := map[string]*Task{root.ID: root}
+	ids := root.SubTaskIDs
+	for depth := 0; depth < maxSubtaskDepth && len(ids) > 0; depth++ {
+		tasks, err := p.client.GetTasks(ctx, ids)
+		if err != nil {
+			return nodes, fmt.Errorf("fetch subtasks: %w", err)
+		}
+
+		var next []string
+		for i := range tasks {
+			task := &tasks[i]
+			node := subtaskNode{task: task}
+			for id, parent := range parents {
+				if slices.Contains(parent.SubTaskIDs, task.ID) {
+					node.parentID, node.parentTitle = id, parent.Title
+
+					break
+				}
+			}
+			nodes = append(nodes, node)
+			next = append(next, task.SubTaskIDs...)
+		}
+
+		parents = make(map[string]*Task, len(tasks))
+		for i := range tasks {
+			parents[tasks[i].ID] = &tasks[i]
+		}
+		ids = next
+	}
+
+	return nodes, nil
+}
+
+// maxSubtaskDepth bounds how many levels of nested subtasks are fetched.
+const maxSubtaskDepth = 5
+
 // fetchSubtasks recursively fetches all subtasks for a task (internal helper)
 // Returns a list of subtask info.
 func (p *Provider) fetchSubtasks(ctx context.Context, subtaskIDs []string, depth int) ([]SubtaskInfo, error) {
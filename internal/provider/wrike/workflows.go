@@ -0,0 +1,146 @@
+package wrike
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+// Workflow is a Wrike workflow: the custom statuses a task can have. Each
+// custom status belongs to one of the standard groups Active, Completed,
+// Deferred and Cancelled.
+type Workflow struct {
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	CustomStatuses []CustomStatus `json:"customStatuses"`
+	Standard       bool           `json:"standard"`
+	Hidden         bool           `json:"hidden"`
+}
+
+// CustomStatus is one status of a workflow.
+type CustomStatus struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Group        string `json:"group"`
+	Color        string `json:"color,omitempty"`
+	StandardName bool   `json:"standardName"`
+	Hidden       bool   `json:"hidden"`
+}
+
+type workflowsResponse struct {
+	Data []Workflow `json:"data"`
+}
+
+// GetWorkflows fetches the account's workflows. They rarely change, so the
+// result is cached for the lifetime of the client.
+func (c *Client) GetWorkflows(ctx context.Context) ([]Workflow, error) {
+	c.workflowsMu.Lock()
+	defer c.workflowsMu.Unlock()
+
+	if c.workflows != nil {
+		return c.workflows, nil
+	}
+
+	var response workflowsResponse
+	if err := c.doRequestWithRetry(ctx, http.MethodGet, "/workflows", nil, &response); err != nil {
+		return nil, err
+	}
+	c.workflows = response.Data
+
+	return c.workflows, nil
+}
+
+// UpdateTaskCustomStatus moves a task to a custom status of its workflow.
+func (c *Client) UpdateTaskCustomStatus(ctx context.Context, taskID, customStatusID string) error {
+	bodyBytes, err := json.Marshal(map[string]string{"customStatus": customStatusID})
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+
+	var response taskResponse
+
+	return c.doRequestWithRetry(ctx, http.MethodPut, "/tasks/"+url.PathEscape(taskID),
+		strings.NewReader(string(bodyBytes)), &response)
+}
+
+// findCustomStatus returns the custom status with the given ID and the
+// workflow it belongs to.
+func findCustomStatus(workflows []Workflow, id string) (*Workflow, *CustomStatus) {
+	if id == "" {
+		return nil, nil
+	}
+	for i := range workflows {
+		for j := range workflows[i].CustomStatuses {
+			if workflows[i].CustomStatuses[j].ID == id {
+				return &workflows[i], &workflows[i].CustomStatuses[j]
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// mapCustomStatus converts a custom status to provider status: by its name
+// for review and in-progress statuses, otherwise by its group.
+func mapCustomStatus(cs CustomStatus) provider.Status {
+	name := strings.ToLower(cs.Name)
+	if cs.Group == "Active" {
+		switch {
+		case strings.Contains(name, "review"):
+			return provider.StatusReview
+		case strings.Contains(name, "progress"), strings.Contains(name, "doing"), strings.Contains(name, "working"):
+			return provider.StatusInProgress
+		}
+	}
+
+	switch cs.Group {
+	case "Completed":
+		return provider.StatusDone
+	case "Deferred", "Cancelled":
+		return provider.StatusClosed
+	default:
+		return provider.StatusOpen
+	}
+}
+
+// pickCustomStatus returns the first visible custom status of the workflow
+// that maps to status, or nil when it has none.
+func pickCustomStatus(wf *Workflow, status provider.Status) *CustomStatus {
+	for i := range wf.CustomStatuses {
+		cs := &wf.CustomStatuses[i]
+		if !cs.Hidden && mapCustomStatus(*cs) == status {
+			return cs
+		}
+	}
+
+	return nil
+}
+
+// taskStatus returns a task's provider status and display name, using its
+// custom status when the workflows are known.
+func taskStatus(task *Task, workflows []Workflow) (provider.Status, string) {
+	if _, cs := findCustomStatus(workflows, task.CustomStatusID); cs != nil {
+		return mapCustomStatus(*cs), cs.Name
+	}
+
+	return mapStatus(task.Status), task.Status
+}
+
+// workflowsFor fetches the workflows when a task uses custom statuses.
+// Failures leave the standard status in use.
+func (p *Provider) workflowsFor(ctx context.Context, task *Task) []Workflow {
+	if task.CustomStatusID == "" {
+		return nil
+	}
+	workflows, err := p.client.GetWorkflows(ctx)
+	if err != nil {
+		return nil
+	}
+
+	return workflows
+}
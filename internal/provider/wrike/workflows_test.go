@@ -0,0 +1,120 @@
+package wrike
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+const testWorkflows = `{"data":[{"id":"WF1","name":"Dev","customStatuses":[
+	{"id":"CS1","name":"New","group":"Active"},
+	{"id":"CS2","name":"In Progress","group":"Active"},
+	{"id":"CS3","name":"Code Review","group":"Active"},
+	{"id":"CS4","name":"Shipped","group":"Completed"},
+	{"id":"CS5","name":"Won't Do","group":"Cancelled"}]}]}`
+
+func TestMapCustomStatus(t *testing.T) {
+	tests := []struct {
+		status CustomStatus
+		want   provider.Status
+	}{
+		{CustomStatus{Name: "New", Group: "Active"}, provider.StatusOpen},
+		{CustomStatus{Name: "In Progress", Group: "Active"}, provider.StatusInProgress},
+		{CustomStatus{Name: "Code Review", Group: "Active"}, provider.StatusReview},
+		{CustomStatus{Name: "Shipped", Group: "Completed"}, provider.StatusDone},
+		{CustomStatus{Name: "On Hold", Group: "Deferred"}, provider.StatusClosed},
+		{CustomStatus{Name: "Won't Do", Group: "Cancelled"}, provider.StatusClosed},
+	}
+	for _, tt := range tests {
+		if got := mapCustomStatus(tt.status); got != tt.want {
+			t.Errorf("mapCustomStatus(%q) = %v, want %v", tt.status.Name, got, tt.want)
+		}
+	}
+}
+
+// newWorkflowServer serves a task T1 in custom status "In Progress" with
+// subtask T2, which has subtask T3, and records the last task update.
+func newWorkflowServer(t *testing.T, update *map[string]string) *Provider {
+	t.Helper()
+
+	tasks := map[string]string{
+		"T1": `{"id":"T1","title":"Parent","status":"Active","customStatusId":"CS2","permalink":"https://www.wrike.com/open.htm?id=1","subTaskIds":["T2"]}`,
+		"T2": `{"id":"T2","title":"Child","status":"Completed","customStatusId":"CS4","permalink":"https://www.wrike.com/open.htm?id=2","subTaskIds":["T3"]}`,
+		"T3": `{"id":"T3","title":"Grandchild","status":"Active","permalink":"https://www.wrike.com/open.htm?id=3"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/workflows":
+			_, _ = w.Write([]byte(testWorkflows))
+		case r.Method == http.MethodPut:
+			_ = json.NewDecoder(r.Body).Decode(update)
+			_, _ = w.Write([]byte(`{"data":[]}`))
+		case strings.HasPrefix(r.URL.Path, "/tasks/") && !strings.HasSuffix(r.URL.Path, "/comments"):
+			var data []string
+			for id := range strings.SplitSeq(strings.TrimPrefix(r.URL.Path, "/tasks/"), ",") {
+				if task, ok := tasks[id]; ok {
+					data = append(data, task)
+				}
+			}
+			_, _ = w.Write([]byte(`{"data":[` + strings.Join(data, ",") + `]}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":[]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return &Provider{client: NewClient("token", server.URL)}
+}
+
+func TestSnapshotSubtasks(t *testing.T) {
+	p := newWorkflowServer(t, &map[string]string{})
+
+	snapshot, err := p.Snapshot(context.Background(), "T1")
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+
+	files := map[string]string{}
+	for _, f := range snapshot.Files {
+		files[f.Path] = f.Content
+	}
+	if len(files) != 3 {
+		t.Fatalf("snapshot files = %v, want task.md and two subtask files", len(files))
+	}
+	if main := files["task.md"]; !strings.Contains(main, "**Status:** In Progress") ||
+		!strings.Contains(main, "- [x] Child (Shipped) - subtasks/2.md") || strings.Contains(main, "Grandchild") {
+		t.Errorf("task.md =\n%s", main)
+	}
+	if child := files["subtasks/2.md"]; !strings.Contains(child, "**Parent:** Parent") ||
+		!strings.Contains(child, "- [ ] Grandchild (Active) - subtasks/3.md") {
+		t.Errorf("subtasks/2.md =\n%s", child)
+	}
+	if _, ok := files["subtasks/3.md"]; !ok {
+		t.Error("nested subtask not snapshotted")
+	}
+}
+
+func TestUpdateStatusCustomWorkflow(t *testing.T) {
+	var update map[string]string
+	p := newWorkflowServer(t, &update)
+
+	if err := p.UpdateStatus(context.Background(), "T1", provider.StatusReview); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+	if update["customStatus"] != "CS3" {
+		t.Errorf("update = %v, want customStatus CS3", update)
+	}
+
+	// Tasks without a custom status keep using standard statuses
+	if err := p.UpdateStatus(context.Background(), "T3", provider.StatusDone); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+	if update["status"] != "Completed" {
+		t.Errorf("update = %v, want status Completed", update)
+	}
+}
@@ -3,6 +3,7 @@ package wrike
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -83,15 +84,11 @@ func (p *Provider) Parse(input string) (string, error) {
 
 // Fetch retrieves a task from Wrike and converts it to a WorkUnit.
 func (p *Provider) Fetch(ctx context.Context, id string) (*provider.WorkUnit, error) {
-	// First try to fetch as a direct task ID
-	task, err := p.client.GetTask(ctx, id)
+	task, err := p.fetchTask(ctx, id)
 	if err != nil {
-		// If that fails, try as permalink
-		task, err = p.client.GetTaskByPermalink(ctx, id)
-		if err != nil {
-			return nil, fmt.Errorf("fetch task: %w", err)
-		}
+		return nil, fmt.Errorf("fetch task: %w", err)
 	}
+	status, statusName := taskStatus(task, p.workflowsFor(ctx, task))
 
 	// Fetch comments
 	comments, err := p.client.GetComments(ctx, task.ID)
@@ -128,7 +125,7 @@ func (p *Provider) Fetch(ctx context.Context, id string) (*provider.WorkUnit, er
 		Provider:    ProviderName,
 		Title:       task.Title,
 		Description: task.Description,
-		Status:      mapStatus(task.Status),
+		Status:      status,
 		Priority:    mapPriority(task.Priority),
 		Labels:      []string{},
 		Assignees:   []provider.Person{},
@@ -147,25 +144,75 @@ func (p *Provider) Fetch(ctx context.Context, id string) (*provider.WorkUnit, er
 		TaskType:    "task",
 		Slug:        naming.Slugify(task.Title, 50),
 	}
+	if statusName != task.Status {
+		wu.Metadata["wrike_custom_status"] = statusName
+	}
 
 	return wu, nil
 }
 
-// Snapshot captures the task content from Wrike.
+// Snapshot captures the task content from Wrike. Subtasks, including nested
+// ones, are added as one file each under subtasks/, and task.md lists the
+// direct subtasks as a checklist.
 func (p *Provider) Snapshot(ctx context.Context, id string) (*provider.Snapshot, error) {
-	task, err := p.client.GetTask(ctx, id)
+	task, err := p.fetchTask(ctx, id)
 	if err != nil {
-		task, err = p.client.GetTaskByPermalink(ctx, id)
-		if err != nil {
-			return nil, fmt.Errorf("fetch task for snapshot: %w", err)
-		}
+		return nil, fmt.Errorf("fetch task for snapshot: %w", err)
 	}
 
 	comments, _ := p.client.GetComments(ctx, task.ID)
 
+	// Subtasks are optional: a failed fetch leaves them out
+	subtasks, _ := p.fetchSubtaskTree(ctx, task)
+	var workflows []Workflow
+	if task.CustomStatusID != "" || slices.ContainsFunc(subtasks, func(st subtaskNode) bool { return st.task.CustomStatusID != "" }) {
+		workflows, _ = p.client.GetWorkflows(ctx)
+	}
+
 	var content strings.Builder
+	writeTaskHeader(&content, task, workflows)
+
+	if len(comments) > 0 {
+		content.WriteString("## Comments\n\n")
+		for _, c := range comments {
+			content.WriteString(fmt.Sprintf("### %s - %s\n\n", c.AuthorName, c.CreatedDate.Format(time.RFC3339)))
+			content.WriteString(c.Text)
+			content.WriteString("\n\n")
+		}
+	}
+	writeSubtaskList(&content, task.ID, subtasks, workflows)
+
+	files := []provider.SnapshotFile{
+		{
+			Path:    "task.md",
+			Content: content.String(),
+		},
+	}
+	for _, st := range subtasks {
+		var sub strings.Builder
+		writeTaskHeader(&sub, st.task, workflows)
+		sub.WriteString(fmt.Sprintf("**Parent:** %s\n\n", st.parentTitle))
+		writeSubtaskList(&sub, st.task.ID, subtasks, workflows)
+		files = append(files, provider.SnapshotFile{
+			Path:    subtaskPath(st.task),
+			Content: sub.String(),
+		})
+	}
+
+	return &provider.Snapshot{
+		Type:  ProviderName,
+		Ref:   id,
+		Files: files,
+	}, nil
+}
+
+// writeTaskHeader writes a task's title, status, priority, permalink and
+// description.
+func writeTaskHeader(content *strings.Builder, task *Task, workflows []Workflow) {
+	_, statusName := taskStatus(task, workflows)
+
 	content.WriteString(fmt.Sprintf("# %s\n\n", task.Title))
-	content.WriteString(fmt.Sprintf("**Status:** %s\n", task.Status))
+	content.WriteString(fmt.Sprintf("**Status:** %s\n", statusName))
 	content.WriteString(fmt.Sprintf("**Priority:** %s\n", task.Priority))
 	content.WriteString(fmt.Sprintf("**Permalink:** %s\n\n", task.Permalink))
 
@@ -174,26 +221,41 @@ func (p *Provider) Snapshot(ctx context.Context, id string) (*provider.Snapshot,
 		content.WriteString(task.Description)
 		content.WriteString("\n\n")
 	}
+}
 
-	if len(comments) > 0 {
-		content.WriteString("## Comments\n\n")
-		for _, c := range comments {
-			content.WriteString(fmt.Sprintf("### %s - %s\n\n", c.AuthorName, c.CreatedDate.Format(time.RFC3339)))
-			content.WriteString(c.Text)
-			content.WriteString("\n\n")
+// writeSubtaskList writes the direct subtasks of a task as a checklist
+// linking to their files.
+func writeSubtaskList(content *strings.Builder, parentID string, subtasks []subtaskNode, workflows []Workflow) {
+	var children []subtaskNode
+	for _, st := range subtasks {
+		if st.parentID == parentID {
+			children = append(children, st)
 		}
 	}
+	if len(children) == 0 {
+		return
+	}
 
-	return &provider.Snapshot{
-		Type: ProviderName,
-		Ref:  id,
-		Files: []provider.SnapshotFile{
-			{
-				Path:    "task.md",
-				Content: content.String(),
-			},
-		},
-	}, nil
+	content.WriteString("## Subtasks\n\n")
+	for _, st := range children {
+		status, statusName := taskStatus(st.task, workflows)
+		check := " "
+		if status == provider.StatusDone {
+			check = "x"
+		}
+		content.WriteString(fmt.Sprintf("- [%s] %s (%s) - %s\n", check, st.task.Title, statusName, subtaskPath(st.task)))
+	}
+	content.WriteString("\n")
+}
+
+// subtaskPath returns the snapshot file of a subtask.
+func subtaskPath(task *Task) string {
+	id := ExtractNumericID(task.Permalink)
+	if id == "" {
+		id = task.ID
+	}
+
+	return "subtasks/" + id + ".md"
 }
 
 // ListTasks returns tasks from a folder or space.
@@ -228,6 +290,16 @@ func (p *Provider) ListTasks(ctx context.Context, scopeType, scopeID string) ([]
 // Helper functions
 // ──────────────────────────────────────────────────────────────────────────────
 
+// fetchTask fetches a task by API ID, falling back to its permalink ID.
+func (p *Provider) fetchTask(ctx context.Context, id string) (*Task, error) {
+	task, err := p.client.GetTask(ctx, id)
+	if err != nil {
+		return p.client.GetTaskByPermalink(ctx, id)
+	}
+
+	return task, nil
+}
+
 // mapStatus converts Wrike status to provider status.
 func mapStatus(status string) provider.Status {
 	switch strings.ToLower(status) {
@@ -289,6 +361,9 @@ func buildMetadata(task *Task, subtasks []SubtaskInfo) map[string]any {
 	metadata["permalink"] = task.Permalink
 	metadata["api_id"] = task.ID
 	metadata["wrike_status"] = task.Status
+	if task.CustomStatusID != "" {
+		metadata["wrike_custom_status_id"] = task.CustomStatusID
+	}
 	metadata["wrike_priority"] = task.Priority
 
 	if len(subtasks) > 0 {
@@ -25,8 +25,9 @@ const (
 
 // Config holds client configuration.
 type Config struct {
-	Token string
-	Host  string // Optional: override default API base URL
+	Token  string
+	Host   string            // Optional: override default API base URL
+	States map[string]string // Optional: provider status -> YouTrack state name
 }
 
 // Client wraps the YouTrack API client.
@@ -211,6 +212,47 @@ func (c *Client) UpdateIssue(ctx context.Context, issueID string, updates map[st
 	return &response.Data, nil
 }
 
+// ApplyCommand applies a YouTrack command (e.g. "State Fixed") to an issue,
+// optionally posting comment along with it.
+func (c *Client) ApplyCommand(ctx context.Context, issueID, query, comment string) error {
+	issue := map[string]string{"id": issueID}
+	if IsValidID(issueID) {
+		issue = map[string]string{"idReadable": issueID}
+	}
+	requestBody := map[string]interface{}{
+		"query":  query,
+		"issues": []map[string]string{issue},
+	}
+	if comment != "" {
+		requestBody["comment"] = comment
+	}
+	bodyBytes, err := json.Marshal(requestBody)
+	if err != nil {
+		return fmt.Errorf("marshaling request body: %w", err)
+	}
+
+	return c.doRequestWithRetry(ctx, http.MethodPost, "/commands", bytesReader(bodyBytes), nil)
+}
+
+// AddWorkItem adds a time tracking work item to an issue.
+func (c *Client) AddWorkItem(ctx context.Context, issueID string, minutes int, date time.Time, text string) error {
+	requestBody := map[string]interface{}{
+		"duration": map[string]int{"minutes": minutes},
+		"date":     date.UnixMilli(),
+	}
+	if text != "" {
+		requestBody["text"] = text
+	}
+	bodyBytes, err := json.Marshal(requestBody)
+	if err != nil {
+		return fmt.Errorf("marshaling request body: %w", err)
+	}
+
+	return c.doRequestWithRetry(ctx, http.MethodPost,
+		"/issues/"+url.PathEscape(issueID)+"/timeTracking/workItems",
+		bytesReader(bodyBytes), nil)
+}
+
 // CreateIssue creates a new issue.
 func (c *Client) CreateIssue(ctx context.Context, projectID, summary, description string, customFields []map[string]interface{}) (*Issue, error) {
 	requestBody := map[string]interface{}{
@@ -25,6 +25,7 @@ func Info() provider.ProviderInfo {
 			provider.CapDownloadAttachment: true,
 			provider.CapSnapshot:           true,
 			provider.CapFetchSubtasks:      true,
+			provider.CapLogTime:            true,
 		},
 	}
 }
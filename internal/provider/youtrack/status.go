@@ -3,38 +3,73 @@ package youtrack
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/valksor/go-mehrhof/internal/provider"
 )
 
-// UpdateStatus changes the status of an issue via the State custom field.
+// UpdateStatus changes the state of an issue by applying a "State <name>"
+// command, so YouTrack runs the project's workflow rules for the transition.
 func (p *Provider) UpdateStatus(ctx context.Context, workUnitID string, status provider.Status) error {
-	// Map provider status to YouTrack state name
-	stateName := statusToYouTrackState(status)
-
-	// Build custom field update for State
-	updates := map[string]interface{}{
-		"customFields": []map[string]interface{}{
-			{
-				"name":  "State",
-				"$type": "SingleEnumIssueCustomField",
-				"value": map[string]string{
-					"name": stateName,
-				},
-			},
-		},
-	}
-
-	_, err := p.client.UpdateIssue(ctx, workUnitID, updates)
-	if err != nil {
+	if err := p.client.ApplyCommand(ctx, workUnitID, stateCommand(p.stateName(status)), ""); err != nil {
 		return fmt.Errorf("update status: %w", err)
 	}
 
 	return nil
 }
 
+// OnTaskFinished moves the issue to the done state and comments with where
+// the changes went (branch and pull request).
+func (p *Provider) OnTaskFinished(ctx context.Context, workUnitID string, info provider.FinishInfo) error {
+	if err := p.client.ApplyCommand(ctx, workUnitID, stateCommand(p.stateName(provider.StatusDone)), finishComment(info)); err != nil {
+		return fmt.Errorf("finish issue: %w", err)
+	}
+
+	return nil
+}
+
+// stateName returns the YouTrack state for a provider status, preferring the
+// configured transition names over the default mapping.
+func (p *Provider) stateName(status provider.Status) string {
+	if p.config != nil {
+		if name := p.config.States[string(status)]; name != "" {
+			return name
+		}
+	}
+
+	return statusToYouTrackState(status)
+}
+
+// stateCommand builds the command setting the State field. Multi-word values
+// must be wrapped in braces in YouTrack's command syntax.
+func stateCommand(state string) string {
+	if strings.ContainsAny(state, " \t") {
+		state = "{" + state + "}"
+	}
+
+	return "State " + state
+}
+
+// finishComment describes a finished task for the issue's comment thread.
+// Returns "" when there is nothing to link.
+func finishComment(info provider.FinishInfo) string {
+	var lines []string
+	if info.Branch != "" {
+		lines = append(lines, fmt.Sprintf("Branch: `%s`", info.Branch))
+	}
+	if info.PullRequestURL != "" {
+		lines = append(lines, "Pull request: "+info.PullRequestURL)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	return "Finished with mehrhof.\n\n" + strings.Join(lines, "\n")
+}
+
 // statusToYouTrackState maps provider status to YouTrack state name.
-// This is a default mapping - users may need customization based on their workflow.
+// This is a default mapping - override it with the states setting to match
+// the project's workflow.
 func statusToYouTrackState(status provider.Status) string {
 	switch status {
 	case provider.StatusOpen:
@@ -51,3 +86,22 @@ func statusToYouTrackState(status provider.Status) string {
 		return "New"
 	}
 }
+
+// stringMap converts a config option decoded from YAML into a string map.
+func stringMap(v any) map[string]string {
+	switch m := v.(type) {
+	case map[string]string:
+		return m
+	case map[string]any:
+		out := make(map[string]string, len(m))
+		for k, val := range m {
+			if s, ok := val.(string); ok {
+				out[k] = s
+			}
+		}
+
+		return out
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,75 @@
+package youtrack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+func TestStatusCommands(t *testing.T) {
+	var requests []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/commands" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		requests = append(requests, body)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	cfg := provider.NewConfig().
+		Set("token", "test-token").
+		Set("host", srv.URL).
+		Set("states", map[string]any{"done": "Fixed"})
+	v, err := New(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := v.(*Provider)
+
+	if err := p.UpdateStatus(context.Background(), "ABC-1", provider.StatusInProgress); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+	info := provider.FinishInfo{Branch: "task/abc-1", PullRequestURL: "https://github.com/o/r/pull/7"}
+	if err := p.OnTaskFinished(context.Background(), "ABC-1", info); err != nil {
+		t.Fatalf("OnTaskFinished() error = %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("got %d command requests, want 2", len(requests))
+	}
+	if q := requests[0]["query"]; q != "State {In Progress}" {
+		t.Errorf("status query = %v", q)
+	}
+	if _, ok := requests[0]["comment"]; ok {
+		t.Errorf("status command carries a comment: %v", requests[0])
+	}
+	issues, _ := requests[0]["issues"].([]any)
+	if len(issues) != 1 || issues[0].(map[string]any)["idReadable"] != "ABC-1" {
+		t.Errorf("issues = %v", requests[0]["issues"])
+	}
+
+	if q := requests[1]["query"]; q != "State Fixed" {
+		t.Errorf("finish query = %v, want configured state", q)
+	}
+	want := "Finished with mehrhof.\n\nBranch: `task/abc-1`\nPull request: https://github.com/o/r/pull/7"
+	if c := requests[1]["comment"]; c != want {
+		t.Errorf("finish comment = %q, want %q", c, want)
+	}
+
+	var _ provider.FinishHandler = p
+}
+
+func TestFinishComment_Empty(t *testing.T) {
+	if got := finishComment(provider.FinishInfo{}); got != "" {
+		t.Errorf("finishComment() = %q, want empty", got)
+	}
+}
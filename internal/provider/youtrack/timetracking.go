@@ -0,0 +1,25 @@
+package youtrack
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+// LogTime adds a work item with the time spent to an issue. Time tracking
+// must be enabled for the issue's project.
+func (p *Provider) LogTime(ctx context.Context, workUnitID string, entry provider.TimeEntry) error {
+	date := entry.Started
+	if date.IsZero() {
+		date = time.Now()
+	}
+	minutes := int(max(entry.Duration.Round(time.Minute), time.Minute).Minutes())
+
+	if err := p.client.AddWorkItem(ctx, workUnitID, minutes, date, entry.Comment); err != nil {
+		return fmt.Errorf("log time: %w", err)
+	}
+
+	return nil
+}
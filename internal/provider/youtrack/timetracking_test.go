@@ -0,0 +1,50 @@
+package youtrack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+func TestLogTime(t *testing.T) {
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/issues/ABC-1/timeTracking/workItems" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	v, err := New(context.Background(), provider.NewConfig().Set("token", "test-token").Set("host", srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := v.(*Provider)
+
+	started := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	entry := provider.TimeEntry{Duration: 65*time.Minute + 20*time.Second, Started: started, Comment: "implementing 1h5m"}
+	if err := p.LogTime(context.Background(), "ABC-1", entry); err != nil {
+		t.Fatalf("LogTime() error = %v", err)
+	}
+
+	if d, _ := body["duration"].(map[string]any); d["minutes"] != float64(65) {
+		t.Errorf("duration = %v, want 65 minutes", body["duration"])
+	}
+	if body["date"] != float64(started.UnixMilli()) {
+		t.Errorf("date = %v", body["date"])
+	}
+	if body["text"] != "implementing 1h5m" {
+		t.Errorf("text = %v", body["text"])
+	}
+
+	var _ provider.TimeLogger = p
+}
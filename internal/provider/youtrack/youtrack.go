@@ -27,8 +27,9 @@ func New(_ context.Context, cfg provider.Config) (any, error) {
 	}
 
 	config := &Config{
-		Token: resolvedToken,
-		Host:  host,
+		Token:  resolvedToken,
+		Host:   host,
+		States: stringMap(cfg.Get("states")),
 	}
 
 	return &Provider{
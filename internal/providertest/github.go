@@ -0,0 +1,249 @@
+package providertest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/provider/github"
+)
+
+// GitHub is a fake GitHub REST API (served as a GitHub Enterprise endpoint).
+type GitHub struct {
+	*fake
+
+	// DefaultBranch is reported for every repository (default: "main").
+	DefaultBranch string
+
+	// Repo is the configured "owner/name" used for bare references and PR creation.
+	Repo string
+}
+
+// NewGitHub starts a fake GitHub server that is closed when the test ends.
+func NewGitHub(tb testing.TB) *GitHub {
+	tb.Helper()
+
+	g := &GitHub{DefaultBranch: "main"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/{owner}/{repo}", g.handleRepo)
+	mux.HandleFunc("GET /api/v3/repos/{owner}/{repo}/issues/{number}", g.handleGetIssue)
+	mux.HandleFunc("PATCH /api/v3/repos/{owner}/{repo}/issues/{number}", g.handleEditIssue)
+	mux.HandleFunc("GET /api/v3/repos/{owner}/{repo}/issues/{number}/comments", g.handleListComments)
+	mux.HandleFunc("POST /api/v3/repos/{owner}/{repo}/issues/{number}/comments", g.handleCreateComment)
+	mux.HandleFunc("POST /api/v3/repos/{owner}/{repo}/pulls", g.handleCreatePull)
+	g.fake = newFake(tb, mux)
+
+	return g
+}
+
+// AddIssue seeds an issue in repo ("owner/name").
+func (g *GitHub) AddIssue(repo string, issue Issue) {
+	g.put(issueRef(repo, issue.Number), issue)
+}
+
+// Issue returns the current state of an issue, including comments added by providers.
+func (g *GitHub) Issue(repo string, number int) (Issue, bool) {
+	return g.get(issueRef(repo, number))
+}
+
+// Config returns provider configuration pointing the GitHub provider at this fake.
+func (g *GitHub) Config() provider.Config {
+	cfg := provider.NewConfig().
+		Set("token", "providertest-token").
+		Set("base_url", g.URL()+"/").
+		Set("cache.disabled", true)
+	if owner, repo, ok := strings.Cut(g.Repo, "/"); ok {
+		cfg = cfg.Set("owner", owner).Set("repo", repo)
+	}
+
+	return cfg
+}
+
+// Register adds the GitHub provider to r, wired to this fake.
+func (g *GitHub) Register(r *provider.Registry) {
+	_ = r.Register(github.Info(), func(ctx context.Context, _ provider.Config) (any, error) {
+		return github.New(ctx, g.Config())
+	})
+}
+
+func issueRef(repo string, number int) string {
+	return fmt.Sprintf("%s#%d", repo, number)
+}
+
+func (g *GitHub) issueKey(r *http.Request) (string, int, bool) {
+	number, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil {
+		return "", 0, false
+	}
+
+	return issueRef(r.PathValue("owner")+"/"+r.PathValue("repo"), number), number, true
+}
+
+func (g *GitHub) issueJSON(r *http.Request, issue Issue) map[string]any {
+	labels := make([]map[string]any, len(issue.Labels))
+	for i, l := range issue.Labels {
+		labels[i] = map[string]any{"name": l}
+	}
+
+	return map[string]any{
+		"number":     issue.Number,
+		"title":      issue.Title,
+		"body":       issue.Body,
+		"state":      issue.State,
+		"labels":     labels,
+		"assignees":  []any{},
+		"html_url":   fmt.Sprintf("%s/%s/%s/issues/%d", g.URL(), r.PathValue("owner"), r.PathValue("repo"), issue.Number),
+		"created_at": fixtureTime,
+		"updated_at": fixtureTime,
+	}
+}
+
+func (g *GitHub) handleRepo(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"name":           r.PathValue("repo"),
+		"full_name":      r.PathValue("owner") + "/" + r.PathValue("repo"),
+		"default_branch": g.DefaultBranch,
+	})
+}
+
+func (g *GitHub) handleGetIssue(w http.ResponseWriter, r *http.Request) {
+	key, _, ok := g.issueKey(r)
+	if !ok {
+		notFound(w)
+
+		return
+	}
+	issue, ok := g.get(key)
+	if !ok {
+		notFound(w)
+
+		return
+	}
+	writeJSON(w, http.StatusOK, g.issueJSON(r, issue))
+}
+
+func (g *GitHub) handleEditIssue(w http.ResponseWriter, r *http.Request) {
+	key, _, ok := g.issueKey(r)
+	if !ok {
+		notFound(w)
+
+		return
+	}
+
+	var req struct {
+		State *string `json:"state"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+
+		return
+	}
+	if !g.update(key, func(issue *Issue) {
+		if req.State != nil {
+			issue.State = *req.State
+		}
+	}) {
+		notFound(w)
+
+		return
+	}
+
+	issue, _ := g.get(key)
+	writeJSON(w, http.StatusOK, g.issueJSON(r, issue))
+}
+
+func (g *GitHub) handleListComments(w http.ResponseWriter, r *http.Request) {
+	key, _, ok := g.issueKey(r)
+	if !ok {
+		notFound(w)
+
+		return
+	}
+	issue, ok := g.get(key)
+	if !ok {
+		notFound(w)
+
+		return
+	}
+
+	out := make([]map[string]any, len(issue.Comments))
+	for i, c := range issue.Comments {
+		out[i] = githubCommentJSON(c)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (g *GitHub) handleCreateComment(w http.ResponseWriter, r *http.Request) {
+	key, _, ok := g.issueKey(r)
+	if !ok {
+		notFound(w)
+
+		return
+	}
+
+	var req struct {
+		Body string `json:"body"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+
+		return
+	}
+	c, ok := g.addComment(key, "mehrhof", req.Body)
+	if !ok {
+		notFound(w)
+
+		return
+	}
+	writeJSON(w, http.StatusCreated, githubCommentJSON(c))
+}
+
+func (g *GitHub) handleCreatePull(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+		Head  string `json:"head"`
+		Base  string `json:"base"`
+		Draft bool   `json:"draft"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	owner, repo := r.PathValue("owner"), r.PathValue("repo")
+	pr := g.addPull(PullRequest{
+		Title:  req.Title,
+		Body:   req.Body,
+		Source: req.Head,
+		Target: req.Base,
+		Draft:  req.Draft,
+	}, func(n int) string {
+		return fmt.Sprintf("%s/%s/%s/pull/%d", g.URL(), owner, repo, n)
+	})
+
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"id":       pr.Number,
+		"number":   pr.Number,
+		"title":    pr.Title,
+		"body":     pr.Body,
+		"state":    "open",
+		"draft":    pr.Draft,
+		"html_url": pr.URL,
+	})
+}
+
+func githubCommentJSON(c Comment) map[string]any {
+	return map[string]any{
+		"id":         c.ID,
+		"body":       c.Body,
+		"user":       map[string]any{"login": c.Author},
+		"created_at": c.Created,
+		"updated_at": c.Created,
+	}
+}
@@ -0,0 +1,330 @@
+package providertest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/provider/gitlab"
+)
+
+// GitLab is a fake GitLab REST API (v4).
+type GitLab struct {
+	*fake
+
+	// DefaultBranch is reported for every project (default: "main").
+	DefaultBranch string
+
+	// Project is the configured project path used for bare references and MR creation.
+	Project string
+
+	projMu   sync.Mutex
+	projects map[string]int64 // project path -> numeric ID
+}
+
+// NewGitLab starts a fake GitLab server that is closed when the test ends.
+func NewGitLab(tb testing.TB) *GitLab {
+	tb.Helper()
+
+	g := &GitLab{
+		DefaultBranch: "main",
+		projects:      make(map[string]int64),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/projects/{id}", g.handleProject)
+	mux.HandleFunc("GET /api/v4/projects/{id}/issues/{iid}", g.handleGetIssue)
+	mux.HandleFunc("PUT /api/v4/projects/{id}/issues/{iid}", g.handleUpdateIssue)
+	mux.HandleFunc("GET /api/v4/projects/{id}/issues/{iid}/notes", g.handleListNotes)
+	mux.HandleFunc("POST /api/v4/projects/{id}/issues/{iid}/notes", g.handleCreateNote)
+	mux.HandleFunc("POST /api/v4/projects/{id}/merge_requests", g.handleCreateMR)
+	g.fake = newFake(tb, mux)
+
+	return g
+}
+
+// AddIssue seeds an issue (by IID in Issue.Number) in project ("group/name").
+func (g *GitLab) AddIssue(project string, issue Issue) {
+	g.projectID(project)
+	g.put(issueRef(project, issue.Number), issue)
+}
+
+// Issue returns the current state of an issue, including notes added by providers.
+func (g *GitLab) Issue(project string, iid int) (Issue, bool) {
+	return g.get(issueRef(project, iid))
+}
+
+// Config returns provider configuration pointing the GitLab provider at this fake.
+func (g *GitLab) Config() provider.Config {
+	return provider.NewConfig().
+		Set("token", "providertest-token").
+		Set("host", g.URL()).
+		Set("project_path", g.Project)
+}
+
+// Register adds the GitLab provider to r, wired to this fake.
+func (g *GitLab) Register(r *provider.Registry) {
+	_ = r.Register(gitlab.Info(), func(ctx context.Context, _ provider.Config) (any, error) {
+		return gitlab.New(ctx, g.Config())
+	})
+}
+
+// projectID returns the numeric ID for a project path, allocating one if needed.
+func (g *GitLab) projectID(path string) int64 {
+	g.projMu.Lock()
+	defer g.projMu.Unlock()
+
+	id, ok := g.projects[path]
+	if !ok {
+		id = int64(len(g.projects) + 1)
+		g.projects[path] = id
+	}
+
+	return id
+}
+
+// projectPath resolves a path or numeric ID from the URL to a known project path.
+func (g *GitLab) projectPath(idOrPath string) (string, int64, bool) {
+	g.projMu.Lock()
+	defer g.projMu.Unlock()
+
+	if id, err := strconv.ParseInt(idOrPath, 10, 64); err == nil {
+		for path, pid := range g.projects {
+			if pid == id {
+				return path, pid, true
+			}
+		}
+
+		return "", 0, false
+	}
+
+	id, ok := g.projects[idOrPath]
+
+	return idOrPath, id, ok
+}
+
+func (g *GitLab) issueKey(r *http.Request) (string, string, int64, bool) {
+	path, pid, ok := g.projectPath(r.PathValue("id"))
+	if !ok {
+		return "", "", 0, false
+	}
+	iid, err := strconv.Atoi(r.PathValue("iid"))
+	if err != nil {
+		return "", "", 0, false
+	}
+
+	return issueRef(path, iid), path, pid, true
+}
+
+func (g *GitLab) issueJSON(path string, pid int64, issue Issue) map[string]any {
+	state := issue.State
+	if state == "open" {
+		state = "opened"
+	}
+	labels := issue.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+
+	return map[string]any{
+		"id":          pid*1000 + int64(issue.Number),
+		"iid":         issue.Number,
+		"project_id":  pid,
+		"title":       issue.Title,
+		"description": issue.Body,
+		"state":       state,
+		"labels":      labels,
+		"assignees":   []any{},
+		"web_url":     fmt.Sprintf("%s/%s/-/issues/%d", g.URL(), path, issue.Number),
+		"created_at":  fixtureTime,
+		"updated_at":  fixtureTime,
+	}
+}
+
+func (g *GitLab) handleProject(w http.ResponseWriter, r *http.Request) {
+	path, pid, ok := g.projectPath(r.PathValue("id"))
+	if !ok {
+		notFound(w)
+
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":                  pid,
+		"path_with_namespace": path,
+		"default_branch":      g.DefaultBranch,
+		"web_url":             g.URL() + "/" + path,
+	})
+}
+
+func (g *GitLab) handleGetIssue(w http.ResponseWriter, r *http.Request) {
+	key, path, pid, ok := g.issueKey(r)
+	if !ok {
+		notFound(w)
+
+		return
+	}
+	issue, ok := g.get(key)
+	if !ok {
+		notFound(w)
+
+		return
+	}
+	writeJSON(w, http.StatusOK, g.issueJSON(path, pid, issue))
+}
+
+func (g *GitLab) handleUpdateIssue(w http.ResponseWriter, r *http.Request) {
+	key, path, pid, ok := g.issueKey(r)
+	if !ok {
+		notFound(w)
+
+		return
+	}
+
+	var req struct {
+		StateEvent   string `json:"state_event"`
+		AddLabels    string `json:"add_labels"`
+		RemoveLabels string `json:"remove_labels"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+
+		return
+	}
+	if !g.update(key, func(issue *Issue) {
+		switch req.StateEvent {
+		case "close":
+			issue.State = "closed"
+		case "reopen":
+			issue.State = "open"
+		}
+		if req.AddLabels != "" {
+			issue.Labels = append(issue.Labels, strings.Split(req.AddLabels, ",")...)
+		}
+		for _, l := range strings.Split(req.RemoveLabels, ",") {
+			issue.Labels = removeString(issue.Labels, l)
+		}
+	}) {
+		notFound(w)
+
+		return
+	}
+
+	issue, _ := g.get(key)
+	writeJSON(w, http.StatusOK, g.issueJSON(path, pid, issue))
+}
+
+func (g *GitLab) handleListNotes(w http.ResponseWriter, r *http.Request) {
+	key, _, _, ok := g.issueKey(r)
+	if !ok {
+		notFound(w)
+
+		return
+	}
+	issue, ok := g.get(key)
+	if !ok {
+		notFound(w)
+
+		return
+	}
+
+	out := make([]map[string]any, len(issue.Comments))
+	for i, c := range issue.Comments {
+		out[i] = gitlabNoteJSON(c)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (g *GitLab) handleCreateNote(w http.ResponseWriter, r *http.Request) {
+	key, _, _, ok := g.issueKey(r)
+	if !ok {
+		notFound(w)
+
+		return
+	}
+
+	var req struct {
+		Body string `json:"body"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+
+		return
+	}
+	c, ok := g.addComment(key, "mehrhof", req.Body)
+	if !ok {
+		notFound(w)
+
+		return
+	}
+	writeJSON(w, http.StatusCreated, gitlabNoteJSON(c))
+}
+
+func (g *GitLab) handleCreateMR(w http.ResponseWriter, r *http.Request) {
+	path, pid, ok := g.projectPath(r.PathValue("id"))
+	if !ok {
+		notFound(w)
+
+		return
+	}
+
+	var req struct {
+		Title        string `json:"title"`
+		Description  string `json:"description"`
+		SourceBranch string `json:"source_branch"`
+		TargetBranch string `json:"target_branch"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	mr := g.addPull(PullRequest{
+		Title:  req.Title,
+		Body:   req.Description,
+		Source: req.SourceBranch,
+		Target: req.TargetBranch,
+		Draft:  strings.HasPrefix(req.Title, "Draft:"),
+	}, func(n int) string {
+		return fmt.Sprintf("%s/%s/-/merge_requests/%d", g.URL(), path, n)
+	})
+
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"id":            pid*1000 + int64(mr.Number),
+		"iid":           mr.Number,
+		"project_id":    pid,
+		"title":         mr.Title,
+		"description":   mr.Body,
+		"state":         "opened",
+		"source_branch": mr.Source,
+		"target_branch": mr.Target,
+		"web_url":       mr.URL,
+	})
+}
+
+func gitlabNoteJSON(c Comment) map[string]any {
+	return map[string]any{
+		"id":         c.ID,
+		"body":       c.Body,
+		"author":     map[string]any{"id": 1, "username": c.Author, "name": c.Author},
+		"created_at": c.Created,
+		"updated_at": c.Created,
+		"system":     false,
+	}
+}
+
+// removeString returns s without any occurrences of v.
+func removeString(s []string, v string) []string {
+	out := s[:0]
+	for _, x := range s {
+		if x != v {
+			out = append(out, x)
+		}
+	}
+
+	return out
+}
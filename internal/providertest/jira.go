@@ -0,0 +1,202 @@
+package providertest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+	"github.com/valksor/go-mehrhof/internal/provider/jira"
+)
+
+// jiraTransitions are offered for every issue; performing one sets Issue.State
+// to the transition name.
+var jiraTransitions = []map[string]string{
+	{"id": "11", "name": "To Do"},
+	{"id": "21", "name": "In Progress"},
+	{"id": "31", "name": "In Review"},
+	{"id": "41", "name": "Done"},
+}
+
+// Jira is a fake Jira REST API (v2 and v3 paths are both served).
+type Jira struct {
+	*fake
+
+	// Project is the default project key used for bare references.
+	Project string
+}
+
+// NewJira starts a fake Jira server that is closed when the test ends.
+func NewJira(tb testing.TB) *Jira {
+	tb.Helper()
+
+	j := &Jira{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/{version}/issue/{key}", j.handleGetIssue)
+	mux.HandleFunc("GET /rest/api/{version}/issue/{key}/comment", j.handleListComments)
+	mux.HandleFunc("POST /rest/api/{version}/issue/{key}/comment", j.handleCreateComment)
+	mux.HandleFunc("GET /rest/api/{version}/issue/{key}/transitions", j.handleListTransitions)
+	mux.HandleFunc("POST /rest/api/{version}/issue/{key}/transitions", j.handleTransition)
+	j.fake = newFake(tb, mux)
+
+	return j
+}
+
+// AddIssue seeds an issue keyed by Issue.Key (e.g., "PROJ-1").
+func (j *Jira) AddIssue(issue Issue) {
+	j.put(issue.Key, issue)
+}
+
+// Issue returns the current state of an issue, including comments and transitions.
+func (j *Jira) Issue(key string) (Issue, bool) {
+	return j.get(key)
+}
+
+// Config returns provider configuration pointing the Jira provider at this fake.
+func (j *Jira) Config() provider.Config {
+	return provider.NewConfig().
+		Set("token", "providertest-token").
+		Set("email", "test@example.com").
+		Set("base_url", j.URL()).
+		Set("project", j.Project)
+}
+
+// Register adds the Jira provider to r, wired to this fake.
+func (j *Jira) Register(r *provider.Registry) {
+	_ = r.Register(jira.Info(), func(ctx context.Context, _ provider.Config) (any, error) {
+		return jira.New(ctx, j.Config())
+	})
+}
+
+func (j *Jira) issueJSON(issue Issue) map[string]any {
+	status := issue.State
+	switch status {
+	case "open":
+		status = "To Do"
+	case "closed":
+		status = "Done"
+	}
+	labels := issue.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+	projectKey, _, _ := strings.Cut(issue.Key, "-")
+
+	return map[string]any{
+		"id":   issue.Key,
+		"key":  issue.Key,
+		"self": fmt.Sprintf("%s/rest/api/2/issue/%s", j.URL(), issue.Key),
+		"fields": map[string]any{
+			"summary":     issue.Title,
+			"description": issue.Body,
+			"status":      map[string]any{"name": status},
+			"priority":    map[string]any{"name": "Medium"},
+			"labels":      labels,
+			"created":     fixtureTime,
+			"updated":     fixtureTime,
+			"project":     map[string]any{"key": projectKey},
+			"issuetype":   map[string]any{"name": "Task"},
+		},
+	}
+}
+
+func (j *Jira) handleGetIssue(w http.ResponseWriter, r *http.Request) {
+	issue, ok := j.get(r.PathValue("key"))
+	if !ok {
+		notFound(w)
+
+		return
+	}
+	writeJSON(w, http.StatusOK, j.issueJSON(issue))
+}
+
+func (j *Jira) handleListComments(w http.ResponseWriter, r *http.Request) {
+	issue, ok := j.get(r.PathValue("key"))
+	if !ok {
+		notFound(w)
+
+		return
+	}
+
+	out := make([]map[string]any, len(issue.Comments))
+	for i, c := range issue.Comments {
+		out[i] = jiraCommentJSON(c)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"comments":   out,
+		"startAt":    0,
+		"maxResults": len(out),
+		"total":      len(out),
+	})
+}
+
+func (j *Jira) handleCreateComment(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Body string `json:"body"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+
+		return
+	}
+	c, ok := j.addComment(r.PathValue("key"), "mehrhof", req.Body)
+	if !ok {
+		notFound(w)
+
+		return
+	}
+	writeJSON(w, http.StatusCreated, jiraCommentJSON(c))
+}
+
+func (j *Jira) handleListTransitions(w http.ResponseWriter, r *http.Request) {
+	if _, ok := j.get(r.PathValue("key")); !ok {
+		notFound(w)
+
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"transitions": jiraTransitions})
+}
+
+func (j *Jira) handleTransition(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Transition struct {
+			ID string `json:"id"`
+		} `json:"transition"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+
+		return
+	}
+
+	var name string
+	for _, t := range jiraTransitions {
+		if t["id"] == req.Transition.ID {
+			name = t["name"]
+		}
+	}
+	if name == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": "unknown transition"})
+
+		return
+	}
+	if !j.update(r.PathValue("key"), func(issue *Issue) { issue.State = name }) {
+		notFound(w)
+
+		return
+	}
+	// Real Jira answers 204; the client only accepts 200/201.
+	w.WriteHeader(http.StatusOK)
+}
+
+func jiraCommentJSON(c Comment) map[string]any {
+	return map[string]any{
+		"id":      fmt.Sprint(c.ID),
+		"body":    c.Body,
+		"author":  map[string]any{"displayName": c.Author, "name": c.Author},
+		"created": c.Created,
+		"updated": c.Created,
+	}
+}
@@ -0,0 +1,200 @@
+// Package providertest provides in-memory fake provider servers for integration tests.
+//
+// Each fake wraps an httptest.Server that speaks the subset of the provider's
+// REST API used by the corresponding provider package. Fixtures are seeded with
+// AddIssue, and writes made by the provider (comments, state changes, pull or
+// merge requests) are recorded so tests can assert on them.
+//
+// Usage:
+//
+//	gh := providertest.NewGitHub(t)
+//	gh.AddIssue("acme/app", providertest.Issue{Number: 1, Title: "Fix login"})
+//	gh.Register(cond.GetProviderRegistry())
+//	err := cond.Start(ctx, "github:acme/app#1")
+//
+// Thread safety:
+//   - All fakes are safe for concurrent use; state is guarded by a mutex.
+package providertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fixtureTime is used for timestamps that fixtures leave unset.
+var fixtureTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Issue is a provider-neutral issue fixture.
+type Issue struct {
+	Number   int    // GitHub issue number or GitLab IID
+	Key      string // Jira issue key (e.g., "PROJ-1")
+	Title    string
+	Body     string
+	State    string // "open" or "closed"; defaults to "open"
+	Labels   []string
+	Comments []Comment
+}
+
+// Comment is a comment on an issue fixture.
+type Comment struct {
+	ID      int64
+	Author  string
+	Body    string
+	Created time.Time
+}
+
+// PullRequest records a pull/merge request created through a fake.
+type PullRequest struct {
+	Number int
+	Title  string
+	Body   string
+	Source string
+	Target string
+	Draft  bool
+	URL    string
+}
+
+// fake holds the state shared by all fake servers.
+type fake struct {
+	mu            sync.Mutex
+	srv           *httptest.Server
+	issues        map[string]*Issue // keyed by repo/project + "#" + number, or Jira key
+	pulls         []*PullRequest
+	nextCommentID int64
+}
+
+// newFake starts a server for mux and closes it when the test ends.
+func newFake(tb testing.TB, mux *http.ServeMux) *fake {
+	tb.Helper()
+
+	f := &fake{
+		issues:        make(map[string]*Issue),
+		nextCommentID: 1000,
+	}
+	f.srv = httptest.NewServer(mux)
+	tb.Cleanup(f.srv.Close)
+
+	return f
+}
+
+// URL returns the base URL of the fake server.
+func (f *fake) URL() string {
+	return f.srv.URL
+}
+
+// PullRequests returns copies of all pull/merge requests created so far.
+func (f *fake) PullRequests() []PullRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := make([]PullRequest, len(f.pulls))
+	for i, pr := range f.pulls {
+		out[i] = *pr
+	}
+
+	return out
+}
+
+// put stores an issue fixture, filling defaults.
+func (f *fake) put(key string, issue Issue) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if issue.State == "" {
+		issue.State = "open"
+	}
+	issue.Labels = slices.Clone(issue.Labels)
+	issue.Comments = slices.Clone(issue.Comments)
+	for i := range issue.Comments {
+		if issue.Comments[i].ID == 0 {
+			f.nextCommentID++
+			issue.Comments[i].ID = f.nextCommentID
+		}
+		if issue.Comments[i].Created.IsZero() {
+			issue.Comments[i].Created = fixtureTime
+		}
+	}
+	f.issues[key] = &issue
+}
+
+// get returns a copy of the issue stored under key.
+func (f *fake) get(key string) (Issue, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	issue, ok := f.issues[key]
+	if !ok {
+		return Issue{}, false
+	}
+	cp := *issue
+	cp.Labels = slices.Clone(issue.Labels)
+	cp.Comments = slices.Clone(issue.Comments)
+
+	return cp, true
+}
+
+// update applies fn to the issue stored under key while holding the lock.
+func (f *fake) update(key string, fn func(*Issue)) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	issue, ok := f.issues[key]
+	if !ok {
+		return false
+	}
+	fn(issue)
+
+	return true
+}
+
+// addComment appends a comment to the issue stored under key.
+func (f *fake) addComment(key, author, body string) (Comment, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	issue, ok := f.issues[key]
+	if !ok {
+		return Comment{}, false
+	}
+	f.nextCommentID++
+	c := Comment{ID: f.nextCommentID, Author: author, Body: body, Created: fixtureTime}
+	issue.Comments = append(issue.Comments, c)
+
+	return c, true
+}
+
+// addPull records a new pull/merge request and assigns its number.
+func (f *fake) addPull(pr PullRequest, urlFormat func(number int) string) PullRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	pr.Number = len(f.pulls) + 1
+	pr.URL = urlFormat(pr.Number)
+	f.pulls = append(f.pulls, &pr)
+
+	return pr
+}
+
+// writeJSON encodes v as a JSON response with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// notFound writes a JSON 404 response.
+func notFound(w http.ResponseWriter) {
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": "404 Not Found"})
+}
+
+// decodeBody decodes a JSON request body into v.
+func decodeBody(r *http.Request, v any) error {
+	defer func() { _ = r.Body.Close() }()
+
+	return json.NewDecoder(r.Body).Decode(v)
+}
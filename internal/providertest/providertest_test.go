@@ -0,0 +1,162 @@
+package providertest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/provider"
+)
+
+// resolve creates a provider through the registry, like the conductor does.
+func resolve(t *testing.T, r *provider.Registry, ref string) (any, string) {
+	t.Helper()
+
+	p, id, err := r.Resolve(context.Background(), ref, provider.Config{}, provider.ResolveOptions{})
+	if err != nil {
+		t.Fatalf("Resolve(%q): %v", ref, err)
+	}
+
+	return p, id
+}
+
+func TestGitHub(t *testing.T) {
+	ctx := context.Background()
+	gh := NewGitHub(t)
+	gh.Repo = "acme/app"
+	gh.AddIssue("acme/app", Issue{
+		Number:   7,
+		Title:    "Fix login",
+		Body:     "Users cannot log in",
+		Labels:   []string{"bug"},
+		Comments: []Comment{{Author: "alice", Body: "Repro attached"}},
+	})
+
+	r := provider.NewRegistry()
+	gh.Register(r)
+	p, id := resolve(t, r, "github:acme/app#7")
+
+	wu, err := p.(provider.Reader).Fetch(ctx, id)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if wu.Title != "Fix login" || wu.TaskType != "fix" {
+		t.Errorf("Fetch = title %q type %q", wu.Title, wu.TaskType)
+	}
+	if len(wu.Comments) != 1 || wu.Comments[0].Body != "Repro attached" {
+		t.Errorf("Fetch comments = %+v", wu.Comments)
+	}
+
+	if _, err := p.(provider.Commenter).AddComment(ctx, id, "On it"); err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	if err := p.(provider.StatusUpdater).UpdateStatus(ctx, id, provider.StatusClosed); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	issue, _ := gh.Issue("acme/app", 7)
+	if len(issue.Comments) != 2 || issue.Comments[1].Body != "On it" {
+		t.Errorf("comments after AddComment = %+v", issue.Comments)
+	}
+	if issue.State != "closed" {
+		t.Errorf("state = %q, want closed", issue.State)
+	}
+
+	pr, err := p.(provider.PRCreator).CreatePullRequest(ctx, provider.PullRequestOptions{
+		Title:        "Fix login",
+		SourceBranch: "fix/7",
+	})
+	if err != nil {
+		t.Fatalf("CreatePullRequest: %v", err)
+	}
+	if pr.Number != 1 || pr.URL == "" {
+		t.Errorf("CreatePullRequest = %+v", pr)
+	}
+	pulls := gh.PullRequests()
+	if len(pulls) != 1 || pulls[0].Target != "main" || pulls[0].Source != "fix/7" {
+		t.Errorf("PullRequests = %+v", pulls)
+	}
+
+	if _, err := p.(provider.Reader).Fetch(ctx, "acme/app#99"); err == nil {
+		t.Error("Fetch of missing issue: expected error")
+	}
+}
+
+func TestGitLab(t *testing.T) {
+	ctx := context.Background()
+	gl := NewGitLab(t)
+	gl.Project = "group/app"
+	gl.AddIssue("group/app", Issue{
+		Number:   3,
+		Title:    "Add export",
+		Labels:   []string{"feature"},
+		Comments: []Comment{{Author: "bob", Body: "CSV please"}},
+	})
+
+	r := provider.NewRegistry()
+	gl.Register(r)
+	p, id := resolve(t, r, "gitlab:group/app#3")
+
+	wu, err := p.(provider.Reader).Fetch(ctx, id)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if wu.Title != "Add export" || wu.Status != provider.StatusOpen {
+		t.Errorf("Fetch = title %q status %q", wu.Title, wu.Status)
+	}
+	if len(wu.Comments) != 1 {
+		t.Errorf("Fetch comments = %+v", wu.Comments)
+	}
+
+	if _, err := p.(provider.Commenter).AddComment(ctx, id, "Started"); err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	if err := p.(provider.StatusUpdater).UpdateStatus(ctx, id, provider.StatusClosed); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	issue, _ := gl.Issue("group/app", 3)
+	if len(issue.Comments) != 2 || issue.State != "closed" {
+		t.Errorf("issue after writes = %+v", issue)
+	}
+
+	mr, err := p.(provider.PRCreator).CreatePullRequest(ctx, provider.PullRequestOptions{
+		Title:        "Add export",
+		SourceBranch: "feature/3",
+	})
+	if err != nil {
+		t.Fatalf("CreatePullRequest: %v", err)
+	}
+	if mr.Number != 1 {
+		t.Errorf("CreatePullRequest = %+v", mr)
+	}
+	if pulls := gl.PullRequests(); len(pulls) != 1 || pulls[0].Target != "main" {
+		t.Errorf("PullRequests = %+v", pulls)
+	}
+}
+
+func TestJira(t *testing.T) {
+	ctx := context.Background()
+	j := NewJira(t)
+	j.AddIssue(Issue{Key: "PROJ-12", Title: "Upgrade deps", Body: "Bump everything"})
+
+	r := provider.NewRegistry()
+	j.Register(r)
+	p, id := resolve(t, r, "jira:PROJ-12")
+
+	wu, err := p.(provider.Reader).Fetch(ctx, id)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if wu.Title != "Upgrade deps" || wu.ExternalKey != "PROJ-12" {
+		t.Errorf("Fetch = title %q key %q", wu.Title, wu.ExternalKey)
+	}
+
+	if _, err := p.(provider.Commenter).AddComment(ctx, id, "Done soon"); err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	if err := p.(provider.StatusUpdater).UpdateStatus(ctx, id, provider.StatusInProgress); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	issue, _ := j.Issue("PROJ-12")
+	if len(issue.Comments) != 1 || issue.State != "In Progress" {
+		t.Errorf("issue after writes = %+v", issue)
+	}
+}
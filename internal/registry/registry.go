@@ -0,0 +1,151 @@
+// Package registry records the workspaces on this machine that have mehrhof
+// tasks, in registry.yaml under the user's config directory
+// (~/.config/mehrhof on Linux), so in-flight tasks can be listed across
+// repositories.
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+const (
+	fileName = "registry.yaml"
+
+	// touchInterval limits how often the last-used time of a workspace is
+	// rewritten, so commands do not write the registry every time.
+	touchInterval = time.Hour
+)
+
+// Workspace is one registered repository.
+type Workspace struct {
+	Root     string    `yaml:"root"`
+	AddedAt  time.Time `yaml:"added_at"`
+	LastUsed time.Time `yaml:"last_used"`
+}
+
+// Registry lists the registered workspaces.
+type Registry struct {
+	Version    int         `yaml:"version"`
+	Workspaces []Workspace `yaml:"workspaces"`
+}
+
+// Path returns the path of the registry file.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate user config directory: %w", err)
+	}
+
+	return filepath.Join(dir, "mehrhof", fileName), nil
+}
+
+// Load reads the registry. A missing registry is empty.
+func Load() (*Registry, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	return load(path)
+}
+
+// Record registers a workspace root, or refreshes its last-used time.
+func Record(root string) error {
+	return update(func(r *Registry) bool {
+		now := time.Now()
+		i := slices.IndexFunc(r.Workspaces, func(ws Workspace) bool { return ws.Root == root })
+		if i < 0 {
+			r.Workspaces = append(r.Workspaces, Workspace{Root: root, AddedAt: now, LastUsed: now})
+
+			return true
+		}
+		if now.Sub(r.Workspaces[i].LastUsed) < touchInterval {
+			return false
+		}
+		r.Workspaces[i].LastUsed = now
+
+		return true
+	})
+}
+
+// Forget removes workspace roots from the registry.
+func Forget(roots ...string) error {
+	return update(func(r *Registry) bool {
+		before := len(r.Workspaces)
+		r.Workspaces = slices.DeleteFunc(r.Workspaces, func(ws Workspace) bool { return slices.Contains(roots, ws.Root) })
+
+		return len(r.Workspaces) != before
+	})
+}
+
+// update applies a change to the registry under its lock, writing it when
+// change reports a modification.
+func update(change func(r *Registry) bool) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	return storage.WithLockTimeout(path+".lock", storage.DefaultLockTimeout, func() error {
+		r, err := load(path)
+		if err != nil {
+			return err
+		}
+		if !change(r) {
+			return nil
+		}
+		slices.SortFunc(r.Workspaces, func(a, b Workspace) int { return strings.Compare(a.Root, b.Root) })
+
+		return save(path, r)
+	})
+}
+
+func load(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Registry{Version: 1}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read registry: %w", err)
+	}
+
+	var r Registry
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parse registry %s: %w", path, err)
+	}
+
+	return &r, nil
+}
+
+func save(path string, r *Registry) error {
+	r.Version = 1
+	data, err := yaml.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("marshal registry: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create registry directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write registry: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+
+		return fmt.Errorf("write registry: %w", err)
+	}
+
+	return nil
+}
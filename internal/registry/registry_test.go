@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordAndForget(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	reg, err := Load()
+	if err != nil {
+		t.Fatalf("Load empty registry: %v", err)
+	}
+	if len(reg.Workspaces) != 0 {
+		t.Fatalf("empty registry has %d workspaces", len(reg.Workspaces))
+	}
+
+	for _, root := range []string{"/src/b", "/src/a", "/src/b"} {
+		if err := Record(root); err != nil {
+			t.Fatalf("Record(%s): %v", root, err)
+		}
+	}
+
+	path, err := Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("registry not written: %v", err)
+	}
+	if filepath.Base(filepath.Dir(path)) != "mehrhof" {
+		t.Errorf("registry path %s not in a mehrhof directory", path)
+	}
+
+	reg, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reg.Workspaces) != 2 || reg.Workspaces[0].Root != "/src/a" || reg.Workspaces[1].Root != "/src/b" {
+		t.Fatalf("workspaces = %+v, want /src/a and /src/b", reg.Workspaces)
+	}
+	if reg.Workspaces[0].AddedAt.IsZero() || reg.Workspaces[0].LastUsed.IsZero() {
+		t.Error("timestamps not set")
+	}
+
+	if err := Forget("/src/a", "/src/missing"); err != nil {
+		t.Fatalf("Forget: %v", err)
+	}
+	reg, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reg.Workspaces) != 1 || reg.Workspaces[0].Root != "/src/b" {
+		t.Errorf("workspaces after Forget = %+v, want /src/b", reg.Workspaces)
+	}
+}
@@ -0,0 +1,227 @@
+// Package scheduler runs a graph of dependent jobs, such as the tasks of a
+// plan, with bounded parallelism and spend.
+//
+// A job starts once every job it depends on is done, so work a job depends
+// on is complete, and merged, before the job begins. Jobs ready at the same
+// time start in topological order, ties broken by their position in the
+// input. When a job fails, the jobs depending on it are skipped; the others
+// keep running unless Options.StopOnFailure is set.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrCycle is returned when the jobs' dependencies form a cycle.
+var ErrCycle = errors.New("dependency cycle")
+
+// ErrBudgetSpent is the reason a job was not started once the budget was spent.
+var ErrBudgetSpent = errors.New("budget spent")
+
+// Job is a unit of work in the graph.
+type Job struct {
+	ID        string
+	DependsOn []string // IDs of jobs that must be done first
+}
+
+// Result is what running a job returns.
+type Result struct {
+	Err     error   // nil when the job is done
+	CostUSD float64 // Spend counted against Options.BudgetUSD
+}
+
+// Status is how a job ended.
+type Status string
+
+const (
+	StatusDone       Status = "done"
+	StatusFailed     Status = "failed"
+	StatusSkipped    Status = "skipped"     // A dependency failed or was skipped
+	StatusNotStarted Status = "not_started" // Stopped by a failure, the budget or cancellation
+)
+
+// Outcome is how one job ended.
+type Outcome struct {
+	Job     Job
+	Status  Status
+	Err     error // Failure, or why the job was skipped or not started
+	CostUSD float64
+}
+
+// Options bounds a run.
+type Options struct {
+	MaxParallel   int     // Jobs running at once (values below 1 mean 1)
+	BudgetUSD     float64 // No job starts once the jobs' cost reaches this (0 = unlimited)
+	StopOnFailure bool    // Start no further jobs after a failure
+}
+
+// RunFunc runs one job. budget is what is left of Options.BudgetUSD when
+// the job starts, or 0 when the run is unlimited. It is called from its own
+// goroutine.
+type RunFunc func(ctx context.Context, job Job, budget float64) Result
+
+// Order returns the jobs in topological order, keeping the input order
+// where dependencies allow. It fails on unknown dependencies and cycles.
+func Order(jobs []Job) ([]Job, error) {
+	index := make(map[string]int, len(jobs))
+	for i, job := range jobs {
+		if _, ok := index[job.ID]; ok {
+			return nil, fmt.Errorf("duplicate job %s", job.ID)
+		}
+		index[job.ID] = i
+	}
+	for _, job := range jobs {
+		for _, dep := range job.DependsOn {
+			if _, ok := index[dep]; !ok {
+				return nil, fmt.Errorf("job %s depends on unknown job %s", job.ID, dep)
+			}
+		}
+	}
+
+	ordered := make([]Job, 0, len(jobs))
+	placed := make(map[string]bool, len(jobs))
+	for len(ordered) < len(jobs) {
+		progress := false
+		for _, job := range jobs {
+			if placed[job.ID] || !allPlaced(job.DependsOn, placed) {
+				continue
+			}
+			ordered = append(ordered, job)
+			placed[job.ID] = true
+			progress = true
+
+			// Restart so earlier jobs unblocked by this one come first
+			break
+		}
+		if !progress {
+			var stuck []string
+			for _, job := range jobs {
+				if !placed[job.ID] {
+					stuck = append(stuck, job.ID)
+				}
+			}
+
+			return nil, fmt.Errorf("%w between jobs %v", ErrCycle, stuck)
+		}
+	}
+
+	return ordered, nil
+}
+
+func allPlaced(ids []string, placed map[string]bool) bool {
+	for _, id := range ids {
+		if !placed[id] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// finished is a job's result sent back to the scheduling loop.
+type finished struct {
+	id     string
+	result Result
+}
+
+// Run runs the jobs, returning their outcomes in topological order. The
+// error is only set when the graph is invalid; failed jobs are reported in
+// the outcomes.
+func Run(ctx context.Context, jobs []Job, opts Options, run RunFunc) ([]Outcome, error) {
+	order, err := Order(jobs)
+	if err != nil {
+		return nil, err
+	}
+	parallel := max(opts.MaxParallel, 1)
+
+	outcomes := make(map[string]*Outcome, len(order))
+	for _, job := range order {
+		outcomes[job.ID] = &Outcome{Job: job}
+	}
+
+	results := make(chan finished)
+	running := 0
+	var spent float64
+	var stopReason error
+	for {
+		for _, job := range order {
+			if running >= parallel {
+				break
+			}
+			outcome := outcomes[job.ID]
+			if outcome.Status != "" {
+				continue
+			}
+
+			ready := true
+			for _, dep := range job.DependsOn {
+				switch outcomes[dep].Status {
+				case StatusDone:
+				case StatusFailed, StatusSkipped:
+					outcome.Status = StatusSkipped
+					outcome.Err = fmt.Errorf("dependency %s %s", dep, outcomes[dep].Status)
+				default:
+					ready = false
+				}
+				if outcome.Status != "" {
+					break
+				}
+			}
+			if outcome.Status != "" || !ready {
+				continue
+			}
+
+			if stopReason == nil && ctx.Err() != nil {
+				stopReason = ctx.Err()
+			}
+			if stopReason == nil && opts.BudgetUSD > 0 && spent >= opts.BudgetUSD {
+				stopReason = fmt.Errorf("%w: $%.2f of $%.2f", ErrBudgetSpent, spent, opts.BudgetUSD)
+			}
+			if stopReason != nil {
+				continue
+			}
+
+			var budget float64
+			if opts.BudgetUSD > 0 {
+				budget = opts.BudgetUSD - spent
+			}
+			outcome.Status = "running"
+			running++
+			go func() {
+				results <- finished{id: job.ID, result: run(ctx, job, budget)}
+			}()
+		}
+		if running == 0 {
+			break
+		}
+
+		f := <-results
+		running--
+		spent += f.result.CostUSD
+		outcome := outcomes[f.id]
+		outcome.CostUSD = f.result.CostUSD
+		if f.result.Err != nil {
+			outcome.Status = StatusFailed
+			outcome.Err = f.result.Err
+			if opts.StopOnFailure && stopReason == nil {
+				stopReason = fmt.Errorf("job %s failed", f.id)
+			}
+		} else {
+			outcome.Status = StatusDone
+		}
+	}
+
+	ended := make([]Outcome, 0, len(order))
+	for _, job := range order {
+		outcome := outcomes[job.ID]
+		if outcome.Status == "" {
+			outcome.Status = StatusNotStarted
+			outcome.Err = stopReason
+		}
+		ended = append(ended, *outcome)
+	}
+
+	return ended, nil
+}
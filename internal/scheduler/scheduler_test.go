@@ -0,0 +1,162 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func ids(jobs []Job) []string {
+	out := make([]string, len(jobs))
+	for i, job := range jobs {
+		out[i] = job.ID
+	}
+
+	return out
+}
+
+func TestOrder(t *testing.T) {
+	jobs := []Job{
+		{ID: "api", DependsOn: []string{"schema"}},
+		{ID: "docs"},
+		{ID: "schema"},
+		{ID: "ui", DependsOn: []string{"api", "docs"}},
+	}
+	ordered, err := Order(jobs)
+	if err != nil {
+		t.Fatalf("Order: %v", err)
+	}
+	if got, want := ids(ordered), []string{"docs", "schema", "api", "ui"}; !slices.Equal(got, want) {
+		t.Errorf("Order = %v, want %v", got, want)
+	}
+
+	if _, err := Order([]Job{{ID: "a", DependsOn: []string{"b"}}, {ID: "b", DependsOn: []string{"a"}}}); !errors.Is(err, ErrCycle) {
+		t.Errorf("Order(cycle) = %v, want ErrCycle", err)
+	}
+	if _, err := Order([]Job{{ID: "a", DependsOn: []string{"missing"}}}); err == nil {
+		t.Error("Order(unknown dependency): want error")
+	}
+	if _, err := Order([]Job{{ID: "a"}, {ID: "a"}}); err == nil {
+		t.Error("Order(duplicate): want error")
+	}
+}
+
+// recorder runs jobs, remembering start order and peak concurrency.
+type recorder struct {
+	mu      sync.Mutex
+	started []string
+	running int
+	peak    int
+	fail    map[string]bool
+}
+
+func (r *recorder) run(_ context.Context, job Job, _ float64) Result {
+	r.mu.Lock()
+	r.started = append(r.started, job.ID)
+	r.running++
+	r.peak = max(r.peak, r.running)
+	r.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	r.mu.Lock()
+	r.running--
+	r.mu.Unlock()
+	if r.fail[job.ID] {
+		return Result{Err: errors.New("boom")}
+	}
+
+	return Result{}
+}
+
+func statuses(outcomes []Outcome) map[string]Status {
+	out := make(map[string]Status, len(outcomes))
+	for _, o := range outcomes {
+		out[o.Job.ID] = o.Status
+	}
+
+	return out
+}
+
+func TestRun_DependenciesAndParallelism(t *testing.T) {
+	jobs := []Job{
+		{ID: "a"},
+		{ID: "b"},
+		{ID: "c"},
+		{ID: "d", DependsOn: []string{"a", "b"}},
+	}
+	r := &recorder{}
+	outcomes, err := Run(context.Background(), jobs, Options{MaxParallel: 2}, r.run)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	for id, status := range statuses(outcomes) {
+		if status != StatusDone {
+			t.Errorf("%s: status = %s, want done", id, status)
+		}
+	}
+	if r.peak != 2 {
+		t.Errorf("peak concurrency = %d, want 2", r.peak)
+	}
+	if !slices.Equal(r.started[:2], []string{"a", "b"}) && !slices.Equal(r.started[:2], []string{"b", "a"}) {
+		t.Errorf("started = %v, want a and b first", r.started)
+	}
+	if slices.Index(r.started, "d") < 2 {
+		t.Errorf("started = %v, want d after its dependencies", r.started)
+	}
+}
+
+func TestRun_FailureSkipsDependents(t *testing.T) {
+	jobs := []Job{
+		{ID: "a"},
+		{ID: "b", DependsOn: []string{"a"}},
+		{ID: "c", DependsOn: []string{"b"}},
+		{ID: "d"},
+	}
+	r := &recorder{fail: map[string]bool{"a": true}}
+	outcomes, err := Run(context.Background(), jobs, Options{MaxParallel: 1}, r.run)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	want := map[string]Status{"a": StatusFailed, "b": StatusSkipped, "c": StatusSkipped, "d": StatusDone}
+	if got := statuses(outcomes); !maps.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+
+	r = &recorder{fail: map[string]bool{"a": true}}
+	outcomes, _ = Run(context.Background(), jobs, Options{MaxParallel: 1, StopOnFailure: true}, r.run)
+	if got := statuses(outcomes)["d"]; got != StatusNotStarted {
+		t.Errorf("d with StopOnFailure: status = %s, want not_started", got)
+	}
+}
+
+func TestRun_Budget(t *testing.T) {
+	jobs := []Job{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	var budgets []float64
+	var mu sync.Mutex
+	run := func(_ context.Context, _ Job, budget float64) Result {
+		mu.Lock()
+		budgets = append(budgets, budget)
+		mu.Unlock()
+
+		return Result{CostUSD: 3}
+	}
+
+	outcomes, err := Run(context.Background(), jobs, Options{MaxParallel: 1, BudgetUSD: 5}, run)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !slices.Equal(budgets, []float64{5, 2}) {
+		t.Errorf("budgets = %v, want [5 2]", budgets)
+	}
+	last := outcomes[2]
+	if last.Status != StatusNotStarted || !errors.Is(last.Err, ErrBudgetSpent) {
+		t.Errorf("c = %+v, want not started for the budget", last)
+	}
+}
@@ -0,0 +1,846 @@
+package script
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// failError is the error raised by fail(), reported without the builtin's name.
+type failError struct {
+	msg string
+}
+
+func (e *failError) Error() string {
+	return e.msg
+}
+
+// universe holds the builtins every script can use. None of them can reach
+// the filesystem, the network or the environment.
+var universe map[string]any
+
+func init() {
+	universe = map[string]any{
+		"all":       &Builtin{name: "all", fn: builtinAll},
+		"any":       &Builtin{name: "any", fn: builtinAny},
+		"bool":      &Builtin{name: "bool", fn: builtinBool},
+		"dict":      &Builtin{name: "dict", fn: builtinDict},
+		"enumerate": &Builtin{name: "enumerate", fn: builtinEnumerate},
+		"fail":      &Builtin{name: "fail", fn: builtinFail},
+		"getattr":   &Builtin{name: "getattr", fn: builtinGetattr},
+		"hasattr":   &Builtin{name: "hasattr", fn: builtinHasattr},
+		"int":       &Builtin{name: "int", fn: builtinInt},
+		"len":       &Builtin{name: "len", fn: builtinLen},
+		"list":      &Builtin{name: "list", fn: builtinList},
+		"max":       &Builtin{name: "max", fn: builtinMinMax(1)},
+		"min":       &Builtin{name: "min", fn: builtinMinMax(-1)},
+		"print":     &Builtin{name: "print", fn: builtinPrint},
+		"range":     &Builtin{name: "range", fn: builtinRange},
+		"repr":      &Builtin{name: "repr", fn: builtinRepr},
+		"reversed":  &Builtin{name: "reversed", fn: builtinReversed},
+		"sorted":    &Builtin{name: "sorted", fn: builtinSorted},
+		"str":       &Builtin{name: "str", fn: builtinStr},
+		"type":      &Builtin{name: "type", fn: builtinType},
+	}
+}
+
+// unpack checks a builtin's arguments against its parameter names, of which
+// the first required must be given, and returns them in parameter order.
+// Missing optional parameters are nil.
+func unpack(args []any, kwargs []kwarg, required int, params ...string) ([]any, error) {
+	if len(args) > len(params) {
+		return nil, fmt.Errorf("got %d arguments, want at most %d", len(args), len(params))
+	}
+
+	out := make([]any, len(params))
+	given := make([]bool, len(params))
+	for i, arg := range args {
+		out[i], given[i] = arg, true
+	}
+	for _, kw := range kwargs {
+		i := slices.Index(params, kw.name)
+		if i < 0 {
+			return nil, fmt.Errorf("unexpected keyword argument %s", kw.name)
+		}
+		if given[i] {
+			return nil, fmt.Errorf("got multiple values for %s", kw.name)
+		}
+		out[i], given[i] = kw.value, true
+	}
+	for i := range required {
+		if !given[i] {
+			return nil, fmt.Errorf("missing argument %s", params[i])
+		}
+	}
+
+	return out, nil
+}
+
+func noKwargs(kwargs []kwarg) error {
+	if len(kwargs) > 0 {
+		return fmt.Errorf("unexpected keyword argument %s", kwargs[0].name)
+	}
+
+	return nil
+}
+
+func asString(v any, what string) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string, not %s", what, typeName(v))
+	}
+
+	return s, nil
+}
+
+func asInt(v any, what string) (int64, error) {
+	i, ok := v.(int64)
+	if !ok {
+		return 0, fmt.Errorf("%s must be an int, not %s", what, typeName(v))
+	}
+
+	return i, nil
+}
+
+func builtinAll(_ *thread, args []any, kwargs []kwarg) (any, error) {
+	a, err := unpack(args, kwargs, 1, "x")
+	if err != nil {
+		return nil, err
+	}
+	elems, err := iterate(a[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return !slices.ContainsFunc(elems, func(e any) bool { return !truth(e) }), nil
+}
+
+func builtinAny(_ *thread, args []any, kwargs []kwarg) (any, error) {
+	a, err := unpack(args, kwargs, 1, "x")
+	if err != nil {
+		return nil, err
+	}
+	elems, err := iterate(a[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.ContainsFunc(elems, truth), nil
+}
+
+func builtinBool(_ *thread, args []any, kwargs []kwarg) (any, error) {
+	a, err := unpack(args, kwargs, 0, "x")
+	if err != nil {
+		return nil, err
+	}
+
+	return truth(a[0]), nil
+}
+
+func builtinDict(_ *thread, args []any, kwargs []kwarg) (any, error) {
+	d := newDict()
+	if len(args) > 1 {
+		return nil, fmt.Errorf("got %d arguments, want at most 1", len(args))
+	}
+	if len(args) == 1 {
+		if src, ok := args[0].(*Dict); ok {
+			for _, k := range src.keys {
+				_ = d.set(k, src.values[k])
+			}
+		} else {
+			pairs, err := iterate(args[0])
+			if err != nil {
+				return nil, err
+			}
+			for _, pair := range pairs {
+				kv, err := iterate(pair)
+				if err != nil || len(kv) != 2 {
+					return nil, errors.New("dict elements must be key, value pairs")
+				}
+				if err := d.set(kv[0], kv[1]); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	for _, kw := range kwargs {
+		_ = d.set(kw.name, kw.value)
+	}
+
+	return d, nil
+}
+
+func builtinEnumerate(_ *thread, args []any, kwargs []kwarg) (any, error) {
+	a, err := unpack(args, kwargs, 1, "x", "start")
+	if err != nil {
+		return nil, err
+	}
+	elems, err := iterate(a[0])
+	if err != nil {
+		return nil, err
+	}
+	var start int64
+	if a[1] != nil {
+		if start, err = asInt(a[1], "start"); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]any, len(elems))
+	for i, e := range elems {
+		out[i] = Tuple{start + int64(i), e}
+	}
+
+	return newList(out), nil
+}
+
+func builtinFail(_ *thread, args []any, kwargs []kwarg) (any, error) {
+	if err := noKwargs(kwargs); err != nil {
+		return nil, err
+	}
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = str(arg)
+	}
+
+	return nil, &failError{msg: strings.Join(parts, " ")}
+}
+
+func builtinGetattr(_ *thread, args []any, kwargs []kwarg) (any, error) {
+	a, err := unpack(args, kwargs, 2, "x", "name", "default")
+	if err != nil {
+		return nil, err
+	}
+	name, err := asString(a[1], "name")
+	if err != nil {
+		return nil, err
+	}
+	v, err := attr(a[0], name)
+	if err != nil && len(args)+len(kwargs) == 3 {
+		return a[2], nil
+	}
+
+	return v, err
+}
+
+func builtinHasattr(_ *thread, args []any, kwargs []kwarg) (any, error) {
+	a, err := unpack(args, kwargs, 2, "x", "name")
+	if err != nil {
+		return nil, err
+	}
+	name, err := asString(a[1], "name")
+	if err != nil {
+		return nil, err
+	}
+	_, err = attr(a[0], name)
+
+	return err == nil, nil
+}
+
+func builtinInt(_ *thread, args []any, kwargs []kwarg) (any, error) {
+	a, err := unpack(args, kwargs, 1, "x")
+	if err != nil {
+		return nil, err
+	}
+	switch v := a[0].(type) {
+	case int64:
+		return v, nil
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+
+		return int64(0), nil
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid literal %s", repr(v))
+		}
+
+		return i, nil
+	}
+
+	return nil, fmt.Errorf("cannot convert %s to int", typeName(a[0]))
+}
+
+func builtinLen(_ *thread, args []any, kwargs []kwarg) (any, error) {
+	a, err := unpack(args, kwargs, 1, "x")
+	if err != nil {
+		return nil, err
+	}
+	switch v := a[0].(type) {
+	case string:
+		return int64(len(v)), nil
+	case Tuple:
+		return int64(len(v)), nil
+	case *List:
+		return int64(len(v.elems)), nil
+	case *Dict:
+		return int64(len(v.keys)), nil
+	}
+
+	return nil, fmt.Errorf("%s has no len", typeName(a[0]))
+}
+
+func builtinList(_ *thread, args []any, kwargs []kwarg) (any, error) {
+	a, err := unpack(args, kwargs, 0, "x")
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return newList(nil), nil
+	}
+	elems, err := iterate(a[0])
+
+	return newList(elems), err
+}
+
+func builtinMinMax(sign int) func(*thread, []any, []kwarg) (any, error) {
+	return func(_ *thread, args []any, kwargs []kwarg) (any, error) {
+		if err := noKwargs(kwargs); err != nil {
+			return nil, err
+		}
+		elems := args
+		if len(args) == 1 {
+			var err error
+			if elems, err = iterate(args[0]); err != nil {
+				return nil, err
+			}
+		}
+		if len(elems) == 0 {
+			return nil, errors.New("empty sequence")
+		}
+
+		best := elems[0]
+		for _, e := range elems[1:] {
+			c, err := compare(e, best)
+			if err != nil {
+				return nil, err
+			}
+			if c*sign > 0 {
+				best = e
+			}
+		}
+
+		return best, nil
+	}
+}
+
+func builtinPrint(th *thread, args []any, kwargs []kwarg) (any, error) {
+	if err := noKwargs(kwargs); err != nil {
+		return nil, err
+	}
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = str(arg)
+	}
+	if th.print != nil {
+		th.print(strings.Join(parts, " "))
+	}
+
+	return nil, nil
+}
+
+func builtinRange(_ *thread, args []any, kwargs []kwarg) (any, error) {
+	if err := noKwargs(kwargs); err != nil {
+		return nil, err
+	}
+	if len(args) == 0 || len(args) > 3 {
+		return nil, fmt.Errorf("got %d arguments, want 1 to 3", len(args))
+	}
+	bounds := make([]int64, len(args))
+	for i, arg := range args {
+		var err error
+		if bounds[i], err = asInt(arg, "range argument"); err != nil {
+			return nil, err
+		}
+	}
+
+	start, stop, step := int64(0), bounds[0], int64(1)
+	if len(bounds) > 1 {
+		start, stop = bounds[0], bounds[1]
+	}
+	if len(bounds) > 2 {
+		step = bounds[2]
+	}
+	if step == 0 {
+		return nil, errors.New("step cannot be zero")
+	}
+
+	var out []any
+	for i := start; step > 0 && i < stop || step < 0 && i > stop; i += step {
+		if len(out) >= maxLen {
+			return nil, errors.New("range too large")
+		}
+		out = append(out, i)
+	}
+
+	return newList(out), nil
+}
+
+func builtinRepr(_ *thread, args []any, kwargs []kwarg) (any, error) {
+	a, err := unpack(args, kwargs, 1, "x")
+	if err != nil {
+		return nil, err
+	}
+
+	return repr(a[0]), nil
+}
+
+func builtinReversed(_ *thread, args []any, kwargs []kwarg) (any, error) {
+	a, err := unpack(args, kwargs, 1, "x")
+	if err != nil {
+		return nil, err
+	}
+	elems, err := iterate(a[0])
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(elems)
+
+	return newList(elems), nil
+}
+
+func builtinSorted(th *thread, args []any, kwargs []kwarg) (any, error) {
+	a, err := unpack(args, kwargs, 1, "x", "key", "reverse")
+	if err != nil {
+		return nil, err
+	}
+	elems, err := iterate(a[0])
+	if err != nil {
+		return nil, err
+	}
+
+	keys := elems
+	if a[1] != nil {
+		keys = make([]any, len(elems))
+		for i, e := range elems {
+			if keys[i], err = th.call(a[1], []any{e}, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	order := make([]int, len(elems))
+	for i := range order {
+		order[i] = i
+	}
+	var cmpErr error
+	slices.SortStableFunc(order, func(i, j int) int {
+		c, err := compare(keys[i], keys[j])
+		if err != nil && cmpErr == nil {
+			cmpErr = err
+		}
+		if truth(a[2]) {
+			return -c
+		}
+
+		return c
+	})
+	if cmpErr != nil {
+		return nil, cmpErr
+	}
+
+	out := make([]any, len(order))
+	for i, idx := range order {
+		out[i] = elems[idx]
+	}
+
+	return newList(out), nil
+}
+
+func builtinStr(_ *thread, args []any, kwargs []kwarg) (any, error) {
+	a, err := unpack(args, kwargs, 0, "x")
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return "", nil
+	}
+
+	return str(a[0]), nil
+}
+
+func builtinType(_ *thread, args []any, kwargs []kwarg) (any, error) {
+	a, err := unpack(args, kwargs, 1, "x")
+	if err != nil {
+		return nil, err
+	}
+
+	return typeName(a[0]), nil
+}
+
+// method returns the method name of v bound to v, or nil if it has none.
+func method(v any, name string) *Builtin {
+	var fn func(th *thread, args []any, kwargs []kwarg) (any, error)
+	switch v := v.(type) {
+	case string:
+		fn = stringMethod(v, name)
+	case *List:
+		fn = listMethod(v, name)
+	case *Dict:
+		fn = dictMethod(v, name)
+	}
+	if fn == nil {
+		return nil
+	}
+
+	return &Builtin{name: typeName(v) + "." + name, fn: fn}
+}
+
+func stringMethod(s, name string) func(*thread, []any, []kwarg) (any, error) {
+	simple := func(f func(string) any) func(*thread, []any, []kwarg) (any, error) {
+		return func(_ *thread, args []any, kwargs []kwarg) (any, error) {
+			if _, err := unpack(args, kwargs, 0); err != nil {
+				return nil, err
+			}
+
+			return f(s), nil
+		}
+	}
+	trim := func(f func(string, string) string, space func(string) string) func(*thread, []any, []kwarg) (any, error) {
+		return func(_ *thread, args []any, kwargs []kwarg) (any, error) {
+			a, err := unpack(args, kwargs, 0, "chars")
+			if err != nil {
+				return nil, err
+			}
+			if a[0] == nil {
+				return space(s), nil
+			}
+			chars, err := asString(a[0], "chars")
+			if err != nil {
+				return nil, err
+			}
+
+			return f(s, chars), nil
+		}
+	}
+	affix := func(f func(string, string) bool) func(*thread, []any, []kwarg) (any, error) {
+		return func(_ *thread, args []any, kwargs []kwarg) (any, error) {
+			a, err := unpack(args, kwargs, 1, "x")
+			if err != nil {
+				return nil, err
+			}
+			candidates := []any{a[0]}
+			if t, ok := a[0].(Tuple); ok {
+				candidates = t
+			}
+			for _, c := range candidates {
+				cs, err := asString(c, "argument")
+				if err != nil {
+					return nil, err
+				}
+				if f(s, cs) {
+					return true, nil
+				}
+			}
+
+			return false, nil
+		}
+	}
+
+	switch name {
+	case "lower":
+		return simple(func(s string) any { return strings.ToLower(s) })
+	case "upper":
+		return simple(func(s string) any { return strings.ToUpper(s) })
+	case "isdigit":
+		return simple(func(s string) any {
+			return s != "" && strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' }) < 0
+		})
+	case "splitlines":
+		return simple(func(s string) any {
+			var out []any
+			for line := range strings.Lines(s) {
+				out = append(out, strings.TrimRight(line, "\r\n"))
+			}
+
+			return newList(out)
+		})
+	case "strip":
+		return trim(strings.Trim, strings.TrimSpace)
+	case "lstrip":
+		return trim(strings.TrimLeft, func(s string) string { return strings.TrimLeft(s, " \t\r\n") })
+	case "rstrip":
+		return trim(strings.TrimRight, func(s string) string { return strings.TrimRight(s, " \t\r\n") })
+	case "startswith":
+		return affix(strings.HasPrefix)
+	case "endswith":
+		return affix(strings.HasSuffix)
+	case "count", "find":
+		return func(_ *thread, args []any, kwargs []kwarg) (any, error) {
+			a, err := unpack(args, kwargs, 1, "sub")
+			if err != nil {
+				return nil, err
+			}
+			sub, err := asString(a[0], "sub")
+			if err != nil {
+				return nil, err
+			}
+			if name == "count" {
+				return int64(strings.Count(s, sub)), nil
+			}
+
+			return int64(strings.Index(s, sub)), nil
+		}
+	case "replace":
+		return func(_ *thread, args []any, kwargs []kwarg) (any, error) {
+			a, err := unpack(args, kwargs, 2, "old", "new", "count")
+			if err != nil {
+				return nil, err
+			}
+			old, err := asString(a[0], "old")
+			if err != nil {
+				return nil, err
+			}
+			repl, err := asString(a[1], "new")
+			if err != nil {
+				return nil, err
+			}
+			n := int64(-1)
+			if a[2] != nil {
+				if n, err = asInt(a[2], "count"); err != nil {
+					return nil, err
+				}
+			}
+
+			return strings.Replace(s, old, repl, int(n)), nil
+		}
+	case "split":
+		return func(_ *thread, args []any, kwargs []kwarg) (any, error) {
+			a, err := unpack(args, kwargs, 0, "sep")
+			if err != nil {
+				return nil, err
+			}
+			var parts []string
+			if a[0] == nil {
+				parts = strings.Fields(s)
+			} else {
+				sep, err := asString(a[0], "sep")
+				if err != nil {
+					return nil, err
+				}
+				if sep == "" {
+					return nil, errors.New("empty separator")
+				}
+				parts = strings.Split(s, sep)
+			}
+			out := make([]any, len(parts))
+			for i, p := range parts {
+				out[i] = p
+			}
+
+			return newList(out), nil
+		}
+	case "join":
+		return func(_ *thread, args []any, kwargs []kwarg) (any, error) {
+			a, err := unpack(args, kwargs, 1, "x")
+			if err != nil {
+				return nil, err
+			}
+			elems, err := iterate(a[0])
+			if err != nil {
+				return nil, err
+			}
+			parts := make([]string, len(elems))
+			for i, e := range elems {
+				if parts[i], err = asString(e, "join element"); err != nil {
+					return nil, err
+				}
+			}
+			if len(parts) > 0 && len(s)*(len(parts)-1) > maxLen {
+				return nil, errors.New("string too long")
+			}
+
+			return strings.Join(parts, s), nil
+		}
+	case "format":
+		return func(_ *thread, args []any, kwargs []kwarg) (any, error) {
+			return format(s, args, kwargs)
+		}
+	}
+
+	return nil
+}
+
+// format implements str.format with {}, {0} and {name} fields.
+func format(s string, args []any, kwargs []kwarg) (any, error) {
+	var sb strings.Builder
+	auto := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "{{"), strings.HasPrefix(s[i:], "}}"):
+			sb.WriteByte(s[i])
+			i++
+		case s[i] == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return nil, errors.New("unmatched '{' in format string")
+			}
+			field := s[i+1 : i+end]
+			i += end
+
+			var v any
+			switch n, err := strconv.Atoi(field); {
+			case field == "":
+				if auto >= len(args) {
+					return nil, errors.New("not enough arguments for format string")
+				}
+				v = args[auto]
+				auto++
+			case err == nil:
+				if n < 0 || n >= len(args) {
+					return nil, fmt.Errorf("format index %d out of range", n)
+				}
+				v = args[n]
+			default:
+				i := slices.IndexFunc(kwargs, func(kw kwarg) bool { return kw.name == field })
+				if i < 0 {
+					return nil, fmt.Errorf("keyword %s not given to format", field)
+				}
+				v = kwargs[i].value
+			}
+			sb.WriteString(str(v))
+		case s[i] == '}':
+			return nil, errors.New("single '}' in format string")
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+
+	return sb.String(), nil
+}
+
+func listMethod(l *List, name string) func(*thread, []any, []kwarg) (any, error) {
+	mutable := func(f func(a []any) (any, error), required int, params ...string) func(*thread, []any, []kwarg) (any, error) {
+		return func(_ *thread, args []any, kwargs []kwarg) (any, error) {
+			if l.frozen {
+				return nil, errors.New("cannot modify a frozen list")
+			}
+			a, err := unpack(args, kwargs, required, params...)
+			if err != nil {
+				return nil, err
+			}
+
+			return f(a)
+		}
+	}
+
+	switch name {
+	case "append":
+		return mutable(func(a []any) (any, error) {
+			l.elems = append(l.elems, a[0])
+
+			return nil, nil
+		}, 1, "x")
+	case "extend":
+		return mutable(func(a []any) (any, error) {
+			elems, err := iterate(a[0])
+			if err != nil {
+				return nil, err
+			}
+			l.elems = append(l.elems, elems...)
+
+			return nil, nil
+		}, 1, "x")
+	case "pop":
+		return mutable(func(a []any) (any, error) {
+			index := any(int64(-1))
+			if a[0] != nil {
+				index = a[0]
+			}
+			i, err := seqIndex(index, len(l.elems))
+			if err != nil {
+				return nil, err
+			}
+			v := l.elems[i]
+			l.elems = slices.Delete(l.elems, i, i+1)
+
+			return v, nil
+		}, 0, "index")
+	case "index":
+		return func(_ *thread, args []any, kwargs []kwarg) (any, error) {
+			a, err := unpack(args, kwargs, 1, "x")
+			if err != nil {
+				return nil, err
+			}
+			i := slices.IndexFunc(l.elems, func(e any) bool { return equal(e, a[0]) })
+			if i < 0 {
+				return nil, fmt.Errorf("%s not in list", repr(a[0]))
+			}
+
+			return int64(i), nil
+		}
+	}
+
+	return nil
+}
+
+func dictMethod(d *Dict, name string) func(*thread, []any, []kwarg) (any, error) {
+	switch name {
+	case "get":
+		return func(_ *thread, args []any, kwargs []kwarg) (any, error) {
+			a, err := unpack(args, kwargs, 1, "key", "default")
+			if err != nil {
+				return nil, err
+			}
+			v, ok, err := d.get(a[0])
+			if err != nil || !ok {
+				return a[1], err
+			}
+
+			return v, nil
+		}
+	case "keys":
+		return func(_ *thread, args []any, kwargs []kwarg) (any, error) {
+			if _, err := unpack(args, kwargs, 0); err != nil {
+				return nil, err
+			}
+
+			return newList(slices.Clone(d.keys)), nil
+		}
+	case "values", "items":
+		return func(_ *thread, args []any, kwargs []kwarg) (any, error) {
+			if _, err := unpack(args, kwargs, 0); err != nil {
+				return nil, err
+			}
+			out := make([]any, len(d.keys))
+			for i, k := range d.keys {
+				if name == "items" {
+					out[i] = Tuple{k, d.values[k]}
+				} else {
+					out[i] = d.values[k]
+				}
+			}
+
+			return newList(out), nil
+		}
+	case "pop":
+		return func(_ *thread, args []any, kwargs []kwarg) (any, error) {
+			if d.frozen {
+				return nil, errors.New("cannot modify a frozen dict")
+			}
+			a, err := unpack(args, kwargs, 1, "key", "default")
+			if err != nil {
+				return nil, err
+			}
+			if !hashable(a[0]) {
+				return nil, fmt.Errorf("unhashable type: %s", typeName(a[0]))
+			}
+			v, ok := d.delete(a[0])
+			if !ok {
+				if len(args)+len(kwargs) == 2 {
+					return a[1], nil
+				}
+
+				return nil, fmt.Errorf("key %s not found", repr(a[0]))
+			}
+
+			return v, nil
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,868 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// maxSteps bounds the statements and loop iterations of one call, so a
+// script cannot stall the workflow. It is a variable so tests can lower it.
+var maxSteps = 1_000_000
+
+// maxLen bounds lists and strings built by repetition or range.
+const maxLen = 1 << 20
+
+// module is the global environment of one loaded script.
+type module struct {
+	file        string
+	globals     map[string]any
+	predeclared map[string]any // Functions only available to this module, such as guard
+}
+
+// thread is one call into scripts: it counts steps and carries the context
+// and output of the caller.
+type thread struct {
+	ctx   context.Context
+	steps int
+	stack []*Function
+	print func(msg string)
+}
+
+func (th *thread) tick() error {
+	th.steps++
+	if th.steps > maxSteps {
+		return fmt.Errorf("script exceeded %d steps", maxSteps)
+	}
+	if th.steps%1024 == 0 && th.ctx.Err() != nil {
+		return th.ctx.Err()
+	}
+
+	return nil
+}
+
+// env resolves names: locals, then enclosing comprehension scopes, then
+// module globals, predeclared functions and builtins.
+type env struct {
+	module *module
+	locals map[string]any // nil at the top level of a module
+	parent *env
+}
+
+func (e *env) lookup(name string) (any, bool) {
+	for s := e; s != nil; s = s.parent {
+		if v, ok := s.locals[name]; ok {
+			return v, true
+		}
+	}
+	if v, ok := e.module.globals[name]; ok {
+		return v, true
+	}
+	if v, ok := e.module.predeclared[name]; ok {
+		return v, true
+	}
+	v, ok := universe[name]
+
+	return v, ok
+}
+
+func (e *env) assign(name string, v any) {
+	if e.locals != nil {
+		e.locals[name] = v
+
+		return
+	}
+	e.module.globals[name] = v
+}
+
+type flow int
+
+const (
+	flowNormal flow = iota
+	flowBreak
+	flowContinue
+	flowReturn
+)
+
+func (th *thread) execBlock(e *env, stmts []stmt) (flow, any, error) {
+	for _, s := range stmts {
+		f, v, err := th.exec(e, s)
+		if err != nil {
+			return flowNormal, nil, err
+		}
+		if f != flowNormal {
+			return f, v, nil
+		}
+	}
+
+	return flowNormal, nil, nil
+}
+
+func (th *thread) exec(e *env, s stmt) (flow, any, error) {
+	f, v, err := th.execStmt(e, s)
+	if err != nil {
+		var se *Error
+		if !errors.As(err, &se) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			err = &Error{File: e.module.file, Line: s.stmtLine(), Msg: err.Error()}
+		}
+	}
+
+	return f, v, err
+}
+
+func (th *thread) execStmt(e *env, s stmt) (flow, any, error) {
+	if err := th.tick(); err != nil {
+		return flowNormal, nil, err
+	}
+
+	switch s := s.(type) {
+	case *exprStmt:
+		_, err := th.eval(e, s.x)
+
+		return flowNormal, nil, err
+	case *assignStmt:
+		return flowNormal, nil, th.assign(e, s)
+	case *returnStmt:
+		if s.value == nil {
+			return flowReturn, nil, nil
+		}
+		v, err := th.eval(e, s.value)
+
+		return flowReturn, v, err
+	case *branchStmt:
+		switch s.kind {
+		case "break":
+			return flowBreak, nil, nil
+		case "continue":
+			return flowContinue, nil, nil
+		}
+
+		return flowNormal, nil, nil
+	case *ifStmt:
+		cond, err := th.eval(e, s.cond)
+		if err != nil {
+			return flowNormal, nil, err
+		}
+		if truth(cond) {
+			return th.execBlock(e, s.then)
+		}
+
+		return th.execBlock(e, s.els)
+	case *forStmt:
+		iterable, err := th.eval(e, s.iter)
+		if err != nil {
+			return flowNormal, nil, err
+		}
+		elems, err := iterate(iterable)
+		if err != nil {
+			return flowNormal, nil, err
+		}
+		for _, elem := range elems {
+			if err := th.tick(); err != nil {
+				return flowNormal, nil, err
+			}
+			if err := bindTargets(e, s.targets, elem); err != nil {
+				return flowNormal, nil, err
+			}
+			f, v, err := th.execBlock(e, s.body)
+			if err != nil || f == flowReturn {
+				return f, v, err
+			}
+			if f == flowBreak {
+				break
+			}
+		}
+
+		return flowNormal, nil, nil
+	case *defStmt:
+		fn := &Function{def: s, module: e.module, defaults: make([]any, len(s.defaults))}
+		for i, d := range s.defaults {
+			if d == nil {
+				continue
+			}
+			v, err := th.eval(e, d)
+			if err != nil {
+				return flowNormal, nil, err
+			}
+			fn.defaults[i] = v
+		}
+		e.assign(s.name, fn)
+
+		return flowNormal, nil, nil
+	}
+
+	return flowNormal, nil, fmt.Errorf("unknown statement %T", s)
+}
+
+func bindTargets(e *env, targets []string, v any) error {
+	if len(targets) == 1 {
+		e.assign(targets[0], v)
+
+		return nil
+	}
+
+	elems, err := iterate(v)
+	if err != nil {
+		return fmt.Errorf("cannot unpack %s", typeName(v))
+	}
+	if len(elems) != len(targets) {
+		return fmt.Errorf("cannot unpack %d values into %d variables", len(elems), len(targets))
+	}
+	for i, name := range targets {
+		e.assign(name, elems[i])
+	}
+
+	return nil
+}
+
+func (th *thread) assign(e *env, s *assignStmt) error {
+	value, err := th.eval(e, s.value)
+	if err != nil {
+		return err
+	}
+
+	if s.op != "=" {
+		current, err := th.eval(e, s.target)
+		if err != nil {
+			return err
+		}
+		if value, err = binary(strings.TrimSuffix(s.op, "="), current, value); err != nil {
+			return err
+		}
+	}
+
+	switch target := s.target.(type) {
+	case *identExpr:
+		e.assign(target.name, value)
+	case *tupleExpr:
+		names := make([]string, len(target.elems))
+		for i, elem := range target.elems {
+			names[i] = elem.(*identExpr).name
+		}
+
+		return bindTargets(e, names, value)
+	case *indexExpr:
+		x, err := th.eval(e, target.x)
+		if err != nil {
+			return err
+		}
+		index, err := th.eval(e, target.index)
+		if err != nil {
+			return err
+		}
+
+		return setIndex(x, index, value)
+	}
+
+	return nil
+}
+
+func setIndex(x, index, value any) error {
+	switch x := x.(type) {
+	case *Dict:
+		return x.set(index, value)
+	case *List:
+		if x.frozen {
+			return errors.New("cannot modify a frozen list")
+		}
+		i, err := seqIndex(index, len(x.elems))
+		if err != nil {
+			return err
+		}
+		x.elems[i] = value
+
+		return nil
+	}
+
+	return fmt.Errorf("%s does not support item assignment", typeName(x))
+}
+
+func (th *thread) eval(e *env, x expr) (any, error) {
+	switch x := x.(type) {
+	case *literalExpr:
+		return x.value, nil
+	case *identExpr:
+		v, ok := e.lookup(x.name)
+		if !ok {
+			return nil, fmt.Errorf("undefined: %s", x.name)
+		}
+
+		return v, nil
+	case *listExpr:
+		elems, err := th.evalAll(e, x.elems)
+
+		return newList(elems), err
+	case *tupleExpr:
+		elems, err := th.evalAll(e, x.elems)
+
+		return Tuple(elems), err
+	case *dictExpr:
+		d := newDict()
+		for i := range x.keys {
+			k, err := th.eval(e, x.keys[i])
+			if err != nil {
+				return nil, err
+			}
+			v, err := th.eval(e, x.values[i])
+			if err != nil {
+				return nil, err
+			}
+			if err := d.set(k, v); err != nil {
+				return nil, err
+			}
+		}
+
+		return d, nil
+	case *compExpr:
+		return th.comprehension(e, x)
+	case *unaryExpr:
+		v, err := th.eval(e, x.x)
+		if err != nil {
+			return nil, err
+		}
+
+		return unary(x.op, v)
+	case *binaryExpr:
+		return th.binaryExpr(e, x)
+	case *condExpr:
+		cond, err := th.eval(e, x.cond)
+		if err != nil {
+			return nil, err
+		}
+		if truth(cond) {
+			return th.eval(e, x.then)
+		}
+
+		return th.eval(e, x.els)
+	case *callExpr:
+		fn, err := th.eval(e, x.fn)
+		if err != nil {
+			return nil, err
+		}
+		args, err := th.evalAll(e, x.args)
+		if err != nil {
+			return nil, err
+		}
+		kwargs := make([]kwarg, len(x.kwargs))
+		for i, kw := range x.kwargs {
+			v, err := th.eval(e, kw.value)
+			if err != nil {
+				return nil, err
+			}
+			kwargs[i] = kwarg{name: kw.name, value: v}
+		}
+
+		return th.call(fn, args, kwargs)
+	case *dotExpr:
+		v, err := th.eval(e, x.x)
+		if err != nil {
+			return nil, err
+		}
+
+		return attr(v, x.name)
+	case *indexExpr:
+		v, err := th.eval(e, x.x)
+		if err != nil {
+			return nil, err
+		}
+		index, err := th.eval(e, x.index)
+		if err != nil {
+			return nil, err
+		}
+
+		return getIndex(v, index)
+	case *sliceExpr:
+		return th.slice(e, x)
+	}
+
+	return nil, fmt.Errorf("unknown expression %T", x)
+}
+
+func (th *thread) evalAll(e *env, exprs []expr) ([]any, error) {
+	values := make([]any, len(exprs))
+	for i, x := range exprs {
+		v, err := th.eval(e, x)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	return values, nil
+}
+
+func (th *thread) comprehension(e *env, x *compExpr) (any, error) {
+	iterable, err := th.eval(e, x.iter)
+	if err != nil {
+		return nil, err
+	}
+	elems, err := iterate(iterable)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := &env{module: e.module, locals: make(map[string]any), parent: e}
+	var out []any
+	for _, elem := range elems {
+		if err := th.tick(); err != nil {
+			return nil, err
+		}
+		if err := bindTargets(scope, x.targets, elem); err != nil {
+			return nil, err
+		}
+		if x.cond != nil {
+			ok, err := th.eval(scope, x.cond)
+			if err != nil {
+				return nil, err
+			}
+			if !truth(ok) {
+				continue
+			}
+		}
+		v, err := th.eval(scope, x.elem)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+
+	return newList(out), nil
+}
+
+func (th *thread) binaryExpr(e *env, x *binaryExpr) (any, error) {
+	left, err := th.eval(e, x.x)
+	if err != nil {
+		return nil, err
+	}
+	switch x.op {
+	case "and":
+		if !truth(left) {
+			return left, nil
+		}
+
+		return th.eval(e, x.y)
+	case "or":
+		if truth(left) {
+			return left, nil
+		}
+
+		return th.eval(e, x.y)
+	}
+
+	right, err := th.eval(e, x.y)
+	if err != nil {
+		return nil, err
+	}
+
+	return binary(x.op, left, right)
+}
+
+func (th *thread) slice(e *env, x *sliceExpr) (any, error) {
+	v, err := th.eval(e, x.x)
+	if err != nil {
+		return nil, err
+	}
+
+	var n int
+	switch v := v.(type) {
+	case string:
+		n = len(v)
+	case Tuple:
+		n = len(v)
+	case *List:
+		n = len(v.elems)
+	default:
+		return nil, fmt.Errorf("%s cannot be sliced", typeName(v))
+	}
+
+	bound := func(b expr, def int) (int, error) {
+		if b == nil {
+			return def, nil
+		}
+		bv, err := th.eval(e, b)
+		if err != nil {
+			return 0, err
+		}
+		i, ok := bv.(int64)
+		if !ok {
+			return 0, fmt.Errorf("slice index must be int, not %s", typeName(bv))
+		}
+		if i < 0 {
+			i += int64(n)
+		}
+
+		return int(min(max(i, 0), int64(n))), nil
+	}
+	low, err := bound(x.low, 0)
+	if err != nil {
+		return nil, err
+	}
+	high, err := bound(x.high, n)
+	if err != nil {
+		return nil, err
+	}
+	high = max(high, low)
+
+	switch v := v.(type) {
+	case string:
+		return v[low:high], nil
+	case Tuple:
+		return slices.Clone(v[low:high]), nil
+	case *List:
+		return newList(slices.Clone(v.elems[low:high])), nil
+	}
+
+	return nil, nil
+}
+
+// call calls a script function or builtin.
+func (th *thread) call(fn any, args []any, kwargs []kwarg) (any, error) {
+	switch fn := fn.(type) {
+	case *Builtin:
+		v, err := fn.fn(th, args, kwargs)
+		var se *Error
+		var fe *failError
+		if err != nil && !errors.As(err, &se) && !errors.As(err, &fe) {
+			err = fmt.Errorf("%s: %w", fn.name, err)
+		}
+
+		return v, err
+	case *Function:
+		if slices.Contains(th.stack, fn) {
+			return nil, fmt.Errorf("function %s called recursively", fn.def.name)
+		}
+		locals, err := bindParams(fn, args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+
+		th.stack = append(th.stack, fn)
+		_, v, err := th.execBlock(&env{module: fn.module, locals: locals}, fn.def.body)
+		th.stack = th.stack[:len(th.stack)-1]
+
+		return v, err
+	}
+
+	return nil, fmt.Errorf("%s is not callable", typeName(fn))
+}
+
+func bindParams(fn *Function, args []any, kwargs []kwarg) (map[string]any, error) {
+	params := fn.def.params
+	if len(args) > len(params) {
+		return nil, fmt.Errorf("%s() takes %d arguments, got %d", fn.def.name, len(params), len(args))
+	}
+
+	locals := make(map[string]any, len(params))
+	for i, arg := range args {
+		locals[params[i]] = arg
+	}
+	for _, kw := range kwargs {
+		if !slices.Contains(params, kw.name) {
+			return nil, fmt.Errorf("%s() got an unexpected keyword argument %s", fn.def.name, kw.name)
+		}
+		if _, ok := locals[kw.name]; ok {
+			return nil, fmt.Errorf("%s() got multiple values for %s", fn.def.name, kw.name)
+		}
+		locals[kw.name] = kw.value
+	}
+	for i, name := range params {
+		if _, ok := locals[name]; ok {
+			continue
+		}
+		if fn.def.defaults[i] == nil {
+			return nil, fmt.Errorf("%s() missing argument %s", fn.def.name, name)
+		}
+		locals[name] = fn.defaults[i]
+	}
+
+	return locals, nil
+}
+
+func unary(op string, v any) (any, error) {
+	switch op {
+	case "not":
+		return !truth(v), nil
+	case "-", "+":
+		i, ok := v.(int64)
+		if !ok {
+			return nil, fmt.Errorf("unary %s on %s", op, typeName(v))
+		}
+		if op == "-" {
+			return -i, nil
+		}
+
+		return i, nil
+	}
+
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
+
+func binary(op string, x, y any) (any, error) {
+	switch op {
+	case "==":
+		return equal(x, y), nil
+	case "!=":
+		return !equal(x, y), nil
+	case "<", "<=", ">", ">=":
+		c, err := compare(x, y)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		}
+
+		return c >= 0, nil
+	case "in", "not in":
+		in, err := contains(y, x)
+		if err != nil {
+			return nil, err
+		}
+
+		return in == (op == "in"), nil
+	case "/":
+		return nil, errors.New("floats are not supported; use // for integer division")
+	}
+
+	if xi, ok := x.(int64); ok {
+		if yi, ok := y.(int64); ok {
+			return intOp(op, xi, yi)
+		}
+	}
+
+	switch op {
+	case "+":
+		switch x := x.(type) {
+		case string:
+			if y, ok := y.(string); ok {
+				if len(x)+len(y) > maxLen {
+					return nil, errors.New("string too long")
+				}
+
+				return x + y, nil
+			}
+		case *List:
+			if y, ok := y.(*List); ok {
+				return newList(slices.Concat(x.elems, y.elems)), nil
+			}
+		case Tuple:
+			if y, ok := y.(Tuple); ok {
+				return slices.Concat(x, y), nil
+			}
+		}
+	case "*":
+		if n, ok := y.(int64); ok {
+			return repeat(x, n)
+		}
+		if n, ok := x.(int64); ok {
+			return repeat(y, n)
+		}
+	case "%":
+		if format, ok := x.(string); ok {
+			return percent(format, y)
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported operand types for %s: %s and %s", op, typeName(x), typeName(y))
+}
+
+func intOp(op string, x, y int64) (any, error) {
+	switch op {
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	case "//", "%":
+		if y == 0 {
+			return nil, errors.New("division by zero")
+		}
+		q, r := x/y, x%y
+		if r != 0 && (r < 0) != (y < 0) {
+			q--
+			r += y
+		}
+		if op == "//" {
+			return q, nil
+		}
+
+		return r, nil
+	}
+
+	return nil, fmt.Errorf("unsupported operand types for %s: int and int", op)
+}
+
+func repeat(v any, n int64) (any, error) {
+	n = max(n, 0)
+	switch v := v.(type) {
+	case string:
+		if len(v) > 0 && n > maxLen/int64(len(v)) {
+			return nil, errors.New("string too long")
+		}
+
+		return strings.Repeat(v, int(n)), nil
+	case *List:
+		if len(v.elems) > 0 && n > maxLen/int64(len(v.elems)) {
+			return nil, errors.New("list too long")
+		}
+		out := make([]any, 0, len(v.elems)*int(n))
+		for range n {
+			out = append(out, v.elems...)
+		}
+
+		return newList(out), nil
+	}
+
+	return nil, fmt.Errorf("unsupported operand types for *: %s and int", typeName(v))
+}
+
+// contains reports whether needle is in haystack: a substring of a string,
+// an element of a list or tuple, or a key of a dict.
+func contains(haystack, needle any) (bool, error) {
+	switch h := haystack.(type) {
+	case string:
+		s, ok := needle.(string)
+		if !ok {
+			return false, fmt.Errorf("'in <string>' requires a string, not %s", typeName(needle))
+		}
+
+		return strings.Contains(h, s), nil
+	case Tuple:
+		return slices.ContainsFunc(h, func(e any) bool { return equal(e, needle) }), nil
+	case *List:
+		return slices.ContainsFunc(h.elems, func(e any) bool { return equal(e, needle) }), nil
+	case *Dict:
+		_, ok, err := h.get(needle)
+
+		return ok, err
+	}
+
+	return false, fmt.Errorf("'in' not supported on %s", typeName(haystack))
+}
+
+// percent implements "format" % args with %s, %r, %d and %%.
+func percent(format string, arg any) (any, error) {
+	args := []any{arg}
+	if t, ok := arg.(Tuple); ok {
+		args = t
+	}
+
+	var sb strings.Builder
+	n := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			sb.WriteByte(format[i])
+
+			continue
+		}
+		i++
+		if i >= len(format) {
+			return nil, errors.New("incomplete format")
+		}
+		verb := format[i]
+		if verb == '%' {
+			sb.WriteByte('%')
+
+			continue
+		}
+		if n >= len(args) {
+			return nil, errors.New("not enough arguments for format string")
+		}
+		switch verb {
+		case 's':
+			sb.WriteString(str(args[n]))
+		case 'r':
+			sb.WriteString(repr(args[n]))
+		case 'd':
+			d, ok := args[n].(int64)
+			if !ok {
+				return nil, fmt.Errorf("%%d format requires int, not %s", typeName(args[n]))
+			}
+			fmt.Fprint(&sb, d)
+		default:
+			return nil, fmt.Errorf("unsupported format character %q", verb)
+		}
+		n++
+	}
+	if n < len(args) {
+		return nil, errors.New("not all arguments converted during string formatting")
+	}
+
+	return sb.String(), nil
+}
+
+func seqIndex(index any, n int) (int, error) {
+	i, ok := index.(int64)
+	if !ok {
+		return 0, fmt.Errorf("index must be int, not %s", typeName(index))
+	}
+	if i < 0 {
+		i += int64(n)
+	}
+	if i < 0 || i >= int64(n) {
+		return 0, fmt.Errorf("index %d out of range", index)
+	}
+
+	return int(i), nil
+}
+
+func getIndex(v, index any) (any, error) {
+	switch v := v.(type) {
+	case string:
+		i, err := seqIndex(index, len(v))
+		if err != nil {
+			return nil, err
+		}
+
+		return v[i : i+1], nil
+	case Tuple:
+		i, err := seqIndex(index, len(v))
+		if err != nil {
+			return nil, err
+		}
+
+		return v[i], nil
+	case *List:
+		i, err := seqIndex(index, len(v.elems))
+		if err != nil {
+			return nil, err
+		}
+
+		return v.elems[i], nil
+	case *Dict:
+		value, ok, err := v.get(index)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("key %s not found", repr(index))
+		}
+
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("%s is not indexable", typeName(v))
+}
+
+func attr(v any, name string) (any, error) {
+	if s, ok := v.(*Struct); ok {
+		if f, ok := s.fields[name]; ok {
+			return f, nil
+		}
+
+		return nil, fmt.Errorf("%s has no field %s", s.name, name)
+	}
+	if m := method(v, name); m != nil {
+		return m, nil
+	}
+
+	return nil, fmt.Errorf("%s has no attribute %s", typeName(v), name)
+}
@@ -0,0 +1,164 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// run executes src as a module and returns its globals.
+func run(t *testing.T, src string) (map[string]any, error) {
+	t.Helper()
+
+	stmts, err := parse("test.star", src)
+	if err != nil {
+		return nil, err
+	}
+	m := &module{file: "test.star", globals: make(map[string]any)}
+	th := &thread{ctx: context.Background()}
+	_, _, err = th.execBlock(&env{module: m}, stmts)
+
+	return m.globals, err
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string // repr of the global "x"
+	}{
+		{"arithmetic", "x = 1 + 2 * 3 - 4 // 3", "6"},
+		{"floored division", "x = (-7 // 2, -7 % 2)", "(-4, 1)"},
+		{"string concat", `x = "a" + 'b' * 3`, `"abbb"`},
+		{"percent format", `x = "%s=%d %r%%" % ("n", 3, "q")`, `"n=3 \"q\"%"`},
+		{"comparison", "x = [1 < 2, 2 <= 1, 'a' == 'a', 1 != 1]", "[True, False, True, False]"},
+		{"membership", "x = [2 in [1, 2], 'b' in 'abc', 'k' not in {'k': 1}]", "[True, True, False]"},
+		{"short circuit", "x = (0 or 'y', 1 and 0, None or [])", `("y", 0, [])`},
+		{"conditional", "x = 'yes' if 2 > 1 else 'no'", `"yes"`},
+		{"comprehension", "x = [i * i for i in range(6) if i % 2 == 0]", "[0, 4, 16]"},
+		{"slice", "x = ([1, 2, 3, 4][1:3], 'hello'[:2], (1, 2, 3)[-2:])", `([2, 3], "he", (2, 3))`},
+		{"dict order", "x = {'b': 1, 'a': 2}\nx['c'] = 3", `{"b": 1, "a": 2, "c": 3}`},
+		{"augmented", "x = [1]\nx += [2]\nx[0] *= 5", "[5, 2]"},
+		{"unpack", "a, b = 1, 2\na, b = b, a\nx = (a, b)", "(2, 1)"},
+		{"for break continue", "x = []\nfor i in range(10):\n    if i == 2:\n        continue\n    if i == 4:\n        break\n    x.append(i)", "[0, 1, 3]"},
+		{"elif", "def f(n):\n    if n < 0:\n        return 'neg'\n    elif n == 0:\n        return 'zero'\n    else:\n        return 'pos'\nx = [f(-1), f(0), f(1)]", `["neg", "zero", "pos"]`},
+		{"defaults and kwargs", "def f(a, b = 2, c = 3):\n    return a + b * c\nx = (f(1), f(1, c = 10), f(a = 0, b = 1))", "(7, 21, 3)"},
+		{"closures read globals", "n = 10\ndef f():\n    return n + 1\nx = f()", "11"},
+		{"string methods", `x = " A,b ".strip().lower().split(",")`, `["a", "b"]`},
+		{"join and format", `x = "-".join(["a", "b"]) + " {} {name}".format(1, name = "n")`, `"a-b 1 n"`},
+		{"sorted", "x = sorted(['bb', 'a', 'ccc'], key = len, reverse = True)", `["ccc", "bb", "a"]`},
+		{"builtins", "x = (len('abc'), max(3, 1, 2), min([4, 5]), str(1), int('42'), type({}), any([0, 1]), all([]))", `(3, 3, 4, "1", 42, "dict", True, True)`},
+		{"enumerate", "x = [i + v for i, v in enumerate([10, 20])]", "[10, 21]"},
+		{"dict methods", "d = {'a': 1}\nx = (d.get('a'), d.get('z', 0), d.keys(), d.items())", `(1, 0, ["a"], [("a", 1)])`},
+		{"tuple one", "x = (1,)", "(1,)"},
+		{"triple quoted", "x = '''a\n  b'''", `"a\n  b"`},
+		{"line continuation", "x = 1 + \\\n    2", "3"},
+		{"brackets span lines", "x = [\n    1,\n    2,\n]", "[1, 2]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			globals, err := run(t, tt.src)
+			if err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			if got := repr(globals["x"]); got != tt.want {
+				t.Errorf("x = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEval_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"undefined", "x = y", "test.star:1: undefined: y"},
+		{"type mismatch", "x = 1 + 'a'", "test.star:1:"},
+		{"division", "x = 1 / 2", "test.star:1:"},
+		{"zero division", "x = 1 // 0", "division by zero"},
+		{"index", "x = [1][3]", "test.star:1:"},
+		{"fail", "fail('stop', 1)", "test.star:1: stop 1"},
+		{"recursion", "def f(n):\n    return f(n)\nx = f(1)", "called recursively"},
+		{"slice step", "x = 'abc'[::2]", "test.star:1:"},
+		{"while", "while True:\n    pass", "test.star:1:"},
+		{"load", "load('x.star', 'y')", "test.star:1:"},
+		{"lambda", "f = lambda: 1", "test.star:1:"},
+		{"chained comparison", "x = 1 < 2 < 3", "test.star:1:"},
+		{"return outside def", "return 1", "test.star:1:"},
+		{"break outside loop", "break", "test.star:1:"},
+		{"break in nested def", "for i in []:\n    def f():\n        break", "test.star:3:"},
+		{"nested def", "def f():\n    def g():\n        pass", "test.star:2:"},
+		{"tab indentation", "if True:\n\tx = 1", "test.star:2:"},
+		{"unterminated string", "x = 'abc", "test.star:1:"},
+		{"strings not iterable", "for c in 'abc':\n    pass", "not iterable"},
+		{"unhashable key", "x = {[]: 1}", "unhashable"},
+		{"huge repeat", "x = 'a' * 100000000", "test.star:1:"},
+		{"overflowing repeat", "x = 'ab' * 4611686018427387904", "string too long"},
+		{"overflowing list repeat", "x = [1, 2] * 4611686018427387904", "list too long"},
+		{"mutate while iterating", "x = [1]\nfor i in x:\n    x.append(i)", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := run(t, tt.src)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("run: %v", err)
+				}
+
+				return
+			}
+			if err == nil {
+				t.Fatalf("run succeeded, want error %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEval_StepBudget(t *testing.T) {
+	defer func(n int) { maxSteps = n }(maxSteps)
+	maxSteps = 1000
+
+	_, err := run(t, "x = 0\nfor i in range(100000):\n    x += 1")
+	if err == nil || !strings.Contains(err.Error(), "step") {
+		t.Fatalf("error = %v, want the step budget exceeded", err)
+	}
+}
+
+func TestEval_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	stmts, err := parse("test.star", "x = 0\nfor i in range(100000):\n    x += 1")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	th := &thread{ctx: ctx}
+	_, _, err = th.execBlock(&env{module: &module{file: "test.star", globals: make(map[string]any)}}, stmts)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+}
+
+func TestEval_Frozen(t *testing.T) {
+	globals, err := run(t, "x = {'k': [1]}")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	freeze(globals["x"])
+
+	inner, _, _ := globals["x"].(*Dict).get("k")
+	if err := globals["x"].(*Dict).set("k", nil); err == nil {
+		t.Error("set on a frozen dict succeeded")
+	}
+	if _, err := (&thread{ctx: context.Background()}).call(method(inner, "append"), []any{int64(2)}, nil); err == nil {
+		t.Error("append to a frozen list succeeded")
+	}
+}
@@ -0,0 +1,299 @@
+package script
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNewline
+	tokIndent
+	tokDedent
+	tokName
+	tokInt
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string // Name, operator or decoded string
+	num  int64
+	line int
+}
+
+// keywords may not be used as names. Starlark reserves more words than it
+// uses, so scripts stay valid if the dialect grows.
+var keywords = map[string]bool{
+	"and": true, "break": true, "continue": true, "def": true, "elif": true, "else": true,
+	"for": true, "if": true, "in": true, "not": true, "or": true, "pass": true, "return": true,
+	"None": true, "True": true, "False": true,
+	"as": true, "assert": true, "class": true, "del": true, "except": true, "finally": true,
+	"from": true, "global": true, "import": true, "is": true, "lambda": true, "load": true,
+	"nonlocal": true, "raise": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+// operators, longest first so that "//=" is not read as "/".
+var operators = []string{
+	"//=", "==", "!=", "<=", ">=", "+=", "-=", "*=", "//", "**",
+	"+", "-", "*", "/", "%", "<", ">", "=", "(", ")", "[", "]", "{", "}", ",", ":", ".",
+}
+
+// lexer turns source into tokens, with NEWLINE, INDENT and DEDENT tokens
+// for the block structure. Newlines and indentation inside brackets are
+// ignored.
+type lexer struct {
+	file    string
+	src     string
+	pos     int
+	line    int
+	depth   int   // Open brackets
+	indents []int // Indentation of the enclosing blocks
+	tokens  []token
+}
+
+func tokenize(file, src string) ([]token, error) {
+	lx := &lexer{file: file, src: src, line: 1, indents: []int{0}}
+	if err := lx.run(); err != nil {
+		return nil, err
+	}
+
+	return lx.tokens, nil
+}
+
+func (lx *lexer) errorf(format string, args ...any) error {
+	return &Error{File: lx.file, Line: lx.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (lx *lexer) emit(kind tokenKind, text string) {
+	lx.tokens = append(lx.tokens, token{kind: kind, text: text, line: lx.line})
+}
+
+func (lx *lexer) run() error {
+	atLineStart := true
+	for {
+		if atLineStart && lx.depth == 0 {
+			if err := lx.indentation(); err != nil {
+				return err
+			}
+			atLineStart = false
+		}
+		if lx.pos >= len(lx.src) {
+			break
+		}
+
+		ch := lx.src[lx.pos]
+		switch {
+		case ch == '\n':
+			if lx.depth == 0 && len(lx.tokens) > 0 && lx.tokens[len(lx.tokens)-1].kind != tokNewline {
+				lx.emit(tokNewline, "")
+			}
+			lx.pos++
+			lx.line++
+			atLineStart = lx.depth == 0
+		case ch == ' ' || ch == '\t' || ch == '\r':
+			lx.pos++
+		case ch == '#':
+			for lx.pos < len(lx.src) && lx.src[lx.pos] != '\n' {
+				lx.pos++
+			}
+		case ch == '\\' && strings.HasPrefix(lx.src[lx.pos:], "\\\n"):
+			lx.pos += 2
+			lx.line++
+		case ch == '"' || ch == '\'':
+			if err := lx.str(); err != nil {
+				return err
+			}
+		case isDigit(ch):
+			if err := lx.number(); err != nil {
+				return err
+			}
+		case isLetter(ch):
+			start := lx.pos
+			for lx.pos < len(lx.src) && (isLetter(lx.src[lx.pos]) || isDigit(lx.src[lx.pos])) {
+				lx.pos++
+			}
+			lx.emit(tokName, lx.src[start:lx.pos])
+		default:
+			if err := lx.operator(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(lx.tokens) > 0 && lx.tokens[len(lx.tokens)-1].kind != tokNewline {
+		lx.emit(tokNewline, "")
+	}
+	for len(lx.indents) > 1 {
+		lx.indents = lx.indents[:len(lx.indents)-1]
+		lx.emit(tokDedent, "")
+	}
+	lx.emit(tokEOF, "")
+
+	return nil
+}
+
+// indentation reads the leading spaces of a line and emits INDENT or
+// DEDENT tokens when they differ from the enclosing block. Blank and
+// comment-only lines are skipped.
+func (lx *lexer) indentation() error {
+	for {
+		width := 0
+		for lx.pos < len(lx.src) && (lx.src[lx.pos] == ' ' || lx.src[lx.pos] == '\t') {
+			if lx.src[lx.pos] == '\t' {
+				return lx.errorf("tabs are not allowed in indentation")
+			}
+			width++
+			lx.pos++
+		}
+		if lx.pos >= len(lx.src) {
+			return nil
+		}
+		switch lx.src[lx.pos] {
+		case '\r':
+			lx.pos++
+
+			continue
+		case '\n':
+			lx.pos++
+			lx.line++
+
+			continue
+		case '#':
+			for lx.pos < len(lx.src) && lx.src[lx.pos] != '\n' {
+				lx.pos++
+			}
+
+			continue
+		}
+
+		current := lx.indents[len(lx.indents)-1]
+		switch {
+		case width > current:
+			lx.indents = append(lx.indents, width)
+			lx.emit(tokIndent, "")
+		case width < current:
+			for width < lx.indents[len(lx.indents)-1] {
+				lx.indents = lx.indents[:len(lx.indents)-1]
+				lx.emit(tokDedent, "")
+			}
+			if width != lx.indents[len(lx.indents)-1] {
+				return lx.errorf("unindent does not match any outer indentation level")
+			}
+		}
+
+		return nil
+	}
+}
+
+func (lx *lexer) operator() error {
+	for _, op := range operators {
+		if strings.HasPrefix(lx.src[lx.pos:], op) {
+			switch op {
+			case "(", "[", "{":
+				lx.depth++
+			case ")", "]", "}":
+				if lx.depth > 0 {
+					lx.depth--
+				}
+			case "**":
+				return lx.errorf("operator ** is not supported")
+			}
+			lx.emit(tokOp, op)
+			lx.pos += len(op)
+
+			return nil
+		}
+	}
+
+	return lx.errorf("unexpected character %q", lx.src[lx.pos])
+}
+
+func (lx *lexer) number() error {
+	start := lx.pos
+	for lx.pos < len(lx.src) && (isDigit(lx.src[lx.pos]) || lx.src[lx.pos] == '_') {
+		lx.pos++
+	}
+	if lx.pos < len(lx.src) && (lx.src[lx.pos] == '.' || isLetter(lx.src[lx.pos])) {
+		return lx.errorf("invalid number literal (only decimal integers are supported)")
+	}
+
+	var n int64
+	for _, d := range strings.ReplaceAll(lx.src[start:lx.pos], "_", "") {
+		if n > (1<<62)/10 {
+			return lx.errorf("integer literal out of range")
+		}
+		n = n*10 + int64(d-'0')
+	}
+	lx.tokens = append(lx.tokens, token{kind: tokInt, num: n, line: lx.line})
+
+	return nil
+}
+
+// str reads a single, double or triple quoted string literal.
+func (lx *lexer) str() error {
+	quote := lx.src[lx.pos : lx.pos+1]
+	if strings.HasPrefix(lx.src[lx.pos:], strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+	startLine := lx.line
+	lx.pos += len(quote)
+
+	var sb strings.Builder
+	for {
+		if lx.pos >= len(lx.src) {
+			lx.line = startLine
+
+			return lx.errorf("unterminated string literal")
+		}
+		if strings.HasPrefix(lx.src[lx.pos:], quote) {
+			lx.pos += len(quote)
+
+			break
+		}
+
+		ch := lx.src[lx.pos]
+		switch {
+		case ch == '\n' && len(quote) == 1:
+			return lx.errorf("unterminated string literal")
+		case ch == '\n':
+			lx.line++
+			sb.WriteByte(ch)
+			lx.pos++
+		case ch == '\\' && lx.pos+1 < len(lx.src):
+			esc := lx.src[lx.pos+1]
+			lx.pos += 2
+			switch esc {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case '\\', '\'', '"':
+				sb.WriteByte(esc)
+			case '\n':
+				lx.line++
+			default:
+				return lx.errorf("invalid escape sequence \\%c", esc)
+			}
+		default:
+			sb.WriteByte(ch)
+			lx.pos++
+		}
+	}
+	lx.tokens = append(lx.tokens, token{kind: tokString, text: sb.String(), line: startLine})
+
+	return nil
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isLetter(ch byte) bool {
+	return ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
@@ -0,0 +1,885 @@
+package script
+
+import "fmt"
+
+// Statements.
+type (
+	stmt interface{ stmtLine() int }
+
+	defStmt struct {
+		line     int
+		name     string
+		params   []string
+		defaults []expr // Defaults of the trailing params, nil where there is none
+		body     []stmt
+	}
+	ifStmt struct {
+		line int
+		cond expr
+		then []stmt
+		els  []stmt // An elif is an ifStmt alone in els
+	}
+	forStmt struct {
+		line    int
+		targets []string
+		iter    expr
+		body    []stmt
+	}
+	returnStmt struct {
+		line  int
+		value expr // nil returns None
+	}
+	assignStmt struct {
+		line   int
+		op     string // "=" or an augmented operator such as "+="
+		target expr   // identExpr, indexExpr or tupleExpr of identExprs
+		value  expr
+	}
+	exprStmt struct {
+		line int
+		x    expr
+	}
+	branchStmt struct {
+		line int
+		kind string // "pass", "break" or "continue"
+	}
+)
+
+func (s *defStmt) stmtLine() int    { return s.line }
+func (s *ifStmt) stmtLine() int     { return s.line }
+func (s *forStmt) stmtLine() int    { return s.line }
+func (s *returnStmt) stmtLine() int { return s.line }
+func (s *assignStmt) stmtLine() int { return s.line }
+func (s *exprStmt) stmtLine() int   { return s.line }
+func (s *branchStmt) stmtLine() int { return s.line }
+
+// Expressions.
+type (
+	expr interface{ exprLine() int }
+
+	identExpr struct {
+		line int
+		name string
+	}
+	literalExpr struct {
+		line  int
+		value any
+	}
+	listExpr struct {
+		line  int
+		elems []expr
+	}
+	tupleExpr struct {
+		line  int
+		elems []expr
+	}
+	dictExpr struct {
+		line         int
+		keys, values []expr
+	}
+	compExpr struct { // [elem for targets in iter if cond]
+		line    int
+		elem    expr
+		targets []string
+		iter    expr
+		cond    expr
+	}
+	unaryExpr struct {
+		line int
+		op   string
+		x    expr
+	}
+	binaryExpr struct {
+		line int
+		op   string
+		x, y expr
+	}
+	condExpr struct { // then if cond else els
+		line            int
+		cond, then, els expr
+	}
+	callExpr struct {
+		line   int
+		fn     expr
+		args   []expr
+		kwargs []kwargExpr
+	}
+	kwargExpr struct {
+		name  string
+		value expr
+	}
+	dotExpr struct {
+		line int
+		x    expr
+		name string
+	}
+	indexExpr struct {
+		line     int
+		x, index expr
+	}
+	sliceExpr struct {
+		line      int
+		x         expr
+		low, high expr // nil when omitted
+	}
+)
+
+func (e *identExpr) exprLine() int   { return e.line }
+func (e *literalExpr) exprLine() int { return e.line }
+func (e *listExpr) exprLine() int    { return e.line }
+func (e *tupleExpr) exprLine() int   { return e.line }
+func (e *dictExpr) exprLine() int    { return e.line }
+func (e *compExpr) exprLine() int    { return e.line }
+func (e *unaryExpr) exprLine() int   { return e.line }
+func (e *binaryExpr) exprLine() int  { return e.line }
+func (e *condExpr) exprLine() int    { return e.line }
+func (e *callExpr) exprLine() int    { return e.line }
+func (e *dotExpr) exprLine() int     { return e.line }
+func (e *indexExpr) exprLine() int   { return e.line }
+func (e *sliceExpr) exprLine() int   { return e.line }
+
+// parser is a recursive descent parser for the Starlark subset.
+type parser struct {
+	file   string
+	tokens []token
+	pos    int
+	inDef  bool
+	inLoop bool
+}
+
+func parse(file, src string) ([]stmt, error) {
+	tokens, err := tokenize(file, src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{file: file, tokens: tokens}
+
+	var stmts []stmt
+	for p.peek().kind != tokEOF {
+		s, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, s)
+	}
+
+	return stmts, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &Error{File: p.file, Line: p.peek().line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// isOp reports whether the next token is the operator op.
+func (p *parser) isOp(op string) bool {
+	t := p.peek()
+
+	return t.kind == tokOp && t.text == op
+}
+
+// isKeyword reports whether the next token is the keyword kw.
+func (p *parser) isKeyword(kw string) bool {
+	t := p.peek()
+
+	return t.kind == tokName && t.text == kw
+}
+
+func (p *parser) expectOp(op string) error {
+	if !p.isOp(op) {
+		return p.errorf("expected %q, found %s", op, describe(p.peek()))
+	}
+	p.next()
+
+	return nil
+}
+
+func (p *parser) expectKeyword(kw string) error {
+	if !p.isKeyword(kw) {
+		return p.errorf("expected %q, found %s", kw, describe(p.peek()))
+	}
+	p.next()
+
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	t := p.peek()
+	if t.kind != tokName || keywords[t.text] {
+		return "", p.errorf("expected a name, found %s", describe(t))
+	}
+	p.next()
+
+	return t.text, nil
+}
+
+func describe(t token) string {
+	switch t.kind {
+	case tokEOF:
+		return "end of file"
+	case tokNewline:
+		return "end of line"
+	case tokIndent:
+		return "indentation"
+	case tokDedent:
+		return "unindent"
+	case tokInt:
+		return fmt.Sprintf("%d", t.num)
+	case tokString:
+		return fmt.Sprintf("%q", t.text)
+	}
+
+	return fmt.Sprintf("%q", t.text)
+}
+
+func (p *parser) statement() (stmt, error) {
+	switch {
+	case p.isKeyword("def"):
+		return p.def()
+	case p.isKeyword("if"):
+		return p.ifStatement()
+	case p.isKeyword("for"):
+		return p.forStatement()
+	case p.peek().kind == tokName && keywords[p.peek().text] && !isSimpleKeyword(p.peek().text):
+		return nil, p.errorf("%s is not supported", p.peek().text)
+	}
+
+	s, err := p.simpleStatement()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokNewline {
+		return nil, p.errorf("unexpected %s", describe(p.peek()))
+	}
+	p.next()
+
+	return s, nil
+}
+
+// isSimpleKeyword reports whether a keyword may start a simple statement.
+func isSimpleKeyword(kw string) bool {
+	switch kw {
+	case "return", "pass", "break", "continue", "not", "None", "True", "False":
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) simpleStatement() (stmt, error) {
+	line := p.peek().line
+	switch {
+	case p.isKeyword("return"):
+		if !p.inDef {
+			return nil, p.errorf("return outside a function")
+		}
+		p.next()
+		if p.peek().kind == tokNewline {
+			return &returnStmt{line: line}, nil
+		}
+		value, err := p.exprList()
+		if err != nil {
+			return nil, err
+		}
+
+		return &returnStmt{line: line, value: value}, nil
+	case p.isKeyword("pass"), p.isKeyword("break"), p.isKeyword("continue"):
+		kind := p.next().text
+		if kind != "pass" && !p.inLoop {
+			return nil, &Error{File: p.file, Line: line, Msg: kind + " outside a loop"}
+		}
+
+		return &branchStmt{line: line, kind: kind}, nil
+	}
+
+	x, err := p.exprList()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind == tokOp {
+		switch t.text {
+		case "=", "+=", "-=", "*=", "//=":
+			if err := checkTarget(x, t.text == "="); err != nil {
+				return nil, p.errorf("%v", err)
+			}
+			p.next()
+			value, err := p.exprList()
+			if err != nil {
+				return nil, err
+			}
+
+			return &assignStmt{line: line, op: t.text, target: x, value: value}, nil
+		}
+	}
+
+	return &exprStmt{line: line, x: x}, nil
+}
+
+// checkTarget reports whether x can be assigned to.
+func checkTarget(x expr, allowTuple bool) error {
+	switch x := x.(type) {
+	case *identExpr, *indexExpr:
+		return nil
+	case *tupleExpr:
+		if allowTuple {
+			for _, e := range x.elems {
+				if _, ok := e.(*identExpr); !ok {
+					return fmt.Errorf("can only unpack into names")
+				}
+			}
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("cannot assign to this expression")
+}
+
+func (p *parser) def() (stmt, error) {
+	line := p.next().line
+	if p.inDef {
+		return nil, p.errorf("nested functions are not supported")
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectOp("("); err != nil {
+		return nil, err
+	}
+
+	d := &defStmt{line: line, name: name}
+	for !p.isOp(")") {
+		param, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		var def expr
+		if p.isOp("=") {
+			p.next()
+			if def, err = p.expr(); err != nil {
+				return nil, err
+			}
+		} else if len(d.defaults) > 0 && d.defaults[len(d.defaults)-1] != nil {
+			return nil, p.errorf("parameter %s without a default follows one with a default", param)
+		}
+		d.params = append(d.params, param)
+		d.defaults = append(d.defaults, def)
+		if !p.isOp(",") {
+			break
+		}
+		p.next()
+	}
+	if err := p.expectOp(")"); err != nil {
+		return nil, err
+	}
+
+	inLoop := p.inLoop
+	p.inDef, p.inLoop = true, false
+	d.body, err = p.suite()
+	p.inDef, p.inLoop = false, inLoop
+
+	return d, err
+}
+
+func (p *parser) ifStatement() (stmt, error) {
+	line := p.next().line
+	cond, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	then, err := p.suite()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ifStmt{line: line, cond: cond, then: then}
+	switch {
+	case p.isKeyword("elif"):
+		elif, err := p.ifStatement()
+		if err != nil {
+			return nil, err
+		}
+		s.els = []stmt{elif}
+	case p.isKeyword("else"):
+		p.next()
+		if s.els, err = p.suite(); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (p *parser) forStatement() (stmt, error) {
+	line := p.next().line
+	targets, err := p.targets()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("in"); err != nil {
+		return nil, err
+	}
+	iter, err := p.exprList()
+	if err != nil {
+		return nil, err
+	}
+
+	inLoop := p.inLoop
+	p.inLoop = true
+	body, err := p.suite()
+	p.inLoop = inLoop
+
+	return &forStmt{line: line, targets: targets, iter: iter, body: body}, err
+}
+
+// targets parses the loop variables of a for loop or comprehension.
+func (p *parser) targets() ([]string, error) {
+	var targets []string
+	for {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, name)
+		if !p.isOp(",") {
+			return targets, nil
+		}
+		p.next()
+	}
+}
+
+// suite parses the block after a colon: an indented block, or a simple
+// statement on the same line.
+func (p *parser) suite() ([]stmt, error) {
+	if err := p.expectOp(":"); err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokNewline {
+		s, err := p.simpleStatement()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokNewline {
+			return nil, p.errorf("unexpected %s", describe(p.peek()))
+		}
+		p.next()
+
+		return []stmt{s}, nil
+	}
+	p.next()
+	if p.peek().kind != tokIndent {
+		return nil, p.errorf("expected an indented block")
+	}
+	p.next()
+
+	var body []stmt
+	for p.peek().kind != tokDedent && p.peek().kind != tokEOF {
+		s, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, s)
+	}
+	p.next()
+
+	return body, nil
+}
+
+// exprList parses one expression, or several separated by commas as a tuple.
+func (p *parser) exprList() (expr, error) {
+	line := p.peek().line
+	x, err := p.expr()
+	if err != nil || !p.isOp(",") {
+		return x, err
+	}
+
+	elems := []expr{x}
+	for p.isOp(",") {
+		p.next()
+		if p.peek().kind == tokNewline || p.isOp("=") || p.isOp(":") {
+			break
+		}
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, x)
+	}
+
+	return &tupleExpr{line: line, elems: elems}, nil
+}
+
+func (p *parser) expr() (expr, error) {
+	x, err := p.or()
+	if err != nil || !p.isKeyword("if") {
+		return x, err
+	}
+
+	line := p.next().line
+	cond, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("else"); err != nil {
+		return nil, err
+	}
+	els, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+
+	return &condExpr{line: line, cond: cond, then: x, els: els}, nil
+}
+
+func (p *parser) or() (expr, error) {
+	x, err := p.and()
+	for err == nil && p.isKeyword("or") {
+		line := p.next().line
+		var y expr
+		if y, err = p.and(); err == nil {
+			x = &binaryExpr{line: line, op: "or", x: x, y: y}
+		}
+	}
+
+	return x, err
+}
+
+func (p *parser) and() (expr, error) {
+	x, err := p.not()
+	for err == nil && p.isKeyword("and") {
+		line := p.next().line
+		var y expr
+		if y, err = p.not(); err == nil {
+			x = &binaryExpr{line: line, op: "and", x: x, y: y}
+		}
+	}
+
+	return x, err
+}
+
+func (p *parser) not() (expr, error) {
+	if p.isKeyword("not") {
+		line := p.next().line
+		x, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+
+		return &unaryExpr{line: line, op: "not", x: x}, nil
+	}
+
+	return p.comparison()
+}
+
+// comparison parses at most one comparison: like Starlark, a < b < c is an error.
+func (p *parser) comparison() (expr, error) {
+	x, err := p.arith()
+	if err != nil {
+		return nil, err
+	}
+
+	line := p.peek().line
+	var op string
+	switch {
+	case p.isOp("==") || p.isOp("!=") || p.isOp("<") || p.isOp("<=") || p.isOp(">") || p.isOp(">="):
+		op = p.next().text
+	case p.isKeyword("in"):
+		p.next()
+		op = "in"
+	case p.isKeyword("not") && p.tokens[p.pos+1].kind == tokName && p.tokens[p.pos+1].text == "in":
+		p.pos += 2
+		op = "not in"
+	default:
+		return x, nil
+	}
+
+	y, err := p.arith()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp("==") || p.isOp("!=") || p.isOp("<") || p.isOp("<=") || p.isOp(">") || p.isOp(">=") || p.isKeyword("in") {
+		return nil, p.errorf("comparisons cannot be chained")
+	}
+
+	return &binaryExpr{line: line, op: op, x: x, y: y}, nil
+}
+
+func (p *parser) arith() (expr, error) {
+	x, err := p.term()
+	for err == nil && (p.isOp("+") || p.isOp("-")) {
+		t := p.next()
+		var y expr
+		if y, err = p.term(); err == nil {
+			x = &binaryExpr{line: t.line, op: t.text, x: x, y: y}
+		}
+	}
+
+	return x, err
+}
+
+func (p *parser) term() (expr, error) {
+	x, err := p.unary()
+	for err == nil && (p.isOp("*") || p.isOp("/") || p.isOp("//") || p.isOp("%")) {
+		t := p.next()
+		var y expr
+		if y, err = p.unary(); err == nil {
+			x = &binaryExpr{line: t.line, op: t.text, x: x, y: y}
+		}
+	}
+
+	return x, err
+}
+
+func (p *parser) unary() (expr, error) {
+	if p.isOp("-") || p.isOp("+") {
+		t := p.next()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &unaryExpr{line: t.line, op: t.text, x: x}, nil
+	}
+
+	return p.postfix()
+}
+
+func (p *parser) postfix() (expr, error) {
+	x, err := p.primary()
+	for err == nil {
+		line := p.peek().line
+		switch {
+		case p.isOp("."):
+			p.next()
+			var name string
+			if name, err = p.name(); err == nil {
+				x = &dotExpr{line: line, x: x, name: name}
+			}
+		case p.isOp("("):
+			x, err = p.call(x)
+		case p.isOp("["):
+			x, err = p.index(x)
+		default:
+			return x, nil
+		}
+	}
+
+	return nil, err
+}
+
+func (p *parser) call(fn expr) (expr, error) {
+	call := &callExpr{line: p.next().line, fn: fn}
+	for !p.isOp(")") {
+		if t := p.peek(); t.kind == tokName && !keywords[t.text] && p.tokens[p.pos+1].kind == tokOp && p.tokens[p.pos+1].text == "=" {
+			p.pos += 2
+			value, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			call.kwargs = append(call.kwargs, kwargExpr{name: t.text, value: value})
+		} else {
+			if len(call.kwargs) > 0 {
+				return nil, p.errorf("positional argument follows keyword argument")
+			}
+			arg, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+		}
+		if !p.isOp(",") {
+			break
+		}
+		p.next()
+	}
+
+	return call, p.expectOp(")")
+}
+
+func (p *parser) index(x expr) (expr, error) {
+	line := p.next().line
+
+	var low, high expr
+	var err error
+	if !p.isOp(":") {
+		if low, err = p.expr(); err != nil {
+			return nil, err
+		}
+		if p.isOp("]") {
+			p.next()
+
+			return &indexExpr{line: line, x: x, index: low}, nil
+		}
+	}
+	if err := p.expectOp(":"); err != nil {
+		return nil, err
+	}
+	if !p.isOp("]") {
+		if high, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &sliceExpr{line: line, x: x, low: low, high: high}, p.expectOp("]")
+}
+
+func (p *parser) primary() (expr, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokInt:
+		p.next()
+
+		return &literalExpr{line: t.line, value: t.num}, nil
+	case tokString:
+		p.next()
+
+		return &literalExpr{line: t.line, value: t.text}, nil
+	case tokName:
+		switch t.text {
+		case "None":
+			p.next()
+
+			return &literalExpr{line: t.line}, nil
+		case "True", "False":
+			p.next()
+
+			return &literalExpr{line: t.line, value: t.text == "True"}, nil
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		return &identExpr{line: t.line, name: name}, nil
+	case tokOp:
+		switch t.text {
+		case "(":
+			return p.parenthesized()
+		case "[":
+			return p.list()
+		case "{":
+			return p.dict()
+		}
+	}
+
+	return nil, p.errorf("unexpected %s", describe(t))
+}
+
+func (p *parser) parenthesized() (expr, error) {
+	line := p.next().line
+	if p.isOp(")") {
+		p.next()
+
+		return &tupleExpr{line: line}, nil
+	}
+	x, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp(")") {
+		p.next()
+
+		return x, nil
+	}
+
+	elems := []expr{x}
+	for p.isOp(",") {
+		p.next()
+		if p.isOp(")") {
+			break
+		}
+		if x, err = p.expr(); err != nil {
+			return nil, err
+		}
+		elems = append(elems, x)
+	}
+
+	return &tupleExpr{line: line, elems: elems}, p.expectOp(")")
+}
+
+func (p *parser) list() (expr, error) {
+	line := p.next().line
+	if p.isOp("]") {
+		p.next()
+
+		return &listExpr{line: line}, nil
+	}
+	x, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.isKeyword("for") {
+		p.next()
+		comp := &compExpr{line: line, elem: x}
+		if comp.targets, err = p.targets(); err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("in"); err != nil {
+			return nil, err
+		}
+		if comp.iter, err = p.or(); err != nil {
+			return nil, err
+		}
+		if p.isKeyword("if") {
+			p.next()
+			if comp.cond, err = p.or(); err != nil {
+				return nil, err
+			}
+		}
+
+		return comp, p.expectOp("]")
+	}
+
+	elems := []expr{x}
+	for p.isOp(",") {
+		p.next()
+		if p.isOp("]") {
+			break
+		}
+		if x, err = p.expr(); err != nil {
+			return nil, err
+		}
+		elems = append(elems, x)
+	}
+
+	return &listExpr{line: line, elems: elems}, p.expectOp("]")
+}
+
+func (p *parser) dict() (expr, error) {
+	d := &dictExpr{line: p.next().line}
+	for !p.isOp("}") {
+		key, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		d.keys = append(d.keys, key)
+		d.values = append(d.values, value)
+		if !p.isOp(",") {
+			break
+		}
+		p.next()
+	}
+
+	return d, p.expectOp("}")
+}
@@ -0,0 +1,390 @@
+// Package script runs workflow scripts: small programs in a subset of
+// Starlark, kept in .mehrhof/scripts/*.star, that guard and hook workflow
+// transitions and rewrite agent prompts without a plugin process.
+//
+// A script registers what it provides while it loads:
+//
+//	def needs_tests(task):
+//	    if "test" not in task.source.lower():
+//	        return "the task does not say how to test it"
+//	    return True
+//
+//	guard(name = "needs-tests", state = "idle", event = "implement", check = needs_tests)
+//
+// The dialect has functions with default and keyword arguments, if/elif/else,
+// for loops, list comprehensions, conditional expressions, ints, strings,
+// lists, tuples and dicts, with the usual methods and builtins. It has no
+// floats, while loops, recursion, lambdas or load().
+//
+// Scripts cannot reach the filesystem, the network, the environment or the
+// clock: everything they see is passed to their functions. Each call is
+// bounded by a step budget and the caller's context, and the globals of a
+// script are frozen once it has loaded, so calls cannot affect each other.
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// Ext is the file extension of workflow scripts.
+const Ext = ".star"
+
+// Error is an error raised by a script, with the position it was raised at.
+type Error struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// promptSteps are the steps whose prompts scripts can rewrite.
+var promptSteps = []workflow.Step{
+	workflow.StepPlanning,
+	workflow.StepImplementing,
+	workflow.StepReviewing,
+	workflow.StepDocumenting,
+}
+
+type guardDef struct {
+	name  string // "<script>/<name>"
+	state workflow.State
+	event workflow.Event
+	order int
+	check *Function
+}
+
+type hookDef struct {
+	name     string // "<script>/<name>"
+	state    workflow.State
+	event    workflow.Event
+	order    int
+	critical bool
+	run      *Function
+}
+
+type promptDef struct {
+	step   workflow.Step
+	mutate *Function
+}
+
+// Set is the loaded scripts of a workspace.
+type Set struct {
+	output  func(msg string)
+	guards  []guardDef
+	hooks   []hookDef
+	prompts []promptDef
+}
+
+// Load loads every script in dir, in name order. A missing directory is an
+// empty set. output receives what scripts print; it may be nil.
+func Load(dir string, output func(msg string)) (*Set, error) {
+	s := &Set{output: output}
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read scripts: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != Ext {
+			continue
+		}
+		src, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read script: %w", err)
+		}
+		if err := s.load(entry.Name(), string(src)); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// load runs one script's top level, recording what it registers.
+func (s *Set) load(file, src string) (err error) {
+	defer recoverError(file, 0, &err)
+
+	stmts, err := parse(file, src)
+	if err != nil {
+		return err
+	}
+
+	loading := true
+	m := &module{file: file, globals: make(map[string]any)}
+	m.predeclared = s.registrations(strings.TrimSuffix(file, Ext), &loading)
+
+	th := s.thread(context.Background())
+	_, _, err = th.execBlock(&env{module: m}, stmts)
+	loading = false
+	if err != nil {
+		return err
+	}
+	for _, v := range m.globals {
+		freeze(v)
+	}
+
+	return nil
+}
+
+func (s *Set) thread(ctx context.Context) *thread {
+	return &thread{ctx: ctx, print: s.output}
+}
+
+// call calls a script function on a fresh thread.
+func (s *Set) call(ctx context.Context, fn *Function, args ...any) (result any, err error) {
+	defer recoverError(fn.module.file, fn.def.line, &err)
+
+	return s.thread(ctx).call(fn, args, nil)
+}
+
+// recoverError turns a panic in the interpreter into an *Error, so that no
+// script can crash mehr.
+func recoverError(file string, line int, err *error) {
+	if r := recover(); r != nil {
+		*err = &Error{File: file, Line: line, Msg: fmt.Sprintf("internal error: %v", r)}
+	}
+}
+
+// registrations returns the functions a script registers guards, hooks and
+// prompt mutations with. They only work while the script loads.
+func (s *Set) registrations(script string, loading *bool) map[string]any {
+	register := func(name string, fn func(a []any) error, required int, params ...string) *Builtin {
+		return &Builtin{name: name, fn: func(_ *thread, args []any, kwargs []kwarg) (any, error) {
+			if !*loading {
+				return nil, fmt.Errorf("can only be called while the script loads")
+			}
+			a, err := unpack(args, kwargs, required, params...)
+			if err != nil {
+				return nil, err
+			}
+
+			return nil, fn(a)
+		}}
+	}
+
+	return map[string]any{
+		"guard": register("guard", func(a []any) error {
+			var def guardDef
+			name, err := transitionArgs(a, &def.state, &def.event, &def.order)
+			if err != nil {
+				return err
+			}
+			if def.check, err = asFunction(a[3], "check"); err != nil {
+				return err
+			}
+			def.name = script + "/" + name
+			s.guards = append(s.guards, def)
+
+			return nil
+		}, 4, "name", "state", "event", "check", "order"),
+
+		"hook": register("hook", func(a []any) error {
+			var def hookDef
+			name, err := transitionArgs(a, &def.state, &def.event, &def.order)
+			if err != nil {
+				return err
+			}
+			if def.run, err = asFunction(a[3], "run"); err != nil {
+				return err
+			}
+			def.critical = truth(a[5])
+			def.name = script + "/" + name
+			s.hooks = append(s.hooks, def)
+
+			return nil
+		}, 4, "name", "state", "event", "run", "order", "critical"),
+
+		"prompt": register("prompt", func(a []any) error {
+			step, err := asString(a[0], "step")
+			if err != nil {
+				return err
+			}
+			if !slices.Contains(promptSteps, workflow.Step(step)) {
+				return fmt.Errorf("step must be one of %v, not %q", promptSteps, step)
+			}
+			mutate, err := asFunction(a[1], "mutate")
+			if err != nil {
+				return err
+			}
+			s.prompts = append(s.prompts, promptDef{step: workflow.Step(step), mutate: mutate})
+
+			return nil
+		}, 2, "step", "mutate"),
+	}
+}
+
+// transitionArgs reads the name, state, event and order arguments shared by
+// guard and hook.
+func transitionArgs(a []any, state *workflow.State, event *workflow.Event, order *int) (string, error) {
+	name, err := asString(a[0], "name")
+	if err != nil {
+		return "", err
+	}
+	st, err := asString(a[1], "state")
+	if err != nil {
+		return "", err
+	}
+	ev, err := asString(a[2], "event")
+	if err != nil {
+		return "", err
+	}
+	if name == "" || st == "" || ev == "" {
+		return "", errors.New("name, state and event must not be empty")
+	}
+	*state, *event = workflow.State(st), workflow.Event(ev)
+
+	if a[4] != nil {
+		o, err := asInt(a[4], "order")
+		if err != nil {
+			return "", err
+		}
+		*order = int(o)
+	}
+
+	return name, nil
+}
+
+func asFunction(v any, what string) (*Function, error) {
+	fn, ok := v.(*Function)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a function defined in the script, not %s", what, typeName(v))
+	}
+
+	return fn, nil
+}
+
+// Empty reports whether the scripts registered nothing.
+func (s *Set) Empty() bool {
+	return len(s.guards) == 0 && len(s.hooks) == 0 && len(s.prompts) == 0
+}
+
+// HasTransitionHooks reports whether the scripts registered guards or hooks,
+// which need a state machine built with them.
+func (s *Set) HasTransitionHooks() bool {
+	return len(s.guards) > 0 || len(s.hooks) > 0
+}
+
+// RegisterTransitionHooks adds the scripts' guards and hooks to the
+// transitions they name. A guard passes when its check returns True or None,
+// and refuses when it returns False or a reason. A hook is a non-critical
+// effect unless registered with critical = True.
+func (s *Set) RegisterTransitionHooks(b *workflow.MachineBuilder) error {
+	var errs []error
+
+	for _, g := range s.guards {
+		guard := workflow.TransitionGuard{
+			Name:  g.name,
+			Order: g.order,
+			Check: func(ctx context.Context, wu *workflow.WorkUnit) (bool, string, error) {
+				return s.check(ctx, g, wu)
+			},
+		}
+		if err := b.RegisterTransitionGuard(g.state, g.event, guard); err != nil {
+			errs = append(errs, fmt.Errorf("guard %s: %w", g.name, err))
+		}
+	}
+
+	for _, h := range s.hooks {
+		effect := workflow.TransitionEffect{
+			CriticalEffect: workflow.CriticalEffect{
+				Name:     h.name,
+				Critical: h.critical,
+				Fn: func(ctx context.Context, wu *workflow.WorkUnit) error {
+					_, err := s.call(ctx, h.run, taskValue(wu))
+
+					return err
+				},
+			},
+			Order: h.order,
+		}
+		if err := b.RegisterTransitionEffects(h.state, h.event, effect); err != nil {
+			errs = append(errs, fmt.Errorf("hook %s: %w", h.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Set) check(ctx context.Context, g guardDef, wu *workflow.WorkUnit) (bool, string, error) {
+	result, err := s.call(ctx, g.check, taskValue(wu))
+	if err != nil {
+		return false, "", err
+	}
+
+	switch result := result.(type) {
+	case nil:
+		return true, "", nil
+	case bool:
+		return result, "", nil
+	case string:
+		return result == "", result, nil
+	}
+
+	return false, "", fmt.Errorf("guard %s returned %s, want a bool, a reason or None", g.name, typeName(result))
+}
+
+// MutatePrompt passes prompt through the mutations registered for step, in
+// the order they were registered. A mutation returns the new prompt, or
+// None to keep it.
+func (s *Set) MutatePrompt(ctx context.Context, step workflow.Step, prompt string, wu *workflow.WorkUnit) (string, error) {
+	task := taskValue(wu)
+	for _, p := range s.prompts {
+		if p.step != step {
+			continue
+		}
+		result, err := s.call(ctx, p.mutate, prompt, task)
+		if err != nil {
+			return "", err
+		}
+		switch result := result.(type) {
+		case nil:
+		case string:
+			prompt = result
+		default:
+			return "", fmt.Errorf("prompt mutation %s returned %s, want a string or None", p.mutate.def.name, typeName(result))
+		}
+	}
+
+	return prompt, nil
+}
+
+// taskValue is the read-only task scripts receive, or None without a task.
+func taskValue(wu *workflow.WorkUnit) any {
+	if wu == nil {
+		return nil
+	}
+
+	specs := make([]any, len(wu.Specifications))
+	for i, spec := range wu.Specifications {
+		specs[i] = spec
+	}
+	var source string
+	if wu.Source != nil {
+		source = wu.Source.Content
+	}
+	task := &Struct{name: "task", fields: map[string]any{
+		"id":             wu.ID,
+		"ref":            wu.ExternalID,
+		"title":          wu.Title,
+		"description":    wu.Description,
+		"source":         source,
+		"specifications": Tuple(specs),
+		"checkpoints":    int64(len(wu.Checkpoints)),
+	}}
+
+	return task
+}
@@ -0,0 +1,289 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/workflow"
+)
+
+// load writes scripts into a temporary directory and loads them.
+// It also returns what the scripts print, including from later calls.
+func load(t *testing.T, scripts map[string]string) (*Set, *[]string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, src := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	var printed []string
+	set, err := Load(dir, func(msg string) { printed = append(printed, msg) })
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	return set, &printed
+}
+
+func newMachine(t *testing.T, set *Set) *workflow.Machine {
+	t.Helper()
+
+	builder := workflow.NewMachineBuilder()
+	if err := set.RegisterTransitionHooks(builder); err != nil {
+		t.Fatalf("RegisterTransitionHooks() error = %v", err)
+	}
+	machine := builder.Build(events.NewBus())
+	machine.SetWorkUnit(&workflow.WorkUnit{
+		ID:             "t1",
+		Title:          "Add retries",
+		Source:         &workflow.Source{Reference: "file:task.md", Content: "Retry failed uploads."},
+		Specifications: []string{"specification-1.md"},
+	})
+
+	return machine
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("missing directory", func(t *testing.T) {
+		set, err := Load(filepath.Join(t.TempDir(), "scripts"), nil)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if !set.Empty() {
+			t.Error("set from a missing directory is not empty")
+		}
+	})
+
+	t.Run("ignores other files", func(t *testing.T) {
+		set, _ := load(t, map[string]string{"README.md": "guard(", "notes.txt": "x"})
+		if !set.Empty() {
+			t.Error("set loaded files without the script extension")
+		}
+	})
+
+	t.Run("runs top level in name order", func(t *testing.T) {
+		_, printed := load(t, map[string]string{
+			"b.star": `print("b")`,
+			"a.star": `print("a", 1)`,
+		})
+		if strings.Join(*printed, ",") != "a 1,b" {
+			t.Errorf("printed = %q, want a 1 then b", *printed)
+		}
+	})
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"syntax error", "def f(:\n", "bad.star:1:"},
+		{"runtime error", "x = 1\ny = x + 'a'", "bad.star:2:"},
+		{"unknown step", "def f(p, t):\n    return p\nprompt('testing', f)", "step must be one of"},
+		{"builtin as check", "guard('g', 'idle', 'plan', len)", "check must be a function"},
+		{"missing argument", "guard('g', 'idle', 'plan')", "bad.star:1:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "bad.star"), []byte(tt.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Load(dir, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSet_Guards(t *testing.T) {
+	set, _ := load(t, map[string]string{"policy.star": `
+def allow(task):
+    return True
+
+def needs_tests(task):
+    if "test" not in task.source.lower():
+        return "the task does not say how to test it"
+
+def refuse(task):
+    return False
+
+def broken(task):
+    return 42
+
+def fails(task):
+    fail("no plan for", task.id)
+
+guard(name = "allow", state = "idle", event = "start", check = allow)
+guard(name = "needs-tests", state = "idle", event = "plan", check = needs_tests, order = 1)
+guard("refuse", "idle", "implement", refuse)
+guard("broken", "idle", "review", broken)
+guard("fails", "idle", "finish", fails)
+`})
+	if !set.HasTransitionHooks() {
+		t.Fatal("HasTransitionHooks() = false")
+	}
+
+	tests := []struct {
+		event workflow.Event
+		want  string // "" when the transition is allowed
+	}{
+		{workflow.EventStart, ""},
+		{workflow.EventPlan, "policy/needs-tests on plan from idle: the task does not say how to test it"},
+		{workflow.EventImplement, "policy/refuse"},
+		{workflow.EventReview, "returned int"},
+		{workflow.EventFinish, "no plan for t1"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.event), func(t *testing.T) {
+			err := newMachine(t, set).Dispatch(context.Background(), tt.event)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Dispatch() error = %v", err)
+				}
+
+				return
+			}
+			if !errors.Is(err, workflow.ErrTransitionVetoed) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Dispatch() error = %v, want a veto containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSet_GuardUnknownTransition(t *testing.T) {
+	set, _ := load(t, map[string]string{"x.star": `
+def ok(task):
+    return True
+
+guard("g", "done", "plan", ok)
+`})
+	err := set.RegisterTransitionHooks(workflow.NewMachineBuilder())
+	if err == nil || !strings.Contains(err.Error(), "guard x/g") {
+		t.Errorf("RegisterTransitionHooks() error = %v, want the guard named", err)
+	}
+}
+
+func TestSet_Hooks(t *testing.T) {
+	set, printed := load(t, map[string]string{"notify.star": `
+seen = []
+
+def announce(task):
+    print("finishing", task.title)
+
+def remember(task):
+    seen.append(task.id)
+
+hook(name = "announce", state = "idle", event = "finish", run = announce)
+hook(name = "remember", state = "idle", event = "start", run = remember, critical = True)
+`})
+
+	machine := newMachine(t, set)
+	if err := machine.Dispatch(context.Background(), workflow.EventFinish); err != nil {
+		t.Fatalf("Dispatch(finish) error = %v", err)
+	}
+	if len(*printed) != 1 || (*printed)[0] != "finishing Add retries" {
+		t.Errorf("printed = %q, want the hook's output", *printed)
+	}
+
+	// Globals are frozen after loading, so the critical hook fails.
+	err := newMachine(t, set).Dispatch(context.Background(), workflow.EventStart)
+	if err == nil || !strings.Contains(err.Error(), "frozen") {
+		t.Errorf("Dispatch(start) error = %v, want the critical hook's failure", err)
+	}
+}
+
+func TestSet_MutatePrompt(t *testing.T) {
+	set, _ := load(t, map[string]string{
+		"a.star": `
+def house_rules(prompt, task):
+    return prompt + "\nFollow the house rules for " + task.title + "."
+
+prompt("planning", house_rules)
+`,
+		"b.star": `
+def shout(prompt, task):
+    return prompt.upper()
+
+def keep(prompt, task):
+    return None
+
+prompt("planning", keep)
+prompt(step = "planning", mutate = shout)
+prompt("reviewing", keep)
+`,
+	})
+	if set.HasTransitionHooks() {
+		t.Error("HasTransitionHooks() = true for prompt mutations only")
+	}
+
+	wu := &workflow.WorkUnit{ID: "t1", Title: "Add retries"}
+	got, err := set.MutatePrompt(context.Background(), workflow.StepPlanning, "Plan it.", wu)
+	if err != nil {
+		t.Fatalf("MutatePrompt() error = %v", err)
+	}
+	if want := "PLAN IT.\nFOLLOW THE HOUSE RULES FOR ADD RETRIES."; got != want {
+		t.Errorf("MutatePrompt() = %q, want %q", got, want)
+	}
+
+	got, err = set.MutatePrompt(context.Background(), workflow.StepReviewing, "Review it.", wu)
+	if err != nil || got != "Review it." {
+		t.Errorf("MutatePrompt(reviewing) = %q, %v, want the prompt unchanged", got, err)
+	}
+}
+
+func TestSet_MutatePromptErrors(t *testing.T) {
+	set, _ := load(t, map[string]string{"x.star": `
+def count(prompt, task):
+    return len(prompt)
+
+def spin(prompt, task):
+    n = 0
+    for i in range(100000000):
+        n += 1
+
+prompt("implementing", count)
+prompt("documenting", spin)
+`})
+
+	if _, err := set.MutatePrompt(context.Background(), workflow.StepImplementing, "x", nil); err == nil {
+		t.Error("MutatePrompt() accepted an int result")
+	}
+	if _, err := set.MutatePrompt(context.Background(), workflow.StepDocumenting, "x", nil); err == nil {
+		t.Error("MutatePrompt() ran past the step budget")
+	}
+}
+
+func TestSet_RegisterAfterLoad(t *testing.T) {
+	set, _ := load(t, map[string]string{"x.star": `
+def sneak(p, task):
+    prompt("planning", sneak)
+
+prompt("planning", sneak)
+`})
+
+	_, err := set.MutatePrompt(context.Background(), workflow.StepPlanning, "p", nil)
+	if err == nil || !strings.Contains(err.Error(), "while the script loads") {
+		t.Errorf("MutatePrompt() error = %v, want registration refused", err)
+	}
+}
+
+func TestRecoverError(t *testing.T) {
+	err := func() (err error) {
+		defer recoverError("x.star", 3, &err)
+		panic("boom")
+	}()
+
+	var scriptErr *Error
+	if !errors.As(err, &scriptErr) || err.Error() != "x.star:3: internal error: boom" {
+		t.Errorf("error = %v, want the panic as a script error", err)
+	}
+}
@@ -0,0 +1,318 @@
+package script
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Values are plain Go values: nil (None), bool, int64, string, Tuple,
+// *List, *Dict, *Struct, *Function and *Builtin.
+
+// Tuple is an immutable sequence.
+type Tuple []any
+
+// List is a mutable sequence, frozen once its module has loaded.
+type List struct {
+	elems  []any
+	frozen bool
+}
+
+// Dict is a mapping that keeps insertion order, frozen once its module has
+// loaded. Keys are None, bools, ints and strings.
+type Dict struct {
+	keys   []any
+	values map[any]any
+	frozen bool
+}
+
+// Struct is a read-only record, such as the task passed to scripts.
+type Struct struct {
+	name   string
+	fields map[string]any
+}
+
+// Function is a function defined in a script.
+type Function struct {
+	def      *defStmt
+	defaults []any
+	module   *module
+}
+
+// Builtin is a function provided to scripts, or a method bound to its value.
+type Builtin struct {
+	name string
+	fn   func(th *thread, args []any, kwargs []kwarg) (any, error)
+}
+
+type kwarg struct {
+	name  string
+	value any
+}
+
+func newList(elems []any) *List {
+	return &List{elems: elems}
+}
+
+func newDict() *Dict {
+	return &Dict{values: make(map[any]any)}
+}
+
+func (d *Dict) get(key any) (any, bool, error) {
+	if !hashable(key) {
+		return nil, false, fmt.Errorf("unhashable type: %s", typeName(key))
+	}
+	v, ok := d.values[key]
+
+	return v, ok, nil
+}
+
+func (d *Dict) set(key, value any) error {
+	if d.frozen {
+		return fmt.Errorf("cannot modify a frozen dict")
+	}
+	if !hashable(key) {
+		return fmt.Errorf("unhashable type: %s", typeName(key))
+	}
+	if _, ok := d.values[key]; !ok {
+		d.keys = append(d.keys, key)
+	}
+	d.values[key] = value
+
+	return nil
+}
+
+func (d *Dict) delete(key any) (any, bool) {
+	v, ok := d.values[key]
+	if ok {
+		delete(d.values, key)
+		d.keys = slices.DeleteFunc(d.keys, func(k any) bool { return k == key })
+	}
+
+	return v, ok
+}
+
+func hashable(v any) bool {
+	switch v.(type) {
+	case nil, bool, int64, string:
+		return true
+	}
+
+	return false
+}
+
+// freeze makes v and everything reachable from it immutable.
+func freeze(v any) {
+	switch v := v.(type) {
+	case *List:
+		if v.frozen {
+			return
+		}
+		v.frozen = true
+		for _, e := range v.elems {
+			freeze(e)
+		}
+	case *Dict:
+		if v.frozen {
+			return
+		}
+		v.frozen = true
+		for _, e := range v.values {
+			freeze(e)
+		}
+	case Tuple:
+		for _, e := range v {
+			freeze(e)
+		}
+	}
+}
+
+func typeName(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NoneType"
+	case bool:
+		return "bool"
+	case int64:
+		return "int"
+	case string:
+		return "string"
+	case Tuple:
+		return "tuple"
+	case *List:
+		return "list"
+	case *Dict:
+		return "dict"
+	case *Struct:
+		return v.name
+	case *Function, *Builtin:
+		return "function"
+	}
+
+	return fmt.Sprintf("%T", v)
+}
+
+func truth(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case int64:
+		return v != 0
+	case string:
+		return v != ""
+	case Tuple:
+		return len(v) > 0
+	case *List:
+		return len(v.elems) > 0
+	case *Dict:
+		return len(v.keys) > 0
+	}
+
+	return true
+}
+
+// str converts v as str() does: strings stay unquoted.
+func str(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+
+	return repr(v)
+}
+
+func repr(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "None"
+	case bool:
+		if v {
+			return "True"
+		}
+
+		return "False"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case string:
+		return strconv.Quote(v)
+	case Tuple:
+		if len(v) == 1 {
+			return "(" + repr(v[0]) + ",)"
+		}
+
+		return "(" + joinRepr(v) + ")"
+	case *List:
+		return "[" + joinRepr(v.elems) + "]"
+	case *Dict:
+		parts := make([]string, len(v.keys))
+		for i, k := range v.keys {
+			parts[i] = repr(k) + ": " + repr(v.values[k])
+		}
+
+		return "{" + strings.Join(parts, ", ") + "}"
+	case *Struct:
+		names := make([]string, 0, len(v.fields))
+		for name := range v.fields {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		parts := make([]string, len(names))
+		for i, name := range names {
+			parts[i] = name + " = " + repr(v.fields[name])
+		}
+
+		return v.name + "(" + strings.Join(parts, ", ") + ")"
+	case *Function:
+		return "<function " + v.def.name + ">"
+	case *Builtin:
+		return "<built-in function " + v.name + ">"
+	}
+
+	return fmt.Sprint(v)
+}
+
+func joinRepr(elems []any) string {
+	parts := make([]string, len(elems))
+	for i, e := range elems {
+		parts[i] = repr(e)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func equal(x, y any) bool {
+	switch x := x.(type) {
+	case Tuple:
+		y, ok := y.(Tuple)
+
+		return ok && slices.EqualFunc(x, y, equal)
+	case *List:
+		y, ok := y.(*List)
+
+		return ok && slices.EqualFunc(x.elems, y.elems, equal)
+	case *Dict:
+		y, ok := y.(*Dict)
+		if !ok || len(x.keys) != len(y.keys) {
+			return false
+		}
+		for _, k := range x.keys {
+			yv, ok := y.values[k]
+			if !ok || !equal(x.values[k], yv) {
+				return false
+			}
+		}
+
+		return true
+	case *Struct, *Function, *Builtin:
+		return x == y
+	}
+	if !hashable(y) {
+		return false
+	}
+
+	return x == y
+}
+
+// compare orders two ints or two strings.
+func compare(x, y any) (int, error) {
+	switch x := x.(type) {
+	case int64:
+		if y, ok := y.(int64); ok {
+			return cmpInt(x, y), nil
+		}
+	case string:
+		if y, ok := y.(string); ok {
+			return strings.Compare(x, y), nil
+		}
+	}
+
+	return 0, fmt.Errorf("cannot compare %s with %s", typeName(x), typeName(y))
+}
+
+func cmpInt(x, y int64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+
+	return 0
+}
+
+// iterate returns the elements a for loop visits: the elements of a list or
+// tuple, or the keys of a dict. As in Starlark, strings are not iterable.
+func iterate(v any) ([]any, error) {
+	switch v := v.(type) {
+	case Tuple:
+		return v, nil
+	case *List:
+		return slices.Clone(v.elems), nil
+	case *Dict:
+		return slices.Clone(v.keys), nil
+	}
+
+	return nil, fmt.Errorf("%s is not iterable", typeName(v))
+}
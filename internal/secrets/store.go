@@ -0,0 +1,205 @@
+// Package secrets provides a small file-backed store for provider credentials.
+//
+// Credentials are kept in ~/.mehrhof/credentials.json with 0600 permissions.
+// Unlike static tokens in .mehrhof/.env, stored credentials may carry a
+// refresh token and expiry so that providers can renew them transparently.
+//
+// Thread safety:
+//   - All methods are safe for concurrent use within a single process.
+//   - Writes are atomic (temp file + rename), so concurrent processes never
+//     observe a partially written file.
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned when no credential is stored under a key.
+var ErrNotFound = errors.New("credential not found")
+
+// expiryLeeway is subtracted from ExpiresAt so that tokens are refreshed
+// slightly before the server would reject them.
+const expiryLeeway = 30 * time.Second
+
+// Credential holds an access token and the data needed to refresh it.
+type Credential struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	TokenType    string    `json:"token_type,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+	Host         string    `json:"host,omitempty"`      // Server the credential was issued by
+	ClientID     string    `json:"client_id,omitempty"` // OAuth application used to obtain it
+	Scopes       []string  `json:"scopes,omitempty"`
+}
+
+// Expired reports whether the access token is expired or about to expire.
+// Credentials without an expiry never expire.
+func (c *Credential) Expired() bool {
+	if c.ExpiresAt.IsZero() {
+		return false
+	}
+
+	return time.Now().Add(expiryLeeway).After(c.ExpiresAt)
+}
+
+// Store persists credentials keyed by an arbitrary string (e.g., "gitlab:https://gitlab.example.com").
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+// DefaultPath returns the default credentials file location.
+func DefaultPath() string {
+	home, _ := os.UserHomeDir()
+
+	return filepath.Join(home, ".mehrhof", "credentials.json")
+}
+
+// NewStore creates a store backed by the given file path.
+// If path is empty, DefaultPath is used.
+func NewStore(path string) *Store {
+	if path == "" {
+		path = DefaultPath()
+	}
+
+	return &Store{path: path}
+}
+
+// Path returns the backing file path.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Key builds a store key from a provider name and host.
+func Key(providerName, host string) string {
+	return providerName + ":" + host
+}
+
+// Get returns the credential stored under key.
+func (s *Store) Get(key string) (*Credential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	cred, ok := all[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	return cred, nil
+}
+
+// Set stores a credential under key, replacing any existing value.
+func (s *Store) Set(key string, cred *Credential) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.load()
+	if err != nil {
+		return err
+	}
+	all[key] = cred
+
+	return s.save(all)
+}
+
+// Delete removes the credential stored under key.
+// Deleting a missing key is not an error.
+func (s *Store) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := all[key]; !ok {
+		return nil
+	}
+	delete(all, key)
+
+	return s.save(all)
+}
+
+// Keys returns all stored keys sorted alphabetically.
+func (s *Store) Keys() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Sorted(maps.Keys(all)), nil
+}
+
+// load reads all credentials from disk. A missing file yields an empty map.
+func (s *Store) load() (map[string]*Credential, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]*Credential), nil
+		}
+
+		return nil, fmt.Errorf("read credentials: %w", err)
+	}
+
+	all := make(map[string]*Credential)
+	if len(data) == 0 {
+		return all, nil
+	}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("parse credentials: %w", err)
+	}
+
+	return all, nil
+}
+
+// save writes all credentials to disk atomically with owner-only permissions.
+func (s *Store) save(all map[string]*Credential) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("create credentials directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal credentials: %w", err)
+	}
+
+	// A temp file of its own keeps another process saving at the same time
+	// from writing into it
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("write credentials: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+
+		return fmt.Errorf("write credentials: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		_ = os.Remove(tmpPath)
+
+		return fmt.Errorf("rename credentials: %w", err)
+	}
+
+	return nil
+}
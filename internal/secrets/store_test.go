@@ -0,0 +1,121 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStoreSetGetDelete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "credentials.json")
+	s := NewStore(path)
+
+	if _, err := s.Get("gitlab:https://gitlab.com"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() on empty store error = %v, want ErrNotFound", err)
+	}
+
+	cred := &Credential{AccessToken: "abc", RefreshToken: "def", Host: "https://gitlab.com"}
+	if err := s.Set("gitlab:https://gitlab.com", cred); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat credentials: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("credentials permissions = %o, want 600", perm)
+	}
+
+	// A fresh store must read the persisted value
+	got, err := NewStore(path).Get("gitlab:https://gitlab.com")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.AccessToken != "abc" || got.RefreshToken != "def" {
+		t.Errorf("Get() = %+v, want access=abc refresh=def", got)
+	}
+
+	keys, err := s.Keys()
+	if err != nil {
+		t.Fatalf("Keys() error = %v", err)
+	}
+	if len(keys) != 1 || keys[0] != "gitlab:https://gitlab.com" {
+		t.Errorf("Keys() = %v", keys)
+	}
+
+	if err := s.Delete("gitlab:https://gitlab.com"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := s.Delete("gitlab:https://gitlab.com"); err != nil {
+		t.Fatalf("Delete() of missing key error = %v", err)
+	}
+	if _, err := s.Get("gitlab:https://gitlab.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStoreConcurrentSave(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "credentials.json")
+
+	// Separate stores stand in for separate processes
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			s := NewStore(path)
+			if err := s.Set(fmt.Sprintf("key%d", i), &Credential{AccessToken: "abc"}); err != nil {
+				t.Errorf("Set() error = %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if _, err := NewStore(path).Keys(); err != nil {
+		t.Errorf("Keys() after concurrent saves error = %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d files, want only the credentials", len(entries))
+	}
+}
+
+func TestCredentialExpired(t *testing.T) {
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		want      bool
+	}{
+		{name: "no expiry", want: false},
+		{name: "far future", expiresAt: time.Now().Add(time.Hour), want: false},
+		{name: "within leeway", expiresAt: time.Now().Add(10 * time.Second), want: true},
+		{name: "past", expiresAt: time.Now().Add(-time.Minute), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Credential{AccessToken: "x", ExpiresAt: tt.expiresAt}
+			if got := c.Expired(); got != tt.want {
+				t.Errorf("Expired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewStore(path).Get("any"); err == nil {
+		t.Error("Get() on corrupt file expected error, got nil")
+	}
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// NewToken returns a random bearer token for one run of the server.
+func NewToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// WriteToken writes token to path, readable only by the user.
+func WriteToken(path, token string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("write token: %w", err)
+	}
+	// An existing file keeps its mode on open
+	if err := f.Chmod(0o600); err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("write token: %w", err)
+	}
+	if _, err := f.WriteString(token + "\n"); err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("write token: %w", err)
+	}
+
+	return f.Close()
+}
+
+// authorize guards the API against other users and against web pages the
+// user visits. Browsers send an Origin header with cross-origin requests and
+// DNS rebinding arrives with a foreign Host, so both are refused; everything
+// else needs the bearer token, and POSTs must be JSON, which a page cannot
+// send cross-origin without a preflight.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Origin") != "" {
+			writeError(w, http.StatusForbidden, errors.New("cross-origin requests are not allowed"))
+
+			return
+		}
+		if !loopbackHost(r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("host %q is not a loopback address", r.Host))
+
+			return
+		}
+		if !s.validToken(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mehr"`)
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+
+			return
+		}
+		if r.Method == http.MethodPost {
+			if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType, errors.New("content type must be application/json"))
+
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validToken reports whether r carries the server's token, in the
+// Authorization header or, for GETs from clients that cannot set headers
+// such as calendar apps, in the token query parameter.
+func (s *Server) validToken(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok && r.Method == http.MethodGet {
+		token = r.URL.Query().Get("token")
+	}
+
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// loopbackHost reports whether a Host header names this machine.
+func loopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,348 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/calendar"
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// errorResponse is the body of every failed request.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// statusResponse describes the active task and the running operation.
+type statusResponse struct {
+	Active    bool        `json:"active"`
+	Operation string      `json:"operation,omitempty"`
+	Task      *taskStatus `json:"task,omitempty"`
+}
+
+// taskStatus is the JSON form of conductor.TaskStatus.
+type taskStatus struct {
+	ID             string    `json:"id"`
+	Title          string    `json:"title"`
+	ExternalKey    string    `json:"external_key,omitempty"`
+	State          string    `json:"state"`
+	Ref            string    `json:"ref"`
+	Branch         string    `json:"branch,omitempty"`
+	WorktreePath   string    `json:"worktree_path,omitempty"`
+	Specifications int       `json:"specifications"`
+	Checkpoints    int       `json:"checkpoints"`
+	Agent          string    `json:"agent,omitempty"`
+	Started        time.Time `json:"started"`
+}
+
+// taskSummary is one entry of the task list.
+type taskSummary struct {
+	ID      string    `json:"id"`
+	Title   string    `json:"title"`
+	Ref     string    `json:"ref,omitempty"`
+	Branch  string    `json:"branch,omitempty"`
+	State   string    `json:"state,omitempty"`
+	Active  bool      `json:"active"`
+	Created time.Time `json:"created"`
+}
+
+// questionResponse is the agent's pending question.
+type questionResponse struct {
+	Pending  bool     `json:"pending"`
+	Question string   `json:"question,omitempty"`
+	Options  []string `json:"options,omitempty"`
+}
+
+// operationResponse acknowledges a background operation.
+type operationResponse struct {
+	Operation string `json:"operation"`
+	Status    string `json:"status"`
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	resp := statusResponse{Operation: s.Operation()}
+	if status, err := s.cond.Status(); err == nil {
+		resp.Active = true
+		resp.Task = &taskStatus{
+			ID:             status.TaskID,
+			Title:          status.Title,
+			ExternalKey:    status.ExternalKey,
+			State:          status.State,
+			Ref:            status.Ref,
+			Branch:         status.Branch,
+			WorktreePath:   status.WorktreePath,
+			Specifications: status.Specifications,
+			Checkpoints:    status.Checkpoints,
+			Agent:          status.Agent,
+			Started:        status.Started,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleListTasks(w http.ResponseWriter, _ *http.Request) {
+	ws := s.cond.GetWorkspace()
+	taskIDs, err := ws.ListWorks()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("list tasks: %w", err))
+
+		return
+	}
+
+	var active *storage.ActiveTask
+	if ws.HasActiveTask() {
+		active, _ = ws.LoadActiveTask()
+	}
+
+	tasks := make([]taskSummary, 0, len(taskIDs))
+	for _, id := range taskIDs {
+		work, err := ws.LoadWork(id)
+		if err != nil {
+			continue
+		}
+		task := taskSummary{
+			ID:      id,
+			Title:   work.Metadata.Title,
+			Ref:     work.Source.Ref,
+			Branch:  work.Git.Branch,
+			Created: work.Metadata.CreatedAt,
+		}
+		if active != nil && active.ID == id {
+			task.Active = true
+			task.State = active.State
+		}
+		tasks = append(tasks, task)
+	}
+
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+// handleCalendar serves task milestones as an iCalendar feed, optionally
+// limited to ?since=YYYY-MM-DD.
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, v, time.Local)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid since %q: use YYYY-MM-DD", v))
+
+			return
+		}
+		since = parsed
+	}
+
+	ws := s.cond.GetWorkspace()
+	milestones, err := calendar.Milestones(ws, since)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := calendar.Write(&buf, "mehrhof: "+filepath.Base(ws.Root()), milestones); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="mehrhof.ics"`)
+	_, _ = w.Write(buf.Bytes())
+}
+
+func (s *Server) handleStart(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Reference string `json:"reference"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Reference) == "" {
+		writeError(w, http.StatusBadRequest, errors.New("request body must contain a reference"))
+
+		return
+	}
+
+	err := s.runExclusive("start", func() error {
+		return s.cond.Start(r.Context(), req.Reference)
+	})
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Errorf("start: %w", err))
+
+		return
+	}
+
+	s.handleStatus(w, r)
+}
+
+func (s *Server) handlePlan(w http.ResponseWriter, _ *http.Request) {
+	s.startOperation(w, "plan", func(ctx context.Context) error {
+		if err := s.cond.Plan(ctx); err != nil {
+			return fmt.Errorf("plan: %w", err)
+		}
+
+		return s.cond.RunPlanning(ctx)
+	})
+}
+
+func (s *Server) handleImplement(w http.ResponseWriter, _ *http.Request) {
+	s.startOperation(w, "implement", func(ctx context.Context) error {
+		if err := s.cond.Implement(ctx); err != nil {
+			return fmt.Errorf("implement: %w", err)
+		}
+
+		return s.cond.RunImplementation(ctx)
+	})
+}
+
+// startOperation starts a background workflow operation on the active task
+// and responds with 202 Accepted.
+func (s *Server) startOperation(w http.ResponseWriter, name string, fn func(ctx context.Context) error) {
+	if s.cond.GetActiveTask() == nil {
+		writeError(w, http.StatusConflict, errors.New("no active task"))
+
+		return
+	}
+	if err := s.runOperation(name, fn); err != nil {
+		writeError(w, http.StatusConflict, err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, operationResponse{Operation: name, Status: "started"})
+}
+
+func (s *Server) handleQuestion(w http.ResponseWriter, _ *http.Request) {
+	active := s.cond.GetActiveTask()
+	ws := s.cond.GetWorkspace()
+	if active == nil || !ws.HasPendingQuestion(active.ID) {
+		writeJSON(w, http.StatusOK, questionResponse{})
+
+		return
+	}
+
+	q, err := ws.LoadPendingQuestion(active.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("load question: %w", err))
+
+		return
+	}
+
+	resp := questionResponse{Pending: true, Question: q.Question}
+	for _, opt := range q.Options {
+		resp.Options = append(resp.Options, opt.Label)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleAnswer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Answer string `json:"answer"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Answer) == "" {
+		writeError(w, http.StatusBadRequest, errors.New("request body must contain an answer"))
+
+		return
+	}
+
+	if err := s.cond.AnswerQuestion(req.Answer); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, conductor.ErrNoPendingQuestion) || s.cond.GetActiveTask() == nil {
+			status = http.StatusConflict
+		}
+		writeError(w, status, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleEvents streams workflow events as server-sent events until the client
+// disconnects or the server closes.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming not supported"))
+
+		return
+	}
+
+	// Subscribe before reading the history so no event falls in between
+	ch := s.subscribe()
+	defer s.unsubscribe(ch)
+
+	var past []events.Event
+	if v := r.URL.Query().Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid offset %q", v))
+
+			return
+		}
+		if past, err = s.cond.GetEventBus().Replay(offset); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	var last time.Time
+	for _, e := range past {
+		if e.Timestamp.After(last) {
+			last = e.Timestamp
+		}
+		if err := writeEvent(w, e); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.ctx.Done():
+			return
+		case e := <-ch:
+			// Already sent from the history
+			if !e.Timestamp.IsZero() && !e.Timestamp.After(last) {
+				continue
+			}
+			if err := writeEvent(w, e); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent writes one server-sent event. Events that cannot be encoded
+// are skipped.
+func writeEvent(w io.Writer, e events.Event) error {
+	data, err := json.Marshal(map[string]any{
+		"type":      e.Type,
+		"timestamp": e.Timestamp,
+		"data":      e.Data,
+	})
+	if err != nil {
+		return nil //nolint:nilerr // Skip the event, keep the stream
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+
+	return err
+}
@@ -0,0 +1,222 @@
+// Package server keeps a conductor resident and exposes it over a local
+// HTTP/JSON API, so editors and other tools can drive the workflow without
+// paying for provider and agent initialization on every command.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/events"
+)
+
+// Server-side event types sent on the event stream in addition to the
+// conductor's own events.
+const (
+	TypeOperationStarted   events.Type = "operation_started"
+	TypeOperationCompleted events.Type = "operation_completed"
+	TypeOperationFailed    events.Type = "operation_failed"
+)
+
+// eventBuffer is how many events a slow stream client may fall behind before
+// events are dropped for it.
+const eventBuffer = 64
+
+// ErrBusy is returned when a workflow operation is already running.
+var ErrBusy = errors.New("another operation is running")
+
+// Server serves the API for one conductor. Workflow operations run one at a
+// time in the background; their progress is reported on the event stream.
+type Server struct {
+	cond  *conductor.Conductor
+	mux   *http.ServeMux
+	token string // Bearer token every request must carry
+
+	//nolint:containedctx // cancels background operations on Close
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu        sync.Mutex
+	operation string // Name of the running operation, "" when idle
+
+	subMu       sync.Mutex
+	subscribers map[chan events.Event]struct{}
+	subID       string
+}
+
+// New creates a server for an initialized conductor. Requests must carry
+// token as a bearer token; see NewToken.
+func New(cond *conductor.Conductor, token string) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		cond:        cond,
+		mux:         http.NewServeMux(),
+		token:       token,
+		ctx:         ctx,
+		cancel:      cancel,
+		subscribers: make(map[chan events.Event]struct{}),
+	}
+
+	s.mux.HandleFunc("GET /api/v1/status", s.handleStatus)
+	s.mux.HandleFunc("GET /api/v1/tasks", s.handleListTasks)
+	s.mux.HandleFunc("POST /api/v1/tasks", s.handleStart)
+	s.mux.HandleFunc("POST /api/v1/plan", s.handlePlan)
+	s.mux.HandleFunc("POST /api/v1/implement", s.handleImplement)
+	s.mux.HandleFunc("GET /api/v1/question", s.handleQuestion)
+	s.mux.HandleFunc("POST /api/v1/answer", s.handleAnswer)
+	s.mux.HandleFunc("GET /api/v1/events", s.handleEvents)
+	s.mux.HandleFunc("GET /api/v1/calendar.ics", s.handleCalendar)
+
+	s.subID = cond.GetEventBus().SubscribeAll(s.broadcast)
+
+	// Apply edits to config.yaml and .env without a restart
+	s.wg.Go(func() {
+		if err := cond.WatchConfig(ctx); err != nil {
+			slog.Warn("config hot reload disabled", "error", err)
+		}
+	})
+
+	return s
+}
+
+// Handler returns the HTTP handler serving the API.
+func (s *Server) Handler() http.Handler {
+	return s.authorize(s.mux)
+}
+
+// Close cancels the running operation and the config watcher, waits for
+// them to stop and detaches from the conductor's event bus.
+func (s *Server) Close() {
+	s.cancel()
+	s.wg.Wait()
+	s.cond.GetEventBus().Unsubscribe(s.subID)
+}
+
+// Operation returns the name of the running operation, or "" when idle.
+func (s *Server) Operation() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.operation
+}
+
+// runOperation runs fn in the background unless another operation is running.
+// Its outcome is published on the event stream.
+func (s *Server) runOperation(name string, fn func(ctx context.Context) error) error {
+	s.mu.Lock()
+	if s.operation != "" {
+		s.mu.Unlock()
+
+		return fmt.Errorf("%w: %s", ErrBusy, s.operation)
+	}
+	s.operation = name
+	s.mu.Unlock()
+
+	s.broadcast(operationEvent(TypeOperationStarted, name, nil))
+
+	s.wg.Go(func() {
+		err := fn(s.ctx)
+
+		s.mu.Lock()
+		s.operation = ""
+		s.mu.Unlock()
+
+		switch {
+		case err == nil, errors.Is(err, conductor.ErrPendingQuestion):
+			s.broadcast(operationEvent(TypeOperationCompleted, name, nil))
+		default:
+			slog.Warn("operation failed", "operation", name, "error", err)
+			s.broadcast(operationEvent(TypeOperationFailed, name, err))
+		}
+	})
+
+	return nil
+}
+
+// runExclusive runs fn synchronously while no background operation is
+// running.
+func (s *Server) runExclusive(name string, fn func() error) error {
+	s.mu.Lock()
+	if s.operation != "" {
+		s.mu.Unlock()
+
+		return fmt.Errorf("%w: %s", ErrBusy, s.operation)
+	}
+	s.operation = name
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.operation = ""
+		s.mu.Unlock()
+	}()
+
+	return fn()
+}
+
+// subscribe registers an event stream client.
+func (s *Server) subscribe() chan events.Event {
+	ch := make(chan events.Event, eventBuffer)
+
+	s.subMu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.subMu.Unlock()
+
+	return ch
+}
+
+// unsubscribe removes an event stream client.
+func (s *Server) unsubscribe(ch chan events.Event) {
+	s.subMu.Lock()
+	delete(s.subscribers, ch)
+	s.subMu.Unlock()
+}
+
+// broadcast sends an event to every stream client without blocking; clients
+// that fall behind miss events rather than stalling the workflow.
+func (s *Server) broadcast(e events.Event) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// operationEvent builds an operation lifecycle event.
+func operationEvent(typ events.Type, name string, err error) events.Event {
+	data := map[string]any{"operation": name}
+	if err != nil {
+		data["error"] = err.Error()
+	}
+
+	return events.Event{Type: typ, Timestamp: time.Now(), Data: data}
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Debug("write response", "error", err)
+	}
+}
+
+// writeError writes an error response, mapping known errors to status codes.
+func writeError(w http.ResponseWriter, status int, err error) {
+	if errors.Is(err, ErrBusy) {
+		status = http.StatusConflict
+	}
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
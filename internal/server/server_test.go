@@ -0,0 +1,385 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/provider/file"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/testutil"
+)
+
+const testToken = "test-token"
+
+func newTestServer(t *testing.T) (*Server, *httptest.Server, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	c, err := conductor.New(
+		conductor.WithWorkDir(tmpDir),
+		conductor.WithAutoInit(true),
+		conductor.WithCreateBranch(false),
+		conductor.WithAgent("mock"),
+		conductor.WithStdout(io.Discard),
+		conductor.WithStderr(io.Discard),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	file.Register(c.GetProviderRegistry())
+	testutil.WithMockAgent(c, testutil.NewMockAgent("mock"))
+	if err := c.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	srv := New(c, testToken)
+	httpServer := httptest.NewServer(srv.Handler())
+	t.Cleanup(func() {
+		srv.Close()
+		httpServer.Close()
+	})
+
+	return srv, httpServer, tmpDir
+}
+
+// newRequest returns a request that passes authorize.
+func newRequest(t *testing.T, ctx context.Context, method, url, body string) *http.Request {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req
+}
+
+func doJSON(t *testing.T, method, url, body string, out any) int {
+	t.Helper()
+
+	req := newRequest(t, context.Background(), method, url, body)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("decode %s %s: %v", method, url, err)
+		}
+	}
+
+	return resp.StatusCode
+}
+
+func TestServer_TaskLifecycle(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	srv, httpServer, tmpDir := newTestServer(t)
+	api := httpServer.URL + "/api/v1"
+
+	var status statusResponse
+	if code := doJSON(t, http.MethodGet, api+"/status", "", &status); code != http.StatusOK || status.Active {
+		t.Fatalf("status before start = %d %+v, want inactive", code, status)
+	}
+
+	taskPath := filepath.Join(tmpDir, "task.md")
+	if err := os.WriteFile(taskPath, []byte("# Add caching\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	body := `{"reference": "file:` + filepath.ToSlash(taskPath) + `"}`
+	if code := doJSON(t, http.MethodPost, api+"/tasks", body, &status); code != http.StatusOK {
+		t.Fatalf("start = %d, want 200", code)
+	}
+	if !status.Active || status.Task.Title != "Add caching" {
+		t.Errorf("status after start = %+v", status.Task)
+	}
+
+	var tasks []taskSummary
+	doJSON(t, http.MethodGet, api+"/tasks", "", &tasks)
+	if len(tasks) != 1 || !tasks[0].Active {
+		t.Errorf("tasks = %+v, want the active task", tasks)
+	}
+
+	var errResp errorResponse
+	if code := doJSON(t, http.MethodPost, api+"/answer", `{"answer": "Redis"}`, &errResp); code != http.StatusConflict {
+		t.Errorf("answer without question = %d, want 409", code)
+	}
+
+	taskID := status.Task.ID
+	ws := srv.cond.GetWorkspace()
+	if err := ws.SavePendingQuestion(taskID, &storage.PendingQuestion{
+		Question: "Which cache?",
+		Options:  []storage.QuestionOption{{Label: "Redis"}, {Label: "Memcached"}},
+	}); err != nil {
+		t.Fatalf("SavePendingQuestion: %v", err)
+	}
+
+	var question questionResponse
+	doJSON(t, http.MethodGet, api+"/question", "", &question)
+	if !question.Pending || question.Question != "Which cache?" || len(question.Options) != 2 {
+		t.Errorf("question = %+v", question)
+	}
+
+	if code := doJSON(t, http.MethodPost, api+"/answer", `{"answer": "Redis"}`, nil); code != http.StatusNoContent {
+		t.Fatalf("answer = %d, want 204", code)
+	}
+	if ws.HasPendingQuestion(taskID) {
+		t.Error("answer should clear the pending question")
+	}
+	notes, _ := ws.ReadNotes(taskID)
+	if !strings.Contains(notes, "**A:** Redis") {
+		t.Errorf("notes missing answer:\n%s", notes)
+	}
+}
+
+func TestServer_OneOperationAtATime(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	srv, httpServer, tmpDir := newTestServer(t)
+	api := httpServer.URL + "/api/v1"
+
+	taskPath := filepath.Join(tmpDir, "task.md")
+	if err := os.WriteFile(taskPath, []byte("# Busy task\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := srv.cond.Start(context.Background(), "file:"+taskPath); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	release := make(chan struct{})
+	if err := srv.runOperation("plan", func(ctx context.Context) error {
+		<-release
+
+		return nil
+	}); err != nil {
+		t.Fatalf("runOperation: %v", err)
+	}
+
+	var errResp errorResponse
+	if code := doJSON(t, http.MethodPost, api+"/implement", "", &errResp); code != http.StatusConflict {
+		t.Errorf("implement while planning = %d, want 409", code)
+	}
+	if !strings.Contains(errResp.Error, "plan") {
+		t.Errorf("error = %q, want running operation named", errResp.Error)
+	}
+
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.Operation() != "" {
+		if time.Now().After(deadline) {
+			t.Fatal("operation did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServer_Events(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	srv, httpServer, _ := newTestServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req := newRequest(t, ctx, http.MethodGet, httpServer.URL+"/api/v1/events", "")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET events: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	srv.cond.GetEventBus().Publish(events.ProgressEvent{TaskID: "t1", Message: "Planning"})
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 2 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read event: %v", err)
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	if lines[0] != "event: progress" {
+		t.Errorf("event line = %q", lines[0])
+	}
+	if !strings.Contains(lines[1], `"message":"Planning"`) {
+		t.Errorf("data line = %q", lines[1])
+	}
+}
+
+func TestServer_EventsOffset(t *testing.T) {
+	srv, httpServer, _ := newTestServer(t)
+	api := httpServer.URL + "/api/v1/events"
+
+	logged := []events.Event{
+		{Type: events.TypeStateChanged, Timestamp: time.Now().Add(-time.Minute), Data: map[string]any{"to": "planning"}},
+		{Type: events.TypeProgress, Timestamp: time.Now().Add(-time.Second), Data: map[string]any{"message": "Analyzing"}},
+	}
+	srv.cond.GetEventBus().SetHistory(func(offset int) ([]events.Event, error) {
+		return logged[offset:], nil
+	})
+
+	req := newRequest(t, context.Background(), http.MethodGet, api+"?offset=nope", "")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET events: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid offset status = %d, want 400", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req = newRequest(t, ctx, http.MethodGet, api+"?offset=1", "")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET events: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil {
+		t.Fatalf("read event: %v", err)
+	}
+	if line != "event: progress\n" {
+		t.Errorf("first event line = %q, want the logged progress event", line)
+	}
+}
+
+func TestServer_Calendar(t *testing.T) {
+	srv, httpServer, _ := newTestServer(t)
+	api := httpServer.URL + "/api/v1"
+
+	ws := srv.cond.GetWorkspace()
+	work, err := ws.CreateWork("t1", storage.SourceInfo{Type: "file", Ref: "task.md"})
+	if err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	work.Metadata.Title = "Add caching"
+	if err := ws.SaveWork(work); err != nil {
+		t.Fatalf("SaveWork: %v", err)
+	}
+
+	// Calendar apps cannot set headers
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, api+"/calendar.ics?token="+testToken, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET calendar: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/calendar") {
+		t.Fatalf("calendar = %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(string(body), "SUMMARY:Started: Add caching") {
+		t.Errorf("calendar missing the task start:\n%s", body)
+	}
+
+	var errResp errorResponse
+	if code := doJSON(t, http.MethodGet, api+"/calendar.ics?since=yesterday", "", &errResp); code != http.StatusBadRequest {
+		t.Errorf("bad since = %d, want 400", code)
+	}
+}
+
+func TestServer_Authorize(t *testing.T) {
+	_, httpServer, _ := newTestServer(t)
+	api := httpServer.URL + "/api/v1"
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		header map[string]string
+		want   int
+	}{
+		{"no token", http.MethodGet, api + "/status", map[string]string{"Authorization": ""}, http.StatusUnauthorized},
+		{"wrong token", http.MethodGet, api + "/status", map[string]string{"Authorization": "Bearer nope"}, http.StatusUnauthorized},
+		{"query token on POST", http.MethodPost, api + "/plan?token=" + testToken, map[string]string{"Authorization": ""}, http.StatusUnauthorized},
+		{"origin", http.MethodGet, api + "/status", map[string]string{"Origin": "https://example.com"}, http.StatusForbidden},
+		{"foreign host", http.MethodGet, api + "/status", map[string]string{"Host": "attacker.example:7373"}, http.StatusForbidden},
+		{"form post", http.MethodPost, api + "/answer", map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, http.StatusUnsupportedMediaType},
+		{"no content type", http.MethodPost, api + "/answer", map[string]string{"Content-Type": ""}, http.StatusUnsupportedMediaType},
+		{"authorized", http.MethodGet, api + "/status", nil, http.StatusOK},
+		{"localhost", http.MethodGet, api + "/status", map[string]string{"Host": "localhost:7373"}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newRequest(t, context.Background(), tt.method, tt.url, "{}")
+			for k, v := range tt.header {
+				if k == "Host" {
+					req.Host = v
+				} else if v == "" {
+					req.Header.Del(k)
+				} else {
+					req.Header.Set(k, v)
+				}
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("%s %s: %v", tt.method, tt.url, err)
+			}
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "serve.token")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteToken(path, "secret"); err != nil {
+		t.Fatalf("WriteToken: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("mode = %o, want 600", perm)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "secret\n" {
+		t.Errorf("token file = %q", data)
+	}
+}
@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ErrObjectNotFound is returned by Backend.Get for a key that does not exist.
+var ErrObjectNotFound = errors.New("object not found")
+
+// Backend stores files under slash-separated keys. Task work directories are
+// pushed to and pulled from a backend to share them between machines; see
+// Workspace.PushWork and Workspace.PullWork.
+type Backend interface {
+	// Name describes the backend for messages, e.g. "s3://bucket/prefix".
+	Name() string
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(ctx context.Context, key string, data []byte) error
+	Delete(ctx context.Context, key string) error
+	// List returns the keys starting with prefix, sorted.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// LocalBackend is a Backend on the local filesystem, for example a shared
+// network mount.
+type LocalBackend struct {
+	root string
+}
+
+// NewLocalBackend creates a backend storing keys as files below root.
+func NewLocalBackend(root string) *LocalBackend {
+	return &LocalBackend{root: root}
+}
+
+// Name returns the backend's root directory.
+func (b *LocalBackend) Name() string {
+	return b.root
+}
+
+func (b *LocalBackend) path(key string) (string, error) {
+	clean := path.Clean(key)
+	if clean == "." || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+
+	return filepath.Join(b.root, filepath.FromSlash(clean)), nil
+}
+
+// Get reads the file for key.
+func (b *LocalBackend) Get(_ context.Context, key string) ([]byte, error) {
+	p, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+
+	return data, err
+}
+
+// Put writes the file for key, creating directories as needed.
+func (b *LocalBackend) Put(_ context.Context, key string, data []byte) error {
+	p, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, p)
+}
+
+// Delete removes the file for key. Missing keys are not an error.
+func (b *LocalBackend) Delete(_ context.Context, key string) error {
+	p, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// List walks the backend root for keys starting with prefix.
+func (b *LocalBackend) List(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(b.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasSuffix(d.Name(), ".tmp") {
+			return nil
+		}
+		rel, err := filepath.Rel(b.root, p)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	slices.Sort(keys)
+
+	return keys, err
+}
+
+// SyncResult counts the files a push or pull transferred.
+type SyncResult struct {
+	Copied  int // Files uploaded or downloaded
+	Deleted int // Files removed because the source no longer has them
+}
+
+// PushWork uploads a task's work directory to the backend under
+// "<taskID>/", replacing what was there: remote files the task no longer has
+// are deleted.
+func (w *Workspace) PushWork(ctx context.Context, b Backend, taskID string) (SyncResult, error) {
+	var result SyncResult
+
+	files, err := w.workFiles(taskID)
+	if err != nil {
+		return result, err
+	}
+	if !slices.Contains(files, workFileName) {
+		return result, fmt.Errorf("task %s has no %s", taskID, workFileName)
+	}
+
+	for _, rel := range files {
+		data, err := os.ReadFile(filepath.Join(w.WorkPath(taskID), filepath.FromSlash(rel)))
+		if err != nil {
+			return result, fmt.Errorf("read %s: %w", rel, err)
+		}
+		if err := b.Put(ctx, taskID+"/"+rel, data); err != nil {
+			return result, fmt.Errorf("upload %s: %w", rel, err)
+		}
+		result.Copied++
+	}
+
+	remote, err := b.List(ctx, taskID+"/")
+	if err != nil {
+		return result, fmt.Errorf("list %s: %w", b.Name(), err)
+	}
+	for _, key := range remote {
+		if !slices.Contains(files, strings.TrimPrefix(key, taskID+"/")) {
+			if err := b.Delete(ctx, key); err != nil {
+				return result, fmt.Errorf("delete %s: %w", key, err)
+			}
+			result.Deleted++
+		}
+	}
+
+	return result, nil
+}
+
+// PullWork downloads a task's work directory from the backend, replacing the
+// local copy: local files the remote copy does not have are deleted.
+// Downloads go through a staging directory, so a failed pull leaves the
+// local task untouched.
+func (w *Workspace) PullWork(ctx context.Context, b Backend, taskID string) (SyncResult, error) {
+	var result SyncResult
+
+	if taskID == "" || taskID != filepath.Base(taskID) || strings.HasPrefix(taskID, ".") {
+		return result, fmt.Errorf("invalid task ID %q", taskID)
+	}
+	keys, err := b.List(ctx, taskID+"/")
+	if err != nil {
+		return result, fmt.Errorf("list %s: %w", b.Name(), err)
+	}
+	if !slices.Contains(keys, taskID+"/"+workFileName) {
+		return result, fmt.Errorf("%w: task %s in %s", ErrObjectNotFound, taskID, b.Name())
+	}
+
+	if err := os.MkdirAll(w.workRoot, 0o755); err != nil {
+		return result, fmt.Errorf("create work directory: %w", err)
+	}
+	staging, err := os.MkdirTemp(w.workRoot, ".pull-"+taskID+"-")
+	if err != nil {
+		return result, fmt.Errorf("create staging directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+
+	for _, key := range keys {
+		rel := path.Clean(strings.TrimPrefix(key, taskID+"/"))
+		if path.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+			return result, fmt.Errorf("remote key escapes the task directory: %s", key)
+		}
+		data, err := b.Get(ctx, key)
+		if err != nil {
+			return result, fmt.Errorf("download %s: %w", key, err)
+		}
+		p := filepath.Join(staging, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return result, err
+		}
+		if err := os.WriteFile(p, data, 0o644); err != nil {
+			return result, fmt.Errorf("write %s: %w", rel, err)
+		}
+		result.Copied++
+	}
+
+	// Keep other processes from writing into the task while it is replaced
+	lock, err := w.Lock(taskID)
+	if err != nil {
+		return result, err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	if w.WorkExists(taskID) {
+		local, err := w.workFiles(taskID)
+		if err != nil {
+			return result, err
+		}
+		for _, rel := range local {
+			if !slices.Contains(keys, taskID+"/"+rel) {
+				result.Deleted++
+			}
+		}
+		if err := os.RemoveAll(w.WorkPath(taskID)); err != nil {
+			return result, fmt.Errorf("replace local task: %w", err)
+		}
+	}
+	if err := os.Rename(staging, w.WorkPath(taskID)); err != nil {
+		return result, fmt.Errorf("move pulled task into place: %w", err)
+	}
+
+	return result, nil
+}
+
+// RemoteWorks returns the IDs of the tasks stored in the backend.
+func (w *Workspace) RemoteWorks(ctx context.Context, b Backend) ([]string, error) {
+	keys, err := b.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", b.Name(), err)
+	}
+
+	var ids []string
+	for _, key := range keys {
+		if id, rest, ok := strings.Cut(key, "/"); ok && rest == workFileName {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+// workFiles returns the slash-separated paths of a task's files, relative to
+// its work directory, skipping temporary files.
+func (w *Workspace) workFiles(taskID string) ([]string, error) {
+	root := w.WorkPath(taskID)
+	var files []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !d.Type().IsRegular() || strings.HasSuffix(d.Name(), ".tmp") {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("collect task files: %w", err)
+	}
+
+	return files, nil
+}
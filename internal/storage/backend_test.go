@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLocalBackend(t *testing.T) {
+	ctx := context.Background()
+	b := NewLocalBackend(t.TempDir())
+
+	if err := b.Put(ctx, "task1/notes/a.md", []byte("a")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := b.Put(ctx, "task2/work.yaml", []byte("b")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if data, err := b.Get(ctx, "task1/notes/a.md"); err != nil || string(data) != "a" {
+		t.Errorf("Get = %q, %v", data, err)
+	}
+	if _, err := b.Get(ctx, "task1/missing"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Get missing error = %v, want ErrObjectNotFound", err)
+	}
+	if keys, _ := b.List(ctx, "task1/"); !slices.Equal(keys, []string{"task1/notes/a.md"}) {
+		t.Errorf("List = %v", keys)
+	}
+	if err := b.Put(ctx, "../escape", []byte("x")); err == nil {
+		t.Error("Put accepted a key outside the backend")
+	}
+	if err := b.Delete(ctx, "task1/notes/a.md"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := b.Delete(ctx, "task1/notes/a.md"); err != nil {
+		t.Errorf("Delete of a missing key: %v", err)
+	}
+}
+
+func TestPushPullWork(t *testing.T) {
+	ctx := context.Background()
+	b := NewLocalBackend(t.TempDir())
+
+	alice := newBundleWorkspace(t)
+	if _, err := alice.CreateWork("task1", SourceInfo{Type: "file", Ref: "task.md"}); err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	if err := alice.SaveSpecification("task1", 1, "# Add a cache\n"); err != nil {
+		t.Fatalf("SaveSpecification: %v", err)
+	}
+	if err := b.Put(ctx, "task1/stale.md", []byte("removed locally")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	pushed, err := alice.PushWork(ctx, b, "task1")
+	if err != nil {
+		t.Fatalf("PushWork: %v", err)
+	}
+	if pushed.Copied == 0 || pushed.Deleted != 1 {
+		t.Errorf("PushWork = %+v, want files copied and the stale file deleted", pushed)
+	}
+	if ids, _ := alice.RemoteWorks(ctx, b); !slices.Equal(ids, []string{"task1"}) {
+		t.Errorf("RemoteWorks = %v", ids)
+	}
+
+	bob := newBundleWorkspace(t)
+	if err := os.MkdirAll(bob.WorkPath("task1"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bob.WorkPath("task1"), "local-only.md"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pulled, err := bob.PullWork(ctx, b, "task1")
+	if err != nil {
+		t.Fatalf("PullWork: %v", err)
+	}
+	if pulled.Copied != pushed.Copied || pulled.Deleted != 1 {
+		t.Errorf("PullWork = %+v, want %d copied and 1 deleted", pulled, pushed.Copied)
+	}
+	if spec, err := bob.LoadSpecification("task1", 1); err != nil || !strings.Contains(spec, "Add a cache") {
+		t.Errorf("specification not pulled: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(bob.WorkPath("task1"), "local-only.md")); !os.IsNotExist(err) {
+		t.Error("pull kept a file the remote task does not have")
+	}
+	if tasks, _ := bob.ListWorks(); !slices.Equal(tasks, []string{"task1"}) {
+		t.Errorf("ListWorks = %v", tasks)
+	}
+
+	if _, err := bob.PullWork(ctx, b, "missing"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("PullWork of a missing task error = %v, want ErrObjectNotFound", err)
+	}
+}
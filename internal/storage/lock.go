@@ -3,14 +3,67 @@ package storage
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
+// ErrLocked is returned when a lock is still held by another process when
+// the timeout for acquiring it runs out.
+var ErrLocked = errors.New("locked by another process")
+
+// LockHolder identifies the process holding a lock. It is written into the
+// lock file while the lock is held, so a process waiting for the lock can
+// tell the user who has it.
+type LockHolder struct {
+	PID      int       `yaml:"pid"`
+	Owner    string    `yaml:"owner"`
+	Hostname string    `yaml:"hostname"`
+	Command  string    `yaml:"command,omitempty"`
+	Since    time.Time `yaml:"since"`
+}
+
+// String describes the holder for error messages.
+func (h *LockHolder) String() string {
+	command := h.Command
+	if command == "" {
+		command = "process"
+	}
+
+	return fmt.Sprintf("%s (pid %d, %s@%s) since %s", command, h.PID, h.Owner, h.Hostname, h.Since.Format(time.DateTime))
+}
+
+// ReadLockHolder returns the holder recorded in a lock file. Returns nil
+// without error when the lock is free or its holder is unknown.
+func ReadLockHolder(path string) (*LockHolder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil //nolint:nilnil // no lock file means no holder
+		}
+
+		return nil, fmt.Errorf("read lock file: %w", err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, nil //nolint:nilnil // released locks are empty
+	}
+
+	var holder LockHolder
+	if err := yaml.Unmarshal(data, &holder); err != nil {
+		return nil, fmt.Errorf("parse lock file: %w", err)
+	}
+
+	return &holder, nil
+}
+
 // FileLock provides file-based locking for concurrent access.
-// Uses flock(2) for cross-process advisory locking.
+// Uses flock(2) for cross-process advisory locking. While held, the lock
+// file records the holding process (see LockHolder).
 type FileLock struct {
 	file *os.File
 	path string
@@ -43,6 +96,7 @@ func (l *FileLock) Lock() error {
 	}
 
 	l.file = f
+	l.claim()
 
 	return nil
 }
@@ -73,6 +127,7 @@ func (l *FileLock) TryLock() (bool, error) {
 	}
 
 	l.file = f
+	l.claim()
 
 	return true, nil
 }
@@ -93,7 +148,12 @@ func (l *FileLock) LockWithTimeout(timeout time.Duration) error {
 		}
 
 		if time.Now().After(deadline) {
-			return fmt.Errorf("lock timeout after %v", timeout)
+			holder, _ := ReadLockHolder(l.path)
+			if holder == nil {
+				return fmt.Errorf("%w: lock timeout after %v on %s", ErrLocked, timeout, filepath.Base(l.path))
+			}
+
+			return fmt.Errorf("%w: %s is held by %s", ErrLocked, filepath.Base(l.path), holder)
 		}
 
 		time.Sleep(interval)
@@ -110,7 +170,8 @@ func (l *FileLock) Unlock() error {
 		return nil
 	}
 
-	// Release the lock
+	// Clear the holder record, then release the lock
+	_ = l.file.Truncate(0)
 	if err := syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN); err != nil {
 		return fmt.Errorf("release lock: %w", err)
 	}
@@ -125,6 +186,48 @@ func (l *FileLock) Unlock() error {
 	return nil
 }
 
+// claim records the current process as the lock holder. A holder record
+// left in the file means its process exited without unlocking (flock locks
+// are released by the kernel when a process dies), so the stale lock is
+// taken over.
+func (l *FileLock) claim() {
+	if previous, err := ReadLockHolder(l.path); err == nil && previous != nil {
+		slog.Warn("taking over stale lock", "path", l.path, "holder", previous.String())
+	}
+
+	data, err := yaml.Marshal(&LockHolder{
+		PID:      os.Getpid(),
+		Owner:    currentOwner(),
+		Hostname: currentHostname(),
+		Command:  currentCommand(),
+		Since:    time.Now(),
+	})
+	if err != nil {
+		return
+	}
+	// The holder record is informational; locking works without it
+	if err := l.file.Truncate(0); err == nil {
+		_, _ = l.file.WriteAt(data, 0)
+	}
+}
+
+// currentCommand returns the program name and up to two subcommands, such
+// as "mehr task pull".
+func currentCommand() string {
+	if len(os.Args) == 0 {
+		return ""
+	}
+	parts := []string{filepath.Base(os.Args[0])}
+	for _, arg := range os.Args[1:min(len(os.Args), 3)] {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		parts = append(parts, arg)
+	}
+
+	return strings.Join(parts, " ")
+}
+
 // WithLock executes a function while holding an exclusive lock.
 // The lock is automatically released when the function returns.
 func WithLock(lockPath string, fn func() error) error {
@@ -1,8 +1,11 @@
 package storage
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -244,3 +247,55 @@ func TestWithLockTimeout_Concurrent(t *testing.T) {
 		t.Errorf("counter = %d, want 3", counter)
 	}
 }
+
+func TestFileLock_Holder(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "holder.lock")
+
+	lock := NewFileLock(lockPath)
+	if err := lock.Lock(); err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+
+	holder, err := ReadLockHolder(lockPath)
+	if err != nil || holder == nil {
+		t.Fatalf("ReadLockHolder = %v, %v", holder, err)
+	}
+	if holder.PID != os.Getpid() || holder.Hostname == "" {
+		t.Errorf("holder = %+v, want this process", holder)
+	}
+
+	err = NewFileLock(lockPath).LockWithTimeout(50 * time.Millisecond)
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("LockWithTimeout error = %v, want ErrLocked", err)
+	}
+	if !strings.Contains(err.Error(), fmt.Sprintf("pid %d", os.Getpid())) {
+		t.Errorf("error %q does not name the holding process", err)
+	}
+
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if holder, _ := ReadLockHolder(lockPath); holder != nil {
+		t.Errorf("holder after Unlock = %+v, want none", holder)
+	}
+}
+
+func TestFileLock_StaleHolder(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "stale.lock")
+
+	// A crashed process leaves its record behind, but flock was released
+	if err := os.WriteFile(lockPath, []byte("pid: 999999\nowner: ghost\nhostname: gone\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	lock := NewFileLock(lockPath)
+	acquired, err := lock.TryLock()
+	if err != nil || !acquired {
+		t.Fatalf("TryLock = %v, %v, want stale lock taken over", acquired, err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	if holder, _ := ReadLockHolder(lockPath); holder == nil || holder.PID != os.Getpid() {
+		t.Errorf("holder = %+v, want this process", holder)
+	}
+}
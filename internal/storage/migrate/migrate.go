@@ -0,0 +1,144 @@
+// Package migrate upgrades storage files written by older versions of
+// mehrhof. Each kind of versioned file (work.yaml, session files) has a
+// chain of registered migrations, each moving a document one schema version
+// forward. Migrations work on the decoded YAML document, so they do not
+// depend on the current Go types.
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kinds of versioned storage files.
+const (
+	KindWork    = "work"
+	KindSession = "session"
+)
+
+// ErrNoMigration is returned when no chain of migrations leads from a
+// file's version to the wanted one, typically because the file was written
+// by a newer mehrhof.
+var ErrNoMigration = errors.New("no migration path")
+
+// Migration upgrades one kind of document from one schema version to the next.
+type Migration struct {
+	Kind        string
+	From        string // "" for files written before the kind was versioned
+	To          string
+	Description string
+	Apply       func(doc map[string]any) error
+}
+
+// Registry holds migrations by kind.
+type Registry struct {
+	migrations map[string][]Migration
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{migrations: make(map[string][]Migration)}
+}
+
+// Register adds a migration. It panics on a second migration of the same
+// kind and source version, since the upgrade path would be ambiguous.
+func (r *Registry) Register(m Migration) {
+	if slices.ContainsFunc(r.migrations[m.Kind], func(other Migration) bool { return other.From == m.From }) {
+		panic(fmt.Sprintf("migrate: duplicate %s migration from version %q", m.Kind, m.From))
+	}
+	r.migrations[m.Kind] = append(r.migrations[m.Kind], m)
+}
+
+// Plan returns the migrations that take a document of kind from version
+// from to version to, in the order they apply. It is empty when the
+// versions are equal.
+func (r *Registry) Plan(kind, from, to string) ([]Migration, error) {
+	var plan []Migration
+	for version := from; version != to; {
+		i := slices.IndexFunc(r.migrations[kind], func(m Migration) bool { return m.From == version })
+		if i < 0 || len(plan) > len(r.migrations[kind]) {
+			return nil, fmt.Errorf("%w for %s from version %q to %q", ErrNoMigration, kind, from, to)
+		}
+		m := r.migrations[kind][i]
+		plan = append(plan, m)
+		version = m.To
+	}
+
+	return plan, nil
+}
+
+// Upgrade applies the migrations taking a YAML document of kind to version
+// to. It returns the upgraded document and the migrations applied; data is
+// returned as is when it already has that version.
+func (r *Registry) Upgrade(kind string, data []byte, to string) ([]byte, []Migration, error) {
+	from, err := Version(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	plan, err := r.Plan(kind, from, to)
+	if err != nil || len(plan) == 0 {
+		return data, nil, err
+	}
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("parse %s document: %w", kind, err)
+	}
+	if doc == nil {
+		doc = make(map[string]any)
+	}
+	for _, m := range plan {
+		if err := m.Apply(doc); err != nil {
+			return nil, nil, fmt.Errorf("migrate %s from %q to %q: %w", kind, m.From, m.To, err)
+		}
+		doc["version"] = m.To
+	}
+
+	upgraded, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal %s document: %w", kind, err)
+	}
+
+	return upgraded, plan, nil
+}
+
+// Version returns the schema version of a YAML document, "" when it has none.
+func Version(data []byte) (string, error) {
+	var header struct {
+		Version string `yaml:"version"`
+	}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return "", fmt.Errorf("parse version: %w", err)
+	}
+
+	return header.Version, nil
+}
+
+// Default holds the built-in migrations used by the storage package.
+var Default = NewRegistry()
+
+func init() {
+	Default.Register(Migration{
+		Kind:        KindWork,
+		From:        "",
+		To:          "1",
+		Description: "add schema version to work.yaml",
+		Apply:       func(map[string]any) error { return nil },
+	})
+	Default.Register(Migration{
+		Kind:        KindSession,
+		From:        "",
+		To:          "1",
+		Description: "add schema version and kind to session file",
+		Apply: func(doc map[string]any) error {
+			if _, ok := doc["kind"]; !ok {
+				doc["kind"] = "Session"
+			}
+
+			return nil
+		},
+	})
+}
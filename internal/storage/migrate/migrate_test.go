@@ -0,0 +1,82 @@
+package migrate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testRegistry() *Registry {
+	r := NewRegistry()
+	r.Register(Migration{Kind: KindWork, From: "", To: "1", Description: "version", Apply: func(map[string]any) error { return nil }})
+	r.Register(Migration{Kind: KindWork, From: "1", To: "2", Description: "rename name to title", Apply: func(doc map[string]any) error {
+		doc["title"] = doc["name"]
+		delete(doc, "name")
+
+		return nil
+	}})
+
+	return r
+}
+
+func TestPlan(t *testing.T) {
+	r := testRegistry()
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     int
+		wantErr  bool
+	}{
+		{"current", "2", "2", 0, false},
+		{"one step", "1", "2", 1, false},
+		{"chain from unversioned", "", "2", 2, false},
+		{"newer than known", "3", "2", 0, true},
+		{"downgrade", "2", "1", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := r.Plan(KindWork, tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Plan error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrNoMigration) {
+				t.Errorf("Plan error = %v, want ErrNoMigration", err)
+			}
+			if len(plan) != tt.want {
+				t.Errorf("Plan = %d migrations, want %d", len(plan), tt.want)
+			}
+		})
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	r := testRegistry()
+
+	upgraded, applied, err := r.Upgrade(KindWork, []byte("name: Add cache\n"), "2")
+	if err != nil {
+		t.Fatalf("Upgrade: %v", err)
+	}
+	if len(applied) != 2 {
+		t.Errorf("applied %d migrations, want 2", len(applied))
+	}
+	if s := string(upgraded); !strings.Contains(s, "title: Add cache") || !strings.Contains(s, "version: \"2\"") || strings.Contains(s, "name:") {
+		t.Errorf("upgraded document =\n%s", s)
+	}
+
+	current := []byte("version: \"2\"\ntitle: x\n")
+	if same, applied, err := r.Upgrade(KindWork, current, "2"); err != nil || len(applied) != 0 || string(same) != string(current) {
+		t.Errorf("Upgrade of a current document = %q, %d, %v", same, len(applied), err)
+	}
+}
+
+func TestRegister_Duplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register accepted a second migration from the same version")
+		}
+	}()
+
+	r := testRegistry()
+	r.Register(Migration{Kind: KindWork, From: "1", To: "2b"})
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PhaseTime is the wall-clock time spent in one workflow phase.
+type PhaseTime struct {
+	Phase    string
+	Duration time.Duration
+}
+
+// PhaseTimes sums the wall-clock time of ended sessions per phase, from
+// their StartedAt to EndedAt, in the order the phases were first worked on.
+func PhaseTimes(sessions []*Session) []PhaseTime {
+	ended := slices.DeleteFunc(slices.Clone(sessions), func(s *Session) bool {
+		return s.Metadata.EndedAt.Before(s.Metadata.StartedAt) || s.Metadata.StartedAt.IsZero()
+	})
+	slices.SortStableFunc(ended, func(a, b *Session) int {
+		return a.Metadata.StartedAt.Compare(b.Metadata.StartedAt)
+	})
+
+	var times []PhaseTime
+	for _, s := range ended {
+		d := s.Metadata.EndedAt.Sub(s.Metadata.StartedAt)
+		i := slices.IndexFunc(times, func(t PhaseTime) bool { return t.Phase == s.Metadata.Type })
+		if i < 0 {
+			times = append(times, PhaseTime{Phase: s.Metadata.Type})
+			i = len(times) - 1
+		}
+		times[i].Duration += d
+	}
+
+	return times
+}
+
+// TotalTime returns the time spent in all phases.
+func TotalTime(times []PhaseTime) time.Duration {
+	var total time.Duration
+	for _, t := range times {
+		total += t.Duration
+	}
+
+	return total
+}
+
+// FormatPhaseTimes describes phase times as "planning 12m, implementing 1h5m".
+func FormatPhaseTimes(times []PhaseTime) string {
+	parts := make([]string, 0, len(times))
+	for _, t := range times {
+		parts = append(parts, t.Phase+" "+FormatMinutes(t.Duration))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// FormatMinutes formats a duration rounded to minutes, e.g. "1h5m" or "0m".
+func FormatMinutes(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	if minutes >= 60 {
+		return fmt.Sprintf("%dh%dm", minutes/60, minutes%60)
+	}
+
+	return fmt.Sprintf("%dm", minutes)
+}
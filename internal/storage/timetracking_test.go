@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPhaseTimes(t *testing.T) {
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	session := func(typ string, from, minutes int) *Session {
+		s := &Session{Metadata: SessionMetadata{Type: typ, StartedAt: start.Add(time.Duration(from) * time.Minute)}}
+		if minutes > 0 {
+			s.Metadata.EndedAt = s.Metadata.StartedAt.Add(time.Duration(minutes) * time.Minute)
+		}
+
+		return s
+	}
+
+	times := PhaseTimes([]*Session{
+		session("implementing", 20, 50),
+		session("planning", 0, 12),
+		session("implementing", 90, 15),
+		session("reviewing", 120, 0), // still running
+	})
+
+	want := []PhaseTime{{"planning", 12 * time.Minute}, {"implementing", 65 * time.Minute}}
+	if len(times) != len(want) {
+		t.Fatalf("PhaseTimes() = %+v, want %+v", times, want)
+	}
+	for i := range want {
+		if times[i] != want[i] {
+			t.Errorf("PhaseTimes()[%d] = %+v, want %+v", i, times[i], want[i])
+		}
+	}
+	if total := TotalTime(times); total != 77*time.Minute {
+		t.Errorf("TotalTime() = %v, want 1h17m", total)
+	}
+	if got := FormatPhaseTimes(times); got != "planning 12m, implementing 1h5m" {
+		t.Errorf("FormatPhaseTimes() = %q", got)
+	}
+}
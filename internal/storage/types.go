@@ -1,6 +1,10 @@
 package storage
 
-import "time"
+import (
+	"cmp"
+	"slices"
+	"time"
+)
 
 // ActiveTask represents the currently active task (stored in .active_task).
 type ActiveTask struct {
@@ -20,8 +24,30 @@ type TaskWork struct {
 	Metadata WorkMetadata `yaml:"metadata"`
 	Source   SourceInfo   `yaml:"source"`
 	Git      GitInfo      `yaml:"git,omitempty"`
+	Repos    []RepoInfo   `yaml:"repositories,omitempty"` // Attached secondary repositories
 	Agent    AgentInfo    `yaml:"agent,omitempty"`
 	Costs    CostStats    `yaml:"costs,omitempty"`
+	Lessons  []Lesson     `yaml:"lessons,omitempty"` // Failure categories seen by quality checks, guardrails and reviews
+	Parked   *ParkedTask  `yaml:"parked,omitempty"`  // Set while another task is active (see 'mehr task switch')
+	Review   *ReviewGate  `yaml:"review,omitempty"`  // Outcome of the latest consensus review
+}
+
+// ReviewGate records the latest consensus review of a task. Its blocking
+// findings stop the task from being finished until a later review clears them.
+type ReviewGate struct {
+	ReviewedAt   time.Time `yaml:"reviewed_at"`
+	Agents       []string  `yaml:"agents"`
+	MinAgreement int       `yaml:"min_agreement"`
+	Blocking     []string  `yaml:"blocking,omitempty"` // Findings reported by at least MinAgreement agents
+}
+
+// ParkedTask records a task set aside by 'mehr task switch', so switching
+// back restores it as it was.
+type ParkedTask struct {
+	Ref       string    `yaml:"ref"`
+	State     string    `yaml:"state"`
+	WIPCommit string    `yaml:"wip_commit,omitempty"` // Commit holding the changes that were uncommitted at the switch
+	ParkedAt  time.Time `yaml:"parked_at"`
 }
 
 // WorkMetadata holds task identification.
@@ -35,6 +61,20 @@ type WorkMetadata struct {
 	ExternalKey string `yaml:"external_key,omitempty"` // User-facing key (e.g., "FEATURE-123")
 	TaskType    string `yaml:"task_type,omitempty"`    // Task type (e.g., "feature", "fix")
 	Slug        string `yaml:"slug,omitempty"`         // URL-safe title slug
+
+	// Monorepo scoping
+	Scope             string `yaml:"scope,omitempty"`               // Repo-relative subdirectory the task is restricted to
+	AllowOutsideScope bool   `yaml:"allow_outside_scope,omitempty"` // Permit file changes outside Scope
+
+	// Spec template used to seed planning (see SpecTemplate)
+	SpecTemplate string `yaml:"spec_template,omitempty"`
+
+	// Task decomposition (see 'mehr split')
+	ParentID   string    `yaml:"parent_id,omitempty"`   // Task this task was split from
+	FinishedAt time.Time `yaml:"finished_at,omitempty"` // When 'mehr finish' completed the task
+
+	// Planned effort from the task source (e.g., "2h30m"), see 'mehr report estimates'
+	Estimate string `yaml:"estimate,omitempty"`
 }
 
 // SourceInfo tracks the original source (read-only reference).
@@ -45,6 +85,94 @@ type SourceInfo struct {
 	ReadAt  time.Time `yaml:"read_at"`           // when source was read
 	Files   []string  `yaml:"files,omitempty"`   // relative paths to source files (e.g., "source/task.md")
 	Content string    `yaml:"content,omitempty"` // kept for backwards compat, empty for new tasks
+
+	// Incremental refresh (see 'mehr refresh')
+	States   map[string]SourceFileState `yaml:"states,omitempty"`   // per-file version, keyed by path under source/
+	Delta    *SourceDelta               `yaml:"delta,omitempty"`    // changes applied by the last sync
+	Revision int                        `yaml:"revision,omitempty"` // 0 for the snapshot taken at start
+	Drift    *SourceDrift               `yaml:"drift,omitempty"`    // differences from the snapshot taken at start
+}
+
+// SourceFileState identifies the snapshotted version of one source file.
+type SourceFileState struct {
+	ModTime time.Time `yaml:"mod_time,omitempty"` // zero when the provider does not track it
+	Size    int64     `yaml:"size"`
+	Hash    string    `yaml:"sha256"`
+	ETag    string    `yaml:"etag,omitempty"` // HTTP sources only
+}
+
+// SourceDelta records which source files the last sync changed.
+type SourceDelta struct {
+	SyncedAt time.Time `yaml:"synced_at"`
+	Revision int       `yaml:"revision,omitempty"` // revision the sync produced, unchanged when empty
+	Added    []string  `yaml:"added,omitempty"`
+	Modified []string  `yaml:"modified,omitempty"`
+	Removed  []string  `yaml:"removed,omitempty"`
+}
+
+// Empty reports whether the sync found no changes.
+func (d *SourceDelta) Empty() bool {
+	return len(d.Added) == 0 && len(d.Modified) == 0 && len(d.Removed) == 0
+}
+
+// SourceDrift records that the task's source no longer matches the snapshot
+// taken when the task started, i.e. that its requirements changed. The diff
+// itself is kept in source-drift.diff next to work.yaml.
+type SourceDrift struct {
+	Revision   int       `yaml:"revision"`    // source revision the drift was computed for
+	DetectedAt time.Time `yaml:"detected_at"` // when the source first drifted
+	Files      []string  `yaml:"files"`       // paths under source/ that differ from the original
+}
+
+// Lesson counts the failures of one category for a task, so recurring
+// mistakes can be pointed out to the agent in later prompts.
+type Lesson struct {
+	Source   string    `yaml:"source"`            // Check that failed: "quality", "guardrail" or "review"
+	Category string    `yaml:"category"`          // Failure category, e.g. "failing tests"
+	Count    int       `yaml:"count"`             // Number of failed checks in this category
+	Example  string    `yaml:"example,omitempty"` // Detail from the most recent failure
+	LastSeen time.Time `yaml:"last_seen"`
+}
+
+// RecordLesson counts a failure of category reported by source.
+func (tw *TaskWork) RecordLesson(source, category, example string, at time.Time) {
+	for i := range tw.Lessons {
+		lesson := &tw.Lessons[i]
+		if lesson.Source != source || lesson.Category != category {
+			continue
+		}
+		lesson.Count++
+		lesson.LastSeen = at
+		if example != "" {
+			lesson.Example = example
+		}
+
+		return
+	}
+
+	tw.Lessons = append(tw.Lessons, Lesson{
+		Source:   source,
+		Category: category,
+		Count:    1,
+		Example:  example,
+		LastSeen: at,
+	})
+}
+
+// RecurringLessons returns the lessons seen at least threshold times, most
+// frequent first.
+func (tw *TaskWork) RecurringLessons(threshold int) []Lesson {
+	var recurring []Lesson
+	for _, lesson := range tw.Lessons {
+		if lesson.Count >= threshold {
+			recurring = append(recurring, lesson)
+		}
+	}
+	slices.SortStableFunc(recurring, func(a, b Lesson) int {
+		return cmp.Compare(b.Count, a.Count)
+	})
+
+	return recurring
 }
 
 // GitInfo holds git-related information.
@@ -59,6 +187,16 @@ type GitInfo struct {
 	BranchPattern string `yaml:"branch_pattern,omitempty"` // Template used to generate branch
 }
 
+// RepoInfo holds git information for a secondary repository attached to a task.
+type RepoInfo struct {
+	Name         string `yaml:"name"`                    // Workspace name from config
+	Path         string `yaml:"path"`                    // Repository path (relative to project root or absolute)
+	Branch       string `yaml:"branch,omitempty"`        // Task branch in this repository
+	BaseBranch   string `yaml:"base_branch,omitempty"`   // Branch the task branch was created from
+	TargetBranch string `yaml:"target_branch,omitempty"` // Branch to merge or open a PR into
+	PRURL        string `yaml:"pr_url,omitempty"`        // Pull request opened on finish
+}
+
 // StepAgentInfo holds per-step agent resolution info.
 type StepAgentInfo struct {
 	Name      string            `yaml:"name,omitempty"`       // Resolved agent name for this step
@@ -94,8 +232,43 @@ type Specification struct {
 	CreatedAt   time.Time `yaml:"created_at,omitempty"`
 	UpdatedAt   time.Time `yaml:"updated_at,omitempty"`
 	CompletedAt time.Time `yaml:"completed_at,omitempty"`
-	Sections    []string  `yaml:"-"` // Parsed from markdown content
-	Content     string    `yaml:"-"` // Raw markdown content (without frontmatter)
+	Sections    []string  `yaml:"-"`                  // Parsed from markdown content
+	Content     string    `yaml:"-"`                  // Raw markdown content (without frontmatter)
+	Template    string    `yaml:"template,omitempty"` // Spec template the file was scaffolded from
+
+	// Plan vs actual tracking (see SpecAccuracy)
+	PredictedFiles   []string `yaml:"predicted_files,omitempty"`   // Files the plan said it would touch
+	ImplementedFiles []string `yaml:"implemented_files,omitempty"` // Files changed while implementing this spec
+
+	// Per-spec implementation progress
+	Sessions    []string `yaml:"sessions,omitempty"`    // Session files that implemented this spec
+	Checkpoints []int    `yaml:"checkpoints,omitempty"` // Checkpoints holding this spec's changes
+
+	// Critic assessment from planning (see workflow.spec_critique)
+	Critique *SpecCritique `yaml:"critique,omitempty"`
+}
+
+// Specification critique verdicts.
+const (
+	CritiqueVerdictPass   = "pass"
+	CritiqueVerdictRevise = "revise"
+)
+
+// SpecCritique is a critic agent's assessment of a specification against the
+// planning rubric, scored 1-5 per criterion.
+type SpecCritique struct {
+	Critic       string   `yaml:"critic"`
+	Rounds       int      `yaml:"rounds"`  // Critique rounds run, including the final one
+	Verdict      string   `yaml:"verdict"` // CritiqueVerdictPass or CritiqueVerdictRevise
+	Completeness int      `yaml:"completeness"`
+	Testability  int      `yaml:"testability"`
+	EdgeCases    int      `yaml:"edge_cases"`
+	Issues       []string `yaml:"issues,omitempty"` // Issues left in the final round
+}
+
+// Passed reports whether the critic accepted the specification.
+func (sc *SpecCritique) Passed() bool {
+	return sc.Verdict == CritiqueVerdictPass
 }
 
 // Note represents a user note added via the note command.
@@ -117,6 +290,30 @@ type Session struct {
 	Metadata  SessionMetadata `yaml:"metadata"`
 	Usage     *UsageInfo      `yaml:"usage,omitempty"`
 	Exchanges []Exchange      `yaml:"exchanges,omitempty"`
+	Changes   []FileSummary   `yaml:"changes,omitempty"` // Structural summary of files changed in the session
+
+	// User notes and bookmarks on individual exchanges
+	Annotations []Annotation `yaml:"annotations,omitempty"`
+}
+
+// Annotation is a user note attached to one exchange of a session.
+type Annotation struct {
+	Exchange  int       `yaml:"exchange"` // 1-based exchange number
+	Note      string    `yaml:"note,omitempty"`
+	Bookmark  bool      `yaml:"bookmark,omitempty"`
+	CreatedAt time.Time `yaml:"created_at"`
+}
+
+// Bookmarks returns the session's bookmarked annotations.
+func (s *Session) Bookmarks() []Annotation {
+	var bookmarks []Annotation
+	for _, a := range s.Annotations {
+		if a.Bookmark {
+			bookmarks = append(bookmarks, a)
+		}
+	}
+
+	return bookmarks
 }
 
 // SessionMetadata holds session identification.
@@ -126,6 +323,12 @@ type SessionMetadata struct {
 	Type      string    `yaml:"type"` // planning, implementing, reviewing, checkpointing
 	Agent     string    `yaml:"agent"`
 	State     string    `yaml:"state,omitempty"` // task state when session started
+	// Specification number implemented by this session (0 when not spec-scoped)
+	Specification int `yaml:"specification,omitempty"`
+	// Agent-native conversation ID, used to resume the conversation (e.g. claude --resume)
+	AgentSessionID string `yaml:"agent_session_id,omitempty"`
+	// Set when the run was interrupted before it finished
+	Canceled bool `yaml:"canceled,omitempty"`
 }
 
 // UsageInfo tracks token/cost usage.
@@ -136,6 +339,34 @@ type UsageInfo struct {
 	CostUSD      float64 `yaml:"cost_usd,omitempty"`
 }
 
+// UsageRecord is one agent call in a task's usage ledger (usage.yaml).
+type UsageRecord struct {
+	Timestamp    time.Time `yaml:"timestamp"`
+	Step         string    `yaml:"step"`
+	Agent        string    `yaml:"agent,omitempty"`
+	Model        string    `yaml:"model,omitempty"`
+	InputTokens  int       `yaml:"input_tokens"`
+	OutputTokens int       `yaml:"output_tokens"`
+	CachedTokens int       `yaml:"cached_tokens,omitempty"`
+	CostUSD      float64   `yaml:"cost_usd,omitempty"`
+}
+
+// EventRecord is one event in a task's event log (events.jsonl).
+type EventRecord struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Type      string         `json:"type"`
+	Data      map[string]any `json:"data,omitempty"`
+}
+
+// EventFilter selects events from a task's event log. Zero fields match all.
+type EventFilter struct {
+	Offset int       // Skip the first Offset events of the log
+	Since  time.Time // Only events at or after Since
+	Until  time.Time // Only events before Until
+	Types  []string  // Only events of these types
+	Limit  int       // Only the most recent Limit matching events
+}
+
 // CostStats tracks cumulative token/cost usage across all workflow steps.
 type CostStats struct {
 	TotalInputTokens  int                      `yaml:"total_input_tokens"`
@@ -165,7 +396,28 @@ type Exchange struct {
 // FileChange records a file modification.
 type FileChange struct {
 	Path      string `yaml:"path"`
-	Operation string `yaml:"operation"` // create, update, delete
+	Operation string `yaml:"operation"`      // create, update, delete
+	Line      int    `yaml:"line,omitempty"` // First changed line, if known
+}
+
+// FileSummary is a language-aware structural summary of one changed file.
+// Symbols are written as declared, e.g. "func New", "func (*Server) Run", "type Config".
+type FileSummary struct {
+	Path      string      `yaml:"path"`
+	Language  string      `yaml:"language,omitempty"`
+	Operation string      `yaml:"operation"` // create, update, delete
+	Added     []string    `yaml:"added,omitempty"`
+	Modified  []string    `yaml:"modified,omitempty"`
+	Removed   []string    `yaml:"removed,omitempty"`
+	API       []APIChange `yaml:"api,omitempty"` // Exported API changes (Go packages)
+}
+
+// APIChange records a change to an exported declaration.
+type APIChange struct {
+	Change string `yaml:"change"` // added, removed, changed
+	Symbol string `yaml:"symbol"`
+	Before string `yaml:"before,omitempty"` // Declaration before the change
+	After  string `yaml:"after,omitempty"`  // Declaration after the change
 }
 
 // Checkpoint records a git checkpoint for undo/redo.
@@ -194,7 +446,7 @@ func NewTaskWork(id string, source SourceInfo) *TaskWork {
 	now := time.Now()
 
 	return &TaskWork{
-		Version: "1",
+		Version: WorkSchemaVersion,
 		Metadata: WorkMetadata{
 			ID:        id,
 			CreatedAt: now,
@@ -209,7 +461,7 @@ func NewSession(sessionType, agent, state string) *Session {
 	now := time.Now()
 
 	return &Session{
-		Version: "1",
+		Version: SessionSchemaVersion,
 		Kind:    "Session",
 		Metadata: SessionMetadata{
 			StartedAt: now,
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskWork_Lessons(t *testing.T) {
+	tw := &TaskWork{}
+	first := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	later := first.Add(time.Hour)
+
+	tw.RecordLesson("quality", "failing tests", "--- FAIL: TestA", first)
+	tw.RecordLesson("review", "security", "", first)
+	tw.RecordLesson("quality", "failing tests", "--- FAIL: TestB", later)
+	tw.RecordLesson("quality", "failing tests", "", later)
+	tw.RecordLesson("review", "security", "token logged", later)
+
+	if len(tw.Lessons) != 2 {
+		t.Fatalf("got %d lessons, want 2", len(tw.Lessons))
+	}
+	tests := tw.Lessons[0]
+	if tests.Count != 3 || tests.Example != "--- FAIL: TestB" || !tests.LastSeen.Equal(later) {
+		t.Errorf("failing tests lesson = %+v", tests)
+	}
+
+	recurring := tw.RecurringLessons(2)
+	if len(recurring) != 2 || recurring[0].Category != "failing tests" {
+		t.Errorf("RecurringLessons(2) = %+v, want most frequent first", recurring)
+	}
+	if recurring := tw.RecurringLessons(3); len(recurring) != 1 {
+		t.Errorf("RecurringLessons(3) = %+v, want only failing tests", recurring)
+	}
+}
@@ -7,19 +7,26 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/valksor/go-mehrhof/internal/config"
 )
 
 const (
-	taskDirName     = ".mehrhof"
 	workSubDirName  = "work"
 	plannedDirName  = "planned"
 	activeTaskFile  = ".active_task"
 	workFileName    = "work.yaml"
 	notesFileName   = "notes.md"
+	notesDirName    = "notes"
+	usageFileName   = "usage.yaml"
+	eventsFileName  = "events.jsonl"
 	specsDirName    = "specifications"
+	docsDirName     = "documentation"
 	sessionsDirName = "sessions"
 	configFileName  = "config.yaml"
+	localConfigFile = "config.local.yaml"
 	envFileName     = ".env"
+	serveTokenFile  = "serve.token"
 
 	// Usage buffer configuration.
 	defaultUsageFlushInterval  = 5 * time.Second // Auto-flush interval
@@ -38,6 +45,7 @@ type usageBuffer struct {
 // Workspace manages task storage within a repository.
 type Workspace struct {
 	root     string // Repository root
+	taskDir  string // Task root relative to root, in slash form (see config.TaskDir)
 	taskRoot string // .mehrhof directory
 	workRoot string // .mehrhof/work directory
 
@@ -45,6 +53,10 @@ type Workspace struct {
 	usageMu   sync.RWMutex
 	usageBuf  map[string]map[string]*usageBuffer // taskID -> step -> buffer
 	lastFlush time.Time
+
+	// Task locks held through Lock
+	lockMu    sync.Mutex
+	taskLocks map[string]*TaskLock
 }
 
 // OpenWorkspace opens or creates a workspace in the given directory.
@@ -55,10 +67,11 @@ func OpenWorkspace(repoRoot string, cfg *WorkspaceConfig) (*Workspace, error) {
 		return nil, fmt.Errorf("resolve path: %w", err)
 	}
 
-	taskRoot := filepath.Join(absRoot, taskDirName)
+	taskDir := config.TaskDir()
+	taskRoot := filepath.Join(absRoot, filepath.FromSlash(taskDir))
 
 	// Determine work directory path from config or default
-	workDir := ".mehrhof/work" // default
+	workDir := taskDir + "/" + workSubDirName // default
 	if cfg != nil && cfg.Storage.WorkDir != "" {
 		workDir = cfg.Storage.WorkDir
 	}
@@ -67,6 +80,7 @@ func OpenWorkspace(repoRoot string, cfg *WorkspaceConfig) (*Workspace, error) {
 
 	return &Workspace{
 		root:      absRoot,
+		taskDir:   taskDir,
 		taskRoot:  taskRoot,
 		workRoot:  workRoot,
 		usageBuf:  make(map[string]map[string]*usageBuffer),
@@ -89,11 +103,33 @@ func (w *Workspace) WorkRoot() string {
 	return w.workRoot
 }
 
+// TemplatesDir returns the .mehrhof/templates directory holding task templates.
+func (w *Workspace) TemplatesDir() string {
+	return filepath.Join(w.taskRoot, "templates")
+}
+
+// ScriptsDir returns the .mehrhof/scripts directory holding workflow scripts.
+func (w *Workspace) ScriptsDir() string {
+	return filepath.Join(w.taskRoot, "scripts")
+}
+
+// CacheDir returns the .mehrhof/cache directory holding data that can be
+// rebuilt, such as the repository map.
+func (w *Workspace) CacheDir() string {
+	return filepath.Join(w.taskRoot, "cache")
+}
+
 // ConfigPath returns the path to the config file.
 func (w *Workspace) ConfigPath() string {
 	return filepath.Join(w.taskRoot, configFileName)
 }
 
+// LocalConfigPath returns the path of the personal, git-ignored config
+// that overrides config.yaml.
+func (w *Workspace) LocalConfigPath() string {
+	return filepath.Join(w.taskRoot, localConfigFile)
+}
+
 // HasConfig returns true if the config file exists.
 func (w *Workspace) HasConfig() bool {
 	_, err := os.Stat(w.ConfigPath())
@@ -106,6 +142,11 @@ func (w *Workspace) EnvPath() string {
 	return filepath.Join(w.taskRoot, envFileName)
 }
 
+// ServeTokenPath returns the path the API token of `mehr serve` is written to.
+func (w *Workspace) ServeTokenPath() string {
+	return filepath.Join(w.taskRoot, serveTokenFile)
+}
+
 // LoadEnv reads the .env file and returns key-value pairs.
 // Returns empty map if file doesn't exist.
 func (w *Workspace) LoadEnv() (map[string]string, error) {
@@ -163,7 +204,7 @@ func (w *Workspace) UpdateGitignore() error {
 	}
 
 	// Load config to get work directory setting
-	workDirEntry := ".mehrhof/work/" // default
+	workDirEntry := w.taskDir + "/" + workSubDirName + "/" // default
 	cfg, err := w.LoadConfig()
 	if err == nil && cfg.Storage.WorkDir != "" {
 		workDirEntry = cfg.Storage.WorkDir
@@ -176,8 +217,13 @@ func (w *Workspace) UpdateGitignore() error {
 	// Define entries to add
 	entries := []string{
 		workDirEntry,
-		taskDirName + "/" + envFileName,
+		w.taskDir + "/" + envFileName,
+		w.taskDir + "/" + indexFileName,
+		w.taskDir + "/" + configFileName + backupSuffix,
+		w.taskDir + "/" + localConfigFile,
+		w.taskDir + "/" + serveTokenFile,
 		activeTaskFile,
+		activeTaskFile + backupSuffix,
 	}
 
 	modified := false
@@ -38,6 +38,13 @@ func (w *Workspace) LoadActiveTask() (*ActiveTask, error) {
 
 // SaveActiveTask saves the active task reference using atomic write pattern.
 func (w *Workspace) SaveActiveTask(active *ActiveTask) error {
+	return w.withActiveTaskLock(func() error {
+		return w.saveActiveTask(active)
+	})
+}
+
+// saveActiveTask writes .active_task. Callers must hold the active task lock.
+func (w *Workspace) saveActiveTask(active *ActiveTask) error {
 	data, err := yaml.Marshal(active)
 	if err != nil {
 		return fmt.Errorf("marshal active task: %w", err)
@@ -45,6 +52,7 @@ func (w *Workspace) SaveActiveTask(active *ActiveTask) error {
 
 	// Use atomic write pattern: write to temp file, then rename
 	path := w.ActiveTaskPath()
+	keepBackup(path)
 	tmpPath := path + ".tmp"
 	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
 		return fmt.Errorf("write active task: %w", err)
@@ -64,21 +72,31 @@ func (w *Workspace) SaveActiveTask(active *ActiveTask) error {
 
 // ClearActiveTask removes the active task file.
 func (w *Workspace) ClearActiveTask() error {
-	err := os.Remove(w.ActiveTaskPath())
-	if os.IsNotExist(err) {
-		return nil
-	}
+	return w.withActiveTaskLock(func() error {
+		err := os.Remove(w.ActiveTaskPath())
+		if os.IsNotExist(err) {
+			return nil
+		}
 
-	return err
+		return err
+	})
 }
 
 // UpdateActiveTaskState updates just the state field.
 func (w *Workspace) UpdateActiveTaskState(state string) error {
-	active, err := w.LoadActiveTask()
-	if err != nil {
-		return err
-	}
-	active.State = state
+	return w.withActiveTaskLock(func() error {
+		active, err := w.LoadActiveTask()
+		if err != nil {
+			return err
+		}
+		active.State = state
+
+		return w.saveActiveTask(active)
+	})
+}
 
-	return w.SaveActiveTask(active)
+// withActiveTaskLock runs fn holding the lock on .active_task, so a daemon
+// and a CLI process never interleave their writes.
+func (w *Workspace) withActiveTaskLock(fn func() error) error {
+	return WithLockTimeout(w.activeTaskLockPath(), DefaultLockTimeout, fn)
 }
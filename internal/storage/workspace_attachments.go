@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// attachmentsDirName holds files downloaded for a task, such as images its
+// source references.
+const attachmentsDirName = "attachments"
+
+// SaveAttachment stores a downloaded file in the task's attachments/
+// directory and returns its slash-separated path relative to the work
+// directory.
+func (w *Workspace) SaveAttachment(taskID, name string, data []byte) (string, error) {
+	dir := filepath.Join(w.WorkPath(taskID), attachmentsDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create attachments directory: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, name), data); err != nil {
+		return "", fmt.Errorf("write attachment %s: %w", name, err)
+	}
+
+	return attachmentsDirName + "/" + name, nil
+}
+
+// FindAttachment returns the path, relative to the work directory, of a
+// stored attachment named stem plus any extension, or "" if there is none.
+func (w *Workspace) FindAttachment(taskID, stem string) string {
+	matches, _ := filepath.Glob(filepath.Join(w.WorkPath(taskID), attachmentsDirName, filepath.Base(stem)+".*"))
+	if len(matches) == 0 {
+		return ""
+	}
+
+	return attachmentsDirName + "/" + filepath.Base(matches[0])
+}
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveAttachment(t *testing.T) {
+	ws, _ := OpenWorkspace(t.TempDir(), nil)
+
+	if got := ws.FindAttachment("task-1", "img-abc"); got != "" {
+		t.Errorf("FindAttachment() before save = %q, want empty", got)
+	}
+
+	rel, err := ws.SaveAttachment("task-1", "img-abc.png", []byte("png"))
+	if err != nil {
+		t.Fatalf("SaveAttachment: %v", err)
+	}
+	if rel != "attachments/img-abc.png" {
+		t.Errorf("SaveAttachment() = %q", rel)
+	}
+	data, err := os.ReadFile(filepath.Join(ws.WorkPath("task-1"), filepath.FromSlash(rel)))
+	if err != nil || string(data) != "png" {
+		t.Errorf("stored attachment = %q, %v", data, err)
+	}
+
+	if got := ws.FindAttachment("task-1", "img-abc"); got != rel {
+		t.Errorf("FindAttachment() = %q, want %q", got, rel)
+	}
+}
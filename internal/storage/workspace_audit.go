@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// auditFileName is the task's append-only audit log.
+const auditFileName = "audit.log"
+
+// maxAuditTarget bounds the target of an audit entry, such as the arguments
+// of a git commit with a long message.
+const maxAuditTarget = 512
+
+// Kinds of audited operations.
+const (
+	AuditFileWrite  = "file_write"
+	AuditFileDelete = "file_delete"
+	AuditGit        = "git"
+	AuditProvider   = "provider"
+)
+
+// ErrAuditTampered is returned when the audit log's hash chain is broken.
+var ErrAuditTampered = errors.New("audit log has been modified")
+
+// AuditEntry is one operation performed during a task. Entries are chained:
+// each records the hash of the one before it, and its own hash covers that,
+// so changing, inserting or removing an entry breaks the chain from there on.
+type AuditEntry struct {
+	Seq    int       `json:"seq"` // 1-based position in the log
+	Time   time.Time `json:"time"`
+	Kind   string    `json:"kind"`             // One of the Audit* kinds
+	Target string    `json:"target"`           // File path, git arguments or provider call
+	Detail string    `json:"detail,omitempty"` // e.g., content hash and size of a written file
+	Error  string    `json:"error,omitempty"`  // Set when the operation failed
+	Prev   string    `json:"prev"`             // Hash of the previous entry ("" for the first)
+	Hash   string    `json:"hash"`
+}
+
+// computeHash returns the entry's hash over all its other fields.
+func (e AuditEntry) computeHash() string {
+	e.Hash = ""
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditLogPath returns the path to the task's audit log.
+func (w *Workspace) AuditLogPath(taskID string) string {
+	return filepath.Join(w.WorkPath(taskID), auditFileName)
+}
+
+// AppendAudit appends an entry to the task's audit log, chaining it to the
+// last entry. Seq, Prev and Hash are set here; Time when it is zero.
+// Appends from other processes wait on a lock so the chain stays linear. The
+// task's work directory must exist.
+func (w *Workspace) AppendAudit(taskID string, entry AuditEntry) error {
+	lock := NewFileLock(filepath.Join(w.LocksDir(), "audit-"+taskID+".lock"))
+	if err := lock.LockWithTimeout(DefaultLockTimeout); err != nil {
+		return fmt.Errorf("lock audit log: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	f, err := os.OpenFile(w.AuditLogPath(taskID), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	last, err := lastAuditEntry(f)
+	if err != nil {
+		return err
+	}
+	if last != nil {
+		entry.Seq, entry.Prev = last.Seq+1, last.Hash
+	} else {
+		entry.Seq, entry.Prev = 1, ""
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	entry.Time = entry.Time.UTC()
+	if len(entry.Target) > maxAuditTarget {
+		entry.Target = entry.Target[:maxAuditTarget] + "..."
+	}
+	entry.Hash = entry.computeHash()
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode audit entry: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write audit log: %w", err)
+	}
+
+	return nil
+}
+
+// lastAuditEntry returns the last entry of an audit log, or nil when it is
+// empty. Only the end of the file is read.
+func lastAuditEntry(f *os.File) (*AuditEntry, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat audit log: %w", err)
+	}
+	if info.Size() == 0 {
+		return nil, nil
+	}
+
+	// An entry is bounded by maxAuditTarget plus small fields
+	offset := max(0, info.Size()-16<<10)
+	buf := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(buf, offset); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("read audit log: %w", err)
+	}
+	buf = bytes.TrimRight(buf, "\n")
+	if i := bytes.LastIndexByte(buf, '\n'); i >= 0 {
+		buf = buf[i+1:]
+	}
+
+	var entry AuditEntry
+	if err := json.Unmarshal(buf, &entry); err != nil {
+		return nil, fmt.Errorf("%w: last entry is unreadable", ErrAuditTampered)
+	}
+
+	return &entry, nil
+}
+
+// ReadAuditLog returns the entries of the task's audit log, oldest first.
+// A task without an audit log has no entries. A line that cannot be decoded
+// is returned as an error wrapping ErrAuditTampered.
+func (w *Workspace) ReadAuditLog(taskID string) ([]AuditEntry, error) {
+	f, err := os.Open(w.AuditLogPath(taskID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxEventLineBytes)
+	for n := 1; scanner.Scan(); n++ {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return entries, fmt.Errorf("%w: line %d is unreadable", ErrAuditTampered, n)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("read audit log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// VerifyAuditChain checks that entries form an unbroken hash chain, and
+// returns an error wrapping ErrAuditTampered at the first entry that does not.
+func VerifyAuditChain(entries []AuditEntry) error {
+	prev := ""
+	for i, e := range entries {
+		switch {
+		case e.Seq != i+1:
+			return fmt.Errorf("%w: entry %d has sequence number %d", ErrAuditTampered, i+1, e.Seq)
+		case e.Prev != prev:
+			return fmt.Errorf("%w: entry %d does not follow entry %d", ErrAuditTampered, e.Seq, i)
+		case e.Hash != e.computeHash():
+			return fmt.Errorf("%w: entry %d does not match its hash", ErrAuditTampered, e.Seq)
+		}
+		prev = e.Hash
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	ws, err := OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := os.MkdirAll(ws.WorkPath("task"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if entries, err := ws.ReadAuditLog("task"); err != nil || entries != nil {
+		t.Fatalf("ReadAuditLog(no log) = %v, %v", entries, err)
+	}
+
+	for _, e := range []AuditEntry{
+		{Kind: AuditFileWrite, Target: "main.go", Detail: "sha256:abc, 12 bytes"},
+		{Kind: AuditGit, Target: "git commit -m " + strings.Repeat("x", 2*maxAuditTarget)},
+		{Kind: AuditProvider, Target: "github: create pull request", Error: "rate limited"},
+	} {
+		if err := ws.AppendAudit("task", e); err != nil {
+			t.Fatalf("AppendAudit: %v", err)
+		}
+	}
+
+	entries, err := ws.ReadAuditLog("task")
+	if err != nil {
+		t.Fatalf("ReadAuditLog: %v", err)
+	}
+	if len(entries) != 3 || entries[0].Prev != "" || entries[1].Prev != entries[0].Hash || entries[2].Seq != 3 {
+		t.Fatalf("entries = %+v, want a chain of 3", entries)
+	}
+	if len(entries[1].Target) != maxAuditTarget+3 {
+		t.Errorf("long target kept %d bytes", len(entries[1].Target))
+	}
+	if err := VerifyAuditChain(entries); err != nil {
+		t.Errorf("VerifyAuditChain() = %v", err)
+	}
+}
+
+func TestVerifyAuditChain_Tampered(t *testing.T) {
+	ws, err := OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := os.MkdirAll(ws.WorkPath("task"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"a.go", "b.go", "c.go"} {
+		if err := ws.AppendAudit("task", AuditEntry{Kind: AuditFileWrite, Target: path}); err != nil {
+			t.Fatalf("AppendAudit: %v", err)
+		}
+	}
+	entries, err := ws.ReadAuditLog("task")
+	if err != nil {
+		t.Fatalf("ReadAuditLog: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func([]AuditEntry) []AuditEntry
+		want   string
+	}{
+		{name: "edited", tamper: func(e []AuditEntry) []AuditEntry { e[1].Target = "other.go"; return e }, want: "entry 2 does not match its hash"},
+		{name: "removed", tamper: func(e []AuditEntry) []AuditEntry { return append(e[:1], e[2:]...) }, want: "entry 2 has sequence number 3"},
+		{name: "rehashed", tamper: func(e []AuditEntry) []AuditEntry {
+			e[1].Target = "other.go"
+			e[1].Hash = e[1].computeHash()
+			return e
+		}, want: "entry 3 does not follow entry 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyAuditChain(tt.tamper(append([]AuditEntry(nil), entries...)))
+			if !errors.Is(err, ErrAuditTampered) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("VerifyAuditChain() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	// A corrupted line is reported when reading
+	data, err := os.ReadFile(ws.AuditLogPath("task"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ws.AuditLogPath("task"), append(data, "{not json\n"...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.ReadAuditLog("task"); !errors.Is(err, ErrAuditTampered) || !strings.Contains(err.Error(), "line 4") {
+		t.Errorf("ReadAuditLog(corrupted) = %v, want line 4 reported", err)
+	}
+}
@@ -0,0 +1,230 @@
+package storage
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Task bundle errors.
+var (
+	ErrNotTaskBundle     = errors.New("not a mehrhof task bundle")
+	ErrBundleVersion     = errors.New("unsupported task bundle version")
+	ErrTaskExists        = errors.New("task already exists")
+	ErrWorkSchemaVersion = errors.New("unsupported work.yaml schema version")
+)
+
+const (
+	// BundleVersion is the current task bundle format version.
+	BundleVersion = 1
+
+	bundleManifestName = "bundle.json"
+)
+
+// BundleManifest is the first entry of a task bundle.
+type BundleManifest struct {
+	Version       int       `json:"version"`
+	SchemaVersion string    `json:"schema_version"` // work.yaml version
+	CreatedAt     time.Time `json:"created_at"`
+	TaskID        string    `json:"task_id"`
+	Title         string    `json:"title,omitempty"`
+	Files         int       `json:"files"`
+}
+
+// ExportBundle writes a task's work directory (work.yaml, source, notes,
+// specifications, sessions, pending question, usage and events) to out as a
+// gzip-compressed tar stream, so the task can be moved to another machine
+// or attached to a ticket. Entries are relative to the work directory and
+// preceded by bundle.json.
+func (w *Workspace) ExportBundle(taskID string, out io.Writer) (*BundleManifest, error) {
+	work, err := w.LoadWork(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	root := w.WorkPath(taskID)
+	files, err := w.workFiles(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := work.Version
+	if schema == "" {
+		schema = WorkSchemaVersion
+	}
+	manifest := &BundleManifest{
+		Version:       BundleVersion,
+		SchemaVersion: schema,
+		CreatedAt:     time.Now().UTC(),
+		TaskID:        taskID,
+		Title:         work.Metadata.Title,
+		Files:         len(files),
+	}
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal bundle manifest: %w", err)
+	}
+	if err := writeBundleEntry(tw, bundleManifestName, data, 0o644, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	for _, rel := range files {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("stat %s: %w", rel, err)
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", rel, err)
+		}
+		if err := writeBundleEntry(tw, rel, data, info.Mode().Perm(), info.ModTime()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("close bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("compress bundle: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// ImportBundle reads a bundle written by ExportBundle and creates the task in
+// this workspace. Bundles from a newer mehrhof, or whose work.yaml uses a
+// schema this build does not know, are rejected. An existing task with the
+// same ID is never overwritten. The task is only visible once every entry
+// has been extracted.
+func (w *Workspace) ImportBundle(r io.Reader) (*TaskWork, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotTaskBundle, err)
+	}
+	tr := tar.NewReader(gz)
+
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != bundleManifestName {
+		return nil, fmt.Errorf("%w: missing %s", ErrNotTaskBundle, bundleManifestName)
+	}
+	var manifest BundleManifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: invalid manifest: %w", ErrNotTaskBundle, err)
+	}
+	if manifest.Version < 1 || manifest.Version > BundleVersion {
+		return nil, fmt.Errorf("%w %d (this mehrhof reads version %d)", ErrBundleVersion, manifest.Version, BundleVersion)
+	}
+	if !canUpgradeWork(manifest.SchemaVersion) {
+		return nil, fmt.Errorf("%w %q (this mehrhof reads %q)", ErrWorkSchemaVersion, manifest.SchemaVersion, WorkSchemaVersion)
+	}
+	if manifest.TaskID == "" || manifest.TaskID != filepath.Base(manifest.TaskID) || strings.HasPrefix(manifest.TaskID, ".") {
+		return nil, fmt.Errorf("%w: invalid task ID %q", ErrNotTaskBundle, manifest.TaskID)
+	}
+	if w.WorkExists(manifest.TaskID) {
+		return nil, fmt.Errorf("%w: %s", ErrTaskExists, manifest.TaskID)
+	}
+
+	if err := os.MkdirAll(w.workRoot, 0o755); err != nil {
+		return nil, fmt.Errorf("create work directory: %w", err)
+	}
+	// Extract next to the final directory, hidden from ListWorks, then rename
+	staging, err := os.MkdirTemp(w.workRoot, ".import-"+manifest.TaskID+"-")
+	if err != nil {
+		return nil, fmt.Errorf("create staging directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+
+	if err := extractBundle(tr, staging); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(staging, workFileName))
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing %s", ErrNotTaskBundle, workFileName)
+	}
+	var work TaskWork
+	if err := yaml.Unmarshal(data, &work); err != nil {
+		return nil, fmt.Errorf("parse work file: %w", err)
+	}
+	if !canUpgradeWork(work.Version) {
+		return nil, fmt.Errorf("%w %q (this mehrhof reads %q)", ErrWorkSchemaVersion, work.Version, WorkSchemaVersion)
+	}
+	if work.Metadata.ID != manifest.TaskID {
+		return nil, fmt.Errorf("%w: work.yaml is for task %q, manifest for %q", ErrNotTaskBundle, work.Metadata.ID, manifest.TaskID)
+	}
+
+	if err := os.Rename(staging, w.WorkPath(manifest.TaskID)); err != nil {
+		if w.WorkExists(manifest.TaskID) {
+			return nil, fmt.Errorf("%w: %s", ErrTaskExists, manifest.TaskID)
+		}
+
+		return nil, fmt.Errorf("move imported task into place: %w", err)
+	}
+
+	return &work, nil
+}
+
+// extractBundle writes the remaining regular file entries of a bundle below dir.
+func extractBundle(tr *tar.Reader, dir string) error {
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrNotTaskBundle, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(hdr.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("%w: entry escapes the task directory: %s", ErrNotTaskBundle, hdr.Name)
+		}
+
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return fmt.Errorf("create directory for %s: %w", name, err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("read bundle entry %s: %w", name, err)
+		}
+		if err := os.WriteFile(p, data, os.FileMode(hdr.Mode).Perm()); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+}
+
+func writeBundleEntry(tw *tar.Writer, name string, data []byte, mode os.FileMode, modTime time.Time) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    int64(mode),
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("write bundle header %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("write bundle entry %s: %w", name, err)
+	}
+
+	return nil
+}
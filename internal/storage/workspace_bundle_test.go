@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newBundleWorkspace(t *testing.T) *Workspace {
+	t.Helper()
+
+	ws, _ := OpenWorkspace(t.TempDir(), nil)
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+
+	return ws
+}
+
+func TestExportImportBundle(t *testing.T) {
+	src := newBundleWorkspace(t)
+	work, err := src.CreateWork("task1", SourceInfo{Type: "file", Ref: "task.md"})
+	if err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	work.Metadata.Title = "Cache lookups"
+	if err := src.SaveWork(work); err != nil {
+		t.Fatalf("SaveWork: %v", err)
+	}
+	if err := src.AppendNote("task1", "Use an LRU", "planning"); err != nil {
+		t.Fatalf("AppendNote: %v", err)
+	}
+	if err := src.SaveSpecification("task1", 1, "# Add a cache\n"); err != nil {
+		t.Fatalf("SaveSpecification: %v", err)
+	}
+	if _, _, err := src.CreateSession("task1", "planning", "claude", "planning"); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if err := src.SavePendingQuestion("task1", &PendingQuestion{Question: "Which eviction policy?", AskedAt: time.Now()}); err != nil {
+		t.Fatalf("SavePendingQuestion: %v", err)
+	}
+
+	var buf bytes.Buffer
+	manifest, err := src.ExportBundle("task1", &buf)
+	if err != nil {
+		t.Fatalf("ExportBundle: %v", err)
+	}
+	if manifest.TaskID != "task1" || manifest.SchemaVersion != WorkSchemaVersion || manifest.Title != "Cache lookups" {
+		t.Errorf("manifest = %+v", manifest)
+	}
+	data := buf.Bytes()
+
+	dst := newBundleWorkspace(t)
+	imported, err := dst.ImportBundle(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ImportBundle: %v", err)
+	}
+	if imported.Metadata.Title != "Cache lookups" {
+		t.Errorf("imported title = %q", imported.Metadata.Title)
+	}
+	if notes, _ := dst.ReadNotes("task1"); !strings.Contains(notes, "Use an LRU") {
+		t.Errorf("notes not imported:\n%s", notes)
+	}
+	if spec, err := dst.LoadSpecification("task1", 1); err != nil || !strings.Contains(spec, "Add a cache") {
+		t.Errorf("specification not imported: %v", err)
+	}
+	if files, _ := dst.ListSessionFiles("task1"); len(files) != 1 {
+		t.Errorf("sessions = %v, want 1", files)
+	}
+	if q, err := dst.LoadPendingQuestion("task1"); err != nil || q.Question != "Which eviction policy?" {
+		t.Errorf("pending question not imported: %v", err)
+	}
+	if tasks, _ := dst.ListWorks(); len(tasks) != 1 {
+		t.Errorf("ListWorks = %v, want only task1", tasks)
+	}
+
+	if _, err := dst.ImportBundle(bytes.NewReader(data)); !errors.Is(err, ErrTaskExists) {
+		t.Errorf("second ImportBundle error = %v, want ErrTaskExists", err)
+	}
+}
+
+// bundleWith builds a bundle from a manifest and entries.
+func bundleWith(t *testing.T, manifest string, entries map[string]string) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := writeBundleEntry(tw, bundleManifestName, []byte(manifest), 0o644, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range entries {
+		if err := writeBundleEntry(tw, name, []byte(content), 0o644, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &buf
+}
+
+func TestImportBundle_Rejects(t *testing.T) {
+	work := "version: \"1\"\nmetadata:\n  id: task1\n"
+
+	tests := []struct {
+		name     string
+		bundle   func(t *testing.T) *bytes.Buffer
+		wantErr  error
+		contains string
+	}{
+		{
+			name:    "not gzip",
+			bundle:  func(*testing.T) *bytes.Buffer { return bytes.NewBufferString("plain text") },
+			wantErr: ErrNotTaskBundle,
+		},
+		{
+			name: "newer bundle format",
+			bundle: func(t *testing.T) *bytes.Buffer {
+				return bundleWith(t, `{"version": 2, "schema_version": "1", "task_id": "task1"}`, map[string]string{"work.yaml": work})
+			},
+			wantErr: ErrBundleVersion,
+		},
+		{
+			name: "unknown work schema",
+			bundle: func(t *testing.T) *bytes.Buffer {
+				return bundleWith(t, `{"version": 1, "schema_version": "2", "task_id": "task1"}`, map[string]string{"work.yaml": work})
+			},
+			wantErr: ErrWorkSchemaVersion,
+		},
+		{
+			name: "work.yaml schema differs from manifest",
+			bundle: func(t *testing.T) *bytes.Buffer {
+				return bundleWith(t, `{"version": 1, "schema_version": "1", "task_id": "task1"}`,
+					map[string]string{"work.yaml": "version: \"2\"\nmetadata:\n  id: task1\n"})
+			},
+			wantErr: ErrWorkSchemaVersion,
+		},
+		{
+			name: "path traversal",
+			bundle: func(t *testing.T) *bytes.Buffer {
+				return bundleWith(t, `{"version": 1, "schema_version": "1", "task_id": "task1"}`,
+					map[string]string{"work.yaml": work, "../../config.yaml": "agent: evil\n"})
+			},
+			wantErr:  ErrNotTaskBundle,
+			contains: "escapes",
+		},
+		{
+			name: "task ID is a path",
+			bundle: func(t *testing.T) *bytes.Buffer {
+				return bundleWith(t, `{"version": 1, "schema_version": "1", "task_id": "../task1"}`, map[string]string{"work.yaml": work})
+			},
+			wantErr: ErrNotTaskBundle,
+		},
+		{
+			name: "missing work.yaml",
+			bundle: func(t *testing.T) *bytes.Buffer {
+				return bundleWith(t, `{"version": 1, "schema_version": "1", "task_id": "task1"}`, map[string]string{"notes.md": "# Notes\n"})
+			},
+			wantErr: ErrNotTaskBundle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := newBundleWorkspace(t)
+
+			_, err := ws.ImportBundle(tt.bundle(t))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ImportBundle error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.contains != "" && !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("error %q does not mention %q", err, tt.contains)
+			}
+			if tasks, _ := ws.ListWorks(); len(tasks) != 0 {
+				t.Errorf("rejected bundle left tasks behind: %v", tasks)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// ChildProgress is the aggregate completion of the tasks split from a task.
+type ChildProgress struct {
+	Total int
+	Done  int
+}
+
+// Complete reports whether the task has children and all are finished.
+func (p ChildProgress) Complete() bool {
+	return p.Total > 0 && p.Done == p.Total
+}
+
+// ListChildren returns the tasks split from parentID, in creation order.
+func (w *Workspace) ListChildren(parentID string) ([]*TaskWork, error) {
+	works, err := w.LoadWorks()
+	if err != nil {
+		return nil, err
+	}
+
+	var children []*TaskWork
+	for _, work := range works {
+		if work.Metadata.ParentID == parentID {
+			children = append(children, work)
+		}
+	}
+	slices.SortFunc(children, func(a, b *TaskWork) int {
+		return cmp.Or(a.Metadata.CreatedAt.Compare(b.Metadata.CreatedAt), strings.Compare(a.Metadata.ID, b.Metadata.ID))
+	})
+
+	return children, nil
+}
+
+// ChildProgress counts the tasks split from parentID and how many of them
+// are finished. Children whose work directory was deleted on finish are not
+// counted.
+func (w *Workspace) ChildProgress(parentID string) (ChildProgress, error) {
+	children, err := w.ListChildren(parentID)
+	if err != nil {
+		return ChildProgress{}, err
+	}
+
+	progress := ChildProgress{Total: len(children)}
+	for _, child := range children {
+		if !child.Metadata.FinishedAt.IsZero() {
+			progress.Done++
+		}
+	}
+
+	return progress, nil
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChildProgress(t *testing.T) {
+	ws, err := OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	create := func(id, parent string, finished bool) {
+		t.Helper()
+		work, err := ws.CreateWork(id, SourceInfo{Type: "file", Ref: id + ".md"})
+		if err != nil {
+			t.Fatalf("CreateWork(%s): %v", id, err)
+		}
+		work.Metadata.ParentID = parent
+		if finished {
+			work.Metadata.FinishedAt = time.Now()
+		}
+		if err := ws.SaveWork(work); err != nil {
+			t.Fatalf("SaveWork(%s): %v", id, err)
+		}
+	}
+	create("parent", "", false)
+	create("child-b", "parent", true)
+	create("child-a", "parent", false)
+	create("other", "", false)
+
+	children, err := ws.ListChildren("parent")
+	if err != nil {
+		t.Fatalf("ListChildren: %v", err)
+	}
+	if len(children) != 2 || children[0].Metadata.ID != "child-b" || children[1].Metadata.ID != "child-a" {
+		t.Errorf("children = %v, want child-b, child-a in creation order", children)
+	}
+
+	progress, err := ws.ChildProgress("parent")
+	if err != nil {
+		t.Fatalf("ChildProgress: %v", err)
+	}
+	if progress != (ChildProgress{Total: 2, Done: 1}) || progress.Complete() {
+		t.Errorf("progress = %+v", progress)
+	}
+
+	if progress, _ := ws.ChildProgress("other"); progress.Total != 0 || progress.Complete() {
+		t.Errorf("progress without children = %+v", progress)
+	}
+}
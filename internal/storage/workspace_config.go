@@ -3,9 +3,12 @@ package storage
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/valksor/go-mehrhof/internal/config"
 )
 
 // WorkspaceConfig holds workspace-specific configuration that users can customize.
@@ -26,6 +29,34 @@ type WorkspaceConfig struct {
 	Plugins   PluginsConfig               `yaml:"plugins,omitempty"`
 	Update    UpdateSettings              `yaml:"update,omitempty"`
 	Storage   StorageSettings             `yaml:"storage,omitempty"`
+
+	// Notifications alert the user when an agent is waiting on them
+	Notifications NotificationSettings `yaml:"notifications,omitempty"`
+
+	// Guardrails block checkpoints that would commit secrets or large blobs
+	Guardrails GuardrailSettings `yaml:"guardrails,omitempty"`
+
+	// Telemetry exports OpenTelemetry traces and metrics over OTLP
+	Telemetry TelemetrySettings `yaml:"telemetry,omitempty"`
+
+	// Context chooses the repository convention files included in agent prompts
+	Context ContextSettings `yaml:"context,omitempty"`
+
+	// Workspaces are secondary repositories that tasks can attach, keyed by name
+	Workspaces map[string]RepositoryWorkspace `yaml:"workspaces,omitempty"`
+
+	// UI configures how changes are shown to the user
+	UI UISettings `yaml:"ui,omitempty"`
+}
+
+// RepositoryWorkspace configures a secondary repository a task can attach
+// (e.g., a frontend repo next to the backend repo holding .mehrhof).
+type RepositoryWorkspace struct {
+	Path          string         `yaml:"path"`                     // Repository path (relative to project root or absolute)
+	BranchPattern string         `yaml:"branch_pattern,omitempty"` // Default: git.branch_pattern
+	TargetBranch  string         `yaml:"target_branch,omitempty"`  // Default: detected base branch
+	PRProvider    string         `yaml:"pr_provider,omitempty"`    // Provider used to open PRs on finish (e.g., "github")
+	PRConfig      map[string]any `yaml:"pr_config,omitempty"`      // Provider config for PRs (e.g., owner, repo)
 }
 
 // PluginsConfig holds plugin-related configuration.
@@ -49,6 +80,14 @@ type GitHubSettings struct {
 	TargetBranch  string                  `yaml:"target_branch,omitempty"`  // Default: detected from repo
 	DraftPR       bool                    `yaml:"draft_pr,omitempty"`       // Create PRs as draft
 	Comments      *GitHubCommentsSettings `yaml:"comments,omitempty"`
+	App           *GitHubAppSettings      `yaml:"app,omitempty"` // Authenticate as a GitHub App instead of a token
+}
+
+// GitHubAppSettings holds GitHub App credentials used to mint installation tokens.
+type GitHubAppSettings struct {
+	AppID          int64  `yaml:"app_id"`                    // GitHub App ID
+	PrivateKeyPath string `yaml:"private_key_path"`          // Path to the app's PEM private key
+	InstallationID int64  `yaml:"installation_id,omitempty"` // Optional; detected from owner/repo
 }
 
 // GitHubCommentsSettings controls automated GitHub issue commenting.
@@ -69,11 +108,13 @@ type WrikeSettings struct {
 
 // GitLabSettings holds GitLab provider configuration.
 type GitLabSettings struct {
-	Token         string `yaml:"token,omitempty"`          // GitLab token (env vars take priority)
-	Host          string `yaml:"host,omitempty"`           // GitLab host (default: https://gitlab.com)
-	ProjectPath   string `yaml:"project_path,omitempty"`   // Default project path (e.g., group/project)
-	BranchPattern string `yaml:"branch_pattern,omitempty"` // Default: "issue/{key}-{slug}"
-	CommitPrefix  string `yaml:"commit_prefix,omitempty"`  // Default: "[#{key}]"
+	Token         string `yaml:"token,omitempty"`           // GitLab token (env vars take priority)
+	Host          string `yaml:"host,omitempty"`            // GitLab host (default: https://gitlab.com)
+	ProjectPath   string `yaml:"project_path,omitempty"`    // Default project path (e.g., group/project)
+	BranchPattern string `yaml:"branch_pattern,omitempty"`  // Default: "issue/{key}-{slug}"
+	CommitPrefix  string `yaml:"commit_prefix,omitempty"`   // Default: "[#{key}]"
+	OAuthClientID string `yaml:"oauth_client_id,omitempty"` // OAuth application ID for 'mehr auth gitlab'
+	LogTime       bool   `yaml:"log_time,omitempty"`        // Post time spent as a /spend quick action on finish
 }
 
 // NotionSettings holds Notion provider configuration.
@@ -83,6 +124,7 @@ type NotionSettings struct {
 	StatusProperty      string `yaml:"status_property,omitempty"`      // Property name for status (default: Status)
 	DescriptionProperty string `yaml:"description_property,omitempty"` // Property name for description
 	LabelsProperty      string `yaml:"labels_property,omitempty"`      // Property name for labels (default: Tags)
+	FinishStatus        string `yaml:"finish_status,omitempty"`        // Status set when a task finishes (default: Done, "none" disables)
 }
 
 // JiraSettings holds Jira provider configuration.
@@ -91,6 +133,7 @@ type JiraSettings struct {
 	Email   string `yaml:"email,omitempty"`    // Email for Cloud auth
 	BaseURL string `yaml:"base_url,omitempty"` // Base URL (optional, auto-detected)
 	Project string `yaml:"project,omitempty"`  // Default project key
+	LogTime bool   `yaml:"log_time,omitempty"` // Log time spent as a worklog on finish
 }
 
 // LinearSettings holds Linear provider configuration.
@@ -101,8 +144,10 @@ type LinearSettings struct {
 
 // YouTrackSettings holds YouTrack provider configuration.
 type YouTrackSettings struct {
-	Token string `yaml:"token,omitempty"` // YouTrack token (env vars take priority)
-	Host  string `yaml:"host,omitempty"`  // YouTrack host
+	Token   string            `yaml:"token,omitempty"`    // YouTrack token (env vars take priority)
+	Host    string            `yaml:"host,omitempty"`     // YouTrack host
+	States  map[string]string `yaml:"states,omitempty"`   // Mehrhof status -> YouTrack state name (e.g. done: Fixed)
+	LogTime bool              `yaml:"log_time,omitempty"` // Log time spent as a work item on finish
 }
 
 // AgentAliasConfig defines a user-defined agent alias that wraps an existing agent
@@ -114,12 +159,27 @@ type AgentAliasConfig struct {
 	Args        []string          `yaml:"args,omitempty"`        // CLI arguments to pass
 }
 
+// DefaultWorktreePattern names task worktrees after the task ID.
+const DefaultWorktreePattern = "{task_id}"
+
 // GitSettings holds git-related configuration.
 type GitSettings struct {
-	CommitPrefix  string `yaml:"commit_prefix"`
-	BranchPattern string `yaml:"branch_pattern"`
-	AutoCommit    bool   `yaml:"auto_commit"`
-	SignCommits   bool   `yaml:"sign_commits"`
+	CommitPrefix     string `yaml:"commit_prefix"`
+	BranchPattern    string `yaml:"branch_pattern"`
+	AutoCommit       bool   `yaml:"auto_commit"`
+	SignCommits      bool   `yaml:"sign_commits"`
+	SigningFormat    string `yaml:"signing_format,omitempty"`     // "openpgp", "ssh" or "x509" (default: git's gpg.format)
+	SigningKey       string `yaml:"signing_key,omitempty"`        // Key ID or SSH key file (default: git's user.signingkey)
+	WorktreeDir      string `yaml:"worktree_dir,omitempty"`       // Default: ../<repo>-worktrees
+	WorktreePattern  string `yaml:"worktree_pattern,omitempty"`   // Default: "{task_id}"
+	SyncStrategy     string `yaml:"sync_strategy,omitempty"`      // "rebase" (default) or "merge" for 'mehr sync'
+	SyncBeforeFinish bool   `yaml:"sync_before_finish,omitempty"` // Sync with the base branch before finishing
+	// Agent-written commit messages: "conventional" or "plain" (default: templated messages)
+	CommitMessageStyle string `yaml:"commit_message_style,omitempty"`
+	// Limit what automatic checkpoints commit (default: all changes)
+	CheckpointAgentFiles bool     `yaml:"checkpoint_agent_files,omitempty"` // Only files the agent changed
+	CheckpointInclude    []string `yaml:"checkpoint_include,omitempty"`     // Only paths matching these globs
+	CheckpointExclude    []string `yaml:"checkpoint_exclude,omitempty"`     // Never paths matching these globs
 }
 
 // StepAgentConfig holds agent configuration for a specific workflow step.
@@ -135,16 +195,126 @@ type AgentSettings struct {
 	Timeout    int                        `yaml:"timeout"`
 	MaxRetries int                        `yaml:"max_retries"`
 	Steps      map[string]StepAgentConfig `yaml:"steps,omitempty"` // Per-step agent configuration
+
+	// Context sent to agents: full, summary or minimal (default: full)
+	Context string `yaml:"context,omitempty"`
+	// Estimated prompt tokens above which older notes and sessions are summarized (default: 100000)
+	ContextTokens int `yaml:"context_tokens,omitempty"`
+	// Agent that summarizes older context, ideally a cheap one (default: the step's agent)
+	SummaryAgent string `yaml:"summary_agent,omitempty"`
+	// Agent that critiques draft specifications (default: the reviewing agent)
+	CriticAgent string `yaml:"critic_agent,omitempty"`
+
+	// Sandbox the implementing agent's CLI runs in: docker, bwrap or none (default: none)
+	Sandbox string `yaml:"sandbox,omitempty"`
+	// Image with the agent's CLI installed, required by the docker sandbox
+	SandboxImage string `yaml:"sandbox_image,omitempty"`
+	// Host environment variables passed into the sandbox besides the agent's own
+	SandboxEnv []string `yaml:"sandbox_env,omitempty"`
+	// Hosts the sandboxed agent may reach besides its API endpoint
+	SandboxHosts []string `yaml:"sandbox_hosts,omitempty"`
+	// Host paths the bwrap sandbox may read besides the system directories and the agent's installation
+	SandboxPaths []string `yaml:"sandbox_paths,omitempty"`
+
+	// Deadline in seconds per phase: planning, implementing or reviewing (default: none)
+	PhaseTimeouts map[string]int `yaml:"phase_timeouts,omitempty"`
+	// What happens when a phase deadline passes: fail, pause or retry (default: fail)
+	OnTimeout string `yaml:"on_timeout,omitempty"`
+
+	// Caching of planning and review responses, so identical prompts are not paid for twice
+	Cache AgentCacheSettings `yaml:"cache,omitempty"`
+}
+
+// AgentCacheSettings configures the on-disk cache of agent responses in
+// .mehrhof/cache/agent.
+type AgentCacheSettings struct {
+	// Answer identical planning and review prompts to the same agent from the cache (default: false)
+	Enabled bool `yaml:"enabled,omitempty"`
+	// Hours a cached response stays valid (default: 24)
+	TTLHours int `yaml:"ttl_hours,omitempty"`
 }
 
+// DefaultAgentCacheTTLHours is how long cached agent responses stay valid
+// when agent.cache.ttl_hours is not set.
+const DefaultAgentCacheTTLHours = 24
+
+// Values of agent.on_timeout.
+const (
+	OnTimeoutFail  = "fail"  // Stop the phase with an error
+	OnTimeoutPause = "pause" // Checkpoint the partial work and ask how to continue
+	OnTimeoutRetry = "retry" // Run the phase once more with minimal context
+)
+
+// OnTimeoutPolicies lists the valid agent.on_timeout values.
+var OnTimeoutPolicies = []string{OnTimeoutFail, OnTimeoutPause, OnTimeoutRetry}
+
+// TimeoutPhases lists the phases agent.phase_timeouts can bound.
+var TimeoutPhases = []string{"planning", "implementing", "reviewing"}
+
+// DefaultContextTokens is the estimated prompt size above which older notes
+// and sessions are summarized when agent.context_tokens is not set.
+const DefaultContextTokens = 100_000
+
 // WorkflowSettings holds workflow-related configuration.
 type WorkflowSettings struct {
 	AutoInit             bool `yaml:"auto_init"`
 	SessionRetentionDays int  `yaml:"session_retention_days"`
 	DeleteWorkOnFinish   bool `yaml:"delete_work_on_finish"`  // Delete work dirs on finish (default: false)
 	DeleteWorkOnAbandon  bool `yaml:"delete_work_on_abandon"` // Delete work dirs on abandon (default: true)
+
+	// Documentation phase run after implementation
+	DocumentAfterImplement bool     `yaml:"document_after_implement,omitempty"` // Update docs after each implementation (default: false)
+	DocPaths               []string `yaml:"doc_paths,omitempty"`                // Files the documentation phase may change (default: DefaultDocPaths)
+
+	// Failures of the same category needed before prompts warn about it (default: 2, -1 disables)
+	LessonThreshold int `yaml:"lesson_threshold,omitempty"`
+
+	// How headless runs answer agent questions: fail, default_option, ask_agent or skip (default: skip)
+	QuestionPolicy string `yaml:"question_policy,omitempty"`
+
+	// Changelog entry written on finish
+	Changelog ChangelogSettings `yaml:"changelog,omitempty"`
+
+	// Consensus review by several agents
+	Review ReviewSettings `yaml:"review,omitempty"`
+
+	// Critic pass over each planned specification before it is marked ready
+	SpecCritique       bool `yaml:"spec_critique,omitempty"`        // Critique and revise specifications (default: false)
+	SpecCritiqueRounds int  `yaml:"spec_critique_rounds,omitempty"` // Critique rounds at most (default: 2)
+}
+
+// DefaultSpecCritiqueRounds is the number of critique rounds a specification
+// gets at most.
+const DefaultSpecCritiqueRounds = 2
+
+// ReviewSettings configures consensus reviews, where several agents review
+// the changes independently and only findings enough of them agree on block
+// finishing.
+type ReviewSettings struct {
+	Agents       []string `yaml:"agents,omitempty"`        // Reviewing agents; two or more enable consensus review
+	MinAgreement int      `yaml:"min_agreement,omitempty"` // Agents that must report a finding for it to block (default: 2)
 }
 
+// DefaultReviewMinAgreement is the number of agents that must report a
+// finding before it blocks finishing.
+const DefaultReviewMinAgreement = 2
+
+// ChangelogSettings configures the changelog entry generated on finish.
+type ChangelogSettings struct {
+	Enabled bool   `yaml:"enabled,omitempty"` // Write an entry on finish (default: false)
+	Format  string `yaml:"format,omitempty"`  // "keepachangelog" (default) or "towncrier"
+	Path    string `yaml:"path,omitempty"`    // Default: CHANGELOG.md, or changelog.d/ for towncrier
+}
+
+// DefaultLessonThreshold is the number of failures in one category after
+// which later prompts include it as a lesson.
+const DefaultLessonThreshold = 2
+
+// DefaultDocPaths are the files the documentation phase may change when
+// workflow.doc_paths is not set. A trailing slash matches a directory, other
+// patterns without a slash match file names.
+var DefaultDocPaths = []string{"docs/", "doc/", "README*", "*.md", "*.mdx", "*.rst", "*.adoc"}
+
 // UpdateSettings holds update-related configuration.
 type UpdateSettings struct {
 	Enabled       bool `yaml:"enabled"`        // Enable automatic update checks
@@ -153,7 +323,123 @@ type UpdateSettings struct {
 
 // StorageSettings holds storage-related configuration.
 type StorageSettings struct {
-	WorkDir string `yaml:"work_dir,omitempty"` // Path to work directory (relative to project root)
+	WorkDir string                 `yaml:"work_dir,omitempty"` // Path to work directory (relative to project root)
+	Remote  *RemoteStorageSettings `yaml:"remote,omitempty"`   // Shared storage task work directories are pushed to
+}
+
+// RemoteStorageSettings configures shared storage for task work directories,
+// so tasks can be handed between developers and survive ephemeral machines.
+// Keys may reference environment variables (${VAR}).
+type RemoteStorageSettings struct {
+	Type      string `yaml:"type"`                 // local, s3 or gcs
+	Path      string `yaml:"path,omitempty"`       // Directory for local (e.g. a network mount), relative to project root
+	Bucket    string `yaml:"bucket,omitempty"`     // Bucket for s3 and gcs
+	Prefix    string `yaml:"prefix,omitempty"`     // Key prefix inside the bucket, e.g. "mehrhof/myproject"
+	Region    string `yaml:"region,omitempty"`     // Bucket region (s3 default: $AWS_REGION, else us-east-1)
+	Endpoint  string `yaml:"endpoint,omitempty"`   // S3-compatible endpoint URL (MinIO, R2); gcs uses storage.googleapis.com
+	AccessKey string `yaml:"access_key,omitempty"` // Default: $AWS_ACCESS_KEY_ID (gcs: an HMAC key)
+	SecretKey string `yaml:"secret_key,omitempty"` // Default: $AWS_SECRET_ACCESS_KEY
+	AutoPush  bool   `yaml:"auto_push,omitempty"`  // Push the task after every workflow step
+}
+
+// NotificationSettings configures where pending question alerts are sent.
+// Webhook URLs and headers may reference environment variables (${VAR}).
+type NotificationSettings struct {
+	Desktop  bool                  `yaml:"desktop,omitempty"`  // notify-send (Linux) or osascript (macOS)
+	Slack    *SlackNotification    `yaml:"slack,omitempty"`    // Slack incoming webhook
+	Webhooks []WebhookNotification `yaml:"webhooks,omitempty"` // Generic HTTP webhooks receiving JSON
+}
+
+// SlackNotification holds a Slack incoming webhook. Pending questions are
+// always posted; task lifecycle messages are enabled per event.
+type SlackNotification struct {
+	WebhookURL string              `yaml:"webhook_url"`
+	Channel    string              `yaml:"channel,omitempty"` // Override the webhook's default channel
+	Events     *SlackEventSettings `yaml:"events,omitempty"`
+}
+
+// SlackEventSettings controls which task lifecycle events are posted to Slack.
+type SlackEventSettings struct {
+	OnTaskStarted bool `yaml:"on_task_started"` // Post when a task is started
+	OnPlanDone    bool `yaml:"on_plan_done"`    // Post the new specification's summary
+	OnPRCreated   bool `yaml:"on_pr_created"`   // Post the PR link
+	OnFinished    bool `yaml:"on_finished"`     // Post when the task is finished
+}
+
+// WebhookNotification holds a generic HTTP webhook endpoint.
+type WebhookNotification struct {
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+}
+
+// TelemetrySettings configures the OTLP/HTTP export of traces and metrics.
+// Header values may reference environment variables (${VAR}).
+type TelemetrySettings struct {
+	Enabled     bool              `yaml:"enabled,omitempty"`
+	Endpoint    string            `yaml:"endpoint,omitempty"`     // Collector base URL (default: http://localhost:4318)
+	Headers     map[string]string `yaml:"headers,omitempty"`      // Sent with every export (e.g., authentication)
+	ServiceName string            `yaml:"service_name,omitempty"` // service.name resource attribute (default: mehrhof)
+}
+
+// GuardrailSettings configures the scan of agent changes before checkpoints.
+type GuardrailSettings struct {
+	Disabled        bool     `yaml:"disabled,omitempty"`           // Skip the scan entirely
+	MaxFileSizeKB   int64    `yaml:"max_file_size_kb,omitempty"`   // Block files larger than this (default: 5120)
+	MaxBinarySizeKB int64    `yaml:"max_binary_size_kb,omitempty"` // Block binary files larger than this (default: 100)
+	Ignore          []string `yaml:"ignore,omitempty"`             // Path globs that are never scanned (e.g., "testdata/**")
+	DisableEntropy  bool     `yaml:"disable_entropy,omitempty"`    // Only detect credentials with a known format, not high-entropy strings
+
+	// Policy for files written from agent output
+	Deny      []string `yaml:"deny,omitempty"`      // Path globs agents may never change (e.g., "deploy/**", "*.pem")
+	Allow     []string `yaml:"allow,omitempty"`     // Exceptions to deny and protected
+	Protected []string `yaml:"protected,omitempty"` // Path globs agents change only with --allow-protected (e.g., "go.sum")
+	Secrets   string   `yaml:"secrets,omitempty"`   // Secrets in written files: reject or redact (default: reject)
+}
+
+// ContextSettings chooses the repository files, such as contribution guides
+// and lint configs, that are included in agent prompts.
+type ContextSettings struct {
+	// Globs relative to the repository root, for every step (default: DefaultConventionGlobs, plus DefaultStyleGuideGlobs for implementing and reviewing)
+	IncludeGlobs []string `yaml:"include_globs,omitempty"`
+	// Per-step globs replacing include_globs, keyed by step name; an empty list includes nothing
+	Steps map[string]ContextStepSettings `yaml:"steps,omitempty"`
+	// Map of the repository's files and symbols given to implementation agents
+	CodeMap CodeMapSettings `yaml:"code_map,omitempty"`
+}
+
+// CodeMapSettings configures the repository map in implementation prompts.
+type CodeMapSettings struct {
+	Disabled bool `yaml:"disabled,omitempty"`  // Leave the map out of prompts
+	MaxFiles int  `yaml:"max_files,omitempty"` // Files related to the specification to list (default: 15)
+}
+
+// DefaultCodeMapFiles is how many related files the repository map lists
+// when context.code_map.max_files is not set.
+const DefaultCodeMapFiles = 15
+
+// UISettings configures how changes are shown to the user.
+type UISettings struct {
+	// Command opening each file changed by an implementation run, e.g. "code -g {file}:{line}"
+	// or a preset: code, cursor, idea, goland, pycharm, webstorm, phpstorm (default: none)
+	EditorCmd string `yaml:"editor_cmd,omitempty"`
+}
+
+// ContextStepSettings overrides the included files for one workflow step.
+type ContextStepSettings struct {
+	IncludeGlobs []string `yaml:"include_globs"`
+}
+
+// DefaultConventionGlobs are the convention documents included in agent
+// prompts when context.include_globs is not set.
+var DefaultConventionGlobs = []string{"AGENTS.md", "CLAUDE.md", "CONTRIBUTING.md", ".github/CONTRIBUTING.md", "docs/CONTRIBUTING.md"}
+
+// DefaultStyleGuideGlobs are the style guides and lint configs additionally
+// included for implementing and reviewing when context.include_globs is not
+// set.
+var DefaultStyleGuideGlobs = []string{
+	"STYLE*.md", "docs/STYLE*.md", ".editorconfig",
+	".golangci.yml", ".golangci.yaml", ".eslintrc*", "eslint.config.*", ".prettierrc*",
+	"ruff.toml", ".rubocop.yml", ".stylelintrc*",
 }
 
 // ProvidersSettings holds provider-related configuration.
@@ -189,7 +475,7 @@ func NewDefaultWorkspaceConfig() *WorkspaceConfig {
 			CheckInterval: 24,
 		},
 		Storage: StorageSettings{
-			WorkDir: ".mehrhof/work", // Default: .mehrhof/work (relative to project root)
+			WorkDir: config.TaskDir() + "/" + workSubDirName, // Default: .mehrhof/work (relative to project root)
 		},
 		Env: make(map[string]string),
 	}
@@ -210,6 +496,21 @@ func (cfg *WorkspaceConfig) GetEnvForAgent(agentName string) map[string]string {
 	return result
 }
 
+// LogTimeEnabled reports whether time spent on tasks is logged to the named
+// provider on finish.
+func (cfg *WorkspaceConfig) LogTimeEnabled(providerName string) bool {
+	switch providerName {
+	case "jira":
+		return cfg.Jira != nil && cfg.Jira.LogTime
+	case "youtrack":
+		return cfg.YouTrack != nil && cfg.YouTrack.LogTime
+	case "gitlab":
+		return cfg.GitLab != nil && cfg.GitLab.LogTime
+	default:
+		return false
+	}
+}
+
 // SaveConfig saves the workspace configuration to .mehrhof/config.yaml.
 func (w *Workspace) SaveConfig(cfg *WorkspaceConfig) error {
 	// Ensure .mehrhof directory exists
@@ -296,7 +597,7 @@ func (w *Workspace) SaveConfig(cfg *WorkspaceConfig) error {
 	}
 
 	// Add storage section comment if storage work_dir is default/empty
-	if cfg.Storage.WorkDir == "" || cfg.Storage.WorkDir == ".mehrhof/work" {
+	if cfg.Storage.WorkDir == "" || cfg.Storage.WorkDir == config.TaskDir()+"/"+workSubDirName {
 		content += `
 # Storage settings
 # Configure where task work directories are stored (relative to project root)
@@ -305,6 +606,11 @@ func (w *Workspace) SaveConfig(cfg *WorkspaceConfig) error {
 #     work_dir: .mehrhof/work    # Default location
 #     work_dir: tasks/           # Alternative: store in project root tasks/ directory
 #     work_dir: .task-work       # Alternative: hidden directory in project root
+#     remote:                    # Share work directories through a bucket ('mehr task push/pull')
+#         type: s3               # local, s3 or gcs
+#         bucket: team-mehrhof
+#         prefix: myproject
+#         auto_push: true        # Push after every workflow step
 `
 	}
 
@@ -320,6 +626,7 @@ func (w *Workspace) SaveConfig(cfg *WorkspaceConfig) error {
 `
 	}
 
+	keepBackup(w.ConfigPath())
 	if err := os.WriteFile(w.ConfigPath(), []byte(content), 0o644); err != nil {
 		return fmt.Errorf("write config file: %w", err)
 	}
@@ -327,8 +634,34 @@ func (w *Workspace) SaveConfig(cfg *WorkspaceConfig) error {
 	return nil
 }
 
-// LoadConfig loads the workspace configuration from .mehrhof/config.yaml.
+// LoadConfig loads the workspace configuration: the defaults, overridden by
+// .mehrhof/config.yaml, overridden by the personal config.local.yaml.
+// Callers that save the configuration back use LoadSharedConfig, so personal
+// overrides never end up in the committed file.
 func (w *Workspace) LoadConfig() (*WorkspaceConfig, error) {
+	cfg, err := w.LoadSharedConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(w.LocalConfigPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+
+		return nil, fmt.Errorf("read local config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse local config file: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// LoadSharedConfig loads the workspace configuration from
+// .mehrhof/config.yaml alone, over the defaults.
+func (w *Workspace) LoadSharedConfig() (*WorkspaceConfig, error) {
 	data, err := os.ReadFile(w.ConfigPath())
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -346,3 +679,34 @@ func (w *Workspace) LoadConfig() (*WorkspaceConfig, error) {
 
 	return cfg, nil
 }
+
+// LoadConfigFile returns only the settings config.yaml sets, without the
+// defaults LoadConfig fills in, or nil when there is no config file.
+func (w *Workspace) LoadConfigFile() (*WorkspaceConfig, error) {
+	return loadConfigLayer(w.ConfigPath())
+}
+
+// LoadLocalConfigFile returns only the settings config.local.yaml sets, or
+// nil when there is no local config file.
+func (w *Workspace) LoadLocalConfigFile() (*WorkspaceConfig, error) {
+	return loadConfigLayer(w.LocalConfigPath())
+}
+
+// loadConfigLayer parses one config file without defaults.
+func loadConfigLayer(path string) (*WorkspaceConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil //nolint:nilnil // No config file is not an error
+		}
+
+		return nil, fmt.Errorf("read %s: %w", filepath.Base(path), err)
+	}
+
+	cfg := &WorkspaceConfig{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const contextDirName = "context"
+
+// ContextSummaryKey identifies the summary of some context: the source
+// revision it was made for and a hash of the summarized text. A new revision
+// or any change to the text gets a new summary.
+func ContextSummaryKey(revision int, text string) string {
+	sum := sha256.Sum256([]byte(text))
+
+	return fmt.Sprintf("r%d-%s", revision, hex.EncodeToString(sum[:8]))
+}
+
+// contextSummaryPath returns where a cached context summary is stored.
+func (w *Workspace) contextSummaryPath(taskID, key string) string {
+	return filepath.Join(w.WorkPath(taskID), contextDirName, "summary-"+key+".md")
+}
+
+// LoadContextSummary returns a cached context summary, or "" with ok false
+// when there is none for the key.
+func (w *Workspace) LoadContextSummary(taskID, key string) (string, bool, error) {
+	data, err := os.ReadFile(w.contextSummaryPath(taskID, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("read context summary: %w", err)
+	}
+
+	return string(data), true, nil
+}
+
+// SaveContextSummary caches a context summary under its key.
+func (w *Workspace) SaveContextSummary(taskID, key, summary string) error {
+	path := w.contextSummaryPath(taskID, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create context directory: %w", err)
+	}
+
+	return writeFileAtomic(path, []byte(summary))
+}
@@ -0,0 +1,26 @@
+package storage
+
+import "testing"
+
+func TestContextSummaryCache(t *testing.T) {
+	ws, err := OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := ContextSummaryKey(1, "older notes")
+	if key == ContextSummaryKey(2, "older notes") || key == ContextSummaryKey(1, "other notes") {
+		t.Errorf("ContextSummaryKey(%q) does not depend on revision and text", key)
+	}
+
+	if _, ok, err := ws.LoadContextSummary("t1", key); ok || err != nil {
+		t.Errorf("LoadContextSummary before saving = %v, %v; want not found", ok, err)
+	}
+	if err := ws.SaveContextSummary("t1", key, "- decided X"); err != nil {
+		t.Fatalf("SaveContextSummary: %v", err)
+	}
+	summary, ok, err := ws.LoadContextSummary("t1", key)
+	if err != nil || !ok || summary != "- decided X" {
+		t.Errorf("LoadContextSummary = %q, %v, %v; want saved summary", summary, ok, err)
+	}
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+var documentationReportPattern = regexp.MustCompile(`^documentation-(\d+)\.md$`)
+
+// DocumentationDir returns the directory holding documentation reports.
+func (w *Workspace) DocumentationDir(taskID string) string {
+	return filepath.Join(w.WorkPath(taskID), docsDirName)
+}
+
+// DocumentationReportPath returns the path for a documentation report.
+func (w *Workspace) DocumentationReportPath(taskID string, number int) string {
+	filename := fmt.Sprintf("documentation-%d.md", number)
+
+	return filepath.Join(w.DocumentationDir(taskID), filename)
+}
+
+// ListDocumentationReports returns all documentation report numbers for a task.
+func (w *Workspace) ListDocumentationReports(taskID string) ([]int, error) {
+	entries, err := os.ReadDir(w.DocumentationDir(taskID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []int{}, nil
+		}
+
+		return nil, fmt.Errorf("read documentation directory: %w", err)
+	}
+
+	var numbers []int
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if matches := documentationReportPattern.FindStringSubmatch(entry.Name()); matches != nil {
+			num, _ := strconv.Atoi(matches[1])
+			numbers = append(numbers, num)
+		}
+	}
+
+	slices.Sort(numbers)
+
+	return numbers, nil
+}
+
+// NextDocumentationReportNumber returns the next available documentation report number.
+func (w *Workspace) NextDocumentationReportNumber(taskID string) (int, error) {
+	numbers, err := w.ListDocumentationReports(taskID)
+	if err != nil {
+		return 0, err
+	}
+	if len(numbers) == 0 {
+		return 1, nil
+	}
+
+	return numbers[len(numbers)-1] + 1, nil
+}
+
+// SaveDocumentationReport saves a documentation report file (markdown).
+func (w *Workspace) SaveDocumentationReport(taskID string, number int, content string) error {
+	if err := os.MkdirAll(w.DocumentationDir(taskID), 0o755); err != nil {
+		return fmt.Errorf("create documentation directory: %w", err)
+	}
+
+	return os.WriteFile(w.DocumentationReportPath(taskID, number), []byte(content), 0o644)
+}
+
+// LoadDocumentationReport loads a documentation report's content.
+func (w *Workspace) LoadDocumentationReport(taskID string, number int) (string, error) {
+	data, err := os.ReadFile(w.DocumentationReportPath(taskID, number))
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDocumentationReports(t *testing.T) {
+	ws, _ := OpenWorkspace(t.TempDir(), nil)
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+	if _, err := ws.CreateWork("task1", SourceInfo{Type: "file", Ref: "task.md"}); err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+
+	if numbers, err := ws.ListDocumentationReports("task1"); err != nil || len(numbers) != 0 {
+		t.Fatalf("ListDocumentationReports before any report = %v, %v", numbers, err)
+	}
+
+	for i, content := range []string{"# Documentation Update 1\n", "# Documentation Update 2\n"} {
+		number, err := ws.NextDocumentationReportNumber("task1")
+		if err != nil {
+			t.Fatalf("NextDocumentationReportNumber: %v", err)
+		}
+		if number != i+1 {
+			t.Errorf("NextDocumentationReportNumber = %d, want %d", number, i+1)
+		}
+		if err := ws.SaveDocumentationReport("task1", number, content); err != nil {
+			t.Fatalf("SaveDocumentationReport: %v", err)
+		}
+	}
+
+	numbers, err := ws.ListDocumentationReports("task1")
+	if err != nil {
+		t.Fatalf("ListDocumentationReports: %v", err)
+	}
+	if !slices.Equal(numbers, []int{1, 2}) {
+		t.Errorf("ListDocumentationReports = %v, want [1 2]", numbers)
+	}
+
+	content, err := ws.LoadDocumentationReport("task1", 2)
+	if err != nil {
+		t.Fatalf("LoadDocumentationReport: %v", err)
+	}
+	if content != "# Documentation Update 2\n" {
+		t.Errorf("LoadDocumentationReport = %q", content)
+	}
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// maxEventLineBytes bounds one line of the event log when reading it back.
+const maxEventLineBytes = 4 << 20
+
+// EventsPath returns the path to the task's event log.
+func (w *Workspace) EventsPath(taskID string) string {
+	return filepath.Join(w.WorkPath(taskID), eventsFileName)
+}
+
+// AppendEvent appends an event to the task's event log. Each event is one
+// JSON line written with a single append, so concurrent writers never
+// interleave within a line.
+func (w *Workspace) AppendEvent(taskID string, record EventRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		// Keep the event even if some data cannot be encoded.
+		record.Data = stringifyEventData(record.Data)
+		if line, err = json.Marshal(record); err != nil {
+			return fmt.Errorf("encode event: %w", err)
+		}
+	}
+
+	f, err := os.OpenFile(w.EventsPath(taskID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open event log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write event log: %w", err)
+	}
+
+	return nil
+}
+
+// ReadEvents returns the events in the task's event log that match filter,
+// oldest first. A task without an event log has no events. Lines that cannot
+// be decoded, such as one cut short by a crash, are skipped.
+func (w *Workspace) ReadEvents(taskID string, filter EventFilter) ([]EventRecord, error) {
+	f, err := os.Open(w.EventsPath(taskID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open event log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var records []EventRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxEventLineBytes)
+	for n := 0; scanner.Scan(); {
+		var record EventRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		if n++; n <= filter.Offset {
+			continue
+		}
+		if filter.matches(record) {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read event log: %w", err)
+	}
+
+	if filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[len(records)-filter.Limit:]
+	}
+
+	return records, nil
+}
+
+func (f EventFilter) matches(record EventRecord) bool {
+	if !f.Since.IsZero() && record.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !record.Timestamp.Before(f.Until) {
+		return false
+	}
+	if len(f.Types) > 0 && !slices.Contains(f.Types, record.Type) {
+		return false
+	}
+
+	return true
+}
+
+// stringifyEventData replaces values that cannot be encoded as JSON with
+// their string form.
+func stringifyEventData(data map[string]any) map[string]any {
+	out := make(map[string]any, len(data))
+	for k, v := range data {
+		if _, err := json.Marshal(v); err != nil {
+			v = fmt.Sprint(v)
+		}
+		out[k] = v
+	}
+
+	return out
+}
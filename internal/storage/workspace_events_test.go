@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestAppendReadEvents(t *testing.T) {
+	ws, _ := OpenWorkspace(t.TempDir(), nil)
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+	if _, err := ws.CreateWork("task1", SourceInfo{Type: "file", Ref: "task.md"}); err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+
+	if events, err := ws.ReadEvents("task1", EventFilter{}); err != nil || events != nil {
+		t.Fatalf("ReadEvents without a log = %v, %v", events, err)
+	}
+
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	records := []EventRecord{
+		{Timestamp: start, Type: "state_changed", Data: map[string]any{"from": "idle", "to": "planning"}},
+		{Timestamp: start.Add(time.Minute), Type: "progress", Data: map[string]any{"message": "Agent analyzing task..."}},
+		{Timestamp: start.Add(2 * time.Minute), Type: "error", Data: map[string]any{"err": errors.New("boom"), "ch": make(chan int)}},
+		{Timestamp: start.Add(3 * time.Minute), Type: "progress", Data: map[string]any{"message": "done"}},
+	}
+	for _, r := range records {
+		if err := ws.AppendEvent("task1", r); err != nil {
+			t.Fatalf("AppendEvent(%s): %v", r.Type, err)
+		}
+	}
+
+	// A line cut short by a crash is skipped
+	f, err := os.OpenFile(ws.EventsPath("task1"), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	_, _ = f.WriteString(`{"timestamp":"2026-01-01T10:05:00Z","ty`)
+	_ = f.Close()
+
+	all, err := ws.ReadEvents("task1", EventFilter{})
+	if err != nil {
+		t.Fatalf("ReadEvents: %v", err)
+	}
+	if len(all) != 4 {
+		t.Fatalf("got %d events, want 4", len(all))
+	}
+	if all[0].Data["to"] != "planning" || !all[1].Timestamp.Equal(start.Add(time.Minute)) {
+		t.Errorf("events[0:2] = %+v", all[:2])
+	}
+	if _, ok := all[2].Data["ch"].(string); !ok {
+		t.Errorf("unencodable data should be kept as a string: %+v", all[2].Data)
+	}
+
+	tests := []struct {
+		name   string
+		filter EventFilter
+		want   []string
+	}{
+		{"types", EventFilter{Types: []string{"progress"}}, []string{"Agent analyzing task...", "done"}},
+		{"limit keeps newest", EventFilter{Types: []string{"progress"}, Limit: 1}, []string{"done"}},
+		{"offset skips the first events", EventFilter{Offset: 2, Types: []string{"progress"}}, []string{"done"}},
+		{"time window", EventFilter{Since: start.Add(time.Minute), Until: start.Add(3 * time.Minute), Types: []string{"progress"}}, []string{"Agent analyzing task..."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ws.ReadEvents("task1", tt.filter)
+			if err != nil {
+				t.Fatalf("ReadEvents: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d events, want %d", len(got), len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got[i].Data["message"] != want {
+					t.Errorf("event %d message = %v, want %q", i, got[i].Data["message"], want)
+				}
+			}
+		})
+	}
+}
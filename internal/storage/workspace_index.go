@@ -0,0 +1,469 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	indexFileName = "index.json"
+
+	// indexVersion is the index file format version. An index written by
+	// another version is ignored until 'mehr reindex' rebuilds it.
+	indexVersion = 1
+
+	// Kinds of searchable documents.
+	DocSpecification = "specification"
+	DocNotes         = "notes"
+)
+
+// ErrNoIndex is returned by index queries when the workspace has no index.
+var ErrNoIndex = errors.New("workspace has no index (run 'mehr reindex')")
+
+// workIndex is the optional workspace index in .mehrhof/index.json. It holds
+// every task's work.yaml and the terms of its specifications and notes, so
+// listing, cost summaries and search read one file instead of walking every
+// work directory. It is created by Reindex and kept current by the
+// Workspace methods that write tasks, specifications and notes.
+type workIndex struct {
+	Version   int                     `json:"version"`
+	BuiltAt   time.Time               `json:"built_at"`
+	Tasks     map[string]*TaskWork    `json:"tasks"`
+	Documents map[string]*indexedDocs `json:"documents"` // By task ID
+}
+
+// indexedDocs are the searchable documents of one task.
+type indexedDocs struct {
+	Specs map[int]*indexedDoc `json:"specifications,omitempty"`
+	Notes *indexedDoc         `json:"notes,omitempty"`
+}
+
+// indexedDoc holds the term frequencies of one document.
+type indexedDoc struct {
+	Title string         `json:"title,omitempty"`
+	Terms map[string]int `json:"terms"`
+}
+
+// SearchHit is one document matching a search.
+type SearchHit struct {
+	TaskID  string  `json:"task_id"`
+	Kind    string  `json:"kind"`             // DocSpecification or DocNotes
+	Number  int     `json:"number,omitempty"` // Specification number
+	Title   string  `json:"title,omitempty"`
+	Path    string  `json:"path"`
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet,omitempty"` // First line containing a search term
+}
+
+// IndexStats describes a rebuilt index.
+type IndexStats struct {
+	Tasks          int
+	Specifications int
+	Notes          int
+}
+
+// IndexPath returns the path to the workspace index.
+func (w *Workspace) IndexPath() string {
+	return filepath.Join(w.taskRoot, indexFileName)
+}
+
+// HasIndex reports whether the workspace keeps an index.
+func (w *Workspace) HasIndex() bool {
+	_, err := os.Stat(w.IndexPath())
+
+	return err == nil
+}
+
+func (w *Workspace) indexLockPath() string {
+	return filepath.Join(w.LocksDir(), "index.lock")
+}
+
+// Reindex rebuilds the index from the work directories, creating it if the
+// workspace had none.
+func (w *Workspace) Reindex() (IndexStats, error) {
+	var stats IndexStats
+
+	ids, err := w.ListWorks()
+	if err != nil {
+		return stats, err
+	}
+
+	idx := &workIndex{
+		Version:   indexVersion,
+		BuiltAt:   time.Now(),
+		Tasks:     make(map[string]*TaskWork, len(ids)),
+		Documents: make(map[string]*indexedDocs, len(ids)),
+	}
+	for _, id := range ids {
+		work, err := w.LoadWork(id)
+		if err != nil {
+			slog.Warn("skipping unreadable task while indexing", "task", id, "error", err)
+
+			continue
+		}
+		idx.Tasks[id] = work
+		stats.Tasks++
+
+		numbers, err := w.ListSpecifications(id)
+		if err != nil {
+			return stats, err
+		}
+		for _, n := range numbers {
+			content, err := w.LoadSpecification(id, n)
+			if err != nil {
+				continue
+			}
+			idx.setSpec(id, n, content)
+			stats.Specifications++
+		}
+		if notes, err := w.ReadNotes(id); err == nil {
+			idx.setNotes(id, notes)
+			stats.Notes++
+		}
+	}
+
+	err = WithLock(w.indexLockPath(), func() error {
+		return w.writeIndex(idx)
+	})
+
+	return stats, err
+}
+
+// LoadWorks returns every task's work metadata, sorted by task ID. With an
+// index it is read from the index in one go; otherwise each work.yaml is
+// read. Unreadable tasks are skipped.
+func (w *Workspace) LoadWorks() ([]*TaskWork, error) {
+	if idx, err := w.readIndex(); err == nil {
+		works := make([]*TaskWork, 0, len(idx.Tasks))
+		for _, work := range idx.Tasks {
+			works = append(works, work)
+		}
+		slices.SortFunc(works, func(a, b *TaskWork) int { return strings.Compare(a.Metadata.ID, b.Metadata.ID) })
+
+		return works, nil
+	}
+
+	ids, err := w.ListWorks()
+	if err != nil {
+		return nil, err
+	}
+	works := make([]*TaskWork, 0, len(ids))
+	for _, id := range ids {
+		work, err := w.LoadWork(id)
+		if err != nil {
+			continue
+		}
+		works = append(works, work)
+	}
+
+	return works, nil
+}
+
+// Search finds specifications and notes containing every word of query,
+// best matches first. A word ending in '*' matches by prefix. Returns
+// ErrNoIndex when the workspace has no index.
+func (w *Workspace) Search(query string, limit int) ([]SearchHit, error) {
+	idx, err := w.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	var words []string
+	for _, field := range strings.Fields(strings.ToLower(query)) {
+		prefix := strings.HasSuffix(field, "*")
+		for _, term := range indexTerms(field) {
+			if prefix {
+				term += "*"
+			}
+			words = append(words, term)
+		}
+	}
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	type candidate struct {
+		hit SearchHit
+		doc *indexedDoc
+	}
+	var all []candidate
+	for taskID, docs := range idx.Documents {
+		for n, doc := range docs.Specs {
+			all = append(all, candidate{SearchHit{TaskID: taskID, Kind: DocSpecification, Number: n, Title: doc.Title}, doc})
+		}
+		if docs.Notes != nil {
+			all = append(all, candidate{SearchHit{TaskID: taskID, Kind: DocNotes}, docs.Notes})
+		}
+	}
+
+	// Inverse document frequency per query word
+	idf := make([]float64, len(words))
+	for i, word := range words {
+		var df int
+		for _, c := range all {
+			if termCount(c.doc, word) > 0 {
+				df++
+			}
+		}
+		idf[i] = math.Log(1 + float64(len(all))/float64(max(df, 1)))
+	}
+
+	var hits []SearchHit
+	for _, c := range all {
+		score := 0.0
+		for i, word := range words {
+			tf := termCount(c.doc, word)
+			if tf == 0 {
+				score = 0
+
+				break
+			}
+			score += math.Log(1+float64(tf)) * idf[i]
+		}
+		if score == 0 {
+			continue
+		}
+		c.hit.Score = score
+		hits = append(hits, c.hit)
+	}
+
+	slices.SortFunc(hits, func(a, b SearchHit) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+
+			return 1
+		}
+		if c := strings.Compare(a.TaskID, b.TaskID); c != 0 {
+			return c
+		}
+
+		return a.Number - b.Number
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	for i := range hits {
+		hits[i].Path, hits[i].Snippet = w.searchSnippet(hits[i], words)
+	}
+
+	return hits, nil
+}
+
+// termCount returns how often a term, or terms with a prefix when word ends
+// in '*', occur in doc.
+func termCount(doc *indexedDoc, word string) int {
+	prefix, ok := strings.CutSuffix(word, "*")
+	if !ok {
+		return doc.Terms[word]
+	}
+
+	var n int
+	for term, count := range doc.Terms {
+		if strings.HasPrefix(term, prefix) {
+			n += count
+		}
+	}
+
+	return n
+}
+
+// searchSnippet returns the document path of a hit and its first line
+// containing a search word.
+func (w *Workspace) searchSnippet(hit SearchHit, words []string) (string, string) {
+	var path, content string
+	if hit.Kind == DocSpecification {
+		path = w.SpecificationPath(hit.TaskID, hit.Number)
+		data, _ := os.ReadFile(path)
+		content = string(data)
+	} else {
+		path = w.NotesPath(hit.TaskID)
+		if _, err := os.Stat(path); err != nil {
+			path = w.NotesDir(hit.TaskID)
+		}
+		content, _ = w.ReadNotes(hit.TaskID)
+	}
+	if rel, err := filepath.Rel(w.root, path); err == nil {
+		path = rel
+	}
+
+	for line := range strings.SplitSeq(content, "\n") {
+		lower := strings.ToLower(line)
+		for _, word := range words {
+			if strings.Contains(lower, strings.TrimSuffix(word, "*")) {
+				line = strings.TrimSpace(line)
+				if len(line) > 120 {
+					line = line[:117] + "..."
+				}
+
+				return path, line
+			}
+		}
+	}
+
+	return path, ""
+}
+
+// indexTerms splits text into lowercase words of two or more letters or digits.
+func indexTerms(text string) []string {
+	return slices.DeleteFunc(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), func(term string) bool { return len(term) < 2 })
+}
+
+func newIndexedDoc(title, content string) *indexedDoc {
+	doc := &indexedDoc{Title: title, Terms: make(map[string]int)}
+	for _, term := range indexTerms(content) {
+		doc.Terms[term]++
+	}
+
+	return doc
+}
+
+func (idx *workIndex) docs(taskID string) *indexedDocs {
+	docs := idx.Documents[taskID]
+	if docs == nil {
+		docs = &indexedDocs{}
+		idx.Documents[taskID] = docs
+	}
+
+	return docs
+}
+
+func (idx *workIndex) setSpec(taskID string, number int, content string) {
+	docs := idx.docs(taskID)
+	if docs.Specs == nil {
+		docs.Specs = make(map[int]*indexedDoc)
+	}
+	// Index the body only: frontmatter holds status and timestamps
+	if rest, ok := strings.CutPrefix(content, "---\n"); ok {
+		if end := strings.Index(rest, "\n---"); end >= 0 {
+			content = rest[end+4:]
+		}
+	}
+	title := ""
+	for line := range strings.SplitSeq(content, "\n") {
+		if heading, ok := strings.CutPrefix(line, "# "); ok {
+			title = strings.TrimSpace(heading)
+
+			break
+		}
+	}
+	docs.Specs[number] = newIndexedDoc(title, content)
+}
+
+func (idx *workIndex) setNotes(taskID, notes string) {
+	idx.docs(taskID).Notes = newIndexedDoc("", notes)
+}
+
+// readIndex loads the index. A missing index, or one written in another
+// format version, returns ErrNoIndex.
+func (w *Workspace) readIndex() (*workIndex, error) {
+	data, err := os.ReadFile(w.IndexPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNoIndex
+		}
+
+		return nil, fmt.Errorf("read index: %w", err)
+	}
+
+	var idx workIndex
+	if err := json.Unmarshal(data, &idx); err != nil || idx.Version != indexVersion {
+		return nil, ErrNoIndex
+	}
+	if idx.Tasks == nil {
+		idx.Tasks = make(map[string]*TaskWork)
+	}
+	if idx.Documents == nil {
+		idx.Documents = make(map[string]*indexedDocs)
+	}
+
+	return &idx, nil
+}
+
+func (w *Workspace) writeIndex(idx *workIndex) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("marshal index: %w", err)
+	}
+
+	tmp := w.IndexPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write index: %w", err)
+	}
+	if err := os.Rename(tmp, w.IndexPath()); err != nil {
+		_ = os.Remove(tmp)
+
+		return fmt.Errorf("write index: %w", err)
+	}
+
+	return nil
+}
+
+// updateIndex applies a change to the index when the workspace keeps one.
+// Index failures are logged, never returned: the work directory stays the
+// source of truth and 'mehr reindex' repairs a stale index.
+func (w *Workspace) updateIndex(change func(idx *workIndex)) {
+	if !w.HasIndex() {
+		return
+	}
+
+	err := WithLockTimeout(w.indexLockPath(), 5*time.Second, func() error {
+		idx, err := w.readIndex()
+		if err != nil {
+			return err
+		}
+		change(idx)
+
+		return w.writeIndex(idx)
+	})
+	if err != nil {
+		slog.Warn("failed to update workspace index", "error", err)
+	}
+}
+
+func (w *Workspace) indexWork(work *TaskWork) {
+	w.updateIndex(func(idx *workIndex) {
+		idx.Tasks[work.Metadata.ID] = work
+	})
+}
+
+func (w *Workspace) unindexWork(taskID string) {
+	w.updateIndex(func(idx *workIndex) {
+		delete(idx.Tasks, taskID)
+		delete(idx.Documents, taskID)
+	})
+}
+
+func (w *Workspace) indexSpecification(taskID string, number int, content string) {
+	w.updateIndex(func(idx *workIndex) {
+		idx.setSpec(taskID, number, content)
+	})
+}
+
+func (w *Workspace) indexNotes(taskID string) {
+	notes, err := w.ReadNotes(taskID)
+	if err != nil {
+		return
+	}
+	w.updateIndex(func(idx *workIndex) {
+		idx.setNotes(taskID, notes)
+	})
+}
+
+// String describes the stats for messages.
+func (s IndexStats) String() string {
+	return fmt.Sprintf("%d task(s), %d specification(s), %d with notes", s.Tasks, s.Specifications, s.Notes)
+}
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReindexAndLoadWorks(t *testing.T) {
+	ws := newBundleWorkspace(t)
+	for _, id := range []string{"task2", "task1"} {
+		if _, err := ws.CreateWork(id, SourceInfo{Type: "file", Ref: id + ".md"}); err != nil {
+			t.Fatalf("CreateWork: %v", err)
+		}
+	}
+
+	// Without an index, works are read from the work directories
+	works, err := ws.LoadWorks()
+	if err != nil || len(works) != 2 {
+		t.Fatalf("LoadWorks = %d works, %v", len(works), err)
+	}
+	if _, err := ws.Search("anything", 0); !errors.Is(err, ErrNoIndex) {
+		t.Errorf("Search without index error = %v, want ErrNoIndex", err)
+	}
+
+	if err := ws.SaveSpecification("task1", 1, "# Add a cache\n\nCache responses.\n"); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := ws.Reindex()
+	if err != nil {
+		t.Fatalf("Reindex: %v", err)
+	}
+	if stats.Tasks != 2 || stats.Specifications != 1 {
+		t.Errorf("Reindex stats = %+v", stats)
+	}
+	if !ws.HasIndex() {
+		t.Fatal("HasIndex = false after Reindex")
+	}
+
+	// Writes keep the index current
+	work, _ := ws.LoadWork("task2")
+	work.Metadata.Title = "Renamed"
+	if err := ws.SaveWork(work); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.CreateWork("task3", SourceInfo{Type: "file", Ref: "task3.md"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.DeleteWork("task1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Remove the work files so only the index can answer
+	if err := os.RemoveAll(ws.WorkRoot()); err != nil {
+		t.Fatal(err)
+	}
+	works, err = ws.LoadWorks()
+	if err != nil {
+		t.Fatalf("LoadWorks: %v", err)
+	}
+	var ids []string
+	for _, w := range works {
+		ids = append(ids, w.Metadata.ID)
+	}
+	if !slices.Equal(ids, []string{"task2", "task3"}) {
+		t.Errorf("LoadWorks IDs = %v, want [task2 task3]", ids)
+	}
+	if works[0].Metadata.Title != "Renamed" {
+		t.Errorf("indexed title = %q, want Renamed", works[0].Metadata.Title)
+	}
+	if hits, _ := ws.Search("cache", 0); len(hits) != 0 {
+		t.Errorf("Search found deleted task: %+v", hits)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ws := newBundleWorkspace(t)
+	for _, id := range []string{"task1", "task2"} {
+		if _, err := ws.CreateWork(id, SourceInfo{Type: "file", Ref: id + ".md"}); err != nil {
+			t.Fatalf("CreateWork: %v", err)
+		}
+	}
+	if _, err := ws.Reindex(); err != nil {
+		t.Fatalf("Reindex: %v", err)
+	}
+
+	if err := ws.SaveSpecificationWithMeta("task1", &Specification{
+		Number:  1,
+		Status:  SpecificationStatusDraft,
+		Content: "# Retry uploads\n\nRetry failed uploads with exponential backoff.\nRetry at most five times.\n",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.SaveSpecification("task2", 1, "# Upload progress\n\nShow upload progress.\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.AppendNote("task2", "Users want retries on flaky networks.", "planning"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string // task/kind of hits, best first
+	}{
+		{"single word", "backoff", []string{"task1/specification"}},
+		{"prefix across documents", "upload*", []string{"task1/specification", "task2/specification"}},
+		{"all words required", "retry backoff", []string{"task1/specification"}},
+		{"prefix", "retr*", []string{"task1/specification", "task2/notes"}},
+		{"case insensitive", "PROGRESS", []string{"task2/specification"}},
+		{"frontmatter not indexed", "draft", nil},
+		{"no match", "database", nil},
+		{"no terms", "- !", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := ws.Search(tt.query, 0)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			var got []string
+			for _, hit := range hits {
+				got = append(got, hit.TaskID+"/"+hit.Kind)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	hits, _ := ws.Search("backoff", 1)
+	if len(hits) != 1 || hits[0].Title != "Retry uploads" || !strings.Contains(hits[0].Snippet, "exponential backoff") {
+		t.Errorf("hit = %+v, want title and snippet of the specification", hits)
+	}
+}
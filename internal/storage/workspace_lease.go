@@ -0,0 +1,279 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/user"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLeaseTTL is how long a task lease stays valid without a heartbeat.
+const DefaultLeaseTTL = 2 * time.Minute
+
+// ErrLeaseHeld is returned when another conductor holds an unexpired lease on a task.
+var ErrLeaseHeld = errors.New("task is leased by another conductor")
+
+// TaskLease records which conductor currently owns a task.
+// A lease with PID 0 is a reservation created by 'mehr task steal': any
+// process of the same owner on the same host may claim it.
+type TaskLease struct {
+	TaskID      string    `yaml:"task_id"`
+	Owner       string    `yaml:"owner"`
+	Hostname    string    `yaml:"hostname"`
+	PID         int       `yaml:"pid"`
+	AcquiredAt  time.Time `yaml:"acquired_at"`
+	HeartbeatAt time.Time `yaml:"heartbeat_at"`
+	ExpiresAt   time.Time `yaml:"expires_at"`
+}
+
+// newTaskLease creates a lease for the current process.
+func newTaskLease(taskID string, ttl time.Duration) *TaskLease {
+	now := time.Now()
+
+	return &TaskLease{
+		TaskID:      taskID,
+		Owner:       currentOwner(),
+		Hostname:    currentHostname(),
+		PID:         os.Getpid(),
+		AcquiredAt:  now,
+		HeartbeatAt: now,
+		ExpiresAt:   now.Add(ttl),
+	}
+}
+
+// Holder returns a human-readable description of the lease holder.
+func (l *TaskLease) Holder() string {
+	if l.PID == 0 {
+		return fmt.Sprintf("%s@%s", l.Owner, l.Hostname)
+	}
+
+	return fmt.Sprintf("%s@%s (pid %d)", l.Owner, l.Hostname, l.PID)
+}
+
+// Expired reports whether the lease can be taken over. A lease is expired
+// when its heartbeat lapsed, or when its process on this host no longer exists.
+func (l *TaskLease) Expired(now time.Time) bool {
+	if !now.Before(l.ExpiresAt) {
+		return true
+	}
+	if l.PID > 0 && l.Hostname == currentHostname() && !processAlive(l.PID) {
+		return true
+	}
+
+	return false
+}
+
+// heldBy reports whether the lease belongs to (or is reserved for) the given lease's holder.
+func (l *TaskLease) heldBy(other *TaskLease) bool {
+	if l.Owner != other.Owner || l.Hostname != other.Hostname {
+		return false
+	}
+
+	return l.PID == other.PID || l.PID == 0
+}
+
+// TaskLeasePath returns the path to the lease file for a task.
+func (w *Workspace) TaskLeasePath(taskID string) string {
+	return filepath.Join(w.LocksDir(), taskID+".lease")
+}
+
+// LoadTaskLease loads the current lease for a task.
+// Returns nil without error when the task has no lease.
+func (w *Workspace) LoadTaskLease(taskID string) (*TaskLease, error) {
+	data, err := os.ReadFile(w.TaskLeasePath(taskID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil //nolint:nilnil // no lease is not an error
+		}
+
+		return nil, fmt.Errorf("read task lease: %w", err)
+	}
+
+	var lease TaskLease
+	if err := yaml.Unmarshal(data, &lease); err != nil {
+		return nil, fmt.Errorf("parse task lease: %w", err)
+	}
+
+	return &lease, nil
+}
+
+// AcquireTaskLease takes the lease on a task for the current process.
+// Returns ErrLeaseHeld if another conductor holds an unexpired lease.
+// Re-acquiring a lease already held by this process renews it.
+func (w *Workspace) AcquireTaskLease(taskID string, ttl time.Duration) (*TaskLease, error) {
+	lease := newTaskLease(taskID, ttl)
+
+	err := w.WithTaskLock(taskID, func() error {
+		current, err := w.LoadTaskLease(taskID)
+		if err != nil {
+			return err
+		}
+		if current != nil && !current.heldBy(lease) && !current.Expired(time.Now()) {
+			return leaseHeldError(current)
+		}
+		if current != nil && current.heldBy(lease) && current.PID == lease.PID {
+			lease.AcquiredAt = current.AcquiredAt
+		}
+
+		return w.saveTaskLease(lease)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return lease, nil
+}
+
+// RenewTaskLease extends a lease held by the current process.
+// Returns ErrLeaseHeld if the lease was stolen in the meantime.
+func (w *Workspace) RenewTaskLease(lease *TaskLease, ttl time.Duration) error {
+	return w.WithTaskLock(lease.TaskID, func() error {
+		current, err := w.LoadTaskLease(lease.TaskID)
+		if err != nil {
+			return err
+		}
+		if current != nil && !current.heldBy(lease) {
+			return leaseHeldError(current)
+		}
+
+		now := time.Now()
+		lease.HeartbeatAt = now
+		lease.ExpiresAt = now.Add(ttl)
+
+		return w.saveTaskLease(lease)
+	})
+}
+
+// ReleaseTaskLease removes a lease held by the current process.
+// A lease that has since been taken over by someone else is left untouched.
+func (w *Workspace) ReleaseTaskLease(lease *TaskLease) error {
+	return w.WithTaskLock(lease.TaskID, func() error {
+		current, err := w.LoadTaskLease(lease.TaskID)
+		if err != nil {
+			return err
+		}
+		if current == nil || !current.heldBy(lease) {
+			return nil
+		}
+
+		if err := os.Remove(w.TaskLeasePath(lease.TaskID)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove task lease: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ClearStaleTaskLease removes a task's lease when it has expired, for
+// example because its conductor crashed. It reports whether a lease was removed.
+func (w *Workspace) ClearStaleTaskLease(taskID string) (bool, error) {
+	removed := false
+	err := w.WithTaskLock(taskID, func() error {
+		current, err := w.LoadTaskLease(taskID)
+		if err != nil || current == nil || !current.Expired(time.Now()) {
+			return err
+		}
+
+		if err := os.Remove(w.TaskLeasePath(taskID)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove task lease: %w", err)
+		}
+		removed = true
+
+		return nil
+	})
+
+	return removed, err
+}
+
+// StealTaskLease takes over the lease on a task for the current user and host.
+// An unexpired lease held by someone else is only taken when force is set.
+// The new lease is a reservation (PID 0) so the next mehr command run by this
+// user on this host can claim it. Returns the previous lease, if any.
+func (w *Workspace) StealTaskLease(taskID string, ttl time.Duration, force bool) (*TaskLease, error) {
+	reservation := newTaskLease(taskID, ttl)
+	reservation.PID = 0
+
+	var previous *TaskLease
+	err := w.WithTaskLock(taskID, func() error {
+		current, err := w.LoadTaskLease(taskID)
+		if err != nil {
+			return err
+		}
+		if current != nil && !force && !current.heldBy(reservation) && !current.Expired(time.Now()) {
+			return fmt.Errorf("%w (use --force to take it anyway)", leaseHeldError(current))
+		}
+		previous = current
+
+		return w.saveTaskLease(reservation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return previous, nil
+}
+
+// saveTaskLease writes a lease using the atomic write pattern.
+// Callers must hold the task lock.
+func (w *Workspace) saveTaskLease(lease *TaskLease) error {
+	data, err := yaml.Marshal(lease)
+	if err != nil {
+		return fmt.Errorf("marshal task lease: %w", err)
+	}
+
+	if err := os.MkdirAll(w.LocksDir(), 0o755); err != nil {
+		return fmt.Errorf("create locks directory: %w", err)
+	}
+
+	path := w.TaskLeasePath(lease.TaskID)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("write task lease: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		if removeErr := os.Remove(tmpPath); removeErr != nil {
+			slog.Warn("failed to clean up temp file after rename error", "path", tmpPath, "error", removeErr)
+		}
+
+		return fmt.Errorf("save task lease: %w", err)
+	}
+
+	return nil
+}
+
+func leaseHeldError(lease *TaskLease) error {
+	return fmt.Errorf("%w: held by %s until %s", ErrLeaseHeld, lease.Holder(), lease.ExpiresAt.Format(time.RFC3339))
+}
+
+func currentOwner() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+
+	return "unknown"
+}
+
+func currentHostname() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return "unknown"
+	}
+
+	return host
+}
+
+// processAlive reports whether a process with the given PID exists on this host.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+// foreignLease writes a lease owned by another host so it is treated as live.
+func foreignLease(t *testing.T, ws *Workspace, taskID string, expires time.Time) {
+	t.Helper()
+
+	lease := &TaskLease{
+		TaskID:      taskID,
+		Owner:       "alice",
+		Hostname:    "other-host",
+		PID:         4242,
+		AcquiredAt:  time.Now(),
+		HeartbeatAt: time.Now(),
+		ExpiresAt:   expires,
+	}
+	if err := ws.saveTaskLease(lease); err != nil {
+		t.Fatalf("saveTaskLease: %v", err)
+	}
+}
+
+func TestAcquireTaskLease(t *testing.T) {
+	ws, _ := OpenWorkspace(t.TempDir(), nil)
+
+	lease, err := ws.AcquireTaskLease("task1", time.Minute)
+	if err != nil {
+		t.Fatalf("AcquireTaskLease: %v", err)
+	}
+	if lease.PID != os.Getpid() {
+		t.Errorf("PID = %d, want %d", lease.PID, os.Getpid())
+	}
+
+	loaded, err := ws.LoadTaskLease("task1")
+	if err != nil {
+		t.Fatalf("LoadTaskLease: %v", err)
+	}
+	if loaded == nil || loaded.Owner != lease.Owner || loaded.Hostname != lease.Hostname {
+		t.Errorf("loaded lease = %+v, want %+v", loaded, lease)
+	}
+
+	// Re-acquiring from the same process renews.
+	if _, err := ws.AcquireTaskLease("task1", time.Minute); err != nil {
+		t.Errorf("re-acquire: %v", err)
+	}
+
+	if err := ws.ReleaseTaskLease(lease); err != nil {
+		t.Fatalf("ReleaseTaskLease: %v", err)
+	}
+	if loaded, _ := ws.LoadTaskLease("task1"); loaded != nil {
+		t.Error("lease should be removed after release")
+	}
+}
+
+func TestAcquireTaskLease_Held(t *testing.T) {
+	ws, _ := OpenWorkspace(t.TempDir(), nil)
+	foreignLease(t, ws, "task1", time.Now().Add(time.Hour))
+
+	_, err := ws.AcquireTaskLease("task1", time.Minute)
+	if !errors.Is(err, ErrLeaseHeld) {
+		t.Errorf("AcquireTaskLease error = %v, want ErrLeaseHeld", err)
+	}
+}
+
+func TestAcquireTaskLease_Expired(t *testing.T) {
+	ws, _ := OpenWorkspace(t.TempDir(), nil)
+	foreignLease(t, ws, "task1", time.Now().Add(-time.Second))
+
+	if _, err := ws.AcquireTaskLease("task1", time.Minute); err != nil {
+		t.Errorf("AcquireTaskLease on expired lease: %v", err)
+	}
+}
+
+func TestTaskLease_ExpiredDeadProcess(t *testing.T) {
+	lease := newTaskLease("task1", time.Hour)
+	lease.PID = 1 << 30 // no such process
+
+	if !lease.Expired(time.Now()) {
+		t.Error("lease of a dead local process should be expired")
+	}
+}
+
+func TestClearStaleTaskLease(t *testing.T) {
+	ws, _ := OpenWorkspace(t.TempDir(), nil)
+
+	foreignLease(t, ws, "live", time.Now().Add(time.Hour))
+	if removed, err := ws.ClearStaleTaskLease("live"); err != nil || removed {
+		t.Errorf("ClearStaleTaskLease(live) = %v, %v; want false, nil", removed, err)
+	}
+
+	foreignLease(t, ws, "stale", time.Now().Add(-time.Second))
+	if removed, err := ws.ClearStaleTaskLease("stale"); err != nil || !removed {
+		t.Errorf("ClearStaleTaskLease(stale) = %v, %v; want true, nil", removed, err)
+	}
+	if lease, _ := ws.LoadTaskLease("stale"); lease != nil {
+		t.Error("stale lease should be removed")
+	}
+
+	if removed, err := ws.ClearStaleTaskLease("none"); err != nil || removed {
+		t.Errorf("ClearStaleTaskLease(none) = %v, %v; want false, nil", removed, err)
+	}
+}
+
+func TestRenewTaskLease_Stolen(t *testing.T) {
+	ws, _ := OpenWorkspace(t.TempDir(), nil)
+
+	lease, err := ws.AcquireTaskLease("task1", time.Minute)
+	if err != nil {
+		t.Fatalf("AcquireTaskLease: %v", err)
+	}
+	if err := ws.RenewTaskLease(lease, time.Minute); err != nil {
+		t.Errorf("RenewTaskLease: %v", err)
+	}
+
+	foreignLease(t, ws, "task1", time.Now().Add(time.Hour))
+	if err := ws.RenewTaskLease(lease, time.Minute); !errors.Is(err, ErrLeaseHeld) {
+		t.Errorf("RenewTaskLease error = %v, want ErrLeaseHeld", err)
+	}
+
+	// Releasing a stolen lease leaves the new holder in place.
+	if err := ws.ReleaseTaskLease(lease); err != nil {
+		t.Fatalf("ReleaseTaskLease: %v", err)
+	}
+	if loaded, _ := ws.LoadTaskLease("task1"); loaded == nil || loaded.Owner != "alice" {
+		t.Errorf("foreign lease should survive release, got %+v", loaded)
+	}
+}
+
+func TestStealTaskLease(t *testing.T) {
+	tests := []struct {
+		name    string
+		expires time.Duration
+		force   bool
+		wantErr bool
+	}{
+		{name: "expired lease", expires: -time.Second},
+		{name: "live lease without force", expires: time.Hour, wantErr: true},
+		{name: "live lease with force", expires: time.Hour, force: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws, _ := OpenWorkspace(t.TempDir(), nil)
+			foreignLease(t, ws, "task1", time.Now().Add(tt.expires))
+
+			previous, err := ws.StealTaskLease("task1", time.Minute, tt.force)
+			if tt.wantErr {
+				if !errors.Is(err, ErrLeaseHeld) {
+					t.Errorf("StealTaskLease error = %v, want ErrLeaseHeld", err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("StealTaskLease: %v", err)
+			}
+			if previous == nil || previous.Owner != "alice" {
+				t.Errorf("previous = %+v, want alice's lease", previous)
+			}
+
+			// The reservation can be claimed by this process.
+			if _, err := ws.AcquireTaskLease("task1", time.Minute); err != nil {
+				t.Errorf("AcquireTaskLease after steal: %v", err)
+			}
+		})
+	}
+}
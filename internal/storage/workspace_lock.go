@@ -8,6 +8,20 @@ import (
 
 const locksDirName = "locks"
 
+// DefaultLockTimeout is how long workspace writes wait for a lock held by
+// another process before failing with ErrLocked.
+const DefaultLockTimeout = 10 * time.Second
+
+// TaskLock is an exclusive lock on a task, held until Unlock. Writes to the
+// task through the Workspace holding it proceed; writes from other processes
+// wait for it, and fail with ErrLocked naming the holder when it is not
+// released in time.
+type TaskLock struct {
+	ws     *Workspace
+	taskID string
+	lock   *FileLock
+}
+
 // LocksDir returns the path to the locks directory.
 func (w *Workspace) LocksDir() string {
 	return filepath.Join(w.taskRoot, locksDirName)
@@ -18,15 +32,70 @@ func (w *Workspace) TaskLockPath(taskID string) string {
 	return filepath.Join(w.LocksDir(), taskID+".lock")
 }
 
+// activeTaskLockPath returns the path to the lock file guarding .active_task.
+func (w *Workspace) activeTaskLockPath() string {
+	return filepath.Join(w.LocksDir(), "active_task.lock")
+}
+
+// Lock takes the lock on a task for a sequence of operations that must not
+// interleave with other processes, waiting up to DefaultLockTimeout. A lock
+// left behind by a process that died is taken over.
+func (w *Workspace) Lock(taskID string) (*TaskLock, error) {
+	if w.holdsTaskLock(taskID) {
+		return nil, fmt.Errorf("task %s is already locked by this process", taskID)
+	}
+
+	lock := NewFileLock(w.TaskLockPath(taskID))
+	if err := lock.LockWithTimeout(DefaultLockTimeout); err != nil {
+		return nil, fmt.Errorf("lock task %s: %w", taskID, err)
+	}
+
+	held := &TaskLock{ws: w, taskID: taskID, lock: lock}
+	w.lockMu.Lock()
+	if w.taskLocks == nil {
+		w.taskLocks = make(map[string]*TaskLock)
+	}
+	w.taskLocks[taskID] = held
+	w.lockMu.Unlock()
+
+	return held, nil
+}
+
+// Unlock releases the task lock. Unlocking twice is a no-op.
+func (l *TaskLock) Unlock() error {
+	l.ws.lockMu.Lock()
+	if l.ws.taskLocks[l.taskID] == l {
+		delete(l.ws.taskLocks, l.taskID)
+	}
+	l.ws.lockMu.Unlock()
+
+	return l.lock.Unlock()
+}
+
+func (w *Workspace) holdsTaskLock(taskID string) bool {
+	w.lockMu.Lock()
+	defer w.lockMu.Unlock()
+
+	_, ok := w.taskLocks[taskID]
+
+	return ok
+}
+
 // WithTaskLock executes a function while holding an exclusive lock on the task.
 // This prevents concurrent processes from modifying the same task simultaneously.
+// It waits up to DefaultLockTimeout for another process to release the lock,
+// and runs fn right away when this Workspace already holds it (see Lock).
 func (w *Workspace) WithTaskLock(taskID string, fn func() error) error {
-	return WithLock(w.TaskLockPath(taskID), fn)
+	return w.WithTaskLockTimeout(taskID, DefaultLockTimeout, fn)
 }
 
 // WithTaskLockTimeout executes a function while holding a task lock,
 // with a timeout for acquiring the lock.
 func (w *Workspace) WithTaskLockTimeout(taskID string, timeout time.Duration, fn func() error) error {
+	if w.holdsTaskLock(taskID) {
+		return fn()
+	}
+
 	return WithLockTimeout(w.TaskLockPath(taskID), timeout, fn)
 }
 
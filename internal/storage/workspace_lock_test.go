@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWorkspaceLock(t *testing.T) {
+	ws := newBundleWorkspace(t)
+	work, err := ws.CreateWork("task1", SourceInfo{Type: "file", Ref: "task.md"})
+	if err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+
+	lock, err := ws.Lock("task1")
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if _, err := ws.Lock("task1"); err == nil {
+		t.Error("Lock of a task this workspace already holds succeeded")
+	}
+
+	// The holding workspace keeps writing
+	done := make(chan error, 1)
+	go func() { done <- ws.SaveWork(work) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("SaveWork while holding the lock: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("SaveWork blocked on a lock held by its own workspace")
+	}
+
+	// Another process (another Workspace) has to wait
+	other, _ := OpenWorkspace(ws.Root(), nil)
+	err = other.WithTaskLockTimeout("task1", 50*time.Millisecond, func() error { return nil })
+	if !errors.Is(err, ErrLocked) {
+		t.Errorf("WithTaskLockTimeout error = %v, want ErrLocked", err)
+	}
+
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Errorf("second Unlock: %v", err)
+	}
+	if err := other.SaveWork(work); err != nil {
+		t.Errorf("SaveWork after Unlock: %v", err)
+	}
+}
+
+func TestActiveTaskWritesLocked(t *testing.T) {
+	ws := newBundleWorkspace(t)
+	if err := ws.SaveActiveTask(&ActiveTask{ID: "task1", State: "idle"}); err != nil {
+		t.Fatalf("SaveActiveTask: %v", err)
+	}
+
+	held := NewFileLock(ws.activeTaskLockPath())
+	if err := held.Lock(); err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- ws.UpdateActiveTaskState("planning") }()
+	select {
+	case err := <-done:
+		t.Fatalf("UpdateActiveTaskState finished while the lock was held: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	_ = held.Unlock()
+	if err := <-done; err != nil {
+		t.Fatalf("UpdateActiveTaskState: %v", err)
+	}
+	if active, _ := ws.LoadActiveTask(); active.State != "planning" {
+		t.Errorf("State = %q, want planning", active.State)
+	}
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/valksor/go-mehrhof/internal/storage/migrate"
+)
+
+const (
+	// WorkSchemaVersion is the work.yaml schema version this build reads and writes.
+	WorkSchemaVersion = "1"
+
+	// SessionSchemaVersion is the session file schema version this build reads and writes.
+	SessionSchemaVersion = "1"
+)
+
+// MigrationChange describes a storage file written by an older version of
+// mehrhof and the migrations that upgrade it.
+type MigrationChange struct {
+	Path  string   // Relative to the repository root
+	Kind  string   // migrate.KindWork or migrate.KindSession
+	From  string   // "" for files written before versioning
+	To    string   // Current schema version
+	Steps []string // Descriptions of the migrations, in order
+}
+
+// Migrate upgrades every work.yaml and session file of the workspace to the
+// current schema versions, writing each upgraded file atomically. With
+// dryRun nothing is written. It returns the files that need (or got) an
+// upgrade.
+func (w *Workspace) Migrate(dryRun bool) ([]MigrationChange, error) {
+	taskIDs, err := w.ListWorks()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []MigrationChange
+	for _, taskID := range taskIDs {
+		change, err := migrateFile(w, taskID, filepath.Join(w.WorkPath(taskID), workFileName), migrate.KindWork, WorkSchemaVersion, &TaskWork{}, dryRun)
+		if err != nil {
+			return changes, err
+		}
+		if change != nil {
+			changes = append(changes, *change)
+		}
+
+		files, err := w.ListSessionFiles(taskID)
+		if err != nil {
+			return changes, err
+		}
+		for _, name := range files {
+			change, err := migrateFile(w, taskID, w.SessionPath(taskID, name), migrate.KindSession, SessionSchemaVersion, &Session{}, dryRun)
+			if err != nil {
+				return changes, err
+			}
+			if change != nil {
+				changes = append(changes, *change)
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// migrateFile upgrades one storage file, decoding it into v. Returns nil
+// when the file is current.
+func migrateFile(w *Workspace, taskID, path, kind, version string, v any, dryRun bool) (*MigrationChange, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rel, relErr := filepath.Rel(w.root, path)
+	if relErr != nil {
+		rel = path
+	}
+
+	var applied []migrate.Migration
+	if dryRun {
+		from, err := migrate.Version(data)
+		if err == nil {
+			applied, err = migrate.Default.Plan(kind, from, version)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rel, err)
+		}
+	} else {
+		if applied, err = upgradeDocument(kind, data, version, v); err != nil {
+			return nil, fmt.Errorf("%s: %w", rel, err)
+		}
+	}
+	if len(applied) == 0 {
+		return nil, nil //nolint:nilnil // file is current
+	}
+
+	change := &MigrationChange{Path: rel, Kind: kind, From: applied[0].From, To: version}
+	for _, m := range applied {
+		change.Steps = append(change.Steps, m.Description)
+	}
+	if !dryRun {
+		if err := w.writeMigrated(taskID, path, v); err != nil {
+			return nil, fmt.Errorf("%s: %w", rel, err)
+		}
+	}
+
+	return change, nil
+}
+
+// upgradeDocument decodes a versioned storage file into v, first applying
+// the registered migrations from its version to version. It returns the
+// migrations applied.
+func upgradeDocument(kind string, data []byte, version string, v any) ([]migrate.Migration, error) {
+	upgraded, applied, err := migrate.Default.Upgrade(kind, data, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(upgraded, v); err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// canUpgradeWork reports whether a work.yaml of the given schema version
+// can be read, directly or after migration.
+func canUpgradeWork(version string) bool {
+	_, err := migrate.Default.Plan(migrate.KindWork, version, WorkSchemaVersion)
+
+	return err == nil
+}
+
+// saveMigrated writes a file upgraded while loading it. A failed write is
+// logged only: the upgraded data is still returned to the caller, and the
+// file is upgraded again on the next load.
+func (w *Workspace) saveMigrated(taskID, path string, v any, applied []migrate.Migration) {
+	if err := w.writeMigrated(taskID, path, v); err != nil {
+		slog.Warn("failed to save migrated storage file", "path", path, "error", err)
+
+		return
+	}
+	slog.Info("migrated storage file", "path", path, "from", applied[0].From, "to", applied[len(applied)-1].To)
+}
+
+// writeMigrated writes an upgraded storage file atomically under the task lock.
+func (w *Workspace) writeMigrated(taskID, path string, v any) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal migrated file: %w", err)
+	}
+
+	return w.WithTaskLock(taskID, func() error {
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0o644); err != nil {
+			return fmt.Errorf("write migrated file: %w", err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			_ = os.Remove(tmp)
+
+			return fmt.Errorf("write migrated file: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeUnversionedTask writes a work.yaml and session file the way mehrhof
+// did before they carried a schema version.
+func writeUnversionedTask(t *testing.T, ws *Workspace) {
+	t.Helper()
+
+	if err := os.MkdirAll(ws.SessionsDir("old1"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	work := "metadata:\n  id: old1\n  title: Old task\nsource:\n  type: file\n  ref: task.md\n"
+	if err := os.WriteFile(filepath.Join(ws.WorkPath("old1"), workFileName), []byte(work), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	session := "metadata:\n  type: planning\n  agent: claude\n"
+	if err := os.WriteFile(ws.SessionPath("old1", "planning.yaml"), []byte(session), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadWork_Migrates(t *testing.T) {
+	ws := newBundleWorkspace(t)
+	writeUnversionedTask(t, ws)
+
+	work, err := ws.LoadWork("old1")
+	if err != nil {
+		t.Fatalf("LoadWork: %v", err)
+	}
+	if work.Version != WorkSchemaVersion || work.Metadata.Title != "Old task" {
+		t.Errorf("work = version %q, title %q", work.Version, work.Metadata.Title)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(ws.WorkPath("old1"), workFileName))
+	if !strings.Contains(string(data), "version: \"1\"") {
+		t.Errorf("work.yaml not rewritten:\n%s", data)
+	}
+
+	session, err := ws.LoadSession("old1", "planning.yaml")
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if session.Version != SessionSchemaVersion || session.Kind != "Session" {
+		t.Errorf("session = version %q, kind %q", session.Version, session.Kind)
+	}
+}
+
+func TestLoadWork_NewerVersion(t *testing.T) {
+	ws := newBundleWorkspace(t)
+	if err := os.MkdirAll(ws.WorkPath("new1"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ws.WorkPath("new1"), workFileName), []byte("version: \"99\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ws.LoadWork("new1"); err == nil {
+		t.Error("LoadWork read a work.yaml from a newer schema version")
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	ws := newBundleWorkspace(t)
+	writeUnversionedTask(t, ws)
+	if _, err := ws.CreateWork("current", SourceInfo{Type: "file", Ref: "task.md"}); err != nil {
+		t.Fatal(err)
+	}
+	workFile := filepath.Join(ws.WorkPath("old1"), workFileName)
+	before, _ := os.ReadFile(workFile)
+
+	changes, err := ws.Migrate(true)
+	if err != nil {
+		t.Fatalf("Migrate dry run: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("dry run changes = %+v, want work.yaml and session of old1", changes)
+	}
+	if changes[0].Path != filepath.Join(".mehrhof", "work", "old1", workFileName) || changes[0].From != "" || changes[0].To != WorkSchemaVersion || len(changes[0].Steps) != 1 {
+		t.Errorf("change = %+v", changes[0])
+	}
+	if after, _ := os.ReadFile(workFile); string(after) != string(before) {
+		t.Error("dry run rewrote work.yaml")
+	}
+
+	if changes, err = ws.Migrate(false); err != nil || len(changes) != 2 {
+		t.Fatalf("Migrate = %d changes, %v", len(changes), err)
+	}
+	if changes, err = ws.Migrate(true); err != nil || len(changes) != 0 {
+		t.Errorf("Migrate after upgrade = %+v, %v, want nothing left", changes, err)
+	}
+}
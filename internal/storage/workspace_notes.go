@@ -1,48 +1,118 @@
 package storage
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 )
 
+// noteEntryTimeFormat prefixes note entry filenames so they sort in the order
+// they were written, regardless of who wrote them.
+const noteEntryTimeFormat = "20060102T150405.000000000Z"
+
 // NotesPath returns the path to notes.md.
 func (w *Workspace) NotesPath(taskID string) string {
 	return filepath.Join(w.WorkPath(taskID), notesFileName)
 }
 
-// AppendNote adds a note to notes.md.
-func (w *Workspace) AppendNote(taskID, content, state string) error {
-	notesPath := w.NotesPath(taskID)
+// NotesDir returns the directory holding one file per note entry.
+func (w *Workspace) NotesDir(taskID string) string {
+	return filepath.Join(w.WorkPath(taskID), notesDirName)
+}
 
-	// Read existing content
-	existing, _ := os.ReadFile(notesPath)
+// AppendNote adds a note to the task. Each note is written to its own file in
+// the notes directory instead of being appended to notes.md, so notes added
+// concurrently from different worktrees or users never conflict in git.
+func (w *Workspace) AppendNote(taskID, content, state string) error {
+	notesDir := w.NotesDir(taskID)
+	if err := os.MkdirAll(notesDir, 0o755); err != nil {
+		return fmt.Errorf("create notes directory: %w", err)
+	}
 
-	// Format new note
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	now := time.Now()
 	stateTag := ""
 	if state != "" {
 		stateTag = fmt.Sprintf(" [%s]", state)
 	}
-	newNote := fmt.Sprintf("\n## %s%s\n\n%s\n", timestamp, stateTag, content)
+	entry := fmt.Sprintf("## %s%s\n\n%s\n", now.Format("2006-01-02 15:04:05"), stateTag, content)
 
-	// Use strings.Builder for efficient concatenation
-	var b strings.Builder
-	b.Grow(len(existing) + len(newNote))
-	b.Write(existing)
-	b.WriteString(newNote)
+	name := now.UTC().Format(noteEntryTimeFormat) + "-" + noteEntrySuffix() + ".md"
 
-	return os.WriteFile(notesPath, []byte(b.String()), 0o644)
+	if err := os.WriteFile(filepath.Join(notesDir, name), []byte(entry), 0o644); err != nil {
+		return err
+	}
+	w.indexNotes(taskID)
+
+	return nil
 }
 
-// ReadNotes reads the notes file content.
+// ReadNotes returns the task's notes: notes.md, which holds the header and
+// notes written before per-entry storage, followed by every note entry in
+// the order it was written.
 func (w *Workspace) ReadNotes(taskID string) (string, error) {
-	data, err := os.ReadFile(w.NotesPath(taskID))
+	legacy, legacyErr := os.ReadFile(w.NotesPath(taskID))
+	if legacyErr != nil && !errors.Is(legacyErr, os.ErrNotExist) {
+		return "", legacyErr
+	}
+
+	entries, err := w.noteEntries(taskID)
 	if err != nil {
 		return "", err
 	}
+	if legacyErr != nil && len(entries) == 0 {
+		return "", legacyErr
+	}
+
+	var b strings.Builder
+	b.Write(legacy)
+	for _, name := range entries {
+		data, err := os.ReadFile(filepath.Join(w.NotesDir(taskID), name))
+		if err != nil {
+			continue
+		}
+		b.WriteString("\n")
+		b.Write(data)
+	}
+
+	return b.String(), nil
+}
+
+// noteEntries returns the note entry filenames, oldest first.
+func (w *Workspace) noteEntries(taskID string) ([]string, error) {
+	dirEntries, err := os.ReadDir(w.NotesDir(taskID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("read notes directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range dirEntries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	slices.Sort(names)
+
+	return names, nil
+}
+
+// noteEntrySuffix returns a random suffix that keeps entry filenames unique
+// when two notes are written in the same instant.
+func noteEntrySuffix() string {
+	bytes := make([]byte, 4)
+	if _, err := rand.Read(bytes); err != nil {
+		return fmt.Sprintf("%08x", time.Now().UnixNano()&0xffffffff)
+	}
 
-	return string(data), nil
+	return hex.EncodeToString(bytes)
 }
@@ -0,0 +1,258 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	planDocumentFileName = "plan.md"
+	planItemsDirName     = "items"
+
+	// planOutcomePrefix starts the line recording an item's last outcome.
+	planOutcomePrefix = "> mehr:"
+
+	// planDependsPrefix starts the line listing the items an item depends on.
+	planDependsPrefix = "depends:"
+)
+
+// ErrNoPlanItems is returned when a plan document has no task list items.
+var ErrNoPlanItems = errors.New("plan document has no task list items")
+
+// PlanItem is one task list item of a plan document. Each item becomes a
+// task when the plan is executed.
+type PlanItem struct {
+	Number      int    // 1-based position in the document
+	Title       string // Text of the task list line
+	Description string // Indented lines below the item
+	DependsOn   []int  // Items that must be done first, from a "depends: 1, 2" line
+	Done        bool   // Checked off
+	Outcome     string // Last recorded outcome, without the marker
+
+	line int // Index of the item's line in the document
+}
+
+// PlanItemOutcome is the result of executing one plan item.
+type PlanItemOutcome struct {
+	Done     bool   // The item's task finished
+	TaskID   string // Task created for the item, if any
+	FailedAt string // Phase the run stopped in
+	Error    string
+	CostUSD  float64
+	At       time.Time
+}
+
+// String renders the outcome as recorded in the plan document.
+func (o PlanItemOutcome) String() string {
+	var sb strings.Builder
+	if o.Done {
+		sb.WriteString("done")
+	} else {
+		sb.WriteString("failed")
+		if o.FailedAt != "" {
+			sb.WriteString(" at " + o.FailedAt)
+		}
+	}
+	if o.TaskID != "" {
+		sb.WriteString(", task " + o.TaskID)
+	}
+	if o.CostUSD > 0 {
+		fmt.Fprintf(&sb, ", $%.2f", o.CostUSD)
+	}
+	if !o.At.IsZero() {
+		sb.WriteString(", " + o.At.Format("2006-01-02 15:04"))
+	}
+	if o.Error != "" {
+		// Keep the outcome on one line
+		sb.WriteString(": " + strings.Join(strings.Fields(o.Error), " "))
+	}
+
+	return sb.String()
+}
+
+// PlanDocumentPath returns the path of a plan's document, plan.md, whose
+// task list items are executed by 'mehr plan execute'.
+func (w *Workspace) PlanDocumentPath(planID string) string {
+	return filepath.Join(w.PlannedPath(planID), planDocumentFileName)
+}
+
+// LoadPlanItems reads the task list items of a plan's document.
+func (w *Workspace) LoadPlanItems(planID string) ([]PlanItem, error) {
+	data, err := os.ReadFile(w.PlanDocumentPath(planID))
+	if err != nil {
+		return nil, fmt.Errorf("read plan document: %w", err)
+	}
+
+	items := ParsePlanItems(string(data))
+	if len(items) == 0 {
+		return nil, ErrNoPlanItems
+	}
+
+	return items, nil
+}
+
+// ParsePlanItems reads the top-level task list items ("- [ ] ...") of a plan
+// document. Lines indented below an item are its description.
+func ParsePlanItems(content string) []PlanItem {
+	var items []PlanItem
+	var body []string
+
+	flush := func() {
+		if len(items) > 0 {
+			items[len(items)-1].Description = strings.TrimSpace(strings.Join(body, "\n"))
+		}
+		body = nil
+	}
+	inItem := false
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if title, checked, ok := parsePlanItemLine(line); ok {
+			flush()
+			items = append(items, PlanItem{Number: len(items) + 1, Title: title, Done: checked, line: i})
+			inItem = true
+
+			continue
+		}
+		if !inItem {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			body = append(body, "")
+		case line[0] != ' ' && line[0] != '\t':
+			// An unindented line ends the item
+			flush()
+			inItem = false
+		case strings.HasPrefix(trimmed, planOutcomePrefix):
+			items[len(items)-1].Outcome = strings.TrimSpace(strings.TrimPrefix(trimmed, planOutcomePrefix))
+		case parsePlanDepends(trimmed) != nil:
+			items[len(items)-1].DependsOn = append(items[len(items)-1].DependsOn, parsePlanDepends(trimmed)...)
+		default:
+			body = append(body, trimmed)
+		}
+	}
+	if inItem {
+		flush()
+	}
+
+	return items
+}
+
+// parsePlanItemLine parses an unindented "- [ ] title" or "* [x] title" line.
+func parsePlanItemLine(line string) (string, bool, bool) {
+	if len(line) < 6 || (line[0] != '-' && line[0] != '*') || line[1] != ' ' || line[2] != '[' || line[4] != ']' {
+		return "", false, false
+	}
+	var checked bool
+	switch line[3] {
+	case ' ':
+	case 'x', 'X':
+		checked = true
+	default:
+		return "", false, false
+	}
+	title := strings.TrimSpace(line[5:])
+	if title == "" {
+		return "", false, false
+	}
+
+	return title, checked, true
+}
+
+// parsePlanDepends parses a "depends: 1, #3" line into item numbers. It
+// returns nil for any other line.
+func parsePlanDepends(line string) []int {
+	if len(line) < len(planDependsPrefix) || !strings.EqualFold(line[:len(planDependsPrefix)], planDependsPrefix) {
+		return nil
+	}
+
+	fields := strings.FieldsFunc(line[len(planDependsPrefix):], func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	numbers := make([]int, 0, len(fields))
+	for _, field := range fields {
+		n, err := strconv.Atoi(strings.TrimPrefix(field, "#"))
+		if err != nil || n < 1 {
+			return nil
+		}
+		numbers = append(numbers, n)
+	}
+	if len(numbers) == 0 {
+		return nil
+	}
+
+	return numbers
+}
+
+// RecordPlanItemOutcome writes an item's outcome below it in the plan
+// document, replacing an earlier one, and checks the item off when it is
+// done.
+func (w *Workspace) RecordPlanItemOutcome(planID string, number int, outcome PlanItemOutcome) error {
+	path := w.PlanDocumentPath(planID)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read plan document: %w", err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	items := ParsePlanItems(string(data))
+	if number < 1 || number > len(items) {
+		return fmt.Errorf("plan item %d not found", number)
+	}
+	item := items[number-1]
+
+	if outcome.Done {
+		lines[item.line] = lines[item.line][:3] + "x" + lines[item.line][4:]
+	}
+
+	// The item ends before the next unindented line or trailing blank lines
+	end := item.line + 1
+	for end < len(lines) && (strings.TrimSpace(lines[end]) == "" || lines[end][0] == ' ' || lines[end][0] == '\t') {
+		end++
+	}
+	for end > item.line+1 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+
+	record := "  " + planOutcomePrefix + " " + outcome.String()
+	replaced := false
+	for i := item.line + 1; i < end; i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), planOutcomePrefix) {
+			lines[i] = record
+			replaced = true
+
+			break
+		}
+	}
+	if !replaced {
+		lines = append(lines[:end], append([]string{record}, lines[end:]...)...)
+	}
+
+	return writeFileAtomic(path, []byte(strings.Join(lines, "\n")))
+}
+
+// WritePlanItemSource writes a plan item as a markdown task file in the
+// plan's items/ directory and returns its path.
+func (w *Workspace) WritePlanItemSource(planID string, item PlanItem) (string, error) {
+	dir := filepath.Join(w.PlannedPath(planID), planItemsDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create plan items directory: %w", err)
+	}
+
+	content := "# " + item.Title + "\n"
+	if item.Description != "" {
+		content += "\n" + item.Description + "\n"
+	}
+	path := filepath.Join(dir, strconv.Itoa(item.Number)+".md")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return "", fmt.Errorf("write plan item: %w", err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPlanDocument = `# Auth rollout
+
+Some context that is not an item.
+
+- [ ] Add the session store
+  Store sessions in redis.
+  Expire them after a day.
+
+- [x] Add login endpoint
+  > mehr: done, task a1b2c3d4
+* [ ] Add logout endpoint
+  Depends: 1, #2
+  depends: on the login work
+
+## Notes
+  - [ ] indented lists are not items
+`
+
+func TestParsePlanItems(t *testing.T) {
+	items := ParsePlanItems(testPlanDocument)
+	if len(items) != 3 {
+		t.Fatalf("len(items) = %d, want 3: %+v", len(items), items)
+	}
+
+	if items[0].Title != "Add the session store" || items[0].Done {
+		t.Errorf("items[0] = %+v", items[0])
+	}
+	if want := "Store sessions in redis.\nExpire them after a day."; items[0].Description != want {
+		t.Errorf("items[0].Description = %q, want %q", items[0].Description, want)
+	}
+	if !items[1].Done || items[1].Outcome != "done, task a1b2c3d4" || items[1].Description != "" {
+		t.Errorf("items[1] = %+v", items[1])
+	}
+	if items[2].Number != 3 || items[2].Title != "Add logout endpoint" {
+		t.Errorf("items[2] = %+v", items[2])
+	}
+	if !slices.Equal(items[2].DependsOn, []int{1, 2}) || items[2].Description != "depends: on the login work" {
+		t.Errorf("items[2] depends on %v, description %q", items[2].DependsOn, items[2].Description)
+	}
+}
+
+func TestRecordPlanItemOutcome(t *testing.T) {
+	ws, err := OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.CreatePlan("p1", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.LoadPlanItems("p1"); err == nil {
+		t.Error("LoadPlanItems without plan.md: want error")
+	}
+	if err := os.WriteFile(ws.PlanDocumentPath("p1"), []byte(testPlanDocument), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)
+	failed := PlanItemOutcome{TaskID: "t1", FailedAt: "implementation", Error: "agent\nexited", At: at}
+	if err := ws.RecordPlanItemOutcome("p1", 1, failed); err != nil {
+		t.Fatalf("RecordPlanItemOutcome: %v", err)
+	}
+	if err := ws.RecordPlanItemOutcome("p1", 1, PlanItemOutcome{Done: true, TaskID: "t2", CostUSD: 1.2, At: at}); err != nil {
+		t.Fatalf("RecordPlanItemOutcome: %v", err)
+	}
+	if err := ws.RecordPlanItemOutcome("p1", 3, failed); err != nil {
+		t.Fatalf("RecordPlanItemOutcome: %v", err)
+	}
+	if err := ws.RecordPlanItemOutcome("p1", 4, failed); err == nil {
+		t.Error("RecordPlanItemOutcome(4): want error")
+	}
+
+	data, err := os.ReadFile(ws.PlanDocumentPath("p1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := string(data)
+	for _, want := range []string{
+		"- [x] Add the session store\n  Store sessions in redis.\n  Expire them after a day.\n  > mehr: done, task t2, $1.20, 2026-10-15 09:30\n\n- [x] Add login",
+		"  depends: on the login work\n  > mehr: failed at implementation, task t1, 2026-10-15 09:30: agent exited\n\n## Notes",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("plan document missing %q:\n%s", want, doc)
+		}
+	}
+
+	items, err := ws.LoadPlanItems("p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !items[0].Done || items[0].Outcome != "done, task t2, $1.20, 2026-10-15 09:30" || strings.Contains(items[0].Description, "mehr:") {
+		t.Errorf("items[0] = %+v", items[0])
+	}
+
+	if err := os.WriteFile(ws.PlanDocumentPath("p1"), []byte("# Empty\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.LoadPlanItems("p1"); !errors.Is(err, ErrNoPlanItems) {
+		t.Errorf("LoadPlanItems = %v, want ErrNoPlanItems", err)
+	}
+}
+
+func TestWritePlanItemSource(t *testing.T) {
+	ws, err := OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := ws.WritePlanItemSource("p1", PlanItem{Number: 2, Title: "Add logout", Description: "Clear the cookie."})
+	if err != nil {
+		t.Fatalf("WritePlanItemSource: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Add logout\n\nClear the cookie.\n"; string(data) != want {
+		t.Errorf("content = %q, want %q", data, want)
+	}
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// PRTemplateName is the name of the workspace template in .mehrhof/templates
+// that overrides the repository's pull request template.
+const PRTemplateName = "pr"
+
+// repoPRTemplates are the pull and merge request templates GitHub and GitLab
+// pick up, relative to the repository root, in order of precedence.
+var repoPRTemplates = []string{
+	".github/PULL_REQUEST_TEMPLATE.md",
+	".github/pull_request_template.md",
+	"PULL_REQUEST_TEMPLATE.md",
+	"pull_request_template.md",
+	"docs/PULL_REQUEST_TEMPLATE.md",
+	"docs/pull_request_template.md",
+	".gitlab/merge_request_templates/Default.md",
+	".gitlab/merge_request_templates/default.md",
+}
+
+// LoadPRTemplate returns the template for generated pull request bodies and
+// the path it was read from: .mehrhof/templates/pr.md when present, otherwise
+// the repository's own pull or merge request template. Both are empty when
+// there is none.
+func (w *Workspace) LoadPRTemplate() (string, string, error) {
+	paths := []string{w.SpecTemplatePath(PRTemplateName)}
+	for _, rel := range repoPRTemplates {
+		paths = append(paths, filepath.Join(w.root, rel))
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("read PR template: %w", err)
+		}
+
+		return string(data), path, nil
+	}
+
+	return "", "", nil
+}
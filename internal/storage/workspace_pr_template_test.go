@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadPRTemplate(t *testing.T) {
+	root := t.TempDir()
+	ws, err := OpenWorkspace(root, nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+
+	if tmpl, path, err := ws.LoadPRTemplate(); err != nil || tmpl != "" || path != "" {
+		t.Fatalf("LoadPRTemplate() without templates = %q, %q, %v", tmpl, path, err)
+	}
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	gitlab := filepath.Join(root, ".gitlab", "merge_request_templates", "Default.md")
+	write(gitlab, "## What does this MR do?\n")
+	if tmpl, path, _ := ws.LoadPRTemplate(); path != gitlab || tmpl != "## What does this MR do?\n" {
+		t.Errorf("LoadPRTemplate() = %q from %q, want the GitLab template", tmpl, path)
+	}
+
+	github := filepath.Join(root, ".github", "PULL_REQUEST_TEMPLATE.md")
+	write(github, "## Description\n")
+	if _, path, _ := ws.LoadPRTemplate(); path != github {
+		t.Errorf("LoadPRTemplate() path = %q, want the GitHub template", path)
+	}
+
+	override := ws.SpecTemplatePath(PRTemplateName)
+	write(override, "{summary}\n")
+	if tmpl, path, _ := ws.LoadPRTemplate(); path != override || tmpl != "{summary}\n" {
+		t.Errorf("LoadPRTemplate() = %q from %q, want the workspace override", tmpl, path)
+	}
+
+	names, err := ws.ListSpecTemplates()
+	if err != nil {
+		t.Fatalf("ListSpecTemplates: %v", err)
+	}
+	if slices.Contains(names, PRTemplateName) {
+		t.Errorf("ListSpecTemplates() = %v, should not list the PR template", names)
+	}
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// backupSuffix is appended to a YAML file's name for the copy of its last
+// readable version, kept on every save.
+const backupSuffix = ".bak"
+
+// ErrNoBackup is returned by RestoreBackup when a file has no readable backup.
+var ErrNoBackup = errors.New("no readable backup")
+
+// BackupPath returns the path of the backup kept for path.
+func BackupPath(path string) string {
+	return path + backupSuffix
+}
+
+// keepBackup copies the file at path to its backup before it is overwritten,
+// if it is readable YAML, so the backup is always the last good version.
+// Failures are logged: a missing backup must not fail the save.
+func keepBackup(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil || !isYAML(data) {
+		return
+	}
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(BackupPath(path), data, mode); err != nil {
+		slog.Warn("failed to write backup", "path", BackupPath(path), "error", err)
+	}
+}
+
+// isYAML reports whether data parses as YAML.
+func isYAML(data []byte) bool {
+	var node yaml.Node
+
+	return yaml.Unmarshal(data, &node) == nil
+}
+
+// RestoreBackup replaces the file at path with its backup. It returns
+// ErrNoBackup when there is no backup or the backup does not parse.
+func RestoreBackup(path string) error {
+	data, err := os.ReadFile(BackupPath(path))
+	if err != nil || !isYAML(data) {
+		return fmt.Errorf("%w for %s", ErrNoBackup, filepath.Base(path))
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("restore %s: %w", filepath.Base(path), err)
+	}
+
+	return nil
+}
+
+// MissingWorkFiles returns the directories and files of a task's work
+// directory that CreateWork creates and that are missing, relative to the
+// work directory.
+func (w *Workspace) MissingWorkFiles(taskID string) []string {
+	var missing []string
+	for _, name := range []string{specsDirName, sessionsDirName, notesFileName} {
+		if _, err := os.Stat(filepath.Join(w.WorkPath(taskID), name)); os.IsNotExist(err) {
+			missing = append(missing, name)
+		}
+	}
+
+	return missing
+}
+
+// RepairWorkDir recreates the missing directories and notes file of a
+// task's work directory, and returns what it created.
+func (w *Workspace) RepairWorkDir(taskID string) ([]string, error) {
+	missing := w.MissingWorkFiles(taskID)
+	for _, name := range missing {
+		path := filepath.Join(w.WorkPath(taskID), name)
+		var err error
+		if name == notesFileName {
+			err = os.WriteFile(path, []byte("# Notes\n\n"), 0o644)
+		} else {
+			err = os.MkdirAll(path, 0o755)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("recreate %s: %w", name, err)
+		}
+	}
+
+	return missing, nil
+}
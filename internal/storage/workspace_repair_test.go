@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSaveWork_KeepsBackup(t *testing.T) {
+	ws, err := OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	work, err := ws.CreateWork("task-1", SourceInfo{Type: "file", Ref: "task.md"})
+	if err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	workFile := filepath.Join(ws.WorkPath("task-1"), workFileName)
+	if _, err := os.Stat(BackupPath(workFile)); !os.IsNotExist(err) {
+		t.Fatalf("backup written for a new file: %v", err)
+	}
+
+	work.Metadata.Title = "First"
+	if err := ws.SaveWork(work); err != nil {
+		t.Fatalf("SaveWork: %v", err)
+	}
+	if _, err := os.Stat(BackupPath(workFile)); err != nil {
+		t.Fatalf("no backup after save: %v", err)
+	}
+
+	// A malformed file is not backed up over the last good version
+	if err := os.WriteFile(workFile, []byte("metadata: [unclosed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	work.Metadata.Title = "Second"
+	if err := ws.SaveWork(work); err != nil {
+		t.Fatalf("SaveWork: %v", err)
+	}
+	if data, err := os.ReadFile(BackupPath(workFile)); err != nil || !isYAML(data) {
+		t.Fatalf("backup replaced by the malformed file: %v", err)
+	}
+	work.Metadata.Title = "Third"
+	if err := ws.SaveWork(work); err != nil {
+		t.Fatalf("SaveWork: %v", err)
+	}
+	if err := os.WriteFile(workFile, []byte("metadata: [unclosed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.LoadWork("task-1"); err == nil {
+		t.Fatal("expected malformed work.yaml to fail loading")
+	}
+
+	if err := RestoreBackup(workFile); err != nil {
+		t.Fatalf("RestoreBackup: %v", err)
+	}
+	restored, err := ws.LoadWork("task-1")
+	if err != nil {
+		t.Fatalf("LoadWork after restore: %v", err)
+	}
+	if restored.Metadata.Title != "Second" {
+		t.Errorf("restored title = %q, want the last good version", restored.Metadata.Title)
+	}
+}
+
+func TestRestoreBackup_NoBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "work.yaml")
+	if err := RestoreBackup(path); !errors.Is(err, ErrNoBackup) {
+		t.Errorf("RestoreBackup() = %v, want ErrNoBackup", err)
+	}
+}
+
+func TestRepairWorkDir(t *testing.T) {
+	ws, err := OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if _, err := ws.CreateWork("task-1", SourceInfo{Type: "file", Ref: "task.md"}); err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	if missing := ws.MissingWorkFiles("task-1"); len(missing) != 0 {
+		t.Fatalf("new work dir missing %v", missing)
+	}
+
+	if err := os.RemoveAll(ws.SessionsDir("task-1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(ws.NotesPath("task-1")); err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := ws.RepairWorkDir("task-1")
+	if err != nil {
+		t.Fatalf("RepairWorkDir: %v", err)
+	}
+	if !slices.Equal(created, []string{sessionsDirName, notesFileName}) {
+		t.Errorf("created = %v", created)
+	}
+	if missing := ws.MissingWorkFiles("task-1"); len(missing) != 0 {
+		t.Errorf("still missing %v", missing)
+	}
+}
@@ -1,13 +1,17 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/valksor/go-mehrhof/internal/storage/migrate"
 )
 
 // Session methods
@@ -52,9 +56,13 @@ func (w *Workspace) LoadSession(taskID, filename string) (*Session, error) {
 	}
 
 	var session Session
-	if err := yaml.Unmarshal(data, &session); err != nil {
+	applied, err := upgradeDocument(migrate.KindSession, data, SessionSchemaVersion, &session)
+	if err != nil {
 		return nil, fmt.Errorf("parse session file: %w", err)
 	}
+	if len(applied) > 0 {
+		w.saveMigrated(taskID, sessionFile, &session, applied)
+	}
 
 	return &session, nil
 }
@@ -67,6 +75,7 @@ func (w *Workspace) SaveSession(taskID, filename string, session *Session) error
 	if err != nil {
 		return fmt.Errorf("marshal session: %w", err)
 	}
+	keepBackup(sessionFile)
 
 	return os.WriteFile(sessionFile, data, 0o644)
 }
@@ -98,6 +107,47 @@ func (w *Workspace) ListSessions(taskID string) ([]*Session, error) {
 	return sessions, nil
 }
 
+// ListSessionFiles returns the filenames of a task's sessions, oldest first.
+func (w *Workspace) ListSessionFiles(taskID string) ([]string, error) {
+	entries, err := os.ReadDir(w.SessionsDir(taskID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("read sessions directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
+			continue
+		}
+		files = append(files, entry.Name())
+	}
+	slices.Sort(files)
+
+	return files, nil
+}
+
+// AnnotateSession attaches an annotation to an exchange of a session.
+func (w *Workspace) AnnotateSession(taskID, filename string, annotation Annotation) error {
+	session, err := w.LoadSession(taskID, filename)
+	if err != nil {
+		return err
+	}
+
+	if annotation.Exchange < 1 || annotation.Exchange > len(session.Exchanges) {
+		return fmt.Errorf("exchange %d out of range: session has %d exchange(s)", annotation.Exchange, len(session.Exchanges))
+	}
+	if annotation.CreatedAt.IsZero() {
+		annotation.CreatedAt = time.Now()
+	}
+	session.Annotations = append(session.Annotations, annotation)
+
+	return w.SaveSession(taskID, filename, session)
+}
+
 // GetSourceContent returns combined source content for prompts
 // Reads from actual files in source/ directory (hybrid storage).
 func (w *Workspace) GetSourceContent(taskID string) (string, error) {
@@ -186,6 +236,28 @@ func (w *Workspace) LoadPendingQuestion(taskID string) (*PendingQuestion, error)
 	return &q, nil
 }
 
+// ErrNoPendingQuestion is returned when answering without a pending question.
+var ErrNoPendingQuestion = errors.New("no pending question")
+
+// AnswerPendingQuestion records an answer to the task's pending question as a
+// note and clears the question, so the next planning run continues with it.
+func (w *Workspace) AnswerPendingQuestion(taskID, answer string) error {
+	if !w.HasPendingQuestion(taskID) {
+		return ErrNoPendingQuestion
+	}
+	q, err := w.LoadPendingQuestion(taskID)
+	if err != nil {
+		return fmt.Errorf("load pending question: %w", err)
+	}
+
+	note := fmt.Sprintf("**Q:** %s\n\n**A:** %s", q.Question, answer)
+	if err := w.AppendNote(taskID, note, "answer"); err != nil {
+		return fmt.Errorf("save answer: %w", err)
+	}
+
+	return w.ClearPendingQuestion(taskID)
+}
+
 // ClearPendingQuestion removes the pending question file.
 func (w *Workspace) ClearPendingQuestion(taskID string) error {
 	err := os.Remove(w.PendingQuestionPath(taskID))
@@ -195,3 +267,30 @@ func (w *Workspace) ClearPendingQuestion(taskID string) error {
 
 	return err
 }
+
+// LatestSessionFile returns the filename of the newest session of the given
+// type, or "" when the task has none. Filenames start with the session's
+// start time, so the newest one sorts last.
+func (w *Workspace) LatestSessionFile(taskID, sessionType string) (string, error) {
+	entries, err := os.ReadDir(w.SessionsDir(taskID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("read sessions directory: %w", err)
+	}
+
+	latest := ""
+	suffix := "-" + sessionType + ".yaml"
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), suffix) {
+			continue
+		}
+		if entry.Name() > latest {
+			latest = entry.Name()
+		}
+	}
+
+	return latest, nil
+}
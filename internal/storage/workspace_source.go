@@ -0,0 +1,276 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/textdiff"
+)
+
+const (
+	sourceDirName         = "source"
+	originalSourceDirName = "source-original"
+	sourceDriftFileName   = "source-drift.diff"
+)
+
+// SourceFile is the content of one re-fetched source file.
+type SourceFile struct {
+	Path    string // Relative to source/, slash-separated
+	Content string
+}
+
+// SourceUpdate is a re-fetched task source, as produced by the provider.
+type SourceUpdate struct {
+	Changed []SourceFile               // added or modified files
+	Removed []string                   // paths no longer present in the source
+	States  map[string]SourceFileState // every file of the new revision
+}
+
+// RefreshSource applies a re-fetched source to the task's source/ copy as a
+// new revision. Before the first change the snapshot taken at start is kept
+// in source-original/, and the drift from it is recomputed on every refresh:
+// recorded in work.yaml and written as a unified diff to source-drift.diff.
+// Returns the changes applied by this refresh.
+func (w *Workspace) RefreshSource(taskID string, update SourceUpdate) (*SourceDelta, error) {
+	work, err := w.LoadWork(taskID)
+	if err != nil {
+		return nil, err
+	}
+	source := &work.Source
+	workPath := w.WorkPath(taskID)
+	now := time.Now()
+
+	delta := &SourceDelta{SyncedAt: now, Removed: slices.Clone(update.Removed)}
+	for _, f := range update.Changed {
+		_, known := source.States[f.Path]
+		if known || slices.Contains(source.Files, sourceDirName+"/"+f.Path) {
+			delta.Modified = append(delta.Modified, f.Path)
+		} else {
+			delta.Added = append(delta.Added, f.Path)
+		}
+	}
+	slices.Sort(delta.Added)
+	slices.Sort(delta.Modified)
+	slices.Sort(delta.Removed)
+
+	if !delta.Empty() {
+		if err := preserveOriginalSource(workPath, source.Files); err != nil {
+			return nil, fmt.Errorf("keep original source: %w", err)
+		}
+		if err := applySourceUpdate(workPath, update); err != nil {
+			return nil, err
+		}
+		source.Revision++
+		delta.Revision = source.Revision
+	}
+
+	source.States = make(map[string]SourceFileState, len(update.States))
+	source.Files = make([]string, 0, len(update.States))
+	for path, state := range update.States {
+		source.States[path] = state
+		source.Files = append(source.Files, sourceDirName+"/"+path)
+	}
+	slices.Sort(source.Files)
+	source.ReadAt = now
+	source.Delta = delta
+
+	if err := w.updateSourceDrift(taskID, source, now); err != nil {
+		return nil, err
+	}
+	if err := w.SaveWork(work); err != nil {
+		return nil, fmt.Errorf("save work: %w", err)
+	}
+
+	return delta, nil
+}
+
+// SourceDriftDiff returns the unified diff between the task's original and
+// current source, or "" when the source has not drifted.
+func (w *Workspace) SourceDriftDiff(taskID string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(w.WorkPath(taskID), sourceDriftFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read source drift: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// preserveOriginalSource copies the source files to source-original/ unless
+// an earlier refresh already did.
+func preserveOriginalSource(workPath string, files []string) error {
+	originalDir := filepath.Join(workPath, originalSourceDirName)
+	if _, err := os.Stat(originalDir); err == nil {
+		return nil
+	}
+
+	tmpDir := originalDir + ".tmp"
+	_ = os.RemoveAll(tmpDir)
+	for _, file := range files {
+		rel, ok := strings.CutPrefix(file, sourceDirName+"/")
+		if !ok {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(workPath, filepath.FromSlash(file)))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(tmpDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dest, data, 0o644); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(tmpDir, 0o755); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpDir, originalDir)
+}
+
+// applySourceUpdate writes changed files to source/ and deletes removed ones.
+func applySourceUpdate(workPath string, update SourceUpdate) error {
+	sourceDir := filepath.Join(workPath, sourceDirName)
+	for _, f := range update.Changed {
+		dest := filepath.Join(sourceDir, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return fmt.Errorf("create directory: %w", err)
+		}
+		if err := os.WriteFile(dest, []byte(f.Content), 0o644); err != nil {
+			return fmt.Errorf("write source file %s: %w", f.Path, err)
+		}
+	}
+	for _, path := range update.Removed {
+		if err := os.Remove(filepath.Join(sourceDir, filepath.FromSlash(path))); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove source file %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// updateSourceDrift compares source/ with source-original/ and records the
+// result. A source that changed back to its original clears the drift.
+func (w *Workspace) updateSourceDrift(taskID string, source *SourceInfo, now time.Time) error {
+	workPath := w.WorkPath(taskID)
+	driftPath := filepath.Join(workPath, sourceDriftFileName)
+	originalDir := filepath.Join(workPath, originalSourceDirName)
+	if _, err := os.Stat(originalDir); err != nil {
+		return nil // Never changed since start
+	}
+
+	original, err := readSourceTree(originalDir)
+	if err != nil {
+		return fmt.Errorf("read original source: %w", err)
+	}
+	current, err := readSourceTree(filepath.Join(workPath, sourceDirName))
+	if err != nil {
+		return fmt.Errorf("read source: %w", err)
+	}
+
+	paths := make([]string, 0, len(original)+len(current))
+	for path := range original {
+		paths = append(paths, path)
+	}
+	for path := range current {
+		if _, ok := original[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+
+	var diff strings.Builder
+	var files []string
+	for _, path := range paths {
+		before, inOriginal := original[path]
+		after, inCurrent := current[path]
+		if inOriginal == inCurrent && before == after {
+			continue
+		}
+		from, to := "a/"+path, "b/"+path
+		if !inOriginal {
+			from = "/dev/null"
+		}
+		if !inCurrent {
+			to = "/dev/null"
+		}
+		files = append(files, path)
+		diff.WriteString(textdiff.Unified(from, to, before, after))
+	}
+
+	if len(files) == 0 {
+		source.Drift = nil
+		if err := os.Remove(driftPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove source drift: %w", err)
+		}
+
+		return nil
+	}
+
+	detectedAt := now
+	if source.Drift != nil {
+		detectedAt = source.Drift.DetectedAt
+	}
+	source.Drift = &SourceDrift{Revision: source.Revision, DetectedAt: detectedAt, Files: files}
+
+	return writeFileAtomic(driftPath, []byte(diff.String()))
+}
+
+// readSourceTree reads every file below dir, keyed by slash-separated
+// relative path.
+func readSourceTree(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(data)
+
+		return nil
+	})
+
+	return files, err
+}
+
+// writeFileAtomic writes data to a temp file and renames it over path.
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRefreshSource_Drift(t *testing.T) {
+	ws, _ := OpenWorkspace(t.TempDir(), nil)
+	taskID := "task-1"
+	if _, err := ws.CreateWork(taskID, SourceInfo{
+		Type:  "dir",
+		Ref:   "dir:spec",
+		Files: []string{"source/spec.md"},
+		States: map[string]SourceFileState{
+			"spec.md": {Hash: "h1"},
+		},
+	}); err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	sourceDir := filepath.Join(ws.WorkPath(taskID), "source")
+	if err := os.MkdirAll(sourceDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "spec.md"), []byte("# Spec\nUse REST.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing changed: no revision, no drift
+	delta, err := ws.RefreshSource(taskID, SourceUpdate{States: map[string]SourceFileState{"spec.md": {Hash: "h1"}}})
+	if err != nil {
+		t.Fatalf("RefreshSource: %v", err)
+	}
+	if !delta.Empty() || delta.Revision != 0 {
+		t.Errorf("unchanged delta = %+v", delta)
+	}
+	if _, err := os.Stat(filepath.Join(ws.WorkPath(taskID), "source-original")); !os.IsNotExist(err) {
+		t.Error("source-original/ should not exist before the first change")
+	}
+
+	delta, err = ws.RefreshSource(taskID, SourceUpdate{
+		Changed: []SourceFile{{Path: "spec.md", Content: "# Spec\nUse gRPC.\n"}, {Path: "api.md", Content: "# API\n"}},
+		States:  map[string]SourceFileState{"spec.md": {Hash: "h2"}, "api.md": {Hash: "h3"}},
+	})
+	if err != nil {
+		t.Fatalf("RefreshSource: %v", err)
+	}
+	if delta.Revision != 1 || !slices.Equal(delta.Modified, []string{"spec.md"}) || !slices.Equal(delta.Added, []string{"api.md"}) {
+		t.Errorf("delta = %+v", delta)
+	}
+
+	work, err := ws.LoadWork(taskID)
+	if err != nil {
+		t.Fatalf("LoadWork: %v", err)
+	}
+	if work.Source.Revision != 1 || work.Source.Drift == nil {
+		t.Fatalf("source = %+v", work.Source)
+	}
+	if !slices.Equal(work.Source.Drift.Files, []string{"api.md", "spec.md"}) {
+		t.Errorf("drift files = %v", work.Source.Drift.Files)
+	}
+	if data, _ := os.ReadFile(filepath.Join(ws.WorkPath(taskID), "source-original", "spec.md")); string(data) != "# Spec\nUse REST.\n" {
+		t.Errorf("original spec.md = %q", data)
+	}
+
+	diff, err := ws.SourceDriftDiff(taskID)
+	if err != nil {
+		t.Fatalf("SourceDriftDiff: %v", err)
+	}
+	for _, want := range []string{"--- /dev/null\n+++ b/api.md\n", "-Use REST.\n+Use gRPC.\n"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+
+	// Reverting to the original content clears the drift
+	if _, err := ws.RefreshSource(taskID, SourceUpdate{
+		Changed: []SourceFile{{Path: "spec.md", Content: "# Spec\nUse REST.\n"}},
+		Removed: []string{"api.md"},
+		States:  map[string]SourceFileState{"spec.md": {Hash: "h1"}},
+	}); err != nil {
+		t.Fatalf("RefreshSource: %v", err)
+	}
+	work, _ = ws.LoadWork(taskID)
+	if work.Source.Revision != 2 || work.Source.Drift != nil {
+		t.Errorf("source after revert = revision %d, drift %+v", work.Source.Revision, work.Source.Drift)
+	}
+	if diff, _ := ws.SourceDriftDiff(taskID); diff != "" {
+		t.Errorf("drift diff after revert = %q", diff)
+	}
+}
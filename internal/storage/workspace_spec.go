@@ -28,8 +28,12 @@ func (w *Workspace) SpecificationPath(taskID string, number int) string {
 // SaveSpecification saves a specification file (markdown).
 func (w *Workspace) SaveSpecification(taskID string, number int, content string) error {
 	specPath := w.SpecificationPath(taskID, number)
+	if err := os.WriteFile(specPath, []byte(content), 0o644); err != nil {
+		return err
+	}
+	w.indexSpecification(taskID, number, content)
 
-	return os.WriteFile(specPath, []byte(content), 0o644)
+	return nil
 }
 
 // LoadSpecification loads a specification file content.
@@ -217,6 +221,42 @@ func (w *Workspace) UpdateSpecificationStatus(taskID string, number int, status
 	return w.SaveSpecificationWithMeta(taskID, spec)
 }
 
+// StartSpecificationImplementation marks a specification as implementing and
+// links the session working on it. Specifications already implementing are
+// left as they are so interrupted runs can resume.
+func (w *Workspace) StartSpecificationImplementation(taskID string, number int, session string) error {
+	spec, err := w.ParseSpecification(taskID, number)
+	if err != nil {
+		return err
+	}
+
+	spec.Status = SpecificationStatusImplementing
+	if session != "" && !slices.Contains(spec.Sessions, session) {
+		spec.Sessions = append(spec.Sessions, session)
+	}
+
+	return w.SaveSpecificationWithMeta(taskID, spec)
+}
+
+// CompleteSpecificationImplementation marks a specification as done and
+// records the checkpoint holding its changes (0 when none was created).
+func (w *Workspace) CompleteSpecificationImplementation(taskID string, number, checkpoint int) error {
+	spec, err := w.ParseSpecification(taskID, number)
+	if err != nil {
+		return err
+	}
+
+	spec.Status = SpecificationStatusDone
+	if spec.CompletedAt.IsZero() {
+		spec.CompletedAt = time.Now()
+	}
+	if checkpoint > 0 && !slices.Contains(spec.Checkpoints, checkpoint) {
+		spec.Checkpoints = append(spec.Checkpoints, checkpoint)
+	}
+
+	return w.SaveSpecificationWithMeta(taskID, spec)
+}
+
 // ListSpecificationsWithStatus returns all specifications with their parsed status.
 func (w *Workspace) ListSpecificationsWithStatus(taskID string) ([]*Specification, error) {
 	numbers, err := w.ListSpecifications(taskID)
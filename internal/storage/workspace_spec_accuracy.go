@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	specHeadingPattern  = regexp.MustCompile(`^#{1,6}\s+(.*)$`)
+	specBacktickPattern = regexp.MustCompile("`([^`\\s]+)`")
+	specListItemPattern = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+(\S+)`)
+	specExtPattern      = regexp.MustCompile(`\.[A-Za-z][A-Za-z0-9]{0,7}$`)
+)
+
+// SpecAccuracy compares the files a specification predicted it would touch
+// with the files actually changed while implementing it.
+type SpecAccuracy struct {
+	Number    int      `json:"number"`
+	Title     string   `json:"title,omitempty"`
+	Predicted []string `json:"predicted"`
+	Actual    []string `json:"actual"`
+	Matched   []string `json:"matched"`
+	Unplanned []string `json:"unplanned"` // Changed but not predicted
+	Missed    []string `json:"missed"`    // Predicted but not changed
+}
+
+// Implemented reports whether any implementation has been recorded for the spec.
+func (a *SpecAccuracy) Implemented() bool {
+	return len(a.Actual) > 0
+}
+
+// Precision is the share of changed files that were predicted.
+func (a *SpecAccuracy) Precision() float64 {
+	if len(a.Actual) == 0 {
+		return 0
+	}
+
+	return float64(len(a.Matched)) / float64(len(a.Actual))
+}
+
+// Recall is the share of predicted files that were changed.
+func (a *SpecAccuracy) Recall() float64 {
+	if len(a.Predicted) == 0 {
+		return 0
+	}
+
+	return float64(len(a.Matched)) / float64(len(a.Predicted))
+}
+
+// Drift is the share of all involved files that were either unplanned or missed
+// (0 means the plan matched exactly, 1 means no overlap at all).
+func (a *SpecAccuracy) Drift() float64 {
+	union := len(a.Matched) + len(a.Unplanned) + len(a.Missed)
+	if union == 0 {
+		return 0
+	}
+
+	return float64(len(a.Unplanned)+len(a.Missed)) / float64(union)
+}
+
+// ComputeSpecAccuracy compares a specification's predicted and implemented files.
+// Predicted files are extracted from the content when none were recorded.
+func ComputeSpecAccuracy(spec *Specification) SpecAccuracy {
+	predicted := spec.PredictedFiles
+	if len(predicted) == 0 {
+		predicted = ExtractPredictedFiles(spec.Content)
+	}
+	predicted = normalizeFileList(predicted)
+	actual := normalizeFileList(spec.ImplementedFiles)
+
+	acc := SpecAccuracy{
+		Number:    spec.Number,
+		Title:     spec.Title,
+		Predicted: predicted,
+		Actual:    actual,
+		Matched:   []string{},
+		Unplanned: []string{},
+		Missed:    []string{},
+	}
+	for _, f := range actual {
+		if slices.Contains(predicted, f) {
+			acc.Matched = append(acc.Matched, f)
+		} else {
+			acc.Unplanned = append(acc.Unplanned, f)
+		}
+	}
+	for _, f := range predicted {
+		if !slices.Contains(actual, f) {
+			acc.Missed = append(acc.Missed, f)
+		}
+	}
+
+	return acc
+}
+
+// ExtractPredictedFiles finds file paths mentioned in a specification.
+// Sections whose heading mentions files (e.g., "Files to Modify") are preferred;
+// without such a section, backtick-quoted paths anywhere in the document are used.
+func ExtractPredictedFiles(content string) []string {
+	var inFileSection, hasFileSection bool
+	var sectionFiles, allFiles []string
+
+	for line := range strings.SplitSeq(content, "\n") {
+		if m := specHeadingPattern.FindStringSubmatch(line); m != nil {
+			inFileSection = strings.Contains(strings.ToLower(m[1]), "file")
+			hasFileSection = hasFileSection || inFileSection
+
+			continue
+		}
+
+		var candidates []string
+		for _, m := range specBacktickPattern.FindAllStringSubmatch(line, -1) {
+			candidates = append(candidates, m[1])
+		}
+		for _, c := range candidates {
+			if looksLikeFilePath(c) {
+				allFiles = append(allFiles, c)
+			}
+		}
+
+		if !inFileSection {
+			continue
+		}
+		if m := specListItemPattern.FindStringSubmatch(line); m != nil {
+			candidates = append(candidates, strings.Trim(m[1], "`*_:,;()"))
+		}
+		for _, c := range candidates {
+			if looksLikeFilePath(c) {
+				sectionFiles = append(sectionFiles, c)
+			}
+		}
+	}
+
+	if hasFileSection && len(sectionFiles) > 0 {
+		return normalizeFileList(sectionFiles)
+	}
+
+	return normalizeFileList(allFiles)
+}
+
+// RecordImplementedFiles records files changed while implementing a specification.
+// The predicted files are captured from the spec content the first time.
+func (w *Workspace) RecordImplementedFiles(taskID string, number int, files []string) error {
+	spec, err := w.ParseSpecification(taskID, number)
+	if err != nil {
+		return err
+	}
+
+	if len(spec.PredictedFiles) == 0 {
+		spec.PredictedFiles = ExtractPredictedFiles(spec.Content)
+	}
+	spec.ImplementedFiles = normalizeFileList(append(spec.ImplementedFiles, files...))
+
+	return w.SaveSpecificationWithMeta(taskID, spec)
+}
+
+// SpecAccuracyReport computes plan-vs-actual accuracy for every specification of a task.
+func (w *Workspace) SpecAccuracyReport(taskID string) ([]SpecAccuracy, error) {
+	specs, err := w.ListSpecificationsWithStatus(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := make([]SpecAccuracy, 0, len(specs))
+	for _, spec := range specs {
+		report = append(report, ComputeSpecAccuracy(spec))
+	}
+
+	return report, nil
+}
+
+// looksLikeFilePath reports whether s is plausibly a repository-relative file path.
+func looksLikeFilePath(s string) bool {
+	if s == "" || strings.Contains(s, "://") || strings.ContainsAny(s, "(){}<>=$\"'") {
+		return false
+	}
+
+	return specExtPattern.MatchString(s)
+}
+
+// normalizeFileList cleans, de-duplicates and sorts file paths.
+func normalizeFileList(files []string) []string {
+	out := make([]string, 0, len(files))
+	for _, f := range files {
+		f = strings.TrimSpace(strings.ReplaceAll(f, "\\", "/"))
+		if f == "" {
+			continue
+		}
+		f = strings.TrimPrefix(path.Clean(f), "./")
+		if !slices.Contains(out, f) {
+			out = append(out, f)
+		}
+	}
+	slices.Sort(out)
+
+	return out
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestExtractPredictedFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name: "files section with list items",
+			content: `# Specification 1
+
+## Overview
+Update ` + "`README.md`" + ` afterwards.
+
+## Files to Modify
+- internal/api/handler.go: add endpoint
+- ` + "`internal/api/handler_test.go`" + `
+1. **cmd/app/main.go**
+- Add a new helper
+
+## Testing
+Run ` + "`go test ./...`" + `
+`,
+			want: []string{"cmd/app/main.go", "internal/api/handler.go", "internal/api/handler_test.go"},
+		},
+		{
+			name: "backticks without files section",
+			content: `# Spec
+
+Change ` + "`pkg/a.go`" + ` and ` + "`./pkg/b.go`" + `, see ` + "`https://example.com/x.html`" + `.
+Call ` + "`foo()`" + `.
+`,
+			want: []string{"pkg/a.go", "pkg/b.go"},
+		},
+		{
+			name:    "no files",
+			content: "# Spec\n\nJust prose.\n",
+			want:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractPredictedFiles(tt.content)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ExtractPredictedFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeSpecAccuracy(t *testing.T) {
+	spec := &Specification{
+		Number:           1,
+		PredictedFiles:   []string{"a.go", "b.go", "c.go"},
+		ImplementedFiles: []string{"./a.go", "b.go", "d.go"},
+	}
+
+	acc := ComputeSpecAccuracy(spec)
+
+	if !slices.Equal(acc.Matched, []string{"a.go", "b.go"}) {
+		t.Errorf("Matched = %v", acc.Matched)
+	}
+	if !slices.Equal(acc.Unplanned, []string{"d.go"}) {
+		t.Errorf("Unplanned = %v", acc.Unplanned)
+	}
+	if !slices.Equal(acc.Missed, []string{"c.go"}) {
+		t.Errorf("Missed = %v", acc.Missed)
+	}
+	if got, want := acc.Precision(), 2.0/3.0; got != want {
+		t.Errorf("Precision = %v, want %v", got, want)
+	}
+	if got, want := acc.Recall(), 2.0/3.0; got != want {
+		t.Errorf("Recall = %v, want %v", got, want)
+	}
+	if got, want := acc.Drift(), 0.5; got != want {
+		t.Errorf("Drift = %v, want %v", got, want)
+	}
+}
+
+func TestRecordImplementedFiles(t *testing.T) {
+	ws, _ := OpenWorkspace(t.TempDir(), nil)
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+	if _, err := ws.CreateWork("task1", SourceInfo{Type: "file", Ref: "task.md", ReadAt: time.Now()}); err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+
+	content := "# Specification 1\n\n## Files\n- `a.go`\n- `b.go`\n"
+	if err := ws.SaveSpecification("task1", 1, content); err != nil {
+		t.Fatalf("SaveSpecification: %v", err)
+	}
+
+	if err := ws.RecordImplementedFiles("task1", 1, []string{"a.go"}); err != nil {
+		t.Fatalf("RecordImplementedFiles: %v", err)
+	}
+	if err := ws.RecordImplementedFiles("task1", 1, []string{"c.go", "a.go"}); err != nil {
+		t.Fatalf("RecordImplementedFiles: %v", err)
+	}
+
+	report, err := ws.SpecAccuracyReport("task1")
+	if err != nil {
+		t.Fatalf("SpecAccuracyReport: %v", err)
+	}
+	if len(report) != 1 {
+		t.Fatalf("report len = %d, want 1", len(report))
+	}
+
+	acc := report[0]
+	if !slices.Equal(acc.Predicted, []string{"a.go", "b.go"}) {
+		t.Errorf("Predicted = %v", acc.Predicted)
+	}
+	if !slices.Equal(acc.Actual, []string{"a.go", "c.go"}) {
+		t.Errorf("Actual = %v", acc.Actual)
+	}
+	if acc.Title != "Specification 1" {
+		t.Errorf("Title = %q", acc.Title)
+	}
+}
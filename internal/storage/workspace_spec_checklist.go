@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"regexp"
+	"strings"
+)
+
+// specChecklistItemPattern matches a list item, with or without a checkbox.
+var specChecklistItemPattern = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+(?:\[[ xX]\]\s+)?(.+?)\s*$`)
+
+// ExtractChecklist returns the items of a specification's "Checklist"
+// section: the task list items of the task source that the specification
+// covers, copied verbatim by the planner.
+func ExtractChecklist(content string) []string {
+	var inChecklist bool
+	var items []string
+
+	for line := range strings.SplitSeq(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if m := specHeadingPattern.FindStringSubmatch(line); m != nil {
+			inChecklist = strings.EqualFold(strings.TrimSpace(m[1]), "checklist")
+
+			continue
+		}
+		if !inChecklist {
+			continue
+		}
+		if m := specChecklistItemPattern.FindStringSubmatch(line); m != nil {
+			items = append(items, m[1])
+		}
+	}
+
+	return items
+}
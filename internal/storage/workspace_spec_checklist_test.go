@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtractChecklist(t *testing.T) {
+	content := "# Spec\n\n## Overview\n- not an item\n\n## Checklist\r\n- [ ] Add login form\r\n- Validate input\n1. [x] Numbered\n\n## Testing\n- nope\n"
+
+	got := ExtractChecklist(content)
+	want := []string{"Add login form", "Validate input", "Numbered"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ExtractChecklist() = %q, want %q", got, want)
+	}
+
+	if got := ExtractChecklist("# Spec\n\n- item\n"); got != nil {
+		t.Errorf("ExtractChecklist(no section) = %q, want nil", got)
+	}
+}
@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+//go:embed spectemplates/*.md
+var builtInSpecTemplates embed.FS
+
+// ErrSpecTemplateNotFound is returned when no project or built-in spec template has a name.
+var ErrSpecTemplateNotFound = errors.New("spec template not found")
+
+var specTemplateName = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// BuiltInSpecTemplates returns the names of the spec templates shipped with mehrhof.
+func BuiltInSpecTemplates() []string {
+	entries, _ := builtInSpecTemplates.ReadDir("spectemplates")
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".md"))
+	}
+
+	return names
+}
+
+// SpecTemplatePath returns the path of a project spec template.
+// Spec templates are markdown files next to the task templates in .mehrhof/templates.
+func (w *Workspace) SpecTemplatePath(name string) string {
+	return filepath.Join(w.TemplatesDir(), name+".md")
+}
+
+// ListSpecTemplates returns the names of project and built-in spec templates, sorted.
+func (w *Workspace) ListSpecTemplates() ([]string, error) {
+	names := BuiltInSpecTemplates()
+
+	entries, err := os.ReadDir(w.TemplatesDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read templates directory: %w", err)
+	}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".md")
+		if ok && !entry.IsDir() && name != PRTemplateName && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	return names, nil
+}
+
+// LoadSpecTemplate returns the content of a spec template. Project templates
+// override built-in templates with the same name.
+func (w *Workspace) LoadSpecTemplate(name string) (string, error) {
+	if !specTemplateName.MatchString(name) {
+		return "", fmt.Errorf("invalid spec template name %q", name)
+	}
+
+	data, err := os.ReadFile(w.SpecTemplatePath(name))
+	if err == nil {
+		return string(data), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("read spec template: %w", err)
+	}
+
+	data, err = builtInSpecTemplates.ReadFile("spectemplates/" + name + ".md")
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrSpecTemplateNotFound, name)
+	}
+
+	return string(data), nil
+}
+
+// RenderSpecTemplate replaces {name} placeholders with vars. Unknown
+// placeholders are left as they are.
+func RenderSpecTemplate(content string, vars map[string]string) string {
+	pairs := make([]string, 0, 2*len(vars))
+	for k, v := range vars {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+
+	return strings.NewReplacer(pairs...).Replace(content)
+}
+
+// TemplateVars returns the placeholder values of a task for spec templates.
+func (t *TaskWork) TemplateVars() map[string]string {
+	return map[string]string{
+		"task_id": t.Metadata.ID,
+		"title":   t.Metadata.Title,
+		"key":     t.Metadata.ExternalKey,
+		"type":    t.Metadata.TaskType,
+		"slug":    t.Metadata.Slug,
+	}
+}
+
+// SaveSpecificationFromTemplate scaffolds the next specification of a task
+// from a spec template and returns its number. The spec starts as a draft.
+func (w *Workspace) SaveSpecificationFromTemplate(taskID, template string, vars map[string]string) (int, error) {
+	content, err := w.LoadSpecTemplate(template)
+	if err != nil {
+		return 0, err
+	}
+
+	number, err := w.NextSpecificationNumber(taskID)
+	if err != nil {
+		return 0, err
+	}
+
+	spec := &Specification{
+		Number:   number,
+		Status:   SpecificationStatusDraft,
+		Template: template,
+		Content:  RenderSpecTemplate(content, vars),
+	}
+	for line := range strings.Lines(spec.Content) {
+		if title, ok := strings.CutPrefix(strings.TrimSpace(line), "# "); ok {
+			spec.Title = title
+
+			break
+		}
+	}
+
+	if err := w.SaveSpecificationWithMeta(taskID, spec); err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
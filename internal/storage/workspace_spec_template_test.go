@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSpecTemplates(t *testing.T) {
+	ws, err := OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+
+	builtIn := BuiltInSpecTemplates()
+	for _, name := range []string{"api-endpoint", "bugfix", "go-service", "migration"} {
+		if !slices.Contains(builtIn, name) {
+			t.Errorf("BuiltInSpecTemplates() = %v, missing %q", builtIn, name)
+		}
+	}
+
+	if err := os.MkdirAll(ws.TemplatesDir(), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(ws.SpecTemplatePath("bugfix"), []byte("# Fix {title}\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := os.WriteFile(ws.SpecTemplatePath("rfc"), []byte("# RFC {title}\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	// Task templates share the directory and are not spec templates
+	if err := os.WriteFile(filepath.Join(ws.TemplatesDir(), "nightly.yaml"), []byte("title: x\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	names, err := ws.ListSpecTemplates()
+	if err != nil {
+		t.Fatalf("ListSpecTemplates: %v", err)
+	}
+	if want := []string{"api-endpoint", "bugfix", "go-service", "migration", "rfc"}; !slices.Equal(names, want) {
+		t.Errorf("ListSpecTemplates() = %v, want %v", names, want)
+	}
+
+	content, err := ws.LoadSpecTemplate("bugfix")
+	if err != nil || content != "# Fix {title}\n" {
+		t.Errorf("LoadSpecTemplate(bugfix) = %q, %v, want project override", content, err)
+	}
+	content, err = ws.LoadSpecTemplate("migration")
+	if err != nil || !strings.Contains(content, "{title}") {
+		t.Errorf("LoadSpecTemplate(migration) = %q, %v, want built-in", content, err)
+	}
+	if _, err := ws.LoadSpecTemplate("missing"); !errors.Is(err, ErrSpecTemplateNotFound) {
+		t.Errorf("LoadSpecTemplate(missing) error = %v, want ErrSpecTemplateNotFound", err)
+	}
+	if _, err := ws.LoadSpecTemplate("../work"); err == nil {
+		t.Error("LoadSpecTemplate(../work) should fail")
+	}
+}
+
+func TestRenderSpecTemplate(t *testing.T) {
+	got := RenderSpecTemplate("# {title} ({key}) {unknown}", map[string]string{"title": "Add login", "key": "PROJ-1"})
+	if want := "# Add login (PROJ-1) {unknown}"; got != want {
+		t.Errorf("RenderSpecTemplate() = %q, want %q", got, want)
+	}
+}
+
+func TestSaveSpecificationFromTemplate(t *testing.T) {
+	ws, err := OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+	work, err := ws.CreateWork("task1", SourceInfo{Type: "file", Ref: "task.md"})
+	if err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	work.Metadata.Title = "Crash on empty input"
+	if err := ws.SaveSpecification("task1", 1, "# Existing"); err != nil {
+		t.Fatalf("SaveSpecification: %v", err)
+	}
+
+	number, err := ws.SaveSpecificationFromTemplate("task1", "bugfix", work.TemplateVars())
+	if err != nil {
+		t.Fatalf("SaveSpecificationFromTemplate: %v", err)
+	}
+	if number != 2 {
+		t.Errorf("number = %d, want 2", number)
+	}
+
+	spec, err := ws.ParseSpecification("task1", number)
+	if err != nil {
+		t.Fatalf("ParseSpecification: %v", err)
+	}
+	if spec.Template != "bugfix" || spec.Status != SpecificationStatusDraft || spec.Title != "Crash on empty input" {
+		t.Errorf("spec = template %q, status %q, title %q", spec.Template, spec.Status, spec.Title)
+	}
+	if !strings.Contains(spec.Content, "## Root Cause") {
+		t.Errorf("spec content = %q, want bugfix sections", spec.Content)
+	}
+
+	if _, err := ws.SaveSpecificationFromTemplate("task1", "missing", nil); err == nil {
+		t.Error("SaveSpecificationFromTemplate(missing) should fail")
+	}
+}
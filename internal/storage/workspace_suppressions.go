@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// suppressionsFileName lists a task's suppressed guardrail findings.
+const suppressionsFileName = "suppressions.yaml"
+
+// Suppression marks a guardrail finding of a task as a false positive, so it
+// no longer blocks checkpoints or pushes.
+type Suppression struct {
+	ID        string    `yaml:"id"` // Finding fingerprint, as shown in guardrail reports
+	Reason    string    `yaml:"reason,omitempty"`
+	CreatedAt time.Time `yaml:"created_at"`
+}
+
+// SuppressionsPath returns the path of a task's suppressions file.
+func (w *Workspace) SuppressionsPath(taskID string) string {
+	return filepath.Join(w.WorkPath(taskID), suppressionsFileName)
+}
+
+// LoadSuppressions returns a task's suppressed findings; none when the task
+// has no suppressions file.
+func (w *Workspace) LoadSuppressions(taskID string) ([]Suppression, error) {
+	data, err := os.ReadFile(w.SuppressionsPath(taskID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read suppressions: %w", err)
+	}
+
+	var suppressions []Suppression
+	if err := yaml.Unmarshal(data, &suppressions); err != nil {
+		return nil, fmt.Errorf("parse suppressions: %w", err)
+	}
+
+	return suppressions, nil
+}
+
+// SuppressedIDs returns the IDs of a task's suppressed findings.
+func (w *Workspace) SuppressedIDs(taskID string) ([]string, error) {
+	suppressions, err := w.LoadSuppressions(taskID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(suppressions))
+	for i, s := range suppressions {
+		ids[i] = s.ID
+	}
+
+	return ids, nil
+}
+
+// AddSuppression records a suppressed finding, replacing an earlier
+// suppression with the same ID.
+func (w *Workspace) AddSuppression(taskID string, suppression Suppression) error {
+	suppressions, err := w.LoadSuppressions(taskID)
+	if err != nil {
+		return err
+	}
+	if suppression.CreatedAt.IsZero() {
+		suppression.CreatedAt = time.Now()
+	}
+	suppressions = slices.DeleteFunc(suppressions, func(s Suppression) bool { return s.ID == suppression.ID })
+
+	return w.saveSuppressions(taskID, append(suppressions, suppression))
+}
+
+// RemoveSuppression deletes a suppression and reports whether it existed.
+func (w *Workspace) RemoveSuppression(taskID, id string) (bool, error) {
+	suppressions, err := w.LoadSuppressions(taskID)
+	if err != nil {
+		return false, err
+	}
+	kept := slices.DeleteFunc(slices.Clone(suppressions), func(s Suppression) bool { return s.ID == id })
+	if len(kept) == len(suppressions) {
+		return false, nil
+	}
+
+	return true, w.saveSuppressions(taskID, kept)
+}
+
+func (w *Workspace) saveSuppressions(taskID string, suppressions []Suppression) error {
+	data, err := yaml.Marshal(suppressions)
+	if err != nil {
+		return fmt.Errorf("encode suppressions: %w", err)
+	}
+	if err := os.MkdirAll(w.WorkPath(taskID), 0o755); err != nil {
+		return fmt.Errorf("create work directory: %w", err)
+	}
+	if err := writeFileAtomic(w.SuppressionsPath(taskID), data); err != nil {
+		return fmt.Errorf("write suppressions: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSuppressions(t *testing.T) {
+	ws, err := OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+
+	if got, err := ws.LoadSuppressions("task"); err != nil || got != nil {
+		t.Fatalf("LoadSuppressions(no file) = %v, %v", got, err)
+	}
+
+	if err := ws.AddSuppression("task", Suppression{ID: "a1", Reason: "test fixture"}); err != nil {
+		t.Fatalf("AddSuppression: %v", err)
+	}
+	if err := ws.AddSuppression("task", Suppression{ID: "b2"}); err != nil {
+		t.Fatalf("AddSuppression: %v", err)
+	}
+	if err := ws.AddSuppression("task", Suppression{ID: "a1", Reason: "sample key"}); err != nil {
+		t.Fatalf("AddSuppression: %v", err)
+	}
+
+	suppressions, err := ws.LoadSuppressions("task")
+	if err != nil {
+		t.Fatalf("LoadSuppressions: %v", err)
+	}
+	if len(suppressions) != 2 || suppressions[1].ID != "a1" || suppressions[1].Reason != "sample key" || suppressions[1].CreatedAt.IsZero() {
+		t.Errorf("suppressions = %+v, want b2 and the replaced a1", suppressions)
+	}
+
+	removed, err := ws.RemoveSuppression("task", "b2")
+	if err != nil || !removed {
+		t.Fatalf("RemoveSuppression(b2) = %v, %v", removed, err)
+	}
+	if removed, _ := ws.RemoveSuppression("task", "zz"); removed {
+		t.Error("RemoveSuppression(unknown) = true")
+	}
+	if ids, err := ws.SuppressedIDs("task"); err != nil || !slices.Equal(ids, []string{"a1"}) {
+		t.Errorf("SuppressedIDs() = %v, %v, want [a1]", ids, err)
+	}
+}
@@ -136,6 +136,78 @@ func TestLoadConfigDefaults(t *testing.T) {
 	}
 }
 
+func TestLoadConfigFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	ws, _ := OpenWorkspace(tmpDir, nil)
+
+	cfg, err := ws.LoadConfigFile()
+	if err != nil || cfg != nil {
+		t.Fatalf("LoadConfigFile() without config = %v, %v; want nil, nil", cfg, err)
+	}
+
+	if err := os.MkdirAll(ws.TaskRoot(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ws.ConfigPath(), []byte("git:\n  commit_prefix: \"{key}:\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err = ws.LoadConfigFile()
+	if err != nil {
+		t.Fatalf("LoadConfigFile failed: %v", err)
+	}
+	if cfg.Git.CommitPrefix != "{key}:" {
+		t.Errorf("Git.CommitPrefix = %q, want %q", cfg.Git.CommitPrefix, "{key}:")
+	}
+	// Settings the file does not set stay empty
+	if cfg.Agent.Default != "" {
+		t.Errorf("Agent.Default = %q, want empty", cfg.Agent.Default)
+	}
+}
+
+func TestLoadConfig_LocalOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	ws, _ := OpenWorkspace(tmpDir, nil)
+	if err := os.MkdirAll(ws.TaskRoot(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	shared := "agent:\n  default: claude\n  timeout: 600\nenv:\n  SHARED: one\n"
+	if err := os.WriteFile(ws.ConfigPath(), []byte(shared), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	local := "agent:\n  default: codex\nenv:\n  MINE: two\n"
+	if err := os.WriteFile(ws.LocalConfigPath(), []byte(local), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := ws.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Agent.Default != "codex" || cfg.Agent.Timeout != 600 {
+		t.Errorf("Agent = %q, %d; want codex, 600", cfg.Agent.Default, cfg.Agent.Timeout)
+	}
+	if cfg.Env["SHARED"] != "one" || cfg.Env["MINE"] != "two" {
+		t.Errorf("Env = %v, want both entries", cfg.Env)
+	}
+
+	sharedCfg, err := ws.LoadSharedConfig()
+	if err != nil {
+		t.Fatalf("LoadSharedConfig failed: %v", err)
+	}
+	if sharedCfg.Agent.Default != "claude" || sharedCfg.Env["MINE"] != "" {
+		t.Errorf("LoadSharedConfig includes local overrides: %+v", sharedCfg)
+	}
+
+	if err := os.WriteFile(ws.LocalConfigPath(), []byte("agent: [\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.LoadConfig(); err == nil {
+		t.Error("LoadConfig with malformed config.local.yaml: want error")
+	}
+}
+
 func TestEnsureInitialized(t *testing.T) {
 	tmpDir := t.TempDir()
 	ws, _ := OpenWorkspace(tmpDir, nil)
@@ -180,6 +252,47 @@ func TestUpdateGitignore(t *testing.T) {
 	if !contains(content, ".active_task") {
 		t.Error(".gitignore does not contain .active_task")
 	}
+	if !contains(content, ".mehrhof/config.local.yaml") {
+		t.Error(".gitignore does not contain .mehrhof/config.local.yaml")
+	}
+	if !contains(content, ".mehrhof/serve.token") {
+		t.Error(".gitignore does not contain .mehrhof/serve.token")
+	}
+}
+
+func TestTaskDirOverride(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MEHR_TASK_DIR", ".ai-tasks")
+
+	ws, err := OpenWorkspace(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace failed: %v", err)
+	}
+	if want := filepath.Join(tmpDir, ".ai-tasks"); ws.TaskRoot() != want {
+		t.Errorf("TaskRoot() = %q, want %q", ws.TaskRoot(), want)
+	}
+	if want := filepath.Join(tmpDir, ".ai-tasks", "work"); ws.WorkRoot() != want {
+		t.Errorf("WorkRoot() = %q, want %q", ws.WorkRoot(), want)
+	}
+	if want := filepath.Join(tmpDir, ".ai-tasks", "config.yaml"); ws.ConfigPath() != want {
+		t.Errorf("ConfigPath() = %q, want %q", ws.ConfigPath(), want)
+	}
+	if got := NewDefaultWorkspaceConfig().Storage.WorkDir; got != ".ai-tasks/work" {
+		t.Errorf("default work_dir = %q, want .ai-tasks/work", got)
+	}
+
+	if err := ws.UpdateGitignore(); err != nil {
+		t.Fatalf("UpdateGitignore failed: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(tmpDir, ".gitignore"))
+	for _, entry := range []string{".ai-tasks/work/", ".ai-tasks/.env"} {
+		if !contains(string(data), entry) {
+			t.Errorf(".gitignore does not contain %s:\n%s", entry, data)
+		}
+	}
+	if contains(string(data), ".mehrhof") {
+		t.Errorf(".gitignore still mentions .mehrhof:\n%s", data)
+	}
 }
 
 func TestUpdateGitignoreExisting(t *testing.T) {
@@ -579,6 +692,63 @@ func TestAppendAndReadNotes(t *testing.T) {
 	}
 }
 
+func TestReadNotes_MergesEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	ws, _ := OpenWorkspace(tmpDir, nil)
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+
+	source := SourceInfo{Type: "file", Ref: "task.md"}
+	if _, err := ws.CreateWork("test123", source); err != nil {
+		t.Fatalf("CreateWork(test123): %v", err)
+	}
+
+	// Notes written before per-entry storage stay in notes.md
+	legacy := "# Notes\n\n## 2025-01-01 10:00:00\n\nLegacy note\n"
+	if err := os.WriteFile(ws.NotesPath("test123"), []byte(legacy), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	// An entry from another worktree, written earlier than ours
+	if err := os.MkdirAll(ws.NotesDir("test123"), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	other := filepath.Join(ws.NotesDir("test123"), "20000101T000000.000000000Z-ffffffff.md")
+	if err := os.WriteFile(other, []byte("## 2000-01-01 00:00:00\n\nOther user note\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	for _, note := range []string{"First note", "Second note"} {
+		if err := ws.AppendNote("test123", note, ""); err != nil {
+			t.Fatalf("AppendNote: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(ws.NotesPath("test123"))
+	if err != nil || string(data) != legacy {
+		t.Errorf("AppendNote should not modify notes.md, got %q", data)
+	}
+
+	content, err := ws.ReadNotes("test123")
+	if err != nil {
+		t.Fatalf("ReadNotes: %v", err)
+	}
+	order := []string{"Legacy note", "Other user note", "First note", "Second note"}
+	last := -1
+	for _, note := range order {
+		idx := strings.Index(content, note)
+		if idx <= last {
+			t.Fatalf("notes out of order, want %v:\n%s", order, content)
+		}
+		last = idx
+	}
+
+	if _, err := ws.ReadNotes("missing"); err == nil {
+		t.Error("ReadNotes() should fail for a task without notes")
+	}
+}
+
 func TestSpecsDir(t *testing.T) {
 	tmpDir := t.TempDir()
 	ws, _ := OpenWorkspace(tmpDir, nil)
@@ -876,6 +1046,84 @@ func TestLoadAndSaveSession(t *testing.T) {
 	}
 }
 
+func TestLatestSessionFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	ws, _ := OpenWorkspace(tmpDir, nil)
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+
+	if got, err := ws.LatestSessionFile("missing", "planning"); err != nil || got != "" {
+		t.Errorf("LatestSessionFile(missing) = %q, %v, want empty", got, err)
+	}
+
+	source := SourceInfo{Type: "file", Ref: "task.md"}
+	if _, err := ws.CreateWork("test123", source); err != nil {
+		t.Fatalf("CreateWork(test123): %v", err)
+	}
+	for _, name := range []string{
+		"2025-01-01T10-00-00-planning.yaml",
+		"2025-01-02T10-00-00-planning.yaml",
+		"2025-01-03T10-00-00-implementation.yaml",
+	} {
+		if err := ws.SaveSession("test123", name, NewSession("planning", "claude", "idle")); err != nil {
+			t.Fatalf("SaveSession: %v", err)
+		}
+	}
+
+	got, err := ws.LatestSessionFile("test123", "planning")
+	if err != nil {
+		t.Fatalf("LatestSessionFile: %v", err)
+	}
+	if got != "2025-01-02T10-00-00-planning.yaml" {
+		t.Errorf("LatestSessionFile() = %q, want newest planning session", got)
+	}
+}
+
+func TestAnnotateSession(t *testing.T) {
+	tmpDir := t.TempDir()
+	ws, _ := OpenWorkspace(tmpDir, nil)
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+
+	source := SourceInfo{Type: "file", Ref: "task.md"}
+	if _, err := ws.CreateWork("test123", source); err != nil {
+		t.Fatalf("CreateWork(test123): %v", err)
+	}
+
+	session := NewSession("planning", "claude", "planning")
+	session.Exchanges = []Exchange{
+		{Role: "user", Content: "Plan the cache"},
+		{Role: "agent", Content: "Use Redis with a 5 minute TTL"},
+	}
+	const name = "2025-01-01T10-00-00-planning.yaml"
+	if err := ws.SaveSession("test123", name, session); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+
+	if err := ws.AnnotateSession("test123", name, Annotation{Exchange: 2, Note: "Chose Redis", Bookmark: true}); err != nil {
+		t.Fatalf("AnnotateSession: %v", err)
+	}
+	if err := ws.AnnotateSession("test123", name, Annotation{Exchange: 3, Note: "out of range"}); err == nil {
+		t.Error("AnnotateSession() should reject an exchange past the end")
+	}
+
+	loaded, err := ws.LoadSession("test123", name)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	bookmarks := loaded.Bookmarks()
+	if len(loaded.Annotations) != 1 || len(bookmarks) != 1 || bookmarks[0].Note != "Chose Redis" || bookmarks[0].CreatedAt.IsZero() {
+		t.Errorf("annotations = %+v", loaded.Annotations)
+	}
+
+	files, err := ws.ListSessionFiles("test123")
+	if err != nil || len(files) != 1 || files[0] != name {
+		t.Errorf("ListSessionFiles() = %v, %v", files, err)
+	}
+}
+
 func TestGetSourceContent(t *testing.T) {
 	tmpDir := t.TempDir()
 	ws, _ := OpenWorkspace(tmpDir, nil)
@@ -1277,6 +1525,56 @@ func TestUpdateSpecStatus(t *testing.T) {
 	}
 }
 
+func TestSpecImplementationProgress(t *testing.T) {
+	tmpDir := t.TempDir()
+	ws, _ := OpenWorkspace(tmpDir, nil)
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+
+	source := SourceInfo{Type: "file", Ref: "task.md"}
+	if _, err := ws.CreateWork("test123", source); err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	if err := ws.SaveSpecification("test123", 2, "# Spec Two\n\nContent."); err != nil {
+		t.Fatalf("SaveSpecification: %v", err)
+	}
+
+	// Starting twice (a resumed run) links both sessions
+	for _, session := range []string{"s1.yaml", "s2.yaml", "s2.yaml"} {
+		if err := ws.StartSpecificationImplementation("test123", 2, session); err != nil {
+			t.Fatalf("StartSpecificationImplementation: %v", err)
+		}
+	}
+
+	loaded, _ := ws.ParseSpecification("test123", 2)
+	if loaded.Status != SpecificationStatusImplementing {
+		t.Errorf("Status = %q, want %q", loaded.Status, SpecificationStatusImplementing)
+	}
+	if len(loaded.Sessions) != 2 || loaded.Sessions[0] != "s1.yaml" || loaded.Sessions[1] != "s2.yaml" {
+		t.Errorf("Sessions = %v, want [s1.yaml s2.yaml]", loaded.Sessions)
+	}
+
+	if err := ws.CompleteSpecificationImplementation("test123", 2, 4); err != nil {
+		t.Fatalf("CompleteSpecificationImplementation: %v", err)
+	}
+
+	loaded, _ = ws.ParseSpecification("test123", 2)
+	if loaded.Status != SpecificationStatusDone || loaded.CompletedAt.IsZero() {
+		t.Errorf("Status = %q, CompletedAt = %v, want done with timestamp", loaded.Status, loaded.CompletedAt)
+	}
+	if len(loaded.Checkpoints) != 1 || loaded.Checkpoints[0] != 4 {
+		t.Errorf("Checkpoints = %v, want [4]", loaded.Checkpoints)
+	}
+	if loaded.Content != "# Spec Two\n\nContent." {
+		t.Errorf("Content = %q, content should be preserved", loaded.Content)
+	}
+
+	if err := ws.StartSpecificationImplementation("test123", 9, "s3.yaml"); err == nil {
+		t.Error("StartSpecificationImplementation on missing spec should fail")
+	}
+}
+
 func TestListSpecsWithStatus(t *testing.T) {
 	tmpDir := t.TempDir()
 	ws, _ := OpenWorkspace(tmpDir, nil)
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UsagePath returns the path to a task's usage ledger.
+func (w *Workspace) UsagePath(taskID string) string {
+	return filepath.Join(w.WorkPath(taskID), usageFileName)
+}
+
+// AppendUsageRecord appends one agent call to a task's usage ledger.
+// The ledger is a stream of YAML documents, so appends never rewrite history.
+func (w *Workspace) AppendUsageRecord(taskID string, record UsageRecord) error {
+	data, err := yaml.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal usage record: %w", err)
+	}
+
+	f, err := os.OpenFile(w.UsagePath(taskID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open usage ledger: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.Write(append([]byte("---\n"), data...)); err != nil {
+		return fmt.Errorf("write usage record: %w", err)
+	}
+
+	return nil
+}
+
+// LoadUsageRecords reads a task's usage ledger. Tasks without a ledger return no records.
+func (w *Workspace) LoadUsageRecords(taskID string) ([]UsageRecord, error) {
+	f, err := os.Open(w.UsagePath(taskID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("open usage ledger: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var records []UsageRecord
+	dec := yaml.NewDecoder(f)
+	for {
+		var record UsageRecord
+		if err := dec.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, fmt.Errorf("parse usage ledger: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUsageLedger(t *testing.T) {
+	ws, err := OpenWorkspace(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+	if _, err := ws.CreateWork("task1", SourceInfo{Type: "file", Ref: "task.md"}); err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+
+	records, err := ws.LoadUsageRecords("task1")
+	if err != nil || len(records) != 0 {
+		t.Fatalf("LoadUsageRecords without ledger = %v, %v", records, err)
+	}
+
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	want := []UsageRecord{
+		{Timestamp: at, Step: "planning", Agent: "claude", Model: "opus", InputTokens: 100, OutputTokens: 20, CostUSD: 0.5},
+		{Timestamp: at.Add(time.Hour), Step: "implementing", Agent: "claude", InputTokens: 300, OutputTokens: 80, CachedTokens: 40, CostUSD: 1.25},
+	}
+	for _, record := range want {
+		if err := ws.AppendUsageRecord("task1", record); err != nil {
+			t.Fatalf("AppendUsageRecord: %v", err)
+		}
+	}
+
+	records, err = ws.LoadUsageRecords("task1")
+	if err != nil {
+		t.Fatalf("LoadUsageRecords: %v", err)
+	}
+	if len(records) != len(want) {
+		t.Fatalf("LoadUsageRecords returned %d records, want %d", len(records), len(want))
+	}
+	for i := range want {
+		if !records[i].Timestamp.Equal(want[i].Timestamp) {
+			t.Errorf("record %d Timestamp = %v, want %v", i, records[i].Timestamp, want[i].Timestamp)
+		}
+		records[i].Timestamp = want[i].Timestamp
+		if records[i] != want[i] {
+			t.Errorf("record %d = %+v, want %+v", i, records[i], want[i])
+		}
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/valksor/go-mehrhof/internal/storage/migrate"
 )
 
 // WorkPath returns the path for a specific task's work directory.
@@ -81,9 +83,13 @@ func (w *Workspace) LoadWork(taskID string) (*TaskWork, error) {
 	}
 
 	var work TaskWork
-	if err := yaml.Unmarshal(data, &work); err != nil {
+	applied, err := upgradeDocument(migrate.KindWork, data, WorkSchemaVersion, &work)
+	if err != nil {
 		return nil, fmt.Errorf("parse work file: %w", err)
 	}
+	if len(applied) > 0 {
+		w.saveMigrated(taskID, workFile, &work, applied)
+	}
 
 	return &work, nil
 }
@@ -99,20 +105,30 @@ func (w *Workspace) SaveWork(work *TaskWork) error {
 		return fmt.Errorf("marshal work: %w", err)
 	}
 
-	// Use atomic write pattern: write to temp file, then rename
-	tmpFile := workFile + ".tmp"
-	if err := os.WriteFile(tmpFile, data, 0o644); err != nil {
-		return fmt.Errorf("write work file: %w", err)
-	}
-	// Atomic rename is guaranteed to be atomic on POSIX systems
-	if err := os.Rename(tmpFile, workFile); err != nil {
-		// Clean up temp file on error, log if cleanup fails
-		if removeErr := os.Remove(tmpFile); removeErr != nil {
-			slog.Warn("failed to clean up temp file after rename error", "path", tmpFile, "error", removeErr)
+	// Use atomic write pattern: write to temp file, then rename. The task
+	// lock keeps another process from writing the same temp file meanwhile.
+	err = w.WithTaskLock(work.Metadata.ID, func() error {
+		keepBackup(workFile)
+		tmpFile := workFile + ".tmp"
+		if err := os.WriteFile(tmpFile, data, 0o644); err != nil {
+			return fmt.Errorf("write work file: %w", err)
 		}
+		// Atomic rename is guaranteed to be atomic on POSIX systems
+		if err := os.Rename(tmpFile, workFile); err != nil {
+			// Clean up temp file on error, log if cleanup fails
+			if removeErr := os.Remove(tmpFile); removeErr != nil {
+				slog.Warn("failed to clean up temp file after rename error", "path", tmpFile, "error", removeErr)
+			}
 
-		return fmt.Errorf("save work: %w", err)
+			return fmt.Errorf("save work: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	w.indexWork(work)
 
 	return nil
 }
@@ -248,9 +264,15 @@ func (w *Workspace) flushTaskUsageLocked(taskID string) error {
 
 // DeleteWork removes a work directory.
 func (w *Workspace) DeleteWork(taskID string) error {
-	workPath := w.WorkPath(taskID)
+	err := w.WithTaskLock(taskID, func() error {
+		return os.RemoveAll(w.WorkPath(taskID))
+	})
+	if err != nil {
+		return err
+	}
+	w.unindexWork(taskID)
 
-	return os.RemoveAll(workPath)
+	return nil
 }
 
 // ListWorks returns all task IDs in the work directory.
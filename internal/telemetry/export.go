@@ -0,0 +1,210 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OTLP enum values used in the JSON encoding.
+const (
+	spanKindInternal      = 1
+	statusCodeOK          = 1
+	statusCodeError       = 2
+	temporalityCumulative = 2
+)
+
+// scopeName identifies the instrumentation library in exported data.
+const scopeName = "github.com/valksor/go-mehrhof"
+
+// exporter posts OTLP/HTTP JSON payloads to a collector.
+type exporter struct {
+	endpoint   string
+	headers    map[string]string
+	httpClient *http.Client
+}
+
+func newExporter(endpoint string, headers map[string]string) *exporter {
+	return &exporter{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		headers:    headers,
+		httpClient: &http.Client{Timeout: flushTimeout},
+	}
+}
+
+// export sends spans to /v1/traces and counters to /v1/metrics. Empty
+// payloads are skipped.
+func (e *exporter) export(ctx context.Context, resource []Attr, start time.Time, spans []*Span, counters []counter) error {
+	var errs []error
+
+	if len(spans) > 0 {
+		if err := e.post(ctx, "/v1/traces", tracesPayload(resource, spans)); err != nil {
+			errs = append(errs, fmt.Errorf("export traces: %w", err))
+		}
+	}
+	if len(counters) > 0 {
+		if err := e.post(ctx, "/v1/metrics", metricsPayload(resource, start, time.Now(), counters)); err != nil {
+			errs = append(errs, fmt.Errorf("export metrics: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (e *exporter) post(ctx context.Context, path string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return fmt.Errorf("collector returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	return nil
+}
+
+// tracesPayload encodes spans as an ExportTraceServiceRequest.
+func tracesPayload(resource []Attr, spans []*Span) map[string]any {
+	encoded := make([]map[string]any, 0, len(spans))
+	for _, s := range spans {
+		s.mu.Lock()
+		span := map[string]any{
+			"traceId":           s.traceID,
+			"spanId":            s.spanID,
+			"name":              s.name,
+			"kind":              spanKindInternal,
+			"startTimeUnixNano": unixNano(s.start),
+			"endTimeUnixNano":   unixNano(s.end),
+			"attributes":        encodeAttrs(s.attrs),
+			"status":            map[string]any{"code": statusCodeOK},
+		}
+		if s.parentID != "" {
+			span["parentSpanId"] = s.parentID
+		}
+		if s.err != nil {
+			span["status"] = map[string]any{"code": statusCodeError, "message": s.err.Error()}
+		}
+		s.mu.Unlock()
+
+		encoded = append(encoded, span)
+	}
+
+	return map[string]any{
+		"resourceSpans": []map[string]any{{
+			"resource": map[string]any{"attributes": encodeAttrs(resource)},
+			"scopeSpans": []map[string]any{{
+				"scope": map[string]any{"name": scopeName},
+				"spans": encoded,
+			}},
+		}},
+	}
+}
+
+// metricsPayload encodes counters as cumulative monotonic sums in an
+// ExportMetricsServiceRequest, one metric per name.
+func metricsPayload(resource []Attr, start, now time.Time, counters []counter) map[string]any {
+	var names []string
+	points := make(map[string][]map[string]any)
+	for _, c := range counters {
+		if _, ok := points[c.name]; !ok {
+			names = append(names, c.name)
+		}
+		points[c.name] = append(points[c.name], map[string]any{
+			"attributes":        encodeAttrs(c.attrs),
+			"startTimeUnixNano": unixNano(start),
+			"timeUnixNano":      unixNano(now),
+			"asDouble":          c.value,
+		})
+	}
+
+	metrics := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		metrics = append(metrics, map[string]any{
+			"name": name,
+			"unit": metricUnit(name),
+			"sum": map[string]any{
+				"dataPoints":             points[name],
+				"aggregationTemporality": temporalityCumulative,
+				"isMonotonic":            true,
+			},
+		})
+	}
+
+	return map[string]any{
+		"resourceMetrics": []map[string]any{{
+			"resource": map[string]any{"attributes": encodeAttrs(resource)},
+			"scopeMetrics": []map[string]any{{
+				"scope":   map[string]any{"name": scopeName},
+				"metrics": metrics,
+			}},
+		}},
+	}
+}
+
+// metricUnit derives the UCUM unit from the metric name's suffix.
+func metricUnit(name string) string {
+	switch {
+	case strings.HasSuffix(name, ".duration"):
+		return "s"
+	case strings.HasSuffix(name, ".tokens"):
+		return "{token}"
+	case strings.HasSuffix(name, ".cost"):
+		return "USD"
+	default:
+		return "1"
+	}
+}
+
+// encodeAttrs encodes attributes as OTLP KeyValue objects.
+func encodeAttrs(attrs []Attr) []map[string]any {
+	encoded := make([]map[string]any, 0, len(attrs))
+	for _, a := range attrs {
+		var value map[string]any
+		switch v := a.Value.(type) {
+		case string:
+			value = map[string]any{"stringValue": v}
+		case int64:
+			// int64 values are strings in the OTLP JSON encoding
+			value = map[string]any{"intValue": strconv.FormatInt(v, 10)}
+		case float64:
+			value = map[string]any{"doubleValue": v}
+		case bool:
+			value = map[string]any{"boolValue": v}
+		default:
+			value = map[string]any{"stringValue": a.String()}
+		}
+		encoded = append(encoded, map[string]any{"key": a.Key, "value": value})
+	}
+
+	return encoded
+}
+
+// unixNano encodes a timestamp as the decimal string OTLP JSON expects.
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,211 @@
+// Package telemetry records OpenTelemetry spans and counters for workflow
+// phases, agent runs and provider calls, and exports them to an OTLP/HTTP
+// collector as JSON.
+//
+// The exporter speaks the OTLP/HTTP JSON encoding directly so the binary does
+// not pull in the OpenTelemetry SDK. A nil *Telemetry, and the spans it
+// returns, are valid and record nothing, so callers never check whether
+// telemetry is enabled.
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// Defaults used when neither the config nor the environment set a value.
+const (
+	DefaultEndpoint    = "http://localhost:4318"
+	DefaultServiceName = "mehrhof"
+)
+
+// flushTimeout bounds the export that runs when a root span ends, so an
+// unreachable collector cannot stall the workflow.
+const flushTimeout = 5 * time.Second
+
+// Config describes where telemetry is exported.
+type Config struct {
+	Endpoint    string            // OTLP/HTTP base URL; /v1/traces and /v1/metrics are appended
+	Headers     map[string]string // Sent with every export (e.g., authentication)
+	ServiceName string            // service.name resource attribute
+}
+
+// Telemetry buffers finished spans and accumulates counters until Flush.
+type Telemetry struct {
+	exporter *exporter
+	resource []Attr
+	start    time.Time
+
+	mu       sync.Mutex
+	spans    []*Span
+	counters map[string]*counter
+}
+
+// counter is a cumulative sum for one metric name and attribute set.
+type counter struct {
+	name  string
+	attrs []Attr
+	value float64
+}
+
+// New creates a telemetry recorder exporting to cfg.
+func New(cfg Config) *Telemetry {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = DefaultEndpoint
+	}
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = DefaultServiceName
+	}
+
+	return &Telemetry{
+		exporter: newExporter(cfg.Endpoint, cfg.Headers),
+		resource: []Attr{
+			String("service.name", cfg.ServiceName),
+			String("service.instance.id", randomHex(8)),
+		},
+		start:    time.Now(),
+		counters: make(map[string]*counter),
+	}
+}
+
+// FromSettings creates a telemetry recorder from the workspace config, or
+// returns nil when telemetry is disabled. The standard OTEL_EXPORTER_OTLP_ENDPOINT,
+// OTEL_EXPORTER_OTLP_HEADERS and OTEL_SERVICE_NAME variables override the
+// config, and environment variables in headers are expanded.
+func FromSettings(settings storage.TelemetrySettings) *Telemetry {
+	if !settings.Enabled {
+		return nil
+	}
+
+	cfg := Config{
+		Endpoint:    settings.Endpoint,
+		Headers:     make(map[string]string, len(settings.Headers)),
+		ServiceName: settings.ServiceName,
+	}
+	for k, v := range settings.Headers {
+		cfg.Headers[k] = os.ExpandEnv(v)
+	}
+
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); v != "" {
+		cfg.Endpoint = v
+	}
+	if v := os.Getenv("OTEL_SERVICE_NAME"); v != "" {
+		cfg.ServiceName = v
+	}
+	maps.Copy(cfg.Headers, parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")))
+
+	return New(cfg)
+}
+
+// parseHeaders parses the OTEL_EXPORTER_OTLP_HEADERS format: comma-separated
+// key=value pairs.
+func parseHeaders(s string) map[string]string {
+	headers := make(map[string]string)
+	for pair := range strings.SplitSeq(s, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		headers[key] = strings.TrimSpace(value)
+	}
+
+	return headers
+}
+
+// Enabled reports whether anything is recorded.
+func (t *Telemetry) Enabled() bool {
+	return t != nil
+}
+
+// Add increases the counter name for the given attributes by value.
+func (t *Telemetry) Add(name string, value float64, attrs ...Attr) {
+	if t == nil {
+		return
+	}
+
+	key := counterKey(name, attrs)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c, ok := t.counters[key]
+	if !ok {
+		c = &counter{name: name, attrs: slices.Clone(attrs)}
+		t.counters[key] = c
+	}
+	c.value += value
+}
+
+// counterKey identifies a counter by name and attribute set, independent of
+// attribute order.
+func counterKey(name string, attrs []Attr) string {
+	parts := make([]string, 0, len(attrs))
+	for _, a := range attrs {
+		parts = append(parts, a.Key+"="+a.String())
+	}
+	slices.Sort(parts)
+
+	return name + "{" + strings.Join(parts, ",") + "}"
+}
+
+// Flush exports the spans finished since the last flush and the current
+// value of every counter. Counters are cumulative since the recorder was
+// created, so they are sent again on every flush.
+func (t *Telemetry) Flush(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	spans := t.spans
+	t.spans = nil
+	counters := make([]counter, 0, len(t.counters))
+	for _, c := range t.counters {
+		counters = append(counters, *c)
+	}
+	t.mu.Unlock()
+
+	slices.SortFunc(counters, func(a, b counter) int {
+		return strings.Compare(counterKey(a.name, a.attrs), counterKey(b.name, b.attrs))
+	})
+
+	return t.exporter.export(ctx, t.resource, t.start, spans, counters)
+}
+
+// finish buffers a finished span and exports everything once the root span
+// of a trace ends.
+func (t *Telemetry) finish(s *Span) {
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+
+	if s.parentID != "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	if err := t.Flush(ctx); err != nil {
+		slog.Debug("export telemetry", "error", err)
+	}
+}
+
+// randomHex returns n random bytes hex-encoded.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,216 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+)
+
+// collector records the OTLP payloads posted to it by path.
+type collector struct {
+	mu       sync.Mutex
+	payloads map[string][]map[string]any
+	headers  http.Header
+}
+
+func newCollector(t *testing.T) (*collector, *httptest.Server) {
+	t.Helper()
+
+	c := &collector{payloads: make(map[string][]map[string]any)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode %s: %v", r.URL.Path, err)
+		}
+		c.mu.Lock()
+		c.payloads[r.URL.Path] = append(c.payloads[r.URL.Path], payload)
+		c.headers = r.Header.Clone()
+		c.mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+
+	return c, srv
+}
+
+// path walks nested maps and single-element lists of a decoded payload.
+func path(v any, keys ...any) any {
+	for _, k := range keys {
+		switch key := k.(type) {
+		case string:
+			m, _ := v.(map[string]any)
+			v = m[key]
+		case int:
+			l, _ := v.([]any)
+			if key >= len(l) {
+				return nil
+			}
+			v = l[key]
+		}
+	}
+
+	return v
+}
+
+func TestNilTelemetry(t *testing.T) {
+	var tel *Telemetry
+	if tel.Enabled() {
+		t.Error("nil telemetry should be disabled")
+	}
+
+	ctx, span := tel.StartSpan(t.Context(), "noop")
+	if span != nil || SpanFromContext(ctx) != nil {
+		t.Error("nil telemetry should not create spans")
+	}
+	span.SetAttributes(String("k", "v"))
+	span.End(nil)
+	tel.Add("mehr.runs", 1)
+	if err := tel.Flush(t.Context()); err != nil {
+		t.Errorf("Flush: %v", err)
+	}
+
+	if FromSettings(storage.TelemetrySettings{}) != nil {
+		t.Error("FromSettings should return nil when disabled")
+	}
+}
+
+func TestSpansExportedWhenRootEnds(t *testing.T) {
+	c, srv := newCollector(t)
+	tel := New(Config{Endpoint: srv.URL, Headers: map[string]string{"Authorization": "Bearer x"}, ServiceName: "test"})
+
+	ctx, root := tel.StartSpan(t.Context(), "mehr.planning", String("mehr.phase", "planning"))
+	_, child := tel.StartSpan(ctx, "agent.run")
+	child.SetAttributes(Int("mehr.tokens.input", 1200))
+	child.End(errors.New("agent crashed"))
+
+	if len(c.payloads["/v1/traces"]) != 0 {
+		t.Fatal("child span should not trigger an export")
+	}
+	root.End(nil)
+
+	traces := c.payloads["/v1/traces"]
+	if len(traces) != 1 {
+		t.Fatalf("got %d trace exports, want 1", len(traces))
+	}
+	if got := c.headers.Get("Authorization"); got != "Bearer x" {
+		t.Errorf("Authorization = %q", got)
+	}
+
+	rs := path(traces[0], "resourceSpans", 0)
+	if got := path(rs, "resource", "attributes", 0, "value", "stringValue"); got != "test" {
+		t.Errorf("service.name = %v", got)
+	}
+
+	spans, _ := path(rs, "scopeSpans", 0, "spans").([]any)
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	childSpan, rootSpan := spans[0], spans[1]
+	if path(childSpan, "traceId") != path(rootSpan, "traceId") {
+		t.Error("child should share the root's trace ID")
+	}
+	if path(childSpan, "parentSpanId") != path(rootSpan, "spanId") {
+		t.Error("child should point at the root span")
+	}
+	if got := path(childSpan, "status", "code"); got != float64(statusCodeError) {
+		t.Errorf("child status = %v, want error", got)
+	}
+	if got := path(childSpan, "attributes", 0, "value", "intValue"); got != "1200" {
+		t.Errorf("intValue = %v, want string 1200", got)
+	}
+	if got := path(rootSpan, "status", "code"); got != float64(statusCodeOK) {
+		t.Errorf("root status = %v, want ok", got)
+	}
+}
+
+func TestCountersAreCumulative(t *testing.T) {
+	c, srv := newCollector(t)
+	tel := New(Config{Endpoint: srv.URL})
+
+	tel.Add("mehr.agent.tokens", 100, String("type", "input"), String("mehr.agent", "claude"))
+	tel.Add("mehr.agent.tokens", 50, String("mehr.agent", "claude"), String("type", "input"))
+	tel.Add("mehr.agent.tokens", 10, String("mehr.agent", "claude"), String("type", "output"))
+	if err := tel.Flush(t.Context()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	tel.Add("mehr.agent.tokens", 1, String("type", "output"), String("mehr.agent", "claude"))
+	if err := tel.Flush(t.Context()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	metrics := c.payloads["/v1/metrics"]
+	if len(metrics) != 2 {
+		t.Fatalf("got %d metric exports, want 2", len(metrics))
+	}
+	if len(c.payloads["/v1/traces"]) != 0 {
+		t.Error("no spans should have been exported")
+	}
+
+	metric := path(metrics[1], "resourceMetrics", 0, "scopeMetrics", 0, "metrics", 0)
+	if got := path(metric, "unit"); got != "{token}" {
+		t.Errorf("unit = %v", got)
+	}
+	if got := path(metric, "sum", "aggregationTemporality"); got != float64(temporalityCumulative) {
+		t.Errorf("temporality = %v", got)
+	}
+	points, _ := path(metric, "sum", "dataPoints").([]any)
+	if len(points) != 2 {
+		t.Fatalf("got %d data points, want 2", len(points))
+	}
+	values := map[any]any{}
+	for _, p := range points {
+		for _, attr := range path(p, "attributes").([]any) {
+			if path(attr, "key") == "type" {
+				values[path(attr, "value", "stringValue")] = path(p, "asDouble")
+			}
+		}
+	}
+	if values["input"] != float64(150) || values["output"] != float64(11) {
+		t.Errorf("values = %v, want input 150 and output 11", values)
+	}
+}
+
+func TestFlushReportsCollectorErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "bad payload", http.StatusBadRequest)
+	}))
+	t.Cleanup(srv.Close)
+
+	tel := New(Config{Endpoint: srv.URL})
+	tel.Add("mehr.retries", 1)
+	if err := tel.Flush(t.Context()); err == nil {
+		t.Error("Flush should fail when the collector rejects the payload")
+	}
+}
+
+func TestFromSettings_EnvOverrides(t *testing.T) {
+	c, srv := newCollector(t)
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", srv.URL)
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "x-team=platform, x-empty")
+	t.Setenv("TELEMETRY_TOKEN", "secret")
+
+	tel := FromSettings(storage.TelemetrySettings{
+		Enabled:  true,
+		Endpoint: "http://unreachable.invalid",
+		Headers:  map[string]string{"Authorization": "Bearer ${TELEMETRY_TOKEN}"},
+	})
+	tel.Add("mehr.retries", 1)
+	if err := tel.Flush(t.Context()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if got := c.headers.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if got := c.headers.Get("X-Team"); got != "platform" {
+		t.Errorf("X-Team = %q", got)
+	}
+	rs := path(c.payloads["/v1/metrics"][0], "resourceMetrics", 0)
+	if got := path(rs, "resource", "attributes", 0, "value", "stringValue"); got != DefaultServiceName {
+		t.Errorf("service.name = %v", got)
+	}
+}
@@ -0,0 +1,157 @@
+// Package textdiff computes line-based unified diffs of small texts, such as
+// task sources and specifications.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change.
+const contextLines = 3
+
+// maxCells bounds the LCS table; larger inputs are diffed as a whole-file
+// replacement instead.
+const maxCells = 1_000_000
+
+type editKind byte
+
+const (
+	kept    editKind = ' '
+	removed editKind = '-'
+	added   editKind = '+'
+)
+
+type edit struct {
+	kind editKind
+	text string
+	a, b int // line positions in a and b before this edit
+}
+
+// Unified returns the unified diff turning a into b, with fromName and
+// toName in the file headers. It returns "" when the texts are equal.
+func Unified(fromName, toName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	edits := diffLines(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+	for _, h := range hunks(edits) {
+		writeHunk(&sb, edits[h[0]:h[1]])
+	}
+
+	return sb.String()
+}
+
+// splitLines splits text into lines, ignoring a final newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines computes a minimal edit script with a longest-common-subsequence
+// table.
+func diffLines(a, b []string) []edit {
+	n, m := len(a), len(b)
+	if n*m > maxCells {
+		return replaceAll(a, b)
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	edits := make([]edit, 0, n+m)
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			edits = append(edits, edit{kind: kept, text: a[i], a: i, b: j})
+			i++
+			j++
+		case j < m && (i == n || lcs[i][j+1] > lcs[i+1][j]):
+			edits = append(edits, edit{kind: added, text: b[j], a: i, b: j})
+			j++
+		default:
+			edits = append(edits, edit{kind: removed, text: a[i], a: i, b: j})
+			i++
+		}
+	}
+
+	return edits
+}
+
+func replaceAll(a, b []string) []edit {
+	edits := make([]edit, 0, len(a)+len(b))
+	for i, line := range a {
+		edits = append(edits, edit{kind: removed, text: line, a: i})
+	}
+	for j, line := range b {
+		edits = append(edits, edit{kind: added, text: line, a: len(a), b: j})
+	}
+
+	return edits
+}
+
+// hunks groups changes with their context into [start, end) edit ranges,
+// merging changes whose context overlaps.
+func hunks(edits []edit) [][2]int {
+	var out [][2]int
+	for i, e := range edits {
+		if e.kind == kept {
+			continue
+		}
+		start := max(i-contextLines, 0)
+		end := min(i+contextLines+1, len(edits))
+		if n := len(out); n > 0 && start <= out[n-1][1] {
+			out[n-1][1] = end
+
+			continue
+		}
+		out = append(out, [2]int{start, end})
+	}
+
+	return out
+}
+
+func writeHunk(sb *strings.Builder, edits []edit) {
+	aLen, bLen := 0, 0
+	for _, e := range edits {
+		if e.kind != added {
+			aLen++
+		}
+		if e.kind != removed {
+			bLen++
+		}
+	}
+	aStart, bStart := edits[0].a+1, edits[0].b+1
+	if aLen == 0 {
+		aStart--
+	}
+	if bLen == 0 {
+		bStart--
+	}
+
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", aStart, aLen, bStart, bLen)
+	for _, e := range edits {
+		sb.WriteByte(byte(e.kind))
+		sb.WriteString(e.text)
+		sb.WriteByte('\n')
+	}
+}
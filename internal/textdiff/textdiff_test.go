@@ -0,0 +1,47 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	b := "one\ntwo\nTHREE\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\n"
+
+	want := `--- a/task.md
++++ b/task.md
+@@ -1,6 +1,6 @@
+ one
+ two
+-three
++THREE
+ four
+ five
+ six
+@@ -8,3 +8,4 @@
+ eight
+ nine
+ ten
++eleven
+`
+	if got := Unified("a/task.md", "b/task.md", a, b); got != want {
+		t.Errorf("Unified() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnified_EdgeCases(t *testing.T) {
+	if got := Unified("a", "b", "same\n", "same\n"); got != "" {
+		t.Errorf("equal texts: %q", got)
+	}
+
+	got := Unified("a", "b", "", "new\n")
+	if !strings.Contains(got, "@@ -0,0 +1,1 @@\n+new\n") {
+		t.Errorf("added file:\n%s", got)
+	}
+
+	got = Unified("a", "b", "old\n", "")
+	if !strings.Contains(got, "@@ -1,1 +0,0 @@\n-old\n") {
+		t.Errorf("removed file:\n%s", got)
+	}
+}
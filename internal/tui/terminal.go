@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// makeRaw switches the terminal f to unbuffered, unechoed input and returns
+// a function restoring the previous settings. Signals such as Ctrl+C keep
+// working. Input that is not a terminal is left untouched.
+func makeRaw(f *os.File) (func(), error) {
+	state, err := stty(f, "-g")
+	if err != nil {
+		return func() {}, nil //nolint:nilerr // Not a terminal: keys arrive as they are
+	}
+	if _, err := stty(f, "-icanon", "-echo", "min", "1"); err != nil {
+		return nil, err
+	}
+
+	return func() { _, _ = stty(f, state) }, nil
+}
+
+// terminalSize returns the columns and rows of the terminal f, or the
+// defaults when they cannot be determined.
+func terminalSize(f *os.File) (int, int) {
+	out, err := stty(f, "size")
+	if err != nil {
+		return defaultWidth, defaultHeight
+	}
+	rows, cols, ok := strings.Cut(out, " ")
+	if !ok {
+		return defaultWidth, defaultHeight
+	}
+	height, err1 := strconv.Atoi(rows)
+	width, err2 := strconv.Atoi(cols)
+	if err1 != nil || err2 != nil || width <= 0 || height <= 0 {
+		return defaultWidth, defaultHeight
+	}
+
+	return width, height
+}
+
+func stty(f *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = f
+	out, err := cmd.Output()
+
+	return strings.TrimSpace(string(out)), err
+}
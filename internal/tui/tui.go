@@ -0,0 +1,259 @@
+// Package tui implements the full-screen terminal dashboard behind mehr ui.
+//
+// The dashboard follows a model/update/view structure: conductor events from
+// the event bus and key presses update a model, and the whole model is
+// redrawn after every update. Nothing is polled; task details are re-read
+// only when an event says they changed.
+package tui
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/events"
+)
+
+// eventBuffer is how many events the dashboard may fall behind before events
+// are dropped from the stream.
+const eventBuffer = 256
+
+// Terminal control sequences.
+const (
+	enterAltScreen = "\x1b[?1049h"
+	leaveAltScreen = "\x1b[?1049l"
+	hideCursor     = "\x1b[?25l"
+	showCursor     = "\x1b[?25h"
+	clearScreen    = "\x1b[H\x1b[2J"
+)
+
+// action is a workflow operation bound to a key.
+type action struct {
+	run  func(ctx context.Context, c *conductor.Conductor) error
+	name string
+	key  rune
+}
+
+var actions = []action{
+	{key: 'p', name: "plan", run: func(ctx context.Context, c *conductor.Conductor) error {
+		if err := c.Plan(ctx); err != nil {
+			return err
+		}
+
+		return c.RunPlanning(ctx)
+	}},
+	{key: 'i', name: "implement", run: func(ctx context.Context, c *conductor.Conductor) error {
+		if err := c.Implement(ctx); err != nil {
+			return err
+		}
+
+		return c.RunImplementation(ctx)
+	}},
+	{key: 'r', name: "review", run: func(ctx context.Context, c *conductor.Conductor) error {
+		if err := c.Review(ctx); err != nil {
+			return err
+		}
+
+		return c.RunReview(ctx)
+	}},
+	{key: 'u', name: "undo", run: func(ctx context.Context, c *conductor.Conductor) error {
+		return c.Undo(ctx)
+	}},
+}
+
+// result reports the end of an operation.
+type result struct {
+	err  error
+	name string
+}
+
+// App is a running dashboard for one conductor.
+type App struct {
+	cond *conductor.Conductor
+	in   io.Reader
+	out  io.Writer
+	ops  sync.WaitGroup
+	done chan result
+	m    model
+}
+
+// New creates a dashboard that reads keys from in and draws to out.
+func New(cond *conductor.Conductor, in io.Reader, out io.Writer) *App {
+	return &App{
+		cond: cond,
+		in:   in,
+		out:  out,
+		done: make(chan result, 1),
+		m:    newModel(),
+	}
+}
+
+// Run draws the dashboard until the user quits, input ends or ctx is
+// cancelled. When in is a terminal it is switched to unbuffered input for
+// the duration. A running operation is cancelled and awaited before Run
+// returns.
+func (a *App) Run(ctx context.Context) error {
+	defer a.ops.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if f, ok := a.in.(*os.File); ok {
+		restore, err := makeRaw(f)
+		if err != nil {
+			return fmt.Errorf("configure terminal: %w", err)
+		}
+		defer restore()
+		a.m.width, a.m.height = terminalSize(f)
+	}
+
+	eventCh := make(chan events.Event, eventBuffer)
+	push := func(e events.Event) {
+		// Handlers run on the publisher's goroutine, possibly under the
+		// conductor's lock: never block here.
+		select {
+		case eventCh <- e:
+		default:
+		}
+	}
+
+	// Start the stream with the task's recent history, then follow it live
+	bus := a.cond.GetEventBus()
+	subID, err := bus.SubscribeFrom(events.TypeAll, -maxEvents, push)
+	if err != nil {
+		subID = bus.SubscribeAll(push)
+	}
+	defer bus.Unsubscribe(subID)
+
+	// The history is already queued; show it without a refresh per event,
+	// the first refresh below covers it
+	for len(eventCh) > 0 {
+		a.m.addEvent(<-eventCh)
+	}
+
+	// Apply edits to config.yaml and .env without a restart; failed
+	// reloads arrive as error events
+	a.ops.Go(func() { _ = a.cond.WatchConfig(ctx) })
+
+	keyCh := make(chan rune)
+	go a.readKeys(ctx, keyCh)
+
+	_, _ = io.WriteString(a.out, enterAltScreen+hideCursor)
+	defer func() { _, _ = io.WriteString(a.out, showCursor+leaveAltScreen) }()
+
+	a.refresh(ctx)
+	for {
+		a.draw()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case e := <-eventCh:
+			a.m.addEvent(e)
+			if refreshesOn(e.Type) {
+				a.refresh(ctx)
+			}
+		case res := <-a.done:
+			a.m.finish(res.name, res.err)
+			a.refresh(ctx)
+		case key, ok := <-keyCh:
+			if !ok || key == 'q' {
+				return nil
+			}
+			a.handleKey(ctx, key)
+		}
+	}
+}
+
+// handleKey starts the operation bound to key, one at a time.
+func (a *App) handleKey(ctx context.Context, key rune) {
+	for _, act := range actions {
+		if act.key != key {
+			continue
+		}
+		if a.m.operation != "" {
+			a.m.message = fmt.Sprintf("%s is still running", a.m.operation)
+
+			return
+		}
+		if a.m.task == nil {
+			a.m.message = "no active task - start one with mehr start"
+
+			return
+		}
+
+		a.m.start(act.name)
+		a.ops.Go(func() {
+			a.done <- result{name: act.name, err: act.run(ctx, a.cond)}
+		})
+
+		return
+	}
+}
+
+// readKeys sends key presses until input ends.
+func (a *App) readKeys(ctx context.Context, keyCh chan<- rune) {
+	defer close(keyCh)
+
+	r := bufio.NewReader(a.in)
+	for {
+		key, _, err := r.ReadRune()
+		if err != nil {
+			return
+		}
+		select {
+		case keyCh <- key:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// refresh re-reads the active task's details.
+func (a *App) refresh(ctx context.Context) {
+	status, err := a.cond.Status()
+	if err != nil {
+		a.m.setTask(nil, snapshot{})
+
+		return
+	}
+
+	ws := a.cond.GetWorkspace()
+	var snap snapshot
+	snap.specs, _ = ws.ListSpecificationsWithStatus(status.TaskID)
+	if work := a.cond.GetTaskWork(); work != nil {
+		snap.costs = work.Costs
+	}
+	if v := a.cond.GetVCS(); v != nil {
+		snap.checkpoints, _ = v.ListCheckpoints(ctx, status.TaskID)
+	}
+	if q, err := ws.LoadPendingQuestion(status.TaskID); err == nil {
+		snap.question = q.Question
+	}
+
+	a.m.setTask(status, snap)
+}
+
+// draw renders the model as a full frame.
+func (a *App) draw() {
+	_, _ = io.WriteString(a.out, clearScreen+a.m.view())
+}
+
+// refreshesOn reports whether events of type t change the task details
+// shown outside the event stream.
+func refreshesOn(t events.Type) bool {
+	switch t {
+	case events.TypeStateChanged, events.TypeCheckpoint, events.TypeQuestionPending,
+		events.TypeTaskStarted, events.TypeTaskFinished, events.TypePlanCompleted,
+		events.TypeImplementDone, events.TypeBlueprintReady, events.TypeBranchCreated:
+		return true
+	case events.TypeProgress, events.TypeError, events.TypeFileChanged,
+		events.TypeAgentMessage, events.TypePRCreated, events.TypeConfigReloaded, events.TypeRateLimited:
+		return false
+	}
+
+	return false
+}
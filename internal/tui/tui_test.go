@@ -0,0 +1,156 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/testutil"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+func TestFormatEvent(t *testing.T) {
+	tests := []struct {
+		event events.Event
+		want  string
+	}{
+		{events.StateChangedEvent{From: "idle", To: "planning"}.ToEvent(), "state idle → planning"},
+		{events.Event{Type: events.TypeProgress, Data: map[string]any{"message": "Agent analyzing task..."}}, "Agent analyzing task..."},
+		{events.Event{Type: events.TypeCheckpoint, Data: map[string]any{"checkpoint": 3}}, "checkpoint #3"},
+		{events.Event{Type: events.TypePlanCompleted}, "plan completed"},
+		{events.Event{Type: events.TypeAgentMessage, Data: map[string]any{"event": agent.Event{
+			Type:     agent.EventToolUse,
+			ToolCall: &agent.ToolCall{Name: "Read", Description: "Reading main.go"},
+		}}}, "agent: Reading main.go"},
+		{events.Event{Type: events.TypeAgentMessage, Data: map[string]any{"event": agent.Event{
+			Type: agent.EventText, Text: "thinking",
+		}}}, ""},
+		{events.Event{Type: events.TypeAgentMessage, Data: map[string]any{"event": map[string]any{
+			"type": "tool_use", "tool": "Bash",
+		}}}, "agent: Bash"},
+	}
+
+	for _, tt := range tests {
+		if got := formatEvent(tt.event); got != tt.want {
+			t.Errorf("formatEvent(%s) = %q, want %q", tt.event.Type, got, tt.want)
+		}
+	}
+}
+
+func TestModelView(t *testing.T) {
+	display.SetColorsEnabled(false)
+	t.Cleanup(func() { display.SetColorsEnabled(true) })
+
+	m := newModel()
+	m.height = 20
+	m.setTask(&conductor.TaskStatus{TaskID: "abc123", Title: "Add caching", State: "implementing", Agent: "claude"}, snapshot{
+		specs:       []*storage.Specification{{Number: 1, Title: "Cache layer", Status: storage.SpecificationStatusReady}},
+		checkpoints: []*vcs.Checkpoint{{Number: 1, Message: "Planning complete", Timestamp: time.Now()}},
+		costs:       storage.CostStats{TotalInputTokens: 1200, TotalOutputTokens: 300, TotalCostUSD: 0.05},
+	})
+	for i := range 30 {
+		m.addEvent(events.Event{Type: events.TypeProgress, Data: map[string]any{"message": "step " + string(rune('A'+i))}})
+	}
+	m.start("implement")
+
+	lines := strings.Split(m.view(), "\r\n")
+	if len(lines) != m.height {
+		t.Fatalf("view has %d lines, want %d:\n%s", len(lines), m.height, strings.Join(lines, "\n"))
+	}
+
+	frame := strings.Join(lines, "\n")
+	for _, want := range []string{"Add caching", "1. Cache layer", "1200 in / 300 out tokens, $0.05", "#1", "Planning complete", "step ^", "Running implement..."} {
+		if !strings.Contains(frame, want) {
+			t.Errorf("view missing %q:\n%s", want, frame)
+		}
+	}
+	if strings.Contains(frame, "step A") {
+		t.Errorf("view should only show the newest events:\n%s", frame)
+	}
+
+	m.finish("implement", errors.New("agent crashed"))
+	if !strings.Contains(m.view(), "implement failed: agent crashed") {
+		t.Error("view should report the failed operation")
+	}
+}
+
+// syncBuffer collects output written concurrently by the app.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) waitFor(t *testing.T, text string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		b.mu.Lock()
+		found := strings.Contains(b.buf.String(), text)
+		b.mu.Unlock()
+		if found {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("output never contained %q", text)
+}
+
+func TestApp_Run(t *testing.T) {
+	display.SetColorsEnabled(false)
+	t.Cleanup(func() { display.SetColorsEnabled(true) })
+
+	c, err := conductor.New(
+		conductor.WithWorkDir(t.TempDir()),
+		conductor.WithAutoInit(true),
+		conductor.WithAgent("mock"),
+		conductor.WithStdout(io.Discard),
+		conductor.WithStderr(io.Discard),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	testutil.WithMockAgent(c, testutil.NewMockAgent("mock"))
+	if err := c.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	in, keys := io.Pipe()
+	var out syncBuffer
+	errCh := make(chan error, 1)
+	go func() { errCh <- New(c, in, &out).Run(context.Background()) }()
+
+	out.waitFor(t, "No active task")
+
+	_, _ = keys.Write([]byte("p"))
+	out.waitFor(t, "no active task - start one")
+
+	c.GetEventBus().PublishRaw(events.Event{Type: events.TypeProgress, Data: map[string]any{"message": "hello from the bus"}})
+	out.waitFor(t, "hello from the bus")
+
+	_, _ = keys.Write([]byte("q"))
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after q")
+	}
+}
@@ -0,0 +1,251 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/conductor"
+	"github.com/valksor/go-mehrhof/internal/display"
+	"github.com/valksor/go-mehrhof/internal/events"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+// Layout limits.
+const (
+	defaultWidth   = 80
+	defaultHeight  = 24
+	maxEvents      = 200 // Event lines kept for the stream
+	maxCheckpoints = 5   // Most recent checkpoints shown
+)
+
+// snapshot holds the task details re-read on refresh.
+type snapshot struct {
+	specs       []*storage.Specification
+	checkpoints []*vcs.Checkpoint
+	costs       storage.CostStats
+	question    string
+}
+
+// model is everything the dashboard shows.
+type model struct {
+	task      *conductor.TaskStatus
+	snap      snapshot
+	events    []string
+	operation string // Running operation, "" when idle
+	message   string // Outcome of the last operation or key press
+	width     int
+	height    int
+}
+
+func newModel() model {
+	return model{width: defaultWidth, height: defaultHeight}
+}
+
+func (m *model) setTask(task *conductor.TaskStatus, snap snapshot) {
+	m.task = task
+	m.snap = snap
+}
+
+func (m *model) start(name string) {
+	m.operation = name
+	m.message = ""
+}
+
+func (m *model) finish(name string, err error) {
+	m.operation = ""
+	if err != nil {
+		m.message = fmt.Sprintf("%s failed: %v", name, err)
+
+		return
+	}
+	m.message = name + " finished"
+}
+
+// addEvent appends e to the event stream, keeping the newest maxEvents.
+func (m *model) addEvent(e events.Event) {
+	line := formatEvent(e)
+	if line == "" {
+		return
+	}
+	ts := e.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	m.events = append(m.events, ts.Format("15:04:05")+"  "+line)
+	if len(m.events) > maxEvents {
+		m.events = m.events[len(m.events)-maxEvents:]
+	}
+}
+
+// formatEvent describes e in one line, or returns "" for events too noisy
+// for the stream (streamed agent text, usage and tool results).
+func formatEvent(e events.Event) string {
+	switch e.Type {
+	case events.TypeStateChanged:
+		return fmt.Sprintf("state %v → %v", e.Data["from"], e.Data["to"])
+	case events.TypeProgress:
+		return fmt.Sprint(e.Data["message"])
+	case events.TypeError:
+		return "error: " + fmt.Sprint(e.Data["error"])
+	case events.TypeFileChanged:
+		return fmt.Sprintf("%v %v", e.Data["operation"], e.Data["path"])
+	case events.TypeCheckpoint:
+		return fmt.Sprintf("checkpoint #%v", e.Data["checkpoint"])
+	case events.TypeAgentMessage:
+		return formatAgentEvent(e.Data["event"])
+	case events.TypeQuestionPending:
+		return "agent asked a question"
+	case events.TypePolicyViolation:
+		return fmt.Sprintf("policy %v: %v (%v)", e.Data["action"], e.Data["path"], e.Data["rule"])
+	case events.TypeConfigReloaded:
+		return fmt.Sprintf("config reloaded %v", e.Data["files"])
+	case events.TypeRateLimited:
+		return fmt.Sprintf("rate limited by %v, waiting %v", e.Data["host"], e.Data["wait"])
+	case events.TypeBlueprintReady, events.TypeTaskStarted, events.TypeTaskFinished,
+		events.TypeBranchCreated, events.TypePlanCompleted, events.TypeImplementDone, events.TypePRCreated:
+		return strings.ReplaceAll(string(e.Type), "_", " ")
+	}
+
+	return string(e.Type)
+}
+
+// formatAgentEvent describes the tool calls of a live agent event or of one
+// replayed from the event log, where it is stored as a map.
+func formatAgentEvent(v any) string {
+	var tool, description string
+	switch ae := v.(type) {
+	case agent.Event:
+		if ae.Type != agent.EventToolUse || ae.ToolCall == nil {
+			return ""
+		}
+		tool, description = ae.ToolCall.Name, ae.ToolCall.Description
+	case map[string]any:
+		if ae["type"] != string(agent.EventToolUse) {
+			return ""
+		}
+		tool, _ = ae["tool"].(string)
+		description, _ = ae["description"].(string)
+	default:
+		return ""
+	}
+
+	if description != "" {
+		return "agent: " + description
+	}
+	if tool == "" {
+		return ""
+	}
+
+	return "agent: " + tool
+}
+
+// view renders the model as a frame of at most height lines.
+func (m *model) view() string {
+	var top []string
+	add := func(format string, args ...any) {
+		top = append(top, fmt.Sprintf(format, args...))
+	}
+
+	if m.task == nil {
+		add("%s", display.Bold("mehr ui"))
+		add("%s", display.Muted("No active task - start one with mehr start <ref>"))
+	} else {
+		title := m.task.Title
+		if title == "" {
+			title = m.task.TaskID
+		}
+		add("%s  %s", display.Bold(title), display.Muted(m.task.TaskID))
+		add("State: %s   Branch: %s   Agent: %s",
+			display.FormatStateStringColored(m.task.State), orNone(m.task.Branch), orNone(m.task.Agent))
+		if m.snap.question != "" {
+			add("%s %s %s", display.Warning("Question:"), m.snap.question, display.Muted("(answer with mehr note)"))
+		}
+
+		add("")
+		add("%s", display.Bold("Specifications"))
+		if len(m.snap.specs) == 0 {
+			add("  %s", display.Muted("none yet"))
+		}
+		for _, spec := range m.snap.specs {
+			add("  %d. %s  %s", spec.Number, orNone(spec.Title), display.FormatSpecificationStatusWithIconColored(spec.Status))
+		}
+
+		costs := m.snap.costs
+		add("")
+		add("%s  %d in / %d out tokens, $%.2f", display.Bold("Usage"),
+			costs.TotalInputTokens, costs.TotalOutputTokens, costs.TotalCostUSD)
+
+		add("")
+		add("%s", display.Bold("Checkpoints"))
+		if len(m.snap.checkpoints) == 0 {
+			add("  %s", display.Muted("none yet"))
+		}
+		checkpoints := m.snap.checkpoints
+		if len(checkpoints) > maxCheckpoints {
+			checkpoints = checkpoints[len(checkpoints)-maxCheckpoints:]
+		}
+		for _, cp := range checkpoints {
+			add("  #%d  %s  %s", cp.Number, cp.Timestamp.Format("15:04"), cp.Message)
+		}
+	}
+	add("")
+	add("%s", display.Bold("Events"))
+
+	var bottom []string
+	switch {
+	case m.operation != "":
+		bottom = append(bottom, display.Info("Running "+m.operation+"..."))
+	case m.message != "":
+		bottom = append(bottom, m.message)
+	}
+	bottom = append(bottom, display.Muted("[p]lan  [i]mplement  [r]eview  [u]ndo  [q]uit"))
+
+	// The event stream gets whatever height is left, newest last.
+	room := max(m.height-len(top)-len(bottom), 1)
+	stream := m.events
+	if len(stream) > room {
+		stream = stream[len(stream)-room:]
+	}
+
+	lines := make([]string, 0, m.height)
+	lines = append(lines, top...)
+	for _, line := range stream {
+		lines = append(lines, "  "+line)
+	}
+	for range room - len(stream) {
+		lines = append(lines, "")
+	}
+	lines = append(lines, bottom...)
+
+	for i, line := range lines {
+		lines[i] = truncate(line, m.width)
+	}
+
+	return strings.Join(lines, "\r\n")
+}
+
+// truncate cuts plain lines longer than width. Colored lines are left alone
+// because their escape sequences do not take up columns.
+func truncate(line string, width int) string {
+	if width <= 0 || strings.Contains(line, "\x1b[") {
+		return line
+	}
+	runes := []rune(line)
+	if len(runes) <= width {
+		return line
+	}
+
+	return string(runes[:width-1]) + "…"
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
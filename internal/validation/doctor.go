@@ -0,0 +1,361 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+// Error codes for environment diagnostics.
+const (
+	CodeGitOK               = "GIT_OK"
+	CodeGitMissing          = "GIT_MISSING"
+	CodeGitOutdated         = "GIT_OUTDATED"
+	CodeNotGitRepo          = "NOT_GIT_REPO"
+	CodeWorktreeOK          = "WORKTREE_OK"
+	CodeWorktreeUnsupported = "WORKTREE_UNSUPPORTED"
+	CodeConfigOK            = "CONFIG_OK"
+	CodeAgentOK             = "AGENT_OK"
+	CodeAgentMissing        = "AGENT_MISSING"
+	CodeAgentUnavailable    = "AGENT_UNAVAILABLE"
+	CodeTokenOK             = "TOKEN_OK"
+	CodeTokenMissing        = "TOKEN_MISSING"
+	CodeTokenRejected       = "TOKEN_REJECTED"
+	CodeTokenUnchecked      = "TOKEN_UNCHECKED"
+	CodeRateLimitLow        = "RATE_LIMIT_LOW"
+	CodeWorkspaceOK         = "WORKSPACE_OK"
+	CodeActiveTaskCorrupt   = "ACTIVE_TASK_CORRUPT"
+	CodeActiveTaskOrphaned  = "ACTIVE_TASK_ORPHANED"
+	CodeWorktreeMissing     = "WORKTREE_MISSING"
+	CodeWorkDirBroken       = "WORK_DIR_BROKEN"
+	CodeWorkDirIncomplete   = "WORK_DIR_INCOMPLETE"
+	CodeWorktreeDetached    = "WORKTREE_DETACHED"
+)
+
+// MinGitVersion is the oldest git with every worktree command mehrhof uses
+// (git worktree remove arrived in 2.17).
+const MinGitVersion = "2.17.0"
+
+// lowRateLimit is the number of remaining API requests below which the
+// provider's rate limit is reported.
+const lowRateLimit = 100
+
+// ProviderToken is a provider token for Doctor to check.
+type ProviderToken struct {
+	Provider string // "github" or "gitlab"
+	Host     string // API host, empty for the public instance
+	Token    string // Resolved token, empty when none is configured
+	EnvVar   string // Environment variable that sets the token, e.g. GITHUB_TOKEN
+}
+
+// DoctorOptions configures the environment checks of Doctor.
+type DoctorOptions struct {
+	Agents  *agent.Registry // Agents whose CLIs are checked
+	Tokens  []ProviderToken // Provider tokens to check
+	Offline bool            // Skip the live token checks
+}
+
+// Doctor checks the environment mehrhof runs in: git and worktree support,
+// the workspace configuration, agent CLIs, provider tokens and the state of
+// the workspace. Passed checks are recorded with SeverityOK, problems with a
+// suggestion how to fix them.
+func (v *Validator) Doctor(ctx context.Context, opts DoctorOptions) (*Result, error) {
+	result := NewResult()
+
+	ws, err := storage.OpenWorkspace(v.workspacePath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open workspace: %w", err)
+	}
+
+	repo := v.checkGit(ctx, result)
+
+	wsResult, err := v.validateWorkspace(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("workspace validation: %w", err)
+	}
+	if wsResult.Errors == 0 && wsResult.Warnings == 0 {
+		result.AddOK(CodeConfigOK, "Workspace configuration is valid", "config")
+	}
+	result.Merge(wsResult)
+
+	cfg, err := ws.LoadConfig()
+	if err != nil {
+		cfg = storage.NewDefaultWorkspaceConfig()
+	}
+	if opts.Agents != nil {
+		checkAgents(cfg, opts.Agents, result)
+	}
+	for _, token := range opts.Tokens {
+		checkProviderToken(ctx, token, opts.Offline, result)
+	}
+	checkWorkspaceState(ctx, ws, repo, result)
+
+	if v.opts.Strict && result.Warnings > 0 {
+		result.Valid = false
+	}
+
+	return result, nil
+}
+
+// checkGit checks the git version and, inside a repository, that worktrees
+// can be listed. It returns the repository, or nil outside of one.
+func (v *Validator) checkGit(ctx context.Context, result *Result) *vcs.Git {
+	version, err := vcs.Version(ctx)
+	if err != nil {
+		result.AddErrorWithSuggestion(CodeGitMissing, fmt.Sprintf("git is not available: %v", err), "git", "",
+			"Install git "+MinGitVersion+" or newer and make sure it is on PATH")
+
+		return nil
+	}
+	if compareVersions(version, MinGitVersion) < 0 {
+		result.AddWarningWithSuggestion(CodeGitOutdated,
+			fmt.Sprintf("git %s is older than %s; worktree commands may fail", version, MinGitVersion), "git", "",
+			"Upgrade git to "+MinGitVersion+" or newer")
+	} else {
+		result.AddOK(CodeGitOK, "git "+version, "git")
+	}
+
+	repo, err := vcs.New(ctx, v.workspacePath)
+	if err != nil {
+		result.AddInfo(CodeNotGitRepo, "Not a git repository; branches, checkpoints and worktrees are disabled", "git", "")
+
+		return nil
+	}
+	if _, err := repo.ListWorktrees(ctx); err != nil {
+		result.AddWarningWithSuggestion(CodeWorktreeUnsupported, fmt.Sprintf("Worktrees are not usable: %v", err), "git.worktree", "",
+			"Run 'git worktree list' to see the problem, or start tasks without --worktree")
+
+		return repo
+	}
+	result.AddOK(CodeWorktreeOK, "Worktrees are supported", "git.worktree")
+
+	return repo
+}
+
+// compareVersions compares dotted version numbers like "2.39.3" and
+// "2.43.0.windows.1", ignoring anything after the third number.
+func compareVersions(a, b string) int {
+	canonical := func(version string) string {
+		parts := strings.Split(version, ".")
+		numbers := make([]string, 0, 3)
+		for _, part := range parts {
+			if len(numbers) == 3 || part == "" || strings.Trim(part, "0123456789") != "" {
+				break
+			}
+			numbers = append(numbers, part)
+		}
+
+		return "v" + strings.Join(numbers, ".")
+	}
+
+	return semver.Compare(canonical(a), canonical(b))
+}
+
+// checkAgents checks that the default agent (following aliases) is installed
+// and notes the other agents whose CLI is missing.
+func checkAgents(cfg *storage.WorkspaceConfig, reg *agent.Registry, result *Result) {
+	defaultAgent := cfg.Agent.Default
+	seen := map[string]bool{}
+	for alias, ok := cfg.Agents[defaultAgent]; ok && !seen[defaultAgent]; alias, ok = cfg.Agents[defaultAgent] {
+		seen[defaultAgent] = true
+		defaultAgent = alias.Extends
+	}
+
+	var available []string
+	for _, name := range reg.List() {
+		ag, err := reg.Get(name)
+		if err != nil {
+			continue
+		}
+		if err := ag.Available(); err == nil {
+			available = append(available, name)
+			result.AddOK(CodeAgentOK, fmt.Sprintf("Agent %s is available", name), "agent."+name)
+		} else if name == defaultAgent {
+			result.AddErrorWithSuggestion(CodeAgentUnavailable,
+				fmt.Sprintf("Default agent %s is not available: %v", name, err), "agent.default", "",
+				fmt.Sprintf("Install the %s CLI, or set agent.default to an installed agent", name))
+		} else {
+			result.AddInfo(CodeAgentUnavailable, fmt.Sprintf("Agent %s is not installed", name), "agent."+name, "")
+		}
+	}
+
+	if defaultAgent != "" && !slices.Contains(reg.List(), defaultAgent) {
+		result.AddErrorWithSuggestion(CodeAgentMissing,
+			fmt.Sprintf("Default agent %q is not a known agent", cfg.Agent.Default), "agent.default", "",
+			"Set agent.default to one of: "+strings.Join(reg.List(), ", "))
+	}
+	if len(available) == 0 {
+		result.AddErrorWithSuggestion(CodeAgentUnavailable, "No agent CLI is installed", "agent", "",
+			"Install an agent CLI such as claude and make sure it is on PATH")
+	}
+}
+
+// checkProviderToken checks that a provider token is set and, unless
+// offline, that the provider accepts it and has requests left.
+func checkProviderToken(ctx context.Context, token ProviderToken, offline bool, result *Result) {
+	path := token.Provider + ".token"
+	login := fmt.Sprintf("Run 'mehr %s login' or set %s", token.Provider, token.EnvVar)
+	if token.Token == "" {
+		result.AddWarningWithSuggestion(CodeTokenMissing, fmt.Sprintf("No %s token is configured", token.Provider), path, "", login)
+
+		return
+	}
+	if offline {
+		result.AddInfo(CodeTokenUnchecked, fmt.Sprintf("%s token is set (not checked offline)", token.Provider), path, "")
+
+		return
+	}
+
+	status, err := CheckToken(ctx, token.Provider, token.Host, token.Token)
+	switch {
+	case errors.Is(err, ErrTokenRejected):
+		result.AddErrorWithSuggestion(CodeTokenRejected, fmt.Sprintf("The %s token is invalid or expired: %v", token.Provider, err), path, "",
+			login+" with a new token")
+	case err != nil && status != nil:
+		// Rate limited: the token may be fine, the provider will not say
+		result.AddWarningWithSuggestion(CodeRateLimitLow, err.Error(), path, "",
+			"Wait for the rate limit to reset before running tasks against "+token.Provider)
+	case err != nil:
+		result.AddWarningWithSuggestion(CodeTokenUnchecked, fmt.Sprintf("Could not check the %s token: %v", token.Provider, err), path, "",
+			"Check your network connection, or run with --offline")
+	default:
+		result.AddOK(CodeTokenOK, fmt.Sprintf("%s token is valid (%s)", token.Provider, status.User), path)
+		if status.RateLimitRemaining >= 0 && status.RateLimitRemaining < lowRateLimit {
+			result.AddWarningWithSuggestion(CodeRateLimitLow,
+				fmt.Sprintf("Only %d %s API requests left until %s", status.RateLimitRemaining, token.Provider, status.RateLimitReset.Format(time.Kitchen)),
+				path, "", "Avoid syncing many tasks until the rate limit resets")
+		}
+	}
+}
+
+// checkWorkspaceState finds an active task whose work directory is gone,
+// work directories that cannot be loaded or lack files, and task worktrees
+// the task no longer points at.
+func checkWorkspaceState(ctx context.Context, ws *storage.Workspace, repo *vcs.Git, result *Result) {
+	problems := result.Errors + result.Warnings
+	fix := "Run 'mehr doctor --fix'"
+
+	if ws.HasActiveTask() {
+		active, err := ws.LoadActiveTask()
+		switch {
+		case err != nil:
+			result.AddErrorWithSuggestion(CodeActiveTaskCorrupt, fmt.Sprintf("Active task file is unreadable: %v", err), ".active_task", ws.ActiveTaskPath(),
+				fix+" to restore it from its backup or remove it")
+		case !ws.WorkExists(active.ID):
+			result.AddErrorWithSuggestion(CodeActiveTaskOrphaned, fmt.Sprintf("Active task %s has no work directory", active.ID), ".active_task", ws.ActiveTaskPath(),
+				fix+" to remove "+ws.ActiveTaskPath()+", then start the task again")
+		case active.WorktreePath != "":
+			if _, err := os.Stat(active.WorktreePath); err != nil {
+				result.AddWarningWithSuggestion(CodeWorktreeMissing, fmt.Sprintf("Worktree of active task %s is missing: %s", active.ID, active.WorktreePath), ".active_task", ws.ActiveTaskPath(),
+					"Run 'git worktree prune' and 'mehr abandon', or restore the worktree")
+			}
+		}
+	}
+
+	works, broken, err := loadWorks(ws)
+	if err != nil {
+		result.AddError(CodeWorkDirBroken, fmt.Sprintf("Cannot list work directories: %v", err), "work", ws.WorkRoot())
+
+		return
+	}
+	for _, b := range broken {
+		result.AddErrorWithSuggestion(CodeWorkDirBroken, fmt.Sprintf("Task %s cannot be loaded: %v", b.id, b.err), "work/"+b.id, ws.WorkPath(b.id),
+			fix+" to restore work.yaml from its backup, or remove "+ws.WorkPath(b.id)+" if the task is no longer needed")
+	}
+	for _, work := range works {
+		id := work.Metadata.ID
+		if missing := ws.MissingWorkFiles(id); len(missing) > 0 {
+			result.AddWarningWithSuggestion(CodeWorkDirIncomplete, fmt.Sprintf("Task %s is missing %s", id, strings.Join(missing, ", ")), "work/"+id, ws.WorkPath(id),
+				fix+" to recreate them")
+		}
+	}
+	for _, d := range detachedWorktrees(ctx, repo, works) {
+		result.AddWarningWithSuggestion(CodeWorktreeDetached, fmt.Sprintf("Worktree %s on branch %s is not linked to task %s", d.path, d.work.Git.Branch, d.work.Metadata.ID),
+			"work/"+d.work.Metadata.ID, ws.WorkPath(d.work.Metadata.ID), fix+" to link it")
+	}
+
+	if result.Errors+result.Warnings == problems {
+		result.AddOK(CodeWorkspaceOK, fmt.Sprintf("Workspace state is consistent (%d task(s))", len(works)), "work")
+	}
+}
+
+// brokenWork is a work directory whose work file cannot be loaded.
+type brokenWork struct {
+	id  string
+	err error
+}
+
+// loadWorks loads every work directory. It reads the work root directly,
+// as ListWorks skips directories without a work file.
+func loadWorks(ws *storage.Workspace) ([]*storage.TaskWork, []brokenWork, error) {
+	entries, err := os.ReadDir(ws.WorkRoot())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+
+	var works []*storage.TaskWork
+	var broken []brokenWork
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		work, err := ws.LoadWork(entry.Name())
+		if err != nil {
+			broken = append(broken, brokenWork{id: entry.Name(), err: err})
+
+			continue
+		}
+		works = append(works, work)
+	}
+
+	return works, broken, nil
+}
+
+// detachedWorktree is a worktree on a task's branch that the task does not
+// point at.
+type detachedWorktree struct {
+	path string
+	work *storage.TaskWork
+}
+
+// detachedWorktrees finds linked worktrees checked out on the branch of a
+// task that has no worktree path, or one that no longer exists.
+func detachedWorktrees(ctx context.Context, repo *vcs.Git, works []*storage.TaskWork) []detachedWorktree {
+	if repo == nil {
+		return nil
+	}
+	worktrees, err := repo.ListWorktrees(ctx)
+	if err != nil {
+		return nil
+	}
+
+	var detached []detachedWorktree
+	for _, wt := range worktrees {
+		if wt.Main || wt.Bare || wt.Prunable || wt.Branch == "" {
+			continue
+		}
+		for _, work := range works {
+			if work.Git.Branch != wt.Branch || work.Git.WorktreePath == wt.Path {
+				continue
+			}
+			if work.Git.WorktreePath != "" {
+				if _, err := os.Stat(work.Git.WorktreePath); err == nil {
+					continue
+				}
+			}
+			detached = append(detached, detachedWorktree{path: wt.Path, work: work})
+		}
+	}
+
+	return detached
+}
@@ -0,0 +1,150 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+)
+
+// Repair is a change Repair made to the workspace.
+type Repair struct {
+	Code    string `json:"code"`           // Code of the finding the repair fixes
+	Message string `json:"message"`        // What was changed
+	Path    string `json:"path,omitempty"` // File or directory that was changed
+}
+
+// Repair fixes the workspace problems Doctor reports that have a safe
+// automatic fix: malformed config.yaml, work.yaml and .active_task files are
+// restored from the backup written on every save, an .active_task pointing
+// at a missing work directory is removed, missing specifications/, sessions/
+// and notes.md are recreated, and worktrees on a task's branch are linked
+// back to the task. Problems without a fix are left for Doctor to report.
+func (v *Validator) Repair(ctx context.Context) ([]Repair, error) {
+	ws, err := storage.OpenWorkspace(v.workspacePath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open workspace: %w", err)
+	}
+
+	repairs := make([]Repair, 0)
+	add := func(code, path, format string, args ...any) {
+		repairs = append(repairs, Repair{Code: code, Message: fmt.Sprintf(format, args...), Path: path})
+	}
+
+	if ws.HasConfig() {
+		if _, err := ws.LoadSharedConfig(); err != nil && storage.RestoreBackup(ws.ConfigPath()) == nil {
+			add(CodeYAMLSyntax, ws.ConfigPath(), "Restored config.yaml from its backup")
+		}
+	}
+
+	entries, err := os.ReadDir(ws.WorkRoot())
+	if err != nil && !os.IsNotExist(err) {
+		return repairs, fmt.Errorf("read work directories: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		id := entry.Name()
+		if _, err := ws.LoadWork(id); err != nil {
+			workFile := filepath.Join(ws.WorkPath(id), "work.yaml")
+			if storage.RestoreBackup(workFile) != nil {
+				continue
+			}
+			if _, err := ws.LoadWork(id); err != nil {
+				continue
+			}
+			add(CodeWorkDirBroken, workFile, "Restored work.yaml of task %s from its backup", id)
+		}
+		created, err := ws.RepairWorkDir(id)
+		if err != nil {
+			return repairs, fmt.Errorf("repair task %s: %w", id, err)
+		}
+		if len(created) > 0 {
+			add(CodeWorkDirIncomplete, ws.WorkPath(id), "Recreated %s of task %s", strings.Join(created, ", "), id)
+		}
+	}
+
+	if err := repairActiveTask(ws, add); err != nil {
+		return repairs, err
+	}
+
+	repo, err := vcs.New(ctx, v.workspacePath)
+	if err != nil {
+		return repairs, nil //nolint:nilerr // Not a git repository; no worktrees to link
+	}
+	works, _, err := loadWorks(ws)
+	if err != nil {
+		return repairs, fmt.Errorf("load tasks: %w", err)
+	}
+	if err := relinkWorktrees(ctx, ws, repo, works, add); err != nil {
+		return repairs, err
+	}
+
+	return repairs, nil
+}
+
+// repairActiveTask restores an unreadable .active_task from its backup, and
+// removes it when it cannot be restored or its work directory is gone.
+func repairActiveTask(ws *storage.Workspace, add func(code, path, format string, args ...any)) error {
+	if !ws.HasActiveTask() {
+		return nil
+	}
+
+	path := ws.ActiveTaskPath()
+	active, err := ws.LoadActiveTask()
+	if err != nil {
+		if storage.RestoreBackup(path) == nil {
+			if active, err = ws.LoadActiveTask(); err == nil {
+				add(CodeActiveTaskCorrupt, path, "Restored .active_task from its backup")
+			}
+		}
+		if err != nil {
+			if err := ws.ClearActiveTask(); err != nil {
+				return fmt.Errorf("remove unreadable active task: %w", err)
+			}
+			add(CodeActiveTaskCorrupt, path, "Removed the unreadable .active_task")
+
+			return nil
+		}
+	}
+
+	if !ws.WorkExists(active.ID) {
+		if err := ws.ClearActiveTask(); err != nil {
+			return fmt.Errorf("remove orphaned active task: %w", err)
+		}
+		add(CodeActiveTaskOrphaned, path, "Removed .active_task of task %s, whose work directory is gone", active.ID)
+	}
+
+	return nil
+}
+
+// relinkWorktrees points tasks at the worktree checked out on their branch,
+// in work.yaml and, for the active task, in .active_task.
+func relinkWorktrees(ctx context.Context, ws *storage.Workspace, repo *vcs.Git, works []*storage.TaskWork, add func(code, path, format string, args ...any)) error {
+	for _, d := range detachedWorktrees(ctx, repo, works) {
+		id := d.work.Metadata.ID
+		d.work.Git.WorktreePath = d.path
+		if err := ws.SaveWork(d.work); err != nil {
+			return fmt.Errorf("link worktree of task %s: %w", id, err)
+		}
+
+		active, err := ws.LoadActiveTask()
+		if err == nil && active.ID == id && active.WorktreePath != d.path {
+			active.WorktreePath = d.path
+			if err := ws.SaveActiveTask(active); err != nil {
+				return fmt.Errorf("link worktree of active task %s: %w", id, err)
+			}
+		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("load active task: %w", err)
+		}
+		add(CodeWorktreeDetached, d.path, "Linked worktree %s to task %s", d.path, id)
+	}
+
+	return nil
+}
@@ -13,6 +13,7 @@ const (
 	SeverityError   Severity = "error"
 	SeverityWarning Severity = "warning"
 	SeverityInfo    Severity = "info"
+	SeverityOK      Severity = "ok" // A diagnostic check that passed
 )
 
 // Finding represents a single validation issue.
@@ -66,6 +67,11 @@ func (r *Result) AddInfo(code, message, path, file string) {
 	r.addFinding(SeverityInfo, code, message, path, file, "")
 }
 
+// AddOK records a diagnostic check that passed.
+func (r *Result) AddOK(code, message, path string) {
+	r.addFinding(SeverityOK, code, message, path, "", "")
+}
+
 func (r *Result) addFinding(severity Severity, code, message, path, file, suggestion string) {
 	finding := Finding{
 		Severity:   severity,
@@ -83,8 +89,8 @@ func (r *Result) addFinding(severity Severity, code, message, path, file, sugges
 		r.Valid = false
 	case SeverityWarning:
 		r.Warnings++
-	case SeverityInfo:
-		// Info level findings don't affect validation result
+	case SeverityInfo, SeverityOK:
+		// Info level findings and passed checks don't affect validation result
 	}
 }
 
@@ -0,0 +1,122 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrTokenRejected is returned by CheckToken when the provider refuses the
+// token.
+var ErrTokenRejected = errors.New("token rejected")
+
+// tokenCheckTimeout bounds a single token check request.
+const tokenCheckTimeout = 10 * time.Second
+
+// TokenStatus describes a token the provider accepted.
+type TokenStatus struct {
+	User               string    // Login of the token's user
+	RateLimitRemaining int       // Requests left in the window, -1 when unknown
+	RateLimitReset     time.Time // End of the rate-limit window, zero when unknown
+}
+
+// TokenCheckURL returns the endpoint that identifies the user of a token for
+// github or gitlab. host is empty for the public instance, a bare host name,
+// or a base URL with scheme.
+func TokenCheckURL(provider, host string) (string, error) {
+	base := strings.TrimSuffix(host, "/")
+	if base != "" && !strings.Contains(base, "://") {
+		base = "https://" + base
+	}
+
+	switch provider {
+	case "github":
+		if base == "" || base == "https://github.com" {
+			return "https://api.github.com/user", nil
+		}
+
+		return base + "/api/v3/user", nil
+	case "gitlab":
+		if base == "" {
+			base = "https://gitlab.com"
+		}
+
+		return base + "/api/v4/user", nil
+	}
+
+	return "", fmt.Errorf("token check not supported for provider %q", provider)
+}
+
+// CheckToken asks the provider who the token belongs to. It returns
+// ErrTokenRejected when the provider answers 401 or 403 with requests left,
+// and other errors when the provider cannot be reached or is rate limited.
+func CheckToken(ctx context.Context, provider, host, token string) (*TokenStatus, error) {
+	url, err := TokenCheckURL(provider, host)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, tokenCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	if provider == "gitlab" {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("reach %s: %w", provider, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	status := &TokenStatus{RateLimitRemaining: -1}
+	readRateLimit(resp.Header, status)
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || (resp.StatusCode == http.StatusForbidden && status.RateLimitRemaining == 0):
+		return status, fmt.Errorf("%s rate limit exceeded, resets at %s", provider, status.RateLimitReset.Format(time.Kitchen))
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("%w by %s (HTTP %d)", ErrTokenRejected, provider, resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s answered HTTP %d", provider, resp.StatusCode)
+	}
+
+	var user struct {
+		Login    string `json:"login"`    // GitHub
+		Username string `json:"username"` // GitLab
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return nil, fmt.Errorf("decode %s user: %w", provider, err)
+	}
+	status.User = user.Login
+	if status.User == "" {
+		status.User = user.Username
+	}
+
+	return status, nil
+}
+
+// readRateLimit reads the rate-limit headers of GitHub (X-RateLimit-*) and
+// GitLab (RateLimit-*), where present.
+func readRateLimit(h http.Header, status *TokenStatus) {
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		if remaining, err := strconv.Atoi(h.Get(prefix + "Remaining")); err == nil {
+			status.RateLimitRemaining = remaining
+		}
+		if reset, err := strconv.ParseInt(h.Get(prefix+"Reset"), 10, 64); err == nil {
+			status.RateLimitReset = time.Unix(reset, 0)
+		}
+	}
+}
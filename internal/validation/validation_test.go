@@ -1,12 +1,21 @@
 package validation
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 	"testing"
 
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/config"
 	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/testutil"
+	"github.com/valksor/go-mehrhof/internal/vcs"
 )
 
 func TestResultAddError(t *testing.T) {
@@ -273,6 +282,53 @@ func TestValidateGitSettings(t *testing.T) {
 			git:          storage.GitSettings{BranchPattern: "{key}", CommitPrefix: "[{key}]"},
 			wantWarnings: 0,
 		},
+		{
+			name:         "worktree naming",
+			git:          storage.GitSettings{BranchPattern: "{key}", WorktreeDir: "~/worktrees", WorktreePattern: "{slug}"},
+			wantWarnings: 0,
+		},
+		{
+			name:         "unknown worktree placeholder",
+			git:          storage.GitSettings{BranchPattern: "{key}", WorktreePattern: "{repo}-{slug}"},
+			wantWarnings: 1,
+		},
+		{
+			name:       "other user's home",
+			git:        storage.GitSettings{BranchPattern: "{key}", WorktreeDir: "~bob/worktrees"},
+			wantErrors: 1,
+		},
+		{
+			name: "conventional commit messages",
+			git:  storage.GitSettings{BranchPattern: "{key}", CommitMessageStyle: "conventional"},
+		},
+		{
+			name:       "unknown commit message style",
+			git:        storage.GitSettings{BranchPattern: "{key}", CommitMessageStyle: "gitmoji"},
+			wantErrors: 1,
+		},
+		{
+			name: "ssh signing",
+			git:  storage.GitSettings{BranchPattern: "{key}", SignCommits: true, SigningFormat: "ssh", SigningKey: "~/.ssh/id_ed25519.pub"},
+		},
+		{
+			name:       "unknown signing format",
+			git:        storage.GitSettings{BranchPattern: "{key}", SignCommits: true, SigningFormat: "pgp"},
+			wantErrors: 1,
+		},
+		{
+			name: "checkpoint globs",
+			git:  storage.GitSettings{BranchPattern: "{key}", CheckpointInclude: []string{"src/**", "*.go"}, CheckpointExclude: []string{"*.swp"}},
+		},
+		{
+			name:       "invalid checkpoint glob",
+			git:        storage.GitSettings{BranchPattern: "{key}", CheckpointExclude: []string{"[*.swp"}},
+			wantErrors: 1,
+		},
+		{
+			name:         "signing key without sign_commits",
+			git:          storage.GitSettings{BranchPattern: "{key}", SigningKey: "ABCD1234"},
+			wantWarnings: 1,
+		},
 	}
 
 	for _, tt := range tests {
@@ -353,6 +409,7 @@ func TestValidateWorkflowSettings(t *testing.T) {
 		name         string
 		workflow     storage.WorkflowSettings
 		wantWarnings int
+		wantErrors   int
 	}{
 		{
 			name:         "valid retention days",
@@ -369,6 +426,39 @@ func TestValidateWorkflowSettings(t *testing.T) {
 			workflow:     storage.WorkflowSettings{SessionRetentionDays: 400},
 			wantWarnings: 1,
 		},
+		{
+			name:     "towncrier changelog",
+			workflow: storage.WorkflowSettings{SessionRetentionDays: 30, Changelog: storage.ChangelogSettings{Enabled: true, Format: "towncrier"}},
+		},
+		{
+			name:       "unknown changelog format",
+			workflow:   storage.WorkflowSettings{SessionRetentionDays: 30, Changelog: storage.ChangelogSettings{Format: "news"}},
+			wantErrors: 1,
+		},
+		{
+			name:     "consensus review",
+			workflow: storage.WorkflowSettings{SessionRetentionDays: 30, Review: storage.ReviewSettings{Agents: []string{"claude", "codex", "gemini"}, MinAgreement: 2}},
+		},
+		{
+			name:       "consensus review with one agent",
+			workflow:   storage.WorkflowSettings{SessionRetentionDays: 30, Review: storage.ReviewSettings{Agents: []string{"claude"}}},
+			wantErrors: 1,
+		},
+		{
+			name:       "consensus review agent listed twice",
+			workflow:   storage.WorkflowSettings{SessionRetentionDays: 30, Review: storage.ReviewSettings{Agents: []string{"claude", "claude"}}},
+			wantErrors: 1,
+		},
+		{
+			name:       "min agreement above agent count",
+			workflow:   storage.WorkflowSettings{SessionRetentionDays: 30, Review: storage.ReviewSettings{Agents: []string{"claude", "codex"}, MinAgreement: 3}},
+			wantErrors: 1,
+		},
+		{
+			name:       "negative spec critique rounds",
+			workflow:   storage.WorkflowSettings{SessionRetentionDays: 30, SpecCritique: true, SpecCritiqueRounds: -1},
+			wantErrors: 1,
+		},
 	}
 
 	for _, tt := range tests {
@@ -378,6 +468,179 @@ func TestValidateWorkflowSettings(t *testing.T) {
 			if result.Warnings != tt.wantWarnings {
 				t.Errorf("expected %d warnings, got %d", tt.wantWarnings, result.Warnings)
 			}
+			if result.Errors != tt.wantErrors {
+				t.Errorf("expected %d errors, got %d", tt.wantErrors, result.Errors)
+			}
+		})
+	}
+}
+
+func TestValidateContextSettings(t *testing.T) {
+	tests := []struct {
+		name       string
+		context    storage.ContextSettings
+		wantErrors int
+	}{
+		{
+			name:       "empty",
+			wantErrors: 0,
+		},
+		{
+			name: "valid globs and steps",
+			context: storage.ContextSettings{
+				IncludeGlobs: []string{"CONTRIBUTING.md", "docs/*.md"},
+				Steps:        map[string]storage.ContextStepSettings{"planning": {}, "reviewing": {IncludeGlobs: []string{".editorconfig"}}},
+			},
+			wantErrors: 0,
+		},
+		{
+			name:       "invalid glob",
+			context:    storage.ContextSettings{IncludeGlobs: []string{"docs/[.md"}},
+			wantErrors: 1,
+		},
+		{
+			name:       "unknown step",
+			context:    storage.ContextSettings{Steps: map[string]storage.ContextStepSettings{"testing": {}}},
+			wantErrors: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewResult()
+			validateContextSettings(tt.context, "config.yaml", result)
+			if result.Errors != tt.wantErrors {
+				t.Errorf("expected %d errors, got %d", tt.wantErrors, result.Errors)
+			}
+		})
+	}
+}
+
+func TestValidateGuardrailSettings(t *testing.T) {
+	tests := []struct {
+		name       string
+		guardrails storage.GuardrailSettings
+		wantErrors int
+	}{
+		{
+			name:       "empty",
+			wantErrors: 0,
+		},
+		{
+			name: "valid policy",
+			guardrails: storage.GuardrailSettings{
+				Deny:      []string{"deploy/**", "*.pem"},
+				Allow:     []string{"deploy/README.md"},
+				Protected: []string{"go.sum"},
+				Secrets:   "redact",
+			},
+			wantErrors: 0,
+		},
+		{
+			name:       "invalid glob",
+			guardrails: storage.GuardrailSettings{Deny: []string{"keys/[.pem"}},
+			wantErrors: 1,
+		},
+		{
+			name:       "unknown secrets handling",
+			guardrails: storage.GuardrailSettings{Secrets: "ignore"},
+			wantErrors: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewResult()
+			validateGuardrailSettings(tt.guardrails, "config.yaml", result)
+			if result.Errors != tt.wantErrors {
+				t.Errorf("expected %d errors, got %d", tt.wantErrors, result.Errors)
+			}
+		})
+	}
+}
+
+func TestValidateAgentSandbox(t *testing.T) {
+	tests := []struct {
+		name       string
+		settings   storage.AgentSettings
+		wantErrors int
+	}{
+		{
+			name:       "unset",
+			wantErrors: 0,
+		},
+		{
+			name:       "bwrap",
+			settings:   storage.AgentSettings{Sandbox: "bwrap"},
+			wantErrors: 0,
+		},
+		{
+			name:       "docker with image",
+			settings:   storage.AgentSettings{Sandbox: "docker", SandboxImage: "ghcr.io/acme/claude:latest"},
+			wantErrors: 0,
+		},
+		{
+			name:       "docker without image",
+			settings:   storage.AgentSettings{Sandbox: "docker"},
+			wantErrors: 1,
+		},
+		{
+			name:       "unknown sandbox",
+			settings:   storage.AgentSettings{Sandbox: "firejail"},
+			wantErrors: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewResult()
+			validateAgentSettings(tt.settings, "config.yaml", []string{"claude"}, nil, result)
+			if result.Errors != tt.wantErrors {
+				t.Errorf("expected %d errors, got %d", tt.wantErrors, result.Errors)
+			}
+		})
+	}
+}
+
+func TestValidateAgentPhaseTimeouts(t *testing.T) {
+	tests := []struct {
+		name       string
+		settings   storage.AgentSettings
+		wantErrors int
+	}{
+		{
+			name:     "valid",
+			settings: storage.AgentSettings{PhaseTimeouts: map[string]int{"implementing": 1200}, OnTimeout: "pause"},
+		},
+		{
+			name:       "unknown phase",
+			settings:   storage.AgentSettings{PhaseTimeouts: map[string]int{"documenting": 60}},
+			wantErrors: 1,
+		},
+		{
+			name:       "negative timeout",
+			settings:   storage.AgentSettings{PhaseTimeouts: map[string]int{"planning": -1}},
+			wantErrors: 1,
+		},
+		{
+			name:       "unknown policy",
+			settings:   storage.AgentSettings{OnTimeout: "ignore"},
+			wantErrors: 1,
+		},
+		{
+			name:       "negative cache ttl",
+			settings:   storage.AgentSettings{Cache: storage.AgentCacheSettings{Enabled: true, TTLHours: -1}},
+			wantErrors: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewResult()
+			validateAgentSettings(tt.settings, "config.yaml", []string{"claude"}, nil, result)
+			if result.Errors != tt.wantErrors {
+				t.Errorf("expected %d errors, got %d", tt.wantErrors, result.Errors)
+			}
 		})
 	}
 }
@@ -723,6 +986,56 @@ func TestValidatorValidate_NoConfig(t *testing.T) {
 	}
 }
 
+func TestValidatorValidate_LocalConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	ws, err := storage.OpenWorkspace(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := os.MkdirAll(ws.TaskRoot(), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(ws.ConfigPath(), []byte("git:\n  branch_pattern: \"{bogus}\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ws.LocalConfigPath(), []byte("agent:\n  default: nosuchagent\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	v := New(tmpDir, Options{})
+	v.SetBuiltInAgents([]string{"claude"})
+	result, err := v.Validate(t.Context())
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	files := map[string]string{}
+	for _, f := range result.Findings {
+		files[f.Code] = f.File
+	}
+	if files[CodeGitPatternInvalid] != ws.ConfigPath() {
+		t.Errorf("%s reported in %q, want %q", CodeGitPatternInvalid, files[CodeGitPatternInvalid], ws.ConfigPath())
+	}
+	if files[CodeInvalidEnum] != ws.LocalConfigPath() {
+		t.Errorf("%s reported in %q, want %q", CodeInvalidEnum, files[CodeInvalidEnum], ws.LocalConfigPath())
+	}
+	// The shared finding is not repeated for the local layer
+	if result.Warnings != 1 || result.Errors != 1 {
+		t.Errorf("got %d error(s), %d warning(s); want 1, 1: %+v", result.Errors, result.Warnings, result.Findings)
+	}
+
+	if err := os.WriteFile(ws.LocalConfigPath(), []byte("agent: [\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = v.Validate(t.Context())
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if result.Valid {
+		t.Error("expected invalid result for malformed config.local.yaml")
+	}
+}
+
 // Tests for validateStorageSettings
 
 func TestValidateStorageSettings_ValidPaths(t *testing.T) {
@@ -881,6 +1194,40 @@ func TestValidateStorageSettings_InvalidCharacters(t *testing.T) {
 	}
 }
 
+func TestValidateStorageSettings_Remote(t *testing.T) {
+	tests := []struct {
+		name     string
+		remote   *storage.RemoteStorageSettings
+		wantCode string // "" means valid
+	}{
+		{"s3", &storage.RemoteStorageSettings{Type: "s3", Bucket: "team"}, ""},
+		{"gcs with keys", &storage.RemoteStorageSettings{Type: "gcs", Bucket: "team", AccessKey: "${K}", SecretKey: "${S}"}, ""},
+		{"local", &storage.RemoteStorageSettings{Type: "local", Path: "/mnt/shared"}, ""},
+		{"unknown type", &storage.RemoteStorageSettings{Type: "ftp"}, CodeInvalidEnum},
+		{"s3 without bucket", &storage.RemoteStorageSettings{Type: "s3"}, CodeRemoteStorage},
+		{"only access key", &storage.RemoteStorageSettings{Type: "s3", Bucket: "team", AccessKey: "AKIA"}, CodeRemoteStorage},
+		{"local without path", &storage.RemoteStorageSettings{Type: "local"}, CodeRemoteStorage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewResult()
+			validateStorageSettings(storage.StorageSettings{Remote: tt.remote}, "config.yaml", result)
+
+			if tt.wantCode == "" {
+				if !result.Valid {
+					t.Errorf("unexpected findings: %+v", result.Findings)
+				}
+
+				return
+			}
+			if result.Valid || result.Findings[0].Code != tt.wantCode {
+				t.Errorf("findings = %+v, want %s", result.Findings, tt.wantCode)
+			}
+		})
+	}
+}
+
 func TestValidateWorkspaceConfig_WithStorageSettings(t *testing.T) {
 	builtInAgents := []string{"claude"}
 
@@ -927,3 +1274,343 @@ func TestValidateWorkspaceConfig_WithStorageSettings(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTaskDir(t *testing.T) {
+	tests := []struct {
+		name      string
+		dir       string
+		wantError bool
+	}{
+		{"not set", "", false},
+		{"relative", ".ai-tasks", false},
+		{"outside repository", "../tasks", true},
+		{"absolute", "/var/tasks", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(config.TaskDirEnv, tt.dir)
+			result := NewResult()
+			validateTaskDir(result)
+			if got := result.Errors > 0; got != tt.wantError {
+				t.Errorf("validateTaskDir(%q) errors = %d, wantError %v", tt.dir, result.Errors, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestTokenCheckURL(t *testing.T) {
+	tests := []struct {
+		provider, host, want string
+	}{
+		{"github", "", "https://api.github.com/user"},
+		{"github", "github.com", "https://api.github.com/user"},
+		{"github", "ghe.example.com", "https://ghe.example.com/api/v3/user"},
+		{"gitlab", "", "https://gitlab.com/api/v4/user"},
+		{"gitlab", "https://gitlab.example.com/", "https://gitlab.example.com/api/v4/user"},
+	}
+	for _, tt := range tests {
+		got, err := TokenCheckURL(tt.provider, tt.host)
+		if err != nil || got != tt.want {
+			t.Errorf("TokenCheckURL(%q, %q) = %q, %v; want %q", tt.provider, tt.host, got, err, tt.want)
+		}
+	}
+
+	if _, err := TokenCheckURL("jira", ""); err == nil {
+		t.Error("expected an error for a provider without token check")
+	}
+}
+
+func TestCheckToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v3/user" && r.Header.Get("Authorization") == "Bearer good":
+			w.Header().Set("X-RateLimit-Remaining", "4999")
+			w.Header().Set("X-RateLimit-Reset", "1700000000")
+			_, _ = w.Write([]byte(`{"login":"octocat"}`))
+		case r.URL.Path == "/api/v4/user" && r.Header.Get("PRIVATE-TOKEN") == "good":
+			_, _ = w.Write([]byte(`{"username":"tanuki"}`))
+		case r.Header.Get("Authorization") == "Bearer limited":
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	status, err := CheckToken(ctx, "github", srv.URL, "good")
+	if err != nil {
+		t.Fatalf("github: %v", err)
+	}
+	if status.User != "octocat" || status.RateLimitRemaining != 4999 || status.RateLimitReset.Unix() != 1700000000 {
+		t.Errorf("github status = %+v", status)
+	}
+
+	status, err = CheckToken(ctx, "gitlab", srv.URL, "good")
+	if err != nil {
+		t.Fatalf("gitlab: %v", err)
+	}
+	if status.User != "tanuki" || status.RateLimitRemaining != -1 {
+		t.Errorf("gitlab status = %+v", status)
+	}
+
+	if _, err := CheckToken(ctx, "gitlab", srv.URL, "bad"); !errors.Is(err, ErrTokenRejected) {
+		t.Errorf("bad token: err = %v, want ErrTokenRejected", err)
+	}
+
+	_, err = CheckToken(ctx, "github", srv.URL, "limited")
+	if err == nil || errors.Is(err, ErrTokenRejected) {
+		t.Errorf("rate limited: err = %v, want a rate limit error", err)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	cfg := storage.NewDefaultWorkspaceConfig()
+	if result := ValidateConfig(cfg, "config.yaml", []string{"claude"}); !result.Valid {
+		t.Errorf("default config invalid: %s", result.Format("text"))
+	}
+
+	cfg.Agent.Default = "nope"
+	if result := ValidateConfig(cfg, "config.yaml", []string{"claude"}); result.Valid {
+		t.Error("expected an unknown default agent to be invalid")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.43.0", MinGitVersion, 1},
+		{"2.17.0", MinGitVersion, 0},
+		{"2.9.5", MinGitVersion, -1},
+		{"2.39.3", "2.39.3", 0},
+		{"2.43.0.windows.1", "2.43.0", 0},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// findingCodes returns the codes of the findings with the given severity.
+func findingCodes(r *Result, severity Severity) []string {
+	var codes []string
+	for _, f := range r.Findings {
+		if f.Severity == severity {
+			codes = append(codes, f.Code)
+		}
+	}
+
+	return codes
+}
+
+func TestDoctor_WorkspaceState(t *testing.T) {
+	dir := t.TempDir()
+	ws, err := storage.OpenWorkspace(dir, nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+	if _, err := ws.CreateWork("good", storage.SourceInfo{Type: "file", Ref: "task.md"}); err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+
+	result, err := New(dir, Options{}).Doctor(context.Background(), DoctorOptions{})
+	if err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+	if !slices.Contains(findingCodes(result, SeverityOK), CodeWorkspaceOK) {
+		t.Errorf("consistent workspace not reported OK: %+v", result.Findings)
+	}
+
+	if err := ws.SaveActiveTask(&storage.ActiveTask{ID: "gone", Ref: "file:gone.md"}); err != nil {
+		t.Fatalf("SaveActiveTask: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(ws.WorkRoot(), "broken"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	result, err = New(dir, Options{}).Doctor(context.Background(), DoctorOptions{})
+	if err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+	errs := findingCodes(result, SeverityError)
+	for _, want := range []string{CodeActiveTaskOrphaned, CodeWorkDirBroken} {
+		if !slices.Contains(errs, want) {
+			t.Errorf("errors = %v, want %s", errs, want)
+		}
+	}
+	if result.Valid {
+		t.Error("expected an inconsistent workspace to be invalid")
+	}
+}
+
+func TestDoctor_Tokens(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "good" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+		w.Header().Set("RateLimit-Remaining", "5")
+		_, _ = w.Write([]byte(`{"username":"tanuki"}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		token   string
+		offline bool
+		want    []string
+	}{
+		{"missing", "", false, []string{CodeTokenMissing}},
+		{"offline", "good", true, []string{CodeTokenUnchecked}},
+		{"rejected", "bad", false, []string{CodeTokenRejected}},
+		{"valid with low rate limit", "good", false, []string{CodeTokenOK, CodeRateLimitLow}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewResult()
+			token := ProviderToken{Provider: "gitlab", Host: srv.URL, Token: tt.token, EnvVar: "GITLAB_TOKEN"}
+			checkProviderToken(context.Background(), token, tt.offline, result)
+
+			var codes []string
+			for _, f := range result.Findings {
+				codes = append(codes, f.Code)
+			}
+			if !slices.Equal(codes, tt.want) {
+				t.Errorf("codes = %v, want %v", codes, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckAgents_NoneInstalled(t *testing.T) {
+	result := NewResult()
+	checkAgents(storage.NewDefaultWorkspaceConfig(), agent.NewRegistry(), result)
+
+	errs := findingCodes(result, SeverityError)
+	if !slices.Contains(errs, CodeAgentMissing) || !slices.Contains(errs, CodeAgentUnavailable) {
+		t.Errorf("errors = %v, want the unknown default agent and no installed agent", errs)
+	}
+}
+
+func TestRepair(t *testing.T) {
+	dir := t.TempDir()
+	testutil.CreateTempGitRepoInDir(t, dir)
+	ws, err := storage.OpenWorkspace(dir, nil)
+	if err != nil {
+		t.Fatalf("OpenWorkspace: %v", err)
+	}
+	if err := ws.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized: %v", err)
+	}
+	source := storage.SourceInfo{Type: "file", Ref: "task.md"}
+
+	// Malformed config.yaml and work.yaml with backups from an earlier save
+	cfg := storage.NewDefaultWorkspaceConfig()
+	for range 2 {
+		if err := ws.SaveConfig(cfg); err != nil {
+			t.Fatalf("SaveConfig: %v", err)
+		}
+	}
+	corrupt := func(path string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte("key: [unclosed"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	corrupt(ws.ConfigPath())
+	broken, err := ws.CreateWork("broken", source)
+	if err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	if err := ws.SaveWork(broken); err != nil {
+		t.Fatalf("SaveWork: %v", err)
+	}
+	corrupt(filepath.Join(ws.WorkPath("broken"), "work.yaml"))
+
+	// Missing sessions/ and notes.md
+	if _, err := ws.CreateWork("incomplete", source); err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	_ = os.RemoveAll(ws.SessionsDir("incomplete"))
+	_ = os.Remove(ws.NotesPath("incomplete"))
+
+	// A worktree on a task's branch the task does not point at
+	linked, err := ws.CreateWork("linked", source)
+	if err != nil {
+		t.Fatalf("CreateWork: %v", err)
+	}
+	linked.Git.Branch = "feature/linked"
+	if err := ws.SaveWork(linked); err != nil {
+		t.Fatalf("SaveWork: %v", err)
+	}
+	wtPath := filepath.Join(t.TempDir(), "linked")
+	repo, err := vcs.New(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("vcs.New: %v", err)
+	}
+	if err := repo.CreateWorktreeNewBranch(context.Background(), wtPath, "feature/linked", ""); err != nil {
+		t.Fatalf("CreateWorktreeNewBranch: %v", err)
+	}
+
+	// An active task whose work directory is gone
+	if err := ws.SaveActiveTask(&storage.ActiveTask{ID: "gone", Ref: "file:gone.md"}); err != nil {
+		t.Fatalf("SaveActiveTask: %v", err)
+	}
+
+	v := New(dir, Options{})
+	before, err := v.Doctor(context.Background(), DoctorOptions{})
+	if err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+	for _, want := range []string{CodeWorkDirIncomplete, CodeWorktreeDetached} {
+		if !slices.Contains(findingCodes(before, SeverityWarning), want) {
+			t.Errorf("Doctor warnings = %v, want %s", findingCodes(before, SeverityWarning), want)
+		}
+	}
+
+	repairs, err := v.Repair(context.Background())
+	if err != nil {
+		t.Fatalf("Repair: %v", err)
+	}
+	var codes []string
+	for _, r := range repairs {
+		codes = append(codes, r.Code)
+	}
+	want := []string{CodeYAMLSyntax, CodeWorkDirBroken, CodeWorkDirIncomplete, CodeActiveTaskOrphaned, CodeWorktreeDetached}
+	if !slices.Equal(codes, want) {
+		t.Errorf("repairs = %v, want %v", codes, want)
+	}
+
+	if _, err := ws.LoadConfig(); err != nil {
+		t.Errorf("config not restored: %v", err)
+	}
+	if ws.HasActiveTask() {
+		t.Error("orphaned .active_task not removed")
+	}
+	work, err := ws.LoadWork("linked")
+	if err != nil {
+		t.Fatalf("LoadWork: %v", err)
+	}
+	if resolved, _ := filepath.EvalSymlinks(wtPath); work.Git.WorktreePath != wtPath && work.Git.WorktreePath != resolved {
+		t.Errorf("worktree path = %q, want %q", work.Git.WorktreePath, wtPath)
+	}
+
+	after, err := v.Doctor(context.Background(), DoctorOptions{})
+	if err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+	if !slices.Contains(findingCodes(after, SeverityOK), CodeWorkspaceOK) {
+		t.Errorf("workspace not consistent after repair: %+v", after.Findings)
+	}
+	if again, err := v.Repair(context.Background()); err != nil || len(again) != 0 {
+		t.Errorf("second Repair = %+v, %v; want nothing to repair", again, err)
+	}
+}
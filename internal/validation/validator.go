@@ -6,7 +6,9 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/valksor/go-mehrhof/internal/config"
 	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
 )
 
 // Options configures validation behavior.
@@ -40,7 +42,7 @@ func (v *Validator) Validate(ctx context.Context) (*Result, error) {
 	result := NewResult()
 
 	// Validate workspace config
-	wsResult, err := v.validateWorkspace()
+	wsResult, err := v.validateWorkspace(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("workspace validation: %w", err)
 	}
@@ -55,8 +57,9 @@ func (v *Validator) Validate(ctx context.Context) (*Result, error) {
 }
 
 // validateWorkspace validates the workspace configuration.
-func (v *Validator) validateWorkspace() (*Result, error) {
+func (v *Validator) validateWorkspace(ctx context.Context) (*Result, error) {
 	result := NewResult()
+	validateTaskDir(result)
 
 	ws, err := storage.OpenWorkspace(v.workspacePath, nil)
 	if err != nil {
@@ -64,30 +67,108 @@ func (v *Validator) validateWorkspace() (*Result, error) {
 	}
 
 	configPath := ws.ConfigPath()
+	localPath := ws.LocalConfigPath()
+	_, localErr := os.Stat(localPath)
+	hasLocal := localErr == nil
 
 	// Check if config file exists
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+	if _, err := os.Stat(configPath); os.IsNotExist(err) && !hasLocal {
 		// No config file is valid - defaults are used
 		result.AddInfo("CONFIG_NOT_FOUND", "No workspace config found, using defaults", "", configPath)
 
 		return result, nil
 	}
 
-	// Load and validate workspace config
-	cfg, err := ws.LoadConfig()
+	// Load and validate the shared workspace config
+	cfg, err := ws.LoadSharedConfig()
 	if err != nil {
 		result.AddError("YAML_SYNTAX", fmt.Sprintf("Failed to parse config: %s", err), "", configPath)
 
 		return result, nil
 	}
-
-	// Run workspace-specific validations
 	validateWorkspaceConfig(cfg, configPath, v.builtInAgents, result)
 
+	// The local overrides are validated merged over the shared config;
+	// only the problems they introduce are reported against them
+	if hasLocal {
+		merged, err := ws.LoadConfig()
+		if err != nil {
+			result.AddError("YAML_SYNTAX", fmt.Sprintf("Failed to parse local config: %s", err), "", localPath)
+
+			return result, nil
+		}
+		local := NewResult()
+		validateWorkspaceConfig(merged, localPath, v.builtInAgents, local)
+		result.Merge(newFindings(local, result))
+		cfg = merged
+	}
+
+	v.validateSigning(ctx, cfg.Git, configPath, result)
+
 	return result, nil
 }
 
+// newFindings returns the findings of result that known does not already
+// report, by code, setting and message.
+func newFindings(result, known *Result) *Result {
+	type key struct{ code, path, message string }
+	seen := make(map[key]bool, len(known.Findings))
+	for _, f := range known.Findings {
+		seen[key{f.Code, f.Path, f.Message}] = true
+	}
+
+	fresh := NewResult()
+	for _, f := range result.Findings {
+		if !seen[key{f.Code, f.Path, f.Message}] {
+			fresh.addFinding(f.Severity, f.Code, f.Message, f.Path, f.File, f.Suggestion)
+		}
+	}
+
+	return fresh
+}
+
+// validateTaskDir reports a task directory override that is ignored
+// because it points outside the repository.
+func validateTaskDir(result *Result) {
+	dir := os.Getenv(config.TaskDirEnv)
+	if dir == "" {
+		return
+	}
+	if err := config.ValidateTaskDir(dir); err != nil {
+		result.AddErrorWithSuggestion(
+			CodeInvalidPath,
+			fmt.Sprintf("%s=%q: %s; using %s", config.TaskDirEnv, dir, err, config.MehrhofDir),
+			config.TaskDirEnv,
+			"",
+			"Use a directory relative to the repository root, such as .ai-tasks",
+		)
+	}
+}
+
+// validateSigning checks that commits can be signed as configured: a key is
+// set where the format needs one and the signing program is installed.
+func (v *Validator) validateSigning(ctx context.Context, git storage.GitSettings, configPath string, result *Result) {
+	if !git.SignCommits || (git.SigningFormat != "" && !vcs.ValidSigningFormat(git.SigningFormat)) {
+		return
+	}
+	repo, err := vcs.New(ctx, v.workspacePath)
+	if err != nil {
+		return // Not a git repository; nothing is committed
+	}
+
+	signed := repo.WithSigning(vcs.Signing{Format: git.SigningFormat, Key: git.SigningKey})
+	if err := signed.CheckSigning(ctx); err != nil {
+		result.AddErrorWithSuggestion(
+			CodeSigningSetup,
+			err.Error(),
+			"git.sign_commits",
+			configPath,
+			"Configure a signing key (git.signing_key or git config user.signingkey) or set sign_commits: false",
+		)
+	}
+}
+
 // WorkspaceConfigPath returns the expected workspace config file path.
 func (v *Validator) WorkspaceConfigPath() string {
-	return filepath.Join(v.workspacePath, ".mehrhof", "config.yaml")
+	return filepath.Join(config.TaskRoot(v.workspacePath), "config.yaml")
 }
@@ -3,11 +3,18 @@ package validation
 import (
 	"fmt"
 	"os"
+	"path"
 	"regexp"
 	"slices"
 	"strings"
 
+	"github.com/valksor/go-mehrhof/internal/agent"
+	"github.com/valksor/go-mehrhof/internal/bucket"
+	"github.com/valksor/go-mehrhof/internal/changelog"
+	"github.com/valksor/go-mehrhof/internal/guardrail"
 	"github.com/valksor/go-mehrhof/internal/storage"
+	"github.com/valksor/go-mehrhof/internal/vcs"
+	"github.com/valksor/go-mehrhof/internal/workflow"
 )
 
 // Error codes for workspace validation.
@@ -23,6 +30,9 @@ const (
 	CodeInvalidRange        = "INVALID_RANGE"
 	CodePluginNotFound      = "PLUGIN_NOT_FOUND"
 	CodeInvalidPath         = "INVALID_PATH"
+	CodeSigningSetup        = "SIGNING_SETUP"
+	CodeRemoteStorage       = "REMOTE_STORAGE"
+	CodeDuplicateAgent      = "DUPLICATE_AGENT"
 )
 
 // Valid git pattern placeholders.
@@ -31,11 +41,22 @@ var validGitPlaceholders = []string{"{key}", "{task_id}", "{type}", "{slug}", "{
 // Pattern to match environment variable references like ${VAR_NAME}.
 var envVarRefPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
 
+// ValidateConfig validates a workspace configuration that is not (yet) on
+// disk, such as one about to be saved. configPath is only used in findings.
+func ValidateConfig(cfg *storage.WorkspaceConfig, configPath string, builtInAgents []string) *Result {
+	result := NewResult()
+	validateWorkspaceConfig(cfg, configPath, builtInAgents, result)
+
+	return result
+}
+
 // validateWorkspaceConfig validates all aspects of a workspace configuration.
 func validateWorkspaceConfig(cfg *storage.WorkspaceConfig, configPath string, builtInAgents []string, result *Result) {
 	validateGitSettings(cfg.Git, configPath, result)
 	validateAgentSettings(cfg.Agent, configPath, builtInAgents, cfg.Agents, result)
 	validateWorkflowSettings(cfg.Workflow, configPath, result)
+	validateContextSettings(cfg.Context, configPath, result)
+	validateGuardrailSettings(cfg.Guardrails, configPath, result)
 	validateStorageSettings(cfg.Storage, configPath, result)
 	validateAgentAliases(cfg.Agents, configPath, builtInAgents, result)
 	validatePluginsConfig(cfg.Plugins, configPath, result)
@@ -58,6 +79,61 @@ func validateGitSettings(git storage.GitSettings, configPath string, result *Res
 	if git.CommitPrefix != "" {
 		validateGitPattern(git.CommitPrefix, "git.commit_prefix", configPath, result)
 	}
+
+	// Validate worktree naming
+	if git.WorktreePattern != "" {
+		validateGitPattern(git.WorktreePattern, "git.worktree_pattern", configPath, result)
+	}
+	if strings.HasPrefix(git.WorktreeDir, "~") && git.WorktreeDir != "~" && !strings.HasPrefix(git.WorktreeDir, "~/") {
+		result.AddError(CodeInvalidPath, "Worktree directory can only expand the current user's home (~/)", "git.worktree_dir", configPath)
+	}
+
+	// Validate commit message style
+	if git.CommitMessageStyle != "" && !vcs.ValidCommitStyle(git.CommitMessageStyle) {
+		result.AddErrorWithSuggestion(
+			CodeInvalidEnum,
+			fmt.Sprintf("Unknown commit message style %q", git.CommitMessageStyle),
+			"git.commit_message_style",
+			configPath,
+			"Valid styles: conventional, plain",
+		)
+	}
+
+	// Validate checkpoint path globs
+	globs := []struct {
+		field    string
+		patterns []string
+	}{
+		{"git.checkpoint_include", git.CheckpointInclude},
+		{"git.checkpoint_exclude", git.CheckpointExclude},
+	}
+	for _, g := range globs {
+		for _, pattern := range g.patterns {
+			if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil {
+				result.AddError(CodeInvalidPath, fmt.Sprintf("Invalid glob %q: %v", pattern, err), g.field, configPath)
+			}
+		}
+	}
+
+	// Validate commit signing
+	if git.SigningFormat != "" && !vcs.ValidSigningFormat(git.SigningFormat) {
+		result.AddErrorWithSuggestion(
+			CodeInvalidEnum,
+			fmt.Sprintf("Unknown signing format %q", git.SigningFormat),
+			"git.signing_format",
+			configPath,
+			"Valid formats: openpgp, ssh, x509",
+		)
+	}
+	if !git.SignCommits && (git.SigningFormat != "" || git.SigningKey != "") {
+		result.AddWarningWithSuggestion(
+			CodeSigningSetup,
+			"Signing format or key is set but commits are not signed",
+			"git.sign_commits",
+			configPath,
+			"Set sign_commits: true to sign checkpoint and merge commits",
+		)
+	}
 }
 
 // validateGitPattern checks if a git pattern contains valid placeholders.
@@ -88,15 +164,15 @@ func validateGitPattern(pattern, path, configPath string, result *Result) {
 }
 
 // validateAgentSettings validates agent-related configuration.
-func validateAgentSettings(agent storage.AgentSettings, configPath string, builtInAgents []string, aliases map[string]storage.AgentAliasConfig, result *Result) {
+func validateAgentSettings(settings storage.AgentSettings, configPath string, builtInAgents []string, aliases map[string]storage.AgentAliasConfig, result *Result) {
 	// Validate default agent
-	if agent.Default != "" {
-		isBuiltIn := slices.Contains(builtInAgents, agent.Default)
-		_, isAlias := aliases[agent.Default]
+	if settings.Default != "" {
+		isBuiltIn := slices.Contains(builtInAgents, settings.Default)
+		_, isAlias := aliases[settings.Default]
 		if !isBuiltIn && !isAlias {
 			result.AddErrorWithSuggestion(
 				CodeInvalidEnum,
-				fmt.Sprintf("Unknown default agent %q", agent.Default),
+				fmt.Sprintf("Unknown default agent %q", settings.Default),
 				"agent.default",
 				configPath,
 				"Available agents: "+strings.Join(builtInAgents, ", "),
@@ -105,13 +181,57 @@ func validateAgentSettings(agent storage.AgentSettings, configPath string, built
 	}
 
 	// Validate timeout range (0-3600 seconds)
-	if agent.Timeout < 0 || agent.Timeout > 3600 {
-		result.AddError(CodeInvalidRange, fmt.Sprintf("Timeout %d is out of range (0-3600)", agent.Timeout), "agent.timeout", configPath)
+	if settings.Timeout < 0 || settings.Timeout > 3600 {
+		result.AddError(CodeInvalidRange, fmt.Sprintf("Timeout %d is out of range (0-3600)", settings.Timeout), "agent.timeout", configPath)
 	}
 
 	// Validate max retries range (0-10)
-	if agent.MaxRetries < 0 || agent.MaxRetries > 10 {
-		result.AddError(CodeInvalidRange, fmt.Sprintf("Max retries %d is out of range (0-10)", agent.MaxRetries), "agent.max_retries", configPath)
+	if settings.MaxRetries < 0 || settings.MaxRetries > 10 {
+		result.AddError(CodeInvalidRange, fmt.Sprintf("Max retries %d is out of range (0-10)", settings.MaxRetries), "agent.max_retries", configPath)
+	}
+
+	// Validate sandbox mode and the docker image it needs
+	if settings.Sandbox != "" && !slices.Contains(agent.SandboxModes, settings.Sandbox) {
+		result.AddErrorWithSuggestion(
+			CodeInvalidEnum,
+			fmt.Sprintf("Unknown agent sandbox %q", settings.Sandbox),
+			"agent.sandbox",
+			configPath,
+			"Valid values: "+strings.Join(agent.SandboxModes, ", "),
+		)
+	}
+	if settings.Sandbox == agent.SandboxDocker && settings.SandboxImage == "" {
+		result.AddError(CodeInvalidEnum, "The docker sandbox needs an image with the agent's CLI", "agent.sandbox_image", configPath)
+	}
+
+	// Validate phase deadlines and what happens when one passes
+	for phase, seconds := range settings.PhaseTimeouts {
+		field := "agent.phase_timeouts." + phase
+		if !slices.Contains(storage.TimeoutPhases, phase) {
+			result.AddErrorWithSuggestion(
+				CodeInvalidEnum,
+				fmt.Sprintf("Unknown phase %q", phase),
+				field,
+				configPath,
+				"Valid phases: "+strings.Join(storage.TimeoutPhases, ", "),
+			)
+		}
+		if seconds < 0 {
+			result.AddError(CodeInvalidRange, fmt.Sprintf("Phase timeout %d must not be negative", seconds), field, configPath)
+		}
+	}
+	if settings.OnTimeout != "" && !slices.Contains(storage.OnTimeoutPolicies, settings.OnTimeout) {
+		result.AddErrorWithSuggestion(
+			CodeInvalidEnum,
+			fmt.Sprintf("Unknown timeout policy %q", settings.OnTimeout),
+			"agent.on_timeout",
+			configPath,
+			"Valid values: "+strings.Join(storage.OnTimeoutPolicies, ", "),
+		)
+	}
+
+	if settings.Cache.TTLHours < 0 {
+		result.AddError(CodeInvalidRange, fmt.Sprintf("Cache TTL %d hours must not be negative", settings.Cache.TTLHours), "agent.cache.ttl_hours", configPath)
 	}
 }
 
@@ -121,10 +241,107 @@ func validateWorkflowSettings(workflow storage.WorkflowSettings, configPath stri
 	if workflow.SessionRetentionDays < 0 || workflow.SessionRetentionDays > 365 {
 		result.AddWarning(CodeInvalidRange, fmt.Sprintf("Session retention days %d may be unreasonable (expected 1-365)", workflow.SessionRetentionDays), "workflow.session_retention_days", configPath)
 	}
+
+	// Validate changelog format
+	if format := workflow.Changelog.Format; format != "" && !changelog.ValidFormat(format) {
+		result.AddErrorWithSuggestion(
+			CodeInvalidEnum,
+			fmt.Sprintf("Unknown changelog format %q", format),
+			"workflow.changelog.format",
+			configPath,
+			"Valid formats: keepachangelog, towncrier",
+		)
+	}
+
+	// Validate consensus review agents
+	review := workflow.Review
+	if len(review.Agents) == 1 {
+		result.AddErrorWithSuggestion(
+			CodeInvalidRange,
+			"Consensus review needs at least two agents",
+			"workflow.review.agents",
+			configPath,
+			"Add another agent, or remove the setting to review with the reviewing agent alone",
+		)
+	}
+	seen := make(map[string]bool, len(review.Agents))
+	for _, name := range review.Agents {
+		if seen[name] {
+			result.AddError(CodeDuplicateAgent, fmt.Sprintf("Agent %q is listed twice", name), "workflow.review.agents", configPath)
+		}
+		seen[name] = true
+	}
+	if review.MinAgreement < 0 || (len(review.Agents) > 1 && review.MinAgreement > len(review.Agents)) {
+		result.AddError(
+			CodeInvalidRange,
+			fmt.Sprintf("min_agreement %d must be between 1 and the number of review agents (%d)", review.MinAgreement, len(review.Agents)),
+			"workflow.review.min_agreement",
+			configPath,
+		)
+	}
+
+	// Validate spec critique rounds
+	if workflow.SpecCritiqueRounds < 0 {
+		result.AddError(CodeInvalidRange, fmt.Sprintf("spec_critique_rounds %d must not be negative", workflow.SpecCritiqueRounds), "workflow.spec_critique_rounds", configPath)
+	}
+}
+
+// validateContextSettings validates the globs of files included in prompts.
+func validateContextSettings(ctx storage.ContextSettings, configPath string, result *Result) {
+	validateGlobs := func(field string, patterns []string) {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				result.AddError(CodeInvalidPath, fmt.Sprintf("Invalid glob %q: %v", pattern, err), field, configPath)
+			}
+		}
+	}
+
+	validateGlobs("context.include_globs", ctx.IncludeGlobs)
+	for name, step := range ctx.Steps {
+		field := "context.steps." + name
+		if !workflow.IsValidStep(name) {
+			result.AddErrorWithSuggestion(
+				CodeInvalidEnum,
+				fmt.Sprintf("Unknown step %q", name),
+				field,
+				configPath,
+				"Valid steps: planning, implementing, reviewing, documenting, checkpointing, resolving",
+			)
+		}
+		validateGlobs(field+".include_globs", step.IncludeGlobs)
+	}
+}
+
+// validateGuardrailSettings validates the guardrail path policy and secret handling.
+func validateGuardrailSettings(g storage.GuardrailSettings, configPath string, result *Result) {
+	validateGlobs := func(field string, patterns []string) {
+		for _, pattern := range patterns {
+			if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil {
+				result.AddError(CodeInvalidPath, fmt.Sprintf("Invalid glob %q: %v", pattern, err), field, configPath)
+			}
+		}
+	}
+
+	validateGlobs("guardrails.ignore", g.Ignore)
+	validateGlobs("guardrails.deny", g.Deny)
+	validateGlobs("guardrails.allow", g.Allow)
+	validateGlobs("guardrails.protected", g.Protected)
+
+	if g.Secrets != "" && g.Secrets != guardrail.SecretsReject && g.Secrets != guardrail.SecretsRedact {
+		result.AddErrorWithSuggestion(
+			CodeInvalidEnum,
+			fmt.Sprintf("Unknown secrets handling %q", g.Secrets),
+			"guardrails.secrets",
+			configPath,
+			"Valid values: reject, redact",
+		)
+	}
 }
 
 // validateStorageSettings validates storage-related configuration.
 func validateStorageSettings(storage storage.StorageSettings, configPath string, result *Result) {
+	validateRemoteStorage(storage.Remote, configPath, result)
+
 	if storage.WorkDir == "" {
 		return // Empty is fine, will use default
 	}
@@ -158,6 +375,36 @@ func validateStorageSettings(storage storage.StorageSettings, configPath string,
 	}
 }
 
+// validateRemoteStorage checks that remote storage names a known type and the
+// location it needs.
+func validateRemoteStorage(remote *storage.RemoteStorageSettings, configPath string, result *Result) {
+	if remote == nil {
+		return
+	}
+
+	switch remote.Type {
+	case bucket.TypeLocal:
+		if remote.Path == "" {
+			result.AddError(CodeRemoteStorage, "Local remote storage needs a path", "storage.remote.path", configPath)
+		}
+	case bucket.TypeS3, bucket.TypeGCS:
+		if remote.Bucket == "" {
+			result.AddError(CodeRemoteStorage, remote.Type+" remote storage needs a bucket", "storage.remote.bucket", configPath)
+		}
+		if (remote.AccessKey == "") != (remote.SecretKey == "") {
+			result.AddError(CodeRemoteStorage, "Set both access_key and secret_key, or neither to use the AWS environment variables", "storage.remote", configPath)
+		}
+	default:
+		result.AddErrorWithSuggestion(
+			CodeInvalidEnum,
+			fmt.Sprintf("Unknown remote storage type %q", remote.Type),
+			"storage.remote.type",
+			configPath,
+			"Valid types: local, s3, gcs",
+		)
+	}
+}
+
 // validateAgentAliases validates agent alias configurations including circular dependency detection.
 func validateAgentAliases(aliases map[string]storage.AgentAliasConfig, configPath string, builtInAgents []string, result *Result) {
 	if len(aliases) == 0 {
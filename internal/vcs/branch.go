@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -162,13 +164,16 @@ func (g *Git) RenameBranch(ctx context.Context, oldName, newName string) error {
 
 // MergeBranch merges a branch into the current branch.
 func (g *Git) MergeBranch(ctx context.Context, name string, noFF bool) error {
-	args := []string{"merge", name}
+	args := append(g.signingConfig(), "merge", name)
 	if noFF {
 		args = append(args, "--no-ff")
 	}
+	if g.signing != nil {
+		args = append(args, "-S")
+	}
 	_, err := g.run(ctx, args...)
 
-	return err
+	return g.signingError(ctx, err)
 }
 
 // MergeSquash performs a squash merge.
@@ -180,9 +185,13 @@ func (g *Git) MergeSquash(ctx context.Context, name string) error {
 
 // RebaseBranch rebases current branch onto another.
 func (g *Git) RebaseBranch(ctx context.Context, onto string) error {
-	_, err := g.run(ctx, "rebase", onto)
+	args := append(g.signingConfig(), "rebase")
+	if g.signing != nil {
+		args = append(args, "--gpg-sign")
+	}
+	_, err := g.run(ctx, append(args, onto)...)
 
-	return err
+	return g.signingError(ctx, err)
 }
 
 // AbortRebase aborts an in-progress rebase.
@@ -192,13 +201,81 @@ func (g *Git) AbortRebase(ctx context.Context) error {
 	return err
 }
 
-// ContinueRebase continues a rebase after resolving conflicts.
+// ContinueRebase continues a rebase after resolving conflicts. Commit
+// messages are kept as they are instead of opening an editor.
 func (g *Git) ContinueRebase(ctx context.Context) error {
-	_, err := g.run(ctx, "rebase", "--continue")
+	args := append(g.signingConfig(), "-c", "core.editor=true", "rebase", "--continue")
+	_, err := g.run(ctx, args...)
+
+	return g.signingError(ctx, err)
+}
+
+// AbortMerge aborts an in-progress merge.
+func (g *Git) AbortMerge(ctx context.Context) error {
+	_, err := g.run(ctx, "merge", "--abort")
 
 	return err
 }
 
+// ResetMerge abandons a merge, including a squash merge that leaves no
+// MERGE_HEAD behind, and restores the pre-merge state.
+func (g *Git) ResetMerge(ctx context.Context) error {
+	_, err := g.run(ctx, "reset", "--merge")
+
+	return err
+}
+
+// Operations a repository can be in the middle of.
+const (
+	OperationRebase = "rebase"
+	OperationMerge  = "merge"
+)
+
+// OperationInProgress returns the rebase or merge waiting for conflicts to
+// be resolved, or "" when there is none.
+func (g *Git) OperationInProgress(ctx context.Context) string {
+	checks := []struct {
+		path      string
+		operation string
+	}{
+		{"rebase-merge", OperationRebase},
+		{"rebase-apply", OperationRebase},
+		{"MERGE_HEAD", OperationMerge},
+	}
+	for _, check := range checks {
+		out, err := g.run(ctx, "rev-parse", "--git-path", check.path)
+		if err != nil {
+			continue
+		}
+		path := strings.TrimSpace(out)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(g.repoRoot, path)
+		}
+		if _, err := os.Stat(path); err == nil {
+			return check.operation
+		}
+	}
+
+	return ""
+}
+
+// ConflictedFiles returns the paths with unresolved merge conflicts.
+func (g *Git) ConflictedFiles(ctx context.Context) ([]string, error) {
+	out, err := g.run(ctx, "diff", "--name-only", "--diff-filter=U", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("list conflicted files: %w", err)
+	}
+
+	var files []string
+	for file := range strings.SplitSeq(out, "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+
+	return files, nil
+}
+
 // GetBranchCommitCount returns the number of commits in branch ahead of base.
 func (g *Git) GetBranchCommitCount(ctx context.Context, branch, base string) (int, error) {
 	out, err := g.run(ctx, "rev-list", "--count", fmt.Sprintf("%s..%s", base, branch))
@@ -757,3 +757,133 @@ func TestForcePushBranch_NoRemote(t *testing.T) {
 		t.Error("ForcePushBranch should fail without remote")
 	}
 }
+
+// conflictingBranches commits different README.md contents on the base
+// branch and on "feature", so merging or rebasing them conflicts.
+func conflictingBranches(t *testing.T, dir, base string) {
+	t.Helper()
+	ctx := context.Background()
+
+	commit := func(content, msg string) {
+		if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		if err := runGit(ctx, dir, "commit", "-am", msg); err != nil {
+			t.Fatalf("git commit: %v", err)
+		}
+	}
+
+	if err := runGit(ctx, dir, "checkout", "-b", "feature"); err != nil {
+		t.Fatalf("checkout feature: %v", err)
+	}
+	commit("# Feature\n", "feature change")
+	if err := runGit(ctx, dir, "checkout", base); err != nil {
+		t.Fatalf("checkout base: %v", err)
+	}
+	commit("# Base\n", "base change")
+	if err := runGit(ctx, dir, "checkout", "feature"); err != nil {
+		t.Fatalf("checkout feature: %v", err)
+	}
+}
+
+func TestRebaseConflict(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	dir := initTestRepo(t)
+	g, err := New(ctx, dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	base, _ := g.CurrentBranch(ctx)
+	conflictingBranches(t, dir, base)
+
+	if op := g.OperationInProgress(ctx); op != "" {
+		t.Errorf("OperationInProgress() = %q before rebase, want none", op)
+	}
+	if err := g.RebaseBranch(ctx, base); err == nil {
+		t.Fatal("RebaseBranch should fail with conflicts")
+	}
+	if op := g.OperationInProgress(ctx); op != OperationRebase {
+		t.Errorf("OperationInProgress() = %q, want %q", op, OperationRebase)
+	}
+	files, err := g.ConflictedFiles(ctx)
+	if err != nil {
+		t.Fatalf("ConflictedFiles: %v", err)
+	}
+	if len(files) != 1 || files[0] != "README.md" {
+		t.Errorf("ConflictedFiles() = %v, want [README.md]", files)
+	}
+
+	// Resolve and continue without an editor
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Both\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := g.Add(ctx, "README.md"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := g.ContinueRebase(ctx); err != nil {
+		t.Fatalf("ContinueRebase: %v", err)
+	}
+	if op := g.OperationInProgress(ctx); op != "" {
+		t.Errorf("OperationInProgress() = %q after rebase, want none", op)
+	}
+}
+
+func TestMergeConflict_Abort(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	dir := initTestRepo(t)
+	g, err := New(ctx, dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	base, _ := g.CurrentBranch(ctx)
+	conflictingBranches(t, dir, base)
+
+	if err := g.MergeBranch(ctx, base, false); err == nil {
+		t.Fatal("MergeBranch should fail with conflicts")
+	}
+	if op := g.OperationInProgress(ctx); op != OperationMerge {
+		t.Errorf("OperationInProgress() = %q, want %q", op, OperationMerge)
+	}
+	if err := g.AbortMerge(ctx); err != nil {
+		t.Fatalf("AbortMerge: %v", err)
+	}
+	if op := g.OperationInProgress(ctx); op != "" {
+		t.Errorf("OperationInProgress() = %q after abort, want none", op)
+	}
+}
+
+func TestMergeSquashConflict_Reset(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	dir := initTestRepo(t)
+	g, err := New(ctx, dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	base, _ := g.CurrentBranch(ctx)
+	conflictingBranches(t, dir, base)
+
+	if err := g.MergeSquash(ctx, base); err == nil {
+		t.Fatal("MergeSquash should fail with conflicts")
+	}
+	if files, _ := g.ConflictedFiles(ctx); len(files) == 0 {
+		t.Fatal("ConflictedFiles() is empty after a conflicting squash merge")
+	}
+	if err := g.ResetMerge(ctx); err != nil {
+		t.Fatalf("ResetMerge: %v", err)
+	}
+	if changed, _ := g.HasChanges(ctx); changed {
+		t.Error("HasChanges() = true after ResetMerge, want a clean tree")
+	}
+}
@@ -27,6 +27,26 @@ const CheckpointPrefix = "task-checkpoint"
 // checkpointTagRe matches checkpoint tags: task-checkpoint/<taskID>/<number>.
 var checkpointTagRe = regexp.MustCompile(`^task-checkpoint/([^/]+)/(\d+)$`)
 
+// CheckpointOptions configures what a checkpoint commits.
+type CheckpointOptions struct {
+	Paths []string // Commit only these changed paths; empty commits all changes
+}
+
+// stageCheckpoint stages the changes a checkpoint commits and returns the
+// options committing exactly them.
+func (g *Git) stageCheckpoint(ctx context.Context, opts []CheckpointOptions) (CommitOptions, error) {
+	if len(opts) == 0 || len(opts[0].Paths) == 0 {
+		return CommitOptions{}, g.AddAll(ctx)
+	}
+
+	paths := opts[0].Paths
+	if err := g.Add(ctx, append([]string{"-A", "--"}, paths...)...); err != nil {
+		return CommitOptions{}, err
+	}
+
+	return CommitOptions{Paths: paths}, nil
+}
+
 // CreateCheckpoint creates a checkpoint for a task with default prefix [taskID].
 func (g *Git) CreateCheckpoint(ctx context.Context, taskID, message string) (*Checkpoint, error) {
 	defaultPrefix := fmt.Sprintf("[%s]", taskID)
@@ -35,7 +55,8 @@ func (g *Git) CreateCheckpoint(ctx context.Context, taskID, message string) (*Ch
 }
 
 // CreateCheckpointWithPrefix creates a checkpoint for a task with a custom commit prefix.
-func (g *Git) CreateCheckpointWithPrefix(ctx context.Context, taskID, message, commitPrefix string) (*Checkpoint, error) {
+// Optional CheckpointOptions limit what it commits.
+func (g *Git) CreateCheckpointWithPrefix(ctx context.Context, taskID, message, commitPrefix string, opts ...CheckpointOptions) (*Checkpoint, error) {
 	// Get next checkpoint number
 	existing, err := g.ListCheckpoints(ctx, taskID)
 	if err != nil {
@@ -55,11 +76,12 @@ func (g *Git) CreateCheckpointWithPrefix(ctx context.Context, taskID, message, c
 
 	var commitHash string
 	if hasChanges {
-		if err := g.AddAll(ctx); err != nil {
+		commitOpts, err := g.stageCheckpoint(ctx, opts)
+		if err != nil {
 			return nil, fmt.Errorf("stage changes: %w", err)
 		}
 		commitMsg := fmt.Sprintf("%s checkpoint %d: %s", commitPrefix, number, message)
-		commitHash, err = g.Commit(ctx, commitMsg)
+		commitHash, err = g.Commit(ctx, commitMsg, commitOpts)
 		if err != nil {
 			return nil, fmt.Errorf("create commit: %w", err)
 		}
@@ -87,6 +109,46 @@ func (g *Git) CreateCheckpointWithPrefix(ctx context.Context, taskID, message, c
 	}, nil
 }
 
+// CreateCheckpointAt creates checkpoint number for a task, committing even when
+// there are no changes. Multi-repository tasks use it to keep checkpoint numbers
+// and commits aligned across repositories so undo and redo move them together.
+func (g *Git) CreateCheckpointAt(ctx context.Context, taskID, message, commitPrefix string, number int) (*Checkpoint, error) {
+	if err := g.AddAll(ctx); err != nil {
+		return nil, fmt.Errorf("stage changes: %w", err)
+	}
+	commitMsg := fmt.Sprintf("%s checkpoint %d: %s", commitPrefix, number, message)
+	commitHash, err := g.Commit(ctx, commitMsg, CommitOptions{AllowEmpty: true})
+	if err != nil {
+		return nil, fmt.Errorf("create commit: %w", err)
+	}
+
+	tagName := fmt.Sprintf("%s/%s/%d", CheckpointPrefix, taskID, number)
+	if _, err := g.run(ctx, "tag", tagName, commitHash); err != nil {
+		return nil, fmt.Errorf("create checkpoint tag: %w", err)
+	}
+
+	return &Checkpoint{
+		ID:        commitHash,
+		TaskID:    taskID,
+		Number:    number,
+		Message:   message,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// NextCheckpointNumber returns the number the next checkpoint of a task gets.
+func (g *Git) NextCheckpointNumber(ctx context.Context, taskID string) (int, error) {
+	existing, err := g.ListCheckpoints(ctx, taskID)
+	if err != nil {
+		return 0, err
+	}
+	if len(existing) == 0 {
+		return 1, nil
+	}
+
+	return existing[len(existing)-1].Number + 1, nil
+}
+
 // ListCheckpoints returns all checkpoints for a task.
 func (g *Git) ListCheckpoints(ctx context.Context, taskID string) ([]*Checkpoint, error) {
 	prefix := fmt.Sprintf("%s/%s/", CheckpointPrefix, taskID)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -104,6 +105,63 @@ func TestCreateCheckpointNoChanges(t *testing.T) {
 	}
 }
 
+func TestCreateCheckpointPaths(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	dir := initTestRepo(t)
+	g, err := New(ctx, dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	for name, content := range map[string]string{"src/app.go": "package app\n", "notes.swp": "swap", "staged.txt": "staged"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	if err := os.Remove(filepath.Join(dir, "README.md")); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := g.Add(ctx, "staged.txt"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	changed, err := g.ChangedFiles(ctx)
+	if err != nil {
+		t.Fatalf("ChangedFiles: %v", err)
+	}
+	if got := strings.Join(changed, ","); got != "README.md,staged.txt,notes.swp,src/app.go" {
+		t.Errorf("ChangedFiles() = %v, want files in untracked directories listed", changed)
+	}
+
+	cp, err := g.CreateCheckpointWithPrefix(ctx, "task-paths", "agent files", "[task-paths]", CheckpointOptions{Paths: []string{"src/app.go", "README.md"}})
+	if err != nil {
+		t.Fatalf("CreateCheckpointWithPrefix: %v", err)
+	}
+
+	committed, err := g.run(ctx, "show", "--name-only", "--format=", cp.ID)
+	if err != nil {
+		t.Fatalf("git show: %v", err)
+	}
+	if got := strings.Fields(committed); strings.Join(got, ",") != "README.md,src/app.go" {
+		t.Errorf("checkpoint committed %v, want only the selected paths", got)
+	}
+
+	status, err := g.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if len(status) != 2 || status[0].Path != "staged.txt" || !status[0].IsStaged() || status[1].Path != "notes.swp" {
+		t.Errorf("Status() = %+v, want staged.txt still staged and notes.swp untracked", status)
+	}
+}
+
 func TestListCheckpoints(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -835,3 +893,46 @@ func TestCheckpointTracker_SaveAuto(t *testing.T) {
 		t.Errorf("checkpoint number = %d, want 1", cp.Number)
 	}
 }
+
+func TestCreateCheckpointAt(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	dir := initTestRepo(t)
+	g, err := New(ctx, dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	next, err := g.NextCheckpointNumber(ctx, "task-at")
+	if err != nil || next != 1 {
+		t.Fatalf("NextCheckpointNumber() = %d, %v, want 1", next, err)
+	}
+
+	// Without changes each checkpoint still gets its own commit
+	first, err := g.CreateCheckpointAt(ctx, "task-at", "first", "[task-at]", 1)
+	if err != nil {
+		t.Fatalf("CreateCheckpointAt: %v", err)
+	}
+	second, err := g.CreateCheckpointAt(ctx, "task-at", "second", "[task-at]", 2)
+	if err != nil {
+		t.Fatalf("CreateCheckpointAt: %v", err)
+	}
+	if first.ID == second.ID {
+		t.Error("aligned checkpoints should have distinct commits")
+	}
+
+	if next, _ := g.NextCheckpointNumber(ctx, "task-at"); next != 3 {
+		t.Errorf("NextCheckpointNumber() = %d, want 3", next)
+	}
+
+	undone, err := g.Undo(ctx, "task-at")
+	if err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if undone.Number != 1 {
+		t.Errorf("Undo() checkpoint = %d, want 1", undone.Number)
+	}
+}
@@ -0,0 +1,132 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Styles of generated commit messages.
+const (
+	CommitStyleConventional = "conventional" // type(scope): description
+	CommitStylePlain        = "plain"        // Imperative summary line
+)
+
+// MaxSubjectLength is the longest subject line, prefix included, a generated
+// commit message may have.
+const MaxSubjectLength = 72
+
+// conventionalSubjectRe matches a conventional commit subject.
+var conventionalSubjectRe = regexp.MustCompile(`^(feat|fix|docs|style|refactor|perf|test|build|ci|chore|revert)(\([\w./-]+\))?!?: \S`)
+
+// CommitMessage is a commit message split into subject and body.
+type CommitMessage struct {
+	Subject string
+	Body    string
+}
+
+// ValidCommitStyle reports whether style names a commit message style.
+func ValidCommitStyle(style string) bool {
+	return style == CommitStyleConventional || style == CommitStylePlain
+}
+
+// ParseCommitMessage reads a commit message written by an agent, dropping
+// code fences and quotes around it, and checks it follows style.
+func ParseCommitMessage(text, style string) (CommitMessage, error) {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text[strings.Index(text, "\n")+1:], "\n")
+		text = strings.TrimSpace(strings.TrimSuffix(text, "```"))
+	}
+
+	subject, body, _ := strings.Cut(text, "\n")
+	subject = strings.Trim(strings.TrimSpace(subject), "\"'`")
+	msg := CommitMessage{Subject: subject, Body: strings.TrimSpace(body)}
+
+	if msg.Subject == "" {
+		return CommitMessage{}, errors.New("empty commit message")
+	}
+	if style == CommitStyleConventional && !conventionalSubjectRe.MatchString(msg.Subject) {
+		return CommitMessage{}, fmt.Errorf("not a conventional commit subject: %q", msg.Subject)
+	}
+
+	return msg, nil
+}
+
+// Format renders the message with prefix (e.g. "[FEATURE-123]") before the
+// subject. A subject too long for the prefix is cut at a word boundary.
+func (m CommitMessage) Format(prefix string) string {
+	subject := m.Subject
+	if prefix != "" {
+		subject = prefix + " " + subject
+	}
+	if len(subject) > MaxSubjectLength {
+		cut := subject[:MaxSubjectLength]
+		if i := strings.LastIndex(cut, " "); i > len(prefix) {
+			cut = cut[:i]
+		}
+		subject = cut
+	}
+
+	if m.Body == "" {
+		return subject
+	}
+
+	return subject + "\n\n" + m.Body
+}
+
+// StagedDiff returns the staged changes as a stat summary followed by the
+// patch, cut to at most maxBytes of patch. Optional paths limit the diff.
+func (g *Git) StagedDiff(ctx context.Context, maxBytes int, paths ...string) (string, error) {
+	pathspec := append([]string{"--"}, paths...)
+	stat, err := g.run(ctx, append([]string{"diff", "--cached", "--stat"}, pathspec...)...)
+	if err != nil {
+		return "", fmt.Errorf("staged diff: %w", err)
+	}
+	patch, err := g.run(ctx, append([]string{"diff", "--cached"}, pathspec...)...)
+	if err != nil {
+		return "", fmt.Errorf("staged diff: %w", err)
+	}
+
+	if maxBytes > 0 && len(patch) > maxBytes {
+		patch = patch[:maxBytes] + "\n[diff truncated]\n"
+	}
+
+	return stat + "\n" + patch, nil
+}
+
+// CreateCheckpointWithMessage creates a checkpoint for a task, committing
+// all changes, or those optional CheckpointOptions select, with message as is.
+func (g *Git) CreateCheckpointWithMessage(ctx context.Context, taskID, message string, opts ...CheckpointOptions) (*Checkpoint, error) {
+	number, err := g.NextCheckpointNumber(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	commitOpts, err := g.stageCheckpoint(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("stage changes: %w", err)
+	}
+	commitHash, err := g.Commit(ctx, message, commitOpts)
+	if err != nil {
+		return nil, fmt.Errorf("create commit: %w", err)
+	}
+
+	tagName := fmt.Sprintf("%s/%s/%d", CheckpointPrefix, taskID, number)
+	if _, err := g.run(ctx, "tag", tagName, commitHash); err != nil {
+		return nil, fmt.Errorf("create checkpoint tag: %w", err)
+	}
+
+	subject, _, _ := strings.Cut(message, "\n")
+
+	return &Checkpoint{
+		ID:        commitHash,
+		TaskID:    taskID,
+		Number:    number,
+		Message:   subject,
+		Timestamp: time.Now(),
+	}, nil
+}
@@ -0,0 +1,141 @@
+package vcs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseCommitMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		style       string
+		wantSubject string
+		wantBody    string
+		wantErr     bool
+	}{
+		{
+			name:        "conventional with body",
+			text:        "feat(api): cache user lookups\n\nLookups hit the database on every request.",
+			style:       CommitStyleConventional,
+			wantSubject: "feat(api): cache user lookups",
+			wantBody:    "Lookups hit the database on every request.",
+		},
+		{
+			name:        "breaking change",
+			text:        "refactor!: drop the v1 client",
+			style:       CommitStyleConventional,
+			wantSubject: "refactor!: drop the v1 client",
+		},
+		{
+			name:        "code fence and quotes",
+			text:        "```\n\"fix: handle empty input\"\n```",
+			style:       CommitStyleConventional,
+			wantSubject: "fix: handle empty input",
+		},
+		{
+			name:    "not conventional",
+			text:    "Cache user lookups",
+			style:   CommitStyleConventional,
+			wantErr: true,
+		},
+		{
+			name:        "plain",
+			text:        "Cache user lookups\n",
+			style:       CommitStylePlain,
+			wantSubject: "Cache user lookups",
+		},
+		{
+			name:    "empty",
+			text:    "  \n",
+			style:   CommitStylePlain,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := ParseCommitMessage(tt.text, tt.style)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if msg.Subject != tt.wantSubject || msg.Body != tt.wantBody {
+				t.Errorf("ParseCommitMessage() = %+v, want subject %q, body %q", msg, tt.wantSubject, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCommitMessage_Format(t *testing.T) {
+	msg := CommitMessage{Subject: "feat: cache user lookups", Body: "Saves a query per request."}
+	if got, want := msg.Format("[FEATURE-1]"), "[FEATURE-1] feat: cache user lookups\n\nSaves a query per request."; got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+	if got := (CommitMessage{Subject: "fix: typo"}).Format(""); got != "fix: typo" {
+		t.Errorf("Format() without prefix = %q", got)
+	}
+
+	long := CommitMessage{Subject: "feat: " + strings.Repeat("word ", 20)}
+	subject := long.Format("[FEATURE-1]")
+	if len(subject) > MaxSubjectLength || strings.HasSuffix(subject, " ") || !strings.HasPrefix(subject, "[FEATURE-1] feat: word") {
+		t.Errorf("Format() = %q, want a subject cut at a word within %d characters", subject, MaxSubjectLength)
+	}
+}
+
+func TestValidCommitStyle(t *testing.T) {
+	for style, want := range map[string]bool{"conventional": true, "plain": true, "": false, "gitmoji": false} {
+		if got := ValidCommitStyle(style); got != want {
+			t.Errorf("ValidCommitStyle(%q) = %v, want %v", style, got, want)
+		}
+	}
+}
+
+func TestCreateCheckpointWithMessage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	dir := initTestRepo(t)
+	g, err := New(ctx, dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "cache.go"), []byte("package cache\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := g.AddAll(ctx); err != nil {
+		t.Fatalf("AddAll: %v", err)
+	}
+	diff, err := g.StagedDiff(ctx, 10)
+	if err != nil {
+		t.Fatalf("StagedDiff: %v", err)
+	}
+	if !strings.Contains(diff, "cache.go") || !strings.Contains(diff, "[diff truncated]") {
+		t.Errorf("StagedDiff() = %q, want the stat and a truncated patch", diff)
+	}
+
+	message := "[FEATURE-1] feat: add cache package\n\nHolds the lookup cache."
+	cp, err := g.CreateCheckpointWithMessage(ctx, "task-123", message)
+	if err != nil {
+		t.Fatalf("CreateCheckpointWithMessage: %v", err)
+	}
+	if cp.Number != 1 || cp.Message != "[FEATURE-1] feat: add cache package" {
+		t.Errorf("checkpoint = %+v", cp)
+	}
+
+	got, err := g.GetCommitMessage(ctx, "HEAD")
+	if err != nil {
+		t.Fatalf("GetCommitMessage: %v", err)
+	}
+	if got != message {
+		t.Errorf("commit message = %q, want %q", got, message)
+	}
+	if checkpoints, _ := g.ListCheckpoints(ctx, "task-123"); len(checkpoints) != 1 || checkpoints[0].ID != cp.ID {
+		t.Errorf("ListCheckpoints() = %v, want the new checkpoint", checkpoints)
+	}
+}
@@ -6,8 +6,12 @@
 //   - Worktree management for parallel task execution
 //   - Status and diff operations
 //
+// Projects without version control get a NullVCS instead of a Git; both
+// implement the VCS interface and report what they support via Capability.
+//
 // Thread safety:
 //   - Git methods are safe for concurrent use as they don't maintain mutable state.
+//   - WithSigning returns a new Git rather than changing the receiver.
 //   - The Git value itself should not be copied after creation.
 //
 // Usage:
@@ -43,6 +47,18 @@ const (
 // Git provides git operations for a repository.
 type Git struct {
 	repoRoot string
+	signing  *Signing        // Sign created commits; nil leaves it to git's config
+	observe  CommandObserver // Told about every command run; may be nil
+}
+
+// CommandObserver is called after each git command a Git runs, with its
+// arguments and the error it failed with, if any.
+type CommandObserver func(args []string, err error)
+
+// WithObserver returns a Git for the same repository that reports every
+// command it runs to observe, such as for an audit log.
+func (g *Git) WithObserver(observe CommandObserver) *Git {
+	return &Git{repoRoot: g.repoRoot, signing: g.signing, observe: observe}
 }
 
 // New creates a Git instance for the given path.
@@ -67,6 +83,21 @@ func IsRepo(ctx context.Context, path string) bool {
 	return err == nil
 }
 
+// Version returns the version of the installed git, e.g. "2.43.0" for
+// "git version 2.43.0" or "2.39.3" for "git version 2.39.3 (Apple Git-146)".
+func Version(ctx context.Context) (string, error) {
+	out, err := runGitCommandContext(ctx, "", "--version")
+	if err != nil {
+		return "", fmt.Errorf("git --version: %w", err)
+	}
+	fields := strings.Fields(out)
+	if len(fields) < 3 || fields[0] != "git" || fields[1] != "version" {
+		return "", fmt.Errorf("unexpected git --version output: %q", strings.TrimSpace(out))
+	}
+
+	return fields[2], nil
+}
+
 // findRepoRoot locates the git repository root.
 func findRepoRoot(ctx context.Context, path string) (string, error) {
 	absPath, err := filepath.Abs(path)
@@ -120,6 +151,26 @@ func (g *Git) Status(ctx context.Context) ([]FileStatus, error) {
 	return files, nil
 }
 
+// ChangedFiles returns the paths of all uncommitted changes. Files inside
+// untracked directories are listed one by one, and a rename is listed as the
+// deleted and the added path.
+func (g *Git) ChangedFiles(ctx context.Context) ([]string, error) {
+	out, err := g.run(ctx, "status", "--porcelain", "-z", "--untracked-files=all", "--no-renames")
+	if err != nil {
+		return nil, fmt.Errorf("git status: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range strings.Split(strings.TrimSuffix(out, "\x00"), "\x00") {
+		if len(entry) < gitStatusMinLength {
+			continue
+		}
+		paths = append(paths, entry[gitStatusPathStart:])
+	}
+
+	return paths, nil
+}
+
 // FileStatus represents a file's git status.
 type FileStatus struct {
 	Index   byte   // Status in index
@@ -165,23 +216,30 @@ func (g *Git) AddAll(ctx context.Context) error {
 
 // CommitOptions configures commit behavior.
 type CommitOptions struct {
-	AllowEmpty bool // Create commit even with no changes
+	AllowEmpty bool     // Create commit even with no changes
+	Paths      []string // Commit only these paths, leaving other staged changes staged
 }
 
 // Commit creates a commit with the given message.
 // Optional CommitOptions can be provided to modify behavior.
 func (g *Git) Commit(ctx context.Context, message string, opts ...CommitOptions) (string, error) {
-	args := []string{"commit"}
+	args := append(g.signingConfig(), "commit")
 
 	// Apply options if provided
 	if len(opts) > 0 && opts[0].AllowEmpty {
 		args = append(args, "--allow-empty")
 	}
+	if g.signing != nil {
+		args = append(args, "-S")
+	}
 
 	args = append(args, "-m", message)
+	if len(opts) > 0 && len(opts[0].Paths) > 0 {
+		args = append(append(args, "--"), opts[0].Paths...)
+	}
 
 	if _, err := g.run(ctx, args...); err != nil {
-		return "", fmt.Errorf("git commit: %w", err)
+		return "", fmt.Errorf("git commit: %w", g.signingError(ctx, err))
 	}
 
 	// Get the commit hash
@@ -267,6 +325,16 @@ func (g *Git) ResetSoft(ctx context.Context, ref string) error {
 	return nil
 }
 
+// ResetMixed resets to a ref, keeping changes in the working tree unstaged.
+func (g *Git) ResetMixed(ctx context.Context, ref string) error {
+	_, err := g.run(ctx, "reset", "--mixed", ref)
+	if err != nil {
+		return fmt.Errorf("reset mixed to %s: %w", ref, err)
+	}
+
+	return nil
+}
+
 // Clean removes untracked files.
 func (g *Git) Clean(ctx context.Context, force bool) error {
 	args := []string{"clean", "-d"}
@@ -278,6 +346,34 @@ func (g *Git) Clean(ctx context.Context, force bool) error {
 	return err
 }
 
+// RestorePaths returns paths to their state at HEAD, discarding staged and
+// unstaged changes. Paths HEAD does not contain are removed.
+func (g *Git) RestorePaths(ctx context.Context, paths ...string) error {
+	var tracked []string
+	for _, path := range paths {
+		if _, err := g.run(ctx, "cat-file", "-e", "HEAD:"+path); err == nil {
+			tracked = append(tracked, path)
+
+			continue
+		}
+		if _, err := g.run(ctx, "rm", "--cached", "--quiet", "--ignore-unmatch", "--", path); err != nil {
+			return fmt.Errorf("unstage %s: %w", path, err)
+		}
+		if err := os.Remove(filepath.Join(g.repoRoot, path)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove %s: %w", path, err)
+		}
+	}
+
+	if len(tracked) > 0 {
+		args := append([]string{"checkout", "HEAD", "--"}, tracked...)
+		if _, err := g.run(ctx, args...); err != nil {
+			return fmt.Errorf("restore paths: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // Stash saves changes to stash.
 func (g *Git) Stash(ctx context.Context, message string) error {
 	args := []string{"stash", "push"}
@@ -298,7 +394,12 @@ func (g *Git) StashPop(ctx context.Context) error {
 
 // run executes a git command in the repo root with context.
 func (g *Git) run(ctx context.Context, args ...string) (string, error) {
-	return runGitCommandContext(ctx, g.repoRoot, args...)
+	out, err := runGitCommandContext(ctx, g.repoRoot, args...)
+	if g.observe != nil {
+		g.observe(args, err)
+	}
+
+	return out, err
 }
 
 // runGitCommandContext executes a git command with context.
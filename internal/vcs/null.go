@@ -0,0 +1,65 @@
+package vcs
+
+import (
+	"context"
+	"fmt"
+)
+
+const nullVCSName = "none"
+
+// NullVCS is the VCS of a project without version control. It has no
+// capabilities: queries return empty results and operations that need
+// version control fail with ErrUnsupported.
+type NullVCS struct {
+	root string
+}
+
+// NewNull returns a NullVCS rooted at root.
+func NewNull(root string) *NullVCS {
+	return &NullVCS{root: root}
+}
+
+// Name returns "none".
+func (n *NullVCS) Name() string {
+	return nullVCSName
+}
+
+// Root returns the project root directory.
+func (n *NullVCS) Root() string {
+	return n.root
+}
+
+// Capabilities returns no capabilities.
+func (n *NullVCS) Capabilities() Capability {
+	return 0
+}
+
+// IsWorktree returns false.
+func (n *NullVCS) IsWorktree() bool {
+	return false
+}
+
+// CurrentBranch fails with ErrUnsupported; there are no branches.
+func (n *NullVCS) CurrentBranch(context.Context) (string, error) {
+	return "", fmt.Errorf("current branch: %w", ErrUnsupported)
+}
+
+// Status returns no files; changes are not tracked.
+func (n *NullVCS) Status(context.Context) ([]FileStatus, error) {
+	return nil, nil
+}
+
+// HasChanges returns false; changes are not tracked.
+func (n *NullVCS) HasChanges(context.Context) (bool, error) {
+	return false, nil
+}
+
+// ListCheckpoints returns no checkpoints.
+func (n *NullVCS) ListCheckpoints(context.Context, string) ([]*Checkpoint, error) {
+	return nil, nil
+}
+
+var (
+	_ VCS = (*Git)(nil)
+	_ VCS = (*NullVCS)(nil)
+)
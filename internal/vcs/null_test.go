@@ -0,0 +1,72 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNullVCS(t *testing.T) {
+	ctx := context.Background()
+	n := NewNull("/project")
+
+	if n.Name() != "none" || n.Root() != "/project" || n.IsWorktree() {
+		t.Errorf("NullVCS = %q, %q, worktree %v", n.Name(), n.Root(), n.IsWorktree())
+	}
+	if n.Capabilities() != 0 {
+		t.Errorf("Capabilities() = %v, want none", n.Capabilities())
+	}
+	if _, err := n.CurrentBranch(ctx); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("CurrentBranch() error = %v, want ErrUnsupported", err)
+	}
+	if files, err := n.Status(ctx); err != nil || len(files) != 0 {
+		t.Errorf("Status() = %v, %v, want no files", files, err)
+	}
+	if changed, err := n.HasChanges(ctx); err != nil || changed {
+		t.Errorf("HasChanges() = %v, %v, want false", changed, err)
+	}
+	if checkpoints, err := n.ListCheckpoints(ctx, "task1"); err != nil || len(checkpoints) != 0 {
+		t.Errorf("ListCheckpoints() = %v, %v, want none", checkpoints, err)
+	}
+}
+
+func TestCapability(t *testing.T) {
+	c := CapBranches | CapCheckpoints
+
+	if !c.Has(CapBranches) || !c.Has(CapBranches|CapCheckpoints) {
+		t.Error("Has() should report present capabilities")
+	}
+	if c.Has(CapWorktrees) || c.Has(CapBranches|CapWorktrees) {
+		t.Error("Has() should report missing capabilities")
+	}
+	if got := c.String(); got != "branches, checkpoints" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := Capability(0).String(); got != "none" {
+		t.Errorf("String() = %q, want none", got)
+	}
+}
+
+func TestRequire(t *testing.T) {
+	if err := Require(&Git{repoRoot: "/repo"}, CapCheckpoints|CapPullRequests, "undo"); err != nil {
+		t.Errorf("Require(git) = %v, want nil", err)
+	}
+
+	err := Require(NewNull("/project"), CapCheckpoints, "undo")
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("Require(null) error = %v, want ErrUnsupported", err)
+	}
+	if !strings.Contains(err.Error(), "undo requires a git repository") {
+		t.Errorf("Require(null) error = %q", err)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+
+	v := Detect(context.Background(), dir)
+	if v.Name() != "none" || v.Root() != dir {
+		t.Errorf("Detect(non-repo) = %s at %s, want none at %s", v.Name(), v.Root(), dir)
+	}
+}
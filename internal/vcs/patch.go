@@ -0,0 +1,78 @@
+package vcs
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// FormatPatch writes the commits in revRange (e.g. "base..branch") as a
+// numbered mailbox patch series into dir and returns the file names, oldest
+// commit first.
+func (g *Git) FormatPatch(ctx context.Context, revRange, dir string) ([]string, error) {
+	// --keep-subject keeps "[PREFIX] ..." commit subjects out of [PATCH n/m] handling
+	out, err := g.run(ctx, "format-patch", "--keep-subject", "--no-signature", "-o", dir, revRange)
+	if err != nil {
+		return nil, fmt.Errorf("format-patch %s: %w", revRange, err)
+	}
+
+	var files []string
+	for line := range strings.SplitSeq(strings.TrimSpace(out), "\n") {
+		if line != "" {
+			files = append(files, filepath.Base(line))
+		}
+	}
+
+	return files, nil
+}
+
+// ApplyMailbox applies a FormatPatch series onto the current branch, one
+// commit per patch, keeping authors and messages. A patch that does not
+// apply leaves the am session in progress; see AbortMailbox.
+func (g *Git) ApplyMailbox(ctx context.Context, patches ...string) error {
+	args := append(g.signingConfig(), "am", "--3way", "--keep", "--keep-cr")
+	if g.signing != nil {
+		args = append(args, "-S")
+	}
+	_, err := g.run(ctx, append(args, patches...)...)
+
+	return g.signingError(ctx, err)
+}
+
+// AbortMailbox abandons an in-progress ApplyMailbox and restores the branch.
+func (g *Git) AbortMailbox(ctx context.Context) error {
+	_, err := g.run(ctx, "am", "--abort")
+
+	return err
+}
+
+// CreateBundle writes branch, without the history it shares with base, to a
+// git bundle file.
+func (g *Git) CreateBundle(ctx context.Context, file, branch, base string) error {
+	if _, err := g.run(ctx, "bundle", "create", file, branch, "^"+base); err != nil {
+		return fmt.Errorf("create bundle: %w", err)
+	}
+
+	return nil
+}
+
+// FetchBundle verifies that the repository has the history a bundle builds
+// on and creates branch from the bundle's ref.
+func (g *Git) FetchBundle(ctx context.Context, file, ref, branch string) error {
+	if _, err := g.run(ctx, "bundle", "verify", file); err != nil {
+		return fmt.Errorf("verify bundle: %w", err)
+	}
+	if _, err := g.run(ctx, "fetch", file, ref+":refs/heads/"+branch); err != nil {
+		return fmt.Errorf("fetch bundle: %w", err)
+	}
+
+	return nil
+}
+
+// HasCommit reports whether the repository contains commit.
+func (g *Git) HasCommit(ctx context.Context, commit string) bool {
+	_, err := g.run(ctx, "cat-file", "-e", commit+"^{commit}")
+
+	return err == nil
+}
@@ -0,0 +1,159 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Signature formats, as git's gpg.format takes them.
+const (
+	SigningOpenPGP = "openpgp"
+	SigningSSH     = "ssh"
+	SigningX509    = "x509"
+)
+
+// ErrSigning is returned when a commit could not be signed.
+var ErrSigning = errors.New("commit signing failed")
+
+// Signing configures how commits are signed. An empty Format or Key keeps
+// what git itself is configured with (gpg.format, user.signingkey).
+type Signing struct {
+	Format string // openpgp, ssh or x509
+	Key    string // Key ID, SSH key file (~/ allowed) or literal "key::" SSH key
+}
+
+// ValidSigningFormat reports whether format names a signature format.
+func ValidSigningFormat(format string) bool {
+	return format == SigningOpenPGP || format == SigningSSH || format == SigningX509
+}
+
+// WithSigning returns a Git for the same repository that signs the commits
+// it creates, including merge commits and commits replayed by a rebase.
+func (g *Git) WithSigning(s Signing) *Git {
+	if key, err := expandSigningKey(s.Key); err == nil {
+		s.Key = key
+	}
+
+	return &Git{repoRoot: g.repoRoot, signing: &s, observe: g.observe}
+}
+
+// Signs reports whether commits created through g are signed.
+func (g *Git) Signs() bool {
+	return g.signing != nil
+}
+
+// signingConfig returns the -c options that select the configured format and
+// key, placed before the git subcommand.
+func (g *Git) signingConfig() []string {
+	if g.signing == nil {
+		return nil
+	}
+
+	var args []string
+	if g.signing.Format != "" {
+		args = append(args, "-c", "gpg.format="+g.signing.Format)
+	}
+	if g.signing.Key != "" {
+		args = append(args, "-c", "user.signingkey="+g.signing.Key)
+	}
+
+	return args
+}
+
+// signingError explains a failed commit when it failed to be signed.
+func (g *Git) signingError(ctx context.Context, err error) error {
+	if g.signing == nil || err == nil {
+		return err
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "failed to sign") && !strings.Contains(msg, "failed to write commit object") {
+		return err
+	}
+	if setupErr := g.CheckSigning(ctx); setupErr != nil {
+		return setupErr
+	}
+
+	return fmt.Errorf("%w: %w", ErrSigning, err)
+}
+
+// CheckSigning verifies that commits can be signed: a key is configured
+// where the format needs one, an SSH key file exists and the signing program
+// is installed. It does not sign anything.
+func (g *Git) CheckSigning(ctx context.Context) error {
+	var s Signing
+	if g.signing != nil {
+		s = *g.signing
+	}
+
+	format := s.Format
+	if format == "" {
+		format, _ = g.GetConfig(ctx, "gpg.format")
+	}
+	if format == "" {
+		format = SigningOpenPGP
+	}
+	if !ValidSigningFormat(format) {
+		return fmt.Errorf("%w: unknown signature format %q (use openpgp, ssh or x509)", ErrSigning, format)
+	}
+
+	key := s.Key
+	if key == "" {
+		key, _ = g.GetConfig(ctx, "user.signingkey")
+	}
+
+	program, programKey := "gpg", "gpg.program"
+	switch format {
+	case SigningSSH:
+		program, programKey = "ssh-keygen", "gpg.ssh.program"
+		if key == "" {
+			return fmt.Errorf("%w: no SSH signing key configured (set git.signing_key or run 'git config user.signingkey ~/.ssh/id_ed25519.pub')", ErrSigning)
+		}
+		if path, isFile := sshKeyFile(key); isFile {
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("%w: SSH signing key %s: %w", ErrSigning, path, err)
+			}
+		}
+	case SigningX509:
+		program, programKey = "gpgsm", "gpg.x509.program"
+	}
+	if configured, _ := g.GetConfig(ctx, programKey); configured != "" {
+		program = configured
+	}
+	if _, err := exec.LookPath(program); err != nil {
+		return fmt.Errorf("%w: %s signing needs %s: %w", ErrSigning, format, program, err)
+	}
+
+	return nil
+}
+
+// sshKeyFile returns the file an SSH signing key names, and false for keys
+// given literally.
+func sshKeyFile(key string) (string, bool) {
+	if strings.HasPrefix(key, "key::") || strings.HasPrefix(key, "ssh-") {
+		return "", false
+	}
+	path, err := expandSigningKey(key)
+	if err != nil {
+		return key, true
+	}
+
+	return path, true
+}
+
+// expandSigningKey expands a leading ~/ in a key file path.
+func expandSigningKey(key string) (string, error) {
+	if !strings.HasPrefix(key, "~/") {
+		return key, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home directory: %w", err)
+	}
+
+	return filepath.Join(home, key[2:]), nil
+}
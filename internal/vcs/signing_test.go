@@ -0,0 +1,150 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckSigning(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	dir := initTestRepo(t)
+	g, err := New(ctx, dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		signing Signing
+		want    string
+	}{
+		{name: "ssh without key", signing: Signing{Format: SigningSSH}, want: "no SSH signing key configured"},
+		{name: "missing ssh key file", signing: Signing{Format: SigningSSH, Key: filepath.Join(dir, "missing.pub")}, want: "missing.pub"},
+		{name: "unknown format", signing: Signing{Format: "pgp"}, want: `unknown signature format "pgp"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := g.WithSigning(tt.signing).CheckSigning(ctx)
+			if !errors.Is(err, ErrSigning) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("CheckSigning() = %v, want ErrSigning mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommit_SigningFailure(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	dir := initTestRepo(t)
+	g, err := New(ctx, dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	signed := g.WithSigning(Signing{Format: SigningSSH, Key: filepath.Join(dir, "missing.pub")})
+	_, err = signed.Commit(ctx, "unsigned", CommitOptions{AllowEmpty: true})
+	if !errors.Is(err, ErrSigning) || !strings.Contains(err.Error(), "missing.pub") {
+		t.Errorf("Commit() = %v, want ErrSigning naming the missing key", err)
+	}
+}
+
+func TestWithSigning(t *testing.T) {
+	g := &Git{repoRoot: "/repo"}
+	signed := g.WithSigning(Signing{Format: SigningSSH, Key: "key::ssh-ed25519 AAAA"})
+
+	if g.Signs() || !signed.Signs() || signed.Root() != "/repo" {
+		t.Fatalf("WithSigning changed the receiver or lost the repository")
+	}
+	want := []string{"-c", "gpg.format=ssh", "-c", "user.signingkey=key::ssh-ed25519 AAAA"}
+	if got := signed.signingConfig(); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("signingConfig() = %q, want %q", got, want)
+	}
+	if got := g.WithSigning(Signing{}).signingConfig(); len(got) != 0 {
+		t.Errorf("signingConfig() = %q, want git's own configuration", got)
+	}
+}
+
+func TestCommit_SSHSigned(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+
+	ctx := context.Background()
+	dir := initTestRepo(t)
+	key := filepath.Join(t.TempDir(), "id_ed25519")
+	if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", key).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen: %v: %s", err, out)
+	}
+
+	g, err := New(ctx, dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	signed := g.WithSigning(Signing{Format: SigningSSH, Key: key + ".pub"})
+	if err := signed.CheckSigning(ctx); err != nil {
+		t.Fatalf("CheckSigning: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "signed.txt"), []byte("signed\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	cp, err := signed.CreateCheckpoint(ctx, "task-123", "signed")
+	if err != nil {
+		t.Fatalf("CreateCheckpoint: %v", err)
+	}
+
+	raw, err := g.run(ctx, "cat-file", "commit", cp.ID)
+	if err != nil {
+		t.Fatalf("cat-file: %v", err)
+	}
+	if !strings.Contains(raw, "-----BEGIN SSH SIGNATURE-----") {
+		t.Errorf("checkpoint commit is not SSH-signed:\n%s", raw)
+	}
+}
+
+func TestWithObserver(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	g, err := New(ctx, initTestRepo(t))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	var commands []string
+	observed := g.WithObserver(func(args []string, err error) {
+		commands = append(commands, strings.Join(args, " ")+fmt.Sprintf(" (failed: %v)", err != nil))
+	}).WithSigning(Signing{})
+
+	if _, err := observed.CurrentBranch(ctx); err != nil {
+		t.Fatalf("CurrentBranch: %v", err)
+	}
+	_ = observed.Checkout(ctx, "no-such-branch")
+	if _, err := g.CurrentBranch(ctx); err != nil {
+		t.Fatalf("CurrentBranch: %v", err)
+	}
+
+	want := []string{"rev-parse --abbrev-ref HEAD (failed: false)", "checkout no-such-branch (failed: true)"}
+	if strings.Join(commands, "\n") != strings.Join(want, "\n") {
+		t.Errorf("observed %q, want %q", commands, want)
+	}
+}
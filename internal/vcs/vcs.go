@@ -0,0 +1,109 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnsupported is returned when the project's version control backend
+// lacks a capability an operation needs.
+var ErrUnsupported = errors.New("not supported by version control")
+
+// Capability is a feature a version control backend may provide.
+// Capabilities are bit flags and can be combined.
+type Capability uint
+
+const (
+	// CapBranches allows creating, switching and merging task branches.
+	CapBranches Capability = 1 << iota
+	// CapCheckpoints allows checkpoint commits and undo/redo.
+	CapCheckpoints
+	// CapWorktrees allows running tasks in separate worktrees.
+	CapWorktrees
+	// CapPullRequests allows pushing branches for pull requests.
+	CapPullRequests
+	// CapStatus allows listing changed files.
+	CapStatus
+)
+
+// capabilityNames lists capability names in flag order.
+var capabilityNames = []string{"branches", "checkpoints", "worktrees", "pull requests", "status"}
+
+// Has reports whether all capabilities in want are present.
+func (c Capability) Has(want Capability) bool {
+	return c&want == want
+}
+
+// String returns the capability names joined by commas.
+func (c Capability) String() string {
+	if c == 0 {
+		return "none"
+	}
+
+	var names []string
+	for i, name := range capabilityNames {
+		if c.Has(1 << i) {
+			names = append(names, name)
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// VCS is the version control backend of a project. Git is the full
+// implementation; NullVCS stands in when no version control is detected, so
+// callers query capabilities instead of checking for a nil *Git.
+type VCS interface {
+	// Name identifies the backend ("git" or "none").
+	Name() string
+	// Root returns the project root directory.
+	Root() string
+	// Capabilities returns the features the backend supports.
+	Capabilities() Capability
+	// IsWorktree reports whether Root is a linked worktree.
+	IsWorktree() bool
+	// CurrentBranch returns the checked-out branch.
+	CurrentBranch(ctx context.Context) (string, error)
+	// Status returns the changed files.
+	Status(ctx context.Context) ([]FileStatus, error)
+	// HasChanges reports whether there are uncommitted changes.
+	HasChanges(ctx context.Context) (bool, error)
+	// ListCheckpoints returns the checkpoints of a task.
+	ListCheckpoints(ctx context.Context, taskID string) ([]*Checkpoint, error)
+}
+
+// Detect returns the Git repository containing path, or a NullVCS rooted at
+// path when there is none.
+func Detect(ctx context.Context, path string) VCS {
+	g, err := New(ctx, path)
+	if err != nil {
+		return NewNull(path)
+	}
+
+	return g
+}
+
+// Require returns an error wrapping ErrUnsupported when v lacks any of the
+// capabilities in want. feature describes the operation for the message.
+func Require(v VCS, want Capability, feature string) error {
+	if v.Capabilities().Has(want) {
+		return nil
+	}
+	if v.Name() == nullVCSName {
+		return fmt.Errorf("%s requires a git repository: %w", feature, ErrUnsupported)
+	}
+
+	return fmt.Errorf("%s needs %s: %w", feature, want&^v.Capabilities(), ErrUnsupported)
+}
+
+// Name returns "git".
+func (g *Git) Name() string {
+	return "git"
+}
+
+// Capabilities returns every capability; git supports all of them.
+func (g *Git) Capabilities() Capability {
+	return CapBranches | CapCheckpoints | CapWorktrees | CapPullRequests | CapStatus
+}
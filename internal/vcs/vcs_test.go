@@ -700,6 +700,60 @@ func TestClean(t *testing.T) {
 	}
 }
 
+func TestRestorePaths(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	dir := initTestRepo(t)
+	g, err := New(ctx, dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	files := map[string]string{
+		"README.md":   "changed\n",
+		"new.txt":     "new",
+		"staged.txt":  "staged",
+		"keep.txt":    "keep",
+		"sub/new.txt": "nested",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	if err := g.Add(ctx, "staged.txt"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	if err := g.RestorePaths(ctx, "README.md", "new.txt", "staged.txt", "sub/new.txt"); err != nil {
+		t.Fatalf("RestorePaths: %v", err)
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(dir, "README.md")); string(data) != "# Test\n" {
+		t.Errorf("README.md = %q, want the committed content", data)
+	}
+	for _, name := range []string{"new.txt", "staged.txt", "sub/new.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed", name)
+		}
+	}
+
+	changed, err := g.ChangedFiles(ctx)
+	if err != nil {
+		t.Fatalf("ChangedFiles: %v", err)
+	}
+	if len(changed) != 1 || changed[0] != "keep.txt" {
+		t.Errorf("ChangedFiles = %v, want only keep.txt", changed)
+	}
+}
+
 func TestStashAndPop(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
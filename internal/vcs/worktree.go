@@ -15,6 +15,19 @@ type Worktree struct {
 	Commit string // HEAD commit
 	Bare   bool   // Is this the bare repository
 	Main   bool   // Is this the main worktree
+
+	Locked     bool   // Locked with 'git worktree lock'
+	LockReason string // Reason given when locking (may be empty)
+	Prunable   bool   // Git considers the worktree stale (e.g. directory gone)
+}
+
+// ManagedWorktree is a worktree in the worktrees directory mehrhof creates
+// task worktrees in.
+type ManagedWorktree struct {
+	Worktree
+
+	Name    string // Directory name (the task ID with the default naming)
+	Missing bool   // The worktree directory no longer exists
 }
 
 // ListWorktrees returns all worktrees in the repository.
@@ -48,6 +61,11 @@ func (g *Git) ListWorktrees(ctx context.Context) ([]Worktree, error) {
 			current.Branch = strings.TrimPrefix(branch, "refs/heads/")
 		} else if line == "bare" {
 			current.Bare = true
+		} else if line == "locked" || strings.HasPrefix(line, "locked ") {
+			current.Locked = true
+			current.LockReason = strings.TrimSpace(strings.TrimPrefix(line, "locked"))
+		} else if line == "prunable" || strings.HasPrefix(line, "prunable ") {
+			current.Prunable = true
 		}
 	}
 
@@ -126,6 +144,16 @@ func (g *Git) RemoveWorktree(ctx context.Context, path string, force bool) error
 	return nil
 }
 
+// UnlockWorktree removes the lock of a worktree so it can be removed or pruned.
+func (g *Git) UnlockWorktree(ctx context.Context, path string) error {
+	_, err := g.run(ctx, "worktree", "unlock", path)
+	if err != nil {
+		return fmt.Errorf("unlock worktree: %w", err)
+	}
+
+	return nil
+}
+
 // PruneWorktrees removes stale worktree information.
 func (g *Git) PruneWorktrees(ctx context.Context) error {
 	_, err := g.run(ctx, "worktree", "prune")
@@ -166,20 +194,87 @@ func (g *Git) WorktreeExists(ctx context.Context, path string) bool {
 	return false
 }
 
-// GetWorktreePath returns a standard worktree path for a task
-// Worktrees are created as siblings of the main repo: ../repo-worktrees/task-id.
-func (g *Git) GetWorktreePath(taskID string) string {
+// WorktreesDir returns the default directory task worktrees are created in:
+// ../repo-worktrees next to the main repo.
+func (g *Git) WorktreesDir() string {
 	repoName := filepath.Base(g.repoRoot)
 	parent := filepath.Dir(g.repoRoot)
 
-	return filepath.Join(parent, repoName+"-worktrees", taskID)
+	return filepath.Join(parent, repoName+"-worktrees")
+}
+
+// ResolveWorktreesDir returns the absolute worktrees directory for a configured
+// location. A leading ~ is the user's home directory and relative paths are
+// relative to the repository root. An empty location is WorktreesDir.
+func (g *Git) ResolveWorktreesDir(dir string) (string, error) {
+	switch {
+	case dir == "":
+		return g.WorktreesDir(), nil
+	case dir == "~" || strings.HasPrefix(dir, "~/"):
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("resolve home directory: %w", err)
+		}
+
+		return filepath.Join(home, dir[1:]), nil
+	case filepath.IsAbs(dir):
+		return filepath.Clean(dir), nil
+	default:
+		return filepath.Join(g.repoRoot, dir), nil
+	}
+}
+
+// GetWorktreePath returns a standard worktree path for a task
+// Worktrees are created as siblings of the main repo: ../repo-worktrees/task-id.
+func (g *Git) GetWorktreePath(taskID string) string {
+	return filepath.Join(g.WorktreesDir(), taskID)
 }
 
 // EnsureWorktreesDir creates the worktrees directory if it doesn't exist.
 func (g *Git) EnsureWorktreesDir() error {
-	repoName := filepath.Base(g.repoRoot)
-	parent := filepath.Dir(g.repoRoot)
-	worktreesDir := filepath.Join(parent, repoName+"-worktrees")
+	return os.MkdirAll(g.WorktreesDir(), 0o755)
+}
+
+// ListManagedWorktrees returns the worktrees registered directly in dir, the
+// worktrees directory; an empty dir is WorktreesDir. Worktrees elsewhere
+// (including the main one) are left out.
+func (g *Git) ListManagedWorktrees(ctx context.Context, dir string) ([]ManagedWorktree, error) {
+	worktrees, err := g.ListWorktrees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if dir == "" {
+		dir = g.WorktreesDir()
+	}
+	dir = resolvePath(dir)
+	var managed []ManagedWorktree
+	for _, wt := range worktrees {
+		if wt.Main || filepath.Dir(resolvePath(wt.Path)) != dir {
+			continue
+		}
+
+		_, statErr := os.Stat(wt.Path)
+		managed = append(managed, ManagedWorktree{
+			Worktree: wt,
+			Name:     filepath.Base(wt.Path),
+			Missing:  os.IsNotExist(statErr),
+		})
+	}
+
+	return managed, nil
+}
+
+// resolvePath returns path with symlinks resolved as far as it exists, so
+// paths reported by git compare equal to paths built from the repo root.
+func resolvePath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	// The path itself may be gone; resolve its parent instead
+	if resolved, err := filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+		return filepath.Join(resolved, filepath.Base(path))
+	}
 
-	return os.MkdirAll(worktreesDir, 0o755)
+	return filepath.Clean(path)
 }
@@ -515,3 +515,91 @@ func TestGetMainWorktreePath_NotWorktree(t *testing.T) {
 		t.Error("GetMainWorktreePath should fail on main repo")
 	}
 }
+
+func TestListManagedWorktrees(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	dir := initTestRepo(t)
+	g, err := New(ctx, dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	baseBranch, _ := g.CurrentBranch(ctx)
+	for _, id := range []string{"task-a", "task-b"} {
+		if err := g.CreateWorktreeNewBranch(ctx, g.GetWorktreePath(id), "feature/"+id, baseBranch); err != nil {
+			t.Fatalf("CreateWorktreeNewBranch(%s): %v", id, err)
+		}
+	}
+	// Worktrees outside the worktrees directory are not managed
+	if err := g.CreateWorktreeNewBranch(ctx, filepath.Join(t.TempDir(), "other"), "other", baseBranch); err != nil {
+		t.Fatalf("CreateWorktreeNewBranch(other): %v", err)
+	}
+
+	if err := runGit(ctx, dir, "worktree", "lock", "--reason", "in use", g.GetWorktreePath("task-b")); err != nil {
+		t.Fatalf("lock worktree: %v", err)
+	}
+	if err := os.RemoveAll(g.GetWorktreePath("task-b")); err != nil {
+		t.Fatalf("remove worktree dir: %v", err)
+	}
+
+	managed, err := g.ListManagedWorktrees(ctx, "")
+	if err != nil {
+		t.Fatalf("ListManagedWorktrees: %v", err)
+	}
+	if len(managed) != 2 {
+		t.Fatalf("got %d managed worktrees, want 2: %+v", len(managed), managed)
+	}
+
+	a, b := managed[0], managed[1]
+	if a.Name != "task-a" || a.Branch != "feature/task-a" || a.Missing || a.Locked {
+		t.Errorf("task-a = %+v", a)
+	}
+	if b.Name != "task-b" || !b.Missing || !b.Locked || b.LockReason != "in use" {
+		t.Errorf("task-b = %+v", b)
+	}
+
+	if err := g.UnlockWorktree(ctx, b.Path); err != nil {
+		t.Fatalf("UnlockWorktree: %v", err)
+	}
+	if err := g.PruneWorktrees(ctx); err != nil {
+		t.Fatalf("PruneWorktrees: %v", err)
+	}
+	managed, _ = g.ListManagedWorktrees(ctx, "")
+	if len(managed) != 1 || managed[0].Name != "task-a" {
+		t.Errorf("after prune = %+v, want only task-a", managed)
+	}
+}
+
+func TestResolveWorktreesDir(t *testing.T) {
+	ctx := context.Background()
+	dir := initTestRepo(t)
+	g, err := New(ctx, dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	home, _ := os.UserHomeDir()
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"", g.WorktreesDir()},
+		{"~/worktrees", filepath.Join(home, "worktrees")},
+		{"/srv/worktrees/", "/srv/worktrees"},
+		{"../trees", filepath.Join(filepath.Dir(g.Root()), "trees")},
+	}
+
+	for _, tt := range tests {
+		got, err := g.ResolveWorktreesDir(tt.dir)
+		if err != nil {
+			t.Fatalf("ResolveWorktreesDir(%q): %v", tt.dir, err)
+		}
+		if got != tt.want {
+			t.Errorf("ResolveWorktreesDir(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package workflow
 import (
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/valksor/go-mehrhof/internal/events"
 )
@@ -35,11 +36,13 @@ func NewMachineBuilder() *MachineBuilder {
 		copied := make([]Transition, len(transitions))
 		for i, t := range transitions {
 			copied[i] = Transition{
-				From:    t.From,
-				Event:   t.Event,
-				To:      t.To,
-				Guards:  append([]GuardFunc{}, t.Guards...),
-				Effects: append([]EffectFunc{}, t.Effects...),
+				From:              t.From,
+				Event:             t.Event,
+				To:                t.To,
+				Guards:            append([]GuardFunc{}, t.Guards...),
+				Effects:           append([]EffectFunc{}, t.Effects...),
+				TransitionGuards:  slices.Clone(t.TransitionGuards),
+				TransitionEffects: slices.Clone(t.TransitionEffects),
 			}
 		}
 		b.transitions[k] = copied
@@ -188,8 +191,8 @@ func (b *MachineBuilder) AddGuardToTransition(from State, event Event, guard Gua
 }
 
 // AddEffectToTransition adds an effect to an existing transition.
-// Note: This adds the underlying EffectFunc. For critical effects,
-// use RegisterTransitionEffects which handles the CriticalEffect wrapper.
+// Note: This adds the underlying EffectFunc. For critical, ordered or
+// time-limited effects, use RegisterTransitionEffects.
 func (b *MachineBuilder) AddEffectToTransition(from State, event Event, effect EffectFunc) error {
 	key := TransitionKey{From: from, Event: event}
 	transitions, ok := b.transitions[key]
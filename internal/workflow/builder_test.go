@@ -3,7 +3,9 @@ package workflow
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/valksor/go-mehrhof/internal/events"
 )
@@ -312,3 +314,128 @@ func TestWrapEffect(t *testing.T) {
 		t.Error("WrapCriticalEffect should create critical effect")
 	}
 }
+
+func TestMachineBuilder_RegisterTransitionGuard(t *testing.T) {
+	newMachine := func(t *testing.T, guards ...TransitionGuard) *Machine {
+		t.Helper()
+		builder := NewMachineBuilder()
+		for _, g := range guards {
+			if err := builder.RegisterTransitionGuard(StateIdle, EventFinish, g); err != nil {
+				t.Fatalf("RegisterTransitionGuard(%s) error = %v", g.Name, err)
+			}
+		}
+		machine := builder.Build(events.NewBus())
+		machine.SetWorkUnit(&WorkUnit{ID: "test", Specifications: []string{"spec.md"}})
+
+		return machine
+	}
+
+	var order []string
+	guard := func(name string, rank int, passed bool, reason string) TransitionGuard {
+		return TransitionGuard{
+			Name:  name,
+			Order: rank,
+			Check: func(ctx context.Context, wu *WorkUnit) (bool, string, error) {
+				order = append(order, name)
+
+				return passed, reason, nil
+			},
+		}
+	}
+
+	t.Run("runs in order and passes", func(t *testing.T) {
+		order = nil
+		machine := newMachine(t, guard("b", 0, true, ""), guard("c", -1, true, ""), guard("a", 0, true, ""))
+		if err := machine.Dispatch(context.Background(), EventFinish); err != nil {
+			t.Fatalf("Dispatch() error = %v", err)
+		}
+		if strings.Join(order, ",") != "c,a,b" {
+			t.Errorf("guard order = %v, want c,a,b", order)
+		}
+		if machine.State() != StateDone {
+			t.Errorf("state = %v, want done", machine.State())
+		}
+	})
+
+	t.Run("veto with reason", func(t *testing.T) {
+		order = nil
+		machine := newMachine(t, guard("changelog/has-entry", 0, false, "no CHANGELOG entry"), guard("z", 1, true, ""))
+		err := machine.Dispatch(context.Background(), EventFinish)
+		if !errors.Is(err, ErrTransitionVetoed) || !strings.Contains(err.Error(), "no CHANGELOG entry") {
+			t.Errorf("Dispatch() error = %v, want a veto with the reason", err)
+		}
+		if len(order) != 1 {
+			t.Errorf("guards after the veto ran: %v", order)
+		}
+		if machine.State() != StateIdle {
+			t.Errorf("state = %v, want idle", machine.State())
+		}
+	})
+
+	t.Run("timeout vetoes", func(t *testing.T) {
+		slow := TransitionGuard{
+			Name:    "slow",
+			Timeout: 10 * time.Millisecond,
+			Check: func(ctx context.Context, wu *WorkUnit) (bool, string, error) {
+				<-ctx.Done()
+
+				return false, "", ctx.Err()
+			},
+		}
+		err := newMachine(t, slow).Dispatch(context.Background(), EventFinish)
+		if !errors.Is(err, ErrTransitionVetoed) || !strings.Contains(err.Error(), "timed out") {
+			t.Errorf("Dispatch() error = %v, want a timeout veto", err)
+		}
+	})
+
+	t.Run("unknown transition", func(t *testing.T) {
+		if err := NewMachineBuilder().RegisterTransitionGuard(StateDone, EventPlan, guard("x", 0, true, "")); err == nil {
+			t.Error("expected an error for a missing transition")
+		}
+	})
+}
+
+func TestMachineBuilder_RegisterTransitionEffects(t *testing.T) {
+	var ran []string
+	effect := func(name string, order int, critical bool, err error) TransitionEffect {
+		return TransitionEffect{
+			CriticalEffect: CriticalEffect{
+				Name:     name,
+				Critical: critical,
+				Fn: func(ctx context.Context, wu *WorkUnit) error {
+					ran = append(ran, name)
+
+					return err
+				},
+			},
+			Order: order,
+		}
+	}
+
+	builder := NewMachineBuilder()
+	err := builder.RegisterTransitionEffects(StateIdle, EventFinish,
+		effect("notify", 1, false, errors.New("offline")),
+		effect("stamp", 0, true, nil),
+	)
+	if err != nil {
+		t.Fatalf("RegisterTransitionEffects() error = %v", err)
+	}
+	machine := builder.Build(events.NewBus())
+	machine.SetWorkUnit(&WorkUnit{ID: "test", Specifications: []string{"spec.md"}})
+
+	if err := machine.Dispatch(context.Background(), EventFinish); err != nil {
+		t.Fatalf("Dispatch() error = %v, non-critical failures must not block", err)
+	}
+	if strings.Join(ran, ",") != "stamp,notify" {
+		t.Errorf("effect order = %v, want stamp,notify", ran)
+	}
+
+	// A failing critical effect blocks the transition
+	builder = NewMachineBuilder()
+	_ = builder.RegisterTransitionEffects(StateIdle, EventFinish, effect("archive", 0, true, errors.New("disk full")))
+	machine = builder.Build(events.NewBus())
+	machine.SetWorkUnit(&WorkUnit{ID: "test", Specifications: []string{"spec.md"}})
+	if err := machine.Dispatch(context.Background(), EventFinish); err == nil || machine.State() != StateIdle {
+		t.Errorf("Dispatch() error = %v, state %v; want blocked in idle", err, machine.State())
+	}
+}
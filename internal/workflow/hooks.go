@@ -0,0 +1,136 @@
+package workflow
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+const (
+	// DefaultGuardTimeout bounds a transition guard without its own timeout.
+	DefaultGuardTimeout = 30 * time.Second
+
+	// DefaultEffectTimeout bounds a transition effect without its own timeout.
+	DefaultEffectTimeout = 5 * time.Minute
+)
+
+// ErrTransitionVetoed is returned by Dispatch when a transition guard refuses
+// the transition.
+var ErrTransitionVetoed = errors.New("transition vetoed")
+
+// TransitionGuard is a guard registered on an existing transition, usually
+// by a workflow plugin. Unlike a GuardFunc it can explain a refusal, which
+// Dispatch reports.
+type TransitionGuard struct {
+	Name    string        // Unique name, e.g. "changelog/has-entry"
+	Order   int           // Lower runs first; equal orders run by name
+	Timeout time.Duration // Default DefaultGuardTimeout
+	Check   func(ctx context.Context, wu *WorkUnit) (passed bool, reason string, err error)
+}
+
+// TransitionEffect is an effect registered on an existing transition. It runs
+// after all guards pass and before the state changes, so a failing critical
+// effect blocks the transition.
+type TransitionEffect struct {
+	CriticalEffect
+	Order   int           // Lower runs first; equal orders run by name
+	Timeout time.Duration // Default DefaultEffectTimeout
+}
+
+// RegisterTransitionGuard adds a guard to every transition from a state on an
+// event. Registered guards run after the transition's own guards, in Order.
+func (b *MachineBuilder) RegisterTransitionGuard(from State, event Event, guard TransitionGuard) error {
+	if guard.Name == "" || guard.Check == nil {
+		return errors.New("transition guard needs a name and a check")
+	}
+
+	return b.updateTransitions(from, event, func(t *Transition) {
+		t.TransitionGuards = append(t.TransitionGuards, guard)
+		slices.SortStableFunc(t.TransitionGuards, func(a, b TransitionGuard) int {
+			return cmp.Or(cmp.Compare(a.Order, b.Order), cmp.Compare(a.Name, b.Name))
+		})
+	})
+}
+
+// RegisterTransitionEffects adds effects to every transition from a state on
+// an event. Registered effects run in Order once the transition is allowed.
+func (b *MachineBuilder) RegisterTransitionEffects(from State, event Event, effects ...TransitionEffect) error {
+	for _, e := range effects {
+		if e.Name == "" || e.Fn == nil {
+			return errors.New("transition effect needs a name and a function")
+		}
+	}
+
+	return b.updateTransitions(from, event, func(t *Transition) {
+		t.TransitionEffects = append(t.TransitionEffects, effects...)
+		slices.SortStableFunc(t.TransitionEffects, func(a, b TransitionEffect) int {
+			return cmp.Or(cmp.Compare(a.Order, b.Order), cmp.Compare(a.Name, b.Name))
+		})
+	})
+}
+
+// updateTransitions applies fn to every transition from a state on an event.
+func (b *MachineBuilder) updateTransitions(from State, event Event, fn func(t *Transition)) error {
+	key := TransitionKey{From: from, Event: event}
+	transitions, ok := b.transitions[key]
+	if !ok || len(transitions) == 0 {
+		return fmt.Errorf("no transition from %s on event %s", from, event)
+	}
+
+	for i := range transitions {
+		fn(&transitions[i])
+	}
+
+	return nil
+}
+
+// runTransitionGuards runs the registered guards in order and returns
+// ErrTransitionVetoed for the first that refuses, fails or times out.
+func runTransitionGuards(ctx context.Context, wu *WorkUnit, t Transition) error {
+	for _, g := range t.TransitionGuards {
+		timeout := cmp.Or(g.Timeout, DefaultGuardTimeout)
+		gctx, cancel := context.WithTimeout(ctx, timeout)
+		passed, reason, err := g.Check(gctx, wu)
+		timedOut := gctx.Err() != nil && ctx.Err() == nil
+		cancel()
+
+		switch {
+		case timedOut:
+			reason = fmt.Sprintf("timed out after %s", timeout)
+		case err != nil:
+			reason = err.Error()
+		case passed:
+			continue
+		case reason == "":
+			reason = "refused"
+		}
+
+		return fmt.Errorf("%w: %s on %s from %s: %s", ErrTransitionVetoed, g.Name, t.Event, t.From, reason)
+	}
+
+	return nil
+}
+
+// runTransitionEffects runs the registered effects in order. Only a critical
+// effect failure is returned; others are logged.
+func runTransitionEffects(ctx context.Context, wu *WorkUnit, t Transition) error {
+	for _, e := range t.TransitionEffects {
+		ectx, cancel := context.WithTimeout(ctx, cmp.Or(e.Timeout, DefaultEffectTimeout))
+		err := e.Fn(ectx, wu)
+		cancel()
+
+		if err == nil {
+			continue
+		}
+		if e.Critical {
+			return fmt.Errorf("critical effect %s failed: %w", e.Name, err)
+		}
+		slog.Debug("non-critical effect failed", "effect", e.Name, "error", err)
+	}
+
+	return nil
+}
@@ -147,6 +147,15 @@ func (m *Machine) Dispatch(ctx context.Context, event Event) error {
 		return fmt.Errorf("no valid transition from %s on event %s (guards failed)", from, event)
 	}
 
+	// Registered guards and effects may veto or augment the transition;
+	// like guards they run outside the lock
+	if err := runTransitionGuards(ctx, wu, *validTransition); err != nil {
+		return err
+	}
+	if err := runTransitionEffects(ctx, wu, *validTransition); err != nil {
+		return err
+	}
+
 	// Acquire write lock for the actual transition
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -708,7 +708,9 @@ func TestAllSteps(t *testing.T) {
 		StepPlanning,
 		StepImplementing,
 		StepReviewing,
+		StepDocumenting,
 		StepCheckpointing,
+		StepResolving,
 	}
 
 	if len(steps) != len(expectedSteps) {
@@ -881,6 +883,9 @@ func TestCanTransition(t *testing.T) {
 		{"implementing error", StateImplementing, EventError, true},
 		{"reviewing done", StateReviewing, EventReviewDone, true},
 		{"reviewing error", StateReviewing, EventError, true},
+		{"idle to documenting", StateIdle, EventDocument, true},
+		{"documenting done", StateDocumenting, EventDocumentDone, true},
+		{"documenting error", StateDocumenting, EventError, true},
 		{"global abort from any state", StateIdle, EventAbort, true},
 		{"global abort from planning", StatePlanning, EventAbort, true},
 		{"invalid transition", StatePlanning, EventImplement, false},
@@ -9,6 +9,7 @@ const (
 	StatePlanning     State = "planning"     // Agent creating specifications
 	StateImplementing State = "implementing" // Agent implementing specifications
 	StateReviewing    State = "reviewing"    // Code review phase
+	StateDocumenting  State = "documenting"  // Agent updating documentation (optional)
 	StateDone         State = "done"         // Task completed
 	StateFailed       State = "failed"       // Error state
 	StateWaiting      State = "waiting"      // Waiting for user answer to agent question
@@ -28,12 +29,14 @@ const (
 	EventPlan      Event = "plan"      // Enter planning phase
 	EventImplement Event = "implement" // Enter implementation phase
 	EventReview    Event = "review"    // Enter review phase
+	EventDocument  Event = "document"  // Enter documentation phase
 	EventFinish    Event = "finish"    // Complete task
 
 	// Phase completion.
 	EventPlanDone      Event = "plan_done"      // Planning completed
 	EventImplementDone Event = "implement_done" // Implementation completed
 	EventReviewDone    Event = "review_done"    // Review completed
+	EventDocumentDone  Event = "document_done"  // Documentation updated
 
 	// Checkpoint operations.
 	EventCheckpoint     Event = "checkpoint"
@@ -98,6 +101,12 @@ var StateRegistry = map[State]StateInfo{
 		Terminal:    false,
 		Phase:       true,
 	},
+	StateDocumenting: {
+		Name:        StateDocumenting,
+		Description: "Agent updating documentation",
+		Terminal:    false,
+		Phase:       false, // Optional, entered only when requested
+	},
 	StateDone: {
 		Name:        StateDone,
 		Description: "Task completed",
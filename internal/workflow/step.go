@@ -10,8 +10,12 @@ const (
 	StepImplementing Step = "implementing"
 	// StepReviewing is the review phase where code is reviewed.
 	StepReviewing Step = "reviewing"
+	// StepDocumenting is the optional documentation phase after implementation.
+	StepDocumenting Step = "documenting"
 	// StepCheckpointing is the checkpointing phase for git operations.
 	StepCheckpointing Step = "checkpointing"
+	// StepResolving resolves merge and rebase conflicts when syncing with the base branch.
+	StepResolving Step = "resolving"
 )
 
 // AllSteps returns all valid step names.
@@ -20,7 +24,9 @@ func AllSteps() []Step {
 		StepPlanning,
 		StepImplementing,
 		StepReviewing,
+		StepDocumenting,
 		StepCheckpointing,
+		StepResolving,
 	}
 }
 
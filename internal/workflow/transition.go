@@ -7,6 +7,10 @@ type Transition struct {
 	To      State
 	Guards  []GuardFunc
 	Effects []EffectFunc
+
+	// Registered through MachineBuilder, in run order
+	TransitionGuards  []TransitionGuard
+	TransitionEffects []TransitionEffect
 }
 
 // TransitionKey uniquely identifies a transition.
@@ -20,6 +24,7 @@ type TransitionKey struct {
 //
 //	idle -> planning -> idle (specs created)
 //	idle -> implementing -> idle (code changed)
+//	idle -> documenting -> idle (docs updated, optional)
 //	idle -> reviewing -> idle (review done)
 //	idle -> done (finish)
 var TransitionTable = map[TransitionKey][]Transition{
@@ -70,6 +75,17 @@ var TransitionTable = map[TransitionKey][]Transition{
 		{From: StateImplementing, Event: EventUndo, To: StateReverting, Guards: []GuardFunc{GuardCanUndo}},
 	},
 
+	// === Documentation Phase (optional) ===
+	{StateIdle, EventDocument}: {
+		{From: StateIdle, Event: EventDocument, To: StateDocumenting, Guards: []GuardFunc{GuardHasSpecifications}},
+	},
+	{StateDocumenting, EventDocumentDone}: {
+		{From: StateDocumenting, Event: EventDocumentDone, To: StateIdle},
+	},
+	{StateDocumenting, EventError}: {
+		{From: StateDocumenting, Event: EventError, To: StateIdle},
+	},
+
 	// === Review Phase ===
 	{StateIdle, EventReview}: {
 		{From: StateIdle, Event: EventReview, To: StateReviewing, Guards: []GuardFunc{GuardCanReview}},